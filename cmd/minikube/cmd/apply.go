@@ -0,0 +1,206 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/addons"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/clusterspec"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var applyFile string
+
+// applyCmd represents the apply command
+var applyCmd = &cobra.Command{
+	Use:   "apply -f FILE",
+	Short: "Creates or changes a cluster to match a cluster spec",
+	Long: `Creates or changes a cluster to match the versioned YAML spec in FILE, which can be committed to git to share the definition of a development cluster.
+
+The cluster is created when it does not exist. Otherwise it is started again to apply a new Kubernetes version or new certificate options, its workers are added or deleted, control planes are added to highly available clusters, and its addons are enabled or disabled. The driver, the container runtime and the resources of the nodes can only be changed by deleting the cluster.
+
+apiVersion: minikube.sigs.k8s.io/v1alpha1
+kind: Cluster
+metadata:
+  name: dev
+spec:
+  driver: docker
+  kubernetesVersion: v1.28.4
+  containerRuntime: containerd
+  nodes:
+    controlPlanes: 1
+    workers: 2
+  resources:
+    cpus: "2"
+    memory: 4g
+    diskSize: 20g
+  addons:
+    ingress: true
+    metrics-server: true
+    storage-provisioner: true
+  certificates:
+    apiServerNames: [dev.example.com]
+    apiServerIPs: [192.168.1.10]
+    expiration: 8760h`,
+	Example: `minikube apply -f cluster.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if applyFile == "" || len(args) != 0 {
+			exit.Message(reason.Usage, "Usage: minikube apply -f FILE")
+		}
+		spec, err := clusterspec.Load(applyFile)
+		if err != nil {
+			exit.Message(reason.Usage, "Invalid cluster spec {{.file}}: {{.err}}", out.V{"file": applyFile, "err": err})
+		}
+		for name := range spec.Spec.Addons {
+			if _, ok := assets.Addons[name]; !ok {
+				exit.Message(reason.AddonUnsupported, "'{{.name}}' is not a valid minikube addon", out.V{"name": name})
+			}
+		}
+
+		name := spec.Metadata.Name
+		if name == "" {
+			name = ClusterFlagValue()
+		} else if cmd.Flags().Changed(config.ProfileName) && name != ClusterFlagValue() {
+			exit.Message(reason.Usage, "The spec is the one of cluster {{.name}}, not of {{.profile}}", out.V{"name": name, "profile": ClusterFlagValue()})
+		}
+		viper.Set(config.ProfileName, name)
+
+		cc, err := config.Load(name)
+		if err != nil && !config.IsNotExist(err) {
+			exit.Error(reason.HostConfigLoad, "Error getting cluster config", err)
+		}
+		if cc == nil {
+			out.Step(style.Happy, "Creating cluster {{.name}} from {{.file}}", out.V{"name": name, "file": applyFile})
+			setStartFlagsFromSpec(spec, true)
+			runStart(startCmd, nil)
+		} else {
+			if conflicts := spec.Conflicts(*cc); len(conflicts) > 0 {
+				exit.Message(reason.GuestApplyConflict, "The cluster {{.name}} can not be changed to match the spec, delete it with 'minikube delete -p {{.name}}' and apply it again:\n\t{{.conflicts}}", out.V{"name": name, "conflicts": strings.Join(conflicts, "\n\t")})
+			}
+			changes := spec.Changes(*cc)
+			for _, c := range changes {
+				out.Infof("{{.change}}", out.V{"change": c})
+			}
+			if len(changes) > 0 || !clusterRunning(*cc) {
+				out.Step(style.Restarting, "Starting cluster {{.name}} to apply {{.file}}", out.V{"name": name, "file": applyFile})
+				setStartFlagsFromSpec(spec, false)
+				runStart(startCmd, nil)
+			}
+		}
+
+		cc, err = config.Load(name)
+		if err != nil {
+			exit.Error(reason.HostConfigLoad, "Error getting cluster config", err)
+		}
+		applyNodes(cmd, cc, spec.Spec.Nodes)
+		applyAddons(cc, spec.Spec.Addons)
+		out.Step(style.Ready, "The cluster {{.name}} matches {{.file}}", out.V{"name": name, "file": applyFile})
+	},
+}
+
+// setStartFlagsFromSpec sets the flags of 'minikube start' to the ones of spec, the nodes of an existing cluster being applied by applyNodes
+func setStartFlagsFromSpec(spec *clusterspec.Cluster, create bool) {
+	s := spec.Spec
+	flags := map[string]string{
+		"driver":          s.Driver,
+		kubernetesVersion: s.KubernetesVersion,
+		containerRuntime:  s.ContainerRuntime,
+		cpus:              s.Resources.CPUs,
+		memory:            s.Resources.Memory,
+		"apiserver-names": strings.Join(s.Certificates.APIServerNames, ","),
+		"apiserver-ips":   strings.Join(s.Certificates.APIServerIPs, ","),
+		certExpiration:    s.Certificates.Expiration,
+	}
+	if create {
+		flags[humanReadableDiskSize] = s.Resources.DiskSize
+		if n := s.Nodes.ControlPlanes + s.Nodes.Workers; n > 1 {
+			flags[nodes] = strconv.Itoa(n)
+		}
+		if s.Nodes.ControlPlanes > 1 {
+			flags[controlPlanes] = strconv.Itoa(s.Nodes.ControlPlanes)
+		}
+	}
+	for name, value := range flags {
+		if value == "" {
+			continue
+		}
+		if err := startCmd.Flags().Set(name, value); err != nil {
+			exit.Message(reason.Usage, "Invalid cluster spec {{.file}}: {{.err}}", out.V{"file": applyFile, "err": err})
+		}
+	}
+}
+
+// applyNodes adds and deletes nodes until cc has the nodes of the spec
+func applyNodes(cmd *cobra.Command, cc *config.ClusterConfig, want clusterspec.Nodes) {
+	cps, workers := clusterspec.CountNodes(*cc)
+	poolName = ""
+	for ; cps < want.ControlPlanes; cps++ {
+		cp, worker = true, true
+		addNode(cmd, cc)
+	}
+	for ; workers < want.Workers; workers++ {
+		cp, worker = false, true
+		addNode(cmd, cc)
+	}
+	for i := len(cc.Nodes) - 1; i >= 0 && workers > want.Workers; i-- {
+		n := cc.Nodes[i]
+		if n.ControlPlane || n.Pool != "" {
+			continue
+		}
+		deleteNode(cc, n.Name)
+		workers--
+	}
+}
+
+// applyAddons enables and disables the addons of cc as in the spec, the addons missing from the spec are left as they are
+func applyAddons(cc *config.ClusterConfig, want map[string]bool) {
+	var names []string
+	for name := range want {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		enable := want[name]
+		if assets.Addons[name].IsEnabled(cc) == enable {
+			klog.Infof("addon %s is already enabled=%v", name, enable)
+			continue
+		}
+		if err := addons.SetAndSave(cc.Name, name, strconv.FormatBool(enable)); err != nil {
+			exit.Error(reason.InternalAddonEnable, "Failed to change the addon", err)
+		}
+		if enable {
+			out.Styled(style.AddonEnable, "The '{{.name}}' addon is enabled", out.V{"name": name})
+		} else {
+			out.Styled(style.AddonDisable, "The '{{.name}}' addon is disabled", out.V{"name": name})
+		}
+	}
+}
+
+func init() {
+	applyCmd.Flags().StringVarP(&applyFile, "filename", "f", "", "The file of the cluster spec, or - to read it from stdin")
+}
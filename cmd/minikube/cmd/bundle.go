@@ -0,0 +1,280 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	cmdConfig "k8s.io/minikube/cmd/minikube/cmd/config"
+	"k8s.io/minikube/pkg/drivers/kic"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/images"
+	"k8s.io/minikube/pkg/minikube/bundle"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/detect"
+	"k8s.io/minikube/pkg/minikube/download"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/driver/auxdriver"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/image"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/version"
+)
+
+var (
+	bundleKubernetesVersion string
+	bundleContainerRuntime  string
+	bundleDriver            string
+	bundleAddons            []string
+)
+
+// bundleCmd represents the bundle command
+var bundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Export and import the artifacts needed to start a cluster offline",
+	Long:  "Packs the artifacts minikube downloads into a single archive on a host with internet access, and imports them on an air-gapped host, which starts clusters with 'minikube start --offline'.",
+}
+
+// bundleExportCmd represents the bundle export command
+var bundleExportCmd = &cobra.Command{
+	Use:   "export FILE",
+	Short: "Download the artifacts of a cluster and pack them into FILE",
+	Long: `Downloads, unless they are cached, the artifacts minikube needs to start a cluster with the given Kubernetes version, container runtime and driver, and packs them into FILE, a gzipped tarball:
+the ISO or the kicbase image, the preload tarball (or the Kubernetes binaries and images without one), the images of the addons and of kube-vip for multi-control-plane clusters, and the driver binary of the kvm2, hyperkit and vz drivers.`,
+	Example: `minikube bundle export minikube-bundle.tar.gz --driver=kvm2 --kubernetes-version=v1.28.4 --addons=storage-provisioner,metrics-server`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		drv := bundleDriver
+		if drv == "" {
+			drv = driver.Docker
+		}
+		if !driver.Supported(drv) {
+			exit.Message(reason.Usage, "The driver '{{.driver}}' is not supported on {{.os}}/{{.arch}}", out.V{"driver": drv, "os": runtime.GOOS, "arch": runtime.GOARCH})
+		}
+		k8sVersion := bundleKubernetesVersion
+		rt := bundleContainerRuntime
+
+		roots := bundleRoots()
+		var files []bundle.File
+		add := func(p string) {
+			entry, err := roots.Entry(p)
+			if err != nil {
+				exit.Error(reason.HostBundle, "Unable to add the artifact to the bundle", err)
+			}
+			files = append(files, bundle.File{Entry: entry, Path: p})
+		}
+
+		out.Step(style.FileDownload, "Downloading the artifacts of Kubernetes {{.version}} with {{.runtime}} for the {{.driver}} driver ...", out.V{"version": k8sVersion, "runtime": rt, "driver": drv})
+		switch {
+		case driver.IsKIC(drv):
+			if err := download.ImageToCache(kic.BaseImage); err != nil {
+				exit.Error(reason.InetCacheTar, "Failed to cache the kicbase image", err)
+			}
+			add(download.ImagePathInCache(kic.BaseImage))
+		case driver.IsVM(drv):
+			u, err := download.ISO(download.DefaultISOURLs(), false)
+			if err != nil {
+				exit.Error(reason.InetCacheISO, "Failed to cache the ISO", err)
+			}
+			p, err := download.LocalISOPath(u)
+			if err != nil {
+				exit.Error(reason.InetCacheISO, "Failed to cache the ISO", err)
+			}
+			add(p)
+		}
+
+		if download.PreloadExists(k8sVersion, rt, drv, true) {
+			if err := download.Preload(k8sVersion, rt, drv); err != nil {
+				exit.Error(reason.InetCacheTar, "Failed to cache the preload tarball", err)
+			}
+			add(download.TarballPath(k8sVersion, rt))
+			if fileExistsAt(download.PreloadChecksumPath(k8sVersion, rt)) {
+				add(download.PreloadChecksumPath(k8sVersion, rt))
+			}
+		} else {
+			for _, b := range constants.KubernetesReleaseBinaries {
+				p, err := download.Binary(b, k8sVersion, "linux", runtime.GOARCH, download.DefaultKubeBinariesURL())
+				if err != nil {
+					exit.Error(reason.InetCacheBinaries, "Failed to cache the Kubernetes binaries", err)
+				}
+				add(p)
+			}
+			imgs, err := images.Kubeadm("", k8sVersion)
+			if err != nil {
+				exit.Error(reason.InetCacheBinaries, "Failed to list the Kubernetes images", err)
+			}
+			for _, p := range cacheBundleImages(imgs) {
+				add(p)
+			}
+		}
+
+		// the preloads published before kube-vip was added to them lack its images, which multi-control-plane clusters pull
+		addonImages := append(bundleAddonImages(bundleAddons), images.KubeVip(""), images.KubeVipCloudProvider(""))
+		for _, p := range cacheBundleImages(addonImages) {
+			add(p)
+		}
+
+		if p := bundleDriverBinary(drv); p != "" {
+			files = append(files, bundle.File{Entry: "bin/" + filepath.Base(p), Path: p})
+		}
+
+		m := bundle.Manifest{
+			MinikubeVersion:   version.GetVersion(),
+			KubernetesVersion: k8sVersion,
+			ContainerRuntime:  rt,
+			Driver:            drv,
+			Arch:              runtime.GOARCH,
+			Images:            addonImages,
+		}
+		out.Step(style.Waiting, "Packing {{.count}} artifacts into {{.file}} ...", out.V{"count": len(files), "file": args[0]})
+		if err := bundle.Write(args[0], m, files); err != nil {
+			exit.Error(reason.HostBundle, "Unable to write the bundle", err)
+		}
+		out.Step(style.Ready, "Done! Import it on the offline host with: minikube bundle import {{.file}}", out.V{"file": filepath.Base(args[0])})
+	},
+}
+
+// bundleImportCmd represents the bundle import command
+var bundleImportCmd = &cobra.Command{
+	Use:     "import FILE",
+	Short:   "Import the artifacts of a bundle into the cache of minikube",
+	Long:    "Extracts the artifacts of a bundle made by 'minikube bundle export' into the cache of minikube, and adds the images of its addons to the images loaded into the clusters, without reaching the network.",
+	Example: "minikube bundle import minikube-bundle.tar.gz",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		m, err := bundle.Read(args[0])
+		if err != nil {
+			exit.Error(reason.HostBundle, "Unable to read the bundle", err)
+		}
+		if m.Arch != runtime.GOARCH {
+			exit.Message(reason.Usage, "The bundle is for {{.bundle}} hosts, not for {{.arch}} ones", out.V{"bundle": m.Arch, "arch": runtime.GOARCH})
+		}
+		if m.MinikubeVersion != version.GetVersion() {
+			out.WarningT("The bundle was made by minikube {{.bundle}}, whose ISO or kicbase image may not be the one of minikube {{.version}}", out.V{"bundle": m.MinikubeVersion, "version": version.GetVersion()})
+		}
+
+		out.Step(style.Waiting, "Importing {{.count}} artifacts ...", out.V{"count": len(m.Files)})
+		if _, err := bundle.Extract(args[0], bundleRoots()); err != nil {
+			exit.Error(reason.HostBundle, "Unable to import the bundle", err)
+		}
+		if len(m.Images) > 0 {
+			if err := cmdConfig.AddToConfigMap(cacheImageConfigKey, m.Images); err != nil {
+				exit.Error(reason.InternalAddConfig, "Failed to update config", err)
+			}
+		}
+
+		startArgs := "--offline --driver=" + m.Driver + " --kubernetes-version=" + m.KubernetesVersion
+		if m.ContainerRuntime != "" {
+			startArgs += " --container-runtime=" + m.ContainerRuntime
+		}
+		out.Step(style.Ready, "Imported Kubernetes {{.version}} for the {{.driver}} driver, start a cluster with: minikube start {{.args}}", out.V{"version": m.KubernetesVersion, "driver": m.Driver, "args": startArgs})
+	},
+}
+
+// bundleRoots returns the directories the entries of the bundles are relative to
+func bundleRoots() bundle.Roots {
+	return bundle.Roots{
+		"cache": localpath.MakeWritableCachePath(),
+		"bin":   localpath.MakeMiniPath("bin"),
+	}
+}
+
+// bundleAddonImages returns the default images of the addons, with their registries
+func bundleAddonImages(addons []string) []string {
+	var imgs []string
+	for _, name := range addons {
+		addon, ok := assets.Addons[name]
+		if !ok {
+			exit.Message(reason.Usage, "The addon '{{.name}}' does not exist, see 'minikube addons list'", out.V{"name": name})
+		}
+		for k, img := range addon.Images {
+			if reg := addon.Registries[k]; reg != "" {
+				img = reg + "/" + img
+			}
+			imgs = append(imgs, img)
+		}
+	}
+	sort.Strings(imgs)
+	return imgs
+}
+
+// cacheBundleImages caches imgs, and returns the tarballs of the ones which exist
+func cacheBundleImages(imgs []string) []string {
+	if err := image.SaveToDir(imgs, detect.ImageCacheDir(), false); err != nil {
+		exit.Error(reason.InetCacheTar, "Failed to cache the images", err)
+	}
+	var paths []string
+	for _, img := range imgs {
+		if p := image.CachePath(detect.ImageCacheDir(), img); fileExistsAt(p) {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// bundleDriverBinary returns the binary drv runs, installing it if needed, or "" if it has none
+func bundleDriverBinary(drv string) string {
+	var exe string
+	switch drv {
+	case driver.KVM2, driver.HyperKit:
+		exe = "docker-machine-driver-" + drv
+	case driver.VZ:
+		exe = "vfkit"
+	default:
+		return ""
+	}
+	v, err := version.GetSemverVersion()
+	if err != nil {
+		exit.Error(reason.InternalSemverParse, "Error parsing minikube version", err)
+	}
+	if err := auxdriver.InstallOrUpdate(drv, localpath.MakeMiniPath("bin"), v, viper.GetBool(interactive), false); err != nil {
+		exit.Error(reason.DrvNotFound, "Failed to install the driver binary", err)
+	}
+	if p := localpath.MakeMiniPath("bin", exe); fileExistsAt(p) {
+		return p
+	}
+	p, err := exec.LookPath(exe)
+	if err != nil {
+		out.WarningT("Unable to find the {{.driver}} binary, the bundle does not hold it", out.V{"driver": exe})
+		return ""
+	}
+	return p
+}
+
+// fileExistsAt returns whether p exists
+func fileExistsAt(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
+}
+
+func init() {
+	bundleExportCmd.Flags().StringVar(&bundleKubernetesVersion, "kubernetes-version", constants.DefaultKubernetesVersion, "The Kubernetes version of the bundle")
+	bundleExportCmd.Flags().StringVar(&bundleContainerRuntime, "container-runtime", constants.Docker, "The container runtime of the bundle")
+	bundleExportCmd.Flags().StringVar(&bundleDriver, "driver", "", "The driver of the bundle (defaults to docker)")
+	bundleExportCmd.Flags().StringSliceVar(&bundleAddons, "addons", []string{"storage-provisioner"}, "The addons whose images the bundle holds")
+	bundleCmd.AddCommand(bundleExportCmd)
+	bundleCmd.AddCommand(bundleImportCmd)
+}
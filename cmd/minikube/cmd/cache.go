@@ -17,17 +17,28 @@ limitations under the License.
 package cmd
 
 import (
+	"os"
+	"path/filepath"
+	"time"
+
+	units "github.com/docker/go-units"
+	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"k8s.io/klog/v2"
 	cmdConfig "k8s.io/minikube/cmd/minikube/cmd/config"
+	"k8s.io/minikube/pkg/minikube/cachegc"
 	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/detect"
+	"k8s.io/minikube/pkg/minikube/download"
 	"k8s.io/minikube/pkg/minikube/exit"
 	"k8s.io/minikube/pkg/minikube/image"
+	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/machine"
 	"k8s.io/minikube/pkg/minikube/node"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
 )
 
 // cacheImageConfigKey is the config field name used to store which images we have previously cached
@@ -45,18 +56,33 @@ var cacheCmd = &cobra.Command{
 // addCacheCmd represents the cache add command
 var addCacheCmd = &cobra.Command{
 	Use:   "add",
-	Short: "Add an image to local cache.",
-	Long:  "Add an image to local cache.",
+	Short: "Add an image or an OCI artifact to local cache.",
+	Long: `Add an image to local cache, and load it into the cluster.
+OCI artifacts, like Helm charts, are given with an oci:// prefix: they are cached by digest, and pushed to the registry addon of the cluster if it is enabled, for offline installs.`,
+	Example: "minikube cache add oci://registry-1.docker.io/bitnamicharts/nginx:15.0.0",
 	Run: func(cmd *cobra.Command, args []string) {
-		out.WarningT("\"minikube cache\" will be deprecated in upcoming versions, please switch to \"minikube image load\"")
+		images, artifacts := splitArtifacts(args)
+		profiles := cacheAddProfiles()
+		if len(images) > 0 {
+			out.WarningT("\"minikube cache\" will be deprecated in upcoming versions, please switch to \"minikube image load\"")
+		}
 		// Cache and load images into docker daemon
-		if err := machine.CacheAndLoadImages(args, cacheAddProfiles(), false); err != nil {
+		if err := machine.CacheAndLoadImages(images, profiles, false); err != nil {
 			exit.Error(reason.InternalCacheLoad, "Failed to cache and load images", err)
 		}
+		if len(artifacts) > 0 {
+			if err := image.SaveArtifacts(artifacts, image.ArtifactCacheDir()); err != nil {
+				exit.Error(reason.InternalCacheLoad, "Failed to cache artifacts", err)
+			}
+			if err := node.PushArtifactsToRegistry(artifacts, profiles); err != nil {
+				exit.Error(reason.GuestCacheLoad, "Failed to push artifacts to the registry addon", err)
+			}
+		}
 		// Add images to config file
 		if err := cmdConfig.AddToConfigMap(cacheImageConfigKey, args); err != nil {
 			exit.Error(reason.InternalAddConfig, "Failed to update config", err)
 		}
+		enforceCacheQuota()
 	},
 }
 
@@ -90,10 +116,14 @@ var deleteCacheCmd = &cobra.Command{
 		if err := cmdConfig.DeleteFromConfigMap(cacheImageConfigKey, args); err != nil {
 			exit.Error(reason.InternalDelConfig, "Failed to delete images from config", err)
 		}
+		images, artifacts := splitArtifacts(args)
 		// Delete images from cache/images directory
-		if err := image.DeleteFromCacheDir(args); err != nil {
+		if err := image.DeleteFromCacheDir(images); err != nil {
 			exit.Error(reason.HostDelCache, "Failed to delete images", err)
 		}
+		if err := image.DeleteArtifacts(artifacts, image.ArtifactCacheDir()); err != nil {
+			exit.Error(reason.HostDelCache, "Failed to delete artifacts", err)
+		}
 	},
 }
 
@@ -103,16 +133,202 @@ var reloadCacheCmd = &cobra.Command{
 	Short: "reload cached images.",
 	Long:  "reloads images previously added using the 'cache add' subcommand",
 	Run: func(cmd *cobra.Command, args []string) {
-		err := node.CacheAndLoadImagesInConfig(cacheAddProfiles())
+		profiles := cacheAddProfiles()
+		err := node.CacheAndLoadImagesInConfig(profiles)
 		if err != nil {
 			exit.Error(reason.GuestCacheLoad, "Failed to reload cached images", err)
 		}
+		artifacts, err := node.ArtifactsInConfigFile()
+		if err != nil {
+			exit.Error(reason.GuestCacheLoad, "Failed to read cached artifacts", err)
+		}
+		if err := node.PushArtifactsToRegistry(artifacts, profiles); err != nil {
+			exit.Error(reason.GuestCacheLoad, "Failed to push artifacts to the registry addon", err)
+		}
+	},
+}
+
+// splitArtifacts separates the OCI artifacts from the images in the arguments of the cache commands
+func splitArtifacts(args []string) (images, artifacts []string) {
+	for _, a := range args {
+		if image.IsArtifact(a) {
+			artifacts = append(artifacts, a)
+		} else {
+			images = append(images, a)
+		}
+	}
+	return images, artifacts
+}
+
+var (
+	pruneMaxSize   string
+	pruneOlderThan time.Duration
+	pruneDryRun    bool
+	pruneAll       bool
+)
+
+// pruneCacheCmd represents the cache prune command
+var pruneCacheCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Evict the least recently used images, ISOs, preloads and binaries from the local cache.",
+	Long: `Evicts the least recently used entries of the local cache until it is under --max-size, which defaults to the quota set with 'minikube config set cache-quota',
+and the entries unused for longer than --older-than. With --all, all the entries are evicted.
+The preloads, ISOs, base images and binaries of the existing clusters and the images added with 'minikube cache add' are never evicted,
+delete the clusters or the images first to evict them.`,
+	Example: `minikube cache prune --max-size=20GB
+minikube cache prune --older-than=720h --dry-run
+minikube cache prune --all`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		o := cachegc.Options{OlderThan: pruneOlderThan, Protected: cacheInUse(), DryRun: pruneDryRun}
+		maxSize := pruneMaxSize
+		if maxSize == "" {
+			maxSize = viper.GetString(config.CacheQuota)
+		}
+		if maxSize != "" {
+			var err error
+			if o.MaxSize, err = units.FromHumanSize(maxSize); err != nil {
+				exit.Message(reason.Usage, "Invalid --max-size: {{.error}}", out.V{"error": err})
+			}
+		}
+		o.Unused = pruneAll
+		if o.MaxSize == 0 && o.OlderThan == 0 && !o.Unused {
+			exit.Message(reason.Usage, "Choose the entries of the cache to evict with --max-size, --older-than or --all")
+		}
+
+		evicted, err := cachegc.Prune(localpath.MakeWritableCachePath(), o)
+		if len(evicted) > 0 {
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Kind", "Entry", "Size", "Last Used"})
+			table.SetAutoFormatHeaders(false)
+			table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+			table.SetCenterSeparator("|")
+			for _, e := range evicted {
+				rel, _ := filepath.Rel(localpath.MakeWritableCachePath(), e.Path)
+				table.Append([]string{e.Kind, rel, units.HumanSize(float64(e.Size)), e.LastUsed.Format(time.RFC3339)})
+			}
+			table.Render()
+		}
+		if err != nil {
+			exit.Error(reason.HostDelCache, "Failed to prune the cache", err)
+		}
+		size := units.HumanSize(float64(cachegc.Size(evicted)))
+		switch {
+		case len(evicted) == 0:
+			out.Styled(style.Empty, "Nothing to evict from the cache")
+		case pruneDryRun:
+			out.Styled(style.Notice, "{{.count}} entries of the cache would be evicted, freeing {{.size}}", out.V{"count": len(evicted), "size": size})
+		default:
+			out.Styled(style.Deleted, "Evicted {{.count}} entries of the cache, freeing {{.size}}", out.V{"count": len(evicted), "size": size})
+		}
+	},
+}
+
+// cacheInUse returns the paths of the cache used by the existing clusters: their preloads, ISOs, base images and binaries,
+// and the images and artifacts added with minikube cache add
+func cacheInUse() []string {
+	profiles, _, err := config.ListProfiles()
+	if err != nil {
+		klog.Warningf("error listing profiles: %v", err)
+	}
+	var paths []string
+	for _, p := range profiles {
+		cc := p.Config
+		if cc == nil {
+			continue
+		}
+		k8s := cc.KubernetesConfig
+		paths = append(paths, download.TarballPath(k8s.KubernetesVersion, k8s.ContainerRuntime))
+		if cc.PreloadFrom != "" {
+			paths = append(paths, download.CustomTarballPath(cc.PreloadFrom))
+		}
+		if iso, err := download.LocalISOPath(cc.MinikubeISO); err == nil {
+			paths = append(paths, iso)
+		}
+		if cc.KicBaseImage != "" {
+			paths = append(paths, download.ImagePathInCache(cc.KicBaseImage))
+		}
+		bins, _ := filepath.Glob(localpath.MakeWritableCachePath("*", "*", k8s.KubernetesVersion))
+		paths = append(paths, bins...)
+	}
+	added, err := cmdConfig.ListConfigMap(cacheImageConfigKey)
+	if err != nil {
+		klog.Warningf("error listing the cached images: %v", err)
+	}
+	for _, img := range added {
+		// the artifacts share the blobs of one layout
+		if image.IsArtifact(img) {
+			paths = append(paths, image.ArtifactCacheDir())
+			continue
+		}
+		paths = append(paths, image.CachePath(detect.ImageCacheDir(), img))
+	}
+	return paths
+}
+
+// enforceCacheQuota evicts the least recently used entries of the cache beyond the quota set with 'minikube config set cache-quota'
+func enforceCacheQuota() {
+	quota := viper.GetString(config.CacheQuota)
+	if quota == "" {
+		return
+	}
+	maxSize, err := units.FromHumanSize(quota)
+	if err != nil {
+		klog.Warningf("invalid %s %q: %v", config.CacheQuota, quota, err)
+		return
+	}
+	evicted, err := cachegc.Prune(localpath.MakeWritableCachePath(), cachegc.Options{MaxSize: maxSize, Protected: cacheInUse()})
+	if err != nil {
+		klog.Warningf("failed to keep the cache under %s: %v", quota, err)
+	}
+	if len(evicted) > 0 {
+		out.Styled(style.Deleted, "Evicted {{.count}} least recently used entries of the cache, freeing {{.size}} to keep it under {{.quota}}", out.V{"count": len(evicted), "size": units.HumanSize(float64(cachegc.Size(evicted))), "quota": quota})
+	}
+}
+
+var warmManifests []string
+
+// warmCacheCmd represents the cache warm command
+var warmCacheCmd = &cobra.Command{
+	Use:   "warm",
+	Short: "Pull the images of Kubernetes manifests into all nodes.",
+	Long: `Pull the images referenced by Kubernetes manifests and Helm charts into all the nodes of the cluster in parallel,
+so they are ready when the manifests are applied. Helm charts are rendered with their default values, which requires helm.`,
+	Example: "minikube cache warm -f ./k8s/",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(warmManifests) == 0 {
+			exit.Message(reason.Usage, "Please provide the manifests to warm the cache for with -f")
+		}
+		images, err := image.FromManifests(warmManifests)
+		if err != nil {
+			exit.Message(reason.Usage, "Failed to read the images of the manifests: {{.error}}", out.V{"error": err})
+		}
+		if len(images) == 0 {
+			out.Styled(style.Empty, "No image found in the manifests")
+			return
+		}
+		for _, p := range cacheAddProfiles() {
+			out.Step(style.Pulling, "Pulling {{.count}} images into {{.profile}} ...", out.V{"count": len(images), "profile": p.Name})
+			for _, img := range images {
+				out.Infof("{{.image}}", out.V{"image": img})
+			}
+			if err := machine.PullImages(images, p); err != nil {
+				exit.Error(reason.GuestImagePull, "Failed to pull images", err)
+			}
+		}
 	},
 }
 
 func init() {
 	addCacheCmdFlags()
+	warmCacheCmd.Flags().StringSliceVarP(&warmManifests, "filename", "f", nil, "Manifest files, Helm charts or directories containing them")
 	cacheCmd.AddCommand(addCacheCmd)
 	cacheCmd.AddCommand(deleteCacheCmd)
 	cacheCmd.AddCommand(reloadCacheCmd)
+	cacheCmd.AddCommand(warmCacheCmd)
+	pruneCacheCmd.Flags().StringVar(&pruneMaxSize, "max-size", "", "The size the cache is pruned under (ex. 20GB), the cache-quota setting by default")
+	pruneCacheCmd.Flags().DurationVar(&pruneOlderThan, "older-than", 0, "Evict the entries unused for longer (ex. 720h)")
+	pruneCacheCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "If true, only list the entries which would be evicted")
+	pruneCacheCmd.Flags().BoolVar(&pruneAll, allFlag, false, "If true, evict all the entries but the ones in use")
+	cacheCmd.AddCommand(pruneCacheCmd)
 }
@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/bootstrapper"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var certsOutput string
+
+// certsCmd represents the set of certs subcommands
+var certsCmd = &cobra.Command{
+	Use:   "certs",
+	Short: "Inspect the certificates of a minikube cluster",
+	Long:  "Inspect the certificates of a minikube cluster",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.Message(reason.Usage, "Usage: minikube certs [history]")
+	},
+}
+
+// certsHistoryCmd represents the certs history command
+var certsHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show the history of certificate operations for a profile",
+	Long:  "Show every certificate generation, renewal, deletion and symlink installation recorded for a profile, and why it happened.",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit.Message(reason.Usage, "Usage: minikube certs history")
+		}
+
+		events, err := bootstrapper.CertHistory(ClusterFlagValue())
+		if err != nil {
+			exit.Error(reason.HostCertHistory, "Unable to read the cert history", err)
+		}
+
+		switch certsOutput {
+		case "json":
+			printCertHistoryJSON(events)
+		case "table":
+			printCertHistoryTable(events)
+		default:
+			exit.Message(reason.Usage, "Invalid output format '{{.output}}'. Valid values: 'table', 'json'", out.V{"output": certsOutput})
+		}
+	},
+}
+
+func printCertHistoryTable(events []bootstrapper.CertEvent) {
+	if len(events) == 0 {
+		out.Styled(style.Empty, "No certificate operations have been recorded for this profile.")
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Time", "Operation", "Path", "Reason"})
+	table.SetAutoFormatHeaders(false)
+	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+	table.SetCenterSeparator("|")
+	for _, e := range events {
+		table.Append([]string{e.Time.Local().Format(time.RFC3339), e.Operation, e.Path, e.Reason})
+	}
+	table.Render()
+}
+
+func printCertHistoryJSON(events []bootstrapper.CertEvent) {
+	jsonString, err := json.Marshal(events)
+	if err != nil {
+		exit.Error(reason.InternalJSONMarshal, "Failed to marshal cert history", err)
+	}
+	out.String(string(jsonString))
+}
+
+func init() {
+	certsHistoryCmd.Flags().StringVarP(&certsOutput, "output", "o", "table", "The output format. One of 'table', 'json'")
+	certsCmd.AddCommand(certsHistoryCmd)
+}
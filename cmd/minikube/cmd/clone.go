@@ -0,0 +1,331 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/hooks"
+	"k8s.io/minikube/pkg/minikube/idle"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var cloneImages bool
+
+// cloneCmd represents the clone command
+var cloneCmd = &cobra.Command{
+	Use:   "clone SRC DST",
+	Short: "Create a cluster with the configuration of another one",
+	Long: `Creates the cluster DST with the configuration, nodes and addons of the cluster SRC, so that copies of a prepared environment can be iterated on without configuring them from scratch.
+
+The machines of DST get new IPs, and their certificates are issued for them. The images of the running nodes of SRC, but the ones of Kubernetes, are loaded into the nodes of DST. The static IP, subnet, exposed ports and load balancer IP range of SRC are not cloned as they would conflict with the ones of SRC, and the ports it forwards with --port-forward are forwarded from free host ports. Its start hooks are not cloned either, so that no command runs before being reviewed, nor its persistent mounts, which would share its host directories, nor are the credentials of its registries and of their mirrors.`,
+	Example: "minikube clone dev dev-experiment",
+	Args:    cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		src, dst := args[0], args[1]
+		if !config.ProfileNameValid(dst) || config.ProfileNameInReservedKeywords(dst) {
+			exit.Message(reason.Usage, "The profile name {{.name}} is not valid: only alphanumeric and dashes '-' are permitted, starting with alphanumeric", out.V{"name": dst})
+		}
+		api, srcCC := mustload.Partial(src)
+		api.Close()
+		if config.ProfileExists(dst) {
+			exit.Message(reason.Usage, "The cluster {{.name}} already exists, delete it with 'minikube delete -p {{.name}}' or choose another name", out.V{"name": dst})
+		}
+
+		dstCC, workers, warnings, err := cloneConfig(*srcCC, dst)
+		if err != nil {
+			exit.Message(reason.Usage, "Unable to clone the cluster {{.name}}: {{.err}}", out.V{"name": src, "err": err})
+		}
+		for _, w := range warnings {
+			out.WarningT("{{.warning}}", out.V{"warning": w})
+		}
+
+		var imgDir string
+		var imgs map[string][]string
+		if cloneImages {
+			imgDir = localpath.MakeMiniPath("clone", dst)
+			if err := os.MkdirAll(imgDir, 0755); err != nil {
+				exit.Error(reason.HostHomeMkdir, "Error creating minikube directory", err)
+			}
+			defer func() {
+				if err := os.RemoveAll(imgDir); err != nil {
+					klog.Warningf("removing %s: %v", imgDir, err)
+				}
+			}()
+			imgs = exportCloneImages(srcCC, imgDir)
+		}
+
+		out.Step(style.Happy, "Cloning cluster {{.src}} into {{.dst}}", out.V{"src": src, "dst": dst})
+		cc, srcNodes := startCloned(cmd, dstCC, workers)
+		if len(imgs) > 0 {
+			importCloneImages(cc, src, srcNodes, imgs, imgDir)
+		}
+		out.Step(style.Ready, "Cloned cluster {{.src}} into {{.dst}}", out.V{"src": src, "dst": dst})
+	},
+}
+
+// copyConfig returns a deep copy of src, which leaves it untouched
+func copyConfig(src config.ClusterConfig) (config.ClusterConfig, error) {
+	var cc config.ClusterConfig
+	data, err := json.Marshal(src)
+	if err != nil {
+		return cc, errors.Wrap(err, "marshal config")
+	}
+	if err := json.Unmarshal(data, &cc); err != nil {
+		return cc, errors.Wrap(err, "unmarshal config")
+	}
+	return cc, nil
+}
+
+// redactCredentials blanks the credentials kept in plain text in cc, so that they are not copied out of the profile of the cluster,
+// and returns the warnings telling how to set them again
+func redactCredentials(cc *config.ClusterConfig) []string {
+	var warnings []string
+	for i := range cc.RegistryMirrors {
+		m := &cc.RegistryMirrors[i]
+		if m.Username == "" && m.Password == "" {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("The credentials of the mirror %s of %s are not copied, set them again with 'minikube registry-mirror add %s %s --username --password'", m.URL, m.Registry, m.Registry, m.URL))
+		m.Username = ""
+		m.Password = ""
+	}
+	// the credentials are all the entry of the registry, the cluster is logged in to it again
+	for _, c := range cc.RegistryCredentials {
+		login := "minikube registry login " + c.Registry
+		if c.Helper != "" {
+			login += " --helper=" + c.Helper
+		}
+		if len(c.Namespaces) > 0 {
+			login += " --namespaces=" + strings.Join(c.Namespaces, ",")
+		}
+		warnings = append(warnings, fmt.Sprintf("The credentials of the registry %s are not copied, log in to it again with '%s'", c.Registry, login))
+	}
+	cc.RegistryCredentials = nil
+	return warnings
+}
+
+// cloneConfig returns the config of the cluster dst cloned from src with its primary control plane only, the workers of src
+// added to the clone afterwards, and warnings about the settings of src which are not cloned
+func cloneConfig(src config.ClusterConfig, dst string) (config.ClusterConfig, []config.Node, []string, error) {
+	var cc config.ClusterConfig
+	var warnings []string
+	if driver.BareMetal(src.Driver) || src.Driver == driver.SSH {
+		return cc, nil, nil, fmt.Errorf("the machine of the %s driver can not be duplicated", src.Driver)
+	}
+	if config.IsHA(src) {
+		return cc, nil, nil, fmt.Errorf("highly available clusters can not be cloned, their virtual IP is only chosen when they are created")
+	}
+	cc, err := copyConfig(src)
+	if err != nil {
+		return cc, nil, nil, err
+	}
+	warnings = append(warnings, redactCredentials(&cc)...)
+
+	cc.Name = dst
+	cc.KubernetesConfig.ClusterName = dst
+	cc.KubernetesConfig.NodeIP = ""
+	cc.KubernetesConfig.APIServerHAVIP = ""
+	cc.UUID = ""
+	cc.ScheduledStop = nil
+	cc.ScheduledStart = ""
+	cc.SSHAuthSock = ""
+	cc.SSHAgentPID = 0
+	if cc.RegistryPort != 0 {
+		port, err := idle.FreePort()
+		if err != nil {
+			return cc, nil, nil, errors.Wrap(err, "registry port")
+		}
+		cc.RegistryPort = port
+	}
+	for i := range cc.PortForwards {
+		f := &cc.PortForwards[i]
+		port, err := idle.FreePort()
+		if err != nil {
+			return cc, nil, nil, errors.Wrap(err, "port forward")
+		}
+		warnings = append(warnings, fmt.Sprintf("The host port %d forwarded by %s is %d in the clone", f.HostPort, src.Name, port))
+		f.HostPort = port
+	}
+	// the hooks run any command on the host, they are only run once the user sets them again
+	for _, h := range []struct {
+		flag  string
+		hooks []config.Hook
+	}{{preStartHook, cc.PreStartHooks}, {postStartHook, cc.PostStartHooks}} {
+		for _, hook := range h.hooks {
+			warnings = append(warnings, fmt.Sprintf("The hook %q of %s is not cloned, review it and set it again with --%s", hooks.String(hook), src.Name, h.flag))
+		}
+	}
+	cc.PreStartHooks = nil
+	cc.PostStartHooks = nil
+	// the host directories may not exist on this host, or would be shared with src
+	for _, m := range cc.PersistentMounts {
+		warnings = append(warnings, fmt.Sprintf("The persistent mount %s of %s is not cloned, set it again with --%s", cluster.MountSpec(m), src.Name, persistentMount))
+	}
+	cc.PersistentMounts = nil
+	if cc.StaticIP != "" {
+		warnings = append(warnings, fmt.Sprintf("The static IP %s of %s is not cloned", cc.StaticIP, src.Name))
+		cc.StaticIP = ""
+	}
+	if cc.Subnet != "" {
+		warnings = append(warnings, fmt.Sprintf("The subnet %s of %s is not cloned", cc.Subnet, src.Name))
+		cc.Subnet = ""
+	}
+	if len(cc.ExposedPorts) > 0 {
+		warnings = append(warnings, fmt.Sprintf("The ports %v exposed by %s are not cloned", cc.ExposedPorts, src.Name))
+		cc.ExposedPorts = nil
+	}
+	if cc.LoadBalancerPool != "" && cc.LoadBalancerPool != "auto" {
+		warnings = append(warnings, fmt.Sprintf("The load balancer IP range %s of %s is not cloned, the clone picks its own", cc.LoadBalancerPool, src.Name))
+		cc.LoadBalancerPool = "auto"
+	}
+
+	var primary *config.Node
+	var workers []config.Node
+	for i := range cc.Nodes {
+		n := cc.Nodes[i]
+		n.IP = ""
+		switch {
+		case primary == nil && n.ControlPlane:
+			primary = &n
+		case config.IsWindows(n):
+			warnings = append(warnings, fmt.Sprintf("The Windows node %s of %s is not cloned", n.Name, src.Name))
+		default:
+			workers = append(workers, n)
+		}
+	}
+	if primary == nil {
+		return cc, nil, nil, fmt.Errorf("the cluster %s has no control plane", src.Name)
+	}
+	cc.Nodes = []config.Node{*primary}
+	return cc, workers, warnings, nil
+}
+
+// startCloned creates the cluster of cc returned by cloneConfig, adds its workers, and returns its config
+// with the names of the nodes it was cloned from, in the order of its nodes
+func startCloned(cmd *cobra.Command, cc config.ClusterConfig, workers []config.Node) (*config.ClusterConfig, []string) {
+	if err := config.SaveProfile(cc.Name, &cc); err != nil {
+		exit.Error(reason.HostSaveProfile, "failed to save config", err)
+	}
+	viper.Set(config.ProfileName, cc.Name)
+	runStart(startCmd, nil)
+
+	started, err := config.Load(cc.Name)
+	if err != nil {
+		exit.Error(reason.HostConfigLoad, "Error getting cluster config", err)
+	}
+	srcNodes := []string{cc.Nodes[0].Name}
+	for _, n := range workers {
+		srcNodes = append(srcNodes, n.Name)
+		cp, worker = false, true
+		poolName = n.Pool
+		nodeKubeletConfig = n.KubeletConfig
+		nodeDriver = n.Driver
+		addNode(cmd, started)
+	}
+	return started, srcNodes
+}
+
+// exportCloneImages exports the images of the running nodes of cc into dir, and returns the images of each node
+func exportCloneImages(cc *config.ClusterConfig, dir string) map[string][]string {
+	api, err := machine.NewAPIClient()
+	if err != nil {
+		exit.Error(reason.NewAPIClient, "Failed to get API client", err)
+	}
+	defer api.Close()
+
+	imgs := map[string][]string{}
+	for _, n := range cc.Nodes {
+		machineName := config.MachineName(*cc, n)
+		if config.IsWindows(n) {
+			continue
+		}
+		if st, err := machine.Status(api, machineName); err != nil || st != state.Running.String() {
+			out.WarningT("{{.name}} is not running, its images are not loaded into the clone", out.V{"name": machineName})
+			continue
+		}
+		h, err := machine.GetHost(api, *cc, n)
+		if err != nil {
+			exit.Error(reason.GuestLoadHost, "Error getting host", err)
+		}
+		r, err := machine.CommandRunner(h)
+		if err != nil {
+			exit.Error(reason.InternalCommandRunner, "Failed to get command runner", err)
+		}
+		out.Step(style.Waiting, "Exporting the images of {{.name}} ...", out.V{"name": machineName})
+		names, err := machine.ExportImages(cc, r, dir)
+		if err != nil {
+			out.WarningT("Unable to export the images of {{.name}}: {{.error}}", out.V{"name": machineName, "error": err})
+			continue
+		}
+		imgs[n.Name] = names
+	}
+	return imgs
+}
+
+// importCloneImages loads the images exported from the nodes srcNodes of src into the nodes of cc cloned from them
+func importCloneImages(cc *config.ClusterConfig, src string, srcNodes []string, imgs map[string][]string, dir string) {
+	api, err := machine.NewAPIClient()
+	if err != nil {
+		exit.Error(reason.NewAPIClient, "Failed to get API client", err)
+	}
+	defer api.Close()
+
+	for i, n := range cc.Nodes {
+		if i >= len(srcNodes) {
+			break
+		}
+		names := imgs[srcNodes[i]]
+		if len(names) == 0 {
+			continue
+		}
+		machineName := config.MachineName(*cc, n)
+		h, err := machine.GetHost(api, *cc, n)
+		if err != nil {
+			exit.Error(reason.GuestLoadHost, "Error getting host", err)
+		}
+		r, err := machine.CommandRunner(h)
+		if err != nil {
+			exit.Error(reason.InternalCommandRunner, "Failed to get command runner", err)
+		}
+		out.Step(style.Waiting, "Loading the images of {{.src}} into {{.name}} ...", out.V{"src": src, "name": machineName})
+		if err := machine.ImportImages(cc, r, names, dir); err != nil {
+			out.WarningT("Unable to load the images into {{.name}}: {{.error}}", out.V{"name": machineName, "error": err})
+		}
+	}
+}
+
+func init() {
+	cloneCmd.Flags().BoolVar(&cloneImages, "images", true, "If true, the images of the nodes of the source cluster are loaded into the nodes of the clone")
+}
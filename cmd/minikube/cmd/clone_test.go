@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestCloneConfig(t *testing.T) {
+	src := config.ClusterConfig{
+		Name:                "dev",
+		Driver:              "docker",
+		StaticIP:            "192.168.200.200",
+		ExposedPorts:        []string{"8080:80"},
+		PreStartHooks:       []config.Hook{{Command: "curl https://example.com/setup | sh"}},
+		PersistentMounts:    []config.Mount{{Source: "/home/me/src", Target: "/src", Type: "9p"}},
+		PortForwards:        []config.PortForward{{Namespace: "default", Service: "web", HostPort: 8080, Port: 80}},
+		Addons:              map[string]bool{"ingress": true},
+		RegistryCredentials: []config.RegistryCredential{{Registry: "ghcr.io", Username: "me", Password: "token", Namespaces: []string{"dev"}}},
+		RegistryMirrors: []config.RegistryMirror{
+			{Registry: "docker.io", URL: "https://mirror.gcr.io"},
+			{Registry: "docker.io", URL: "https://r.example.com", Username: "me", Password: "secret"},
+		},
+		KubernetesConfig: config.KubernetesConfig{
+			ClusterName: "dev",
+			NodeIP:      "192.168.200.200",
+		},
+		Nodes: []config.Node{
+			{Name: "", IP: "192.168.200.200", ControlPlane: true, Worker: true},
+			{Name: "m02", IP: "192.168.200.3", Worker: true, Pool: "gpu"},
+			{Name: "m03", IP: "192.168.200.4", Worker: true, OS: config.WindowsOS},
+		},
+	}
+
+	cc, workers, warnings, err := cloneConfig(src, "dev-copy")
+	if err != nil {
+		t.Fatalf("cloneConfig: %v", err)
+	}
+	if cc.Name != "dev-copy" || cc.KubernetesConfig.ClusterName != "dev-copy" || cc.KubernetesConfig.NodeIP != "" || cc.StaticIP != "" || cc.ExposedPorts != nil {
+		t.Errorf("cloneConfig() = %+v", cc)
+	}
+	if len(cc.Nodes) != 1 || cc.Nodes[0].IP != "" || !cc.Nodes[0].ControlPlane {
+		t.Errorf("cloneConfig() nodes = %+v, expected the primary control plane without IP", cc.Nodes)
+	}
+	if len(workers) != 1 || workers[0].Name != "m02" || workers[0].Pool != "gpu" {
+		t.Errorf("cloneConfig() workers = %+v, expected m02", workers)
+	}
+	if len(cc.PortForwards) != 1 || cc.PortForwards[0].HostPort == 8080 || cc.PortForwards[0].Port != 80 || src.PortForwards[0].HostPort != 8080 {
+		t.Errorf("cloneConfig() port forwards = %+v, expected another host port", cc.PortForwards)
+	}
+	if cc.PreStartHooks != nil || len(src.PreStartHooks) != 1 {
+		t.Errorf("cloneConfig() pre start hooks = %v, expected none", cc.PreStartHooks)
+	}
+	if cc.PersistentMounts != nil || len(src.PersistentMounts) != 1 {
+		t.Errorf("cloneConfig() persistent mounts = %v, expected none", cc.PersistentMounts)
+	}
+	if cc.RegistryMirrors[1].Username != "" || cc.RegistryMirrors[1].Password != "" || src.RegistryMirrors[1].Password != "secret" {
+		t.Errorf("cloneConfig() mirrors = %+v, expected no credentials", cc.RegistryMirrors)
+	}
+	if cc.RegistryCredentials != nil || len(src.RegistryCredentials) != 1 {
+		t.Errorf("cloneConfig() registry credentials = %+v, expected none", cc.RegistryCredentials)
+	}
+	// the credentials of the mirror and of the registry, the hook, the persistent mount, the port forward, the static IP, the exposed ports and the Windows node
+	if len(warnings) != 8 {
+		t.Errorf("cloneConfig() warnings = %v", warnings)
+	}
+	if !cc.Addons["ingress"] {
+		t.Errorf("cloneConfig() addons = %v", cc.Addons)
+	}
+	cc.Addons["ingress"] = false
+	if src.Name != "dev" || src.Nodes[1].IP == "" || !src.Addons["ingress"] {
+		t.Errorf("cloneConfig() changed the source config: %+v", src)
+	}
+
+	src.Driver = "none"
+	if _, _, _, err := cloneConfig(src, "dev-copy"); err == nil {
+		t.Errorf("cloneConfig() of a none cluster succeeded, expected an error")
+	}
+}
@@ -123,6 +123,15 @@ var settings = []Setting{
 		name: config.ReminderWaitPeriodInHours,
 		set:  SetInt,
 	},
+	{
+		name: config.WantReleaseNotes,
+		set:  SetBool,
+	},
+	{
+		name:        config.ReleaseFeedURL,
+		set:         SetString,
+		validations: []setFn{IsValidURL},
+	},
 	{
 		name: config.WantNoneDriverWarning,
 		set:  SetBool,
@@ -165,8 +174,24 @@ var settings = []Setting{
 		set:  SetBool,
 	},
 	{
-		name: config.Rootless,
-		set:  SetBool,
+		name:        config.Rootless,
+		set:         SetString,
+		validations: []setFn{IsValidRootless},
+	},
+	{
+		name:        config.DownloadParallelism,
+		set:         SetInt,
+		validations: []setFn{IsPositive},
+	},
+	{
+		name:        config.DownloadRateLimit,
+		set:         SetString,
+		validations: []setFn{IsValidRate},
+	},
+	{
+		name:        config.CacheQuota,
+		set:         SetString,
+		validations: []setFn{IsValidDiskSize},
 	},
 	{
 		name: config.MaxAuditEntries,
@@ -19,6 +19,7 @@ package config
 import (
 	"net"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"time"
@@ -257,6 +258,57 @@ var addonsConfigureCmd = &cobra.Command{
 					out.ErrT(style.Fatal, "Failed to configure registry-aliases {{.profile}}", out.V{"profile": profile})
 				}
 			}
+		case "gatekeeper":
+			profile := ClusterFlagValue()
+			_, cfg := mustload.Partial(profile)
+
+			validator := func(s string) bool {
+				fi, err := os.Stat(s)
+				return err == nil && fi.IsDir()
+			}
+			dir := AskForStaticValidatedValue("-- Enter the directory of the ConstraintTemplates and Constraints to sync: ", validator)
+			abs, err := filepath.Abs(dir)
+			if err != nil {
+				exit.Error(reason.HostPathMissing, "Failed to get the absolute path of the policy directory", err)
+			}
+			cfg.KubernetesConfig.GatekeeperPolicyDir = abs
+
+			if err := config.SaveProfile(profile, cfg); err != nil {
+				out.ErrT(style.Fatal, "Failed to save config {{.profile}}", out.V{"profile": profile})
+			}
+			if assets.Addons["gatekeeper"].IsEnabled(cfg) {
+				if err := addons.MountGatekeeperPolicies(cfg, "gatekeeper", "true"); err != nil {
+					out.ErrT(style.Fatal, "Failed to mount the policy directory: {{.error}}", out.V{"error": err})
+				}
+			}
+		case "pull-policy":
+			profile := ClusterFlagValue()
+			_, cfg := mustload.Partial(profile)
+
+			policyValidator := func(s string) bool {
+				return s == "Always" || s == "IfNotPresent" || s == "Never"
+			}
+			nsFormat := regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+			nsValidator := func(s string) bool {
+				namespaces := addons.PullPolicyNamespaces(s)
+				for _, ns := range namespaces {
+					if !nsFormat.MatchString(ns) {
+						return false
+					}
+				}
+				return len(namespaces) > 0
+			}
+			cfg.KubernetesConfig.PullPolicy = AskForStaticValidatedValue("-- Enter the imagePullPolicy to force (Always, IfNotPresent or Never): ", policyValidator)
+			cfg.KubernetesConfig.PullPolicyNamespaces = AskForStaticValidatedValue("-- Enter the namespaces separated by commas: ", nsValidator)
+
+			if err := config.SaveProfile(profile, cfg); err != nil {
+				out.ErrT(style.Fatal, "Failed to save config {{.profile}}", out.V{"profile": profile})
+			}
+			if assets.Addons["pull-policy"].IsEnabled(cfg) {
+				if err := addons.ConfigurePullPolicyWebhook(cfg, "pull-policy", "true"); err != nil {
+					out.ErrT(style.Fatal, "Failed to configure the pull-policy webhook: {{.error}}", out.V{"error": err})
+				}
+			}
 		case "auto-pause-interval":
 			profile := ClusterFlagValue()
 			_, cfg := mustload.Partial(profile)
@@ -58,6 +58,9 @@ var addonsEnableCmd = &cobra.Command{
 			out.Styled(style.Waiting, msg)
 			addon = replacement
 		}
+		if addon == "gvisor" {
+			out.Styled(style.Tip, "The gvisor addon is superseded by 'minikube start --container-runtime-sandbox=gvisor', which supports containerd and cri-o, survives restarts, and can make gVisor the default of namespaces with --sandbox-namespaces")
+		}
 		addonBundle, ok := assets.Addons[addon]
 		if ok {
 			maintainer := addonBundle.Maintainer
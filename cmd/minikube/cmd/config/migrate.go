@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"sort"
+
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var migrateDryRun bool
+
+var configMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrates the configs written by older minikube versions",
+	Long: `Translates the deprecated settings of the minikube config file, and the configs of the profiles written by older minikube versions, to the current schema.
+The configs of the profiles are also migrated when they are loaded, and written by the next save of the profile.`,
+	Example: `minikube config migrate --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		m, err := config.ReadConfig(localpath.ConfigFile())
+		if err != nil {
+			exit.Error(reason.InternalConfigMigrate, "Unable to read the minikube config", err)
+		}
+		migrated := map[string][]string{}
+		if applied := config.MigrateSettings(m); len(applied) > 0 {
+			migrated[localpath.ConfigFile()] = applied
+			if !migrateDryRun {
+				if err := config.WriteConfig(localpath.ConfigFile(), m); err != nil {
+					exit.Error(reason.InternalConfigMigrate, "Unable to write the minikube config", err)
+				}
+			}
+		}
+
+		profiles, err := config.MigrateProfiles(migrateDryRun)
+		for name, applied := range profiles {
+			migrated[name] = applied
+		}
+		printMigrations(migrated)
+		if err != nil {
+			exit.Error(reason.InternalConfigMigrate, "Unable to migrate the profiles", err)
+		}
+	},
+}
+
+// printMigrations prints the migrations applied, or to apply on a dry run, by config
+func printMigrations(migrated map[string][]string) {
+	if len(migrated) == 0 {
+		out.Step(style.Check, "The configs are up to date")
+		return
+	}
+	var names []string
+	for name := range migrated {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if migrateDryRun {
+			out.Step(style.Notice, "Would migrate {{.config}}:", out.V{"config": name})
+		} else {
+			out.Step(style.Check, "Migrated {{.config}}:", out.V{"config": name})
+		}
+		for _, m := range migrated[name] {
+			out.Infof("{{.migration}}", out.V{"migration": m})
+		}
+	}
+}
+
+func init() {
+	configMigrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "If set, only print the migrations, without writing the configs")
+	ConfigCmd.AddCommand(configMigrateCmd)
+}
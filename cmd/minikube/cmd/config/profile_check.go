@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/kubeconfig"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/util"
+)
+
+var profileRepair bool
+
+var profileCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the profiles, certificates and kubeconfig for files corrupted by a crash",
+	Long: `Check the configs of all profiles, the minikube certificates and the kubeconfig for files corrupted by a crash or a power loss.
+
+With --repair, corrupted or missing profile configs are reconstructed from the state of their machine, and a corrupted kubeconfig is moved aside so that it can be regenerated.`,
+	Example: "minikube profile check --repair",
+	Run: func(cmd *cobra.Command, args []string) {
+		broken := 0
+		for _, name := range checkedProfiles() {
+			if _, err := config.Load(name); err != nil {
+				if !profileRepair {
+					out.FailureT("The config of profile {{.profile}} is broken: {{.error}}", out.V{"profile": name, "error": err})
+					broken++
+					continue
+				}
+				if err := reconstructProfile(name); err != nil {
+					out.FailureT("Unable to reconstruct the config of profile {{.profile}}: {{.error}}", out.V{"profile": name, "error": err})
+					broken++
+					continue
+				}
+				out.Step(style.Celebrate, "Reconstructed the config of profile {{.profile}} from its machine", out.V{"profile": name})
+			}
+
+			dir := config.ProfileFolderPath(name)
+			if err := checkKeyPair(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")); err != nil {
+				out.FailureT("The client certificate of profile {{.profile}} is broken: {{.error}}", out.V{"profile": name, "error": err})
+				broken++
+			}
+			if err := checkKeyPair(filepath.Join(dir, "apiserver.crt"), filepath.Join(dir, "apiserver.key")); err != nil {
+				out.FailureT("The API server certificate of profile {{.profile}} is broken: {{.error}}", out.V{"profile": name, "error": err})
+				broken++
+			}
+		}
+
+		if err := checkKeyPair(localpath.CACert(), localpath.MakeMiniPath("ca.key")); err != nil {
+			out.FailureT("The minikube CA is broken: {{.error}}", out.V{"error": err})
+			broken++
+		}
+
+		path := kubeconfig.PathFromEnv()
+		if _, err := clientcmd.LoadFromFile(path); err != nil && !os.IsNotExist(err) {
+			if !profileRepair {
+				out.FailureT("The kubeconfig {{.path}} is broken: {{.error}}", out.V{"path": path, "error": err})
+				broken++
+			} else if err := os.Rename(path, path+".corrupted"); err != nil {
+				out.FailureT("Unable to move the kubeconfig {{.path}} aside: {{.error}}", out.V{"path": path, "error": err})
+				broken++
+			} else {
+				out.Step(style.Celebrate, "Moved the broken kubeconfig to {{.path}}, run 'minikube update-context -p <profile>' for each profile to regenerate it", out.V{"path": path + ".corrupted"})
+			}
+		}
+
+		if broken == 0 {
+			out.Step(style.Check, "No broken files found")
+			return
+		}
+		if !profileRepair {
+			exit.Message(reason.HostProfileCheck, "Found {{.count}} broken files, run 'minikube profile check --repair' to repair them", out.V{"count": broken})
+		}
+		exit.Message(reason.HostProfileCheck, "{{.count}} broken files could not be repaired, run 'minikube start' to regenerate the broken certificates", out.V{"count": broken})
+	},
+}
+
+// checkedProfiles returns the names of the profiles, and of the machines which have lost their profile
+func checkedProfiles() []string {
+	valid, invalid, err := config.ListProfiles()
+	if err != nil {
+		klog.Warningf("unable to list profiles: %v", err)
+	}
+	seen := map[string]bool{}
+	names := []string{}
+	for _, p := range append(valid, invalid...) {
+		if !seen[p.Name] {
+			seen[p.Name] = true
+			names = append(names, p.Name)
+		}
+		if p.Config == nil {
+			continue
+		}
+		for _, n := range p.Config.Nodes {
+			seen[config.MachineName(*p.Config, n)] = true
+		}
+	}
+
+	machines, err := os.ReadDir(localpath.MakeMiniPath("machines"))
+	if err != nil {
+		return names
+	}
+	for _, m := range machines {
+		if m.IsDir() && !seen[m.Name()] {
+			names = append(names, m.Name())
+		}
+	}
+	return names
+}
+
+// reconstructProfile rebuilds the config of a profile from the state of its machine
+func reconstructProfile(name string) error {
+	api, err := machine.NewAPIClient()
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+	cc, err := machine.ReconstructConfig(api, name)
+	if err != nil {
+		return err
+	}
+	return config.SaveProfile(name, cc)
+}
+
+// checkKeyPair checks that a certificate and its key can be parsed and match, if the certificate exists
+func checkKeyPair(certPath, keyPath string) error {
+	certPEM, err := os.ReadFile(certPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := util.ParseCertificate(certPEM); err != nil {
+		return err
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return err
+	}
+	_, err = tls.X509KeyPair(certPEM, keyPEM)
+	return err
+}
+
+func init() {
+	profileCheckCmd.Flags().BoolVar(&profileRepair, "repair", false, "Reconstruct broken profile configs from the state of their machine")
+	ProfileCmd.AddCommand(profileCheckCmd)
+}
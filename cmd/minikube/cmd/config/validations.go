@@ -29,6 +29,8 @@ import (
 	"k8s.io/minikube/pkg/minikube/cruntime"
 	"k8s.io/minikube/pkg/minikube/driver"
 	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/rootless"
+	"k8s.io/minikube/pkg/minikube/transfer"
 )
 
 // IsValidDriver checks if a driver is supported
@@ -147,6 +149,22 @@ func IsValidPath(name, path string) error {
 	return nil
 }
 
+// IsValidRootless checks if a string is a valid mode of rootless
+func IsValidRootless(name, mode string) error {
+	if !rootless.Valid(mode) {
+		return fmt.Errorf("%s must be true, false or %s", name, rootless.Strict)
+	}
+	return nil
+}
+
+// IsValidRate checks if a string is a valid bandwidth, such as 10MB
+func IsValidRate(name, rate string) error {
+	if _, err := transfer.ParseRate(rate); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	return nil
+}
+
 // IsValidRuntime checks if a string is a valid runtime
 func IsValidRuntime(_, runtime string) error {
 	_, err := cruntime.New(cruntime.Config{Type: runtime})
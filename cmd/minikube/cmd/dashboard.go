@@ -136,6 +136,9 @@ func kubectlProxy(kubectlVersion string, binaryURL string, contextName string, p
 	// port=0 picks a random system port
 
 	kubectlArgs := []string{"--context", contextName, "proxy", "--port", strconv.Itoa(port)}
+	if server := backgroundServer(contextName); server != "" {
+		kubectlArgs = append(kubectlArgs, "--server", server)
+	}
 
 	var cmd *exec.Cmd
 	if kubectl, err := exec.LookPath("kubectl"); err == nil {
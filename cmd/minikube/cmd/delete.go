@@ -45,12 +45,15 @@ import (
 	"k8s.io/minikube/pkg/minikube/download"
 	"k8s.io/minikube/pkg/minikube/driver"
 	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/hostdns"
 	"k8s.io/minikube/pkg/minikube/kubeconfig"
 	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/node"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/out/register"
 	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/schedule"
 	"k8s.io/minikube/pkg/minikube/sshagent"
 	"k8s.io/minikube/pkg/minikube/style"
 )
@@ -91,9 +94,19 @@ func (error DeletionError) Error() string {
 }
 
 var hostAndDirsDeleter = func(api libmachine.API, cc *config.ClusterConfig, profileName string) error {
+	killPersistentMountProcesses(profileName)
 	if err := killMountProcess(); err != nil {
 		out.FailureT("Failed to kill mount process: {{.error}}", out.V{"error": err})
 	}
+	if err := killPortForwardProcess(profileName); err != nil {
+		out.FailureT("Failed to kill port-forward process: {{.error}}", out.V{"error": err})
+	}
+	if err := killIdleProxyProcess(profileName); err != nil {
+		out.FailureT("Failed to kill idle-proxy process: {{.error}}", out.V{"error": err})
+	}
+	if err := schedule.RemoveStart(profileName); err != nil {
+		out.FailureT("Failed to remove the scheduled start: {{.error}}", out.V{"error": err})
+	}
 	if err := sshagent.Stop(profileName); err != nil {
 		out.FailureT("Failed to stop ssh-agent process: {{.error}}", out.V{"error": err})
 	}
@@ -233,8 +246,11 @@ func runDelete(_ *cobra.Command, args []string) {
 	defer cancel()
 
 	if deleteAll {
-		deleteContainersAndVolumes(delCtx, oci.Docker)
-		deleteContainersAndVolumes(delCtx, oci.Podman)
+		// in a system-wide install, the containers of other users must be kept
+		if !localpath.SystemWide() {
+			deleteContainersAndVolumes(delCtx, oci.Docker)
+			deleteContainersAndVolumes(delCtx, oci.Podman)
+		}
 
 		errs := DeleteProfiles(profilesToDelete)
 		register.Reg.SetStep(register.Done)
@@ -333,15 +349,38 @@ func deleteProfile(ctx context.Context, profile *config.Profile) error {
 	if profile.Config != nil {
 		klog.Infof("%s configuration: %+v", profile.Name, profile.Config)
 
+		if config.IsHybrid(*profile.Config) {
+			node.UnrouteHybridNetworks(*profile.Config)
+		}
+
+		if profile.Config.Addons["ingress-dns"] && hostdns.Reachable(profile.Config.Driver) {
+			if cp, err := config.PrimaryControlPlane(profile.Config); err == nil {
+				if err := hostdns.Remove(profile.Name, cp.IP); err != nil {
+					klog.Warningf("failed to remove the DNS configuration of the host for ingress-dns: %v", err)
+				}
+			}
+		}
+
 		// if driver is oci driver, delete containers and volumes
 		if driver.IsKIC(profile.Config.Driver) {
 			if err := unpauseIfNeeded(profile); err != nil {
 				klog.Warningf("failed to unpause %s : %v", profile.Name, err)
 			}
 			out.Styled(style.DeletingHost, `Deleting "{{.profile_name}}" in {{.driver_name}} ...`, out.V{"profile_name": profile.Name, "driver_name": profile.Config.Driver})
-			for _, n := range profile.Config.Nodes {
+		}
+		for _, n := range profile.Config.Nodes {
+			// the nodes of a hybrid cluster may run on a kic driver when the cluster does not
+			if drv := config.NodeDriver(*profile.Config, n); driver.IsKIC(drv) {
 				machineName := config.MachineName(*profile.Config, n)
-				delete.PossibleLeftOvers(ctx, machineName, profile.Config.Driver)
+				delete.PossibleLeftOvers(ctx, machineName, drv)
+				if err := oci.StopTunnels(localpath.MachinePath(machineName)); err != nil {
+					klog.Warningf("failed to stop tunnels to the remote daemon host: %v", err)
+				}
+			}
+		}
+		if driver.IsKIC(profile.Config.Driver) {
+			if err := oci.StopTunnels(localpath.Profile(profile.Name)); err != nil {
+				klog.Warningf("failed to stop tunnels to the remote daemon host: %v", err)
 			}
 		}
 	} else {
@@ -373,6 +412,8 @@ func deleteProfile(ctx context.Context, profile *config.Profile) error {
 		}
 	}
 
+	cleanupHostRoutes(profile.Name)
+
 	if err := hostAndDirsDeleter(api, cc, profile.Name); err != nil {
 		return err
 	}
@@ -430,6 +471,13 @@ func deleteHosts(api libmachine.API, cc *config.ClusterConfig) {
 	if cc != nil {
 		for _, n := range cc.Nodes {
 			machineName := config.MachineName(*cc, n)
+			if config.IsWindows(n) {
+				if err := node.DeleteWindows(*cc, n); err != nil {
+					out.FailureT("Failed to delete cluster: {{.error}}", out.V{"error": err})
+					out.Styled(style.Notice, `You may need to manually remove the "{{.name}}" VM from your hypervisor`, out.V{"name": machineName})
+				}
+				continue
+			}
 			if err := machine.DeleteHost(api, machineName); err != nil {
 				switch errors.Cause(err).(type) {
 				case mcnerror.ErrHostDoesNotExist:
@@ -598,7 +646,7 @@ func killMountProcess() error {
 	}
 
 	for _, path := range paths {
-		if err := killProcess(path); err != nil {
+		if err := killProcess(path, constants.MountProcessFileName); err != nil {
 			return err
 		}
 	}
@@ -606,12 +654,47 @@ func killMountProcess() error {
 	return nil
 }
 
+// killPortForwardProcess kills the port-forward process of the profile, if any
+func killPortForwardProcess(profile string) error {
+	return killProcess(localpath.Profile(profile), constants.PortForwardProcessFileName)
+}
+
+// killPersistentMountProcesses kills the mount processes of the persistent mounts of the profile, if any.
+// They are also listed in the mount pidfile, from which they are removed so that killMountProcess does not find them stale.
+func killPersistentMountProcesses(profile string) {
+	pidPath := filepath.Join(localpath.Profile(profile), constants.PersistentMountProcessFileName)
+	if _, err := os.Stat(pidPath); os.IsNotExist(err) {
+		return
+	}
+	pids, err := getPids(pidPath)
+	if err != nil {
+		klog.Warningf("reading %s: %v", pidPath, err)
+		return
+	}
+	for _, pid := range pids {
+		if err := trySigKillProcess(pid); err != nil {
+			klog.Infof("skipping mount process %d: %v", pid, err)
+		}
+		if err := removePid(localpath.Profile(profile), strconv.Itoa(pid)); err != nil {
+			klog.Warningf("removing mount pid %d: %v", pid, err)
+		}
+	}
+	if err := os.Remove(pidPath); err != nil {
+		klog.Warningf("removing %s: %v", pidPath, err)
+	}
+}
+
+// killIdleProxyProcess kills the idle-proxy process of the profile, if any
+func killIdleProxyProcess(profile string) error {
+	return killProcess(localpath.Profile(profile), constants.IdleProxyProcessFileName)
+}
+
 // killProcess takes a path to look for a pidfile (space-separated),
 // it reads the file and converts it to a bunch of pid ints,
 // then it tries to kill each one of them.
 // If no errors were encountered, it cleans the pidfile
-func killProcess(path string) error {
-	pidPath := filepath.Join(path, constants.MountProcessFileName)
+func killProcess(path, pidFile string) error {
+	pidPath := filepath.Join(path, pidFile)
 	if _, err := os.Stat(pidPath); os.IsNotExist(err) {
 		return nil
 	}
@@ -642,12 +725,12 @@ func killProcess(path string) error {
 		for _, e := range errs {
 			out.Err("%v\n", e)
 		}
-		return errors.New("multiple errors encountered while closing mount processes")
+		return errors.New("multiple errors encountered while closing processes")
 	}
 
 	// if no errors were encoutered, it's safe to delete pidFile
 	if err := os.Remove(pidPath); err != nil {
-		return errors.Wrap(err, "while closing mount-pids file")
+		return errors.Wrap(err, "while closing pids file")
 	}
 
 	return nil
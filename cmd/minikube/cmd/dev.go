@@ -0,0 +1,331 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"debug/elf"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/images"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/download"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/node"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/sysinit"
+	"k8s.io/minikube/pkg/util"
+)
+
+var (
+	devKubeletFrom    string
+	devKubeletRestore bool
+	devNodeE2EImages  []string
+)
+
+// nodeE2EImages are the images of the node e2e tests pulled by `minikube dev node-e2e`, the pause image of the cluster is added to them
+var nodeE2EImages = []string{
+	"registry.k8s.io/e2e-test-images/agnhost:2.47",
+	"registry.k8s.io/e2e-test-images/busybox:1.36.1-1",
+	"registry.k8s.io/e2e-test-images/httpd:2.4.38-4",
+	"registry.k8s.io/e2e-test-images/nginx:1.14-4",
+	"registry.k8s.io/e2e-test-images/nonewprivs:1.3",
+	"registry.k8s.io/e2e-test-images/nonroot:1.4",
+	"registry.k8s.io/e2e-test-images/perl:5.26",
+	"registry.k8s.io/node-problem-detector/node-problem-detector:v0.8.17",
+}
+
+// elfMachines are the ELF machines of the architectures of the nodes
+var elfMachines = map[string]elf.Machine{
+	"amd64":   elf.EM_X86_64,
+	"arm64":   elf.EM_AARCH64,
+	"ppc64le": elf.EM_PPC64,
+	"s390x":   elf.EM_S390,
+}
+
+// testHandler is the RuntimeClass handler the node e2e tests expect the container runtime to have
+const testHandler = "test-handler"
+
+const containerdTestHandler = `
+[plugins."io.containerd.grpc.v1.cri".containerd.runtimes.test-handler]
+  runtime_type = "io.containerd.runc.v2"
+`
+
+const crioTestHandlerPath = "/etc/crio/crio.conf.d/10-test-handler.conf"
+
+// devCmd represents the dev command
+var devCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Commands for Kubernetes contributors iterating on the node components",
+	Long:  "Commands for Kubernetes contributors iterating on the node components against minikube nodes, instead of cloud VMs",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.Message(reason.Usage, "Usage: minikube dev [kubelet|node-e2e]")
+	},
+}
+
+var devKubeletCmd = &cobra.Command{
+	Use:   "kubelet",
+	Short: "Replaces the kubelet of the nodes with a binary built from source",
+	Long: `Replaces the kubelet of the nodes with a binary built from source, and restarts it.
+The kubelet is replaced on all the nodes, or on the node of --node. It stays replaced across restarts of the cluster, until --restore puts back the released kubelet.`,
+	Example: `make WHAT=cmd/kubelet KUBE_BUILD_PLATFORMS=linux/amd64
+minikube dev kubelet --from ./_output/local/bin/linux/amd64/kubelet`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if (devKubeletFrom == "") == !devKubeletRestore {
+			exit.Message(reason.Usage, "Usage: minikube dev kubelet [--from <path> | --restore]")
+		}
+		co := mustload.Running(ClusterFlagValue())
+		version := co.Config.KubernetesConfig.KubernetesVersion
+
+		src := devKubeletFrom
+		if devKubeletRestore {
+			var err error
+			src, err = download.Binary("kubelet", version, "linux", runtime.GOARCH, co.Config.BinaryMirror)
+			if err != nil {
+				exit.Error(reason.InetCacheBinaries, "Failed to download the kubelet", err)
+			}
+		} else if err := checkKubeletBinary(src, runtime.GOARCH); err != nil {
+			exit.Message(reason.Usage, "{{.error}}", out.V{"error": err})
+		}
+
+		nodes := co.Config.Nodes
+		if nodeName != "" {
+			n, _, err := node.Retrieve(*co.Config, nodeName)
+			if err != nil {
+				exit.Message(reason.GuestNodeRetrieve, "Node {{.nodeName}} does not exist.", out.V{"nodeName": nodeName})
+			}
+			nodes = []config.Node{*n}
+		}
+		for _, n := range nodes {
+			name := config.MachineName(*co.Config, n)
+			out.Step(style.Waiting, "Replacing the kubelet of {{.name}} ...", out.V{"name": name})
+			v, err := replaceKubelet(remoteCommandRunner(&co, n.Name), src, bsutil.BinaryPath(version, "kubelet"))
+			if err != nil {
+				exit.Error(reason.GuestKubeletReplace, "Failed to replace the kubelet", err)
+			}
+			out.Step(style.Check, "{{.name}} runs {{.version}}", out.V{"name": name, "version": v})
+		}
+	},
+}
+
+var devNodeE2ECmd = &cobra.Command{
+	Use:   "node-e2e",
+	Short: "Configures a node for running the node e2e tests of Kubernetes against it",
+	Long: `Configures a node for running the node e2e tests of Kubernetes against it over ssh: enables the cgroup controllers the tests use,
+adds the test-handler runtime handler to the container runtime, pulls the images of the tests, and stops the kubelet of the node, since the tests run their own.
+Prints the command running the tests from a Kubernetes checkout. Run 'minikube start' afterwards to start the kubelet of the node again.`,
+	Example: "minikube dev node-e2e --node m02",
+	Run: func(cmd *cobra.Command, args []string) {
+		co := mustload.Running(ClusterFlagValue())
+		n := co.CP.Node
+		if nodeName != "" {
+			var err error
+			n, _, err = node.Retrieve(*co.Config, nodeName)
+			if err != nil {
+				exit.Message(reason.GuestNodeRetrieve, "Node {{.nodeName}} does not exist.", out.V{"nodeName": nodeName})
+			}
+		}
+		if n.ControlPlane {
+			out.WarningT("The kubelet of the control plane node {{.name}} is stopped: the cluster is not managed until the next 'minikube start'", out.V{"name": n.Name})
+		}
+		h, err := machine.GetHost(co.API, *co.Config, *n)
+		if err != nil {
+			exit.Error(reason.GuestLoadHost, "Error getting host", err)
+		}
+		r, err := machine.CommandRunner(h)
+		if err != nil {
+			exit.Error(reason.InternalCommandRunner, "Failed to get command runner", err)
+		}
+		cr, err := cruntime.New(cruntime.Config{Type: co.Config.KubernetesConfig.ContainerRuntime, Runner: r, Socket: co.Config.KubernetesConfig.CRISocket})
+		if err != nil {
+			exit.Error(reason.InternalRuntime, "Failed runtime", err)
+		}
+
+		if err := enableCgroupControllers(r); err != nil {
+			out.WarningT("Unable to enable the cgroup controllers of the node: {{.error}}", out.V{"error": err})
+		}
+		if err := configureTestHandler(r, co.Config.KubernetesConfig.ContainerRuntime); err != nil {
+			exit.Error(reason.GuestNodeE2E, "Failed to add the test-handler runtime handler", err)
+		}
+		pullNodeE2EImages(cr, *co.Config)
+		if err := sysinit.New(r).ForceStop("kubelet"); err != nil {
+			exit.Error(reason.GuestNodeE2E, "Failed to stop the kubelet", err)
+		}
+
+		host, err := h.Driver.GetSSHHostname()
+		if err != nil {
+			exit.Error(reason.IfSSHClient, "Error getting ssh client", err)
+		}
+		port, err := h.Driver.GetSSHPort()
+		if err != nil {
+			exit.Error(reason.IfSSHClient, "Error getting ssh client", err)
+		}
+		cgroupDriver, err := cr.CGroupDriver()
+		if err != nil {
+			klog.Warningf("cgroup driver: %v", err)
+			cgroupDriver = "systemd"
+		}
+		out.Step(style.Ready, "Node {{.name}} is ready for the node e2e tests. From a Kubernetes checkout, run:", out.V{"name": n.Name})
+		out.Ln("make test-e2e-node REMOTE=true REMOTE_MODE=ssh HOSTS=%s SSH_USER=%s SSH_KEY=%s SSH_OPTIONS=\"-p %d\" TEST_ARGS='--kubelet-flags=\"--cgroup-driver=%s\" --container-runtime-endpoint=unix://%s'",
+			host, h.Driver.GetSSHUsername(), h.Driver.GetSSHKeyPath(), port, cgroupDriver, cr.SocketPath())
+	},
+}
+
+// checkKubeletBinary checks that the file is a Linux binary for the architecture of the nodes
+func checkKubeletBinary(path, arch string) error {
+	f, err := elf.Open(path)
+	if err != nil {
+		return fmt.Errorf("%s is not a Linux binary, build the kubelet with KUBE_BUILD_PLATFORMS=linux/%s: %v", path, arch, err)
+	}
+	defer f.Close()
+	if m, ok := elfMachines[arch]; ok && f.Machine != m {
+		return fmt.Errorf("%s is a %s binary, the nodes are %s: build the kubelet with KUBE_BUILD_PLATFORMS=linux/%s", path, f.Machine, arch, arch)
+	}
+	return nil
+}
+
+// replaceKubelet stops the kubelet of the node, replaces its binary and starts it again. Returns the version of the new kubelet.
+func replaceKubelet(r command.Runner, src, dst string) (string, error) {
+	sm := sysinit.New(r)
+	// the binary of a running kubelet can not be overwritten
+	if err := sm.ForceStop("kubelet"); err != nil {
+		klog.Warningf("unable to stop kubelet: %v", err)
+	}
+	if err := machine.CopyBinary(r, src, dst); err != nil {
+		return "", errors.Wrapf(err, "copying %s", src)
+	}
+	if err := sm.Restart("kubelet"); err != nil {
+		return "", errors.Wrap(err, "restarting kubelet")
+	}
+	rr, err := r.RunCmd(exec.Command("sudo", dst, "--version"))
+	if err != nil {
+		return "", errors.Wrap(err, "kubelet version")
+	}
+	return strings.TrimSpace(rr.Stdout.String()), nil
+}
+
+// enableCgroupControllers enables the available cgroup v2 controllers for the children of the root cgroup,
+// where the kubelet of the tests creates its cgroups
+func enableCgroupControllers(r command.Runner) error {
+	rr, err := r.RunCmd(exec.Command("stat", "-fc", "%T", "/sys/fs/cgroup"))
+	if err != nil {
+		return errors.Wrap(err, "cgroup version")
+	}
+	if strings.TrimSpace(rr.Stdout.String()) != "cgroup2fs" {
+		klog.Infof("the node uses cgroup v1, all the controllers are enabled")
+		return nil
+	}
+	available, err := r.RunCmd(exec.Command("cat", "/sys/fs/cgroup/cgroup.controllers"))
+	if err != nil {
+		return errors.Wrap(err, "available controllers")
+	}
+	enabled, err := r.RunCmd(exec.Command("cat", "/sys/fs/cgroup/cgroup.subtree_control"))
+	if err != nil {
+		return errors.Wrap(err, "enabled controllers")
+	}
+	var missing []string
+	for _, c := range oci.MissingControllers(strings.Fields(enabled.Stdout.String()), strings.Fields(available.Stdout.String())) {
+		missing = append(missing, "+"+c)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	klog.Infof("enabling cgroup controllers %v", missing)
+	_, err = r.RunCmd(exec.Command("sudo", "sh", "-c", fmt.Sprintf("echo '%s' > /sys/fs/cgroup/cgroup.subtree_control", strings.Join(missing, " "))))
+	return err
+}
+
+// configureTestHandler adds the test-handler runtime handler, running runc, to the container runtime of the node
+func configureTestHandler(r command.Runner, rt string) error {
+	switch rt {
+	case constants.Containerd:
+		rr, err := r.RunCmd(exec.Command("sudo", "cat", "/etc/containerd/config.toml"))
+		if err != nil {
+			return errors.Wrap(err, "reading containerd config")
+		}
+		cfg, changed := withContainerdTestHandler(rr.Stdout.String())
+		if !changed {
+			return nil
+		}
+		if err := r.Copy(assets.NewMemoryAssetTarget([]byte(cfg), "/etc/containerd/config.toml", "0644")); err != nil {
+			return errors.Wrap(err, "writing containerd config")
+		}
+		return sysinit.New(r).Restart("containerd")
+	case constants.CRIO:
+		rr, err := r.RunCmd(exec.Command("sh", "-c", "command -v runc"))
+		if err != nil {
+			return errors.Wrap(err, "finding runc")
+		}
+		cfg := fmt.Sprintf("[crio.runtime.runtimes.%s]\nruntime_path = %q\nruntime_type = \"oci\"\n", testHandler, strings.TrimSpace(rr.Stdout.String()))
+		if err := r.Copy(assets.NewMemoryAssetTarget([]byte(cfg), crioTestHandlerPath, "0644")); err != nil {
+			return errors.Wrap(err, "writing cri-o config")
+		}
+		return sysinit.New(r).Restart("crio")
+	default:
+		out.WarningT("The {{.runtime}} container runtime has no runtime handlers: the RuntimeClass tests using {{.handler}} fail. Use the containerd or cri-o runtime to run them.",
+			out.V{"runtime": rt, "handler": testHandler})
+		return nil
+	}
+}
+
+// withContainerdTestHandler returns the containerd config with the test-handler runtime, and whether it was added
+func withContainerdTestHandler(cfg string) (string, bool) {
+	if strings.Contains(cfg, "runtimes."+testHandler+"]") {
+		return cfg, false
+	}
+	return strings.TrimRight(cfg, "\n") + "\n" + containerdTestHandler, true
+}
+
+// pullNodeE2EImages pulls the images of the node e2e tests, which expect them to be present
+func pullNodeE2EImages(cr cruntime.Manager, cc config.ClusterConfig) {
+	imgs := devNodeE2EImages
+	if v, err := util.ParseKubernetesVersion(cc.KubernetesConfig.KubernetesVersion); err == nil {
+		imgs = append(imgs, images.Pause(v, cc.KubernetesConfig.ImageRepository))
+	}
+	for _, img := range imgs {
+		out.Step(style.Pulling, "Pulling {{.image}} ...", out.V{"image": img})
+		if err := cr.PullImage(img); err != nil {
+			out.WarningT("Failed to pull {{.image}}: {{.error}}", out.V{"image": img, "error": err})
+		}
+	}
+}
+
+func init() {
+	devKubeletCmd.Flags().StringVar(&devKubeletFrom, "from", "", "The kubelet binary to run on the nodes, like ./_output/local/bin/linux/amd64/kubelet")
+	devKubeletCmd.Flags().BoolVar(&devKubeletRestore, "restore", false, "Put back the released kubelet of the Kubernetes version of the cluster")
+	devKubeletCmd.Flags().StringVarP(&nodeName, "node", "n", "", "The node to replace the kubelet of. Defaults to all the nodes.")
+	devNodeE2ECmd.Flags().StringSliceVar(&devNodeE2EImages, "images", nodeE2EImages, "The images to pull for the tests")
+	devNodeE2ECmd.Flags().StringVarP(&nodeName, "node", "n", "", "The node to configure. Defaults to the primary control plane.")
+	devCmd.AddCommand(devKubeletCmd)
+	devCmd.AddCommand(devNodeE2ECmd)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCheckKubeletBinary(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "kubelet")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkKubeletBinary(script, "amd64"); err == nil {
+		t.Errorf("checkKubeletBinary(%s): expected an error for a script", script)
+	}
+
+	if runtime.GOOS != "linux" {
+		t.Skip("the test binary is not an ELF binary")
+	}
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkKubeletBinary(self, runtime.GOARCH); err != nil {
+		t.Errorf("checkKubeletBinary(%s, %s): %v", self, runtime.GOARCH, err)
+	}
+	other := "arm64"
+	if runtime.GOARCH == "arm64" {
+		other = "amd64"
+	}
+	if err := checkKubeletBinary(self, other); err == nil {
+		t.Errorf("checkKubeletBinary(%s, %s): expected an error for another architecture", self, other)
+	}
+}
+
+func TestWithContainerdTestHandler(t *testing.T) {
+	cfg := `version = 2
+[plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runc]
+  runtime_type = "io.containerd.runc.v2"
+`
+	got, changed := withContainerdTestHandler(cfg)
+	if !changed || !strings.HasPrefix(got, cfg) || !strings.Contains(got, `runtimes.test-handler]`) {
+		t.Errorf("withContainerdTestHandler did not add the test handler:\n%s", got)
+	}
+	if again, changed := withContainerdTestHandler(got); changed || again != got {
+		t.Errorf("withContainerdTestHandler added the test handler twice:\n%s", again)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/hostdns"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/servicedns"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	dnsListenAddress string
+	dnsConfigureHost bool
+)
+
+// dnsCmd represents the dns command
+var dnsCmd = &cobra.Command{
+	Use:   "dns",
+	Short: "Resolve the names of the cluster services on the host",
+	Long: `Runs a DNS server on the host answering <service>.<namespace>.svc.<profile> with the IPs of the services, kept in sync with the cluster, until Ctrl-C.
+
+A service resolves to the IPs of its load balancer if it has some, to its cluster IPs otherwise, which the host reaches with 'minikube tunnel' or 'minikube route add'.
+With --configure-host, the default on macOS and Windows, the resolver of the host sends the queries of the domain to the server on macOS, and on Windows when listening on port 53. Elsewhere, configure a forwarder of the domain to the server, for example with dnsmasq.`,
+	Example: `minikube dns
+curl http://web.default.svc.minikube`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cname := ClusterFlagValue()
+		mustload.Healthy(cname)
+
+		host, p, err := net.SplitHostPort(dnsListenAddress)
+		if err != nil {
+			exit.Message(reason.Usage, "Sorry, the --listen-address flag is not valid: {{.err}}", out.V{"err": err})
+		}
+		port, err := strconv.Atoi(p)
+		if err != nil {
+			exit.Message(reason.Usage, "Sorry, the --listen-address flag is not valid: {{.err}}", out.V{"err": err})
+		}
+
+		client, err := backgroundClient(cname)
+		if err != nil {
+			exit.Error(reason.InternalKubernetesClient, "error creating clientset", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctrlC := make(chan os.Signal, 1)
+		signal.Notify(ctrlC, os.Interrupt)
+		go func() {
+			<-ctrlC
+			cancel()
+		}()
+
+		s := servicedns.NewServer(cname)
+		if err := s.Watch(ctx, client); err != nil {
+			exit.Error(reason.SvcDNS, "Unable to watch the services", err)
+		}
+
+		zone := strings.TrimSuffix(s.Zone(), ".")
+		configured := false
+		if dnsConfigureHost && !hostdns.ZoneSupported {
+			out.Styled(style.Notice, "--configure-host is not supported on {{.os}}, configure a forwarder of {{.zone}} to {{.address}}, for example with dnsmasq", out.V{"os": runtime.GOOS, "zone": zone, "address": dnsListenAddress})
+		} else if dnsConfigureHost {
+			if err := hostdns.ConfigureZone(cname, zone, host, port); err != nil {
+				out.WarningT("Unable to configure the resolver of the host, send the queries of {{.zone}} to {{.address}}: {{.error}}", out.V{"zone": zone, "address": dnsListenAddress, "error": err})
+			} else {
+				configured = true
+			}
+		}
+
+		out.Step(style.Connectivity, "Resolving the services as <service>.<namespace>.{{.zone}} on {{.address}}, press Ctrl-C to stop", out.V{"zone": zone, "address": dnsListenAddress})
+		err = s.ListenAndServe(ctx, dnsListenAddress)
+		if configured {
+			if rerr := hostdns.RemoveZone(cname, zone); rerr != nil {
+				klog.Warningf("failed to remove the resolver configuration of %s: %v", zone, rerr)
+			}
+		}
+		if err != nil {
+			exit.Error(reason.SvcDNS, "Unable to answer the DNS queries of the services", err)
+		}
+	},
+}
+
+func init() {
+	dnsCmd.Flags().StringVar(&dnsListenAddress, "listen-address", "127.0.0.1:10053", "The address the DNS server listens on, in the IP:PORT format. Port 53 usually needs root")
+	dnsCmd.Flags().BoolVar(&dnsConfigureHost, "configure-host", hostdns.ZoneSupported, "Make the resolver of the host send the queries of the services to the DNS server, on macOS, and on Windows with port 53")
+}
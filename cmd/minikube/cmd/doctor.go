@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/minikube/pkg/minikube/detect"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// doctorCmd represents the doctor command
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the host environment minikube runs in",
+	Long: `Diagnose the host environment minikube runs in, such as running nested inside another VM, container or CI runner, and suggest fixes for known problems.
+With --gpu, also diagnose the NVIDIA GPU setup of the host and of the cluster.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		drvName := viper.GetString("driver")
+		n := detect.Nested()
+		out.Step(style.Check, "minikube is running on {{.env}}", out.V{"env": n.String()})
+		if n.CgroupVersion != "" {
+			out.Infof("cgroup version: {{.version}}", out.V{"version": n.CgroupVersion})
+		}
+		if n.RootFSType != "" {
+			out.Infof("root filesystem: {{.fs}}", out.V{"fs": n.RootFSType})
+		}
+		out.Infof("/dev/kvm available: {{.kvm}}", out.V{"kvm": n.KVMAvailable})
+		if n.InotifyMaxUserWatches > 0 {
+			out.Infof("fs.inotify.max_user_watches: {{.watches}}, fs.inotify.max_user_instances: {{.instances}}", out.V{"watches": n.InotifyMaxUserWatches, "instances": n.InotifyMaxUserInstances})
+		}
+
+		problems := warnNestedEnvironment(n, drvName)
+		if doctorGPU {
+			problems += diagnoseGPU(ClusterFlagValue(), drvName)
+		}
+		if problems == 0 {
+			out.Step(style.Happy, "No problems found")
+		}
+	},
+}
+
+var doctorGPU bool
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorGPU, "gpu", false, "Also check the NVIDIA driver and Container Toolkit of the host against what the cluster expects, repair the nvidia runtime config of the node, and run a CUDA smoke test pod")
+}
+
+// warnNestedEnvironment explains problems known to break minikube when it is nested inside another VM or container.
+// drvName may be empty if the driver is not known yet. Returns the number of problems found.
+func warnNestedEnvironment(n detect.NestedEnvironment, drvName string) int {
+	if !n.IsNested() {
+		return 0
+	}
+	problems := 0
+	sharesKernel := drvName == "" || driver.IsKIC(drvName) || driver.BareMetal(drvName)
+
+	if (drvName == "" || driver.IsKVM(drvName) || driver.IsQEMU(drvName)) && !n.KVMAvailable {
+		problems++
+		out.WarningT("/dev/kvm is not available inside this {{.env}}, so the kvm2 and qemu2 drivers cannot be used. Enable nested virtualization, pass /dev/kvm through, or use the docker driver.", out.V{"env": n.String()})
+	}
+	if sharesKernel && n.LowInotifyLimits() {
+		problems++
+		out.WarningT("The inotify limits of this {{.env}} are low (max_user_watches={{.watches}}, max_user_instances={{.instances}}), which causes kubelet 'too many open files' failures. On the outer host run: sudo sysctl fs.inotify.max_user_watches={{.recWatches}} fs.inotify.max_user_instances={{.recInstances}}",
+			out.V{"env": n.String(), "watches": n.InotifyMaxUserWatches, "instances": n.InotifyMaxUserInstances, "recWatches": detect.RecommendedInotifyMaxUserWatches, "recInstances": detect.RecommendedInotifyMaxUserInstances})
+	}
+	if sharesKernel && n.NeedsFlatCgroups() {
+		problems++
+		out.Styled(style.Notice, "Running in a container with cgroup v1: kubelet QoS cgroups will be disabled as they cannot be nested")
+	}
+	if sharesKernel && n.OverlayRootFS() {
+		problems++
+		out.WarningT("The root filesystem of this {{.env}} is overlay, on which the overlay2 storage driver cannot be stacked. Mount a volume at /var/lib/docker (or the podman storage dir) of the outer container.", out.V{"env": n.String()})
+	}
+	return problems
+}
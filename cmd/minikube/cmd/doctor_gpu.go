@@ -0,0 +1,250 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/detect"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/sysinit"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+)
+
+// gpuSmokeTestManifest runs the CUDA vector addition sample on a GPU, which prints "Test PASSED"
+const gpuSmokeTestManifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: minikube-gpu-smoke-test
+  namespace: default
+spec:
+  restartPolicy: Never
+  containers:
+  - name: cuda-vectoradd
+    image: nvcr.io/nvidia/k8s/cuda-sample:vectoradd-cuda12.5.0
+    resources:
+      limits:
+        nvidia.com/gpu: 1
+`
+
+// diagnoseGPU checks the NVIDIA setup of the host, and of the nodes of the cluster if it runs, repairing the runtime config
+// of the node. Returns the number of problems found.
+func diagnoseGPU(profile, drvName string) int {
+	cc, err := config.Load(profile)
+	if err == nil {
+		drvName = cc.Driver
+	}
+	if driver.IsKVM(drvName) {
+		out.Infof("With the kvm2 driver, the GPUs are passed through to the VM, which runs its own NVIDIA driver")
+	} else {
+		h := detect.Nvidia()
+		out.Step(style.Check, "NVIDIA driver {{.driver}} (CUDA {{.cuda}}), Container Toolkit {{.toolkit}}, GPUs: {{.gpus}}",
+			out.V{"driver": orNone(h.DriverVersion), "cuda": orNone(h.CUDAVersion), "toolkit": orNone(h.ToolkitVersion), "gpus": orNone(strings.Join(h.GPUs, ", "))})
+		if problems := warnNvidiaHost(h, drvName); problems > 0 {
+			return problems
+		}
+	}
+
+	if err != nil {
+		if !config.IsNotExist(err) {
+			klog.Warningf("loading profile %s: %v", profile, err)
+		}
+		out.Infof("Profile {{.profile}} not found, skipping the checks of the cluster", out.V{"profile": profile})
+		return 0
+	}
+	if cc.GPUs == "" {
+		out.WarningT("The cluster {{.profile}} was started without GPUs. Recreate it with: minikube delete -p {{.profile}} && minikube start -p {{.profile}} --gpus all", out.V{"profile": profile})
+		return 1
+	}
+	if !clusterRunning(*cc) {
+		out.Infof("The cluster {{.profile}} is not running, skipping the checks of the cluster", out.V{"profile": profile})
+		return 0
+	}
+
+	co := mustload.Running(profile)
+	r := co.CP.Runner
+	problems := 0
+	if !driver.IsKVM(cc.Driver) {
+		p, err := checkNodeNvidia(r, cc.KubernetesConfig.ContainerRuntime)
+		if err != nil {
+			out.WarningT("Unable to check the NVIDIA setup of the node: {{.error}}", out.V{"error": err})
+			return problems + 1
+		}
+		problems += p
+	}
+	return problems + gpuSmokeTest(r, *cc)
+}
+
+// warnNvidiaHost explains the problems of the NVIDIA driver and toolkit of the host, which the nodes of the docker driver use.
+// Returns the number of problems found.
+func warnNvidiaHost(h detect.NvidiaHost, drvName string) int {
+	if h.DriverVersion == "" {
+		out.WarningT("nvidia-smi failed: install the NVIDIA driver, see https://docs.nvidia.com/datacenter/tesla/tesla-installation-notes/")
+		return 1
+	}
+	problems := 0
+	if !detect.VersionAtLeast(h.DriverVersion, detect.MinNvidiaDriverVersion) {
+		problems++
+		out.WarningT("The NVIDIA driver {{.version}} is older than {{.min}}, which CUDA 12 in the cluster requires. Upgrade the NVIDIA driver of the host.",
+			out.V{"version": h.DriverVersion, "min": detect.MinNvidiaDriverVersion})
+	}
+	if h.CUDAVersion != "" && !detect.VersionAtLeast(h.CUDAVersion, detect.MinCUDAVersion) {
+		problems++
+		out.WarningT("The NVIDIA driver supports CUDA {{.version}}, older than the CUDA {{.min}} of the containers of the cluster. Upgrade the NVIDIA driver of the host.",
+			out.V{"version": h.CUDAVersion, "min": detect.MinCUDAVersion})
+	}
+	if drvName != "" && !driver.IsDocker(drvName) {
+		return problems
+	}
+	switch {
+	case h.ToolkitVersion == "":
+		problems++
+		out.WarningT("nvidia-ctk not found: install the NVIDIA Container Toolkit, see https://docs.nvidia.com/datacenter/cloud-native/container-toolkit/latest/install-guide.html")
+	case !detect.VersionAtLeast(h.ToolkitVersion, detect.MinNvidiaToolkitVersion):
+		problems++
+		out.WarningT("The NVIDIA Container Toolkit {{.version}} is older than {{.min}}. Upgrade it, see https://docs.nvidia.com/datacenter/cloud-native/container-toolkit/latest/install-guide.html",
+			out.V{"version": h.ToolkitVersion, "min": detect.MinNvidiaToolkitVersion})
+	case !h.DockerRuntime:
+		problems++
+		out.WarningT("Docker does not have the nvidia runtime. Run: sudo nvidia-ctk runtime configure --runtime=docker && sudo systemctl restart docker")
+	}
+	return problems
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+// clusterRunning returns whether the primary control plane of the cluster runs
+func clusterRunning(cc config.ClusterConfig) bool {
+	api, err := machine.NewAPIClient()
+	if err != nil {
+		klog.Warningf("libmachine: %v", err)
+		return false
+	}
+	defer api.Close()
+	cp, err := config.PrimaryControlPlane(&cc)
+	if err != nil {
+		return false
+	}
+	st, err := machine.Status(api, config.MachineName(cc, cp))
+	return err == nil && st == state.Running.String()
+}
+
+// checkNodeNvidia checks that the node sees the GPUs, and repairs the config of its container runtime if nvidia is not its default runtime.
+// Returns the number of problems found which were not repaired.
+func checkNodeNvidia(r command.Runner, runtime string) (int, error) {
+	if rr, err := r.RunCmd(exec.Command("nvidia-smi", "-L")); err != nil {
+		out.WarningT("The node does not see the GPUs: {{.output}}. Recreate the cluster with --gpus all.", out.V{"output": strings.TrimSpace(rr.Output())})
+		return 1, nil
+	}
+	rr, err := r.RunCmd(exec.Command("nvidia-ctk", "--version"))
+	if err != nil {
+		return 0, errors.Wrap(err, "nvidia-ctk")
+	}
+	if v := detect.ParseNvidiaCTKVersion(rr.Stdout.String()); !detect.VersionAtLeast(v, detect.MinNvidiaToolkitVersion) {
+		out.WarningT("The NVIDIA Container Toolkit {{.version}} of the node is older than {{.min}}. Recreate the cluster with a newer minikube.",
+			out.V{"version": orNone(v), "min": detect.MinNvidiaToolkitVersion})
+		return 1, nil
+	}
+
+	rc, ok := cruntime.NvidiaRuntimeConfigs[runtime]
+	if !ok {
+		return 0, fmt.Errorf("unsupported container runtime %q", runtime)
+	}
+	rr, err = r.RunCmd(exec.Command("sudo", "cat", rc.Path))
+	if err == nil && rc.Configured.MatchString(rr.Stdout.String()) {
+		out.Step(style.Check, "{{.runtime}} in the node uses the nvidia runtime", out.V{"runtime": runtime})
+		return 0, nil
+	}
+
+	out.Step(style.Workaround, "Configuring the nvidia runtime of {{.runtime}} in the node ...", out.V{"runtime": runtime})
+	if err := cruntime.ConfigureNvidiaRuntime(r, runtime); err != nil {
+		return 0, err
+	}
+	if err := sysinit.New(r).Restart(rc.Service); err != nil {
+		return 0, errors.Wrapf(err, "restart %s", rc.Service)
+	}
+	return 0, nil
+}
+
+// gpuSmokeTest runs a CUDA pod on a GPU of the cluster. Returns the number of problems found.
+func gpuSmokeTest(r command.Runner, cc config.ClusterConfig) int {
+	kubectl := func(args ...string) (string, error) {
+		args = append([]string{"KUBECONFIG=" + vmpath.GuestPersistentDir + "/kubeconfig", kapi.KubectlBinaryPath(cc.KubernetesConfig.KubernetesVersion)}, args...)
+		rr, err := r.RunCmd(exec.Command("sudo", args...))
+		if err != nil {
+			return "", errors.Wrapf(err, "kubectl %s: %s", args[2], rr.Output())
+		}
+		return strings.TrimSpace(rr.Stdout.String()), nil
+	}
+
+	gpus, err := kubectl("get", "nodes", "-o", `jsonpath={.items[*].status.allocatable.nvidia\.com/gpu}`)
+	if err != nil || gpus == "" || gpus == "0" {
+		addon := "nvidia-device-plugin"
+		if driver.IsKVM(cc.Driver) {
+			addon = "nvidia-gpu-device-plugin"
+		}
+		out.WarningT("No node has allocatable GPUs. Check that the device plugin runs: minikube addons enable {{.addon}}", out.V{"addon": addon})
+		return 1
+	}
+
+	out.Step(style.Waiting, "Running a CUDA smoke test pod ...")
+	path := "/tmp/minikube-gpu-smoke-test.yaml"
+	if err := r.Copy(assets.NewMemoryAssetTarget([]byte(gpuSmokeTestManifest), path, "0644")); err != nil {
+		out.WarningT("Unable to run the CUDA smoke test: {{.error}}", out.V{"error": err})
+		return 1
+	}
+	defer func() {
+		if _, err := kubectl("delete", "--ignore-not-found", "-f", path); err != nil {
+			klog.Warningf("deleting smoke test pod: %v", err)
+		}
+	}()
+	if _, err := kubectl("apply", "-f", path); err != nil {
+		out.WarningT("Unable to run the CUDA smoke test: {{.error}}", out.V{"error": err})
+		return 1
+	}
+	timeout := fmt.Sprintf("--timeout=%s", 5*time.Minute)
+	_, waitErr := kubectl("wait", "--for=jsonpath={.status.phase}=Succeeded", timeout, "pod/minikube-gpu-smoke-test")
+	logs, _ := kubectl("logs", "pod/minikube-gpu-smoke-test")
+	if waitErr != nil || !strings.Contains(logs, "Test PASSED") {
+		describe, _ := kubectl("describe", "pod/minikube-gpu-smoke-test")
+		klog.Infof("smoke test pod:\n%s", describe)
+		out.WarningT("The CUDA smoke test failed: {{.logs}}", out.V{"logs": orNone(logs)})
+		return 1
+	}
+	out.Step(style.Check, "The CUDA smoke test passed")
+	return 0
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/detect"
+	"k8s.io/minikube/pkg/minikube/driver"
+)
+
+func TestWarnNestedEnvironment(t *testing.T) {
+	dind := detect.NestedEnvironment{
+		Container:               "docker",
+		CIRunner:                "github-actions",
+		CgroupVersion:           "v1",
+		RootFSType:              "overlay",
+		InotifyMaxUserWatches:   8192,
+		InotifyMaxUserInstances: 128,
+	}
+
+	tests := []struct {
+		description string
+		env         detect.NestedEnvironment
+		driver      string
+		expected    int
+	}{
+		{"bare metal", detect.NestedEnvironment{}, driver.Docker, 0},
+		{"nested VM with kvm", detect.NestedEnvironment{Hypervisor: "kvm", KVMAvailable: true}, driver.KVM2, 0},
+		{"nested VM without kvm", detect.NestedEnvironment{Hypervisor: "kvm"}, driver.KVM2, 1},
+		{"docker in docker", dind, driver.Docker, 3},
+		{"docker in docker with unknown driver", dind, "", 4},
+		{"ssh driver from a container", dind, driver.SSH, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := warnNestedEnvironment(tc.env, tc.driver); got != tc.expected {
+				t.Errorf("warnNestedEnvironment() = %d problems, want %d", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestWarnNvidiaHost(t *testing.T) {
+	healthy := detect.NvidiaHost{DriverVersion: "535.129.03", GPUs: []string{"Tesla T4"}, CUDAVersion: "12.2", ToolkitVersion: "1.14.3", DockerRuntime: true}
+	noRuntime := healthy
+	noRuntime.DockerRuntime = false
+	oldDriver := healthy
+	oldDriver.DriverVersion = "470.223.02"
+	oldDriver.CUDAVersion = "11.4"
+
+	tests := []struct {
+		description string
+		host        detect.NvidiaHost
+		driver      string
+		expected    int
+	}{
+		{"healthy", healthy, driver.Docker, 0},
+		{"no driver", detect.NvidiaHost{}, driver.Docker, 1},
+		{"old driver", oldDriver, driver.Docker, 2},
+		{"no docker runtime", noRuntime, driver.Docker, 1},
+		{"no docker runtime with the none driver", noRuntime, driver.None, 0},
+		{"old toolkit", detect.NvidiaHost{DriverVersion: "535.129.03", ToolkitVersion: "1.13.5"}, "", 1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := warnNvidiaHost(tc.host, tc.driver); got != tc.expected {
+				t.Errorf("warnNvidiaHost() = %d problems, want %d", got, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/idle"
+	"k8s.io/minikube/pkg/minikube/kubeconfig"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/node"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// idleProxyCmd represents the idle-proxy command
+var idleProxyCmd = &cobra.Command{
+	Use:   "idle-proxy",
+	Short: "Pause or stop the cluster while kubectl does not use it, resuming it on the next kubectl call",
+	Long: `Forwards the connections of kubectl to the apiserver, and pauses or stops the cluster once no connection was open for the duration set with 'minikube start --idle-timeout', until Ctrl-C. The next connection resumes the cluster before being forwarded.
+
+'minikube start' runs it in the background and points the kubeconfig context at it, and 'minikube stop' and 'minikube delete' stop it.`,
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		cname := ClusterFlagValue()
+		api, cc := mustload.Partial(cname)
+		api.Close()
+		if !idle.Enabled(*cc) {
+			out.Styled(style.Notice, "No idle timeout is set, set one with: minikube start --idle-timeout=15m")
+			return
+		}
+
+		l, err := net.Listen("tcp", net.JoinHostPort(oci.DefaultBindIPV4, strconv.Itoa(cc.IdleProxyPort)))
+		if err != nil {
+			exit.Error(reason.HostIdleProxy, "Failed to listen for the idle proxy", err)
+		}
+		p := &idle.Proxy{
+			Timeout:  cc.IdleTimeout,
+			Upstream: func() (string, error) { return idleUpstream(cname) },
+			Suspend:  func() error { return suspendIdle(cname) },
+			Resume:   func() error { return resumeIdle(cname) },
+		}
+		if cc.IdleAction == idle.ActionPause {
+			p.Suspended = idlePaused(*cc)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctrlC := make(chan os.Signal, 1)
+		signal.Notify(ctrlC, os.Interrupt)
+		go func() {
+			<-ctrlC
+			cancel()
+		}()
+
+		out.Step(style.Connectivity, "Forwarding {{.address}} to the apiserver, the cluster is suspended after {{.timeout}} without connections", out.V{"address": l.Addr(), "timeout": cc.IdleTimeout})
+		if err := p.Serve(ctx, l); err != nil {
+			exit.Error(reason.HostIdleProxy, "Failed to serve the idle proxy", err)
+		}
+	},
+}
+
+// idleUpstream returns the host:port of the apiserver of the profile, which moves when the machines restart
+func idleUpstream(profile string) (string, error) {
+	cc, err := config.Load(profile)
+	if err != nil {
+		return "", errors.Wrap(err, "load config")
+	}
+	cp, err := config.PrimaryControlPlane(cc)
+	if err != nil {
+		return "", err
+	}
+	var hostname string
+	var ip net.IP
+	var port int
+	if cc.Addons["auto-pause"] {
+		hostname, ip, port, err = driver.AutoPauseProxyEndpoint(cc, &cp, cc.Driver)
+	} else {
+		hostname, ip, port, err = driver.ControlPlaneEndpoint(cc, &cp, cc.Driver)
+	}
+	if err != nil {
+		return "", err
+	}
+	if ip != nil {
+		hostname = ip.String()
+	}
+	return net.JoinHostPort(hostname, strconv.Itoa(port)), nil
+}
+
+// idleRuntimes calls f with the runner and container runtime of each Linux node of cc
+func idleRuntimes(cc config.ClusterConfig, f func(cruntime.Manager, command.Runner) error) error {
+	api, err := machine.NewAPIClient()
+	if err != nil {
+		return errors.Wrap(err, "libmachine")
+	}
+	defer api.Close()
+	for _, n := range cc.Nodes {
+		if config.IsWindows(n) {
+			continue
+		}
+		h, err := machine.LoadHost(api, config.MachineName(cc, n))
+		if err != nil {
+			return errors.Wrap(err, "load host")
+		}
+		r, err := machine.CommandRunner(h)
+		if err != nil {
+			return errors.Wrap(err, "command runner")
+		}
+		cr, err := cruntime.New(cruntime.Config{Type: cc.KubernetesConfig.ContainerRuntime, Runner: r})
+		if err != nil {
+			return errors.Wrap(err, "container runtime")
+		}
+		if err := f(cr, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// idlePaused returns whether the kube-system containers of the primary control plane of cc are paused
+func idlePaused(cc config.ClusterConfig) bool {
+	paused := false
+	cp, err := config.PrimaryControlPlane(&cc)
+	if err != nil {
+		klog.Warningf("primary control plane of %s: %v", cc.Name, err)
+		return false
+	}
+	cc.Nodes = []config.Node{cp}
+	err = idleRuntimes(cc, func(cr cruntime.Manager, _ command.Runner) error {
+		var err error
+		paused, err = cluster.CheckIfPaused(cr, []string{"kube-system"})
+		return err
+	})
+	if err != nil {
+		klog.Warningf("checking whether %s is paused: %v", cc.Name, err)
+	}
+	return paused
+}
+
+// suspendIdle pauses or stops the cluster of the profile, as set with --idle-action
+func suspendIdle(profile string) error {
+	cc, err := config.Load(profile)
+	if err != nil {
+		return errors.Wrap(err, "load config")
+	}
+	if cc.IdleAction == idle.ActionStop {
+		api, err := machine.NewAPIClient()
+		if err != nil {
+			return errors.Wrap(err, "libmachine")
+		}
+		defer api.Close()
+		for _, n := range cc.Nodes {
+			if config.IsWindows(n) {
+				if _, err := node.StopWindows(*cc, n); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := machine.StopHost(api, config.MachineName(*cc, n)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return idleRuntimes(*cc, func(cr cruntime.Manager, r command.Runner) error {
+		_, err := cluster.Pause(cr, r, []string{"kube-system"})
+		return err
+	})
+}
+
+// resumeIdle unpauses or starts the cluster of the profile suspended by suspendIdle
+func resumeIdle(profile string) error {
+	cc, err := config.Load(profile)
+	if err != nil {
+		return errors.Wrap(err, "load config")
+	}
+	if cc.IdleAction == idle.ActionStop {
+		// a start run by the idle proxy leaves it running, see startIdleProxy
+		c := exec.Command(os.Args[0], "start", "--profile", profile)
+		c.Env = append(os.Environ(), constants.IsMinikubeChildProcess+"=true")
+		if output, err := c.CombinedOutput(); err != nil {
+			return errors.Wrapf(err, "start: %s", output)
+		}
+		return nil
+	}
+	return idleRuntimes(*cc, func(cr cruntime.Manager, r command.Runner) error {
+		_, err := cluster.Unpause(cr, r, nil)
+		return err
+	})
+}
+
+// startIdleProxy restarts the background process suspending the cluster after --idle-timeout without kubectl connections,
+// and points the kubeconfig context at it
+func startIdleProxy(cc *config.ClusterConfig) {
+	// the cluster was started by its idle proxy to resume it
+	resumed := idle.ProcessPid(cc.Name) == os.Getppid()
+	if !resumed {
+		if err := killIdleProxyProcess(cc.Name); err != nil {
+			klog.Warningf("failed to kill the idle-proxy process: %v", err)
+		}
+	}
+	if cc.IdleTimeout == 0 || cc.KubernetesConfig.KubernetesVersion == constants.NoKubernetesVersion {
+		return
+	}
+	if cc.IdleProxyPort == 0 {
+		port, err := idle.FreePort()
+		if err != nil {
+			out.WarningT("Unable to start the idle proxy: {{.error}}", out.V{"error": err})
+			return
+		}
+		cc.IdleProxyPort = port
+		if err := config.SaveProfile(cc.Name, cc); err != nil {
+			exit.Error(reason.HostSaveProfile, "failed to save config", err)
+		}
+	}
+	if !resumed {
+		if err := idle.StartProcess(cc.Name); err != nil {
+			out.WarningT("Unable to start the idle proxy: {{.error}}", out.V{"error": err})
+			return
+		}
+	}
+	if _, err := kubeconfig.UpdateEndpoint(cc.Name, oci.DefaultBindIPV4, cc.IdleProxyPort, kubeconfig.PathFromEnv(), kubeconfig.NewExtension()); err != nil {
+		exit.Error(reason.HostKubeconfigUpdate, "update config", err)
+	}
+	switch {
+	case resumed:
+	case cc.IdleAction == idle.ActionStop:
+		out.Step(style.Pause, "The cluster is stopped after {{.timeout}} without kubectl activity, and started again on the next kubectl call", out.V{"timeout": cc.IdleTimeout})
+	default:
+		out.Step(style.Pause, "The cluster is paused after {{.timeout}} without kubectl activity, and unpaused on the next kubectl call", out.V{"timeout": cc.IdleTimeout})
+	}
+}
+
+// kubeconfigEndpoint returns the endpoint of the kubeconfig context of cc: its idle proxy if enabled, else hostname:port
+func kubeconfigEndpoint(cc config.ClusterConfig, hostname string, port int) (string, int) {
+	if idle.Enabled(cc) {
+		return oci.DefaultBindIPV4, cc.IdleProxyPort
+	}
+	return hostname, port
+}
+
+// backgroundServer returns the URL of the apiserver the long running commands of minikube, such as tunnel, connect to past the
+// idle proxy, so that they do not keep the cluster from idling, or "" without an idle proxy
+func backgroundServer(profile string) string {
+	cc, err := config.Load(profile)
+	if err != nil || !idle.Enabled(*cc) {
+		return ""
+	}
+	addr, err := idleUpstream(profile)
+	if err != nil {
+		klog.Warningf("apiserver endpoint of %s: %v", profile, err)
+		return ""
+	}
+	return "https://" + addr
+}
+
+// backgroundClientConfig returns the client config of a long running command, see backgroundServer
+func backgroundClientConfig(profile string) (*rest.Config, error) {
+	c, err := kapi.ClientConfig(profile)
+	if err != nil {
+		return nil, err
+	}
+	if s := backgroundServer(profile); s != "" {
+		c.Host = s
+	}
+	return c, nil
+}
+
+// backgroundClient returns the client of a long running command, see backgroundServer
+func backgroundClient(profile string) (*kubernetes.Clientset, error) {
+	c, err := backgroundClientConfig(profile)
+	if err != nil {
+		return nil, err
+	}
+	return kubernetes.NewForConfig(c)
+}
+
+func init() {
+	RootCmd.AddCommand(idleProxyCmd)
+}
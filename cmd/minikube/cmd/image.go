@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
 	"io"
 	"net/url"
 	"os"
@@ -26,12 +27,18 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"k8s.io/minikube/pkg/addons"
+	"k8s.io/minikube/pkg/minikube/buildkit"
 	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/exit"
 	"k8s.io/minikube/pkg/minikube/image"
+	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
 	docker "k8s.io/minikube/third_party/go-dockerclient"
 )
 
@@ -56,6 +63,12 @@ var (
 	buildEnv   []string
 	buildOpt   []string
 	format     string
+
+	buildSecrets   []string
+	buildSSH       []string
+	buildPlatforms []string
+
+	pushRegistryAddon bool
 )
 
 func saveFile(r io.Reader) (string, error) {
@@ -269,10 +282,14 @@ func createTar(dir string) (string, error) {
 
 // buildImageCmd represents the image build command
 var buildImageCmd = &cobra.Command{
-	Use:     "build PATH | URL | -",
-	Short:   "Build a container image in minikube",
-	Long:    "Build a container image, using the container runtime.",
-	Example: `minikube image build .`,
+	Use:   "build PATH | URL | -",
+	Short: "Build a container image in minikube",
+	Long: `Build a container image, using the container runtime.
+With containerd, the image is built by a buildkitd managed in the node, whose build cache is kept across restarts.
+The secrets and SSH keys of the build are copied into the node for its duration only.`,
+	Example: `minikube image build .
+minikube image build -t app:dev --secret id=npmrc,src=$HOME/.npmrc --ssh default=$HOME/.ssh/id_ed25519 .
+minikube image build -t app:dev --platform linux/amd64,linux/arm64 .`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) < 1 {
 			exit.Message(reason.Usage, "Please provide a path or url to build")
@@ -311,13 +328,17 @@ var buildImageCmd = &cobra.Command{
 				// Otherwise, assume it's a tar
 			}
 		}
+		extras := machine.BuildExtras{Secrets: buildSecrets, SSH: buildSSH, Platforms: buildPlatforms}
+		if err := extras.Validate(profile.Config.KubernetesConfig.ContainerRuntime); err != nil {
+			exit.Message(reason.Usage, "{{.error}}", out.V{"error": err})
+		}
 		if runtime.GOOS == "windows" && strings.Contains(dockerFile, "\\") {
 			// if dockerFile is a DOS path, translate it into UNIX path
 			// because we are going to build this image in UNIX environment
 			out.String("minikube detects that you are using DOS-style path %s. minikube will convert it to UNIX-style by replacing all \\ to /", dockerFile)
 			dockerFile = strings.ReplaceAll(dockerFile, "\\", "/")
 		}
-		if err := machine.BuildImage(img, dockerFile, tag, push, buildEnv, buildOpt, []*config.Profile{profile}, allNodes, nodeName); err != nil {
+		if err := machine.BuildImage(img, dockerFile, tag, push, buildEnv, buildOpt, extras, []*config.Profile{profile}, allNodes, nodeName); err != nil {
 			exit.Error(reason.GuestImageBuild, "Failed to build image", err)
 		}
 		if tmp != "" {
@@ -326,6 +347,34 @@ var buildImageCmd = &cobra.Command{
 	},
 }
 
+// buildkitAddrCmd represents the image buildkit-addr command
+var buildkitAddrCmd = &cobra.Command{
+	Use:   "buildkit-addr",
+	Short: "Print the address of the buildkitd building the images of containerd",
+	Long: `Starts the buildkitd managed in the primary control plane node, and prints its address, for buildctl, docker buildx and IDE integrations:
+export BUILDKIT_HOST=$(minikube image buildkit-addr)
+The buildkitd of the VM drivers is reached over TLS with the client certificates of minikube.`,
+	Example: "minikube image buildkit-addr",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		co := mustload.Running(ClusterFlagValue())
+		defer co.API.Close()
+		cc := co.Config
+		if !buildkit.SupportsRuntime(cc.KubernetesConfig.ContainerRuntime) {
+			exit.Message(reason.Usage, "The images of the {{.runtime}} container runtime are not built by buildkitd, use 'minikube {{.runtime}}-env' instead", out.V{"runtime": cc.KubernetesConfig.ContainerRuntime})
+		}
+		tcp := buildkit.ListensTCP(cc.Driver)
+		if err := buildkit.Ensure(co.CP.Runner, tcp); err != nil {
+			exit.Error(reason.GuestImageBuild, "Failed to start buildkitd", err)
+		}
+		out.Ln("%s", buildkit.Address(cc.Driver, config.MachineName(*cc, *co.CP.Node), co.CP.IP.String()))
+		if tcp {
+			certs := localpath.MakeMiniPath("certs")
+			out.ErrT(style.Tip, "Connect with --tlscacert={{.ca}} --tlscert={{.cert}} --tlskey={{.key}}", out.V{"ca": filepath.Join(certs, "ca.pem"), "cert": filepath.Join(certs, "cert.pem"), "key": filepath.Join(certs, "key.pem")})
+		}
+	},
+}
+
 var listImageCmd = &cobra.Command{
 	Use:   "ls",
 	Short: "List images",
@@ -370,10 +419,19 @@ $ minikube image tag source target
 var pushImageCmd = &cobra.Command{
 	Use:   "push",
 	Short: "Push images",
+	Long: `Pushes images of the cluster to their registries.
+With --registry-addon, pushes images of the docker daemon of the host, or else of their registries, to the registry addon of the cluster over TLS,
+where the pods pull them from as localhost:5000/REPOSITORY:TAG.`,
 	Example: `
 $ minikube image push busybox
+
+$ minikube image push --registry-addon my-app:dev
 `,
 	Run: func(cmd *cobra.Command, args []string) {
+		if pushRegistryAddon {
+			pushToRegistryAddon(args)
+			return
+		}
 		profile, err := config.LoadProfile(viper.GetString(config.ProfileName))
 		if err != nil {
 			exit.Error(reason.Usage, "loading profile", err)
@@ -385,6 +443,28 @@ $ minikube image push busybox
 	},
 }
 
+// pushToRegistryAddon pushes the images imgs to the registry addon of the cluster, trusting its cert signed by the minikube CA
+func pushToRegistryAddon(imgs []string) {
+	if len(imgs) == 0 {
+		exit.Message(reason.Usage, "Please provide an image to push")
+	}
+	co := mustload.Running(ClusterFlagValue())
+	if !co.Config.Addons["registry"] {
+		exit.Message(reason.Usage, "The registry addon is not enabled, enable it with: minikube addons enable registry -p {{.profile}}", out.V{"profile": co.Config.Name})
+	}
+	addr, err := addons.RegistryAddr(co.Config)
+	if err != nil {
+		exit.Error(reason.GuestImagePush, "Failed to find the address of the registry addon", err)
+	}
+	for _, img := range imgs {
+		pushed, err := image.PushToRegistry(img, addr, localpath.CACert())
+		if err != nil {
+			exit.Error(reason.GuestImagePush, "Failed to push images", err)
+		}
+		out.Step(style.Ready, "Pushed {{.image}} to {{.pushed}}, the pods pull it as {{.name}}", out.V{"image": img, "pushed": pushed, "name": strings.Replace(pushed, addr, fmt.Sprintf("localhost:%d", constants.RegistryAddonPort), 1)})
+	}
+}
+
 func init() {
 	loadImageCmd.Flags().BoolVar(&pull, "pull", false, "Pull the remote image (no caching)")
 	loadImageCmd.Flags().BoolVar(&imgDaemon, "daemon", false, "Cache image from docker daemon")
@@ -400,12 +480,17 @@ func init() {
 	buildImageCmd.Flags().StringArrayVar(&buildOpt, "build-opt", nil, "Specify arbitrary flags to pass to the build. (format: key=value)")
 	buildImageCmd.Flags().StringVarP(&nodeName, "node", "n", "", "The node to build on. Defaults to the primary control plane.")
 	buildImageCmd.Flags().BoolVar(&allNodes, "all", false, "Build image on all nodes.")
+	buildImageCmd.Flags().StringArrayVar(&buildSecrets, "secret", nil, "A secret of the build, mounted by RUN --mount=type=secret. (format: id=ID,src=PATH)")
+	buildImageCmd.Flags().StringArrayVar(&buildSSH, "ssh", nil, "An SSH key of the build, mounted by RUN --mount=type=ssh. (format: ID=PATH)")
+	buildImageCmd.Flags().StringSliceVar(&buildPlatforms, "platform", nil, "The platforms the image is built for, several of them only with the containerd container runtime. (format: os/arch[/variant])")
 	imageCmd.AddCommand(buildImageCmd)
+	imageCmd.AddCommand(buildkitAddrCmd)
 	saveImageCmd.Flags().BoolVar(&imgDaemon, "daemon", false, "Cache image to docker daemon")
 	saveImageCmd.Flags().BoolVar(&imgRemote, "remote", false, "Cache image to remote registry")
 	imageCmd.AddCommand(saveImageCmd)
 	listImageCmd.Flags().StringVar(&format, "format", "short", "Format output. One of: short|table|json|yaml")
 	imageCmd.AddCommand(listImageCmd)
 	imageCmd.AddCommand(tagImageCmd)
+	pushImageCmd.Flags().BoolVar(&pushRegistryAddon, "registry-addon", false, "Push images of the host to the registry addon of the cluster, over TLS trusted with the minikube CA")
 	imageCmd.AddCommand(pushImageCmd)
 }
@@ -0,0 +1,158 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/minikube/pkg/drivers/kic"
+	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/minikube/baseoverlay"
+	"k8s.io/minikube/pkg/minikube/detect"
+	"k8s.io/minikube/pkg/minikube/download"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+const (
+	baseTypeISO     = "iso"
+	baseTypeKicbase = "kicbase"
+)
+
+var (
+	baseOverlay string
+	baseType    string
+	baseFrom    string
+)
+
+// buildBaseCmd represents the image build-base command
+var buildBaseCmd = &cobra.Command{
+	Use:   "build-base --overlay DIR",
+	Short: "Build a customized ISO or kicbase image from an overlay directory",
+	Long: `Layers the content of an overlay directory onto the minikube ISO or the kicbase image, and caches the result for 'minikube start', so that adding packages, kernel modules or configs to the base does not need a build of its own.
+
+The overlay directory holds:
+  files/    copied onto the root filesystem, such as files/etc/sysctl.d/99-custom.conf or kernel modules in files/lib/modules/
+  packages  packages installed into kicbase with apt-get, one per line, not supported by the ISO which has no package manager
+  modules   kernel modules loaded by the ISO at boot, one per line, not supported by kicbase which shares the kernel of the host
+
+Building the ISO requires xorriso, and the kicbase image requires docker or podman.`,
+	Example: `minikube image build-base --overlay ./overlay/
+minikube image build-base --overlay ./overlay/ --type=iso`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if baseOverlay == "" {
+			exit.Message(reason.Usage, "Please provide the overlay directory with --overlay")
+		}
+		o, err := baseoverlay.Load(baseOverlay)
+		if err != nil {
+			exit.Message(reason.Usage, "Unable to read the overlay: {{.error}}", out.V{"error": err})
+		}
+		switch baseType {
+		case baseTypeKicbase:
+			if len(o.Modules) > 0 {
+				exit.Message(reason.Usage, "The kernel modules of the overlay can only be loaded by the ISO, the containers of kicbase share the kernel of the host")
+			}
+			buildKicbaseOverlay(o)
+		case baseTypeISO:
+			if len(o.Packages) > 0 {
+				exit.Message(reason.Usage, "The packages of the overlay can only be installed into kicbase, the ISO has no package manager")
+			}
+			buildISOOverlay(o)
+		default:
+			exit.Message(reason.Usage, "The --type flag must be {{.iso}} or {{.kicbase}}", out.V{"iso": baseTypeISO, "kicbase": baseTypeKicbase})
+		}
+	},
+}
+
+// buildKicbaseOverlay builds the image layering o onto the kicbase image, tagged with the digest of both
+func buildKicbaseOverlay(o *baseoverlay.Overlay) {
+	base := baseFrom
+	if base == "" {
+		base = kic.BaseImage
+	}
+	ociBin := oci.Docker
+	if _, err := exec.LookPath(ociBin); err != nil {
+		ociBin = oci.Podman
+	}
+	hash, err := o.Hash(base)
+	if err != nil {
+		exit.Error(reason.HostBaseOverlay, "Unable to read the overlay", err)
+	}
+	tag := "minikube-local/kicbase-overlay:" + hash
+	out.Step(style.Provisioning, "Building {{.tag}} from {{.base}} with {{.bin}} ...", out.V{"tag": tag, "base": base, "bin": ociBin})
+	if err := o.BuildKicbase(ociBin, base, tag); err != nil {
+		exit.Error(reason.HostBaseOverlay, "Unable to build the kicbase image", err)
+	}
+	out.Step(style.Ready, "Built {{.tag}}, start clusters with it with: minikube start --base-image={{.tag}}", out.V{"tag": tag})
+}
+
+// buildISOOverlay writes the ISO layering o onto the minikube ISO into the ISO cache, named by the digest of both
+func buildISOOverlay(o *baseoverlay.Overlay) {
+	base, err := overlayBaseISO()
+	if err != nil {
+		exit.Error(reason.InetCacheISO, "Unable to cache the base ISO", err)
+	}
+	hash, err := o.Hash(base)
+	if err != nil {
+		exit.Error(reason.HostBaseOverlay, "Unable to read the overlay", err)
+	}
+	dst := filepath.Join(detect.ISOCacheDir(), "minikube-overlay-"+hash+".iso")
+	if _, err := os.Stat(dst); err != nil {
+		out.Step(style.Provisioning, "Building {{.iso}} from {{.base}} ...", out.V{"iso": dst, "base": base})
+		if err := o.BuildISO(base, dst); err != nil {
+			exit.Error(reason.HostBaseOverlay, "Unable to build the ISO", err)
+		}
+	}
+	out.Step(style.Ready, "Built {{.iso}}, start clusters with it with: minikube start --iso-url={{.url}}", out.V{"iso": dst, "url": "file://" + filepath.ToSlash(dst)})
+}
+
+// overlayBaseISO returns the path of the ISO of --base, a path or a URL, or of the default ISO, downloading it if needed
+func overlayBaseISO() (string, error) {
+	if baseFrom != "" {
+		if _, err := os.Stat(baseFrom); err == nil {
+			return filepath.Abs(baseFrom)
+		}
+		if u, err := url.Parse(baseFrom); err == nil && u.Scheme == "file" {
+			return filepath.FromSlash(strings.TrimPrefix(baseFrom, "file://")), nil
+		}
+	}
+	urls := download.DefaultISOURLs()
+	if baseFrom != "" {
+		urls = []string{baseFrom}
+	}
+	u, err := download.ISO(urls, baseFrom != "")
+	if err != nil {
+		return "", err
+	}
+	return download.LocalISOPath(u)
+}
+
+func init() {
+	buildBaseCmd.Flags().StringVar(&baseOverlay, "overlay", "", "The overlay directory, holding a files directory and packages and modules files")
+	buildBaseCmd.Flags().StringVar(&baseType, "type", baseTypeKicbase, "The base to build: kicbase, the image of the docker and podman drivers, or iso, the ISO of the VM drivers")
+	buildBaseCmd.Flags().StringVar(&baseFrom, "base", "", "The base to layer the overlay onto, the kicbase image or the ISO (a path or URL) of this minikube by default")
+	imageCmd.AddCommand(buildBaseCmd)
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/image"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	syncWatch    bool
+	syncInterval time.Duration
+)
+
+// imageSyncCmd represents the image sync command
+var imageSyncCmd = &cobra.Command{
+	Use:   "sync DIR",
+	Short: "Load the image tarballs and OCI image layouts of a directory into minikube",
+	Long: `Loads the image tarballs (*.tar) and OCI image layouts of a directory into all the nodes of the cluster.
+With --watch, keeps watching the directory, and loads the tarballs and layouts as they are added or changed, once they were left unchanged for --interval, so that they are not loaded while being written.
+The images of an OCI image layout are named by their io.containerd.image.name annotation, or by their org.opencontainers.image.ref.name annotation, a tag of the repository named after the layout if it is not a full name.`,
+	Example: `minikube image sync ./dist/
+minikube image sync --watch ./dist/`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if syncInterval < 100*time.Millisecond {
+			exit.Message(reason.Usage, "--interval must be at least 100ms")
+		}
+		dir := args[0]
+		if st, err := os.Stat(dir); err != nil || !st.IsDir() {
+			exit.Message(reason.Usage, "{{.dir}} is not a directory", out.V{"dir": dir})
+		}
+		profile, err := config.LoadProfile(viper.GetString(config.ProfileName))
+		if err != nil {
+			exit.Error(reason.Usage, "loading profile", err)
+		}
+
+		s := newImageSyncer()
+		if !syncWatch {
+			scan, err := scanSyncDir(dir)
+			if err != nil {
+				exit.Error(reason.HostPathMissing, "Failed to read the directory", err)
+			}
+			for _, e := range s.Observe(scan) {
+				if err := syncEntry(profile, filepath.Join(dir, e)); err != nil {
+					exit.Error(reason.GuestImageLoad, "Failed to load image", err)
+				}
+			}
+			return
+		}
+
+		ctrlC := make(chan os.Signal, 1)
+		signal.Notify(ctrlC, os.Interrupt, syscall.SIGTERM)
+		out.Step(style.Running, "Loading the images of {{.dir}} into {{.profile}} as they appear. Press Ctrl+C to stop.", out.V{"dir": dir, "profile": profile.Name})
+		ticker := time.NewTicker(syncInterval)
+		defer ticker.Stop()
+		for {
+			scan, err := scanSyncDir(dir)
+			if err != nil {
+				klog.Warningf("scanning %s: %v", dir, err)
+			}
+			for _, e := range s.Observe(scan) {
+				if err := syncEntry(profile, filepath.Join(dir, e)); err != nil {
+					out.FailureT("Failed to load {{.entry}}: {{.error}}", out.V{"entry": e, "error": err})
+				}
+			}
+			select {
+			case <-ctrlC:
+				return
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+// syncStamp identifies a version of the content of a tarball or of an OCI image layout
+type syncStamp struct {
+	size int64
+	mod  time.Time
+}
+
+// scanSyncDir returns the stamps of the image tarballs and of the OCI image layouts of dir, by name.
+// The stamp of a layout is the one of its index.json, which is written last.
+func scanSyncDir(dir string) (map[string]syncStamp, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	scan := map[string]syncStamp{}
+	for _, e := range entries {
+		// skip the hidden files, like the temporary files of downloads
+		if strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		p := filepath.Join(dir, e.Name())
+		switch {
+		case e.IsDir() && image.IsLayout(p):
+			p = filepath.Join(p, "index.json")
+		case e.Type().IsRegular() && strings.HasSuffix(e.Name(), ".tar"):
+		default:
+			continue
+		}
+		st, err := os.Stat(p)
+		if err != nil {
+			klog.Warningf("stat %s: %v", p, err)
+			continue
+		}
+		scan[e.Name()] = syncStamp{size: st.Size(), mod: st.ModTime()}
+	}
+	return scan, nil
+}
+
+// imageSyncer finds the tarballs and layouts of the scans of a directory to load
+type imageSyncer struct {
+	started bool
+	// loaded are the stamps of the loaded entries
+	loaded map[string]syncStamp
+	// seen are the stamps of the previous scan
+	seen map[string]syncStamp
+}
+
+func newImageSyncer() *imageSyncer {
+	return &imageSyncer{loaded: map[string]syncStamp{}, seen: map[string]syncStamp{}}
+}
+
+// Observe returns the entries of scan to load, sorted by name: all of them for the first scan, and then the ones added or changed since they were loaded,
+// once their stamp did not change since the previous scan
+func (s *imageSyncer) Observe(scan map[string]syncStamp) []string {
+	var load []string
+	for e, st := range scan {
+		if l, ok := s.loaded[e]; ok && l == st {
+			continue
+		}
+		if prev, ok := s.seen[e]; s.started && (!ok || prev != st) {
+			continue
+		}
+		s.loaded[e] = st
+		load = append(load, e)
+	}
+	// forget the removed entries, to load them again if they come back
+	for e := range s.loaded {
+		if _, ok := scan[e]; !ok {
+			delete(s.loaded, e)
+		}
+	}
+	s.seen = scan
+	s.started = true
+	sort.Strings(load)
+	return load
+}
+
+// syncEntry loads the image tarball or the OCI image layout p into the nodes of the profile
+func syncEntry(profile *config.Profile, p string) error {
+	tarballs := []string{p}
+	if image.IsLayout(p) {
+		tmp, err := os.MkdirTemp("", "image-sync")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		t := filepath.Join(tmp, filepath.Base(p)+".tar")
+		names, err := image.LayoutToTarball(p, t)
+		if err != nil {
+			return err
+		}
+		klog.Infof("images of %s: %v", p, names)
+		tarballs = []string{t}
+	}
+	if err := machine.DoLoadImages(tarballs, []*config.Profile{profile}, "", true); err != nil {
+		return errors.Wrapf(err, "loading %s", p)
+	}
+	out.Step(style.Ready, "Loaded {{.entry}} into {{.profile}}", out.V{"entry": filepath.Base(p), "profile": profile.Name})
+	return nil
+}
+
+func init() {
+	imageSyncCmd.Flags().BoolVar(&syncWatch, "watch", false, "Keep watching the directory, and load the tarballs and layouts as they are added or changed")
+	imageSyncCmd.Flags().DurationVar(&syncInterval, "interval", 2*time.Second, "The interval between the scans of the directory with --watch")
+	imageCmd.AddCommand(imageSyncCmd)
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestScanSyncDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"app.tar", ".app.tar.part", "notes.txt", "layout/oci-layout", "layout/index.json", "other/index.json"} {
+		p := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	scan, err := scanSyncDir(dir)
+	if err != nil {
+		t.Fatalf("scanSyncDir: %v", err)
+	}
+	var got []string
+	for e := range scan {
+		got = append(got, e)
+	}
+	if len(got) != 2 || scan["app.tar"].size != int64(len("app.tar")) || scan["layout"].size != int64(len("layout/index.json")) {
+		t.Errorf("scanSyncDir() = %v, want app.tar and layout", scan)
+	}
+}
+
+func TestImageSyncer(t *testing.T) {
+	t0 := time.Now()
+	v1 := syncStamp{size: 1, mod: t0}
+	v2 := syncStamp{size: 2, mod: t0.Add(time.Second)}
+	s := newImageSyncer()
+	steps := []struct {
+		scan map[string]syncStamp
+		want []string
+	}{
+		// the entries present at the start are loaded at once
+		{map[string]syncStamp{"a.tar": v1}, []string{"a.tar"}},
+		// a new entry is loaded once unchanged for a scan
+		{map[string]syncStamp{"a.tar": v1, "b.tar": v1}, nil},
+		{map[string]syncStamp{"a.tar": v1, "b.tar": v2}, nil},
+		{map[string]syncStamp{"a.tar": v1, "b.tar": v2}, []string{"b.tar"}},
+		// a changed entry is loaded again
+		{map[string]syncStamp{"a.tar": v2, "b.tar": v2}, nil},
+		{map[string]syncStamp{"a.tar": v2, "b.tar": v2}, []string{"a.tar"}},
+		// a removed entry is loaded again when it comes back
+		{map[string]syncStamp{"b.tar": v2}, nil},
+		{map[string]syncStamp{"a.tar": v2, "b.tar": v2}, nil},
+		{map[string]syncStamp{"a.tar": v2, "b.tar": v2}, []string{"a.tar"}},
+	}
+	for i, st := range steps {
+		if got := s.Observe(st.scan); !reflect.DeepEqual(got, st.want) {
+			t.Errorf("scan %d: Observe() = %v, want %v", i, got, st.want)
+		}
+	}
+}
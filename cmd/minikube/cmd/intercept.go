@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/intercept"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/sshutil"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	interceptTo        string
+	interceptPort      int32
+	interceptNamespace string
+	interceptRestore   bool
+)
+
+// interceptCmd represents the intercept command
+var interceptCmd = &cobra.Command{
+	Use:   "intercept svc/SERVICE --to HOST:PORT",
+	Short: "Route the traffic of an in-cluster service to a process on the host",
+	Long: `Route the traffic of an in-cluster service to a process running on the host, for example a backend under development.
+
+The service is pointed at a relay pod which tunnels connections over SSH to the host process. The service is restored when the command exits. Use --restore to restore a service left intercepted by a killed intercept.`,
+	Example: "minikube intercept svc/backend --to localhost:8080",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			exit.Message(reason.Usage, "Usage: minikube intercept svc/SERVICE --to HOST:PORT")
+		}
+		svc := strings.TrimPrefix(strings.TrimPrefix(args[0], "svc/"), "service/")
+
+		cname := ClusterFlagValue()
+		co := mustload.Healthy(cname)
+		client, err := backgroundClient(cname)
+		if err != nil {
+			exit.Error(reason.InternalKubernetesClient, "error creating clientset", err)
+		}
+
+		if interceptRestore {
+			if err := intercept.Restore(context.Background(), client, interceptNamespace, svc); err != nil {
+				exit.Error(reason.SvcIntercept, "Unable to restore the service", err)
+			}
+			out.Step(style.Check, "Restored service {{.namespace}}/{{.service}}", out.V{"namespace": interceptNamespace, "service": svc})
+			return
+		}
+
+		if interceptTo == "" {
+			exit.Message(reason.Usage, "Please specify where to send the traffic with --to, for example --to localhost:8080")
+		}
+		if _, _, err := net.SplitHostPort(interceptTo); err != nil {
+			exit.Message(reason.Usage, "Invalid --to address {{.to}}: {{.error}}", out.V{"to": interceptTo, "error": err})
+		}
+
+		sshClient, err := sshutil.NewSSHClient(co.CP.Host.Driver)
+		if err != nil {
+			exit.Error(reason.IfSSHClient, "Unable to create an SSH client", err)
+		}
+		defer sshClient.Close()
+
+		// the reverse tunnel: connections to this listener on the node end up at the host process
+		l, err := sshClient.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			exit.Error(reason.SvcIntercept, "Unable to open a reverse tunnel to the node", err)
+		}
+		tunnelPort := l.Addr().(*net.TCPAddr).Port
+		klog.Infof("reverse tunnel listening on node port %d", tunnelPort)
+
+		ctrlC := make(chan os.Signal, 1)
+		signal.Notify(ctrlC, os.Interrupt)
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-ctrlC
+			cancel()
+		}()
+		relayErr := make(chan error, 1)
+		go func() {
+			relayErr <- intercept.Relay(ctx, l, interceptTo)
+		}()
+
+		o := intercept.Options{
+			Namespace:  interceptNamespace,
+			Service:    svc,
+			Port:       interceptPort,
+			NodeName:   config.MachineName(*co.Config, *co.CP.Node),
+			NodeIP:     co.CP.Node.IP,
+			TunnelPort: tunnelPort,
+		}
+		if err := intercept.Redirect(ctx, client, o); err != nil {
+			restoreIntercept(client, svc)
+			exit.Error(reason.SvcIntercept, "Unable to intercept the service", err)
+		}
+		if err := kapi.WaitForPods(client, interceptNamespace, fmt.Sprintf("%s=%s", intercept.Label, svc)); err != nil {
+			restoreIntercept(client, svc)
+			exit.Error(reason.SvcIntercept, "The relay pod did not start", err)
+		}
+
+		out.Step(style.Connectivity, "Traffic to service {{.namespace}}/{{.service}} is now sent to {{.to}}", out.V{"namespace": interceptNamespace, "service": svc, "to": interceptTo})
+		out.Styled(style.Notice, "Press Ctrl-C to restore the service")
+
+		select {
+		case <-ctx.Done():
+		case err := <-relayErr:
+			if err != nil {
+				out.WarningT("The reverse tunnel failed: {{.error}}", out.V{"error": err})
+			}
+		}
+		restoreIntercept(client, svc)
+	},
+}
+
+func restoreIntercept(client kubernetes.Interface, svc string) {
+	if err := intercept.Restore(context.Background(), client, interceptNamespace, svc); err != nil {
+		out.ErrT(style.Failure, "Unable to restore service {{.namespace}}/{{.service}}, run 'minikube intercept svc/{{.service}} --restore': {{.error}}", out.V{"namespace": interceptNamespace, "service": svc, "error": err})
+		return
+	}
+	out.Step(style.Check, "Restored service {{.namespace}}/{{.service}}", out.V{"namespace": interceptNamespace, "service": svc})
+}
+
+func init() {
+	interceptCmd.Flags().StringVar(&interceptTo, "to", "", "Address of the host process to send the traffic to, e.g. localhost:8080")
+	interceptCmd.Flags().Int32Var(&interceptPort, "port", 0, "The service port to intercept. Required if the service has more than one port")
+	interceptCmd.Flags().StringVarP(&interceptNamespace, "namespace", "n", "default", "The namespace of the service")
+	interceptCmd.Flags().BoolVar(&interceptRestore, "restore", false, "Restore a service left intercepted, e.g. after the intercept was killed")
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/kubeconfig"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var kubeconfigRepair bool
+
+// kubeconfigCmd represents the kubeconfig command
+var kubeconfigCmd = &cobra.Command{
+	Use:   "kubeconfig",
+	Short: "Check the kubeconfig entries of a cluster",
+	Long:  "Check the kubeconfig entries of a cluster",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.Message(reason.Usage, "Usage: minikube kubeconfig verify [--repair]")
+	},
+}
+
+// kubeconfigVerifyCmd represents the kubeconfig verify command
+var kubeconfigVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the kubeconfig entries of a cluster against the running cluster",
+	Long: `Verify the kubeconfig entries of a cluster against the running cluster: the API server endpoint and its reachability, the CA and the client certificate.
+
+With --repair, the broken entries are rewritten, which fixes most "Unable to connect to the server" and "x509: certificate signed by unknown authority" errors of kubectl.`,
+	Example: "minikube kubeconfig verify --repair",
+	Run: func(cmd *cobra.Command, args []string) {
+		cname := ClusterFlagValue()
+		co := mustload.Running(cname)
+
+		// the same address as the one start writes, with the --apiserver-name instead of the IP of the node
+		addr := "https://" + net.JoinHostPort(co.CP.Hostname, strconv.Itoa(co.CP.Port))
+		if name := co.Config.KubernetesConfig.APIServerName; name != constants.APIServerName {
+			addr = strings.ReplaceAll(addr, co.CP.Node.IP, name)
+		}
+		kcs := &kubeconfig.Settings{
+			ClusterName:          cname,
+			Namespace:            co.Config.KubernetesConfig.Namespace,
+			ClusterServerAddress: addr,
+			ClientCertificate:    localpath.ClientCert(cname),
+			ClientKey:            localpath.ClientKey(cname),
+			CertificateAuthority: localpath.CACert(),
+			EmbedCerts:           co.Config.EmbedCerts,
+		}
+		path := kubeconfig.PathFromEnv()
+		kcs.SetPath(path)
+
+		problems, err := kubeconfig.Verify(kcs)
+		if err != nil {
+			exit.Error(reason.HostKubeconfigVerify, "Unable to read the kubeconfig", err)
+		}
+		if len(problems) == 0 {
+			out.Step(style.Check, `The "{{.context}}" entries of {{.path}} are valid`, out.V{"context": cname, "path": path})
+			return
+		}
+		for _, p := range problems {
+			out.FailureT("{{.problem}}", out.V{"problem": p})
+		}
+		if !kubeconfigRepair {
+			exit.Message(reason.HostKubeconfigVerify, `The "{{.context}}" entries of {{.path}} are broken, run 'minikube kubeconfig verify --repair' to fix them`, out.V{"context": cname, "path": path})
+		}
+
+		if err := kubeconfig.Repair(kcs); err != nil {
+			exit.Error(reason.HostKubeconfigUpdate, "Unable to repair the kubeconfig", err)
+		}
+		problems, err = kubeconfig.Verify(kcs)
+		if err != nil {
+			exit.Error(reason.HostKubeconfigVerify, "Unable to read the kubeconfig", err)
+		}
+		if len(problems) > 0 {
+			for _, p := range problems {
+				out.FailureT("{{.problem}}", out.V{"problem": p})
+			}
+			exit.Message(reason.HostKubeconfigVerify, `The "{{.context}}" entries of {{.path}} are still broken after the repair, run 'minikube start' to regenerate the certificates of the cluster`, out.V{"context": cname, "path": path})
+		}
+		out.Step(style.Celebrate, `The "{{.context}}" entries of {{.path}} have been repaired`, out.V{"context": cname, "path": path})
+	},
+}
+
+func init() {
+	kubeconfigVerifyCmd.Flags().BoolVar(&kubeconfigRepair, "repair", false, "Rewrite the broken kubeconfig entries of the cluster")
+	kubeconfigCmd.AddCommand(kubeconfigVerifyCmd)
+}
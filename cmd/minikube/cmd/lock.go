@@ -0,0 +1,196 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/lease"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	leaseTTL    time.Duration
+	leaseWait   time.Duration
+	leaseMode   string
+	leaseHolder string
+	leaseForce  bool
+)
+
+// leasePollInterval is how often lock acquire --wait checks whether the lease was released
+const leasePollInterval = 5 * time.Second
+
+// leasedCommands are the commands changing a cluster, which honor its lease. The subcommands of a listed command do too.
+var leasedCommands = []string{
+	"minikube start", "minikube stop", "minikube delete", "minikube reset", "minikube resize", "minikube apply",
+	"minikube pause", "minikube unpause", "minikube throttle", "minikube snapshot create", "minikube restore",
+	"minikube addons enable", "minikube addons disable", "minikube addons configure",
+	"minikube node add", "minikube node delete", "minikube node start", "minikube node stop", "minikube node drain",
+	"minikube image load", "minikube image rm", "minikube image build", "minikube image pull", "minikube image tag",
+	"minikube registry-mirror add", "minikube registry-mirror remove", "minikube workloads move", "minikube dev",
+}
+
+// lockCmd represents the lock command
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Acquire and release the lease of a profile, for the automation jobs sharing a machine",
+	Long: `Leases a profile to an automation job, so that the jobs sharing a machine do not change the same cluster at once.
+
+The lease is recorded in the profile. While it is active, the commands changing the cluster, such as start, stop, delete, addons enable or image load, fail for the other jobs, or only warn with --mode=warn. The holder runs them with the ID of the lease in $MINIKUBE_LEASE. The lease expires after --ttl, so that a job which died does not hold the cluster forever.`,
+}
+
+// lockAcquireCmd represents the lock acquire command
+var lockAcquireCmd = &cobra.Command{
+	Use:   "acquire [PROFILE]",
+	Short: "Acquire the lease of a profile, and print its ID",
+	Long: `Acquires the lease of the profile for --ttl, and prints its ID, to pass to the commands of the holder in $MINIKUBE_LEASE.
+
+With $MINIKUBE_LEASE set to the ID of the active lease, the lease is renewed for --ttl. An active lease of another holder fails the command, unless --wait is set, in which case the command waits for the lease to be released or to expire.`,
+	Example: `export MINIKUBE_LEASE=$(minikube lock acquire ci --ttl 30m --wait 10m)
+minikube start -p ci
+minikube lock release ci`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		profile := lockProfile(args)
+		if leaseTTL <= 0 {
+			exit.Message(reason.Usage, "The --ttl flag must be positive")
+		}
+		if leaseMode != lease.ModeBlock && leaseMode != lease.ModeWarn {
+			exit.Message(reason.Usage, "The --mode flag must be one of: {{.modes}}", out.V{"modes": strings.Join(lease.Modes, ", ")})
+		}
+		holder := leaseHolder
+		if holder == "" {
+			holder = defaultLeaseHolder()
+		}
+
+		deadline := time.Now().Add(leaseWait)
+		waiting := false
+		for {
+			l, err := lease.Acquire(profile, os.Getenv(constants.MinikubeLeaseEnv), holder, leaseMode, leaseTTL, time.Now())
+			if err == nil {
+				out.ErrT(style.Ready, "Acquired the lease of {{.profile}} until {{.expires}}", out.V{"profile": profile, "expires": l.Expires.Format(time.RFC3339)})
+				out.Ln(l.ID)
+				return
+			}
+			var held *lease.HeldError
+			if !errors.As(err, &held) {
+				exit.Error(reason.HostSaveProfile, "Unable to acquire the lease", err)
+			}
+			if !time.Now().Before(deadline) {
+				exit.Message(reason.ProfileLeased, "The profile {{.profile}} is leased by {{.holder}} until {{.expires}}", out.V{"profile": profile, "holder": held.Lease.Holder, "expires": held.Lease.Expires.Format(time.RFC3339)})
+			}
+			if !waiting {
+				waiting = true
+				out.ErrT(style.Waiting, "Waiting for {{.holder}} to release the lease of {{.profile}} ...", out.V{"holder": held.Lease.Holder, "profile": profile})
+			}
+			time.Sleep(leasePollInterval)
+		}
+	},
+}
+
+// lockReleaseCmd represents the lock release command
+var lockReleaseCmd = &cobra.Command{
+	Use:     "release [PROFILE]",
+	Short:   "Release the lease of a profile",
+	Long:    "Releases the lease of the profile, which has to be the one of $MINIKUBE_LEASE unless it expired or --force is set.",
+	Example: "minikube lock release ci",
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		profile := lockProfile(args)
+		err := lease.Release(profile, os.Getenv(constants.MinikubeLeaseEnv), leaseForce, time.Now())
+		var held *lease.HeldError
+		if errors.As(err, &held) {
+			exit.Message(reason.ProfileLeased, "The profile {{.profile}} is leased by {{.holder}} until {{.expires}}, release it with --force", out.V{"profile": profile, "holder": held.Lease.Holder, "expires": held.Lease.Expires.Format(time.RFC3339)})
+		}
+		if err != nil {
+			exit.Error(reason.HostSaveProfile, "Unable to release the lease", err)
+		}
+		out.Step(style.Unpause, "Released the lease of {{.profile}}", out.V{"profile": profile})
+	},
+}
+
+// lockProfile returns the profile of the lock commands, the argument or --profile
+func lockProfile(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return ClusterFlagValue()
+}
+
+// defaultLeaseHolder describes the holder of a lease by the user and the host running the command
+func defaultLeaseHolder() string {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return name
+	}
+	return fmt.Sprintf("%s@%s", name, host)
+}
+
+// isLeasedCommand returns whether the command honors the lease of the profile
+func isLeasedCommand(path string) bool {
+	for _, c := range leasedCommands {
+		if path == c || strings.HasPrefix(path, c+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// enforceLease fails or warns about a command changing the cluster when another holder has the lease of the profile
+func enforceLease(cmd *cobra.Command) {
+	if !isLeasedCommand(cmd.CommandPath()) {
+		return
+	}
+	profile := ClusterFlagValue()
+	l, err := lease.Check(profile, os.Getenv(constants.MinikubeLeaseEnv), time.Now())
+	if err != nil {
+		out.WarningT("Unable to check the lease of {{.profile}}: {{.error}}", out.V{"profile": profile, "error": err})
+		return
+	}
+	if l == nil {
+		return
+	}
+	if l.Mode == lease.ModeWarn {
+		out.WarningT("The profile {{.profile}} is leased by {{.holder}} until {{.expires}}", out.V{"profile": profile, "holder": l.Holder, "expires": l.Expires.Format(time.RFC3339)})
+		return
+	}
+	exit.Message(reason.ProfileLeased, "The profile {{.profile}} is leased by {{.holder}} until {{.expires}}", out.V{"profile": profile, "holder": l.Holder, "expires": l.Expires.Format(time.RFC3339)})
+}
+
+func init() {
+	lockAcquireCmd.Flags().DurationVar(&leaseTTL, "ttl", 30*time.Minute, "How long the lease lasts, unless it is renewed or released")
+	lockAcquireCmd.Flags().DurationVar(&leaseWait, "wait", 0, "How long to wait for the lease of another holder to be released or to expire, 0 to fail at once")
+	lockAcquireCmd.Flags().StringVar(&leaseMode, "mode", lease.ModeBlock, fmt.Sprintf("What the commands changing the cluster do for the other holders: %s", strings.Join(lease.Modes, " or ")))
+	lockAcquireCmd.Flags().StringVar(&leaseHolder, "holder", "", "Description of the holder shown to the other holders, such as the name of the job. Defaults to the user and the host")
+	lockReleaseCmd.Flags().BoolVar(&leaseForce, "force", false, "Release the lease of another holder")
+	lockCmd.AddCommand(lockAcquireCmd)
+	lockCmd.AddCommand(lockReleaseCmd)
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import "testing"
+
+func TestIsLeasedCommand(t *testing.T) {
+	tests := map[string]bool{
+		"minikube start":             true,
+		"minikube addons enable":     true,
+		"minikube dev kubelet":       true,
+		"minikube status":            false,
+		"minikube addons list":       false,
+		"minikube node list":         false,
+		"minikube snapshot list":     false,
+		"minikube lock acquire":      false,
+		"minikube image ls":          false,
+		"minikube startup-something": false,
+	}
+	for path, want := range tests {
+		if got := isLeasedCommand(path); got != want {
+			t.Errorf("isLeasedCommand(%q) = %t, want %t", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/mdns"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	mdnsAddress   string
+	mdnsInterface string
+)
+
+// mdnsCmd represents the mdns command
+var mdnsCmd = &cobra.Command{
+	Use:   "mdns",
+	Short: "Advertise the load balancers and ingresses of the cluster on the local network",
+	Long: `Answers the multicast DNS (Bonjour) queries of the local network for the endpoints of the cluster, kept in sync with it, until Ctrl-C.
+
+A service of type LoadBalancer is advertised as <service>-<namespace>.local once it has an IP, for example with 'minikube tunnel', and an ingress with its hosts ending in .local.
+The names resolve to the IPs of the endpoints inside the network of the interface, as with a bridged network, and the others, which only the host reaches, are not advertised. With --address, they all resolve to that IP instead, for example the one of the host when it forwards the ports to the cluster.`,
+	Example: `minikube mdns
+minikube mdns --address=192.168.1.20 --interface=en0`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cname := ClusterFlagValue()
+		mustload.Healthy(cname)
+
+		var address net.IP
+		if mdnsAddress != "" {
+			if address = net.ParseIP(mdnsAddress); address == nil {
+				exit.Message(reason.Usage, "Sorry, the --address flag is not a valid IP: {{.address}}", out.V{"address": mdnsAddress})
+			}
+		}
+		var iface *net.Interface
+		if mdnsInterface != "" {
+			var err error
+			if iface, err = net.InterfaceByName(mdnsInterface); err != nil {
+				exit.Message(reason.Usage, "Sorry, the --interface flag is not valid: {{.err}}", out.V{"err": err})
+			}
+		}
+
+		client, err := backgroundClient(cname)
+		if err != nil {
+			exit.Error(reason.InternalKubernetesClient, "error creating clientset", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctrlC := make(chan os.Signal, 1)
+		signal.Notify(ctrlC, os.Interrupt)
+		go func() {
+			<-ctrlC
+			cancel()
+		}()
+
+		var lan []*net.IPNet
+		if address == nil {
+			if lan, err = mdns.LANNetworks(iface); err != nil {
+				exit.Error(reason.SvcMDNS, "Unable to find the local network", err)
+			}
+		}
+		r := mdns.NewResponder(address, lan)
+		if err := r.Watch(ctx, client); err != nil {
+			exit.Error(reason.SvcMDNS, "Unable to watch the services and ingresses", err)
+		}
+		for _, n := range r.Names() {
+			out.Infof("Advertising {{.name}}", out.V{"name": strings.TrimSuffix(n, ".")})
+		}
+
+		out.Step(style.Connectivity, "Advertising the load balancers as <service>-<namespace>.local on the local network, press Ctrl-C to stop")
+		if err := r.Serve(ctx, iface); err != nil {
+			exit.Error(reason.SvcMDNS, "Unable to answer the mDNS queries", err)
+		}
+	},
+}
+
+func init() {
+	mdnsCmd.Flags().StringVar(&mdnsAddress, "address", "", "The IP all the names resolve to, rather than the IPs of the endpoints")
+	mdnsCmd.Flags().StringVar(&mdnsInterface, "interface", "", "The network interface to advertise on, the default one if empty")
+}
@@ -32,11 +32,14 @@ import (
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/command"
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/detect"
 	"k8s.io/minikube/pkg/minikube/driver"
 	"k8s.io/minikube/pkg/minikube/exit"
 	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/mountnotify"
+	"k8s.io/minikube/pkg/minikube/mountsync"
 	"k8s.io/minikube/pkg/minikube/mustload"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/reason"
@@ -61,9 +64,13 @@ const (
 	defaultMountPort          = 0
 	mountPortDescription      = "Specify the port that the mount should be setup on, where 0 means any free port."
 	defaultMountType          = nineP
-	mountTypeDescription      = "Specify the mount filesystem type (supported types: 9p)"
+	mountTypeDescription      = "Specify the mount filesystem type (supported types: 9p, virtiofs, sync). virtiofs is only supported by minikube start on the kvm2, qemu2 (on Linux) and vz drivers, sync syncs the directories both ways instead of mounting"
 	defaultMountUID           = "docker"
 	mountUIDDescription       = "Default user id used for the mount"
+	syncIgnoreDescription     = "Patterns of the paths not synced by the sync mount type, such as .git or *.log, matching the names at any depth, or the relative paths if they contain a slash"
+	syncConflictDescription   = "What the sync mount type keeps of a path changed on both sides: the host version, the guest version, or the newer one (host, guest, newer)"
+	readOnlyDescription       = "Do not let the node change the host directory: 9p mounts it read-only, and sync reverts the changes made in the node"
+	forwardEventsDescription  = "Forward the file changes of the host directory to the inotify watchers of the 9p mount in the node, such as hot-reloading development servers, which the node does not notify of them otherwise"
 )
 
 func defaultMountOptions() []string {
@@ -81,6 +88,10 @@ var (
 	gid          string
 	mSize        int
 	options      []string
+	syncIgnore   []string
+	syncConflict string
+	readOnly     bool
+	fwdEvents    bool
 )
 
 // supportedFilesystems is a map of filesystem types to not warn against.
@@ -125,10 +136,25 @@ var mountCmd = &cobra.Command{
 			debugVal = 1 // ufs.StartServer takes int debug param
 		}
 
+		if mountType == constants.VirtiofsMountType {
+			exit.Message(reason.Usage, "virtiofs shares are attached to the VM on its creation, start the cluster with: minikube start --mount --mount-type=virtiofs --mount-string={{.mount}}", out.V{"mount": mountString})
+		}
+
+		if fwdEvents && mountType != nineP {
+			exit.Message(reason.Usage, "--forward-events is only supported by 9p mounts")
+		}
+		if fwdEvents && readOnly {
+			exit.Message(reason.Usage, "--forward-events notifies the changes through the mount, which --read-only does not let the node change")
+		}
+
 		co := mustload.Running(ClusterFlagValue())
 		if co.CP.Host.Driver.DriverName() == driver.None {
 			exit.Message(reason.Usage, `'none' driver does not support 'minikube mount' command`)
 		}
+		if mountType == constants.SyncMountType {
+			runSyncMount(co, hostPath, vmPath)
+			return
+		}
 		if driver.IsQEMU(co.Config.Driver) && pkgnetwork.IsBuiltinQEMU(co.Config.Network) {
 			msg := "minikube mount is not currently implemented with the builtin network on QEMU"
 			if runtime.GOOS == "darwin" {
@@ -185,6 +211,9 @@ var mountCmd = &cobra.Command{
 			parts := strings.Split(o, "=")
 			cfg.Options[parts[0]] = parts[1]
 		}
+		if readOnly {
+			cfg.Options["ro"] = ""
+		}
 
 		// An escape valve to allow future hackers to try NFS, VirtFS, or other FS types.
 		if !supportedFilesystems[cfg.Type] {
@@ -248,6 +277,9 @@ var mountCmd = &cobra.Command{
 		out.Step(style.Success, "Successfully mounted {{.sourcePath}} to {{.destinationPath}}", out.V{"sourcePath": hostPath, "destinationPath": vmPath})
 		out.Ln("")
 		out.Styled(style.Notice, "NOTE: This process must stay alive for the mount to be accessible ...")
+		if fwdEvents {
+			forwardMountEvents(co.CP.Runner, hostPath, vmPath)
+		}
 		wg.Wait()
 	},
 }
@@ -262,6 +294,26 @@ func init() {
 	mountCmd.Flags().StringVar(&gid, constants.MountGIDFlag, defaultMountGID, mountGIDDescription)
 	mountCmd.Flags().StringSliceVar(&options, constants.MountOptionsFlag, defaultMountOptions(), mountOptionsDescription)
 	mountCmd.Flags().IntVar(&mSize, constants.MountMSizeFlag, defaultMountMSize, mountMSizeDescription)
+	mountCmd.Flags().StringSliceVar(&syncIgnore, constants.MountSyncIgnoreFlag, []string{}, syncIgnoreDescription)
+	mountCmd.Flags().StringVar(&syncConflict, constants.MountSyncConflictFlag, mountsync.ConflictHost, syncConflictDescription)
+	mountCmd.Flags().BoolVar(&readOnly, constants.MountReadOnlyFlag, false, readOnlyDescription)
+	mountCmd.Flags().BoolVar(&fwdEvents, constants.MountForwardEventsFlag, false, forwardEventsDescription)
+}
+
+// forwardMountEvents notifies the watchers of vmPath in the node of the changes of hostPath, as long as the mount process runs
+func forwardMountEvents(r command.Runner, hostPath, vmPath string) {
+	w, err := mountnotify.NewWatcher(hostPath)
+	if err != nil {
+		out.WarningT("Unable to forward the file changes of {{.path}}: {{.error}}", out.V{"path": hostPath, "error": err})
+		return
+	}
+	injector := mountnotify.NewInjector(r, vmPath)
+	go w.Run(func(changes []mountnotify.Change) {
+		if err := injector.Inject(changes); err != nil {
+			klog.Warningf("forwarding the changes of %s: %v", hostPath, err)
+		}
+	}, make(chan struct{}))
+	out.Infof("Forwarding the file changes of {{.path}} to the node", out.V{"path": hostPath})
 }
 
 // getPort uses the requested port or asks the kernel for a free open port that is ready to use
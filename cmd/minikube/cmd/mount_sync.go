@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/mountsync"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/util/lock"
+)
+
+// syncMountInterval is the interval between the sync cycles of the sync mount type
+const syncMountInterval = 2 * time.Second
+
+// runSyncMount syncs hostPath and vmPath of the control plane both ways until the process is interrupted, as the sync mount type
+func runSyncMount(co mustload.ClusterController, hostPath, vmPath string) {
+	opts := mountsync.Options{Ignore: syncIgnore, Conflict: syncConflict, UID: uid, GID: gid, ReadOnly: readOnly}
+	if err := opts.Validate(); err != nil {
+		exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+	}
+	hostPath, err := filepath.Abs(hostPath)
+	if err != nil {
+		exit.Error(reason.HostPathStat, "resolving the host path", err)
+	}
+
+	out.Step(style.Mounting, "Syncing host path {{.sourcePath}} with {{.destinationPath}} in the node ...", out.V{"sourcePath": hostPath, "destinationPath": vmPath})
+	out.Infof("Mount type:   {{.name}}", out.V{"name": constants.SyncMountType})
+	out.Infof("User ID:      {{.userID}}", out.V{"userID": opts.UID})
+	out.Infof("Group ID:     {{.groupID}}", out.V{"groupID": opts.GID})
+	out.Infof("Conflicts:    {{.policy}}", out.V{"policy": opts.Conflict})
+	out.Infof("Ignored:      {{.ignore}}", out.V{"ignore": strings.Join(opts.Ignore, ", ")})
+	out.Infof("Read-only:    {{.readOnly}}", out.V{"readOnly": opts.ReadOnly})
+
+	pid := os.Getpid()
+	if err := lock.AppendToFile(filepath.Join(localpath.Profile(co.Config.Name), constants.MountProcessFileName), []byte(fmt.Sprintf(" %d", pid)), 0o644); err != nil {
+		exit.Error(reason.HostMountPid, "Error writing mount pid", err)
+	}
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+
+	s := mountsync.New(co.CP.Runner, hostPath, vmPath, opts)
+	ticker := time.NewTicker(syncMountInterval)
+	defer ticker.Stop()
+	synced := false
+	for {
+		stats, err := s.Sync()
+		switch {
+		case err != nil:
+			out.FailureT("Failed to sync {{.path}}: {{.error}}", out.V{"path": hostPath, "error": err})
+		case !synced:
+			synced = true
+			out.Step(style.Success, "Successfully synced {{.sourcePath}} with {{.destinationPath}}", out.V{"sourcePath": hostPath, "destinationPath": vmPath})
+			out.Ln("")
+			out.Styled(style.Notice, "NOTE: This process must stay alive for the directories to be synced ...")
+		case stats.ToGuest+stats.ToHost+stats.Removed > 0:
+			out.Infof("Synced {{.toGuest}} paths to the node, {{.toHost}} paths to the host, removed {{.removed}} paths", out.V{"toGuest": stats.ToGuest, "toHost": stats.ToHost, "removed": stats.Removed})
+		}
+		for _, p := range stats.Conflicts {
+			out.WarningT("{{.path}} changed on both sides, kept the {{.policy}} version", out.V{"path": p, "policy": opts.Conflict})
+		}
+
+		select {
+		case sig := <-c:
+			if err := removePidFromFile(pid); err != nil {
+				out.FailureT("Failed removing pid from pidfile: {{.error}}", out.V{"error": err})
+			}
+			exit.Message(reason.Interrupted, "Received {{.name}} signal", out.V{"name": sig})
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/netem"
+	"k8s.io/minikube/pkg/minikube/node"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	networkNode    string
+	networkLatency time.Duration
+	networkJitter  time.Duration
+	networkLoss    string
+)
+
+// networkCmd represents the network command
+var networkCmd = &cobra.Command{
+	Use:   "network",
+	Short: "Impair and restore the network of the nodes",
+	Long:  "Impairs the network of the nodes with latency and packet loss, to test workloads against slow and flaky networks, and restores it.",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.Message(reason.Usage, "Usage: minikube network [impair|restore]")
+	},
+}
+
+// networkImpairCmd represents the network impair command
+var networkImpairCmd = &cobra.Command{
+	Use:   "impair",
+	Short: "Adds latency and packet loss to the network of the nodes",
+	Long: `Adds latency and packet loss to the traffic leaving the cluster network interface of the nodes, with the netem queueing discipline of tc.
+The impairment applies to the traffic between the nodes, and from the nodes to the host, and lasts until 'minikube network restore' or the node restarts.`,
+	Example: `minikube network impair --node m02 --latency 200ms --loss 5%
+minikube network impair --latency 100ms --jitter 20ms`,
+	Run: func(cmd *cobra.Command, args []string) {
+		impairment := netem.Impairment{Latency: networkLatency, Jitter: networkJitter}
+		if networkLoss != "" {
+			loss, err := netem.ParsePercent(networkLoss)
+			if err != nil {
+				exit.Message(reason.Usage, "Sorry, the --loss flag is not valid: {{.err}}", out.V{"err": err})
+			}
+			impairment.Loss = loss
+		}
+		if err := impairment.Validate(); err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+
+		co := mustload.Running(ClusterFlagValue())
+		for _, n := range networkNodes(co) {
+			err := onNodeNetwork(co, n, func(r command.Runner, dev string) error {
+				return netem.Impair(r, dev, impairment)
+			})
+			if err != nil {
+				exit.Error(reason.GuestNetworkImpair, "Failed to impair the network", err)
+			}
+			out.Step(style.Connectivity, "Impaired the network of {{.node}} with {{.impairment}}", out.V{"node": n.Name, "impairment": impairment})
+		}
+	},
+}
+
+// networkRestoreCmd represents the network restore command
+var networkRestoreCmd = &cobra.Command{
+	Use:     "restore",
+	Short:   "Removes the impairments of the network of the nodes",
+	Long:    "Removes the latency and packet loss added by 'minikube network impair' to the network of the nodes.",
+	Example: `minikube network restore --node m02`,
+	Run: func(cmd *cobra.Command, args []string) {
+		co := mustload.Running(ClusterFlagValue())
+		for _, n := range networkNodes(co) {
+			if err := onNodeNetwork(co, n, netem.Restore); err != nil {
+				exit.Error(reason.GuestNetworkImpair, "Failed to restore the network", err)
+			}
+			out.Step(style.Ready, "Restored the network of {{.node}}", out.V{"node": n.Name})
+		}
+	},
+}
+
+// networkNodes returns the node of the --node flag, or all the nodes when it is not set
+func networkNodes(co mustload.ClusterController) []config.Node {
+	if networkNode == "" {
+		return co.Config.Nodes
+	}
+	n, _, err := node.Retrieve(*co.Config, networkNode)
+	if err != nil {
+		exit.Message(reason.GuestNodeRetrieve, "Node {{.nodeName}} does not exist.", out.V{"nodeName": networkNode})
+	}
+	return []config.Node{*n}
+}
+
+// onNodeNetwork runs fn with a command runner of the node, and its network interface holding the IP of the node
+func onNodeNetwork(co mustload.ClusterController, n config.Node, fn func(command.Runner, string) error) error {
+	h, err := machine.GetHost(co.API, *co.Config, n)
+	if err != nil {
+		return errors.Wrap(err, "getting host")
+	}
+	r, err := machine.CommandRunner(h)
+	if err != nil {
+		return errors.Wrap(err, "getting command runner")
+	}
+	dev, err := netem.Device(r, n.IP)
+	if err != nil {
+		return err
+	}
+	return fn(r, dev)
+}
+
+func init() {
+	networkImpairCmd.Flags().StringVarP(&networkNode, "node", "n", "", "The node to impair. Defaults to all the nodes.")
+	networkImpairCmd.Flags().DurationVar(&networkLatency, "latency", 0, "Delay added to the packets leaving the nodes, for example 200ms")
+	networkImpairCmd.Flags().DurationVar(&networkJitter, "jitter", 0, "Random variation of the latency, for example 20ms")
+	networkImpairCmd.Flags().StringVar(&networkLoss, "loss", "", "Percent of the packets leaving the nodes dropped, for example 5%")
+	networkRestoreCmd.Flags().StringVarP(&networkNode, "node", "n", "", "The node to restore. Defaults to all the nodes.")
+	networkCmd.AddCommand(networkImpairCmd)
+	networkCmd.AddCommand(networkRestoreCmd)
+}
@@ -17,10 +17,21 @@ limitations under the License.
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
 	"k8s.io/minikube/pkg/minikube/cni"
 	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/download"
 	"k8s.io/minikube/pkg/minikube/driver"
 	"k8s.io/minikube/pkg/minikube/exit"
 	"k8s.io/minikube/pkg/minikube/mustload"
@@ -29,17 +40,40 @@ import (
 	"k8s.io/minikube/pkg/minikube/out/register"
 	"k8s.io/minikube/pkg/minikube/reason"
 	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/windows"
+	"k8s.io/minikube/pkg/util"
 )
 
 var (
-	cp     bool
-	worker bool
+	cp         bool
+	worker     bool
+	nodeCount  int
+	poolName   string
+	poolCPUs   int
+	poolMemory string
+	poolLabels []string
+	poolTaints []string
+
+	nodeKubeletConfig []string
+	nodeDriver        string
+
+	nodeOS       string
+	windowsImage string
+	windowsUser  string
 )
 
+// taintRegexp matches the KEY[=VALUE]:EFFECT taints of the kubelet
+var taintRegexp = regexp.MustCompile(`^[^=:,]+(=[^=:,]*)?:(NoSchedule|PreferNoSchedule|NoExecute)$`)
+
 var nodeAddCmd = &cobra.Command{
 	Use:   "add",
 	Short: "Adds a node to the given cluster.",
-	Long:  "Adds a node to the given cluster config, and starts it.",
+	Long: `Adds a node to the given cluster config, and starts it.
+With --pool, the node joins a node pool: the nodes of a pool share their CPUs, memory, labels and taints. The pool is created with the settings of the flags when it does not exist yet.`,
+	Example: `minikube node add --pool workers --cpus 4 --memory 8g --count 3
+minikube node add --pool gpu --labels accelerator=nvidia --taints nvidia.com/gpu=present:NoSchedule
+minikube node add --kubelet-config maxPods=20,evictionHard.memory.available=500Mi,featureGates.InPlacePodVerticalScaling=true
+MINIKUBE_WINDOWS_PASSWORD=<password> minikube node add --os windows --windows-image ./windows-server-2022.vhdx`,
 	Run: func(cmd *cobra.Command, args []string) {
 		co := mustload.Healthy(ClusterFlagValue())
 		cc := co.Config
@@ -47,56 +81,207 @@ var nodeAddCmd = &cobra.Command{
 		if driver.BareMetal(cc.Driver) {
 			out.FailureT("none driver does not support multi-node clusters")
 		}
+		if driver.IsWSL(cc.Driver) {
+			out.FailureT("wsl driver does not support multi-node clusters")
+		}
 
-		name := node.Name(len(cc.Nodes) + 1)
-
-		// for now control-plane feature is not supported
-		if cp {
-			out.Step(style.Unsupported, "Adding a control-plane node is not yet supported, setting control-plane flag to false")
+		// the apiservers are only reachable through a single endpoint behind the virtual IP of a highly available cluster
+		if cp && !config.IsHA(*cc) {
+			out.Step(style.Unsupported, "Adding a control-plane node is only supported for clusters started with --ha or --control-planes, setting control-plane flag to false")
 			cp = false
 		}
 
-		out.Step(style.Happy, "Adding node {{.name}} to cluster {{.cluster}}", out.V{"name": name, "cluster": cc.Name})
-		// TODO: Deal with parameters better. Ideally we should be able to acceot any node-specific minikube start params here.
-		n := config.Node{
-			Name:              name,
-			Worker:            worker,
-			ControlPlane:      cp,
-			KubernetesVersion: cc.KubernetesConfig.KubernetesVersion,
+		if nodeCount < 1 {
+			exit.Message(reason.Usage, "--count must be at least 1")
 		}
-
-		// Make sure to decrease the default amount of memory we use per VM if this is the first worker node
-		if len(cc.Nodes) == 1 {
-			if viper.GetString(memory) == "" {
-				cc.Memory = 2200
+		if nodeOS != "linux" {
+			if err := validateWindowsNode(cc.Driver); err != nil {
+				exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
 			}
-
-			if !cc.MultiNodeRequested || cni.IsDisabled(*cc) {
-				warnAboutMultiNodeCNI()
+			out.Step(style.Unsupported, "Windows nodes are experimental: pods are scheduled on them, but the pod network needs a CNI supporting Windows")
+		}
+		if nodeDriver != "" && nodeDriver != cc.Driver {
+			if err := node.ValidateHybrid(*cc, nodeDriver, runtime.GOOS); err != nil {
+				exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+			}
+			if driver.IsVM(nodeDriver) && cc.MinikubeISO == "" {
+				url, err := download.ISO(download.DefaultISOURLs(), false)
+				if err != nil {
+					exit.Error(reason.InetCacheISO, "Failed to cache ISO", err)
+				}
+				cc.MinikubeISO = url
 			}
+		} else {
+			nodeDriver = ""
 		}
-
-		register.Reg.SetStep(register.InitialSetup)
-		if err := node.Add(cc, n, false); err != nil {
-			_, err := maybeDeleteAndRetry(cmd, *cc, n, nil, err)
-			if err != nil {
-				exit.Error(reason.GuestNodeAdd, "failed to add node", err)
+		if len(nodeKubeletConfig) > 0 {
+			if err := validateNodeKubeletConfig(cc.KubernetesConfig.KubernetesVersion, nodeKubeletConfig); err != nil {
+				exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
 			}
 		}
-
-		if err := config.SaveProfile(cc.Name, cc); err != nil {
-			exit.Error(reason.HostSaveProfile, "failed to save config", err)
+		poolFlags := cmd.Flags().Changed("cpus") || cmd.Flags().Changed("memory") || cmd.Flags().Changed("labels") || cmd.Flags().Changed("taints")
+		if poolName == "" && poolFlags {
+			exit.Message(reason.Usage, "--cpus, --memory, --labels and --taints are the settings of a node pool, use them with --pool")
+		}
+		if poolName != "" {
+			if pool := config.NodePoolByName(*cc, poolName); pool != nil {
+				if poolFlags {
+					exit.Message(reason.Usage, "The node pool {{.pool}} already exists, the settings of its nodes can not be changed", out.V{"pool": poolName})
+				}
+			} else {
+				pool, err := newNodePool(poolName, poolCPUs, poolMemory, poolLabels, poolTaints)
+				if err != nil {
+					exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+				}
+				if (pool.CPUs != 0 || pool.Memory != 0) && !driver.HasResourceLimits(cc.Driver) {
+					out.WarningT("The '{{.name}}' driver does not respect the --cpus and --memory flags", out.V{"name": cc.Driver})
+				}
+				out.Step(style.Happy, "Creating node pool {{.pool}} in cluster {{.cluster}}", out.V{"pool": poolName, "cluster": cc.Name})
+				cc.NodePools = append(cc.NodePools, pool)
+			}
 		}
 
-		out.Step(style.Ready, "Successfully added {{.name}} to {{.cluster}}!", out.V{"name": name, "cluster": cc.Name})
+		for i := 0; i < nodeCount; i++ {
+			addNode(cmd, cc)
+		}
 	},
 }
 
+// addNode adds a node to the cluster, in the node pool of --pool if any
+func addNode(cmd *cobra.Command, cc *config.ClusterConfig) {
+	name := node.Name(len(cc.Nodes) + 1)
+
+	out.Step(style.Happy, "Adding node {{.name}} to cluster {{.cluster}}", out.V{"name": name, "cluster": cc.Name})
+	// TODO: Deal with parameters better. Ideally we should be able to acceot any node-specific minikube start params here.
+	n := config.Node{
+		Name:              name,
+		Worker:            worker,
+		ControlPlane:      cp,
+		KubernetesVersion: cc.KubernetesConfig.KubernetesVersion,
+		Pool:              poolName,
+		KubeletConfig:     nodeKubeletConfig,
+		Driver:            nodeDriver,
+	}
+	if cp {
+		pcp, err := config.PrimaryControlPlane(cc)
+		if err != nil {
+			exit.Error(reason.GuestCpConfig, "Unable to get control-plane node", err)
+		}
+		n.Port = pcp.Port
+	}
+
+	// Make sure to decrease the default amount of memory we use per VM if this is the first worker node
+	if len(cc.Nodes) == 1 {
+		if viper.GetString(memory) == "" {
+			cc.Memory = 2200
+		}
+
+		if !cc.MultiNodeRequested || cni.IsDisabled(*cc) {
+			warnAboutMultiNodeCNI()
+		}
+	}
+
+	register.Reg.SetStep(register.InitialSetup)
+	if nodeOS == config.WindowsOS {
+		if err := node.AddWindows(cc, n, windowsImage, windowsUser, os.Getenv(constants.MinikubeWindowsPasswordEnv)); err != nil {
+			exit.Error(reason.GuestNodeAdd, "failed to add Windows node", err)
+		}
+	} else if err := node.Add(cc, n, false); err != nil {
+		_, err := maybeDeleteAndRetry(cmd, *cc, n, nil, err)
+		if err != nil {
+			exit.Error(reason.GuestNodeAdd, "failed to add node", err)
+		}
+	}
+
+	if err := config.SaveProfile(cc.Name, cc); err != nil {
+		exit.Error(reason.HostSaveProfile, "failed to save config", err)
+	}
+
+	out.Step(style.Ready, "Successfully added {{.name}} to {{.cluster}}!", out.V{"name": name, "cluster": cc.Name})
+}
+
+// validateNodeKubeletConfig checks the kubelet config overrides of a node, applied by kubeadm join patches of the kubeletconfiguration target
+func validateNodeKubeletConfig(k8sVersion string, overrides []string) error {
+	v, err := util.ParseKubernetesVersion(k8sVersion)
+	if err != nil {
+		return errors.Wrap(err, "parsing Kubernetes version")
+	}
+	if v.LT(semver.MustParse("1.25.0")) {
+		return fmt.Errorf("--kubelet-config requires Kubernetes v1.25.0 or later, the cluster runs %s", k8sVersion)
+	}
+	_, err = bsutil.KubeletConfigPatch(config.Node{KubeletConfig: overrides})
+	return err
+}
+
+// validateWindowsNode checks the flags of an experimental Windows node
+func validateWindowsNode(drv string) error {
+	if nodeOS != config.WindowsOS {
+		return fmt.Errorf("invalid --os %q, expected linux or windows", nodeOS)
+	}
+	if !windows.Supported(drv) {
+		return fmt.Errorf("Windows nodes need a cluster on the hyperv or virtualbox driver, not %s", drv)
+	}
+	if cp || poolName != "" || nodeDriver != "" || len(nodeKubeletConfig) > 0 {
+		return fmt.Errorf("Windows nodes are workers, --control-plane, --pool, --driver and --kubelet-config do not apply to them")
+	}
+	if windowsImage == "" {
+		return fmt.Errorf("Windows nodes need --windows-image")
+	}
+	if _, err := os.Stat(windowsImage); err != nil {
+		return errors.Wrap(err, "Windows image")
+	}
+	if os.Getenv(constants.MinikubeWindowsPasswordEnv) == "" {
+		return fmt.Errorf("Windows nodes need the password of --windows-user in the %s environment variable", constants.MinikubeWindowsPasswordEnv)
+	}
+	return nil
+}
+
+// newNodePool returns a node pool with the settings of the flags, where 0 CPUs or memory keeps those of the cluster
+func newNodePool(name string, cpus int, memory string, labels, taints []string) (config.NodePool, error) {
+	pool := config.NodePool{Name: name, CPUs: cpus, Labels: labels, Taints: taints}
+	if cpus != 0 && cpus < minimumCPUS {
+		return pool, fmt.Errorf("the node pool %s needs at least %d CPUs, got %d", name, minimumCPUS, cpus)
+	}
+	if memory != "" {
+		mem, err := util.CalculateSizeInMB(memory)
+		if err != nil {
+			return pool, errors.Wrapf(err, "invalid memory %q of the node pool %s", memory, name)
+		}
+		if mem < minUsableMem {
+			return pool, fmt.Errorf("the node pool %s needs at least %dMB of memory, got %dMB", name, minUsableMem, mem)
+		}
+		pool.Memory = mem
+	}
+	for _, l := range labels {
+		if k, _, ok := strings.Cut(l, "="); !ok || k == "" {
+			return pool, fmt.Errorf("invalid label %q of the node pool %s, expected KEY=VALUE", l, name)
+		}
+	}
+	for _, t := range taints {
+		if !taintRegexp.MatchString(t) {
+			return pool, fmt.Errorf("invalid taint %q of the node pool %s, expected KEY[=VALUE]:EFFECT with NoSchedule, PreferNoSchedule or NoExecute", t, name)
+		}
+	}
+	return pool, nil
+}
+
 func init() {
 	// TODO(https://github.com/kubernetes/minikube/issues/7366): We should figure out which minikube start flags to actually import
-	nodeAddCmd.Flags().BoolVar(&cp, "control-plane", false, "This flag is currently unsupported.")
+	nodeAddCmd.Flags().BoolVar(&cp, "control-plane", false, "If set, the added node will be a control plane of the highly available cluster. Defaults to false.")
 	nodeAddCmd.Flags().BoolVar(&worker, "worker", true, "If true, the added node will be marked for work. Defaults to true.")
 	nodeAddCmd.Flags().Bool(deleteOnFailure, false, "If set, delete the current cluster if start fails and try again. Defaults to false.")
+	nodeAddCmd.Flags().IntVar(&nodeCount, "count", 1, "The number of nodes to add.")
+	nodeAddCmd.Flags().StringVar(&poolName, "pool", "", "The node pool of the added nodes, created with the settings of --cpus, --memory, --labels and --taints if it does not exist.")
+	nodeAddCmd.Flags().IntVar(&poolCPUs, "cpus", 0, "Number of CPUs of the nodes of a new node pool. Defaults to the CPUs of the cluster.")
+	nodeAddCmd.Flags().StringVar(&poolMemory, "memory", "", "Amount of RAM of the nodes of a new node pool, in the format <number>[<unit>], where unit = b, k, m or g. Defaults to the memory of the cluster.")
+	nodeAddCmd.Flags().StringSliceVar(&poolLabels, "labels", nil, "Labels of the nodes of a new node pool, formatted as KEY=VALUE.")
+	nodeAddCmd.Flags().StringSliceVar(&poolTaints, "taints", nil, "Taints of the nodes of a new node pool, formatted as KEY[=VALUE]:EFFECT.")
+	nodeAddCmd.Flags().StringVar(&nodeDriver, "driver", "", "The driver of the added nodes, when it differs from the one of the cluster: a cluster on the kvm2 driver can have nodes on the docker or podman driver, and the other way around (Linux only). The host forwards the traffic between the networks of the drivers with sudo iptables, and the cluster needs --cni=flannel, calico or cilium.")
+	nodeAddCmd.Flags().StringSliceVar(&nodeKubeletConfig, "kubelet-config", nil, "Overrides of the KubeletConfiguration of the added nodes, formatted as FIELD=VALUE or FIELD.KEY=VALUE for the map fields, for example maxPods=20,evictionHard.memory.available=500Mi,featureGates.InPlacePodVerticalScaling=true. Requires Kubernetes v1.25.0 or later.")
+
+	nodeAddCmd.Flags().StringVar(&nodeOS, "os", "linux", "The operating system of the added nodes, linux or windows. Windows nodes are experimental: they need a cluster on the hyperv or virtualbox driver, and are created from --windows-image.")
+	nodeAddCmd.Flags().StringVar(&windowsImage, "windows-image", "", "The disk image of the Windows nodes: a sysprepped Windows Server 2019 or 2022 VHDX (hyperv) or VDI with the guest additions (virtualbox), with the Containers feature enabled.")
+	nodeAddCmd.Flags().StringVar(&windowsUser, "windows-user", "Administrator", "The administrator of --windows-image, whose password is read from the MINIKUBE_WINDOWS_PASSWORD environment variable.")
 
 	nodeCmd.AddCommand(nodeAddCmd)
 }
@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+)
+
+func TestNewNodePool(t *testing.T) {
+	tests := []struct {
+		description string
+		cpus        int
+		memory      string
+		labels      []string
+		taints      []string
+		wantMemory  int
+		wantErr     bool
+	}{
+		{description: "inherit cluster resources"},
+		{description: "resources", cpus: 4, memory: "8g", wantMemory: 8192},
+		{description: "labels and taints", labels: []string{"tier=backend", "accelerator="}, taints: []string{"dedicated=gpu:NoSchedule", "spot:PreferNoSchedule"}},
+		{description: "too few cpus", cpus: 1, wantErr: true},
+		{description: "too little memory", memory: "100m", wantErr: true},
+		{description: "invalid memory", memory: "lots", wantErr: true},
+		{description: "label without value", labels: []string{"tier"}, wantErr: true},
+		{description: "taint without effect", taints: []string{"dedicated=gpu"}, wantErr: true},
+		{description: "taint with unknown effect", taints: []string{"dedicated=gpu:Always"}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			pool, err := newNodePool("workers", tc.cpus, tc.memory, tc.labels, tc.taints)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("newNodePool() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if pool.Name != "workers" || pool.CPUs != tc.cpus || pool.Memory != tc.wantMemory {
+				t.Errorf("newNodePool() = %+v, want %d CPUs and %dMB of memory", pool, tc.cpus, tc.wantMemory)
+			}
+		})
+	}
+}
+
+func TestValidateNodeKubeletConfig(t *testing.T) {
+	tests := []struct {
+		version   string
+		overrides []string
+		valid     bool
+	}{
+		{"v1.30.0", []string{"maxPods=20", "featureGates.InPlacePodVerticalScaling=true"}, true},
+		{"v1.24.17", []string{"maxPods=20"}, false},
+		{"v1.30.0", []string{"max-pods=20"}, false},
+	}
+	for _, tc := range tests {
+		err := validateNodeKubeletConfig(tc.version, tc.overrides)
+		if (err == nil) != tc.valid {
+			t.Errorf("validateNodeKubeletConfig(%s, %v) = %v, want valid = %t", tc.version, tc.overrides, err, tc.valid)
+		}
+	}
+}
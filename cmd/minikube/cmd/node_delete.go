@@ -35,7 +35,8 @@ import (
 var nodeDeleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Deletes a node from a cluster.",
-	Long:  "Deletes a node from a cluster.",
+	Long: `Deletes a node from a cluster.
+The pods of the node are deleted without waiting for their eviction: drain the node first with 'minikube node drain' to evict them gracefully.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if len(args) == 0 {
 			exit.Message(reason.Usage, "Usage: minikube node delete [name]")
@@ -43,22 +44,27 @@ var nodeDeleteCmd = &cobra.Command{
 		name := args[0]
 
 		co := mustload.Healthy(ClusterFlagValue())
-		out.Step(style.DeletingHost, "Deleting node {{.name}} from cluster {{.cluster}}", out.V{"name": name, "cluster": co.Config.Name})
+		deleteNode(co.Config, name)
+	},
+}
 
-		n, err := node.Delete(*co.Config, name)
-		if err != nil {
-			exit.Error(reason.GuestNodeDelete, "deleting node", err)
-		}
+// deleteNode deletes the node name from the cluster cc
+func deleteNode(cc *config.ClusterConfig, name string) {
+	out.Step(style.DeletingHost, "Deleting node {{.name}} from cluster {{.cluster}}", out.V{"name": name, "cluster": cc.Name})
 
-		if driver.IsKIC(co.Config.Driver) {
-			machineName := config.MachineName(*co.Config, *n)
-			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-			defer cancel()
-			delete.PossibleLeftOvers(ctx, machineName, co.Config.Driver)
-		}
+	n, err := node.Delete(*cc, name)
+	if err != nil {
+		exit.Error(reason.GuestNodeDelete, "deleting node", err)
+	}
 
-		out.Step(style.Deleted, "Node {{.name}} was successfully deleted.", out.V{"name": name})
-	},
+	if driver.IsKIC(cc.Driver) {
+		machineName := config.MachineName(*cc, *n)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		delete.PossibleLeftOvers(ctx, machineName, cc.Driver)
+	}
+
+	out.Step(style.Deleted, "Node {{.name}} was successfully deleted.", out.V{"name": name})
 }
 
 func init() {
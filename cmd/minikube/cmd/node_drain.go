@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/node"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	drainGracePeriod int
+	drainForce       bool
+	drainTimeout     time.Duration
+)
+
+var nodeDrainCmd = &cobra.Command{
+	Use:   "drain",
+	Short: "Drains a node of a cluster before deleting it.",
+	Long: `Cordons a node, and evicts its pods through the eviction API, honoring their PodDisruptionBudgets.
+The pods of DaemonSets and the static pods stay on the node. The pods not managed by a controller are only evicted with --force, as they are not recreated on another node.
+The node stays cordoned: delete it with 'minikube node delete', or make it schedulable again with 'kubectl uncordon'.`,
+	Example: `minikube node drain m02
+minikube node drain m02 --grace-period 10 --force`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			exit.Message(reason.Usage, "Usage: minikube node drain [name]")
+		}
+		name := args[0]
+		if drainTimeout <= 0 {
+			exit.Message(reason.Usage, "--timeout must be positive")
+		}
+
+		co := mustload.Healthy(ClusterFlagValue())
+		out.Step(style.Waiting, "Draining node {{.name}} of cluster {{.cluster}} ...", out.V{"name": name, "cluster": co.Config.Name})
+
+		opts := node.DrainOptions{GracePeriod: drainGracePeriod, Force: drainForce, Timeout: drainTimeout}
+		if err := node.Drain(*co.Config, name, opts); err != nil {
+			exit.Error(reason.GuestNodeDrain, "draining node", err)
+		}
+		out.Step(style.Ready, "Node {{.name}} was successfully drained.", out.V{"name": name})
+	},
+}
+
+func init() {
+	nodeDrainCmd.Flags().IntVar(&drainGracePeriod, "grace-period", -1, "Seconds the evicted pods have to terminate. If negative, the termination grace period of each pod is used.")
+	nodeDrainCmd.Flags().BoolVar(&drainForce, "force", false, "If set, also evict the pods not managed by a controller, which are not recreated on another node.")
+	nodeDrainCmd.Flags().DurationVar(&drainTimeout, "timeout", 5*time.Minute, "How long to wait for the pods to be evicted.")
+	nodeCmd.AddCommand(nodeDrainCmd)
+}
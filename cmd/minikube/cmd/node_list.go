@@ -19,6 +19,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"k8s.io/klog/v2"
@@ -26,6 +27,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/exit"
 	"k8s.io/minikube/pkg/minikube/mustload"
 	"k8s.io/minikube/pkg/minikube/reason"
+	netutil "k8s.io/minikube/pkg/network"
 )
 
 var nodeListCmd = &cobra.Command{
@@ -46,8 +48,23 @@ var nodeListCmd = &cobra.Command{
 			klog.Infof("%v", cc.Nodes)
 		}
 
+		extraNets, err := netutil.ParseExtraNetworks(cc.ExtraNetworks)
+		if err != nil {
+			klog.Warningf("invalid extra networks %v: %v", cc.ExtraNetworks, err)
+		}
 		for _, n := range cc.Nodes {
 			machineName := config.MachineName(*cc, n)
+			// the IPs on the extra networks follow, like storage=192.168.58.2
+			var extraIPs []string
+			for _, en := range extraNets {
+				if ip, ok := n.ExtraIPs[en.Name]; ok {
+					extraIPs = append(extraIPs, fmt.Sprintf("%s=%s", en.Name, ip))
+				}
+			}
+			if len(extraIPs) > 0 {
+				fmt.Printf("%s\t%s\t%s\n", machineName, n.IP, strings.Join(extraIPs, ","))
+				continue
+			}
 			fmt.Printf("%s\t%s\n", machineName, n.IP)
 		}
 		os.Exit(0)
@@ -62,6 +62,8 @@ func runPause(_ *cobra.Command, _ []string) {
 		exit.Message(reason.Usage, "Use -A to specify all namespaces")
 	}
 
+	holdWorkloads(co.Config.Name, namespaces)
+
 	ids := []string{}
 
 	for _, n := range co.Config.Nodes {
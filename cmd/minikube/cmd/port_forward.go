@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/portforward"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// portForwardCmd represents the port-forward command
+var portForwardCmd = &cobra.Command{
+	Use:   "port-forward",
+	Short: "Forward the ports declared with 'minikube start --port-forward' to the host",
+	Long: `Forwards the ports of services and pods declared with 'minikube start --port-forward' to 127.0.0.1 of the host, until Ctrl-C.
+
+'minikube start' runs it in the background, and 'minikube stop' and 'minikube delete' stop it. A forward moves to another ready pod when its pod goes away, and is re-established when the cluster comes back.`,
+	Example: `minikube start --port-forward=svc/web:8080:80 --port-forward=monitoring/pod/app=grafana:3000:3000
+minikube port-forward`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cname := ClusterFlagValue()
+		co := mustload.Healthy(cname)
+		if len(co.Config.PortForwards) == 0 {
+			out.Styled(style.Notice, "No port forwards are declared, add some with: minikube start --port-forward=[NAMESPACE/]svc/NAME:HOSTPORT:PORT")
+			return
+		}
+
+		restConfig, err := backgroundClientConfig(cname)
+		if err != nil {
+			exit.Error(reason.InternalKubernetesClient, "error creating client config", err)
+		}
+		client, err := backgroundClient(cname)
+		if err != nil {
+			exit.Error(reason.InternalKubernetesClient, "error creating clientset", err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ctrlC := make(chan os.Signal, 1)
+		signal.Notify(ctrlC, os.Interrupt)
+		go func() {
+			<-ctrlC
+			cancel()
+		}()
+
+		out.Step(style.Connectivity, "Forwarding {{.count}} port(s) to 127.0.0.1, press Ctrl-C to stop", out.V{"count": len(co.Config.PortForwards)})
+		var wg sync.WaitGroup
+		for _, f := range co.Config.PortForwards {
+			wg.Add(1)
+			go func(f config.PortForward) {
+				defer wg.Done()
+				portforward.Forward(ctx, restConfig, client, f)
+			}(f)
+		}
+		wg.Wait()
+	},
+}
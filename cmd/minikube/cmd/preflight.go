@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/deprecations"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	preflightTo     string
+	preflightOutput string
+)
+
+// preflightCmd represents the set of preflight subcommands
+var preflightCmd = &cobra.Command{
+	Use:   "preflight",
+	Short: "Checks to run before changing the cluster",
+	Long:  "Checks to run before changing the cluster",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.Message(reason.Usage, "Usage: minikube preflight [upgrade]")
+	},
+}
+
+var preflightUpgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Finds the resources using APIs deprecated or removed by a Kubernetes version",
+	Long: `Scans the resources of the cluster for APIs deprecated or removed by the target Kubernetes version, and prints how to migrate them.
+A resource is reported when a field manager wrote it with such an API, or when its last applied configuration has one.
+Exits with an error if resources use APIs removed by the target version.`,
+	Example: "minikube preflight upgrade --to v1.31",
+	Run: func(cmd *cobra.Command, args []string) {
+		target, err := semver.ParseTolerant(preflightTo)
+		if err != nil {
+			exit.Message(reason.Usage, "Invalid Kubernetes version {{.version}}: {{.error}}", out.V{"version": preflightTo, "error": err})
+		}
+		profile := ClusterFlagValue()
+		co := mustload.Running(profile)
+		current, err := semver.ParseTolerant(co.Config.KubernetesConfig.KubernetesVersion)
+		if err == nil && target.LTE(current) {
+			exit.Message(reason.Usage, "The target version {{.target}} is not newer than the version of the cluster, {{.current}}", out.V{"target": preflightTo, "current": co.Config.KubernetesConfig.KubernetesVersion})
+		}
+
+		_, dyn := workloadClients(profile)
+		findings, err := deprecations.Find(context.Background(), dyn, target)
+		if err != nil {
+			exit.Error(reason.InternalKubernetesClient, "Failed to scan the resources of the cluster", err)
+		}
+
+		switch preflightOutput {
+		case "json":
+			b, err := json.Marshal(findings)
+			if err != nil {
+				exit.Error(reason.InternalJSONMarshal, "Failed to marshal the findings", err)
+			}
+			out.String(string(b))
+		case "table":
+			printPreflightTable(findings)
+		default:
+			exit.Message(reason.Usage, "Invalid output format '{{.output}}'. Valid values: 'table', 'json'", out.V{"output": preflightOutput})
+		}
+
+		removed := 0
+		for _, f := range findings {
+			if f.Removed {
+				removed++
+			}
+		}
+		if removed > 0 {
+			exit.Message(reason.KubernetesRemovedAPIs, "{{.count}} resources use APIs removed in {{.target}}. Migrate them to the replacement APIs before running: minikube start -p {{.profile}} --kubernetes-version={{.target}}",
+				out.V{"count": removed, "target": preflightTo, "profile": profile})
+		}
+		if len(findings) > 0 {
+			out.WarningT("{{.count}} resources use APIs deprecated in {{.target}}, which a later version removes", out.V{"count": len(findings), "target": preflightTo})
+		}
+		out.Step(style.Ready, "Ready to upgrade: minikube start -p {{.profile}} --kubernetes-version={{.target}}", out.V{"profile": profile, "target": preflightTo})
+	},
+}
+
+func printPreflightTable(findings []deprecations.Finding) {
+	if len(findings) == 0 {
+		out.Styled(style.Check, "No resource uses an API deprecated or removed in {{.version}}", out.V{"version": preflightTo})
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Status", "Kind", "Resource", "API Version", "Replacement", "Removed In", "Written By"})
+	table.SetAutoFormatHeaders(false)
+	table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+	table.SetCenterSeparator("|")
+	for _, f := range findings {
+		status := "deprecated"
+		if f.Removed {
+			status = "removed"
+		}
+		name := f.Name
+		if f.Namespace != "" {
+			name = f.Namespace + "/" + f.Name
+		}
+		replacement := f.Replacement
+		if replacement == "" {
+			replacement = "none"
+		}
+		table.Append([]string{status, f.Kind, name, f.GroupVersion(), replacement, f.RemovedIn, strings.Join(f.Users, ", ")})
+	}
+	table.Render()
+}
+
+func init() {
+	preflightUpgradeCmd.Flags().StringVar(&preflightTo, "to", constants.NewestKubernetesVersion, "The Kubernetes version to upgrade to")
+	preflightUpgradeCmd.Flags().StringVarP(&preflightOutput, "output", "o", "table", "The output format. One of 'table', 'json'")
+	preflightCmd.AddCommand(preflightUpgradeCmd)
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/image"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/preloadbuild"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/util"
+)
+
+var (
+	preloadKubernetesVersion string
+	preloadContainerRuntime  string
+	preloadDriver            string
+	preloadImagesFrom        []string
+	preloadImages            []string
+	preloadOutput            string
+)
+
+// preloadCmd represents the preload command
+var preloadCmd = &cobra.Command{
+	Use:   "preload",
+	Short: "Build preload tarballs holding the images of your applications",
+	Long:  "Builds preload tarballs holding the images of your applications next to the ones of Kubernetes, which 'minikube start --preload-from' extracts into the nodes so the clusters start with all their images.",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.Message(reason.Usage, "Usage: minikube preload build")
+	},
+}
+
+// preloadBuildCmd represents the preload build command
+var preloadBuildCmd = &cobra.Command{
+	Use:   "build",
+	Short: "Build a preload tarball holding the images of Kubernetes and of your applications",
+	Long: `Pulls the images of Kubernetes, the ones referenced by the manifests of --images-from and the ones of --images into a temporary docker or podman container,
+and packs its image store and the Kubernetes binaries into a preload tarball. Start clusters of the same Kubernetes version and container runtime with it, from a file or a URL:
+'minikube start --preload-from=FILE|URL'.`,
+	Example: `minikube preload build --images-from manifests/ --kubernetes-version v1.30.0
+minikube preload build --images ghcr.io/example/app:v1 --container-runtime containerd -o app-preload.tar.zst`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateRuntime(preloadContainerRuntime); err != nil || preloadContainerRuntime == constants.DefaultContainerRuntime {
+			exit.Message(reason.Usage, "Invalid container runtime {{.runtime}}, choose docker, containerd or cri-o", out.V{"runtime": preloadContainerRuntime})
+		}
+		if _, err := util.ParseKubernetesVersion(preloadKubernetesVersion); err != nil {
+			exit.Message(reason.Usage, "Invalid Kubernetes version {{.version}}: {{.error}}", out.V{"version": preloadKubernetesVersion, "error": err})
+		}
+		if preloadDriver != oci.Docker && preloadDriver != oci.Podman {
+			exit.Message(reason.Usage, "The preload tarballs are built with the docker or podman driver, not with {{.driver}}", out.V{"driver": preloadDriver})
+		}
+
+		o := preloadbuild.Options{
+			KubernetesVersion: preloadKubernetesVersion,
+			ContainerRuntime:  preloadContainerRuntime,
+			Images:            preloadImages,
+			OCIBinary:         preloadDriver,
+		}
+		if len(preloadImagesFrom) > 0 {
+			imgs, err := image.FromManifests(preloadImagesFrom)
+			if err != nil {
+				exit.Error(reason.Usage, "Failed to find the images of the manifests", err)
+			}
+			o.Images = append(o.Images, imgs...)
+		}
+		if len(o.Images) == 0 {
+			out.WarningT("No images of --images-from or --images, the tarball only holds the images of Kubernetes")
+		}
+
+		dst := preloadOutput
+		if dst == "" {
+			dst = fmt.Sprintf("preloaded-images-k8s-%s-%s-custom.tar.zst", preloadKubernetesVersion, preloadContainerRuntime)
+		}
+		out.Step(style.Waiting, "Building the preload of Kubernetes {{.version}} with {{.runtime}} and {{.count}} images ...", out.V{"version": preloadKubernetesVersion, "runtime": preloadContainerRuntime, "count": len(o.Images)})
+		if err := preloadbuild.Build(o, dst); err != nil {
+			exit.Error(reason.GuestImageBuild, "Failed to build the preload tarball", err)
+		}
+		out.Step(style.Ready, "Done! Start clusters with it: minikube start --preload-from={{.file}} --kubernetes-version={{.version}} --container-runtime={{.runtime}}", out.V{"file": filepath.Base(dst), "version": preloadKubernetesVersion, "runtime": preloadContainerRuntime})
+	},
+}
+
+func init() {
+	preloadBuildCmd.Flags().StringVar(&preloadKubernetesVersion, "kubernetes-version", constants.DefaultKubernetesVersion, "The Kubernetes version of the preload")
+	preloadBuildCmd.Flags().StringVar(&preloadContainerRuntime, "container-runtime", constants.Docker, "The container runtime of the preload")
+	preloadBuildCmd.Flags().StringVar(&preloadDriver, "driver", oci.Docker, "The driver running the temporary container the images are pulled into, docker or podman")
+	preloadBuildCmd.Flags().StringSliceVar(&preloadImagesFrom, "images-from", []string{}, "Manifest files, Helm chart directories, or directories searched recursively for both, whose images are added to the preload")
+	preloadBuildCmd.Flags().StringSliceVar(&preloadImages, "images", []string{}, "Images added to the preload")
+	preloadBuildCmd.Flags().StringVarP(&preloadOutput, "output", "o", "", "The preload tarball, preloaded-images-k8s-VERSION-RUNTIME-custom.tar.zst by default")
+	preloadCmd.AddCommand(preloadBuildCmd)
+}
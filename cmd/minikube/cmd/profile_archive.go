@@ -0,0 +1,271 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	cmdConfig "k8s.io/minikube/cmd/minikube/cmd/config"
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/profilearchive"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+	"k8s.io/minikube/pkg/util/retry"
+	"k8s.io/minikube/pkg/version"
+)
+
+var (
+	profileExportOutput string
+	profileExportImages bool
+	profileImportName   string
+	profileImportDriver string
+)
+
+// profileExportCmd represents the profile export command
+var profileExportCmd = &cobra.Command{
+	Use:   "export NAME",
+	Short: "Export a cluster into an archive which recreates it on another host",
+	Long: `Packs the config of the cluster NAME, its enabled addons, the images of its running nodes but the ones of Kubernetes, and the manifests applied to it with kubectl apply into an archive, which 'minikube profile import' recreates the cluster from on another host, so that onboarding a teammate is one command. The credentials of its registries and of their mirrors are not exported.
+
+The archive is a tarball compressed with zstd, or with gzip when its name ends with .gz or .tgz.`,
+	Example: "minikube profile export dev -o dev.tar.zst",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		api, cc := mustload.Partial(name)
+		api.Close()
+		if _, _, _, err := cloneConfig(*cc, name); err != nil {
+			exit.Message(reason.Usage, "Unable to export the cluster {{.name}}: {{.err}}", out.V{"name": name, "err": err})
+		}
+		dst := profileExportOutput
+		if dst == "" {
+			dst = name + ".tar.zst"
+		}
+
+		dir := localpath.MakeMiniPath("export", name)
+		if err := os.RemoveAll(dir); err != nil {
+			exit.Error(reason.HostHomeMkdir, "Error creating minikube directory", err)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			exit.Error(reason.HostHomeMkdir, "Error creating minikube directory", err)
+		}
+		defer func() {
+			if err := os.RemoveAll(dir); err != nil {
+				klog.Warningf("removing %s: %v", dir, err)
+			}
+		}()
+
+		out.Step(style.Waiting, "Exporting cluster {{.name}} ...", out.V{"name": name})
+		exported, err := copyConfig(*cc)
+		if err != nil {
+			exit.Error(reason.HostProfileArchive, "Unable to write the profile archive", err)
+		}
+		for _, w := range redactCredentials(&exported) {
+			out.WarningT("{{.warning}}", out.V{"warning": w})
+		}
+		m := profilearchive.Manifest{MinikubeVersion: version.GetVersion(), Config: exported}
+		if profileExportImages {
+			m.Images = exportCloneImages(cc, filepath.Join(dir, profilearchive.ImagesDir))
+		}
+		if cc.KubernetesConfig.KubernetesVersion != constants.NoKubernetesVersion {
+			if err := exportAppliedObjects(*cc, filepath.Join(dir, profilearchive.ObjectsFile)); err != nil {
+				out.WarningT("Unable to export the manifests applied to {{.name}}: {{.error}}", out.V{"name": name, "error": err})
+			}
+		}
+		if err := profilearchive.Write(dst, m, dir); err != nil {
+			exit.Error(reason.HostProfileArchive, "Unable to write the profile archive", err)
+		}
+		out.Step(style.Ready, "Exported cluster {{.name}} to {{.file}}, recreate it with: minikube profile import {{.file}}", out.V{"name": name, "file": dst})
+	},
+}
+
+// profileImportCmd represents the profile import command
+var profileImportCmd = &cobra.Command{
+	Use:   "import FILE",
+	Short: "Recreate a cluster exported with 'minikube profile export'",
+	Long: `Creates the cluster exported into FILE by 'minikube profile export', with its config, nodes and addons, loads the exported images into its nodes and applies the exported manifests.
+
+The machines get new IPs, and their certificates are issued for them. The static IP, subnet, exposed ports and load balancer IP range of the exported cluster are not imported as they may conflict with the networks of this host, and its start hooks, which run any command, are not imported either, nor its persistent mounts, whose host directories may not exist on this host.`,
+	Example: `minikube profile import dev.tar.zst
+minikube profile import dev.tar.zst --name=dev2 --driver=docker`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := localpath.MakeMiniPath("import", strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0])))
+		if err := os.RemoveAll(dir); err != nil {
+			exit.Error(reason.HostHomeMkdir, "Error creating minikube directory", err)
+		}
+		defer func() {
+			if err := os.RemoveAll(dir); err != nil {
+				klog.Warningf("removing %s: %v", dir, err)
+			}
+		}()
+		m, err := profilearchive.Extract(args[0], dir)
+		if err != nil {
+			exit.Error(reason.HostProfileArchive, "Unable to read the profile archive", err)
+		}
+
+		src := m.Config.Name
+		name := profileImportName
+		if name == "" {
+			name = src
+		}
+		if !config.ProfileNameValid(name) || config.ProfileNameInReservedKeywords(name) {
+			exit.Message(reason.Usage, "The profile name {{.name}} is not valid: only alphanumeric and dashes '-' are permitted, starting with alphanumeric", out.V{"name": name})
+		}
+		if config.ProfileExists(name) {
+			exit.Message(reason.Usage, "The cluster {{.name}} already exists, delete it with 'minikube delete -p {{.name}}' or import it with --name", out.V{"name": name})
+		}
+		if m.MinikubeVersion != version.GetVersion() {
+			out.WarningT("{{.file}} was exported by minikube {{.exported}}, this is minikube {{.version}}", out.V{"file": args[0], "exported": m.MinikubeVersion, "version": version.GetVersion()})
+		}
+
+		cc, workers, warnings, err := cloneConfig(m.Config, name)
+		if err != nil {
+			exit.Message(reason.Usage, "Unable to import the cluster {{.name}}: {{.err}}", out.V{"name": src, "err": err})
+		}
+		if profileImportDriver != "" {
+			cc.Driver = profileImportDriver
+		}
+		if !driver.Supported(cc.Driver) {
+			exit.Message(reason.Usage, "The {{.driver}} driver of the exported cluster is not supported on {{.os}}/{{.arch}}, choose another one with --driver", out.V{"driver": cc.Driver, "os": runtime.GOOS, "arch": runtime.GOARCH})
+		}
+		if cc.Mount {
+			warnings = append(warnings, "The host directory "+cc.MountString+" mounted into the exported cluster must exist on this host")
+		}
+		for _, w := range warnings {
+			out.WarningT("{{.warning}}", out.V{"warning": w})
+		}
+
+		out.Step(style.Happy, "Importing cluster {{.src}} as {{.name}}", out.V{"src": src, "name": name})
+		started, srcNodes := startCloned(cmd, cc, workers)
+		if len(m.Images) > 0 {
+			importCloneImages(started, src, srcNodes, m.Images, filepath.Join(dir, profilearchive.ImagesDir))
+		}
+		objects := filepath.Join(dir, profilearchive.ObjectsFile)
+		if _, err := os.Stat(objects); err == nil {
+			out.Step(style.Waiting, "Applying the manifests exported from {{.src}} ...", out.V{"src": src})
+			if err := applyObjects(*started, objects); err != nil {
+				out.WarningT("Unable to apply the exported manifests: {{.error}}", out.V{"error": err})
+			}
+		}
+		out.Step(style.Ready, "Imported cluster {{.src}} as {{.name}}", out.V{"src": src, "name": name})
+	},
+}
+
+// primaryRunner returns the command runner of the primary control plane of cc, which must be running
+func primaryRunner(cc config.ClusterConfig) (command.Runner, error) {
+	api, err := machine.NewAPIClient()
+	if err != nil {
+		return nil, errors.Wrap(err, "libmachine")
+	}
+	defer api.Close()
+	cp, err := config.PrimaryControlPlane(&cc)
+	if err != nil {
+		return nil, err
+	}
+	if st, err := machine.Status(api, config.MachineName(cc, cp)); err != nil || st != state.Running.String() {
+		return nil, errors.Errorf("the control plane %s is not running", config.MachineName(cc, cp))
+	}
+	h, err := machine.GetHost(api, cc, cp)
+	if err != nil {
+		return nil, errors.Wrap(err, "get host")
+	}
+	return machine.CommandRunner(h)
+}
+
+// exportAppliedObjects writes the manifests applied to the cluster of cc with kubectl apply to dst
+func exportAppliedObjects(cc config.ClusterConfig, dst string) error {
+	r, err := primaryRunner(cc)
+	if err != nil {
+		return err
+	}
+	kubectl := kapi.KubectlBinaryPath(cc.KubernetesConfig.KubernetesVersion)
+	rr, err := r.RunCmd(exec.Command("sudo", "KUBECONFIG=/var/lib/minikube/kubeconfig", kubectl, "api-resources", "--verbs=list,create", "-o", "name"))
+	if err != nil {
+		return errors.Wrap(err, "api resources")
+	}
+	var resources []string
+	for _, res := range strings.Fields(rr.Stdout.String()) {
+		// events are not applied, and too many to list
+		if res != "events" && res != "events.events.k8s.io" {
+			resources = append(resources, res)
+		}
+	}
+	rr, err = r.RunCmd(exec.Command("sudo", "KUBECONFIG=/var/lib/minikube/kubeconfig", kubectl, "get", strings.Join(resources, ","), "--all-namespaces", "-o", "json"))
+	if err != nil {
+		return errors.Wrap(err, "get objects")
+	}
+	objects, err := profilearchive.AppliedObjects(rr.Stdout.Bytes())
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, objects, 0644)
+}
+
+// applyObjects applies the manifests exported by exportAppliedObjects into src to the cluster of cc
+func applyObjects(cc config.ClusterConfig, src string) error {
+	r, err := primaryRunner(cc)
+	if err != nil {
+		return err
+	}
+	f, err := assets.NewFileAsset(src, vmpath.GuestPersistentDir, path.Base(src), "0640")
+	if err != nil {
+		return errors.Wrap(err, "read manifests")
+	}
+	defer f.Close()
+	if err := r.Copy(f); err != nil {
+		return errors.Wrap(err, "copy manifests")
+	}
+	kubectl := kapi.KubectlBinaryPath(cc.KubernetesConfig.KubernetesVersion)
+	apply := func() error {
+		_, err := r.RunCmd(exec.Command("sudo", "KUBECONFIG=/var/lib/minikube/kubeconfig", kubectl, "apply", "-f", path.Join(vmpath.GuestPersistentDir, path.Base(src))))
+		return err
+	}
+	// the custom resources wait for their definitions to be established
+	return retry.Expo(apply, 2*time.Second, time.Minute)
+}
+
+func init() {
+	profileExportCmd.Flags().StringVarP(&profileExportOutput, "output", "o", "", "The archive to write, NAME.tar.zst by default")
+	profileExportCmd.Flags().BoolVar(&profileExportImages, "images", true, "If true, the images of the running nodes are exported")
+	profileImportCmd.Flags().StringVar(&profileImportName, "name", "", "The name of the imported cluster, the name of the exported one by default")
+	profileImportCmd.Flags().StringVar(&profileImportDriver, "driver", "", "The driver of the imported cluster, the driver of the exported one by default")
+	cmdConfig.ProfileCmd.AddCommand(profileExportCmd)
+	cmdConfig.ProfileCmd.AddCommand(profileImportCmd)
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/proxy"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// proxyCmd represents the proxy command
+var proxyCmd = &cobra.Command{
+	Use:   "proxy",
+	Short: "Inspect the proxy settings passed to the cluster",
+	Long:  "Inspects the proxy settings of the host, which minikube passes to the container runtime, kubelet and addon pods of the cluster.",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.Message(reason.Usage, "Usage: minikube proxy status")
+	},
+}
+
+// proxyStatusCmd represents the proxy status command
+var proxyStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Shows the proxy settings of the host and of the nodes",
+	Long: `Shows the proxy settings detected on the host and where they come from, the NO_PROXY computed for the cluster,
+and the proxy environment of the container runtime and kubelet of the running nodes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		api, cc := mustload.Partial(ClusterFlagValue())
+		defer api.Close()
+
+		s := proxy.Detect()
+		if s.Empty() {
+			out.Styled(style.Internet, "The host uses no proxy")
+		} else {
+			out.Styled(style.Internet, "Proxy of the host, from the {{.source}}:", out.V{"source": s.Source})
+			out.Infof("HTTP_PROXY={{.value}}", out.V{"value": proxy.Mask(s.HTTP)})
+			out.Infof("HTTPS_PROXY={{.value}}", out.V{"value": proxy.Mask(s.HTTPS)})
+			out.Infof("NO_PROXY={{.value}}", out.V{"value": strings.Join(s.NoProxy, ",")})
+		}
+		if s.PACURL != "" {
+			out.Infof("PAC file: {{.url}}", out.V{"url": s.PACURL})
+		}
+		if cc.DisableProxyPropagation {
+			out.Styled(style.Notice, "The proxy settings are not passed to {{.profile}}, which was started with --propagate-proxy=false", out.V{"profile": cc.Name})
+			return
+		}
+		podCIDR, err := bsutil.PodCIDR(*cc)
+		if err != nil {
+			exit.Error(reason.InternalBootstrapper, "Unable to get the pod CIDR", err)
+		}
+		env := proxy.Env(s, *cc, podCIDR)
+		if len(env) == 0 {
+			out.Styled(style.Notice, "No proxy is passed to the cluster")
+		} else {
+			out.Styled(style.Internet, "NO_PROXY of the cluster: {{.value}}", out.V{"value": strings.Join(proxy.NoProxy(s, *cc, podCIDR), ",")})
+		}
+
+		for _, n := range cc.Nodes {
+			showNodeProxy(api, cc, n, env)
+		}
+	},
+}
+
+// showNodeProxy shows the proxy environment of the services of a running node, and whether it is the one of the host
+func showNodeProxy(api libmachine.API, cc *config.ClusterConfig, n config.Node, env []string) {
+	name := config.MachineName(*cc, n)
+	if st, err := machine.Status(api, name); err != nil || st != state.Running.String() {
+		out.Infof("{{.node}}: not running", out.V{"node": name})
+		return
+	}
+	h, err := machine.LoadHost(api, name)
+	if err != nil {
+		klog.Warningf("loading host %s: %v", name, err)
+		return
+	}
+	r, err := machine.CommandRunner(h)
+	if err != nil {
+		klog.Warningf("getting the command runner of %s: %v", name, err)
+		return
+	}
+	for _, svc := range proxy.Services(cc.KubernetesConfig.ContainerRuntime) {
+		nodeEnv, err := proxy.NodeEnv(r, svc)
+		if err != nil {
+			out.WarningT("Unable to read the proxy environment of {{.service}} on {{.node}}: {{.error}}", out.V{"service": svc, "node": name, "error": err})
+			continue
+		}
+		freshness := "up to date"
+		if strings.Join(nodeEnv, "\n") != strings.Join(env, "\n") {
+			freshness = "outdated, run minikube start to update it"
+		}
+		if len(nodeEnv) == 0 {
+			out.Infof("{{.node}}: {{.service}} has no proxy ({{.state}})", out.V{"node": name, "service": svc, "state": freshness})
+			continue
+		}
+		var masked []string
+		for _, e := range nodeEnv {
+			k, v, _ := strings.Cut(e, "=")
+			masked = append(masked, k+"="+proxy.Mask(v))
+		}
+		out.Infof("{{.node}}: {{.service}} uses {{.env}} ({{.state}})", out.V{"node": name, "service": svc, "env": strings.Join(masked, " "), "state": freshness})
+	}
+}
+
+func init() {
+	proxyCmd.AddCommand(proxyStatusCmd)
+}
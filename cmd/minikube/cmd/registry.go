@@ -0,0 +1,240 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/homedir"
+
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/registryauth"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	loginUsername      string
+	loginPassword      string
+	loginPasswordStdin bool
+	loginFromDocker    bool
+	loginDockerConfig  string
+	loginHelper        string
+	loginNamespaces    []string
+)
+
+// registryCmd represents the registry command
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Manage the credentials of the private registries pulled by the cluster",
+	Long: `Manages the credentials of private registries, which the kubelet of every node pulls the images of all the pods with,
+and which are also given as an imagePullSecret to the default service account of the chosen namespaces.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.Message(reason.Usage, "Usage: minikube registry [login|logout|list]")
+	},
+}
+
+// registryLoginCmd represents the registry login command
+var registryLoginCmd = &cobra.Command{
+	Use:   "login REGISTRY",
+	Short: "Log the cluster in to a private registry",
+	Long: `Stores the credentials of a private registry in the profile of the cluster, and gives them to the kubelet of every node and to the namespaces of --namespaces.
+The credentials are given with --username and --password, taken from the docker config of the host with --from-docker-config, or a token is got from a cloud CLI with --helper.
+The tokens of the cloud CLIs expire within hours, run the login again to refresh them.`,
+	Example: `minikube registry login registry.example.com --username=me --password-stdin < password.txt
+minikube registry login ghcr.io --from-docker-config --namespaces=default,dev
+minikube registry login us-docker.pkg.dev --helper=gcloud
+minikube registry login 123456789012.dkr.ecr.us-east-1.amazonaws.com --helper=aws`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cred := config.RegistryCredential{Registry: args[0], Helper: loginHelper, Namespaces: loginNamespaces}
+		var err error
+		cred.Username, cred.Password, err = registryLoginCredentials(cred.Registry)
+		if err != nil {
+			exit.Message(reason.Usage, "{{.error}}", out.V{"error": err})
+		}
+
+		api, cc := mustload.Partial(ClusterFlagValue())
+		defer api.Close()
+		stale := registryauth.Namespaces(cc.RegistryCredentials)
+		cc.RegistryCredentials = registryauth.Add(cc.RegistryCredentials, cred)
+		saveRegistryCredentials(api, cc, stale)
+		out.Step(style.Ready, "{{.profile}} is logged in to {{.registry}} as {{.user}}", out.V{"profile": cc.Name, "registry": cred.Registry, "user": cred.Username})
+	},
+}
+
+// registryLogoutCmd represents the registry logout command
+var registryLogoutCmd = &cobra.Command{
+	Use:     "logout REGISTRY",
+	Short:   "Log the cluster out of a private registry",
+	Long:    "Removes the credentials of a private registry from the profile of the cluster, the nodes, and the imagePullSecrets of its namespaces.",
+	Example: "minikube registry logout registry.example.com",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		api, cc := mustload.Partial(ClusterFlagValue())
+		defer api.Close()
+		stale := registryauth.Namespaces(cc.RegistryCredentials)
+		var removed bool
+		cc.RegistryCredentials, removed = registryauth.Remove(cc.RegistryCredentials, args[0])
+		if !removed {
+			exit.Message(reason.Usage, "{{.profile}} is not logged in to {{.registry}}, see 'minikube registry list'", out.V{"profile": cc.Name, "registry": args[0]})
+		}
+		saveRegistryCredentials(api, cc, stale)
+		out.Step(style.Deleted, "{{.profile}} is logged out of {{.registry}}", out.V{"profile": cc.Name, "registry": args[0]})
+	},
+}
+
+// registryListCmd represents the registry list command
+var registryListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the private registries the cluster is logged in to",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		api, cc := mustload.Partial(ClusterFlagValue())
+		defer api.Close()
+		if len(cc.RegistryCredentials) == 0 {
+			out.Styled(style.Empty, "{{.profile}} is logged in to no registry, log in with 'minikube registry login REGISTRY'", out.V{"profile": cc.Name})
+			return
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Registry", "Username", "Helper", "Namespaces"})
+		table.SetAutoFormatHeaders(false)
+		table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+		table.SetCenterSeparator("|")
+		for _, c := range cc.RegistryCredentials {
+			table.Append([]string{c.Registry, c.Username, c.Helper, strings.Join(c.Namespaces, ", ")})
+		}
+		table.Render()
+	},
+}
+
+// registryLoginCredentials returns the username and password of the registry, from the source selected by the flags
+func registryLoginCredentials(registry string) (string, string, error) {
+	sources := 0
+	for _, set := range []bool{loginUsername != "", loginFromDocker, loginHelper != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return "", "", fmt.Errorf("give the credentials with exactly one of --username, --from-docker-config and --helper")
+	}
+	switch {
+	case loginHelper != "":
+		return registryauth.FromHelper(loginHelper, registry)
+	case loginFromDocker:
+		p := loginDockerConfig
+		if p == "" {
+			p = dockerConfigPath()
+		}
+		return registryauth.FromDockerConfig(p, registry)
+	}
+	password := loginPassword
+	if loginPasswordStdin {
+		if password != "" {
+			return "", "", fmt.Errorf("--password and --password-stdin are mutually exclusive")
+		}
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", "", errors.Wrap(err, "reading the password")
+		}
+		password = strings.TrimRight(string(b), "\r\n")
+	}
+	if password == "" {
+		return "", "", fmt.Errorf("give the password of %s with --password or --password-stdin", loginUsername)
+	}
+	return loginUsername, password, nil
+}
+
+// dockerConfigPath returns the path of the docker config of the host
+func dockerConfigPath() string {
+	if d := os.Getenv("DOCKER_CONFIG"); d != "" {
+		return filepath.Join(d, "config.json")
+	}
+	return filepath.Join(homedir.HomeDir(), ".docker", "config.json")
+}
+
+// saveRegistryCredentials saves the credentials of the cluster, and gives them to its running nodes and to its namespaces,
+// removing the imagePullSecrets of the namespaces of stale which no credentials select anymore
+func saveRegistryCredentials(api libmachine.API, cc *config.ClusterConfig, stale []string) {
+	if err := config.SaveProfile(cc.Name, cc); err != nil {
+		exit.Error(reason.HostSaveProfile, "failed to save config", err)
+	}
+	running := false
+	for _, n := range cc.Nodes {
+		name := config.MachineName(*cc, n)
+		if st, err := machine.Status(api, name); err != nil || st != state.Running.String() {
+			out.Styled(style.Notice, "{{.node}} is not running, it is given the credentials when it starts", out.V{"node": name})
+			continue
+		}
+		running = true
+		h, err := machine.LoadHost(api, name)
+		if err != nil {
+			exit.Error(reason.GuestLoadHost, "Error getting host", err)
+		}
+		r, err := machine.CommandRunner(h)
+		if err != nil {
+			exit.Error(reason.InternalCommandRunner, "Failed to get command runner", err)
+		}
+		if err := registryauth.ApplyNode(r, cc.RegistryCredentials); err != nil {
+			exit.Error(reason.GuestRegistryAuth, "Failed to give the credentials of the registries to the nodes", err)
+		}
+	}
+	if !running || cc.KubernetesConfig.KubernetesVersion == constants.NoKubernetesVersion {
+		return
+	}
+	c, err := kapi.Client(cc.Name)
+	if err != nil {
+		exit.Error(reason.GuestRegistryAuth, "Failed to create the imagePullSecrets of the registries", err)
+	}
+	missing, err := registryauth.SyncSecrets(context.Background(), c, cc.RegistryCredentials, stale)
+	if err != nil {
+		exit.Error(reason.GuestRegistryAuth, "Failed to create the imagePullSecrets of the registries", err)
+	}
+	if len(missing) > 0 {
+		out.WarningT("The namespaces {{.namespaces}} do not exist, their imagePullSecrets are created by the next start", out.V{"namespaces": strings.Join(missing, ", ")})
+	}
+}
+
+func init() {
+	registryLoginCmd.Flags().StringVar(&loginUsername, "username", "", "The username of the registry")
+	registryLoginCmd.Flags().StringVar(&loginPassword, "password", "", "The password of the registry, kept in the profile of the cluster")
+	registryLoginCmd.Flags().BoolVar(&loginPasswordStdin, "password-stdin", false, "If true, the password is read from the standard input")
+	registryLoginCmd.Flags().BoolVar(&loginFromDocker, "from-docker-config", false, "If true, the credentials are taken from the docker config of the host, as stored by docker login or its credential helper")
+	registryLoginCmd.Flags().StringVar(&loginDockerConfig, "docker-config", "", "The docker config of --from-docker-config, $DOCKER_CONFIG/config.json or ~/.docker/config.json by default")
+	registryLoginCmd.Flags().StringVar(&loginHelper, "helper", "", fmt.Sprintf("The cloud CLI getting a token of the registry. Options include: [%s]", strings.Join(registryauth.Helpers, ",")))
+	registryLoginCmd.Flags().StringSliceVar(&loginNamespaces, "namespaces", []string{}, "The namespaces given the credentials as an imagePullSecret of their default service account")
+	registryCmd.AddCommand(registryLoginCmd)
+	registryCmd.AddCommand(registryLogoutCmd)
+	registryCmd.AddCommand(registryListCmd)
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/olekukonko/tablewriter"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/registrymirror"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/sysinit"
+)
+
+var (
+	mirrorUsername string
+	mirrorPassword string
+	mirrorCACert   string
+	mirrorInsecure bool
+)
+
+// registryMirrorCmd represents the registry-mirror command
+var registryMirrorCmd = &cobra.Command{
+	Use:   "registry-mirror",
+	Short: "Manage the mirrors of the registries pulled by the nodes",
+	Long: `Manages the mirrors which the container runtime of the nodes pulls the images of registries from, with their credentials and TLS settings,
+by generating the hosts.toml of containerd or the registries.conf of cri-o in every node. The changes apply to the running cluster, and are kept for the next starts.
+Only supported by the containerd and cri-o container runtimes, the mirrors of docker are set with 'minikube start --registry-mirror'.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.Message(reason.Usage, "Usage: minikube registry-mirror [add|remove|list]")
+	},
+}
+
+// registryMirrorAddCmd represents the registry-mirror add command
+var registryMirrorAddCmd = &cobra.Command{
+	Use:   "add REGISTRY MIRROR_URL",
+	Short: "Add a mirror of a registry",
+	Long:  "Adds a mirror of a registry, pulled from before the other mirrors added later and before the registry itself. Adding the same mirror again replaces its settings.",
+	Example: `minikube registry-mirror add docker.io https://mirror.gcr.io
+minikube registry-mirror add quay.io https://registry.example.com:5000 --username=me --password=secret --ca-cert=./ca.pem
+minikube registry-mirror add localhost:5000 http://192.168.49.1:5000`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		m := config.RegistryMirror{Registry: args[0], URL: args[1], Username: mirrorUsername, Password: mirrorPassword, Insecure: mirrorInsecure}
+		if mirrorCACert != "" {
+			b, err := os.ReadFile(mirrorCACert)
+			if err != nil {
+				exit.Message(reason.Usage, "Unable to read the CA of the mirror: {{.error}}", out.V{"error": err})
+			}
+			m.CACert = string(b)
+		}
+		if err := registrymirror.Validate(m); err != nil {
+			exit.Message(reason.Usage, "{{.error}}", out.V{"error": err})
+		}
+
+		api, cc := loadRegistryMirrorCluster()
+		defer api.Close()
+		cc.RegistryMirrors = registrymirror.Add(cc.RegistryMirrors, m)
+		saveRegistryMirrors(api, cc, nil)
+		out.Step(style.Ready, "{{.registry}} is pulled from {{.mirror}}", out.V{"registry": m.Registry, "mirror": m.URL})
+	},
+}
+
+// registryMirrorRemoveCmd represents the registry-mirror remove command
+var registryMirrorRemoveCmd = &cobra.Command{
+	Use:     "remove REGISTRY [MIRROR_URL]",
+	Short:   "Remove the mirrors of a registry",
+	Long:    "Removes the mirrors of a registry, or only the one of MIRROR_URL.",
+	Example: "minikube registry-mirror remove docker.io",
+	Args:    cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		var mirrorURL string
+		if len(args) > 1 {
+			mirrorURL = args[1]
+		}
+		api, cc := loadRegistryMirrorCluster()
+		defer api.Close()
+		var removed bool
+		cc.RegistryMirrors, removed = registrymirror.Remove(cc.RegistryMirrors, args[0], mirrorURL)
+		if !removed {
+			exit.Message(reason.Usage, "{{.registry}} has no such mirror, see 'minikube registry-mirror list'", out.V{"registry": args[0]})
+		}
+		saveRegistryMirrors(api, cc, []string{args[0]})
+		out.Step(style.Deleted, "Removed the mirrors of {{.registry}}", out.V{"registry": args[0]})
+	},
+}
+
+// registryMirrorListCmd represents the registry-mirror list command
+var registryMirrorListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the mirrors of the registries",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		api, cc := mustload.Partial(ClusterFlagValue())
+		defer api.Close()
+		if len(cc.RegistryMirrors) == 0 {
+			out.Styled(style.Empty, "{{.profile}} has no mirrors, add one with 'minikube registry-mirror add REGISTRY MIRROR_URL'", out.V{"profile": cc.Name})
+			return
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Registry", "Mirror", "Auth", "TLS"})
+		table.SetAutoFormatHeaders(false)
+		table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+		table.SetCenterSeparator("|")
+		for _, m := range cc.RegistryMirrors {
+			auth := "none"
+			if m.Username != "" {
+				auth = m.Username
+			}
+			table.Append([]string{m.Registry, m.URL, auth, mirrorTLS(m)})
+		}
+		table.Render()
+	},
+}
+
+// mirrorTLS describes how the TLS of the mirror m is verified
+func mirrorTLS(m config.RegistryMirror) string {
+	switch {
+	case strings.HasPrefix(m.URL, "http://"):
+		return "none"
+	case m.Insecure:
+		return "not verified"
+	case m.CACert != "":
+		return "custom CA"
+	}
+	return "verified"
+}
+
+// loadRegistryMirrorCluster loads the cluster whose mirrors are managed, which must use containerd or cri-o
+func loadRegistryMirrorCluster() (libmachine.API, *config.ClusterConfig) {
+	api, cc := mustload.Partial(ClusterFlagValue())
+	if rt := cc.KubernetesConfig.ContainerRuntime; !registrymirror.SupportsRuntime(rt) {
+		api.Close()
+		exit.Message(reason.Usage, "The mirrors of registries are managed for the containerd and cri-o container runtimes, {{.profile}} uses {{.runtime}}: use 'minikube start --registry-mirror' instead", out.V{"profile": cc.Name, "runtime": rt})
+	}
+	return api, cc
+}
+
+// saveRegistryMirrors saves the mirrors of the cluster, and generates them into its running nodes, removing those of the removed registries
+func saveRegistryMirrors(api libmachine.API, cc *config.ClusterConfig, removed []string) {
+	if err := config.SaveProfile(cc.Name, cc); err != nil {
+		exit.Error(reason.HostSaveProfile, "failed to save config", err)
+	}
+	for _, n := range cc.Nodes {
+		name := config.MachineName(*cc, n)
+		if st, err := machine.Status(api, name); err != nil || st != state.Running.String() {
+			out.Styled(style.Notice, "{{.node}} is not running, its mirrors are configured when it starts", out.V{"node": name})
+			continue
+		}
+		if err := applyRegistryMirrors(api, cc, name, removed); err != nil {
+			exit.Error(reason.GuestRegistryMirror, "Failed to configure the mirrors of the registries", err)
+		}
+	}
+}
+
+// applyRegistryMirrors generates the mirrors of the cluster into the running node name, restarting cri-o to read them
+func applyRegistryMirrors(api libmachine.API, cc *config.ClusterConfig, name string, removed []string) error {
+	h, err := machine.LoadHost(api, name)
+	if err != nil {
+		return errors.Wrap(err, "loading host")
+	}
+	r, err := machine.CommandRunner(h)
+	if err != nil {
+		return errors.Wrap(err, "getting command runner")
+	}
+	rt := cc.KubernetesConfig.ContainerRuntime
+	if err := registrymirror.Apply(r, rt, cc.RegistryMirrors, cc.InsecureRegistry, removed); err != nil {
+		return errors.Wrapf(err, "configuring %s", name)
+	}
+	if rt == constants.CRIO {
+		return errors.Wrapf(sysinit.New(r).Restart("crio"), "restarting cri-o on %s", name)
+	}
+	return nil
+}
+
+func init() {
+	registryMirrorAddCmd.Flags().StringVar(&mirrorUsername, "username", "", "The username of the mirror")
+	registryMirrorAddCmd.Flags().StringVar(&mirrorPassword, "password", "", "The password of the mirror, kept in the profile of the cluster")
+	registryMirrorAddCmd.Flags().StringVar(&mirrorCACert, "ca-cert", "", "The PEM file of the CA which signed the certificate of the mirror")
+	registryMirrorAddCmd.Flags().BoolVar(&mirrorInsecure, "insecure", false, "If true, the certificate of the mirror is not verified")
+	registryMirrorCmd.AddCommand(registryMirrorAddCmd)
+	registryMirrorCmd.AddCommand(registryMirrorRemoveCmd)
+	registryMirrorCmd.AddCommand(registryMirrorListCmd)
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/notify"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	releaseNotesOutput string
+	releaseNotesAll    bool
+)
+
+var releaseNotesCmd = &cobra.Command{
+	Use:   "release-notes",
+	Short: "Print what's new in the minikube and Kubernetes releases for this profile",
+	Long: `Print the minikube and Kubernetes releases newer than the ones in use, and the security advisories of the base image fixed since this minikube version.
+
+The release notes are read from the release feed, set with 'minikube config set ReleaseFeedURL <url or file>'. Only the entries relevant to the driver, container runtime and enabled addons of the profile are printed, unless --all is set.
+To be notified of them instead of the plain update notice, run: 'minikube config set WantReleaseNotes true'`,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, err := notify.NewFeedSource(notify.FeedURL()).Entries()
+		if err != nil {
+			exit.Error(reason.InetVersionUnavailable, "Unable to fetch the release feed", err)
+		}
+
+		var cc *config.ClusterConfig
+		if !releaseNotesAll {
+			if existing, err := config.Load(ClusterFlagValue()); err == nil {
+				cc = existing
+			}
+		}
+		relevant := notify.Relevant(entries, notify.InterestOf(cc))
+
+		switch releaseNotesOutput {
+		case "":
+			printReleaseNotes(relevant)
+		case "json":
+			if relevant == nil {
+				relevant = []notify.FeedEntry{}
+			}
+			b, err := json.Marshal(relevant)
+			if err != nil {
+				exit.Error(reason.InternalJSONMarshal, "release notes json failure", err)
+			}
+			out.Ln(string(b))
+		default:
+			exit.Message(reason.InternalOutputUsage, "error: --output must be 'json'")
+		}
+	},
+}
+
+// printReleaseNotes prints the feed entries grouped by kind
+func printReleaseNotes(entries []notify.FeedEntry) {
+	if len(entries) == 0 {
+		out.Styled(style.Happy, "No new releases or security advisories are relevant to your configuration")
+		return
+	}
+	groups := []struct {
+		kind  string
+		st    style.Enum
+		title string
+	}{
+		{notify.FeedSecurity, style.Warning, "Security advisories:"},
+		{notify.FeedMinikube, style.Celebrate, "minikube releases:"},
+		{notify.FeedKubernetes, style.Notice, "Kubernetes releases:"},
+	}
+	for _, g := range groups {
+		printed := false
+		for _, e := range entries {
+			if e.Kind != g.kind {
+				continue
+			}
+			if !printed {
+				out.Styled(g.st, g.title)
+				printed = true
+			}
+			out.Infof("{{.entry}}", out.V{"entry": e})
+			if e.Summary != "" {
+				out.Ln("      %s", e.Summary)
+			}
+			if e.URL != "" {
+				out.Ln("      %s", e.URL)
+			}
+		}
+	}
+}
+
+func init() {
+	releaseNotesCmd.Flags().StringVarP(&releaseNotesOutput, "output", "o", "", "One of 'json'.")
+	releaseNotesCmd.Flags().BoolVar(&releaseNotesAll, "all", false, "Print the entries relevant to any configuration, not only to the driver, container runtime and addons of the profile.")
+}
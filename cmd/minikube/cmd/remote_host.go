@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/spf13/viper"
+	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+// useRemoteHost points the docker and podman clients of minikube to the remote host of --host, or of the profile
+func useRemoteHost() error {
+	host := viper.GetString(config.RemoteHost)
+	cc, err := config.Load(ClusterFlagValue())
+	if err == nil {
+		if host == "" {
+			host = cc.RemoteHost
+		} else if host != cc.RemoteHost {
+			where := "on this machine"
+			if cc.RemoteHost != "" {
+				where = "on " + cc.RemoteHost
+			}
+			return fmt.Errorf("the cluster %q runs %s, delete it to run it on %s", cc.Name, where, host)
+		}
+	}
+	if host == "" {
+		return nil
+	}
+	if err := validateRemoteHost(host); err != nil {
+		return err
+	}
+	for _, env := range []string{constants.DockerHostEnv, constants.PodmanContainerHostEnv} {
+		if err := os.Setenv(env, host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRemoteHost checks that host is a ssh://[USER@]HOST[:PORT] URL
+func validateRemoteHost(host string) error {
+	u, err := url.Parse(host)
+	if err != nil || u.Scheme != "ssh" || u.Hostname() == "" {
+		return fmt.Errorf("the --host %q is not valid, expected ssh://[USER@]HOST[:PORT]", host)
+	}
+	return nil
+}
+
+// kicSSHPort returns the local port reaching the ssh port of the kic container of the machine,
+// through a tunnel when the daemon runs on a remote host
+func kicSSHPort(driverName, machineName string) (int, error) {
+	port, err := oci.ForwardedPort(driverName, machineName, 22)
+	if err != nil || !oci.IsSSHDaemonHost(driverName) {
+		return port, err
+	}
+	return oci.ForwardedPortTunnel(driverName, localpath.MachinePath(machineName), 22, port)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"testing"
+
+	"github.com/spf13/viper"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+func TestUseRemoteHost(t *testing.T) {
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+	t.Setenv(constants.DockerHostEnv, "")
+	t.Setenv(constants.PodmanContainerHostEnv, "")
+	defer viper.Set(config.ProfileName, viper.GetString(config.ProfileName))
+	viper.Set(config.ProfileName, "remote")
+	defer viper.Set(config.RemoteHost, "")
+
+	viper.Set(config.RemoteHost, "tcp://dev-box:2376")
+	if err := useRemoteHost(); err == nil {
+		t.Errorf("useRemoteHost() accepted a tcp:// host")
+	}
+
+	viper.Set(config.RemoteHost, "ssh://me@dev-box")
+	if err := useRemoteHost(); err != nil {
+		t.Fatalf("useRemoteHost() failed: %v", err)
+	}
+	if got := os.Getenv(constants.DockerHostEnv); got != "ssh://me@dev-box" {
+		t.Errorf("%s = %q, want the remote host", constants.DockerHostEnv, got)
+	}
+
+	// the next commands of the profile use its host
+	if err := config.SaveProfile("remote", &config.ClusterConfig{Name: "remote", RemoteHost: "ssh://me@dev-box"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(constants.DockerHostEnv, "")
+	viper.Set(config.RemoteHost, "")
+	if err := useRemoteHost(); err != nil {
+		t.Fatalf("useRemoteHost() failed: %v", err)
+	}
+	if got := os.Getenv(constants.DockerHostEnv); got != "ssh://me@dev-box" {
+		t.Errorf("%s = %q, want the remote host of the profile", constants.DockerHostEnv, got)
+	}
+
+	viper.Set(config.RemoteHost, "ssh://me@other-box")
+	if err := useRemoteHost(); err == nil {
+		t.Errorf("useRemoteHost() accepted another host for an existing cluster")
+	}
+}
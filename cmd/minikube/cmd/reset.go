@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+	cmdcfg "k8s.io/minikube/cmd/minikube/cmd/config"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/node"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+)
+
+// resetPaths is the Kubernetes state wiped from the guest on top of 'kubeadm reset'.
+// The certs are copied again from the profile dir, and the kubeadm managed ones regenerated.
+var resetPaths = []string{
+	path.Join(vmpath.GuestPersistentDir, "etcd"),
+	vmpath.GuestKubernetesCertsDir,
+	vmpath.GuestManifestsDir,
+	"/etc/kubernetes/*.conf",
+	constants.KubeadmYamlPath,
+}
+
+// resetCmd represents the reset command
+var resetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Wipes the Kubernetes state of a cluster and bootstraps it again, keeping the machines",
+	Long: `Wipes the Kubernetes state of a cluster (etcd, static pod manifests and certificates in the guest) and bootstraps a fresh cluster on the existing machines.
+
+This is a much faster alternative to 'minikube delete && minikube start', as the machines are not re-provisioned and nothing is downloaded again. Images in the container runtime are kept.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			exit.Message(reason.Usage, "Usage: minikube reset")
+		}
+		cname := ClusterFlagValue()
+		co := mustload.Running(cname)
+		cc := co.Config
+		if cc.KubernetesConfig.KubernetesVersion == constants.NoKubernetesVersion {
+			exit.Message(reason.Usage, "The cluster {{.name}} does not run Kubernetes, there is nothing to reset", out.V{"name": cname})
+		}
+
+		out.Step(style.Resetting, "Resetting Kubernetes in cluster {{.name}} ...", out.V{"name": cname})
+		for _, n := range cc.Nodes {
+			if err := resetNode(co.API, *cc, n); err != nil {
+				exit.Error(reason.GuestReset, "Unable to reset the node", err)
+			}
+		}
+
+		cleanupHostRoutes(cname)
+
+		for _, n := range cc.Nodes {
+			n := n
+			h, err := machine.LoadHost(co.API, config.MachineName(*cc, n))
+			if err != nil {
+				exit.Error(reason.GuestLoadHost, "Unable to load host", err)
+			}
+			r, err := machine.CommandRunner(h)
+			if err != nil {
+				exit.Error(reason.InternalCommandRunner, "Unable to get command runner", err)
+			}
+			s := node.Starter{
+				Runner:         r,
+				PreExists:      true,
+				MachineAPI:     co.API,
+				Host:           h,
+				Cfg:            cc,
+				Node:           &n,
+				ExistingAddons: cc.Addons,
+			}
+			if _, err := node.Start(s, n.ControlPlane); err != nil {
+				exit.Error(reason.GuestReset, "Unable to bootstrap the node again", err)
+			}
+		}
+		out.Step(style.Ready, "Cluster {{.name}} has been reset", out.V{"name": cname})
+	},
+}
+
+// resetNode runs 'kubeadm reset' on a node and wipes the rest of its Kubernetes state
+func resetNode(api libmachine.API, cc config.ClusterConfig, n config.Node) error {
+	h, err := machine.LoadHost(api, config.MachineName(cc, n))
+	if err != nil {
+		return errors.Wrap(err, "load host")
+	}
+	r, err := machine.CommandRunner(h)
+	if err != nil {
+		return errors.Wrap(err, "command runner")
+	}
+	bs, err := cluster.Bootstrapper(api, viper.GetString(cmdcfg.Bootstrapper), cc, r)
+	if err != nil {
+		return errors.Wrap(err, "bootstrapper")
+	}
+	cr, err := cruntime.New(cruntime.Config{Type: cc.KubernetesConfig.ContainerRuntime, Runner: r})
+	if err != nil {
+		return errors.Wrap(err, "runtime")
+	}
+	// Unpause the cluster if necessary to avoid hung kubeadm
+	if _, err := cluster.Unpause(cr, r, nil); err != nil {
+		klog.Errorf("unpause failed: %v", err)
+	}
+	if err := bs.DeleteCluster(cc.KubernetesConfig); err != nil {
+		klog.Warningf("kubeadm reset on %s failed, wiping its state anyway: %v", n.Name, err)
+	}
+	return wipeKubernetesState(r)
+}
+
+// wipeKubernetesState removes the Kubernetes state left in the guest after 'kubeadm reset'
+func wipeKubernetesState(r command.Runner) error {
+	if _, err := r.RunCmd(exec.Command("/bin/bash", "-c", "sudo rm -rf "+strings.Join(resetPaths, " "))); err != nil {
+		return errors.Wrap(err, "wipe kubernetes state")
+	}
+	return nil
+}
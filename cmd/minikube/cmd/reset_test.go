@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"path"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+// wipeCmd is the only command wipeKubernetesState may run
+const wipeCmd = `/bin/bash -c "sudo rm -rf /var/lib/minikube/etcd /var/lib/minikube/certs /etc/kubernetes/manifests /etc/kubernetes/*.conf /var/tmp/minikube/kubeadm.yaml"`
+
+// wipedBy returns whether removing the paths of rm removes p: p is one of them, matches one of their globs, or is below one of them
+func wipedBy(rm []string, p string) bool {
+	for _, r := range rm {
+		if ok, _ := path.Match(r, p); ok || strings.HasPrefix(p, r+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func TestWipeKubernetesState(t *testing.T) {
+	f := command.NewFakeCommandRunner()
+	f.SetCommandToOutput(map[string]string{wipeCmd: ""})
+	// the fake runner fails any other command than the registered one
+	if err := wipeKubernetesState(f); err != nil {
+		t.Fatalf("wipeKubernetesState: %v", err)
+	}
+
+	rm := strings.Fields(strings.TrimSuffix(strings.TrimPrefix(wipeCmd, `/bin/bash -c "sudo rm -rf `), `"`))
+	tests := []struct {
+		path  string
+		wiped bool
+	}{
+		{"/var/lib/minikube/etcd/member/snap/db", true},
+		{"/var/lib/minikube/certs/apiserver.crt", true},
+		{"/var/lib/minikube/certs/etcd/server.key", true},
+		{"/etc/kubernetes/manifests/kube-apiserver.yaml", true},
+		{"/etc/kubernetes/admin.conf", true},
+		{"/etc/kubernetes/kubelet.conf", true},
+		{"/var/tmp/minikube/kubeadm.yaml", true},
+		// user data and what is not downloaded again
+		{"/data/db", false},
+		{"/var/lib/minikube/binaries/v1.29.0/kubelet", false},
+		{"/var/lib/minikube/images/pause_3.9", false},
+		{"/var/lib/minikube/kubeconfig", false},
+		{"/tmp/hostpath-provisioner/default/pvc/data", false},
+		{"/var/lib/docker/overlay2/l/ABC", false},
+		{"/var/lib/containerd/io.containerd.content.v1.content/blobs", false},
+		{"/var/lib/kubelet/config.yaml", false},
+		{"/etc/kubernetes/addons/storageclass.yaml", false},
+		{"/etc/kubernetes/manifests.bak/kube-apiserver.yaml", false},
+		{"/home/docker/.bashrc", false},
+	}
+	for _, tc := range tests {
+		if got := wipedBy(rm, tc.path); got != tc.wiped {
+			t.Errorf("wipeKubernetesState wipes %s: %t, want %t", tc.path, got, tc.wiped)
+		}
+	}
+}
+
+func TestWipeKubernetesStateFails(t *testing.T) {
+	f := command.NewFakeCommandRunner()
+	f.SetCommandToOutput(map[string]string{"sudo true": ""})
+	if err := wipeKubernetesState(f); err == nil {
+		t.Errorf("wipeKubernetesState succeeded, although the rm failed")
+	}
+}
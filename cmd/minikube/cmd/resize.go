@@ -0,0 +1,309 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+	pkgutil "k8s.io/minikube/pkg/util"
+)
+
+const resizeDiskFlag = "disk"
+
+var (
+	resizeCPUs   string
+	resizeMemory string
+	resizeDisk   string
+)
+
+// resizeCmd represents the resize command
+var resizeCmd = &cobra.Command{
+	Use:   "resize",
+	Short: "Change the CPUs, memory and disk of an existing cluster",
+	Long: `Changes the CPUs and memory of the machines of an existing cluster to --cpus and --memory, or to the values set with 'minikube config set cpus' and 'minikube config set memory', and grows their disks to --disk, without recreating it.
+
+The docker and podman drivers resize running containers, and the kvm2 and hyperv drivers hot-plug the CPUs and memory where the hypervisor allows it. The machines of the other drivers are stopped, resized and started again. The kubelet is restarted so that the allocatable resources of the nodes match. 'minikube start --cpus --memory' resizes an existing cluster the same way.
+
+The kvm2 driver grows disks live, the qemu2, hyperv, hyperkit, vz, firecracker and cloud-hypervisor drivers while the machines are stopped, and the filesystem of the running machines is then expanded online. The docker and podman volumes have no size of their own and use the disk of the docker or podman host.`,
+	Example: `minikube resize --cpus=4 --memory=8g
+minikube config set memory 8g && minikube resize
+minikube resize --disk=60g`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cname := ClusterFlagValue()
+		var cc *config.ClusterConfig
+		if cmd.Flags().Changed(resizeDiskFlag) {
+			// the filesystems of the grown disks are expanded by the guests
+			co := mustload.Running(cname)
+			co.API.Close()
+			cc = co.Config
+		} else {
+			api, c := mustload.Partial(cname)
+			api.Close()
+			cc = c
+		}
+		if !machine.SupportsResize(cc.Driver) {
+			exit.Message(reason.Usage, "The {{.driver}} driver does not support resizing a cluster", out.V{"driver": cc.Driver})
+		}
+
+		// the flags take precedence over 'minikube config', which viper already holds
+		if cmd.Flags().Changed(cpus) {
+			viper.Set(cpus, resizeCPUs)
+		}
+		if cmd.Flags().Changed(memory) {
+			viper.Set(memory, resizeMemory)
+		}
+		newDisk := cc.DiskSize
+		if cmd.Flags().Changed(resizeDiskFlag) {
+			newDisk = resizeDiskSize(*cc)
+		}
+		if !viper.IsSet(cpus) && !viper.IsSet(memory) && !cmd.Flags().Changed(resizeDiskFlag) {
+			exit.Message(reason.Usage, "Set the new size with --cpus, --memory and --disk, or with 'minikube config set cpus' and 'minikube config set memory'")
+		}
+		newCPUs, newMemory := cc.CPUs, cc.Memory
+		if viper.IsSet(cpus) {
+			validateCPUCount(cc.Driver)
+			newCPUs = getCPUCount(cc.Driver)
+		}
+		if viper.IsSet(memory) {
+			newMemory = getMemorySize(startCmd, cc.Driver)
+			validateRequestedMemorySize(newMemory, cc.Driver)
+		}
+		if newCPUs == cc.CPUs && newMemory == cc.Memory && newDisk == cc.DiskSize {
+			out.Step(style.Check, "The cluster {{.cluster}} already has {{.cpus}} CPUs, {{.memory}}MB of memory and {{.disk}}MB of disk", out.V{"cluster": cc.Name, "cpus": cc.CPUs, "memory": cc.Memory, "disk": cc.DiskSize})
+			return
+		}
+
+		stopped := false
+		if newCPUs != cc.CPUs || newMemory != cc.Memory {
+			out.Step(style.Provisioning, "Resizing cluster {{.cluster}} to {{.cpus}} CPUs and {{.memory}}MB of memory ...", out.V{"cluster": cc.Name, "cpus": newCPUs, "memory": newMemory})
+			var err error
+			if stopped, err = resizeMachines(*cc, newCPUs, newMemory); err != nil {
+				exit.Error(reason.HostResize, "Unable to resize the cluster", err)
+			}
+			cc.CPUs, cc.Memory = newCPUs, newMemory
+		}
+		var grown []config.Node
+		if newDisk != cc.DiskSize {
+			out.Step(style.Provisioning, "Growing the disks of cluster {{.cluster}} to {{.disk}}MB ...", out.V{"cluster": cc.Name, "disk": newDisk})
+			var err error
+			var diskStopped bool
+			if grown, diskStopped, err = growDisks(*cc, newDisk); err != nil {
+				exit.Error(reason.HostResize, "Unable to grow the disks of the cluster", err)
+			}
+			stopped = stopped || diskStopped
+			cc.DiskSize = newDisk
+		}
+		if err := config.SaveProfile(cc.Name, cc); err != nil {
+			exit.Error(reason.HostSaveProfile, "failed to save config", err)
+		}
+		if stopped {
+			// the machines stopped to be resized start again with the rest of the cluster
+			viper.Set(config.ProfileName, cc.Name)
+			runStart(startCmd, nil)
+		}
+		if len(grown) > 0 {
+			expandFilesystems(*cc, grown)
+		}
+		if !stopped {
+			out.Step(style.Ready, "Resized cluster {{.cluster}}", out.V{"cluster": cc.Name})
+		}
+	},
+}
+
+// resizeDiskSize returns the size in MB of --disk, which the disks of the machines of cc can grow to
+func resizeDiskSize(cc config.ClusterConfig) int {
+	size, err := pkgutil.CalculateSizeInMB(resizeDisk)
+	if err != nil {
+		exit.Message(reason.Usage, "Unable to parse disk size '{{.diskSize}}': {{.error}}", out.V{"diskSize": resizeDisk, "error": err})
+	}
+	if driver.IsKIC(cc.Driver) {
+		exit.Message(reason.Usage, "The {{.driver}} volumes of the cluster have no size of their own, free or grow the disk of {{.driver}} instead", out.V{"driver": cc.Driver})
+	}
+	if !machine.SupportsDiskResize(cc.Driver) {
+		exit.Message(reason.Usage, "The {{.driver}} driver does not support growing disks", out.V{"driver": cc.Driver})
+	}
+	if size < cc.DiskSize {
+		exit.Message(reason.Usage, "Disks can only grow, the cluster {{.cluster}} already has {{.disk}}MB of disk", out.V{"cluster": cc.Name, "disk": cc.DiskSize})
+	}
+	return size
+}
+
+// resizeMachines changes the machines of the nodes of cc to cpus and memory, live when the driver can,
+// and returns whether some running machines were stopped instead, for the caller to start them again
+func resizeMachines(cc config.ClusterConfig, cpus int, memory int) (bool, error) {
+	api, err := machine.NewAPIClient()
+	if err != nil {
+		return false, errors.Wrap(err, "libmachine")
+	}
+	defer api.Close()
+
+	resized := cc
+	resized.CPUs, resized.Memory = cpus, memory
+	stopped := false
+	for _, n := range cc.Nodes {
+		// the nodes of a pool with their own resources keep them
+		current, target := config.WithNodePool(cc, n), config.WithNodePool(resized, n)
+		if config.IsWindows(n) || !machine.SupportsResize(config.NodeDriver(cc, n)) || (current.CPUs == target.CPUs && current.Memory == target.Memory) {
+			continue
+		}
+		machineName := config.MachineName(cc, n)
+		st, err := machine.Status(api, machineName)
+		if err != nil {
+			return stopped, err
+		}
+		switch st {
+		case state.None.String():
+			continue
+		case state.Running.String():
+			live, err := machine.LiveResize(cc, n, target.CPUs, target.Memory)
+			if err != nil {
+				return stopped, errors.Wrapf(err, "resize %s", machineName)
+			}
+			if live {
+				restartKubelet(api, machineName)
+				continue
+			}
+			out.Step(style.Restarting, "Restarting node {{.name}} to resize it ...", out.V{"name": machineName})
+			if err := machine.StopHost(api, machineName); err != nil {
+				return stopped, errors.Wrapf(err, "stop %s", machineName)
+			}
+			stopped = true
+		}
+		if err := machine.ResizeStopped(cc, n, target.CPUs, target.Memory); err != nil {
+			return stopped, errors.Wrapf(err, "resize %s", machineName)
+		}
+	}
+	return stopped, nil
+}
+
+// restartKubelet restarts the kubelet of a resized machine, for its node to report the new allocatable resources
+func restartKubelet(api libmachine.API, machineName string) {
+	h, err := machine.LoadHost(api, machineName)
+	if err == nil {
+		var r command.Runner
+		if r, err = machine.CommandRunner(h); err == nil {
+			err = machine.RestartKubelet(r)
+		}
+	}
+	if err != nil {
+		klog.Warningf("restarting the kubelet of %s: %v", machineName, err)
+		out.WarningT("The node {{.name}} reports its previous resources until its kubelet restarts: {{.error}}", out.V{"name": machineName, "error": err})
+	}
+}
+
+// growDisks grows the disks of the machines of the nodes of cc to size MB, live when the driver can, and returns
+// the nodes whose filesystems are to be expanded and whether some running machines were stopped instead
+func growDisks(cc config.ClusterConfig, size int) ([]config.Node, bool, error) {
+	api, err := machine.NewAPIClient()
+	if err != nil {
+		return nil, false, errors.Wrap(err, "libmachine")
+	}
+	defer api.Close()
+
+	var grown []config.Node
+	stopped := false
+	for _, n := range cc.Nodes {
+		if config.IsWindows(n) || !machine.SupportsDiskResize(config.NodeDriver(cc, n)) {
+			continue
+		}
+		machineName := config.MachineName(cc, n)
+		st, err := machine.Status(api, machineName)
+		if err != nil {
+			return grown, stopped, err
+		}
+		switch st {
+		case state.None.String():
+			continue
+		case state.Running.String():
+			live, err := machine.LiveGrowDisk(cc, n, size)
+			if err != nil {
+				return grown, stopped, errors.Wrapf(err, "grow the disk of %s", machineName)
+			}
+			if live {
+				grown = append(grown, n)
+				continue
+			}
+			out.Step(style.Restarting, "Restarting node {{.name}} to grow its disk ...", out.V{"name": machineName})
+			if err := machine.StopHost(api, machineName); err != nil {
+				return grown, stopped, errors.Wrapf(err, "stop %s", machineName)
+			}
+			stopped = true
+		}
+		if err := machine.GrowDiskStopped(cc, n, size); err != nil {
+			return grown, stopped, errors.Wrapf(err, "grow the disk of %s", machineName)
+		}
+		grown = append(grown, n)
+	}
+	return grown, stopped, nil
+}
+
+// expandFilesystems expands the filesystems of the running nodes to their grown disks
+func expandFilesystems(cc config.ClusterConfig, nodes []config.Node) {
+	api, err := machine.NewAPIClient()
+	if err != nil {
+		exit.Error(reason.NewAPIClient, "libmachine", err)
+	}
+	defer api.Close()
+	for _, n := range nodes {
+		machineName := config.MachineName(cc, n)
+		h, err := machine.LoadHost(api, machineName)
+		if err == nil {
+			var r command.Runner
+			if r, err = machine.CommandRunner(h); err == nil {
+				err = machine.ExpandFilesystem(r)
+			}
+		}
+		if err != nil {
+			exit.Error(reason.HostResize, "Unable to expand the filesystems of the grown disks", errors.Wrapf(err, "expand the filesystem of %s", machineName))
+		}
+	}
+}
+
+// resizeExisting resizes the machines of the existing cluster when start changes its CPUs or memory,
+// leaving the machines it stops for start to bring up
+func resizeExisting(existing config.ClusterConfig, cc config.ClusterConfig) {
+	if existing.CPUs == cc.CPUs && existing.Memory == cc.Memory {
+		return
+	}
+	out.Step(style.Provisioning, "Resizing cluster {{.cluster}} to {{.cpus}} CPUs and {{.memory}}MB of memory ...", out.V{"cluster": cc.Name, "cpus": cc.CPUs, "memory": cc.Memory})
+	if _, err := resizeMachines(existing, cc.CPUs, cc.Memory); err != nil {
+		exit.Error(reason.HostResize, "Unable to resize the cluster", err)
+	}
+}
+
+func init() {
+	resizeCmd.Flags().StringVar(&resizeCPUs, cpus, "", fmt.Sprintf("Number of CPUs of the machines, or %q for all the CPUs of the host", constants.MaxResources))
+	resizeCmd.Flags().StringVar(&resizeMemory, memory, "", fmt.Sprintf("Memory of the machines (format: <number>[<unit>], where unit = b, k, m or g), or %q for the most the host allows", constants.MaxResources))
+	resizeCmd.Flags().StringVar(&resizeDisk, resizeDiskFlag, "", "Size to grow the disks of the machines to (format: <number>[<unit>], where unit = b, k, m or g)")
+}
@@ -40,6 +40,8 @@ import (
 	"k8s.io/minikube/pkg/minikube/notify"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/rootless"
+	"k8s.io/minikube/pkg/minikube/transfer"
 	"k8s.io/minikube/pkg/minikube/translate"
 	"k8s.io/minikube/pkg/version"
 )
@@ -49,7 +51,7 @@ var (
 		localpath.MiniPath(),
 		localpath.MakeMiniPath("certs"),
 		localpath.MakeMiniPath("machines"),
-		localpath.MakeMiniPath("cache"),
+		localpath.MakeWritableCachePath(),
 		localpath.MakeMiniPath("config"),
 		localpath.MakeMiniPath("addons"),
 		localpath.MakeMiniPath("files"),
@@ -64,6 +66,11 @@ var RootCmd = &cobra.Command{
 	Short: "minikube quickly sets up a local Kubernetes cluster",
 	Long:  `minikube provisions and manages local Kubernetes clusters optimized for development workflows.`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if localpath.SystemWide() {
+			if err := localpath.EnsureUserDir(); err != nil {
+				exit.Error(reason.HostHomeMkdir, "Error creating the minikube directory of the user", err)
+			}
+		}
 		for _, path := range dirs {
 			if err := os.MkdirAll(path, 0777); err != nil {
 				exit.Error(reason.HostHomeMkdir, "Error creating minikube directory", err)
@@ -82,9 +89,28 @@ var RootCmd = &cobra.Command{
 		// viper maps $MINIKUBE_ROOTLESS to "rootless" property automatically, but it does not do vice versa,
 		// so we map "rootless" property to $MINIKUBE_ROOTLESS expliclity here.
 		// $MINIKUBE_ROOTLESS is referred by KIC runner, which is decoupled from viper.
-		if viper.GetBool(config.Rootless) {
+		if !rootless.Valid(viper.GetString(config.Rootless)) {
+			exit.Message(reason.Usage, "The --rootless flag must be true, false or {{.strict}}", out.V{"strict": rootless.Strict})
+		}
+		if rootless.Enabled(viper.GetString(config.Rootless)) {
 			os.Setenv(constants.MinikubeRootlessEnv, "true")
 		}
+		if err := useRemoteHost(); err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+		enforceLease(cmd)
+		parallelism := viper.GetInt(config.DownloadParallelism)
+		if parallelism < 1 {
+			exit.Message(reason.Usage, "The --{{.flag}} flag must be positive", out.V{"flag": config.DownloadParallelism})
+		}
+		transfer.SetParallelism(parallelism)
+		if limit := viper.GetString(config.DownloadRateLimit); limit != "" {
+			bps, err := transfer.ParseRate(limit)
+			if err != nil {
+				exit.Message(reason.Usage, "Invalid --{{.flag}}: {{.err}}", out.V{"flag": config.DownloadRateLimit, "err": err})
+			}
+			transfer.SetRateLimit(bps)
+		}
 	},
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
 		if err := audit.LogCommandEnd(auditID); err != nil {
@@ -220,11 +246,15 @@ func init() {
 	}
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine) // avoid `generate-docs_test.go` complaining about "Docs are not updated"
 
-	RootCmd.PersistentFlags().StringP(config.ProfileName, "p", constants.DefaultClusterName, `The name of the minikube VM being used. This can be set to allow having multiple instances of minikube independently.`)
+	RootCmd.PersistentFlags().StringP(config.ProfileName, "p", config.DefaultProfileName(), `The name of the minikube VM being used. This can be set to allow having multiple instances of minikube independently.`)
 	RootCmd.PersistentFlags().StringP(configCmd.Bootstrapper, "b", "kubeadm", "The name of the cluster bootstrapper that will set up the Kubernetes cluster.")
 	RootCmd.PersistentFlags().String(config.UserFlag, "", "Specifies the user executing the operation. Useful for auditing operations executed by 3rd party tools. Defaults to the operating system username.")
 	RootCmd.PersistentFlags().Bool(config.SkipAuditFlag, false, "Skip recording the current command in the audit logs.")
-	RootCmd.PersistentFlags().Bool(config.Rootless, false, "Force to use rootless driver (docker and podman driver only)")
+	RootCmd.PersistentFlags().String(config.RemoteHost, "", "Run the cluster on a remote Linux machine, formatted as ssh://[USER@]HOST[:PORT]. The docker or podman driver runs on the remote machine over ssh, while the kubeconfig, tunnels and service URLs keep working from this machine. Kept in the profile, the next commands of the profile do not need it.")
+	RootCmd.PersistentFlags().String(config.Rootless, "false", "Force to use rootless driver (docker and podman driver only). With 'strict', start also fails if it would run a privileged helper on the host, such as sudo or setuid binaries")
+	RootCmd.PersistentFlags().Lookup(config.Rootless).NoOptDefVal = "true"
+	RootCmd.PersistentFlags().Int(config.DownloadParallelism, transfer.DefaultParallelism, "The number of images, and of parts of the preloads, downloaded concurrently.")
+	RootCmd.PersistentFlags().String(config.DownloadRateLimit, "", "The bandwidth all the downloads may use together, per second (ex. 10MB). Unlimited by default.")
 
 	translate.DetermineLocale()
 
@@ -233,12 +263,19 @@ func init() {
 			Message: translate.T("Basic Commands:"),
 			Commands: []*cobra.Command{
 				startCmd,
+				applyCmd,
 				statusCmd,
 				stopCmd,
 				deleteCmd,
+				resetCmd,
+				resizeCmd,
+				snapshotCmd,
+				restoreCmd,
 				dashboardCmd,
 				pauseCmd,
 				unpauseCmd,
+				throttleCmd,
+				watchCmd,
 			},
 		},
 		{
@@ -247,7 +284,11 @@ func init() {
 				dockerEnvCmd,
 				podmanEnvCmd,
 				cacheCmd,
+				bundleCmd,
+				preloadCmd,
 				imageCmd,
+				registryMirrorCmd,
+				registryCmd,
 			},
 		},
 		{
@@ -256,7 +297,11 @@ func init() {
 				configCmd.AddonsCmd,
 				configCmd.ConfigCmd,
 				configCmd.ProfileCmd,
+				cloneCmd,
+				lockCmd,
 				updateContextCmd,
+				kubeconfigCmd,
+				systemInstallCmd,
 			},
 		},
 		{
@@ -264,6 +309,13 @@ func init() {
 			Commands: []*cobra.Command{
 				serviceCmd,
 				tunnelCmd,
+				interceptCmd,
+				routeCmd,
+				dnsCmd,
+				mdnsCmd,
+				portForwardCmd,
+				networkCmd,
+				proxyCmd,
 			},
 		},
 		{
@@ -274,6 +326,8 @@ func init() {
 				kubectlCmd,
 				nodeCmd,
 				cpCmd,
+				workloadsCmd,
+				devCmd,
 			},
 		},
 		{
@@ -283,7 +337,11 @@ func init() {
 				sshHostCmd,
 				ipCmd,
 				logsCmd,
+				doctorCmd,
+				certsCmd,
+				preflightCmd,
 				updateCheckCmd,
+				releaseNotesCmd,
 				versionCmd,
 				optionsCmd,
 			},
@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/spf13/cobra"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/tunnel"
+	pkgnetwork "k8s.io/minikube/pkg/network"
+)
+
+var routePods bool
+
+// routeCmd represents the set of route subcommands
+var routeCmd = &cobra.Command{
+	Use:   "route",
+	Short: "Manage host routes to the cluster networks",
+	Long:  "Manage host routes to the service and pod networks of the cluster, so that ClusterIPs and pod IPs can be reached directly from the host without 'minikube tunnel'.",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.Message(reason.Usage, "Usage: minikube route [add|delete|list]")
+	},
+}
+
+// routeAddCmd represents the route add command
+var routeAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add host routes to the service network, and to the pod networks with --pods",
+	Long: `Add host routes to the service network of the cluster via the control plane node, and with --pods to the pod network of each node via that node.
+
+The routes stay until 'minikube route delete', and are removed automatically by 'minikube stop' and 'minikube delete'. Adding routes requires sudo.`,
+	Example: "minikube route add --pods",
+	Run: func(cmd *cobra.Command, args []string) {
+		cname := ClusterFlagValue()
+		co := mustload.Healthy(cname)
+
+		if driver.NeedsPortForward(co.Config.Driver) {
+			exit.Message(reason.Unimplemented, "The {{.driver}} driver does not provide IP connectivity to the nodes from the host, use 'minikube tunnel' instead", out.V{"driver": co.Config.Driver})
+		}
+		if driver.IsQEMU(co.Config.Driver) && pkgnetwork.IsBuiltinQEMU(co.Config.Network) {
+			exit.Message(reason.Unimplemented, "minikube route is not supported with the builtin network on QEMU, try starting minikube with '--network=socket_vmnet'")
+		}
+		if driver.BareMetal(co.Config.Driver) {
+			exit.Message(reason.Usage, "The cluster networks are already routed on the host with the none driver")
+		}
+
+		routes := []tunnel.HostRoute{{CIDR: co.Config.KubernetesConfig.ServiceCIDR, Gateway: co.CP.IP.String()}}
+		if routePods {
+			client, err := kapi.Client(cname)
+			if err != nil {
+				exit.Error(reason.InternalKubernetesClient, "error creating clientset", err)
+			}
+			nodes, err := client.CoreV1().Nodes().List(context.Background(), meta.ListOptions{})
+			if err != nil {
+				exit.Error(reason.SvcRoute, "Unable to list the nodes", err)
+			}
+			routes = append(routes, tunnel.PodRoutes(nodes.Items)...)
+		}
+
+		conflicts, err := tunnel.LocalConflicts(routes)
+		if err != nil {
+			klog.Warningf("unable to check routes for conflicts with local networks: %v", err)
+		}
+		if len(conflicts) > 0 {
+			exit.Message(reason.SvcRoute, "The cluster networks conflict with the networks of this host:\n{{.conflicts}}", out.V{"conflicts": strings.Join(conflicts, "\n")})
+		}
+
+		if err := tunnel.AddHostRoutes(cname, routes); err != nil {
+			exit.Error(reason.SvcRoute, "Unable to add the host routes", err)
+		}
+		for _, r := range routes {
+			out.Step(style.Connectivity, "Added route {{.route}}", out.V{"route": r.String()})
+		}
+	},
+}
+
+// routeDeleteCmd represents the route delete command
+var routeDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete the host routes added by 'minikube route add'",
+	Long:  "Delete the host routes added by 'minikube route add'",
+	Run: func(cmd *cobra.Command, args []string) {
+		deleted, err := tunnel.DeleteHostRoutes(ClusterFlagValue())
+		for _, r := range deleted {
+			out.Step(style.Deleted, "Deleted route {{.route}}", out.V{"route": r.String()})
+		}
+		if err != nil {
+			exit.Error(reason.SvcRoute, "Unable to delete the host routes", err)
+		}
+		if len(deleted) == 0 {
+			out.Styled(style.Empty, "No routes to delete")
+		}
+	},
+}
+
+// routeListCmd represents the route list command
+var routeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the host routes added by 'minikube route add'",
+	Long:  "List the host routes added by 'minikube route add'",
+	Run: func(cmd *cobra.Command, args []string) {
+		routes, err := tunnel.LoadHostRoutes(ClusterFlagValue())
+		if err != nil {
+			exit.Error(reason.SvcRoute, "Unable to read the host routes", err)
+		}
+		for _, r := range routes {
+			out.String("%s\n", r.String())
+		}
+	},
+}
+
+// cleanupHostRoutes deletes the host routes of a profile, which would point to a stopped or deleted node otherwise
+func cleanupHostRoutes(profile string) {
+	deleted, err := tunnel.DeleteHostRoutes(profile)
+	if err != nil {
+		out.WarningT("Unable to delete the host routes of {{.profile}}: {{.error}}", out.V{"profile": profile, "error": err})
+	}
+	for _, r := range deleted {
+		klog.Infof("deleted host route %s", r)
+	}
+}
+
+func init() {
+	routeAddCmd.Flags().BoolVar(&routePods, "pods", false, "Also add routes to the pod network of each node")
+	routeCmd.AddCommand(routeAddCmd)
+	routeCmd.AddCommand(routeDeleteCmd)
+	routeCmd.AddCommand(routeListCmd)
+}
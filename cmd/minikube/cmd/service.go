@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"net/url"
@@ -31,8 +32,10 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/drivers/qemu"
 	"k8s.io/minikube/pkg/kapi"
 	"k8s.io/minikube/pkg/minikube/browser"
 	"k8s.io/minikube/pkg/minikube/driver"
@@ -44,6 +47,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/service"
 	"k8s.io/minikube/pkg/minikube/style"
 	"k8s.io/minikube/pkg/minikube/tunnel/kic"
+	"k8s.io/minikube/pkg/minikube/tunnel/usernet"
 	pkgnetwork "k8s.io/minikube/pkg/network"
 )
 
@@ -87,14 +91,6 @@ var serviceCmd = &cobra.Command{
 		cname := ClusterFlagValue()
 		co := mustload.Healthy(cname)
 
-		if driver.IsQEMU(co.Config.Driver) && pkgnetwork.IsBuiltinQEMU(co.Config.Network) {
-			msg := "minikube service is not currently implemented with the builtin network on QEMU"
-			if runtime.GOOS == "darwin" {
-				msg += ", try starting minikube with '--network=socket_vmnet'"
-			}
-			exit.Message(reason.Unimplemented, msg)
-		}
-
 		var services service.URLs
 		services, err := service.GetServiceURLs(co.API, co.Config.Name, namespace, serviceURLTemplate)
 		if err != nil {
@@ -118,6 +114,10 @@ var serviceCmd = &cobra.Command{
 You may select another namespace by using 'minikube service {{.service}} -n <namespace>'. Or list out all the services using 'minikube service list'`, out.V{"service": args[0], "namespace": namespace})
 		}
 
+		if driver.IsQEMU(co.Config.Driver) && pkgnetwork.IsBuiltinQEMU(co.Config.Network) {
+			forwardQEMUNodePorts(co, services)
+		}
+
 		var data [][]string
 		for _, svc := range services {
 			openUrls, err := service.WaitForService(co.API, co.Config.Name, namespace, svc.Name, serviceURLTemplate, serviceURLMode, https, wait, interval)
@@ -184,7 +184,7 @@ func startKicServiceTunnel(services service.URLs, configName, driverName string)
 
 	var data [][]string
 	for _, svc := range services {
-		port, err := oci.ForwardedPort(driverName, configName, 22)
+		port, err := kicSSHPort(driverName, configName)
 		if err != nil {
 			exit.Error(reason.DrvPortForward, "error getting ssh port", err)
 		}
@@ -228,6 +228,28 @@ func startKicServiceTunnel(services service.URLs, configName, driverName string)
 	<-ctrlC
 }
 
+// forwardQEMUNodePorts forwards the NodePorts of the services to the same ports of the host, as the
+// builtin network of QEMU is not routable. The forwards last until the VM stops.
+func forwardQEMUNodePorts(co mustload.ClusterController, services service.URLs) {
+	clientset, err := kapi.Client(co.Config.Name)
+	if err != nil {
+		exit.Error(reason.InternalKubernetesClient, "error creating clientset", err)
+	}
+	d, ok := co.CP.Host.Driver.(*qemu.Driver)
+	if !ok {
+		exit.Message(reason.Unimplemented, "minikube service cannot forward ports with the {{.driver}} driver", out.V{"driver": co.Config.Driver})
+	}
+	for _, svc := range services {
+		s, err := clientset.CoreV1().Services(svc.Namespace).Get(context.Background(), svc.Name, metav1.GetOptions{})
+		if err != nil {
+			exit.Error(reason.SvcNotFound, "error getting service", err)
+		}
+		if err := usernet.Ensure(d, usernet.NodePortForwards(*s, "127.0.0.1")); err != nil {
+			exit.Error(reason.SvcTunnelStart, "error forwarding the node ports of the service", err)
+		}
+	}
+}
+
 func mutateURLs(serviceName string, urls []string) ([]string, error) {
 	formattedUrls := make([]string, 0)
 	for _, rawURL := range urls {
@@ -236,6 +258,11 @@ func mutateURLs(serviceName string, urls []string) ([]string, error) {
 		if err != nil {
 			exit.Error(reason.SvcTunnelStart, "No valid URL found for tunnel.", err)
 		}
+		// the --format template is for the http ports
+		if parsedURL.Scheme == "udp" {
+			formattedUrls = append(formattedUrls, rawURL)
+			continue
+		}
 		port, err := strconv.Atoi(parsedURL.Port())
 		if err != nil {
 			exit.Error(reason.SvcTunnelStart, "No valid port found for tunnel.", err)
@@ -269,14 +296,14 @@ func openURLs(urls [][]string) {
 			continue
 		}
 
-		_, err := url.Parse(u[3])
+		parsedURL, err := url.Parse(u[3])
 		if err != nil {
 			klog.Warningf("failed to parse url %q: %v (will not open)", u[3], err)
 			out.String(fmt.Sprintf("%s\n", u))
 			continue
 		}
 
-		if serviceURLMode {
+		if serviceURLMode || parsedURL.Scheme == "udp" {
 			out.String(fmt.Sprintf("%s\n", u))
 			continue
 		}
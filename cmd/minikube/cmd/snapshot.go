@@ -0,0 +1,394 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/snapshot"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var snapshotMethod string
+
+// snapshotCmd represents the snapshot command
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Take, list and delete snapshots of the state of a cluster",
+	Long: `Takes snapshots of the state of a cluster, which 'minikube restore' rolls the cluster back to, to checkpoint a working cluster before risky experiments.
+
+The virtualbox and qemu2 drivers snapshot the disks of the machines. With the other drivers, or with --method=etcd, the snapshot holds the etcd data of the control planes and the images of the workloads. The kvm2 driver does not support disk snapshots, and requires --method=etcd.`,
+}
+
+// snapshotCreateCmd represents the snapshot create command
+var snapshotCreateCmd = &cobra.Command{
+	Use:     "create NAME",
+	Short:   "Take a snapshot of the state of a cluster",
+	Long:    "Takes the snapshot NAME of the state of a running cluster. The etcd snapshots stop the control planes for the time etcd data is copied, and the qemu2 driver stops the machines to snapshot their disks.",
+	Example: "minikube snapshot create before-upgrade",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := snapshot.ValidateName(name); err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+		co := mustload.Running(ClusterFlagValue())
+		cc := co.Config
+		if snapshot.Exists(cc.Name, name) {
+			exit.Message(reason.Usage, "The cluster {{.cluster}} already has a snapshot {{.name}}, delete it with 'minikube snapshot delete {{.name}}'", out.V{"cluster": cc.Name, "name": name})
+		}
+
+		method := snapshotMethod
+		if method == "" && cc.Driver == driver.KVM2 {
+			exit.Message(reason.Usage, "The kvm2 driver does not support snapshots of the disks of its machines, pass --method=etcd to snapshot the etcd data and the images of the cluster instead")
+		}
+		if method == "" {
+			method = snapshot.MethodEtcd
+			if diskSnapshots(*cc) {
+				method = snapshot.MethodDisk
+			}
+		}
+		m := snapshot.Manifest{
+			Name:              name,
+			Profile:           cc.Name,
+			Created:           time.Now(),
+			Method:            method,
+			Driver:            cc.Driver,
+			KubernetesVersion: cc.KubernetesConfig.KubernetesVersion,
+		}
+		for _, n := range cc.Nodes {
+			m.Machines = append(m.Machines, config.MachineName(*cc, n))
+		}
+
+		out.Step(style.Waiting, "Taking the {{.method}} snapshot {{.name}} of cluster {{.cluster}} ...", out.V{"method": method, "name": name, "cluster": cc.Name})
+		var err error
+		switch method {
+		case snapshot.MethodDisk:
+			if !diskSnapshots(*cc) {
+				exit.Message(reason.Usage, "The {{.driver}} driver does not snapshot disks, use --method=etcd", out.V{"driver": cc.Driver})
+			}
+			err = takeDiskSnapshot(co.API, cc, name)
+		case snapshot.MethodEtcd:
+			m.Images, err = takeEtcdSnapshot(co.API, cc, name)
+		default:
+			exit.Message(reason.Usage, "The --method flag must be one of: {{.disk}}, {{.etcd}}", out.V{"disk": snapshot.MethodDisk, "etcd": snapshot.MethodEtcd})
+		}
+		if err == nil {
+			err = snapshot.Save(m)
+		}
+		if err != nil {
+			if rerr := snapshot.Remove(cc.Name, name); rerr != nil {
+				klog.Warningf("removing the incomplete snapshot: %v", rerr)
+			}
+			exit.Error(reason.HostSnapshot, "Unable to take the snapshot", err)
+		}
+		out.Step(style.Ready, "Took the snapshot {{.name}}, roll the cluster back to it with: minikube restore {{.name}}", out.V{"name": name})
+	},
+}
+
+// snapshotListCmd represents the snapshot list command
+var snapshotListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the snapshots of a cluster",
+	Run: func(cmd *cobra.Command, args []string) {
+		cname := ClusterFlagValue()
+		ms, err := snapshot.List(cname)
+		if err != nil {
+			exit.Error(reason.HostSnapshot, "Unable to list the snapshots", err)
+		}
+		if len(ms) == 0 {
+			out.Step(style.Empty, "The cluster {{.cluster}} has no snapshots", out.V{"cluster": cname})
+			return
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Name", "Created", "Method", "Kubernetes", "Nodes"})
+		table.SetAutoFormatHeaders(false)
+		table.SetBorders(tablewriter.Border{Left: true, Top: true, Right: true, Bottom: true})
+		table.SetCenterSeparator("|")
+		for _, m := range ms {
+			table.Append([]string{m.Name, m.Created.Format(time.RFC3339), m.Method, m.KubernetesVersion, strings.Join(m.Machines, ", ")})
+		}
+		table.Render()
+	},
+}
+
+// snapshotDeleteCmd represents the snapshot delete command
+var snapshotDeleteCmd = &cobra.Command{
+	Use:     "delete NAME",
+	Short:   "Delete a snapshot of a cluster",
+	Example: "minikube snapshot delete before-upgrade",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cname := ClusterFlagValue()
+		m, err := snapshot.Load(cname, args[0])
+		if err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+		if m.Method == snapshot.MethodDisk {
+			api, cc := mustload.Partial(cname)
+			api.Close()
+			for _, n := range cc.Nodes {
+				machineName := config.MachineName(*cc, n)
+				if !contains(m.Machines, machineName) {
+					continue
+				}
+				if err := machine.DeleteDiskSnapshot(config.NodeDriver(*cc, n), machineName, m.Name); err != nil {
+					out.WarningT("Unable to delete the disk snapshot of {{.name}}: {{.error}}", out.V{"name": machineName, "error": err})
+				}
+			}
+		}
+		if err := snapshot.Remove(cname, m.Name); err != nil {
+			exit.Error(reason.HostSnapshot, "Unable to delete the snapshot", err)
+		}
+		out.Step(style.Deleted, "Deleted the snapshot {{.name}}", out.V{"name": m.Name})
+	},
+}
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore NAME",
+	Short: "Roll a cluster back to a snapshot",
+	Long: `Rolls a cluster back to the snapshot NAME taken by 'minikube snapshot create'.
+
+The machines of a disk snapshot are stopped, their disks reverted, and the cluster started again. An etcd snapshot replaces the etcd data of the control planes and loads the images of the workloads the nodes no longer have, restarting the containers of the nodes.`,
+	Example: "minikube restore before-upgrade",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cname := ClusterFlagValue()
+		m, err := snapshot.Load(cname, args[0])
+		if err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+
+		api, cc := mustload.Partial(cname)
+		var missing []string
+		for _, machineName := range m.Machines {
+			if !hasMachine(*cc, machineName) {
+				missing = append(missing, machineName)
+			}
+		}
+		if len(missing) > 0 {
+			exit.Message(reason.HostSnapshot, "The nodes {{.nodes}} of the snapshot were deleted from the cluster, which can not be rolled back to it", out.V{"nodes": strings.Join(missing, ", ")})
+		}
+
+		out.Step(style.Restarting, "Rolling cluster {{.cluster}} back to the snapshot {{.name}} of {{.created}} ...", out.V{"cluster": cname, "name": m.Name, "created": m.Created.Format(time.RFC3339)})
+		switch m.Method {
+		case snapshot.MethodDisk:
+			for _, n := range cc.Nodes {
+				machineName := config.MachineName(*cc, n)
+				if !contains(m.Machines, machineName) {
+					continue
+				}
+				stop(api, machineName)
+				if err := machine.RestoreDiskSnapshot(config.NodeDriver(*cc, n), machineName, m.Name); err != nil {
+					exit.Error(reason.HostSnapshot, "Unable to restore the disk snapshot", err)
+				}
+			}
+			api.Close()
+			// the kubeconfig and the config of the cluster are the ones of the running cluster
+			runStart(startCmd, nil)
+		case snapshot.MethodEtcd:
+			api.Close()
+			co := mustload.Running(cname)
+			err = restoreEtcdSnapshot(co.API, co.Config, *m)
+			if err != nil {
+				exit.Error(reason.HostSnapshot, "Unable to restore the snapshot", err)
+			}
+		default:
+			exit.Message(reason.HostSnapshot, "The snapshot {{.name}} was taken with the unknown method {{.method}}", out.V{"name": m.Name, "method": m.Method})
+		}
+		if m.KubernetesVersion != cc.KubernetesConfig.KubernetesVersion {
+			out.WarningT("The snapshot was taken with Kubernetes {{.snapshot}}, the cluster now runs {{.version}}", out.V{"snapshot": m.KubernetesVersion, "version": cc.KubernetesConfig.KubernetesVersion})
+		}
+		out.Step(style.Ready, "Rolled cluster {{.cluster}} back to the snapshot {{.name}}", out.V{"cluster": cname, "name": m.Name})
+	},
+}
+
+// diskSnapshots returns whether the drivers of all the nodes of cc snapshot disks
+func diskSnapshots(cc config.ClusterConfig) bool {
+	for _, n := range cc.Nodes {
+		if config.IsWindows(n) || !machine.SupportsDiskSnapshots(config.NodeDriver(cc, n)) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasMachine returns whether cc has a node of the machine machineName
+func hasMachine(cc config.ClusterConfig, machineName string) bool {
+	for _, n := range cc.Nodes {
+		if config.MachineName(cc, n) == machineName {
+			return true
+		}
+	}
+	return false
+}
+
+// takeDiskSnapshot snapshots the disks of the machines of cc, stopping and starting them again when the driver needs it
+func takeDiskSnapshot(api libmachine.API, cc *config.ClusterConfig, name string) error {
+	needsStop := false
+	for _, n := range cc.Nodes {
+		needsStop = needsStop || machine.DiskSnapshotNeedsStop(config.NodeDriver(*cc, n))
+	}
+	if needsStop {
+		for _, n := range cc.Nodes {
+			stop(api, config.MachineName(*cc, n))
+		}
+	}
+	for _, n := range cc.Nodes {
+		if err := machine.TakeDiskSnapshot(config.NodeDriver(*cc, n), config.MachineName(*cc, n), name); err != nil {
+			return err
+		}
+	}
+	if needsStop {
+		out.Step(style.Restarting, "Starting the cluster {{.cluster}} again", out.V{"cluster": cc.Name})
+		runStart(startCmd, nil)
+	}
+	return nil
+}
+
+// takeEtcdSnapshot exports the images of the nodes of cc and copies the etcd data of its control planes,
+// all the etcd members being stopped for the data to be consistent, and returns the images of each machine
+func takeEtcdSnapshot(api libmachine.API, cc *config.ClusterConfig, name string) (map[string][]string, error) {
+	runners := snapshotRunners(api, cc)
+	imgs := map[string][]string{}
+	for _, n := range cc.Nodes {
+		machineName := config.MachineName(*cc, n)
+		r, ok := runners[machineName]
+		if !ok {
+			continue
+		}
+		out.Step(style.Waiting, "Exporting the images of {{.name}} ...", out.V{"name": machineName})
+		names, err := machine.ExportImages(cc, r, snapshot.ImagesDir(cc.Name, name))
+		if err != nil {
+			return nil, err
+		}
+		imgs[machineName] = names
+	}
+
+	var cps []config.Node
+	for _, n := range cc.Nodes {
+		if n.ControlPlane {
+			cps = append(cps, n)
+		}
+	}
+	defer func() {
+		for _, n := range cps {
+			if err := machine.StartKubelet(runners[config.MachineName(*cc, n)]); err != nil {
+				out.WarningT("Unable to start the kubelet of {{.name}}: {{.error}}", out.V{"name": config.MachineName(*cc, n), "error": err})
+			}
+		}
+	}()
+	for _, n := range cps {
+		if err := machine.StopEtcd(runners[config.MachineName(*cc, n)]); err != nil {
+			return nil, err
+		}
+	}
+	for _, n := range cps {
+		machineName := config.MachineName(*cc, n)
+		out.Step(style.Waiting, "Copying the etcd data of {{.name}} ...", out.V{"name": machineName})
+		if err := machine.SaveEtcd(runners[machineName], snapshot.EtcdPath(cc.Name, name, machineName)); err != nil {
+			return nil, err
+		}
+	}
+	return imgs, nil
+}
+
+// restoreEtcdSnapshot replaces the etcd data of the control planes of cc with the one of m, and loads the images of m
+// the nodes no longer have
+func restoreEtcdSnapshot(api libmachine.API, cc *config.ClusterConfig, m snapshot.Manifest) error {
+	runners := snapshotRunners(api, cc)
+	for machineName, imgs := range m.Images {
+		r, ok := runners[machineName]
+		if !ok || len(imgs) == 0 {
+			continue
+		}
+		out.Step(style.Waiting, "Loading the images of {{.name}} ...", out.V{"name": machineName})
+		if err := machine.ImportImages(cc, r, imgs, snapshot.ImagesDir(m.Profile, m.Name)); err != nil {
+			return err
+		}
+	}
+
+	var cps []string
+	for _, n := range cc.Nodes {
+		machineName := config.MachineName(*cc, n)
+		if n.ControlPlane && contains(m.Machines, machineName) {
+			cps = append(cps, machineName)
+		}
+	}
+	defer func() {
+		for _, r := range runners {
+			if err := machine.StartKubelet(r); err != nil {
+				klog.Warningf("starting the kubelet: %v", err)
+			}
+		}
+	}()
+	for _, r := range runners {
+		if err := machine.StopContainers(r); err != nil {
+			return err
+		}
+	}
+	for _, machineName := range cps {
+		out.Step(style.Waiting, "Restoring the etcd data of {{.name}} ...", out.V{"name": machineName})
+		if err := machine.RestoreEtcd(runners[machineName], snapshot.EtcdPath(m.Profile, m.Name, machineName)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotRunners returns the command runners of the Linux nodes of cc, which must be running
+func snapshotRunners(api libmachine.API, cc *config.ClusterConfig) map[string]command.Runner {
+	runners := map[string]command.Runner{}
+	for _, n := range cc.Nodes {
+		if config.IsWindows(n) {
+			continue
+		}
+		machineName := config.MachineName(*cc, n)
+		h, err := machine.GetHost(api, *cc, n)
+		if err != nil {
+			exit.Error(reason.GuestLoadHost, "Error getting host", err)
+		}
+		r, err := machine.CommandRunner(h)
+		if err != nil {
+			exit.Error(reason.InternalCommandRunner, "Failed to get command runner", err)
+		}
+		runners[machineName] = r
+	}
+	return runners
+}
+
+func init() {
+	snapshotCreateCmd.Flags().StringVar(&snapshotMethod, "method", "", "How to capture the state of the cluster: disk snapshots the disks of the machines with the virtualbox and qemu2 drivers, etcd copies the etcd data and exports the images of the workloads (defaults to disk when the driver supports it)")
+	snapshotCmd.AddCommand(snapshotCreateCmd)
+	snapshotCmd.AddCommand(snapshotListCmd)
+	snapshotCmd.AddCommand(snapshotDeleteCmd)
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -26,6 +27,8 @@ import (
 	"os"
 	"os/exec"
 	"os/user"
+	"path"
+	"path/filepath"
 	"regexp"
 	"runtime"
 	"sort"
@@ -46,6 +49,7 @@ import (
 	"github.com/spf13/viper"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/minikube/pkg/minikube/command"
 	"k8s.io/minikube/pkg/minikube/firewall"
 	netutil "k8s.io/minikube/pkg/network"
@@ -53,8 +57,12 @@ import (
 	"k8s.io/klog/v2"
 	cmdcfg "k8s.io/minikube/cmd/minikube/cmd/config"
 	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/drivers/plugin"
+	"k8s.io/minikube/pkg/drivers/vz"
 	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
 	"k8s.io/minikube/pkg/minikube/bootstrapper/images"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/cni"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/cruntime"
@@ -63,20 +71,30 @@ import (
 	"k8s.io/minikube/pkg/minikube/driver"
 	"k8s.io/minikube/pkg/minikube/driver/auxdriver"
 	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/hooks"
+	"k8s.io/minikube/pkg/minikube/idle"
+	"k8s.io/minikube/pkg/minikube/kata"
 	"k8s.io/minikube/pkg/minikube/kubeconfig"
 	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mountsync"
 	"k8s.io/minikube/pkg/minikube/mustload"
 	"k8s.io/minikube/pkg/minikube/node"
 	"k8s.io/minikube/pkg/minikube/notify"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/out/register"
 	"k8s.io/minikube/pkg/minikube/pause"
+	"k8s.io/minikube/pkg/minikube/portforward"
 	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/sandbox"
+	"k8s.io/minikube/pkg/minikube/sshutil"
 	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/tuning"
+	"k8s.io/minikube/pkg/minikube/wasm"
 	pkgtrace "k8s.io/minikube/pkg/trace"
 
 	"k8s.io/minikube/pkg/minikube/registry"
+	"k8s.io/minikube/pkg/minikube/rootless"
 	"k8s.io/minikube/pkg/minikube/translate"
 	"k8s.io/minikube/pkg/util"
 	"k8s.io/minikube/pkg/version"
@@ -162,8 +180,12 @@ func runStart(cmd *cobra.Command, _ []string) {
 	displayVersion(version.GetVersion())
 	go download.CleanUpOlderPreloads()
 
-	// Avoid blocking execution on optional HTTP fetches
-	go notify.MaybePrintUpdateTextFromGithub()
+	if viper.GetBool(offline) {
+		download.SetOffline()
+	} else {
+		// Avoid blocking execution on optional HTTP fetches
+		go notify.MaybePrintUpdateTextFromGithub()
+	}
 
 	displayEnviron(os.Environ())
 	if viper.GetBool(force) {
@@ -185,6 +207,9 @@ func runStart(cmd *cobra.Command, _ []string) {
 		out.WarningT("Profile name '{{.name}}' is not valid", out.V{"name": ClusterFlagValue()})
 		exit.Message(reason.Usage, "Only alphanumeric and dashes '-' are permitted. Minimum 2 characters, starting with alphanumeric.")
 	}
+	if !config.ProfileNameScoped(ClusterFlagValue()) {
+		exit.Message(reason.Usage, "minikube is installed system-wide, so profile names must start with '{{.prefix}}' to not collide with the clusters of other users", out.V{"prefix": config.ProfilePrefix()})
+	}
 	existing, err := config.Load(ClusterFlagValue())
 	if err != nil && !config.IsNotExist(err) {
 		kind := reason.HostConfigLoad
@@ -194,6 +219,11 @@ func runStart(cmd *cobra.Command, _ []string) {
 		exit.Message(kind, "Unable to load config: {{.error}}", out.V{"error": err})
 	}
 
+	if cancel, _ := cmd.Flags().GetBool(cancelScheduled); cancel {
+		cancelScheduledStart(ClusterFlagValue(), existing)
+		return
+	}
+
 	if existing != nil {
 		upgradeExistingConfig(cmd, existing)
 	} else {
@@ -221,8 +251,14 @@ func runStart(cmd *cobra.Command, _ []string) {
 		}
 	}
 
+	warnNestedEnvironment(detect.Nested(), ds.Name)
+
 	useForce := viper.GetBool(force)
 
+	if existing != nil && driver.IsVM(existing.Driver) {
+		validateVirtiofsMountChange(cmd, existing)
+	}
+
 	starter, err := provisionWithDriver(cmd, ds, existing)
 	if err != nil {
 		node.ExitIfFatal(err, useForce)
@@ -267,7 +303,7 @@ func runStart(cmd *cobra.Command, _ []string) {
 	validateBuiltImageVersion(starter.Runner, ds.Name)
 
 	if existing != nil && driver.IsKIC(existing.Driver) {
-		if viper.GetBool(createMount) {
+		if viper.GetBool(createMount) && viper.GetString(mountTypeFlag) != constants.SyncMountType {
 			old := ""
 			if len(existing.ContainerVolumeMounts) > 0 {
 				old = existing.ContainerVolumeMounts[0]
@@ -288,11 +324,58 @@ func runStart(cmd *cobra.Command, _ []string) {
 		exit.Error(reason.GuestStart, "failed to start node", err)
 	}
 
+	startPersistentMounts(*starter.Cfg)
+
+	if existing != nil && starter.Cfg.KubernetesConfig.KubernetesVersion != constants.NoKubernetesVersion {
+		releaseWorkloads(starter.Cfg.Name)
+	}
+
+	if err := hooks.Run(hooks.PostStart, *starter.Cfg, starter.Runner, starter.Cfg.PostStartHooks); err != nil {
+		exit.Error(reason.HostHook, "Failed to run the post-start hooks", err)
+	}
+
+	startPortForwards(*starter.Cfg)
+	startIdleProxy(starter.Cfg)
+	scheduleStart(cmd, starter.Cfg)
+
+	enforceCacheQuota()
+
 	if err := showKubectlInfo(kubeconfig, starter.Node.KubernetesVersion, starter.Node.ContainerRuntime, starter.Cfg.Name); err != nil {
 		klog.Errorf("kubectl info: %v", err)
 	}
 }
 
+// startPortForwards restarts the background process forwarding the ports declared with --port-forward
+func startPortForwards(cc config.ClusterConfig) {
+	if err := killPortForwardProcess(cc.Name); err != nil {
+		klog.Warningf("failed to kill the port-forward process: %v", err)
+	}
+	if len(cc.PortForwards) == 0 || cc.KubernetesConfig.KubernetesVersion == constants.NoKubernetesVersion {
+		return
+	}
+	if err := portforward.StartProcess(cc.Name); err != nil {
+		out.WarningT("Unable to start forwarding the ports: {{.error}}", out.V{"error": err})
+		return
+	}
+	for _, f := range cc.PortForwards {
+		out.Step(style.Connectivity, "Forwarding 127.0.0.1:{{.host_port}} to {{.forward}}", out.V{"host_port": f.HostPort, "forward": portforward.String(f)})
+	}
+}
+
+// startPersistentMounts restarts the background mount processes of the mounts declared with --persistent-mount
+func startPersistentMounts(cc config.ClusterConfig) {
+	killPersistentMountProcesses(cc.Name)
+	if len(cc.PersistentMounts) == 0 {
+		return
+	}
+	for _, m := range cc.PersistentMounts {
+		out.Step(style.Mounting, "Creating mount {{.name}} ...", out.V{"name": cluster.MountSpec(m)})
+	}
+	if err := cluster.StartPersistentMounts(cc.Name, cc); err != nil {
+		out.WarningT("Unable to start the mounts: {{.error}}", out.V{"error": err})
+	}
+}
+
 func provisionWithDriver(cmd *cobra.Command, ds registry.DriverState, existing *config.ClusterConfig) (node.Starter, error) {
 	driverName := ds.Name
 	klog.Infof("selected driver: %s", driverName)
@@ -305,6 +388,9 @@ func provisionWithDriver(cmd *cobra.Command, ds registry.DriverState, existing *
 	virtualBoxMacOS13PlusWarning(driverName)
 	validateFlags(cmd, driverName)
 	validateUser(driverName)
+	if viper.GetString(config.Rootless) == rootless.Strict {
+		validateRootlessStrict(driverName, existing)
+	}
 	if driverName == oci.Docker {
 		validateDockerStorageDriver(driverName)
 	}
@@ -355,6 +441,10 @@ func provisionWithDriver(cmd *cobra.Command, ds registry.DriverState, existing *
 		os.Exit(0)
 	}
 
+	if existing != nil {
+		resizeExisting(*existing, cc)
+	}
+
 	if driver.IsVM(driverName) && !driver.IsSSH(driverName) {
 		url, err := download.ISO(viper.GetStringSlice(isoURL), cmd.Flags().Changed(isoURL))
 		if err != nil {
@@ -454,6 +544,35 @@ func imageMatchesBinaryVersion(imageVersion, binaryVersion string) bool {
 }
 
 func startWithDriver(cmd *cobra.Command, starter node.Starter, existing *config.ClusterConfig) (*kubeconfig.Settings, error) {
+	numControlPlanes := requestedControlPlanes()
+	if existing != nil {
+		numControlPlanes = len(config.ControlPlanes(*existing))
+	} else if numControlPlanes > 1 || viper.GetString(controlPlaneVIP) != "" {
+		vip, err := virtualIP(viper.GetString(controlPlaneVIP), starter.Node.IP)
+		if err != nil {
+			return nil, errors.Wrap(err, "virtual IP")
+		}
+		if numControlPlanes > 1 {
+			out.Step(style.Connectivity, "Control plane endpoint of the highly available cluster is the virtual IP {{.vip}}", out.V{"vip": vip})
+		} else {
+			out.Step(style.Connectivity, "Control plane endpoint of the cluster is the kube-vip virtual IP {{.vip}}", out.V{"vip": vip})
+		}
+		starter.Cfg.KubernetesConfig.APIServerHAVIP = vip
+	}
+	if requested := viper.GetString(controlPlaneVIP); existing != nil && cmd.Flags().Changed(controlPlaneVIP) && (existing.KubernetesConfig.APIServerHAVIP == "" || requested != "auto" && requested != existing.KubernetesConfig.APIServerHAVIP) {
+		out.WarningT("The virtual IP of the existing cluster {{.cluster}} cannot be changed, ignoring --vip", out.V{"cluster": existing.Name})
+	}
+
+	rolling := rollingUpgrade(existing, starter.Cfg)
+	drained := false
+	if rolling {
+		out.Step(style.Waiting, "Rolling upgrade of the {{.count}} nodes from Kubernetes {{.old}} to {{.new}}: control planes first, then the workers one at a time",
+			out.V{"count": len(existing.Nodes), "old": existing.KubernetesConfig.KubernetesVersion, "new": starter.Cfg.KubernetesConfig.KubernetesVersion})
+		out.Step(style.Waiting, "Upgrading node {{.name}} (1/{{.total}}) to Kubernetes {{.version}} ...",
+			out.V{"name": config.MachineName(*existing, *starter.Node), "total": len(existing.Nodes), "version": starter.Cfg.KubernetesConfig.KubernetesVersion})
+		drained = drainForUpgrade(*existing, *starter.Node)
+	}
+
 	kubeconfig, err := node.Start(starter, true)
 	if err != nil {
 		kubeconfig, err = maybeDeleteAndRetry(cmd, *starter.Cfg, *starter.Node, starter.ExistingAddons, err)
@@ -461,10 +580,13 @@ func startWithDriver(cmd *cobra.Command, starter node.Starter, existing *config.
 			return nil, err
 		}
 	}
+	if drained {
+		uncordonAfterUpgrade(*starter.Cfg, *starter.Node)
+	}
 
-	numNodes := viper.GetInt(nodes)
+	numNodes := requestedNodes()
 	if existing != nil {
-		if numNodes > 1 {
+		if viper.GetInt(nodes) > 1 {
 			// We ignore the --nodes parameter if we're restarting an existing cluster
 			out.WarningT(`The cluster {{.cluster}} already exists which means the --nodes parameter will be ignored. Use "minikube node add" to add nodes to an existing cluster.`, out.V{"cluster": existing.Name})
 		}
@@ -473,6 +595,8 @@ func startWithDriver(cmd *cobra.Command, starter node.Starter, existing *config.
 	if numNodes > 1 {
 		if driver.BareMetal(starter.Cfg.Driver) {
 			exit.Message(reason.DrvUnsupportedMulti, "The none driver is not compatible with multi-node clusters.")
+		} else if driver.IsWSL(starter.Cfg.Driver) {
+			exit.Message(reason.DrvUnsupportedMulti, "The wsl driver is not compatible with multi-node clusters, the distros of WSL share a single network.")
 		} else {
 			if existing == nil {
 				for i := 1; i < numNodes; i++ {
@@ -480,7 +604,8 @@ func startWithDriver(cmd *cobra.Command, starter node.Starter, existing *config.
 					n := config.Node{
 						Name:              nodeName,
 						Worker:            true,
-						ControlPlane:      false,
+						ControlPlane:      i < numControlPlanes,
+						Port:              starter.Node.Port,
 						KubernetesVersion: starter.Cfg.KubernetesConfig.KubernetesVersion,
 						ContainerRuntime:  starter.Cfg.KubernetesConfig.ContainerRuntime,
 					}
@@ -490,9 +615,19 @@ func startWithDriver(cmd *cobra.Command, starter node.Starter, existing *config.
 						return nil, errors.Wrap(err, "adding node")
 					}
 				}
+			} else if rolling {
+				if err := upgradeNodes(existing, starter.Cfg); err != nil {
+					return nil, errors.Wrap(err, "upgrading node")
+				}
 			} else {
 				for _, n := range existing.Nodes {
-					if !n.ControlPlane {
+					if config.IsWindows(n) {
+						if err := node.StartWindows(*starter.Cfg, n); err != nil {
+							return nil, errors.Wrap(err, "starting Windows node")
+						}
+						continue
+					}
+					if !config.IsPrimaryControlPlane(*existing, n) {
 						err := node.Add(starter.Cfg, n, viper.GetBool(deleteOnFailure))
 						if err != nil {
 							return nil, errors.Wrap(err, "adding node")
@@ -785,9 +920,35 @@ func selectDriver(existing *config.ClusterConfig) (registry.DriverState, []regis
 	} else {
 		out.Step(style.Sparkle, `Automatically selected the {{.driver}} driver`, out.V{"driver": pick.String()})
 	}
+	if viper.GetBool(dryRun) {
+		explainDriverChoice(pick, append(alts, rejects...), choices)
+	}
 	return pick, alts, false
 }
 
+// explainDriverChoice shows the installed drivers in the order they were ranked, with why each one was not selected
+func explainDriverChoice(pick registry.DriverState, others []registry.DriverState, ranked []registry.DriverState) {
+	rejection := map[string]string{}
+	for _, ds := range others {
+		rejection[ds.Name] = ds.Rejection
+	}
+	out.Step(style.Tip, "The installed drivers, ranked by the probes of this host:")
+	for _, ds := range ranked {
+		if !ds.State.Installed {
+			continue
+		}
+		why := rejection[ds.Name]
+		if ds.Name == pick.Name {
+			why = "selected"
+		}
+		if ds.Probe != "" {
+			out.Infof("{{.name}}: {{.why}} (probe: {{.probe}})", out.V{"name": ds.Name, "why": why, "probe": ds.Probe})
+			continue
+		}
+		out.Infof("{{.name}}: {{.why}}", out.V{"name": ds.Name, "why": why})
+	}
+}
+
 // hostDriver returns the actual driver used by a libmachine host, which can differ from our config
 func hostDriver(existing *config.ClusterConfig) string {
 	if existing == nil {
@@ -808,9 +969,6 @@ func hostDriver(existing *config.ClusterConfig) string {
 	h, err := api.Load(machineName)
 	if err != nil {
 		klog.Warningf("api.Load failed for %s: %v", machineName, err)
-		if existing.VMDriver != "" {
-			return existing.VMDriver
-		}
 		return existing.Driver
 	}
 
@@ -1169,7 +1327,7 @@ func validateRequestedMemorySize(req int, drvName string) {
 		}
 	}
 
-	advised := suggestMemoryAllocation(sysLimit, containerLimit, viper.GetInt(nodes))
+	advised := suggestMemoryAllocation(sysLimit, containerLimit, requestedNodes())
 	if req > sysLimit {
 		exitIfNotForced(reason.Kind{ID: "RSRC_OVER_ALLOC_MEM", Advice: "Start minikube with less memory allocated: 'minikube start --memory={{.advised}}mb'"},
 			`Requested memory allocation {{.requested}}MB is more than your system limit {{.system_limit}}MB.`,
@@ -1248,6 +1406,92 @@ func validateCPUCount(drvName string) {
 	}
 }
 
+// requestedControlPlanes returns the number of control planes requested by --ha and --control-planes
+func requestedControlPlanes() int {
+	cps := viper.GetInt(controlPlanes)
+	if viper.GetBool(ha) && cps < 3 {
+		return 3
+	}
+	return cps
+}
+
+// requestedNodes returns the number of nodes requested by --nodes, which is at least the number of control planes
+func requestedNodes() int {
+	if cps := requestedControlPlanes(); cps > viper.GetInt(nodes) {
+		return cps
+	}
+	return viper.GetInt(nodes)
+}
+
+// haVirtualIP returns the virtual IP of a highly available cluster, the last client address of the network of the primary control plane,
+// which minikube keeps out of the DHCP range of the networks it creates (see network.ReservedFrom)
+func haVirtualIP(cpIP string) (string, error) {
+	n, err := netutil.Inspect(cpIP)
+	if err != nil {
+		return "", errors.Wrapf(err, "inspecting network of %s", cpIP)
+	}
+	if n.ClientMax == cpIP {
+		return "", errors.Errorf("the network %s has no free address left for the virtual IP", n.CIDR)
+	}
+	return n.ClientMax, nil
+}
+
+// virtualIP returns the virtual IP requested with --vip, which must be a free address of the network of the primary control plane,
+// or the one of haVirtualIP for "auto" or none
+func virtualIP(requested, cpIP string) (string, error) {
+	if requested == "" || requested == "auto" {
+		return haVirtualIP(cpIP)
+	}
+	n, err := netutil.Inspect(cpIP)
+	if err != nil {
+		return "", errors.Wrapf(err, "inspecting network of %s", cpIP)
+	}
+	_, cidr, err := net.ParseCIDR(n.CIDR)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing network %s", n.CIDR)
+	}
+	if !cidr.Contains(net.ParseIP(requested)) {
+		return "", errors.Errorf("%s is not in the network %s of the cluster", requested, n.CIDR)
+	}
+	for _, taken := range []string{cpIP, n.IP, n.Gateway, n.Broadcast} {
+		if requested == taken {
+			return "", errors.Errorf("%s is not a free address of the network %s", requested, n.CIDR)
+		}
+	}
+	return requested, nil
+}
+
+// validateVIP checks --vip, announced by kube-vip with ARP on the network of the nodes like the load balancer pool
+func validateVIP(vip, drvName string) error {
+	if vip == "" {
+		return nil
+	}
+	if driver.BareMetal(drvName) || driver.NeedsPortForward(drvName) || driver.IsWSL(drvName) || driver.IsSSH(drvName) {
+		return errors.Errorf("the virtual IP is not reachable from the host with the %s driver", drvName)
+	}
+	if vip != "auto" && net.ParseIP(vip).To4() == nil {
+		return errors.Errorf("%q is neither auto nor an IPv4 address", vip)
+	}
+	return nil
+}
+
+// validateControlPlanes validates the number of control planes requested for the driver
+func validateControlPlanes(drvName string, cps int) error {
+	if cps < 1 {
+		return errors.Errorf("--control-planes must be at least 1, got %d", cps)
+	}
+	if cps == 1 {
+		return nil
+	}
+	if driver.BareMetal(drvName) || driver.IsWSL(drvName) || driver.IsSSH(drvName) {
+		return errors.Errorf("The %s driver does not support multiple control planes", drvName)
+	}
+	if cps%2 == 0 {
+		out.WarningT("An even number of control planes does not tolerate more failures than {{.cps}}, as etcd needs a majority", out.V{"cps": cps - 1})
+	}
+	return nil
+}
+
 // validateFlags validates the supplied flags against known bad combinations
 func validateFlags(cmd *cobra.Command, drvName string) {
 	if cmd.Flags().Changed(humanReadableDiskSize) {
@@ -1265,6 +1509,12 @@ func validateFlags(cmd *cobra.Command, drvName string) {
 
 	validateCPUCount(drvName)
 
+	if cmd.Flags().Changed(ha) || cmd.Flags().Changed(controlPlanes) {
+		if err := validateControlPlanes(drvName, requestedControlPlanes()); err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+	}
+
 	if drvName == driver.None && viper.GetBool(noKubernetes) {
 		exit.Message(reason.Usage, "Cannot use the option --no-kubernetes on the {{.name}} driver", out.V{"name": drvName})
 	}
@@ -1277,6 +1527,16 @@ func validateFlags(cmd *cobra.Command, drvName string) {
 		validateListenAddress(viper.GetString(listenAddress))
 	}
 
+	if drvName == driver.HyperV {
+		mem := 0
+		if cmd.Flags().Changed(memory) {
+			mem, _ = util.CalculateSizeInMB(viper.GetString(memory))
+		}
+		if err := validateHypervMemory(viper.GetBool(hypervDynamicMemory), viper.GetBool(hypervNestedVirt), mem, getHypervMemorySize(hypervMinMemory), getHypervMemorySize(hypervMaxMemory), viper.GetInt(hypervMemoryBuffer)); err != nil {
+			exitIfNotForced(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+	}
+
 	if cmd.Flags().Changed(imageRepository) {
 		viper.Set(imageRepository, validateImageRepository(viper.GetString(imageRepository)))
 	}
@@ -1285,7 +1545,16 @@ func validateFlags(cmd *cobra.Command, drvName string) {
 		if err != nil {
 			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
 		}
-
+		if driver.IsKIC(drvName) && runtime.GOOS == "linux" && !oci.IsExternalDaemonHost(drvName) {
+			if si, err := oci.CachedDaemonInfo(drvName); err == nil && si.Rootless {
+				start, err := oci.UnprivilegedPortStart()
+				if err != nil {
+					klog.Warningf("unable to check unprivileged ports: %v", err)
+				} else if err := validateRootlessPorts(viper.GetStringSlice(ports), start); err != nil {
+					exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+				}
+			}
+		}
 	}
 
 	if cmd.Flags().Changed(subnet) {
@@ -1304,7 +1573,247 @@ func validateFlags(cmd *cobra.Command, drvName string) {
 	}
 
 	if cmd.Flags().Changed(staticIP) {
-		if err := validateStaticIP(viper.GetString(staticIP), drvName, viper.GetString(subnet)); err != nil {
+		if err := validateStaticIP(viper.GetString(staticIP), drvName, viper.GetString(network), viper.GetString(subnet)); err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(extraNetwork) {
+		if err := validateExtraNetworks(viper.GetStringSlice(extraNetwork), drvName, viper.GetString(network)); err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+	}
+
+	if viper.GetBool(createMount) && viper.GetString(mountTypeFlag) == constants.VirtiofsMountType {
+		if err := validateVirtiofsMount(drvName, runtime.GOOS, viper.GetString(mountString)); err != nil {
+			exit.Message(reason.Usage, "Sorry, the virtiofs mount is not valid: {{.err}}", out.V{"err": err})
+		}
+	}
+
+	if viper.GetBool(createMount) && viper.GetString(mountTypeFlag) == constants.SyncMountType {
+		opts := mountsync.Options{Ignore: viper.GetStringSlice(mountSyncIgnore), Conflict: viper.GetString(mountSyncConflict)}
+		if err := opts.Validate(); err != nil {
+			exit.Message(reason.Usage, "Sorry, the sync mount is not valid: {{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(kubernetesImagesDir) && viper.GetString(kubernetesImagesDir) != "" {
+		if err := bsutil.CheckLocalBuild(localBuildDir(nil)); err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(kubeadmPatches) && viper.GetString(kubeadmPatches) != "" {
+		if err := bsutil.ValidateKubeadmPatches(viper.GetString(kubeadmPatches)); err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(userData) && viper.GetString(userData) != "" {
+		if !driver.IsVM(drvName) {
+			exit.Message(reason.Usage, "Sorry, --user-data is only supported by the VM drivers, not by the {{.driver}} driver", out.V{"driver": drvName})
+		}
+		if err := machine.ValidateUserData(viper.GetString(userData)); err != nil {
+			exit.Message(reason.Usage, "Sorry, the --user-data file is not valid: {{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(provision) && viper.GetString(provision) != "" {
+		if _, err := machine.LoadProvisionManifest(viper.GetString(provision)); err != nil {
+			exit.Message(reason.Usage, "Sorry, the --provision manifest is not valid: {{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(upgradeStrategy) && !contains(upgradeStrategies, viper.GetString(upgradeStrategy)) {
+		exit.Message(reason.Usage, "Sorry, the --upgrade-strategy flag must be one of: {{.strategies}}", out.V{"strategies": strings.Join(upgradeStrategies, ", ")})
+	}
+	validateStartSchedule(cmd, drvName)
+	if cmd.Flags().Changed(idleAction) && !contains(idle.Actions, viper.GetString(idleAction)) {
+		exit.Message(reason.Usage, "Sorry, the --idle-action flag must be one of: {{.actions}}", out.V{"actions": strings.Join(idle.Actions, ", ")})
+	}
+	if viper.GetDuration(idleTimeout) < 0 {
+		exit.Message(reason.Usage, "Sorry, the --idle-timeout flag must not be negative")
+	}
+	if cmd.Flags().Changed(recoverState) && !contains(machine.StateRecoveryModes, viper.GetString(recoverState)) {
+		exit.Message(reason.Usage, "Sorry, the --recover-state flag must be one of: {{.modes}}", out.V{"modes": strings.Join(machine.StateRecoveryModes, ", ")})
+	}
+
+	if viper.GetString(config.RemoteHost) != "" && !driver.IsKIC(drvName) {
+		exit.Message(reason.Usage, "The --host flag is only supported by the docker and podman drivers")
+	}
+
+	for _, flag := range []string{zones, regions} {
+		if err := validateTopology(viper.GetStringSlice(flag)); err != nil {
+			exit.Message(reason.Usage, "Sorry, the --{{.flag}} flag is not valid: {{.err}}", out.V{"flag": flag, "err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(ipFamily) {
+		k8sVersion, err := getKubernetesVersion(nil)
+		if err != nil {
+			exit.Error(reason.Usage, "getting Kubernetes version", err)
+		}
+		if err := validateIPFamily(viper.GetString(ipFamily), drvName, k8sVersion, viper.GetString(cniFlag)); err != nil {
+			exit.Message(reason.Usage, "Sorry, the --ip-family flag is not valid: {{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(loadBalancerPool) {
+		if err := validateLoadBalancerPool(viper.GetString(loadBalancerPool), drvName); err != nil {
+			exit.Message(reason.Usage, "Sorry, the --load-balancer-pool flag is not valid: {{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(controlPlaneVIP) {
+		if err := validateVIP(viper.GetString(controlPlaneVIP), drvName); err != nil {
+			exit.Message(reason.Usage, "Sorry, the --vip flag is not valid: {{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(cniVersion) || cmd.Flags().Changed(cniValues) || cmd.Flags().Changed(cniOverlay) {
+		cniName := ""
+		if cmd.Flags().Changed(cniFlag) {
+			cniName = viper.GetString(cniFlag)
+		}
+		if err := validateCNICustomization(cniName, viper.GetString(cniVersion), viper.GetStringSlice(cniValues), viper.GetStringSlice(cniOverlay)); err != nil {
+			exit.Message(reason.Usage, "Sorry, the CNI flags are not valid: {{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(portForward) {
+		specs, _ := cmd.Flags().GetStringArray(portForward)
+		if err := validatePortForwards(specs); err != nil {
+			exit.Message(reason.Usage, "Sorry, the --port-forward flag is not valid: {{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(persistentMount) {
+		specs, _ := cmd.Flags().GetStringArray(persistentMount)
+		if err := validatePersistentMounts(specs, drvName); err != nil {
+			exit.Message(reason.Usage, "Sorry, the --persistent-mount flag is not valid: {{.err}}", out.V{"err": err})
+		}
+	}
+
+	for _, flag := range []string{preStartHook, postStartHook} {
+		specs, _ := cmd.Flags().GetStringArray(flag)
+		for _, spec := range specs {
+			if _, err := hooks.Parse(spec); err != nil {
+				exit.Message(reason.Usage, "Sorry, the --{{.flag}} flag is not valid: {{.err}}", out.V{"flag": flag, "err": err})
+			}
+		}
+	}
+
+	if cmd.Flags().Changed(sshProxy) && viper.GetString(sshProxy) != "" {
+		if _, err := sshutil.ParseProxy(viper.GetString(sshProxy)); err != nil {
+			exit.Message(reason.Usage, "Sorry, the --ssh-proxy flag is not valid: {{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(spiffeTrustDomain) && viper.GetString(spiffeTrustDomain) != "" {
+		if _, err := util.GetSPIFFEID(viper.GetString(spiffeTrustDomain), "user/minikube-user"); err != nil {
+			exit.Message(reason.Usage, "Sorry, the --spiffe-trust-domain flag is not valid: {{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(vzRosetta) || cmd.Flags().Changed(vzSharedFolders) || cmd.Flags().Changed(vzBridgeInterface) {
+		if drvName != driver.VZ {
+			exit.Message(reason.Usage, "The --vz-rosetta, --vz-shared-folders and --vz-bridge-interface flags are only supported by the vz driver")
+		}
+		if viper.GetBool(vzRosetta) && runtime.GOARCH != "arm64" {
+			exit.Message(reason.Usage, "Rosetta is only available on Apple silicon")
+		}
+		for _, f := range viper.GetStringSlice(vzSharedFolders) {
+			if _, _, err := vz.ParseSharedFolder(f); err != nil {
+				exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+			}
+		}
+	}
+
+	if cmd.Flags().Changed(pluginOpts) {
+		if !driver.IsPlugin(drvName) {
+			exit.Message(reason.Usage, "The --plugin-opts flag is only supported by driver plugins")
+		}
+		if _, err := plugin.ParseOptions(viper.GetStringSlice(pluginOpts)); err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(firecrackerKernel) || cmd.Flags().Changed(firecrackerJailer) {
+		if drvName != driver.Firecracker {
+			exit.Message(reason.Usage, "The --firecracker-kernel and --firecracker-jailer flags are only supported by the firecracker driver")
+		}
+		if k := viper.GetString(firecrackerKernel); k != "" {
+			if _, err := os.Stat(k); err != nil {
+				exit.Message(reason.Usage, "The kernel image {{.path}} is not readable: {{.err}}", out.V{"path": k, "err": err})
+			}
+		}
+	}
+
+	if cmd.Flags().Changed(cloudHypervisorKernel) {
+		if drvName != driver.CloudHypervisor {
+			exit.Message(reason.Usage, "The --cloud-hypervisor-kernel flag is only supported by the cloud-hypervisor driver")
+		}
+		if _, err := os.Stat(viper.GetString(cloudHypervisorKernel)); err != nil {
+			exit.Message(reason.Usage, "The kernel image {{.path}} is not readable: {{.err}}", out.V{"path": viper.GetString(cloudHypervisorKernel), "err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(tuningProfile) || cmd.Flags().Changed(tuningOpts) || cmd.Flags().Changed(sysctls) || cmd.Flags().Changed(kernelModules) {
+		s, err := tuning.Resolve(viper.GetString(tuningProfile), viper.GetStringSlice(tuningOpts))
+		if err == nil {
+			err = s.AddSysctls(viper.GetStringSlice(sysctls))
+		}
+		if err == nil {
+			err = s.AddModules(viper.GetStringSlice(kernelModules))
+		}
+		if err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(wasmRuntime) && viper.GetString(wasmRuntime) != "" {
+		if !wasm.Valid(viper.GetString(wasmRuntime)) {
+			exit.Message(reason.Usage, "Invalid wasm runtime {{.runtime}}, valid runtimes are: {{.runtimes}}", out.V{"runtime": viper.GetString(wasmRuntime), "runtimes": strings.Join(wasm.Runtimes, ", ")})
+		}
+		existing, _ := config.Load(ClusterFlagValue())
+		if getContainerRuntime(existing) != constants.Containerd {
+			exit.Message(reason.Usage, "The --wasm-runtime flag is only supported by the containerd container runtime, use --container-runtime=containerd")
+		}
+	}
+
+	if cmd.Flags().Changed(runtimeClass) && viper.GetString(runtimeClass) != "" {
+		existing, _ := config.Load(ClusterFlagValue())
+		if err := validateRuntimeClass(viper.GetString(runtimeClass), drvName, getContainerRuntime(existing)); err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(containerRuntimeSandbox) || cmd.Flags().Changed(sandboxNamespaces) {
+		existing, _ := config.Load(ClusterFlagValue())
+		s := viper.GetString(containerRuntimeSandbox)
+		if !cmd.Flags().Changed(containerRuntimeSandbox) && existing != nil {
+			s = existing.ContainerRuntimeSandbox
+		}
+		version := viper.GetString(kubernetesVersion)
+		if existing != nil && !cmd.Flags().Changed(kubernetesVersion) {
+			version = existing.KubernetesConfig.KubernetesVersion
+		}
+		if err := validateSandbox(s, viper.GetStringSlice(sandboxNamespaces), getContainerRuntime(existing), version); err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+	}
+
+	if cmd.Flags().Changed(preloadFrom) && viper.GetString(preloadFrom) != "" {
+		from, err := validatePreloadFrom(viper.GetString(preloadFrom))
+		if err != nil {
+			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
+		}
+		viper.Set(preloadFrom, from)
+	}
+
+	if cmd.Flags().Changed(ociRuntime) {
+		existing, _ := config.Load(ClusterFlagValue())
+		if err := validateOCIRuntime(viper.GetString(ociRuntime), getContainerRuntime(existing)); err != nil {
 			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
 		}
 	}
@@ -1313,6 +1822,13 @@ func validateFlags(cmd *cobra.Command, drvName string) {
 		if err := validateGPUs(viper.GetString(gpus), drvName, viper.GetString(containerRuntime)); err != nil {
 			exit.Message(reason.Usage, "{{.err}}", out.V{"err": err})
 		}
+		// the nodes of the docker driver run the GPU containers with the NVIDIA driver and Container Toolkit of the host,
+		// only a missing driver is fatal, the other problems are warned about
+		if driver.IsDocker(drvName) {
+			if h := detect.Nvidia(); warnNvidiaHost(h, drvName) > 0 && h.DriverVersion == "" {
+				exit.Message(reason.HostNvidia, "The host cannot pass its NVIDIA GPUs to the cluster")
+			}
+		}
 	}
 
 	if driver.IsSSH(drvName) {
@@ -1406,6 +1922,65 @@ func validatePort(port string, isHost bool) error {
 	return nil
 }
 
+// validateRootlessStrict exits if the start would run a privileged helper on the host, which --rootless=strict forbids
+func validateRootlessStrict(drvName string, existing *config.ClusterConfig) {
+	s := rootless.Start{Root: os.Geteuid() == 0, Drivers: []string{drvName}, Addons: map[string]bool{}}
+	if existing != nil {
+		for _, n := range existing.Nodes {
+			s.Drivers = append(s.Drivers, config.NodeDriver(*existing, n))
+		}
+		for a, enabled := range existing.Addons {
+			s.Addons[a] = enabled
+		}
+	}
+	for _, a := range viper.GetStringSlice(config.AddonListFlag) {
+		s.Addons[a] = true
+	}
+	if driver.IsKIC(drvName) {
+		if si, err := oci.CachedDaemonInfo(drvName); err == nil {
+			s.RootlessDaemon = si.Rootless
+		}
+	}
+	vs := rootless.Audit(s)
+	if len(vs) == 0 {
+		return
+	}
+	for _, v := range vs {
+		out.ErrT(style.Stopped, "{{.helper}}: {{.cause}}", out.V{"helper": v.Helper, "cause": v.Cause})
+		out.ErrT(style.Tip, "  {{.fix}}", out.V{"fix": v.Fix})
+	}
+	exit.Message(reason.DrvRootlessStrict, "The start would run privileged helpers on this host, which --rootless=strict forbids")
+}
+
+// validateRootlessPorts validates that a rootless driver is allowed to publish the host ports of the --ports flag
+func validateRootlessPorts(ports []string, unprivilegedStart int) error {
+	var portSpecs []string
+	for _, p := range ports {
+		if strings.Contains(p, ":") {
+			portSpecs = append(portSpecs, p)
+		}
+	}
+	_, portBindingsMap, err := nat.ParsePortSpecs(portSpecs)
+	if err != nil {
+		return err
+	}
+	for _, portBindings := range portBindingsMap {
+		for _, portBinding := range portBindings {
+			if portBinding.HostPort == "" {
+				continue
+			}
+			p, err := strconv.Atoi(portBinding.HostPort)
+			if err != nil {
+				return err
+			}
+			if p < unprivilegedStart {
+				return errors.Errorf("Sorry, the rootless driver cannot publish host ports below %d: %d. To allow it, run 'sudo sysctl net.ipv4.ip_unprivileged_port_start=%d'", unprivilegedStart, p, p)
+			}
+		}
+	}
+	return nil
+}
+
 // validateDiskSize validates the supplied disk size
 func validateDiskSize(diskSize string) error {
 	diskSizeMB, err := util.CalculateSizeInMB(diskSize)
@@ -1418,6 +1993,52 @@ func validateDiskSize(diskSize string) error {
 	return nil
 }
 
+// validatePreloadFrom validates the preload tarball of --preload-from, returning the absolute path of a file so it is found by the next starts
+func validatePreloadFrom(from string) (string, error) {
+	// the drive letter of a windows path parses as a scheme of a single letter
+	if u, err := url.Parse(from); err == nil && len(u.Scheme) > 1 {
+		if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+			return "", errors.Errorf("the preload tarball %s is not a file or an http(s) URL", from)
+		}
+		return from, nil
+	}
+	p, err := filepath.Abs(from)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(p); err != nil || info.IsDir() {
+		return "", errors.Errorf("the preload tarball %s is not a file", from)
+	}
+	return p, nil
+}
+
+// validateHypervMemory validates the dynamic memory and nested virtualization settings of the hyperv driver.
+// The sizes are in MB, 0 when not set.
+func validateHypervMemory(dynamic, nested bool, memory, minMemory, maxMemory, buffer int) error {
+	if !dynamic {
+		if minMemory != 0 || maxMemory != 0 || buffer != 0 {
+			return errors.Errorf("The --%s, --%s and --%s flags require --%s", hypervMinMemory, hypervMaxMemory, hypervMemoryBuffer, hypervDynamicMemory)
+		}
+		return nil
+	}
+	if nested {
+		return errors.Errorf("Hyper-V nested virtualization requires dynamic memory to be disabled")
+	}
+	if buffer != 0 && (buffer < 5 || buffer > 2000) {
+		return errors.Errorf("The dynamic memory buffer must be between 5 and 2000 percent, got %d", buffer)
+	}
+	if minMemory != 0 && maxMemory != 0 && minMemory > maxMemory {
+		return errors.Errorf("The minimum memory %dMB is more than the maximum memory %dMB", minMemory, maxMemory)
+	}
+	if memory != 0 && minMemory > memory {
+		return errors.Errorf("The minimum memory %dMB is more than the startup memory %dMB", minMemory, memory)
+	}
+	if memory != 0 && maxMemory != 0 && maxMemory < memory {
+		return errors.Errorf("The maximum memory %dMB is less than the startup memory %dMB", maxMemory, memory)
+	}
+	return nil
+}
+
 // validateRuntime validates the supplied runtime
 func validateRuntime(rtime string) error {
 	validOptions := cruntime.ValidRuntimes()
@@ -1459,13 +2080,43 @@ func validateGPUs(value, drvName, rtime string) error {
 	if err := validateGPUsArch(); err != nil {
 		return err
 	}
+	if driver.IsKVM(drvName) {
+		return validateKVMGPUs(value)
+	}
 	if value != "nvidia" && value != "all" {
 		return errors.Errorf(`The gpus flag must be passed a value of "nvidia" or "all"`)
 	}
-	if drvName == constants.Docker && (rtime == constants.Docker || rtime == constants.DefaultContainerRuntime) {
+	if drvName != constants.Docker {
+		return errors.Errorf("The gpus flag can only be used with the docker or kvm2 driver")
+	}
+	switch rtime {
+	case constants.DefaultContainerRuntime, constants.Docker, constants.Containerd, constants.CRIO, "cri-o":
 		return nil
 	}
-	return errors.Errorf("The gpus flag can only be used with the docker driver and docker container-runtime")
+	return errors.Errorf("The gpus flag can only be used with the docker, containerd or crio container-runtime")
+}
+
+// iommuGroupsPath lists the IOMMU groups of the host, empty if IOMMU is disabled
+var iommuGroupsPath = "/sys/kernel/iommu_groups"
+
+// pciAddressRe matches a PCI address as listed in /sys/bus/pci/devices: domain:bus:slot.function
+var pciAddressRe = regexp.MustCompile(`^[0-9a-f]{4}:[0-9a-f]{2}:[0-9a-f]{2}\.[0-7]$`)
+
+// validateKVMGPUs validates the devices to passthrough to the kvm2 VM, and that the host has IOMMU enabled to do so.
+// The devices are themselves checked by the driver when it creates the VM.
+func validateKVMGPUs(value string) error {
+	if value != "nvidia" && value != "all" {
+		for _, dev := range strings.Split(value, ",") {
+			if !pciAddressRe.MatchString(dev) {
+				return errors.Errorf(`The gpus flag must be passed "nvidia", "all" or a comma separated list of PCI addresses like 0000:01:00.0 with the kvm2 driver, got %q`, dev)
+			}
+		}
+	}
+	groups, err := os.ReadDir(iommuGroupsPath)
+	if err != nil || len(groups) == 0 {
+		return errors.Errorf("GPU passthrough requires IOMMU, but no IOMMU groups were found in %s. See https://minikube.sigs.k8s.io/docs/tutorials/nvidia_gpu/", iommuGroupsPath)
+	}
+	return nil
 }
 
 func validateGPUsArch() error {
@@ -1476,6 +2127,75 @@ func validateGPUsArch() error {
 	return errors.Errorf("The GPUs flag is only supported on amd64, arm64 & ppc64le, currently using %s", runtime.GOARCH)
 }
 
+// validateOCIRuntime validates that the OCI runtime rt can be run by the container runtime rtime.
+// Whether the nodes use cgroup v2, which crun needs, is checked when the container runtime is configured.
+func validateOCIRuntime(rt, rtime string) error {
+	if !cruntime.ValidOCIRuntime(rt) {
+		return errors.Errorf("Invalid OCI runtime %q, valid OCI runtimes are: %s", rt, strings.Join(cruntime.OCIRuntimes(), ", "))
+	}
+	if rt == cruntime.Crun && rtime != constants.Containerd && rtime != constants.CRIO {
+		return errors.Errorf("The --oci-runtime flag is only supported by the containerd and cri-o container runtimes, use --container-runtime=containerd")
+	}
+	return nil
+}
+
+// validateRuntimeClass validates that the sandboxed runtime rc can run on the driver, with the container runtime rtime
+func validateRuntimeClass(rc, drvName, rtime string) error {
+	if rc != kata.Name {
+		return errors.Errorf("Invalid runtime class %q, valid runtime classes are: %s", rc, strings.Join(kata.RuntimeClasses, ", "))
+	}
+	if !kata.SupportsRuntime(rtime) {
+		return errors.Errorf("Kata Containers is only supported by the containerd and cri-o container runtimes, use --container-runtime=containerd")
+	}
+	// Kata runs a VM per pod, which needs the nodes to have nested virtualization
+	switch {
+	case driver.IsKVM(drvName), driver.IsQEMU(drvName) && runtime.GOOS == "linux":
+	case drvName == driver.HyperV:
+		if !viper.GetBool(hypervNestedVirt) {
+			return errors.Errorf("Kata Containers needs nested virtualization, use --hyperv-nested-virt")
+		}
+	case driver.IsKIC(drvName):
+		if runtime.GOOS != "linux" {
+			return errors.Errorf("Kata Containers needs /dev/kvm, which the %s driver only has on a Linux host", drvName)
+		}
+		if _, err := os.Stat("/dev/kvm"); err != nil {
+			return errors.Errorf("Kata Containers needs /dev/kvm, which the host has not: enable virtualization or load the kvm module")
+		}
+	case driver.BareMetal(drvName), driver.IsSSH(drvName):
+		// checked on the node when it starts
+	default:
+		return errors.Errorf("Kata Containers needs nested virtualization, which the %s driver does not support: use the kvm2, qemu2 or docker driver on a Linux host", drvName)
+	}
+	return nil
+}
+
+// validateSandbox validates the sandbox s, registered with the container runtime rtime, and the namespaces defaulting to it in Kubernetes version
+func validateSandbox(s string, namespaces []string, rtime, version string) error {
+	if s == "" {
+		if len(namespaces) > 0 {
+			return errors.Errorf("The --sandbox-namespaces flag needs a sandbox, use --container-runtime-sandbox=%s", sandbox.GVisor)
+		}
+		return nil
+	}
+	if !sandbox.Valid(s) {
+		return errors.Errorf("Invalid sandbox %q, valid sandboxes are: %s", s, strings.Join(sandbox.Sandboxes, ", "))
+	}
+	if !sandbox.SupportsRuntime(rtime) {
+		return errors.Errorf("The %s sandbox is only supported by the containerd and cri-o container runtimes, use --container-runtime=containerd", s)
+	}
+	if len(namespaces) == 0 || version == "" || version == constants.NoKubernetesVersion {
+		return nil
+	}
+	v, err := util.ParseKubernetesVersion(version)
+	if err != nil {
+		// the aliases such as stable are resolved later
+		klog.Infof("unable to parse the Kubernetes version %q: %v", version, err)
+		return nil
+	}
+	_, err = sandbox.AdmissionAPIVersion(v)
+	return err
+}
+
 func getContainerRuntime(old *config.ClusterConfig) string {
 	paramRuntime := viper.GetString(containerRuntime)
 
@@ -1772,14 +2492,19 @@ func validateKubernetesVersion(old *config.ClusterConfig) {
 		klog.Infof("No Kubernetes version set for minikube, setting Kubernetes version to %s", constants.NoKubernetesVersion)
 		return
 	}
-	if nvs.Major > newestVersion.Major {
+	// the version of a local build is the one of its tree, usually ahead of the released versions
+	localBuild := localBuildDir(old) != ""
+	if localBuild {
+		out.Styled(style.Workaround, "Using the local build of Kubernetes {{.version}} in {{.dir}}", out.V{"version": kubernetesVer, "dir": localBuildDir(old)})
+	}
+	if nvs.Major > newestVersion.Major && !localBuild {
 		out.WarningT("Specified Major version of Kubernetes {{.specifiedMajor}} is newer than the newest supported Major version: {{.newestMajor}}", out.V{"specifiedMajor": nvs.Major, "newestMajor": newestVersion.Major})
 		if !viper.GetBool(force) {
 			out.WarningT("You can force an unsupported Kubernetes version via the --force flag")
 		}
 		exitIfNotForced(reason.KubernetesTooNew, "Kubernetes {{.version}} is not supported by this release of minikube", out.V{"version": nvs})
 	}
-	if nvs.GT(newestVersion) {
+	if nvs.GT(newestVersion) && !localBuild {
 		out.WarningT("Specified Kubernetes version {{.specified}} is newer than the newest supported version: {{.newest}}. Use `minikube config defaults kubernetes-version` for details.", out.V{"specified": nvs, "newest": constants.NewestKubernetesVersion})
 		if contains(constants.ValidKubernetesVersions, kubernetesVer) {
 			out.Styled(style.Check, "Kubernetes version {{.specified}} found in version list", out.V{"specified": nvs})
@@ -1851,7 +2576,36 @@ func validateContainerRuntime(old *config.ClusterConfig) {
 }
 
 func isBaseImageApplicable(drv string) bool {
-	return registry.IsKIC(drv)
+	return registry.IsKIC(drv) || driver.IsLXD(drv) || driver.IsWSL(drv)
+}
+
+// localBuildDir returns the absolute directory of the local build of Kubernetes of --kubernetes-images-dir, or else of the existing cluster
+func localBuildDir(old *config.ClusterConfig) string {
+	if dir := viper.GetString(kubernetesImagesDir); dir != "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			klog.Warningf("unable to get the absolute path of %s: %v", dir, err)
+			return dir
+		}
+		return abs
+	}
+	if old != nil {
+		return old.KubernetesConfig.KubernetesImagesDir
+	}
+	return ""
+}
+
+// localBuildVersion returns the version of the local build of Kubernetes in dir, which --kubernetes-version has to match if set
+func localBuildVersion(dir, paramVersion string) (string, error) {
+	v, err := bsutil.LocalBuildVersion(dir)
+	if err != nil {
+		return "", errors.Wrap(err, "version of the local build")
+	}
+	v = version.VersionPrefix + strings.TrimPrefix(v, version.VersionPrefix)
+	if paramVersion != "" && version.VersionPrefix+strings.TrimPrefix(paramVersion, version.VersionPrefix) != v {
+		return "", errors.Errorf("the local build in %s is Kubernetes %s, not %s", dir, v, paramVersion)
+	}
+	return v, nil
 }
 
 func getKubernetesVersion(old *config.ClusterConfig) (string, error) {
@@ -1872,6 +2626,10 @@ $ minikube config unset kubernetes-version`)
 
 	paramVersion := viper.GetString(kubernetesVersion)
 
+	if dir := localBuildDir(old); dir != "" {
+		return localBuildVersion(dir, paramVersion)
+	}
+
 	// try to load the old version first if the user didn't specify anything
 	if paramVersion == "" && old != nil {
 		paramVersion = old.KubernetesConfig.KubernetesVersion
@@ -1941,10 +2699,12 @@ func validateSubnet(subnet string) error {
 	return nil
 }
 
-func validateStaticIP(staticIP, drvName, subnet string) error {
-	if !driver.IsKIC(drvName) {
+func validateStaticIP(staticIP, drvName, netName, subnet string) error {
+	_, bridged := netutil.ParseBridged(netName)
+	supported := driver.IsKIC(drvName) || drvName == driver.KVM2 || drvName == driver.HyperV || (driver.IsQEMU(drvName) && !netutil.IsBuiltinQEMU(netName) && !bridged)
+	if !supported {
 		if staticIP != "" {
-			out.WarningT("--static-ip is only implemented on Docker and Podman drivers, flag will be ignored")
+			out.WarningT("--static-ip is only implemented on Docker, Podman, KVM, Hyper-V and QEMU with socket_vmnet drivers, flag will be ignored")
 		}
 		return nil
 	}
@@ -1965,6 +2725,65 @@ func validateStaticIP(staticIP, drvName, subnet string) error {
 	return nil
 }
 
+// validateVirtiofsMount checks that the driver can share the host directory of the mount string with virtiofs
+func validateVirtiofsMount(drvName, goos, mount string) error {
+	if drvName != driver.KVM2 && drvName != driver.VZ && !(driver.IsQEMU(drvName) && goos == "linux") {
+		return fmt.Errorf("the virtiofs mount type is only implemented on the KVM, vz and QEMU (on Linux) drivers, not on %s", drvName)
+	}
+	i := strings.LastIndex(mount, ":")
+	if i == -1 {
+		return fmt.Errorf("the mount string %q must be in the form <source directory>:<target directory>", mount)
+	}
+	host, guest := mount[:i], mount[i+1:]
+	if !filepath.IsAbs(host) {
+		return fmt.Errorf("the source directory %s must be an absolute path", host)
+	}
+	if st, err := os.Stat(host); err != nil || !st.IsDir() {
+		return fmt.Errorf("the source directory %s is not a directory", host)
+	}
+	if !strings.HasPrefix(guest, "/") {
+		return fmt.Errorf("the target directory %s must be an absolute path", guest)
+	}
+	return nil
+}
+
+// validateVirtiofsMountChange exits if the flags change the host directory shared with virtiofs by the existing VM, as it is attached on creation
+func validateVirtiofsMountChange(cmd *cobra.Command, existing *config.ClusterConfig) {
+	cc := *existing
+	updateBoolFromFlag(cmd, &cc.Mount, createMount)
+	updateStringFromFlag(cmd, &cc.MountString, mountString)
+	updateStringFromFlag(cmd, &cc.MountType, mountTypeFlag)
+	old, _ := config.VirtiofsMount(*existing)
+	mount, _ := config.VirtiofsMount(cc)
+	if old != mount {
+		exit.Message(reason.GuestMountConflict, "Sorry, the virtiofs share of the {{.driver}} VM is set up on its creation, and cannot be changed (previous share: '{{.old}}', new share: '{{.new}}'). Delete the cluster to change it", out.V{
+			"driver": existing.Driver,
+			"old":    old,
+			"new":    mount,
+		})
+	}
+}
+
+// validateExtraNetworks checks that the driver can attach the extra networks, and that their names are valid
+func validateExtraNetworks(specs []string, drvName, netName string) error {
+	if len(specs) == 0 {
+		return nil
+	}
+	if !driver.IsKIC(drvName) && drvName != driver.KVM2 {
+		return fmt.Errorf("--extra-network is only implemented on Docker, Podman and KVM drivers")
+	}
+	nets, err := netutil.ParseExtraNetworks(specs)
+	if err != nil {
+		return err
+	}
+	for _, n := range nets {
+		if n.Network == netName {
+			return fmt.Errorf("extra network %q is the network of the cluster, %s", n.Name, netName)
+		}
+	}
+	return nil
+}
+
 func validateBareMetal(drvName string) {
 	if !driver.BareMetal(drvName) {
 		return
@@ -2074,3 +2893,149 @@ func contains(sl []string, s string) bool {
 	}
 	return false
 }
+
+// validateIPFamily checks the IP family of the cluster: IPv6 is configured for the container networks, the private network of kvm2,
+// the kubeadm dual-stack settings of Kubernetes v1.23.0+ and the bridge and kindnet CNIs
+func validateIPFamily(family, drvName, k8sVersion, cniName string) error {
+	switch family {
+	case config.IPv4Family:
+		return nil
+	case config.IPv6Family, config.DualStack:
+	default:
+		return errors.Errorf("%q is not one of %s, %s or %s", family, config.IPv4Family, config.IPv6Family, config.DualStack)
+	}
+	if !driver.IsKIC(drvName) && !driver.IsKVM(drvName) {
+		return errors.Errorf("%s needs the docker, podman or kvm2 driver, not %s", family, drvName)
+	}
+	if k8sVersion == constants.NoKubernetesVersion {
+		return nil
+	}
+	v, err := util.ParseKubernetesVersion(k8sVersion)
+	if err != nil {
+		return errors.Wrap(err, "parsing Kubernetes version")
+	}
+	if v.LT(semver.MustParse("1.23.0")) {
+		return errors.Errorf("%s needs Kubernetes v1.23.0 or later, not %s", family, k8sVersion)
+	}
+	if cniName != "" && cniName != "auto" && cniName != "bridge" && cniName != "kindnet" {
+		return errors.Errorf("%s needs the bridge or kindnet CNI, not %s", family, cniName)
+	}
+	return nil
+}
+
+// validateCNICustomization checks that the files of the --cni-values and --cni-overlay flags exist, and, when the --cni
+// flag is given, that the CNI supports them
+func validateCNICustomization(cniName, version string, values, overlays []string) error {
+	for _, f := range append(append([]string{}, values...), overlays...) {
+		if _, err := os.Stat(f); err != nil {
+			return err
+		}
+	}
+	if cniName == "" {
+		return nil
+	}
+	helm := strings.HasPrefix(cniName, cni.HelmPrefix)
+	switch cniName {
+	case "calico", "cilium", "flannel":
+	case "auto", "bridge", "kindnet", "true", "false":
+		if version != "" || len(overlays) > 0 {
+			return errors.Errorf("the %s CNI has no version nor overlays", cniName)
+		}
+	default:
+		if version != "" && !helm {
+			return errors.Errorf("--%s needs --%s=calico, cilium, flannel or helm:CHART", cniVersion, cniFlag)
+		}
+	}
+	if len(values) > 0 && !helm {
+		return errors.Errorf("--%s needs --%s=helm:CHART", cniValues, cniFlag)
+	}
+	return nil
+}
+
+// validatePortForwards checks the format of the port forwards, and that no two of them use the same host port
+func validatePortForwards(specs []string) error {
+	hostPorts := map[int]string{}
+	for _, spec := range specs {
+		f, err := portforward.Parse(spec)
+		if err != nil {
+			return err
+		}
+		if other, ok := hostPorts[f.HostPort]; ok {
+			return errors.Errorf("%q and %q both use host port %d", other, spec, f.HostPort)
+		}
+		hostPorts[f.HostPort] = spec
+	}
+	return nil
+}
+
+// validatePersistentMounts checks the format of the persistent mounts, that their host directories exist, and that no two of them use the same target
+func validatePersistentMounts(specs []string, drvName string) error {
+	targets := map[string]string{}
+	for _, spec := range specs {
+		if spec == "" {
+			continue
+		}
+		if drvName == driver.None {
+			return errors.Errorf("the %s driver does not support mounts", drvName)
+		}
+		m, err := cluster.ParseMount(spec)
+		if err != nil {
+			return err
+		}
+		if st, err := os.Stat(m.Source); err != nil || !st.IsDir() {
+			return errors.Errorf("%s is not a directory", m.Source)
+		}
+		target := path.Clean(m.Target)
+		if other, ok := targets[target]; ok {
+			return errors.Errorf("%q and %q both mount %s", other, spec, target)
+		}
+		targets[target] = spec
+	}
+	return nil
+}
+
+// validateLoadBalancerPool checks the pool of LoadBalancer IPs, which kube-vip announces with ARP on the network of the nodes
+func validateLoadBalancerPool(pool, drvName string) error {
+	if pool == "" {
+		return nil
+	}
+	if driver.BareMetal(drvName) || driver.NeedsPortForward(drvName) {
+		return errors.Errorf("the IPs of the pool are not reachable from the host with the %s driver, use minikube tunnel instead", drvName)
+	}
+	if pool == "auto" {
+		return nil
+	}
+	start, end, ok := strings.Cut(pool, "-")
+	if !ok {
+		return errors.Errorf("%q is not in the START-END format", pool)
+	}
+	startIP := net.ParseIP(start).To4()
+	endIP := net.ParseIP(end).To4()
+	if startIP == nil || endIP == nil {
+		return errors.Errorf("%q is not a range of IPv4 addresses", pool)
+	}
+	if bytes.Compare(startIP, endIP) > 0 {
+		return errors.Errorf("%s is after %s", start, end)
+	}
+	return nil
+}
+
+// validateTopology checks the values of --zones and --regions, which are label values optionally prefixed with NODE=
+func validateTopology(values []string) error {
+	for _, v := range values {
+		value := v
+		if node, val, ok := strings.Cut(v, "="); ok {
+			if node == "" {
+				return errors.Errorf("%q is missing the node name", v)
+			}
+			value = val
+		}
+		if value == "" {
+			return errors.Errorf("%q is missing the value", v)
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return errors.Errorf("%q: %s", value, strings.Join(errs, "; "))
+		}
+	}
+	return nil
+}
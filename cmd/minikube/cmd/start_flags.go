@@ -18,6 +18,8 @@ package cmd
 
 import (
 	"fmt"
+	"net"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"time"
@@ -30,8 +32,10 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/drivers/kic"
 	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/drivers/vz"
 	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
 	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil/kverify"
+	"k8s.io/minikube/pkg/minikube/cluster"
 	"k8s.io/minikube/pkg/minikube/cni"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
@@ -40,10 +44,20 @@ import (
 	"k8s.io/minikube/pkg/minikube/download"
 	"k8s.io/minikube/pkg/minikube/driver"
 	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/hooks"
+	"k8s.io/minikube/pkg/minikube/idle"
+	"k8s.io/minikube/pkg/minikube/kata"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mountsync"
 	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/portforward"
 	"k8s.io/minikube/pkg/minikube/proxy"
 	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/sandbox"
 	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/tuning"
+	"k8s.io/minikube/pkg/minikube/wasm"
+	netutil "k8s.io/minikube/pkg/network"
 	pkgutil "k8s.io/minikube/pkg/util"
 	"k8s.io/minikube/pkg/version"
 )
@@ -63,9 +77,17 @@ const (
 	networkPlugin           = "network-plugin"
 	enableDefaultCNI        = "enable-default-cni"
 	cniFlag                 = "cni"
+	cniVersion              = "cni-version"
+	cniValues               = "cni-values"
+	cniOverlay              = "cni-overlay"
 	hypervVirtualSwitch     = "hyperv-virtual-switch"
 	hypervUseExternalSwitch = "hyperv-use-external-switch"
 	hypervExternalAdapter   = "hyperv-external-adapter"
+	hypervDynamicMemory     = "hyperv-dynamic-memory"
+	hypervMinMemory         = "hyperv-min-memory"
+	hypervMaxMemory         = "hyperv-max-memory"
+	hypervMemoryBuffer      = "hyperv-memory-buffer"
+	hypervNestedVirt        = "hyperv-nested-virtualization"
 	kvmNetwork              = "kvm-network"
 	kvmQemuURI              = "kvm-qemu-uri"
 	kvmGPU                  = "kvm-gpu"
@@ -92,14 +114,34 @@ const (
 	mountPortFlag           = "mount-port"
 	mountTypeFlag           = "mount-type"
 	mountUID                = "mount-uid"
+	mountSyncIgnore         = "mount-sync-ignore"
+	mountSyncConflict       = "mount-sync-conflict"
+	mountForwardEvents      = "mount-forward-events"
 	disableDriverMounts     = "disable-driver-mounts"
 	cacheImages             = "cache-images"
 	uuid                    = "uuid"
 	vpnkitSock              = "hyperkit-vpnkit-sock"
 	vsockPorts              = "hyperkit-vsock-ports"
+	vzRosetta               = "vz-rosetta"
+	vzSharedFolders         = "vz-shared-folders"
+	vzBridgeInterface       = "vz-bridge-interface"
+	pluginOpts              = "plugin-opts"
+	firecrackerKernel       = "firecracker-kernel"
+	firecrackerJailer       = "firecracker-jailer"
+	cloudHypervisorKernel   = "cloud-hypervisor-kernel"
+	tuningProfile           = "tuning"
+	tuningOpts              = "tuning-opts"
+	sysctls                 = "sysctl"
+	kernelModules           = "kernel-modules"
+	wasmRuntime             = "wasm-runtime"
+	runtimeClass            = "runtime-class"
+	containerRuntimeSandbox = "container-runtime-sandbox"
+	sandboxNamespaces       = "sandbox-namespaces"
+	ociRuntime              = "oci-runtime"
 	embedCerts              = "embed-certs"
 	noVTXCheck              = "no-vtx-check"
 	downloadOnly            = "download-only"
+	offline                 = "offline"
 	dnsProxy                = "dns-proxy"
 	hostDNSResolver         = "host-dns-resolver"
 	waitComponents          = "wait"
@@ -116,7 +158,10 @@ const (
 	hostOnlyNicType         = "host-only-nic-type"
 	natNicType              = "nat-nic-type"
 	nodes                   = "nodes"
+	ha                      = "ha"
+	controlPlanes           = "control-planes"
 	preload                 = "preload"
+	preloadFrom             = "preload-from"
 	deleteOnFailure         = "delete-on-failure"
 	forceSystemd            = "force-systemd"
 	kicBaseImage            = "base-image"
@@ -129,18 +174,42 @@ const (
 	sshSSHUser              = "ssh-user"
 	sshSSHKey               = "ssh-key"
 	sshSSHPort              = "ssh-port"
+	sshProxy                = "ssh-proxy"
 	defaultSSHUser          = "root"
 	defaultSSHPort          = 22
 	listenAddress           = "listen-address"
 	extraDisks              = "extra-disks"
 	certExpiration          = "cert-expiration"
+	spiffeTrustDomain       = "spiffe-trust-domain"
+	kubernetesImagesDir     = "kubernetes-images-dir"
+	kubeadmPatches          = "kubeadm-patches"
+	recoverState            = "recover-state"
+	upgradeStrategy         = "upgrade-strategy"
+	idleTimeout             = "idle-timeout"
+	idleAction              = "idle-action"
+	startSchedule           = "schedule"
+	cancelScheduled         = "cancel-scheduled"
+	preStartHook            = "pre-start-hook"
+	postStartHook           = "post-start-hook"
+	userData                = "user-data"
+	provision               = "provision"
+	zones                   = "zones"
+	regions                 = "regions"
+	ipFamily                = "ip-family"
+	loadBalancerPool        = "load-balancer-pool"
+	controlPlaneVIP         = "vip"
+	portForward             = "port-forward"
+	persistentMount         = "persistent-mount"
+	importHostCerts         = "import-host-certs"
 	binaryMirror            = "binary-mirror"
 	disableOptimizations    = "disable-optimizations"
+	propagateProxy          = "propagate-proxy"
 	disableMetrics          = "disable-metrics"
 	qemuFirmwarePath        = "qemu-firmware-path"
 	socketVMnetClientPath   = "socket-vmnet-client-path"
 	socketVMnetPath         = "socket-vmnet-path"
 	staticIP                = "static-ip"
+	extraNetwork            = "extra-network"
 	autoPauseInterval       = "auto-pause-interval"
 	gpus                    = "gpus"
 )
@@ -164,9 +233,10 @@ func initMinikubeFlags() {
 	startCmd.Flags().String(memory, "", fmt.Sprintf("Amount of RAM to allocate to Kubernetes (format: <number>[<unit>], where unit = b, k, m or g). Use %q to use the maximum amount of memory. Use %q to not specify a limit (Docker/Podman only)", constants.MaxResources, constants.NoLimit))
 	startCmd.Flags().String(humanReadableDiskSize, defaultDiskSize, "Disk size allocated to the minikube VM (format: <number>[<unit>], where unit = b, k, m or g).")
 	startCmd.Flags().Bool(downloadOnly, false, "If true, only download and cache files for later use - don't install or start anything.")
+	startCmd.Flags().Bool(offline, false, "If true, use only the cached artifacts, such as the ones imported by 'minikube bundle import', and fail rather than reach the network for the missing ones.")
 	startCmd.Flags().Bool(cacheImages, true, "If true, cache docker images for the current bootstrapper and load them into the machine. Always false with --driver=none.")
 	startCmd.Flags().StringSlice(isoURL, download.DefaultISOURLs(), "Locations to fetch the minikube ISO from.")
-	startCmd.Flags().String(kicBaseImage, kic.BaseImage, "The base image to use for docker/podman drivers. Intended for local development.")
+	startCmd.Flags().String(kicBaseImage, kic.BaseImage, "The base image to use for docker/podman/lxd/wsl drivers. Intended for local development.")
 	startCmd.Flags().Bool(keepContext, false, "This will keep the existing kubectl context and will create a minikube context.")
 	startCmd.Flags().Bool(embedCerts, false, "if true, will embed the certs in kubeconfig.")
 	startCmd.Flags().String(containerRuntime, constants.DefaultContainerRuntime, fmt.Sprintf("The container runtime to be used. Valid options: %s (default: auto)", strings.Join(cruntime.ValidRuntimes(), ", ")))
@@ -180,32 +250,70 @@ func initMinikubeFlags() {
 	startCmd.Flags().Uint16(mountPortFlag, defaultMountPort, mountPortDescription)
 	startCmd.Flags().String(mountTypeFlag, defaultMountType, mountTypeDescription)
 	startCmd.Flags().String(mountUID, defaultMountUID, mountUIDDescription)
+	startCmd.Flags().StringSlice(mountSyncIgnore, []string{}, syncIgnoreDescription)
+	startCmd.Flags().String(mountSyncConflict, mountsync.ConflictHost, syncConflictDescription)
+	startCmd.Flags().Bool(mountForwardEvents, false, forwardEventsDescription+", for the 9p mounts of --mount and --persistent-mount")
 	startCmd.Flags().StringSlice(config.AddonListFlag, nil, "Enable addons. see `minikube addons list` for a list of valid addon names.")
 	startCmd.Flags().String(criSocket, "", "The cri socket path to be used.")
 	startCmd.Flags().String(networkPlugin, "", "DEPRECATED: Replaced by --cni")
 	startCmd.Flags().Bool(enableDefaultCNI, false, "DEPRECATED: Replaced by --cni=bridge")
-	startCmd.Flags().String(cniFlag, "", "CNI plug-in to use. Valid options: auto, bridge, calico, cilium, flannel, kindnet, or path to a CNI manifest (default: auto)")
+	startCmd.Flags().String(cniFlag, "", "CNI plug-in to use. Valid options: auto, bridge, calico, cilium, flannel, kindnet, path to a CNI manifest, or helm:CHART to render a Helm chart with the helm of the host (default: auto)")
+	startCmd.Flags().String(cniVersion, "", "Version of the images of the calico, cilium or flannel CNI, for example 1.15.1, or of the chart of a helm:CHART CNI")
+	startCmd.Flags().StringSlice(cniValues, []string{}, "Values files passed to the chart of a helm:CHART CNI")
+	startCmd.Flags().StringSlice(cniOverlay, []string{}, "Files of patches merged into the objects of the CNI manifest with the same kind and name, for the calico, cilium, flannel, manifest and helm:CHART CNIs")
 	startCmd.Flags().StringSlice(waitComponents, kverify.DefaultWaitList, fmt.Sprintf("comma separated list of Kubernetes components to verify and wait for after starting a cluster. defaults to %q, available options: %q . other acceptable values are 'all' or 'none', 'true' and 'false'", strings.Join(kverify.DefaultWaitList, ","), strings.Join(kverify.AllComponentsList, ",")))
 	startCmd.Flags().Duration(waitTimeout, 6*time.Minute, "max time to wait per Kubernetes or host to be healthy.")
 	startCmd.Flags().Bool(nativeSSH, true, "Use native Golang SSH client (default true). Set to 'false' to use the command line 'ssh' command when accessing the docker machine. Useful for the machine drivers when they will not start with 'Waiting for SSH'.")
 	startCmd.Flags().Bool(autoUpdate, true, "If set, automatically updates drivers to the latest version. Defaults to true.")
 	startCmd.Flags().Bool(installAddons, true, "If set, install addons. Defaults to true.")
 	startCmd.Flags().IntP(nodes, "n", 1, "The number of nodes to spin up. Defaults to 1.")
+	startCmd.Flags().Bool(ha, false, "If set, start a highly available cluster of 3 control planes behind a virtual IP. Equivalent to --control-planes=3.")
+	startCmd.Flags().Int(controlPlanes, 1, "The number of control plane nodes to spin up, behind a virtual IP when more than 1. Counts toward --nodes. Defaults to 1.")
 	startCmd.Flags().Bool(preload, true, "If set, download tarball of preloaded images if available to improve start time. Defaults to true.")
+	startCmd.Flags().String(preloadFrom, "", "The preload tarball replacing the one of minikube, built by 'minikube preload build' for the same Kubernetes version and container runtime. A file of the host, or an http(s) URL which is downloaded into the cache.")
 	startCmd.Flags().Bool(noKubernetes, false, "If set, minikube VM/container will start without starting or configuring Kubernetes. (only works on new clusters)")
 	startCmd.Flags().Bool(deleteOnFailure, false, "If set, delete the current cluster if start fails and try again. Defaults to false.")
 	startCmd.Flags().Bool(forceSystemd, false, "If set, force the container runtime to use systemd as cgroup manager. Defaults to false.")
-	startCmd.Flags().String(network, "", "network to run minikube with. Now it is used by docker/podman and KVM drivers. If left empty, minikube will create a new network.")
+	startCmd.Flags().String(network, "", "network to run minikube with. Now it is used by docker/podman, KVM, QEMU and vz drivers. If left empty, minikube will create a new network. bridged:<ifname> attaches the VM to the network of a host interface, giving it an IP of that network (QEMU and vz drivers only)")
 	startCmd.Flags().StringVarP(&outputFormat, "output", "o", "text", "Format to print stdout in. Options include: [text,json]")
 	startCmd.Flags().String(trace, "", "Send trace events. Options include: [gcp]")
-	startCmd.Flags().Int(extraDisks, 0, "Number of extra disks created and attached to the minikube VM (currently only implemented for hyperkit, kvm2, and qemu2 drivers)")
+	startCmd.Flags().Int(extraDisks, 0, "Number of extra disks created and attached to the minikube VM (currently only implemented for hyperkit, kvm2, qemu2, and vz drivers)")
 	startCmd.Flags().Duration(certExpiration, constants.DefaultCertExpiration, "Duration until minikube certificate expiration, defaults to three years (26280h).")
+	startCmd.Flags().Bool(importHostCerts, false, "If true, copies the CA certificates added to the host trust store (Keychain, Windows certificate store or NSS/ca-certificates), such as the CA of a TLS intercepting corporate proxy, into the minikube certs dir so they are trusted inside the cluster.")
 	startCmd.Flags().String(binaryMirror, "", "Location to fetch kubectl, kubelet, & kubeadm binaries from.")
+	startCmd.Flags().Bool(propagateProxy, true, "Pass the proxy settings of the host, from the environment, or the system settings on Windows and macOS, to the container runtime, kubelet and addon pods, with NO_PROXY covering the nodes, services and pods")
 	startCmd.Flags().Bool(disableOptimizations, false, "If set, disables optimizations that are set for local Kubernetes. Including decreasing CoreDNS replicas from 2 to 1. Defaults to false.")
 	startCmd.Flags().Bool(disableMetrics, false, "If set, disables metrics reporting (CPU and memory usage), this can improve CPU usage. Defaults to false.")
-	startCmd.Flags().String(staticIP, "", "Set a static IP for the minikube cluster, the IP must be: private, IPv4, and the last octet must be between 2 and 254, for example 192.168.200.200 (Docker and Podman drivers only)")
+	startCmd.Flags().String(staticIP, "", "Set a static IP for the minikube cluster, the IP must be: private, IPv4, and the last octet must be between 2 and 254, for example 192.168.200.200 (Docker, Podman, KVM, Hyper-V and QEMU with socket_vmnet drivers only)")
+	startCmd.Flags().StringSlice(extraNetwork, []string{}, "Additional network to attach the nodes to, in the NAME=NETWORK format, for example storage=virbr2. NETWORK is a libvirt network or host bridge for kvm2, a network for docker and podman, and is created if it does not exist (KVM, Docker and Podman drivers only)")
+	startCmd.Flags().String(upgradeStrategy, upgradeRolling, fmt.Sprintf("How the nodes of a multi-node cluster are upgraded to a new --kubernetes-version: %q upgrades the control planes first, then drains, upgrades and uncordons the workers one at a time, %q upgrades all the nodes without draining them", upgradeRolling, upgradeAllAtOnce))
+	startCmd.Flags().String(recoverState, machine.RecoverAutoRepair, fmt.Sprintf("How to recover the state of a node restarted after an unclean shutdown: %q repairs the filesystem and containerd images, %q also restores the etcd data saved by the last clean stop when the etcd database is corrupted, %q only reports the problems", machine.RecoverAutoRepair, machine.RecoverRestoreSnapshot, machine.RecoverNone))
+	startCmd.Flags().StringSlice(zones, []string{}, "Zones to label the nodes with as topology.kubernetes.io/zone, assigned round-robin in node order, or to a node in the NODE=ZONE format, for example zone-a,zone-b,m03=zone-c")
+	startCmd.Flags().StringSlice(regions, []string{}, "Regions to label the nodes with as topology.kubernetes.io/region, assigned round-robin in node order, or to a node in the NODE=REGION format")
+	startCmd.Flags().String(ipFamily, config.IPv4Family, "The IP family of the nodes, pods and services: ipv4, ipv6 (single-stack) or dual (dual-stack). ipv6 and dual need the docker, podman or kvm2 driver, Kubernetes v1.23.0 or later, and the bridge or kindnet CNI")
+	startCmd.Flags().String(loadBalancerPool, "", "IPs given to LoadBalancer services without running minikube tunnel, in the START-END format, for example 192.168.49.200-192.168.49.254, or 'auto' for the .200 to .254 addresses of the cluster network. The IPs are announced by kube-vip on the network of the nodes, so they are reachable from the host (not supported by the none driver, nor by the drivers needing port forwarding such as docker on macOS)")
+	startCmd.Flags().String(controlPlaneVIP, "", "Put the control plane behind a virtual IP announced by kube-vip, also with a single control plane, so that control planes can be added later: an IPv4 address of the cluster network, or 'auto' for its last address. The apiserver certificate includes it, and LoadBalancer services get IPs of the network as with --load-balancer-pool=auto unless that flag is set (same drivers as --load-balancer-pool)")
+	startCmd.Flags().StringArray(portForward, []string{}, "Port of a service or pod forwarded to 127.0.0.1 of the host while the cluster runs, in the [NAMESPACE/]svc/NAME:HOSTPORT:PORT or [NAMESPACE/]pod/SELECTOR:HOSTPORT:PORT format, for example svc/web:8080:80. Can be repeated, and replaces the forwards of an existing cluster")
+	startCmd.Flags().StringArray(persistentMount, []string{}, "Directory of the host mounted in the primary control plane by a background mount process on every start, without running minikube mount, in the SRC:DST[,type=9p|sync][,uid=UID][,gid=GID][,ro] format, for example $HOME/src:/src,type=sync. The type, uid and gid default to 9p and to --mount-uid and --mount-gid, and ro keeps the node from changing the host directory. Can be repeated, and replaces the mounts of an existing cluster, or removes them if empty")
+	startCmd.Flags().Duration(idleTimeout, 0, "Duration without kubectl activity after which the cluster is paused or stopped, as set with --idle-action, to save battery and memory. kubectl then reaches the apiserver through a proxy on 127.0.0.1, which resumes the cluster on the next call, while the long running commands of minikube, such as tunnel and dashboard, connect past it and do not count as activity. To disable, set to 0s")
+	startCmd.Flags().String(idleAction, idle.ActionPause, fmt.Sprintf("What is done to a cluster idle for --idle-timeout: %q pauses the kube-system containers, which resumes in seconds, %q stops the machines, which frees their memory but restarts the cluster on the next kubectl call, which may time out meanwhile", idle.ActionPause, idle.ActionStop))
+	startCmd.Flags().String(startSchedule, "", "Recurring schedule on which a timer of the host starts the cluster, such as before the workday: a cron expression (MINUTE HOUR DAY MONTH WEEKDAY, for example '0 9 * * 1-5') or the [WEEKDAYS] HH:MM format (for example 'Mon-Fri 09:00'). Uses systemd user timers on Linux, launchd on macOS, and the task scheduler on Windows, which only supports a single time of some days of the week")
+	startCmd.Flags().Bool(cancelScheduled, false, "Remove the timer starting the cluster on the schedule set with --schedule, without starting the cluster")
+	startCmd.Flags().StringArray(preStartHook, []string{}, "Command run before kubeadm initializes or restarts the control plane, on each start, in the [host:|guest:]COMMAND format: by the shell of the host by default, or as root by bash in the primary control plane with guest:. Runs with the MINIKUBE_PROFILE, MINIKUBE_HOOK and MINIKUBE_IP environment variables, and KUBECONFIG and kubectl of the cluster in the guest. Not run with --no-kubernetes. Can be repeated, and replaces the hooks of an existing cluster")
+	startCmd.Flags().StringArray(postStartHook, []string{}, "Command run once all the nodes are Ready, on each start, such as to log into a registry or apply base manifests, in the same format and environment as --pre-start-hook. Can be repeated, and replaces the hooks of an existing cluster")
+	startCmd.Flags().String(userData, "", "cloud-init user-data file applied to the VMs on boot, to add users, files, sysctls and commands without building a custom ISO: a script starting with #!, run on the first boot, or a #cloud-config of which bootcmd, write_files, groups and users are applied on each boot, as the root filesystem of the ISO is in memory, and runcmd on the first boot. The other keys, such as packages as the ISO has no package manager, are rejected. Only supported by the VM drivers")
+	startCmd.Flags().String(provision, "", "YAML manifest of the files and systemd drop-ins kept provisioned in the nodes, as the files of ~/.minikube/files are: 'files' with a path, a content or a source file relative to the manifest, a mode, an owner and the systemd units to restart when they change, and 'systemdDropIns' with a unit, a name and a content, which restart their unit when they change. They are compared and applied on every start, and the ones no longer declared are removed. Replaces the manifest of an existing cluster, or removes it if empty")
 	startCmd.Flags().Duration(autoPauseInterval, time.Minute*1, "Duration of inactivity before the minikube VM is paused (default 1m0s).  To disable, set to 0s")
-	startCmd.Flags().StringP(gpus, "g", "", "Allow pods to use your NVIDIA GPUs. Options include: [all,nvidia] (Docker driver with Docker container-runtime only)")
+	startCmd.Flags().StringP(gpus, "g", "", "Allow pods to use your NVIDIA GPUs. Options include: [all,nvidia] (Docker driver with the docker, containerd or crio container-runtime, or kvm2 driver which also accepts a comma separated list of PCI addresses to passthrough)")
+	startCmd.Flags().String(tuningProfile, "", fmt.Sprintf("Tuning profile of the kernel and ulimits of the nodes. Options include: [%s]. 'dev' raises the inotify, open files and pid limits for file watchers and controllers with many watches", strings.Join(tuning.Profiles, ",")))
+	startCmd.Flags().StringSlice(tuningOpts, []string{}, "Override values of the tuning profile, in the key=value format where key is a sysctl or 'nofile', for example fs.inotify.max_user_watches=2097152,nofile=65536")
+	startCmd.Flags().StringSlice(sysctls, []string{}, "Sysctls set on the nodes before the kubelet starts, in the key=value format, for example net.ipv4.ip_forward=1,net.netfilter.nf_conntrack_max=262144. They override the values of the tuning profile, and are checked against the running kernel of the nodes. The docker and podman drivers share the kernel of the host, on which they must be set")
+	startCmd.Flags().StringSlice(kernelModules, []string{}, "Kernel modules loaded on the nodes before the kubelet starts, for example sctp,nf_conntrack. They are checked against the running kernel of the nodes, and loaded before the sysctls they add are set. The docker and podman drivers share the kernel of the host, on which they must be loaded")
+	startCmd.Flags().String(wasmRuntime, "", fmt.Sprintf("Install the runwasi shim of a WebAssembly runtime into the nodes, register it with containerd, and create the '%s' RuntimeClass running the pods selecting it with the shim. Options include: [%s]. Only supported by the containerd container runtime", wasm.RuntimeClassName, strings.Join(wasm.Runtimes, ",")))
+	startCmd.Flags().String(runtimeClass, "", fmt.Sprintf("Install a sandboxed runtime into the nodes, register its handler with the container runtime, and create the RuntimeClass of its name running the pods selecting it. Options include: [%s]. 'kata' runs the pods in the VMs of Kata Containers, which needs nested virtualization: the kvm2 and qemu2 drivers, hyperv with --hyperv-nested-virt, or the docker and podman drivers on a Linux host with /dev/kvm. Only supported by the containerd and cri-o container runtimes", strings.Join(kata.RuntimeClasses, ",")))
+	startCmd.Flags().String(containerRuntimeSandbox, "", fmt.Sprintf("Sandboxed runtime registered with the container runtime, run by the pods selecting the RuntimeClass of its name. Options include: [%s]. Its binaries are carried by the preloads, or downloaded. Only supported by the containerd and cri-o container runtimes", strings.Join(sandbox.Sandboxes, ",")))
+	startCmd.Flags().StringSlice(sandboxNamespaces, []string{}, "Namespaces whose pods run in the sandbox of --container-runtime-sandbox unless they select a RuntimeClass, with a MutatingAdmissionPolicy enabled in the apiserver. Requires Kubernetes v1.32 or later")
+	startCmd.Flags().String(ociRuntime, "", fmt.Sprintf("Low-level OCI runtime run by the container runtime to create the containers. Options include: [%s]. 'crun' starts containers faster and with less memory than the default 'runc', and needs the nodes to use cgroup v2. Only supported by the containerd and cri-o container runtimes", strings.Join(cruntime.OCIRuntimes(), ",")))
 }
 
 // initKubernetesFlags inits the commandline flags for Kubernetes related options
@@ -223,6 +331,9 @@ func initKubernetesFlags() {
 	startCmd.Flags().String(apiServerName, constants.APIServerName, "The authoritative apiserver hostname for apiserver certificates and connectivity. This can be used if you want to make the apiserver available from outside the machine")
 	startCmd.Flags().StringSliceVar(&apiServerNames, "apiserver-names", nil, "A set of apiserver names which are used in the generated certificate for kubernetes.  This can be used if you want to make the apiserver available from outside the machine")
 	startCmd.Flags().IPSliceVar(&apiServerIPs, "apiserver-ips", nil, "A set of apiserver IP Addresses which are used in the generated certificate for kubernetes.  This can be used if you want to make the apiserver available from outside the machine")
+	startCmd.Flags().String(spiffeTrustDomain, "", "If set, embeds SPIFFE IDs (spiffe://<trust-domain>/...) as URI SANs in the generated apiserver and client certificates, e.g. cluster.local")
+	startCmd.Flags().String(kubernetesImagesDir, "", "Directory of a local build of Kubernetes, holding the kube-apiserver, kube-controller-manager, kube-scheduler and kube-proxy image tarballs and the kubeadm, kubelet and kubectl binaries. The Kubernetes version is the one of the build.")
+	startCmd.Flags().String(kubeadmPatches, "", "Directory of patches applied by kubeadm (its --patches flag) to the kube-apiserver, kube-controller-manager, kube-scheduler and etcd static pods and to the kubelet configuration, named TARGET[SUFFIX][+PATCHTYPE].EXTENSION, e.g. kube-apiserver+merge.yaml. Requires Kubernetes v1.22 or later.")
 }
 
 // initDriverFlags inits the commandline flags for vm drivers
@@ -258,12 +369,32 @@ func initDriverFlags() {
 	startCmd.Flags().String(hypervVirtualSwitch, "", "The hyperv virtual switch name. Defaults to first found. (hyperv driver only)")
 	startCmd.Flags().Bool(hypervUseExternalSwitch, false, "Whether to use external switch over Default Switch if virtual switch not explicitly specified. (hyperv driver only)")
 	startCmd.Flags().String(hypervExternalAdapter, "", "External Adapter on which external switch will be created if no external switch is found. (hyperv driver only)")
+	startCmd.Flags().Bool(hypervDynamicMemory, false, "Enable dynamic memory, so the VM starts with --memory and Hyper-V balloons it between --hyperv-min-memory and --hyperv-max-memory. (hyperv driver only)")
+	startCmd.Flags().String(hypervMinMemory, "", "Minimum memory of the VM with dynamic memory, defaults to the Hyper-V minimum. (hyperv driver only)")
+	startCmd.Flags().String(hypervMaxMemory, "", "Maximum memory of the VM with dynamic memory, defaults to the Hyper-V maximum. (hyperv driver only)")
+	startCmd.Flags().Int(hypervMemoryBuffer, 0, "Percentage of memory Hyper-V reserves above the demand of the VM with dynamic memory, between 5 and 2000. Defaults to 20. (hyperv driver only)")
+	startCmd.Flags().Bool(hypervNestedVirt, false, "Expose the virtualization extensions of the CPU to the VM, to run VMs inside minikube. Not compatible with dynamic memory. (hyperv driver only)")
 
 	// docker & podman
 	startCmd.Flags().String(listenAddress, "", "IP Address to use to expose ports (docker and podman driver only)")
 	startCmd.Flags().StringSlice(ports, []string{}, "List of ports that should be exposed (docker and podman driver only)")
 	startCmd.Flags().String(subnet, "", "Subnet to be used on kic cluster. If left empty, minikube will choose subnet address, beginning from 192.168.49.0. (docker and podman driver only)")
 
+	// vz
+	startCmd.Flags().Bool(vzRosetta, false, "Run amd64 binaries and images with Rosetta (vz driver on Apple silicon only)")
+	startCmd.Flags().StringSlice(vzSharedFolders, []string{}, "Host folders to share with the guest via virtiofs, in the HOST_PATH:GUEST_PATH format (vz driver only)")
+	startCmd.Flags().String(vzBridgeInterface, "en0", "Host interface the VM is bridged to with --network=bridged, through a socket_vmnet running in bridged mode on it (vz driver only)")
+
+	// plugin
+	startCmd.Flags().StringSlice(pluginOpts, []string{}, "Options passed to an out-of-tree driver plugin, in the key=value format (plugin:<name> drivers only)")
+
+	// firecracker
+	startCmd.Flags().String(firecrackerKernel, "", "Path of an uncompressed vmlinux image built with the minikube kernel config (firecracker driver only)")
+	startCmd.Flags().Bool(firecrackerJailer, false, "Run firecracker chrooted and unprivileged with the Firecracker jailer (firecracker driver only)")
+
+	// cloud-hypervisor
+	startCmd.Flags().String(cloudHypervisorKernel, "", "Path of an uncompressed vmlinux image built with the minikube kernel config (cloud-hypervisor driver only)")
+
 	// qemu
 	startCmd.Flags().String(qemuFirmwarePath, "", "Path to the qemu firmware file. Defaults: For Linux, the default firmware location. For macOS, the brew installation location. For Windows, C:\\Program Files\\qemu\\share")
 }
@@ -283,6 +414,7 @@ func initNetworkingFlags() {
 	startCmd.Flags().String(sshSSHUser, defaultSSHUser, "SSH user (ssh driver only)")
 	startCmd.Flags().String(sshSSHKey, "", "SSH key (ssh driver only)")
 	startCmd.Flags().Int(sshSSHPort, defaultSSHPort, "SSH port (ssh driver only)")
+	startCmd.Flags().String(sshProxy, "", "Reach the machines through an SSH jump host (ssh://[user@]host[:port]) or a SOCKS5 proxy (socks5://[user[:password]@]host[:port]), for provisioning and 'minikube ssh'")
 
 	// socket vmnet
 	startCmd.Flags().String(socketVMnetClientPath, "", "Path to the socket vmnet client binary (QEMU driver only)")
@@ -372,7 +504,7 @@ func getMemorySize(cmd *cobra.Command, drvName string) int {
 		klog.Warningf("Unable to query memory limits: %+v", err)
 	}
 
-	mem := suggestMemoryAllocation(sysLimit, containerLimit, viper.GetInt(nodes))
+	mem := suggestMemoryAllocation(sysLimit, containerLimit, requestedNodes())
 	if cmd.Flags().Changed(memory) || viper.IsSet(memory) {
 		memString := viper.GetString(memory)
 		var err error
@@ -397,6 +529,19 @@ func getMemorySize(cmd *cobra.Command, drvName string) int {
 	return mem
 }
 
+// getHypervMemorySize returns the size in MB of a dynamic memory flag of the hyperv driver, 0 if unset
+func getHypervMemorySize(flag string) int {
+	size := viper.GetString(flag)
+	if size == "" {
+		return 0
+	}
+	mb, err := pkgutil.CalculateSizeInMB(size)
+	if err != nil {
+		exit.Message(reason.Usage, "Unable to parse {{.flag}} '{{.size}}': {{.error}}", out.V{"flag": flag, "size": size, "error": err})
+	}
+	return mb
+}
+
 func getDiskSize() int {
 	diskSize, err := pkgutil.CalculateSizeInMB(viper.GetString(humanReadableDiskSize))
 	if err != nil {
@@ -468,11 +613,63 @@ func getCNIConfig(cmd *cobra.Command) string {
 	return chosenCNI
 }
 
+// absPaths returns the absolute paths of files given on the command line, which the cluster uses from any directory
+func absPaths(paths []string) []string {
+	var abs []string
+	for _, p := range paths {
+		a, err := filepath.Abs(p)
+		if err != nil {
+			klog.Warningf("unable to get the absolute path of %s: %v", p, err)
+			a = p
+		}
+		abs = append(abs, a)
+	}
+	return abs
+}
+
+// kubeadmPatchesDir returns the absolute path of the --kubeadm-patches directory, which start may not be run from again
+func kubeadmPatchesDir() string {
+	dir := viper.GetString(kubeadmPatches)
+	if dir == "" {
+		return ""
+	}
+	return absPaths([]string{dir})[0]
+}
+
+// userDataFile returns the absolute path of the --user-data file, which start may not be run from again
+func userDataFile() string {
+	f := viper.GetString(userData)
+	if f == "" {
+		return ""
+	}
+	return absPaths([]string{f})[0]
+}
+
+// provisionFromFlag returns the files and drop-ins of the --provision manifest, which validateFlags already checked
+func provisionFromFlag() ([]config.GuestFile, []config.SystemdDropIn) {
+	f := viper.GetString(provision)
+	if f == "" {
+		return nil, nil
+	}
+	m, err := machine.LoadProvisionManifest(absPaths([]string{f})[0])
+	if err != nil {
+		klog.Warningf("skipping the provision manifest %s: %v", f, err)
+		return nil, nil
+	}
+	return m.Files, m.SystemdDropIns
+}
+
 func getNetwork(driverName string) string {
 	n := viper.GetString(network)
+	if driverName == driver.VZ {
+		return getVZNetwork(n)
+	}
 	if !driver.IsQEMU(driverName) {
 		return n
 	}
+	if _, ok := netutil.ParseBridged(n); ok {
+		return getQEMUBridgedNetwork(n)
+	}
 	switch n {
 	case "socket_vmnet":
 		if runtime.GOOS != "darwin" {
@@ -492,7 +689,7 @@ func getNetwork(driverName string) string {
 		n = "builtin"
 	case "builtin":
 	default:
-		exit.Message(reason.Usage, "--network with QEMU must be 'builtin' or 'socket_vmnet'")
+		exit.Message(reason.Usage, "--network with QEMU must be 'builtin', 'socket_vmnet' or 'bridged:<ifname>'")
 	}
 	if n == "builtin" {
 		msg := "You are using the QEMU driver without a dedicated network, which doesn't support `minikube service` & `minikube tunnel` commands."
@@ -504,6 +701,53 @@ func getNetwork(driverName string) string {
 	return n
 }
 
+// getQEMUBridgedNetwork validates the bridged network of the QEMU driver, which has to name the host interface:
+// an interface with socket_vmnet running in bridged mode on it on macOS, or a bridge on Linux
+func getQEMUBridgedNetwork(n string) string {
+	iface, _ := netutil.ParseBridged(n)
+	if iface == "" {
+		exit.Message(reason.Usage, "--network=bridged with QEMU must name the host interface, as in --network=bridged:en0")
+	}
+	if _, err := net.InterfaceByName(iface); err != nil {
+		exit.Message(reason.Usage, "The host interface {{.iface}} of --network was not found: {{.error}}", out.V{"iface": iface, "error": err})
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		if detect.SocketVMNetClientPath() == "" {
+			exit.Message(reason.NotFoundSocketVMNet, "\n\n")
+		}
+	case "linux":
+	default:
+		exit.Message(reason.Usage, "The bridged network of QEMU is only supported on macOS and Linux")
+	}
+	return n
+}
+
+// getVZNetwork validates the network of the vz driver, the macOS shared network by default
+func getVZNetwork(n string) string {
+	switch n {
+	case "", vz.NetworkNAT:
+		return vz.NetworkNAT
+	}
+	if _, ok := netutil.ParseBridged(n); ok {
+		if detect.SocketVMNetClientPath() == "" {
+			exit.Message(reason.NotFoundSocketVMNet, "\n\n")
+		}
+		return n
+	}
+	exit.Message(reason.Usage, "--network with vz must be 'nat', 'bridged' or 'bridged:<ifname>'")
+	return ""
+}
+
+// bridgeInterface returns the host interface of the bridged network of the cluster,
+// the one of --vz-bridge-interface for the vz driver when the network does not name one
+func bridgeInterface(cc config.ClusterConfig) string {
+	if iface, _ := netutil.ParseBridged(cc.Network); iface != "" {
+		return iface
+	}
+	return cc.VZBridgeInterface
+}
+
 // generateNewConfigFromFlags generate a config.ClusterConfig based on flags
 func generateNewConfigFromFlags(cmd *cobra.Command, k8sVersion string, rtime string, drvName string) config.ClusterConfig {
 	var cc config.ClusterConfig
@@ -538,6 +782,23 @@ func generateNewConfigFromFlags(cmd *cobra.Command, k8sVersion string, rtime str
 		HyperkitVpnKitSock:      viper.GetString(vpnkitSock),
 		HyperkitVSockPorts:      viper.GetStringSlice(vsockPorts),
 		NFSShare:                viper.GetStringSlice(nfsShare),
+		VZRosetta:               viper.GetBool(vzRosetta),
+		VZSharedFolders:         viper.GetStringSlice(vzSharedFolders),
+		VZBridgeInterface:       viper.GetString(vzBridgeInterface),
+		PluginOptions:           viper.GetStringSlice(pluginOpts),
+		PreloadFrom:             viper.GetString(preloadFrom),
+		FirecrackerKernel:       viper.GetString(firecrackerKernel),
+		FirecrackerJailer:       viper.GetBool(firecrackerJailer),
+		CloudHypervisorKernel:   viper.GetString(cloudHypervisorKernel),
+		Tuning:                  viper.GetString(tuningProfile),
+		TuningOptions:           viper.GetStringSlice(tuningOpts),
+		Sysctls:                 viper.GetStringSlice(sysctls),
+		KernelModules:           viper.GetStringSlice(kernelModules),
+		WasmRuntime:             viper.GetString(wasmRuntime),
+		RuntimeClass:            viper.GetString(runtimeClass),
+		ContainerRuntimeSandbox: viper.GetString(containerRuntimeSandbox),
+		SandboxNamespaces:       viper.GetStringSlice(sandboxNamespaces),
+		OCIRuntime:              viper.GetString(ociRuntime),
 		NFSSharesRoot:           viper.GetString(nfsSharesRoot),
 		DockerEnv:               config.DockerEnv,
 		DockerOpt:               config.DockerOpt,
@@ -547,6 +808,11 @@ func generateNewConfigFromFlags(cmd *cobra.Command, k8sVersion string, rtime str
 		HypervVirtualSwitch:     viper.GetString(hypervVirtualSwitch),
 		HypervUseExternalSwitch: viper.GetBool(hypervUseExternalSwitch),
 		HypervExternalAdapter:   viper.GetString(hypervExternalAdapter),
+		HypervDynamicMemory:     viper.GetBool(hypervDynamicMemory),
+		HypervMinMemory:         getHypervMemorySize(hypervMinMemory),
+		HypervMaxMemory:         getHypervMemorySize(hypervMaxMemory),
+		HypervMemoryBuffer:      viper.GetInt(hypervMemoryBuffer),
+		HypervNestedVirt:        viper.GetBool(hypervNestedVirt),
 		KVMNetwork:              viper.GetString(kvmNetwork),
 		KVMQemuURI:              viper.GetString(kvmQemuURI),
 		KVMGPU:                  viper.GetBool(kvmGPU),
@@ -565,8 +831,10 @@ func generateNewConfigFromFlags(cmd *cobra.Command, k8sVersion string, rtime str
 		SSHUser:                 viper.GetString(sshSSHUser),
 		SSHKey:                  viper.GetString(sshSSHKey),
 		SSHPort:                 viper.GetInt(sshSSHPort),
+		SSHProxy:                viper.GetString(sshProxy),
 		ExtraDisks:              viper.GetInt(extraDisks),
 		CertExpiration:          viper.GetDuration(certExpiration),
+		ImportHostCerts:         viper.GetBool(importHostCerts),
 		Mount:                   viper.GetBool(createMount),
 		MountString:             viper.GetString(mountString),
 		Mount9PVersion:          viper.GetString(mount9PVersion),
@@ -577,13 +845,18 @@ func generateNewConfigFromFlags(cmd *cobra.Command, k8sVersion string, rtime str
 		MountPort:               uint16(viper.GetUint(mountPortFlag)),
 		MountType:               viper.GetString(mountTypeFlag),
 		MountUID:                viper.GetString(mountUID),
+		MountSyncIgnore:         viper.GetStringSlice(mountSyncIgnore),
+		MountSyncConflict:       viper.GetString(mountSyncConflict),
+		MountForwardEvents:      viper.GetBool(mountForwardEvents),
 		BinaryMirror:            viper.GetString(binaryMirror),
 		DisableOptimizations:    viper.GetBool(disableOptimizations),
+		DisableProxyPropagation: !viper.GetBool(propagateProxy),
 		DisableMetrics:          viper.GetBool(disableMetrics),
 		CustomQemuFirmwarePath:  viper.GetString(qemuFirmwarePath),
 		SocketVMnetClientPath:   detect.SocketVMNetClientPath(),
 		SocketVMnetPath:         detect.SocketVMNetPath(),
 		StaticIP:                viper.GetString(staticIP),
+		ExtraNetworks:           viper.GetStringSlice(extraNetwork),
 		KubernetesConfig: config.KubernetesConfig{
 			KubernetesVersion:      k8sVersion,
 			ClusterName:            ClusterFlagValue(),
@@ -591,25 +864,54 @@ func generateNewConfigFromFlags(cmd *cobra.Command, k8sVersion string, rtime str
 			APIServerName:          viper.GetString(apiServerName),
 			APIServerNames:         apiServerNames,
 			APIServerIPs:           apiServerIPs,
+			SPIFFETrustDomain:      viper.GetString(spiffeTrustDomain),
+			KubernetesImagesDir:    localBuildDir(nil),
+			KubeadmPatches:         kubeadmPatchesDir(),
 			DNSDomain:              viper.GetString(dnsDomain),
 			FeatureGates:           viper.GetString(featureGates),
 			ContainerRuntime:       rtime,
 			CRISocket:              viper.GetString(criSocket),
 			NetworkPlugin:          chosenNetworkPlugin,
 			ServiceCIDR:            viper.GetString(serviceCIDR),
+			IPFamily:               viper.GetString(ipFamily),
 			ImageRepository:        getRepository(cmd, k8sVersion),
 			ExtraOptions:           getExtraOptions(),
 			ShouldLoadCachedImages: viper.GetBool(cacheImages),
 			CNI:                    getCNIConfig(cmd),
+			CNIVersion:             viper.GetString(cniVersion),
+			CNIValues:              absPaths(viper.GetStringSlice(cniValues)),
+			CNIOverlays:            absPaths(viper.GetStringSlice(cniOverlay)),
 			NodePort:               viper.GetInt(apiServerPort),
 		},
-		MultiNodeRequested: viper.GetInt(nodes) > 1,
+		MultiNodeRequested: requestedNodes() > 1,
 		AutoPauseInterval:  viper.GetDuration(autoPauseInterval),
+		StateRecovery:      viper.GetString(recoverState),
+		Zones:              viper.GetStringSlice(zones),
+		Regions:            viper.GetStringSlice(regions),
+		RemoteHost:         viper.GetString(config.RemoteHost),
 		GPUs:               viper.GetString(gpus),
+		LoadBalancerPool:   loadBalancerPoolFromFlags(),
+		PortForwards:       portForwardsFromFlag(cmd),
+		PersistentMounts:   persistentMountsFromFlag(cmd),
+		IdleTimeout:        viper.GetDuration(idleTimeout),
+		IdleAction:         viper.GetString(idleAction),
+		PreStartHooks:      hooksFromFlag(cmd, preStartHook),
+		PostStartHooks:     hooksFromFlag(cmd, postStartHook),
+		UserData:           userDataFile(),
 	}
 	cc.VerifyComponents = interpretWaitFlag(*cmd)
-	if viper.GetBool(createMount) && driver.IsKIC(drvName) {
+	cc.GuestFiles, cc.SystemdDropIns = provisionFromFlag()
+	// on macOS, the bridged network goes through the socket_vmnet running in bridged mode on the interface
+	_, bridged := netutil.ParseBridged(cc.Network)
+	if bridged && (drvName == driver.VZ || (driver.IsQEMU(drvName) && runtime.GOOS == "darwin")) && !cmd.Flags().Changed(socketVMnetPath) {
+		cc.SocketVMnetPath = detect.SocketVMNetBridgedPath(bridgeInterface(cc))
+	}
+	// the sync mount type syncs the directory with the container instead of mounting it
+	if viper.GetBool(createMount) && driver.IsKIC(drvName) && viper.GetString(mountTypeFlag) != constants.SyncMountType {
 		cc.ContainerVolumeMounts = []string{viper.GetString(mountString)}
+		if oci.IsExternalDaemonHost(drvName) {
+			out.WarningT("The directory mounted with {{.mount}} is on the remote {{.driver}} host {{.host}}, not on this machine", out.V{"mount": viper.GetString(mountString), "driver": drvName, "host": oci.DaemonHost(drvName)})
+		}
 	}
 
 	if driver.IsKIC(drvName) {
@@ -632,6 +934,14 @@ func generateNewConfigFromFlags(cmd *cobra.Command, k8sVersion string, rtime str
 			}
 			out.Styled(style.Notice, "Using {{.driver_name}} driver with root privileges", out.V{"driver_name": driver.FullName(drvName)})
 		}
+		// the registry addon is published on the same port of the host across the restarts of the container, so the host keeps trusting it
+		if !oci.IsExternalDaemonHost(drvName) {
+			port, err := idle.FreePort()
+			if err != nil {
+				klog.Warningf("unable to pick the port of the registry addon: %v", err)
+			}
+			cc.RegistryPort = port
+		}
 		// for btrfs: if k8s < v1.25.0-beta.0 set kubelet's LocalStorageCapacityIsolation feature gate flag to false,
 		// and if k8s >= v1.25.0-beta.0 (when it went ga and removed as feature gate), set kubelet's localStorageCapacityIsolation option (via kubeadm config) to false.
 		// ref: https://github.com/kubernetes/minikube/issues/14728#issue-1327885840
@@ -645,6 +955,18 @@ func generateNewConfigFromFlags(cmd *cobra.Command, k8sVersion string, rtime str
 				}
 			}
 		}
+		// kubelet QoS cgroups cannot be nested inside a cgroup v1 container, e.g. docker-in-docker on CI runners
+		if detect.Nested().NeedsFlatCgroups() {
+			klog.Infof("auto-disabling kubelet QoS cgroups because minikube runs nested in a %s with cgroup v1", detect.Nested())
+			for _, eo := range []string{"kubelet.cgroups-per-qos=false", "kubelet.enforce-node-allocatable=\"\""} {
+				if cc.KubernetesConfig.ExtraOptions.Exists(eo) {
+					continue
+				}
+				if err := cc.KubernetesConfig.ExtraOptions.Set(eo); err != nil {
+					exit.Error(reason.InternalConfigSet, "failed to set extra option", err)
+				}
+			}
+		}
 		if runtime.GOOS == "linux" && si.DockerOS == "Docker Desktop" {
 			out.WarningT("For an improved experience it's recommended to use Docker Engine instead of Docker Desktop.\nDocker Engine installation instructions: https://docs.docker.com/engine/install/#server")
 		}
@@ -684,17 +1006,12 @@ func checkNumaCount(k8sVersion string) {
 	}
 }
 
-// upgradeExistingConfig upgrades legacy configuration files
+// upgradeExistingConfig upgrades legacy configuration files with the defaults of the flags, the rest is migrated by config.Migrate on load
 func upgradeExistingConfig(cmd *cobra.Command, cc *config.ClusterConfig) {
 	if cc == nil {
 		return
 	}
 
-	if cc.VMDriver != "" && cc.Driver == "" {
-		klog.Infof("config upgrade: Driver=%s", cc.VMDriver)
-		cc.Driver = cc.VMDriver
-	}
-
 	if cc.Name == "" {
 		klog.Infof("config upgrade: Name=%s", ClusterFlagValue())
 		cc.Name = ClusterFlagValue()
@@ -717,18 +1034,6 @@ func upgradeExistingConfig(cmd *cobra.Command, cc *config.ClusterConfig) {
 		cc.Memory = memInMB
 	}
 
-	// pre minikube 1.9.2 cc.KubernetesConfig.NodePort was not populated.
-	// in minikube config there were two fields for api server port.
-	// one in cc.KubernetesConfig.NodePort and one in cc.Nodes.Port
-	// this makes sure api server port not be set as 0!
-	if cc.KubernetesConfig.NodePort == 0 {
-		cc.KubernetesConfig.NodePort = viper.GetInt(apiServerPort)
-	}
-
-	if cc.CertExpiration == 0 {
-		cc.CertExpiration = constants.DefaultCertExpiration
-	}
-
 }
 
 // updateExistingConfigFromFlags will update the existing config from the flags - used on a second start
@@ -739,11 +1044,24 @@ func updateExistingConfigFromFlags(cmd *cobra.Command, existing *config.ClusterC
 
 	cc := *existing
 
-	if cmd.Flags().Changed(memory) && getMemorySize(cmd, cc.Driver) != cc.Memory {
-		out.WarningT("You cannot change the memory size for an existing minikube cluster. Please first delete the cluster.")
+	// the machines are resized by provisionWithDriver, see resizeExisting
+	resizable := machine.SupportsResize(cc.Driver)
+	if cmd.Flags().Changed(memory) || (resizable && viper.IsSet(memory)) {
+		if mem := getMemorySize(cmd, cc.Driver); mem != cc.Memory {
+			if resizable {
+				cc.Memory = mem
+			} else {
+				out.WarningT("You cannot change the memory size for an existing minikube cluster. Please first delete the cluster.")
+			}
+		}
 	}
 
-	if cmd.Flags().Changed(cpus) && viper.GetInt(cpus) != cc.CPUs {
+	if resizable && (cmd.Flags().Changed(cpus) || viper.IsSet(cpus)) {
+		if cpuCount := getCPUCount(cc.Driver); cpuCount != cc.CPUs {
+			validateCPUCount(cc.Driver)
+			cc.CPUs = cpuCount
+		}
+	} else if cmd.Flags().Changed(cpus) && viper.GetInt(cpus) != cc.CPUs {
 		out.WarningT("You cannot change the CPUs for an existing minikube cluster. Please first delete the cluster.")
 	}
 
@@ -751,7 +1069,11 @@ func updateExistingConfigFromFlags(cmd *cobra.Command, existing *config.ClusterC
 	validateRequestedMemorySize(cc.Memory, cc.Driver)
 
 	if cmd.Flags().Changed(humanReadableDiskSize) && getDiskSize() != existing.DiskSize {
-		out.WarningT("You cannot change the disk size for an existing minikube cluster. Please first delete the cluster.")
+		if machine.SupportsDiskResize(existing.Driver) && getDiskSize() > existing.DiskSize {
+			out.WarningT("You cannot change the disk size with 'minikube start' for an existing minikube cluster. Grow its disk with: minikube resize --disk={{.size}}", out.V{"size": viper.GetString(humanReadableDiskSize)})
+		} else {
+			out.WarningT("You cannot change the disk size for an existing minikube cluster. Please first delete the cluster.")
+		}
 	}
 
 	checkExtraDiskOptions(cmd, cc.Driver)
@@ -763,6 +1085,14 @@ func updateExistingConfigFromFlags(cmd *cobra.Command, existing *config.ClusterC
 		out.WarningT("You cannot change the static IP of an existing minikube cluster. Please first delete the cluster.")
 	}
 
+	if cmd.Flags().Changed(extraNetwork) && strings.Join(viper.GetStringSlice(extraNetwork), ",") != strings.Join(existing.ExtraNetworks, ",") {
+		out.WarningT("You cannot change the extra networks of an existing minikube cluster. Please first delete the cluster.")
+	}
+
+	if cmd.Flags().Changed(ipFamily) && viper.GetString(ipFamily) != existing.KubernetesConfig.IPFamily && (existing.KubernetesConfig.IPFamily != "" || viper.GetString(ipFamily) != config.IPv4Family) {
+		out.WarningT("You cannot change the IP family of an existing minikube cluster. Please first delete the cluster.")
+	}
+
 	updateBoolFromFlag(cmd, &cc.KeepContext, keepContext)
 	updateBoolFromFlag(cmd, &cc.EmbedCerts, embedCerts)
 	updateStringFromFlag(cmd, &cc.MinikubeISO, isoURL)
@@ -771,11 +1101,37 @@ func updateExistingConfigFromFlags(cmd *cobra.Command, existing *config.ClusterC
 	updateStringFromFlag(cmd, &cc.HyperkitVpnKitSock, vpnkitSock)
 	updateStringSliceFromFlag(cmd, &cc.HyperkitVSockPorts, vsockPorts)
 	updateStringSliceFromFlag(cmd, &cc.NFSShare, nfsShare)
+	updateBoolFromFlag(cmd, &cc.VZRosetta, vzRosetta)
+	updateStringSliceFromFlag(cmd, &cc.VZSharedFolders, vzSharedFolders)
+	updateStringFromFlag(cmd, &cc.VZBridgeInterface, vzBridgeInterface)
+	updateStringSliceFromFlag(cmd, &cc.PluginOptions, pluginOpts)
+	updateStringFromFlag(cmd, &cc.PreloadFrom, preloadFrom)
+	updateStringFromFlag(cmd, &cc.FirecrackerKernel, firecrackerKernel)
+	updateBoolFromFlag(cmd, &cc.FirecrackerJailer, firecrackerJailer)
+	updateStringFromFlag(cmd, &cc.CloudHypervisorKernel, cloudHypervisorKernel)
+	updateStringFromFlag(cmd, &cc.Tuning, tuningProfile)
+	updateStringSliceFromFlag(cmd, &cc.TuningOptions, tuningOpts)
+	updateStringSliceFromFlag(cmd, &cc.Sysctls, sysctls)
+	updateStringSliceFromFlag(cmd, &cc.KernelModules, kernelModules)
+	updateStringFromFlag(cmd, &cc.WasmRuntime, wasmRuntime)
+	updateStringFromFlag(cmd, &cc.RuntimeClass, runtimeClass)
+	updateStringFromFlag(cmd, &cc.ContainerRuntimeSandbox, containerRuntimeSandbox)
+	updateStringSliceFromFlag(cmd, &cc.SandboxNamespaces, sandboxNamespaces)
+	updateStringFromFlag(cmd, &cc.OCIRuntime, ociRuntime)
 	updateStringFromFlag(cmd, &cc.NFSSharesRoot, nfsSharesRoot)
 	updateStringFromFlag(cmd, &cc.HostOnlyCIDR, hostOnlyCIDR)
 	updateStringFromFlag(cmd, &cc.HypervVirtualSwitch, hypervVirtualSwitch)
 	updateBoolFromFlag(cmd, &cc.HypervUseExternalSwitch, hypervUseExternalSwitch)
 	updateStringFromFlag(cmd, &cc.HypervExternalAdapter, hypervExternalAdapter)
+	updateBoolFromFlag(cmd, &cc.HypervDynamicMemory, hypervDynamicMemory)
+	if cmd.Flags().Changed(hypervMinMemory) {
+		cc.HypervMinMemory = getHypervMemorySize(hypervMinMemory)
+	}
+	if cmd.Flags().Changed(hypervMaxMemory) {
+		cc.HypervMaxMemory = getHypervMemorySize(hypervMaxMemory)
+	}
+	updateIntFromFlag(cmd, &cc.HypervMemoryBuffer, hypervMemoryBuffer)
+	updateBoolFromFlag(cmd, &cc.HypervNestedVirt, hypervNestedVirt)
 	updateStringFromFlag(cmd, &cc.KVMNetwork, kvmNetwork)
 	updateStringFromFlag(cmd, &cc.KVMQemuURI, kvmQemuURI)
 	updateBoolFromFlag(cmd, &cc.KVMGPU, kvmGPU)
@@ -793,9 +1149,17 @@ func updateExistingConfigFromFlags(cmd *cobra.Command, existing *config.ClusterC
 	updateStringFromFlag(cmd, &cc.SSHUser, sshSSHUser)
 	updateStringFromFlag(cmd, &cc.SSHKey, sshSSHKey)
 	updateIntFromFlag(cmd, &cc.SSHPort, sshSSHPort)
+	updateStringFromFlag(cmd, &cc.SSHProxy, sshProxy)
 	updateStringFromFlag(cmd, &cc.KubernetesConfig.Namespace, startNamespace)
 	updateStringFromFlag(cmd, &cc.KubernetesConfig.APIServerName, apiServerName)
 	updateStringSliceFromFlag(cmd, &cc.KubernetesConfig.APIServerNames, "apiserver-names")
+	updateStringFromFlag(cmd, &cc.KubernetesConfig.SPIFFETrustDomain, spiffeTrustDomain)
+	if cmd.Flags().Changed(kubernetesImagesDir) {
+		cc.KubernetesConfig.KubernetesImagesDir = localBuildDir(nil)
+	}
+	if cmd.Flags().Changed(kubeadmPatches) {
+		cc.KubernetesConfig.KubeadmPatches = kubeadmPatchesDir()
+	}
 	updateStringFromFlag(cmd, &cc.KubernetesConfig.DNSDomain, dnsDomain)
 	updateStringFromFlag(cmd, &cc.KubernetesConfig.FeatureGates, featureGates)
 	updateStringFromFlag(cmd, &cc.KubernetesConfig.ContainerRuntime, containerRuntime)
@@ -805,6 +1169,7 @@ func updateExistingConfigFromFlags(cmd *cobra.Command, existing *config.ClusterC
 	updateBoolFromFlag(cmd, &cc.KubernetesConfig.ShouldLoadCachedImages, cacheImages)
 	updateIntFromFlag(cmd, &cc.KubernetesConfig.NodePort, apiServerPort)
 	updateDurationFromFlag(cmd, &cc.CertExpiration, certExpiration)
+	updateBoolFromFlag(cmd, &cc.ImportHostCerts, importHostCerts)
 	updateBoolFromFlag(cmd, &cc.Mount, createMount)
 	updateStringFromFlag(cmd, &cc.MountString, mountString)
 	updateStringFromFlag(cmd, &cc.Mount9PVersion, mount9PVersion)
@@ -815,12 +1180,42 @@ func updateExistingConfigFromFlags(cmd *cobra.Command, existing *config.ClusterC
 	updateUint16FromFlag(cmd, &cc.MountPort, mountPortFlag)
 	updateStringFromFlag(cmd, &cc.MountType, mountTypeFlag)
 	updateStringFromFlag(cmd, &cc.MountUID, mountUID)
+	updateStringSliceFromFlag(cmd, &cc.MountSyncIgnore, mountSyncIgnore)
+	updateStringFromFlag(cmd, &cc.MountSyncConflict, mountSyncConflict)
+	updateBoolFromFlag(cmd, &cc.MountForwardEvents, mountForwardEvents)
 	updateStringFromFlag(cmd, &cc.BinaryMirror, binaryMirror)
 	updateBoolFromFlag(cmd, &cc.DisableOptimizations, disableOptimizations)
+	if cmd.Flags().Changed(propagateProxy) {
+		cc.DisableProxyPropagation = !viper.GetBool(propagateProxy)
+	}
 	updateStringFromFlag(cmd, &cc.CustomQemuFirmwarePath, qemuFirmwarePath)
 	updateStringFromFlag(cmd, &cc.SocketVMnetClientPath, socketVMnetClientPath)
 	updateStringFromFlag(cmd, &cc.SocketVMnetPath, socketVMnetPath)
 	updateDurationFromFlag(cmd, &cc.AutoPauseInterval, autoPauseInterval)
+	updateDurationFromFlag(cmd, &cc.IdleTimeout, idleTimeout)
+	updateStringFromFlag(cmd, &cc.IdleAction, idleAction)
+	updateStringFromFlag(cmd, &cc.StateRecovery, recoverState)
+	updateStringFromFlag(cmd, &cc.LoadBalancerPool, loadBalancerPool)
+	if cmd.Flags().Changed(portForward) {
+		cc.PortForwards = portForwardsFromFlag(cmd)
+	}
+	if cmd.Flags().Changed(persistentMount) {
+		cc.PersistentMounts = persistentMountsFromFlag(cmd)
+	}
+	if cmd.Flags().Changed(userData) {
+		cc.UserData = userDataFile()
+	}
+	if cmd.Flags().Changed(provision) {
+		cc.GuestFiles, cc.SystemdDropIns = provisionFromFlag()
+	}
+	if cmd.Flags().Changed(preStartHook) {
+		cc.PreStartHooks = hooksFromFlag(cmd, preStartHook)
+	}
+	if cmd.Flags().Changed(postStartHook) {
+		cc.PostStartHooks = hooksFromFlag(cmd, postStartHook)
+	}
+	updateStringSliceFromFlag(cmd, &cc.Zones, zones)
+	updateStringSliceFromFlag(cmd, &cc.Regions, regions)
 
 	if cmd.Flags().Changed(kubernetesVersion) {
 		kubeVer, err := getKubernetesVersion(existing)
@@ -840,6 +1235,13 @@ func updateExistingConfigFromFlags(cmd *cobra.Command, existing *config.ClusterC
 	if cmd.Flags().Changed(cniFlag) || cmd.Flags().Changed(enableDefaultCNI) {
 		cc.KubernetesConfig.CNI = getCNIConfig(cmd)
 	}
+	updateStringFromFlag(cmd, &cc.KubernetesConfig.CNIVersion, cniVersion)
+	if cmd.Flags().Changed(cniValues) {
+		cc.KubernetesConfig.CNIValues = absPaths(viper.GetStringSlice(cniValues))
+	}
+	if cmd.Flags().Changed(cniOverlay) {
+		cc.KubernetesConfig.CNIOverlays = absPaths(viper.GetStringSlice(cniOverlay))
+	}
 
 	if cmd.Flags().Changed(waitComponents) {
 		cc.VerifyComponents = interpretWaitFlag(*cmd)
@@ -952,7 +1354,7 @@ func interpretWaitFlag(cmd cobra.Command) map[string]bool {
 }
 
 func checkExtraDiskOptions(cmd *cobra.Command, driverName string) {
-	supportedDrivers := []string{driver.HyperKit, driver.KVM2, driver.QEMU2}
+	supportedDrivers := []string{driver.HyperKit, driver.KVM2, driver.QEMU2, driver.VZ, driver.Firecracker, driver.CloudHypervisor}
 
 	if cmd.Flags().Changed(extraDisks) {
 		supported := false
@@ -967,3 +1369,75 @@ func checkExtraDiskOptions(cmd *cobra.Command, driverName string) {
 		}
 	}
 }
+
+// loadBalancerPoolFromFlags returns the pool of --load-balancer-pool, which defaults to auto with --vip
+func loadBalancerPoolFromFlags() string {
+	if pool := viper.GetString(loadBalancerPool); pool != "" || viper.GetString(controlPlaneVIP) == "" {
+		return pool
+	}
+	return "auto"
+}
+
+// hooksFromFlag returns the hooks of the --pre-start-hook or --post-start-hook flag, which validateFlags already checked
+func hooksFromFlag(cmd *cobra.Command, flag string) []config.Hook {
+	specs, err := cmd.Flags().GetStringArray(flag)
+	if err != nil {
+		klog.Warningf("failed to get the %s flag: %v", flag, err)
+		return nil
+	}
+	var hs []config.Hook
+	for _, spec := range specs {
+		h, err := hooks.Parse(spec)
+		if err != nil {
+			klog.Warningf("skipping hook %q: %v", spec, err)
+			continue
+		}
+		hs = append(hs, h)
+	}
+	return hs
+}
+
+// portForwardsFromFlag returns the port forwards of the --port-forward flag, which validateFlags already checked
+func portForwardsFromFlag(cmd *cobra.Command) []config.PortForward {
+	specs, err := cmd.Flags().GetStringArray(portForward)
+	if err != nil {
+		klog.Warningf("failed to get the %s flag: %v", portForward, err)
+		return nil
+	}
+	var forwards []config.PortForward
+	for _, spec := range specs {
+		f, err := portforward.Parse(spec)
+		if err != nil {
+			klog.Warningf("skipping port forward %q: %v", spec, err)
+			continue
+		}
+		forwards = append(forwards, f)
+	}
+	return forwards
+}
+
+// persistentMountsFromFlag returns the mounts of the --persistent-mount flag, which validateFlags already checked, with absolute host directories
+func persistentMountsFromFlag(cmd *cobra.Command) []config.Mount {
+	specs, err := cmd.Flags().GetStringArray(persistentMount)
+	if err != nil {
+		klog.Warningf("failed to get the %s flag: %v", persistentMount, err)
+		return nil
+	}
+	var mounts []config.Mount
+	for _, spec := range specs {
+		if spec == "" {
+			continue
+		}
+		m, err := cluster.ParseMount(spec)
+		if err != nil {
+			klog.Warningf("skipping mount %q: %v", spec, err)
+			continue
+		}
+		if m.Source, err = filepath.Abs(m.Source); err != nil {
+			klog.Warningf("skipping mount %q: %v", spec, err)
+			continue
+		}
+		mounts = append(mounts, m)
+	}
+	return mounts
+}
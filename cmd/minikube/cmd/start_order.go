@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/startorder"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// startOrderClient fails fast, as holding and releasing workloads is best effort
+func startOrderClient(cname string) (kubernetes.Interface, error) {
+	cfg, err := kapi.ClientConfig(cname)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Timeout = 10 * time.Second
+	return kubernetes.NewForConfig(cfg)
+}
+
+// holdWorkloads holds the workloads with a start priority in namespaces (all if empty),
+// so that releaseWorkloads brings them back up in priority order
+func holdWorkloads(cname string, namespaces []string) {
+	c, err := startOrderClient(cname)
+	if err != nil {
+		klog.Warningf("unable to hold workloads with a start priority: %v", err)
+		return
+	}
+	held, err := startorder.Hold(context.Background(), c, namespaces)
+	if err != nil {
+		out.WarningT("Unable to hold the workloads with a start priority: {{.error}}", out.V{"error": err})
+	}
+	if len(held) > 0 {
+		out.Step(style.Stopping, "Holding {{.count}} workloads with a start priority", out.V{"count": len(held)})
+	}
+}
+
+// releaseWorkloads releases the held workloads, tier by tier in priority order
+func releaseWorkloads(cname string) {
+	c, err := startOrderClient(cname)
+	if err != nil {
+		klog.Warningf("unable to release workloads with a start priority: %v", err)
+		return
+	}
+	err = startorder.Release(context.Background(), c, startorder.DefaultTierTimeout, func(tier []startorder.Workload) {
+		var names []string
+		for _, w := range tier {
+			names = append(names, w.Namespace+"/"+w.Name)
+		}
+		out.Step(style.Launch, "Starting workloads of priority {{.priority}}: {{.names}}", out.V{"priority": tier[0].Priority, "names": strings.Join(names, ", ")})
+	})
+	if err != nil {
+		out.WarningT("Workloads with a start priority did not all get ready: {{.error}}", out.V{"error": err})
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/schedule"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// validateStartSchedule checks the --schedule flag, which is not bound to viper as 'minikube stop' has a flag of the same name
+func validateStartSchedule(cmd *cobra.Command, drvName string) {
+	if !cmd.Flags().Changed(startSchedule) {
+		return
+	}
+	expr, _ := cmd.Flags().GetString(startSchedule)
+	if _, err := schedule.ParseCron(expr); err != nil {
+		exit.Message(reason.Usage, "Sorry, the --schedule flag is not valid: {{.err}}", out.V{"err": err})
+	}
+	if driver.BareMetal(drvName) {
+		exit.Message(reason.Usage, "Scheduled starts are not supported by the none driver")
+	}
+}
+
+// scheduleStart installs the timer of the host starting the cluster on the schedule set with --schedule
+func scheduleStart(cmd *cobra.Command, cc *config.ClusterConfig) {
+	if !cmd.Flags().Changed(startSchedule) {
+		return
+	}
+	expr, _ := cmd.Flags().GetString(startSchedule)
+	c, err := schedule.ParseCron(expr)
+	if err != nil {
+		exit.Message(reason.Usage, "Sorry, the --schedule flag is not valid: {{.err}}", out.V{"err": err})
+	}
+	if err := schedule.InstallStart(cc.Name, c); err != nil {
+		exit.Error(reason.HostScheduledStart, "Unable to schedule the start of the cluster", err)
+	}
+	cc.ScheduledStart = c.String()
+	if err := config.SaveProfile(cc.Name, cc); err != nil {
+		exit.Error(reason.HostSaveProfile, "failed to save config", err)
+	}
+	out.Step(style.Waiting, "The cluster is started on the schedule {{.schedule}}, cancel it with: minikube start -p {{.name}} --cancel-scheduled", out.V{"schedule": cc.ScheduledStart, "name": cc.Name})
+}
+
+// cancelScheduledStart removes the timer of the host starting the cluster of the profile
+func cancelScheduledStart(profile string, existing *config.ClusterConfig) {
+	if err := schedule.RemoveStart(profile); err != nil {
+		exit.Error(reason.HostScheduledStart, "Unable to cancel the scheduled start of the cluster", err)
+	}
+	if existing != nil && existing.ScheduledStart != "" {
+		existing.ScheduledStart = ""
+		if err := config.SaveProfile(profile, existing); err != nil {
+			exit.Error(reason.HostSaveProfile, "failed to save config", err)
+		}
+	}
+	out.Step(style.Stopped, "Scheduled starts of {{.name}} cancelled", out.V{"name": profile})
+}
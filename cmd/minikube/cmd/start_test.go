@@ -18,6 +18,8 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -744,6 +746,132 @@ func TestValidatePorts(t *testing.T) {
 	}
 }
 
+func TestValidateRootlessPorts(t *testing.T) {
+	tests := []struct {
+		ports   []string
+		wantErr bool
+	}{
+		{[]string{"8080:80", "443"}, false},
+		{[]string{"127.0.0.1:8000-8001:80-81/tcp"}, false},
+		{[]string{"127.0.0.1::80"}, false},
+		{[]string{"80:80"}, true},
+		{[]string{"8080:80", "0.0.0.0:443:443"}, true},
+	}
+	for _, test := range tests {
+		t.Run(strings.Join(test.ports, ","), func(t *testing.T) {
+			err := validateRootlessPorts(test.ports, 1024)
+			if (err != nil) != test.wantErr {
+				t.Errorf("validateRootlessPorts(%v) = %v, want error: %t", test.ports, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRequestedControlPlanes(t *testing.T) {
+	tests := []struct {
+		ha            bool
+		controlPlanes int
+		nodes         int
+		wantCPs       int
+		wantNodes     int
+	}{
+		{false, 1, 1, 1, 1},
+		{false, 1, 3, 1, 3},
+		{true, 1, 1, 3, 3},
+		{true, 5, 2, 5, 5},
+		{false, 3, 4, 3, 4},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("ha=%t,control-planes=%d,nodes=%d", test.ha, test.controlPlanes, test.nodes), func(t *testing.T) {
+			viper.Set(ha, test.ha)
+			viper.Set(controlPlanes, test.controlPlanes)
+			viper.Set(nodes, test.nodes)
+			defer viper.Reset()
+			if got := requestedControlPlanes(); got != test.wantCPs {
+				t.Errorf("requestedControlPlanes() = %d, want %d", got, test.wantCPs)
+			}
+			if got := requestedNodes(); got != test.wantNodes {
+				t.Errorf("requestedNodes() = %d, want %d", got, test.wantNodes)
+			}
+		})
+	}
+}
+
+func TestValidateControlPlanes(t *testing.T) {
+	tests := []struct {
+		driver  string
+		cps     int
+		wantErr bool
+	}{
+		{driver.Docker, 3, false},
+		{driver.KVM2, 1, false},
+		{driver.None, 1, false},
+		{driver.Docker, 0, true},
+		{driver.None, 3, true},
+		{driver.SSH, 3, true},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s/%d", test.driver, test.cps), func(t *testing.T) {
+			err := validateControlPlanes(test.driver, test.cps)
+			if (err != nil) != test.wantErr {
+				t.Errorf("validateControlPlanes(%q, %d) = %v, want error: %t", test.driver, test.cps, err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestHAVirtualIP(t *testing.T) {
+	vip, err := haVirtualIP("203.0.113.5")
+	if err != nil {
+		t.Fatalf("haVirtualIP: %v", err)
+	}
+	if vip != "203.0.113.254" {
+		t.Errorf("haVirtualIP(%q) = %q, want %q", "203.0.113.5", vip, "203.0.113.254")
+	}
+}
+
+func TestVirtualIP(t *testing.T) {
+	tests := []struct {
+		requested string
+		want      string
+		wantErr   bool
+	}{
+		{"", "203.0.113.254", false},
+		{"auto", "203.0.113.254", false},
+		{"203.0.113.100", "203.0.113.100", false},
+		{"203.0.113.5", "", true},
+		{"203.0.113.255", "", true},
+		{"198.51.100.100", "", true},
+	}
+	for _, test := range tests {
+		got, err := virtualIP(test.requested, "203.0.113.5")
+		if (err != nil) != test.wantErr || got != test.want {
+			t.Errorf("virtualIP(%q) = %q, %v, want %q, error: %t", test.requested, got, err, test.want, test.wantErr)
+		}
+	}
+}
+
+func TestValidateVIP(t *testing.T) {
+	tests := []struct {
+		vip, driver string
+		valid       bool
+	}{
+		{"", driver.None, true},
+		{"auto", driver.KVM2, true},
+		{"192.168.39.100", driver.KVM2, true},
+		{"auto", driver.None, false},
+		{"auto", driver.SSH, false},
+		{"fd00::100", driver.KVM2, false},
+		{"vip", driver.KVM2, false},
+	}
+	for _, tc := range tests {
+		err := validateVIP(tc.vip, tc.driver)
+		if (err == nil) != tc.valid {
+			t.Errorf("validateVIP(%q, %q) = %v, want valid = %t", tc.vip, tc.driver, err, tc.valid)
+		}
+	}
+}
+
 func TestValidateSubnet(t *testing.T) {
 	type subnetTest struct {
 		subnet   string
@@ -785,6 +913,7 @@ func TestValidateStaticIP(t *testing.T) {
 	tests := []struct {
 		staticIP string
 		drvName  string
+		network  string
 		errorMsg string
 	}{
 		{
@@ -827,10 +956,27 @@ func TestValidateStaticIP(t *testing.T) {
 			drvName:  "docker",
 			errorMsg: "",
 		},
+		{
+			staticIP: "8.8.8.8",
+			drvName:  "kvm2",
+			errorMsg: "static IP must be private",
+		},
+		{
+			staticIP: "192.168.105.50",
+			drvName:  "qemu2",
+			network:  "socket_vmnet",
+			errorMsg: "",
+		},
+		{
+			staticIP: "8.8.8.8",
+			drvName:  "qemu2",
+			network:  "builtin",
+			errorMsg: "",
+		},
 	}
 	for _, tt := range tests {
 		gotError := ""
-		got := validateStaticIP(tt.staticIP, tt.drvName, "")
+		got := validateStaticIP(tt.staticIP, tt.drvName, tt.network, "")
 		if got != nil {
 			gotError = got.Error()
 		}
@@ -840,6 +986,111 @@ func TestValidateStaticIP(t *testing.T) {
 	}
 }
 
+func TestValidateExtraNetworks(t *testing.T) {
+	tests := []struct {
+		specs    []string
+		drvName  string
+		network  string
+		errorMsg string
+	}{
+		{
+			specs:   []string{"storage=virbr2"},
+			drvName: "kvm2",
+		},
+		{
+			specs:   []string{"mgmt", "storage=storage-net"},
+			drvName: "docker",
+		},
+		{
+			specs:    []string{"storage=virbr2"},
+			drvName:  "qemu2",
+			errorMsg: "--extra-network is only implemented on Docker, Podman and KVM drivers",
+		},
+		{
+			specs:    []string{"mgmt=mk-net"},
+			drvName:  "kvm2",
+			network:  "mk-net",
+			errorMsg: `extra network "mgmt" is the network of the cluster, mk-net`,
+		},
+		{
+			specs:    []string{"storage=virbr2", "storage=virbr3"},
+			drvName:  "kvm2",
+			errorMsg: `extra network "storage" is defined more than once`,
+		},
+	}
+	for _, tt := range tests {
+		gotError := ""
+		got := validateExtraNetworks(tt.specs, tt.drvName, tt.network)
+		if got != nil {
+			gotError = got.Error()
+		}
+		if gotError != tt.errorMsg {
+			t.Errorf("validateExtraNetworks(%v, %s): got %v, expected %v", tt.specs, tt.drvName, got, tt.errorMsg)
+		}
+	}
+}
+
+func TestValidateVirtiofsMount(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		drvName  string
+		goos     string
+		mount    string
+		errorMsg string
+	}{
+		{
+			drvName: "kvm2",
+			goos:    "linux",
+			mount:   dir + ":/src",
+		},
+		{
+			drvName: "qemu2",
+			goos:    "linux",
+			mount:   dir + ":/src",
+		},
+		{
+			drvName:  "qemu2",
+			goos:     "darwin",
+			mount:    dir + ":/src",
+			errorMsg: "the virtiofs mount type is only implemented on the KVM, vz and QEMU (on Linux) drivers, not on qemu2",
+		},
+		{
+			drvName:  "docker",
+			goos:     "linux",
+			mount:    dir + ":/src",
+			errorMsg: "the virtiofs mount type is only implemented on the KVM, vz and QEMU (on Linux) drivers, not on docker",
+		},
+		{
+			drvName:  "vz",
+			goos:     "darwin",
+			mount:    "src:/src",
+			errorMsg: "the source directory src must be an absolute path",
+		},
+		{
+			drvName:  "vz",
+			goos:     "darwin",
+			mount:    dir + "/missing:/src",
+			errorMsg: "the source directory " + dir + "/missing is not a directory",
+		},
+		{
+			drvName:  "kvm2",
+			goos:     "linux",
+			mount:    dir + ":src",
+			errorMsg: "the target directory src must be an absolute path",
+		},
+	}
+	for _, tt := range tests {
+		gotError := ""
+		got := validateVirtiofsMount(tt.drvName, tt.goos, tt.mount)
+		if got != nil {
+			gotError = got.Error()
+		}
+		if gotError != tt.errorMsg {
+			t.Errorf("validateVirtiofsMount(%s, %s, %s): got %v, expected %v", tt.drvName, tt.goos, tt.mount, got, tt.errorMsg)
+		}
+	}
+}
+
 func TestImageMatchesBinaryVersion(t *testing.T) {
 	tests := []struct {
 		imageVersion  string
@@ -872,8 +1123,11 @@ func TestValidateGPUs(t *testing.T) {
 		{"nvidia", "docker", "docker", ""},
 		{"all", "docker", "", ""},
 		{"nvidia", "docker", "", ""},
-		{"all", "kvm", "docker", "The gpus flag can only be used with the docker driver and docker container-runtime"},
-		{"nvidia", "docker", "containerd", "The gpus flag can only be used with the docker driver and docker container-runtime"},
+		{"all", "docker", "containerd", ""},
+		{"nvidia", "docker", "crio", ""},
+		{"all", "docker", "cri-o", ""},
+		{"all", "hyperv", "docker", "The gpus flag can only be used with the docker or kvm2 driver"},
+		{"nvidia", "docker", "gvisor", "The gpus flag can only be used with the docker, containerd or crio container-runtime"},
 		{"cat", "docker", "docker", `The gpus flag must be passed a value of "nvidia" or "all"`},
 	}
 
@@ -888,3 +1142,323 @@ func TestValidateGPUs(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateHypervMemory(t *testing.T) {
+	tests := []struct {
+		dynamic, nested    bool
+		mem, min, max, buf int
+		valid              bool
+	}{
+		{false, false, 6000, 0, 0, 0, true},
+		{false, true, 6000, 0, 0, 0, true},
+		{true, false, 6000, 2048, 16384, 20, true},
+		{true, false, 0, 2048, 0, 0, true},
+		{false, false, 6000, 2048, 0, 0, false},
+		{true, true, 6000, 0, 0, 0, false},
+		{true, false, 6000, 0, 0, 4, false},
+		{true, false, 6000, 8192, 0, 0, false},
+		{true, false, 6000, 0, 4096, 0, false},
+		{true, false, 0, 8192, 4096, 0, false},
+	}
+	for _, tc := range tests {
+		err := validateHypervMemory(tc.dynamic, tc.nested, tc.mem, tc.min, tc.max, tc.buf)
+		if (err == nil) != tc.valid {
+			t.Errorf("validateHypervMemory(%+v) = %v, want valid = %t", tc, err, tc.valid)
+		}
+	}
+}
+
+func TestValidateKVMGPUs(t *testing.T) {
+	defer func(p string) { iommuGroupsPath = p }(iommuGroupsPath)
+	iommuGroupsPath = t.TempDir()
+	if err := validateGPUs("all", "kvm2", "containerd"); err == nil {
+		t.Errorf("validateGPUs succeeded on a host without IOMMU groups")
+	}
+
+	if err := os.Mkdir(filepath.Join(iommuGroupsPath, "0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		gpus  string
+		valid bool
+	}{
+		{"all", true},
+		{"nvidia", true},
+		{"0000:01:00.0", true},
+		{"0000:01:00.0,0000:01:00.1", true},
+		{"01:00.0", false},
+		{"0000:01:00.0,", false},
+		{"cat", false},
+	}
+	for _, tc := range tests {
+		err := validateGPUs(tc.gpus, "kvm2", "containerd")
+		if (err == nil) != tc.valid {
+			t.Errorf("validateGPUs(%s, kvm2) = %v, want valid = %t", tc.gpus, err, tc.valid)
+		}
+	}
+}
+
+func TestValidateTopology(t *testing.T) {
+	tests := []struct {
+		values []string
+		valid  bool
+	}{
+		{nil, true},
+		{[]string{"zone-a", "zone-b"}, true},
+		{[]string{"zone-a", "m03=zone-c"}, true},
+		{[]string{"=zone-c"}, false},
+		{[]string{"m02="}, false},
+		{[]string{"zone a"}, false},
+		{[]string{""}, false},
+	}
+	for _, tc := range tests {
+		err := validateTopology(tc.values)
+		if (err == nil) != tc.valid {
+			t.Errorf("validateTopology(%v) = %v, want valid = %t", tc.values, err, tc.valid)
+		}
+	}
+}
+
+func TestValidateIPFamily(t *testing.T) {
+	tests := []struct {
+		family, driver, version, cni string
+		valid                        bool
+	}{
+		{"ipv4", "hyperkit", "v1.20.0", "calico", true},
+		{"ipv6", "docker", "v1.30.0", "", true},
+		{"dual", "kvm2", "v1.30.0", "kindnet", true},
+		{"dual", "podman", "v1.30.0", "bridge", true},
+		{"ipv5", "docker", "v1.30.0", "", false},
+		{"ipv6", "virtualbox", "v1.30.0", "", false},
+		{"dual", "docker", "v1.22.0", "", false},
+		{"ipv6", "docker", "v1.30.0", "calico", false},
+	}
+	for _, tc := range tests {
+		err := validateIPFamily(tc.family, tc.driver, tc.version, tc.cni)
+		if (err == nil) != tc.valid {
+			t.Errorf("validateIPFamily(%q, %q, %q, %q) = %v, want valid = %t", tc.family, tc.driver, tc.version, tc.cni, err, tc.valid)
+		}
+	}
+}
+
+func TestValidateRuntimeClass(t *testing.T) {
+	tests := []struct {
+		rc, driver, runtime string
+		valid               bool
+	}{
+		{"kata", "kvm2", "containerd", true},
+		{"kata", "ssh", "crio", true},
+		{"gvisor", "kvm2", "containerd", false},
+		{"kata", "kvm2", "docker", false},
+		{"kata", "hyperkit", "containerd", false},
+		{"kata", "virtualbox", "crio", false},
+	}
+	for _, tc := range tests {
+		err := validateRuntimeClass(tc.rc, tc.driver, tc.runtime)
+		if (err == nil) != tc.valid {
+			t.Errorf("validateRuntimeClass(%q, %q, %q) = %v, want valid = %t", tc.rc, tc.driver, tc.runtime, err, tc.valid)
+		}
+	}
+}
+
+func TestValidateSandbox(t *testing.T) {
+	tests := []struct {
+		sandbox    string
+		namespaces []string
+		runtime    string
+		version    string
+		valid      bool
+	}{
+		{"", nil, "docker", "v1.28.4", true},
+		{"gvisor", nil, "containerd", "v1.28.4", true},
+		{"gvisor", []string{"untrusted"}, "crio", "v1.32.0", true},
+		{"gvisor", []string{"untrusted"}, "containerd", "stable", true},
+		{"", []string{"untrusted"}, "containerd", "v1.32.0", false},
+		{"kata", nil, "containerd", "v1.28.4", false},
+		{"gvisor", nil, "docker", "v1.28.4", false},
+		{"gvisor", []string{"untrusted"}, "containerd", "v1.31.0", false},
+	}
+	for _, tc := range tests {
+		err := validateSandbox(tc.sandbox, tc.namespaces, tc.runtime, tc.version)
+		if (err == nil) != tc.valid {
+			t.Errorf("validateSandbox(%q, %v, %q, %q) = %v, want valid = %t", tc.sandbox, tc.namespaces, tc.runtime, tc.version, err, tc.valid)
+		}
+	}
+}
+
+func TestValidateOCIRuntime(t *testing.T) {
+	tests := []struct {
+		ociRuntime string
+		runtime    string
+		valid      bool
+	}{
+		{"", "docker", true},
+		{"runc", "containerd", true},
+		{"crun", "containerd", true},
+		{"crun", "crio", true},
+		{"crun", "docker", false},
+		{"youki", "containerd", false},
+	}
+	for _, tc := range tests {
+		err := validateOCIRuntime(tc.ociRuntime, tc.runtime)
+		if (err == nil) != tc.valid {
+			t.Errorf("validateOCIRuntime(%q, %q) = %v, want valid = %t", tc.ociRuntime, tc.runtime, err, tc.valid)
+		}
+	}
+}
+
+func TestValidatePreloadFrom(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "preload.tar.lz4")
+	if err := os.WriteFile(file, []byte("preload"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		from  string
+		want  string
+		valid bool
+	}{
+		{file, file, true},
+		{"https://example.com/preload.tar.lz4", "https://example.com/preload.tar.lz4", true},
+		{"http://example.com/preload.tar.lz4?checksum=sha256:abc", "http://example.com/preload.tar.lz4?checksum=sha256:abc", true},
+		{"gs://bucket/preload.tar.lz4", "", false},
+		{"https:///preload.tar.lz4", "", false},
+		{filepath.Dir(file), "", false},
+		{filepath.Join(filepath.Dir(file), "missing.tar.lz4"), "", false},
+	}
+	for _, tc := range tests {
+		got, err := validatePreloadFrom(tc.from)
+		if (err == nil) != tc.valid || got != tc.want {
+			t.Errorf("validatePreloadFrom(%q) = %q, %v, want %q, valid = %t", tc.from, got, err, tc.want, tc.valid)
+		}
+	}
+}
+
+func TestValidateLoadBalancerPool(t *testing.T) {
+	tests := []struct {
+		pool, driver string
+		valid        bool
+	}{
+		{"", "none", true},
+		{"auto", "kvm2", true},
+		{"192.168.39.200-192.168.39.254", "kvm2", true},
+		{"192.168.39.200-192.168.39.200", "virtualbox", true},
+		{"auto", "none", false},
+		{"192.168.39.200", "kvm2", false},
+		{"192.168.39.254-192.168.39.200", "kvm2", false},
+		{"fd00::1-fd00::2", "kvm2", false},
+	}
+	for _, tc := range tests {
+		err := validateLoadBalancerPool(tc.pool, tc.driver)
+		if (err == nil) != tc.valid {
+			t.Errorf("validateLoadBalancerPool(%q, %q) = %v, want valid = %t", tc.pool, tc.driver, err, tc.valid)
+		}
+	}
+}
+
+func TestValidatePortForwards(t *testing.T) {
+	tests := []struct {
+		specs []string
+		valid bool
+	}{
+		{nil, true},
+		{[]string{"svc/web:8080:80", "monitoring/pod/app=grafana:3000:3000"}, true},
+		{[]string{"svc/web:8080"}, false},
+		{[]string{"svc/web:8080:80", "svc/api:8080:80"}, false},
+	}
+	for _, tc := range tests {
+		err := validatePortForwards(tc.specs)
+		if (err == nil) != tc.valid {
+			t.Errorf("validatePortForwards(%q) = %v, want valid = %t", tc.specs, err, tc.valid)
+		}
+	}
+}
+
+func TestValidatePersistentMounts(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		specs  []string
+		driver string
+		valid  bool
+	}{
+		{nil, driver.KVM2, true},
+		{[]string{""}, driver.None, true},
+		{[]string{dir + ":/src", dir + ":/data,type=sync,ro"}, driver.KVM2, true},
+		{[]string{dir + ":/src"}, driver.None, false},
+		{[]string{filepath.Join(dir, "missing") + ":/src"}, driver.KVM2, false},
+		{[]string{dir + ":/src", dir + ":/src/"}, driver.KVM2, false},
+		{[]string{dir + ":/src,type=nfs"}, driver.KVM2, false},
+	}
+	for _, tc := range tests {
+		err := validatePersistentMounts(tc.specs, tc.driver)
+		if (err == nil) != tc.valid {
+			t.Errorf("validatePersistentMounts(%q, %s) = %v, want valid = %t", tc.specs, tc.driver, err, tc.valid)
+		}
+	}
+}
+
+func TestValidateCNICustomization(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "overlay.yaml")
+	if err := os.WriteFile(file, []byte("kind: ConfigMap\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		cni      string
+		version  string
+		values   []string
+		overlays []string
+		valid    bool
+	}{
+		{"cilium", "1.15.1", nil, []string{file}, true},
+		{"helm:cilium/cilium", "1.15.1", []string{file}, []string{file}, true},
+		{"/tmp/cni.yaml", "", nil, []string{file}, true},
+		{"", "1.15.1", nil, nil, true},
+		{"cilium", "", nil, []string{"/does/not/exist"}, false},
+		{"kindnet", "1.15.1", nil, nil, false},
+		{"bridge", "", nil, []string{file}, false},
+		{"/tmp/cni.yaml", "1.15.1", nil, nil, false},
+		{"calico", "", []string{file}, nil, false},
+	}
+	for _, tc := range tests {
+		err := validateCNICustomization(tc.cni, tc.version, tc.values, tc.overlays)
+		if (err == nil) != tc.valid {
+			t.Errorf("validateCNICustomization(%q, %q, %q, %q) = %v, want valid = %t", tc.cni, tc.version, tc.values, tc.overlays, err, tc.valid)
+		}
+	}
+}
+
+func TestUpgradeOrder(t *testing.T) {
+	cc := cfg.ClusterConfig{
+		Name: "minikube",
+		KubernetesConfig: cfg.KubernetesConfig{
+			KubernetesVersion: "v1.27.0",
+		},
+		Nodes: []cfg.Node{
+			{Name: "", ControlPlane: true, Worker: true},
+			{Name: "m02", Worker: true},
+			{Name: "m03", ControlPlane: true, Worker: true},
+			{Name: "m04", Worker: true},
+		},
+	}
+	var names []string
+	for _, n := range upgradeOrder(cc) {
+		names = append(names, n.Name)
+	}
+	if strings.Join(names, ",") != "m03,m02,m04" {
+		t.Errorf("upgradeOrder() = %v, want the control plane m03 before the workers m02 and m04", names)
+	}
+
+	viper.Set(upgradeStrategy, upgradeRolling)
+	defer viper.Set(upgradeStrategy, nil)
+	upgraded := cc
+	upgraded.KubernetesConfig.KubernetesVersion = "v1.28.4"
+	if !rollingUpgrade(&cc, &upgraded) {
+		t.Errorf("rollingUpgrade() = false for a new version of a multi-node cluster")
+	}
+	if rollingUpgrade(&cc, &cc) {
+		t.Errorf("rollingUpgrade() = true for the same version")
+	}
+	viper.Set(upgradeStrategy, upgradeAllAtOnce)
+	if rollingUpgrade(&cc, &upgraded) {
+		t.Errorf("rollingUpgrade() = true with the %s strategy", upgradeAllAtOnce)
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/node"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// How the nodes of a multi-node cluster are upgraded to a new Kubernetes version
+const (
+	// upgradeRolling upgrades the control planes first, then drains, upgrades and uncordons the workers one at a time
+	upgradeRolling = "rolling"
+	// upgradeAllAtOnce upgrades all the nodes without draining them
+	upgradeAllAtOnce = "all-at-once"
+)
+
+// upgradeStrategies are the values of --upgrade-strategy
+var upgradeStrategies = []string{upgradeRolling, upgradeAllAtOnce}
+
+// upgradeDrainOptions drain the nodes being upgraded, the pods not managed by a controller staying on them
+var upgradeDrainOptions = node.DrainOptions{GracePeriod: -1, Timeout: 5 * time.Minute}
+
+// rollingUpgrade returns whether starting the existing cluster with cc is a rolling upgrade of its Kubernetes version
+func rollingUpgrade(existing *config.ClusterConfig, cc *config.ClusterConfig) bool {
+	if existing == nil || len(existing.Nodes) < 2 || viper.GetString(upgradeStrategy) != upgradeRolling {
+		return false
+	}
+	from, to := existing.KubernetesConfig.KubernetesVersion, cc.KubernetesConfig.KubernetesVersion
+	return from != to && from != constants.NoKubernetesVersion && to != constants.NoKubernetesVersion
+}
+
+// upgradeOrder returns the nodes of cc upgraded after the primary control plane: the other control planes, then the workers
+func upgradeOrder(cc config.ClusterConfig) []config.Node {
+	var cps, workers []config.Node
+	for _, n := range cc.Nodes {
+		switch {
+		case config.IsPrimaryControlPlane(cc, n):
+		case n.ControlPlane:
+			cps = append(cps, n)
+		default:
+			workers = append(workers, n)
+		}
+	}
+	return append(cps, workers...)
+}
+
+// drainForUpgrade drains the node n of the running cluster existing before it is upgraded, and returns whether it was drained.
+// A node which can not be drained is upgraded anyway, with its pods.
+func drainForUpgrade(existing config.ClusterConfig, n config.Node) bool {
+	api, err := machine.NewAPIClient()
+	if err != nil {
+		klog.Warningf("libmachine: %v", err)
+		return false
+	}
+	defer api.Close()
+	machineName := config.MachineName(existing, n)
+	if st, err := machine.Status(api, machineName); err != nil || st != state.Running.String() {
+		klog.Infof("not draining %s, which is not running", machineName)
+		return false
+	}
+	out.Step(style.Waiting, "Draining node {{.name}} ...", out.V{"name": machineName})
+	if err := node.Drain(existing, n.Name, upgradeDrainOptions); err != nil {
+		out.WarningT("Unable to drain {{.name}}, upgrading it with its pods: {{.error}}", out.V{"name": machineName, "error": err})
+	}
+	return true
+}
+
+// uncordonAfterUpgrade makes a node drained by drainForUpgrade schedulable again
+func uncordonAfterUpgrade(cc config.ClusterConfig, n config.Node) {
+	if err := node.Uncordon(cc, n.Name); err != nil {
+		out.WarningT("Unable to uncordon {{.name}}, make it schedulable again with 'kubectl uncordon {{.name}}': {{.error}}", out.V{"name": config.MachineName(cc, n), "error": err})
+	}
+}
+
+// upgradeNodes upgrades the nodes of existing but its primary control plane to the Kubernetes version of cc one at a time,
+// draining each node before its upgrade and uncordoning it afterwards
+func upgradeNodes(existing *config.ClusterConfig, cc *config.ClusterConfig) error {
+	order := upgradeOrder(*existing)
+	for i, n := range order {
+		if config.IsWindows(n) {
+			if err := node.StartWindows(*cc, n); err != nil {
+				return err
+			}
+			continue
+		}
+		// the node of cc holds the new Kubernetes version
+		upgraded := n
+		for _, cn := range cc.Nodes {
+			if cn.Name == n.Name {
+				upgraded = cn
+			}
+		}
+		out.Step(style.Waiting, "Upgrading node {{.name}} ({{.index}}/{{.total}}) to Kubernetes {{.version}} ...",
+			out.V{"name": config.MachineName(*cc, n), "index": i + 2, "total": len(order) + 1, "version": cc.KubernetesConfig.KubernetesVersion})
+		drained := drainForUpgrade(*cc, n)
+		if err := node.Add(cc, upgraded, viper.GetBool(deleteOnFailure)); err != nil {
+			return err
+		}
+		if drained {
+			uncordonAfterUpgrade(*cc, upgraded)
+		}
+	}
+	return nil
+}
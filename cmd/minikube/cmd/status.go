@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -33,7 +34,10 @@ import (
 	"github.com/docker/machine/libmachine/state"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/kapi"
 	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil/kverify"
 	"k8s.io/minikube/pkg/minikube/cluster"
 	"k8s.io/minikube/pkg/minikube/config"
@@ -326,6 +330,10 @@ func nodeStatus(api libmachine.API, cc config.ClusterConfig, n config.Node) (*St
 		Worker:     !controlPlane,
 	}
 
+	if config.IsWindows(n) {
+		return windowsNodeStatus(cc, n, st)
+	}
+
 	hs, err := machine.Status(api, name)
 	klog.Infof("%s host status = %q (err=%v)", name, hs, err)
 	if err != nil {
@@ -410,7 +418,8 @@ func nodeStatus(api libmachine.API, cc config.ClusterConfig, n config.Node) (*St
 		klog.Errorf("forwarded endpoint: %v", err)
 		st.Kubeconfig = Misconfigured
 	} else {
-		err := kubeconfig.VerifyEndpoint(cc.Name, hostname, port)
+		khostname, kport := kubeconfigEndpoint(cc, hostname, port)
+		err := kubeconfig.VerifyEndpoint(cc.Name, khostname, kport)
 		if err != nil && st.Host != state.Starting.String() {
 			klog.Errorf("kubeconfig endpoint: %v", err)
 			st.Kubeconfig = Misconfigured
@@ -430,6 +439,47 @@ func nodeStatus(api libmachine.API, cc config.ClusterConfig, n config.Node) (*St
 	return st, nil
 }
 
+// windowsNodeStatus returns the status of an experimental Windows node: its VM is not managed by libmachine,
+// and its kubelet is running when the node is ready
+func windowsNodeStatus(cc config.ClusterConfig, n config.Node, st *Status) (*Status, error) {
+	vm, err := node.WindowsVM(cc)
+	if err != nil {
+		return st, err
+	}
+	hs, err := vm.State(st.Name)
+	klog.Infof("%s Windows VM status = %q (err=%v)", st.Name, hs, err)
+	if err != nil {
+		return st, errors.Wrap(err, "host")
+	}
+	if hs == state.None {
+		return st, nil
+	}
+	st.Host = hs.String()
+	st.APIServer = Irrelevant
+	st.Kubeconfig = Irrelevant
+	if hs != state.Running {
+		st.Kubelet = st.Host
+		return st, nil
+	}
+
+	st.Kubelet = state.Stopped.String()
+	client, err := kapi.Client(cc.Name)
+	if err != nil {
+		return st, err
+	}
+	kn, err := client.CoreV1().Nodes().Get(context.Background(), st.Name, meta.GetOptions{})
+	if err != nil {
+		klog.Warningf("unable to get Windows node %q: %v", st.Name, err)
+		return st, nil
+	}
+	for _, c := range kn.Status.Conditions {
+		if c.Type == core.NodeReady && c.Status == core.ConditionTrue {
+			st.Kubelet = state.Running.String()
+		}
+	}
+	return st, nil
+}
+
 func init() {
 	statusCmd.Flags().StringVarP(&statusFormat, "format", "f", defaultStatusFormat,
 		`Go template format string for the status output.  The format for Go templates can be found here: https://pkg.go.dev/text/template
@@ -23,16 +23,19 @@ import (
 
 	"github.com/docker/machine/libmachine"
 	"github.com/docker/machine/libmachine/mcnerror"
+	"github.com/docker/machine/libmachine/state"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/exit"
 	"k8s.io/minikube/pkg/minikube/kubeconfig"
 	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/machine"
 	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/node"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/out/register"
 	"k8s.io/minikube/pkg/minikube/reason"
@@ -130,13 +133,37 @@ func stopProfile(profile string) int {
 	api, cc := mustload.Partial(profile)
 	defer api.Close()
 
+	killPersistentMountProcesses(profile)
 	if err := killMountProcess(); err != nil {
 		out.WarningT("Unable to kill mount process: {{.error}}", out.V{"error": err})
 	}
+	if err := killPortForwardProcess(profile); err != nil {
+		out.WarningT("Unable to kill port-forward process: {{.error}}", out.V{"error": err})
+	}
+	if err := killIdleProxyProcess(profile); err != nil {
+		out.WarningT("Unable to kill idle-proxy process: {{.error}}", out.V{"error": err})
+	}
+
+	cleanupHostRoutes(profile)
+
+	if len(cc.Nodes) > 0 && cc.KubernetesConfig.KubernetesVersion != constants.NoKubernetesVersion {
+		if st, err := machine.Status(api, config.MachineName(*cc, cc.Nodes[0])); err == nil && st == state.Running.String() {
+			holdWorkloads(profile, nil)
+		}
+	}
 
 	for _, n := range cc.Nodes {
 		machineName := config.MachineName(*cc, n)
 
+		if config.IsWindows(n) {
+			if exists, err := node.StopWindows(*cc, n); err != nil {
+				out.WarningT("Unable to stop the Windows VM {{.name}}: {{.error}}", out.V{"name": machineName, "error": err})
+			} else if exists {
+				stoppedNodes++
+			}
+			continue
+		}
+
 		nonexistent := stop(api, machineName)
 		if !nonexistent {
 			stoppedNodes++
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"os"
+	"os/user"
+	"runtime"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	systemHome  string
+	systemGroup string
+)
+
+// systemInstallCmd represents the system-install command
+var systemInstallCmd = &cobra.Command{
+	Use:   "system-install",
+	Short: "Set up minikube to be shared by the users of this host",
+	Long: `Set up a system-wide install of minikube, so that the developers of a shared host can each run their own clusters safely.
+
+In a system-wide install:
+ * the profiles of each user live in their own directory, only readable by them
+ * downloaded ISOs, images and binaries are kept in a single cache, which root fills and all users read
+ * profile names are prefixed with the user name, so the containers, VMs and networks of different users do not collide
+ * 'minikube delete --all' only deletes the clusters of the current user
+ * every command is recorded in an audit log per user, which the other users can read but not write
+
+Only the members of --group can use the install. This command must be run as root.`,
+	Example: "sudo minikube system-install --group=minikube",
+	Run: func(cmd *cobra.Command, args []string) {
+		if runtime.GOOS != "linux" {
+			exit.Message(reason.Unimplemented, "System-wide installs are only supported on Linux")
+		}
+		if os.Geteuid() != 0 {
+			exit.Message(reason.Usage, "minikube system-install must be run as root, try: sudo minikube system-install")
+		}
+		g, err := user.LookupGroup(systemGroup)
+		if err != nil {
+			exit.Message(reason.Usage, "The group {{.group}} does not exist, create it with: sudo groupadd {{.group}}", out.V{"group": systemGroup})
+		}
+		gid, err := strconv.Atoi(g.Gid)
+		if err != nil {
+			exit.Error(reason.HostSystemInstall, "Invalid group id", err)
+		}
+
+		cfg := localpath.SystemConfig{Home: systemHome, Group: systemGroup}
+		if err := localpath.SetupSystemHome(cfg, gid); err != nil {
+			exit.Error(reason.HostSystemInstall, "Unable to set up the system-wide directory", err)
+		}
+		if err := localpath.WriteSystemConfig(localpath.SystemConfigFile, cfg); err != nil {
+			exit.Error(reason.HostSystemInstall, "Unable to write the system-wide config", err)
+		}
+
+		out.Step(style.Ready, "minikube is now installed system-wide in {{.home}}", out.V{"home": systemHome})
+		out.Styled(style.Tip, "Add users to the {{.group}} group with: sudo usermod -aG {{.group}} USER", out.V{"group": systemGroup})
+		out.Styled(style.Tip, "Fill the shared cache with: sudo minikube start --download-only")
+	},
+}
+
+func init() {
+	systemInstallCmd.Flags().StringVar(&systemHome, "home", "/var/lib/minikube", "Directory holding the shared cache and the directories of the users")
+	systemInstallCmd.Flags().StringVar(&systemGroup, "group", "minikube", "Group of the users allowed to use minikube")
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/pressure"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+const (
+	throttlePause     = "pause"
+	throttleScaleDown = "scale-down"
+)
+
+var (
+	throttleMemory     float64
+	throttleCPU        float64
+	throttleMargin     float64
+	throttleFor        time.Duration
+	throttleInterval   time.Duration
+	throttleMode       string
+	throttleNamespaces []string
+)
+
+var throttleCmd = &cobra.Command{
+	Use:   "throttle",
+	Short: "Throttles the cluster while the host is under memory or CPU pressure",
+	Long: `Watches the memory and CPU usage of the host, and throttles the cluster while the usage stays above a threshold: pauses the cluster,
+or scales down the Deployments and StatefulSets of some namespaces. The cluster is resumed once the usage stays below the thresholds, minus a margin.
+Runs until interrupted, and resumes the cluster when interrupted.`,
+	Example: `minikube throttle --memory-threshold 85 --cpu-threshold 90
+minikube throttle --mode scale-down --namespaces dev,monitoring`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateThrottleFlags(); err != nil {
+			exit.Message(reason.Usage, "{{.error}}", out.V{"error": err})
+		}
+		cname := ClusterFlagValue()
+		co := mustload.Running(cname)
+
+		ctrlC := make(chan os.Signal, 1)
+		signal.Notify(ctrlC, os.Interrupt, syscall.SIGTERM)
+
+		m := pressure.NewMonitor(pressure.Thresholds{Memory: throttleMemory, CPU: throttleCPU, Margin: throttleMargin, For: throttleFor})
+		out.Step(style.Running, "Throttling {{.profile}} while the host uses over {{.memory}}% of its memory or {{.cpu}}% of its CPU for {{.for}}. Press Ctrl+C to stop.",
+			out.V{"profile": cname, "memory": throttleMemory, "cpu": throttleCPU, "for": throttleFor})
+		ticker := time.NewTicker(throttleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctrlC:
+				if m.Throttled() {
+					out.Step(style.Unpause, "Resuming {{.profile}} before exiting ...", out.V{"profile": cname})
+					if err := throttleCluster(&co, false); err != nil {
+						exit.Error(reason.GuestUnpause, "Failed to resume the cluster", err)
+					}
+				}
+				return
+			case <-ticker.C:
+			}
+			s, err := pressure.Read()
+			if err != nil {
+				klog.Warningf("reading the usage of the host: %v", err)
+				continue
+			}
+			klog.Infof("host usage: memory %.0f%%, CPU %.0f%%", s.Memory, s.CPU)
+			usage := out.V{"profile": cname, "memory": fmt.Sprintf("%.0f", s.Memory), "cpu": fmt.Sprintf("%.0f", s.CPU)}
+			switch m.Observe(s, time.Now()) {
+			case pressure.Throttle:
+				out.Step(style.Pause, "The host is under pressure (memory {{.memory}}%, CPU {{.cpu}}%), throttling {{.profile}} ...", usage)
+				if err := throttleCluster(&co, true); err != nil {
+					out.WarningT("Failed to throttle the cluster: {{.error}}", out.V{"error": err})
+				}
+			case pressure.Resume:
+				out.Step(style.Unpause, "The pressure subsided (memory {{.memory}}%, CPU {{.cpu}}%), resuming {{.profile}} ...", usage)
+				if err := throttleCluster(&co, false); err != nil {
+					out.WarningT("Failed to resume the cluster: {{.error}}", out.V{"error": err})
+				}
+			}
+		}
+	},
+}
+
+func validateThrottleFlags() error {
+	if throttleMemory <= 0 && throttleCPU <= 0 {
+		return fmt.Errorf("at least one of --memory-threshold and --cpu-threshold must be set")
+	}
+	if throttleMemory > 100 || throttleCPU > 100 {
+		return fmt.Errorf("the thresholds are percents, between 0 and 100")
+	}
+	if throttleInterval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s")
+	}
+	switch throttleMode {
+	case throttlePause:
+	case throttleScaleDown:
+		if len(throttleNamespaces) == 0 {
+			return fmt.Errorf("--mode=%s requires --namespaces", throttleScaleDown)
+		}
+	default:
+		return fmt.Errorf("invalid --mode %q, valid values: %s, %s", throttleMode, throttlePause, throttleScaleDown)
+	}
+	return nil
+}
+
+// throttleCluster throttles the cluster with the mode of the flags, or resumes it
+func throttleCluster(co *mustload.ClusterController, throttle bool) error {
+	if throttleMode == throttleScaleDown {
+		return throttleNamespacesScale(co.Config.Name, throttle)
+	}
+	// a nil slice pauses all the namespaces, including the control plane
+	namespaces := throttleNamespaces
+	if len(namespaces) == 0 {
+		namespaces = nil
+	}
+	count := 0
+	for _, n := range co.Config.Nodes {
+		host, err := machine.LoadHost(co.API, config.MachineName(*co.Config, n))
+		if err != nil {
+			return errors.Wrap(err, "loading host")
+		}
+		r, err := machine.CommandRunner(host)
+		if err != nil {
+			return errors.Wrap(err, "getting command runner")
+		}
+		cr, err := cruntime.New(cruntime.Config{Type: co.Config.KubernetesConfig.ContainerRuntime, Runner: r})
+		if err != nil {
+			return errors.Wrap(err, "getting runtime")
+		}
+		var ids []string
+		if throttle {
+			ids, err = cluster.Pause(cr, r, namespaces)
+		} else {
+			ids, err = cluster.Unpause(cr, r, namespaces)
+		}
+		if err != nil {
+			return err
+		}
+		count += len(ids)
+	}
+	if throttle {
+		out.Step(style.Pause, "Paused {{.count}} containers", out.V{"count": count})
+	} else {
+		out.Step(style.Unpause, "Unpaused {{.count}} containers", out.V{"count": count})
+	}
+	return nil
+}
+
+// throttleNamespacesScale scales the workloads of the namespaces of the flag down, or back up
+func throttleNamespacesScale(cname string, down bool) error {
+	cfg, err := kapi.ClientConfig(cname)
+	if err != nil {
+		return err
+	}
+	c, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return err
+	}
+	scale := pressure.ScaleUp
+	if down {
+		scale = pressure.ScaleDown
+	}
+	scaled, err := scale(context.Background(), c, throttleNamespaces)
+	if len(scaled) > 0 {
+		out.Infof("Scaled {{.workloads}}", out.V{"workloads": strings.Join(scaled, ", ")})
+	}
+	return err
+}
+
+func init() {
+	throttleCmd.Flags().Float64Var(&throttleMemory, "memory-threshold", 90, "Percent of the memory of the host in use above which the cluster is throttled, 0 to ignore the memory")
+	throttleCmd.Flags().Float64Var(&throttleCPU, "cpu-threshold", 90, "Percent of the CPU of the host in use above which the cluster is throttled, 0 to ignore the CPU")
+	throttleCmd.Flags().Float64Var(&throttleMargin, "resume-margin", 10, "Percents below the thresholds the usage of the host must get to, to resume the cluster")
+	throttleCmd.Flags().DurationVar(&throttleFor, "for", 30*time.Second, "How long the usage must stay above the thresholds to throttle the cluster, or below to resume it")
+	throttleCmd.Flags().DurationVar(&throttleInterval, "interval", 5*time.Second, "How often the usage of the host is sampled")
+	throttleCmd.Flags().StringVar(&throttleMode, "mode", throttlePause, fmt.Sprintf("How to throttle the cluster. Options include: [%s,%s]", throttlePause, throttleScaleDown))
+	throttleCmd.Flags().StringSliceVarP(&throttleNamespaces, "namespaces", "n", []string{}, "Namespaces to throttle: the namespaces to pause, all if empty, or to scale down")
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateThrottleFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		memory     float64
+		cpu        float64
+		mode       string
+		namespaces []string
+		wantErr    bool
+	}{
+		{name: "pause", memory: 90, cpu: 90, mode: "pause"},
+		{name: "memory only", memory: 85, mode: "pause"},
+		{name: "scale down", memory: 90, mode: "scale-down", namespaces: []string{"dev"}},
+		{name: "no threshold", mode: "pause", wantErr: true},
+		{name: "over 100", cpu: 120, mode: "pause", wantErr: true},
+		{name: "scale down without namespaces", memory: 90, mode: "scale-down", wantErr: true},
+		{name: "invalid mode", memory: 90, mode: "stop", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			throttleMemory, throttleCPU, throttleMode, throttleNamespaces, throttleInterval = tc.memory, tc.cpu, tc.mode, tc.namespaces, 5*time.Second
+			if err := validateThrottleFlags(); (err != nil) != tc.wantErr {
+				t.Errorf("validateThrottleFlags() = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -22,15 +22,13 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
-	"runtime"
 	"strconv"
 
 	"github.com/juju/fslock"
 	"github.com/spf13/cobra"
 
 	"k8s.io/klog/v2"
-	"k8s.io/minikube/pkg/drivers/kic/oci"
-	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/drivers/qemu"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/driver"
 	"k8s.io/minikube/pkg/minikube/exit"
@@ -41,6 +39,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/style"
 	"k8s.io/minikube/pkg/minikube/tunnel"
 	"k8s.io/minikube/pkg/minikube/tunnel/kic"
+	"k8s.io/minikube/pkg/minikube/tunnel/usernet"
 	pkgnetwork "k8s.io/minikube/pkg/network"
 )
 
@@ -61,12 +60,10 @@ var tunnelCmd = &cobra.Command{
 		cname := ClusterFlagValue()
 		co := mustload.Healthy(cname)
 
-		if driver.IsQEMU(co.Config.Driver) && pkgnetwork.IsBuiltinQEMU(co.Config.Network) {
-			msg := "minikube tunnel is not currently implemented with the builtin network on QEMU"
-			if runtime.GOOS == "darwin" {
-				msg += ", try starting minikube with '--network=socket_vmnet'"
-			}
-			exit.Message(reason.Unimplemented, msg)
+		// the LoadBalancer emulator of the tunnel would overwrite the ingress IPs allocated from the pool
+		if co.Config.LoadBalancerPool != "" {
+			out.Styled(style.Notice, "LoadBalancer services of the {{.profile}} cluster get an IP of its load balancer pool, there is no need to run minikube tunnel", out.V{"profile": cname})
+			return
 		}
 
 		if cleanup {
@@ -83,7 +80,7 @@ var tunnelCmd = &cobra.Command{
 		// We define the tunnel and minikube error free if the API server responds within a second.
 		// This also contributes to better UX, the tunnel status check can happen every second and
 		// doesn't hang on the API server call during startup and shutdown time or if there is a temporary error.
-		clientset, err := kapi.Client(cname)
+		clientset, err := backgroundClient(cname)
 		if err != nil {
 			exit.Error(reason.InternalKubernetesClient, "error creating clientset", err)
 		}
@@ -96,8 +93,23 @@ var tunnelCmd = &cobra.Command{
 			cancel()
 		}()
 
+		if driver.IsQEMU(co.Config.Driver) && pkgnetwork.IsBuiltinQEMU(co.Config.Network) {
+			d, ok := co.CP.Host.Driver.(*qemu.Driver)
+			if !ok {
+				exit.Message(reason.Unimplemented, "minikube tunnel cannot forward ports with the {{.driver}} driver", out.V{"driver": co.Config.Driver})
+			}
+
+			outputTunnelStarted()
+			qemuTunnel := usernet.NewTunnel(ctx, d, bindAddress, clientset.CoreV1(), clientset.NetworkingV1())
+			if err := qemuTunnel.Start(); err != nil {
+				exit.Error(reason.SvcTunnelStart, "error starting tunnel", err)
+			}
+
+			return
+		}
+
 		if driver.NeedsPortForward(co.Config.Driver) || bindAddress != "" {
-			port, err := oci.ForwardedPort(co.Config.Driver, cname, 22)
+			port, err := kicSSHPort(co.Config.Driver, cname)
 			if err != nil {
 				exit.Error(reason.DrvPortForward, "error getting ssh port", err)
 			}
@@ -95,6 +95,8 @@ var unpauseCmd = &cobra.Command{
 			ids = append(ids, uids...)
 		}
 
+		releaseWorkloads(cname)
+
 		register.Reg.SetStep(register.Done)
 
 		if namespaces == nil {
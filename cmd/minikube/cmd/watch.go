@@ -0,0 +1,253 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/spf13/cobra"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+var (
+	watchEvents   []string
+	watchExec     string
+	watchInterval time.Duration
+	watchDebounce time.Duration
+)
+
+// watchComponents are the components of a node whose state changes are events, named <component>-<state>
+var watchComponents = []string{"host", "kubelet", "apiserver", "node"}
+
+// watchEventNames are the events of the --event flag
+var watchEventNames = []string{
+	"host-running", "host-stopped",
+	"kubelet-running", "kubelet-stopped",
+	"apiserver-running", "apiserver-stopped", "apiserver-paused",
+	"node-ready", "node-not-ready",
+}
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watches the nodes of a cluster, and runs a command on their events",
+	Long: `Watches the status of the nodes of a cluster, and reports the changes of the state of their host, kubelet and apiserver as events.
+A node is ready while its host and kubelet, and the apiserver of a control-plane node, are running.
+A change of state is only reported once it lasted for --debounce, so that a flapping component does not flood the command of --exec.
+The command of --exec is run through the shell on each event, with the MINIKUBE_EVENT, MINIKUBE_NODE, MINIKUBE_STATE and MINIKUBE_PROFILE environment variables.
+Runs until interrupted.`,
+	Example: `minikube watch
+minikube watch --event node-not-ready --exec ./alert.sh
+minikube watch --event apiserver-stopped --exec 'minikube start' --debounce 1m`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := validateWatchFlags(); err != nil {
+			exit.Message(reason.Usage, "{{.error}}", out.V{"error": err})
+		}
+		cname := ClusterFlagValue()
+		api, cc := mustload.Partial(cname)
+		defer api.Close()
+
+		ctrlC := make(chan os.Signal, 1)
+		signal.Notify(ctrlC, os.Interrupt, syscall.SIGTERM)
+
+		w := newEventWatcher(watchEvents, watchDebounce)
+		out.Step(style.Running, "Watching the nodes of {{.profile}}. Press Ctrl+C to stop.", out.V{"profile": cname})
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		for {
+			// reload the config, to watch the nodes added since
+			if c, err := config.Load(cname); err != nil {
+				klog.Warningf("reloading the config of %s: %v", cname, err)
+			} else {
+				cc = c
+			}
+			var statuses []*Status
+			for _, n := range cc.Nodes {
+				st, err := nodeStatus(api, *cc, n)
+				if err != nil {
+					klog.Warningf("status of %s: %v", st.Name, err)
+				}
+				statuses = append(statuses, st)
+			}
+			for _, ev := range w.Observe(statuses, time.Now()) {
+				out.Step(style.Notice, "{{.node}}: {{.event}}", out.V{"node": ev.Node, "event": ev.Name})
+				if watchExec != "" {
+					runWatchExec(cname, ev)
+				}
+			}
+			select {
+			case <-ctrlC:
+				return
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+func validateWatchFlags() error {
+	for _, e := range watchEvents {
+		if !contains(watchEventNames, e) {
+			return fmt.Errorf("invalid --event %q, valid values: %s", e, strings.Join(watchEventNames, ", "))
+		}
+	}
+	if watchInterval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s")
+	}
+	if watchDebounce < 0 {
+		return fmt.Errorf("--debounce can not be negative")
+	}
+	return nil
+}
+
+// watchEvent is a change of state of a component of a node
+type watchEvent struct {
+	Name  string
+	Node  string
+	State string
+}
+
+// pendingState is a new state of a component, reported once it lasted for the debounce
+type pendingState struct {
+	state string
+	since time.Time
+}
+
+// eventWatcher turns the statuses of the nodes into events
+type eventWatcher struct {
+	filter   []string
+	debounce time.Duration
+	// states are the last reported states, by node and component
+	states  map[string]string
+	pending map[string]pendingState
+}
+
+// newEventWatcher returns a watcher reporting the events of filter, all if empty
+func newEventWatcher(filter []string, debounce time.Duration) *eventWatcher {
+	return &eventWatcher{filter: filter, debounce: debounce, states: map[string]string{}, pending: map[string]pendingState{}}
+}
+
+// Observe returns the events of the statuses observed at now. The first states of a node are not events.
+func (w *eventWatcher) Observe(statuses []*Status, now time.Time) []watchEvent {
+	var events []watchEvent
+	for _, st := range statuses {
+		states := componentStates(st)
+		for _, c := range watchComponents {
+			s, ok := states[c]
+			if !ok {
+				continue
+			}
+			key := st.Name + "/" + c
+			last, seen := w.states[key]
+			if !seen {
+				w.states[key] = s
+				continue
+			}
+			if s == last {
+				delete(w.pending, key)
+				continue
+			}
+			p, ok := w.pending[key]
+			if !ok || p.state != s {
+				p = pendingState{state: s, since: now}
+				w.pending[key] = p
+			}
+			if now.Sub(p.since) < w.debounce {
+				continue
+			}
+			w.states[key] = s
+			delete(w.pending, key)
+			ev := watchEvent{Name: c + "-" + s, Node: st.Name, State: s}
+			if len(w.filter) == 0 || contains(w.filter, ev.Name) {
+				events = append(events, ev)
+			}
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Node < events[j].Node })
+	return events
+}
+
+// componentStates returns the states of the components of a node status, the apiserver only for a control plane
+func componentStates(st *Status) map[string]string {
+	states := map[string]string{
+		"host":    runningState(st.Host),
+		"kubelet": runningState(st.Kubelet),
+	}
+	ready := states["host"] == "running" && states["kubelet"] == "running"
+	if !st.Worker {
+		states["apiserver"] = runningState(st.APIServer)
+		ready = ready && states["apiserver"] == "running"
+	}
+	states["node"] = "not-ready"
+	if ready {
+		states["node"] = "ready"
+	}
+	return states
+}
+
+// runningState returns the event state of a component status: running, paused or stopped
+func runningState(s string) string {
+	switch s {
+	case state.Running.String():
+		return "running"
+	case state.Paused.String():
+		return "paused"
+	}
+	return "stopped"
+}
+
+// runWatchExec runs the command of --exec on an event, through the shell
+func runWatchExec(cname string, ev watchEvent) {
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", watchExec)
+	} else {
+		c = exec.Command("/bin/sh", "-c", watchExec)
+	}
+	c.Env = append(os.Environ(),
+		"MINIKUBE_EVENT="+ev.Name,
+		"MINIKUBE_NODE="+ev.Node,
+		"MINIKUBE_STATE="+ev.State,
+		"MINIKUBE_PROFILE="+cname)
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	klog.Infof("running %q on %s of %s", watchExec, ev.Name, ev.Node)
+	if err := c.Run(); err != nil {
+		out.WarningT("The command of --exec failed on {{.event}} of {{.node}}: {{.error}}", out.V{"event": ev.Name, "node": ev.Node, "error": err})
+	}
+}
+
+func init() {
+	watchCmd.Flags().StringSliceVar(&watchEvents, "event", []string{}, fmt.Sprintf("Events to report, all if empty. Options include: [%s]", strings.Join(watchEventNames, ",")))
+	watchCmd.Flags().StringVar(&watchExec, "exec", "", "Command run through the shell on each event, with the MINIKUBE_EVENT, MINIKUBE_NODE, MINIKUBE_STATE and MINIKUBE_PROFILE environment variables")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 5*time.Second, "How often the status of the nodes is checked")
+	watchCmd.Flags().DurationVar(&watchDebounce, "debounce", 10*time.Second, "How long a new state must last to be reported as an event")
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEventWatcher(t *testing.T) {
+	running := &Status{Name: "m01", Host: "Running", Kubelet: "Running", APIServer: "Running"}
+	apiserverDown := &Status{Name: "m01", Host: "Running", Kubelet: "Running", APIServer: "Stopped"}
+	worker := &Status{Name: "m02", Host: "Running", Kubelet: "Running", APIServer: Irrelevant, Worker: true}
+	workerDown := &Status{Name: "m02", Host: "Stopped", Kubelet: "Stopped", APIServer: "Stopped", Worker: true}
+	start := time.Now()
+
+	tests := []struct {
+		description string
+		filter      []string
+		debounce    time.Duration
+		steps       [][]*Status
+		want        []watchEvent
+	}{
+		{
+			description: "first states are not events",
+			steps:       [][]*Status{{apiserverDown, workerDown}},
+		},
+		{
+			description: "changes",
+			steps:       [][]*Status{{running, worker}, {apiserverDown, workerDown}},
+			want: []watchEvent{
+				{Name: "apiserver-stopped", Node: "m01", State: "stopped"},
+				{Name: "node-not-ready", Node: "m01", State: "not-ready"},
+				{Name: "host-stopped", Node: "m02", State: "stopped"},
+				{Name: "kubelet-stopped", Node: "m02", State: "stopped"},
+				{Name: "node-not-ready", Node: "m02", State: "not-ready"},
+			},
+		},
+		{
+			description: "filter",
+			filter:      []string{"node-not-ready", "node-ready"},
+			steps:       [][]*Status{{running, worker}, {apiserverDown, workerDown}, {running, workerDown}},
+			want: []watchEvent{
+				{Name: "node-not-ready", Node: "m01", State: "not-ready"},
+				{Name: "node-not-ready", Node: "m02", State: "not-ready"},
+				{Name: "node-ready", Node: "m01", State: "ready"},
+			},
+		},
+		{
+			description: "debounced flapping",
+			filter:      []string{"node-not-ready", "node-ready"},
+			debounce:    3 * time.Second,
+			steps:       [][]*Status{{running}, {apiserverDown}, {running}, {apiserverDown}, {running}},
+		},
+		{
+			description: "debounced change",
+			filter:      []string{"node-not-ready"},
+			debounce:    3 * time.Second,
+			steps:       [][]*Status{{running}, {apiserverDown}, {apiserverDown}, {apiserverDown}, {apiserverDown}, {apiserverDown}},
+			want:        []watchEvent{{Name: "node-not-ready", Node: "m01", State: "not-ready"}},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			w := newEventWatcher(tc.filter, tc.debounce)
+			var got []watchEvent
+			for i, statuses := range tc.steps {
+				got = append(got, w.Observe(statuses, start.Add(time.Duration(i)*time.Second))...)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateWatchFlags(t *testing.T) {
+	defer func(events []string, interval, debounce time.Duration) {
+		watchEvents, watchInterval, watchDebounce = events, interval, debounce
+	}(watchEvents, watchInterval, watchDebounce)
+
+	tests := []struct {
+		events   []string
+		interval time.Duration
+		debounce time.Duration
+		wantErr  bool
+	}{
+		{interval: 5 * time.Second},
+		{events: []string{"node-not-ready", "apiserver-paused"}, interval: 5 * time.Second, debounce: time.Minute},
+		{events: []string{"node-down"}, interval: 5 * time.Second, wantErr: true},
+		{interval: 100 * time.Millisecond, wantErr: true},
+		{interval: 5 * time.Second, debounce: -time.Second, wantErr: true},
+	}
+	for _, tc := range tests {
+		watchEvents, watchInterval, watchDebounce = tc.events, tc.interval, tc.debounce
+		if err := validateWatchFlags(); (err != nil) != tc.wantErr {
+			t.Errorf("validateWatchFlags() with events %v, interval %s, debounce %s: error = %v, wantErr %v", tc.events, tc.interval, tc.debounce, err, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/mustload"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/workloads"
+)
+
+var (
+	moveFrom      string
+	moveTo        string
+	moveNamespace string
+	moveVolumes   bool
+	moveTimeout   time.Duration
+)
+
+// workloadsCmd represents the set of workloads subcommands
+var workloadsCmd = &cobra.Command{
+	Use:   "workloads",
+	Short: "Manage the workloads of the cluster",
+	Long:  "Operations on the workloads running in the cluster",
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.Message(reason.Usage, "Usage: minikube workloads [move]")
+	},
+}
+
+var workloadsMoveCmd = &cobra.Command{
+	Use:   "move",
+	Short: "Move the workloads of a namespace to another cluster",
+	Long: `Snapshots the resources of a namespace of a cluster, applies them to another cluster, and waits for the workloads to be ready.
+With --volumes, the data of the persistent volume claims is copied as well, while the Deployments and StatefulSets of the namespace
+are scaled down in the source cluster. They are scaled back up once copied, the source cluster is otherwise left as it is.`,
+	Example: "minikube workloads move --from dev --to dev2 --namespace app --volumes",
+	Run: func(cmd *cobra.Command, args []string) {
+		if moveTo == "" {
+			exit.Message(reason.Usage, "Please specify the target cluster with --to")
+		}
+		from := moveFrom
+		if from == "" {
+			from = ClusterFlagValue()
+		}
+		if from == moveTo {
+			exit.Message(reason.Usage, "The source and target clusters must be different")
+		}
+		src := mustload.Running(from)
+		dst := mustload.Running(moveTo)
+		srcCS, srcDyn := workloadClients(from)
+		dstCS, dstDyn := workloadClients(moveTo)
+		ctx := context.Background()
+
+		objs, err := workloads.Snapshot(ctx, srcDyn, moveNamespace)
+		if err != nil {
+			exit.Error(reason.KubernetesWorkloadsMove, "Failed to snapshot the namespace", err)
+		}
+		if len(objs) == 0 {
+			out.Styled(style.Meh, `Nothing to move in namespace "{{.namespace}}" of "{{.from}}"`, out.V{"namespace": moveNamespace, "from": from})
+			return
+		}
+		// the workloads are applied once their volumes have their data
+		var config, wl []workloads.Object
+		var claims []string
+		for _, o := range objs {
+			if o.IsWorkload() {
+				wl = append(wl, o)
+				continue
+			}
+			config = append(config, o)
+			if o.Resource.Resource == "persistentvolumeclaims" {
+				claims = append(claims, o.GetName())
+			}
+		}
+
+		out.Step(style.Copying, `Moving {{.count}} resources of namespace "{{.namespace}}" from "{{.from}}" to "{{.to}}" ...`,
+			out.V{"count": len(objs), "namespace": moveNamespace, "from": from, "to": moveTo})
+		if err := workloads.Apply(ctx, dstCS, dstDyn, moveNamespace, config); err != nil {
+			exit.Error(reason.KubernetesWorkloadsMove, "Failed to apply the resources", err)
+		}
+
+		if moveVolumes && len(claims) > 0 {
+			srcVols, err := workloads.BoundVolumes(ctx, srcCS, moveNamespace, claims, moveTimeout)
+			if err != nil {
+				exit.Error(reason.KubernetesWorkloadsMove, "Failed to find the volumes of the source cluster", err)
+			}
+			dstVols, err := workloads.BoundVolumes(ctx, dstCS, moveNamespace, claims, moveTimeout)
+			if err != nil {
+				exit.Error(reason.KubernetesWorkloadsMove, "Failed to provision the volumes of the target cluster", err)
+			}
+			out.Step(style.Waiting, `Scaling down the workloads of namespace "{{.namespace}}" of "{{.from}}" while their volumes are copied ...`, out.V{"namespace": moveNamespace, "from": from})
+			quiesced, err := workloads.Quiesce(ctx, srcCS, moveNamespace, claims, moveTimeout)
+			if err == nil {
+				err = copyVolumes(src, dst, srcVols, dstVols)
+			}
+			if rerr := workloads.Resume(ctx, srcCS, moveNamespace, quiesced); rerr != nil {
+				out.WarningT("Failed to scale the workloads of {{.from}} back up: {{.error}}", out.V{"from": from, "error": rerr})
+			}
+			if err != nil {
+				exit.Error(reason.KubernetesWorkloadsMove, "Failed to copy the volumes", err)
+			}
+		}
+
+		if err := workloads.Apply(ctx, dstCS, dstDyn, moveNamespace, wl); err != nil {
+			exit.Error(reason.KubernetesWorkloadsMove, "Failed to apply the workloads", err)
+		}
+		out.Step(style.Waiting, "Waiting for the workloads to be ready ...")
+		if err := workloads.WaitReady(ctx, dstCS, moveNamespace, wl, moveTimeout); err != nil {
+			exit.Error(reason.KubernetesWorkloadsMove, "The workloads did not become ready", err)
+		}
+		out.Step(style.Ready, `Namespace "{{.namespace}}" is running in "{{.to}}"`, out.V{"namespace": moveNamespace, "to": moveTo})
+	},
+}
+
+// copyVolumes copies the data of the host path volumes of the claims of the src cluster to the ones of the dst cluster
+func copyVolumes(src, dst mustload.ClusterController, srcVols, dstVols []workloads.Volume) error {
+	for i, v := range srcVols {
+		if v.Path == "" || dstVols[i].Path == "" {
+			out.WarningT("Skipping the data of claim {{.claim}}, which is not a host path volume", out.V{"claim": v.Claim})
+			continue
+		}
+		out.Step(style.Copying, "Copying the data of claim {{.claim}} ...", out.V{"claim": v.Claim})
+		if err := workloads.CopyVolume(src.CP.Runner, dst.CP.Runner, v.Path, dstVols[i].Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workloadClients returns the typed and dynamic clients of the kubeconfig context of a cluster
+func workloadClients(profile string) (kubernetes.Interface, dynamic.Interface) {
+	cfg, err := kapi.ClientConfig(profile)
+	if err != nil {
+		exit.Error(reason.InternalKubernetesClient, "Failed to get the client config", err)
+	}
+	cs, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		exit.Error(reason.InternalKubernetesClient, "Failed to create the client", err)
+	}
+	dyn, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		exit.Error(reason.InternalKubernetesClient, "Failed to create the client", err)
+	}
+	return cs, dyn
+}
+
+func init() {
+	workloadsMoveCmd.Flags().StringVar(&moveFrom, "from", "", "The cluster to move the workloads from, defaults to the current profile")
+	workloadsMoveCmd.Flags().StringVar(&moveTo, "to", "", "The cluster to move the workloads to")
+	workloadsMoveCmd.Flags().StringVarP(&moveNamespace, "namespace", "n", "default", "The namespace to move")
+	workloadsMoveCmd.Flags().BoolVar(&moveVolumes, "volumes", false, "Copy the data of the persistent volume claims, for host path volumes like the ones of the default storage class")
+	workloadsMoveCmd.Flags().DurationVar(&moveTimeout, "timeout", 5*time.Minute, "How long to wait for the volumes to be bound and the workloads to be ready")
+	workloadsCmd.AddCommand(workloadsMoveCmd)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/klog/v2"
+)
+
+var (
+	runtimeScheme = runtime.NewScheme()
+	codecs        = serializer.NewCodecFactory(runtimeScheme)
+	deserializer  = codecs.UniversalDeserializer()
+)
+
+func handler(w http.ResponseWriter, r *http.Request) {
+	policy, err := policyFromPath(r.URL.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if contentType := r.Header.Get("Content-Type"); contentType != "application/json" {
+		http.Error(w, "wrong content type: "+contentType, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	review := admissionv1.AdmissionReview{}
+	if _, _, err := deserializer.Decode(body, nil, &review); err != nil || review.Request == nil {
+		http.Error(w, "could not decode admission review", http.StatusBadRequest)
+		return
+	}
+
+	req := review.Request
+	log.Printf("AdmissionReview for Kind=%v Namespace=%v Name=%v UID=%v Operation=%v", req.Kind, req.Namespace, req.Name, req.UID, req.Operation)
+
+	review.Response = admissionDecision(req, policy)
+	review.Request = nil
+	resp, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if _, err := w.Write(resp); err != nil {
+		log.Printf("error writing response %v", err)
+	}
+}
+
+func main() {
+	addr := flag.String("addr", ":8443", "address to serve on")
+	certFile := flag.String("tls-cert-file", "/etc/webhook/certs/tls.crt", "serving certificate, issued by the cluster CA")
+	keyFile := flag.String("tls-key-file", "/etc/webhook/certs/tls.key", "private key of the serving certificate")
+	flag.Parse()
+
+	http.HandleFunc(pathPrefix, handler)
+
+	log.Printf("Starting HTTPS webhook server on %+v", *addr)
+	if err := http.ListenAndServeTLS(*addr, *certFile, *keyFile, nil); err != nil {
+		klog.Fatalf("Start https server failed with %s", err)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mattbaird/jsonpatch"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// pathPrefix is the path of the webhook, followed by the pull policy to rewrite to, e.g. /mutate/Never
+const pathPrefix = "/mutate/"
+
+// policyFromPath returns the pull policy of the webhook path
+func policyFromPath(p string) (corev1.PullPolicy, error) {
+	policy := corev1.PullPolicy(strings.TrimPrefix(p, pathPrefix))
+	switch policy {
+	case corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown pull policy in path %q", p)
+}
+
+// admissionDecision creates the admission decision for the request, rewriting the pull policy of the pod
+func admissionDecision(req *admissionv1.AdmissionRequest, policy corev1.PullPolicy) *admissionv1.AdmissionResponse {
+	var pod corev1.Pod
+	if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
+		return admissionError(req, err)
+	}
+
+	patch, err := json.Marshal(pullPolicyPatch(&pod, policy))
+	if err != nil {
+		return admissionError(req, err)
+	}
+
+	patchType := admissionv1.PatchTypeJSONPatch
+	return &admissionv1.AdmissionResponse{
+		UID:       req.UID,
+		Allowed:   true,
+		Patch:     patch,
+		PatchType: &patchType,
+	}
+}
+
+// admissionError allows the request unchanged, reporting the error
+func admissionError(req *admissionv1.AdmissionRequest, err error) *admissionv1.AdmissionResponse {
+	return &admissionv1.AdmissionResponse{
+		UID:     req.UID,
+		Allowed: true,
+		Result:  &metav1.Status{Message: err.Error()},
+	}
+}
+
+// pullPolicyPatch returns the patch setting the pull policy of all the containers of the pod
+func pullPolicyPatch(pod *corev1.Pod, policy corev1.PullPolicy) []jsonpatch.JsonPatchOperation {
+	patch := []jsonpatch.JsonPatchOperation{}
+	for i, c := range pod.Spec.InitContainers {
+		if c.ImagePullPolicy != policy {
+			patch = append(patch, jsonpatch.NewPatch("add", fmt.Sprintf("/spec/initContainers/%d/imagePullPolicy", i), policy))
+		}
+	}
+	for i, c := range pod.Spec.Containers {
+		if c.ImagePullPolicy != policy {
+			patch = append(patch, jsonpatch.NewPatch("add", fmt.Sprintf("/spec/containers/%d/imagePullPolicy", i), policy))
+		}
+	}
+	return patch
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestPolicyFromPath(t *testing.T) {
+	if p, err := policyFromPath("/mutate/Never"); err != nil || p != corev1.PullNever {
+		t.Errorf("policyFromPath(/mutate/Never) = %q, %v", p, err)
+	}
+	if _, err := policyFromPath("/mutate/Sometimes"); err == nil {
+		t.Errorf("expected an error for an unknown policy")
+	}
+}
+
+func TestAdmissionDecision(t *testing.T) {
+	pod := corev1.Pod{Spec: corev1.PodSpec{
+		InitContainers: []corev1.Container{{Name: "init", Image: "init:local"}},
+		Containers: []corev1.Container{
+			{Name: "app", Image: "app:local", ImagePullPolicy: corev1.PullAlways},
+			{Name: "sidecar", Image: "sidecar:local", ImagePullPolicy: corev1.PullNever},
+		},
+	}}
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := admissionDecision(&admissionv1.AdmissionRequest{UID: "1", Object: runtime.RawExtension{Raw: raw}}, corev1.PullNever)
+	if !resp.Allowed || resp.UID != "1" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	var patch []map[string]interface{}
+	if err := json.Unmarshal(resp.Patch, &patch); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/spec/initContainers/0/imagePullPolicy", "/spec/containers/0/imagePullPolicy"}
+	if len(patch) != len(want) {
+		t.Fatalf("got patch %s, want paths %v", resp.Patch, want)
+	}
+	for i, p := range patch {
+		if p["path"] != want[i] || p["value"] != "Never" {
+			t.Errorf("got patch %v, want path %s with value Never", p, want[i])
+		}
+	}
+}
@@ -0,0 +1,63 @@
+//go:build linux
+
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// vsock-agent runs in the minikube VM, and runs the commands of minikube received over vsock,
+// for the drivers which do not need SSH to reach the VM.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"golang.org/x/sys/unix"
+
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+var port = flag.Uint("port", command.VsockPort, "vsock port to listen on")
+
+func main() {
+	flag.Parse()
+
+	fd, err := unix.Socket(unix.AF_VSOCK, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		log.Fatalf("vsock socket: %v", err)
+	}
+	if err := unix.Bind(fd, &unix.SockaddrVM{CID: unix.VMADDR_CID_ANY, Port: uint32(*port)}); err != nil {
+		log.Fatalf("bind vsock port %d: %v", *port, err)
+	}
+	if err := unix.Listen(fd, 16); err != nil {
+		log.Fatalf("listen: %v", err)
+	}
+	log.Printf("listening on vsock port %d", *port)
+
+	for {
+		nfd, _, err := unix.Accept4(fd, unix.SOCK_CLOEXEC)
+		if err != nil {
+			log.Printf("accept: %v", err)
+			continue
+		}
+		conn := os.NewFile(uintptr(nfd), "vsock")
+		go func() {
+			if err := command.ServeVsock(conn); err != nil {
+				log.Printf("serve: %v", err)
+			}
+		}()
+	}
+}
@@ -67,6 +67,14 @@ var (
 	//go:embed inspektor-gadget/*.tmpl inspektor-gadget/*.yaml
 	InspektorGadgetAssets embed.FS
 
+	// GatekeeperAssets assets for gatekeeper addon
+	//go:embed gatekeeper/gatekeeper.yaml.tmpl
+	GatekeeperAssets embed.FS
+
+	// PullPolicyAssets assets for pull-policy addon
+	//go:embed pull-policy/pull-policy.yaml.tmpl
+	PullPolicyAssets embed.FS
+
 	// KongAssets assets for kong addon
 	//go:embed kong/kong-ingress-controller.yaml.tmpl
 	KongAssets embed.FS
@@ -170,4 +178,8 @@ var (
 	// NvidiaDevicePlugin assets for nvidia-device-plugin addon
 	//go:embed nvidia-device-plugin/*.tmpl
 	NvidiaDevicePlugin embed.FS
+
+	// NvidiaDCGMExporter assets for nvidia-dcgm-exporter addon
+	//go:embed nvidia-dcgm-exporter/*.tmpl
+	NvidiaDCGMExporter embed.FS
 )
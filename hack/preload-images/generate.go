@@ -21,6 +21,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"time"
 
 	"github.com/pkg/errors"
@@ -32,7 +33,9 @@ import (
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/cruntime"
 	"k8s.io/minikube/pkg/minikube/detect"
+	"k8s.io/minikube/pkg/minikube/download"
 	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/sandbox"
 	"k8s.io/minikube/pkg/minikube/sysinit"
 	"k8s.io/minikube/pkg/util"
 	"k8s.io/minikube/pkg/util/retry"
@@ -75,6 +78,8 @@ func generateTarball(kubernetesVersion, containerRuntime, tarballFilename string
 	if containerRuntime != "docker" { // kic overlay image is only needed by containerd and cri-o https://github.com/kubernetes/minikube/issues/7428
 		imgs = append(imgs, images.KindNet(""))
 	}
+	// the static pod of kube-vip runs on the control planes of multi-control-plane clusters, before any image can be loaded
+	imgs = append(imgs, images.KubeVip(""), images.KubeVipCloudProvider(""))
 
 	runner := command.NewKICRunner(profile, driver.OCIBinary)
 
@@ -127,6 +132,16 @@ func generateTarball(kubernetesVersion, containerRuntime, tarballFilename string
 	if err := bsutil.TransferBinaries(kcfg, runner, sm, ""); err != nil {
 		return errors.Wrap(err, "transferring k8s binaries")
 	}
+	// carry the binaries of the gVisor sandbox next to the k8s ones, for start --container-runtime-sandbox=gvisor
+	if sandbox.SupportsRuntime(containerRuntime) {
+		binaries, err := download.GVisor(runtime.GOARCH)
+		if err != nil {
+			return errors.Wrap(err, "downloading gVisor")
+		}
+		if err := sandbox.Install(runner, binaries); err != nil {
+			return errors.Wrap(err, "transferring gVisor binaries")
+		}
+	}
 	// Create image tarball
 	if err := createImageTarball(tarballFilename, containerRuntime); err != nil {
 		return errors.Wrap(err, "create tarball")
@@ -188,7 +203,7 @@ func createImageTarball(tarballFilename, containerRuntime string) error {
 		dirs = append(dirs, "./lib/containers")
 	}
 
-	args := []string{"exec", profile, "sudo", "tar", "-I", "lz4", "-C", "/var", "-cf", tarballFilename}
+	args := []string{"exec", profile, "sudo", "tar", "-I", download.PreloadCompressor, "-C", "/var", "-cf", tarballFilename}
 	args = append(args, dirs...)
 	cmd := exec.Command("docker", args...)
 	cmd.Stdout = os.Stdout
@@ -27,19 +27,26 @@ import (
 	"k8s.io/minikube/hack/update"
 )
 
-const dockerHubRegistryURL = "https://hub.docker.com/v2/repositories/library/registry/tags"
+const (
+	dockerHubRegistryURL = "https://hub.docker.com/v2/repositories/library/registry/tags"
+	// registryProxyVersion is the nginx passing the TLS connections of the nodes through to the registry, pinned by its digest
+	registryProxyVersion = "1.27.2-alpine"
+)
 
 var schema = map[string]update.Item{
 	"pkg/minikube/assets/addons.go": {
 		Replace: map[string]string{
-			`"registry:.*`: `"registry:{{.Version}}@{{.SHA}}",`,
+			`"registry:.*`:               `"registry:{{.Version}}@{{.SHA}}",`,
+			`"RegistryProxy": "nginx:.*`: `"RegistryProxy": "nginx:{{.ProxyVersion}}@{{.ProxySHA}}",`,
 		},
 	},
 }
 
 type Data struct {
-	Version string
-	SHA     string
+	Version      string
+	SHA          string
+	ProxyVersion string
+	ProxySHA     string
 }
 
 // Response is used to unmarshal the response from Docker Hub
@@ -60,7 +67,12 @@ func main() {
 		klog.Fatalf("failed to get image SHA: %v", err)
 	}
 
-	data := Data{Version: version, SHA: sha}
+	proxySHA, err := update.GetImageSHA(fmt.Sprintf("docker.io/nginx:%s", registryProxyVersion))
+	if err != nil {
+		klog.Fatalf("failed to get image SHA: %v", err)
+	}
+
+	data := Data{Version: version, SHA: sha, ProxyVersion: registryProxyVersion, ProxySHA: proxySHA}
 
 	update.Apply(schema, data)
 }
@@ -19,6 +19,7 @@ package addons
 import (
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
 	"path"
 	"runtime"
@@ -34,9 +35,9 @@ import (
 	"github.com/spf13/viper"
 
 	"k8s.io/klog/v2"
-	"k8s.io/minikube/pkg/drivers/kic/oci"
 	"k8s.io/minikube/pkg/kapi"
 	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
 	"k8s.io/minikube/pkg/minikube/cluster"
 	"k8s.io/minikube/pkg/minikube/command"
 	"k8s.io/minikube/pkg/minikube/config"
@@ -47,6 +48,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/machine"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/out/register"
+	"k8s.io/minikube/pkg/minikube/proxy"
 	"k8s.io/minikube/pkg/minikube/reason"
 	"k8s.io/minikube/pkg/minikube/style"
 	"k8s.io/minikube/pkg/minikube/sysinit"
@@ -324,20 +326,6 @@ func addonSpecificChecks(cc *config.ClusterConfig, name string, enable bool, run
 		}
 	}
 
-	if name == "registry" {
-		if driver.NeedsPortForward(cc.Driver) {
-			port, err := oci.ForwardedPort(cc.Driver, cc.Name, constants.RegistryAddonPort)
-			if err != nil {
-				return false, errors.Wrap(err, "registry port")
-			}
-			if enable {
-				out.Boxed(`Registry addon with {{.driver}} driver uses port {{.port}} please use that instead of default port 5000`, out.V{"driver": cc.Driver, "port": port})
-			}
-			out.Styled(style.Documentation, `For more information see: https://minikube.sigs.k8s.io/docs/drivers/{{.driver}}`, out.V{"driver": cc.Driver})
-		}
-		return false, nil
-	}
-
 	if name == "auto-pause" && !enable { // needs to be disabled before deleting the service file in the internal disable
 		if err := sysinit.New(runner).DisableNow("auto-pause"); err != nil {
 			klog.ErrorS(err, "failed to disable", "service", "auto-pause")
@@ -405,6 +393,15 @@ func supportLegacyIngress(addon *assets.Addon, cc config.ClusterConfig) error {
 func enableOrDisableAddonInternal(cc *config.ClusterConfig, addon *assets.Addon, runner command.Runner, data interface{}, enable bool) error {
 	deployFiles := []string{}
 
+	var proxyEnv []string
+	if enable && !cc.DisableProxyPropagation {
+		podCIDR, err := bsutil.PodCIDR(*cc)
+		if err != nil {
+			return errors.Wrap(err, "pod CIDR")
+		}
+		proxyEnv = proxy.Env(proxy.Detect(), *cc, podCIDR)
+	}
+
 	for _, addon := range addon.Assets {
 		var f assets.CopyableFile
 		var err error
@@ -413,6 +410,11 @@ func enableOrDisableAddonInternal(cc *config.ClusterConfig, addon *assets.Addon,
 			if err != nil {
 				return errors.Wrapf(err, "evaluate bundled addon %s asset", addon.GetSourcePath())
 			}
+			if len(proxyEnv) > 0 && strings.HasSuffix(f.GetTargetName(), ".yaml") {
+				if f, err = withProxyEnv(f, proxyEnv); err != nil {
+					return errors.Wrapf(err, "passing the proxy environment to addon %s asset", addon.GetSourcePath())
+				}
+			}
 
 		} else {
 			f = addon
@@ -455,6 +457,19 @@ func enableOrDisableAddonInternal(cc *config.ClusterConfig, addon *assets.Addon,
 	return retry.Expo(apply, 250*time.Millisecond, 2*time.Minute)
 }
 
+// withProxyEnv returns the manifest f with the proxy environment env added to the containers of its workloads
+func withProxyEnv(f assets.CopyableFile, env []string) (assets.CopyableFile, error) {
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	b, err = proxy.InjectPodEnv(b, env)
+	if err != nil {
+		return nil, err
+	}
+	return assets.NewMemoryAsset(b, f.GetTargetDir(), f.GetTargetName(), f.GetPermissions()), nil
+}
+
 func verifyAddonStatus(cc *config.ClusterConfig, name string, val string) error {
 	ns := "kube-system"
 	if name == "ingress" {
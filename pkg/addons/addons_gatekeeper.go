@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+)
+
+// MountGatekeeperPolicies mounts the policy directory of the host in the node, where the gatekeeper addon syncs the policies from
+func MountGatekeeperPolicies(cc *config.ClusterConfig, name, val string) error {
+	enable, err := strconv.ParseBool(val)
+	if err != nil {
+		return errors.Wrapf(err, "parsing bool: %s", name)
+	}
+	if !enable {
+		return nil
+	}
+	dir := cc.KubernetesConfig.GatekeeperPolicyDir
+	if dir == "" {
+		out.Styled(style.Tip, "To sync the policies of a directory, run: minikube addons configure gatekeeper")
+		return nil
+	}
+	if driver.BareMetal(cc.Driver) {
+		out.WarningT("The {{.driver}} driver does not support mounts, copy the policies to {{.path}}", out.V{"driver": cc.Driver, "path": vmpath.GuestGatekeeperPolicyDir})
+		return nil
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return errors.Wrap(err, "policy directory")
+	}
+	ms := dir + ":" + vmpath.GuestGatekeeperPolicyDir
+	out.Step(style.Mounting, "Creating mount {{.name}} ...", out.V{"name": ms})
+	return cluster.StartMountProcess(cc.Name, *cc, ms)
+}
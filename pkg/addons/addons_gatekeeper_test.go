@@ -0,0 +1,51 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"path/filepath"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/driver"
+)
+
+func TestMountGatekeeperPolicies(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "policies")
+	tests := []struct {
+		name   string
+		val    string
+		driver string
+		dir    string
+		err    bool
+	}{
+		{"not a bool", "yes", driver.QEMU2, missing, true},
+		{"disabled", "false", driver.QEMU2, missing, false},
+		{"no policy directory", "true", driver.QEMU2, "", false},
+		{"bare metal", "true", driver.None, missing, false},
+		{"missing policy directory", "true", driver.QEMU2, missing, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cc := &config.ClusterConfig{Name: "p1", Driver: tc.driver, KubernetesConfig: config.KubernetesConfig{GatekeeperPolicyDir: tc.dir}}
+			err := MountGatekeeperPolicies(cc, "gatekeeper", tc.val)
+			if (err != nil) != tc.err {
+				t.Errorf("MountGatekeeperPolicies(%q) error = %v, want error = %t", tc.val, err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/hostdns"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// enableOrDisableIngressDNSHost points the resolver of the host to the ingress-dns addon for the names of the test domain.
+// It runs on every start, as the IP of the cluster may change. Failures only warn, as the DNS can be configured manually.
+func enableOrDisableIngressDNSHost(cc *config.ClusterConfig, name, val string) error {
+	enable, err := strconv.ParseBool(val)
+	if err != nil {
+		return errors.Wrapf(err, "parsing bool: %s", name)
+	}
+	if !hostdns.Reachable(cc.Driver) {
+		klog.Infof("not configuring the DNS of the host for %s with the %s driver", name, cc.Driver)
+		return nil
+	}
+	cp, err := config.PrimaryControlPlane(cc)
+	if err != nil {
+		return errors.Wrap(err, "getting control plane")
+	}
+
+	if !enable {
+		if err := hostdns.Remove(cc.Name, cp.IP); err != nil {
+			out.WarningT("Unable to remove the DNS configuration of the host for *.{{.domain}} names: {{.error}}", out.V{"domain": hostdns.Domain, "error": err})
+		}
+		return nil
+	}
+	if err := hostdns.Configure(cc.Name, cp.IP); err != nil {
+		out.WarningT("Unable to configure the DNS of the host for *.{{.domain}} names, see https://minikube.sigs.k8s.io/docs/handbook/addons/ingress-dns/ to do it manually: {{.error}}", out.V{"domain": hostdns.Domain, "error": err})
+		return nil
+	}
+	out.Styled(style.Tip, "*.{{.domain}} names of the ingresses now resolve to {{.ip}} on this host", out.V{"domain": hostdns.Domain, "ip": cp.IP})
+	return nil
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/util"
+)
+
+const (
+	pullPolicyNamespace   = "pull-policy"
+	pullPolicyService     = "pull-policy-webhook"
+	pullPolicySecret      = "pull-policy-webhook-certs"
+	pullPolicyWebhookName = "pull-policy.minikube.sigs.k8s.io"
+
+	// DefaultPullPolicy is the pull policy forced by the pull-policy addon unless configured
+	DefaultPullPolicy = string(corev1.PullIfNotPresent)
+	// DefaultPullPolicyNamespaces are the namespaces of the pull-policy addon unless configured
+	DefaultPullPolicyNamespaces = "default"
+)
+
+// ConfigurePullPolicyWebhook issues the serving certificate of the pull-policy webhook from the cluster CA and registers the webhook
+func ConfigurePullPolicyWebhook(cc *config.ClusterConfig, name, val string) error {
+	enable, err := strconv.ParseBool(val)
+	if err != nil {
+		return errors.Wrapf(err, "parsing bool: %s", name)
+	}
+	client, err := kapi.Client(cc.Name)
+	if err != nil {
+		return errors.Wrap(err, "client")
+	}
+	webhooks := client.AdmissionregistrationV1().MutatingWebhookConfigurations()
+	if !enable {
+		// the certificate is deleted with the namespace of the addon
+		if err := webhooks.Delete(context.TODO(), pullPolicyService, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "deleting webhook")
+		}
+		return nil
+	}
+
+	policy := cc.KubernetesConfig.PullPolicy
+	if policy == "" {
+		policy = DefaultPullPolicy
+	}
+	namespaces := PullPolicyNamespaces(cc.KubernetesConfig.PullPolicyNamespaces)
+	if len(namespaces) == 0 {
+		namespaces = PullPolicyNamespaces(DefaultPullPolicyNamespaces)
+	}
+
+	if err := applyPullPolicySecret(cc, client); err != nil {
+		return err
+	}
+	ca, err := os.ReadFile(localpath.CACert())
+	if err != nil {
+		return errors.Wrap(err, "reading cluster CA")
+	}
+	wh := pullPolicyWebhook(policy, namespaces, ca)
+	existing, err := webhooks.Get(context.TODO(), wh.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = webhooks.Create(context.TODO(), wh, metav1.CreateOptions{})
+	case err == nil:
+		wh.ResourceVersion = existing.ResourceVersion
+		_, err = webhooks.Update(context.TODO(), wh, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return errors.Wrap(err, "registering webhook")
+	}
+	out.Styled(style.Tip, "The imagePullPolicy of the pods in {{.namespaces}} is set to {{.policy}}", out.V{"namespaces": strings.Join(namespaces, ", "), "policy": policy})
+	return nil
+}
+
+// applyPullPolicySecret issues the serving certificate of the webhook from the cluster CA, and stores it in a secret
+func applyPullPolicySecret(cc *config.ClusterConfig, client kubernetes.Interface) error {
+	certPath := filepath.Join(localpath.Profile(cc.Name), "pull-policy-webhook.crt")
+	keyPath := filepath.Join(localpath.Profile(cc.Name), "pull-policy-webhook.key")
+	host := fmt.Sprintf("%s.%s.svc", pullPolicyService, pullPolicyNamespace)
+	dnsNames := []string{pullPolicyService, fmt.Sprintf("%s.%s", pullPolicyService, pullPolicyNamespace), host}
+	caKey := filepath.Join(localpath.MiniPath(), "ca.key")
+	if err := util.GenerateSignedCert(certPath, keyPath, host, nil, dnsNames, nil, localpath.CACert(), caKey, cc.CertExpiration); err != nil {
+		return errors.Wrap(err, "generating webhook certificate")
+	}
+	crt, err := os.ReadFile(certPath)
+	if err != nil {
+		return err
+	}
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: pullPolicySecret, Namespace: pullPolicyNamespace},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: crt, corev1.TLSPrivateKeyKey: key},
+	}
+	secrets := client.CoreV1().Secrets(pullPolicyNamespace)
+	if _, err := secrets.Create(context.TODO(), secret, metav1.CreateOptions{}); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return errors.Wrap(err, "creating webhook certificate secret")
+		}
+		klog.Infof("updating the existing %s secret", pullPolicySecret)
+		if _, err := secrets.Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+			return errors.Wrap(err, "updating webhook certificate secret")
+		}
+	}
+	return nil
+}
+
+// pullPolicyWebhook returns the webhook rewriting the pull policy of the pods of namespaces, trusting the cluster CA
+func pullPolicyWebhook(policy string, namespaces []string, ca []byte) *admissionregistrationv1.MutatingWebhookConfiguration {
+	// the pull policy is a convenience, never block the pods when the webhook is down
+	failurePolicy := admissionregistrationv1.Ignore
+	sideEffects := admissionregistrationv1.SideEffectClassNone
+	path := "/mutate/" + policy
+	return &admissionregistrationv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   pullPolicyService,
+			Labels: map[string]string{"kubernetes.io/minikube-addons": "pull-policy"},
+		},
+		Webhooks: []admissionregistrationv1.MutatingWebhook{{
+			Name: pullPolicyWebhookName,
+			Rules: []admissionregistrationv1.RuleWithOperations{{
+				Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Create},
+				Rule: admissionregistrationv1.Rule{
+					APIGroups:   []string{""},
+					APIVersions: []string{"v1"},
+					Resources:   []string{"pods"},
+				},
+			}},
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{
+					Key:      corev1.LabelMetadataName,
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   namespaces,
+				}},
+			},
+			ClientConfig: admissionregistrationv1.WebhookClientConfig{
+				Service: &admissionregistrationv1.ServiceReference{
+					Namespace: pullPolicyNamespace,
+					Name:      pullPolicyService,
+					Path:      &path,
+				},
+				CABundle: ca,
+			},
+			FailurePolicy:           &failurePolicy,
+			SideEffects:             &sideEffects,
+			AdmissionReviewVersions: []string{"v1"},
+		}},
+	}
+}
+
+// PullPolicyNamespaces parses the comma separated namespaces of the pull-policy addon
+func PullPolicyNamespaces(s string) []string {
+	var namespaces []string
+	for _, ns := range strings.Split(s, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces
+}
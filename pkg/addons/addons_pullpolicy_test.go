@@ -0,0 +1,50 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPullPolicyNamespaces(t *testing.T) {
+	got := PullPolicyNamespaces(" default, dev ,,team-a")
+	want := []string{"default", "dev", "team-a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PullPolicyNamespaces() = %v, want %v", got, want)
+	}
+	if got := PullPolicyNamespaces(""); got != nil {
+		t.Errorf("PullPolicyNamespaces(\"\") = %v, want nil", got)
+	}
+}
+
+func TestPullPolicyWebhook(t *testing.T) {
+	wh := pullPolicyWebhook("Never", []string{"default", "dev"}, []byte("ca"))
+	if len(wh.Webhooks) != 1 {
+		t.Fatalf("expected 1 webhook, got %d", len(wh.Webhooks))
+	}
+	w := wh.Webhooks[0]
+	if p := *w.ClientConfig.Service.Path; p != "/mutate/Never" {
+		t.Errorf("path = %q, want /mutate/Never", p)
+	}
+	if string(w.ClientConfig.CABundle) != "ca" {
+		t.Errorf("CABundle = %q, want the cluster CA", w.ClientConfig.CABundle)
+	}
+	if got := w.NamespaceSelector.MatchExpressions[0].Values; !reflect.DeepEqual(got, []string{"default", "dev"}) {
+		t.Errorf("namespaces = %v, want [default dev]", got)
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package addons
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"github.com/otiai10/copy"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/minikube/bootstrapper"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// RegistryAddr returns the address of the registry addon of cc from the host
+func RegistryAddr(cc *config.ClusterConfig) (string, error) {
+	if driver.NeedsPortForward(cc.Driver) {
+		port := cc.RegistryPort
+		if port == 0 {
+			p, err := oci.ForwardedPort(cc.Driver, cc.Name, constants.RegistryAddonPort)
+			if err != nil {
+				return "", err
+			}
+			port = p
+		}
+		return net.JoinHostPort(oci.DaemonHost(cc.Driver), strconv.Itoa(port)), nil
+	}
+	cp, err := config.PrimaryControlPlane(cc)
+	if err != nil {
+		return "", err
+	}
+	if cp.IP == "" {
+		return "", errors.New("the control plane has no IP yet")
+	}
+	return net.JoinHostPort(cp.IP, strconv.Itoa(constants.RegistryAddonPort)), nil
+}
+
+// enableOrDisableRegistryTLS generates the serving cert of the registry addon, signed by the minikube CA, before its manifests are applied,
+// and makes the docker and podman clients of the user trust the CA at the address of the registry, so no insecure registry has to be declared.
+// Failing to install the trust on the host only warns, as images can still be pushed with minikube image push --registry-addon.
+func enableOrDisableRegistryTLS(cc *config.ClusterConfig, name, val string) error {
+	enable, err := strconv.ParseBool(val)
+	if err != nil {
+		return errors.Wrapf(err, "parsing bool: %s", name)
+	}
+	addr, err := RegistryAddr(cc)
+	if err != nil {
+		klog.Warningf("unable to find the address of the %s addon: %v", name, err)
+	}
+
+	if !enable {
+		if addr != "" {
+			removeRegistryTrust(addr)
+		}
+		return nil
+	}
+
+	var hosts []string
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		hosts = append(hosts, host)
+	}
+	if err := bootstrapper.GenerateRegistryCert(*cc, hosts); err != nil {
+		return errors.Wrap(err, "registry cert")
+	}
+	if addr == "" {
+		return nil
+	}
+	if err := installRegistryTrust(addr); err != nil {
+		out.WarningT("Unable to make the host trust the registry addon at {{.addr}}: {{.error}}", out.V{"addr": addr, "error": err})
+	}
+	if runtime.GOOS == "linux" {
+		out.Styled(style.Tip, "To push to it with the docker daemon of this host, make it trust the minikube CA: sudo mkdir -p /etc/docker/certs.d/{{.addr}} && sudo cp {{.ca}} /etc/docker/certs.d/{{.addr}}/ca.crt", out.V{"addr": addr, "ca": localpath.CACert()})
+	}
+	out.Styled(style.Tip, "The registry addon is served with TLS at {{.addr}}, push images to it with: minikube image push --registry-addon IMAGE", out.V{"addr": addr})
+	return nil
+}
+
+// registryTrustDirs returns the directories the container clients of the user look up the CA of the registry at addr in
+func registryTrustDirs(addr string) []string {
+	// the directories are named host:port, which windows does not allow
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	home := homedir.HomeDir()
+	return []string{
+		// docker desktop
+		filepath.Join(home, ".docker", "certs.d", addr),
+		// podman, buildah and skopeo
+		filepath.Join(home, ".config", "containers", "certs.d", addr),
+	}
+}
+
+// installRegistryTrust copies the minikube CA into the trust directories of the registry at addr
+func installRegistryTrust(addr string) error {
+	for _, d := range registryTrustDirs(addr) {
+		dst := filepath.Join(d, "ca.crt")
+		klog.Infof("copying %s -> %s", localpath.CACert(), dst)
+		if err := copy.Copy(localpath.CACert(), dst); err != nil {
+			return errors.Wrapf(err, "copy to %s", dst)
+		}
+	}
+	return nil
+}
+
+// removeRegistryTrust removes the minikube CA from the trust directories of the registry at addr
+func removeRegistryTrust(addr string) {
+	for _, d := range registryTrustDirs(addr) {
+		if err := os.Remove(filepath.Join(d, "ca.crt")); err != nil && !os.IsNotExist(err) {
+			klog.Warningf("removing the CA of %s: %v", d, err)
+			continue
+		}
+		// only removed when empty, the user may keep other files there
+		if err := os.Remove(d); err != nil && !os.IsNotExist(err) {
+			klog.Infof("keeping %s: %v", d, err)
+		}
+	}
+}
@@ -74,6 +74,11 @@ var Addons = []*Addon{
 		validations: []setFn{SupportsAmd64, IsRuntimeContainerd},
 		callbacks:   []setFn{EnableOrDisableAddon, verifyAddonStatus},
 	},
+	{
+		name:      "gatekeeper",
+		set:       SetBool,
+		callbacks: []setFn{EnableOrDisableAddon, MountGatekeeperPolicies},
+	},
 	{
 		name:      "helm-tiller",
 		set:       SetBool,
@@ -87,7 +92,7 @@ var Addons = []*Addon{
 	{
 		name:      "ingress-dns",
 		set:       SetBool,
-		callbacks: []setFn{EnableOrDisableAddon},
+		callbacks: []setFn{EnableOrDisableAddon, enableOrDisableIngressDNSHost},
 	},
 	{
 		name:      "istio-provisioner",
@@ -142,7 +147,7 @@ var Addons = []*Addon{
 	{
 		name:      "registry",
 		set:       SetBool,
-		callbacks: []setFn{EnableOrDisableAddon, verifyAddonStatus},
+		callbacks: []setFn{enableOrDisableRegistryTLS, EnableOrDisableAddon, verifyAddonStatus},
 	},
 	{
 		name:      "registry-creds",
@@ -232,4 +237,14 @@ var Addons = []*Addon{
 		set:       SetBool,
 		callbacks: []setFn{EnableOrDisableAddon},
 	},
+	{
+		name:      "nvidia-dcgm-exporter",
+		set:       SetBool,
+		callbacks: []setFn{EnableOrDisableAddon},
+	},
+	{
+		name:      "pull-policy",
+		set:       SetBool,
+		callbacks: []setFn{EnableOrDisableAddon, ConfigurePullPolicyWebhook},
+	},
 }
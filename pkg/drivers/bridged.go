@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drivers
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// arpEntryRe matches the entries of `arp -an` on macOS, like "? (192.168.1.23) at 5e:a:ef:e4:c:ee on en0 ifscope [ethernet]",
+// and of /proc/net/arp on Linux, like "192.168.1.23     0x1         0x2         52:54:00:e4:0c:ee     *        br0"
+var arpEntryRe = regexp.MustCompile(`(?m)(?:\((\d+\.\d+\.\d+\.\d+)\) at ([0-9a-fA-F:]+) on |^(\d+\.\d+\.\d+\.\d+)\s+0x\w+\s+0x\w+\s+([0-9a-fA-F:]+)\s)`)
+
+// BridgedIP returns the IP the VM got from the DHCP server of the network of iface.
+// The leases of that server are out of reach, so the neighbours on iface are probed to fill the ARP table of the host,
+// and the VM is looked up by its MAC address.
+func BridgedIP(iface, mac string) (string, error) {
+	subnet, err := interfaceSubnet(iface)
+	if err != nil {
+		return "", err
+	}
+	probe(subnet)
+	o, err := arpTable(iface)
+	if err != nil {
+		return "", err
+	}
+	ip := findARPEntry(o, mac)
+	if ip == "" {
+		return "", fmt.Errorf("could not find an IP address for %s", mac)
+	}
+	return ip, nil
+}
+
+// arpTable returns the ARP table of the host, read from the kernel on Linux which may not have the arp command
+func arpTable(iface string) (string, error) {
+	if runtime.GOOS == "linux" {
+		b, err := os.ReadFile("/proc/net/arp")
+		if err != nil {
+			return "", errors.Wrap(err, "arp table")
+		}
+		return string(b), nil
+	}
+	o, err := exec.Command("arp", "-an", "-i", iface).Output()
+	if err != nil {
+		return "", errors.Wrap(err, "arp")
+	}
+	return string(o), nil
+}
+
+// interfaceSubnet returns the IPv4 network of the host interface, at most a /24 around the host address
+func interfaceSubnet(iface string) (*net.IPNet, error) {
+	i, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, errors.Wrapf(err, "interface %s", iface)
+	}
+	addrs, err := i.Addrs()
+	if err != nil {
+		return nil, errors.Wrapf(err, "addresses of %s", iface)
+	}
+	for _, a := range addrs {
+		n, ok := a.(*net.IPNet)
+		if !ok || n.IP.To4() == nil {
+			continue
+		}
+		if ones, _ := n.Mask.Size(); ones < 24 {
+			n.Mask = net.CIDRMask(24, 32)
+		}
+		return &net.IPNet{IP: n.IP.To4().Mask(n.Mask), Mask: n.Mask}, nil
+	}
+	return nil, fmt.Errorf("interface %s has no IPv4 address", iface)
+}
+
+// probe sends a UDP datagram to every address of the subnet: the host resolves each address with ARP first,
+// which adds the VM to its ARP table
+func probe(subnet *net.IPNet) {
+	ip := subnet.IP.To4()
+	for n := ip.Mask(subnet.Mask); subnet.Contains(n); n = nextIP(n) {
+		c, err := net.Dial("udp4", net.JoinHostPort(n.String(), "9"))
+		if err != nil {
+			continue
+		}
+		_, _ = c.Write([]byte{0})
+		c.Close()
+	}
+}
+
+func nextIP(ip net.IP) net.IP {
+	n := make(net.IP, len(ip))
+	copy(n, ip)
+	for i := len(n) - 1; i >= 0; i-- {
+		n[i]++
+		if n[i] != 0 {
+			break
+		}
+	}
+	return n
+}
+
+// findARPEntry returns the IP of the entry of the MAC address in the output of `arp -an`, which strips the leading zeros of the octets
+func findARPEntry(arp, mac string) string {
+	mac = strings.ToLower(TrimMacAddress(mac))
+	for _, m := range arpEntryRe.FindAllStringSubmatch(arp, -1) {
+		ip, hw := m[1], m[2]
+		if ip == "" {
+			ip, hw = m[3], m[4]
+		}
+		if strings.ToLower(TrimMacAddress(hw)) == mac {
+			return ip
+		}
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drivers
+
+import (
+	"testing"
+)
+
+func TestFindARPEntry(t *testing.T) {
+	tests := []struct {
+		name string
+		arp  string
+		mac  string
+		want string
+	}{
+		{
+			name: "macOS",
+			arp: `? (192.168.1.1) at 0:11:22:33:44:55 on en0 ifscope [ethernet]
+? (192.168.1.23) at 5a:94:ef:e4:c:ee on en0 ifscope [ethernet]
+? (192.168.1.40) at (incomplete) on en0 ifscope [ethernet]
+`,
+			mac:  "5a:94:ef:e4:0c:ee",
+			want: "192.168.1.23",
+		},
+		{
+			name: "linux",
+			arp: `IP address       HW type     Flags       HW address            Mask     Device
+192.168.1.1      0x1         0x2         00:11:22:33:44:55     *        br0
+192.168.1.23     0x1         0x2         52:54:00:e4:0c:ee     *        br0
+192.168.1.40     0x1         0x0         00:00:00:00:00:00     *        br0
+`,
+			mac:  "52:54:00:e4:0c:ee",
+			want: "192.168.1.23",
+		},
+		{
+			name: "no entry",
+			arp:  "? (192.168.1.1) at 0:11:22:33:44:55 on en0 ifscope [ethernet]\n",
+			mac:  "5a:94:ef:e4:0c:ef",
+		},
+	}
+	for _, tc := range tests {
+		if got := findARPEntry(tc.arp, tc.mac); got != tc.want {
+			t.Errorf("%s: findARPEntry() = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
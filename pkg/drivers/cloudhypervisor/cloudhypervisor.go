@@ -0,0 +1,296 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cloudhypervisor implements a driver running the minikube VM with cloud-hypervisor
+// (https://www.cloudhypervisor.org). minikube runs its commands in the VM through a vsock
+// device instead of SSH.
+package cloudhypervisor
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+
+	pkgdrivers "k8s.io/minikube/pkg/drivers"
+	"k8s.io/minikube/pkg/drivers/microvm"
+	"k8s.io/minikube/pkg/drivers/tapnet"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+const (
+	defaultSSHUser = "docker"
+
+	// DriverName is the name of the driver
+	DriverName = "cloud-hypervisor"
+
+	// guestCID is the context ID of the vsock device of the VM
+	guestCID = 3
+)
+
+// Driver is the cloud-hypervisor driver
+type Driver struct {
+	*drivers.BaseDriver
+	*pkgdrivers.CommonDriver
+	Boot2DockerURL string
+	DiskSize       int
+	Memory         int
+	CPU            int
+	ExtraDisks     int
+	MACAddress     string
+	Cmdline        string
+	// Kernel is the path of an uncompressed vmlinux image built with the minikube kernel config
+	Kernel string
+	// Subnet is the /24 network of the tap device of the VM, the host being .1 and the VM .2
+	Subnet string
+	// Program is the path of the cloud-hypervisor binary
+	Program string
+}
+
+// NewDriver creates a new cloud-hypervisor driver
+func NewDriver(hostName, storePath string) drivers.Driver {
+	return &Driver{
+		Program: "cloud-hypervisor",
+		BaseDriver: &drivers.BaseDriver{
+			SSHUser:     defaultSSHUser,
+			MachineName: hostName,
+			StorePath:   storePath,
+		},
+	}
+}
+
+// DriverName returns the name of the driver
+func (d *Driver) DriverName() string {
+	return DriverName
+}
+
+// GetSSHHostname returns hostname for use with ssh
+func (d *Driver) GetSSHHostname() (string, error) {
+	return d.IPAddress, nil
+}
+
+// GetSSHKeyPath returns the path of the SSH key of the machine
+func (d *Driver) GetSSHKeyPath() string {
+	return d.ResolveStorePath("id_rsa")
+}
+
+// GetSSHUsername returns the user name for SSH
+func (d *Driver) GetSSHUsername() string {
+	if d.SSHUser == "" {
+		d.SSHUser = defaultSSHUser
+	}
+	return d.SSHUser
+}
+
+// GetURL returns a Docker URL inside this host
+func (d *Driver) GetURL() (string, error) {
+	if d.IPAddress == "" {
+		return "", nil
+	}
+	return fmt.Sprintf("tcp://%s:2376", d.IPAddress), nil
+}
+
+// GetIP returns the IP address of the VM
+func (d *Driver) GetIP() (string, error) {
+	return d.IPAddress, nil
+}
+
+// DialVsock connects to the command agent of the VM, through the vsock device of cloud-hypervisor
+func (d *Driver) DialVsock() (net.Conn, error) {
+	return command.DialHybridVsock(d.vsockPath(), command.VsockPort)
+}
+
+// PreCreateCheck checks that cloud-hypervisor, the kernel and the tools managing the network are available
+func (d *Driver) PreCreateCheck() error {
+	return microvm.CheckHost([]string{d.Program}, d.Kernel, "cloud-hypervisor-kernel")
+}
+
+// Create creates the disks of the VM, allocates its network and starts it
+func (d *Driver) Create() error {
+	if err := microvm.CreateDisks(d.BaseDriver, d.Boot2DockerURL, d.DiskSize, d.ExtraDisks); err != nil {
+		return err
+	}
+	if d.Subnet == "" {
+		subnet, err := tapnet.FreeSubnet()
+		if err != nil {
+			return err
+		}
+		d.Subnet = subnet
+	}
+	log.Info("Starting cloud-hypervisor VM...")
+	return d.Start()
+}
+
+// Start sets up the network of the VM and boots it, and waits for the command agent to be up
+func (d *Driver) Start() error {
+	if err := d.network().Setup(); err != nil {
+		return errors.Wrap(err, "setup network")
+	}
+
+	os.Remove(d.socketPath())
+	os.Remove(d.vsockPath())
+	if err := microvm.StartDetached(exec.Command(d.Program, d.args()...), d.ResolveStorePath("cloud-hypervisor.log"), d.pidfilePath()); err != nil {
+		return err
+	}
+
+	d.IPAddress = tapnet.GuestIP(d.Subnet)
+	log.Infof("Waiting for the command agent of the VM on vsock port %d...", command.VsockPort)
+	return d.waitForAgent(3 * time.Minute)
+}
+
+// args returns the arguments of cloud-hypervisor booting the VM
+func (d *Driver) args() []string {
+	args := []string{
+		"--api-socket", "path=" + d.socketPath(),
+		"--kernel", d.Kernel,
+		"--initramfs", microvm.InitrdPath(d.BaseDriver),
+		"--cmdline", d.Cmdline,
+		"--cpus", fmt.Sprintf("boot=%d", d.CPU),
+		"--memory", fmt.Sprintf("size=%dM", d.Memory),
+		"--disk",
+	}
+	for _, disk := range microvm.Disks(d.BaseDriver, d.ExtraDisks) {
+		args = append(args, "path="+disk)
+	}
+	return append(args,
+		"--net", fmt.Sprintf("tap=%s,mac=%s", tapnet.TapName(d.MachineName), d.MACAddress),
+		"--vsock", fmt.Sprintf("cid=%d,socket=%s", guestCID, d.vsockPath()),
+		"--serial", "file="+d.ResolveStorePath("console.log"),
+		"--console", "off",
+	)
+}
+
+// network returns the tap network of the VM
+func (d *Driver) network() *tapnet.Network {
+	return &tapnet.Network{
+		MachineName: d.MachineName,
+		Subnet:      d.Subnet,
+		MACAddress:  d.MACAddress,
+		StateDir:    d.ResolveStorePath("."),
+	}
+}
+
+// waitForAgent waits until the command agent of the VM accepts connections
+func (d *Driver) waitForAgent(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		conn, err := d.DialVsock()
+		if err == nil {
+			conn.Close()
+			return nil
+		}
+		if s, _ := d.GetState(); s == state.Stopped {
+			return fmt.Errorf("cloud-hypervisor exited, see %s", d.ResolveStorePath("cloud-hypervisor.log"))
+		}
+		time.Sleep(time.Second)
+	}
+	return fmt.Errorf("timed out waiting for the command agent of the VM")
+}
+
+// GetState returns the state of the VM, as reported by cloud-hypervisor
+func (d *Driver) GetState() (state.State, error) {
+	if !microvm.Running(d.pidfilePath()) {
+		return state.Stopped, nil
+	}
+
+	var info struct {
+		State string `json:"state"`
+	}
+	if err := d.api(http.MethodGet, "vm.info", nil, &info); err != nil {
+		return state.Error, err
+	}
+	return vmState(info.State), nil
+}
+
+// vmState converts a VM state reported by cloud-hypervisor to a libmachine state
+func vmState(s string) state.State {
+	switch s {
+	case "Running":
+		return state.Running
+	case "Paused":
+		return state.Paused
+	case "Created":
+		return state.Starting
+	case "Shutdown":
+		return state.Stopped
+	}
+	return state.None
+}
+
+// Stop presses the power button of the VM, cloud-hypervisor exits once the guest has shut down
+func (d *Driver) Stop() error {
+	if err := d.api(http.MethodPut, "vm.power-button", nil, nil); err != nil {
+		return err
+	}
+	if err := microvm.WaitStopped(d, time.Minute); err != nil {
+		return err
+	}
+	return d.network().StopDNSMasq()
+}
+
+// Kill stops the VM immediately
+func (d *Driver) Kill() error {
+	if err := microvm.Kill(d.pidfilePath()); err != nil {
+		return errors.Wrap(err, "kill cloud-hypervisor")
+	}
+	return d.network().StopDNSMasq()
+}
+
+// Remove stops the VM and removes its network, the machine dir is deleted by the caller
+func (d *Driver) Remove() error {
+	if s, err := d.GetState(); err != nil || s != state.Stopped {
+		if err := d.Kill(); err != nil {
+			return errors.Wrap(err, "kill")
+		}
+	}
+	if err := d.network().Teardown(); err != nil {
+		log.Warnf("unable to remove the network of %s: %v", d.MachineName, err)
+	}
+	os.Remove(d.pidfilePath())
+	os.Remove(d.socketPath())
+	os.Remove(d.vsockPath())
+	return nil
+}
+
+// Restart stops and starts the VM
+func (d *Driver) Restart() error {
+	return pkgdrivers.Restart(d)
+}
+
+// api calls the cloud-hypervisor API on its unix socket
+func (d *Driver) api(method, endpoint string, body interface{}, resp interface{}) error {
+	return microvm.API(d.socketPath(), method, "http://localhost/api/v1/"+endpoint, body, resp)
+}
+
+func (d *Driver) pidfilePath() string {
+	return d.ResolveStorePath("cloud-hypervisor.pid")
+}
+
+func (d *Driver) socketPath() string {
+	return d.ResolveStorePath("cloud-hypervisor.sock")
+}
+
+// vsockPath returns the unix socket of the hybrid vsock device of the VM
+func (d *Driver) vsockPath() string {
+	return d.ResolveStorePath("vsock.sock")
+}
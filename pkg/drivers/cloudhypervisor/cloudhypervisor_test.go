@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudhypervisor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/docker/machine/libmachine/state"
+
+	"k8s.io/minikube/pkg/drivers/tapnet"
+)
+
+func TestArgs(t *testing.T) {
+	d := NewDriver("minikube", "/home/user/.minikube").(*Driver)
+	d.Kernel = "/opt/minikube/vmlinux"
+	d.CPU = 2
+	d.Memory = 2048
+	d.ExtraDisks = 1
+	d.MACAddress = "02:00:00:00:00:01"
+	d.Cmdline = "console=ttyS0"
+
+	args := strings.Join(d.args(), " ")
+	dir := "/home/user/.minikube/machines/minikube/"
+	for _, want := range []string{
+		"--kernel /opt/minikube/vmlinux --initramfs " + dir + "initrd",
+		"--cpus boot=2 --memory size=2048M",
+		"--disk path=" + dir + "minikube.rawdisk path=" + dir + "minikube-0.rawdisk --net",
+		"--net tap=" + tapnet.TapName("minikube") + ",mac=02:00:00:00:00:01",
+		"--vsock cid=3,socket=" + dir + "vsock.sock",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("args() = %q, want it to contain %q", args, want)
+		}
+	}
+}
+
+func TestVMState(t *testing.T) {
+	tests := map[string]state.State{
+		"Running":  state.Running,
+		"Paused":   state.Paused,
+		"Created":  state.Starting,
+		"Shutdown": state.Stopped,
+		"":         state.None,
+	}
+	for s, want := range tests {
+		if got := vmState(s); got != want {
+			t.Errorf("vmState(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
@@ -18,6 +18,7 @@ package drivers
 
 import (
 	"bufio"
+	"crypto/rand"
 	"fmt"
 	"io"
 	"os"
@@ -40,6 +41,9 @@ import (
 // LeasesPath is the path to dhcpd leases
 const LeasesPath = "/var/db/dhcpd_leases"
 
+// VirtiofsTag is the tag the host directory of a virtiofs mount is shared with the guest under
+const VirtiofsTag = "minikube-mount"
+
 var leadingZeroRegexp = regexp.MustCompile(`0([A-Fa-f0-9](:|$))`)
 
 // This file is for common code shared among internal machine drivers
@@ -268,3 +272,15 @@ func parseDHCPdLeasesFile(file io.Reader) ([]DHCPEntry, error) {
 func TrimMacAddress(rawUUID string) string {
 	return leadingZeroRegexp.ReplaceAllString(rawUUID, "$1")
 }
+
+// GenerateMACAddress returns a random locally administered unicast MAC address, as socket_vmnet doesn't support multicast
+func GenerateMACAddress() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	// Set local bit, ensure unicast address
+	buf[0] = (buf[0] | 2) & 0xfe
+	mac := fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x", buf[0], buf[1], buf[2], buf[3], buf[4], buf[5])
+	return mac, nil
+}
@@ -0,0 +1,409 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package firecracker implements a driver running the minikube VM as a Firecracker microVM
+// (https://firecracker-microvm.github.io), optionally confined by the Firecracker jailer.
+package firecracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+
+	pkgdrivers "k8s.io/minikube/pkg/drivers"
+	"k8s.io/minikube/pkg/drivers/microvm"
+	"k8s.io/minikube/pkg/drivers/tapnet"
+)
+
+const (
+	defaultSSHUser = "docker"
+
+	// DriverName is the name of the driver
+	DriverName = "firecracker"
+
+	// jailSocket is the path of the API socket inside the jail
+	jailSocket = "/run/firecracker.socket"
+)
+
+// Driver is the firecracker driver
+type Driver struct {
+	*drivers.BaseDriver
+	*pkgdrivers.CommonDriver
+	Boot2DockerURL string
+	DiskSize       int
+	Memory         int
+	CPU            int
+	ExtraDisks     int
+	MACAddress     string
+	Cmdline        string
+	// Kernel is the path of an uncompressed vmlinux image built with the minikube kernel config
+	Kernel string
+	// Jailer runs firecracker chrooted and unprivileged with the Firecracker jailer
+	Jailer bool
+	// Subnet is the /24 network of the tap device of the VM, the host being .1 and the VM .2
+	Subnet string
+	// Program is the path of the firecracker binary
+	Program string
+}
+
+// NewDriver creates a new firecracker driver
+func NewDriver(hostName, storePath string) drivers.Driver {
+	return &Driver{
+		Program: "firecracker",
+		BaseDriver: &drivers.BaseDriver{
+			SSHUser:     defaultSSHUser,
+			MachineName: hostName,
+			StorePath:   storePath,
+		},
+	}
+}
+
+// DriverName returns the name of the driver
+func (d *Driver) DriverName() string {
+	return DriverName
+}
+
+// GetSSHHostname returns hostname for use with ssh
+func (d *Driver) GetSSHHostname() (string, error) {
+	return d.IPAddress, nil
+}
+
+// GetSSHKeyPath returns the path of the SSH key of the machine
+func (d *Driver) GetSSHKeyPath() string {
+	return d.ResolveStorePath("id_rsa")
+}
+
+// GetSSHUsername returns the user name for SSH
+func (d *Driver) GetSSHUsername() string {
+	if d.SSHUser == "" {
+		d.SSHUser = defaultSSHUser
+	}
+	return d.SSHUser
+}
+
+// GetURL returns a Docker URL inside this host
+func (d *Driver) GetURL() (string, error) {
+	if d.IPAddress == "" {
+		return "", nil
+	}
+	return fmt.Sprintf("tcp://%s:2376", d.IPAddress), nil
+}
+
+// GetIP returns the IP address of the VM
+func (d *Driver) GetIP() (string, error) {
+	return d.IPAddress, nil
+}
+
+// PreCreateCheck checks that firecracker, the kernel and the tools managing the network are available
+func (d *Driver) PreCreateCheck() error {
+	tools := []string{d.Program}
+	if d.Jailer {
+		tools = append(tools, "jailer")
+	}
+	return microvm.CheckHost(tools, d.Kernel, "firecracker-kernel")
+}
+
+// Create creates the disks of the VM, allocates its network and starts it
+func (d *Driver) Create() error {
+	if err := microvm.CreateDisks(d.BaseDriver, d.Boot2DockerURL, d.DiskSize, d.ExtraDisks); err != nil {
+		return err
+	}
+	if d.Subnet == "" {
+		subnet, err := tapnet.FreeSubnet()
+		if err != nil {
+			return err
+		}
+		d.Subnet = subnet
+	}
+	log.Info("Starting firecracker VM...")
+	return d.Start()
+}
+
+// Start sets up the network of the VM and boots it, and waits for SSH to be up
+func (d *Driver) Start() error {
+	if err := d.network().Setup(); err != nil {
+		return errors.Wrap(err, "setup network")
+	}
+
+	cfg, err := json.MarshalIndent(d.vmConfig(), "", "  ")
+	if err != nil {
+		return err
+	}
+	if d.Jailer {
+		if err := d.startJailer(cfg); err != nil {
+			return err
+		}
+	} else {
+		if err := os.WriteFile(d.ResolveStorePath("firecracker.json"), cfg, 0644); err != nil {
+			return errors.Wrap(err, "write config")
+		}
+		os.Remove(d.socketPath())
+		cmd := exec.Command(d.Program, "--api-sock", d.socketPath(), "--config-file", d.ResolveStorePath("firecracker.json"))
+		if err := microvm.StartDetached(cmd, d.ResolveStorePath("firecracker.log"), d.pidfilePath()); err != nil {
+			return err
+		}
+	}
+
+	d.IPAddress = tapnet.GuestIP(d.Subnet)
+	log.Infof("Waiting for VM to start (ssh -p 22 docker@%s)...", d.IPAddress)
+	return pkgdrivers.WaitForTCP(net.JoinHostPort(d.IPAddress, "22"), 3*time.Minute)
+}
+
+// startJailer starts firecracker in the jailer, which daemonizes and writes the pid of firecracker in the jail
+func (d *Driver) startJailer(cfg []byte) error {
+	if err := d.prepareJail(cfg); err != nil {
+		return errors.Wrap(err, "prepare jail")
+	}
+	program, err := exec.LookPath(d.Program)
+	if err != nil {
+		return err
+	}
+	logFile, err := os.OpenFile(d.ResolveStorePath("firecracker.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Wrap(err, "open firecracker log")
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command("sudo", d.jailerArgs(program, os.Getuid(), os.Getgid())...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	log.Debugf("executing: %s", strings.Join(cmd.Args, " "))
+	return errors.Wrap(cmd.Run(), "start jailer")
+}
+
+// network returns the tap network of the VM
+func (d *Driver) network() *tapnet.Network {
+	return &tapnet.Network{
+		MachineName: d.MachineName,
+		Subnet:      d.Subnet,
+		MACAddress:  d.MACAddress,
+		StateDir:    d.ResolveStorePath("."),
+	}
+}
+
+// vmConfig returns the firecracker configuration of the VM, with the paths as seen by firecracker
+func (d *Driver) vmConfig() map[string]interface{} {
+	drives := []map[string]interface{}{}
+	for i, disk := range d.disks() {
+		drives = append(drives, map[string]interface{}{
+			"drive_id":       fmt.Sprintf("disk%d", i),
+			"path_on_host":   d.vmPath(disk),
+			"is_root_device": false,
+			"is_read_only":   false,
+		})
+	}
+	return map[string]interface{}{
+		"boot-source": map[string]interface{}{
+			"kernel_image_path": d.vmPath(d.Kernel),
+			"initrd_path":       d.vmPath(microvm.InitrdPath(d.BaseDriver)),
+			"boot_args":         d.Cmdline,
+		},
+		"drives": drives,
+		"machine-config": map[string]interface{}{
+			"vcpu_count":   d.CPU,
+			"mem_size_mib": d.Memory,
+		},
+		"network-interfaces": []map[string]interface{}{{
+			"iface_id":      "eth0",
+			"guest_mac":     d.MACAddress,
+			"host_dev_name": tapnet.TapName(d.MachineName),
+		}},
+	}
+}
+
+// disks returns the paths of the disks of the VM on the host
+func (d *Driver) disks() []string {
+	return microvm.Disks(d.BaseDriver, d.ExtraDisks)
+}
+
+// vmPath returns the path of a host file as seen by firecracker, which is in the root of the jail with the jailer
+func (d *Driver) vmPath(path string) string {
+	if d.Jailer {
+		return "/" + filepath.Base(path)
+	}
+	return path
+}
+
+// jailID returns the id of the VM for the jailer, which allows alphanumeric characters and hyphens
+func (d *Driver) jailID() string {
+	id := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+			return r
+		}
+		return '-'
+	}, d.MachineName)
+	if len(id) > 64 {
+		id = id[:64]
+	}
+	return id
+}
+
+func (d *Driver) jailBase() string {
+	return d.ResolveStorePath("jailer")
+}
+
+// jailRoot returns the root directory of the jail, as created by the jailer
+func (d *Driver) jailRoot() string {
+	return filepath.Join(d.jailBase(), filepath.Base(d.Program), d.jailID(), "root")
+}
+
+// jailerArgs returns the arguments of sudo to run firecracker in the jailer
+func (d *Driver) jailerArgs(program string, uid, gid int) []string {
+	return []string{"jailer",
+		"--id", d.jailID(),
+		"--exec-file", program,
+		"--uid", strconv.Itoa(uid),
+		"--gid", strconv.Itoa(gid),
+		"--chroot-base-dir", d.jailBase(),
+		"--daemonize",
+		"--",
+		"--api-sock", jailSocket,
+		"--config-file", "/firecracker.json",
+	}
+}
+
+// prepareJail recreates the root of the jail, with hard links to the files of the VM
+func (d *Driver) prepareJail(cfg []byte) error {
+	// the jail is owned by root once used
+	if err := tapnet.Sudo("rm", "-rf", filepath.Dir(d.jailRoot())); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(d.jailRoot(), 0755); err != nil {
+		return err
+	}
+	files := append([]string{d.Kernel, microvm.InitrdPath(d.BaseDriver)}, d.disks()...)
+	for _, f := range files {
+		dst := filepath.Join(d.jailRoot(), filepath.Base(f))
+		if err := os.Link(f, dst); err != nil {
+			// the kernel may be on another filesystem
+			if err := copyFile(f, dst); err != nil {
+				return errors.Wrapf(err, "add %s to the jail", f)
+			}
+		}
+	}
+	return os.WriteFile(filepath.Join(d.jailRoot(), "firecracker.json"), cfg, 0644)
+}
+
+func copyFile(src, dst string) error {
+	b, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(dst, b, 0644)
+}
+
+// GetState returns the state of the VM, as reported by firecracker
+func (d *Driver) GetState() (state.State, error) {
+	if !microvm.Running(d.pidfilePath()) {
+		return state.Stopped, nil
+	}
+
+	var resp struct {
+		State string `json:"state"`
+	}
+	if err := d.api(http.MethodGet, "/", nil, &resp); err != nil {
+		return state.Error, err
+	}
+	return vmState(resp.State), nil
+}
+
+// vmState converts an instance state reported by firecracker to a libmachine state
+func vmState(s string) state.State {
+	switch s {
+	case "Running":
+		return state.Running
+	case "Paused":
+		return state.Paused
+	case "Not started":
+		return state.Starting
+	}
+	return state.None
+}
+
+// Stop asks the guest to shut down, firecracker exits when it does
+func (d *Driver) Stop() error {
+	if err := d.api(http.MethodPut, "/actions", map[string]string{"action_type": "SendCtrlAltDel"}, nil); err != nil {
+		return err
+	}
+	if err := microvm.WaitStopped(d, time.Minute); err != nil {
+		return err
+	}
+	return d.network().StopDNSMasq()
+}
+
+// Kill stops the VM immediately
+func (d *Driver) Kill() error {
+	if err := microvm.Kill(d.pidfilePath()); err != nil {
+		return errors.Wrap(err, "kill firecracker")
+	}
+	return d.network().StopDNSMasq()
+}
+
+// Remove stops the VM and removes its network, the machine dir is deleted by the caller
+func (d *Driver) Remove() error {
+	if s, err := d.GetState(); err != nil || s != state.Stopped {
+		if err := d.Kill(); err != nil {
+			return errors.Wrap(err, "kill")
+		}
+	}
+	if err := d.network().Teardown(); err != nil {
+		log.Warnf("unable to remove the network of %s: %v", d.MachineName, err)
+	}
+	if d.Jailer {
+		if err := tapnet.Sudo("rm", "-rf", d.jailBase()); err != nil {
+			return errors.Wrap(err, "remove jail")
+		}
+	}
+	os.Remove(d.pidfilePath())
+	os.Remove(d.socketPath())
+	return nil
+}
+
+// Restart stops and starts the VM
+func (d *Driver) Restart() error {
+	return pkgdrivers.Restart(d)
+}
+
+// api calls the firecracker API on its unix socket
+func (d *Driver) api(method, path string, body interface{}, resp interface{}) error {
+	return microvm.API(d.socketPath(), method, "http://firecracker"+path, body, resp)
+}
+
+func (d *Driver) pidfilePath() string {
+	if d.Jailer {
+		return filepath.Join(d.jailRoot(), filepath.Base(d.Program)+".pid")
+	}
+	return d.ResolveStorePath("firecracker.pid")
+}
+
+func (d *Driver) socketPath() string {
+	if d.Jailer {
+		return filepath.Join(d.jailRoot(), jailSocket)
+	}
+	return d.ResolveStorePath("firecracker.sock")
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package firecracker
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/machine/libmachine/state"
+)
+
+func TestVMConfigJailer(t *testing.T) {
+	d := NewDriver("minikube", "/home/user/.minikube").(*Driver)
+	d.Kernel = "/opt/minikube/vmlinux"
+	d.CPU = 2
+	d.Memory = 2048
+	d.ExtraDisks = 1
+
+	cfg := d.vmConfig()
+	boot := cfg["boot-source"].(map[string]interface{})
+	if boot["kernel_image_path"] != "/opt/minikube/vmlinux" {
+		t.Errorf("kernel_image_path = %v without the jailer, want the host path", boot["kernel_image_path"])
+	}
+	if n := len(cfg["drives"].([]map[string]interface{})); n != 2 {
+		t.Errorf("got %d drives, want 2", n)
+	}
+
+	d.Jailer = true
+	cfg = d.vmConfig()
+	boot = cfg["boot-source"].(map[string]interface{})
+	if boot["kernel_image_path"] != "/vmlinux" || boot["initrd_path"] != "/initrd" {
+		t.Errorf("boot-source = %v with the jailer, want paths in the jail", boot)
+	}
+	for _, drive := range cfg["drives"].([]map[string]interface{}) {
+		if p := drive["path_on_host"].(string); filepath.Dir(p) != "/" {
+			t.Errorf("drive path = %q with the jailer, want a path in the jail", p)
+		}
+	}
+
+	args := strings.Join(d.jailerArgs("/usr/bin/firecracker", 1000, 1000), " ")
+	want := "jailer --id minikube --exec-file /usr/bin/firecracker --uid 1000 --gid 1000 --chroot-base-dir /home/user/.minikube/machines/minikube/jailer --daemonize -- --api-sock /run/firecracker.socket --config-file /firecracker.json"
+	if args != want {
+		t.Errorf("jailerArgs() = %q, want %q", args, want)
+	}
+	if got := d.socketPath(); got != "/home/user/.minikube/machines/minikube/jailer/firecracker/minikube/root/run/firecracker.socket" {
+		t.Errorf("socketPath() = %q with the jailer", got)
+	}
+}
+
+func TestJailID(t *testing.T) {
+	d := NewDriver("my_cluster.m02", "").(*Driver)
+	if got := d.jailID(); got != "my-cluster-m02" {
+		t.Errorf("jailID() = %q, want my-cluster-m02", got)
+	}
+}
+
+func TestVMState(t *testing.T) {
+	tests := map[string]state.State{
+		"Running":     state.Running,
+		"Paused":      state.Paused,
+		"Not started": state.Starting,
+		"":            state.None,
+	}
+	for s, want := range tests {
+		if got := vmState(s); got != want {
+			t.Errorf("vmState(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
@@ -46,6 +46,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/reason"
 	"k8s.io/minikube/pkg/minikube/style"
 	"k8s.io/minikube/pkg/minikube/sysinit"
+	"k8s.io/minikube/pkg/network"
 	"k8s.io/minikube/pkg/util/retry"
 )
 
@@ -99,7 +100,7 @@ func (d *Driver) Create() error {
 		networkName = d.NodeConfig.ClusterName
 	}
 	staticIP := d.NodeConfig.StaticIP
-	if gateway, err := oci.CreateNetwork(d.OCIBinary, networkName, d.NodeConfig.Subnet, staticIP); err != nil {
+	if gateway, err := oci.CreateNetwork(d.OCIBinary, networkName, d.NodeConfig.Subnet, staticIP, d.NodeConfig.IPv6); err != nil {
 		msg := "Unable to create dedicated network, this might result in cluster IP change after restart: {{.error}}"
 		args := out.V{"error": err}
 		if staticIP != "" {
@@ -121,6 +122,13 @@ func (d *Driver) Create() error {
 		klog.Infof("calculated static IP %q for the %q container", ip.String(), d.NodeConfig.MachineName)
 		params.IP = ip.String()
 	}
+	if d.NodeConfig.IPv6 && params.IP != "" {
+		ipv6, err := network.IPv6Of(params.IP)
+		if err != nil {
+			return err
+		}
+		params.IPv6 = ipv6
+	}
 	drv := d.DriverName()
 
 	listAddr := oci.DefaultBindIPV4
@@ -152,6 +160,7 @@ func (d *Driver) Create() error {
 		oci.PortMapping{
 			ListenAddress: listAddr,
 			ContainerPort: constants.RegistryAddonPort,
+			HostPort:      int32(d.NodeConfig.RegistryPort),
 		},
 		oci.PortMapping{
 			ListenAddress: listAddr,
@@ -187,13 +196,14 @@ func (d *Driver) Create() error {
 	go func() {
 		defer waitForPreload.Done()
 		// If preload doesn't exist, don't bother extracting tarball to volume
-		if !download.PreloadExists(d.NodeConfig.KubernetesVersion, d.NodeConfig.ContainerRuntime, d.DriverName()) {
+		tarballPath, ok := download.PreloadTarball(d.NodeConfig.KubernetesVersion, d.NodeConfig.ContainerRuntime, d.DriverName(), d.NodeConfig.PreloadFrom)
+		if !ok {
 			return
 		}
 		t := time.Now()
 		klog.Infof("Starting extracting preloaded images to volume ...")
 		// Extract preloaded images to container
-		if err := oci.ExtractTarballToVolume(d.NodeConfig.OCIBinary, download.TarballPath(d.NodeConfig.KubernetesVersion, d.NodeConfig.ContainerRuntime), params.Name, d.NodeConfig.ImageDigest); err != nil {
+		if err := oci.ExtractTarballToVolume(d.NodeConfig.OCIBinary, tarballPath, params.Name, d.NodeConfig.ImageDigest); err != nil {
 			if strings.Contains(err.Error(), "No space left on device") {
 				pErr = oci.ErrInsufficientDockerStorage
 				return
@@ -212,6 +222,16 @@ func (d *Driver) Create() error {
 		return errors.Wrap(err, "create kic node")
 	}
 
+	// the networks are shared by the nodes and clusters connected to them, so they are not removed with the container
+	for _, n := range d.NodeConfig.ExtraNetworks {
+		if _, err := oci.CreateNetwork(d.OCIBinary, n, "", "", false); err != nil {
+			return errors.Wrapf(err, "creating extra network %s", n)
+		}
+		if err := oci.ConnectNetwork(d.OCIBinary, n, params.Name); err != nil {
+			return errors.Wrapf(err, "connecting extra network %s", n)
+		}
+	}
+
 	if err := d.prepareSSH(); err != nil {
 		return errors.Wrap(err, "prepare kic ssh")
 	}
@@ -304,6 +324,10 @@ func (d *Driver) GetExternalIP() (string, error) {
 
 // GetSSHHostname returns hostname for use with ssh
 func (d *Driver) GetSSHHostname() (string, error) {
+	// the ssh port of the container is reached through a tunnel to a remote daemon host
+	if oci.IsSSHDaemonHost(d.OCIBinary) {
+		return oci.DefaultBindIPV4, nil
+	}
 	return oci.DaemonHost(d.DriverName()), nil
 }
 
@@ -313,6 +337,9 @@ func (d *Driver) GetSSHPort() (int, error) {
 	if err != nil {
 		return p, errors.Wrap(err, "get ssh host-port")
 	}
+	if oci.IsSSHDaemonHost(d.OCIBinary) {
+		return oci.ForwardedPortTunnel(d.OCIBinary, d.ResolveStorePath(""), constants.SSHPort, p)
+	}
 	return p, nil
 }
 
@@ -36,6 +36,11 @@ type SysInfo struct {
 	StorageDriver string   // the storage driver for the daemon  (for example overlay2)
 	Errors        []string // any server issues
 	DockerOS      string   // used to detect if using Docker Desktop or Docker Engine on Linux
+	CgroupVersion string   // cgroup version of the daemon host ("1" or "2"), empty if unknown
+	// CgroupControllers are the cgroup v2 controllers available to the daemon, only reported by podman
+	CgroupControllers []string
+	// PortForwarder is the network command used by rootless podman to publish ports (slirp4netns or pasta)
+	PortForwarder string
 }
 
 var (
@@ -60,7 +65,8 @@ var CachedDaemonInfo = func(ociBin string) (SysInfo, error) {
 func DaemonInfo(ociBin string) (SysInfo, error) {
 	if ociBin == Podman {
 		p, err := podmanSystemInfo()
-		cachedSysInfo = &SysInfo{CPUs: p.Host.Cpus, TotalMemory: p.Host.MemTotal, OSType: p.Host.Os, Swarm: false, Rootless: p.Host.Security.Rootless, StorageDriver: p.Store.GraphDriverName}
+		cachedSysInfo = &SysInfo{CPUs: p.Host.Cpus, TotalMemory: p.Host.MemTotal, OSType: p.Host.Os, Swarm: false, Rootless: p.Host.Security.Rootless, StorageDriver: p.Store.GraphDriverName,
+			CgroupVersion: strings.TrimPrefix(p.Host.CgroupVersion, "v"), CgroupControllers: p.Host.CgroupControllers, PortForwarder: p.portForwarder()}
 		return *cachedSysInfo, err
 	}
 	d, err := dockerSystemInfo()
@@ -71,7 +77,7 @@ func DaemonInfo(ociBin string) (SysInfo, error) {
 			break
 		}
 	}
-	cachedSysInfo = &SysInfo{CPUs: d.NCPU, TotalMemory: d.MemTotal, OSType: d.OSType, Swarm: d.Swarm.LocalNodeState == "active", Rootless: rootless, StorageDriver: d.Driver, Errors: d.ServerErrors, DockerOS: d.OperatingSystem}
+	cachedSysInfo = &SysInfo{CPUs: d.NCPU, TotalMemory: d.MemTotal, OSType: d.OSType, Swarm: d.Swarm.LocalNodeState == "active", Rootless: rootless, StorageDriver: d.Driver, Errors: d.ServerErrors, DockerOS: d.OperatingSystem, CgroupVersion: d.CgroupVersion}
 	return *cachedSysInfo, err
 }
 
@@ -111,6 +117,7 @@ type dockerSysInfo struct {
 	SystemTime         time.Time `json:"SystemTime"`
 	LoggingDriver      string    `json:"LoggingDriver"`
 	CgroupDriver       string    `json:"CgroupDriver"`
+	CgroupVersion      string    `json:"CgroupVersion"`
 	NEventsListener    int       `json:"NEventsListener"`
 	KernelVersion      string    `json:"KernelVersion"`
 	OperatingSystem    string    `json:"OperatingSystem"`
@@ -187,9 +194,10 @@ type dockerSysInfo struct {
 // podmanSysInfo represents the output of podman system info --format '{{json .}}'
 type podmanSysInfo struct {
 	Host struct {
-		BuildahVersion string `json:"BuildahVersion"`
-		CgroupVersion  string `json:"CgroupVersion"`
-		Conmon         struct {
+		BuildahVersion    string   `json:"BuildahVersion"`
+		CgroupVersion     string   `json:"CgroupVersion"`
+		CgroupControllers []string `json:"cgroupControllers"`
+		Conmon            struct {
 			Package string `json:"package"`
 			Path    string `json:"path"`
 			Version string `json:"version"`
@@ -217,6 +225,13 @@ type podmanSysInfo struct {
 		Security    struct {
 			Rootless bool `json:"rootless"`
 		} `json:"security"`
+		RootlessNetworkCmd string `json:"rootlessNetworkCmd"`
+		Slirp4NetNS        struct {
+			Executable string `json:"executable"`
+		} `json:"slirp4netns"`
+		Pasta struct {
+			Executable string `json:"executable"`
+		} `json:"pasta"`
 		Uptime string `json:"uptime"`
 	} `json:"host"`
 	Registries struct {
@@ -245,6 +260,20 @@ type podmanSysInfo struct {
 	} `json:"store"`
 }
 
+// portForwarder returns the network command rootless podman uses to publish ports, empty if none is installed
+func (p podmanSysInfo) portForwarder() string {
+	switch {
+	case p.Host.RootlessNetworkCmd == "pasta" && p.Host.Pasta.Executable != "":
+		return "pasta"
+	case p.Host.RootlessNetworkCmd == "slirp4netns" && p.Host.Slirp4NetNS.Executable != "":
+		return "slirp4netns"
+	case p.Host.RootlessNetworkCmd == "" && p.Host.Slirp4NetNS.Executable != "":
+		// podman < 5 does not report the command and always uses slirp4netns
+		return "slirp4netns"
+	}
+	return ""
+}
+
 var dockerInfoGetter = func() (string, error) {
 	rr, err := runCmd(exec.Command(Docker, "system", "info", "--format", "{{json .}}"))
 	return rr.Stdout.String(), err
@@ -63,8 +63,9 @@ func firstSubnetAddr(subnet string) string {
 	return subnet
 }
 
-// CreateNetwork creates a network returns gateway and error, minikube creates one network per cluster
-func CreateNetwork(ociBin, networkName, subnet, staticIP string) (net.IP, error) {
+// CreateNetwork creates a network returns gateway and error, minikube creates one network per cluster.
+// With ipv6, the network also has the IPv6 subnet paired with its IPv4 one.
+func CreateNetwork(ociBin, networkName, subnet, staticIP string, ipv6 bool) (net.IP, error) {
 	defaultBridgeName := defaultBridgeName(ociBin)
 	if networkName == defaultBridgeName {
 		klog.Infof("skipping creating network since default network %s was specified", networkName)
@@ -103,7 +104,7 @@ func CreateNetwork(ociBin, networkName, subnet, staticIP string) (net.IP, error)
 			klog.Errorf("failed to find free subnet for %s network %s after %d attempts: %v", ociBin, networkName, 20, err)
 			return nil, fmt.Errorf("un-retryable: %w", err)
 		}
-		info.gateway, err = tryCreateDockerNetwork(ociBin, subnet, info.mtu, networkName)
+		info.gateway, err = tryCreateDockerNetwork(ociBin, subnet, info.mtu, networkName, ipv6)
 		if err == nil {
 			klog.Infof("%s network %s %s created", ociBin, networkName, subnet.CIDR)
 			return info.gateway, nil
@@ -119,7 +120,7 @@ func CreateNetwork(ociBin, networkName, subnet, staticIP string) (net.IP, error)
 	return info.gateway, fmt.Errorf("failed to create %s network %s: %w", ociBin, networkName, err)
 }
 
-func tryCreateDockerNetwork(ociBin string, subnet *network.Parameters, mtu int, name string) (net.IP, error) {
+func tryCreateDockerNetwork(ociBin string, subnet *network.Parameters, mtu int, name string, ipv6 bool) (net.IP, error) {
 	gateway := net.ParseIP(subnet.Gateway)
 	klog.Infof("attempt to create %s network %s %s with gateway %s and MTU of %d ...", ociBin, name, subnet.CIDR, subnet.Gateway, mtu)
 	args := []string{
@@ -129,6 +130,17 @@ func tryCreateDockerNetwork(ociBin string, subnet *network.Parameters, mtu int,
 		fmt.Sprintf("--subnet=%s", subnet.CIDR),
 		fmt.Sprintf("--gateway=%s", subnet.Gateway),
 	}
+	if ipv6 {
+		subnetV6, err := network.IPv6Subnet(subnet.IP)
+		if err != nil {
+			return nil, err
+		}
+		gatewayV6, err := network.IPv6Of(subnet.Gateway)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "--ipv6", fmt.Sprintf("--subnet=%s", subnetV6), fmt.Sprintf("--gateway=%s", gatewayV6))
+	}
 	if ociBin == Docker {
 		// options documentation https://docs.docker.com/engine/reference/commandline/network_create/#bridge-driver-options
 		args = append(args, "-o")
@@ -305,6 +317,22 @@ func RemoveNetwork(ociBin string, name string) error {
 	return err
 }
 
+// ConnectNetwork attaches the container to a network, as an additional interface
+func ConnectNetwork(ociBin string, name string, container string) error {
+	rr, err := runCmd(exec.Command(ociBin, "network", "connect", name, container))
+	if err != nil {
+		// Error response from daemon: endpoint with name minikube already exists in network storage
+		if strings.Contains(rr.Output(), "already exists") {
+			return nil
+		}
+		if isNetworkNotFound(rr.Output()) {
+			return ErrNetworkNotFound
+		}
+		return errors.Wrapf(err, "connecting %s to network %s", container, name)
+	}
+	return nil
+}
+
 func networkExists(ociBin string, name string) bool {
 	_, err := containerNetworkInspect(ociBin, name)
 	if err != nil && !errors.Is(err, ErrNetworkNotFound) { // log unexpected error
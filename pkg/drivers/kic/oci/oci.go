@@ -21,7 +21,6 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"net/url"
 	"os"
 	"os/exec"
 	"runtime"
@@ -189,6 +188,9 @@ func CreateContainerNode(p CreateParams) error { //nolint to suppress cyclomatic
 	if p.Network != "" && p.IP != "" {
 		runArgs = append(runArgs, "--network", p.Network)
 		runArgs = append(runArgs, "--ip", p.IP)
+		if p.IPv6 != "" {
+			runArgs = append(runArgs, "--ip6", p.IPv6)
+		}
 	}
 	if p.GPUs != "" {
 		runArgs = append(runArgs, "--gpus", "all")
@@ -342,6 +344,31 @@ func StartContainer(ociBin string, container string) error {
 	return nil
 }
 
+// UpdateContainerResources changes the CPUs and the memory in MB of a running container with "docker/podman update"
+func UpdateContainerResources(ociBin string, container string, cpus int, memory int) error {
+	args := []string{"update"}
+	if cpus > 0 {
+		args = append(args, fmt.Sprintf("--cpus=%d", cpus))
+	}
+	if memory > 0 && HasMemoryCgroup() {
+		args = append(args, fmt.Sprintf("--memory=%dmb", memory))
+		if hasMemorySwapCgroup() {
+			// keep swap disabled, as when created
+			args = append(args, fmt.Sprintf("--memory-swap=%dmb", memory))
+		}
+	}
+	if len(args) == 1 {
+		return nil
+	}
+	args = append(args, container)
+
+	if _, err := runCmd(exec.Command(ociBin, args...)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // ContainerID returns id of a container name
 func ContainerID(ociBin string, nameOrID string) (string, error) {
 	rr, err := runCmd(exec.Command(ociBin, "container", "inspect", "-f", "{{.Id}}", nameOrID))
@@ -501,6 +528,9 @@ func generatePortMappings(portMappings ...PortMapping) []string {
 		// let docker pick a host port by leaving it as ::
 		// example --publish=127.0.0.17::8443 will get a random host port for 8443
 		publish := fmt.Sprintf("--publish=%s::%d", pm.ListenAddress, pm.ContainerPort)
+		if pm.HostPort != 0 {
+			publish = fmt.Sprintf("--publish=%s:%d:%d", pm.ListenAddress, pm.HostPort, pm.ContainerPort)
+		}
 		result = append(result, publish)
 	}
 	return result
@@ -689,23 +719,8 @@ func iptablesFileExists(ociBin string, nameOrID string) bool {
 // For Docker return the host part of DOCKER_HOST environment variable if set
 // or DefaultBindIPV4 otherwise
 func DaemonHost(driver string) string {
-	if driver == Podman {
-		if dh := os.Getenv(constants.PodmanContainerHostEnv); dh != "" {
-			if u, err := url.Parse(dh); err == nil {
-				if u.Host != "" {
-					return u.Hostname()
-				}
-			}
-		}
-	}
-	if driver == Docker {
-		if dh := os.Getenv(constants.DockerHostEnv); dh != "" {
-			if u, err := url.Parse(dh); err == nil {
-				if u.Host != "" {
-					return u.Hostname()
-				}
-			}
-		}
+	if u := daemonHostURL(driver); u != nil {
+		return u.Hostname()
 	}
 	return DefaultBindIPV4
 }
@@ -714,21 +729,7 @@ func DaemonHost(driver string) string {
 // For Podman driver return true if CONTAINER_HOST is set to a URI, and the URI contains a host item
 // For Docker driver return true if DOCKER_HOST is set to a URI, and the URI contains a host item
 func IsExternalDaemonHost(driver string) bool {
-	if driver == Podman {
-		if dh := os.Getenv(constants.PodmanContainerHostEnv); dh != "" {
-			if u, err := url.Parse(dh); err == nil {
-				return u.Host != ""
-			}
-		}
-	}
-	if driver == Docker {
-		if dh := os.Getenv(constants.DockerHostEnv); dh != "" {
-			if u, err := url.Parse(dh); err == nil {
-				return u.Host != ""
-			}
-		}
-	}
-	return false
+	return daemonHostURL(driver) != nil
 }
 
 func podmanVersion() (semver.Version, error) {
@@ -18,6 +18,7 @@ package oci
 
 import (
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -142,3 +143,14 @@ func TestDaemonHost(t *testing.T) {
 		}
 	}
 }
+
+func TestGeneratePortMappings(t *testing.T) {
+	got := generatePortMappings(
+		PortMapping{ListenAddress: "127.0.0.1", ContainerPort: 8443},
+		PortMapping{ListenAddress: "127.0.0.1", ContainerPort: 5000, HostPort: 41234},
+	)
+	want := []string{"--publish=127.0.0.1::8443", "--publish=127.0.0.1:41234:5000"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("generatePortMappings() = %v, want %v", got, want)
+	}
+}
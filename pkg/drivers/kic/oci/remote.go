@@ -0,0 +1,224 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shirou/gopsutil/v3/process"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/util/retry"
+)
+
+// portTunnel is the state of an ssh tunnel forwarding a local port to a port published on the remote daemon host
+type portTunnel struct {
+	PID        int `json:"pid"`
+	LocalPort  int `json:"localPort"`
+	RemotePort int `json:"remotePort"`
+}
+
+// daemonHostURL returns the URL of the daemon of driver set in DOCKER_HOST or CONTAINER_HOST,
+// nil if the daemon is local
+func daemonHostURL(driver string) *url.URL {
+	var dh string
+	switch driver {
+	case Docker:
+		dh = os.Getenv(constants.DockerHostEnv)
+	case Podman:
+		dh = os.Getenv(constants.PodmanContainerHostEnv)
+	}
+	if dh == "" {
+		return nil
+	}
+	u, err := url.Parse(dh)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	return u
+}
+
+// IsSSHDaemonHost returns whether the OCI daemon of driver is reached over ssh://,
+// in which case only the ssh port of the remote host can be assumed reachable
+func IsSSHDaemonHost(driver string) bool {
+	u := daemonHostURL(driver)
+	return u != nil && u.Scheme == "ssh"
+}
+
+// ForwardedPortTunnel ensures an ssh tunnel forwards a local port to the port the container port is published to
+// on the remote daemon host, and returns the local port. The tunnel is a background ssh process recorded in dir,
+// which outlives minikube and is replaced when the published port changes.
+func ForwardedPortTunnel(driver, dir string, containerPort, remotePort int) (int, error) {
+	u := daemonHostURL(driver)
+	if u == nil || u.Scheme != "ssh" {
+		return 0, fmt.Errorf("the %s daemon is not reached over ssh", driver)
+	}
+	stateFile := tunnelStateFile(dir, containerPort)
+	if t, err := loadTunnel(stateFile); err == nil {
+		if t.RemotePort == remotePort && t.alive() {
+			return t.LocalPort, nil
+		}
+		t.stop()
+	}
+
+	localPort, err := freeLocalPort()
+	if err != nil {
+		return 0, errors.Wrap(err, "finding a free local port")
+	}
+	cmd := exec.Command("ssh", sshTunnelArgs(u, localPort, remotePort)...)
+	klog.Infof("starting tunnel: %s", cmd.Args)
+	if err := cmd.Start(); err != nil {
+		return 0, errors.Wrap(err, "starting ssh tunnel")
+	}
+	t := portTunnel{PID: cmd.Process.Pid, LocalPort: localPort, RemotePort: remotePort}
+	// the tunnel outlives minikube, do not keep a child process around
+	if err := cmd.Process.Release(); err != nil {
+		klog.Warningf("release ssh tunnel process: %v", err)
+	}
+	listening := func() error {
+		if !t.alive() {
+			return fmt.Errorf("tunnel to %s port %d is not up", u.Host, remotePort)
+		}
+		return nil
+	}
+	if err := retry.Local(listening, 15*time.Second); err != nil {
+		t.stop()
+		return 0, err
+	}
+
+	b, err := json.Marshal(t)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(stateFile, b, 0600); err != nil {
+		t.stop()
+		return 0, errors.Wrap(err, "writing tunnel state")
+	}
+	return localPort, nil
+}
+
+// TunnelPort returns the local port of the running ssh tunnel recorded in dir for the container port,
+// without starting one: the tunnels are started by ForwardedPortTunnel
+func TunnelPort(dir string, containerPort, remotePort int) (int, error) {
+	t, err := loadTunnel(tunnelStateFile(dir, containerPort))
+	if err != nil {
+		return 0, errors.Wrap(err, "no tunnel to the remote daemon host")
+	}
+	if t.RemotePort != remotePort || !t.alive() {
+		return 0, fmt.Errorf("the tunnel to port %d of the remote daemon host is down", remotePort)
+	}
+	return t.LocalPort, nil
+}
+
+// StopTunnels stops the ssh tunnels recorded in dir
+func StopTunnels(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "tunnel-*.json"))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		t, err := loadTunnel(f)
+		if err != nil {
+			klog.Warningf("loading tunnel %s: %v", f, err)
+		} else {
+			t.stop()
+		}
+		if err := os.Remove(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sshTunnelArgs(u *url.URL, localPort, remotePort int) []string {
+	args := []string{"-N",
+		"-o", "ExitOnForwardFailure=yes",
+		"-o", "BatchMode=yes",
+		"-o", "ServerAliveInterval=30",
+		"-L", fmt.Sprintf("%s:%d:%s:%d", DefaultBindIPV4, localPort, DefaultBindIPV4, remotePort),
+	}
+	if u.Port() != "" {
+		args = append(args, "-p", u.Port())
+	}
+	target := u.Hostname()
+	if u.User != nil && u.User.Username() != "" {
+		target = u.User.Username() + "@" + target
+	}
+	return append(args, target)
+}
+
+func tunnelStateFile(dir string, containerPort int) string {
+	return filepath.Join(dir, fmt.Sprintf("tunnel-%d.json", containerPort))
+}
+
+func loadTunnel(path string) (portTunnel, error) {
+	var t portTunnel
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return t, err
+	}
+	err = json.Unmarshal(b, &t)
+	return t, err
+}
+
+// alive returns whether the ssh process runs and accepts connections on the local port
+func (t portTunnel) alive() bool {
+	if ok, err := process.PidExists(int32(t.PID)); err != nil || !ok {
+		return false
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(DefaultBindIPV4, strconv.Itoa(t.LocalPort)), time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+func (t portTunnel) stop() {
+	p, err := process.NewProcess(int32(t.PID))
+	if err != nil {
+		return
+	}
+	if name, err := p.Name(); err != nil || name != "ssh" && name != "ssh.exe" {
+		// the pid was reused by another process
+		return
+	}
+	if err := p.Kill(); err != nil {
+		klog.Warningf("stopping ssh tunnel %d: %v", t.PID, err)
+	}
+}
+
+func freeLocalPort() (int, error) {
+	l, err := net.Listen("tcp", net.JoinHostPort(DefaultBindIPV4, "0"))
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSSHTunnelArgs(t *testing.T) {
+	tests := []struct {
+		dockerHost string
+		ssh        bool
+		want       []string
+	}{
+		{"ssh://me@build-box:2222", true, []string{"-p", "2222", "me@build-box"}},
+		{"ssh://build-box", true, []string{"build-box"}},
+		{"tcp://build-box:2376", false, nil},
+		{"", false, nil},
+	}
+	for _, tc := range tests {
+		t.Setenv("DOCKER_HOST", tc.dockerHost)
+		if got := IsSSHDaemonHost(Docker); got != tc.ssh {
+			t.Errorf("IsSSHDaemonHost(%q) = %t, want %t", tc.dockerHost, got, tc.ssh)
+		}
+		if !tc.ssh {
+			continue
+		}
+		args := sshTunnelArgs(daemonHostURL(Docker), 40000, 32768)
+		want := append([]string{"-N", "-o", "ExitOnForwardFailure=yes", "-o", "BatchMode=yes", "-o", "ServerAliveInterval=30",
+			"-L", "127.0.0.1:40000:127.0.0.1:32768"}, tc.want...)
+		if !reflect.DeepEqual(args, want) {
+			t.Errorf("sshTunnelArgs(%q) = %v, want %v", tc.dockerHost, args, want)
+		}
+	}
+}
+
+func TestStopTunnels(t *testing.T) {
+	dir := t.TempDir()
+	// the pid of a process which is not ssh is left alone
+	state := []byte(`{"pid": 1, "localPort": 40000, "remotePort": 32768}`)
+	if err := os.WriteFile(tunnelStateFile(dir, 8443), state, 0600); err != nil {
+		t.Fatal(err)
+	}
+	tun, err := loadTunnel(tunnelStateFile(dir, 8443))
+	if err != nil || tun.RemotePort != 32768 {
+		t.Fatalf("loadTunnel() = %v, %v", tun, err)
+	}
+	if err := StopTunnels(dir); err != nil {
+		t.Fatalf("StopTunnels: %v", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 0 {
+		t.Errorf("StopTunnels left %v", files)
+	}
+}
+
+func TestTunnelPort(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := TunnelPort(dir, 8443, 32771); err == nil {
+		t.Errorf("TunnelPort succeeded without a tunnel")
+	}
+	// a tunnel whose ssh process is gone is not restarted
+	if err := os.WriteFile(tunnelStateFile(dir, 8443), []byte(`{"pid": 999999999, "localPort": 40000, "remotePort": 32771}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TunnelPort(dir, 8443, 32771); err == nil {
+		t.Errorf("TunnelPort succeeded with a dead tunnel")
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "tunnel-*.json")); len(files) != 1 {
+		t.Errorf("TunnelPort changed the recorded tunnels: %v", files)
+	}
+}
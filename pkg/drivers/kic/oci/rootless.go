@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// RootlessCgroupDocURL documents the cgroup v2 delegation rootless daemons need
+const RootlessCgroupDocURL = "https://rootlesscontaine.rs/getting-started/common/cgroup2/"
+
+// RootlessControllers are the cgroup v2 controllers kubelet needs delegated to the user running a rootless daemon
+var RootlessControllers = []string{"cpu", "cpuset", "io", "memory", "pids"}
+
+// ErrRootlessCgroupV1 is thrown when a rootless daemon runs on a host with cgroup v1
+var ErrRootlessCgroupV1 = errors.New("rootless mode requires cgroup v2, but the host uses cgroup v1")
+
+// ErrRootlessNoPortForwarder is thrown when rootless podman has neither slirp4netns nor pasta to publish ports
+var ErrRootlessNoPortForwarder = errors.New("rootless podman requires slirp4netns or pasta to publish ports, but neither is installed")
+
+// CheckRootlessCgroups returns an error if the cgroup controllers kubelet needs are not delegated to the rootless daemon
+func CheckRootlessCgroups(ociBin string, si SysInfo) error {
+	if si.CgroupVersion == "1" {
+		return ErrRootlessCgroupV1
+	}
+	controllers := si.CgroupControllers
+	if len(controllers) == 0 {
+		// docker does not report the controllers, read the delegation of the local user instead
+		if IsExternalDaemonHost(ociBin) || runtime.GOOS != "linux" {
+			return nil
+		}
+		var err error
+		controllers, err = delegatedControllers(os.Getuid())
+		if err != nil {
+			return err
+		}
+	}
+	if missing := MissingControllers(controllers, RootlessControllers); len(missing) > 0 {
+		return fmt.Errorf("cgroup controllers not delegated to the rootless %s daemon: %s", ociBin, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// CheckRootlessPortForwarder returns an error if rootless podman cannot publish the ports of the node
+func CheckRootlessPortForwarder(ociBin string, si SysInfo) error {
+	if ociBin != Podman || IsExternalDaemonHost(ociBin) {
+		return nil
+	}
+	if si.PortForwarder == "" {
+		return ErrRootlessNoPortForwarder
+	}
+	return nil
+}
+
+// UnprivilegedPortStart returns the first host port a rootless daemon is allowed to publish
+func UnprivilegedPortStart() (int, error) {
+	b, err := os.ReadFile("/proc/sys/net/ipv4/ip_unprivileged_port_start")
+	if err != nil {
+		return 0, errors.Wrap(err, "read ip_unprivileged_port_start")
+	}
+	return strconv.Atoi(strings.TrimSpace(string(b)))
+}
+
+// delegatedControllers returns the cgroup v2 controllers systemd delegates to the user manager of uid
+func delegatedControllers(uid int) ([]string, error) {
+	p := filepath.Join("/sys/fs/cgroup/user.slice", fmt.Sprintf("user-%d.slice", uid), fmt.Sprintf("user@%d.service", uid), "cgroup.controllers")
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "read delegated cgroup controllers")
+	}
+	return strings.Fields(string(b)), nil
+}
+
+// MissingControllers returns the cgroup controllers of want that are not in have
+func MissingControllers(have, want []string) []string {
+	avail := map[string]bool{}
+	for _, c := range have {
+		avail[c] = true
+	}
+	var missing []string
+	for _, c := range want {
+		if !avail[c] {
+			missing = append(missing, c)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMissingControllers(t *testing.T) {
+	got := MissingControllers([]string{"cpu", "memory", "pids"}, []string{"cpuset", "cpu", "io", "memory", "hugetlb", "pids"})
+	want := []string{"cpuset", "io", "hugetlb"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingControllers = %v, want %v", got, want)
+	}
+	if got := MissingControllers([]string{"cpu", "memory"}, []string{"cpu", "memory"}); len(got) != 0 {
+		t.Errorf("MissingControllers = %v, want none", got)
+	}
+}
+
+func TestCheckRootlessCgroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		si      SysInfo
+		wantErr bool
+	}{
+		{"delegated", SysInfo{CgroupVersion: "2", CgroupControllers: []string{"cpuset", "cpu", "io", "memory", "hugetlb", "pids"}}, false},
+		{"not delegated", SysInfo{CgroupVersion: "2", CgroupControllers: []string{"memory", "pids"}}, true},
+		{"cgroup v1", SysInfo{CgroupVersion: "1"}, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckRootlessCgroups(Podman, tc.si)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CheckRootlessCgroups(%+v) = %v, want error: %t", tc.si, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckRootlessPortForwarder(t *testing.T) {
+	t.Setenv("CONTAINER_HOST", "")
+	if err := CheckRootlessPortForwarder(Podman, SysInfo{PortForwarder: "pasta"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := CheckRootlessPortForwarder(Podman, SysInfo{}); err != ErrRootlessNoPortForwarder {
+		t.Errorf("expected %v, got %v", ErrRootlessNoPortForwarder, err)
+	}
+	if err := CheckRootlessPortForwarder(Docker, SysInfo{}); err != nil {
+		t.Errorf("unexpected error for docker: %v", err)
+	}
+}
+
+func TestPodmanPortForwarder(t *testing.T) {
+	tests := []struct {
+		name    string
+		rawJSON string
+		want    string
+	}{
+		{"pasta", `{"host":{"rootlessNetworkCmd":"pasta","pasta":{"executable":"/usr/bin/pasta"}}}`, "pasta"},
+		{"slirp4netns", `{"host":{"rootlessNetworkCmd":"slirp4netns","slirp4netns":{"executable":"/usr/bin/slirp4netns"}}}`, "slirp4netns"},
+		{"podman 4", `{"host":{"slirp4netns":{"executable":"/usr/bin/slirp4netns"}}}`, "slirp4netns"},
+		{"missing", `{"host":{"rootlessNetworkCmd":"pasta","pasta":{"executable":""}}}`, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			daemonResponseMock = tc.rawJSON
+			podmanInfoGetter = daemonInfoGetterMock
+			si, err := DaemonInfo(Podman)
+			if err != nil {
+				t.Fatalf("DaemonInfo: %v", err)
+			}
+			if si.PortForwarder != tc.want {
+				t.Errorf("PortForwarder = %q, want %q", si.PortForwarder, tc.want)
+			}
+		})
+	}
+}
@@ -61,6 +61,7 @@ type CreateParams struct {
 	OCIBinary     string            // docker or podman
 	Network       string            // network name that the container will attach to
 	IP            string            // static IP to assign the container in the cluster network
+	IPv6          string            // static IPv6 address to assign the container in the cluster network, if IPv6 is enabled on it
 	GPUs          string            // add NVIDIA GPU devices to the container
 }
 
@@ -22,6 +22,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
 	"strings"
@@ -131,7 +132,7 @@ func allVolumesByLabel(ociBin string, label string) ([]string, error) {
 // ExtractTarballToVolume runs a docker image imageName which extracts the tarball at tarballPath
 // to the volume named volumeName
 func ExtractTarballToVolume(ociBin string, tarballPath, volumeName, imageName string) error {
-	cmdArgs := []string{"run", "--rm", "--entrypoint", "/usr/bin/tar"}
+	cmdArgs := []string{"run", "--rm"}
 	// Podman:
 	// when selinux setenforce is enforced, normal mount will lead to file permissions error (-?????????)
 	// - option 1: label the file as container private (mount option :Z), but will alter the file in the host machine
@@ -139,7 +140,22 @@ func ExtractTarballToVolume(ociBin string, tarballPath, volumeName, imageName st
 	if ociBin == Podman && runtime.GOOS == "linux" {
 		cmdArgs = append(cmdArgs, "--security-opt", "label=disable")
 	}
-	cmdArgs = append(cmdArgs, "-v", fmt.Sprintf("%s:/preloaded.tar:ro", tarballPath), "-v", fmt.Sprintf("%s:/extractDir", volumeName), imageName, "-I", "lz4", "-xf", "/preloaded.tar", "-C", "/extractDir")
+	if IsExternalDaemonHost(ociBin) {
+		// the tarball is not on the remote daemon host, stream it to the container instead of mounting it
+		f, err := os.Open(tarballPath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		cmdArgs = append(cmdArgs, "-i", "-v", fmt.Sprintf("%s:/extractDir", volumeName))
+		cmdArgs = append(cmdArgs, extractArgs(tarballPath, imageName, "-")...)
+		cmd := exec.Command(ociBin, cmdArgs...)
+		cmd.Stdin = f
+		_, err = runCmd(cmd)
+		return err
+	}
+	cmdArgs = append(cmdArgs, "-v", fmt.Sprintf("%s:/preloaded.tar:ro", tarballPath), "-v", fmt.Sprintf("%s:/extractDir", volumeName))
+	cmdArgs = append(cmdArgs, extractArgs(tarballPath, imageName, "/preloaded.tar")...)
 	cmd := exec.Command(ociBin, cmdArgs...)
 	if _, err := runCmd(cmd); err != nil {
 		return err
@@ -147,6 +163,17 @@ func ExtractTarballToVolume(ociBin string, tarballPath, volumeName, imageName st
 	return nil
 }
 
+// extractArgs returns the arguments of the run of imageName extracting the tarball at tarballPath, read from src, to /extractDir.
+// The tarballs of older versions are compressed with lz4, pzstd decompresses the zstd ones in parallel,
+// and zstd on the images without it, as the nodes do.
+func extractArgs(tarballPath, imageName, src string) []string {
+	if strings.HasSuffix(tarballPath, ".lz4") {
+		return []string{"--entrypoint", "/usr/bin/tar", imageName, "-I", "lz4", "-xf", src, "-C", "/extractDir"}
+	}
+	script := fmt.Sprintf("d=zstd; if command -v pzstd >/dev/null; then d=pzstd; fi; exec tar -I $d -xf %s -C /extractDir", src)
+	return []string{"--entrypoint", "/bin/sh", imageName, "-c", script}
+}
+
 // createVolume creates a volume to be attached to the container with correct labels and prefixes based on profile name
 // Caution ! if volume already exists does NOT return an error and will not apply the minikube labels on it.
 // TODO: this should be fixed as a part of https://github.com/kubernetes/minikube/issues/6530
@@ -0,0 +1,33 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oci
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractArgs(t *testing.T) {
+	got := strings.Join(extractArgs("/cache/preloaded-images-k8s-v18-v1.28.3-docker-overlay2-amd64.tar.lz4", "kicbase", "/preloaded.tar"), " ")
+	if want := "--entrypoint /usr/bin/tar kicbase -I lz4 -xf /preloaded.tar -C /extractDir"; got != want {
+		t.Errorf("extractArgs(lz4) = %q, want %q", got, want)
+	}
+	args := extractArgs("/cache/preloaded-images-k8s-v20-v1.30.0-docker-overlay2-amd64.tar.zst", "kicbase", "-")
+	if len(args) != 5 || args[1] != "/bin/sh" || !strings.Contains(args[4], "command -v pzstd") || !strings.Contains(args[4], "tar -I $d -xf - -C /extractDir") {
+		t.Errorf("extractArgs(zst) = %q, want a fallback from pzstd to zstd", args)
+	}
+}
@@ -64,10 +64,14 @@ type Config struct {
 	Envs              map[string]string // key,value of environment variables passed to the node
 	KubernetesVersion string            // Kubernetes version to install
 	ContainerRuntime  string            // container runtime kic is running
+	PreloadFrom       string            // preload tarball of the cluster replacing the one of minikube, if set
 	Network           string            // network to run with kic
 	Subnet            string            // subnet to be used on kic cluster
 	StaticIP          string            // static IP for the kic cluster
+	IPv6              bool              // enable IPv6 on the cluster network, the node gets the IPv6 address paired with its IPv4 one
+	ExtraNetworks     []string          // additional networks the container is connected to
 	ExtraArgs         []string          // a list of any extra option to pass to oci binary during creation time, for example --expose 8080...
 	ListenAddress     string            // IP Address to listen to
+	RegistryPort      int               // port of the host the registry addon is published on, 0 for a random one
 	GPUs              string            // add NVIDIA GPU devices to the container
 }
@@ -38,6 +38,12 @@ const domainTmpl = `
   {{.NUMANodeXML}}
   {{end}}
   </cpu>
+  {{if .VirtiofsDir}}
+  <memoryBacking>
+    <source type='memfd'/>
+    <access mode='shared'/>
+  </memoryBacking>
+  {{end}}
   <os>
     <type machine='virt-4.2' arch='aarch64'>hvm</type>
     <loader readonly='yes' type='pflash'>/usr/share/AAVMF/AAVMF_CODE.fd</loader>
@@ -65,6 +71,12 @@ const domainTmpl = `
       <source network='{{.Network}}'/>
       <model type='virtio'/>
     </interface>
+    {{range .ExtraInterfaces}}
+    <interface type='{{.Type}}'>
+      <source {{.Type}}='{{.Source}}'/>
+      <model type='virtio'/>
+    </interface>
+    {{end}}
     <serial type='pty'>
       <target port='0'/>
     </serial>
@@ -80,6 +92,9 @@ const domainTmpl = `
     {{if gt .ExtraDisks 0}}
     {{.ExtraDisksXML}}
     {{end}}
+    {{if .VirtiofsDir}}
+    {{.VirtiofsXML}}
+    {{end}}
   </devices>
 </domain>
 `
@@ -38,6 +38,12 @@ const domainTmpl = `
   {{.NUMANodeXML}}
   {{end}}
   </cpu>
+  {{if .VirtiofsDir}}
+  <memoryBacking>
+    <source type='memfd'/>
+    <access mode='shared'/>
+  </memoryBacking>
+  {{end}}
   <os>
     <type>hvm</type>
     <boot dev='cdrom'/>
@@ -63,6 +69,12 @@ const domainTmpl = `
       <source network='{{.Network}}'/>
       <model type='virtio'/>
     </interface>
+    {{range .ExtraInterfaces}}
+    <interface type='{{.Type}}'>
+      <source {{.Type}}='{{.Source}}'/>
+      <model type='virtio'/>
+    </interface>
+    {{end}}
     <serial type='pty'>
       <target port='0'/>
     </serial>
@@ -78,6 +90,9 @@ const domainTmpl = `
     {{if gt .ExtraDisks 0}}
     {{.ExtraDisksXML}}
     {{end}}
+    {{if .VirtiofsDir}}
+    {{.VirtiofsXML}}
+    {{end}}
   </devices>
 </domain>
 `
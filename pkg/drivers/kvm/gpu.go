@@ -60,14 +60,20 @@ type PCIDevice struct {
 }
 
 // getDevicesXML returns the XML that can be added to the libvirt domain XML to
-// passthrough NVIDIA devices.
-func getDevicesXML() (string, error) {
-	unboundNVIDIADevices, err := getPassthroughableNVIDIADevices()
+// passthrough the selected devices, or all the NVIDIA devices if none is selected.
+func getDevicesXML(selected []string) (string, error) {
+	var devices []string
+	var err error
+	if len(selected) == 0 {
+		devices, err = getPassthroughableNVIDIADevices()
+	} else {
+		devices, err = getPassthroughableDevices(selected)
+	}
 	if err != nil {
 		return "", fmt.Errorf("couldn't generate devices XML: %v", err)
 	}
 	var pciDevices []PCIDevice
-	for _, device := range unboundNVIDIADevices {
+	for _, device := range devices {
 		splits := strings.Split(device, ":")
 		if len(splits) != 3 {
 			log.Infof("Error while parsing PCI device %q. Not splittable into domain:bus:slot.function.", device)
@@ -97,6 +103,40 @@ func getDevicesXML() (string, error) {
 	return devicesXML.String(), nil
 }
 
+// checkIOMMU returns an error if the host doesn't support pci passthrough (IOMMU).
+func checkIOMMU() error {
+	iommuGroups, err := os.ReadDir(sysKernelIOMMUGroupsPath)
+	if err != nil {
+		return fmt.Errorf("error reading %q: %v", sysKernelIOMMUGroupsPath, err)
+	}
+	if len(iommuGroups) == 0 {
+		return fmt.Errorf("no IOMMU groups found at %q. Make sure your host supports IOMMU. See instructions at https://minikube.sigs.k8s.io/docs/tutorials/nvidia_gpu/", sysKernelIOMMUGroupsPath)
+	}
+	return nil
+}
+
+// getPassthroughableDevices checks that the selected devices (like 0000:03:00.1) can be
+// passthrough from the host to a VM. It returns an error if:
+// - host doesn't support pci passthrough (IOMMU).
+// - a device doesn't exist, is bound to a host driver or shares its IOMMU group with a bound device.
+func getPassthroughableDevices(selected []string) ([]string, error) {
+	if err := checkIOMMU(); err != nil {
+		return []string{}, err
+	}
+	for _, device := range selected {
+		if _, err := os.Stat(filepath.Join(sysFsPCIDevicesPath, device)); err != nil {
+			return []string{}, fmt.Errorf("PCI device %s not found: %v", device, err)
+		}
+		if !isUnbound(device) {
+			return []string{}, fmt.Errorf("PCI device %s is bound to a host driver, bind it to vfio-pci to passthrough it. See instructions at https://minikube.sigs.k8s.io/docs/tutorials/nvidia_gpu/", device)
+		}
+		if !isIsolated(device) {
+			return []string{}, fmt.Errorf("PCI device %s has other devices in its IOMMU group that are bound to a host driver. See instructions at https://minikube.sigs.k8s.io/docs/tutorials/nvidia_gpu/", device)
+		}
+	}
+	return selected, nil
+}
+
 // getPassthroughableNVIDIADevices returns a list of NVIDIA devices that can be
 // passthrough from the host to a VM. It returns an error if:
 // - host doesn't support pci passthrough (IOMMU).
@@ -104,12 +144,8 @@ func getDevicesXML() (string, error) {
 func getPassthroughableNVIDIADevices() ([]string, error) {
 
 	// Make sure the host supports IOMMU
-	iommuGroups, err := os.ReadDir(sysKernelIOMMUGroupsPath)
-	if err != nil {
-		return []string{}, fmt.Errorf("error reading %q: %v", sysKernelIOMMUGroupsPath, err)
-	}
-	if len(iommuGroups) == 0 {
-		return []string{}, fmt.Errorf("no IOMMU groups found at %q. Make sure your host supports IOMMU. See instructions at https://minikube.sigs.k8s.io/docs/tutorials/nvidia_gpu/", sysKernelIOMMUGroupsPath)
+	if err := checkIOMMU(); err != nil {
+		return []string{}, err
 	}
 
 	// Get list of PCI devices
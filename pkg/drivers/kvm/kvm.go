@@ -74,6 +74,9 @@ type Driver struct {
 	// Whether to passthrough GPU devices from the host to the VM.
 	GPU bool
 
+	// PCI addresses of the devices to passthrough, all the NVIDIA GPUs if empty.
+	GPUDevices []string
+
 	// Whether to hide the KVM hypervisor signature from the guest
 	Hidden bool
 
@@ -94,6 +97,24 @@ type Driver struct {
 
 	// Extra Disks XML
 	ExtraDisksXML []string
+
+	// StaticIP is reserved for the VM on the private network, if set
+	StaticIP string
+
+	// IPv6 enables the IPv6 subnet paired with the IPv4 one on the private network
+	IPv6 bool
+
+	// ExtraNetworks are the libvirt networks or host bridges of the additional NICs of the VM
+	ExtraNetworks []string
+
+	// ExtraInterfaces are the additional NICs of the VM, resolved from ExtraNetworks on creation
+	ExtraInterfaces []ExtraInterface
+
+	// VirtiofsDir is the host directory shared with the guest through virtiofs, if set
+	VirtiofsDir string
+
+	// Virtiofs XML
+	VirtiofsXML string
 }
 
 const (
@@ -275,6 +296,13 @@ func (d *Driver) Start() (err error) {
 		}
 	}()
 
+	if d.StaticIP != "" {
+		log.Infof("Reserving static IP address %s...", d.StaticIP)
+		if err := addStaticIP(conn, d.PrivateNetwork, d.MachineName, d.PrivateMAC, d.StaticIP); err != nil {
+			return errors.Wrapf(err, "reserving static IP %s", d.StaticIP)
+		}
+	}
+
 	log.Info("Creating domain...")
 	if err := dom.Create(); err != nil {
 		return errors.Wrap(err, "error creating VM")
@@ -330,9 +358,12 @@ func (d *Driver) Create() (err error) {
 	if err != nil {
 		return errors.Wrap(err, "creating network")
 	}
+	if err := d.createExtraNetworks(); err != nil {
+		return errors.Wrap(err, "creating extra networks")
+	}
 	if d.GPU {
 		log.Info("Creating devices...")
-		d.DevicesXML, err = getDevicesXML()
+		d.DevicesXML, err = getDevicesXML(d.GPUDevices)
 		if err != nil {
 			return errors.Wrap(err, "creating devices")
 		}
@@ -378,6 +409,13 @@ func (d *Driver) Create() (err error) {
 		d.ExtraDisksXML = append(d.ExtraDisksXML, extraDisksXML)
 	}
 
+	if d.VirtiofsDir != "" {
+		d.VirtiofsXML, err = getVirtiofsXML(d.VirtiofsDir, pkgdrivers.VirtiofsTag)
+		if err != nil {
+			return errors.Wrap(err, "creating virtiofs XML")
+		}
+	}
+
 	if err := ensureDirPermissions(store); err != nil {
 		log.Errorf("unable to ensure permissions on %s: %v", store, err)
 	}
@@ -22,6 +22,9 @@ import (
 	"bytes"
 	"encoding/xml"
 	"fmt"
+	"net"
+	"os"
+	"path/filepath"
 	"text/template"
 	"time"
 
@@ -41,16 +44,22 @@ const networkTmpl = `
   {{with .Parameters}}
   <ip address='{{.Gateway}}' netmask='{{.Netmask}}'>
     <dhcp>
-      <range start='{{.ClientMin}}' end='{{.ClientMax}}'/>
+      <range start='{{.ClientMin}}' end='{{.DHCPMax}}'/>
     </dhcp>
   </ip>
   {{end}}
+  {{if .IPv6Gateway}}
+  <ip family='ipv6' address='{{.IPv6Gateway}}' prefix='{{.IPv6Prefix}}'/>
+  {{end}}
 </network>
 `
 
 type kvmNetwork struct {
 	Name string
 	network.Parameters
+	// IPv6Gateway is the address of the host on the IPv6 subnet paired with the IPv4 one, whose addresses the VMs configure statically
+	IPv6Gateway string
+	IPv6Prefix  int
 }
 
 type kvmIface struct {
@@ -148,6 +157,17 @@ func (d *Driver) ensureNetwork() error {
 		log.Debugf("Successfully activated %s network", d.PrivateNetwork)
 	}
 
+	// networks: extra
+	for _, iface := range d.ExtraInterfaces {
+		if iface.Type != "network" {
+			continue
+		}
+		log.Infof("Ensuring network %s is active", iface.Source)
+		if err := setupNetwork(conn, iface.Source); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -184,45 +204,135 @@ func (d *Driver) createNetwork() error {
 	}()
 	if err == nil {
 		log.Debugf("found existing private KVM network %s", d.PrivateNetwork)
+		if d.StaticIP != "" {
+			return checkStaticIPInNetwork(netp, d.StaticIP)
+		}
 		return nil
 	}
 
+	// the network of a static IP is its /24, do not look further if it is taken
+	startAddr, tries := firstSubnetAddr, 20
+	if d.StaticIP != "" {
+		startAddr, tries = d.StaticIP, 1
+	}
+	return createSubnetNetwork(conn, d.PrivateNetwork, startAddr, tries, d.IPv6)
+}
+
+// createSubnetNetwork creates and starts an isolated network, with DHCP on the first free subnet from startAddr,
+// and with ipv6 the IPv6 subnet paired with it
+func createSubnetNetwork(conn *libvirt.Connect, name, startAddr string, tries int, ipv6 bool) error {
+	var err error
 	// retry up to 5 times to create kvm network
-	for attempts, subnetAddr := 0, firstSubnetAddr; attempts < 5; attempts++ {
+	for attempts, subnetAddr := 0, startAddr; attempts < 5; attempts++ {
 		// Rather than iterate through all of the valid subnets, give up at 20 to avoid a lengthy user delay for something that is unlikely to work.
 		// will be like 192.168.39.0/24,..., 192.168.248.0/24 (in increment steps of 11)
 		var subnet *network.Parameters
-		subnet, err = network.FreeSubnet(subnetAddr, 11, 20)
+		subnet, err = network.FreeSubnet(subnetAddr, 11, tries)
 		if err != nil {
-			log.Debugf("failed to find free subnet for private KVM network %s after %d attempts: %v", d.PrivateNetwork, 20, err)
+			log.Debugf("failed to find free subnet for KVM network %s after %d attempts: %v", name, tries, err)
 			return fmt.Errorf("un-retryable: %w", err)
 		}
-		// create the XML for the private network from our networkTmpl
+		// create the XML for the network from our networkTmpl
 		tryNet := kvmNetwork{
-			Name:       d.PrivateNetwork,
+			Name:       name,
 			Parameters: *subnet,
 		}
+		if ipv6 {
+			if tryNet.IPv6Gateway, err = network.IPv6Of(subnet.Gateway); err != nil {
+				return fmt.Errorf("un-retryable: %w", err)
+			}
+			tryNet.IPv6Prefix = network.IPv6Prefix
+		}
 		tmpl := template.Must(template.New("network").Parse(networkTmpl))
 		var networkXML bytes.Buffer
 		if err = tmpl.Execute(&networkXML, tryNet); err != nil {
-			return fmt.Errorf("executing private KVM network template: %w", err)
+			return fmt.Errorf("executing KVM network template: %w", err)
 		}
 		// define the network using our template
 		var network *libvirt.Network
 		network, err = conn.NetworkDefineXML(networkXML.String())
 		if err != nil {
-			return fmt.Errorf("defining private KVM network %s %s from xml %s: %w", d.PrivateNetwork, subnet.CIDR, networkXML.String(), err)
+			return fmt.Errorf("defining KVM network %s %s from xml %s: %w", name, subnet.CIDR, networkXML.String(), err)
 		}
 		// and finally create & start it
-		log.Debugf("trying to create private KVM network %s %s...", d.PrivateNetwork, subnet.CIDR)
+		log.Debugf("trying to create KVM network %s %s...", name, subnet.CIDR)
 		if err = network.Create(); err == nil {
-			log.Debugf("private KVM network %s %s created", d.PrivateNetwork, subnet.CIDR)
+			log.Debugf("KVM network %s %s created", name, subnet.CIDR)
 			return nil
 		}
-		log.Debugf("failed to create private KVM network %s %s, will retry: %v", d.PrivateNetwork, subnet.CIDR, err)
+		log.Debugf("failed to create KVM network %s %s, will retry: %v", name, subnet.CIDR, err)
 		subnetAddr = subnet.IP
 	}
-	return fmt.Errorf("failed to create private KVM network %s: %w", d.PrivateNetwork, err)
+	return fmt.Errorf("failed to create KVM network %s: %w", name, err)
+}
+
+// ExtraInterface is an additional NIC of the VM
+type ExtraInterface struct {
+	// Type is the libvirt interface type, "network" or "bridge"
+	Type string
+	// Source is the name of the libvirt network or host bridge
+	Source string
+}
+
+// createExtraNetworks resolves the extra networks to the interfaces of the VM:
+// an existing libvirt network, else a bridge of the host, else a new isolated libvirt network.
+// The networks are shared by the nodes and clusters attached to them, so they are not deleted with the VM.
+func (d *Driver) createExtraNetworks() error {
+	if len(d.ExtraNetworks) == 0 {
+		return nil
+	}
+	conn, err := getConnection(d.ConnectionURI)
+	if err != nil {
+		return errors.Wrap(err, "getting libvirt connection")
+	}
+	defer conn.Close()
+
+	d.ExtraInterfaces = nil
+	for _, name := range d.ExtraNetworks {
+		if name == d.Network || name == d.PrivateNetwork {
+			return fmt.Errorf("extra network %s is already attached to the VM", name)
+		}
+		iface := ExtraInterface{Type: "network", Source: name}
+		if n, err := conn.LookupNetworkByName(name); err == nil {
+			_ = n.Free()
+			log.Debugf("found existing KVM network %s", name)
+		} else if _, err := os.Stat(filepath.Join("/sys/class/net", name, "bridge")); err == nil {
+			log.Debugf("attaching host bridge %s", name)
+			iface.Type = "bridge"
+		} else if err := createSubnetNetwork(conn, name, firstSubnetAddr, 20, false); err != nil {
+			return errors.Wrapf(err, "creating extra network %s", name)
+		}
+		d.ExtraInterfaces = append(d.ExtraInterfaces, iface)
+	}
+	return nil
+}
+
+// checkStaticIPInNetwork returns an error if the static IP is not in the subnet of an existing network
+func checkStaticIPInNetwork(n *libvirt.Network, staticIP string) error {
+	xmldoc, err := n.GetXMLDesc(0)
+	if err != nil {
+		return errors.Wrap(err, "getting network XML")
+	}
+	var nd struct {
+		Name string `xml:"name"`
+		IP   struct {
+			Address string `xml:"address,attr"`
+			Netmask string `xml:"netmask,attr"`
+			Prefix  int    `xml:"prefix,attr"`
+		} `xml:"ip"`
+	}
+	if err := xml.Unmarshal([]byte(xmldoc), &nd); err != nil {
+		return errors.Wrap(err, "parsing network XML")
+	}
+	mask := net.IPMask(net.ParseIP(nd.IP.Netmask).To4())
+	if nd.IP.Netmask == "" {
+		mask = net.CIDRMask(nd.IP.Prefix, 32)
+	}
+	subnet := &net.IPNet{IP: net.ParseIP(nd.IP.Address).Mask(mask), Mask: mask}
+	if !subnet.Contains(net.ParseIP(staticIP)) {
+		return fmt.Errorf("static IP %s is not in the subnet %s of the existing KVM network %s, delete the network or choose another IP", staticIP, subnet, nd.Name)
+	}
+	return nil
 }
 
 func (d *Driver) deleteNetwork() error {
@@ -0,0 +1,46 @@
+//go:build linux
+
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kvm
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+// virtiofsTmpl is the XML of the virtiofs share of a host directory, libvirt running virtiofsd for it.
+// The guest memory must be shared with virtiofsd, see the memoryBacking of the domain.
+const virtiofsTmpl = `
+<filesystem type='mount' accessmode='passthrough'>
+  <driver type='virtiofs' queue='1024'/>
+  <source dir='{{.Dir}}'/>
+  <target dir='{{.Tag}}'/>
+</filesystem>
+`
+
+// getVirtiofsXML returns the XML that can be added to the libvirt domain XML
+// to share dir with the guest under tag
+func getVirtiofsXML(dir string, tag string) (string, error) {
+	tmpl := template.Must(template.New("").Parse(virtiofsTmpl))
+	var virtiofsXML bytes.Buffer
+	if err := tmpl.Execute(&virtiofsXML, struct{ Dir, Tag string }{dir, tag}); err != nil {
+		return "", fmt.Errorf("couldn't generate virtiofs XML: %v", err)
+	}
+	return virtiofsXML.String(), nil
+}
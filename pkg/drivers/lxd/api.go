@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lxd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// response is the envelope of the responses of the LXD API
+type response struct {
+	Type       string          `json:"type"`
+	StatusCode int             `json:"status_code"`
+	ErrorCode  int             `json:"error_code"`
+	Error      string          `json:"error"`
+	Operation  string          `json:"operation"`
+	Metadata   json.RawMessage `json:"metadata"`
+}
+
+// operation is the metadata of a background operation of LXD
+type operation struct {
+	StatusCode int                    `json:"status_code"`
+	Err        string                 `json:"err"`
+	Metadata   map[string]interface{} `json:"metadata"`
+}
+
+// statusError is an error returned by the LXD API
+type statusError struct {
+	Code    int
+	Message string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("LXD API: %s (%d)", e.Message, e.Code)
+}
+
+// isNotFound returns whether err is a 404 of the LXD API
+func isNotFound(err error) bool {
+	var se *statusError
+	return errors.As(err, &se) && se.Code == http.StatusNotFound
+}
+
+// SocketPath returns the path of the unix socket of the LXD daemon, honoring LXD_SOCKET and LXD_DIR
+func SocketPath() string {
+	if s := os.Getenv("LXD_SOCKET"); s != "" {
+		return s
+	}
+	if dir := os.Getenv("LXD_DIR"); dir != "" {
+		return filepath.Join(dir, "unix.socket")
+	}
+	// the snap is the most common way LXD is installed
+	snap := "/var/snap/lxd/common/lxd/unix.socket"
+	if _, err := os.Stat(snap); err == nil {
+		return snap
+	}
+	return "/var/lib/lxd/unix.socket"
+}
+
+// client calls the LXD API on its unix socket
+type client struct {
+	socket string
+	http   *http.Client
+}
+
+func newClient(socket string) *client {
+	return &client{
+		socket: socket,
+		http: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}
+}
+
+// Ping checks that the LXD daemon listening on socket is up, and trusts the user
+func Ping(socket string) error {
+	var server struct {
+		Auth string `json:"auth"`
+	}
+	if err := newClient(socket).query(http.MethodGet, "/1.0", nil, &server); err != nil {
+		return err
+	}
+	if server.Auth != "trusted" {
+		return fmt.Errorf("the LXD daemon on %s does not trust the user", socket)
+	}
+	return nil
+}
+
+// query calls the API with a JSON body, and decodes the metadata of a sync response into resp
+func (c *client) query(method, path string, body interface{}, resp interface{}) error {
+	var b bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&b).Encode(body); err != nil {
+			return err
+		}
+	}
+	r, err := c.do(method, path, &b, map[string]string{"Content-Type": "application/json"})
+	if err != nil {
+		return err
+	}
+	if r.Type == "async" {
+		_, err := c.wait(r.Operation)
+		return err
+	}
+	if resp == nil || len(r.Metadata) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.Metadata, resp)
+}
+
+// do calls the API, and returns its response unless it is an error
+func (c *client) do(method, path string, body io.Reader, headers map[string]string) (*response, error) {
+	req, err := http.NewRequest(method, "http://lxd"+path, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "LXD API on %s", c.socket)
+	}
+	defer resp.Body.Close()
+
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, errors.Wrapf(err, "decoding response of %s %s", method, path)
+	}
+	if r.Type == "error" {
+		return nil, &statusError{Code: r.ErrorCode, Message: r.Error}
+	}
+	return &r, nil
+}
+
+// wait waits for the background operation op to end, and returns it if it succeeded
+func (c *client) wait(op string) (*operation, error) {
+	r, err := c.do(http.MethodGet, op+"/wait?timeout=600", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	var o operation
+	if err := json.Unmarshal(r.Metadata, &o); err != nil {
+		return nil, err
+	}
+	if o.StatusCode >= 400 {
+		return nil, &statusError{Code: o.StatusCode, Message: o.Err}
+	}
+	return &o, nil
+}
+
+// setState runs a state change like start or stop on the instance
+func (c *client) setState(name, action string, force bool) error {
+	body := map[string]interface{}{
+		"action":  action,
+		"timeout": 60,
+		"force":   force,
+	}
+	return c.query(http.MethodPut, "/1.0/instances/"+name+"/state", body, nil)
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lxd
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+)
+
+// ensureImage imports the container image ref into LXD, unless it already was, and returns its alias.
+// LXD does not run OCI images, so the image is flattened into the rootfs of an LXD unified tarball.
+func (c *client) ensureImage(ref string) (string, error) {
+	r, err := name.ParseReference(ref)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing %s", ref)
+	}
+	p := v1.Platform{OS: "linux", Architecture: runtime.GOARCH}
+	img, err := remote.Image(r, remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithPlatform(p))
+	if err != nil {
+		return "", errors.Wrapf(err, "fetching %s", ref)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return "", err
+	}
+	alias := imageAlias(digest)
+	if err := c.query(http.MethodGet, "/1.0/images/aliases/"+alias, nil, nil); err == nil {
+		return alias, nil
+	} else if !isNotFound(err) {
+		return "", err
+	}
+
+	log.Infof("Importing %s into LXD as %s...", ref, alias)
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeUnifiedTarball(pw, img, ref))
+	}()
+	resp, err := c.do(http.MethodPost, "/1.0/images", pr, map[string]string{"Content-Type": "application/octet-stream"})
+	// unblock the writer if LXD did not read the whole tarball
+	pr.Close()
+	if err != nil {
+		return "", errors.Wrap(err, "importing image")
+	}
+	op, err := c.wait(resp.Operation)
+	if err != nil {
+		return "", errors.Wrap(err, "importing image")
+	}
+	fingerprint, ok := op.Metadata["fingerprint"].(string)
+	if !ok {
+		return "", fmt.Errorf("LXD did not return the fingerprint of the imported image")
+	}
+	body := map[string]string{"name": alias, "target": fingerprint}
+	if err := c.query(http.MethodPost, "/1.0/images/aliases", body, nil); err != nil {
+		return "", errors.Wrap(err, "creating image alias")
+	}
+	return alias, nil
+}
+
+// imageAlias returns the LXD alias of the image with the digest, which is its identity
+func imageAlias(digest v1.Hash) string {
+	return "minikube-kicbase-" + digest.Hex[:12]
+}
+
+// writeUnifiedTarball writes the LXD unified tarball of img: its metadata.yaml, and the flattened layers of img under rootfs/
+func writeUnifiedTarball(w io.Writer, img v1.Image, ref string) error {
+	tw := tar.NewWriter(w)
+	metadata := fmt.Sprintf("architecture: %s\ncreation_date: %d\nproperties:\n  os: ubuntu\n  description: %q\n",
+		lxdArchitecture(runtime.GOARCH), time.Now().Unix(), "minikube "+ref)
+	if err := tw.WriteHeader(&tar.Header{Name: "metadata.yaml", Mode: 0644, Size: int64(len(metadata)), Typeflag: tar.TypeReg, ModTime: time.Now()}); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(tw, metadata); err != nil {
+		return err
+	}
+
+	fs := mutate.Extract(img)
+	defer fs.Close()
+	tr := tar.NewReader(fs)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "reading image filesystem")
+		}
+		hdr.Name = "rootfs/" + hdr.Name
+		if hdr.Typeflag == tar.TypeLink {
+			hdr.Linkname = "rootfs/" + hdr.Linkname
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// lxdArchitecture returns the name LXD gives to a GOARCH
+func lxdArchitecture(arch string) string {
+	switch arch {
+	case "amd64":
+		return "x86_64"
+	case "arm64":
+		return "aarch64"
+	case "ppc64le":
+		return "ppc64le"
+	case "s390x":
+		return "s390x"
+	}
+	return arch
+}
@@ -0,0 +1,316 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lxd implements a driver running the minikube node as an LXD system container
+// (https://canonical.com/lxd) of the kic base image, for hosts where Docker is not allowed.
+package lxd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/ssh"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+
+	pkgdrivers "k8s.io/minikube/pkg/drivers"
+)
+
+const (
+	defaultSSHUser = "docker"
+
+	// DriverName is the name of the driver
+	DriverName = "lxd"
+)
+
+// kernelModules are the modules the node needs, which LXD loads on the host before starting the container
+var kernelModules = []string{"ip_tables", "ip6_tables", "iptable_nat", "iptable_filter", "nf_nat", "br_netfilter", "overlay", "netlink_diag"}
+
+// Driver is the lxd driver
+type Driver struct {
+	*drivers.BaseDriver
+	*pkgdrivers.CommonDriver
+	// Image is the kic base image the container is created from
+	Image  string
+	Memory int
+	CPU    int
+	// Socket is the path of the unix socket of the LXD daemon
+	Socket string
+}
+
+// NewDriver creates a new lxd driver
+func NewDriver(hostName, storePath string) drivers.Driver {
+	return &Driver{
+		Socket: SocketPath(),
+		BaseDriver: &drivers.BaseDriver{
+			SSHUser:     defaultSSHUser,
+			MachineName: hostName,
+			StorePath:   storePath,
+		},
+	}
+}
+
+// DriverName returns the name of the driver
+func (d *Driver) DriverName() string {
+	return DriverName
+}
+
+// GetSSHHostname returns hostname for use with ssh
+func (d *Driver) GetSSHHostname() (string, error) {
+	return d.GetIP()
+}
+
+// GetSSHKeyPath returns the path of the SSH key of the machine
+func (d *Driver) GetSSHKeyPath() string {
+	return d.ResolveStorePath("id_rsa")
+}
+
+// GetSSHUsername returns the user name for SSH
+func (d *Driver) GetSSHUsername() string {
+	if d.SSHUser == "" {
+		d.SSHUser = defaultSSHUser
+	}
+	return d.SSHUser
+}
+
+// GetURL returns a Docker URL inside this host
+func (d *Driver) GetURL() (string, error) {
+	ip, err := d.GetIP()
+	if err != nil || ip == "" {
+		return "", err
+	}
+	return fmt.Sprintf("tcp://%s:2376", ip), nil
+}
+
+// GetIP returns the IPv4 address of the container on the LXD bridge
+func (d *Driver) GetIP() (string, error) {
+	if d.IPAddress != "" {
+		return d.IPAddress, nil
+	}
+	var st instanceState
+	if err := d.client().query(http.MethodGet, "/1.0/instances/"+d.MachineName+"/state", nil, &st); err != nil {
+		return "", err
+	}
+	return st.ipv4(), nil
+}
+
+// PreCreateCheck checks that the LXD daemon is reachable and trusts the user
+func (d *Driver) PreCreateCheck() error {
+	return Ping(d.Socket)
+}
+
+// Create imports the kic base image, creates the container with SSH access and starts it
+func (d *Driver) Create() error {
+	c := d.client()
+	alias, err := c.ensureImage(d.Image)
+	if err != nil {
+		return errors.Wrap(err, "import base image")
+	}
+
+	log.Infof("Creating LXD container %s...", d.MachineName)
+	body := map[string]interface{}{
+		"name":     d.MachineName,
+		"type":     "container",
+		"source":   map[string]string{"type": "image", "alias": alias},
+		"profiles": []string{"default"},
+		"config":   d.instanceConfig(),
+		"devices":  instanceDevices(),
+	}
+	if err := c.query(http.MethodPost, "/1.0/instances", body, nil); err != nil {
+		return errors.Wrap(err, "create container")
+	}
+
+	if err := ssh.GenerateSSHKey(d.GetSSHKeyPath()); err != nil {
+		return errors.Wrap(err, "generate ssh key")
+	}
+	pub, err := os.ReadFile(d.GetSSHKeyPath() + ".pub")
+	if err != nil {
+		return err
+	}
+	// sshd accepts an authorized_keys owned by root, and files can be pushed while the container is stopped
+	headers := map[string]string{"X-LXD-uid": "0", "X-LXD-gid": "0", "X-LXD-mode": "0644", "X-LXD-type": "file", "Content-Type": "application/octet-stream"}
+	if _, err := c.do(http.MethodPost, "/1.0/instances/"+d.MachineName+"/files?path=/home/docker/.ssh/authorized_keys", strings.NewReader(string(pub)), headers); err != nil {
+		return errors.Wrap(err, "copy ssh key")
+	}
+	return d.Start()
+}
+
+// instanceConfig returns the config of the container, which mirrors the privileges the kic driver gives to its containers
+func (d *Driver) instanceConfig() map[string]string {
+	rawLXC := []string{
+		"lxc.apparmor.profile=unconfined",
+		"lxc.cap.drop=",
+		"lxc.cgroup.devices.allow=a",
+		"lxc.cgroup2.devices.allow=a",
+		"lxc.mount.auto=proc:rw sys:rw cgroup:rw",
+		// the entrypoint of the kic base image fixes up the container before running systemd
+		"lxc.init.cmd=/usr/local/bin/entrypoint /sbin/init",
+	}
+	cfg := map[string]string{
+		"security.privileged":  "true",
+		"security.nesting":     "true",
+		"linux.kernel_modules": strings.Join(kernelModules, ","),
+		"raw.lxc":              strings.Join(rawLXC, "\n"),
+		"user.minikube":        "true",
+	}
+	if d.CPU > 0 {
+		cfg["limits.cpu"] = strconv.Itoa(d.CPU)
+	}
+	if d.Memory > 0 {
+		cfg["limits.memory"] = fmt.Sprintf("%dMB", d.Memory)
+	}
+	return cfg
+}
+
+// instanceDevices returns the devices added to the ones of the default profile: kubelet reads /dev/kmsg,
+// and the node loads the modules of the host kernel
+func instanceDevices() map[string]map[string]string {
+	return map[string]map[string]string{
+		"kmsg": {
+			"type":   "unix-char",
+			"source": "/dev/kmsg",
+			"path":   "/dev/kmsg",
+		},
+		"modules": {
+			"type":     "disk",
+			"source":   "/lib/modules",
+			"path":     "/lib/modules",
+			"readonly": "true",
+		},
+	}
+}
+
+// Start starts the container, and waits for SSH to be up
+func (d *Driver) Start() error {
+	c := d.client()
+	if err := c.setState(d.MachineName, "start", false); err != nil {
+		return errors.Wrap(err, "start container")
+	}
+
+	d.IPAddress = ""
+	deadline := time.Now().Add(2 * time.Minute)
+	for d.IPAddress == "" {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for the IP of %s", d.MachineName)
+		}
+		time.Sleep(time.Second)
+		var st instanceState
+		if err := c.query(http.MethodGet, "/1.0/instances/"+d.MachineName+"/state", nil, &st); err != nil {
+			return err
+		}
+		d.IPAddress = st.ipv4()
+	}
+	log.Infof("Waiting for container to start (ssh -p 22 docker@%s)...", d.IPAddress)
+	return pkgdrivers.WaitForTCP(net.JoinHostPort(d.IPAddress, "22"), 3*time.Minute)
+}
+
+// instanceState is the state of an LXD instance
+type instanceState struct {
+	Status  string `json:"status"`
+	Network map[string]struct {
+		Addresses []struct {
+			Family  string `json:"family"`
+			Address string `json:"address"`
+			Scope   string `json:"scope"`
+		} `json:"addresses"`
+	} `json:"network"`
+}
+
+// ipv4 returns the global IPv4 address of eth0, which is on the LXD bridge
+func (s instanceState) ipv4() string {
+	for _, a := range s.Network["eth0"].Addresses {
+		if a.Family == "inet" && a.Scope == "global" {
+			return a.Address
+		}
+	}
+	return ""
+}
+
+// GetState returns the state of the container, as reported by LXD
+func (d *Driver) GetState() (state.State, error) {
+	var st instanceState
+	if err := d.client().query(http.MethodGet, "/1.0/instances/"+d.MachineName+"/state", nil, &st); err != nil {
+		if isNotFound(err) {
+			return state.None, nil
+		}
+		return state.Error, err
+	}
+	return containerState(st.Status), nil
+}
+
+// containerState converts a status reported by LXD to a libmachine state
+func containerState(s string) state.State {
+	switch s {
+	case "Running":
+		return state.Running
+	case "Stopped":
+		return state.Stopped
+	case "Frozen":
+		return state.Paused
+	case "Starting":
+		return state.Starting
+	case "Stopping":
+		return state.Stopping
+	case "Error":
+		return state.Error
+	}
+	return state.None
+}
+
+// Stop asks systemd in the container to shut down
+func (d *Driver) Stop() error {
+	d.IPAddress = ""
+	return d.client().setState(d.MachineName, "stop", false)
+}
+
+// Kill stops the container immediately
+func (d *Driver) Kill() error {
+	d.IPAddress = ""
+	return d.client().setState(d.MachineName, "stop", true)
+}
+
+// Remove stops and deletes the container, the image is kept for the next clusters
+func (d *Driver) Remove() error {
+	s, err := d.GetState()
+	if err != nil {
+		return err
+	}
+	if s == state.None {
+		return nil
+	}
+	if s != state.Stopped {
+		if err := d.Kill(); err != nil {
+			return errors.Wrap(err, "kill")
+		}
+	}
+	return d.client().query(http.MethodDelete, "/1.0/instances/"+d.MachineName, nil, nil)
+}
+
+// Restart stops and starts the container
+func (d *Driver) Restart() error {
+	return pkgdrivers.Restart(d)
+}
+
+func (d *Driver) client() *client {
+	return newClient(d.Socket)
+}
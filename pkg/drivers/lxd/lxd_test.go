@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lxd
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+// fakeLXD serves the LXD API on a unix socket, with a single container in the state it is given
+func fakeLXD(t *testing.T, status string) string {
+	// the path of a unix socket is limited to about 100 characters
+	dir, err := os.MkdirTemp("", "lxd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, "unix.socket")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/1.0", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"type": "sync", "status_code": 200, "metadata": {"auth": "trusted"}}`)
+	})
+	mux.HandleFunc("/1.0/instances/minikube/state", func(w http.ResponseWriter, r *http.Request) {
+		if status == "" {
+			fmt.Fprint(w, `{"type": "error", "error_code": 404, "error": "Instance not found"}`)
+			return
+		}
+		if r.Method == http.MethodPut {
+			fmt.Fprint(w, `{"type": "async", "status_code": 100, "operation": "/1.0/operations/stop"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"type": "sync", "status_code": 200, "metadata": {"status": %q, "network": {"eth0": {"addresses": [
+			{"family": "inet6", "address": "fd42::1", "scope": "global"},
+			{"family": "inet", "address": "10.23.4.5", "scope": "global"}]}}}}`, status)
+	})
+	mux.HandleFunc("/1.0/operations/stop/wait", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"type": "sync", "status_code": 200, "metadata": {"status_code": 400, "err": "The instance is already stopped"}}`)
+	})
+	srv := &http.Server{Handler: mux}
+	go func() {
+		_ = srv.Serve(l)
+	}()
+	t.Cleanup(func() { srv.Close() })
+	return socket
+}
+
+func TestDriverAPI(t *testing.T) {
+	d := NewDriver("minikube", "/home/user/.minikube").(*Driver)
+	d.Socket = fakeLXD(t, "Running")
+	if err := d.PreCreateCheck(); err != nil {
+		t.Errorf("PreCreateCheck: %v", err)
+	}
+	if s, err := d.GetState(); err != nil || s != state.Running {
+		t.Errorf("GetState() = %s, %v, want Running", s, err)
+	}
+	if ip, err := d.GetIP(); err != nil || ip != "10.23.4.5" {
+		t.Errorf("GetIP() = %q, %v, want the IPv4 address of eth0", ip, err)
+	}
+	// failed operations are errors
+	if err := d.Stop(); err == nil || !strings.Contains(err.Error(), "already stopped") {
+		t.Errorf("Stop() = %v, want the error of the operation", err)
+	}
+
+	d.Socket = fakeLXD(t, "")
+	if s, err := d.GetState(); err != nil || s != state.None {
+		t.Errorf("GetState() of a missing container = %s, %v, want None", s, err)
+	}
+	if err := d.Remove(); err != nil {
+		t.Errorf("Remove() of a missing container: %v", err)
+	}
+}
+
+func TestInstanceConfig(t *testing.T) {
+	d := NewDriver("minikube", "/home/user/.minikube").(*Driver)
+	d.CPU = 2
+	d.Memory = 4000
+	cfg := d.instanceConfig()
+	if cfg["limits.cpu"] != "2" || cfg["limits.memory"] != "4000MB" {
+		t.Errorf("limits = %s cpu, %s memory, want 2 and 4000MB", cfg["limits.cpu"], cfg["limits.memory"])
+	}
+	if !strings.Contains(cfg["raw.lxc"], "lxc.init.cmd=/usr/local/bin/entrypoint /sbin/init") {
+		t.Errorf("raw.lxc = %q, want the entrypoint of the kic base image", cfg["raw.lxc"])
+	}
+}
+
+func TestWriteUnifiedTarball(t *testing.T) {
+	img, err := random.Image(512, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := writeUnifiedTarball(&b, img, "kicbase:test"); err != nil {
+		t.Fatalf("writeUnifiedTarball: %v", err)
+	}
+
+	tr := tar.NewReader(&b)
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != "metadata.yaml" {
+		t.Fatalf("first entry = %v, %v, want metadata.yaml", hdr, err)
+	}
+	files := 0
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(hdr.Name, "rootfs/") {
+			t.Errorf("entry %s is not in rootfs/", hdr.Name)
+		}
+		files++
+	}
+	if files != 2 {
+		t.Errorf("got %d files in rootfs, want the file of each layer", files)
+	}
+}
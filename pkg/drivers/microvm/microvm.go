@@ -0,0 +1,194 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package microvm holds what the drivers booting the kernel and initrd of the minikube ISO
+// directly with a VMM controlled on a unix socket (firecracker, cloud-hypervisor) have in common.
+package microvm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+
+	pkgdrivers "k8s.io/minikube/pkg/drivers"
+	"k8s.io/minikube/pkg/drivers/hyperkit"
+)
+
+const isoFilename = "boot2docker.iso"
+
+// CheckHost checks that the tools and the uncompressed kernel image needed to run the VM are available
+func CheckHost(tools []string, kernel, kernelFlag string) error {
+	for _, t := range append(tools, "ip", "iptables", "dnsmasq") {
+		if _, err := exec.LookPath(t); err != nil {
+			return errors.Wrapf(err, "%s not found", t)
+		}
+	}
+	if kernel == "" {
+		return fmt.Errorf("the driver needs an uncompressed kernel image, set with --%s", kernelFlag)
+	}
+	if _, err := os.Stat(kernel); err != nil {
+		return errors.Wrap(err, "kernel")
+	}
+	return nil
+}
+
+// InitrdPath returns the path of the initrd of the VM, extracted from the ISO by CreateDisks
+func InitrdPath(d *drivers.BaseDriver) string {
+	return d.ResolveStorePath("initrd")
+}
+
+// Disks returns the paths of the disks of the VM on the host
+func Disks(d *drivers.BaseDriver, extraDisks int) []string {
+	disks := []string{pkgdrivers.GetDiskPath(d)}
+	for i := 0; i < extraDisks; i++ {
+		disks = append(disks, pkgdrivers.ExtraDiskPath(d, i))
+	}
+	return disks
+}
+
+// CreateDisks creates the disks of the VM, and extracts the initrd of the ISO, which is the root filesystem of the VM
+func CreateDisks(d *drivers.BaseDriver, isoURL string, diskSize, extraDisks int) error {
+	if err := pkgdrivers.MakeDiskImage(d, isoURL, diskSize); err != nil {
+		return errors.Wrap(err, "making disk image")
+	}
+	for i := 0; i < extraDisks; i++ {
+		if err := pkgdrivers.CreateRawDisk(pkgdrivers.ExtraDiskPath(d, i), diskSize); err != nil {
+			return err
+		}
+	}
+	if err := hyperkit.ExtractFile(d.ResolveStorePath(isoFilename), "/boot/initrd", InitrdPath(d)); err != nil {
+		return errors.Wrap(err, "extract initrd")
+	}
+	return nil
+}
+
+// StartDetached starts the VMM logging to logPath, and records its pid in pidfile.
+// The VMM outlives minikube, so no child process is kept around.
+func StartDetached(cmd *exec.Cmd, logPath, pidfile string) error {
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Wrap(err, "open log")
+	}
+	defer logFile.Close()
+
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	log.Debugf("executing: %s", strings.Join(cmd.Args, " "))
+	if err := cmd.Start(); err != nil {
+		return errors.Wrapf(err, "start %s", cmd.Path)
+	}
+	if err := os.WriteFile(pidfile, []byte(strconv.Itoa(cmd.Process.Pid)), 0600); err != nil {
+		return errors.Wrap(err, "write pidfile")
+	}
+	if err := cmd.Process.Release(); err != nil {
+		log.Debugf("release %s process: %v", cmd.Path, err)
+	}
+	return nil
+}
+
+// Pid returns the pid recorded in pidfile
+func Pid(pidfile string) (int, error) {
+	p, err := os.ReadFile(pidfile)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(p)))
+}
+
+// Running returns whether the VMM of pidfile is running
+func Running(pidfile string) bool {
+	pid, err := Pid(pidfile)
+	if err != nil {
+		return false
+	}
+	return pkgdrivers.CheckPid(pid) == nil
+}
+
+// Kill kills the VMM of pidfile, if it is running
+func Kill(pidfile string) error {
+	pid, err := Pid(pidfile)
+	if err != nil {
+		return nil
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return nil
+	}
+	if err := p.Kill(); err != nil && pkgdrivers.CheckPid(pid) == nil {
+		return errors.Wrapf(err, "kill %d", pid)
+	}
+	return nil
+}
+
+// WaitStopped waits until the VM is stopped, once the guest has been asked to shut down
+func WaitStopped(d drivers.Driver, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if s, _ := d.GetState(); s == state.Stopped {
+			return nil
+		}
+		time.Sleep(time.Second)
+	}
+	return fmt.Errorf("the VM did not shut down after %s", timeout)
+}
+
+// API calls the HTTP API of the VMM on its unix socket, decoding the JSON response in resp if it is not nil
+func API(socket, method, url string, body interface{}, resp interface{}) error {
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		},
+	}
+	var b bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&b).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, url, &b)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	r, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "VMM API")
+	}
+	defer r.Body.Close()
+	if r.StatusCode >= 300 {
+		return fmt.Errorf("VMM API %s %s: %s", method, url, r.Status)
+	}
+	if resp == nil {
+		return nil
+	}
+	return json.NewDecoder(r.Body).Decode(resp)
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package microvm
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestRunning(t *testing.T) {
+	pidfile := filepath.Join(t.TempDir(), "vmm.pid")
+	if Running(pidfile) {
+		t.Errorf("Running() = true without a pidfile")
+	}
+	if err := os.WriteFile(pidfile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0600); err != nil {
+		t.Fatalf("write pidfile: %v", err)
+	}
+	if !Running(pidfile) {
+		t.Errorf("Running() = false with the pid of the test process")
+	}
+}
+
+func TestAPI(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "vmm.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/vm.info", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"state": "Running"}`))
+	})
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(l)
+	defer srv.Close()
+
+	var info struct {
+		State string `json:"state"`
+	}
+	if err := API(socket, http.MethodGet, "http://localhost/api/v1/vm.info", nil, &info); err != nil {
+		t.Fatalf("API: %v", err)
+	}
+	if info.State != "Running" {
+		t.Errorf("state = %q, want Running", info.State)
+	}
+	if err := API(socket, http.MethodPut, "http://localhost/api/v1/vm.boot", nil, nil); err == nil {
+		t.Errorf("API succeeded on an unknown endpoint")
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package plugin implements out-of-tree drivers, shipped as separate minikube-driver-<name> binaries
+// and selected with --driver=plugin:<name>.
+//
+// minikube runs the plugin binary with the path of a unix socket in the MINIKUBE_DRIVER_PLUGIN_SOCKET
+// environment variable, and talks to it over gRPC on that socket. The plugin exits when its stdin is closed.
+// The messages are encoded as JSON (content type application/grpc+json), so that plugins can be written
+// with any gRPC library without sharing generated code. Go plugins only need to implement Plugin and call Serve.
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/docker/machine/libmachine/state"
+	"google.golang.org/grpc"
+)
+
+const (
+	// APIVersion is the version of the plugin API implemented by this minikube.
+	// It is bumped on any incompatible change, and checked when the plugin is started.
+	APIVersion = "v1"
+	// ServiceName is the gRPC service implemented by plugins
+	ServiceName = "minikube.driver." + APIVersion + ".Driver"
+	// SocketEnv is the environment variable holding the path of the socket the plugin must listen on
+	SocketEnv = "MINIKUBE_DRIVER_PLUGIN_SOCKET"
+	// BinaryPrefix is the prefix of the plugin binaries, looked up in PATH
+	BinaryPrefix = "minikube-driver-"
+)
+
+// InfoResponse describes a plugin
+type InfoResponse struct {
+	APIVersion string `json:"apiVersion"`
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+}
+
+// MachineRequest identifies the machine an operation applies to
+type MachineRequest struct {
+	MachineName string `json:"machineName"`
+	// StorePath is a directory dedicated to the machine, where the plugin may keep its files
+	StorePath string `json:"storePath"`
+}
+
+// CreateRequest is the configuration of a machine to create and start
+type CreateRequest struct {
+	MachineRequest
+	// ISOURL is the file:// URL of the minikube ISO, already downloaded
+	ISOURL     string `json:"isoURL"`
+	CPUs       int    `json:"cpus"`
+	MemoryMB   int    `json:"memoryMB"`
+	DiskSizeMB int    `json:"diskSizeMB"`
+	ExtraDisks int    `json:"extraDisks"`
+	Network    string `json:"network,omitempty"`
+	// SSHUser and SSHPublicKey must be authorized to log into the machine
+	SSHUser      string `json:"sshUser"`
+	SSHPublicKey string `json:"sshPublicKey"`
+	// Options are the plugin specific --plugin-opts
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// StateResponse is the state of a machine, one of the libmachine states: Running, Paused, Saved, Stopped, Stopping, Starting, Error or Timeout.
+// An empty state means the machine does not exist.
+type StateResponse struct {
+	State string `json:"state"`
+}
+
+// IPResponse holds the addresses of a machine
+type IPResponse struct {
+	// IP is the address of the machine, reachable from the host
+	IP string `json:"ip"`
+	// HostIP is the address of the host, reachable from the machine
+	HostIP string `json:"hostIP"`
+}
+
+// SSHResponse is how to reach the SSH server of a machine
+type SSHResponse struct {
+	Hostname string `json:"hostname"`
+	Port     int    `json:"port"`
+}
+
+// Empty is the response of the operations returning nothing
+type Empty struct{}
+
+// Plugin is implemented by Go plugins, and served with Serve
+type Plugin interface {
+	// Create creates and starts a machine
+	Create(ctx context.Context, req *CreateRequest) error
+	Start(ctx context.Context, req *MachineRequest) error
+	Stop(ctx context.Context, req *MachineRequest) error
+	Kill(ctx context.Context, req *MachineRequest) error
+	// Remove deletes a machine and its files, it must succeed if the machine does not exist
+	Remove(ctx context.Context, req *MachineRequest) error
+	State(ctx context.Context, req *MachineRequest) (state.State, error)
+	IP(ctx context.Context, req *MachineRequest) (*IPResponse, error)
+	SSH(ctx context.Context, req *MachineRequest) (*SSHResponse, error)
+}
+
+// jsonCodec encodes the gRPC messages as JSON
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+// server adapts a Plugin to the gRPC service
+type server struct {
+	info   InfoResponse
+	plugin Plugin
+}
+
+func method(name string, newReq func() interface{}, call func(s *server, ctx context.Context, req interface{}) (interface{}, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := newReq()
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			return call(srv.(*server), ctx, req)
+		},
+	}
+}
+
+func newEmpty() interface{} { return &Empty{} }
+
+func newMachineRequest() interface{} { return &MachineRequest{} }
+
+// machineMethod is a method taking a MachineRequest and returning nothing
+func machineMethod(name string, f func(p Plugin, ctx context.Context, req *MachineRequest) error) grpc.MethodDesc {
+	return method(name, newMachineRequest, func(s *server, ctx context.Context, req interface{}) (interface{}, error) {
+		return &Empty{}, f(s.plugin, ctx, req.(*MachineRequest))
+	})
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		method("Info", newEmpty, func(s *server, _ context.Context, _ interface{}) (interface{}, error) {
+			return &s.info, nil
+		}),
+		method("Create", func() interface{} { return &CreateRequest{} }, func(s *server, ctx context.Context, req interface{}) (interface{}, error) {
+			return &Empty{}, s.plugin.Create(ctx, req.(*CreateRequest))
+		}),
+		machineMethod("Start", Plugin.Start),
+		machineMethod("Stop", Plugin.Stop),
+		machineMethod("Kill", Plugin.Kill),
+		machineMethod("Remove", Plugin.Remove),
+		method("State", newMachineRequest, func(s *server, ctx context.Context, req interface{}) (interface{}, error) {
+			st, err := s.plugin.State(ctx, req.(*MachineRequest))
+			if err != nil {
+				return nil, err
+			}
+			return &StateResponse{State: st.String()}, nil
+		}),
+		method("IP", newMachineRequest, func(s *server, ctx context.Context, req interface{}) (interface{}, error) {
+			return s.plugin.IP(ctx, req.(*MachineRequest))
+		}),
+		method("SSH", newMachineRequest, func(s *server, ctx context.Context, req interface{}) (interface{}, error) {
+			return s.plugin.SSH(ctx, req.(*MachineRequest))
+		}),
+	},
+	Streams: []grpc.StreamDesc{},
+}
+
+// newServer returns a gRPC server serving the plugin
+func newServer(name, version string, p Plugin) *grpc.Server {
+	s := grpc.NewServer(grpc.ForceServerCodec(jsonCodec{}))
+	s.RegisterService(&serviceDesc, &server{info: InfoResponse{APIVersion: APIVersion, Name: name, Version: version}, plugin: p})
+	return s
+}
+
+// parseState returns the libmachine state from its name
+func parseState(s string) state.State {
+	for st := state.None; st <= state.Timeout; st++ {
+		if st.String() == s {
+			return st
+		}
+	}
+	return state.Error
+}
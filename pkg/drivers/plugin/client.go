@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// startTimeout is how long to wait for a plugin to listen on its socket
+const startTimeout = 10 * time.Second
+
+var (
+	// clients are the running plugins, shared by the machines using them
+	clients   = map[string]*client{}
+	clientsMu sync.Mutex
+)
+
+// client is a connection to a plugin
+type client struct {
+	conn *grpc.ClientConn
+	info InfoResponse
+	// stdin is kept open for as long as minikube runs, the plugin exits when it is closed
+	stdin io.WriteCloser
+}
+
+// BinaryName returns the name of the binary of a plugin
+func BinaryName(plugin string) string {
+	return BinaryPrefix + plugin
+}
+
+// Lookup returns the path of the binary of a plugin in PATH
+func Lookup(plugin string) (string, error) {
+	return exec.LookPath(BinaryName(plugin))
+}
+
+// Info starts a plugin and returns its description
+func Info(plugin string) (InfoResponse, error) {
+	c, err := launch(plugin)
+	if err != nil {
+		return InfoResponse{}, err
+	}
+	return c.info, nil
+}
+
+// launch starts a plugin, or returns the client of the running one
+func launch(plugin string) (*client, error) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+	if c, ok := clients[plugin]; ok {
+		return c, nil
+	}
+
+	path, err := Lookup(plugin)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s driver plugin not found", plugin)
+	}
+	sock := filepath.Join(os.TempDir(), fmt.Sprintf("%s%s-%d.sock", BinaryPrefix, plugin, os.Getpid()))
+	if err := os.Remove(sock); err != nil && !os.IsNotExist(err) {
+		return nil, errors.Wrap(err, "remove stale socket")
+	}
+
+	cmd := exec.Command(path)
+	cmd.Env = append(os.Environ(), SocketEnv+"="+sock)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("starting driver plugin %s: %s", plugin, path)
+	if err := cmd.Start(); err != nil {
+		return nil, errors.Wrapf(err, "start %s", path)
+	}
+	go func() {
+		s := bufio.NewScanner(stderr)
+		for s.Scan() {
+			log.Debugf("(%s) %s", plugin, s.Text())
+		}
+	}()
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	if err := waitForSocket(sock, exited); err != nil {
+		_ = cmd.Process.Kill()
+		return nil, errors.Wrapf(err, "%s driver plugin", plugin)
+	}
+	c, err := dial(sock)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		return nil, err
+	}
+	c.stdin = stdin
+	if c.info.Name != plugin {
+		log.Warnf("%s driver plugin calls itself %q", plugin, c.info.Name)
+	}
+	log.Infof("using driver plugin %s version %s", plugin, c.info.Version)
+	clients[plugin] = c
+	return c, nil
+}
+
+// waitForSocket waits for the plugin to listen on its socket
+func waitForSocket(sock string, exited chan error) error {
+	deadline := time.After(startTimeout)
+	for {
+		if _, err := os.Stat(sock); err == nil {
+			return nil
+		}
+		select {
+		case err := <-exited:
+			return fmt.Errorf("exited before listening on its socket: %v", err)
+		case <-deadline:
+			return fmt.Errorf("did not listen on %s after %s", sock, startTimeout)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// dial connects to the plugin listening on a socket, and checks its API version
+func dial(sock string) (*client, error) {
+	conn, err := grpc.Dial("passthrough:///"+sock,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", sock)
+		}),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})))
+	if err != nil {
+		return nil, errors.Wrapf(err, "dial %s", sock)
+	}
+	c := &client{conn: conn}
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	if err := c.invoke(ctx, "Info", &Empty{}, &c.info); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "plugin info")
+	}
+	if c.info.APIVersion != APIVersion {
+		conn.Close()
+		return nil, fmt.Errorf("the %s driver plugin implements the %q plugin API, but minikube implements %q", c.info.Name, c.info.APIVersion, APIVersion)
+	}
+	return c, nil
+}
+
+func (c *client) invoke(ctx context.Context, method string, req interface{}, resp interface{}) error {
+	return c.conn.Invoke(ctx, "/"+ServiceName+"/"+method, req, resp)
+}
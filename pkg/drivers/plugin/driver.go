@@ -0,0 +1,292 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/mcnflag"
+	"github.com/docker/machine/libmachine/ssh"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+
+	"k8s.io/minikube/pkg/minikube/registry"
+)
+
+const (
+	defaultSSHUser = "docker"
+	// queryTimeout is the timeout of the operations which do not change the machine
+	queryTimeout = 30 * time.Second
+)
+
+// Driver is a libmachine driver delegating to a plugin
+type Driver struct {
+	*drivers.BaseDriver
+	// Plugin is the name of the plugin, run from the minikube-driver-<Plugin> binary
+	Plugin         string
+	Boot2DockerURL string
+	DiskSize       int
+	Memory         int
+	CPU            int
+	ExtraDisks     int
+	Network        string
+	Options        map[string]string
+
+	// client is set on first use
+	client *client
+}
+
+// NewDriver creates a new driver for a plugin
+func NewDriver(plugin, hostName, storePath string) *Driver {
+	return &Driver{
+		Plugin: plugin,
+		BaseDriver: &drivers.BaseDriver{
+			SSHUser:     defaultSSHUser,
+			MachineName: hostName,
+			StorePath:   storePath,
+		},
+	}
+}
+
+// DriverName returns the name of the driver, as given to --driver
+func (d *Driver) DriverName() string {
+	return registry.PluginPrefix + d.Plugin
+}
+
+func (d *Driver) conn() (*client, error) {
+	if d.client != nil {
+		return d.client, nil
+	}
+	c, err := launch(d.Plugin)
+	if err != nil {
+		return nil, err
+	}
+	d.client = c
+	return c, nil
+}
+
+func (d *Driver) call(method string, timeout time.Duration, req interface{}, resp interface{}) error {
+	c, err := d.conn()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := c.invoke(ctx, method, req, resp); err != nil {
+		return errors.Wrapf(err, "%s plugin %s", d.Plugin, method)
+	}
+	return nil
+}
+
+func (d *Driver) machine() MachineRequest {
+	return MachineRequest{MachineName: d.MachineName, StorePath: d.ResolveStorePath(".")}
+}
+
+// GetCreateFlags is not used by minikube
+func (d *Driver) GetCreateFlags() []mcnflag.Flag {
+	return nil
+}
+
+// SetConfigFromFlags is not used by minikube
+func (d *Driver) SetConfigFromFlags(_ drivers.DriverOptions) error {
+	return nil
+}
+
+// PreCreateCheck starts the plugin, which checks its API version
+func (d *Driver) PreCreateCheck() error {
+	_, err := d.conn()
+	return err
+}
+
+// Create generates the SSH key of the machine, and has the plugin create it
+func (d *Driver) Create() error {
+	if err := os.MkdirAll(d.ResolveStorePath("."), 0755); err != nil {
+		return errors.Wrap(err, "create machine dir")
+	}
+	if err := ssh.GenerateSSHKey(d.GetSSHKeyPath()); err != nil {
+		return errors.Wrap(err, "generate ssh key")
+	}
+	pub, err := os.ReadFile(d.GetSSHKeyPath() + ".pub")
+	if err != nil {
+		return errors.Wrap(err, "read ssh public key")
+	}
+	req := &CreateRequest{
+		MachineRequest: d.machine(),
+		ISOURL:         d.Boot2DockerURL,
+		CPUs:           d.CPU,
+		MemoryMB:       d.Memory,
+		DiskSizeMB:     d.DiskSize,
+		ExtraDisks:     d.ExtraDisks,
+		Network:        d.Network,
+		SSHUser:        d.GetSSHUsername(),
+		SSHPublicKey:   string(pub),
+		Options:        d.Options,
+	}
+	if err := d.call("Create", 0, req, &Empty{}); err != nil {
+		return err
+	}
+	_, err = d.GetIP()
+	return err
+}
+
+// Start starts the machine
+func (d *Driver) Start() error {
+	if err := d.call("Start", 0, d.machine(), &Empty{}); err != nil {
+		return err
+	}
+	_, err := d.GetIP()
+	return err
+}
+
+// Stop stops the machine
+func (d *Driver) Stop() error {
+	return d.call("Stop", 0, d.machine(), &Empty{})
+}
+
+// Kill stops the machine forcibly
+func (d *Driver) Kill() error {
+	return d.call("Kill", 0, d.machine(), &Empty{})
+}
+
+// Remove deletes the machine
+func (d *Driver) Remove() error {
+	return d.call("Remove", 0, d.machine(), &Empty{})
+}
+
+// Restart stops and starts the machine
+func (d *Driver) Restart() error {
+	if err := d.Stop(); err != nil {
+		return err
+	}
+	return d.Start()
+}
+
+// GetState returns the state of the machine
+func (d *Driver) GetState() (state.State, error) {
+	resp := &StateResponse{}
+	if err := d.call("State", queryTimeout, d.machine(), resp); err != nil {
+		return state.Error, err
+	}
+	return parseState(resp.State), nil
+}
+
+func (d *Driver) ips() (*IPResponse, error) {
+	resp := &IPResponse{}
+	if err := d.call("IP", queryTimeout, d.machine(), resp); err != nil {
+		return nil, err
+	}
+	if resp.IP == "" {
+		return nil, fmt.Errorf("%s plugin returned no IP for %s", d.Plugin, d.MachineName)
+	}
+	return resp, nil
+}
+
+// GetIP returns the IP address of the machine, and records it
+func (d *Driver) GetIP() (string, error) {
+	resp, err := d.ips()
+	if err != nil {
+		return "", err
+	}
+	d.IPAddress = resp.IP
+	return resp.IP, nil
+}
+
+// GetHostIP returns the IP address of the host, as seen from the machine
+func (d *Driver) GetHostIP() (string, error) {
+	resp, err := d.ips()
+	if err != nil {
+		return "", err
+	}
+	if resp.HostIP == "" {
+		return "", fmt.Errorf("%s plugin returned no host IP for %s", d.Plugin, d.MachineName)
+	}
+	return resp.HostIP, nil
+}
+
+func (d *Driver) ssh() (*SSHResponse, error) {
+	resp := &SSHResponse{}
+	if err := d.call("SSH", queryTimeout, d.machine(), resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetSSHHostname returns the hostname for use with ssh
+func (d *Driver) GetSSHHostname() (string, error) {
+	resp, err := d.ssh()
+	if err != nil {
+		return "", err
+	}
+	return resp.Hostname, nil
+}
+
+// GetSSHPort returns the port for use with ssh
+func (d *Driver) GetSSHPort() (int, error) {
+	resp, err := d.ssh()
+	if err != nil {
+		return 0, err
+	}
+	if resp.Port == 0 {
+		return drivers.DefaultSSHPort, nil
+	}
+	d.SSHPort = resp.Port
+	return resp.Port, nil
+}
+
+// GetSSHKeyPath returns the path of the SSH key generated for the machine
+func (d *Driver) GetSSHKeyPath() string {
+	return d.ResolveStorePath("id_rsa")
+}
+
+// GetSSHUsername returns the user name for SSH
+func (d *Driver) GetSSHUsername() string {
+	if d.SSHUser == "" {
+		d.SSHUser = defaultSSHUser
+	}
+	return d.SSHUser
+}
+
+// GetURL returns a Docker URL inside this host
+func (d *Driver) GetURL() (string, error) {
+	ip, err := d.GetIP()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("tcp://%s:2376", ip), nil
+}
+
+// ParseOptions parses the --plugin-opts, in the key=value format
+func ParseOptions(opts []string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, o := range opts {
+		k, v, ok := strings.Cut(o, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid plugin option %q, expected key=value", o)
+		}
+		m[k] = v
+	}
+	return m, nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/machine/libmachine/state"
+	"google.golang.org/grpc"
+)
+
+type fakePlugin struct {
+	created *CreateRequest
+	state   state.State
+}
+
+func (p *fakePlugin) Create(_ context.Context, req *CreateRequest) error {
+	p.created = req
+	p.state = state.Running
+	return nil
+}
+
+func (p *fakePlugin) Start(_ context.Context, _ *MachineRequest) error {
+	p.state = state.Running
+	return nil
+}
+
+func (p *fakePlugin) Stop(_ context.Context, _ *MachineRequest) error {
+	p.state = state.Stopped
+	return nil
+}
+
+func (p *fakePlugin) Kill(_ context.Context, _ *MachineRequest) error {
+	p.state = state.Stopped
+	return nil
+}
+
+func (p *fakePlugin) Remove(_ context.Context, req *MachineRequest) error {
+	return fmt.Errorf("cannot remove %s", req.MachineName)
+}
+
+func (p *fakePlugin) State(_ context.Context, _ *MachineRequest) (state.State, error) {
+	return p.state, nil
+}
+
+func (p *fakePlugin) IP(_ context.Context, _ *MachineRequest) (*IPResponse, error) {
+	return &IPResponse{IP: "192.168.105.2", HostIP: "192.168.105.1"}, nil
+}
+
+func (p *fakePlugin) SSH(_ context.Context, _ *MachineRequest) (*SSHResponse, error) {
+	return &SSHResponse{Hostname: "192.168.105.2", Port: 2222}, nil
+}
+
+// serveFake serves a fake plugin in the test process, and returns a driver connected to it
+func serveFake(t *testing.T, p Plugin, info InfoResponse) (*Driver, error) {
+	sock := filepath.Join(t.TempDir(), "plugin.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := grpc.NewServer(grpc.ForceServerCodec(jsonCodec{}))
+	s.RegisterService(&serviceDesc, &server{info: info, plugin: p})
+	t.Cleanup(s.Stop)
+	go func() { _ = s.Serve(l) }()
+
+	c, err := dial(sock)
+	if err != nil {
+		return nil, err
+	}
+	d := NewDriver("fake", "minikube", t.TempDir())
+	d.client = c
+	return d, nil
+}
+
+func TestDriver(t *testing.T) {
+	p := &fakePlugin{}
+	d, err := serveFake(t, p, InfoResponse{APIVersion: APIVersion, Name: "fake", Version: "1.0.0"})
+	if err != nil {
+		t.Fatalf("connecting to the plugin: %v", err)
+	}
+	d.CPU = 2
+	d.Memory = 4096
+	d.Options = map[string]string{"firmware": "efi"}
+
+	if err := d.Create(); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if p.created.MachineName != "minikube" || p.created.CPUs != 2 || p.created.MemoryMB != 4096 {
+		t.Errorf("plugin was asked to create %+v", p.created)
+	}
+	if !reflect.DeepEqual(p.created.Options, d.Options) {
+		t.Errorf("plugin options = %v, want %v", p.created.Options, d.Options)
+	}
+	if !strings.HasPrefix(p.created.SSHPublicKey, "ssh-rsa ") {
+		t.Errorf("plugin was not given the SSH public key: %q", p.created.SSHPublicKey)
+	}
+	if d.IPAddress != "192.168.105.2" {
+		t.Errorf("IP address = %q after Create, want 192.168.105.2", d.IPAddress)
+	}
+
+	if err := d.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	st, err := d.GetState()
+	if err != nil || st != state.Stopped {
+		t.Errorf("GetState() = %v, %v, want Stopped", st, err)
+	}
+
+	if port, err := d.GetSSHPort(); err != nil || port != 2222 {
+		t.Errorf("GetSSHPort() = %d, %v, want 2222", port, err)
+	}
+	if ip, err := d.GetHostIP(); err != nil || ip != "192.168.105.1" {
+		t.Errorf("GetHostIP() = %q, %v, want 192.168.105.1", ip, err)
+	}
+	if err := d.Remove(); err == nil || !strings.Contains(err.Error(), "cannot remove minikube") {
+		t.Errorf("Remove() = %v, want the plugin error", err)
+	}
+}
+
+func TestDialVersionMismatch(t *testing.T) {
+	_, err := serveFake(t, &fakePlugin{}, InfoResponse{APIVersion: "v0", Name: "fake"})
+	if err == nil || !strings.Contains(err.Error(), `implements the "v0" plugin API`) {
+		t.Errorf("dial error = %v, want an API version mismatch", err)
+	}
+}
+
+func TestParseState(t *testing.T) {
+	for st := state.None; st <= state.Timeout; st++ {
+		if got := parseState(st.String()); got != st {
+			t.Errorf("parseState(%q) = %v, want %v", st.String(), got, st)
+		}
+	}
+	if got := parseState("Exploded"); got != state.Error {
+		t.Errorf("parseState(Exploded) = %v, want Error", got)
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	got, err := ParseOptions([]string{"firmware=efi", "args=a=b", "empty="})
+	if err != nil {
+		t.Fatalf("ParseOptions: %v", err)
+	}
+	want := map[string]string{"firmware": "efi", "args": "a=b", "empty": ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseOptions() = %v, want %v", got, want)
+	}
+	for _, o := range []string{"firmware", "=efi"} {
+		if _, err := ParseOptions([]string{o}); err == nil {
+			t.Errorf("ParseOptions(%q) succeeded, want an error", o)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+)
+
+// Serve serves a plugin on the socket given by minikube, until minikube closes the stdin of the plugin.
+// It is called from the main function of the plugin binary.
+func Serve(name, version string, p Plugin) error {
+	sock := os.Getenv(SocketEnv)
+	if sock == "" {
+		return fmt.Errorf("%s is not set: %s is a minikube driver plugin, use it with 'minikube start --driver=plugin:%s'", SocketEnv, os.Args[0], name)
+	}
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %v", sock, err)
+	}
+	s := newServer(name, version, p)
+	go func() {
+		// minikube exited or is done with the plugin
+		_, _ = io.Copy(io.Discard, os.Stdin)
+		s.Stop()
+	}()
+	return s.Serve(l)
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drivers
+
+import (
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestCheckPid(t *testing.T) {
+	if err := CheckPid(os.Getpid()); err != nil {
+		t.Errorf("CheckPid(%d) = %v, want the running test process", os.Getpid(), err)
+	}
+}
+
+func TestWaitForTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := l.Addr().String()
+	if err := WaitForTCP(addr, 5*time.Second); err != nil {
+		t.Errorf("WaitForTCP(%s) = %v, want the listener", addr, err)
+	}
+	l.Close()
+	if err := WaitForTCP(addr, time.Second); err == nil {
+		t.Errorf("WaitForTCP(%s) succeeded after the listener was closed", addr)
+	}
+}
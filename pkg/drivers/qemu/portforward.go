@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package qemu
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PortForward is a TCP or UDP port of the host forwarded to a port of the guest by the builtin network
+type PortForward struct {
+	// Protocol is "tcp" or "udp", empty for tcp
+	Protocol string
+	// HostAddr is the address of the host the port is bound on, empty for all addresses
+	HostAddr string
+	// HostPort is the port of the host
+	HostPort int
+	// GuestPort is the port of the guest
+	GuestPort int
+}
+
+func (f PortForward) String() string {
+	if f.Proto() == "udp" {
+		return fmt.Sprintf("%s:%d -> %d/udp", f.HostAddr, f.HostPort, f.GuestPort)
+	}
+	return fmt.Sprintf("%s:%d -> %d", f.HostAddr, f.HostPort, f.GuestPort)
+}
+
+// Proto returns the protocol of the forward, tcp or udp
+func (f PortForward) Proto() string {
+	if f.Protocol == "" {
+		return "tcp"
+	}
+	return f.Protocol
+}
+
+// AddPortForward forwards a port of the host to the guest, while the VM runs
+func (d *Driver) AddPortForward(f PortForward) error {
+	out, err := d.humanMonitorCommand(hostfwdAdd(f))
+	if err != nil {
+		return err
+	}
+	// hostfwd_add only prints errors
+	if out != "" {
+		return fmt.Errorf("forward %s: %s", f, strings.TrimSpace(out))
+	}
+	return nil
+}
+
+// RemovePortForward removes a port forward added by AddPortForward
+func (d *Driver) RemovePortForward(f PortForward) error {
+	out, err := d.humanMonitorCommand(hostfwdRemove(f))
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(out, "removed") {
+		return fmt.Errorf("remove forward %s: %s", f, strings.TrimSpace(out))
+	}
+	return nil
+}
+
+// PortForwards lists the TCP and UDP ports of the host forwarded to the guest, including the ssh and docker ports
+func (d *Driver) PortForwards() ([]PortForward, error) {
+	out, err := d.humanMonitorCommand("info usernet")
+	if err != nil {
+		return nil, err
+	}
+	return parseUsernet(out), nil
+}
+
+func hostfwdAdd(f PortForward) string {
+	return fmt.Sprintf("hostfwd_add %s:%s:%d-:%d", f.Proto(), f.HostAddr, f.HostPort, f.GuestPort)
+}
+
+func hostfwdRemove(f PortForward) string {
+	return fmt.Sprintf("hostfwd_remove %s:%s:%d", f.Proto(), f.HostAddr, f.HostPort)
+}
+
+// parseUsernet parses the host forwards of the output of 'info usernet':
+//
+//	Protocol[State]    FD  Source Address  Port   Dest. Address  Port RecvQ SendQ
+//	TCP[HOST_FORWARD]  13       127.0.0.1 30080       10.0.2.15 30080     0     0
+//	UDP[HOST_FORWARD]  15       127.0.0.1 30053       10.0.2.15 30053     0     0
+func parseUsernet(out string) []PortForward {
+	var forwards []PortForward
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 6 {
+			continue
+		}
+		protocol := ""
+		switch fields[0] {
+		case "TCP[HOST_FORWARD]":
+		case "UDP[HOST_FORWARD]":
+			protocol = "udp"
+		default:
+			continue
+		}
+		hostPort, err := strconv.Atoi(fields[3])
+		if err != nil {
+			continue
+		}
+		guestPort, err := strconv.Atoi(fields[5])
+		if err != nil {
+			continue
+		}
+		addr := fields[2]
+		if addr == "*" || addr == "0.0.0.0" {
+			addr = ""
+		}
+		forwards = append(forwards, PortForward{Protocol: protocol, HostAddr: addr, HostPort: hostPort, GuestPort: guestPort})
+	}
+	return forwards
+}
+
+// humanMonitorCommand runs a command of the human monitor through QMP, and returns its output
+func (d *Driver) humanMonitorCommand(cmdline string) (string, error) {
+	ret, err := d.runQMP("human-monitor-command", map[string]string{"command-line": cmdline})
+	if err != nil {
+		return "", errors.Wrap(err, cmdline)
+	}
+	var out string
+	if err := json.Unmarshal(ret, &out); err != nil {
+		return "", errors.Wrapf(err, "unmarshal %q resp", cmdline)
+	}
+	return out, nil
+}
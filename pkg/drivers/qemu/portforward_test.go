@@ -0,0 +1,141 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package qemu
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const usernet = `Hub -1 (net0):
+  Protocol[State]    FD  Source Address  Port   Dest. Address  Port RecvQ SendQ
+  TCP[HOST_FORWARD]  14               * 53147       10.0.2.15    22     0     0
+  TCP[HOST_FORWARD]  13       127.0.0.1 30080       10.0.2.15 30080     0     0
+  UDP[HOST_FORWARD]  15       127.0.0.1 30053       10.0.2.15 30053     0     0
+  TCP[ESTABLISHED]   20       127.0.0.1 53201       10.0.2.15    22     0     0
+`
+
+func TestParseUsernet(t *testing.T) {
+	want := []PortForward{
+		{HostAddr: "", HostPort: 53147, GuestPort: 22},
+		{HostAddr: "127.0.0.1", HostPort: 30080, GuestPort: 30080},
+		{Protocol: "udp", HostAddr: "127.0.0.1", HostPort: 30053, GuestPort: 30053},
+	}
+	if got := parseUsernet(usernet); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseUsernet() = %v, want %v", got, want)
+	}
+}
+
+// serveQMP answers the human monitor commands sent to the monitor of d
+func serveQMP(t *testing.T, d *Driver, outputs map[string]string) {
+	if err := os.MkdirAll(filepath.Dir(d.monitorPath()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	l, err := net.Listen("unix", d.monitorPath())
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			dec := json.NewDecoder(conn)
+			enc := json.NewEncoder(conn)
+			_ = enc.Encode(map[string]interface{}{"QMP": map[string]interface{}{"capabilities": []string{}}})
+			for {
+				var cmd struct {
+					Execute   string            `json:"execute"`
+					Arguments map[string]string `json:"arguments"`
+				}
+				if err := dec.Decode(&cmd); err != nil {
+					conn.Close()
+					break
+				}
+				if cmd.Execute != "human-monitor-command" {
+					_ = enc.Encode(map[string]interface{}{"return": map[string]string{}})
+					continue
+				}
+				// events may come before the response
+				_ = enc.Encode(map[string]interface{}{"event": "NIC_RX_FILTER_CHANGED"})
+				_ = enc.Encode(map[string]interface{}{"return": outputs[cmd.Arguments["command-line"]]})
+			}
+		}
+	}()
+}
+
+func TestPortForwards(t *testing.T) {
+	d := NewDriver("m", t.TempDir()).(*Driver)
+	f := PortForward{HostAddr: "127.0.0.1", HostPort: 30080, GuestPort: 30080}
+	serveQMP(t, d, map[string]string{
+		"info usernet":                           usernet,
+		"hostfwd_add tcp:127.0.0.1:30080-:30080": "",
+		"hostfwd_add tcp:127.0.0.1:80-:30081":    "could not set up host forwarding rule 'tcp:127.0.0.1:80-:30081'\r\n",
+		"hostfwd_remove tcp:127.0.0.1:30080":     "host forwarding rule for tcp:127.0.0.1:30080 removed\r\n",
+		"hostfwd_remove tcp:127.0.0.1:80":        "host forwarding rule for tcp:127.0.0.1:80 not found\r\n",
+	})
+
+	forwards, err := d.PortForwards()
+	if err != nil {
+		t.Fatalf("PortForwards: %v", err)
+	}
+	if len(forwards) != 3 || forwards[1] != f {
+		t.Errorf("PortForwards() = %v, want %v second", forwards, f)
+	}
+	if err := d.AddPortForward(f); err != nil {
+		t.Errorf("AddPortForward(%v): %v", f, err)
+	}
+	if err := d.RemovePortForward(f); err != nil {
+		t.Errorf("RemovePortForward(%v): %v", f, err)
+	}
+
+	busy := PortForward{HostAddr: "127.0.0.1", HostPort: 80, GuestPort: 30081}
+	if err := d.AddPortForward(busy); err == nil {
+		t.Errorf("AddPortForward(%v) succeeded, want the error of QEMU", busy)
+	}
+	if err := d.RemovePortForward(busy); err == nil {
+		t.Errorf("RemovePortForward(%v) succeeded for a missing forward", busy)
+	}
+}
+
+func TestHostfwdCommands(t *testing.T) {
+	f := PortForward{HostPort: 8080, GuestPort: 31000}
+	if got, want := hostfwdAdd(f), "hostfwd_add tcp::8080-:31000"; got != want {
+		t.Errorf("hostfwdAdd() = %q, want %q", got, want)
+	}
+	if got, want := hostfwdRemove(f), "hostfwd_remove tcp::8080"; got != want {
+		t.Errorf("hostfwdRemove() = %q, want %q", got, want)
+	}
+	if got, want := f.String(), fmt.Sprintf(":%d -> %d", 8080, 31000); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	udp := PortForward{Protocol: "udp", HostAddr: "127.0.0.1", HostPort: 53, GuestPort: 31053}
+	if got, want := hostfwdAdd(udp), "hostfwd_add udp:127.0.0.1:53-:31053"; got != want {
+		t.Errorf("hostfwdAdd() = %q, want %q", got, want)
+	}
+	if got, want := hostfwdRemove(udp), "hostfwd_remove udp:127.0.0.1:53"; got != want {
+		t.Errorf("hostfwdRemove() = %q, want %q", got, want)
+	}
+}
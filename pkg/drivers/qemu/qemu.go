@@ -30,7 +30,6 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/docker/machine/libmachine/drivers"
@@ -91,6 +90,13 @@ type Driver struct {
 	SocketVMNetPath       string
 	SocketVMNetClientPath string
 	ExtraDisks            int
+	// StaticIP is configured in the guest by minikube, with socket_vmnet only
+	StaticIP string
+	// BridgeInterface is the host interface of the bridged network: a bridge on Linux,
+	// or an interface with socket_vmnet running in bridged mode on it on macOS
+	BridgeInterface string
+	// VirtiofsDir is the host directory shared with the guest through virtiofsd, if set, only on Linux
+	VirtiofsDir string
 }
 
 func (d *Driver) GetMachineName() string {
@@ -158,6 +164,10 @@ func (d *Driver) GetIP() (string, error) {
 	if network.IsBuiltinQEMU(d.Network) {
 		return "127.0.0.1", nil
 	}
+	// the guest moves to its static IP once minikube configured it, see ensureStaticIP in pkg/minikube/machine
+	if d.StaticIP != "" && d.IPAddress != d.StaticIP && sshReachable(d.StaticIP, d.SSHPort) {
+		d.IPAddress = d.StaticIP
+	}
 	return d.IPAddress, nil
 }
 
@@ -170,14 +180,6 @@ func (d *Driver) GetPort() int {
 	return port
 }
 
-func checkPid(pid int) error {
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		return err
-	}
-	return process.Signal(syscall.Signal(0))
-}
-
 func (d *Driver) GetState() (state.State, error) {
 	if runtime.GOOS != "windows" {
 		if _, err := os.Stat(d.pidfilePath()); err != nil {
@@ -191,7 +193,7 @@ func (d *Driver) GetState() (state.State, error) {
 		if err != nil {
 			return state.Error, err
 		}
-		if err := checkPid(pid); err != nil {
+		if err := pkgdrivers.CheckPid(pid); err != nil {
 			// No pid, remove pidfile
 			os.Remove(d.pidfilePath())
 			return state.Stopped, nil
@@ -253,7 +255,7 @@ func (d *Driver) Create() error {
 			}
 			break
 		}
-	case "socket_vmnet":
+	case "socket_vmnet", "bridged":
 		d.SSHPort, err = d.GetSSHPort()
 		if err != nil {
 			return err
@@ -426,6 +428,12 @@ func (d *Driver) Start() error {
 		"-m", fmt.Sprintf("%d", d.Memory),
 		"-smp", fmt.Sprintf("%d", d.CPU),
 		"-boot", "d")
+	if d.VirtiofsDir != "" {
+		if err := d.startVirtiofsd(); err != nil {
+			return errors.Wrap(err, "virtiofsd")
+		}
+		startCmd = append(startCmd, virtiofsArgs(d.Memory, d.virtiofsSocketPath())...)
+	}
 	var isoPath = filepath.Join(machineDir, isoFilename)
 	if d.VirtioDrives {
 		startCmd = append(startCmd,
@@ -448,6 +456,8 @@ func (d *Driver) Start() error {
 		startCmd = append(startCmd,
 			"-device", fmt.Sprintf("virtio-net-pci,netdev=net0,mac=%s", d.MACAddress), "-netdev", "socket,id=net0,fd=3",
 		)
+	case "bridged":
+		startCmd = append(startCmd, bridgedNetArgs(runtime.GOOS, d.MACAddress, d.BridgeInterface)...)
 	default:
 		return fmt.Errorf("unknown network: %s", d.Network)
 	}
@@ -486,7 +496,7 @@ func (d *Driver) Start() error {
 
 	// If socket network, start with socket_vmnet.
 	startProgram := d.Program
-	if d.Network == "socket_vmnet" {
+	if d.Network == "socket_vmnet" || (d.Network == "bridged" && runtime.GOOS == "darwin") {
 		startProgram = d.SocketVMNetClientPath
 		startCmd = append([]string{d.SocketVMNetPath, d.Program}, startCmd...)
 	}
@@ -505,6 +515,12 @@ func (d *Driver) Start() error {
 	case "builtin", "user":
 		d.IPAddress = "127.0.0.1"
 	case "socket_vmnet":
+		if d.StaticIP != "" {
+			if err := d.waitForStaticIP(); err != nil {
+				return err
+			}
+			break
+		}
 		var err error
 		getIP := func() error {
 			// QEMU requires MAC address with leading 0s
@@ -539,6 +555,21 @@ func (d *Driver) Start() error {
 		}
 		out.Styled(style.Restarting, "Successfully unblocked bootpd process from firewall, retrying")
 		return fmt.Errorf("ip not found: %v", err)
+	case "bridged":
+		// the DHCP server of the network of the interface leased the IP, it is found in the ARP table of the host
+		var err error
+		for i := 0; i < 60; i++ {
+			log.Debugf("Attempt %d", i)
+			d.IPAddress, err = pkgdrivers.BridgedIP(d.BridgeInterface, d.MACAddress)
+			if err == nil {
+				break
+			}
+			time.Sleep(2 * time.Second)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "IP address never found in the ARP table of %s", d.BridgeInterface)
+		}
+		log.Debugf("IP: %s", d.IPAddress)
 	}
 
 	log.Infof("Waiting for VM to start (ssh -p %d docker@%s)...", d.SSHPort, d.IPAddress)
@@ -546,6 +577,45 @@ func (d *Driver) Start() error {
 	return WaitForTCPWithDelay(fmt.Sprintf("%s:%d", d.IPAddress, d.SSHPort), time.Second)
 }
 
+// bridgedNetArgs returns the QEMU arguments attaching the VM to the bridged network: the socket_vmnet running in bridged mode
+// on the interface, passed as fd 3 by socket_vmnet_client on macOS, or the bridge iface through qemu-bridge-helper on Linux
+func bridgedNetArgs(goos, mac, iface string) []string {
+	netdev := "socket,id=net0,fd=3"
+	if goos == "linux" {
+		netdev = fmt.Sprintf("bridge,id=net0,br=%s", iface)
+	}
+	return []string{"-device", fmt.Sprintf("virtio-net-pci,netdev=net0,mac=%s", mac), "-netdev", netdev}
+}
+
+// waitForStaticIP waits for the guest to answer on its static IP, or on the IP leased by DHCP
+// on the first boot, before minikube configured the static IP in the guest.
+func (d *Driver) waitForStaticIP() error {
+	// socket_vmnet writes the MAC address to the dhcp leases file with leading 0s stripped
+	mac := pkgdrivers.TrimMacAddress(d.MACAddress)
+	for i := 0; i < 60; i++ {
+		log.Debugf("Attempt %d", i)
+		if sshReachable(d.StaticIP, d.SSHPort) {
+			d.IPAddress = d.StaticIP
+			return nil
+		}
+		if ip, err := pkgdrivers.GetIPAddressByMACAddress(mac); err == nil && sshReachable(ip, d.SSHPort) {
+			d.IPAddress = ip
+			return nil
+		}
+		time.Sleep(2 * time.Second)
+	}
+	return fmt.Errorf("VM not reachable on its static IP %s, nor on an IP from the dhcp leases file", d.StaticIP)
+}
+
+func sshReachable(ip string, port int) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(ip, strconv.Itoa(port)), time.Second)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
 func isBootpdError(err error) bool {
 	if runtime.GOOS != "darwin" {
 		return false
@@ -762,93 +832,85 @@ func (d *Driver) generateUserdataDisk(userdataFile string) (string, error) {
 }
 
 func (d *Driver) RunQMPCommand(command string) (map[string]interface{}, error) {
+	ret, err := d.runQMP(command, nil)
+	if err != nil {
+		return nil, err
+	}
+	var response map[string]interface{}
+	if err := json.Unmarshal(ret, &response); err != nil {
+		return nil, errors.Wrap(err, "unmarshal command resp")
+	}
+	if strings.HasPrefix(command, "query-") {
+		return response, nil
+	}
+	// non-query commands should return an empty response
+	if len(response) != 0 {
+		return nil, fmt.Errorf("%s failed: %v", command, response)
+	}
+	return response, nil
+}
+
+// runQMP runs a QMP command with its arguments, if not nil, on the monitor of the VM, and returns the raw value it returned
+func (d *Driver) runQMP(command string, args interface{}) (json.RawMessage, error) {
 	// connect to monitor
 	conn, err := net.Dial("unix", d.monitorPath())
 	if err != nil {
 		return nil, errors.Wrap(err, "connect")
 	}
 	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		return nil, errors.Wrap(err, "set deadline")
+	}
 
+	dec := json.NewDecoder(conn)
+	enc := json.NewEncoder(conn)
 	// initial QMP response
-	var buf [1024]byte
-	nr, err := conn.Read(buf[:])
-	if err != nil {
+	var greeting map[string]interface{}
+	if err := dec.Decode(&greeting); err != nil {
 		return nil, errors.Wrap(err, "read initial resp")
 	}
-	type qmpInitialResponse struct {
-		QMP struct {
-			Version struct {
-				QEMU struct {
-					Micro int `json:"micro"`
-					Minor int `json:"minor"`
-					Major int `json:"major"`
-				} `json:"qemu"`
-				Package string `json:"package"`
-			} `json:"version"`
-			Capabilities []string `json:"capabilities"`
-		} `jason:"QMP"`
-	}
-
-	var initialResponse qmpInitialResponse
-	if err := json.Unmarshal(buf[:nr], &initialResponse); err != nil {
-		return nil, errors.Wrap(err, "unmarshal initial resp")
-	}
-
 	// run 'qmp_capabilities' to switch to command mode
-	// { "execute": "qmp_capabilities" }
-	type qmpCommand struct {
-		Command string `json:"execute"`
-	}
-	jsonCommand, err := json.Marshal(qmpCommand{Command: "qmp_capabilities"})
-	if err != nil {
-		return nil, errors.Wrap(err, "marshal qmp_capabilities")
-	}
-	if _, err := conn.Write(jsonCommand); err != nil {
+	if err := enc.Encode(map[string]string{"execute": "qmp_capabilities"}); err != nil {
 		return nil, errors.Wrap(err, "write qmp_capabilities")
 	}
-	nr, err = conn.Read(buf[:])
-	if err != nil {
-		return nil, errors.Wrap(err, "read qmp_capabilities resp")
-	}
-	type qmpResponse struct {
-		Return map[string]interface{} `json:"return"`
-	}
-	var response qmpResponse
-	if err := json.Unmarshal(buf[:nr], &response); err != nil {
-		return nil, errors.Wrap(err, "unmarshal qmp_capabilities resp")
-	}
-	// expecting empty response
-	if len(response.Return) != 0 {
-		return nil, fmt.Errorf("qmp_capabilities failed: %v", response.Return)
+	if _, err := qmpReturn(dec); err != nil {
+		return nil, errors.Wrap(err, "qmp_capabilities")
 	}
 
-	// { "execute": command }
-	jsonCommand, err = json.Marshal(qmpCommand{Command: command})
-	if err != nil {
-		return nil, errors.Wrap(err, "marshal command")
+	cmd := map[string]interface{}{"execute": command}
+	if args != nil {
+		cmd["arguments"] = args
 	}
-	if _, err := conn.Write(jsonCommand); err != nil {
+	if err := enc.Encode(cmd); err != nil {
 		return nil, errors.Wrap(err, "write command")
 	}
-	nr, err = conn.Read(buf[:])
+	ret, err := qmpReturn(dec)
 	if err != nil {
-		return nil, errors.Wrap(err, "read command resp")
+		return nil, errors.Wrap(err, command)
 	}
+	return ret, nil
+}
 
-	// Sometimes QEMU returns two JSON objects with the first object being the command response
-	// and the second object being an event log (unimportant)
-	firstRespObj := strings.Split(string(buf[:nr]), "\n")[0]
-	if err := json.Unmarshal([]byte(firstRespObj), &response); err != nil {
-		return nil, errors.Wrap(err, "unmarshal command resp")
-	}
-	if strings.HasPrefix(command, "query-") {
-		return response.Return, nil
-	}
-	// non-query commands should return an empty response
-	if len(response.Return) != 0 {
-		return nil, fmt.Errorf("%s failed: %v", command, response.Return)
+// qmpReturn reads the response to the last command, skipping the events sent in between
+func qmpReturn(dec *json.Decoder) (json.RawMessage, error) {
+	for {
+		var resp struct {
+			Return json.RawMessage `json:"return"`
+			Error  *struct {
+				Class string `json:"class"`
+				Desc  string `json:"desc"`
+			} `json:"error"`
+		}
+		if err := dec.Decode(&resp); err != nil {
+			return nil, errors.Wrap(err, "read resp")
+		}
+		if resp.Error != nil {
+			return nil, fmt.Errorf("%s: %s", resp.Error.Class, resp.Error.Desc)
+		}
+		if resp.Return != nil {
+			return resp.Return, nil
+		}
 	}
-	return response.Return, nil
 }
 
 func WaitForTCPWithDelay(addr string, duration time.Duration) error {
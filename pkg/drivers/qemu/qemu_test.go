@@ -0,0 +1,37 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package qemu
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBridgedNetArgs(t *testing.T) {
+	tests := []struct {
+		goos string
+		want string
+	}{
+		{"darwin", "-device virtio-net-pci,netdev=net0,mac=52:54:00:12:34:56 -netdev socket,id=net0,fd=3"},
+		{"linux", "-device virtio-net-pci,netdev=net0,mac=52:54:00:12:34:56 -netdev bridge,id=net0,br=br0"},
+	}
+	for _, tc := range tests {
+		if got := strings.Join(bridgedNetArgs(tc.goos, "52:54:00:12:34:56", "br0"), " "); got != tc.want {
+			t.Errorf("bridgedNetArgs(%s) = %q, want %q", tc.goos, got, tc.want)
+		}
+	}
+}
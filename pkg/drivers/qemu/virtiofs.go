@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package qemu
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/pkg/errors"
+
+	pkgdrivers "k8s.io/minikube/pkg/drivers"
+	"k8s.io/minikube/pkg/util/retry"
+)
+
+// virtiofsdPaths are where the Linux distributions install virtiofsd, outside of the PATH
+var virtiofsdPaths = []string{"/usr/libexec/virtiofsd", "/usr/lib/virtiofsd", "/usr/lib/qemu/virtiofsd"}
+
+// findVirtiofsd returns the path of the virtiofsd binary
+func findVirtiofsd() (string, error) {
+	if p, err := exec.LookPath("virtiofsd"); err == nil {
+		return p, nil
+	}
+	for _, p := range virtiofsdPaths {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", errors.New("virtiofsd not found, install the virtiofsd package of your distribution")
+}
+
+func (d *Driver) virtiofsSocketPath() string {
+	machineDir := filepath.Join(d.StorePath, "machines", d.GetMachineName())
+	return filepath.Join(machineDir, "virtiofs.sock")
+}
+
+// startVirtiofsd starts virtiofsd serving VirtiofsDir on the vhost-user socket of the VM, and waits for the socket.
+// virtiofsd exits by itself when qemu closes the socket, so it is never stopped by the driver.
+func (d *Driver) startVirtiofsd() error {
+	bin, err := findVirtiofsd()
+	if err != nil {
+		return err
+	}
+	sock := d.virtiofsSocketPath()
+	if err := os.Remove(sock); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "removing the stale virtiofs socket")
+	}
+	logFile, err := os.Create(filepath.Join(filepath.Dir(sock), "virtiofsd.log"))
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	// the namespace sandbox of virtiofsd requires root, minikube runs it as the user
+	cmd := exec.Command(bin, "--socket-path="+sock, "--shared-dir="+d.VirtiofsDir, "--cache=auto", "--sandbox=none")
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	log.Debugf("executing: %s", cmd.String())
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "starting virtiofsd")
+	}
+	go func() {
+		_ = cmd.Wait()
+	}()
+
+	waitSocket := func() error {
+		_, err := os.Stat(sock)
+		return err
+	}
+	if err := retry.Local(waitSocket, 10*time.Second); err != nil {
+		_ = cmd.Process.Kill()
+		return errors.Wrapf(err, "virtiofsd did not create %s, see %s", sock, logFile.Name())
+	}
+	return nil
+}
+
+// virtiofsArgs returns the qemu arguments of the virtiofs device, whose guest memory of memory MB must be shared with virtiofsd
+func virtiofsArgs(memory int, socket string) []string {
+	return []string{
+		"-object", fmt.Sprintf("memory-backend-memfd,id=mem,size=%dM,share=on", memory),
+		"-numa", "node,memdev=mem",
+		"-chardev", fmt.Sprintf("socket,id=virtiofs0,path=%s", socket),
+		"-device", fmt.Sprintf("vhost-user-fs-pci,queue-size=1024,chardev=virtiofs0,tag=%s", pkgdrivers.VirtiofsTag),
+	}
+}
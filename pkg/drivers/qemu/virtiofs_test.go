@@ -0,0 +1,36 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package qemu
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVirtiofsArgs(t *testing.T) {
+	args := strings.Join(virtiofsArgs(4096, "/home/me/.minikube/machines/minikube/virtiofs.sock"), " ")
+	for _, want := range []string{
+		"-object memory-backend-memfd,id=mem,size=4096M,share=on",
+		"-numa node,memdev=mem",
+		"-chardev socket,id=virtiofs0,path=/home/me/.minikube/machines/minikube/virtiofs.sock",
+		"-device vhost-user-fs-pci,queue-size=1024,chardev=virtiofs0,tag=minikube-mount",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("expected the virtiofs args to contain %q, got: %s", want, args)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tapnet manages the network of the VM drivers using a tap device on the host:
+// a /24 subnet with NAT to the outside, and dnsmasq serving DHCP and DNS to the VM.
+package tapnet
+
+import (
+	"fmt"
+	"hash/crc32"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/pkg/errors"
+)
+
+// subnetPrefix is the range the /24 networks of the VMs are allocated from
+const subnetPrefix = "172.30."
+
+// Network is the tap network of a VM
+type Network struct {
+	// MachineName is the name of the machine, which the tap device is named after
+	MachineName string
+	// Subnet is the /24 network of the tap device, the host being .1 and the VM .2
+	Subnet string
+	// MACAddress is the MAC address of the VM, which dnsmasq leases the guest IP to
+	MACAddress string
+	// StateDir is the directory holding the leases and the pidfile of dnsmasq
+	StateDir string
+}
+
+// TapName returns the name of the tap device of a machine, which must fit in IFNAMSIZ
+func TapName(machineName string) string {
+	return fmt.Sprintf("mktap%08x", crc32.ChecksumIEEE([]byte(machineName)))
+}
+
+// FreeSubnet returns the first 172.30.X.0/24 network not used by an interface of the host
+func FreeSubnet() (string, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", errors.Wrap(err, "list interface addresses")
+	}
+	return freeSubnet(addrs)
+}
+
+func freeSubnet(addrs []net.Addr) (string, error) {
+	used := map[string]bool{}
+	for _, a := range addrs {
+		if ipNet, ok := a.(*net.IPNet); ok && ipNet.IP.To4() != nil {
+			used[ipNet.IP.Mask(net.CIDRMask(24, 32)).String()] = true
+		}
+	}
+	for i := 0; i < 256; i++ {
+		subnet := fmt.Sprintf("%s%d.0", subnetPrefix, i)
+		if !used[subnet] {
+			return subnet + "/24", nil
+		}
+	}
+	return "", fmt.Errorf("no free subnet in %s0.0/16", subnetPrefix)
+}
+
+// subnetIP returns the IP of the host in a subnet
+func subnetIP(subnet string, host int) string {
+	ip, _, err := net.ParseCIDR(subnet)
+	if err != nil {
+		return ""
+	}
+	ip = ip.To4()
+	return net.IPv4(ip[0], ip[1], ip[2], byte(host)).String()
+}
+
+// GatewayIP returns the IP of the host on the tap device
+func GatewayIP(subnet string) string {
+	return subnetIP(subnet, 1)
+}
+
+// GuestIP returns the IP of the VM, leased by dnsmasq
+func GuestIP(subnet string) string {
+	return subnetIP(subnet, 2)
+}
+
+// natRules returns the iptables rules giving the VM access to the outside, as table and rule spec
+func natRules(subnet, tap string) [][]string {
+	return [][]string{
+		{"nat", "POSTROUTING", "-s", subnet, "!", "-o", tap, "-j", "MASQUERADE"},
+		{"filter", "FORWARD", "-i", tap, "-j", "ACCEPT"},
+		{"filter", "FORWARD", "-o", tap, "-m", "conntrack", "--ctstate", "RELATED,ESTABLISHED", "-j", "ACCEPT"},
+	}
+}
+
+// dnsmasqArgs returns the arguments of dnsmasq, serving DHCP and DNS to the VM on its tap device
+func (n *Network) dnsmasqArgs() []string {
+	return []string{
+		"--interface=" + TapName(n.MachineName),
+		"--bind-interfaces",
+		"--except-interface=lo",
+		fmt.Sprintf("--dhcp-range=%s,%s,255.255.255.0,infinite", GuestIP(n.Subnet), GuestIP(n.Subnet)),
+		fmt.Sprintf("--dhcp-host=%s,%s", n.MACAddress, GuestIP(n.Subnet)),
+		"--dhcp-leasefile=" + filepath.Join(n.StateDir, "dnsmasq.leases"),
+		"--pid-file=" + n.dnsmasqPidfilePath(),
+	}
+}
+
+func (n *Network) dnsmasqPidfilePath() string {
+	return filepath.Join(n.StateDir, "dnsmasq.pid")
+}
+
+// Setup creates the tap device of the VM with NAT to the outside, and starts dnsmasq on it
+func (n *Network) Setup() error {
+	tap := TapName(n.MachineName)
+	if _, err := net.InterfaceByName(tap); err != nil {
+		log.Infof("Creating tap device %s for %s", tap, n.Subnet)
+		if err := Sudo("ip", "tuntap", "add", "dev", tap, "mode", "tap", "user", strconv.Itoa(os.Getuid())); err != nil {
+			return err
+		}
+		if err := Sudo("ip", "addr", "add", GatewayIP(n.Subnet)+"/24", "dev", tap); err != nil {
+			return err
+		}
+	}
+	if err := Sudo("ip", "link", "set", tap, "up"); err != nil {
+		return err
+	}
+	if err := Sudo("sysctl", "-q", "-w", "net.ipv4.ip_forward=1"); err != nil {
+		return err
+	}
+	for _, r := range natRules(n.Subnet, tap) {
+		check := append([]string{"iptables", "-t", r[0], "-C"}, r[1:]...)
+		if exec.Command("sudo", check...).Run() == nil {
+			continue
+		}
+		if err := Sudo(append([]string{"iptables", "-t", r[0], "-A"}, r[1:]...)...); err != nil {
+			return err
+		}
+	}
+	if err := n.StopDNSMasq(); err != nil {
+		return err
+	}
+	return Sudo(append([]string{"dnsmasq"}, n.dnsmasqArgs()...)...)
+}
+
+// Teardown removes the NAT rules and the tap device of the VM
+func (n *Network) Teardown() error {
+	tap := TapName(n.MachineName)
+	for _, r := range natRules(n.Subnet, tap) {
+		if err := Sudo(append([]string{"iptables", "-t", r[0], "-D"}, r[1:]...)...); err != nil {
+			log.Debugf("delete iptables rule: %v", err)
+		}
+	}
+	if _, err := net.InterfaceByName(tap); err != nil {
+		return nil
+	}
+	return Sudo("ip", "link", "delete", tap)
+}
+
+// StopDNSMasq stops the dnsmasq of the VM, if running
+func (n *Network) StopDNSMasq() error {
+	b, err := os.ReadFile(n.dnsmasqPidfilePath())
+	if err != nil {
+		return nil
+	}
+	pid := strings.TrimSpace(string(b))
+	if _, err := strconv.Atoi(pid); err != nil {
+		return nil
+	}
+	// dnsmasq runs as root
+	if err := exec.Command("sudo", "kill", pid).Run(); err != nil {
+		log.Debugf("kill dnsmasq %s: %v", pid, err)
+	}
+	return Sudo("rm", "-f", n.dnsmasqPidfilePath())
+}
+
+// Sudo runs a command as root
+func Sudo(args ...string) error {
+	cmd := exec.Command("sudo", args...)
+	log.Debugf("executing: %s", strings.Join(cmd.Args, " "))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "%s: %s", strings.Join(cmd.Args, " "), strings.TrimSpace(string(out)))
+	}
+	return nil
+}
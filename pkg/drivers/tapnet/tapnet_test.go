@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tapnet
+
+import (
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestFreeSubnet(t *testing.T) {
+	cidr := func(s string) net.Addr {
+		ip, ipNet, err := net.ParseCIDR(s)
+		if err != nil {
+			t.Fatalf("parse %s: %v", s, err)
+		}
+		ipNet.IP = ip
+		return ipNet
+	}
+	tests := []struct {
+		addrs []net.Addr
+		want  string
+	}{
+		{nil, "172.30.0.0/24"},
+		{[]net.Addr{cidr("192.168.1.10/24"), cidr("172.30.0.1/24")}, "172.30.1.0/24"},
+		{[]net.Addr{cidr("172.30.0.1/24"), cidr("172.30.2.1/24")}, "172.30.1.0/24"},
+	}
+	for _, tc := range tests {
+		got, err := freeSubnet(tc.addrs)
+		if err != nil || got != tc.want {
+			t.Errorf("freeSubnet(%v) = %q, %v, want %q", tc.addrs, got, err, tc.want)
+		}
+	}
+
+	all := []net.Addr{}
+	for i := 0; i < 256; i++ {
+		all = append(all, cidr(fmt.Sprintf("172.30.%d.1/24", i)))
+	}
+	if _, err := freeSubnet(all); err == nil {
+		t.Errorf("freeSubnet() succeeded with all subnets used, want an error")
+	}
+}
+
+func TestSubnetIPs(t *testing.T) {
+	if got := GatewayIP("172.30.5.0/24"); got != "172.30.5.1" {
+		t.Errorf("GatewayIP() = %q, want 172.30.5.1", got)
+	}
+	if got := GuestIP("172.30.5.0/24"); got != "172.30.5.2" {
+		t.Errorf("GuestIP() = %q, want 172.30.5.2", got)
+	}
+}
+
+func TestTapName(t *testing.T) {
+	a, b := TapName("minikube"), TapName("minikube-m02")
+	if a == b {
+		t.Errorf("TapName() = %q for two machines", a)
+	}
+	// IFNAMSIZ is 16, including the terminating NUL
+	if len(a) > 15 {
+		t.Errorf("TapName() = %q, longer than 15 characters", a)
+	}
+}
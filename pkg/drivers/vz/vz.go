@@ -0,0 +1,439 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vz implements a driver running the minikube VM with Apple's Virtualization.framework,
+// through the vfkit command line tool (https://github.com/crc-org/vfkit).
+package vz
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+
+	pkgdrivers "k8s.io/minikube/pkg/drivers"
+)
+
+const (
+	isoFilename    = "boot2docker.iso"
+	defaultSSHUser = "docker"
+
+	// DriverName is the name of the driver
+	DriverName = "vz"
+
+	// NetworkNAT attaches the VM to the macOS shared network, NetworkBridged to the network of a host interface
+	NetworkNAT     = "nat"
+	NetworkBridged = "bridged"
+
+	// rosettaMountTag is the virtiofs tag of the Rosetta share, and rosettaDir where it is mounted in the guest
+	rosettaMountTag = "rosetta"
+	rosettaDir      = "/mnt/rosetta"
+	// rosettaBinfmt registers Rosetta as the binfmt_misc interpreter of x86_64 ELF binaries
+	rosettaBinfmt = `:rosetta:M::\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00\x3e\x00:\xff\xff\xff\xff\xff\xfe\xfe\x00\xff\xff\xff\xff\xff\xff\xff\xff\xfe\xff\xff\xff:` + rosettaDir + `/rosetta:F`
+)
+
+// Driver is the vz driver
+type Driver struct {
+	*drivers.BaseDriver
+	*pkgdrivers.CommonDriver
+	Boot2DockerURL string
+	DiskSize       int
+	Memory         int
+	CPU            int
+	MACAddress     string
+	ExtraDisks     int
+	// Program is the path of the vfkit binary
+	Program string
+	// Rosetta runs amd64 binaries in the guest with Rosetta, only on Apple silicon
+	Rosetta bool
+	// SharedFolders are shared with virtiofs, in the HOST_PATH:GUEST_PATH format
+	SharedFolders []string
+	// VirtiofsDir is the host directory of the virtiofs mount of minikube, shared under pkgdrivers.VirtiofsTag, if set
+	VirtiofsDir string
+	// Network is "nat" for the macOS shared network, or "bridged" for a socket_vmnet bridged to BridgeInterface
+	Network               string
+	BridgeInterface       string
+	SocketVMNetPath       string
+	SocketVMNetClientPath string
+}
+
+// NewDriver creates a new vz driver
+func NewDriver(hostName, storePath string) drivers.Driver {
+	return &Driver{
+		Program: "vfkit",
+		BaseDriver: &drivers.BaseDriver{
+			SSHUser:     defaultSSHUser,
+			MachineName: hostName,
+			StorePath:   storePath,
+		},
+	}
+}
+
+// DriverName returns the name of the driver
+func (d *Driver) DriverName() string {
+	return DriverName
+}
+
+// GetSSHHostname returns hostname for use with ssh
+func (d *Driver) GetSSHHostname() (string, error) {
+	return d.IPAddress, nil
+}
+
+// GetSSHKeyPath returns the path of the SSH key of the machine
+func (d *Driver) GetSSHKeyPath() string {
+	return d.ResolveStorePath("id_rsa")
+}
+
+// GetSSHUsername returns the user name for SSH
+func (d *Driver) GetSSHUsername() string {
+	if d.SSHUser == "" {
+		d.SSHUser = defaultSSHUser
+	}
+	return d.SSHUser
+}
+
+// GetURL returns a Docker URL inside this host
+func (d *Driver) GetURL() (string, error) {
+	if d.IPAddress == "" {
+		return "", nil
+	}
+	return fmt.Sprintf("tcp://%s:2376", d.IPAddress), nil
+}
+
+// GetIP returns the IP address of the VM
+func (d *Driver) GetIP() (string, error) {
+	return d.IPAddress, nil
+}
+
+// PreCreateCheck checks that vfkit is installed and the requested features are available
+func (d *Driver) PreCreateCheck() error {
+	if _, err := exec.LookPath(d.Program); err != nil {
+		return errors.Wrapf(err, "%s not found", d.Program)
+	}
+	if d.Network == NetworkBridged {
+		for _, p := range []string{d.SocketVMNetClientPath, d.SocketVMNetPath} {
+			if _, err := os.Stat(p); err != nil {
+				return errors.Wrap(err, "the bridged network requires socket_vmnet running in bridged mode")
+			}
+		}
+	}
+	if d.Rosetta && runtime.GOARCH != "arm64" {
+		return fmt.Errorf("rosetta is only available on Apple silicon")
+	}
+	for _, f := range d.SharedFolders {
+		if _, _, err := ParseSharedFolder(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Create creates the disks of the VM and starts it
+func (d *Driver) Create() error {
+	if err := pkgdrivers.MakeDiskImage(d.BaseDriver, d.Boot2DockerURL, d.DiskSize); err != nil {
+		return errors.Wrap(err, "making disk image")
+	}
+	for i := 0; i < d.ExtraDisks; i++ {
+		if err := pkgdrivers.CreateRawDisk(pkgdrivers.ExtraDiskPath(d.BaseDriver, i), d.DiskSize); err != nil {
+			return err
+		}
+	}
+	log.Info("Starting vz VM...")
+	return d.Start()
+}
+
+// Start starts the VM with vfkit, and waits for it to get an IP and SSH to be up
+func (d *Driver) Start() error {
+	logFile, err := os.OpenFile(d.ResolveStorePath("vfkit.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return errors.Wrap(err, "open vfkit log")
+	}
+	defer logFile.Close()
+
+	os.Remove(d.socketPath())
+	cmd := exec.Command(d.Program, d.startArgs()...)
+	if d.Network == NetworkBridged {
+		// socket_vmnet_client passes the connection to socket_vmnet as fd 3, and execs vfkit
+		cmd = exec.Command(d.SocketVMNetClientPath, append([]string{d.SocketVMNetPath, d.Program}, d.startArgs()...)...)
+	}
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	log.Debugf("executing: %s", strings.Join(cmd.Args, " "))
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "start vfkit")
+	}
+	if err := os.WriteFile(d.pidfilePath(), []byte(strconv.Itoa(cmd.Process.Pid)), 0600); err != nil {
+		return errors.Wrap(err, "write pidfile")
+	}
+	// vfkit outlives minikube, do not keep a child process around
+	if err := cmd.Process.Release(); err != nil {
+		log.Debugf("release vfkit process: %v", err)
+	}
+
+	mac := pkgdrivers.TrimMacAddress(d.MACAddress)
+	for i := 0; i < 60; i++ {
+		if d.Network == NetworkBridged {
+			d.IPAddress, err = pkgdrivers.BridgedIP(d.BridgeInterface, mac)
+		} else {
+			d.IPAddress, err = pkgdrivers.GetIPAddressByMACAddress(mac)
+		}
+		if err == nil {
+			break
+		}
+		log.Debugf("Attempt %d: %v", i, err)
+		time.Sleep(2 * time.Second)
+	}
+	if err != nil {
+		if d.Network == NetworkBridged {
+			return errors.Wrapf(err, "IP address never found in the ARP table of %s", d.BridgeInterface)
+		}
+		return errors.Wrap(err, "IP address never found in dhcp leases file")
+	}
+
+	log.Infof("Waiting for VM to start (ssh -p 22 docker@%s)...", d.IPAddress)
+	if err := pkgdrivers.WaitForTCP(net.JoinHostPort(d.IPAddress, "22"), 3*time.Minute); err != nil {
+		return err
+	}
+	return d.setupGuest()
+}
+
+// startArgs returns the vfkit arguments to start the VM
+func (d *Driver) startArgs() []string {
+	bootloader := "efi,variable-store=" + d.ResolveStorePath("efi-variable-store")
+	if _, err := os.Stat(d.ResolveStorePath("efi-variable-store")); err != nil {
+		bootloader += ",create"
+	}
+	args := []string{
+		"--cpus", strconv.Itoa(d.CPU),
+		"--memory", strconv.Itoa(d.Memory),
+		"--bootloader", bootloader,
+		"--device", fmt.Sprintf("usb-mass-storage,path=%s,readonly", d.ResolveStorePath(isoFilename)),
+		"--device", fmt.Sprintf("virtio-blk,path=%s", pkgdrivers.GetDiskPath(d.BaseDriver)),
+	}
+	for i := 0; i < d.ExtraDisks; i++ {
+		args = append(args, "--device", fmt.Sprintf("virtio-blk,path=%s", pkgdrivers.ExtraDiskPath(d.BaseDriver, i)))
+	}
+	netdev := fmt.Sprintf("virtio-net,nat,mac=%s", d.MACAddress)
+	if d.Network == NetworkBridged {
+		netdev = fmt.Sprintf("virtio-net,fd=3,mac=%s", d.MACAddress)
+	}
+	args = append(args,
+		"--device", netdev,
+		"--device", fmt.Sprintf("virtio-serial,logFilePath=%s", d.ResolveStorePath("console.log")),
+		"--device", "virtio-rng",
+	)
+	for i, f := range d.SharedFolders {
+		host, _, err := ParseSharedFolder(f)
+		if err != nil {
+			continue
+		}
+		args = append(args, "--device", fmt.Sprintf("virtio-fs,sharedDir=%s,mountTag=%s", host, sharedFolderTag(i)))
+	}
+	if d.VirtiofsDir != "" {
+		args = append(args, "--device", fmt.Sprintf("virtio-fs,sharedDir=%s,mountTag=%s", d.VirtiofsDir, pkgdrivers.VirtiofsTag))
+	}
+	if d.Rosetta {
+		args = append(args, "--device", "rosetta,mountTag="+rosettaMountTag)
+	}
+	return append(args, "--restful-uri", "unix://"+d.socketPath())
+}
+
+// setupGuest mounts the virtiofs shares and registers Rosetta in the guest
+func (d *Driver) setupGuest() error {
+	for _, c := range d.guestCommands() {
+		if _, err := drivers.RunSSHCommandFromDriver(d, c); err != nil {
+			return errors.Wrapf(err, "running %q in the guest", c)
+		}
+	}
+	return nil
+}
+
+// guestCommands returns the commands to run in the guest after each boot
+func (d *Driver) guestCommands() []string {
+	cmds := []string{}
+	for i, f := range d.SharedFolders {
+		_, guest, err := ParseSharedFolder(f)
+		if err != nil {
+			continue
+		}
+		cmds = append(cmds, fmt.Sprintf("sudo mkdir -p %s && sudo mount -t virtiofs %s %s", guest, sharedFolderTag(i), guest))
+	}
+	if d.Rosetta {
+		cmds = append(cmds, fmt.Sprintf("sudo mkdir -p %s && sudo mount -t virtiofs %s %s", rosettaDir, rosettaMountTag, rosettaDir),
+			fmt.Sprintf("[ -e /proc/sys/fs/binfmt_misc/rosetta ] || echo '%s' | sudo tee /proc/sys/fs/binfmt_misc/register", rosettaBinfmt))
+	}
+	return cmds
+}
+
+// ParseSharedFolder parses a shared folder in the HOST_PATH:GUEST_PATH format
+func ParseSharedFolder(f string) (string, string, error) {
+	host, guest, ok := strings.Cut(f, ":")
+	if !ok || !filepath.IsAbs(host) || !strings.HasPrefix(guest, "/") {
+		return "", "", fmt.Errorf("invalid shared folder %q, expected an absolute HOST_PATH:GUEST_PATH", f)
+	}
+	return host, guest, nil
+}
+
+func sharedFolderTag(i int) string {
+	return fmt.Sprintf("minikube-share-%d", i)
+}
+
+// GetState returns the state of the VM, as reported by vfkit
+func (d *Driver) GetState() (state.State, error) {
+	pid, err := d.pid()
+	if err != nil {
+		return state.Stopped, nil
+	}
+	if err := pkgdrivers.CheckPid(pid); err != nil {
+		// No process, remove the stale pidfile
+		os.Remove(d.pidfilePath())
+		return state.Stopped, nil
+	}
+
+	var resp struct {
+		State string `json:"state"`
+	}
+	if err := d.rest(http.MethodGet, nil, &resp); err != nil {
+		return state.Error, err
+	}
+	return vmState(resp.State), nil
+}
+
+// vmState converts a VZVirtualMachineState reported by vfkit to a libmachine state
+func vmState(s string) state.State {
+	switch s {
+	case "VirtualMachineStateRunning":
+		return state.Running
+	case "VirtualMachineStateStopped":
+		return state.Stopped
+	case "VirtualMachineStatePaused":
+		return state.Paused
+	case "VirtualMachineStateStarting", "VirtualMachineStateResuming":
+		return state.Starting
+	case "VirtualMachineStateStopping", "VirtualMachineStatePausing":
+		return state.Stopping
+	case "VirtualMachineStateError":
+		return state.Error
+	}
+	return state.None
+}
+
+// Stop asks the guest to shut down
+func (d *Driver) Stop() error {
+	return d.setState("Stop")
+}
+
+// Kill stops the VM immediately
+func (d *Driver) Kill() error {
+	if err := d.setState("HardStop"); err == nil {
+		return nil
+	}
+	pid, err := d.pid()
+	if err != nil {
+		return nil
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return nil
+	}
+	return p.Kill()
+}
+
+// Remove stops the VM, the machine dir is deleted by the caller
+func (d *Driver) Remove() error {
+	s, err := d.GetState()
+	if err != nil || s != state.Stopped {
+		if err := d.Kill(); err != nil {
+			return errors.Wrap(err, "kill")
+		}
+	}
+	os.Remove(d.pidfilePath())
+	os.Remove(d.socketPath())
+	return nil
+}
+
+// Restart stops and starts the VM
+func (d *Driver) Restart() error {
+	return pkgdrivers.Restart(d)
+}
+
+func (d *Driver) setState(s string) error {
+	return d.rest(http.MethodPost, map[string]string{"state": s}, nil)
+}
+
+// rest calls the vfkit REST API on /vm/state
+func (d *Driver) rest(method string, body interface{}, resp interface{}) error {
+	client := &http.Client{
+		Timeout: 5 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", d.socketPath())
+			},
+		},
+	}
+	var b bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&b).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, "http://vfkit/vm/state", &b)
+	if err != nil {
+		return err
+	}
+	r, err := client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "vfkit API")
+	}
+	defer r.Body.Close()
+	if r.StatusCode >= 300 {
+		return fmt.Errorf("vfkit API %s /vm/state: %s", method, r.Status)
+	}
+	if resp == nil {
+		return nil
+	}
+	return json.NewDecoder(r.Body).Decode(resp)
+}
+
+func (d *Driver) pid() (int, error) {
+	p, err := os.ReadFile(d.pidfilePath())
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(p)))
+}
+
+func (d *Driver) pidfilePath() string {
+	return d.ResolveStorePath("vfkit.pid")
+}
+
+func (d *Driver) socketPath() string {
+	return d.ResolveStorePath("vfkit.sock")
+}
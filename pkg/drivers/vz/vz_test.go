@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vz
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/state"
+)
+
+func TestStartArgs(t *testing.T) {
+	d := &Driver{
+		BaseDriver:    &drivers.BaseDriver{MachineName: "minikube", StorePath: t.TempDir()},
+		CPU:           2,
+		Memory:        4096,
+		MACAddress:    "5a:94:ef:e4:0c:ee",
+		ExtraDisks:    1,
+		Rosetta:       true,
+		SharedFolders: []string{"/Users:/Users", "invalid"},
+		VirtiofsDir:   "/Users/me/src",
+	}
+	args := strings.Join(d.startArgs(), " ")
+	for _, want := range []string{
+		"--cpus 2 --memory 4096",
+		"--bootloader efi,variable-store=",
+		"efi-variable-store,create",
+		"usb-mass-storage,path=",
+		"virtio-blk,path=" + d.ResolveStorePath("minikube.rawdisk"),
+		"virtio-blk,path=" + d.ResolveStorePath("minikube-0.rawdisk"),
+		"virtio-net,nat,mac=5a:94:ef:e4:0c:ee",
+		"virtio-fs,sharedDir=/Users,mountTag=minikube-share-0",
+		"virtio-fs,sharedDir=/Users/me/src,mountTag=minikube-mount",
+		"rosetta,mountTag=rosetta",
+		"--restful-uri unix://" + d.ResolveStorePath("vfkit.sock"),
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("expected vfkit args to contain %q, got: %s", want, args)
+		}
+	}
+	if strings.Contains(args, "minikube-share-1") {
+		t.Errorf("expected the invalid shared folder to be skipped, got: %s", args)
+	}
+}
+
+func TestGuestCommands(t *testing.T) {
+	d := &Driver{SharedFolders: []string{"/Users/me/src:/src"}}
+	cmds := d.guestCommands()
+	if len(cmds) != 1 || cmds[0] != "sudo mkdir -p /src && sudo mount -t virtiofs minikube-share-0 /src" {
+		t.Errorf("unexpected guest commands: %q", cmds)
+	}
+
+	d.Rosetta = true
+	cmds = d.guestCommands()
+	if len(cmds) != 3 || !strings.Contains(cmds[2], "binfmt_misc/register") {
+		t.Errorf("expected rosetta to be mounted and registered, got: %q", cmds)
+	}
+}
+
+func TestParseSharedFolder(t *testing.T) {
+	tests := []struct {
+		in      string
+		host    string
+		guest   string
+		wantErr bool
+	}{
+		{in: "/Users:/Users", host: "/Users", guest: "/Users"},
+		{in: "/Users/me/src:/src", host: "/Users/me/src", guest: "/src"},
+		{in: "/Users", wantErr: true},
+		{in: "relative:/src", wantErr: true},
+		{in: "/Users:relative", wantErr: true},
+	}
+	for _, tc := range tests {
+		host, guest, err := ParseSharedFolder(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseSharedFolder(%q) error = %v, wantErr %v", tc.in, err, tc.wantErr)
+			continue
+		}
+		if host != tc.host || guest != tc.guest {
+			t.Errorf("ParseSharedFolder(%q) = %q, %q; want %q, %q", tc.in, host, guest, tc.host, tc.guest)
+		}
+	}
+}
+
+func TestVMState(t *testing.T) {
+	tests := map[string]state.State{
+		"VirtualMachineStateRunning":  state.Running,
+		"VirtualMachineStateStopped":  state.Stopped,
+		"VirtualMachineStatePaused":   state.Paused,
+		"VirtualMachineStateStarting": state.Starting,
+		"VirtualMachineStateStopping": state.Stopping,
+		"VirtualMachineStateError":    state.Error,
+		"unknown":                     state.None,
+	}
+	for in, want := range tests {
+		if got := vmState(in); got != want {
+			t.Errorf("vmState(%q) = %s; want %s", in, got, want)
+		}
+	}
+}
+
+func TestStartArgsBridged(t *testing.T) {
+	d := &Driver{
+		BaseDriver: &drivers.BaseDriver{MachineName: "minikube", StorePath: t.TempDir()},
+		MACAddress: "5a:94:ef:e4:0c:ee",
+		Network:    NetworkBridged,
+	}
+	args := strings.Join(d.startArgs(), " ")
+	if !strings.Contains(args, "virtio-net,fd=3,mac=5a:94:ef:e4:0c:ee") || strings.Contains(args, "nat") {
+		t.Errorf("expected the bridged network on fd 3, got: %s", args)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wsl
+
+import (
+	"os/exec"
+	"strconv"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/pkg/errors"
+)
+
+// proxyPorts forwards the ports of the distro from the Windows host with netsh, as the IP of the distro changes on every boot.
+// WSL already forwards the loopback of the host to the distros, so only the ports on other addresses, or the ones the
+// localhost forwarding of WSL does not reach, are proxied. netsh requires an elevated prompt.
+func (d *Driver) proxyPorts(forwarded bool) error {
+	addr := d.ListenAddress
+	if addr == "" || addr == loopback {
+		if forwarded {
+			return nil
+		}
+		log.Infof("localhost forwarding of WSL is disabled, proxying the ports of %s with netsh", d.MachineName)
+		addr = loopback
+	}
+	for _, p := range d.proxiedPorts() {
+		if err := portProxy("delete", addr, p, ""); err != nil {
+			log.Debugf("no previous port proxy on %s:%d: %v", addr, p, err)
+		}
+		if err := portProxy("add", addr, p, d.IPAddress); err != nil {
+			return errors.Wrap(err, "proxying ports requires running minikube as Administrator")
+		}
+	}
+	return nil
+}
+
+// removePortProxies removes the port proxies of the distro
+func (d *Driver) removePortProxies() error {
+	var lastErr error
+	for _, addr := range []string{d.ListenAddress, loopback} {
+		if addr == "" {
+			continue
+		}
+		for _, p := range d.proxiedPorts() {
+			if err := portProxy("delete", addr, p, ""); err != nil {
+				lastErr = err
+			}
+		}
+	}
+	return lastErr
+}
+
+// proxiedPorts returns the ports of the distro reachable from the Windows host
+func (d *Driver) proxiedPorts() []int {
+	ports := []int{d.SSHPort}
+	if d.APIServerPort != 0 {
+		ports = append(ports, d.APIServerPort)
+	}
+	return ports
+}
+
+// portProxy adds or deletes the netsh proxy from addr:port of the Windows host to the same port of the distro
+func portProxy(op, addr string, port int, ip string) error {
+	args := []string{"interface", "portproxy", op, "v4tov4", "listenaddress=" + addr, "listenport=" + strconv.Itoa(port)}
+	if op == "add" {
+		args = append(args, "connectaddress="+ip, "connectport="+strconv.Itoa(port))
+	}
+	out, err := exec.Command("netsh", args...).CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "netsh %v: %s", args, out)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wsl
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"runtime"
+	"unicode/utf16"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+)
+
+// Status returns an error if the installed WSL cannot run the distro: systemd requires WSL from the Microsoft Store,
+// the only one supporting --version
+func Status() error {
+	if _, err := wsl(nil, "--version"); err != nil {
+		return errors.Wrap(err, "WSL 2 from the Microsoft Store is required")
+	}
+	return nil
+}
+
+// writeRootfs writes the flattened layers of the image ref, which is the root filesystem of the distro
+func writeRootfs(w io.Writer, ref string) error {
+	r, err := name.ParseReference(ref)
+	if err != nil {
+		return errors.Wrapf(err, "parsing %s", ref)
+	}
+	p := v1.Platform{OS: "linux", Architecture: runtime.GOARCH}
+	img, err := remote.Image(r, remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithPlatform(p))
+	if err != nil {
+		return errors.Wrapf(err, "fetching %s", ref)
+	}
+	return flatten(w, img)
+}
+
+// flatten writes the filesystem of img as a tarball
+func flatten(w io.Writer, img v1.Image) error {
+	fs := mutate.Extract(img)
+	defer fs.Close()
+	tw := tar.NewWriter(w)
+	tr := tar.NewReader(fs)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "reading image filesystem")
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
+
+// decode returns the output of wsl.exe as a string: older versions of WSL ignore WSL_UTF8 and write UTF-16
+func decode(b []byte) string {
+	bom := []byte{0xff, 0xfe}
+	utf16le := bytes.HasPrefix(b, bom)
+	b = bytes.TrimPrefix(b, bom)
+	if !utf16le {
+		// UTF-16LE text of ASCII characters has a NUL every other byte
+		utf16le = len(b) >= 2
+		for i := 1; i < len(b) && utf16le; i += 2 {
+			utf16le = b[i] == 0
+		}
+	}
+	if !utf16le || len(b)%2 != 0 {
+		return string(b)
+	}
+	u := make([]uint16, len(b)/2)
+	for i := range u {
+		u[i] = uint16(b[2*i]) | uint16(b[2*i+1])<<8
+	}
+	return string(utf16.Decode(u))
+}
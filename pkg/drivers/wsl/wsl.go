@@ -0,0 +1,325 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package wsl implements a driver running the minikube node as a dedicated WSL2 distro
+// imported from the kic base image, for Windows hosts without Docker Desktop or Hyper-V.
+package wsl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/ssh"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+
+	pkgdrivers "k8s.io/minikube/pkg/drivers"
+)
+
+const (
+	defaultSSHUser = "docker"
+	loopback       = "127.0.0.1"
+
+	// DriverName is the name of the driver
+	DriverName = "wsl"
+)
+
+// wslExe is the command managing the WSL distros
+var wslExe = "wsl.exe"
+
+// Driver is the wsl driver
+type Driver struct {
+	*drivers.BaseDriver
+	*pkgdrivers.CommonDriver
+	// Image is the kic base image the distro is imported from
+	Image string
+	// APIServerPort is the port of the API server, proxied to the Windows host
+	APIServerPort int
+	// ListenAddress is the address of the Windows host the ports are proxied on
+	ListenAddress string
+}
+
+// NewDriver creates a new wsl driver
+func NewDriver(hostName, storePath string) drivers.Driver {
+	return &Driver{
+		BaseDriver: &drivers.BaseDriver{
+			SSHUser:     defaultSSHUser,
+			MachineName: hostName,
+			StorePath:   storePath,
+		},
+	}
+}
+
+// DriverName returns the name of the driver
+func (d *Driver) DriverName() string {
+	return DriverName
+}
+
+// GetSSHHostname returns hostname for use with ssh, the distros of WSL are reached through the loopback of the Windows host
+func (d *Driver) GetSSHHostname() (string, error) {
+	return loopback, nil
+}
+
+// GetSSHKeyPath returns the path of the SSH key of the machine
+func (d *Driver) GetSSHKeyPath() string {
+	return d.ResolveStorePath("id_rsa")
+}
+
+// GetSSHUsername returns the user name for SSH
+func (d *Driver) GetSSHUsername() string {
+	if d.SSHUser == "" {
+		d.SSHUser = defaultSSHUser
+	}
+	return d.SSHUser
+}
+
+// GetURL returns a Docker URL inside this host
+func (d *Driver) GetURL() (string, error) {
+	ip, err := d.GetIP()
+	if err != nil || ip == "" {
+		return "", err
+	}
+	return fmt.Sprintf("tcp://%s:2376", ip), nil
+}
+
+// GetIP returns the IPv4 address of the distro on the virtual network of WSL
+func (d *Driver) GetIP() (string, error) {
+	if d.IPAddress != "" {
+		return d.IPAddress, nil
+	}
+	o, err := d.exec(nil, "hostname", "-I")
+	if err != nil {
+		return "", errors.Wrap(err, "hostname")
+	}
+	fields := strings.Fields(o)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("%s has no IP address", d.MachineName)
+	}
+	return fields[0], nil
+}
+
+// PreCreateCheck checks that WSL 2 is installed
+func (d *Driver) PreCreateCheck() error {
+	return Status()
+}
+
+// Create imports the kic base image as a distro, configures systemd and SSH in it, and starts it
+func (d *Driver) Create() error {
+	dir := d.ResolveStorePath("distro")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	log.Infof("Importing %s as the WSL distro %s...", d.Image, d.MachineName)
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(writeRootfs(pw, d.Image))
+	}()
+	_, err := wsl(pr, "--import", d.MachineName, dir, "-", "--version", "2")
+	// unblock the writer if wsl.exe did not read the whole rootfs
+	pr.Close()
+	if err != nil {
+		return errors.Wrap(err, "import distro")
+	}
+
+	if d.SSHPort == 0 {
+		if d.SSHPort, err = freePort(); err != nil {
+			return errors.Wrap(err, "ssh port")
+		}
+	}
+	if err := ssh.GenerateSSHKey(d.GetSSHKeyPath()); err != nil {
+		return errors.Wrap(err, "generate ssh key")
+	}
+	pub, err := os.ReadFile(d.GetSSHKeyPath() + ".pub")
+	if err != nil {
+		return err
+	}
+	// the distro boots without systemd until wsl.conf is written
+	files := map[string]string{
+		"/etc/wsl.conf": wslConf(d.MachineName),
+		// the distros of WSL share a network, sshd listens on a port of its own
+		"/etc/ssh/sshd_config.d/minikube.conf": fmt.Sprintf("Port %d\n", d.SSHPort),
+		"/home/docker/.ssh/authorized_keys":    string(pub),
+	}
+	for path, content := range files {
+		if _, err := d.exec(strings.NewReader(content), "sh", "-c", fmt.Sprintf("mkdir -p %s && cat > %s", filepath.ToSlash(filepath.Dir(path)), path)); err != nil {
+			return errors.Wrapf(err, "write %s", path)
+		}
+	}
+	if _, err := wsl(nil, "--terminate", d.MachineName); err != nil {
+		return errors.Wrap(err, "terminate distro")
+	}
+	return d.Start()
+}
+
+// wslConf returns the WSL configuration of the distro: systemd boots the node, and Windows interop is disabled
+func wslConf(hostname string) string {
+	return fmt.Sprintf(`[boot]
+systemd=true
+
+[network]
+hostname=%s
+generateHosts=false
+
+[interop]
+enabled=false
+appendWindowsPath=false
+
+[user]
+default=root
+`, hostname)
+}
+
+// Start boots the distro, keeps it running, and waits for SSH to be up
+func (d *Driver) Start() error {
+	// WSL shuts idle distros down, keep a process running in it
+	keepAlive := exec.Command(wslExe, "--distribution", d.MachineName, "--exec", "/bin/sleep", "infinity")
+	if err := keepAlive.Start(); err != nil {
+		return errors.Wrap(err, "start distro")
+	}
+	if err := keepAlive.Process.Release(); err != nil {
+		return err
+	}
+
+	d.IPAddress = ""
+	deadline := time.Now().Add(2 * time.Minute)
+	for d.IPAddress == "" {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for the IP of %s", d.MachineName)
+		}
+		time.Sleep(time.Second)
+		ip, err := d.GetIP()
+		if err != nil {
+			log.Debugf("waiting for the IP of %s: %v", d.MachineName, err)
+			continue
+		}
+		d.IPAddress = ip
+	}
+
+	addr := net.JoinHostPort(loopback, strconv.Itoa(d.SSHPort))
+	log.Infof("Waiting for the distro to start (ssh -p %d docker@%s)...", d.SSHPort, loopback)
+	forwarded := pkgdrivers.WaitForTCP(addr, time.Minute) == nil
+	if err := d.proxyPorts(forwarded); err != nil {
+		return err
+	}
+	if forwarded {
+		return nil
+	}
+	return pkgdrivers.WaitForTCP(addr, 2*time.Minute)
+}
+
+// GetState returns the state of the distro, as listed by wsl.exe
+func (d *Driver) GetState() (state.State, error) {
+	all, err := wsl(nil, "--list", "--quiet")
+	if err != nil {
+		return state.Error, errors.Wrap(err, "list distros")
+	}
+	if !containsLine(all, d.MachineName) {
+		return state.None, nil
+	}
+	running, err := wsl(nil, "--list", "--running", "--quiet")
+	if err != nil {
+		// wsl.exe fails when no distro is running
+		log.Debugf("list running distros: %v", err)
+		return state.Stopped, nil
+	}
+	if containsLine(running, d.MachineName) {
+		return state.Running, nil
+	}
+	return state.Stopped, nil
+}
+
+// Stop terminates the distro
+func (d *Driver) Stop() error {
+	d.IPAddress = ""
+	_, err := wsl(nil, "--terminate", d.MachineName)
+	return err
+}
+
+// Kill terminates the distro, WSL does not shut distros down gracefully
+func (d *Driver) Kill() error {
+	return d.Stop()
+}
+
+// Remove unregisters the distro, which deletes its disk, and removes the port proxies
+func (d *Driver) Remove() error {
+	s, err := d.GetState()
+	if err != nil {
+		return err
+	}
+	if err := d.removePortProxies(); err != nil {
+		log.Warnf("removing port proxies: %v", err)
+	}
+	if s == state.None {
+		return nil
+	}
+	_, err = wsl(nil, "--unregister", d.MachineName)
+	return err
+}
+
+// Restart terminates and starts the distro
+func (d *Driver) Restart() error {
+	return pkgdrivers.Restart(d)
+}
+
+// exec runs a command in the distro as root, bypassing the login shell
+func (d *Driver) exec(stdin io.Reader, args ...string) (string, error) {
+	return wsl(stdin, append([]string{"--distribution", d.MachineName, "--user", "root", "--exec"}, args...)...)
+}
+
+// wsl runs wsl.exe, and returns its decoded output
+func wsl(stdin io.Reader, args ...string) (string, error) {
+	cmd := exec.Command(wslExe, args...)
+	cmd.Stdin = stdin
+	// wsl.exe writes its own messages in UTF-16, unless WSL_UTF8 is set
+	cmd.Env = append(os.Environ(), "WSL_UTF8=1")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s %s: %v: %s", wslExe, strings.Join(args, " "), err, decode(stderr.Bytes())+decode(stdout.Bytes()))
+	}
+	return decode(stdout.Bytes()), nil
+}
+
+// containsLine returns whether a line of the output of wsl.exe is s
+func containsLine(output, s string) bool {
+	for _, l := range strings.Split(output, "\n") {
+		if strings.TrimSpace(l) == s {
+			return true
+		}
+	}
+	return false
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", net.JoinHostPort(loopback, "0"))
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
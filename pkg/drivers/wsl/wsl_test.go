@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wsl
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+// fakeWSL replaces wsl.exe with a script listing the distros it is given, running and stopped
+func fakeWSL(t *testing.T, running, stopped string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake wsl.exe is a shell script")
+	}
+	script := `#!/bin/sh
+case "$*" in
+"--list --quiet") printf '%s\n' ` + running + ` ` + stopped + ` ;;
+"--list --running --quiet") [ -n "` + running + `" ] || exit 1; printf '%s\n' ` + running + ` ;;
+*"hostname -I") echo "172.28.1.2 10.244.0.1" ;;
+esac
+`
+	p := filepath.Join(t.TempDir(), "wsl.exe")
+	if err := os.WriteFile(p, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := wslExe
+	wslExe = p
+	t.Cleanup(func() { wslExe = old })
+}
+
+func TestGetState(t *testing.T) {
+	d := NewDriver("minikube", "/home/user/.minikube").(*Driver)
+	tests := []struct {
+		running, stopped string
+		want             state.State
+	}{
+		{"Ubuntu minikube", "", state.Running},
+		{"Ubuntu", "minikube", state.Stopped},
+		{"", "minikube", state.Stopped},
+		{"", "Ubuntu minikube-m02", state.None},
+	}
+	for _, tc := range tests {
+		fakeWSL(t, tc.running, tc.stopped)
+		if s, err := d.GetState(); err != nil || s != tc.want {
+			t.Errorf("GetState() with running %q and stopped %q = %s, %v, want %s", tc.running, tc.stopped, s, err, tc.want)
+		}
+	}
+}
+
+func TestGetIP(t *testing.T) {
+	fakeWSL(t, "minikube", "")
+	d := NewDriver("minikube", "/home/user/.minikube").(*Driver)
+	if ip, err := d.GetIP(); err != nil || ip != "172.28.1.2" {
+		t.Errorf("GetIP() = %q, %v, want the first address of the distro", ip, err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	utf16 := []byte{'m', 0, 'k', 0, '\r', 0, '\n', 0}
+	if got := decode(utf16); got != "mk\r\n" {
+		t.Errorf("decode(UTF-16) = %q, want %q", got, "mk\r\n")
+	}
+	if got := decode(append([]byte{0xff, 0xfe}, utf16...)); got != "mk\r\n" {
+		t.Errorf("decode(UTF-16 with BOM) = %q, want %q", got, "mk\r\n")
+	}
+	if got := decode([]byte("minikube\n")); got != "minikube\n" {
+		t.Errorf("decode(UTF-8) = %q, want %q", got, "minikube\n")
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	img, err := random.Image(512, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := flatten(&b, img); err != nil {
+		t.Fatalf("flatten: %v", err)
+	}
+	files := 0
+	tr := tar.NewReader(&b)
+	for {
+		_, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		files++
+	}
+	if files != 2 {
+		t.Errorf("got %d files, want the file of each layer", files)
+	}
+}
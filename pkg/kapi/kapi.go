@@ -47,6 +47,14 @@ var (
 	ReasonableStartTime = time.Minute * 5
 )
 
+// Replicas returns the desired replicas of a Deployment or StatefulSet, which default to 1 when not set
+func Replicas(r *int32) int32 {
+	if r == nil {
+		return 1
+	}
+	return *r
+}
+
 // ClientConfig returns the client configuration for a kubectl context
 func ClientConfig(context string) (*rest.Config, error) {
 	loader := clientcmd.NewDefaultClientConfigLoadingRules()
@@ -17,6 +17,7 @@ limitations under the License.
 package assets
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
 	"runtime"
@@ -25,8 +26,10 @@ import (
 	semver "github.com/blang/semver/v4"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
 	"k8s.io/minikube/deploy/addons"
 	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/vmpath"
 	"k8s.io/minikube/pkg/util"
@@ -332,6 +335,28 @@ var Addons = map[string]*Addon{
 		"Kong":        "docker.io",
 		"KongIngress": "docker.io",
 	}),
+	"gatekeeper": NewAddon([]*BinAsset{
+		MustBinAsset(addons.GatekeeperAssets,
+			"gatekeeper/gatekeeper.yaml.tmpl",
+			vmpath.GuestAddonsDir,
+			"gatekeeper.yaml",
+			"0640"),
+	}, false, "gatekeeper", "3rd party (Open Policy Agent)", "", "https://minikube.sigs.k8s.io/docs/handbook/addons/gatekeeper/", map[string]string{
+		"Kubectl": "bitnami/kubectl:1.24.7@sha256:195f5a7a40cfb06e308701ae850abfa436d23baf9d39c0282298e540c9d07863",
+	}, map[string]string{
+		"Kubectl": "docker.io",
+	}),
+	"pull-policy": NewAddon([]*BinAsset{
+		MustBinAsset(addons.PullPolicyAssets,
+			"pull-policy/pull-policy.yaml.tmpl",
+			vmpath.GuestAddonsDir,
+			"pull-policy.yaml",
+			"0640"),
+	}, false, "pull-policy", "minikube", "", "https://minikube.sigs.k8s.io/docs/handbook/addons/pull-policy/", map[string]string{
+		"PullPolicyWebhook": "k8s-minikube/pull-policy-webhook:v0.0.1",
+	}, map[string]string{
+		"PullPolicyWebhook": "gcr.io",
+	}),
 	"kubevirt": NewAddon([]*BinAsset{
 		MustBinAsset(addons.KubevirtAssets,
 			"kubevirt/pod.yaml.tmpl",
@@ -389,6 +414,11 @@ var Addons = map[string]*Addon{
 		"UpstreamCommunityOperators": "quay.io",
 	}),
 	"registry": NewAddon([]*BinAsset{
+		MustBinAsset(addons.RegistryAssets,
+			"registry/registry-tls-secret.yaml.tmpl",
+			vmpath.GuestAddonsDir,
+			"registry-tls-secret.yaml",
+			"0640"),
 		MustBinAsset(addons.RegistryAssets,
 			"registry/registry-rc.yaml.tmpl",
 			vmpath.GuestAddonsDir,
@@ -405,11 +435,12 @@ var Addons = map[string]*Addon{
 			"registry-proxy.yaml",
 			"0640"),
 	}, false, "registry", "minikube", "", "", map[string]string{
-		"Registry":          "registry:2.8.3@sha256:0a182cb82c93939407967d6d71d6caf11dcef0e5689c6afe2d60518e3b34ab86",
-		"KubeRegistryProxy": "k8s-minikube/kube-registry-proxy:0.0.5@sha256:f107ecd58728a2df5f2bb7e087f65f5363d0019b1e1fd476e4ef16065f44abfb",
+		"Registry": "registry:2.8.3@sha256:0a182cb82c93939407967d6d71d6caf11dcef0e5689c6afe2d60518e3b34ab86",
+		// passes the TLS connections to port 5000 of the nodes through to the registry
+		"RegistryProxy": "nginx:1.27.2-alpine",
 	}, map[string]string{
-		"KubeRegistryProxy": "gcr.io",
-		"Registry":          "docker.io",
+		"RegistryProxy": "docker.io",
+		"Registry":      "docker.io",
 	}),
 	"registry-creds": NewAddon([]*BinAsset{
 		MustBinAsset(addons.RegistryCredsAssets,
@@ -787,6 +818,14 @@ var Addons = map[string]*Addon{
 		}, map[string]string{
 			"NvidiaDevicePlugin": "nvcr.io",
 		}),
+	"nvidia-dcgm-exporter": NewAddon([]*BinAsset{
+		MustBinAsset(addons.NvidiaDCGMExporter, "nvidia-dcgm-exporter/nvidia-dcgm-exporter.yaml.tmpl", vmpath.GuestAddonsDir, "nvidia-dcgm-exporter.yaml", "0640"),
+	}, false, "nvidia-dcgm-exporter", "3rd party (NVIDIA)", "", "https://minikube.sigs.k8s.io/docs/tutorials/nvidia/",
+		map[string]string{
+			"DCGMExporter": "nvidia/k8s/dcgm-exporter:3.3.5-3.4.1-ubuntu22.04",
+		}, map[string]string{
+			"DCGMExporter": "nvcr.io",
+		}),
 }
 
 // parseMapString creates a map based on `str` which is encoded as <key1>=<value1>,<key2>=<value2>,...
@@ -917,6 +956,7 @@ func GenerateTemplateData(addon *Addon, cc *config.ClusterConfig, netInfo Networ
 		IngressAPIVersion       string
 		ContainerRuntime        string
 		RegistryAliases         string
+		RegistryTLS             map[string]string
 		Images                  map[string]string
 		Registries              map[string]string
 		CustomRegistries        map[string]string
@@ -934,6 +974,7 @@ func GenerateTemplateData(addon *Addon, cc *config.ClusterConfig, netInfo Networ
 		LoadBalancerEndIP:      cfg.LoadBalancerEndIP,
 		CustomIngressCert:      cfg.CustomIngressCert,
 		RegistryAliases:        cfg.RegistryAliases,
+		RegistryTLS:            make(map[string]string),
 		IngressAPIVersion:      "v1", // api version for ingress (eg, "v1beta1"; defaults to "v1" for k8s 1.19+)
 		ContainerRuntime:       cfg.ContainerRuntime,
 		Images:                 images,
@@ -967,6 +1008,18 @@ func GenerateTemplateData(addon *Addon, cc *config.ClusterConfig, netInfo Networ
 	opts.NetworkInfo["ControlPlaneNodePort"] = fmt.Sprint(netInfo.ControlPlaneNodePort)
 	opts.NetworkInfo["DNSDomain"] = cfg.DNSDomain
 
+	// the registry addon serves the cert generated for it when it is enabled
+	if addon.Name() == "registry" && enable {
+		for k, p := range map[string]string{"Cert": localpath.RegistryCert(cc.Name), "Key": localpath.RegistryKey(cc.Name)} {
+			b, err := os.ReadFile(p)
+			if err != nil {
+				klog.Warningf("reading %s: %v", p, err)
+				continue
+			}
+			opts.RegistryTLS[k] = base64.StdEncoding.EncodeToString(b)
+		}
+	}
+
 	// Append postfix "/" to registries
 	for k, v := range opts.Registries {
 		if v != "" && !strings.HasSuffix(v, "/") {
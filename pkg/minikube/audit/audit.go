@@ -28,13 +28,19 @@ import (
 	"github.com/google/uuid"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/version"
 )
 
 // userName pulls the user flag, if empty gets the os username.
+// In a system-wide install the os username is always used, so that commands are attributed to the real user.
 func userName() string {
+	if localpath.SystemWide() {
+		return localpath.SystemUser()
+	}
 	u := viper.GetString(config.UserFlag)
 	if u != "" {
 		return u
@@ -62,6 +68,11 @@ func LogCommandStart() (string, error) {
 	}
 	id := uuid.New().String()
 	r := newRow(pflag.Arg(0), args(), userName(), version.GetVersion(), time.Now(), id)
+	if localpath.SystemWide() {
+		if err := appendToSystemLog(r); err != nil {
+			klog.Warningf("failed to log command start to system audit: %v", err)
+		}
+	}
 	if err := appendToLog(r); err != nil {
 		return "", err
 	}
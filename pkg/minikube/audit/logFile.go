@@ -72,6 +72,29 @@ func appendToLog(row *row) error {
 	return nil
 }
 
+// appendToSystemLog appends the row to the audit log of the user in the logs of a system-wide install,
+// which only the user writes: another user creating it first would write the rows of the user.
+func appendToSystemLog(row *row) error {
+	ce := register.CloudEvent(row, row.toMap())
+	bs, err := ce.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("error marshalling event: %v", err)
+	}
+	f, err := os.OpenFile(localpath.SystemAuditLog(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open the system audit log: %v", err)
+	}
+	defer f.Close()
+	// chmod fails with EPERM if the log is owned by another user
+	if err := f.Chmod(0644); err != nil {
+		return fmt.Errorf("the system audit log %s is not owned by %s: %v", f.Name(), localpath.SystemUser(), err)
+	}
+	if _, err := f.WriteString(string(bs) + "\n"); err != nil {
+		return fmt.Errorf("unable to write to system audit log: %v", err)
+	}
+	return nil
+}
+
 // truncateAuditLog truncates the audit log file
 func truncateAuditLog() error {
 	if err := os.Truncate(auditPath(), 0); err != nil {
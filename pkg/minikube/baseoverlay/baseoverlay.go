@@ -0,0 +1,370 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package baseoverlay layers the packages, kernel modules and files of an overlay directory onto the minikube ISO
+// or the kicbase image, so that a customized base does not need a build of its own
+package baseoverlay
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/drivers/hyperkit"
+)
+
+const (
+	// FilesDir is the directory of the overlay copied onto the root filesystem
+	FilesDir = "files"
+	// PackagesFile lists the packages installed into kicbase with apt-get, one per line
+	PackagesFile = "packages"
+	// ModulesFile lists the kernel modules loaded by the ISO at boot, one per line
+	ModulesFile = "modules"
+
+	// modulesUnit loads the modules of the overlay once depmod indexed the ones it added
+	modulesUnit = "minikube-overlay-modules.service"
+)
+
+// Overlay is the content of an overlay directory
+type Overlay struct {
+	Dir      string
+	Files    []string // paths relative to the files directory, in the / separated form of the guest
+	Packages []string
+	Modules  []string
+}
+
+// Load reads the overlay directory dir
+func Load(dir string) (*Overlay, error) {
+	o := &Overlay{Dir: dir}
+	var err error
+	if o.Packages, err = readList(filepath.Join(dir, PackagesFile)); err != nil {
+		return nil, err
+	}
+	if o.Modules, err = readList(filepath.Join(dir, ModulesFile)); err != nil {
+		return nil, err
+	}
+	root := filepath.Join(dir, FilesDir)
+	if _, err := os.Stat(root); err == nil {
+		err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || p == root {
+				return err
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			o.Files = append(o.Files, filepath.ToSlash(rel))
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "list the files of the overlay")
+		}
+	}
+	sort.Strings(o.Files)
+	if len(o.Files) == 0 && len(o.Packages) == 0 && len(o.Modules) == 0 {
+		return nil, errors.Errorf("%s has none of the %s directory, the %s and %s files", dir, FilesDir, PackagesFile, ModulesFile)
+	}
+	return o, nil
+}
+
+// readList returns the lines of the file p but the empty ones and comments, nothing if p does not exist
+func readList(p string) ([]string, error) {
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var list []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			list = append(list, line)
+		}
+	}
+	return list, s.Err()
+}
+
+// Hash returns a digest of the base and the content of o, naming the built artifact
+func (o *Overlay) Hash(base string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "base %s\npackages %s\nmodules %s\n", base, strings.Join(o.Packages, " "), strings.Join(o.Modules, " "))
+	for _, rel := range o.Files {
+		p := filepath.Join(o.Dir, FilesDir, filepath.FromSlash(rel))
+		st, err := os.Lstat(p)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s %o\n", rel, st.Mode())
+		if st.Mode().IsRegular() {
+			b, err := os.ReadFile(p)
+			if err != nil {
+				return "", err
+			}
+			h.Write(b)
+		} else if st.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(p)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintln(h, target)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))[:12], nil
+}
+
+// Dockerfile returns the Dockerfile layering o onto the kicbase image base, built with the overlay directory as context
+func (o *Overlay) Dockerfile(base string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "FROM %s\n", base)
+	if len(o.Packages) > 0 {
+		fmt.Fprintf(&b, "RUN apt-get update && DEBIAN_FRONTEND=noninteractive apt-get install -y --no-install-recommends %s && rm -rf /var/lib/apt/lists/*\n", strings.Join(o.Packages, " "))
+	}
+	if len(o.Files) > 0 {
+		fmt.Fprintf(&b, "COPY %s/ /\n", FilesDir)
+	}
+	return b.String()
+}
+
+// BuildKicbase builds the image tag layering o onto the kicbase image base with ociBin
+func (o *Overlay) BuildKicbase(ociBin, base, tag string) error {
+	if len(o.Modules) > 0 {
+		return errors.Errorf("the kernel modules of the %s file can only be loaded by the ISO, the containers of kicbase share the kernel of the host", ModulesFile)
+	}
+	df, err := os.CreateTemp("", "Dockerfile.overlay")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(df.Name())
+	if _, err := df.WriteString(o.Dockerfile(base)); err != nil {
+		df.Close()
+		return err
+	}
+	if err := df.Close(); err != nil {
+		return err
+	}
+	c := exec.Command(ociBin, "build", "-t", tag, "-f", df.Name(), o.Dir)
+	c.Stdout = os.Stderr
+	c.Stderr = os.Stderr
+	klog.Infof("building %s: %v", tag, c.Args)
+	return errors.Wrapf(c.Run(), "%s build", ociBin)
+}
+
+// BuildISO writes the ISO dst layering o onto the ISO base, with an initramfs archive of o appended to its initrd,
+// and rebuilt by xorriso keeping the boot setup of base
+func (o *Overlay) BuildISO(base, dst string) error {
+	if len(o.Packages) > 0 {
+		return errors.Errorf("the packages of the %s file can only be installed into kicbase, the ISO has no package manager", PackagesFile)
+	}
+	xorriso, err := exec.LookPath("xorriso")
+	if err != nil {
+		return errors.New("xorriso is required to rebuild the ISO, install it with the package manager of the host")
+	}
+	tmp, err := os.MkdirTemp("", "minikube-overlay")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	initrd := filepath.Join(tmp, "initrd")
+	if err := hyperkit.ExtractFile(base, "/boot/initrd", initrd); err != nil {
+		return errors.Wrap(err, "extract initrd")
+	}
+	f, err := os.OpenFile(initrd, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	// the kernel unpacks the concatenated archives in order, the later ones overwriting the files of the earlier ones
+	st, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if pad := (4 - st.Size()%4) % 4; pad > 0 {
+		if _, err := f.Write(make([]byte, pad)); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := o.writeInitramfs(f); err != nil {
+		f.Close()
+		return errors.Wrap(err, "initramfs of the overlay")
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	partial := dst + ".partial"
+	os.Remove(partial)
+	c := exec.Command(xorriso, "-indev", base, "-outdev", partial, "-map", initrd, "/boot/initrd", "-boot_image", "any", "replay")
+	if b, err := c.CombinedOutput(); err != nil {
+		os.Remove(partial)
+		return errors.Wrapf(err, "xorriso: %s", b)
+	}
+	return os.Rename(partial, dst)
+}
+
+// writeInitramfs writes the gzip compressed newc archive of the files and modules of o to w
+func (o *Overlay) writeInitramfs(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	cw := &cpioWriter{w: gz, dirs: map[string]bool{}}
+	for _, rel := range o.Files {
+		p := filepath.Join(o.Dir, FilesDir, filepath.FromSlash(rel))
+		st, err := os.Lstat(p)
+		if err != nil {
+			return err
+		}
+		switch {
+		case st.IsDir():
+			err = cw.dir(rel, uint32(st.Mode().Perm()))
+		case st.Mode()&os.ModeSymlink != 0:
+			var target string
+			if target, err = os.Readlink(p); err == nil {
+				err = cw.symlink(rel, target)
+			}
+		case st.Mode().IsRegular():
+			var b []byte
+			if b, err = os.ReadFile(p); err == nil {
+				err = cw.file(rel, uint32(st.Mode().Perm()), b)
+			}
+		default:
+			err = errors.Errorf("%s is not a regular file, directory or symlink", p)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if len(o.Modules) > 0 {
+		if err := cw.file("etc/systemd/system/"+modulesUnit, 0644, []byte(modulesUnitFile(o.Modules))); err != nil {
+			return err
+		}
+		if err := cw.symlink("etc/systemd/system/multi-user.target.wants/"+modulesUnit, "../"+modulesUnit); err != nil {
+			return err
+		}
+	}
+	if err := cw.trailer(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// modulesUnitFile returns the systemd unit indexing the modules added by the overlay and loading modules
+func modulesUnitFile(modules []string) string {
+	return fmt.Sprintf(`[Unit]
+Description=Load the kernel modules of the minikube overlay
+Before=multi-user.target
+
+[Service]
+Type=oneshot
+ExecStart=/sbin/depmod -a
+ExecStart=/sbin/modprobe -a %s
+RemainAfterExit=yes
+
+[Install]
+WantedBy=multi-user.target
+`, strings.Join(modules, " "))
+}
+
+// cpioWriter writes an archive in the newc format of the initramfs of the kernel
+type cpioWriter struct {
+	w    io.Writer
+	ino  uint32
+	dirs map[string]bool
+}
+
+// parents writes the directories holding name which are not in the archive yet
+func (c *cpioWriter) parents(name string) error {
+	parts := strings.Split(name, "/")
+	for i := 1; i < len(parts); i++ {
+		dir := strings.Join(parts[:i], "/")
+		if !c.dirs[dir] {
+			if err := c.dir(dir, 0755); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *cpioWriter) dir(name string, perm uint32) error {
+	if err := c.parents(name); err != nil {
+		return err
+	}
+	c.dirs[name] = true
+	return c.entry(name, 0040000|perm, nil)
+}
+
+func (c *cpioWriter) file(name string, perm uint32, content []byte) error {
+	if err := c.parents(name); err != nil {
+		return err
+	}
+	return c.entry(name, 0100000|perm, content)
+}
+
+func (c *cpioWriter) symlink(name, target string) error {
+	if err := c.parents(name); err != nil {
+		return err
+	}
+	return c.entry(name, 0120777, []byte(target))
+}
+
+func (c *cpioWriter) trailer() error {
+	return c.entry("TRAILER!!!", 0, nil)
+}
+
+// entry writes a newc header, the name and the data, each padded to 4 bytes
+func (c *cpioWriter) entry(name string, mode uint32, data []byte) error {
+	c.ino++
+	nlink := 1
+	if mode&0040000 != 0 {
+		nlink = 2
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "070701%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X%08X",
+		c.ino, mode, 0, 0, nlink, 0, len(data), 0, 0, 0, 0, len(name)+1, 0)
+	b.WriteString(name)
+	b.WriteByte(0)
+	pad(&b)
+	b.Write(data)
+	pad(&b)
+	_, err := c.w.Write(b.Bytes())
+	return err
+}
+
+func pad(b *bytes.Buffer) {
+	for b.Len()%4 != 0 {
+		b.WriteByte(0)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baseoverlay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func writeOverlay(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeOverlay(t, map[string]string{
+		"packages":                  "# debugging\nstrace\n\ntcpdump\n",
+		"files/etc/sysctl.d/x.conf": "vm.max_map_count=262144",
+	})
+	o, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !reflect.DeepEqual(o.Packages, []string{"strace", "tcpdump"}) || o.Modules != nil {
+		t.Errorf("Load() = %+v", o)
+	}
+	if !reflect.DeepEqual(o.Files, []string{"etc", "etc/sysctl.d", "etc/sysctl.d/x.conf"}) {
+		t.Errorf("Files = %v", o.Files)
+	}
+	want := "FROM kicbase\nRUN apt-get update && DEBIAN_FRONTEND=noninteractive apt-get install -y --no-install-recommends strace tcpdump && rm -rf /var/lib/apt/lists/*\nCOPY files/ /\n"
+	if got := o.Dockerfile("kicbase"); got != want {
+		t.Errorf("Dockerfile() = %q, want %q", got, want)
+	}
+
+	h1, err := o.Hash("kicbase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h2, _ := o.Hash("iso"); h1 == h2 {
+		t.Errorf("Hash() does not depend on the base")
+	}
+
+	if _, err := Load(t.TempDir()); err == nil {
+		t.Errorf("Load() of an empty directory did not fail")
+	}
+}
+
+func TestWriteInitramfs(t *testing.T) {
+	dir := writeOverlay(t, map[string]string{
+		"modules":               "wireguard\n",
+		"files/etc/motd":        "hello",
+		"files/usr/bin/tool.sh": "#!/bin/sh",
+	})
+	o, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	var b bytes.Buffer
+	if err := o.writeInitramfs(&b); err != nil {
+		t.Fatalf("writeInitramfs: %v", err)
+	}
+	gz, err := gzip.NewReader(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	contents := map[string]string{}
+	for off := 0; off < len(archive); {
+		hdr := string(archive[off : off+110])
+		if !strings.HasPrefix(hdr, "070701") {
+			t.Fatalf("invalid header at %d: %q", off, hdr)
+		}
+		size, _ := strconv.ParseUint(hdr[54:62], 16, 32)
+		namesize, _ := strconv.ParseUint(hdr[94:102], 16, 32)
+		name := string(archive[off+110 : off+110+int(namesize)-1])
+		off = align(off + 110 + int(namesize))
+		contents[name] = string(archive[off : off+int(size)])
+		off = align(off + int(size))
+		if name == "TRAILER!!!" {
+			break
+		}
+		names = append(names, name)
+	}
+	for _, want := range []string{"etc/motd", "usr/bin/tool.sh", "etc/systemd/system/" + modulesUnit, "etc/systemd/system/multi-user.target.wants/" + modulesUnit} {
+		if _, ok := contents[want]; !ok {
+			t.Errorf("the archive has no %s: %v", want, names)
+		}
+	}
+	if contents["etc/motd"] != "hello" || !strings.Contains(contents["etc/systemd/system/"+modulesUnit], "modprobe -a wireguard") {
+		t.Errorf("unexpected contents: %v", contents)
+	}
+	if names[0] != "etc" {
+		t.Errorf("the directories are not written before their files: %v", names)
+	}
+
+	if err := o.BuildKicbase("docker", "kicbase", "tag"); err == nil {
+		t.Errorf("BuildKicbase() did not fail with kernel modules")
+	}
+}
+
+func align(n int) int {
+	return (n + 3) &^ 3
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"os/exec"
 	"path"
+	"path/filepath"
 	"runtime"
 	"strings"
 
@@ -39,16 +40,18 @@ import (
 
 // TransferBinaries transfers all required Kubernetes binaries
 func TransferBinaries(cfg config.KubernetesConfig, c command.Runner, sm sysinit.Manager, binariesURL string) error {
-	ok, err := binariesExist(cfg, c)
-	if err == nil && ok {
-		klog.Info("Found k8s binaries, skipping transfer")
-		return nil
+	// the binaries of a local build are transferred on every start, since they are rebuilt under the same version
+	if cfg.KubernetesImagesDir == "" {
+		ok, err := binariesExist(cfg, c)
+		if err == nil && ok {
+			klog.Info("Found k8s binaries, skipping transfer")
+			return nil
+		}
+		klog.Infof("Didn't find k8s binaries: %v\nInitiating transfer...", err)
 	}
-	klog.Infof("Didn't find k8s binaries: %v\nInitiating transfer...", err)
 
 	dir := binRoot(cfg.KubernetesVersion)
-	_, err = c.RunCmd(exec.Command("sudo", "mkdir", "-p", dir))
-	if err != nil {
+	if _, err := c.RunCmd(exec.Command("sudo", "mkdir", "-p", dir)); err != nil {
 		return err
 	}
 
@@ -56,9 +59,13 @@ func TransferBinaries(cfg config.KubernetesConfig, c command.Runner, sm sysinit.
 	for _, name := range constants.KubernetesReleaseBinaries {
 		name := name
 		g.Go(func() error {
-			src, err := download.Binary(name, cfg.KubernetesVersion, "linux", runtime.GOARCH, binariesURL)
-			if err != nil {
-				return errors.Wrapf(err, "downloading %s", name)
+			src := filepath.Join(cfg.KubernetesImagesDir, name)
+			if cfg.KubernetesImagesDir == "" {
+				var err error
+				src, err = download.Binary(name, cfg.KubernetesVersion, "linux", runtime.GOARCH, binariesURL)
+				if err != nil {
+					return errors.Wrapf(err, "downloading %s", name)
+				}
 			}
 
 			if name == "kubelet" && sm.Active(name) {
@@ -97,6 +104,11 @@ func binariesExist(cfg config.KubernetesConfig, c command.Runner) (bool, error)
 	return true, nil
 }
 
+// BinaryPath returns the path of a Kubernetes binary of the version in the node
+func BinaryPath(version, name string) string {
+	return path.Join(binRoot(version), name)
+}
+
 // binRoot returns the persistent path binaries are stored in
 func binRoot(version string) string {
 	return path.Join(vmpath.GuestPersistentDir, "binaries", version)
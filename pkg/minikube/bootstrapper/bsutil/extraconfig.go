@@ -181,6 +181,11 @@ func newComponentOptions(opts config.ExtraOptionSlice, version semver.Version, f
 func optionPairsForComponent(component string, version semver.Version, cp config.Node) map[string]string {
 	// For the ktmpl.V1Beta1 users
 	if component == Apiserver && version.GTE(semver.MustParse("1.14.0-alpha.0")) {
+		if cp.IPv6 != "" {
+			return map[string]string{
+				"certSANs": fmt.Sprintf(`["127.0.0.1", "localhost", "%s", "%s"]`, cp.IP, cp.IPv6),
+			}
+		}
 		return map[string]string{
 			"certSANs": fmt.Sprintf(`["127.0.0.1", "localhost", "%s"]`, cp.IP),
 		}
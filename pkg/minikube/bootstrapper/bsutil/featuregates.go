@@ -23,6 +23,9 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/sandbox"
 	"k8s.io/minikube/third_party/kubeadm/app/features"
 )
 
@@ -67,3 +70,17 @@ func parseFeatureArgs(featureGates string) (map[string]bool, string, error) {
 	componentFeatureArgs = strings.TrimRight(componentFeatureArgs, ",")
 	return kubeadmFeatureArgs, componentFeatureArgs, nil
 }
+
+// withAdmissionPolicyAPI returns the feature gates and the extra options enabling the MutatingAdmissionPolicy API of apiVersion in the apiserver,
+// unless they set the feature gate or the runtime config of the apiserver
+func withAdmissionPolicyAPI(featureGates string, extraOpts config.ExtraOptionSlice, apiVersion string) (string, config.ExtraOptionSlice) {
+	if !strings.Contains(featureGates, sandbox.FeatureGate+"=") {
+		featureGates = strings.TrimLeft(featureGates+","+sandbox.FeatureGate+"=true", ",")
+	}
+	if extraOpts.Get("runtime-config", Apiserver) != "" {
+		return featureGates, extraOpts
+	}
+	opts := append(config.ExtraOptionSlice{}, extraOpts...)
+	opts = append(opts, config.ExtraOption{Component: Apiserver, Key: "runtime-config", Value: "admissionregistration.k8s.io/" + apiVersion + "=true"})
+	return featureGates, opts
+}
@@ -20,6 +20,8 @@ package bsutil
 import (
 	"reflect"
 	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
 )
 
 func TestParseFeatureArgs(t *testing.T) {
@@ -92,3 +94,16 @@ func TestSupport(t *testing.T) {
 	}
 
 }
+
+func TestWithAdmissionPolicyAPI(t *testing.T) {
+	fg, opts := withAdmissionPolicyAPI("", nil, "v1alpha1")
+	if fg != "MutatingAdmissionPolicy=true" || opts.Get("runtime-config", Apiserver) != "admissionregistration.k8s.io/v1alpha1=true" {
+		t.Errorf("withAdmissionPolicyAPI() = %q, %v", fg, opts)
+	}
+
+	user := config.ExtraOptionSlice{{Component: Apiserver, Key: "runtime-config", Value: "api/all=true"}}
+	fg, opts = withAdmissionPolicyAPI("MutatingAdmissionPolicy=false,CSIMigration=true", user, "v1beta1")
+	if fg != "MutatingAdmissionPolicy=false,CSIMigration=true" || len(opts) != 1 {
+		t.Errorf("withAdmissionPolicyAPI() overrode the options of the user: %q, %v", fg, opts)
+	}
+}
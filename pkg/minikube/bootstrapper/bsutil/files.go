@@ -34,6 +34,12 @@ const (
 	InitRestartWrapper = "/etc/init.d/.restart_wrapper.sh"
 	// KubeletInitPath is where Sys-V style init script is installed
 	KubeletInitPath = "/etc/init.d/kubelet"
+	// KubeadmPatchesDir is the directory of the patches of kubeadm, the ones of --kubeadm-patches and of the kubelet config of a joining node
+	KubeadmPatchesDir = "/var/tmp/minikube/patches"
+	// KubeadmAppliedPatchesDir is the copy of the patches the static pods of a control plane were last written with
+	KubeadmAppliedPatchesDir = "/var/tmp/minikube/patches.applied"
+	// KubeletConfigPatchFile is the JSON merge patch of the KubeletConfiguration of a joining node
+	KubeletConfigPatchFile = KubeadmPatchesDir + "/kubeletconfiguration+merge.json"
 )
 
 // CopyFiles combines mkdir requests into a single call to reduce load
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ktmpl
+
+import "text/template"
+
+// KubeVipTemplate is the static pod of kube-vip, which announces the virtual IP of a multi-control-plane cluster from the
+// control plane holding the lease. kube-vip reaches the local apiserver through the "kubernetes" alias of the loopback,
+// so that it does not depend on the virtual IP it provides. With Services, it also announces the IPs of the LoadBalancer services.
+var KubeVipTemplate = template.Must(template.New("kubeVipTemplate").Parse(`apiVersion: v1
+kind: Pod
+metadata:
+  name: kube-vip
+  namespace: kube-system
+spec:
+  containers:
+  - args:
+    - manager
+    env:
+    - name: vip_arp
+      value: "true"
+{{- if .VIP}}
+    - name: port
+      value: "{{.Port}}"
+{{- end}}
+    - name: vip_interface
+      value: {{.Interface}}
+    - name: vip_cidr
+      value: "32"
+{{- if .VIP}}
+    - name: cp_enable
+      value: "true"
+    - name: cp_namespace
+      value: kube-system
+{{- end}}
+{{- if .Services}}
+    - name: svc_enable
+      value: "true"
+{{- end}}
+    - name: vip_leaderelection
+      value: "true"
+    - name: vip_leasename
+      value: plndr-cp-lock
+    - name: vip_leaseduration
+      value: "5"
+    - name: vip_renewdeadline
+      value: "3"
+    - name: vip_retryperiod
+      value: "1"
+{{- if .VIP}}
+    - name: address
+      value: {{.VIP}}
+{{- end}}
+    image: {{.Image}}
+    imagePullPolicy: IfNotPresent
+    name: kube-vip
+    resources: {}
+    securityContext:
+      capabilities:
+        add:
+        - NET_ADMIN
+        - NET_RAW
+    volumeMounts:
+    - mountPath: /etc/kubernetes/admin.conf
+      name: kubeconfig
+  hostAliases:
+  - hostnames:
+    - kubernetes
+    ip: 127.0.0.1
+  hostNetwork: true
+  volumes:
+  - hostPath:
+      path: {{.AdminConf}}
+    name: kubeconfig
+status: {}
+`))
+
+// KubeVipCloudProviderTemplate is the kube-vip cloud provider, which allocates the IPs of the LoadBalancer services from the range of its config map
+var KubeVipCloudProviderTemplate = template.Must(template.New("kubeVipCloudProviderTemplate").Parse(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: kubevip
+  namespace: kube-system
+data:
+  range-global: {{.Range}}
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: kube-vip-cloud-controller
+  namespace: kube-system
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: system:kube-vip-cloud-controller-role
+rules:
+- apiGroups: ["coordination.k8s.io"]
+  resources: ["leases"]
+  verbs: ["get", "create", "update", "list", "put"]
+- apiGroups: [""]
+  resources: ["configmaps", "endpoints", "events", "services/status", "leases"]
+  verbs: ["*"]
+- apiGroups: [""]
+  resources: ["nodes", "services"]
+  verbs: ["list", "get", "watch", "update"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: system:kube-vip-cloud-controller-binding
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: system:kube-vip-cloud-controller-role
+subjects:
+- kind: ServiceAccount
+  name: kube-vip-cloud-controller
+  namespace: kube-system
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: kube-vip-cloud-provider
+  namespace: kube-system
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: kube-vip
+      component: kube-vip-cloud-provider
+  template:
+    metadata:
+      labels:
+        app: kube-vip
+        component: kube-vip-cloud-provider
+    spec:
+      containers:
+      - command:
+        - /kube-vip-cloud-provider
+        - --leader-elect-resource-name=kube-vip-cloud-controller
+        image: {{.Image}}
+        imagePullPolicy: IfNotPresent
+        name: kube-vip-cloud-provider
+      serviceAccountName: kube-vip-cloud-controller
+      tolerations:
+      - key: node-role.kubernetes.io/control-plane
+        effect: NoSchedule
+`))
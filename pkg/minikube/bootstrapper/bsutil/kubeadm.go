@@ -21,6 +21,7 @@ import (
 	"bytes"
 	"fmt"
 	"path"
+	"strings"
 
 	"github.com/blang/semver/v4"
 	"github.com/pkg/errors"
@@ -30,6 +31,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/sandbox"
 	"k8s.io/minikube/pkg/minikube/vmpath"
 	"k8s.io/minikube/pkg/util"
 )
@@ -45,6 +47,13 @@ func GenerateKubeadmYAML(cc config.ClusterConfig, n config.Node, r cruntime.Mana
 		return nil, errors.Wrap(err, "parsing Kubernetes version")
 	}
 
+	// the default RuntimeClass of the sandbox namespaces is set by a MutatingAdmissionPolicy, which the apiserver must serve
+	if len(cc.SandboxNamespaces) > 0 {
+		if apiVersion, err := sandbox.AdmissionAPIVersion(version); err == nil {
+			k8s.FeatureGates, k8s.ExtraOptions = withAdmissionPolicyAPI(k8s.FeatureGates, k8s.ExtraOptions, apiVersion)
+		}
+	}
+
 	// parses a map of the feature gates for kubeadm and component
 	kubeadmFeatureArgs, componentFeatureArgs, err := parseFeatureArgs(k8s.FeatureGates)
 	if err != nil {
@@ -74,15 +83,9 @@ func GenerateKubeadmYAML(cc config.ClusterConfig, n config.Node, r cruntime.Mana
 		return nil, errors.Wrap(err, "generating extra component config for kubeadm")
 	}
 
-	cnm, err := cni.New(&cc)
+	podCIDR, err := PodCIDR(cc)
 	if err != nil {
-		return nil, errors.Wrap(err, "cni")
-	}
-
-	podCIDR := cnm.CIDR()
-	overrideCIDR := k8s.ExtraOptions.Get("pod-network-cidr", Kubeadm)
-	if overrideCIDR != "" {
-		podCIDR = overrideCIDR
+		return nil, err
 	}
 	klog.Infof("Using pod CIDR: %s", podCIDR)
 
@@ -129,7 +132,7 @@ func GenerateKubeadmYAML(cc config.ClusterConfig, n config.Node, r cruntime.Mana
 		CertDir:           vmpath.GuestKubernetesCertsDir,
 		ServiceCIDR:       constants.DefaultServiceCIDR,
 		PodSubnet:         podCIDR,
-		AdvertiseAddress:  n.IP,
+		AdvertiseAddress:  config.NodeIPs(cc, n)[0],
 		APIServerPort:     nodePort,
 		KubernetesVersion: k8s.KubernetesVersion,
 		EtcdDataDir:       EtcdDataDir(),
@@ -142,7 +145,7 @@ func GenerateKubeadmYAML(cc config.ClusterConfig, n config.Node, r cruntime.Mana
 		ComponentOptions:           componentOpts,
 		FeatureArgs:                kubeadmFeatureArgs,
 		DNSDomain:                  k8s.DNSDomain,
-		NodeIP:                     n.IP,
+		NodeIP:                     strings.Join(config.NodeIPs(cc, n), ","),
 		CgroupDriver:               cgroupDriver,
 		ClientCAFile:               path.Join(vmpath.GuestKubernetesCertsDir, "ca.crt"),
 		StaticPodPath:              vmpath.GuestManifestsDir,
@@ -155,6 +158,7 @@ func GenerateKubeadmYAML(cc config.ClusterConfig, n config.Node, r cruntime.Mana
 	if k8s.ServiceCIDR != "" {
 		opts.ServiceCIDR = k8s.ServiceCIDR
 	}
+	opts.ServiceCIDR = familyCIDRs(k8s.IPFamily, opts.ServiceCIDR, constants.DefaultServiceCIDRv6)
 
 	configTmpl := ktmpl.V1Alpha3
 	// v1beta1 works in v1.13, but isn't required until v1.14.
@@ -182,6 +186,31 @@ func GenerateKubeadmYAML(cc config.ClusterConfig, n config.Node, r cruntime.Mana
 	return b.Bytes(), nil
 }
 
+// PodCIDR returns the pod subnets of the cluster, comma separated in dual-stack: the ones of its CNI,
+// unless set with the pod-network-cidr option of kubeadm
+func PodCIDR(cc config.ClusterConfig) (string, error) {
+	if cidr := cc.KubernetesConfig.ExtraOptions.Get("pod-network-cidr", Kubeadm); cidr != "" {
+		return cidr, nil
+	}
+	cnm, err := cni.New(&cc)
+	if err != nil {
+		return "", errors.Wrap(err, "cni")
+	}
+	return familyCIDRs(cc.KubernetesConfig.IPFamily, cnm.CIDR(), constants.DefaultPodCIDRv6), nil
+}
+
+// familyCIDRs returns the subnets of the IP family of the cluster, both in dual-stack with the IPv4 one first
+func familyCIDRs(family, ipv4, ipv6 string) string {
+	switch family {
+	case config.IPv6Family:
+		return ipv6
+	case config.DualStack:
+		return ipv4 + "," + ipv6
+	default:
+		return ipv4
+	}
+}
+
 // These are the components that can be configured
 // through the "extra-config"
 const (
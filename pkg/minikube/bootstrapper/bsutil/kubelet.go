@@ -19,9 +19,13 @@ package bsutil
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/blang/semver/v4"
 	"github.com/pkg/errors"
@@ -83,13 +87,27 @@ func extraKubeletOpts(mc config.ClusterConfig, nc config.Node, r cruntime.Manage
 	}
 
 	if _, ok := extraOpts["node-ip"]; !ok {
-		extraOpts["node-ip"] = nc.IP
+		extraOpts["node-ip"] = strings.Join(config.NodeIPs(mc, nc), ",")
 	}
 	if _, ok := extraOpts["hostname-override"]; !ok {
 		nodeName := KubeNodeName(mc, nc)
 		extraOpts["hostname-override"] = nodeName
 	}
 
+	// the kubelet registers the nodes of a pool with its labels and taints
+	if pool := config.NodePoolByName(mc, nc.Pool); pool != nil {
+		labels := append([]string{"minikube.k8s.io/pool=" + pool.Name}, pool.Labels...)
+		extraOpts["node-labels"] = joinFlagValues(extraOpts["node-labels"], labels)
+		if len(pool.Taints) > 0 {
+			extraOpts["register-with-taints"] = joinFlagValues(extraOpts["register-with-taints"], pool.Taints)
+		}
+	}
+
+	// the kubelet registers the nodes in their zone and region, to test topology aware scheduling
+	if labels := config.TopologyLabels(mc, nc); len(labels) > 0 {
+		extraOpts["node-labels"] = joinFlagValues(extraOpts["node-labels"], labels)
+	}
+
 	// Handled by CRI in 1.24+, and not by kubelet
 	if version.LT(semver.MustParse("1.24.0-alpha.2")) {
 		pauseImage := images.Pause(version, k8s.ImageRepository)
@@ -116,6 +134,14 @@ func extraKubeletOpts(mc config.ClusterConfig, nc config.Node, r cruntime.Manage
 	return extraOpts, nil
 }
 
+// joinFlagValues appends values to the comma separated value of a flag
+func joinFlagValues(value string, values []string) string {
+	if value != "" {
+		values = append([]string{value}, values...)
+	}
+	return strings.Join(values, ",")
+}
+
 // NewKubeletConfig generates a new systemd unit containing a configured kubelet
 // based on the options present in the KubernetesConfig.
 func NewKubeletConfig(mc config.ClusterConfig, nc config.Node, r cruntime.Manager) ([]byte, error) {
@@ -160,3 +186,45 @@ func KubeNodeName(cc config.ClusterConfig, n config.Node) string {
 	}
 	return config.MachineName(cc, n)
 }
+
+// kubeletConfigFieldRegexp matches the FIELD[.KEY] of a KubeletConfiguration override, KEY being a key of a map field such as evictionHard
+var kubeletConfigFieldRegexp = regexp.MustCompile(`^([a-z][A-Za-z0-9]*)(\.(.+))?$`)
+
+// KubeletConfigPatch returns the JSON merge patch of the KubeletConfiguration with the overrides of the node
+func KubeletConfigPatch(n config.Node) ([]byte, error) {
+	patch := map[string]interface{}{}
+	for _, o := range n.KubeletConfig {
+		field, value, ok := strings.Cut(o, "=")
+		m := kubeletConfigFieldRegexp.FindStringSubmatch(field)
+		if !ok || m == nil {
+			return nil, errors.Errorf("invalid kubelet config %q, expected FIELD=VALUE or FIELD.KEY=VALUE, for example maxPods=50 or evictionHard.memory.available=500Mi", o)
+		}
+		if m[3] == "" {
+			patch[m[1]] = kubeletConfigValue(value)
+			continue
+		}
+		entries, ok := patch[m[1]].(map[string]interface{})
+		if !ok {
+			entries = map[string]interface{}{}
+			patch[m[1]] = entries
+		}
+		// the values of the map fields are strings, except the booleans of featureGates
+		if b, err := strconv.ParseBool(value); err == nil && m[1] == "featureGates" {
+			entries[m[3]] = b
+		} else {
+			entries[m[3]] = value
+		}
+	}
+	return json.Marshal(patch)
+}
+
+// kubeletConfigValue returns the JSON value of a KubeletConfiguration field set to s
+func kubeletConfigValue(s string) interface{} {
+	if i, err := strconv.Atoi(s); err == nil {
+		return i
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	return s
+}
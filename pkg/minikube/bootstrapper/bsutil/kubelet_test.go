@@ -203,3 +203,71 @@ ExecStart=/var/lib/minikube/binaries/v1.18.2/kubelet --authorization-mode=Webhoo
 		})
 	}
 }
+
+func TestExtraKubeletOptsNodePool(t *testing.T) {
+	cc := config.ClusterConfig{
+		Name: "minikube",
+		KubernetesConfig: config.KubernetesConfig{
+			KubernetesVersion: constants.DefaultKubernetesVersion,
+			ContainerRuntime:  "containerd",
+			ExtraOptions:      config.ExtraOptionSlice{{Component: Kubelet, Key: "node-labels", Value: "zone=a"}},
+		},
+		NodePools: []config.NodePool{
+			{Name: "gpu", Labels: []string{"accelerator=nvidia"}, Taints: []string{"nvidia.com/gpu=present:NoSchedule"}},
+			{Name: "workers"},
+		},
+	}
+	tests := []struct {
+		node   config.Node
+		labels string
+		taints string
+	}{
+		{config.Node{Name: "m02", IP: "192.168.49.3"}, "zone=a", ""},
+		{config.Node{Name: "m02", IP: "192.168.49.3", Pool: "gpu"}, "zone=a,minikube.k8s.io/pool=gpu,accelerator=nvidia", "nvidia.com/gpu=present:NoSchedule"},
+		{config.Node{Name: "m03", IP: "192.168.49.4", Pool: "workers"}, "zone=a,minikube.k8s.io/pool=workers", ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.node.Pool, func(t *testing.T) {
+			r, err := cruntime.New(cruntime.Config{Type: "containerd"})
+			if err != nil {
+				t.Fatalf("runtime: %v", err)
+			}
+			opts, err := extraKubeletOpts(cc, tc.node, r)
+			if err != nil {
+				t.Fatalf("extraKubeletOpts: %v", err)
+			}
+			if opts["node-labels"] != tc.labels {
+				t.Errorf("node-labels = %q, expected %q", opts["node-labels"], tc.labels)
+			}
+			if opts["register-with-taints"] != tc.taints {
+				t.Errorf("register-with-taints = %q, expected %q", opts["register-with-taints"], tc.taints)
+			}
+		})
+	}
+}
+
+func TestKubeletConfigPatch(t *testing.T) {
+	tests := []struct {
+		overrides []string
+		want      string
+		valid     bool
+	}{
+		{nil, `{}`, true},
+		{[]string{"maxPods=20", "serializeImagePulls=false", "cpuManagerPolicy=static"}, `{"cpuManagerPolicy":"static","maxPods":20,"serializeImagePulls":false}`, true},
+		{[]string{"evictionHard.memory.available=500Mi", "evictionHard.nodefs.available=10%"}, `{"evictionHard":{"memory.available":"500Mi","nodefs.available":"10%"}}`, true},
+		{[]string{"featureGates.InPlacePodVerticalScaling=true", "systemReserved.cpu=1"}, `{"featureGates":{"InPlacePodVerticalScaling":true},"systemReserved":{"cpu":"1"}}`, true},
+		{[]string{"maxPods"}, "", false},
+		{[]string{"MaxPods=20"}, "", false},
+		{[]string{"evictionHard.=1"}, "", false},
+	}
+	for _, tc := range tests {
+		got, err := KubeletConfigPatch(config.Node{KubeletConfig: tc.overrides})
+		if (err == nil) != tc.valid {
+			t.Errorf("KubeletConfigPatch(%v) error = %v, want valid = %t", tc.overrides, err, tc.valid)
+			continue
+		}
+		if tc.valid && string(got) != tc.want {
+			t.Errorf("KubeletConfigPatch(%v) = %s, want %s", tc.overrides, got, tc.want)
+		}
+	}
+}
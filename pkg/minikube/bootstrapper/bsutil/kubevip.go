@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bsutil
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"path"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil/ktmpl"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/images"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+	"k8s.io/minikube/pkg/network"
+	"k8s.io/minikube/pkg/util"
+)
+
+// KubeVipManifestPath is where the static pod of kube-vip is written on the control planes of a multi-control-plane cluster
+var KubeVipManifestPath = path.Join(vmpath.GuestManifestsDir, "kube-vip.yaml")
+
+// LoadBalancerManifestPath is where the kube-vip cloud provider allocating the IPs of LoadBalancer services is written
+var LoadBalancerManifestPath = path.Join(vmpath.GuestAddonsDir, "kube-vip-cloud-provider.yaml")
+
+// GenerateKubeVipManifest generates the kube-vip static pod announcing the virtual IP of the cluster, and the IPs of
+// the LoadBalancer services if the cluster has a pool, from the network interface iface of n
+func GenerateKubeVipManifest(cc config.ClusterConfig, n config.Node, iface string) ([]byte, error) {
+	vip := cc.KubernetesConfig.APIServerHAVIP
+	if vip == "" && cc.LoadBalancerPool == "" {
+		return nil, errors.New("the cluster has neither a virtual IP nor a load balancer pool")
+	}
+	version, err := util.ParseKubernetesVersion(cc.KubernetesConfig.KubernetesVersion)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing Kubernetes version")
+	}
+
+	// since v1.29 admin.conf is only bound to cluster-admin once kubeadm init is done, which in turn waits for the virtual IP
+	adminConf := "/etc/kubernetes/admin.conf"
+	if vip != "" && config.IsPrimaryControlPlane(cc, n) && version.GTE(semver.MustParse("1.29.0-alpha.0")) {
+		adminConf = "/etc/kubernetes/super-admin.conf"
+	}
+
+	opts := struct {
+		VIP       string
+		Port      int
+		Interface string
+		Image     string
+		AdminConf string
+		Services  bool
+	}{
+		VIP:       vip,
+		Port:      n.Port,
+		Interface: iface,
+		Image:     images.KubeVip(cc.KubernetesConfig.ImageRepository),
+		AdminConf: adminConf,
+		Services:  cc.LoadBalancerPool != "",
+	}
+
+	var b bytes.Buffer
+	if err := ktmpl.KubeVipTemplate.Execute(&b, opts); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// KubeVipInterface returns the network interface holding ip in the output of "ip -o -4 addr show", defaulting to eth0
+func KubeVipInterface(ipAddrs string, ip string) string {
+	for _, line := range strings.Split(ipAddrs, "\n") {
+		// 3: eth1    inet 192.168.39.2/24 brd 192.168.39.255 scope global dynamic eth1
+		fields := strings.Fields(line)
+		if len(fields) >= 4 && fields[2] == "inet" && strings.HasPrefix(fields[3], ip+"/") {
+			return fields[1]
+		}
+	}
+	return "eth0"
+}
+
+// LoadBalancerRange returns the START-END range of the load balancer pool of the cluster, resolving "auto" to the
+// addresses of the subnet of the primary control plane kept out of DHCP (.200 to .254), but the virtual IP of the cluster
+func LoadBalancerRange(cc config.ClusterConfig) (string, error) {
+	if cc.LoadBalancerPool != "auto" {
+		return cc.LoadBalancerPool, nil
+	}
+	cp, err := config.PrimaryControlPlane(&cc)
+	if err != nil {
+		return "", err
+	}
+	ip := net.ParseIP(cp.IP).To4()
+	if ip == nil {
+		return "", errors.Errorf("control plane IP %q is not an IPv4 address", cp.IP)
+	}
+	first, last := network.ReservedFrom, 254
+	vip := net.ParseIP(cc.KubernetesConfig.APIServerHAVIP).To4()
+	if vip == nil || !bytes.Equal(vip[:3], ip[:3]) || int(vip[3]) < first || int(vip[3]) > last {
+		return fmt.Sprintf("%d.%d.%d.%d-%d.%d.%d.%d", ip[0], ip[1], ip[2], first, ip[0], ip[1], ip[2], last), nil
+	}
+	// kube-vip cloud provider takes a comma separated list of ranges
+	var ranges []string
+	for _, r := range [][2]int{{first, int(vip[3]) - 1}, {int(vip[3]) + 1, last}} {
+		if r[0] <= r[1] {
+			ranges = append(ranges, fmt.Sprintf("%d.%d.%d.%d-%d.%d.%d.%d", ip[0], ip[1], ip[2], r[0], ip[0], ip[1], ip[2], r[1]))
+		}
+	}
+	return strings.Join(ranges, ","), nil
+}
+
+// GenerateLoadBalancerManifest generates the kube-vip cloud provider allocating the IPs of LoadBalancer services from the pool of the cluster
+func GenerateLoadBalancerManifest(cc config.ClusterConfig) ([]byte, error) {
+	r, err := LoadBalancerRange(cc)
+	if err != nil {
+		return nil, errors.Wrap(err, "load balancer range")
+	}
+	if r == "" {
+		return nil, errors.New("the cluster has no load balancer pool")
+	}
+
+	opts := struct {
+		Range string
+		Image string
+	}{
+		Range: r,
+		Image: images.KubeVipCloudProvider(cc.KubernetesConfig.ImageRepository),
+	}
+
+	var b bytes.Buffer
+	if err := ktmpl.KubeVipCloudProviderTemplate.Execute(&b, opts); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
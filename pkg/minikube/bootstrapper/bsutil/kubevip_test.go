@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bsutil
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestGenerateKubeVipManifest(t *testing.T) {
+	primary := config.Node{Name: "", IP: "192.168.49.2", Port: 8443, ControlPlane: true}
+	secondary := config.Node{Name: "m02", IP: "192.168.49.3", Port: 8443, ControlPlane: true}
+
+	tests := []struct {
+		description string
+		version     string
+		node        config.Node
+		adminConf   string
+	}{
+		{"primary before v1.29", "v1.28.4", primary, "path: /etc/kubernetes/admin.conf"},
+		{"primary since v1.29", "v1.29.0", primary, "path: /etc/kubernetes/super-admin.conf"},
+		{"secondary since v1.29", "v1.29.0", secondary, "path: /etc/kubernetes/admin.conf"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			cc := config.ClusterConfig{
+				KubernetesConfig: config.KubernetesConfig{KubernetesVersion: tc.version, APIServerHAVIP: "192.168.49.254"},
+				Nodes:            []config.Node{primary, secondary},
+			}
+			got, err := GenerateKubeVipManifest(cc, tc.node, "eth1")
+			if err != nil {
+				t.Fatalf("GenerateKubeVipManifest: %v", err)
+			}
+			for _, want := range []string{tc.adminConf, "value: 192.168.49.254", "value: eth1", `value: "8443"`, "ghcr.io/kube-vip/kube-vip:"} {
+				if !strings.Contains(string(got), want) {
+					t.Errorf("manifest does not contain %q:\n%s", want, got)
+				}
+			}
+		})
+	}
+
+	if _, err := GenerateKubeVipManifest(config.ClusterConfig{Nodes: []config.Node{primary}}, primary, "eth0"); err == nil {
+		t.Errorf("expected an error for a cluster without virtual IP")
+	}
+
+	cc := config.ClusterConfig{
+		KubernetesConfig: config.KubernetesConfig{KubernetesVersion: "v1.29.0"},
+		Nodes:            []config.Node{primary},
+		LoadBalancerPool: "auto",
+	}
+	got, err := GenerateKubeVipManifest(cc, primary, "eth0")
+	if err != nil {
+		t.Fatalf("GenerateKubeVipManifest with a load balancer pool: %v", err)
+	}
+	if !strings.Contains(string(got), "svc_enable") || !strings.Contains(string(got), "path: /etc/kubernetes/admin.conf") {
+		t.Errorf("manifest does not announce the services with admin.conf:\n%s", got)
+	}
+	for _, unwanted := range []string{"cp_enable", "name: address", "name: port"} {
+		if strings.Contains(string(got), unwanted) {
+			t.Errorf("manifest of a cluster without virtual IP contains %q:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestLoadBalancerRange(t *testing.T) {
+	nodes := []config.Node{{Name: "", IP: "192.168.39.12", ControlPlane: true}}
+	tests := []struct {
+		pool     string
+		expected string
+	}{
+		{"", ""},
+		{"auto", "192.168.39.200-192.168.39.254"},
+		{"10.0.0.10-10.0.0.20", "10.0.0.10-10.0.0.20"},
+	}
+	for _, tc := range tests {
+		got, err := LoadBalancerRange(config.ClusterConfig{Nodes: nodes, LoadBalancerPool: tc.pool})
+		if err != nil {
+			t.Fatalf("LoadBalancerRange(%q): %v", tc.pool, err)
+		}
+		if got != tc.expected {
+			t.Errorf("LoadBalancerRange(%q) = %q, expected %q", tc.pool, got, tc.expected)
+		}
+	}
+
+	// the virtual IP of the control plane is left out of the pool
+	vips := []struct {
+		vip      string
+		expected string
+	}{
+		{"192.168.39.254", "192.168.39.200-192.168.39.253"},
+		{"192.168.39.210", "192.168.39.200-192.168.39.209,192.168.39.211-192.168.39.254"},
+		{"192.168.39.100", "192.168.39.200-192.168.39.254"},
+	}
+	for _, tc := range vips {
+		cc := config.ClusterConfig{Nodes: nodes, LoadBalancerPool: "auto", KubernetesConfig: config.KubernetesConfig{APIServerHAVIP: tc.vip}}
+		got, err := LoadBalancerRange(cc)
+		if err != nil {
+			t.Fatalf("LoadBalancerRange with VIP %s: %v", tc.vip, err)
+		}
+		if got != tc.expected {
+			t.Errorf("LoadBalancerRange with VIP %s = %q, expected %q", tc.vip, got, tc.expected)
+		}
+	}
+
+	manifest, err := GenerateLoadBalancerManifest(config.ClusterConfig{Nodes: nodes, LoadBalancerPool: "auto"})
+	if err != nil {
+		t.Fatalf("GenerateLoadBalancerManifest: %v", err)
+	}
+	for _, want := range []string{"range-global: 192.168.39.200-192.168.39.254", "ghcr.io/kube-vip/kube-vip-cloud-provider:"} {
+		if !strings.Contains(string(manifest), want) {
+			t.Errorf("manifest does not contain %q:\n%s", want, manifest)
+		}
+	}
+}
+
+func TestKubeVipInterface(t *testing.T) {
+	ipAddrs := `1: lo    inet 127.0.0.1/8 scope host lo\       valid_lft forever preferred_lft forever
+2: eth0    inet 192.168.122.45/24 brd 192.168.122.255 scope global dynamic eth0\       valid_lft 3513sec preferred_lft 3513sec
+3: eth1    inet 192.168.39.2/24 brd 192.168.39.255 scope global dynamic eth1\       valid_lft 3513sec preferred_lft 3513sec
+`
+	tests := []struct {
+		ip       string
+		expected string
+	}{
+		{"192.168.39.2", "eth1"},
+		{"192.168.122.45", "eth0"},
+		{"192.168.39.20", "eth0"},
+	}
+	for _, tc := range tests {
+		if got := KubeVipInterface(ipAddrs, tc.ip); got != tc.expected {
+			t.Errorf("KubeVipInterface(%q) = %q, expected %q", tc.ip, got, tc.expected)
+		}
+	}
+}
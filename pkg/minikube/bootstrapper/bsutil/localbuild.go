@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bsutil
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/bootstrapper/images"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/util"
+)
+
+// LocalBuildComponents are the images of the core components in a local build of Kubernetes, as saved by `make quick-release-images`
+var LocalBuildComponents = []string{"kube-apiserver", "kube-controller-manager", "kube-scheduler", "kube-proxy"}
+
+// CheckLocalBuild checks that dir holds the images and the binaries of a local build of Kubernetes
+func CheckLocalBuild(dir string) error {
+	var missing []string
+	for _, name := range LocalBuildComponents {
+		if _, err := os.Stat(filepath.Join(dir, name+".tar")); err != nil {
+			missing = append(missing, name+".tar")
+		}
+	}
+	for _, name := range constants.KubernetesReleaseBinaries {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s is missing %s of the local build of Kubernetes", dir, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// LocalBuildVersion returns the version of the local build of Kubernetes in dir, from the tag of its kube-apiserver image
+func LocalBuildVersion(dir string) (string, error) {
+	tag, err := imageTarTag(filepath.Join(dir, "kube-apiserver.tar"))
+	if err != nil {
+		return "", err
+	}
+	return versionFromTag(tag)
+}
+
+// versionFromTag returns the Kubernetes version of an image tag, where the "+" of the build metadata is replaced by "_"
+func versionFromTag(ref string) (string, error) {
+	i := strings.LastIndex(ref, ":")
+	if i < 0 || strings.Contains(ref[i:], "/") {
+		return "", fmt.Errorf("image %q has no tag", ref)
+	}
+	v := strings.Replace(ref[i+1:], "_", "+", 1)
+	if _, err := util.ParseKubernetesVersion(v); err != nil {
+		return "", errors.Wrapf(err, "tag of %s is not a Kubernetes version", ref)
+	}
+	return v, nil
+}
+
+// imageTarTag returns the first tag of the image saved in a tarball by `docker save`
+func imageTarTag(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return "", fmt.Errorf("%s has no manifest.json, it was not saved by docker", path)
+		}
+		if err != nil {
+			return "", errors.Wrapf(err, "reading %s", path)
+		}
+		if hdr.Name != "manifest.json" {
+			continue
+		}
+		var manifest []struct {
+			RepoTags []string
+		}
+		if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+			return "", errors.Wrapf(err, "decoding manifest of %s", path)
+		}
+		if len(manifest) == 0 || len(manifest[0].RepoTags) == 0 {
+			return "", fmt.Errorf("the image in %s has no tag", path)
+		}
+		return manifest[0].RepoTags[0], nil
+	}
+}
+
+// LoadLocalBuildImages loads the images of the local build of Kubernetes into the container runtime of the node, tagged for the
+// version of the cluster as kubeadm expects them. The images are retagged, since they carry the architecture in their name, and
+// may come from different builds when only some of them were rebuilt.
+func LoadLocalBuildImages(cc config.ClusterConfig, c command.Runner) error {
+	dir := cc.KubernetesConfig.KubernetesImagesDir
+	version, err := util.ParseKubernetesVersion(cc.KubernetesConfig.KubernetesVersion)
+	if err != nil {
+		return errors.Wrap(err, "parsing Kubernetes version")
+	}
+	cr, err := cruntime.New(cruntime.Config{Type: cc.KubernetesConfig.ContainerRuntime, Runner: c})
+	if err != nil {
+		return errors.Wrap(err, "runtime")
+	}
+
+	var tars []string
+	for _, name := range LocalBuildComponents {
+		tars = append(tars, filepath.Join(dir, name+".tar"))
+	}
+	if err := machine.LoadLocalImages(&cc, c, tars); err != nil {
+		return err
+	}
+
+	for i, name := range LocalBuildComponents {
+		tag, err := imageTarTag(tars[i])
+		if err != nil {
+			return err
+		}
+		target := images.Component(name, version, cc.KubernetesConfig.ImageRepository)
+		if tag == target {
+			continue
+		}
+		// the tag of a previous start may point at an older build
+		if err := cr.RemoveImage(target); err != nil {
+			klog.Infof("unable to remove %s: %v", target, err)
+		}
+		if err := cr.TagImage(tag, target); err != nil {
+			return errors.Wrapf(err, "tagging %s as %s", tag, target)
+		}
+	}
+	return nil
+}
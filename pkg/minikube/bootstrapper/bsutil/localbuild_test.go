@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bsutil
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeImageTar writes a tarball with the manifest.json of `docker save` for the tag
+func writeImageTar(t *testing.T, path, tag string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	tw := tar.NewWriter(f)
+	manifest := `[{"Config":"config.json","RepoTags":["` + tag + `"],"Layers":[]}]`
+	if err := tw.WriteHeader(&tar.Header{Name: "manifest.json", Mode: 0644, Size: int64(len(manifest))}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tw.Write([]byte(manifest)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLocalBuild(t *testing.T) {
+	dir := t.TempDir()
+	writeImageTar(t, filepath.Join(dir, "kube-apiserver.tar"), "registry.k8s.io/kube-apiserver-amd64:v1.31.0-alpha.0.123_0123456789ab")
+
+	err := CheckLocalBuild(dir)
+	if err == nil || !strings.Contains(err.Error(), "kube-proxy.tar") || !strings.Contains(err.Error(), "kubelet") {
+		t.Errorf("CheckLocalBuild() = %v, expected the missing images and binaries", err)
+	}
+	for _, name := range []string{"kube-controller-manager", "kube-scheduler", "kube-proxy"} {
+		writeImageTar(t, filepath.Join(dir, name+".tar"), "registry.k8s.io/"+name+"-amd64:v1.31.0-alpha.0.123_0123456789ab")
+	}
+	for _, name := range []string{"kubeadm", "kubelet", "kubectl"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte{}, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := CheckLocalBuild(dir); err != nil {
+		t.Errorf("CheckLocalBuild() = %v", err)
+	}
+
+	v, err := LocalBuildVersion(dir)
+	if err != nil {
+		t.Fatalf("LocalBuildVersion() = %v", err)
+	}
+	if v != "v1.31.0-alpha.0.123+0123456789ab" {
+		t.Errorf("LocalBuildVersion() = %q, expected %q", v, "v1.31.0-alpha.0.123+0123456789ab")
+	}
+}
+
+func TestVersionFromTag(t *testing.T) {
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{"registry.k8s.io/kube-apiserver-amd64:v1.31.0-alpha.0.123_0123456789ab", "v1.31.0-alpha.0.123+0123456789ab", false},
+		{"localhost:5000/kube-apiserver:v1.30.1", "v1.30.1", false},
+		{"localhost:5000/kube-apiserver", "", true},
+		{"registry.k8s.io/kube-apiserver:latest", "", true},
+	}
+	for _, tc := range tests {
+		got, err := versionFromTag(tc.ref)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("versionFromTag(%q) = %q, %v; want %q, error: %t", tc.ref, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bsutil
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+
+	"github.com/blang/semver/v4"
+	"github.com/pkg/errors"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/util"
+)
+
+// kubeadmPatchRegexp matches the names of the patch files of kubeadm: TARGET[SUFFIX][+PATCHTYPE].EXTENSION
+var kubeadmPatchRegexp = regexp.MustCompile(`^(kube-apiserver|kube-controller-manager|kube-scheduler|etcd|kubeletconfiguration|corednsdeployment)[^+.]*(\+(strategic|merge|json))?\.(json|yaml)$`)
+
+var (
+	// minKubeadmPatchesVersion is the first Kubernetes version whose kubeadm has the --patches flag
+	minKubeadmPatchesVersion = semver.MustParse("1.22.0")
+	// minKubeletStartPatchesVersion is the first Kubernetes version whose kubelet-start phase has the --patches flag
+	minKubeletStartPatchesVersion = semver.MustParse("1.25.0")
+)
+
+// ValidateKubeadmPatches checks that the directory dir only holds patches kubeadm applies
+func ValidateKubeadmPatches(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return errors.Wrap(err, "read the kubeadm patches")
+	}
+	for _, e := range entries {
+		if e.IsDir() || !kubeadmPatchRegexp.MatchString(e.Name()) {
+			return errors.Errorf("%s is not a kubeadm patch, expected TARGET[SUFFIX][+PATCHTYPE].EXTENSION where TARGET is kube-apiserver, kube-controller-manager, kube-scheduler, etcd, kubeletconfiguration or corednsdeployment, PATCHTYPE is strategic, merge or json, and EXTENSION is json or yaml", filepath.Join(dir, e.Name()))
+		}
+	}
+	return nil
+}
+
+// KubeadmPatches returns the patches of the --kubeadm-patches directory of cc to copy into KubeadmPatchesDir
+func KubeadmPatches(cc config.ClusterConfig) ([]assets.CopyableFile, error) {
+	dir := cc.KubernetesConfig.KubeadmPatches
+	if dir == "" {
+		return nil, nil
+	}
+	version, err := util.ParseKubernetesVersion(cc.KubernetesConfig.KubernetesVersion)
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing Kubernetes version")
+	}
+	if version.LT(minKubeadmPatchesVersion) {
+		return nil, errors.Errorf("--kubeadm-patches requires Kubernetes v%s or later", minKubeadmPatchesVersion)
+	}
+	if err := ValidateKubeadmPatches(dir); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "read the kubeadm patches")
+	}
+	var files []assets.CopyableFile
+	for _, e := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, errors.Wrap(err, "read the kubeadm patches")
+		}
+		files = append(files, assets.NewMemoryAssetTarget(b, path.Join(KubeadmPatchesDir, e.Name()), "0644"))
+	}
+	return files, nil
+}
+
+// KubeadmPatchesFlag returns the flag applying the patches of KubeadmPatchesDir to the kubeadm commands of the nodes of cc
+func KubeadmPatchesFlag(cc config.ClusterConfig) string {
+	if cc.KubernetesConfig.KubeadmPatches == "" {
+		return ""
+	}
+	return "--patches " + KubeadmPatchesDir
+}
+
+// KubeletStartPatchesFlag returns the flag applying the kubeletconfiguration patches to the kubelet-start phase of kubeadm v, which only has it since v1.25
+func KubeletStartPatchesFlag(cc config.ClusterConfig, v semver.Version) string {
+	if v.LT(minKubeletStartPatchesVersion) {
+		return ""
+	}
+	return KubeadmPatchesFlag(cc)
+}
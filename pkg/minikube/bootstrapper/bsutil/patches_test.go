@@ -0,0 +1,79 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blang/semver/v4"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestValidateKubeadmPatches(t *testing.T) {
+	tests := []struct {
+		files []string
+		valid bool
+	}{
+		{[]string{"kube-apiserver+merge.yaml", "etcd.json", "kube-scheduler0+json.yaml", "kubeletconfiguration+strategic.yaml"}, true},
+		{[]string{"kube-proxy.yaml"}, false},
+		{[]string{"kube-apiserver+yaml.yaml"}, false},
+		{[]string{"kube-apiserver.txt"}, false},
+	}
+	for _, tc := range tests {
+		dir := t.TempDir()
+		for _, f := range tc.files {
+			if err := os.WriteFile(filepath.Join(dir, f), []byte("{}"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := ValidateKubeadmPatches(dir); (err == nil) != tc.valid {
+			t.Errorf("ValidateKubeadmPatches(%v) = %v, expected valid=%t", tc.files, err, tc.valid)
+		}
+	}
+}
+
+func TestKubeadmPatches(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "kube-apiserver+merge.yaml"), []byte("spec: {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cc := config.ClusterConfig{KubernetesConfig: config.KubernetesConfig{KubernetesVersion: "v1.28.4", KubeadmPatches: dir}}
+	files, err := KubeadmPatches(cc)
+	if err != nil {
+		t.Fatalf("KubeadmPatches: %v", err)
+	}
+	if len(files) != 1 || files[0].GetTargetPath() != KubeadmPatchesDir+"/kube-apiserver+merge.yaml" {
+		t.Errorf("KubeadmPatches() = %v, expected %s/kube-apiserver+merge.yaml", files, KubeadmPatchesDir)
+	}
+	if flag := KubeadmPatchesFlag(cc); flag != "--patches "+KubeadmPatchesDir {
+		t.Errorf("KubeadmPatchesFlag() = %q", flag)
+	}
+	if flag := KubeletStartPatchesFlag(cc, semver.MustParse("1.28.4")); flag != "--patches "+KubeadmPatchesDir {
+		t.Errorf("KubeletStartPatchesFlag(v1.28.4) = %q", flag)
+	}
+	if flag := KubeletStartPatchesFlag(cc, semver.MustParse("1.24.0")); flag != "" {
+		t.Errorf("KubeletStartPatchesFlag(v1.24.0) = %q, expected none", flag)
+	}
+
+	cc.KubernetesConfig.KubernetesVersion = "v1.21.0"
+	if _, err := KubeadmPatches(cc); err == nil {
+		t.Errorf("KubeadmPatches() did not fail for Kubernetes v1.21.0")
+	}
+}
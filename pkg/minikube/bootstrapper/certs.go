@@ -22,6 +22,7 @@ import (
 	"encoding/pem"
 	"fmt"
 	"net"
+	"net/url"
 	"os"
 	"os/exec"
 	"path"
@@ -42,12 +43,15 @@ import (
 	"k8s.io/minikube/pkg/minikube/command"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
 	"k8s.io/minikube/pkg/minikube/kubeconfig"
 	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
 	"k8s.io/minikube/pkg/minikube/vmpath"
 	"k8s.io/minikube/pkg/util"
 	"k8s.io/minikube/pkg/util/lock"
+	"k8s.io/minikube/pkg/util/retry"
 )
 
 // SetupCerts gets the generated credentials required to talk to the APIServer.
@@ -55,7 +59,7 @@ func SetupCerts(cmd command.Runner, k8s config.ClusterConfig, n config.Node) err
 	localPath := localpath.Profile(k8s.KubernetesConfig.ClusterName)
 	klog.Infof("Setting up %s for IP: %s\n", localPath, n.IP)
 
-	ccs, regen, err := generateSharedCACerts()
+	ccs, regen, err := generateSharedCACerts(k8s.KubernetesConfig.ClusterName)
 	if err != nil {
 		return errors.Wrap(err, "shared CA certs")
 	}
@@ -92,6 +96,16 @@ func SetupCerts(cmd command.Runner, k8s config.ClusterConfig, n config.Node) err
 		copyableFiles = append(copyableFiles, certFile)
 	}
 
+	if k8s.ImportHostCerts {
+		imported, err := importHostCACerts()
+		if err != nil {
+			out.WarningT("Unable to import CA certificates from the host trust store: {{.error}}", out.V{"error": err})
+		}
+		if len(imported) > 0 {
+			out.Step(style.Permissions, "Imported {{.count}} CA certificates from the host trust store", out.V{"count": len(imported)})
+		}
+	}
+
 	caCerts, err := collectCACerts()
 	if err != nil {
 		return err
@@ -137,7 +151,7 @@ func SetupCerts(cmd command.Runner, k8s config.ClusterConfig, n config.Node) err
 		}
 	}
 
-	if err := installCertSymlinks(cmd, caCerts); err != nil {
+	if err := installCertSymlinks(cmd, k8s.KubernetesConfig.ClusterName, caCerts); err != nil {
 		return errors.Wrapf(err, "certificate symlinks")
 	}
 
@@ -147,6 +161,20 @@ func SetupCerts(cmd command.Runner, k8s config.ClusterConfig, n config.Node) err
 	return nil
 }
 
+// haAPIServerIPs returns the virtual IP and the IPs of the other control planes of a multi-control-plane cluster
+func haAPIServerIPs(cfg config.ClusterConfig, n config.Node) []net.IP {
+	var ips []net.IP
+	if cfg.KubernetesConfig.APIServerHAVIP != "" {
+		ips = append(ips, net.ParseIP(cfg.KubernetesConfig.APIServerHAVIP))
+	}
+	for _, cp := range config.ControlPlanes(cfg) {
+		if cp.IP != "" && cp.IP != n.IP {
+			ips = append(ips, net.ParseIP(cp.IP))
+		}
+	}
+	return ips
+}
+
 // CACerts has cert and key for CA (and Proxy)
 type CACerts struct {
 	caCert    string
@@ -156,7 +184,8 @@ type CACerts struct {
 }
 
 // generateSharedCACerts generates CA certs shared among profiles, but only if missing
-func generateSharedCACerts() (CACerts, bool, error) {
+// the operations are recorded in the cert history of the profile that triggered them
+func generateSharedCACerts(profile string) (CACerts, bool, error) {
 	regenProfileCerts := false
 	globalPath := localpath.MiniPath()
 	cc := CACerts{
@@ -195,7 +224,8 @@ func generateSharedCACerts() (CACerts, bool, error) {
 	defer releaser.Release()
 
 	for _, ca := range caCertSpecs {
-		if isValid(ca.certPath, ca.keyPath) {
+		valid, why := isValid(profile, ca.certPath, ca.keyPath)
+		if valid {
 			klog.Infof("skipping %s CA generation: %s", ca.subject, ca.keyPath)
 			continue
 		}
@@ -205,6 +235,7 @@ func generateSharedCACerts() (CACerts, bool, error) {
 		if err := util.GenerateCACert(ca.certPath, ca.keyPath, ca.subject); err != nil {
 			return cc, false, errors.Wrap(err, "generate ca cert")
 		}
+		recordCertEvent(profile, CertOpGenerate, ca.certPath, why)
 	}
 
 	return cc, regenProfileCerts, nil
@@ -229,6 +260,18 @@ func generateProfileCerts(cfg config.ClusterConfig, n config.Node, ccs CACerts,
 	apiServerIPs := k8s.APIServerIPs
 	apiServerIPs = append(apiServerIPs,
 		net.ParseIP(n.IP), serviceIP, net.ParseIP(oci.DefaultBindIPV4), net.ParseIP("10.0.0.1"))
+	// the pods of an IPv6 cluster reach the apiserver on the first IPv6 service IP
+	if config.HasIPv6(cfg) {
+		serviceIPv6, err := util.GetServiceClusterIP(constants.DefaultServiceCIDRv6)
+		if err != nil {
+			return nil, errors.Wrap(err, "getting IPv6 service cluster ip")
+		}
+		apiServerIPs = append(apiServerIPs, net.ParseIP(n.IPv6), serviceIPv6, net.IPv6loopback)
+	}
+	// the apiservers of a multi-control-plane cluster share the cert, and are reached through the virtual IP
+	if config.IsHA(cfg) {
+		apiServerIPs = append(apiServerIPs, haAPIServerIPs(cfg, n)...)
+	}
 
 	apiServerNames := k8s.APIServerNames
 	apiServerNames = append(apiServerNames, k8s.APIServerName, constants.ControlPlaneAlias)
@@ -237,7 +280,7 @@ func generateProfileCerts(cfg config.ClusterConfig, n config.Node, ccs CACerts,
 	apiServerAlternateNames = append(apiServerAlternateNames,
 		util.GetAlternateDNS(k8s.DNSDomain)...)
 
-	daemonHost := oci.DaemonHost(k8s.ContainerRuntime)
+	daemonHost := oci.DaemonHost(cfg.Driver)
 	if daemonHost != oci.DefaultBindIPV4 {
 		daemonHostIP := net.ParseIP(daemonHost)
 		// if daemonHost is an IP we add it to the certificate's IPs, otherwise we assume it's an hostname and add it to the alternate names
@@ -248,14 +291,28 @@ func generateProfileCerts(cfg config.ClusterConfig, n config.Node, ccs CACerts,
 		}
 	}
 
+	apiServerURIs, clientURIs, err := spiffeURIs(k8s.SPIFFETrustDomain)
+	if err != nil {
+		return nil, errors.Wrap(err, "spiffe ids")
+	}
+
 	// Generate a hash input for certs that depend on ip/name combinations
 	hi := []string{}
 	hi = append(hi, apiServerAlternateNames...)
 	for _, ip := range apiServerIPs {
 		hi = append(hi, ip.String())
 	}
+	for _, u := range apiServerURIs {
+		hi = append(hi, u.String())
+	}
 	sort.Strings(hi)
 
+	// the client cert only needs a hash if it carries a SPIFFE ID, so that changing the trust domain regenerates it
+	clientHash := ""
+	if len(clientURIs) > 0 {
+		clientHash = fmt.Sprintf("%x", sha1.Sum([]byte(clientURIs[0].String())))[0:8]
+	}
+
 	specs := []struct {
 		certPath string
 		keyPath  string
@@ -264,15 +321,18 @@ func generateProfileCerts(cfg config.ClusterConfig, n config.Node, ccs CACerts,
 		subject        string
 		ips            []net.IP
 		alternateNames []string
+		uris           []*url.URL
 		caCertPath     string
 		caKeyPath      string
 	}{
 		{ // Client cert
+			hash:           clientHash,
 			certPath:       localpath.ClientCert(k8s.ClusterName),
 			keyPath:        localpath.ClientKey(k8s.ClusterName),
 			subject:        "minikube-user",
 			ips:            []net.IP{},
 			alternateNames: []string{},
+			uris:           clientURIs,
 			caCertPath:     ccs.caCert,
 			caKeyPath:      ccs.caKey,
 		},
@@ -283,6 +343,7 @@ func generateProfileCerts(cfg config.ClusterConfig, n config.Node, ccs CACerts,
 			subject:        "minikube",
 			ips:            apiServerIPs,
 			alternateNames: apiServerAlternateNames,
+			uris:           apiServerURIs,
 			caCertPath:     ccs.caCert,
 			caKeyPath:      ccs.caKey,
 		},
@@ -311,27 +372,41 @@ func generateProfileCerts(cfg config.ClusterConfig, n config.Node, ccs CACerts,
 			kp = kp + "." + spec.hash
 		}
 
-		if !regen && isValid(cp, kp) {
-			klog.Infof("skipping %s signed cert generation: %s", spec.subject, kp)
-			continue
+		why := CertReasonCAChanged
+		if !regen {
+			valid, invalidReason := isValid(k8s.ClusterName, cp, kp)
+			// without a trust domain the client cert has no hash, so the SPIFFE ID of a removed one is only seen in the cert
+			if valid && !hasURIs(cp, spec.uris) {
+				valid, invalidReason = false, CertReasonURIsChanged
+			}
+			if valid {
+				klog.Infof("skipping %s signed cert generation: %s", spec.subject, kp)
+				continue
+			}
+			why = invalidReason
+			if why == CertReasonMissing && spec.hash != "" && canRead(spec.certPath) {
+				why = CertReasonHashChanged
+			}
 		}
 
 		klog.Infof("generating %s signed cert: %s", spec.subject, kp)
 		if canRead(cp) {
 			os.Remove(cp)
+			recordCertEvent(k8s.ClusterName, CertOpDelete, cp, why)
 		}
 		if canRead(kp) {
 			os.Remove(kp)
 		}
 		err := util.GenerateSignedCert(
 			cp, kp, spec.subject,
-			spec.ips, spec.alternateNames,
+			spec.ips, spec.alternateNames, spec.uris,
 			spec.caCertPath, spec.caKeyPath,
 			cfg.CertExpiration,
 		)
 		if err != nil {
 			return xfer, errors.Wrapf(err, "generate signed cert for %q", spec.subject)
 		}
+		recordCertEvent(k8s.ClusterName, CertOpGenerate, cp, why)
 
 		if spec.hash != "" {
 			klog.Infof("copying %s -> %s", cp, spec.certPath)
@@ -348,38 +423,290 @@ func generateProfileCerts(cfg config.ClusterConfig, n config.Node, ccs CACerts,
 	return xfer, nil
 }
 
+// GenerateRegistryCert generates the serving cert of the registry addon of cfg, signed by the minikube CA which the nodes already trust,
+// for the names of its service and for hosts, the addresses the host reaches it at
+func GenerateRegistryCert(cfg config.ClusterConfig, hosts []string) error {
+	if _, _, err := generateSharedCACerts(cfg.Name); err != nil {
+		return errors.Wrap(err, "shared CA certs")
+	}
+	ips := []net.IP{net.ParseIP(oci.DefaultBindIPV4), net.IPv6loopback}
+	names := []string{"localhost", "registry.minikube", "registry.kube-system", "registry.kube-system.svc", "registry.kube-system.svc." + cfg.KubernetesConfig.DNSDomain}
+	for _, n := range cfg.Nodes {
+		if ip := net.ParseIP(n.IP); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			ips = append(ips, ip)
+			continue
+		}
+		names = append(names, h)
+	}
+	// the registry-aliases addon points more names to the registry
+	for _, a := range strings.Fields(strings.ReplaceAll(cfg.KubernetesConfig.RegistryAliases, ",", " ")) {
+		names = append(names, a)
+	}
+
+	cp, kp := localpath.RegistryCert(cfg.Name), localpath.RegistryKey(cfg.Name)
+	// the registry only loads its cert when it starts, so a cert still covering all the names is kept
+	if b, err := os.ReadFile(cp); err == nil && canRead(kp) {
+		if c, err := util.ParseCertificate(b); err == nil && time.Until(c.NotAfter) > 24*time.Hour && certCovers(c, localpath.CACert(), ips, names) {
+			return nil
+		}
+	}
+	klog.Infof("generating the registry addon cert %s for %v %v", cp, ips, names)
+	if err := util.GenerateSignedCert(cp, kp, "registry", ips, names, nil, localpath.CACert(), filepath.Join(localpath.MiniPath(), "ca.key"), cfg.CertExpiration); err != nil {
+		return errors.Wrap(err, "generate the registry addon cert")
+	}
+	return nil
+}
+
+// certCovers returns whether the cert c is signed by the CA of the file ca, and valid for all the IPs ips and names
+func certCovers(c *x509.Certificate, ca string, ips []net.IP, names []string) bool {
+	b, err := os.ReadFile(ca)
+	if err != nil {
+		return false
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(b) {
+		return false
+	}
+	if _, err := c.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}); err != nil {
+		return false
+	}
+	for _, ip := range ips {
+		if c.VerifyHostname(ip.String()) != nil {
+			return false
+		}
+	}
+	for _, n := range names {
+		if c.VerifyHostname(n) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// spiffeURIs returns the SPIFFE IDs to embed as URI SANs in the apiserver and client certs, if a trust domain is set
+func spiffeURIs(trustDomain string) ([]*url.URL, []*url.URL, error) {
+	if trustDomain == "" {
+		return nil, nil, nil
+	}
+	apiServerID, err := util.GetSPIFFEID(trustDomain, "ns/kube-system/sa/kube-apiserver")
+	if err != nil {
+		return nil, nil, err
+	}
+	clientID, err := util.GetSPIFFEID(trustDomain, "user/minikube-user")
+	if err != nil {
+		return nil, nil, err
+	}
+	return []*url.URL{apiServerID}, []*url.URL{clientID}, nil
+}
+
+// kubeadmCertComponents maps the certificates managed by kubeadm to the static pods which load them
+var kubeadmCertComponents = map[string][]string{
+	"apiserver-etcd-client":    {"kube-apiserver"},
+	"apiserver-kubelet-client": {"kube-apiserver"},
+	"front-proxy-client":       {"kube-apiserver"},
+	"etcd-server":              {"etcd"},
+	"etcd-peer":                {"etcd"},
+	"etcd-healthcheck-client":  {"etcd"},
+	"controller-manager.conf":  {"kube-controller-manager"},
+	"scheduler.conf":           {"kube-scheduler"},
+}
+
+// kubeadmCertPath returns the path of a kubeadm managed certificate (or kubeconfig) inside the node
+func kubeadmCertPath(cert string) string {
+	if strings.HasSuffix(cert, ".conf") {
+		return path.Join("/etc/kubernetes", cert)
+	}
+	certPath := []string{vmpath.GuestPersistentDir, "certs"}
+	// certs starting with "etcd-" are in the "etcd" dir
+	// ex: etcd-server => etcd/server
+	if strings.HasPrefix(cert, "etcd-") {
+		certPath = append(certPath, "etcd")
+	}
+	certPath = append(certPath, strings.TrimPrefix(cert, "etcd-")+".crt")
+	return path.Join(certPath...)
+}
+
+// affectedComponents returns the sorted list of static pods that have to be restarted to load the given certs
+func affectedComponents(certs []string) []string {
+	seen := map[string]bool{}
+	components := []string{}
+	for _, cert := range certs {
+		for _, c := range kubeadmCertComponents[cert] {
+			if !seen[c] {
+				seen[c] = true
+				components = append(components, c)
+			}
+		}
+	}
+	sort.Strings(components)
+	return components
+}
+
+// generateKubeadmCerts renews the expired certificates managed by kubeadm, and restarts only the static pods using them
 func generateKubeadmCerts(cmd command.Runner, cc config.ClusterConfig) error {
 	if _, err := cmd.RunCmd(exec.Command("ls", path.Join(vmpath.GuestPersistentDir, "certs", "etcd"))); err != nil {
 		klog.Infof("certs directory doesn't exist, likely first start: %v", err)
 		return nil
 	}
 
-	expiredCerts := false
-	certs := []string{"apiserver-etcd-client", "apiserver-kubelet-client", "etcd-server", "etcd-healthcheck-client", "etcd-peer", "front-proxy-client"}
+	expired := []string{}
+	certs := make([]string, 0, len(kubeadmCertComponents))
+	for cert := range kubeadmCertComponents {
+		certs = append(certs, cert)
+	}
+	sort.Strings(certs)
 	for _, cert := range certs {
-		certPath := []string{vmpath.GuestPersistentDir, "certs"}
-		// certs starting with "etcd-" are in the "etcd" dir
-		// ex: etcd-server => etcd/server
-		if strings.HasPrefix(cert, "etcd-") {
-			certPath = append(certPath, "etcd")
-		}
-		certPath = append(certPath, strings.TrimPrefix(cert, "etcd-")+".crt")
-		if !isKubeadmCertValid(cmd, path.Join(certPath...)) {
-			expiredCerts = true
+		if !isKubeadmCertValid(cmd, kubeadmCertPath(cert)) {
+			expired = append(expired, cert)
 		}
 	}
-	if !expiredCerts {
+	if len(expired) == 0 {
 		return nil
 	}
 	out.WarningT("kubeadm certificates have expired. Generating new ones...")
 	kubeadmPath := path.Join(vmpath.GuestPersistentDir, "binaries", cc.KubernetesConfig.KubernetesVersion)
-	bashCmd := fmt.Sprintf("sudo env PATH=\"%s:$PATH\" kubeadm certs renew all --config %s", kubeadmPath, constants.KubeadmYamlPath)
-	if _, err := cmd.RunCmd(exec.Command("/bin/bash", "-c", bashCmd)); err != nil {
-		return fmt.Errorf("failed to renew kubeadm certs: %v", err)
+	for _, cert := range expired {
+		bashCmd := fmt.Sprintf("sudo env PATH=\"%s:$PATH\" kubeadm certs renew %s --config %s", kubeadmPath, cert, constants.KubeadmYamlPath)
+		if _, err := cmd.RunCmd(exec.Command("/bin/bash", "-c", bashCmd)); err != nil {
+			return fmt.Errorf("failed to renew kubeadm cert %s: %v", cert, err)
+		}
+		recordCertEvent(cc.KubernetesConfig.ClusterName, CertOpRenew, kubeadmCertPath(cert), CertReasonExpired)
+	}
+
+	if err := restartStaticPods(cmd, cc, affectedComponents(expired)); err != nil {
+		return errors.Wrap(err, "restart static pods")
+	}
+
+	for _, cert := range expired {
+		if !isKubeadmCertValid(cmd, kubeadmCertPath(cert)) {
+			return fmt.Errorf("kubeadm cert %s is still expired after renewal", cert)
+		}
+		klog.Infof("renewed kubeadm cert %s, %s", cert, kubeadmCertEndDate(cmd, kubeadmCertPath(cert)))
+	}
+	for _, sc := range servedCerts(cc, expired) {
+		if err := verifyServedCert(cmd, sc.addr, sc.certPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// servedCert is the certificate at certPath, which a component serves at addr inside the node
+type servedCert struct {
+	addr     string
+	certPath string
+}
+
+// servedCerts returns the certificates served by the components restarted to load the renewed certs, to check that they loaded them:
+// the one of the apiserver on the port the API is reached at, when a cert of the apiserver was renewed, and the one of etcd when it was renewed
+func servedCerts(cc config.ClusterConfig, expired []string) []servedCert {
+	port := cc.APIServerPort
+	if port <= 0 {
+		port = constants.APIServerPort
+	}
+	served := []servedCert{}
+	apiserver, etcd := false, false
+	for _, cert := range expired {
+		for _, c := range kubeadmCertComponents[cert] {
+			if c == "kube-apiserver" {
+				apiserver = true
+			}
+		}
+		if cert == "etcd-server" {
+			etcd = true
+		}
+	}
+	if apiserver {
+		served = append(served, servedCert{net.JoinHostPort("127.0.0.1", fmt.Sprint(port)), path.Join(vmpath.GuestPersistentDir, "certs", "apiserver.crt")})
+	}
+	if etcd {
+		served = append(served, servedCert{"127.0.0.1:2379", kubeadmCertPath("etcd-server")})
+	}
+	return served
+}
+
+// restartStaticPods stops the running containers of the given static pods, and waits for kubelet to start them again.
+// Components which are not running (ex: the cluster is being restarted) are skipped, as they will load the new certs on start.
+func restartStaticPods(cmd command.Runner, cc config.ClusterConfig, components []string) error {
+	cr, err := cruntime.New(cruntime.Config{Type: cc.KubernetesConfig.ContainerRuntime, Runner: cmd, Socket: cc.KubernetesConfig.CRISocket})
+	if err != nil {
+		return errors.Wrap(err, "runtime")
+	}
+	for _, component := range components {
+		opts := cruntime.ListContainersOptions{State: cruntime.Running, Name: component, Namespaces: []string{"kube-system"}}
+		old, err := cr.ListContainers(opts)
+		if err != nil {
+			return errors.Wrapf(err, "list %s containers", component)
+		}
+		if len(old) == 0 {
+			klog.Infof("%s is not running, it will load the renewed certs on start", component)
+			continue
+		}
+		out.Step(style.Restarting, "Restarting {{.component}} to load the renewed certificates ...", out.V{"component": component})
+		if err := cr.StopContainers(old); err != nil {
+			return errors.Wrapf(err, "stop %s", component)
+		}
+		restarted := func() error {
+			ids, err := cr.ListContainers(opts)
+			if err != nil {
+				return err
+			}
+			stale := map[string]bool{}
+			for _, id := range old {
+				stale[id] = true
+			}
+			for _, id := range ids {
+				if !stale[id] {
+					return nil
+				}
+			}
+			return fmt.Errorf("%s has not been restarted yet", component)
+		}
+		if err := retry.Expo(restarted, 500*time.Millisecond, 2*time.Minute); err != nil {
+			return errors.Wrapf(err, "wait for %s", component)
+		}
 	}
 	return nil
 }
 
+// kubeadmCertEndDate returns the NotAfter date of a certificate inside the node, as printed by openssl
+func kubeadmCertEndDate(cmd command.Runner, certPath string) string {
+	rr, err := cmd.RunCmd(exec.Command("sudo", "/bin/bash", "-c", certPEMCmd(certPath)+" | openssl x509 -noout -enddate"))
+	if err != nil {
+		klog.Infof("unable to get the end date of %s: %v", certPath, err)
+		return ""
+	}
+	return strings.TrimSpace(rr.Stdout.String())
+}
+
+// verifyServedCert checks that the certificate served at addr inside the node is the one at certPath, which means the renewed cert was loaded
+func verifyServedCert(cmd command.Runner, addr string, certPath string) error {
+	rr, err := cmd.RunCmd(exec.Command("/bin/bash", "-c", fmt.Sprintf("openssl s_client -connect %s </dev/null 2>/dev/null | openssl x509 -noout -enddate", addr)))
+	if err != nil {
+		klog.Infof("unable to get the cert served at %s, skipping verification: %v", addr, err)
+		return nil
+	}
+	served := strings.TrimSpace(rr.Stdout.String())
+	if want := kubeadmCertEndDate(cmd, certPath); want != "" && served != want {
+		return fmt.Errorf("%s still serves a certificate with %s, want %s", addr, served, want)
+	}
+	klog.Infof("%s serves the renewed certificate, %s", addr, served)
+	return nil
+}
+
+// certPEMCmd returns a shell command printing the PEM certificate at certPath, extracting it from kubeconfig files
+func certPEMCmd(certPath string) string {
+	if strings.HasSuffix(certPath, ".conf") {
+		return fmt.Sprintf("grep client-certificate-data %s | awk '{print $2}' | base64 -d", certPath)
+	}
+	return "cat " + certPath
+}
+
 // isValidPEMCertificate checks whether the input file is a valid PEM certificate (with at least one CERTIFICATE block)
 func isValidPEMCertificate(filePath string) (bool, error) {
 	fileBytes, err := os.ReadFile(filePath)
@@ -490,7 +817,7 @@ func getSubjectHash(cr command.Runner, filePath string) (string, error) {
 
 // installCertSymlinks installs certs in /usr/share/ca-certificates into system-wide certificate store (/etc/ssl/certs).
 // OpenSSL binary required in minikube ISO
-func installCertSymlinks(cr command.Runner, caCerts map[string]string) error {
+func installCertSymlinks(cr command.Runner, profile string, caCerts map[string]string) error {
 	hasSSLBinary := true
 	_, err := cr.RunCmd(exec.Command("openssl", "version"))
 	if err != nil {
@@ -509,6 +836,7 @@ func installCertSymlinks(cr command.Runner, caCerts map[string]string) error {
 		if _, err := cr.RunCmd(exec.Command("sudo", "/bin/bash", "-c", cmd)); err != nil {
 			return errors.Wrapf(err, "create symlink for %s", caCertFile)
 		}
+		recordCertEvent(profile, CertOpSymlink, certStorePath, "")
 
 		if !hasSSLBinary {
 			continue
@@ -541,49 +869,69 @@ func canRead(path string) bool {
 }
 
 // isValid checks a cert/key path and makes sure it's still valid
-// if a cert is expired or otherwise invalid, it will be deleted
-func isValid(certPath, keyPath string) bool {
+// if a cert is expired or otherwise invalid, it will be deleted and the reason is returned
+func isValid(profile, certPath, keyPath string) (bool, string) {
 	if !canRead(keyPath) {
-		return false
+		return false, CertReasonMissing
 	}
 
 	certFile, err := os.ReadFile(certPath)
 	if err != nil {
 		klog.Infof("failed to read cert file %s: %v", certPath, err)
-		os.Remove(certPath)
-		os.Remove(keyPath)
-		return false
+		removeCert(profile, certPath, keyPath, CertReasonMissing)
+		return false, CertReasonMissing
 	}
 
-	certData, _ := pem.Decode(certFile)
-	if certData == nil {
-		klog.Infof("failed to decode cert file %s", certPath)
-		os.Remove(certPath)
-		os.Remove(keyPath)
-		return false
-	}
-
-	cert, err := x509.ParseCertificate(certData.Bytes)
+	cert, err := util.ParseCertificate(certFile)
 	if err != nil {
 		klog.Infof("failed to parse cert file %s: %v\n", certPath, err)
-		os.Remove(certPath)
-		os.Remove(keyPath)
-		return false
+		removeCert(profile, certPath, keyPath, CertReasonInvalid)
+		return false, CertReasonInvalid
 	}
 
 	if cert.NotAfter.Before(time.Now()) {
 		out.WarningT("Certificate {{.certPath}} has expired. Generating a new one...", out.V{"certPath": filepath.Base(certPath)})
 		klog.Infof("cert expired %s: expiration: %s, now: %s", certPath, cert.NotAfter, time.Now())
-		os.Remove(certPath)
-		os.Remove(keyPath)
-		return false
+		removeCert(profile, certPath, keyPath, CertReasonExpired)
+		return false, CertReasonExpired
 	}
 
+	return true, ""
+}
+
+// hasURIs returns whether the cert at certPath carries exactly the URI SANs uris
+func hasURIs(certPath string, uris []*url.URL) bool {
+	certFile, err := os.ReadFile(certPath)
+	if err != nil {
+		return false
+	}
+	cert, err := util.ParseCertificate(certFile)
+	if err != nil || len(cert.URIs) != len(uris) {
+		return false
+	}
+	for i, u := range uris {
+		if cert.URIs[i].String() != u.String() {
+			return false
+		}
+	}
 	return true
 }
 
+// removeCert deletes a cert/key pair and records the deletion in the cert history
+func removeCert(profile, certPath, keyPath, reason string) {
+	os.Remove(certPath)
+	os.Remove(keyPath)
+	recordCertEvent(profile, CertOpDelete, certPath, reason)
+}
+
 func isKubeadmCertValid(cmd command.Runner, certPath string) bool {
-	_, err := cmd.RunCmd(exec.Command("openssl", "x509", "-noout", "-in", certPath, "-checkend", "86400"))
+	var c *exec.Cmd
+	if strings.HasSuffix(certPath, ".conf") {
+		c = exec.Command("sudo", "/bin/bash", "-c", certPEMCmd(certPath)+" | openssl x509 -noout -checkend 86400")
+	} else {
+		c = exec.Command("openssl", "x509", "-noout", "-in", certPath, "-checkend", "86400")
+	}
+	_, err := cmd.RunCmd(c)
 	if err != nil {
 		klog.Infof("%v", err)
 	}
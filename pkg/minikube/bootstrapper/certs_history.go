@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapper
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+// Cert operations recorded in the cert history log
+const (
+	CertOpGenerate = "generate"
+	CertOpRenew    = "renew"
+	CertOpDelete   = "delete"
+	CertOpSymlink  = "symlink"
+)
+
+// Reasons for a cert operation
+const (
+	CertReasonMissing     = "missing"
+	CertReasonInvalid     = "invalid"
+	CertReasonExpired     = "expired"
+	CertReasonHashChanged = "hash changed"
+	CertReasonCAChanged   = "ca changed"
+	CertReasonURIsChanged = "uris changed"
+)
+
+// CertEvent is a single entry of the cert history log
+type CertEvent struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Path      string    `json:"path"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+// recordCertEvent appends a cert operation to the cert history log of a profile.
+// Failures are only logged, as the history must never prevent certs from being set up.
+func recordCertEvent(profile, op, path, reason string) {
+	if profile == "" {
+		return
+	}
+	e := CertEvent{Time: time.Now().UTC(), Operation: op, Path: path, Reason: reason}
+	if err := appendCertEvent(localpath.CertHistoryLog(profile), e); err != nil {
+		klog.Warningf("unable to record cert %s of %s: %v", op, path, err)
+	}
+}
+
+func appendCertEvent(logPath string, e CertEvent) error {
+	bs, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshalling cert event: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return fmt.Errorf("creating cert history dir: %v", err)
+	}
+	f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening cert history: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(bs, '\n')); err != nil {
+		return fmt.Errorf("writing cert history: %v", err)
+	}
+	return nil
+}
+
+// CertHistory returns the cert operations recorded for a profile, oldest first
+func CertHistory(profile string) ([]CertEvent, error) {
+	return readCertEvents(localpath.CertHistoryLog(profile))
+}
+
+func readCertEvents(logPath string) ([]CertEvent, error) {
+	f, err := os.Open(logPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []CertEvent{}, nil
+		}
+		return nil, fmt.Errorf("opening cert history: %v", err)
+	}
+	defer f.Close()
+
+	events := []CertEvent{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var e CertEvent
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			klog.Warningf("skipping malformed cert history entry %q: %v", s.Text(), err)
+			continue
+		}
+		events = append(events, e)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("reading cert history: %v", err)
+	}
+	return events, nil
+}
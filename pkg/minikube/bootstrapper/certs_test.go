@@ -19,12 +19,15 @@ package bootstrapper
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"k8s.io/minikube/pkg/minikube/command"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/tests"
 	"k8s.io/minikube/pkg/util"
 )
@@ -65,3 +68,214 @@ func TestSetupCerts(t *testing.T) {
 		t.Fatalf("Error starting cluster: %v", err)
 	}
 }
+
+func TestSpiffeURIs(t *testing.T) {
+	apiServerURIs, clientURIs, err := spiffeURIs("")
+	if err != nil {
+		t.Fatalf("spiffeURIs() error = %v", err)
+	}
+	if len(apiServerURIs) != 0 || len(clientURIs) != 0 {
+		t.Errorf("expected no SPIFFE IDs without a trust domain, got %v and %v", apiServerURIs, clientURIs)
+	}
+
+	apiServerURIs, clientURIs, err = spiffeURIs("cluster.local")
+	if err != nil {
+		t.Fatalf("spiffeURIs() error = %v", err)
+	}
+	if len(apiServerURIs) != 1 || apiServerURIs[0].String() != "spiffe://cluster.local/ns/kube-system/sa/kube-apiserver" {
+		t.Errorf("unexpected apiserver SPIFFE IDs: %v", apiServerURIs)
+	}
+	if len(clientURIs) != 1 || clientURIs[0].String() != "spiffe://cluster.local/user/minikube-user" {
+		t.Errorf("unexpected client SPIFFE IDs: %v", clientURIs)
+	}
+
+	if _, _, err := spiffeURIs("cluster.local/bad"); err == nil {
+		t.Errorf("spiffeURIs() should have returned error, but didn't")
+	}
+}
+
+func TestHasURIs(t *testing.T) {
+	dir := t.TempDir()
+	caCert, caKey := filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key")
+	if err := util.GenerateCACert(caCert, caKey, "minikubeCA"); err != nil {
+		t.Fatalf("GenerateCACert: %v", err)
+	}
+	_, clientURIs, err := spiffeURIs("cluster.local")
+	if err != nil {
+		t.Fatalf("spiffeURIs() error = %v", err)
+	}
+	cert, key := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	if err := util.GenerateSignedCert(cert, key, "minikube-user", nil, nil, clientURIs, caCert, caKey, time.Hour); err != nil {
+		t.Fatalf("GenerateSignedCert: %v", err)
+	}
+	if !hasURIs(cert, clientURIs) {
+		t.Errorf("hasURIs() = false, want the SPIFFE ID of the cert")
+	}
+	if hasURIs(cert, nil) {
+		t.Errorf("hasURIs() = true without a trust domain, want the cert to be regenerated")
+	}
+}
+
+func TestCertHistory(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "cert-history.json")
+
+	events, err := readCertEvents(logPath)
+	if err != nil {
+		t.Fatalf("readCertEvents() error = %v", err)
+	}
+	if len(events) != 0 {
+		t.Fatalf("expected no events for a missing log, got %v", events)
+	}
+
+	want := []CertEvent{
+		{Time: time.Now().UTC(), Operation: CertOpDelete, Path: "apiserver.crt.1234abcd", Reason: CertReasonExpired},
+		{Time: time.Now().UTC(), Operation: CertOpGenerate, Path: "apiserver.crt.1234abcd", Reason: CertReasonExpired},
+	}
+	for _, e := range want {
+		if err := appendCertEvent(logPath, e); err != nil {
+			t.Fatalf("appendCertEvent() error = %v", err)
+		}
+	}
+
+	events, err = readCertEvents(logPath)
+	if err != nil {
+		t.Fatalf("readCertEvents() error = %v", err)
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d", len(want), len(events))
+	}
+	for i := range want {
+		if events[i].Operation != want[i].Operation || events[i].Path != want[i].Path || events[i].Reason != want[i].Reason || !events[i].Time.Equal(want[i].Time) {
+			t.Errorf("event %d: expected %+v, got %+v", i, want[i], events[i])
+		}
+	}
+}
+
+func TestAffectedComponents(t *testing.T) {
+	tests := []struct {
+		certs []string
+		want  []string
+	}{
+		{nil, []string{}},
+		{[]string{"etcd-server", "etcd-peer"}, []string{"etcd"}},
+		{[]string{"front-proxy-client", "apiserver-etcd-client", "etcd-healthcheck-client"}, []string{"etcd", "kube-apiserver"}},
+		{[]string{"scheduler.conf", "controller-manager.conf"}, []string{"kube-controller-manager", "kube-scheduler"}},
+	}
+	for _, tc := range tests {
+		got := affectedComponents(tc.certs)
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("affectedComponents(%v) = %v; want %v", tc.certs, got, tc.want)
+		}
+	}
+}
+
+func TestServedCerts(t *testing.T) {
+	tests := []struct {
+		description string
+		port        int
+		expired     []string
+		want        []servedCert
+	}{
+		{"none", 8443, nil, []servedCert{}},
+		{"client certs of etcd", 8443, []string{"etcd-peer", "etcd-healthcheck-client"}, []servedCert{}},
+		{"serving cert of etcd", 8443, []string{"etcd-server"}, []servedCert{{"127.0.0.1:2379", "/var/lib/minikube/certs/etcd/server.crt"}}},
+		{"client cert of the apiserver", 9443, []string{"apiserver-etcd-client"}, []servedCert{{"127.0.0.1:9443", "/var/lib/minikube/certs/apiserver.crt"}}},
+		{"default apiserver port", 0, []string{"front-proxy-client", "etcd-server"}, []servedCert{
+			{"127.0.0.1:8443", "/var/lib/minikube/certs/apiserver.crt"},
+			{"127.0.0.1:2379", "/var/lib/minikube/certs/etcd/server.crt"},
+		}},
+		{"kubeconfigs of the controllers", 8443, []string{"scheduler.conf", "controller-manager.conf"}, []servedCert{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			got := servedCerts(config.ClusterConfig{APIServerPort: tc.port}, tc.expired)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("servedCerts(%v) = %v; want %v", tc.expired, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestKubeadmCertPath(t *testing.T) {
+	tests := map[string]string{
+		"apiserver-kubelet-client": "/var/lib/minikube/certs/apiserver-kubelet-client.crt",
+		"etcd-server":              "/var/lib/minikube/certs/etcd/server.crt",
+		"scheduler.conf":           "/etc/kubernetes/scheduler.conf",
+	}
+	for cert, want := range tests {
+		if got := kubeadmCertPath(cert); got != want {
+			t.Errorf("kubeadmCertPath(%q) = %q; want %q", cert, got, want)
+		}
+	}
+}
+
+func TestHAAPIServerIPs(t *testing.T) {
+	primary := config.Node{Name: "", IP: "192.168.49.2", ControlPlane: true}
+	secondary := config.Node{Name: "m02", IP: "192.168.49.3", ControlPlane: true}
+	provisioning := config.Node{Name: "m03", ControlPlane: true}
+	worker := config.Node{Name: "m04", IP: "192.168.49.5"}
+	cc := config.ClusterConfig{
+		KubernetesConfig: config.KubernetesConfig{APIServerHAVIP: "192.168.49.254"},
+		Nodes:            []config.Node{primary, secondary, provisioning, worker},
+	}
+
+	tests := []struct {
+		node config.Node
+		want []string
+	}{
+		{primary, []string{"192.168.49.254", "192.168.49.3"}},
+		{secondary, []string{"192.168.49.254", "192.168.49.2"}},
+	}
+	for _, tc := range tests {
+		var got []string
+		for _, ip := range haAPIServerIPs(cc, tc.node) {
+			got = append(got, ip.String())
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("haAPIServerIPs(%q) = %v; want %v", tc.node.Name, got, tc.want)
+		}
+	}
+}
+
+func TestGenerateRegistryCert(t *testing.T) {
+	tests.MakeTempDir(t)
+	cc := config.ClusterConfig{
+		Name:           "registry",
+		CertExpiration: constants.DefaultCertExpiration,
+		Nodes:          []config.Node{{IP: "192.168.49.2"}},
+		KubernetesConfig: config.KubernetesConfig{
+			DNSDomain: constants.ClusterDNSDomain,
+		},
+	}
+	if err := GenerateRegistryCert(cc, []string{"127.0.0.1", "docker.example"}); err != nil {
+		t.Fatalf("GenerateRegistryCert() error = %v", err)
+	}
+	b, err := os.ReadFile(localpath.RegistryCert(cc.Name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := util.ParseCertificate(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range []string{"localhost", "127.0.0.1", "192.168.49.2", "docker.example", "registry.kube-system.svc.cluster.local"} {
+		if err := c.VerifyHostname(h); err != nil {
+			t.Errorf("the registry cert is not valid for %s: %v", h, err)
+		}
+	}
+
+	// the cert is kept while it covers the names, and generated again for new ones
+	if err := GenerateRegistryCert(cc, []string{"127.0.0.1"}); err != nil {
+		t.Fatalf("GenerateRegistryCert() error = %v", err)
+	}
+	if kept, _ := os.ReadFile(localpath.RegistryCert(cc.Name)); string(kept) != string(b) {
+		t.Errorf("GenerateRegistryCert() replaced a cert covering all the names")
+	}
+	cc.Nodes[0].IP = "192.168.49.3"
+	if err := GenerateRegistryCert(cc, nil); err != nil {
+		t.Fatalf("GenerateRegistryCert() error = %v", err)
+	}
+	if regen, _ := os.ReadFile(localpath.RegistryCert(cc.Name)); string(regen) == string(b) {
+		t.Errorf("GenerateRegistryCert() kept a cert not covering the new IP of the node")
+	}
+}
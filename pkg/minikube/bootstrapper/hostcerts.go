@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapper
+
+import (
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+// hostCACertPrefix is the file name prefix of the CA certs imported from the host trust store into ~/.minikube/certs
+const hostCACertPrefix = "host-ca-"
+
+// importHostCACerts copies the CA certs added to the host trust store, such as the CA of a TLS intercepting proxy,
+// into ~/.minikube/certs so that collectCACerts installs them in the guest. Returns the paths of the newly imported certs.
+func importHostCACerts() ([]string, error) {
+	certs, err := hostTrustStoreCerts()
+	if err != nil {
+		return nil, errors.Wrap(err, "reading host trust store")
+	}
+	return writeHostCACerts(filepath.Join(localpath.MiniPath(), "certs"), certs, time.Now())
+}
+
+// writeHostCACerts writes the valid CA certs among the given DER certs into dir, skipping the ones already imported
+func writeHostCACerts(dir string, certs [][]byte, now time.Time) ([]string, error) {
+	imported := []string{}
+	for _, der := range certs {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			klog.Infof("skipping unparsable host cert: %v", err)
+			continue
+		}
+		if !cert.BasicConstraintsValid || !cert.IsCA {
+			klog.Infof("skipping host cert %q: not a CA", cert.Subject)
+			continue
+		}
+		if now.After(cert.NotAfter) {
+			klog.Infof("skipping host cert %q: expired on %s", cert.Subject, cert.NotAfter)
+			continue
+		}
+
+		p := filepath.Join(dir, fmt.Sprintf("%s%x.pem", hostCACertPrefix, sha1.Sum(der)))
+		if _, err := os.Stat(p); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return imported, errors.Wrapf(err, "create %s", dir)
+		}
+		if err := os.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+			return imported, errors.Wrapf(err, "write %s", p)
+		}
+		klog.Infof("imported host CA %q to %s", cert.Subject, p)
+		imported = append(imported, p)
+	}
+	return imported, nil
+}
+
+// pemCerts returns the DER bytes of the CERTIFICATE blocks in PEM data
+func pemCerts(data []byte) [][]byte {
+	certs := [][]byte{}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return certs
+		}
+		if block.Type == "CERTIFICATE" {
+			certs = append(certs, block.Bytes)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapper
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// trustListKey matches the SHA-1 hashes keying the certs in the trustList of exported trust settings
+var trustListKey = regexp.MustCompile(`<key>([0-9A-F]{40})</key>`)
+
+// hostTrustStoreCerts returns the certs of the System keychain, where MDM and admins install enterprise CAs,
+// and the certs of the login keychain the user explicitly trusts. The built-in roots live in SystemRootCertificates.keychain and are skipped.
+func hostTrustStoreCerts() ([][]byte, error) {
+	certs := keychainCerts("/Library/Keychains/System.keychain")
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return certs, nil
+	}
+	trusted := userTrustedCerts()
+	for _, der := range keychainCerts(filepath.Join(home, "Library", "Keychains", "login.keychain-db")) {
+		if !trusted[fmt.Sprintf("%X", sha1.Sum(der))] {
+			klog.Infof("skipping login keychain cert %X: the user did not trust it", sha1.Sum(der))
+			continue
+		}
+		certs = append(certs, der)
+	}
+	return certs, nil
+}
+
+// keychainCerts returns the certs of the keychain kc
+func keychainCerts(kc string) [][]byte {
+	if _, err := os.Stat(kc); err != nil {
+		return nil
+	}
+	data, err := exec.Command("security", "find-certificate", "-a", "-p", kc).Output()
+	if err != nil {
+		klog.Infof("unable to read keychain %s: %v", kc, err)
+		return nil
+	}
+	return pemCerts(data)
+}
+
+// userTrustedCerts returns the SHA-1 hashes of the certs the user set trust settings for
+func userTrustedCerts() map[string]bool {
+	f, err := os.CreateTemp("", "minikube-trust-settings-*.plist")
+	if err != nil {
+		klog.Infof("unable to export the trust settings: %v", err)
+		return nil
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+	if out, err := exec.Command("security", "trust-settings-export", f.Name()).CombinedOutput(); err != nil {
+		klog.Infof("unable to export the trust settings: %v: %s", err, out)
+		return nil
+	}
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		klog.Infof("unable to read the trust settings: %v", err)
+		return nil
+	}
+	return trustedHashes(string(data))
+}
+
+// trustedHashes returns the SHA-1 hashes of the certs of the exported trust settings plist, but the ones set to deny
+func trustedHashes(plist string) map[string]bool {
+	hashes := map[string]bool{}
+	keys := trustListKey.FindAllStringSubmatchIndex(plist, -1)
+	for i, k := range keys {
+		end := len(plist)
+		if i+1 < len(keys) {
+			end = keys[i+1][0]
+		}
+		// kSecTrustSettingsResultDeny
+		settings := strings.Join(strings.Fields(plist[k[1]:end]), "")
+		if strings.Contains(settings, "<key>kSecTrustSettingsResult</key><integer>3</integer>") {
+			continue
+		}
+		hashes[plist[k[2]:k[3]]] = true
+	}
+	return hashes
+}
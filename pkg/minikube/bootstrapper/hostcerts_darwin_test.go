@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrustedHashes(t *testing.T) {
+	plist := `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>trustList</key>
+	<dict>
+		<key>0123456789ABCDEF0123456789ABCDEF01234567</key>
+		<dict>
+			<key>issuerName</key>
+			<data>MA==</data>
+			<key>trustSettings</key>
+			<array/>
+		</dict>
+		<key>89ABCDEF0123456789ABCDEF0123456789ABCDEF</key>
+		<dict>
+			<key>trustSettings</key>
+			<array>
+				<dict>
+					<key>kSecTrustSettingsResult</key>
+					<integer>3</integer>
+				</dict>
+			</array>
+		</dict>
+	</dict>
+	<key>trustVersion</key>
+	<integer>1</integer>
+</dict>
+</plist>`
+	want := map[string]bool{"0123456789ABCDEF0123456789ABCDEF01234567": true}
+	if got := trustedHashes(plist); !reflect.DeepEqual(got, want) {
+		t.Errorf("trustedHashes() = %v, want %v", got, want)
+	}
+}
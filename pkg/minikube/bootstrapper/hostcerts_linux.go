@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapper
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+)
+
+// hostAnchorDirs are the directories where locally added CAs are installed, for Debian, Fedora and Arch based distributions
+var hostAnchorDirs = []string{
+	"/usr/local/share/ca-certificates",
+	"/etc/pki/ca-trust/source/anchors",
+	"/etc/ca-certificates/trust-source/anchors",
+}
+
+// hostTrustStoreCerts returns the CAs added to the system anchors dirs and to the NSS database of the user
+func hostTrustStoreCerts() ([][]byte, error) {
+	certs := [][]byte{}
+	for _, dir := range hostAnchorDirs {
+		err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			data, err := os.ReadFile(p)
+			if err != nil {
+				klog.Infof("unable to read %s: %v", p, err)
+				return nil
+			}
+			if found := pemCerts(data); len(found) > 0 {
+				certs = append(certs, found...)
+			} else {
+				// anchors may also be DER encoded
+				certs = append(certs, data)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(certs, nssCerts()...), nil
+}
+
+// nssCerts returns the CAs trusted for TLS in the NSS database of the user, used by Chrome and Firefox
+func nssCerts() [][]byte {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	db := filepath.Join(home, ".pki", "nssdb")
+	if _, err := os.Stat(db); err != nil {
+		return nil
+	}
+	if _, err := exec.LookPath("certutil"); err != nil {
+		klog.Infof("certutil not found, skipping NSS database %s", db)
+		return nil
+	}
+	list, err := exec.Command("certutil", "-L", "-d", "sql:"+db).Output()
+	if err != nil {
+		klog.Infof("unable to list NSS database %s: %v", db, err)
+		return nil
+	}
+	certs := [][]byte{}
+	for _, nick := range parseNSSCATrust(string(list)) {
+		data, err := exec.Command("certutil", "-L", "-d", "sql:"+db, "-n", nick, "-a").Output()
+		if err != nil {
+			klog.Infof("unable to export %q from NSS database: %v", nick, err)
+			continue
+		}
+		certs = append(certs, pemCerts(data)...)
+	}
+	return certs
+}
+
+// parseNSSCATrust returns the nicknames of the certs trusted as TLS CAs in the output of `certutil -L`,
+// whose lines end with the SSL,S/MIME,JAR/XPI trust attributes, ex: "Corp Proxy CA    CT,C,C"
+func parseNSSCATrust(list string) []string {
+	nicks := []string{}
+	for _, line := range strings.Split(list, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		trust := fields[len(fields)-1]
+		attrs := strings.Split(trust, ",")
+		if len(attrs) != 3 || !strings.Contains(attrs[0], "C") {
+			continue
+		}
+		nicks = append(nicks, strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), trust)))
+	}
+	return nicks
+}
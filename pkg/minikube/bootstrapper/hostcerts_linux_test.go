@@ -0,0 +1,37 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseNSSCATrust(t *testing.T) {
+	list := `
+Certificate Nickname                                         Trust Attributes
+                                                             SSL,S/MIME,JAR/XPI
+
+Corp Proxy CA                                                CT,C,C
+my client cert                                               u,u,u
+S/MIME only CA                                               ,C,
+`
+	got := parseNSSCATrust(list)
+	if strings.Join(got, "|") != "Corp Proxy CA" {
+		t.Errorf("parseNSSCATrust() = %q; want [Corp Proxy CA]", got)
+	}
+}
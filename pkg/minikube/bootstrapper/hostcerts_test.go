@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapper
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func testCert(t *testing.T, cn string, isCA bool, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             notAfter.Add(-24 * time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create cert: %v", err)
+	}
+	return der
+}
+
+func TestWriteHostCACerts(t *testing.T) {
+	now := time.Now()
+	proxyCA := testCert(t, "Corp Proxy CA", true, now.Add(time.Hour))
+	certs := [][]byte{
+		proxyCA,
+		testCert(t, "leaf", false, now.Add(time.Hour)),
+		testCert(t, "Expired CA", true, now.Add(-time.Hour)),
+		[]byte("garbage"),
+	}
+	dir := t.TempDir()
+
+	imported, err := writeHostCACerts(dir, certs, now)
+	if err != nil {
+		t.Fatalf("writeHostCACerts() error = %v", err)
+	}
+	if len(imported) != 1 {
+		t.Fatalf("expected only the valid CA to be imported, got %v", imported)
+	}
+
+	// importing again must not duplicate the cert
+	imported, err = writeHostCACerts(dir, certs, now)
+	if err != nil {
+		t.Fatalf("writeHostCACerts() error = %v", err)
+	}
+	if len(imported) != 0 {
+		t.Errorf("expected no cert to be imported twice, got %v", imported)
+	}
+}
+
+func TestPEMCerts(t *testing.T) {
+	der := testCert(t, "Corp Proxy CA", true, time.Now().Add(time.Hour))
+	data := append(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("key")}), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})...)
+	certs := pemCerts(data)
+	if len(certs) != 1 || string(certs[0]) != string(der) {
+		t.Errorf("expected only the certificate block, got %d blocks", len(certs))
+	}
+}
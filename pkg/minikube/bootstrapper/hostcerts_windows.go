@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrapper
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/sys/windows/registry"
+	"k8s.io/klog/v2"
+)
+
+// certCertPropID is the CERT_CERT_PROP_ID property holding the encoded certificate in a serialized store element
+const certCertPropID = 32
+
+// hostRootStores are the SChannel root stores holding CAs added by group policy, the enterprise or the user.
+// The LocalMachine\Root store is skipped as it mostly holds the auto-updated public roots.
+var hostRootStores = []struct {
+	root registry.Key
+	path string
+}{
+	{registry.LOCAL_MACHINE, `SOFTWARE\Policies\Microsoft\SystemCertificates\Root\Certificates`},
+	{registry.LOCAL_MACHINE, `SOFTWARE\Microsoft\EnterpriseCertificates\Root\Certificates`},
+	{registry.CURRENT_USER, `SOFTWARE\Microsoft\SystemCertificates\Root\Certificates`},
+}
+
+// hostTrustStoreCerts returns the certs of the group policy, enterprise and user root stores
+func hostTrustStoreCerts() ([][]byte, error) {
+	certs := [][]byte{}
+	for _, store := range hostRootStores {
+		k, err := registry.OpenKey(store.root, store.path, registry.ENUMERATE_SUB_KEYS)
+		if err != nil {
+			continue
+		}
+		thumbprints, err := k.ReadSubKeyNames(-1)
+		k.Close()
+		if err != nil {
+			klog.Infof("unable to list %s: %v", store.path, err)
+			continue
+		}
+		for _, tp := range thumbprints {
+			ck, err := registry.OpenKey(store.root, store.path+`\`+tp, registry.QUERY_VALUE)
+			if err != nil {
+				continue
+			}
+			blob, _, err := ck.GetBinaryValue("Blob")
+			ck.Close()
+			if err != nil {
+				klog.Infof("unable to read cert %s: %v", tp, err)
+				continue
+			}
+			der, err := parseCertBlob(blob)
+			if err != nil {
+				klog.Infof("unable to parse cert %s: %v", tp, err)
+				continue
+			}
+			certs = append(certs, der)
+		}
+	}
+	return certs, nil
+}
+
+// parseCertBlob extracts the DER certificate from a serialized store element, which is a list of
+// (property id, reserved, length, data) entries with little-endian uint32 headers
+func parseCertBlob(blob []byte) ([]byte, error) {
+	for len(blob) >= 12 {
+		id := binary.LittleEndian.Uint32(blob[0:4])
+		n := binary.LittleEndian.Uint32(blob[8:12])
+		blob = blob[12:]
+		if uint32(len(blob)) < n {
+			return nil, fmt.Errorf("truncated property %d", id)
+		}
+		if id == certCertPropID {
+			return blob[:n], nil
+		}
+		blob = blob[n:]
+	}
+	return nil, fmt.Errorf("no certificate property")
+}
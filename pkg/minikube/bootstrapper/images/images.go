@@ -61,7 +61,13 @@ func essentials(mirror string, v semver.Version) []string {
 
 // componentImage returns a Kubernetes component image to pull
 func componentImage(name string, v semver.Version, mirror string) string {
-	return fmt.Sprintf("%s:v%s", path.Join(kubernetesRepo(mirror), name), v)
+	// like kubeadm, replace the "+" of the build metadata of local builds, which is not allowed in tags
+	return fmt.Sprintf("%s:v%s", path.Join(kubernetesRepo(mirror), name), strings.ReplaceAll(v.String(), "+", "_"))
+}
+
+// Component returns the image of a core Kubernetes component, as kubeadm expects it
+func Component(name string, v semver.Version, mirror string) string {
+	return componentImage(name, v, mirror)
 }
 
 // tagFromKubeadm gets the image tag by running kubeadm image list command on the host machine (Linux only)
@@ -182,6 +188,24 @@ func KindNet(repo string) string {
 	return path.Join(repo, "kindnetd:v20230809-80a64d96")
 }
 
+// KubeVip returns the image used for the virtual IP of multi-control-plane clusters
+// ref: https://github.com/kube-vip/kube-vip/pkgs/container/kube-vip
+func KubeVip(repo string) string {
+	if repo == "" {
+		repo = "ghcr.io/kube-vip"
+	}
+	return path.Join(repo, "kube-vip:v0.7.1")
+}
+
+// KubeVipCloudProvider returns the image allocating the IPs of LoadBalancer services from the pool of the cluster
+// ref: https://github.com/kube-vip/kube-vip-cloud-provider/pkgs/container/kube-vip-cloud-provider
+func KubeVipCloudProvider(repo string) string {
+	if repo == "" {
+		repo = "ghcr.io/kube-vip"
+	}
+	return path.Join(repo, "kube-vip-cloud-provider:v0.0.9")
+}
+
 // all calico images are from https://github.com/projectcalico/calico/blob/master/manifests/calico.yaml
 const calicoVersion = "v3.27.0"
 const calicoRepo = "docker.io/calico"
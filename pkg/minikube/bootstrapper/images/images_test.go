@@ -131,6 +131,23 @@ registry.cn-hangzhou.aliyuncs.com/google_containers/coredns:v1.8.4
 	}
 }
 
+func TestComponent(t *testing.T) {
+	var testCases = []struct {
+		version string
+		mirror  string
+		want    string
+	}{
+		{"1.30.0", "", "registry.k8s.io/kube-apiserver:v1.30.0"},
+		{"1.31.0-alpha.0.123+0123456789ab", "", "registry.k8s.io/kube-apiserver:v1.31.0-alpha.0.123_0123456789ab"},
+		{"1.30.0", "localhost:5000", "localhost:5000/kube-apiserver:v1.30.0"},
+	}
+	for _, tc := range testCases {
+		if got := Component("kube-apiserver", semver.MustParse(tc.version), tc.mirror); got != tc.want {
+			t.Errorf("Component(%q, %q) = %q, want %q", tc.version, tc.mirror, got, tc.want)
+		}
+	}
+}
+
 func TestAuxiliary(t *testing.T) {
 	want := []string{
 		"gcr.io/k8s-minikube/storage-provisioner:" + version.GetStorageProvisionerVersion(),
@@ -181,6 +181,9 @@ func (k *Bootstrapper) init(cfg config.ClusterConfig) error {
 	}
 
 	extraFlags := bsutil.CreateFlagsFromExtraArgs(cfg.KubernetesConfig.ExtraOptions)
+	if patches := bsutil.KubeadmPatchesFlag(cfg); patches != "" {
+		extraFlags = fmt.Sprintf("%s %s", extraFlags, patches)
+	}
 	r, err := cruntime.New(cruntime.Config{Type: cfg.KubernetesConfig.ContainerRuntime, Runner: k.c})
 	if err != nil {
 		return err
@@ -208,6 +211,10 @@ func (k *Bootstrapper) init(cfg config.ClusterConfig) error {
 		)
 	}
 	ignore = append(ignore, bsutil.SkipAdditionalPreflights[r.Name()]...)
+	// the images of a local build are in no registry, and its components may be rebuilt separately under different versions
+	if cfg.KubernetesConfig.KubernetesImagesDir != "" {
+		ignore = append(ignore, "ImagePull", "KubeletVersion")
+	}
 
 	skipSystemVerification := false
 	// Allow older kubeadm versions to function with newer Docker releases.
@@ -265,11 +272,16 @@ func (k *Bootstrapper) init(cfg config.ClusterConfig) error {
 	}
 	kw.Close()
 	wg.Wait()
+	k.recordKubeadmPatches()
 
 	if err := k.applyCNI(cfg, true); err != nil {
 		return errors.Wrap(err, "apply cni")
 	}
 
+	if err := k.applyLoadBalancerPool(cfg); err != nil {
+		out.WarningT("Unable to set up the load balancer pool, LoadBalancer services need minikube tunnel: {{.error}}", out.V{"error": err})
+	}
+
 	wg.Add(3)
 
 	go func() {
@@ -603,6 +615,12 @@ func (k *Bootstrapper) needsReconfigure(conf string, hostname string, port int,
 		return true
 	}
 
+	// the patches of --kubeadm-patches are only applied when the static pods are written
+	if rr, err := k.c.RunCmd(exec.Command("sudo", "/bin/bash", "-c", fmt.Sprintf("[ ! -e %[1]s ] && [ ! -e %[2]s ] || diff -ru %[2]s %[1]s", bsutil.KubeadmPatchesDir, bsutil.KubeadmAppliedPatchesDir))); err != nil {
+		klog.Infof("needs reconfigure: kubeadm patches differ:\n%s", rr.Output())
+		return true
+	}
+
 	// cruntime.Enable() may restart kube-apiserver but does not wait for it to return back
 	// could take five-ish seconds, so hopefully 10 seconds is sufficient to wait for api server to come back up
 	apiStatusTimeout := 10 * time.Second
@@ -705,9 +723,9 @@ func (k *Bootstrapper) restartControlPlane(cfg config.ClusterConfig) error {
 	cmds := []string{
 		fmt.Sprintf("%s phase certs all --config %s", baseCmd, conf),
 		fmt.Sprintf("%s phase kubeconfig all --config %s", baseCmd, conf),
-		fmt.Sprintf("%s phase kubelet-start --config %s", baseCmd, conf),
-		fmt.Sprintf("%s phase %s all --config %s", baseCmd, controlPlane, conf),
-		fmt.Sprintf("%s phase etcd local --config %s", baseCmd, conf),
+		fmt.Sprintf("%s phase kubelet-start --config %s %s", baseCmd, conf, bsutil.KubeletStartPatchesFlag(cfg, k8sVersion)),
+		fmt.Sprintf("%s phase %s all --config %s %s", baseCmd, controlPlane, conf, bsutil.KubeadmPatchesFlag(cfg)),
+		fmt.Sprintf("%s phase etcd local --config %s %s", baseCmd, conf, bsutil.KubeadmPatchesFlag(cfg)),
 	}
 
 	klog.Infof("reconfiguring cluster from %s", conf)
@@ -721,6 +739,7 @@ func (k *Bootstrapper) restartControlPlane(cfg config.ClusterConfig) error {
 			}
 		}
 	}
+	k.recordKubeadmPatches()
 
 	cr, err := cruntime.New(cruntime.Config{Type: cfg.KubernetesConfig.ContainerRuntime, Runner: k.c})
 	if err != nil {
@@ -741,6 +760,10 @@ func (k *Bootstrapper) restartControlPlane(cfg config.ClusterConfig) error {
 		return errors.Wrap(err, "apply cni")
 	}
 
+	if err := k.applyLoadBalancerPool(cfg); err != nil {
+		out.WarningT("Unable to set up the load balancer pool, LoadBalancer services need minikube tunnel: {{.error}}", out.V{"error": err})
+	}
+
 	if err := kverify.WaitForSystemPods(cr, k, cfg, k.c, client, time.Now(), kconst.DefaultControlPlaneTimeout); err != nil {
 		return errors.Wrap(err, "system pods")
 	}
@@ -799,10 +822,21 @@ func (k *Bootstrapper) restartControlPlane(cfg config.ClusterConfig) error {
 	return nil
 }
 
+// recordKubeadmPatches keeps a copy of the kubeadm patches the static pods were written with, see needsReconfigure
+func (k *Bootstrapper) recordKubeadmPatches() {
+	script := fmt.Sprintf("rm -rf %[2]s && if [ -e %[1]s ]; then cp -r %[1]s %[2]s; fi", bsutil.KubeadmPatchesDir, bsutil.KubeadmAppliedPatchesDir)
+	if _, err := k.c.RunCmd(exec.Command("sudo", "/bin/bash", "-c", script)); err != nil {
+		klog.Warningf("unable to record the applied kubeadm patches: %v", err)
+	}
+}
+
 // JoinCluster adds new node to an existing cluster.
 func (k *Bootstrapper) JoinCluster(cc config.ClusterConfig, n config.Node, joinCmd string) error {
 	// Join the master by specifying its token
 	joinCmd = fmt.Sprintf("%s --node-name=%s", joinCmd, config.MachineName(cc, n))
+	if len(n.KubeletConfig) > 0 || cc.KubernetesConfig.KubeadmPatches != "" {
+		joinCmd = fmt.Sprintf("%s --patches %s", joinCmd, bsutil.KubeadmPatchesDir)
+	}
 
 	if _, err := k.c.RunCmd(exec.Command("/bin/bash", "-c", joinCmd)); err != nil {
 		return errors.Wrapf(err, "kubeadm join")
@@ -815,8 +849,8 @@ func (k *Bootstrapper) JoinCluster(cc config.ClusterConfig, n config.Node, joinC
 	return nil
 }
 
-// GenerateToken creates a token and returns the appropriate kubeadm join command to run, or the already existing token
-func (k *Bootstrapper) GenerateToken(cc config.ClusterConfig) (string, error) {
+// GenerateToken creates a token and returns the appropriate kubeadm join command to run for the node, or the already existing token
+func (k *Bootstrapper) GenerateToken(cc config.ClusterConfig, n config.Node) (string, error) {
 	// Take that generated token and use it to get a kubeadm join command
 	tokenCmd := exec.Command("/bin/bash", "-c", fmt.Sprintf("%s token create --print-join-command --ttl=0", bsutil.InvokeKubeadm(cc.KubernetesConfig.KubernetesVersion)))
 	r, err := k.c.RunCmd(tokenCmd)
@@ -840,6 +874,18 @@ func (k *Bootstrapper) GenerateToken(cc config.ClusterConfig) (string, error) {
 	sp := cr.SocketPath()
 	joinCmd = fmt.Sprintf("%s --cri-socket %s", joinCmd, sp)
 
+	if n.ControlPlane {
+		// the joining control plane downloads the shared CAs and service account keys uploaded with this key
+		certCmd := exec.Command("/bin/bash", "-c", fmt.Sprintf("%s init phase upload-certs --upload-certs --config %s", bsutil.InvokeKubeadm(cc.KubernetesConfig.KubernetesVersion), constants.KubeadmYamlPath))
+		rr, err := k.c.RunCmd(certCmd)
+		if err != nil {
+			return "", errors.Wrap(err, "uploading control plane certs")
+		}
+		lines := strings.Split(strings.TrimSpace(rr.Stdout.String()), "\n")
+		certKey := strings.TrimSpace(lines[len(lines)-1])
+		joinCmd = fmt.Sprintf("%s --control-plane --apiserver-advertise-address=%s --apiserver-bind-port=%d --certificate-key %s", joinCmd, config.NodeIPs(cc, n)[0], n.Port, certKey)
+	}
+
 	return joinCmd, nil
 }
 
@@ -937,7 +983,8 @@ func (k *Bootstrapper) UpdateCluster(cfg config.ClusterConfig) error {
 		}
 	}
 
-	if cfg.KubernetesConfig.ShouldLoadCachedImages {
+	// the images of a local build are loaded from its directory when updating the node
+	if cfg.KubernetesConfig.ShouldLoadCachedImages && cfg.KubernetesConfig.KubernetesImagesDir == "" {
 		if err := machine.LoadCachedImages(&cfg, k.c, images, detect.ImageCacheDir(), false); err != nil {
 			out.FailureT("Unable to load cached images: {{.error}}", out.V{"error": err})
 		}
@@ -981,6 +1028,12 @@ func (k *Bootstrapper) UpdateNode(cfg config.ClusterConfig, n config.Node, r cru
 		return errors.Wrap(err, "downloading binaries")
 	}
 
+	if cfg.KubernetesConfig.KubernetesImagesDir != "" {
+		if err := bsutil.LoadLocalBuildImages(cfg, k.c); err != nil {
+			return errors.Wrap(err, "loading images of the local build")
+		}
+	}
+
 	files := []assets.CopyableFile{
 		assets.NewMemoryAssetTarget(kubeletCfg, bsutil.KubeletSystemdConfFile, "0644"),
 		assets.NewMemoryAssetTarget(kubeletService, bsutil.KubeletServiceFile, "0644"),
@@ -990,6 +1043,41 @@ func (k *Bootstrapper) UpdateNode(cfg config.ClusterConfig, n config.Node, r cru
 		files = append(files, assets.NewMemoryAssetTarget(kubeadmCfg, constants.KubeadmYamlPath+".new", "0640"))
 	}
 
+	// the patches of --kubeadm-patches, which the kubelet config overrides of the node come after
+	patches, err := bsutil.KubeadmPatches(cfg)
+	if err != nil {
+		return errors.Wrap(err, "kubeadm patches")
+	}
+	if _, err := k.c.RunCmd(exec.Command("sudo", "rm", "-rf", bsutil.KubeadmPatchesDir)); err != nil {
+		return errors.Wrap(err, "removing the previous kubeadm patches")
+	}
+	files = append(files, patches...)
+
+	// the kubelet config overrides of a joining node are applied by kubeadm join
+	if len(n.KubeletConfig) > 0 {
+		patch, err := bsutil.KubeletConfigPatch(n)
+		if err != nil {
+			return errors.Wrap(err, "generating kubelet config patch")
+		}
+		files = append(files, assets.NewMemoryAssetTarget(patch, bsutil.KubeletConfigPatchFile, "0644"))
+	}
+
+	// the control planes of a multi-control-plane cluster announce the virtual IP in front of their apiservers,
+	// and those of a cluster with a load balancer pool the IPs of its LoadBalancer services
+	if (config.IsHA(cfg) || cfg.LoadBalancerPool != "") && n.ControlPlane {
+		ipAddrs := ""
+		if rr, err := k.c.RunCmd(exec.Command("ip", "-o", "-4", "addr", "show")); err != nil {
+			klog.Warningf("unable to list the network interfaces, kube-vip will use eth0: %v", err)
+		} else {
+			ipAddrs = rr.Stdout.String()
+		}
+		kubeVip, err := bsutil.GenerateKubeVipManifest(cfg, n, bsutil.KubeVipInterface(ipAddrs, n.IP))
+		if err != nil {
+			return errors.Wrap(err, "generating kube-vip manifest")
+		}
+		files = append(files, assets.NewMemoryAssetTarget(kubeVip, bsutil.KubeVipManifestPath, "0600"))
+	}
+
 	// Installs compatibility shims for non-systemd environments
 	kubeletPath := path.Join(vmpath.GuestPersistentDir, "binaries", cfg.KubernetesConfig.KubernetesVersion, "kubelet")
 	shims, err := sm.GenerateInitShim("kubelet", kubeletPath, bsutil.KubeletSystemdConfFile)
@@ -1011,7 +1099,12 @@ func (k *Bootstrapper) UpdateNode(cfg config.ClusterConfig, n config.Node, r cru
 		return errors.Wrap(err, "control plane")
 	}
 
-	if err := machine.AddHostAlias(k.c, constants.ControlPlaneAlias, net.ParseIP(cp.IP)); err != nil {
+	// the control plane endpoint of a multi-control-plane cluster is its virtual IP
+	cpIP := cp.IP
+	if cfg.KubernetesConfig.APIServerHAVIP != "" {
+		cpIP = cfg.KubernetesConfig.APIServerHAVIP
+	}
+	if err := machine.AddHostAlias(k.c, constants.ControlPlaneAlias, net.ParseIP(cpIP)); err != nil {
 		return errors.Wrap(err, "host alias")
 	}
 
@@ -1081,6 +1174,32 @@ func (k *Bootstrapper) applyNodeLabels(cfg config.ClusterConfig) error {
 	return nil
 }
 
+// applyLoadBalancerPool deploys the kube-vip cloud provider giving LoadBalancer services an IP of the pool of the cluster
+func (k *Bootstrapper) applyLoadBalancerPool(cfg config.ClusterConfig) error {
+	if cfg.LoadBalancerPool == "" {
+		return nil
+	}
+	manifest, err := bsutil.GenerateLoadBalancerManifest(cfg)
+	if err != nil {
+		return errors.Wrap(err, "generating load balancer manifest")
+	}
+	if err := k.c.Copy(assets.NewMemoryAssetTarget(manifest, bsutil.LoadBalancerManifestPath, "0640")); err != nil {
+		return errors.Wrap(err, "copy")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), applyTimeoutSeconds*time.Second)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sudo", kubectlPath(cfg), "apply",
+		fmt.Sprintf("--kubeconfig=%s", path.Join(vmpath.GuestPersistentDir, "kubeconfig")), "-f", bsutil.LoadBalancerManifestPath)
+	if _, err := k.c.RunCmd(cmd); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return errors.Wrapf(err, "timeout apply load balancer pool")
+		}
+		return errors.Wrapf(err, "applying load balancer pool")
+	}
+	return nil
+}
+
 // elevateKubeSystemPrivileges gives the kube-system service account cluster admin privileges to work with RBAC.
 func (k *Bootstrapper) elevateKubeSystemPrivileges(cfg config.ClusterConfig) error {
 	start := time.Now()
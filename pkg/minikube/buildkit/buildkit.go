@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package buildkit manages the buildkitd of the nodes which minikube image build builds the images of containerd with
+package buildkit
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/sysinit"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+)
+
+const (
+	// Port is the TCP port buildkitd listens on in the nodes of the drivers whose containers can't be exec'd into
+	Port = 1234
+
+	// keepStorage is the size of the build cache kept by the garbage collection of buildkitd, in MB
+	keepStorage = 10240
+
+	// marker marks the configs generated by minikube, which are rewritten when they differ
+	marker = "# managed by minikube"
+
+	configPath = "/etc/buildkit/buildkitd.toml"
+	dropInPath = "/etc/systemd/system/buildkit.service.d/10-minikube.conf"
+	socket     = "/run/buildkit/buildkitd.sock"
+
+	// the TLS certificates of the node, provisioned for the docker daemon
+	caCert     = "/etc/docker/ca.pem"
+	serverCert = "/etc/docker/server.pem"
+	serverKey  = "/etc/docker/server-key.pem"
+)
+
+// CacheDir is the root of buildkitd, kept on the persistent disk of the nodes so the build cache survives their restarts
+var CacheDir = path.Join(vmpath.GuestPersistentDir, "buildkit")
+
+// SupportsRuntime returns whether the images of the container runtime rt are built by the managed buildkitd
+func SupportsRuntime(rt string) bool {
+	return rt == constants.Containerd
+}
+
+// ListensTCP returns whether buildkitd listens on Port in the nodes of the driver, which are reached over TLS
+func ListensTCP(driverName string) bool {
+	return !driver.IsKIC(driverName) && !driver.BareMetal(driverName)
+}
+
+// Address returns the BUILDKIT_HOST reaching the buildkitd of the node machineName at ip
+func Address(driverName, machineName, ip string) string {
+	switch {
+	case driver.IsDocker(driverName):
+		return "docker-container://" + machineName
+	case driverName == driver.Podman:
+		return "podman-container://" + machineName
+	case driver.BareMetal(driverName):
+		return "unix://" + socket
+	}
+	return fmt.Sprintf("tcp://%s:%d", ip, Port)
+}
+
+// Config returns the buildkitd.toml building into the image store of containerd, with its cache in CacheDir
+func Config() string {
+	return fmt.Sprintf(`%s
+root = %q
+
+[worker.oci]
+  enabled = false
+
+[worker.containerd]
+  enabled = true
+  namespace = "k8s.io"
+  gc = true
+  gckeepstorage = %d
+`, marker, CacheDir, keepStorage)
+}
+
+// DropIn returns the systemd drop-in running the buildkitd binary bin, also on Port with the TLS certificates of the node if tcp is set
+func DropIn(bin string, tcp bool) string {
+	args := []string{bin, "--config", configPath, "--addr", "fd://"}
+	if tcp {
+		args = append(args, "--addr", fmt.Sprintf("tcp://0.0.0.0:%d", Port), "--tlscacert", caCert, "--tlscert", serverCert, "--tlskey", serverKey)
+	}
+	return fmt.Sprintf("%s\n[Service]\nExecStart=\nExecStart=%s\n", marker, strings.Join(args, " "))
+}
+
+// Ensure configures and starts the managed buildkitd of the node of r, restarting it when its config changed
+func Ensure(r command.Runner, tcp bool) error {
+	rr, err := r.RunCmd(exec.Command("sh", "-c", "command -v buildkitd"))
+	if err != nil {
+		return errors.Wrap(err, "buildkitd is not installed on the node")
+	}
+	bin := strings.TrimSpace(rr.Stdout.String())
+
+	if _, err := r.RunCmd(exec.Command("sudo", "mkdir", "-p", CacheDir)); err != nil {
+		return errors.Wrap(err, "creating the build cache")
+	}
+	changed := false
+	for p, content := range map[string]string{configPath: Config(), dropInPath: DropIn(bin, tcp)} {
+		c, err := write(r, p, content)
+		if err != nil {
+			return errors.Wrapf(err, "writing %s", p)
+		}
+		changed = changed || c
+	}
+
+	sm := sysinit.New(r)
+	if !changed {
+		return sm.Start("buildkit")
+	}
+	klog.Infof("restarting buildkitd with its new config")
+	if _, err := r.RunCmd(exec.Command("sudo", "systemctl", "daemon-reload")); err != nil {
+		return errors.Wrap(err, "daemon-reload")
+	}
+	if err := sm.Enable("buildkit.socket"); err != nil {
+		return err
+	}
+	return sm.Restart("buildkit")
+}
+
+// write writes content to the file p of the node if it differs, returning whether it did
+func write(r command.Runner, p, content string) (bool, error) {
+	if rr, err := r.RunCmd(exec.Command("sudo", "cat", p)); err == nil && rr.Stdout.String() == content {
+		return false, nil
+	}
+	return true, r.Copy(assets.NewMemoryAssetTarget([]byte(content), p, "0644"))
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package buildkit
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/driver"
+)
+
+func TestAddress(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   string
+		tcp    bool
+	}{
+		{driver.Docker, "docker-container://minikube", false},
+		{driver.Podman, "podman-container://minikube", false},
+		{driver.None, "unix:///run/buildkit/buildkitd.sock", false},
+		{driver.KVM2, "tcp://192.168.39.2:1234", true},
+		{driver.QEMU2, "tcp://192.168.39.2:1234", true},
+	}
+	for _, tc := range tests {
+		if got := Address(tc.driver, "minikube", "192.168.39.2"); got != tc.want {
+			t.Errorf("Address(%s) = %s, want %s", tc.driver, got, tc.want)
+		}
+		if got := ListensTCP(tc.driver); got != tc.tcp {
+			t.Errorf("ListensTCP(%s) = %t, want %t", tc.driver, got, tc.tcp)
+		}
+	}
+}
+
+func TestConfig(t *testing.T) {
+	c := Config()
+	for _, want := range []string{`root = "/var/lib/minikube/buildkit"`, `namespace = "k8s.io"`, "gckeepstorage = 10240"} {
+		if !strings.Contains(c, want) {
+			t.Errorf("Config() = %s, want it to contain %s", c, want)
+		}
+	}
+}
+
+func TestDropIn(t *testing.T) {
+	want := marker + `
+[Service]
+ExecStart=
+ExecStart=/usr/bin/buildkitd --config /etc/buildkit/buildkitd.toml --addr fd://
+`
+	if got := DropIn("/usr/bin/buildkitd", false); got != want {
+		t.Errorf("DropIn() = %s, want %s", got, want)
+	}
+	got := DropIn("/usr/bin/buildkitd", true)
+	if !strings.Contains(got, "--addr tcp://0.0.0.0:1234 --tlscacert /etc/docker/ca.pem --tlscert /etc/docker/server.pem --tlskey /etc/docker/server-key.pem") {
+		t.Errorf("DropIn() with TCP = %s, want the TLS address", got)
+	}
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package bundle packs the artifacts minikube downloads into a single archive, to start clusters on air-gapped hosts
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// manifestName is the name of the manifest in the archive, before the artifacts
+const manifestName = "bundle.json"
+
+// Manifest describes what a bundle was made for, and what it holds
+type Manifest struct {
+	MinikubeVersion   string
+	KubernetesVersion string
+	ContainerRuntime  string
+	Driver            string
+	Arch              string
+	// Images are the images loaded into the clusters by minikube start, as with 'minikube cache add'
+	Images []string
+	// Files are the entries of the artifacts, ROOT/PATH where PATH is relative to the root directory ROOT
+	Files []string
+}
+
+// File is an artifact to bundle: its entry in the archive, and the file holding it
+type File struct {
+	Entry string
+	Path  string
+}
+
+// Roots maps the roots of the entries to their directories, such as "cache" to the artifact cache
+type Roots map[string]string
+
+// Entry returns the entry of the file p, which must be in one of the roots
+func (r Roots) Entry(p string) (string, error) {
+	for root, dir := range r {
+		rel, err := filepath.Rel(dir, p)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		return path.Join(root, filepath.ToSlash(rel)), nil
+	}
+	return "", errors.Errorf("%s is not in %v", p, r)
+}
+
+// Path returns the file of entry, which must be in one of the roots
+func (r Roots) Path(entry string) (string, error) {
+	root, rel, ok := strings.Cut(entry, "/")
+	dir, known := r[root]
+	if !ok || !known || rel == "" || path.IsAbs(rel) || path.Clean(rel) != rel || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", errors.Errorf("invalid entry %q", entry)
+	}
+	return filepath.Join(dir, filepath.FromSlash(rel)), nil
+}
+
+// Write writes the bundle of m to dst, a gzipped tarball of the manifest and the files, which are the files of m
+func Write(dst string, m Manifest, files []File) (err error) {
+	tmp := dst + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(tmp)
+			return
+		}
+		err = os.Rename(tmp, dst)
+	}()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	m.Files = nil
+	for _, file := range files {
+		m.Files = append(m.Files, file.Entry)
+	}
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "manifest")
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: manifestName, Mode: 0644, Size: int64(len(b))}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(b); err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := writeFile(tw, file); err != nil {
+			return errors.Wrapf(err, "adding %s", file.Path)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func writeFile(tw *tar.Writer, file File) error {
+	p := file.Path
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if !st.Mode().IsRegular() {
+		return errors.Errorf("%s is not a regular file", p)
+	}
+	klog.Infof("adding %s (%d bytes)", p, st.Size())
+	hdr := &tar.Header{Name: file.Entry, Mode: int64(st.Mode().Perm()), Size: st.Size(), ModTime: st.ModTime()}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// Read returns the manifest of the bundle src
+func Read(src string) (*Manifest, error) {
+	return walk(src, nil)
+}
+
+// Extract writes the files of the bundle src to their roots, and returns its manifest
+func Extract(src string, roots Roots) (*Manifest, error) {
+	return walk(src, roots)
+}
+
+// walk reads the manifest of the bundle src, and writes its files to roots unless roots is nil
+func walk(src string, roots Roots) (*Manifest, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s is not a bundle", src)
+	}
+	tr := tar.NewReader(gz)
+
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != manifestName {
+		return nil, errors.Errorf("%s is not a bundle: no %s", src, manifestName)
+	}
+	var m Manifest
+	if err := json.NewDecoder(tr).Decode(&m); err != nil {
+		return nil, errors.Wrap(err, "manifest")
+	}
+	if roots == nil {
+		return &m, nil
+	}
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return &m, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, errors.Errorf("%s is not a regular file", hdr.Name)
+		}
+		p, err := roots.Path(hdr.Name)
+		if err != nil {
+			return nil, err
+		}
+		if err := ExtractFile(tr, p, os.FileMode(hdr.Mode).Perm()); err != nil {
+			return nil, errors.Wrapf(err, "extracting %s", hdr.Name)
+		}
+	}
+}
+
+// ExtractFile writes r to p, atomically so that a partial file is never taken for a cached artifact or a restored profile
+func ExtractFile(r io.Reader, p string, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	klog.Infof("extracting %s", p)
+	tmp := p + ".bundle"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, p)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bundle
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteExtract(t *testing.T) {
+	src := t.TempDir()
+	roots := Roots{"cache": filepath.Join(src, "cache"), "bin": filepath.Join(src, "bin")}
+	contents := map[string]string{
+		filepath.Join(src, "cache", "preloaded-tarball", "preload.tar.lz4"):                "preload",
+		filepath.Join(src, "cache", "images", "amd64", "gcr.io", "storage-provisioner_v5"): "image",
+		filepath.Join(src, "bin", "docker-machine-driver-kvm2"):                            "driver",
+	}
+	var files []File
+	for p, c := range contents {
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(c), 0755); err != nil {
+			t.Fatal(err)
+		}
+		entry, err := roots.Entry(p)
+		if err != nil {
+			t.Fatalf("Entry(%s): %v", p, err)
+		}
+		files = append(files, File{Entry: entry, Path: p})
+	}
+
+	archive := filepath.Join(t.TempDir(), "bundle.tar.gz")
+	m := Manifest{KubernetesVersion: "v1.28.4", Driver: "kvm2", Images: []string{"gcr.io/k8s-minikube/storage-provisioner:v5"}}
+	if err := Write(archive, m, files); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	got, err := Read(archive)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if got.KubernetesVersion != m.KubernetesVersion || !reflect.DeepEqual(got.Images, m.Images) || len(got.Files) != len(files) {
+		t.Errorf("Read() = %+v", got)
+	}
+
+	dst := t.TempDir()
+	dstRoots := Roots{"cache": filepath.Join(dst, "cache"), "bin": filepath.Join(dst, "bin")}
+	if _, err := Extract(archive, dstRoots); err != nil {
+		t.Fatalf("Extract: %v", err)
+	}
+	for p, c := range contents {
+		rel, _ := filepath.Rel(src, p)
+		b, err := os.ReadFile(filepath.Join(dst, rel))
+		if err != nil || string(b) != c {
+			t.Errorf("extracted %s = %q, %v, want %q", rel, b, err, c)
+		}
+	}
+}
+
+func TestRoots(t *testing.T) {
+	roots := Roots{"cache": filepath.FromSlash("/home/user/.minikube/cache")}
+
+	entry, err := roots.Entry(filepath.FromSlash("/home/user/.minikube/cache/iso/amd64/minikube.iso"))
+	if err != nil || entry != "cache/iso/amd64/minikube.iso" {
+		t.Errorf("Entry() = %q, %v", entry, err)
+	}
+	if _, err := roots.Entry(filepath.FromSlash("/etc/passwd")); err == nil {
+		t.Errorf("Entry() of a file out of the roots succeeded")
+	}
+
+	for _, entry := range []string{"cache/../../.ssh/id_rsa", "cache/a/../../b", "other/file", "cache/", "cache//a", "/cache/a"} {
+		if p, err := roots.Path(entry); err == nil {
+			t.Errorf("Path(%q) = %q, want an error", entry, p)
+		}
+	}
+}
+
+func TestReadNotABundle(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(p, []byte("not a bundle"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(p); err == nil {
+		t.Errorf("Read() of a file which is not a bundle succeeded")
+	}
+}
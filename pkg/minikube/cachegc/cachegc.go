@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cachegc evicts the least recently used entries of the cache of minikube: its images, ISOs, preloads and binaries.
+// An entry is used when minikube finds it in the cache, which Touch records in its modification time.
+package cachegc
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// Entry is a file of the cache
+type Entry struct {
+	// Path is the path of the file
+	Path string
+	// Kind is what the file holds, such as image, iso or preload
+	Kind string
+	// Size is the size of the file, in bytes
+	Size int64
+	// LastUsed is when minikube last downloaded or found the file
+	LastUsed time.Time
+}
+
+// Options selects the entries evicted by Prune
+type Options struct {
+	// MaxSize evicts the least recently used entries until the cache is not larger, 0 meaning no quota
+	MaxSize int64
+	// OlderThan evicts the entries not used for longer, 0 meaning no age limit
+	OlderThan time.Duration
+	// Unused evicts all the entries which are not protected
+	Unused bool
+	// Protected are the paths of the files and directories never evicted, as they are used by the clusters
+	Protected []string
+	// DryRun returns the evicted entries without removing them
+	DryRun bool
+}
+
+// kinds are the kinds of the entries by the top directory of the cache holding them
+var kinds = map[string]string{
+	"images":            "image",
+	"kic":               "kic",
+	"iso":               "iso",
+	"preloaded-tarball": "preload",
+	"linux":             "binary",
+	"darwin":            "binary",
+	"windows":           "binary",
+	"artifacts":         "artifact",
+	"layers":            "layer",
+}
+
+// inProgress are the suffixes of the files of a running minikube: its locks, and the partial downloads with their progress
+var inProgress = []string{".lock", ".download", ".parts"}
+
+// Touch records that the entry path was used, making it the most recently used
+func Touch(path string) {
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		klog.Warningf("failed to record the use of %s: %v", path, err)
+	}
+}
+
+// Entries returns the entries of the cache dir, the least recently used first
+func Entries(dir string) ([]Entry, error) {
+	var entries []Entry
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() || busy(p) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, Entry{Path: p, Kind: kind(dir, p), Size: info.Size(), LastUsed: info.ModTime()})
+		return nil
+	})
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].LastUsed.Before(entries[j].LastUsed) })
+	return entries, err
+}
+
+// busy returns whether p may be used by a running minikube
+func busy(p string) bool {
+	for _, s := range inProgress {
+		if strings.HasSuffix(p, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// kind returns the kind of the entry p of the cache dir
+func kind(dir, p string) string {
+	rel, err := filepath.Rel(dir, p)
+	if err != nil {
+		return "other"
+	}
+	if k, ok := kinds[strings.Split(filepath.ToSlash(rel), "/")[0]]; ok {
+		return k
+	}
+	return "other"
+}
+
+// Size returns the total size of the entries
+func Size(entries []Entry) int64 {
+	var size int64
+	for _, e := range entries {
+		size += e.Size
+	}
+	return size
+}
+
+// protected returns whether p is one of the protected paths, or is under one of them
+func protected(p string, paths []string) bool {
+	for _, pp := range paths {
+		if p == pp || strings.HasPrefix(p, pp+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Prune evicts the entries of the cache dir selected by o, returning them
+func Prune(dir string, o Options) ([]Entry, error) {
+	entries, err := Entries(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listing %s", dir)
+	}
+	size := Size(entries)
+	var evicted []Entry
+	for _, e := range entries {
+		if protected(e.Path, o.Protected) {
+			continue
+		}
+		overQuota := o.MaxSize > 0 && size > o.MaxSize
+		tooOld := o.OlderThan > 0 && time.Since(e.LastUsed) > o.OlderThan
+		if !o.Unused && !overQuota && !tooOld {
+			continue
+		}
+		if !o.DryRun {
+			if err := os.Remove(e.Path); err != nil {
+				return evicted, errors.Wrapf(err, "evicting %s", e.Path)
+			}
+			removeEmptyParents(dir, filepath.Dir(e.Path))
+		}
+		size -= e.Size
+		evicted = append(evicted, e)
+	}
+	return evicted, nil
+}
+
+// removeEmptyParents removes d and its parents under the cache dir while they are empty
+func removeEmptyParents(dir, d string) {
+	for d != dir && strings.HasPrefix(d, dir) {
+		if err := os.Remove(d); err != nil {
+			return
+		}
+		d = filepath.Dir(d)
+	}
+}
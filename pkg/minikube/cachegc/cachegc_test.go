@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cachegc
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testCache creates a cache whose entries were last used the given hours ago, each of 100 bytes
+func testCache(t *testing.T, ages map[string]int) string {
+	dir := t.TempDir()
+	for p, hours := range ages {
+		p = filepath.Join(dir, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, make([]byte, 100), 0644); err != nil {
+			t.Fatal(err)
+		}
+		used := time.Now().Add(-time.Duration(hours) * time.Hour)
+		if err := os.Chtimes(p, used, used); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+var ages = map[string]int{
+	"preloaded-tarball/preloaded-images-k8s-v18-v1.27.4-docker-overlay2-amd64.tar.lz4":                300,
+	"preloaded-tarball/preloaded-images-k8s-v18-v1.28.3-docker-overlay2-amd64.tar.lz4":                1,
+	"iso/amd64/minikube-v1.31.0-amd64.iso":                                                            200,
+	"images/amd64/registry.k8s.io/pause_3.9":                                                          100,
+	"linux/amd64/v1.27.4/kubectl":                                                                     50,
+	"linux/amd64/v1.27.4/kubectl.lock":                                                                400,
+	"iso/amd64/minikube-v1.32.0-amd64.iso.download":                                                   400,
+	"preloaded-tarball/preloaded-images-k8s-v18-v1.29.0-docker-overlay2-amd64.tar.lz4.download.parts": 400,
+}
+
+// evictedPaths returns the paths of the evicted entries relative to dir
+func evictedPaths(t *testing.T, dir string, evicted []Entry) []string {
+	var paths []string
+	for _, e := range evicted {
+		rel, err := filepath.Rel(dir, e.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	return paths
+}
+
+func TestEntries(t *testing.T) {
+	dir := testCache(t, ages)
+	entries, err := Entries(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, e := range entries {
+		kinds = append(kinds, e.Kind)
+	}
+	if want := []string{"preload", "iso", "image", "binary", "preload"}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("the kinds of the entries from the least recently used are %v, want %v", kinds, want)
+	}
+	if size := Size(entries); size != 500 {
+		t.Errorf("Size() = %d, want 500", size)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	protectedBinaries := []string{"linux/amd64/v1.27.4"}
+	tests := []struct {
+		name string
+		o    Options
+		want []string
+	}{
+		{"quota", Options{MaxSize: 250}, []string{
+			"preloaded-tarball/preloaded-images-k8s-v18-v1.27.4-docker-overlay2-amd64.tar.lz4",
+			"iso/amd64/minikube-v1.31.0-amd64.iso",
+			"images/amd64/registry.k8s.io/pause_3.9",
+		}},
+		{"age", Options{OlderThan: 150 * time.Hour}, []string{
+			"preloaded-tarball/preloaded-images-k8s-v18-v1.27.4-docker-overlay2-amd64.tar.lz4",
+			"iso/amd64/minikube-v1.31.0-amd64.iso",
+		}},
+		{"unused", Options{Unused: true, Protected: protectedBinaries}, []string{
+			"preloaded-tarball/preloaded-images-k8s-v18-v1.27.4-docker-overlay2-amd64.tar.lz4",
+			"iso/amd64/minikube-v1.31.0-amd64.iso",
+			"images/amd64/registry.k8s.io/pause_3.9",
+			"preloaded-tarball/preloaded-images-k8s-v18-v1.28.3-docker-overlay2-amd64.tar.lz4",
+		}},
+		{"nothing", Options{}, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := testCache(t, ages)
+			for i, p := range tc.o.Protected {
+				tc.o.Protected[i] = filepath.Join(dir, filepath.FromSlash(p))
+			}
+			evicted, err := Prune(dir, tc.o)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := evictedPaths(t, dir, evicted); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Prune() evicted %v, want %v", got, tc.want)
+			}
+			for _, e := range evicted {
+				if _, err := os.Stat(e.Path); !os.IsNotExist(err) {
+					t.Errorf("%s was not removed: %v", e.Path, err)
+				}
+			}
+		})
+	}
+}
+
+func TestPruneDryRun(t *testing.T) {
+	dir := testCache(t, ages)
+	evicted, err := Prune(dir, Options{Unused: true, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evicted) != 5 {
+		t.Errorf("Prune() would evict %d entries, want 5", len(evicted))
+	}
+	for _, e := range evicted {
+		if _, err := os.Stat(e.Path); err != nil {
+			t.Errorf("the dry run removed %s: %v", e.Path, err)
+		}
+	}
+}
+
+func TestTouch(t *testing.T) {
+	dir := testCache(t, ages)
+	p := filepath.Join(dir, "iso", "amd64", "minikube-v1.31.0-amd64.iso")
+	Touch(p)
+	entries, err := Entries(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := entries[len(entries)-1]; last.Path != p {
+		t.Errorf("the most recently used entry is %s, want %s", last.Path, p)
+	}
+}
@@ -29,12 +29,20 @@ import (
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/drivers/plugin"
 	"k8s.io/minikube/pkg/minikube/driver"
 	"k8s.io/minikube/pkg/minikube/machine"
 )
 
 // HostIP gets the ip address to be used for mapping host -> VM and VM -> host
 func HostIP(host *host.Host, clusterName string) (net.IP, error) {
+	if d, ok := host.Driver.(*plugin.Driver); ok {
+		ip, err := d.GetHostIP()
+		if err != nil {
+			return []byte{}, errors.Wrap(err, "Error getting host IP address")
+		}
+		return net.ParseIP(ip), nil
+	}
 	switch host.DriverName {
 	case driver.Docker:
 		return oci.RoutableHostIPFromInside(oci.Docker, clusterName, host.Name)
@@ -125,7 +133,7 @@ func HostIP(host *host.Host, clusterName string) (net.IP, error) {
 		ip := ipMatch[1]
 
 		return net.ParseIP(ip), nil
-	case driver.HyperKit:
+	case driver.HyperKit, driver.VZ, driver.Firecracker, driver.CloudHypervisor, driver.LXD, driver.WSL:
 		vmIPString, _ := host.Driver.GetIP()
 		gatewayIPString := vmIPString[:strings.LastIndex(vmIPString, ".")+1] + "1"
 		return net.ParseIP(gatewayIPString), nil
@@ -18,6 +18,7 @@ package cluster
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"sort"
@@ -28,6 +29,7 @@ import (
 	"github.com/spf13/viper"
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/exit"
 	"k8s.io/minikube/pkg/minikube/localpath"
@@ -35,6 +37,9 @@ import (
 	"k8s.io/minikube/pkg/util/lock"
 )
 
+// nineP is the default mount type
+const nineP = "9p"
+
 // MountConfig defines the options available to the Mount command
 type MountConfig struct {
 	// Type is the filesystem type (Typically 9p)
@@ -170,6 +175,21 @@ func mntCmd(source string, target string, c *MountConfig) string {
 	return fmt.Sprintf("sudo mount -t %s -o %s %s %s", c.Type, strings.Join(opts, ","), source, target)
 }
 
+// MountVirtiofs mounts the virtiofs share of the VM tagged tag at target, unless it is already mounted
+func MountVirtiofs(r mountRunner, tag string, target string) error {
+	rr, err := r.RunCmd(exec.Command("/bin/bash", "-c", virtiofsMntCmd(tag, target)))
+	if err != nil {
+		return errors.Wrapf(err, "mount with cmd %s ", rr.Command())
+	}
+	klog.Infof("virtiofs mount of %s successful", target)
+	return nil
+}
+
+// virtiofsMntCmd returns the command mounting the virtiofs share tagged tag at target, which is done once per boot
+func virtiofsMntCmd(tag string, target string) string {
+	return fmt.Sprintf("sudo mkdir -p %s && (findmnt -n -t virtiofs %s >/dev/null || sudo mount -t virtiofs %s %s)", target, target, tag, target)
+}
+
 // Unmount unmounts a path
 func Unmount(r mountRunner, target string) error {
 	// grep because findmnt will also display the parent!
@@ -180,3 +200,174 @@ func Unmount(r mountRunner, target string) error {
 	klog.Infof("unmount for %s ran successfully", target)
 	return nil
 }
+
+// StartMountProcess runs the mount command in the background, to mount the host directory of the mount string in the cluster
+func StartMountProcess(profile string, cc config.ClusterConfig, mountString string) error {
+	pid, err := startMountCmd(mountArgs(profile, cc, mountString))
+	if err != nil {
+		return err
+	}
+	if err := lock.AppendToFile(filepath.Join(localpath.Profile(profile), constants.MountProcessFileName), []byte(fmt.Sprintf(" %s", strconv.Itoa(pid))), 0o644); err != nil {
+		return errors.Wrap(err, "write mount pid")
+	}
+	return nil
+}
+
+// StartPersistentMounts runs the mount command of each persistent mount of the cluster in the background,
+// recording their pids in the profile directory, where the next start finds them to replace them
+func StartPersistentMounts(profile string, cc config.ClusterConfig) error {
+	var pids []string
+	for _, m := range cc.PersistentMounts {
+		pid, err := startMountCmd(persistentMountArgs(profile, cc, m))
+		if err != nil {
+			return errors.Wrapf(err, "mount %s", MountSpec(m))
+		}
+		pids = append(pids, strconv.Itoa(pid))
+		if err := lock.AppendToFile(filepath.Join(localpath.Profile(profile), constants.MountProcessFileName), []byte(fmt.Sprintf(" %d", pid)), 0o644); err != nil {
+			return errors.Wrap(err, "write mount pid")
+		}
+	}
+	if err := os.WriteFile(filepath.Join(localpath.Profile(profile), constants.PersistentMountProcessFileName), []byte(strings.Join(pids, " ")), 0o644); err != nil {
+		return errors.Wrap(err, "write persistent mount pids")
+	}
+	return nil
+}
+
+// startMountCmd runs minikube with the mount command arguments in the background, and returns its pid
+func startMountCmd(args []string) (int, error) {
+	mountCmd := exec.Command(os.Args[0], args...)
+	mountCmd.Env = append(os.Environ(), constants.IsMinikubeChildProcess+"=true")
+	if klog.V(8).Enabled() {
+		mountCmd.Stdout = os.Stdout
+		mountCmd.Stderr = os.Stderr
+	}
+	if err := mountCmd.Start(); err != nil {
+		return 0, errors.Wrap(err, "start mount")
+	}
+	return mountCmd.Process.Pid, nil
+}
+
+// mountArgs returns the arguments of the mount command of the mount string, with the mount options of the cluster
+func mountArgs(profile string, cc config.ClusterConfig, mountString string) []string {
+	mountDebugVal := 0
+	if klog.V(8).Enabled() {
+		mountDebugVal = 1
+	}
+
+	mountType := cc.MountType
+	// only the mount string is shared with virtiofs, on creation of the VM, the other directories are mounted with 9p
+	if mountType == constants.VirtiofsMountType {
+		mountType = nineP
+	}
+
+	args := []string{"mount", mountString}
+	flags := []struct {
+		name  string
+		value string
+	}{
+		{"profile", profile},
+		{"v", fmt.Sprintf("%d", mountDebugVal)},
+		{constants.Mount9PVersionFlag, cc.Mount9PVersion},
+		{constants.MountGIDFlag, cc.MountGID},
+		{constants.MountIPFlag, cc.MountIP},
+		{constants.MountMSizeFlag, fmt.Sprintf("%d", cc.MountMSize)},
+		{constants.MountPortFlag, fmt.Sprintf("%d", cc.MountPort)},
+		{constants.MountTypeFlag, mountType},
+		{constants.MountUIDFlag, cc.MountUID},
+	}
+	for _, flag := range flags {
+		args = append(args, fmt.Sprintf("--%s", flag.name), flag.value)
+	}
+	for _, option := range cc.MountOptions {
+		args = append(args, fmt.Sprintf("--%s", constants.MountOptionsFlag), option)
+	}
+	if mountType == constants.SyncMountType {
+		for _, pattern := range cc.MountSyncIgnore {
+			args = append(args, fmt.Sprintf("--%s", constants.MountSyncIgnoreFlag), pattern)
+		}
+		if cc.MountSyncConflict != "" {
+			args = append(args, fmt.Sprintf("--%s", constants.MountSyncConflictFlag), cc.MountSyncConflict)
+		}
+	}
+	if mountType == nineP && cc.MountForwardEvents {
+		args = append(args, fmt.Sprintf("--%s", constants.MountForwardEventsFlag))
+	}
+	return args
+}
+
+// persistentMountArgs returns the arguments of the mount command of a persistent mount, with the mount options of the cluster it does not override
+func persistentMountArgs(profile string, cc config.ClusterConfig, m config.Mount) []string {
+	cc.MountType = nineP
+	if m.Type != "" {
+		cc.MountType = m.Type
+	}
+	if m.UID != "" {
+		cc.MountUID = m.UID
+	}
+	if m.GID != "" {
+		cc.MountGID = m.GID
+	}
+	// the changes of read-only mounts can not be notified through them
+	if m.ReadOnly {
+		cc.MountForwardEvents = false
+	}
+	args := mountArgs(profile, cc, m.Source+":"+m.Target)
+	if m.ReadOnly {
+		args = append(args, fmt.Sprintf("--%s", constants.MountReadOnlyFlag))
+	}
+	return args
+}
+
+// ParseMount parses a persistent mount in the SRC:DST[,type=9p|sync][,uid=UID][,gid=GID][,ro] format
+func ParseMount(spec string) (config.Mount, error) {
+	var m config.Mount
+	fields := strings.Split(spec, ",")
+	idx := strings.LastIndex(fields[0], ":")
+	if idx == -1 {
+		return m, errors.Errorf("%q is not in the SRC:DST[,OPTION...] format", spec)
+	}
+	m.Source, m.Target = fields[0][:idx], fields[0][idx+1:]
+	if m.Source == "" {
+		return m, errors.Errorf("%q is missing the host directory", spec)
+	}
+	if !strings.HasPrefix(m.Target, "/") {
+		return m, errors.Errorf("the target directory of %q must be an absolute path", spec)
+	}
+	for _, o := range fields[1:] {
+		k, v, _ := strings.Cut(o, "=")
+		switch k {
+		case "type":
+			if v != nineP && v != constants.SyncMountType {
+				return m, errors.Errorf("unsupported type %q of %q, expected %s or %s", v, spec, nineP, constants.SyncMountType)
+			}
+			m.Type = v
+		case "uid":
+			m.UID = v
+		case "gid":
+			m.GID = v
+		case "ro", "readonly":
+			m.ReadOnly = true
+		default:
+			return m, errors.Errorf("unknown option %q of %q, expected type, uid, gid or ro", o, spec)
+		}
+	}
+	return m, nil
+}
+
+// MountSpec formats a persistent mount the way ParseMount reads it
+func MountSpec(m config.Mount) string {
+	spec := m.Source + ":" + m.Target
+	if m.Type != "" {
+		spec += ",type=" + m.Type
+	}
+	if m.UID != "" {
+		spec += ",uid=" + m.UID
+	}
+	if m.GID != "" {
+		spec += ",gid=" + m.GID
+	}
+	if m.ReadOnly {
+		spec += ",ro"
+	}
+	return spec
+}
@@ -17,9 +17,12 @@ limitations under the License.
 package cluster
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/minikube/pkg/minikube/config"
 )
 
 func TestMntCmd(t *testing.T) {
@@ -75,3 +78,95 @@ func TestMntCmd(t *testing.T) {
 		})
 	}
 }
+
+func TestVirtiofsMntCmd(t *testing.T) {
+	got := virtiofsMntCmd("minikube-mount", "/minikube-host")
+	want := "sudo mkdir -p /minikube-host && (findmnt -n -t virtiofs /minikube-host >/dev/null || sudo mount -t virtiofs minikube-mount /minikube-host)"
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("command diff (-want +got): %s", diff)
+	}
+}
+
+func TestMountArgs(t *testing.T) {
+	cc := config.ClusterConfig{MountType: "sync", MountUID: "docker", MountSyncIgnore: []string{".git", "*.log"}, MountSyncConflict: "newer"}
+	args := strings.Join(mountArgs("p1", cc, "/src:/src"), " ")
+	for _, want := range []string{"mount /src:/src", "--profile p1", "--type sync", "--uid docker", "--sync-ignore .git --sync-ignore *.log", "--sync-conflict newer"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("expected the mount args to contain %q, got: %s", want, args)
+		}
+	}
+
+	// only the mount string is shared with virtiofs
+	cc = config.ClusterConfig{MountType: "virtiofs", MountSyncIgnore: []string{".git"}}
+	args = strings.Join(mountArgs("p1", cc, "/policies:/var/lib/policies"), " ")
+	if !strings.Contains(args, "--type 9p") || strings.Contains(args, "--sync-ignore") {
+		t.Errorf("expected a 9p mount, got: %s", args)
+	}
+
+	// the file changes are only forwarded to 9p mounts
+	cc = config.ClusterConfig{MountType: "9p", MountForwardEvents: true}
+	if args := strings.Join(mountArgs("p1", cc, "/src:/src"), " "); !strings.Contains(args, "--forward-events") {
+		t.Errorf("expected the mount args to contain --forward-events, got: %s", args)
+	}
+	cc.MountType = "sync"
+	if args := strings.Join(mountArgs("p1", cc, "/src:/src"), " "); strings.Contains(args, "--forward-events") {
+		t.Errorf("expected the sync mount args not to contain --forward-events, got: %s", args)
+	}
+}
+
+func TestMountArgsOptions(t *testing.T) {
+	cc := config.ClusterConfig{MountType: "9p", Mount9PVersion: "9p2000.L", MountGID: "docker", MountIP: "192.168.49.1", MountMSize: 262144, MountPort: 40000, MountUID: "docker", MountOptions: []string{"cache=none", "noatime"}}
+	want := []string{"mount", "/policies:/var/lib/policies",
+		"--profile", "p1", "--v", "0", "--9p-version", "9p2000.L", "--gid", "docker", "--ip", "192.168.49.1",
+		"--msize", "262144", "--port", "40000", "--type", "9p", "--uid", "docker",
+		"--options", "cache=none", "--options", "noatime"}
+	if diff := cmp.Diff(want, mountArgs("p1", cc, "/policies:/var/lib/policies")); diff != "" {
+		t.Errorf("mountArgs mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseMount(t *testing.T) {
+	tests := []struct {
+		spec string
+		want config.Mount
+		err  bool
+	}{
+		{spec: "/src:/src", want: config.Mount{Source: "/src", Target: "/src"}},
+		{spec: "/src:/src,type=sync,uid=1000,gid=1000,ro", want: config.Mount{Source: "/src", Target: "/src", Type: "sync", UID: "1000", GID: "1000", ReadOnly: true}},
+		{spec: `C:\Users\me\src:/src,type=9p`, want: config.Mount{Source: `C:\Users\me\src`, Target: "/src", Type: "9p"}},
+		{spec: "/src", err: true},
+		{spec: ":/src", err: true},
+		{spec: "/src:src", err: true},
+		{spec: "/src:/src,type=virtiofs", err: true},
+		{spec: "/src:/src,cache=none", err: true},
+	}
+	for _, tc := range tests {
+		got, err := ParseMount(tc.spec)
+		if (err != nil) != tc.err {
+			t.Errorf("ParseMount(%q) error = %v, want error = %t", tc.spec, err, tc.err)
+			continue
+		}
+		if tc.err {
+			continue
+		}
+		if diff := cmp.Diff(tc.want, got); diff != "" {
+			t.Errorf("ParseMount(%q) mismatch (-want +got):\n%s", tc.spec, diff)
+		}
+		if spec := MountSpec(got); spec != tc.spec {
+			t.Errorf("MountSpec(%+v) = %q, want %q", got, spec, tc.spec)
+		}
+	}
+}
+
+func TestPersistentMountArgs(t *testing.T) {
+	cc := config.ClusterConfig{MountType: "virtiofs", MountUID: "docker", MountGID: "docker", MountForwardEvents: true}
+	args := strings.Join(persistentMountArgs("p1", cc, config.Mount{Source: "/src", Target: "/src", UID: "1000", ReadOnly: true}), " ")
+	for _, want := range []string{"mount /src:/src", "--type 9p", "--uid 1000", "--gid docker", "--read-only"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("expected the mount args to contain %q, got: %s", want, args)
+		}
+	}
+	if strings.Contains(args, "--forward-events") {
+		t.Errorf("expected the args of a read-only mount not to forward the events, got: %s", args)
+	}
+}
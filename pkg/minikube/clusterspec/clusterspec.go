@@ -0,0 +1,265 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clusterspec reads the versioned YAML specs of clusters reconciled by 'minikube apply',
+// which let teams commit the definition of their development cluster next to their code.
+package clusterspec
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/util"
+)
+
+const (
+	// APIVersion is the version of the specs understood by this minikube
+	APIVersion = "minikube.sigs.k8s.io/v1alpha1"
+	// Kind is the kind of the specs
+	Kind = "Cluster"
+)
+
+// Cluster is the spec of a cluster
+type Cluster struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Metadata   Metadata `json:"metadata"`
+	Spec       Spec     `json:"spec"`
+}
+
+// Metadata names the cluster
+type Metadata struct {
+	// Name is the profile of the cluster, defaults to the one of --profile
+	Name string `json:"name,omitempty"`
+}
+
+// Spec is the desired state of a cluster, the empty fields keep the defaults of 'minikube start'
+type Spec struct {
+	Driver            string          `json:"driver,omitempty"`
+	KubernetesVersion string          `json:"kubernetesVersion,omitempty"`
+	ContainerRuntime  string          `json:"containerRuntime,omitempty"`
+	Nodes             Nodes           `json:"nodes,omitempty"`
+	Resources         Resources       `json:"resources,omitempty"`
+	Addons            map[string]bool `json:"addons,omitempty"`
+	Certificates      Certificates    `json:"certificates,omitempty"`
+}
+
+// Nodes is the number of nodes of the cluster
+type Nodes struct {
+	// ControlPlanes defaults to 1, more make a highly available cluster
+	ControlPlanes int `json:"controlPlanes,omitempty"`
+	Workers       int `json:"workers,omitempty"`
+}
+
+// Resources are the resources of each node, in the units of the flags of 'minikube start'
+type Resources struct {
+	CPUs     string `json:"cpus,omitempty"`
+	Memory   string `json:"memory,omitempty"`
+	DiskSize string `json:"diskSize,omitempty"`
+}
+
+// Certificates are the options of the certificates of the apiserver
+type Certificates struct {
+	APIServerNames []string `json:"apiServerNames,omitempty"`
+	APIServerIPs   []string `json:"apiServerIPs,omitempty"`
+	// Expiration is the duration the certificates are valid for, such as 8760h
+	Expiration string `json:"expiration,omitempty"`
+}
+
+// Load reads and validates the spec in path, "-" reads it from stdin
+func Load(path string) (*Cluster, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "read spec")
+	}
+	return Parse(data)
+}
+
+// Parse parses and validates a spec, rejecting the unknown fields
+func Parse(data []byte) (*Cluster, error) {
+	c := &Cluster{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, errors.Wrap(err, "parse spec")
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Validate checks the version and the fields of the spec, and fills in the number of control planes
+func (c *Cluster) Validate() error {
+	if c.APIVersion != APIVersion {
+		return fmt.Errorf("unsupported apiVersion %q, expected %q", c.APIVersion, APIVersion)
+	}
+	if c.Kind != Kind {
+		return fmt.Errorf("unsupported kind %q, expected %q", c.Kind, Kind)
+	}
+	s := &c.Spec
+	if s.Nodes.ControlPlanes < 0 || s.Nodes.Workers < 0 {
+		return fmt.Errorf("the numbers of nodes can not be negative")
+	}
+	if s.Nodes.ControlPlanes == 0 {
+		s.Nodes.ControlPlanes = 1
+	}
+	if s.Nodes.ControlPlanes == 2 {
+		return fmt.Errorf("a highly available cluster needs at least 3 control planes for etcd to keep its quorum, not 2")
+	}
+	if _, err := s.Resources.memoryMB(); err != nil {
+		return err
+	}
+	if _, err := s.Resources.diskSizeMB(); err != nil {
+		return err
+	}
+	for _, ip := range s.Certificates.APIServerIPs {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("invalid apiserver IP %q", ip)
+		}
+	}
+	if s.Certificates.Expiration != "" {
+		if _, err := time.ParseDuration(s.Certificates.Expiration); err != nil {
+			return errors.Wrapf(err, "invalid certificate expiration %q", s.Certificates.Expiration)
+		}
+	}
+	return nil
+}
+
+// Conflicts returns the differences between the spec and the existing cluster cc which can only be
+// applied by deleting and creating the cluster again
+func (c *Cluster) Conflicts(cc config.ClusterConfig) []string {
+	var conflicts []string
+	s := c.Spec
+	if s.Driver != "" && s.Driver != cc.Driver {
+		conflicts = append(conflicts, fmt.Sprintf("driver: %s, the cluster runs on %s", s.Driver, cc.Driver))
+	}
+	if s.ContainerRuntime != "" && s.ContainerRuntime != cc.KubernetesConfig.ContainerRuntime {
+		conflicts = append(conflicts, fmt.Sprintf("containerRuntime: %s, the cluster runs %s", s.ContainerRuntime, cc.KubernetesConfig.ContainerRuntime))
+	}
+	if s.Resources.CPUs != "" && s.Resources.CPUs != fmt.Sprint(cc.CPUs) && s.Resources.CPUs != "max" && s.Resources.CPUs != "no-limit" {
+		conflicts = append(conflicts, fmt.Sprintf("cpus: %s, the nodes have %d", s.Resources.CPUs, cc.CPUs))
+	}
+	if mb, _ := s.Resources.memoryMB(); mb != 0 && mb != cc.Memory {
+		conflicts = append(conflicts, fmt.Sprintf("memory: %s, the nodes have %dMB", s.Resources.Memory, cc.Memory))
+	}
+	if mb, _ := s.Resources.diskSizeMB(); mb != 0 && mb != cc.DiskSize {
+		conflicts = append(conflicts, fmt.Sprintf("diskSize: %s, the nodes have %dMB", s.Resources.DiskSize, cc.DiskSize))
+	}
+	cps, _ := CountNodes(cc)
+	if s.Nodes.ControlPlanes < cps {
+		conflicts = append(conflicts, fmt.Sprintf("controlPlanes: %d, the cluster has %d and control planes can not be removed", s.Nodes.ControlPlanes, cps))
+	}
+	if s.Nodes.ControlPlanes > cps && !config.IsHA(cc) {
+		conflicts = append(conflicts, fmt.Sprintf("controlPlanes: %d, control planes can only be added to highly available clusters", s.Nodes.ControlPlanes))
+	}
+	return conflicts
+}
+
+// Changes returns the differences between the spec and the existing cluster cc which are applied by
+// starting the cluster again, the nodes and the addons aside
+func (c *Cluster) Changes(cc config.ClusterConfig) []string {
+	var changes []string
+	s := c.Spec
+	if s.KubernetesVersion != "" && s.KubernetesVersion != cc.KubernetesConfig.KubernetesVersion {
+		changes = append(changes, fmt.Sprintf("kubernetesVersion: %s, the cluster runs %s", s.KubernetesVersion, cc.KubernetesConfig.KubernetesVersion))
+	}
+	if s.Certificates.APIServerNames != nil && !sameSet(s.Certificates.APIServerNames, cc.KubernetesConfig.APIServerNames) {
+		changes = append(changes, fmt.Sprintf("apiServerNames: %v, the certificates hold %v", s.Certificates.APIServerNames, cc.KubernetesConfig.APIServerNames))
+	}
+	if s.Certificates.APIServerIPs != nil {
+		var ips []string
+		for _, ip := range cc.KubernetesConfig.APIServerIPs {
+			ips = append(ips, ip.String())
+		}
+		if !sameSet(s.Certificates.APIServerIPs, ips) {
+			changes = append(changes, fmt.Sprintf("apiServerIPs: %v, the certificates hold %v", s.Certificates.APIServerIPs, ips))
+		}
+	}
+	if d, _ := time.ParseDuration(s.Certificates.Expiration); d != 0 && d != cc.CertExpiration {
+		changes = append(changes, fmt.Sprintf("expiration: %s, the certificates expire after %s", s.Certificates.Expiration, cc.CertExpiration))
+	}
+	return changes
+}
+
+// CountNodes returns the numbers of control planes and of workers of cc, the workers of the node pools
+// being left out as they are managed with 'minikube node add --pool'
+func CountNodes(cc config.ClusterConfig) (controlPlanes int, workers int) {
+	for _, n := range cc.Nodes {
+		switch {
+		case n.ControlPlane:
+			controlPlanes++
+		case n.Pool == "":
+			workers++
+		}
+	}
+	return controlPlanes, workers
+}
+
+// sameSet returns whether a and b hold the same strings, in any order
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := map[string]int{}
+	for _, s := range a {
+		seen[s]++
+	}
+	for _, s := range b {
+		if seen[s] == 0 {
+			return false
+		}
+		seen[s]--
+	}
+	return true
+}
+
+// memoryMB returns the memory in MB, or 0 if unset or not a size such as "max"
+func (r Resources) memoryMB() (int, error) {
+	return sizeMB("memory", r.Memory, "max", "no-limit")
+}
+
+// diskSizeMB returns the disk size in MB, or 0 if unset
+func (r Resources) diskSizeMB() (int, error) {
+	return sizeMB("diskSize", r.DiskSize)
+}
+
+func sizeMB(field string, size string, keywords ...string) (int, error) {
+	if size == "" {
+		return 0, nil
+	}
+	for _, k := range keywords {
+		if size == k {
+			return 0, nil
+		}
+	}
+	mb, err := util.CalculateSizeInMB(size)
+	if err != nil {
+		return 0, errors.Wrapf(err, "invalid %s %q", field, size)
+	}
+	return mb, nil
+}
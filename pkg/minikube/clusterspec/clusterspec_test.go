@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterspec
+
+import (
+	"net"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+const spec = `apiVersion: minikube.sigs.k8s.io/v1alpha1
+kind: Cluster
+metadata:
+  name: dev
+spec:
+  driver: docker
+  kubernetesVersion: v1.28.4
+  nodes:
+    workers: 2
+  resources:
+    memory: 4g
+  addons:
+    ingress: true
+  certificates:
+    apiServerIPs: [192.168.1.10]
+`
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(spec))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if c.Metadata.Name != "dev" || c.Spec.Nodes.ControlPlanes != 1 || c.Spec.Nodes.Workers != 2 || !c.Spec.Addons["ingress"] {
+		t.Errorf("Parse() = %+v", c)
+	}
+
+	tests := []struct {
+		description string
+		data        string
+	}{
+		{"unknown field", spec + "  gpus: all\n"},
+		{"wrong version", "apiVersion: v2\nkind: Cluster\n"},
+		{"two control planes", "apiVersion: minikube.sigs.k8s.io/v1alpha1\nkind: Cluster\nspec:\n  nodes:\n    controlPlanes: 2\n"},
+		{"invalid memory", "apiVersion: minikube.sigs.k8s.io/v1alpha1\nkind: Cluster\nspec:\n  resources:\n    memory: lots\n"},
+		{"invalid IP", "apiVersion: minikube.sigs.k8s.io/v1alpha1\nkind: Cluster\nspec:\n  certificates:\n    apiServerIPs: [dev]\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			if _, err := Parse([]byte(tc.data)); err == nil {
+				t.Errorf("Parse(%q) succeeded, expected an error", tc.data)
+			}
+		})
+	}
+}
+
+func TestConflictsAndChanges(t *testing.T) {
+	c, err := Parse([]byte(spec))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	cc := config.ClusterConfig{
+		Driver: "docker",
+		Memory: 4096,
+		KubernetesConfig: config.KubernetesConfig{
+			KubernetesVersion: "v1.28.4",
+			APIServerIPs:      []net.IP{net.ParseIP("192.168.1.10")},
+		},
+		Nodes: []config.Node{{Name: "", ControlPlane: true, Worker: true}, {Name: "m02", Worker: true}, {Name: "m03", Worker: true, Pool: "gpu"}},
+	}
+	if got := c.Conflicts(cc); len(got) != 0 {
+		t.Errorf("Conflicts() = %v, expected none", got)
+	}
+	if got := c.Changes(cc); len(got) != 0 {
+		t.Errorf("Changes() = %v, expected none", got)
+	}
+	if cps, workers := CountNodes(cc); cps != 1 || workers != 1 {
+		t.Errorf("CountNodes() = %d, %d, expected 1, 1", cps, workers)
+	}
+
+	cc.Driver = "kvm2"
+	cc.Memory = 2048
+	cc.KubernetesConfig.KubernetesVersion = "v1.27.0"
+	if got := c.Conflicts(cc); len(got) != 2 {
+		t.Errorf("Conflicts() = %v, expected the driver and the memory", got)
+	}
+	if got := c.Changes(cc); len(got) != 1 {
+		t.Errorf("Changes() = %v, expected the Kubernetes version", got)
+	}
+}
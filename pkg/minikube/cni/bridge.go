@@ -48,7 +48,7 @@ var bridgeConf = template.Must(template.New("bridge").Parse(`
       "hairpinMode": true,
       "ipam": {
           "type": "host-local",
-          "subnet": "{{.PodCIDR}}"
+          {{if .PodCIDRv6}}"ranges": [{{if .PodCIDR}}[{"subnet": "{{.PodCIDR}}"}], {{end}}[{"subnet": "{{.PodCIDRv6}}"}]]{{else}}"subnet": "{{.PodCIDR}}"{{end}}
       }
     },
     {
@@ -72,7 +72,8 @@ func (c Bridge) String() string {
 }
 
 func (c Bridge) netconf() (assets.CopyableFile, error) {
-	input := &tmplInput{PodCIDR: DefaultPodCIDR}
+	input := &tmplInput{}
+	input.PodCIDR, input.PodCIDRv6 = familyPodCIDRs(c.cc)
 
 	b := bytes.Buffer{}
 	if err := bridgeConf.Execute(&b, input); err != nil {
@@ -77,7 +77,11 @@ func (c Calico) manifest() (assets.CopyableFile, error) {
 	if err := calicoTmpl.Execute(&b, input); err != nil {
 		return nil, err
 	}
-	return manifestAsset(b.Bytes()), nil
+	m, err := customize(c.cc, b.Bytes(), "node", "kube-controllers", "cni")
+	if err != nil {
+		return nil, err
+	}
+	return manifestAsset(m), nil
 }
 
 // Apply enables the CNI
@@ -859,6 +859,10 @@ func (c Cilium) Apply(r Runner) error {
 	if err != nil {
 		return errors.Wrap(err, "generating cilium cfg")
 	}
+	ciliumCfg, err = customize(c.cc, ciliumCfg, "cilium", "operator-generic")
+	if err != nil {
+		return errors.Wrap(err, "customizing cilium cfg")
+	}
 
 	return applyManifest(c.cc, r, manifestAsset(ciliumCfg))
 }
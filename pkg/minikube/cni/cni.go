@@ -70,10 +70,23 @@ type Manager interface {
 type tmplInput struct {
 	ImageName    string
 	PodCIDR      string
+	PodCIDRv6    string
 	DefaultRoute string
 	CNIConfDir   string
 }
 
+// familyPodCIDRs returns the IPv4 and IPv6 pod subnets of the IP family of the cluster, empty when it does not have the family
+func familyPodCIDRs(cc config.ClusterConfig) (string, string) {
+	switch cc.KubernetesConfig.IPFamily {
+	case config.IPv6Family:
+		return "", constants.DefaultPodCIDRv6
+	case config.DualStack:
+		return DefaultPodCIDR, constants.DefaultPodCIDRv6
+	default:
+		return DefaultPodCIDR, ""
+	}
+}
+
 // New returns a new CNI manager
 func New(cc *config.ClusterConfig) (Manager, error) {
 	if cc.KubernetesConfig.NetworkPlugin != "" && cc.KubernetesConfig.NetworkPlugin != "cni" {
@@ -101,7 +114,11 @@ func New(cc *config.ClusterConfig) (Manager, error) {
 	case "flannel":
 		cnm = Flannel{cc: *cc}
 	default:
-		cnm, err = NewCustom(*cc, cc.KubernetesConfig.CNI)
+		if chart, ok := strings.CutPrefix(cc.KubernetesConfig.CNI, HelmPrefix); ok {
+			cnm, err = NewHelm(*cc, chart)
+		} else {
+			cnm, err = NewCustom(*cc, cc.KubernetesConfig.CNI)
+		}
 	}
 
 	return cnm, err
@@ -52,6 +52,18 @@ func NewCustom(cc config.ClusterConfig, manifest string) (Custom, error) {
 
 // Apply enables the CNI
 func (c Custom) Apply(r Runner) error {
+	if len(c.cc.KubernetesConfig.CNIOverlays) > 0 {
+		b, err := os.ReadFile(c.manifest)
+		if err != nil {
+			return errors.Wrap(err, "manifest")
+		}
+		m, err := customize(c.cc, b)
+		if err != nil {
+			return errors.Wrap(err, "manifest")
+		}
+		return applyManifest(c.cc, r, manifestAsset(m))
+	}
+
 	m, err := assets.NewFileAsset(c.manifest, path.Dir(manifestPath()), path.Base(manifestPath()), "0644")
 	if err != nil {
 		return errors.Wrap(err, "manifest")
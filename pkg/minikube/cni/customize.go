@@ -0,0 +1,178 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cni
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+// imageLine matches the image of a container in a manifest, quoted or not
+var imageLine = regexp.MustCompile(`(?m)^(\s*(?:- )?image:\s*"?)([^"\s]+)("?\s*)$`)
+
+// ImageTag returns the tag of the images of a CNI version, which the calico, cilium and flannel images prefix with v
+func ImageTag(version string) string {
+	if strings.HasPrefix(version, "v") {
+		return version
+	}
+	return "v" + version
+}
+
+// customize sets the tag of the images of the manifest named after one of names to the CNI version of the cluster,
+// then merges the overlays of the cluster into the objects of the manifest
+func customize(cc config.ClusterConfig, manifest []byte, names ...string) ([]byte, error) {
+	if v := cc.KubernetesConfig.CNIVersion; v != "" && len(names) > 0 {
+		manifest = setImageTag(manifest, names, ImageTag(v))
+	}
+	if len(cc.KubernetesConfig.CNIOverlays) == 0 {
+		return manifest, nil
+	}
+	return applyOverlays(manifest, cc.KubernetesConfig.CNIOverlays)
+}
+
+// setImageTag sets the tag of the images of the manifest named after one of names, dropping their digests
+func setImageTag(manifest []byte, names []string, tag string) []byte {
+	return imageLine.ReplaceAllFunc(manifest, func(line []byte) []byte {
+		m := imageLine.FindSubmatch(line)
+		repo, _, _ := strings.Cut(string(m[2]), "@")
+		if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+			repo = repo[:i]
+		}
+		for _, name := range names {
+			if path.Base(repo) == name {
+				return []byte(string(m[1]) + repo + ":" + tag + string(m[3]))
+			}
+		}
+		return line
+	})
+}
+
+// object is a document of a manifest
+type object struct {
+	raw        []byte
+	apiVersion string
+	kind       string
+	name       string
+	namespace  string
+}
+
+// decodeObjects returns the objects of a multi-document manifest, as JSON
+func decodeObjects(manifest []byte) ([]object, error) {
+	var objs []object
+	r := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(manifest)))
+	for {
+		doc, err := r.Read()
+		if err == io.EOF {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		raw, err := yaml.YAMLToJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+		var meta struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Metadata   struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+		}
+		if err := json.Unmarshal(raw, &meta); err != nil {
+			return nil, err
+		}
+		if meta.Kind == "" {
+			// comments only
+			continue
+		}
+		objs = append(objs, object{raw: raw, apiVersion: meta.APIVersion, kind: meta.Kind, name: meta.Metadata.Name, namespace: meta.Metadata.Namespace})
+	}
+}
+
+// applyOverlays merges the patches of the overlay files into the objects of the manifest with the same kind and name,
+// and namespace when the patch has one: a strategic merge for the built-in kinds, a JSON merge patch for the others
+func applyOverlays(manifest []byte, overlays []string) ([]byte, error) {
+	objs, err := decodeObjects(manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "decoding the manifest")
+	}
+	for _, overlay := range overlays {
+		b, err := os.ReadFile(overlay)
+		if err != nil {
+			return nil, errors.Wrap(err, "reading overlay")
+		}
+		patches, err := decodeObjects(b)
+		if err != nil {
+			return nil, errors.Wrapf(err, "decoding overlay %s", overlay)
+		}
+		for _, p := range patches {
+			matched := false
+			for i, o := range objs {
+				if o.kind != p.kind || o.name != p.name || (p.namespace != "" && o.namespace != p.namespace) {
+					continue
+				}
+				if objs[i].raw, err = mergePatch(o, p.raw); err != nil {
+					return nil, errors.Wrapf(err, "patching %s %s with %s", o.kind, o.name, overlay)
+				}
+				matched = true
+			}
+			if !matched {
+				return nil, errors.Errorf("%s %s of overlay %s matches no object of the CNI manifest", p.kind, p.name, overlay)
+			}
+			klog.Infof("patched %s %s with %s", p.kind, p.name, overlay)
+		}
+	}
+
+	var b bytes.Buffer
+	for _, o := range objs {
+		y, err := yaml.JSONToYAML(o.raw)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString("---\n")
+		b.Write(y)
+	}
+	return b.Bytes(), nil
+}
+
+// mergePatch merges patch into o
+func mergePatch(o object, patch []byte) ([]byte, error) {
+	typed, err := scheme.Scheme.New(schema.FromAPIVersionAndKind(o.apiVersion, o.kind))
+	if err != nil {
+		return jsonpatch.MergePatch(o.raw, patch)
+	}
+	return strategicpatch.StrategicMergePatch(o.raw, patch, typed)
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cni
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestSetImageTag(t *testing.T) {
+	manifest := `      containers:
+      - name: cilium-agent
+        image: "quay.io/cilium/cilium:v1.12.3@sha256:30de50c4dc0a1e1077e9e7917a54d5cab253058b3f779822aec00f5c817ca826"
+      - name: operator
+        image: quay.io/cilium/operator-generic:v1.12.3
+      - name: other
+        image: docker.io/library/busybox:1.36
+        - image: localhost:5000/cilium
+`
+	want := `      containers:
+      - name: cilium-agent
+        image: "quay.io/cilium/cilium:v1.15.1"
+      - name: operator
+        image: quay.io/cilium/operator-generic:v1.15.1
+      - name: other
+        image: docker.io/library/busybox:1.36
+        - image: localhost:5000/cilium:v1.15.1
+`
+	got := string(setImageTag([]byte(manifest), []string{"cilium", "operator-generic"}, ImageTag("1.15.1")))
+	if got != want {
+		t.Errorf("setImageTag() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCustomize(t *testing.T) {
+	manifest := `---
+# comments only
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cilium-config
+  namespace: kube-system
+data:
+  kube-proxy-replacement: "probe"
+  tunnel: vxlan
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: cilium
+  namespace: kube-system
+spec:
+  template:
+    spec:
+      containers:
+      - name: cilium-agent
+        image: quay.io/cilium/cilium:v1.12.3
+        env:
+        - name: A
+          value: "1"
+      - name: sidecar
+        image: busybox
+---
+apiVersion: cilium.io/v2
+kind: CiliumClusterwideNetworkPolicy
+metadata:
+  name: policy
+spec:
+  endpointSelector: {}
+`
+	overlay := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cilium-config
+data:
+  kube-proxy-replacement: "strict"
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: cilium
+  namespace: kube-system
+spec:
+  template:
+    spec:
+      containers:
+      - name: cilium-agent
+        env:
+        - name: B
+          value: "2"
+---
+apiVersion: cilium.io/v2
+kind: CiliumClusterwideNetworkPolicy
+metadata:
+  name: policy
+spec:
+  description: patched
+`
+	dir := t.TempDir()
+	overlayPath := filepath.Join(dir, "overlay.yaml")
+	if err := os.WriteFile(overlayPath, []byte(overlay), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cc := config.ClusterConfig{KubernetesConfig: config.KubernetesConfig{CNIVersion: "v1.15.1", CNIOverlays: []string{overlayPath}}}
+	b, err := customize(cc, []byte(manifest), "cilium")
+	if err != nil {
+		t.Fatalf("customize: %v", err)
+	}
+	got := string(b)
+	for _, want := range []string{
+		"kube-proxy-replacement: strict",
+		"tunnel: vxlan",
+		"image: quay.io/cilium/cilium:v1.15.1",
+		"name: A",
+		"name: B",
+		"name: sidecar",
+		"description: patched",
+		"endpointSelector: {}",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("customized manifest does not contain %q:\n%s", want, got)
+		}
+	}
+
+	if err := os.WriteFile(overlayPath, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: missing\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := customize(cc, []byte(manifest)); err == nil {
+		t.Errorf("customize with an overlay matching no object succeeded")
+	}
+}
+
+func TestHelmArgs(t *testing.T) {
+	c := Helm{
+		cc: config.ClusterConfig{KubernetesConfig: config.KubernetesConfig{
+			KubernetesVersion: "v1.29.0",
+			CNIVersion:        "1.15.1",
+			CNIValues:         []string{"/tmp/a.yaml", "/tmp/b.yaml"},
+		}},
+		chart: "cilium/cilium",
+	}
+	want := "template cni cilium/cilium --namespace kube-system --kube-version v1.29.0 --version 1.15.1 --values /tmp/a.yaml --values /tmp/b.yaml"
+	if got := strings.Join(c.args(), " "); got != want {
+		t.Errorf("args() = %q, want %q", got, want)
+	}
+}
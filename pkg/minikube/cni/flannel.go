@@ -73,7 +73,11 @@ func (c Flannel) manifest() (assets.CopyableFile, error) {
 	if err := flannelTmpl.Execute(&b, input); err != nil {
 		return nil, err
 	}
-	return manifestAsset(b.Bytes()), nil
+	m, err := customize(c.cc, b.Bytes(), "flannel")
+	if err != nil {
+		return nil, err
+	}
+	return manifestAsset(m), nil
 }
 
 // CIDR returns the default CIDR used by this CNI
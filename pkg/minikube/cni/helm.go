@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cni
+
+import (
+	"os/exec"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+// HelmPrefix prefixes the chart of a helm CNI in the --cni flag
+const HelmPrefix = "helm:"
+
+// Helm is a CNI manager applying the manifest rendered from a user-specified Helm chart, with the helm of the host
+type Helm struct {
+	cc    config.ClusterConfig
+	chart string
+}
+
+// NewHelm returns a well-formed Helm CNI manager
+func NewHelm(cc config.ClusterConfig, chart string) (Helm, error) {
+	if _, err := exec.LookPath("helm"); err != nil {
+		return Helm{}, errors.Wrap(err, "helm is needed to render the chart of the CNI")
+	}
+	return Helm{cc: cc, chart: chart}, nil
+}
+
+// String returns a string representation of this CNI
+func (c Helm) String() string {
+	return HelmPrefix + c.chart
+}
+
+// args returns the arguments of the helm command rendering the chart
+func (c Helm) args() []string {
+	args := []string{"template", "cni", c.chart, "--namespace", "kube-system", "--kube-version", c.cc.KubernetesConfig.KubernetesVersion}
+	if v := c.cc.KubernetesConfig.CNIVersion; v != "" {
+		args = append(args, "--version", v)
+	}
+	for _, values := range c.cc.KubernetesConfig.CNIValues {
+		args = append(args, "--values", values)
+	}
+	return args
+}
+
+// Apply enables the CNI
+func (c Helm) Apply(r Runner) error {
+	cmd := exec.Command("helm", c.args()...)
+	klog.Infof("rendering the CNI chart: %s", cmd.Args)
+	m, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return errors.Wrapf(err, "helm template: %s", exitErr.Stderr)
+		}
+		return errors.Wrap(err, "helm template")
+	}
+	m, err = customize(c.cc, m)
+	if err != nil {
+		return errors.Wrap(err, "manifest")
+	}
+	return applyManifest(c.cc, r, manifestAsset(m))
+}
+
+// CIDR returns the default CIDR used by this CNI
+func (c Helm) CIDR() string {
+	return DefaultPodCIDR
+}
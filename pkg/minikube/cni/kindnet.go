@@ -113,7 +113,7 @@ spec:
             fieldRef:
               fieldPath: status.podIP
         - name: POD_SUBNET
-          value: {{.PodCIDR}}
+          value: {{if .PodCIDRv6}}"{{if .PodCIDR}}{{.PodCIDR}},{{end}}{{.PodCIDRv6}}"{{else}}{{.PodCIDR}}{{end}}
         volumeMounts:
         - name: cni-cfg
           mountPath: /etc/cni/net.d
@@ -164,10 +164,10 @@ func (c KindNet) String() string {
 func (c KindNet) manifest() (assets.CopyableFile, error) {
 	input := &tmplInput{
 		DefaultRoute: "0.0.0.0/0", // assumes IPv4
-		PodCIDR:      DefaultPodCIDR,
 		ImageName:    images.KindNet(c.cc.KubernetesConfig.ImageRepository),
 		CNIConfDir:   DefaultConfDir,
 	}
+	input.PodCIDR, input.PodCIDRv6 = familyPodCIDRs(c.cc)
 
 	b := bytes.Buffer{}
 	if err := kindNetManifest.Execute(&b, input); err != nil {
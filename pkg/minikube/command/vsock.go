@@ -0,0 +1,188 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// VsockPort is the vsock port the command agent of the guest listens on
+const VsockPort = 1024
+
+// The vsock protocol runs one command per connection. The client sends a request frame,
+// then the stdin of the command as stdin frames ended by an empty one. The agent sends
+// the output of the command as stdout and stderr frames, and its exit code in an exit frame.
+// A frame is a stream byte and the big endian uint32 length of the payload, then the payload.
+const (
+	vsockRequest byte = iota
+	vsockStdin
+	vsockStdout
+	vsockStderr
+	vsockExit
+)
+
+// maxFrameSize bounds the payload of a frame, larger writes are split
+const maxFrameSize = 32 * 1024
+
+// vsockCmd is the payload of the request frame
+type vsockCmd struct {
+	// Cmd is the command line, run by bash
+	Cmd string `json:"cmd"`
+	// Env is added to the environment of the agent
+	Env []string `json:"env,omitempty"`
+}
+
+func writeFrame(w io.Writer, stream byte, payload []byte) error {
+	hdr := make([]byte, 5)
+	hdr[0] = stream
+	binary.BigEndian.PutUint32(hdr[1:], uint32(len(payload)))
+	if _, err := w.Write(append(hdr, payload...)); err != nil {
+		return errors.Wrap(err, "write frame")
+	}
+	return nil
+}
+
+func readFrame(r io.Reader) (byte, []byte, error) {
+	hdr := make([]byte, 5)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return 0, nil, err
+	}
+	n := binary.BigEndian.Uint32(hdr[1:])
+	if n > maxFrameSize {
+		return 0, nil, fmt.Errorf("frame of %d bytes exceeds the maximum of %d", n, maxFrameSize)
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, errors.Wrap(err, "read frame")
+	}
+	return hdr[0], payload, nil
+}
+
+// frameWriter writes to a stream of a connection shared by concurrent writers
+type frameWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	stream byte
+}
+
+func (f *frameWriter) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := 0; i < len(p); i += maxFrameSize {
+		end := i + maxFrameSize
+		if end > len(p) {
+			end = len(p)
+		}
+		// an empty frame ends the stream
+		if end == i {
+			break
+		}
+		if err := writeFrame(f.w, f.stream, p[i:end]); err != nil {
+			return i, err
+		}
+	}
+	return len(p), nil
+}
+
+// copyStdin sends r as the stdin of the command, and ends it
+func copyStdin(w io.Writer, r io.Reader) error {
+	if r != nil {
+		if _, err := io.CopyBuffer(&frameWriter{mu: &sync.Mutex{}, w: w, stream: vsockStdin}, r, make([]byte, maxFrameSize)); err != nil {
+			return errors.Wrap(err, "send stdin")
+		}
+	}
+	return writeFrame(w, vsockStdin, nil)
+}
+
+// ServeVsock runs the command requested on a connection of the command agent of the guest,
+// streaming its output back, and closes the connection once it exits.
+func ServeVsock(conn io.ReadWriteCloser) error {
+	defer conn.Close()
+
+	stream, payload, err := readFrame(conn)
+	if err != nil {
+		return errors.Wrap(err, "read request")
+	}
+	if stream != vsockRequest {
+		return fmt.Errorf("unexpected frame %d, want a request", stream)
+	}
+	var req vsockCmd
+	if err := json.Unmarshal(payload, &req); err != nil {
+		return errors.Wrap(err, "unmarshal request")
+	}
+
+	var mu sync.Mutex
+	cmd := exec.Command("/bin/bash", "-c", req.Cmd)
+	cmd.Env = append(os.Environ(), req.Env...)
+	cmd.Stdout = &frameWriter{mu: &mu, w: conn, stream: vsockStdout}
+	cmd.Stderr = &frameWriter{mu: &mu, w: conn, stream: vsockStderr}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return errors.Wrap(err, "stdin pipe")
+	}
+
+	code := 0
+	if err := cmd.Start(); err != nil {
+		fmt.Fprintf(cmd.Stderr, "%v\n", err)
+		code = 127
+	} else {
+		go func() {
+			for {
+				stream, payload, err := readFrame(conn)
+				if err != nil {
+					// the client went away, or the command exited and the connection is closed
+					if err := cmd.Process.Kill(); err != nil && err != os.ErrProcessDone {
+						klog.Warningf("kill %q: %v", req.Cmd, err)
+					}
+					return
+				}
+				if stream != vsockStdin {
+					continue
+				}
+				if len(payload) == 0 {
+					stdin.Close()
+					continue
+				}
+				if _, err := stdin.Write(payload); err != nil {
+					// the command does not read its stdin
+					continue
+				}
+			}
+		}()
+		if err := cmd.Wait(); err != nil {
+			code = -1
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				code = exitErr.ExitCode()
+			}
+		}
+	}
+
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(int32(code)))
+	mu.Lock()
+	defer mu.Unlock()
+	return writeFrame(conn, vsockExit, b)
+}
@@ -0,0 +1,400 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/util/retry"
+)
+
+// VsockRunner runs commands through the command agent of the guest, over vsock.
+//
+// It implements the CommandRunner interface.
+type VsockRunner struct {
+	dial func() (net.Conn, error)
+	s    *vsockSession
+}
+
+// NewVsockRunner returns a new VsockRunner that will run commands
+// through the connections to the command agent returned by dial.
+func NewVsockRunner(dial func() (net.Conn, error)) *VsockRunner {
+	return &VsockRunner{dial: dial}
+}
+
+// vsockSession is a command running on the command agent
+type vsockSession struct {
+	conn net.Conn
+	done chan error
+	code int
+}
+
+// start runs a command line on the agent, streaming its stdin and output
+func (v *VsockRunner) start(cmd string, env []string, stdin io.Reader, stdout, stderr io.Writer) (*vsockSession, error) {
+	var conn net.Conn
+	dial := func() (err error) {
+		conn, err = v.dial()
+		return err
+	}
+	if err := retry.Expo(dial, 250*time.Millisecond, 2*time.Second); err != nil {
+		return nil, errors.Wrap(err, "dial")
+	}
+
+	req, err := json.Marshal(vsockCmd{Cmd: cmd, Env: env})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := writeFrame(conn, vsockRequest, req); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	s := &vsockSession{conn: conn, done: make(chan error, 1)}
+	go func() {
+		if err := copyStdin(conn, stdin); err != nil {
+			klog.Warningf("%s: %v", cmd, err)
+		}
+	}()
+	go func() {
+		for {
+			stream, payload, err := readFrame(conn)
+			if err != nil {
+				s.done <- errors.Wrap(err, "read output")
+				return
+			}
+			switch stream {
+			case vsockStdout:
+				_, err = stdout.Write(payload)
+			case vsockStderr:
+				_, err = stderr.Write(payload)
+			case vsockExit:
+				if len(payload) != 4 {
+					s.done <- fmt.Errorf("invalid exit frame of %d bytes", len(payload))
+					return
+				}
+				s.code = int(int32(binary.BigEndian.Uint32(payload)))
+				s.done <- nil
+				return
+			}
+			if err != nil {
+				s.done <- err
+				return
+			}
+		}
+	}()
+	return s, nil
+}
+
+// wait waits for the command to exit, and returns an error if it failed
+func (s *vsockSession) wait() error {
+	err := <-s.done
+	if cerr := s.conn.Close(); cerr != nil {
+		klog.Warningf("close vsock connection: %v", cerr)
+	}
+	if err != nil {
+		return err
+	}
+	if s.code != 0 {
+		return fmt.Errorf("Process exited with status %d", s.code)
+	}
+	return nil
+}
+
+// vsockOutputs returns the writers of the output of cmd, which also fill rr
+func vsockOutputs(cmd *exec.Cmd, rr *RunResult) (io.Writer, io.Writer) {
+	outb := io.Writer(&rr.Stdout)
+	if cmd.Stdout != nil {
+		outb = io.MultiWriter(cmd.Stdout, &rr.Stdout)
+	}
+	errb := io.Writer(&rr.Stderr)
+	if cmd.Stderr != nil {
+		errb = io.MultiWriter(cmd.Stderr, &rr.Stderr)
+	}
+	return outb, errb
+}
+
+// RunCmd implements the Command Runner interface to run a exec.Cmd object
+func (v *VsockRunner) RunCmd(cmd *exec.Cmd) (*RunResult, error) {
+	rr := &RunResult{Args: cmd.Args}
+	klog.Infof("Run: %v", rr.Command())
+	start := time.Now()
+
+	outb, errb := vsockOutputs(cmd, rr)
+	s, err := v.start(shellquote.Join(cmd.Args...), cmd.Env, cmd.Stdin, outb, errb)
+	if err != nil {
+		return rr, errors.Wrap(err, "vsock session")
+	}
+	err = s.wait()
+	rr.ExitCode = s.code
+	elapsed := time.Since(start)
+
+	// Decrease log spam
+	if elapsed > (1 * time.Second) {
+		klog.Infof("Completed: %s: (%s)", rr.Command(), elapsed)
+	}
+	if err == nil {
+		return rr, nil
+	}
+
+	return rr, fmt.Errorf("%s: %v\nstdout:\n%s\nstderr:\n%s", rr.Command(), err, rr.Stdout.String(), rr.Stderr.String())
+}
+
+// StartCmd implements the Command Runner interface to start a exec.Cmd object
+func (v *VsockRunner) StartCmd(cmd *exec.Cmd) (*StartedCmd, error) {
+	if v.s != nil {
+		return nil, fmt.Errorf("another vsock command has been started and is currently running")
+	}
+
+	rr := &RunResult{Args: cmd.Args}
+	sc := &StartedCmd{cmd: cmd, rr: rr}
+	klog.Infof("Start: %v", rr.Command())
+
+	outb, errb := vsockOutputs(cmd, rr)
+	s, err := v.start(shellquote.Join(cmd.Args...), cmd.Env, cmd.Stdin, outb, errb)
+	if err != nil {
+		return sc, errors.Wrap(err, "vsock session")
+	}
+	v.s = s
+	return sc, nil
+}
+
+// WaitCmd implements the Command Runner interface to wait until a started exec.Cmd object finishes
+func (v *VsockRunner) WaitCmd(sc *StartedCmd) (*RunResult, error) {
+	if v.s == nil {
+		return nil, fmt.Errorf("there is no vsock command started")
+	}
+
+	rr := sc.rr
+	err := v.s.wait()
+	rr.ExitCode = v.s.code
+	v.s = nil
+
+	if err == nil {
+		return rr, nil
+	}
+
+	return rr, fmt.Errorf("%s: %v\nstdout:\n%s\nstderr:\n%s", rr.Command(), err, rr.Stdout.String(), rr.Stderr.String())
+}
+
+// Copy copies a file to the guest, streaming it as the stdin of cat.
+func (v *VsockRunner) Copy(f assets.CopyableFile) error {
+	dst := path.Join(f.GetTargetDir(), f.GetTargetName())
+
+	// For small files, don't bother risking being wrong for no performance benefit
+	if f.GetLength() > 2048 {
+		exists, err := fileExists(v, f, dst)
+		if err != nil {
+			klog.Infof("existence check for %s: %v", dst, err)
+		}
+
+		if exists {
+			klog.Infof("copy: skipping %s (exists)", dst)
+			return nil
+		}
+	}
+
+	klog.Infof("vsock %s --> %s (%d bytes)", f.GetSourcePath(), dst, f.GetLength())
+	if f.GetLength() == 0 {
+		klog.Warningf("0 byte asset: %+v", f)
+	}
+
+	script := fmt.Sprintf("mkdir -p %s && cat > %s && chmod %s %s",
+		shellquote.Join(f.GetTargetDir()), shellquote.Join(dst), f.GetPermissions(), shellquote.Join(dst))
+	mtime, err := f.GetModTime()
+	if err != nil {
+		klog.Infof("error getting modtime for %s: %v", dst, err)
+	} else if mtime != (time.Time{}) {
+		script += fmt.Sprintf(" && touch -d \"%s\" %s", mtime.Format(layout), shellquote.Join(dst))
+	}
+	cmd := exec.Command("sudo", "/bin/bash", "-c", script)
+	cmd.Stdin = f
+	_, err = v.RunCmd(cmd)
+	return err
+}
+
+// CopyFrom copies a file from the guest, streaming it as the stdout of cat.
+func (v *VsockRunner) CopyFrom(f assets.CopyableFile) error {
+	src := f.GetTargetPath()
+
+	cmd := exec.Command("sudo", "stat", "-c", "%s", src)
+	rr, err := v.RunCmd(cmd)
+	if err != nil {
+		return fmt.Errorf("%s: %v", cmd, err)
+	}
+	length, err := strconv.Atoi(strings.TrimSuffix(rr.Stdout.String(), "\n"))
+	if err != nil {
+		return err
+	}
+	klog.Infof("vsock %s --> %s (%d bytes)", src, f.GetSourcePath(), length)
+	f.SetLength(length)
+
+	// do not keep the content of the file in the RunResult
+	s, err := v.start(shellquote.Join("sudo", "cat", src), nil, nil, f, io.Discard)
+	if err != nil {
+		return errors.Wrap(err, "vsock session")
+	}
+	return s.wait()
+}
+
+// Remove runs a command to delete a file on the guest.
+func (v *VsockRunner) Remove(f assets.CopyableFile) error {
+	dst := path.Join(f.GetTargetDir(), f.GetTargetName())
+	klog.Infof("rm: %s", dst)
+
+	_, err := v.RunCmd(exec.Command("sudo", "rm", dst))
+	return err
+}
+
+// ReadableFile returns assets.ReadableFile for the sourcePath (via `stat` command)
+func (v *VsockRunner) ReadableFile(sourcePath string) (assets.ReadableFile, error) {
+	klog.V(4).Infof("NewVsockReadableFile: %s", sourcePath)
+
+	if !strings.HasPrefix(sourcePath, "/") {
+		return nil, fmt.Errorf("sourcePath must be an absolute Path. Relative Path is not allowed")
+	}
+
+	// get file size and modtime of the destination
+	rr, err := v.RunCmd(exec.Command("stat", "-c", "%#a %s %y", sourcePath))
+	if err != nil {
+		return nil, err
+	}
+
+	stdout := strings.TrimSpace(rr.Stdout.String())
+	outputs := strings.SplitN(stdout, " ", 3)
+
+	permission := outputs[0]
+	size, err := strconv.Atoi(outputs[1])
+	if err != nil {
+		return nil, err
+	}
+
+	modTime, err := time.Parse(layout, outputs[2])
+	if err != nil {
+		return nil, err
+	}
+
+	r, w := io.Pipe()
+	s, err := v.start(shellquote.Join("cat", sourcePath), nil, nil, w, io.Discard)
+	if err != nil {
+		return nil, errors.Wrap(err, "vsock session")
+	}
+	go func() {
+		w.CloseWithError(s.wait())
+	}()
+
+	return &vsockReadableFile{
+		length:      size,
+		sourcePath:  sourcePath,
+		permissions: permission,
+		reader:      r,
+		modTime:     modTime,
+	}, nil
+}
+
+type vsockReadableFile struct {
+	length      int
+	sourcePath  string
+	permissions string
+	modTime     time.Time
+	reader      *io.PipeReader
+}
+
+// GetLength returns length of file
+func (f *vsockReadableFile) GetLength() int {
+	return f.length
+}
+
+// GetSourcePath returns asset name
+func (f *vsockReadableFile) GetSourcePath() string {
+	return f.sourcePath
+}
+
+// GetPermissions returns permissions
+func (f *vsockReadableFile) GetPermissions() string {
+	return f.permissions
+}
+
+func (f *vsockReadableFile) GetModTime() (time.Time, error) {
+	return f.modTime, nil
+}
+
+func (f *vsockReadableFile) Read(p []byte) (int, error) {
+	if f.GetLength() == 0 {
+		return 0, fmt.Errorf("attempted read from a 0 length asset")
+	}
+	return f.reader.Read(p)
+}
+
+func (f *vsockReadableFile) Seek(_ int64, _ int) (int64, error) {
+	return 0, fmt.Errorf("Seek is not implemented for vsockReadableFile")
+}
+
+// Close stops reading the file, which ends the cat command
+func (f *vsockReadableFile) Close() error {
+	return f.reader.Close()
+}
+
+// DialHybridVsock connects to a port of the guest through the unix socket of a hybrid vsock device,
+// as exposed by firecracker and cloud-hypervisor: the host sends "CONNECT <port>\n" and the VMM answers "OK <host port>\n".
+func DialHybridVsock(socket string, port int) (net.Conn, error) {
+	conn, err := net.DialTimeout("unix", socket, 5*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %d\n", port); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "connect")
+	}
+	// read the answer byte by byte, the stream of the guest follows it
+	var line bytes.Buffer
+	b := make([]byte, 1)
+	for !bytes.HasSuffix(line.Bytes(), []byte("\n")) {
+		if err := conn.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		if _, err := conn.Read(b); err != nil {
+			conn.Close()
+			return nil, errors.Wrapf(err, "connect to vsock port %d", port)
+		}
+		line.Write(b)
+	}
+	if !strings.HasPrefix(line.String(), "OK ") {
+		conn.Close()
+		return nil, fmt.Errorf("connect to vsock port %d: %s", port, strings.TrimSpace(line.String()))
+	}
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
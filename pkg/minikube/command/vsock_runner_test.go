@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// pipeDialer serves each connection with ServeVsock, as the agent of the guest does
+func pipeDialer(t *testing.T) func() (net.Conn, error) {
+	return func() (net.Conn, error) {
+		client, server := net.Pipe()
+		go func() {
+			if err := ServeVsock(server); err != nil {
+				t.Errorf("ServeVsock: %v", err)
+			}
+		}()
+		return client, nil
+	}
+}
+
+func TestVsockRunner(t *testing.T) {
+	r := NewVsockRunner(pipeDialer(t))
+
+	rr, err := r.RunCmd(exec.Command("/bin/sh", "-c", "echo out; echo err >&2"))
+	if err != nil {
+		t.Fatalf("RunCmd: %v", err)
+	}
+	if rr.Stdout.String() != "out\n" || rr.Stderr.String() != "err\n" {
+		t.Errorf("RunCmd output = %q, %q, want \"out\\n\", \"err\\n\"", rr.Stdout.String(), rr.Stderr.String())
+	}
+
+	rr, err = r.RunCmd(exec.Command("/bin/sh", "-c", "exit 3"))
+	if err == nil {
+		t.Errorf("RunCmd succeeded for a failing command")
+	}
+	if rr.ExitCode != 3 {
+		t.Errorf("ExitCode = %d, want 3", rr.ExitCode)
+	}
+
+	// larger than a frame
+	in := strings.Repeat("minikube\n", 10000)
+	cmd := exec.Command("cat")
+	cmd.Stdin = strings.NewReader(in)
+	rr, err = r.RunCmd(cmd)
+	if err != nil {
+		t.Fatalf("RunCmd with stdin: %v", err)
+	}
+	if rr.Stdout.String() != in {
+		t.Errorf("RunCmd with stdin returned %d bytes, want %d", rr.Stdout.Len(), len(in))
+	}
+
+	sc, err := r.StartCmd(exec.Command("echo", "started"))
+	if err != nil {
+		t.Fatalf("StartCmd: %v", err)
+	}
+	rr, err = r.WaitCmd(sc)
+	if err != nil {
+		t.Fatalf("WaitCmd: %v", err)
+	}
+	if rr.Stdout.String() != "started\n" {
+		t.Errorf("WaitCmd output = %q, want \"started\\n\"", rr.Stdout.String())
+	}
+}
+
+func TestVsockRunnerReadableFile(t *testing.T) {
+	r := NewVsockRunner(pipeDialer(t))
+
+	f, err := r.ReadableFile("/etc/hostname-does-not-exist")
+	if err == nil {
+		f.Close()
+		t.Errorf("ReadableFile succeeded for a missing file")
+	}
+
+	path := filepath.Join(t.TempDir(), "file")
+	if _, err := r.RunCmd(exec.Command("/bin/sh", "-c", "printf minikube > "+path)); err != nil {
+		t.Fatalf("RunCmd: %v", err)
+	}
+	f, err = r.ReadableFile(path)
+	if err != nil {
+		t.Fatalf("ReadableFile: %v", err)
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(b) != "minikube" || f.GetLength() != len("minikube") {
+		t.Errorf("ReadableFile = %q of length %d, want \"minikube\"", b, f.GetLength())
+	}
+}
+
+func TestDialHybridVsock(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "vsock.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			line, _ := bufio.NewReader(conn).ReadString('\n')
+			if line == fmt.Sprintf("CONNECT %d\n", VsockPort) {
+				fmt.Fprint(conn, "OK 1073741824\nhello")
+			} else {
+				conn.Close()
+			}
+		}
+	}()
+
+	conn, err := DialHybridVsock(socket, VsockPort)
+	if err != nil {
+		t.Fatalf("DialHybridVsock: %v", err)
+	}
+	b := make([]byte, 5)
+	if _, err := io.ReadFull(conn, b); err != nil || string(b) != "hello" {
+		t.Errorf("read after the handshake = %q, %v, want \"hello\"", b, err)
+	}
+	conn.Close()
+
+	if _, err := DialHybridVsock(socket, 1); err == nil {
+		t.Errorf("DialHybridVsock succeeded on a port the VMM refused")
+	}
+}
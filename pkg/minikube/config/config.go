@@ -17,6 +17,7 @@ limitations under the License.
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -27,6 +28,7 @@ import (
 
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/util/lock"
 )
 
 const (
@@ -36,18 +38,30 @@ const (
 	WantBetaUpdateNotification = "WantBetaUpdateNotification"
 	// ReminderWaitPeriodInHours is the key for ReminderWaitPeriodInHours
 	ReminderWaitPeriodInHours = "ReminderWaitPeriodInHours"
+	// WantReleaseNotes is the key for WantReleaseNotes
+	WantReleaseNotes = "WantReleaseNotes"
+	// ReleaseFeedURL is the key for ReleaseFeedURL
+	ReleaseFeedURL = "ReleaseFeedURL"
 	// WantNoneDriverWarning is the key for WantNoneDriverWarning
 	WantNoneDriverWarning = "WantNoneDriverWarning"
 	// WantVirtualBoxDriverWarning is the key for WantVirtualBoxDriverWarning
 	WantVirtualBoxDriverWarning = "WantVirtualBoxDriverWarning"
 	// ProfileName represents the key for the global profile parameter
 	ProfileName = "profile"
+	// RemoteHost is the key for the global host flag (ex. --host=ssh://dev-box)
+	RemoteHost = "host"
 	// UserFlag is the key for the global user flag (ex. --user=user1)
 	UserFlag = "user"
 	// SkipAuditFlag is the key for skipping command from aduit
 	SkipAuditFlag = "skip-audit"
 	// Rootless is the key for the global rootless parameter (boolean)
 	Rootless = "rootless"
+	// DownloadParallelism is the key for the global number of concurrent downloads
+	DownloadParallelism = "download-parallelism"
+	// DownloadRateLimit is the key for the global bandwidth limit of the downloads (ex. 10MB)
+	DownloadRateLimit = "download-rate-limit"
+	// CacheQuota is the key for the size the cache is kept under (ex. 20GB)
+	CacheQuota = "cache-quota"
 	// AddonImages stores custom addon images config
 	AddonImages = "addon-images"
 	// AddonRegistries stores custom addon images config
@@ -127,15 +141,13 @@ func get(name string, config MinikubeConfig) (string, error) {
 
 // WriteConfig writes a minikube config to the JSON file
 func WriteConfig(configFile string, m MinikubeConfig) error {
-	f, err := os.Create(configFile)
-	if err != nil {
-		return fmt.Errorf("create %s: %s", configFile, err)
-	}
-	defer f.Close()
-	err = encode(f, m)
-	if err != nil {
+	var b bytes.Buffer
+	if err := encode(&b, m); err != nil {
 		return fmt.Errorf("encode %s: %s", configFile, err)
 	}
+	if err := lock.WriteFile(configFile, b.Bytes(), 0644); err != nil {
+		return fmt.Errorf("write %s: %s", configFile, err)
+	}
 	return nil
 }
 
@@ -227,6 +239,8 @@ func (c *simpleConfigLoader) LoadConfigFromFile(profileName string, miniHome ...
 	if err := json.Unmarshal(data, &cc); err != nil {
 		return nil, errors.Wrap(err, "unmarshal")
 	}
+	// the migrated config is written by the next save of the profile
+	recordMigrations(profileName, Migrate(&cc))
 	return &cc, nil
 }
 
@@ -236,7 +250,7 @@ func (c *simpleConfigLoader) WriteConfigToFile(profileName string, cc *ClusterCo
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, contents, 0644)
+	return lock.WriteFile(path, contents, 0644)
 }
 
 // MultiNode returns true if the cluster has multiple nodes or if the request is asking for multinode
@@ -0,0 +1,202 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/out/register"
+)
+
+// migration translates a setting of the profile configs written by an older minikube version to the current schema
+type migration struct {
+	description string
+	// migrate migrates the config, and returns whether it changed
+	migrate func(cc *ClusterConfig) bool
+}
+
+// migrations are applied in order on load, so a migration must leave an up to date config untouched
+var migrations = []migration{
+	{
+		// before minikube v1.7, the control plane was described by KubernetesConfig
+		description: "the control plane is listed in Nodes",
+		migrate: func(cc *ClusterConfig) bool {
+			for _, n := range cc.Nodes {
+				if n.ControlPlane {
+					return false
+				}
+			}
+			cp := Node{
+				Name:              cc.KubernetesConfig.NodeName,
+				IP:                cc.KubernetesConfig.NodeIP,
+				Port:              cc.KubernetesConfig.NodePort,
+				KubernetesVersion: cc.KubernetesConfig.KubernetesVersion,
+				ContainerRuntime:  cc.KubernetesConfig.ContainerRuntime,
+				ControlPlane:      true,
+				Worker:            true,
+			}
+			if cp.Port == 0 {
+				cp.Port = constants.APIServerPort
+			}
+			cc.Nodes = append([]Node{cp}, cc.Nodes...)
+			cc.KubernetesConfig.NodeName = ""
+			cc.KubernetesConfig.NodeIP = ""
+			return true
+		},
+	},
+	{
+		description: "VMDriver is replaced by Driver",
+		migrate: func(cc *ClusterConfig) bool {
+			if cc.VMDriver == "" {
+				return false
+			}
+			if cc.Driver == "" {
+				cc.Driver = cc.VMDriver
+			}
+			cc.VMDriver = ""
+			return true
+		},
+	},
+	{
+		description: "KubernetesConfig.EnableDefaultCNI is replaced by KubernetesConfig.CNI=bridge",
+		migrate: func(cc *ClusterConfig) bool {
+			if !cc.KubernetesConfig.EnableDefaultCNI {
+				return false
+			}
+			if cc.KubernetesConfig.CNI == "" {
+				cc.KubernetesConfig.CNI = "bridge"
+			}
+			cc.KubernetesConfig.EnableDefaultCNI = false
+			return true
+		},
+	},
+	{
+		// before minikube v1.9.2, the apiserver port was only set on the nodes
+		description: "the apiserver port is set in KubernetesConfig.NodePort",
+		migrate: func(cc *ClusterConfig) bool {
+			if cc.KubernetesConfig.NodePort != 0 {
+				return false
+			}
+			cc.KubernetesConfig.NodePort = constants.APIServerPort
+			for _, n := range cc.Nodes {
+				if n.ControlPlane && n.Port != 0 {
+					cc.KubernetesConfig.NodePort = n.Port
+					break
+				}
+			}
+			return true
+		},
+	},
+	{
+		description: "CertExpiration defaults to 3 years",
+		migrate: func(cc *ClusterConfig) bool {
+			if cc.CertExpiration != 0 {
+				return false
+			}
+			cc.CertExpiration = constants.DefaultCertExpiration
+			return true
+		},
+	},
+}
+
+// Migrate translates a profile config written by an older minikube version to the current schema, and returns the descriptions of the applied migrations
+func Migrate(cc *ClusterConfig) []string {
+	var applied []string
+	for _, m := range migrations {
+		if m.migrate(cc) {
+			applied = append(applied, m.description)
+		}
+	}
+	return applied
+}
+
+// MigrateProfiles translates the configs of the profiles written by older minikube versions to the current schema,
+// and returns the applied migrations by profile. The migrated configs are written unless dryRun.
+func MigrateProfiles(dryRun bool, miniHome ...string) (map[string][]string, error) {
+	dirs, err := profileDirs(miniHome...)
+	if err != nil {
+		return nil, err
+	}
+	migrated := map[string][]string{}
+	for _, name := range dirs {
+		path := profileFilePath(name, miniHome...)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return migrated, errors.Wrapf(err, "read %s", path)
+		}
+		var cc ClusterConfig
+		if err := json.Unmarshal(data, &cc); err != nil {
+			klog.Warningf("skipping the invalid config %s: %v", path, err)
+			continue
+		}
+		applied := Migrate(&cc)
+		if len(applied) == 0 {
+			continue
+		}
+		migrated[name] = applied
+		if dryRun {
+			continue
+		}
+		if err := SaveProfile(name, &cc, miniHome...); err != nil {
+			return migrated, errors.Wrapf(err, "save %s", name)
+		}
+		recordMigrations(name, applied)
+	}
+	return migrated, nil
+}
+
+// deprecatedSettings are the settings of the minikube config replaced by another setting
+var deprecatedSettings = []struct {
+	old string
+	new string
+}{
+	{old: "vm-driver", new: "driver"},
+}
+
+// MigrateSettings translates the deprecated settings of the minikube config, and returns the descriptions of the applied migrations
+func MigrateSettings(m MinikubeConfig) []string {
+	var applied []string
+	for _, s := range deprecatedSettings {
+		v, ok := m[s.old]
+		if !ok {
+			continue
+		}
+		if _, ok := m[s.new]; !ok {
+			m[s.new] = v
+		}
+		delete(m, s.old)
+		applied = append(applied, fmt.Sprintf("%s is replaced by %s", s.old, s.new))
+	}
+	return applied
+}
+
+// recordMigrations logs the migrations applied to the config of a profile, and records them in its event log
+func recordMigrations(profile string, applied []string) {
+	for _, a := range applied {
+		klog.Infof("migrated the config of %q: %s", profile, a)
+		register.RecordMigration(profile, a)
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+func TestMigrate(t *testing.T) {
+	current := ClusterConfig{
+		Driver:           "docker",
+		CertExpiration:   constants.DefaultCertExpiration,
+		KubernetesConfig: KubernetesConfig{NodePort: 8443, CNI: "calico"},
+		Nodes:            []Node{{Port: 8443, ControlPlane: true, Worker: true}},
+	}
+	legacy := ClusterConfig{
+		VMDriver:         "virtualbox",
+		KubernetesConfig: KubernetesConfig{NodeIP: "192.168.59.100", KubernetesVersion: "v1.16.0", EnableDefaultCNI: true},
+	}
+
+	tests := []struct {
+		description string
+		cc          ClusterConfig
+		want        ClusterConfig
+		wantApplied int
+	}{
+		{description: "current config", cc: current, want: current},
+		{
+			description: "legacy config",
+			cc:          legacy,
+			want: ClusterConfig{
+				Driver:           "virtualbox",
+				CertExpiration:   constants.DefaultCertExpiration,
+				KubernetesConfig: KubernetesConfig{KubernetesVersion: "v1.16.0", NodePort: 8443, CNI: "bridge"},
+				Nodes:            []Node{{IP: "192.168.59.100", Port: 8443, KubernetesVersion: "v1.16.0", ControlPlane: true, Worker: true}},
+			},
+			wantApplied: 5,
+		},
+		{
+			description: "apiserver port of the nodes",
+			cc:          ClusterConfig{Driver: "kvm2", CertExpiration: time.Hour, Nodes: []Node{{Port: 9443, ControlPlane: true}}},
+			want:        ClusterConfig{Driver: "kvm2", CertExpiration: time.Hour, KubernetesConfig: KubernetesConfig{NodePort: 9443}, Nodes: []Node{{Port: 9443, ControlPlane: true}}},
+			wantApplied: 1,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			cc := tc.cc
+			applied := Migrate(&cc)
+			if len(applied) != tc.wantApplied {
+				t.Errorf("Migrate() applied %v, want %d migrations", applied, tc.wantApplied)
+			}
+			if diff := cmp.Diff(tc.want, cc); diff != "" {
+				t.Errorf("Migrate() mismatch (-want +got):\n%s", diff)
+			}
+			if again := Migrate(&cc); len(again) != 0 {
+				t.Errorf("Migrate() of a migrated config applied %v", again)
+			}
+		})
+	}
+}
+
+func TestMigrateSettings(t *testing.T) {
+	m := MinikubeConfig{"vm-driver": "hyperkit", "memory": "4g"}
+	if applied := MigrateSettings(m); len(applied) != 1 {
+		t.Errorf("MigrateSettings() applied %v, want 1 migration", applied)
+	}
+	if diff := cmp.Diff(MinikubeConfig{"driver": "hyperkit", "memory": "4g"}, m); diff != "" {
+		t.Errorf("MigrateSettings() mismatch (-want +got):\n%s", diff)
+	}
+
+	// the current setting wins
+	m = MinikubeConfig{"vm-driver": "hyperkit", "driver": "docker"}
+	MigrateSettings(m)
+	if diff := cmp.Diff(MinikubeConfig{"driver": "docker"}, m); diff != "" {
+		t.Errorf("MigrateSettings() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMigrateProfiles(t *testing.T) {
+	miniHome := t.TempDir()
+	profiles := map[string]string{
+		"legacy":  `{"Name": "legacy", "VMDriver": "virtualbox", "KubernetesConfig": {"NodePort": 8443}, "Nodes": [{"Port": 8443, "ControlPlane": true}]}`,
+		"current": `{"Name": "current", "Driver": "docker", "CertExpiration": 94608000000000000, "KubernetesConfig": {"NodePort": 8443}, "Nodes": [{"Port": 8443, "ControlPlane": true}]}`,
+	}
+	for name, data := range profiles {
+		dir := filepath.Join(miniHome, "profiles", name)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		migrated, err := MigrateProfiles(dryRun, miniHome)
+		if err != nil {
+			t.Fatalf("MigrateProfiles(%t): %v", dryRun, err)
+		}
+		if len(migrated) != 1 || len(migrated["legacy"]) != 2 {
+			t.Errorf("MigrateProfiles(%t) = %v, want the 2 migrations of legacy", dryRun, migrated)
+		}
+	}
+	migrated, err := MigrateProfiles(true, miniHome)
+	if err != nil {
+		t.Fatalf("MigrateProfiles: %v", err)
+	}
+	if len(migrated) != 0 {
+		t.Errorf("MigrateProfiles() after a migration = %v, want none", migrated)
+	}
+}
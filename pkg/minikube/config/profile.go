@@ -27,6 +27,7 @@ import (
 	"github.com/spf13/viper"
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/util/lock"
 )
@@ -92,6 +93,26 @@ func ProfileNameValid(name string) bool {
 	return validName.MatchString(name) && len(name) > 1
 }
 
+// ProfilePrefix returns the prefix of the profiles of the current user in a system-wide install,
+// so that the containers, VMs and networks of different users do not collide. It is empty otherwise.
+func ProfilePrefix() string {
+	if !localpath.SystemWide() {
+		return ""
+	}
+	prefix := strings.Trim(regexp.MustCompile(`[^a-z0-9-]+`).ReplaceAllString(strings.ToLower(localpath.SystemUser()), "-"), "-")
+	return prefix + "-"
+}
+
+// DefaultProfileName returns the name of the profile used when none is given
+func DefaultProfileName() string {
+	return ProfilePrefix() + constants.DefaultClusterName
+}
+
+// ProfileNameScoped checks if the profile name belongs to the current user in a system-wide install
+func ProfileNameScoped(name string) bool {
+	return strings.HasPrefix(name, ProfilePrefix())
+}
+
 // ProfileNameInReservedKeywords checks if the profile is an internal keywords
 func ProfileNameInReservedKeywords(name string) bool {
 	for _, v := range keywords {
@@ -150,30 +171,7 @@ func SaveProfile(name string, cfg *ClusterConfig, miniHome ...string) error {
 		return err
 	}
 
-	// If no config file exists, don't worry about swapping paths
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return lock.WriteFile(path, data, 0600)
-	}
-
-	tf, err := os.CreateTemp(filepath.Dir(path), "config.json.tmp")
-	if err != nil {
-		return err
-	}
-	defer os.Remove(tf.Name())
-
-	if err = os.WriteFile(tf.Name(), data, 0600); err != nil {
-		return err
-	}
-
-	if err = tf.Close(); err != nil {
-		return err
-	}
-
-	if err = os.Remove(path); err != nil {
-		return err
-	}
-
-	return os.Rename(tf.Name(), path)
+	return lock.WriteFile(path, data, 0600)
 }
 
 // DeleteProfile deletes a profile and removes the profile dir
@@ -328,11 +326,170 @@ func ProfileFolderPath(profile string, miniHome ...string) string {
 	return filepath.Join(miniPath, "profiles", profile)
 }
 
+// IsPrimaryControlPlane returns whether n is the first created control plane of the cluster
+func IsPrimaryControlPlane(cc ClusterConfig, n Node) bool {
+	for _, cn := range cc.Nodes {
+		if cn.ControlPlane {
+			return cn.Name == n.Name
+		}
+	}
+	return n.ControlPlane
+}
+
+// ControlPlanes returns the control plane nodes of the cluster, in the order of the config
+func ControlPlanes(cc ClusterConfig) []Node {
+	var cps []Node
+	for _, n := range cc.Nodes {
+		if n.ControlPlane {
+			cps = append(cps, n)
+		}
+	}
+	return cps
+}
+
+// IsHA returns whether the cluster has multiple control planes, or a virtual IP which control planes can be added behind
+func IsHA(cc ClusterConfig) bool {
+	return cc.KubernetesConfig.APIServerHAVIP != "" || len(ControlPlanes(cc)) > 1
+}
+
+// NodePoolByName returns the node pool of the cluster with the name, or nil
+func NodePoolByName(cc ClusterConfig, name string) *NodePool {
+	for i := range cc.NodePools {
+		if cc.NodePools[i].Name == name {
+			return &cc.NodePools[i]
+		}
+	}
+	return nil
+}
+
+// WithNodePool returns the config of the cluster with the resources of the node pool of n, to create its machine
+func WithNodePool(cc ClusterConfig, n Node) ClusterConfig {
+	p := NodePoolByName(cc, n.Pool)
+	if p == nil {
+		return cc
+	}
+	if p.CPUs != 0 {
+		cc.CPUs = p.CPUs
+	}
+	if p.Memory != 0 {
+		cc.Memory = p.Memory
+	}
+	return cc
+}
+
+// NodeDriver returns the driver running the machine of the node
+func NodeDriver(cc ClusterConfig, n Node) string {
+	if n.Driver != "" {
+		return n.Driver
+	}
+	return cc.Driver
+}
+
+// IP families of the clusters
+const (
+	IPv4Family = "ipv4"
+	IPv6Family = "ipv6"
+	DualStack  = "dual"
+)
+
+// HasIPv6 returns whether the cluster runs on IPv6, single-stack or dual-stack
+func HasIPv6(cc ClusterConfig) bool {
+	f := cc.KubernetesConfig.IPFamily
+	return f == IPv6Family || f == DualStack
+}
+
+// NodeIPs returns the addresses of the node in the IP families of the cluster, the primary family first
+func NodeIPs(cc ClusterConfig, n Node) []string {
+	switch cc.KubernetesConfig.IPFamily {
+	case IPv6Family:
+		return []string{n.IPv6}
+	case DualStack:
+		return []string{n.IP, n.IPv6}
+	default:
+		return []string{n.IP}
+	}
+}
+
+// WindowsOS is the operating system of the experimental Windows worker nodes
+const WindowsOS = "windows"
+
+// IsWindows returns whether the node is an experimental Windows worker, whose VM is not managed by libmachine
+func IsWindows(n Node) bool {
+	return n.OS == WindowsOS
+}
+
+// IsHybrid returns whether some nodes of the cluster run on another driver than the cluster
+func IsHybrid(cc ClusterConfig) bool {
+	for _, n := range cc.Nodes {
+		if NodeDriver(cc, n) != cc.Driver {
+			return true
+		}
+	}
+	return false
+}
+
+// TopologyLabels returns the zone and region labels of the node, empty if the cluster has no topology
+func TopologyLabels(cc ClusterConfig, n Node) []string {
+	var labels []string
+	if zone := topologyValue(cc, n, cc.Zones); zone != "" {
+		labels = append(labels, "topology.kubernetes.io/zone="+zone)
+	}
+	if region := topologyValue(cc, n, cc.Regions); region != "" {
+		labels = append(labels, "topology.kubernetes.io/region="+region)
+	}
+	return labels
+}
+
+// topologyValue returns the value of the node: the one of a NODE=VALUE entry naming it, or else the round-robin
+// of the other entries by the position of the node, the nodes being named like in 'minikube node list' or m01, m02, ...
+func topologyValue(cc ClusterConfig, n Node, values []string) string {
+	index := len(cc.Nodes)
+	for i, cn := range cc.Nodes {
+		if cn.Name == n.Name {
+			index = i
+			break
+		}
+	}
+	names := []string{MachineName(cc, n), fmt.Sprintf("m%02d", index+1)}
+	if n.Name != "" {
+		names = append(names, n.Name)
+	}
+	var roundRobin []string
+	for _, v := range values {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok {
+			roundRobin = append(roundRobin, v)
+			continue
+		}
+		for _, nn := range names {
+			if name == nn {
+				return value
+			}
+		}
+	}
+	if len(roundRobin) == 0 {
+		return ""
+	}
+	return roundRobin[index%len(roundRobin)]
+}
+
 // MachineName returns the name of the machine, as seen by the hypervisor given the cluster and node names
 func MachineName(cc ClusterConfig, n Node) string {
 	// For single node cluster, default to back to old naming
-	if (len(cc.Nodes) == 1 && cc.Nodes[0].Name == n.Name) || n.ControlPlane {
+	if (len(cc.Nodes) == 1 && cc.Nodes[0].Name == n.Name) || (n.ControlPlane && IsPrimaryControlPlane(cc, n)) {
 		return cc.Name
 	}
 	return fmt.Sprintf("%s-%s", cc.Name, n.Name)
 }
+
+// VirtiofsMount returns the host and guest directories of the mount string of the cluster if it is shared through virtiofs, empty otherwise
+func VirtiofsMount(cc ClusterConfig) (string, string) {
+	if !cc.Mount || cc.MountType != constants.VirtiofsMountType {
+		return "", ""
+	}
+	i := strings.LastIndex(cc.MountString, ":")
+	if i == -1 {
+		return "", ""
+	}
+	return cc.MountString[:i], cc.MountString[i+1:]
+}
@@ -19,9 +19,12 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/spf13/viper"
+	"k8s.io/minikube/pkg/minikube/localpath"
 )
 
 // TestListProfiles uses a different MINIKUBE_HOME with rest of tests since it relies on file list index
@@ -119,6 +122,28 @@ func TestProfileNameValid(t *testing.T) {
 	}
 }
 
+func TestProfilePrefix(t *testing.T) {
+	t.Setenv(localpath.SystemHomeEnv, "")
+	if p := ProfilePrefix(); p != "" {
+		t.Errorf("ProfilePrefix() = %q without a system-wide install, want none", p)
+	}
+	if !ProfileNameScoped("anything") {
+		t.Errorf("ProfileNameScoped() = false without a system-wide install")
+	}
+
+	t.Setenv(localpath.SystemHomeEnv, t.TempDir())
+	name := DefaultProfileName()
+	if !ProfileNameValid(name) {
+		t.Errorf("DefaultProfileName() = %q, which is not a valid profile name", name)
+	}
+	if !ProfileNameScoped(name) || !ProfileNameScoped(ProfilePrefix()+"dev") {
+		t.Errorf("ProfileNameScoped() = false for the profiles of the user")
+	}
+	if ProfileNameScoped("x-minikube") {
+		t.Errorf("ProfileNameScoped() = true for the profile of another user")
+	}
+}
+
 func TestProfileNameInReservedKeywords(t *testing.T) {
 	var testCases = []struct {
 		name     string
@@ -337,3 +362,185 @@ func TestGetPrimaryControlPlane(t *testing.T) {
 		})
 	}
 }
+
+func TestIsHA(t *testing.T) {
+	cp := Node{Name: "", ControlPlane: true, Worker: true}
+	secondary := Node{Name: "m02", ControlPlane: true, Worker: true}
+	worker := Node{Name: "m03", Worker: true}
+
+	var tests = []struct {
+		description string
+		cc          ClusterConfig
+		cps         int
+		ha          bool
+	}{
+		{"single control plane", ClusterConfig{Nodes: []Node{cp, worker}}, 1, false},
+		{"multiple control planes", ClusterConfig{Nodes: []Node{cp, secondary, worker}}, 2, true},
+		{"virtual ip before the secondaries are added", ClusterConfig{Nodes: []Node{cp}, KubernetesConfig: KubernetesConfig{APIServerHAVIP: "192.168.49.254"}}, 1, true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := len(ControlPlanes(tc.cc)); got != tc.cps {
+				t.Errorf("ControlPlanes() returned %d nodes, expected %d", got, tc.cps)
+			}
+			if got := IsHA(tc.cc); got != tc.ha {
+				t.Errorf("IsHA() = %t, expected %t", got, tc.ha)
+			}
+		})
+	}
+}
+
+func TestMachineNameControlPlanes(t *testing.T) {
+	cc := ClusterConfig{
+		Name: "ha",
+		Nodes: []Node{
+			{Name: "", ControlPlane: true},
+			{Name: "m02", ControlPlane: true},
+			{Name: "m03", ControlPlane: false},
+		},
+	}
+	want := []string{"ha", "ha-m02", "ha-m03"}
+	for i, n := range cc.Nodes {
+		if got := MachineName(cc, n); got != want[i] {
+			t.Errorf("MachineName(%q) = %q, want %q", n.Name, got, want[i])
+		}
+	}
+	single := ClusterConfig{Name: "single", Nodes: []Node{{Name: "", ControlPlane: true}}}
+	if got := MachineName(single, single.Nodes[0]); got != "single" {
+		t.Errorf("MachineName() of a single node = %q, want %q", got, "single")
+	}
+}
+
+func TestWithNodePool(t *testing.T) {
+	cc := ClusterConfig{
+		CPUs:   2,
+		Memory: 2200,
+		NodePools: []NodePool{
+			{Name: "workers", CPUs: 4, Memory: 8192},
+			{Name: "small", Memory: 1024},
+		},
+	}
+
+	var tests = []struct {
+		description string
+		node        Node
+		cpus        int
+		memory      int
+	}{
+		{"no pool", Node{Name: "m02"}, 2, 2200},
+		{"pool", Node{Name: "m02", Pool: "workers"}, 4, 8192},
+		{"pool overriding the memory only", Node{Name: "m02", Pool: "small"}, 2, 1024},
+		{"unknown pool", Node{Name: "m02", Pool: "gpu"}, 2, 2200},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			got := WithNodePool(cc, tc.node)
+			if got.CPUs != tc.cpus || got.Memory != tc.memory {
+				t.Errorf("WithNodePool() has CPUs=%d Memory=%d, expected CPUs=%d Memory=%d", got.CPUs, got.Memory, tc.cpus, tc.memory)
+			}
+		})
+	}
+	if cc.CPUs != 2 || cc.Memory != 2200 {
+		t.Errorf("WithNodePool() changed the config of the cluster")
+	}
+}
+
+func TestTopologyLabels(t *testing.T) {
+	cc := ClusterConfig{
+		Name:    "zoned",
+		Zones:   []string{"zone-a", "zone-b", "m03=zone-c"},
+		Regions: []string{"region-1"},
+		Nodes: []Node{
+			{Name: "", ControlPlane: true},
+			{Name: "m02"},
+			{Name: "m03"},
+			{Name: "m04"},
+		},
+	}
+	tests := []struct {
+		node Node
+		want []string
+	}{
+		{cc.Nodes[0], []string{"topology.kubernetes.io/zone=zone-a", "topology.kubernetes.io/region=region-1"}},
+		{cc.Nodes[1], []string{"topology.kubernetes.io/zone=zone-b", "topology.kubernetes.io/region=region-1"}},
+		{cc.Nodes[2], []string{"topology.kubernetes.io/zone=zone-c", "topology.kubernetes.io/region=region-1"}},
+		{cc.Nodes[3], []string{"topology.kubernetes.io/zone=zone-b", "topology.kubernetes.io/region=region-1"}},
+		// a node being added is after the others
+		{Node{Name: "m05"}, []string{"topology.kubernetes.io/zone=zone-a", "topology.kubernetes.io/region=region-1"}},
+	}
+	for _, tc := range tests {
+		got := TopologyLabels(cc, tc.node)
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("TopologyLabels(%q) = %v, want %v", tc.node.Name, got, tc.want)
+		}
+	}
+
+	// explicit mapping by machine name, without round-robin
+	cc.Zones = []string{"zoned-m02=zone-x"}
+	cc.Regions = nil
+	if got := TopologyLabels(cc, cc.Nodes[0]); len(got) != 0 {
+		t.Errorf("TopologyLabels() of an unmapped node = %v, want none", got)
+	}
+	if got := TopologyLabels(cc, cc.Nodes[1]); len(got) != 1 || got[0] != "topology.kubernetes.io/zone=zone-x" {
+		t.Errorf("TopologyLabels(m02) = %v, want the zone zone-x", got)
+	}
+}
+
+func TestNodeDriver(t *testing.T) {
+	cc := ClusterConfig{Driver: "kvm2", Nodes: []Node{{Name: ""}, {Name: "m02", Driver: "kvm2"}}}
+	if IsHybrid(cc) {
+		t.Errorf("IsHybrid() = true for nodes on the driver of the cluster")
+	}
+	cc.Nodes = append(cc.Nodes, Node{Name: "m03", Driver: "docker"})
+	if !IsHybrid(cc) {
+		t.Errorf("IsHybrid() = false for a node on the docker driver")
+	}
+	if d := NodeDriver(cc, cc.Nodes[0]); d != "kvm2" {
+		t.Errorf("NodeDriver(primary) = %q, want kvm2", d)
+	}
+	if d := NodeDriver(cc, cc.Nodes[2]); d != "docker" {
+		t.Errorf("NodeDriver(m03) = %q, want docker", d)
+	}
+}
+
+func TestNodeIPs(t *testing.T) {
+	n := Node{IP: "192.168.49.2", IPv6: "fd00:192:168:49::2"}
+	tests := []struct {
+		family string
+		want   []string
+	}{
+		{"", []string{"192.168.49.2"}},
+		{IPv4Family, []string{"192.168.49.2"}},
+		{IPv6Family, []string{"fd00:192:168:49::2"}},
+		{DualStack, []string{"192.168.49.2", "fd00:192:168:49::2"}},
+	}
+	for _, tc := range tests {
+		cc := ClusterConfig{KubernetesConfig: KubernetesConfig{IPFamily: tc.family}}
+		if got := NodeIPs(cc, n); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("NodeIPs(%q) = %v, want %v", tc.family, got, tc.want)
+		}
+		if got, want := HasIPv6(cc), len(tc.want) > 1 || tc.family == IPv6Family; got != want {
+			t.Errorf("HasIPv6(%q) = %t, want %t", tc.family, got, want)
+		}
+	}
+}
+
+func TestVirtiofsMount(t *testing.T) {
+	tests := []struct {
+		cc          ClusterConfig
+		host, guest string
+	}{
+		{ClusterConfig{Mount: true, MountType: "virtiofs", MountString: "/home/user/src:/src"}, "/home/user/src", "/src"},
+		{ClusterConfig{Mount: true, MountType: "virtiofs", MountString: `C:\src:/src`}, `C:\src`, "/src"},
+		{ClusterConfig{Mount: true, MountType: "9p", MountString: "/home/user/src:/src"}, "", ""},
+		{ClusterConfig{Mount: false, MountType: "virtiofs", MountString: "/home/user/src:/src"}, "", ""},
+	}
+	for _, tc := range tests {
+		host, guest := VirtiofsMount(tc.cc)
+		if host != tc.host || guest != tc.guest {
+			t.Errorf("VirtiofsMount(%+v) = %q, %q, want %q, %q", tc.cc, host, guest, tc.host, tc.guest)
+		}
+	}
+}
@@ -38,6 +38,7 @@ type ClusterConfig struct {
 	EmbedCerts              bool   // used by kubeconfig.Setup
 	MinikubeISO             string // ISO used for VM-drivers.
 	KicBaseImage            string // base-image used for docker/podman drivers.
+	PreloadFrom             string // preload tarball built by minikube preload build, a file of the host or a URL
 	Memory                  int
 	CPUs                    int
 	DiskSize                int
@@ -45,6 +46,22 @@ type ClusterConfig struct {
 	Driver                  string
 	HyperkitVpnKitSock      string   // Only used by the Hyperkit driver
 	HyperkitVSockPorts      []string // Only used by the Hyperkit driver
+	VZRosetta               bool     // Only used by the vz driver
+	VZSharedFolders         []string // Only used by the vz driver
+	VZBridgeInterface       string   // Only used by the vz driver
+	PluginOptions           []string // Only used by out-of-tree driver plugins, formatted as KEY=VALUE
+	FirecrackerKernel       string   // Only used by the firecracker driver
+	FirecrackerJailer       bool     // Only used by the firecracker driver
+	CloudHypervisorKernel   string   // Only used by the cloud-hypervisor driver
+	Tuning                  string   // Tuning profile of the kernel and ulimits of the nodes
+	TuningOptions           []string // Overrides of the tuning profile, formatted as KEY=VALUE
+	Sysctls                 []string // Sysctls of the nodes, formatted as KEY=VALUE
+	KernelModules           []string // Kernel modules loaded on the nodes
+	WasmRuntime             string   // Wasm shim registered with containerd, run by the pods of the wasm RuntimeClass
+	RuntimeClass            string   // Sandboxed runtime installed into the nodes, run by the pods of the RuntimeClass of its name
+	ContainerRuntimeSandbox string   // Sandbox registered with the container runtime, run by the pods of the RuntimeClass of its name
+	SandboxNamespaces       []string // Namespaces whose pods default to the RuntimeClass of ContainerRuntimeSandbox
+	OCIRuntime              string   // Low-level runtime run by containerd and cri-o, runc if empty
 	DockerEnv               []string // Each entry is formatted as KEY=VALUE.
 	ContainerVolumeMounts   []string // Only used by container drivers: Docker, Podman
 	InsecureRegistry        []string
@@ -53,6 +70,11 @@ type ClusterConfig struct {
 	HypervVirtualSwitch     string
 	HypervUseExternalSwitch bool
 	HypervExternalAdapter   string
+	HypervDynamicMemory     bool
+	HypervMinMemory         int // in MB, only used with HypervDynamicMemory
+	HypervMaxMemory         int // in MB, only used with HypervDynamicMemory
+	HypervMemoryBuffer      int // percentage, only used with HypervDynamicMemory
+	HypervNestedVirt        bool
 	KVMNetwork              string // Only used by the KVM2 driver
 	KVMQemuURI              string // Only used by the KVM2 driver
 	KVMGPU                  bool   // Only used by the KVM2 driver
@@ -73,6 +95,7 @@ type ClusterConfig struct {
 	SSHUser                 string // Only used by ssh driver
 	SSHKey                  string // Only used by ssh driver
 	SSHPort                 int    // Only used by ssh driver
+	SSHProxy                string // ssh:// jump host or socks5:// proxy the machines are reached through
 	KubernetesConfig        KubernetesConfig
 	Nodes                   []Node
 	Addons                  map[string]bool
@@ -81,6 +104,7 @@ type ClusterConfig struct {
 	VerifyComponents        map[string]bool   // map of components to verify and wait for after start.
 	StartHostTimeout        time.Duration
 	ScheduledStop           *ScheduledStopConfig
+	ScheduledStart          string   // cron expression of the recurring start of the cluster by a timer of the host
 	ExposedPorts            []string // Only used by the docker and podman driver
 	ListenAddress           string   // Only used by the docker and podman driver
 	Network                 string   // only used by docker driver
@@ -88,6 +112,7 @@ type ClusterConfig struct {
 	MultiNodeRequested      bool
 	ExtraDisks              int // currently only implemented for hyperkit and kvm2
 	CertExpiration          time.Duration
+	ImportHostCerts         bool // copy the CAs added to the host trust store into ~/.minikube/certs
 	Mount                   bool
 	MountString             string
 	Mount9PVersion          string
@@ -98,44 +123,149 @@ type ClusterConfig struct {
 	MountPort               uint16
 	MountType               string
 	MountUID                string
+	MountSyncIgnore         []string
+	MountSyncConflict       string
+	MountForwardEvents      bool
 	BinaryMirror            string // Mirror location for kube binaries (kubectl, kubelet, & kubeadm)
 	DisableOptimizations    bool
+	DisableProxyPropagation bool // if true, the proxy settings of the host are not passed to the container runtime, kubelet and addon pods
 	DisableMetrics          bool
 	CustomQemuFirmwarePath  string
 	SocketVMnetClientPath   string
 	SocketVMnetPath         string
 	StaticIP                string
+	ExtraNetworks           []string // NAME=NETWORK of the additional networks the nodes are attached to, currently only implemented for kvm2, docker and podman
 	SSHAuthSock             string
 	SSHAgentPID             int
 	AutoPauseInterval       time.Duration // Specifies interval of time to wait before checking if cluster should be paused
 	GPUs                    string
+	NodePools               []NodePool           // node groups with their own resources, labels and taints
+	StateRecovery           string               // how the state of a node is recovered after an unclean shutdown: auto-repair, restore-snapshot or none
+	Zones                   []string             // topology.kubernetes.io/zone of the nodes, assigned round-robin or with NODE=ZONE
+	Regions                 []string             // topology.kubernetes.io/region of the nodes, assigned round-robin or with NODE=REGION
+	RemoteHost              string               // ssh:// URL of the remote machine running the docker or podman daemon of the cluster, set with --host
+	LoadBalancerPool        string               // START-END or auto: the IPs given to LoadBalancer services by kube-vip, empty to rely on minikube tunnel
+	PortForwards            []PortForward        // ports of services and pods forwarded to the host while the cluster runs
+	IdleTimeout             time.Duration        // duration without apiserver connections before IdleAction is taken, 0 to disable
+	IdleAction              string               // pause or stop
+	IdleProxyPort           int                  // port of 127.0.0.1 where the idle proxy forwards the connections of kubectl to the apiserver
+	RegistryPort            int                  // port of the host the docker and podman drivers publish the registry addon on, 0 for a port picked by the driver
+	PreStartHooks           []Hook               // run before kubeadm initializes or restarts the control plane
+	PostStartHooks          []Hook               // run once all the nodes of the cluster are Ready
+	UserData                string               // path of the cloud-init user-data applied by minikube to the VMs on boot
+	GuestFiles              []GuestFile          // files provisioned in the nodes on every start, declared with --provision
+	SystemdDropIns          []SystemdDropIn      // drop-ins of the systemd units of the nodes, declared with --provision
+	RegistryMirrors         []RegistryMirror     // mirrors of registries generated into containerd and cri-o, managed by minikube registry-mirror
+	RegistryCredentials     []RegistryCredential // credentials of private registries given to the nodes and namespaces, managed by minikube registry login
+	PersistentMounts        []Mount              // directories of the host mounted in the primary control plane on every start, declared with --persistent-mount
+}
+
+// NodePool is a group of nodes sharing their resources, labels and taints, instead of those of the cluster
+type NodePool struct {
+	Name   string
+	CPUs   int
+	Memory int
+	Labels []string // formatted as KEY=VALUE
+	Taints []string // formatted as KEY[=VALUE]:EFFECT
+}
+
+// PortForward is a port of a service, or of a pod matching a label selector, forwarded to a port of the host
+type PortForward struct {
+	Namespace string
+	Service   string // name of the service, empty to forward a port of a pod matching Selector
+	Selector  string // label selector of the pods when Service is empty
+	HostPort  int
+	Port      int // port of the service, or of the pod
+}
+
+// Mount is a directory of the host mounted in the primary control plane by a background mount process
+type Mount struct {
+	Source   string // absolute path of the host directory
+	Target   string // absolute path in the node
+	Type     string // 9p or sync
+	UID      string // owner of the files in the node, the one of the cluster mount options if empty
+	GID      string
+	ReadOnly bool // if true, the node can not change the files of the host
+}
+
+// Hook is a command run while the cluster starts, for the setup specific to a site
+type Hook struct {
+	Guest   bool // if true, the command is run as root by bash in the primary control plane, otherwise by the shell of the host
+	Command string
+}
+
+// GuestFile is a file which minikube keeps provisioned in the nodes
+type GuestFile struct {
+	Path    string // absolute path in the nodes
+	Source  string // file of the host holding the content, instead of Content
+	Content string
+	Mode    string   // octal permissions, 0644 by default
+	Owner   string   // USER[:GROUP], root:root by default
+	Restart []string // systemd units restarted when the file changes
+}
+
+// SystemdDropIn is a drop-in of a systemd unit of the nodes, the unit is restarted when it changes
+type SystemdDropIn struct {
+	Unit    string // such as containerd.service
+	Name    string // such as 10-limits.conf
+	Content string
+}
+
+// RegistryMirror is a mirror which the container runtime of the nodes pulls the images of a registry from
+type RegistryMirror struct {
+	Registry string // the mirrored registry, such as docker.io
+	URL      string // http:// for a mirror without TLS
+	Username string
+	Password string
+	CACert   string // PEM of the CA of the mirror
+	Insecure bool   // if true, the certificate of the mirror is not verified
+}
+
+// RegistryCredential is the credentials of a private registry, which the nodes pull the images of all the pods with
+type RegistryCredential struct {
+	Registry   string // such as docker.io or 123456789012.dkr.ecr.us-east-1.amazonaws.com
+	Username   string
+	Password   string
+	Helper     string   // the cloud CLI the token was got from, to refresh it with minikube registry login
+	Namespaces []string // namespaces given the credentials as an imagePullSecret of their default service account
 }
 
 // KubernetesConfig contains the parameters used to configure the VM Kubernetes.
 type KubernetesConfig struct {
-	KubernetesVersion   string
-	ClusterName         string
-	Namespace           string
-	APIServerName       string
-	APIServerNames      []string
-	APIServerIPs        []net.IP
-	DNSDomain           string
-	ContainerRuntime    string
-	CRISocket           string
-	NetworkPlugin       string
-	FeatureGates        string // https://kubernetes.io/docs/reference/command-line-tools-reference/feature-gates/
-	ServiceCIDR         string // the subnet which Kubernetes services will be deployed to
-	ImageRepository     string
-	LoadBalancerStartIP string // currently only used by MetalLB addon
-	LoadBalancerEndIP   string // currently only used by MetalLB addon
-	CustomIngressCert   string // used by Ingress addon
-	RegistryAliases     string // currently only used by registry-aliases addon
-	ExtraOptions        ExtraOptionSlice
+	KubernetesVersion    string
+	ClusterName          string
+	Namespace            string
+	APIServerName        string
+	APIServerNames       []string
+	APIServerIPs         []net.IP
+	APIServerHAVIP       string // virtual IP in front of the apiservers, set by --vip or for multiple control planes
+	DNSDomain            string
+	ContainerRuntime     string
+	CRISocket            string
+	NetworkPlugin        string
+	FeatureGates         string // https://kubernetes.io/docs/reference/command-line-tools-reference/feature-gates/
+	ServiceCIDR          string // the subnet which Kubernetes services will be deployed to
+	IPFamily             string // ipv4, ipv6 or dual: the IP families of the nodes, pods and services, empty for ipv4
+	ImageRepository      string
+	LoadBalancerStartIP  string // currently only used by MetalLB addon
+	LoadBalancerEndIP    string // currently only used by MetalLB addon
+	CustomIngressCert    string // used by Ingress addon
+	RegistryAliases      string // currently only used by registry-aliases addon
+	GatekeeperPolicyDir  string // host directory of the policies synced by the gatekeeper addon
+	PullPolicy           string // image pull policy forced by the pull-policy addon
+	PullPolicyNamespaces string // comma separated namespaces of the pull-policy addon
+	SPIFFETrustDomain    string // if set, SPIFFE IDs are embedded as URI SANs in the apiserver and client certs
+	KubernetesImagesDir  string // if set, the core components are loaded from this local build of Kubernetes
+	KubeadmPatches       string // if set, the directory of the patches applied by kubeadm --patches
+	ExtraOptions         ExtraOptionSlice
 
 	ShouldLoadCachedImages bool
 
-	EnableDefaultCNI bool   // deprecated in preference to CNI
-	CNI              string // CNI to use
+	EnableDefaultCNI bool     // deprecated in preference to CNI
+	CNI              string   // CNI to use
+	CNIVersion       string   // version of the images of the calico, cilium or flannel CNI, or of the chart of a helm CNI
+	CNIValues        []string // values files of the chart of a helm CNI
+	CNIOverlays      []string // files of patches merged into the objects of the CNI manifest
 
 	// We need to keep these in the short term for backwards compatibility
 	NodeIP   string
@@ -152,6 +282,12 @@ type Node struct {
 	ContainerRuntime  string
 	ControlPlane      bool
 	Worker            bool
+	ExtraIPs          map[string]string // IPs of the node on the extra networks, by network name
+	Pool              string            // name of the node pool of the node, if any
+	KubeletConfig     []string          // overrides of the KubeletConfiguration of the node, formatted as FIELD[.KEY]=VALUE
+	Driver            string            // driver of the node when it differs from the driver of the cluster, in a hybrid cluster
+	OS                string            // operating system of the node, "windows" for the experimental Windows workers, empty for Linux
+	IPv6              string            // IPv6 address of the node, in a cluster of the ipv6 or dual IP family
 }
 
 // VersionedExtraOption holds information on flags to apply to a specific range
@@ -78,6 +78,10 @@ const (
 	ClusterDNSDomain = "cluster.local"
 	// DefaultServiceCIDR is The CIDR to be used for service cluster IPs
 	DefaultServiceCIDR = "10.96.0.0/12"
+	// DefaultServiceCIDRv6 is the CIDR to be used for the IPv6 service cluster IPs, kube-apiserver limits it to 20 bits
+	DefaultServiceCIDRv6 = "fd00:10:96::/112"
+	// DefaultPodCIDRv6 is the CIDR to be used for the IPv6 pod IPs, split in a /64 per node
+	DefaultPodCIDRv6 = "fd00:10:244::/56"
 	// HostAlias is a DNS alias to the container/VM host IP
 	HostAlias = "host.minikube.internal"
 	// ControlPlaneAlias is a DNS alias pointing to the apiserver frontend
@@ -107,12 +111,16 @@ const (
 	MinikubeActivePodmanEnv = "MINIKUBE_ACTIVE_PODMAN"
 	// MinikubeForceSystemdEnv is used to force systemd as cgroup manager for the container runtime
 	MinikubeForceSystemdEnv = "MINIKUBE_FORCE_SYSTEMD"
+	// MinikubeWindowsPasswordEnv holds the password of the administrator of the image of the Windows nodes
+	MinikubeWindowsPasswordEnv = "MINIKUBE_WINDOWS_PASSWORD"
 	// TestDiskUsedEnv is used in integration tests for insufficient storage with 'minikube status' (in %)
 	TestDiskUsedEnv = "MINIKUBE_TEST_STORAGE_CAPACITY"
 	// TestDiskAvailableEnv is used in integration tests for insufficient storage with 'minikube status' (in GiB)
 	TestDiskAvailableEnv = "MINIKUBE_TEST_AVAILABLE_STORAGE"
 	// MinikubeRootlessEnv is used to force Rootless Docker/Podman driver
 	MinikubeRootlessEnv = "MINIKUBE_ROOTLESS"
+	// MinikubeLeaseEnv holds the ID of the lease of the profile the commands run under, see 'minikube lock'
+	MinikubeLeaseEnv = "MINIKUBE_LEASE"
 
 	// scheduled stop constants
 
@@ -160,6 +168,18 @@ const (
 	MountTypeFlag = "type"
 	// MountUIDFlag is the flag used to set the mount UID
 	MountUIDFlag = "uid"
+	// MountSyncIgnoreFlag is the flag used to set the ignore patterns of the sync mount type
+	MountSyncIgnoreFlag = "sync-ignore"
+	// MountSyncConflictFlag is the flag used to set the conflict policy of the sync mount type
+	MountSyncConflictFlag = "sync-conflict"
+	// MountReadOnlyFlag is the flag used to mount the directory read-only
+	MountReadOnlyFlag = "read-only"
+	// MountForwardEventsFlag is the flag used to forward the file changes of the host to the watchers of the 9p mount
+	MountForwardEventsFlag = "forward-events"
+	// SyncMountType is the mount type syncing the host directory and the node directory both ways, instead of mounting it
+	SyncMountType = "sync"
+	// VirtiofsMountType is the mount type sharing the host directory with the VM through virtiofs, set up when the VM is created
+	VirtiofsMountType = "virtiofs"
 
 	// Mirror CN
 	AliyunMirror = "registry.cn-hangzhou.aliyuncs.com/google_containers"
@@ -170,6 +190,12 @@ var (
 	IsMinikubeChildProcess = "IS_MINIKUBE_CHILD_PROCESS"
 	// MountProcessFileName is the filename of the mount process
 	MountProcessFileName = ".mount-process"
+	// PortForwardProcessFileName is the filename of the port-forward process
+	PortForwardProcessFileName = ".port-forward-process"
+	// PersistentMountProcessFileName is the filename of the mount processes of the persistent mounts
+	PersistentMountProcessFileName = ".persistent-mount-process"
+	// IdleProxyProcessFileName is the filename of the idle-proxy process
+	IdleProxyProcessFileName = ".idle-proxy-process"
 
 	// SHASuffix is the suffix of a SHA-256 checksum file
 	SHASuffix = ".sha256"
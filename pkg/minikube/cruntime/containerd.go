@@ -39,15 +39,18 @@ import (
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/download"
+	"k8s.io/minikube/pkg/minikube/sandbox"
 	"k8s.io/minikube/pkg/minikube/style"
 	"k8s.io/minikube/pkg/minikube/sysinit"
 )
 
+// ContainerdMirrorsRoot is the directory of the hosts.toml of the registries
+const ContainerdMirrorsRoot = "/etc/containerd/certs.d"
+
 const (
 	containerdNamespaceRoot = "/run/containerd/runc/k8s.io"
 	// ContainerdConfFile is the path to the containerd configuration
 	containerdConfigFile               = "/etc/containerd/config.toml"
-	containerdMirrorsRoot              = "/etc/containerd/certs.d"
 	containerdInsecureRegistryTemplate = `server = "{{.InsecureRegistry -}}"
 
 [host."{{.InsecureRegistry -}}"]
@@ -63,6 +66,9 @@ type Containerd struct {
 	KubernetesVersion semver.Version
 	Init              sysinit.Manager
 	InsecureRegistry  []string
+	Sandbox           string
+	OCIRuntime        string
+	GPUs              bool
 }
 
 // Name is a human readable name for containerd
@@ -198,7 +204,7 @@ func generateContainerdConfig(cr CommandRunner, imageRepository string, kv semve
 		if err := t.Execute(&b, opts); err != nil {
 			return errors.Wrap(err, "unable to create insecure registry template")
 		}
-		regRootPath := path.Join(containerdMirrorsRoot, addr)
+		regRootPath := path.Join(ContainerdMirrorsRoot, addr)
 
 		c := exec.Command("/bin/bash", "-c", fmt.Sprintf("sudo mkdir -p %s && printf %%s \"%s\" | base64 -d | sudo tee %s", regRootPath, base64.StdEncoding.EncodeToString(b.Bytes()), path.Join(regRootPath, "hosts.toml")))
 		if _, err := cr.RunCmd(c); err != nil {
@@ -233,6 +239,17 @@ func (r *Containerd) Enable(disOthers bool, cgroupDriver string, inUserNamespace
 	if err := generateContainerdConfig(r.Runner, r.ImageRepository, r.KubernetesVersion, cgroupDriver, r.InsecureRegistry, inUserNamespace); err != nil {
 		return err
 	}
+	if err := sandbox.ConfigureContainerd(r.Runner, r.Sandbox); err != nil {
+		return err
+	}
+	if err := configureContainerdOCIRuntime(r.Runner, r.OCIRuntime); err != nil {
+		return err
+	}
+	if r.GPUs {
+		if err := ConfigureNvidiaRuntime(r.Runner, "containerd"); err != nil {
+			return err
+		}
+	}
 	if err := enableIPForwarding(r.Runner); err != nil {
 		return err
 	}
@@ -502,12 +519,12 @@ func (r *Containerd) SystemLogCmd(len int) string {
 
 // Preload preloads the container runtime with k8s images
 func (r *Containerd) Preload(cc config.ClusterConfig) error {
-	if !download.PreloadExists(cc.KubernetesConfig.KubernetesVersion, cc.KubernetesConfig.ContainerRuntime, cc.Driver) {
+	tarballPath, ok := download.PreloadTarball(cc.KubernetesConfig.KubernetesVersion, cc.KubernetesConfig.ContainerRuntime, cc.Driver, cc.PreloadFrom)
+	if !ok {
 		return nil
 	}
 
 	k8sVersion := cc.KubernetesConfig.KubernetesVersion
-	cRuntime := cc.KubernetesConfig.ContainerRuntime
 
 	// If images already exist, return
 	images, err := images.Kubeadm(cc.KubernetesConfig.ImageRepository, k8sVersion)
@@ -519,14 +536,13 @@ func (r *Containerd) Preload(cc config.ClusterConfig) error {
 		return nil
 	}
 
-	tarballPath := download.TarballPath(k8sVersion, cRuntime)
 	targetDir := "/"
-	targetName := "preloaded.tar.lz4"
+	targetName := "preloaded.tar" + path.Ext(tarballPath)
 	dest := path.Join(targetDir, targetName)
 
-	c := exec.Command("which", "lz4")
-	if _, err := r.Runner.RunCmd(c); err != nil {
-		return NewErrISOFeature("lz4")
+	decompressor, err := preloadDecompressor(r.Runner, tarballPath)
+	if err != nil {
+		return err
 	}
 
 	// Copy over tarball into host
@@ -548,7 +564,7 @@ func (r *Containerd) Preload(cc config.ClusterConfig) error {
 
 	t = time.Now()
 	// extract the tarball to /var in the VM
-	if rr, err := r.Runner.RunCmd(exec.Command("sudo", "tar", "-I", "lz4", "-C", "/var", "-xf", dest)); err != nil {
+	if rr, err := r.Runner.RunCmd(exec.Command("sudo", "tar", "-I", decompressor, "-C", "/var", "-xf", dest)); err != nil {
 		return errors.Wrapf(err, "extracting tarball: %s", rr.Output())
 	}
 	klog.Infof("Took %f seconds to extract the tarball", time.Since(t).Seconds())
@@ -35,6 +35,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/download"
+	"k8s.io/minikube/pkg/minikube/sandbox"
 	"k8s.io/minikube/pkg/minikube/style"
 	"k8s.io/minikube/pkg/minikube/sysinit"
 )
@@ -51,6 +52,9 @@ type CRIO struct {
 	ImageRepository   string
 	KubernetesVersion semver.Version
 	Init              sysinit.Manager
+	Sandbox           string
+	OCIRuntime        string
+	GPUs              bool
 }
 
 // generateCRIOConfig sets up pause image and cgroup manager for cri-o in crioConfigFile
@@ -208,6 +212,17 @@ func (r *CRIO) Enable(disOthers bool, cgroupDriver string, inUserNamespace bool)
 	if err := generateCRIOConfig(r.Runner, r.ImageRepository, r.KubernetesVersion, cgroupDriver); err != nil {
 		return err
 	}
+	if err := sandbox.ConfigureCRIO(r.Runner, r.Sandbox); err != nil {
+		return err
+	}
+	if err := configureCRIOOCIRuntime(r.Runner, r.OCIRuntime); err != nil {
+		return err
+	}
+	if r.GPUs {
+		if err := ConfigureNvidiaRuntime(r.Runner, "crio"); err != nil {
+			return err
+		}
+	}
 	if err := enableIPForwarding(r.Runner); err != nil {
 		return err
 	}
@@ -399,12 +414,12 @@ func (r *CRIO) SystemLogCmd(len int) string {
 
 // Preload preloads the container runtime with k8s images
 func (r *CRIO) Preload(cc config.ClusterConfig) error {
-	if !download.PreloadExists(cc.KubernetesConfig.KubernetesVersion, cc.KubernetesConfig.ContainerRuntime, cc.Driver) {
+	tarballPath, ok := download.PreloadTarball(cc.KubernetesConfig.KubernetesVersion, cc.KubernetesConfig.ContainerRuntime, cc.Driver, cc.PreloadFrom)
+	if !ok {
 		return nil
 	}
 
 	k8sVersion := cc.KubernetesConfig.KubernetesVersion
-	cRuntime := cc.KubernetesConfig.ContainerRuntime
 
 	// If images already exist, return
 	images, err := images.Kubeadm(cc.KubernetesConfig.ImageRepository, k8sVersion)
@@ -416,14 +431,13 @@ func (r *CRIO) Preload(cc config.ClusterConfig) error {
 		return nil
 	}
 
-	tarballPath := download.TarballPath(k8sVersion, cRuntime)
 	targetDir := "/"
-	targetName := "preloaded.tar.lz4"
+	targetName := "preloaded.tar" + path.Ext(tarballPath)
 	dest := path.Join(targetDir, targetName)
 
-	c := exec.Command("which", "lz4")
-	if _, err := r.Runner.RunCmd(c); err != nil {
-		return NewErrISOFeature("lz4")
+	decompressor, err := preloadDecompressor(r.Runner, tarballPath)
+	if err != nil {
+		return err
 	}
 
 	// Copy over tarball into host
@@ -445,7 +459,7 @@ func (r *CRIO) Preload(cc config.ClusterConfig) error {
 
 	t = time.Now()
 	// extract the tarball to /var in the VM
-	if rr, err := r.Runner.RunCmd(exec.Command("sudo", "tar", "-I", "lz4", "-C", "/var", "-xf", dest)); err != nil {
+	if rr, err := r.Runner.RunCmd(exec.Command("sudo", "tar", "-I", decompressor, "-C", "/var", "-xf", dest)); err != nil {
 		return errors.Wrapf(err, "extracting tarball: %s", rr.Output())
 	}
 	klog.Infof("Took %f seconds to extract the tarball", time.Since(t).Seconds())
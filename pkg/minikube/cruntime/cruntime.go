@@ -28,6 +28,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/assets"
 	"k8s.io/minikube/pkg/minikube/command"
 	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/download"
 	"k8s.io/minikube/pkg/minikube/style"
 	"k8s.io/minikube/pkg/minikube/sysinit"
 )
@@ -157,6 +158,10 @@ type Config struct {
 	InsecureRegistry []string
 	// GPUs add GPU devices to the container
 	GPUs bool
+	// Sandbox is the sandboxed runtime registered with the container runtime ("gvisor" or empty)
+	Sandbox string
+	// OCIRuntime is the low-level runtime run by the container runtime ("runc", "crun" or empty for runc)
+	OCIRuntime string
 }
 
 // ListContainersOptions are the options to use for listing containers
@@ -238,6 +243,9 @@ func New(c Config) (Manager, error) {
 			ImageRepository:   c.ImageRepository,
 			KubernetesVersion: c.KubernetesVersion,
 			Init:              sm,
+			Sandbox:           c.Sandbox,
+			OCIRuntime:        c.OCIRuntime,
+			GPUs:              c.GPUs,
 		}, nil
 	case "containerd":
 		return &Containerd{
@@ -247,6 +255,9 @@ func New(c Config) (Manager, error) {
 			KubernetesVersion: c.KubernetesVersion,
 			Init:              sm,
 			InsecureRegistry:  c.InsecureRegistry,
+			Sandbox:           c.Sandbox,
+			OCIRuntime:        c.OCIRuntime,
+			GPUs:              c.GPUs,
 		}, nil
 	default:
 		return nil, fmt.Errorf("unknown runtime type: %q", c.Type)
@@ -342,3 +353,18 @@ func CheckKernelCompatibility(cr CommandRunner, major, minor int) error {
 	}
 	return nil
 }
+
+// preloadDecompressor returns the program of the node of cr which tar decompresses the preload tarball p with:
+// lz4 for the tarballs of older versions, else pzstd decompressing zstd in parallel, or zstd on the nodes without it
+func preloadDecompressor(cr CommandRunner, p string) (string, error) {
+	progs := []string{"pzstd", "zstd"}
+	if download.IsLZ4(p) {
+		progs = []string{"lz4"}
+	}
+	for _, prog := range progs {
+		if _, err := cr.RunCmd(exec.Command("which", prog)); err == nil {
+			return prog, nil
+		}
+	}
+	return "", NewErrISOFeature(progs[len(progs)-1])
+}
@@ -808,3 +808,19 @@ func TestContainerFunctions(t *testing.T) {
 		})
 	}
 }
+
+func TestPreloadDecompressor(t *testing.T) {
+	tests := []struct {
+		tarball string
+		want    string
+	}{
+		{"preloaded-images-k8s-v20-v1.30.0-docker-overlay2-amd64.tar.zst", "pzstd"},
+		{"preloaded-images-k8s-v19-v1.30.0-docker-overlay2-amd64.tar.lz4", "lz4"},
+	}
+	for _, tc := range tests {
+		got, err := preloadDecompressor(NewFakeRunner(t), tc.tarball)
+		if err != nil || got != tc.want {
+			t.Errorf("preloadDecompressor(%s) = %q, %v, want %q", tc.tarball, got, err, tc.want)
+		}
+	}
+}
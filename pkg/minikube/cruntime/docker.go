@@ -567,7 +567,6 @@ func (r *Docker) configureDocker(driver string) error {
 		StorageDriver: "overlay2",
 	}
 	if r.GPUs {
-		assets.Addons["nvidia-device-plugin"].EnableByDefault()
 		daemonConfig.DefaultRuntime = "nvidia"
 		runtimes := &dockerDaemonRuntimes{}
 		runtimes.Nvidia.Path = "/usr/bin/nvidia-container-runtime"
@@ -586,11 +585,11 @@ func (r *Docker) configureDocker(driver string) error {
 // 2. Extract the preloaded tarball to the correct directory
 // 3. Remove the tarball within the VM
 func (r *Docker) Preload(cc config.ClusterConfig) error {
-	if !download.PreloadExists(cc.KubernetesConfig.KubernetesVersion, cc.KubernetesConfig.ContainerRuntime, cc.Driver) {
+	tarballPath, ok := download.PreloadTarball(cc.KubernetesConfig.KubernetesVersion, cc.KubernetesConfig.ContainerRuntime, cc.Driver, cc.PreloadFrom)
+	if !ok {
 		return nil
 	}
 	k8sVersion := cc.KubernetesConfig.KubernetesVersion
-	cRuntime := cc.KubernetesConfig.ContainerRuntime
 
 	// If images already exist, return
 	images, err := images.Kubeadm(cc.KubernetesConfig.ImageRepository, k8sVersion)
@@ -607,14 +606,13 @@ func (r *Docker) Preload(cc config.ClusterConfig) error {
 		klog.Infof("error saving reference store: %v", err)
 	}
 
-	tarballPath := download.TarballPath(k8sVersion, cRuntime)
 	targetDir := "/"
-	targetName := "preloaded.tar.lz4"
+	targetName := "preloaded.tar" + path.Ext(tarballPath)
 	dest := path.Join(targetDir, targetName)
 
-	c := exec.Command("which", "lz4")
-	if _, err := r.Runner.RunCmd(c); err != nil {
-		return NewErrISOFeature("lz4")
+	decompressor, err := preloadDecompressor(r.Runner, tarballPath)
+	if err != nil {
+		return err
 	}
 
 	// Copy over tarball into host
@@ -635,7 +633,7 @@ func (r *Docker) Preload(cc config.ClusterConfig) error {
 	klog.Infof("Took %f seconds to copy over tarball", time.Since(t).Seconds())
 
 	// extract the tarball to /var in the VM
-	if rr, err := r.Runner.RunCmd(exec.Command("sudo", "tar", "-I", "lz4", "-C", "/var", "-xf", dest)); err != nil {
+	if rr, err := r.Runner.RunCmd(exec.Command("sudo", "tar", "-I", decompressor, "-C", "/var", "-xf", dest)); err != nil {
 		return errors.Wrapf(err, "extracting tarball: %s", rr.Output())
 	}
 
@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cruntime
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// NvidiaRuntimeConfig is where nvidia-ctk configures the nvidia runtime handler of a container runtime of the node, and how to tell it did
+type NvidiaRuntimeConfig struct {
+	Path    string
+	Service string
+	// Configured matches the config once nvidia is the default runtime
+	Configured *regexp.Regexp
+}
+
+// NvidiaRuntimeConfigs are the configs of the container runtimes running the containers with the nvidia runtime
+var NvidiaRuntimeConfigs = map[string]NvidiaRuntimeConfig{
+	"docker":     {"/etc/docker/daemon.json", "docker", regexp.MustCompile(`"default-runtime":\s*"nvidia"`)},
+	"containerd": {containerdConfigFile, "containerd", regexp.MustCompile(`default_runtime_name\s*=\s*"nvidia"`)},
+	"crio":       {"/etc/crio/crio.conf.d/99-nvidia.conf", "crio", regexp.MustCompile(`default_runtime\s*=\s*"nvidia"`)},
+}
+
+// ConfigureNvidiaRuntime makes nvidia the default runtime handler of the container runtime of the node with nvidia-ctk,
+// so that the containers see the GPUs of the host. The container runtime picks it up when restarted.
+func ConfigureNvidiaRuntime(cr CommandRunner, runtime string) error {
+	rc, ok := NvidiaRuntimeConfigs[runtime]
+	if !ok {
+		return fmt.Errorf("unsupported container runtime %q", runtime)
+	}
+	klog.Infof("configuring the nvidia runtime of %s in %s", runtime, rc.Path)
+	c := exec.Command("sudo", "nvidia-ctk", "runtime", "configure", "--runtime="+runtime, "--config="+rc.Path, "--set-as-default")
+	if rr, err := cr.RunCmd(c); err != nil {
+		return errors.Wrapf(err, "nvidia-ctk: %s", rr.Output())
+	}
+	return nil
+}
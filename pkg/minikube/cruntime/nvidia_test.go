@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cruntime
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNvidiaRuntimeConfigured(t *testing.T) {
+	configs := map[string]string{
+		"docker":     `{"exec-opts":["native.cgroupdriver=systemd"],"default-runtime":"nvidia","runtimes":{"nvidia":{"path":"/usr/bin/nvidia-container-runtime"}}}`,
+		"containerd": "[plugins.\"io.containerd.grpc.v1.cri\".containerd]\n  default_runtime_name = \"nvidia\"\n",
+		"crio":       "[crio.runtime]\ndefault_runtime = \"nvidia\"\n",
+	}
+	for runtime, cfg := range configs {
+		rc := NvidiaRuntimeConfigs[runtime]
+		if !rc.Configured.MatchString(cfg) {
+			t.Errorf("%s config %q is not detected as configured", runtime, cfg)
+		}
+		if rc.Configured.MatchString(strings.ReplaceAll(cfg, `"nvidia"`, `"runc"`)) {
+			t.Errorf("%s config with runc is detected as configured", runtime)
+		}
+	}
+}
+
+func TestConfigureNvidiaRuntime(t *testing.T) {
+	r := &ociRunner{FakeRunner: NewFakeRunner(t)}
+	if err := ConfigureNvidiaRuntime(r, "crio"); err != nil {
+		t.Fatalf("ConfigureNvidiaRuntime: %v", err)
+	}
+	want := "sudo nvidia-ctk runtime configure --runtime=crio --config=/etc/crio/crio.conf.d/99-nvidia.conf --set-as-default"
+	if len(r.cmds) != 1 || r.cmds[0] != want {
+		t.Errorf("ConfigureNvidiaRuntime ran %q, want %q", r.cmds, want)
+	}
+	if err := ConfigureNvidiaRuntime(r, "gvisor"); err == nil {
+		t.Errorf("ConfigureNvidiaRuntime succeeded for an unsupported container runtime")
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cruntime
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+)
+
+const (
+	// Runc is the default OCI runtime of containerd and cri-o
+	Runc = "runc"
+	// Crun is the OCI runtime written in C, starting containers faster and with less memory than runc
+	Crun = "crun"
+
+	// crioOCIRuntimeFile is the cri-o drop-in switching the default runtime, read after the one of crioConfigFile
+	crioOCIRuntimeFile = "/etc/crio/crio.conf.d/05-oci-runtime.conf"
+)
+
+// OCIRuntimes returns the valid OCI runtimes
+func OCIRuntimes() []string {
+	return []string{Runc, Crun}
+}
+
+// ValidOCIRuntime returns whether rt is an OCI runtime which can be selected
+func ValidOCIRuntime(rt string) bool {
+	return rt == "" || rt == Runc || rt == Crun
+}
+
+// ociRuntimePath returns the path of the OCI runtime rt in the node, which crun requires to run with cgroup v2
+func ociRuntimePath(cr CommandRunner, rt string) (string, error) {
+	rr, err := cr.RunCmd(exec.Command("sh", "-c", "command -v "+rt))
+	if err != nil {
+		return "", errors.Wrapf(err, "%s is not installed in the node", rt)
+	}
+	if rt == Crun {
+		fs, err := cr.RunCmd(exec.Command("stat", "-fc", "%T", "/sys/fs/cgroup/"))
+		if err != nil {
+			return "", errors.Wrap(err, "detecting the cgroup version")
+		}
+		if t := strings.TrimSpace(fs.Stdout.String()); t != "cgroup2fs" {
+			return "", fmt.Errorf("%s needs the node to use cgroup v2, its /sys/fs/cgroup is %s", rt, t)
+		}
+	}
+	return strings.TrimSpace(rr.Stdout.String()), nil
+}
+
+// configureContainerdOCIRuntime sets the binary run by the runc runtime of containerd to the OCI runtime rt, or resets it to runc
+func configureContainerdOCIRuntime(cr CommandRunner, rt string) error {
+	if _, err := cr.RunCmd(exec.Command("sh", "-c", fmt.Sprintf(`sudo sed -i '/^ *BinaryName = .*$/d' %s`, containerdConfigFile))); err != nil {
+		return errors.Wrap(err, "resetting the OCI runtime")
+	}
+	if rt == "" || rt == Runc {
+		return nil
+	}
+	p, err := ociRuntimePath(cr, rt)
+	if err != nil {
+		return err
+	}
+	// the options of the runc runtime hold SystemdCgroup, set by generateContainerdConfig
+	if _, err := cr.RunCmd(exec.Command("sh", "-c", fmt.Sprintf(`sudo sed -i -r 's|^( *)SystemdCgroup = (.*)$|\1SystemdCgroup = \2\n\1BinaryName = %q|' %s`, p, containerdConfigFile))); err != nil {
+		return errors.Wrapf(err, "configuring %s", rt)
+	}
+	return nil
+}
+
+// configureCRIOOCIRuntime makes the OCI runtime rt the default runtime of cri-o, or removes the drop-in doing it for runc
+func configureCRIOOCIRuntime(cr CommandRunner, rt string) error {
+	if rt == "" || rt == Runc {
+		if _, err := cr.RunCmd(exec.Command("sudo", "rm", "-f", crioOCIRuntimeFile)); err != nil {
+			return errors.Wrap(err, "resetting the OCI runtime")
+		}
+		return nil
+	}
+	p, err := ociRuntimePath(cr, rt)
+	if err != nil {
+		return err
+	}
+	conf := fmt.Sprintf("[crio.runtime]\ndefault_runtime = %q\n\n[crio.runtime.runtimes.%s]\nruntime_path = %q\nruntime_type = \"oci\"\nruntime_root = \"/run/%s\"\n", rt, rt, p, rt)
+	if err := cr.Copy(assets.NewMemoryAssetTarget([]byte(conf), crioOCIRuntimeFile, "0644")); err != nil {
+		return errors.Wrapf(err, "configuring %s", rt)
+	}
+	return nil
+}
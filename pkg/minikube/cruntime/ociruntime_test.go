@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cruntime
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+// ociRunner is a node with crun installed, whose cgroup filesystem is cgroupFS
+type ociRunner struct {
+	*FakeRunner
+	cgroupFS string
+	cmds     []string
+	copied   map[string]string
+}
+
+func (r *ociRunner) RunCmd(cmd *exec.Cmd) (*command.RunResult, error) {
+	c := strings.Join(cmd.Args, " ")
+	r.cmds = append(r.cmds, c)
+	rr := &command.RunResult{}
+	switch {
+	case strings.HasSuffix(c, "command -v crun"):
+		rr.Stdout = *bytes.NewBufferString("/usr/bin/crun\n")
+	case strings.HasPrefix(c, "sh -c command -v"):
+		return rr, fmt.Errorf("not found")
+	case strings.HasPrefix(c, "stat -fc"):
+		rr.Stdout = *bytes.NewBufferString(r.cgroupFS + "\n")
+	}
+	return rr, nil
+}
+
+func (r *ociRunner) Copy(f assets.CopyableFile) error {
+	b := make([]byte, f.GetLength())
+	if _, err := f.Read(b); err != nil {
+		return err
+	}
+	r.copied[f.GetTargetPath()] = string(b)
+	return nil
+}
+
+func TestOCIRuntimePath(t *testing.T) {
+	tests := []struct {
+		rt       string
+		cgroupFS string
+		want     string
+		wantErr  bool
+	}{
+		{rt: Crun, cgroupFS: "cgroup2fs", want: "/usr/bin/crun"},
+		{rt: Crun, cgroupFS: "tmpfs", wantErr: true},
+		{rt: "youki", cgroupFS: "cgroup2fs", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.rt+"-"+tc.cgroupFS, func(t *testing.T) {
+			r := &ociRunner{FakeRunner: NewFakeRunner(t), cgroupFS: tc.cgroupFS}
+			got, err := ociRuntimePath(r, tc.rt)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ociRuntimePath(%s) error = %v, wantErr %v", tc.rt, err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("ociRuntimePath(%s) = %q, want %q", tc.rt, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestConfigureCRIOOCIRuntime(t *testing.T) {
+	r := &ociRunner{FakeRunner: NewFakeRunner(t), cgroupFS: "cgroup2fs", copied: map[string]string{}}
+	if err := configureCRIOOCIRuntime(r, Crun); err != nil {
+		t.Fatalf("configureCRIOOCIRuntime(crun): %v", err)
+	}
+	conf := r.copied[crioOCIRuntimeFile]
+	for _, want := range []string{`default_runtime = "crun"`, `[crio.runtime.runtimes.crun]`, `runtime_path = "/usr/bin/crun"`} {
+		if !strings.Contains(conf, want) {
+			t.Errorf("cri-o config %q does not contain %q", conf, want)
+		}
+	}
+
+	r.cmds = nil
+	if err := configureCRIOOCIRuntime(r, Runc); err != nil {
+		t.Fatalf("configureCRIOOCIRuntime(runc): %v", err)
+	}
+	if want := "sudo rm -f " + crioOCIRuntimeFile; len(r.cmds) != 1 || r.cmds[0] != want {
+		t.Errorf("configureCRIOOCIRuntime(runc) ran %v, want [%s]", r.cmds, want)
+	}
+}
+
+func TestConfigureContainerdOCIRuntime(t *testing.T) {
+	r := &ociRunner{FakeRunner: NewFakeRunner(t), cgroupFS: "cgroup2fs"}
+	if err := configureContainerdOCIRuntime(r, Crun); err != nil {
+		t.Fatalf("configureContainerdOCIRuntime(crun): %v", err)
+	}
+	if last := r.cmds[len(r.cmds)-1]; !strings.Contains(last, `BinaryName = "/usr/bin/crun"`) {
+		t.Errorf("configureContainerdOCIRuntime(crun) ran %q, want it to set BinaryName", last)
+	}
+
+	r = &ociRunner{FakeRunner: NewFakeRunner(t), cgroupFS: "tmpfs"}
+	if err := configureContainerdOCIRuntime(r, Crun); err == nil {
+		t.Errorf("configureContainerdOCIRuntime(crun) on cgroup v1 succeeded, want an error")
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package deprecations finds the resources of a cluster using APIs deprecated or removed by a Kubernetes version
+package deprecations
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"sort"
+
+	"github.com/blang/semver/v4"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/klog/v2"
+)
+
+//go:embed apis.yaml
+var apisYAML []byte
+
+// API is an API version of a kind, deprecated and removed by Kubernetes releases
+type API struct {
+	Group        string `yaml:"group" json:"group"`
+	Version      string `yaml:"version" json:"version"`
+	Kind         string `yaml:"kind" json:"kind"`
+	Resource     string `yaml:"resource" json:"resource"`
+	DeprecatedIn string `yaml:"deprecatedIn" json:"deprecatedIn"`
+	RemovedIn    string `yaml:"removedIn" json:"removedIn"`
+	// Replacement is the group/version serving the kind instead, empty if there is none
+	Replacement string `yaml:"replacement" json:"replacement"`
+}
+
+// GroupVersion returns the apiVersion of the deprecated API
+func (a API) GroupVersion() string {
+	return schema.GroupVersion{Group: a.Group, Version: a.Version}.String()
+}
+
+// APIs returns the deprecated APIs known to minikube
+func APIs() ([]API, error) {
+	var apis []API
+	if err := yaml.Unmarshal(apisYAML, &apis); err != nil {
+		return nil, errors.Wrap(err, "parsing deprecated APIs")
+	}
+	return apis, nil
+}
+
+// Finding is a resource using a deprecated API
+type Finding struct {
+	API
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	// Removed is whether the target version removed the API, the API is only deprecated otherwise
+	Removed bool `json:"removed"`
+	// Users are the field managers which wrote the resource with the deprecated API
+	Users []string `json:"users"`
+}
+
+// Find returns the resources which were written with an API deprecated or removed in the target version,
+// the removed ones first
+func Find(ctx context.Context, client dynamic.Interface, target semver.Version) ([]Finding, error) {
+	apis, err := APIs()
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	for _, a := range apis {
+		deprecated, removed, err := status(a, target)
+		if err != nil {
+			return nil, err
+		}
+		if !deprecated {
+			continue
+		}
+		f, err := find(ctx, client, a, removed)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, f...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Removed && !findings[j].Removed
+	})
+	return findings, nil
+}
+
+// status returns whether the API is deprecated, and removed, in the target version
+func status(a API, target semver.Version) (bool, bool, error) {
+	deprecatedIn, err := semver.ParseTolerant(a.DeprecatedIn)
+	if err != nil {
+		return false, false, errors.Wrapf(err, "%s %s", a.GroupVersion(), a.Kind)
+	}
+	removedIn, err := semver.ParseTolerant(a.RemovedIn)
+	if err != nil {
+		return false, false, errors.Wrapf(err, "%s %s", a.GroupVersion(), a.Kind)
+	}
+	// compare the minor versions, so that a pre-release of the target counts as the release
+	target = semver.Version{Major: target.Major, Minor: target.Minor}
+	return target.GTE(deprecatedIn), target.GTE(removedIn), nil
+}
+
+// find lists the resources of the kind of the API, and returns the ones written with the API
+func find(ctx context.Context, client dynamic.Interface, a API, removed bool) ([]Finding, error) {
+	listVersion := a.GroupVersion()
+	if a.Replacement != "" {
+		listVersion = a.Replacement
+	}
+	gv, err := schema.ParseGroupVersion(listVersion)
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.Resource(gv.WithResource(a.Resource)).List(ctx, metav1.ListOptions{})
+	if apierrors.IsNotFound(err) && a.Replacement != "" {
+		// the replacement is not served yet by older clusters (flowcontrol v1 before 1.29), the deprecated API still is
+		klog.Infof("%s %s is not served, listing with %s", listVersion, a.Resource, a.GroupVersion())
+		list, err = client.Resource(schema.GroupVersionResource{Group: a.Group, Version: a.Version, Resource: a.Resource}).List(ctx, metav1.ListOptions{})
+	}
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			klog.Warningf("%s %s is not served, the %s resources are not checked", listVersion, a.Resource, a.Kind)
+			return nil, nil
+		}
+		return nil, errors.Wrapf(err, "listing %s", a.Resource)
+	}
+	var findings []Finding
+	for i := range list.Items {
+		u := &list.Items[i]
+		users := usersOf(u, a.GroupVersion())
+		// without replacement, the resources are listed with the deprecated API and are all affected
+		if len(users) == 0 && a.Replacement == "" {
+			users = []string{"unknown"}
+		}
+		if len(users) == 0 {
+			continue
+		}
+		findings = append(findings, Finding{API: a, Namespace: u.GetNamespace(), Name: u.GetName(), Removed: removed, Users: users})
+	}
+	return findings, nil
+}
+
+// usersOf returns the field managers of the resource which used the API version, and "kubectl apply"
+// if its last applied configuration has the API version
+func usersOf(u *unstructured.Unstructured, apiVersion string) []string {
+	var users []string
+	seen := map[string]bool{}
+	for _, mf := range u.GetManagedFields() {
+		if mf.APIVersion == apiVersion && !seen[mf.Manager] {
+			seen[mf.Manager] = true
+			users = append(users, mf.Manager)
+		}
+	}
+	if last, ok := u.GetAnnotations()[corev1.LastAppliedConfigAnnotation]; ok {
+		var applied struct {
+			APIVersion string `json:"apiVersion"`
+		}
+		if err := json.Unmarshal([]byte(last), &applied); err == nil && applied.APIVersion == apiVersion {
+			users = append(users, "kubectl apply")
+		}
+	}
+	return users
+}
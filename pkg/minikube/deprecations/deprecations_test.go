@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deprecations
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestAPIs(t *testing.T) {
+	apis, err := APIs()
+	if err != nil {
+		t.Fatalf("APIs: %v", err)
+	}
+	for _, a := range apis {
+		if a.Kind == "" || a.Resource == "" {
+			t.Errorf("%s: missing kind or resource", a.GroupVersion())
+		}
+		if _, _, err := status(a, semver.MustParse("1.30.0")); err != nil {
+			t.Errorf("%s %s: %v", a.GroupVersion(), a.Kind, err)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	object := func(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetNamespace(namespace)
+		u.SetName(name)
+		return u
+	}
+	helm := object("networking.k8s.io/v1", "Ingress", "app", "web")
+	helm.SetManagedFields([]metav1.ManagedFieldsEntry{
+		{Manager: "helm", APIVersion: "networking.k8s.io/v1beta1"},
+		{Manager: "helm", APIVersion: "networking.k8s.io/v1beta1", Operation: metav1.ManagedFieldsOperationUpdate},
+		{Manager: "kube-controller-manager", APIVersion: "networking.k8s.io/v1"},
+	})
+	current := object("networking.k8s.io/v1", "Ingress", "app", "api")
+	current.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubectl", APIVersion: "networking.k8s.io/v1"}})
+	hpa := object("autoscaling/v2", "HorizontalPodAutoscaler", "app", "web")
+	hpa.SetAnnotations(map[string]string{corev1.LastAppliedConfigAnnotation: `{"apiVersion":"autoscaling/v2beta2","kind":"HorizontalPodAutoscaler"}`})
+
+	apis, err := APIs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lists := map[schema.GroupVersionResource]string{}
+	for _, a := range apis {
+		v := a.GroupVersion()
+		if a.Replacement != "" {
+			v = a.Replacement
+		}
+		gv, _ := schema.ParseGroupVersion(v)
+		lists[gv.WithResource(a.Resource)] = a.Kind + "List"
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), lists, helm, current, hpa)
+
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"1.18.0", nil},
+		{"1.21.0", []string{"deprecated Ingress app/web [helm]"}},
+		{"1.22.0", []string{"removed Ingress app/web [helm]"}},
+		{"1.25.0-rc.1", []string{"removed Ingress app/web [helm]", "deprecated HorizontalPodAutoscaler app/web [kubectl apply]"}},
+		{"1.31.0", []string{"removed HorizontalPodAutoscaler app/web [kubectl apply]", "removed Ingress app/web [helm]"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.target, func(t *testing.T) {
+			findings, err := Find(context.Background(), client, semver.MustParse(tc.target))
+			if err != nil {
+				t.Fatalf("Find: %v", err)
+			}
+			var got []string
+			for _, f := range findings {
+				s := "deprecated"
+				if f.Removed {
+					s = "removed"
+				}
+				got = append(got, s+" "+f.Kind+" "+f.Namespace+"/"+f.Name+" "+fmt.Sprint(f.Users))
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("Find() = %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("Find()[%d] = %q, want %q", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFindReplacementNotServed(t *testing.T) {
+	fs := &unstructured.Unstructured{}
+	fs.SetAPIVersion("flowcontrol.apiserver.k8s.io/v1beta3")
+	fs.SetKind("FlowSchema")
+	fs.SetName("mesh")
+	fs.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "istio", APIVersion: "flowcontrol.apiserver.k8s.io/v1beta3"}})
+
+	apis, err := APIs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lists := map[schema.GroupVersionResource]string{}
+	for _, a := range apis {
+		lists[schema.GroupVersionResource{Group: a.Group, Version: a.Version, Resource: a.Resource}] = a.Kind + "List"
+		if a.Replacement != "" {
+			gv, _ := schema.ParseGroupVersion(a.Replacement)
+			lists[gv.WithResource(a.Resource)] = a.Kind + "List"
+		}
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), lists, fs)
+	// a 1.28 cluster does not serve flowcontrol v1 yet
+	client.PrependReactor("list", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		gvr := action.GetResource()
+		if gvr.Group == "flowcontrol.apiserver.k8s.io" && gvr.Version == "v1" {
+			return true, nil, apierrors.NewNotFound(gvr.GroupResource(), "")
+		}
+		return false, nil, nil
+	})
+
+	findings, err := Find(context.Background(), client, semver.MustParse("1.29.0"))
+	if err != nil {
+		t.Fatalf("Find: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Kind != "FlowSchema" || findings[0].Name != "mesh" || findings[0].Removed {
+		t.Errorf("Find() = %+v, want the deprecated FlowSchema mesh", findings)
+	}
+}
@@ -122,17 +122,17 @@ func GithubActionRunner() bool {
 
 // ImageCacheDir returns the path in the minikube home directory to the container image cache for the current architecture
 func ImageCacheDir() string {
-	return filepath.Join(localpath.MakeMiniPath("cache", "images"), runtime.GOARCH)
+	return filepath.Join(localpath.MakeWritableCachePath("images"), runtime.GOARCH)
 }
 
 // KICCacheDir returns the path in the minikube home directory to the container node cache for the current architecture
 func KICCacheDir() string {
-	return filepath.Join(localpath.MakeMiniPath("cache", "kic"), runtime.GOARCH)
+	return filepath.Join(localpath.MakeWritableCachePath("kic"), runtime.GOARCH)
 }
 
 // ISOCacheDir returns the path in the minikube home directory to the virtual machine image cache for the current architecture
 func ISOCacheDir() string {
-	return filepath.Join(localpath.MakeMiniPath("cache", "iso"), runtime.GOARCH)
+	return filepath.Join(localpath.MakeWritableCachePath("iso"), runtime.GOARCH)
 }
 
 // SocketVMNetInstalled returns if socket_vmnet is installed
@@ -152,6 +152,15 @@ func SocketVMNetPath() string {
 	return checkSocketVMNetInstallLocations("/var/run/socket_vmnet")
 }
 
+// SocketVMNetBridgedPath returns the path of the socket of socket_vmnet running in bridged mode on iface, as its Homebrew service does
+func SocketVMNetBridgedPath(iface string) string {
+	p := viper.GetString("socket-vmnet-path")
+	if p != "" {
+		return p
+	}
+	return checkSocketVMNetInstallLocations("/var/run/socket_vmnet.bridged." + iface)
+}
+
 // SocketVMNetClientPath returns the path of socket_vmnet_client (QEMU driver only)
 func SocketVMNetClientPath() string {
 	p := viper.GetString("socket-vmnet-client-path")
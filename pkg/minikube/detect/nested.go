@@ -0,0 +1,288 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RecommendedInotifyMaxUserWatches is the fs.inotify.max_user_watches value below which kubelet and
+// file-watching workloads start failing with "too many open files" errors when nested
+const RecommendedInotifyMaxUserWatches = 524288
+
+// RecommendedInotifyMaxUserInstances is the fs.inotify.max_user_instances value below which
+// kubelet and file-watching workloads start failing when nested
+const RecommendedInotifyMaxUserInstances = 512
+
+// NestedEnvironment describes the environment minikube itself runs in
+type NestedEnvironment struct {
+	Container  string // container minikube runs in: docker, podman, lxc, kubernetes, ... (empty if none)
+	Hypervisor string // hypervisor of the VM minikube runs in: kvm, vmware, hyperv, ... (empty if none detected)
+	CIRunner   string // CI system minikube runs in: github-actions, gitlab, jenkins, ... (empty if none)
+
+	CgroupVersion           string // cgroup version of the host: v1, v2 or empty if unknown
+	RootFSType              string // filesystem type of /, e.g. overlay when minikube runs in a container
+	KVMAvailable            bool   // whether /dev/kvm exists, so that KVM based drivers may work
+	InotifyMaxUserWatches   int    // fs.inotify.max_user_watches, 0 if unknown
+	InotifyMaxUserInstances int    // fs.inotify.max_user_instances, 0 if unknown
+}
+
+// IsNested returns whether minikube runs inside another VM or container, the CI runner only telling where they come from
+func (n NestedEnvironment) IsNested() bool {
+	return n.Container != "" || n.Hypervisor != ""
+}
+
+// InContainer returns whether minikube runs inside a container
+func (n NestedEnvironment) InContainer() bool {
+	return n.Container != ""
+}
+
+// NeedsFlatCgroups returns whether kubelet has to run without QoS cgroups, which cannot be nested in a cgroup v1 container
+func (n NestedEnvironment) NeedsFlatCgroups() bool {
+	return n.InContainer() && n.CgroupVersion == "v1"
+}
+
+// OverlayRootFS returns whether the root filesystem is an overlay, on which overlay based storage drivers cannot be stacked
+func (n NestedEnvironment) OverlayRootFS() bool {
+	return n.InContainer() && n.RootFSType == "overlay"
+}
+
+// LowInotifyLimits returns whether the inotify limits are known to be below the recommended values
+func (n NestedEnvironment) LowInotifyLimits() bool {
+	return (n.InotifyMaxUserWatches > 0 && n.InotifyMaxUserWatches < RecommendedInotifyMaxUserWatches) ||
+		(n.InotifyMaxUserInstances > 0 && n.InotifyMaxUserInstances < RecommendedInotifyMaxUserInstances)
+}
+
+// String returns a human readable description of where minikube runs, e.g. "docker container in a kvm VM"
+func (n NestedEnvironment) String() string {
+	parts := []string{}
+	if n.Container != "" {
+		parts = append(parts, n.Container+" container")
+	}
+	if n.Hypervisor != "" {
+		parts = append(parts, n.Hypervisor+" VM")
+	}
+	if n.CIRunner != "" {
+		parts = append(parts, n.CIRunner+" CI runner")
+	}
+	if len(parts) == 0 {
+		return "bare metal"
+	}
+	return strings.Join(parts, " in a ")
+}
+
+var (
+	nestedOnce sync.Once
+	nested     NestedEnvironment
+)
+
+// Nested returns the detected environment minikube runs in. The result is cached.
+func Nested() NestedEnvironment {
+	nestedOnce.Do(func() {
+		nested = detectNested("/", os.Getenv)
+		nested.CgroupVersion = cgroupVersion()
+	})
+	return nested
+}
+
+// detectNested detects the environment relative to the given filesystem root, which makes it testable
+func detectNested(root string, getenv func(string) string) NestedEnvironment {
+	n := NestedEnvironment{CIRunner: ciRunner(getenv)}
+	if runtime.GOOS != "linux" {
+		return n
+	}
+	n.Container = containerType(root, getenv)
+	n.Hypervisor = hypervisorType(root)
+	n.RootFSType = rootFSType(root)
+	n.KVMAvailable = fileExists(filepath.Join(root, "dev", "kvm"))
+	n.InotifyMaxUserWatches = readIntFile(filepath.Join(root, "proc", "sys", "fs", "inotify", "max_user_watches"))
+	n.InotifyMaxUserInstances = readIntFile(filepath.Join(root, "proc", "sys", "fs", "inotify", "max_user_instances"))
+	return n
+}
+
+// ciRunner returns the name of the CI system minikube runs in, based on well-known environment variables
+func ciRunner(getenv func(string) string) string {
+	runners := []struct {
+		env  string
+		name string
+	}{
+		{"GITHUB_ACTIONS", "github-actions"},
+		{"GITLAB_CI", "gitlab"},
+		{"JENKINS_URL", "jenkins"},
+		{"CIRCLECI", "circleci"},
+		{"BUILDKITE", "buildkite"},
+		{"TRAVIS", "travis"},
+		{"TF_BUILD", "azure-pipelines"},
+		{"PROW_JOB_ID", "prow"},
+	}
+	for _, r := range runners {
+		if v := getenv(r.env); v != "" && v != "false" {
+			return r.name
+		}
+	}
+	return ""
+}
+
+// containerType returns the container technology minikube runs in, if any
+func containerType(root string, getenv func(string) string) string {
+	// set by systemd-nspawn, podman, lxc and others
+	if c := getenv("container"); c != "" {
+		return c
+	}
+	if fileExists(filepath.Join(root, ".dockerenv")) {
+		return "docker"
+	}
+	if fileExists(filepath.Join(root, "run", ".containerenv")) {
+		return "podman"
+	}
+	if getenv("KUBERNETES_SERVICE_HOST") != "" {
+		return "kubernetes"
+	}
+	cg, err := os.ReadFile(filepath.Join(root, "proc", "1", "cgroup"))
+	if err != nil {
+		return ""
+	}
+	s := string(cg)
+	switch {
+	case strings.Contains(s, "kubepods"):
+		return "kubernetes"
+	case strings.Contains(s, "docker"):
+		return "docker"
+	case strings.Contains(s, "libpod"):
+		return "podman"
+	case strings.Contains(s, "lxc"):
+		return "lxc"
+	}
+	return ""
+}
+
+// vmVendors name the hypervisor of a VM by its DMI sys_vendor, which hardware vendors that also make hypervisors share with bare metal
+var vmVendors = []struct {
+	match string
+	name  string
+}{
+	{"qemu", "kvm"},
+	{"kvm", "kvm"},
+	{"vmware", "vmware"},
+	{"microsoft", "hyperv"},
+	{"innotek", "virtualbox"},
+	{"xen", "xen"},
+	{"amazon ec2", "aws"},
+	{"google", "gce"},
+	{"parallels", "parallels"},
+	{"apple", "apple"},
+}
+
+// vmProducts are the DMI product names of VMs, which tell them apart on the architectures whose CPUs have no hypervisor flag
+var vmProducts = []struct {
+	match string
+	name  string
+}{
+	{"kvm", "kvm"},
+	{"vmware virtual platform", "vmware"},
+	{"virtual machine", "hyperv"},
+	{"virtualbox", "virtualbox"},
+	{"hvm domu", "xen"},
+	{"google compute engine", "gce"},
+	{"parallels virtual platform", "parallels"},
+	{"apple virtualization", "apple"},
+}
+
+// hypervisorType returns the hypervisor of the VM minikube runs in, if any: the CPU must have the hypervisor flag,
+// or the DMI product be a VM, as the DMI vendor alone does not tell a Surface or a metal EC2 instance from a VM
+func hypervisorType(root string) string {
+	product := dmiField(root, "product_name")
+	for _, p := range vmProducts {
+		if strings.Contains(product, p.match) {
+			return p.name
+		}
+	}
+	if !cpuFlag(root, "hypervisor") {
+		return ""
+	}
+	vendor := dmiField(root, "sys_vendor")
+	for _, v := range vmVendors {
+		if strings.Contains(vendor, v.match) {
+			return v.name
+		}
+	}
+	return "unknown"
+}
+
+// dmiField returns the DMI field of the machine in lower case, or empty if it is unknown
+func dmiField(root string, name string) string {
+	b, err := os.ReadFile(filepath.Join(root, "sys", "class", "dmi", "id", name))
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(string(b)))
+}
+
+// cpuFlag returns whether the CPU has the flag, according to /proc/cpuinfo
+func cpuFlag(root string, flag string) bool {
+	cpuinfo, err := os.ReadFile(filepath.Join(root, "proc", "cpuinfo"))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(cpuinfo), "\n") {
+		name, flags, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) != "flags" {
+			continue
+		}
+		for _, f := range strings.Fields(flags) {
+			if f == flag {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// rootFSType returns the filesystem type mounted on /, according to /proc/mounts
+func rootFSType(root string) string {
+	b, err := os.ReadFile(filepath.Join(root, "proc", "mounts"))
+	if err != nil {
+		return ""
+	}
+	fsType := ""
+	// the last mount on / is the one in effect
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && fields[1] == "/" {
+			fsType = fields[2]
+		}
+	}
+	return fsType
+}
+
+func readIntFile(path string) int {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	i, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		return 0
+	}
+	return i
+}
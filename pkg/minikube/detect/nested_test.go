@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func writeFile(t *testing.T, root string, name string, content string) {
+	t.Helper()
+	p := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatalf("write %s: %v", p, err)
+	}
+}
+
+func TestDetectNested(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("nested detection only inspects the filesystem on linux")
+	}
+
+	noEnv := func(string) string { return "" }
+
+	t.Run("bare metal", func(t *testing.T) {
+		root := t.TempDir()
+		writeFile(t, root, "proc/cpuinfo", "flags\t\t: fpu vme de pse\n")
+		writeFile(t, root, "dev/kvm", "")
+		writeFile(t, root, "proc/sys/fs/inotify/max_user_watches", "1048576\n")
+		writeFile(t, root, "proc/sys/fs/inotify/max_user_instances", "8192\n")
+
+		n := detectNested(root, noEnv)
+		if n.IsNested() {
+			t.Errorf("expected bare metal, got %s", n)
+		}
+		if !n.KVMAvailable {
+			t.Errorf("expected KVM to be available")
+		}
+		if n.LowInotifyLimits() {
+			t.Errorf("expected inotify limits to be sufficient: %+v", n)
+		}
+	})
+
+	t.Run("docker container in a kvm VM on github actions", func(t *testing.T) {
+		root := t.TempDir()
+		writeFile(t, root, ".dockerenv", "")
+		writeFile(t, root, "proc/mounts", "rootfs / rootfs rw 0 0\noverlay / overlay rw,relatime 0 0\nproc /proc proc rw 0 0\n")
+		writeFile(t, root, "sys/class/dmi/id/sys_vendor", "QEMU\n")
+		writeFile(t, root, "proc/cpuinfo", "processor\t: 0\nflags\t\t: fpu vme hypervisor lahf_lm\n")
+		writeFile(t, root, "proc/sys/fs/inotify/max_user_watches", "8192\n")
+		env := func(k string) string {
+			if k == "GITHUB_ACTIONS" {
+				return "true"
+			}
+			return ""
+		}
+
+		n := detectNested(root, env)
+		if n.Container != "docker" || n.Hypervisor != "kvm" || n.CIRunner != "github-actions" {
+			t.Errorf("unexpected detection: %+v", n)
+		}
+		if got, want := n.String(), "docker container in a kvm VM in a github-actions CI runner"; got != want {
+			t.Errorf("String() = %q, want %q", got, want)
+		}
+		if n.KVMAvailable {
+			t.Errorf("expected KVM to be unavailable")
+		}
+		if !n.OverlayRootFS() {
+			t.Errorf("expected an overlay root filesystem, got %q", n.RootFSType)
+		}
+		if !n.LowInotifyLimits() {
+			t.Errorf("expected inotify limits to be low: %+v", n)
+		}
+	})
+
+	t.Run("bare metal of a hypervisor vendor on a CI runner", func(t *testing.T) {
+		root := t.TempDir()
+		writeFile(t, root, "sys/class/dmi/id/sys_vendor", "Microsoft Corporation\n")
+		writeFile(t, root, "sys/class/dmi/id/product_name", "Surface Laptop 5\n")
+		writeFile(t, root, "proc/cpuinfo", "flags\t\t: fpu vme de pse\n")
+		env := func(k string) string {
+			if k == "CI" {
+				return "true"
+			}
+			return ""
+		}
+
+		n := detectNested(root, env)
+		if n.IsNested() || n.CIRunner != "" {
+			t.Errorf("expected bare metal, got %+v", n)
+		}
+	})
+
+	t.Run("arm64 VM detected by product name", func(t *testing.T) {
+		root := t.TempDir()
+		writeFile(t, root, "sys/class/dmi/id/product_name", "KVM Virtual Machine\n")
+		writeFile(t, root, "proc/cpuinfo", "Features\t: fp asimd evtstrm\n")
+
+		if n := detectNested(root, noEnv); n.Hypervisor != "kvm" {
+			t.Errorf("unexpected detection: %+v", n)
+		}
+	})
+
+	t.Run("kubernetes pod detected by cgroup", func(t *testing.T) {
+		root := t.TempDir()
+		writeFile(t, root, "proc/1/cgroup", "0::/kubepods/besteffort/pod1234\n")
+		writeFile(t, root, "proc/cpuinfo", "flags\t\t: fpu vme hypervisor lahf_lm\n")
+
+		n := detectNested(root, noEnv)
+		if n.Container != "kubernetes" || n.Hypervisor != "unknown" {
+			t.Errorf("unexpected detection: %+v", n)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package detect
+
+import (
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MinNvidiaDriverVersion is the oldest NVIDIA driver running the CUDA 12 user space of the device plugin and the smoke test
+const MinNvidiaDriverVersion = "525.60.13"
+
+// MinCUDAVersion is the oldest CUDA version the NVIDIA driver must support, for the CUDA 12 user space of the containers
+const MinCUDAVersion = "12.0"
+
+// MinNvidiaToolkitVersion is the oldest NVIDIA Container Toolkit whose nvidia-ctk can configure the runtimes of the node
+const MinNvidiaToolkitVersion = "1.14.0"
+
+// NvidiaHost describes the NVIDIA driver and container toolkit installed on the host
+type NvidiaHost struct {
+	DriverVersion  string   // version of the kernel driver, empty if nvidia-smi is missing or fails
+	GPUs           []string // names of the GPUs nvidia-smi lists
+	CUDAVersion    string   // highest CUDA version the driver supports, empty if nvidia-smi is missing or fails
+	ToolkitVersion string   // version of the NVIDIA Container Toolkit, empty if nvidia-ctk is missing
+	DockerRuntime  bool     // whether the docker daemon of the host has the nvidia runtime
+}
+
+// Nvidia detects the NVIDIA driver and container toolkit of the host
+func Nvidia() NvidiaHost {
+	var h NvidiaHost
+	if o, err := exec.Command("nvidia-smi", "--query-gpu=driver_version,name", "--format=csv,noheader").Output(); err == nil {
+		h.DriverVersion, h.GPUs = parseNvidiaSMI(string(o))
+	}
+	if o, err := exec.Command("nvidia-smi").Output(); err == nil {
+		h.CUDAVersion = parseCUDAVersion(string(o))
+	}
+	if o, err := exec.Command("nvidia-ctk", "--version").Output(); err == nil {
+		h.ToolkitVersion = ParseNvidiaCTKVersion(string(o))
+	}
+	if o, err := exec.Command("docker", "info", "--format", "{{json .Runtimes}}").Output(); err == nil {
+		h.DockerRuntime = strings.Contains(string(o), `"nvidia"`)
+	}
+	return h
+}
+
+// parseNvidiaSMI parses the "driver_version, name" CSV lines of nvidia-smi, one per GPU
+func parseNvidiaSMI(s string) (string, []string) {
+	version := ""
+	gpus := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		fields := strings.SplitN(line, ",", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		version = strings.TrimSpace(fields[0])
+		gpus = append(gpus, strings.TrimSpace(fields[1]))
+	}
+	return version, gpus
+}
+
+var cudaVersionRe = regexp.MustCompile(`CUDA Version: (\d+\.\d+)`)
+
+// parseCUDAVersion parses the CUDA version of the header of nvidia-smi, like "Driver Version: 535.129.03   CUDA Version: 12.2"
+func parseCUDAVersion(s string) string {
+	m := cudaVersionRe.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+var nvidiaCTKVersionRe = regexp.MustCompile(`version (\d+\.\d+\.\d+)`)
+
+// ParseNvidiaCTKVersion parses the output of nvidia-ctk --version, like "NVIDIA Container Toolkit CLI version 1.14.3"
+func ParseNvidiaCTKVersion(s string) string {
+	m := nvidiaCTKVersionRe.FindStringSubmatch(s)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// VersionAtLeast returns whether the dot separated numeric version have is at least want.
+// NVIDIA versions like 535.129.03 are not semantic versions, as they have leading zeros.
+func VersionAtLeast(have, want string) bool {
+	h := strings.Split(have, ".")
+	w := strings.Split(want, ".")
+	for i := range w {
+		if i >= len(h) {
+			return false
+		}
+		hn, err := strconv.Atoi(h[i])
+		if err != nil {
+			return false
+		}
+		wn, _ := strconv.Atoi(w[i])
+		if hn != wn {
+			return hn > wn
+		}
+	}
+	return true
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package detect
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNvidiaSMI(t *testing.T) {
+	version, gpus := parseNvidiaSMI("535.129.03, NVIDIA GeForce RTX 3090\n535.129.03, Tesla T4\n")
+	if version != "535.129.03" {
+		t.Errorf("driver version = %q, want 535.129.03", version)
+	}
+	if want := []string{"NVIDIA GeForce RTX 3090", "Tesla T4"}; !reflect.DeepEqual(gpus, want) {
+		t.Errorf("gpus = %v, want %v", gpus, want)
+	}
+	if v := parseCUDAVersion("| NVIDIA-SMI 535.129.03             Driver Version: 535.129.03   CUDA Version: 12.2     |\n"); v != "12.2" {
+		t.Errorf("CUDA version = %q, want 12.2", v)
+	}
+	if v := ParseNvidiaCTKVersion("NVIDIA Container Toolkit CLI version 1.14.3\ncommit: 53b24618a542025b108239fe602e66e912b7d6e2\n"); v != "1.14.3" {
+		t.Errorf("toolkit version = %q, want 1.14.3", v)
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		have, want string
+		expected   bool
+	}{
+		{"535.129.03", MinNvidiaDriverVersion, true},
+		{"525.60.13", MinNvidiaDriverVersion, true},
+		{"525.60.2", MinNvidiaDriverVersion, false},
+		{"470.223.02", MinNvidiaDriverVersion, false},
+		{"1.13.5", MinNvidiaToolkitVersion, false},
+		{"1.14", MinNvidiaToolkitVersion, false},
+		{"", MinNvidiaToolkitVersion, false},
+		{"12.2", MinCUDAVersion, true},
+		{"11.8", MinCUDAVersion, false},
+	}
+	for _, tc := range tests {
+		if got := VersionAtLeast(tc.have, tc.want); got != tc.expected {
+			t.Errorf("VersionAtLeast(%q, %q) = %t, want %t", tc.have, tc.want, got, tc.expected)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"path"
 	"runtime"
 
+	"k8s.io/minikube/pkg/minikube/cachegc"
 	"k8s.io/minikube/pkg/minikube/detect"
 
 	"github.com/blang/semver/v4"
@@ -55,7 +56,7 @@ func binaryWithChecksumURL(binaryName, version, osName, archName, binaryURL stri
 
 // Binary will download a binary onto the host
 func Binary(binary, version, osName, archName, binaryURL string) (string, error) {
-	targetDir := localpath.MakeMiniPath("cache", osName, archName, version)
+	targetDir := localpath.MakeCachePath(osName, archName, version)
 	targetFilepath := path.Join(targetDir, binary)
 	targetLock := targetFilepath + ".lock"
 
@@ -74,6 +75,7 @@ func Binary(binary, version, osName, archName, binaryURL string) (string, error)
 
 	if _, err := checkCache(targetFilepath); err == nil {
 		klog.Infof("Not caching binary, using %s", url)
+		cachegc.Touch(targetFilepath)
 		return targetFilepath, nil
 	}
 
@@ -19,11 +19,14 @@ package download
 import (
 	"flag"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	"github.com/hashicorp/go-getter"
 	"github.com/juju/mutex/v2"
 	"github.com/pkg/errors"
@@ -31,9 +34,14 @@ import (
 	"k8s.io/minikube/pkg/minikube/detect"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/transfer"
 	"k8s.io/minikube/pkg/util/lock"
+	"k8s.io/minikube/pkg/util/retry"
 )
 
+// maxAttempts is the number of attempts of a download before it fails
+const maxAttempts = 4
+
 var (
 	// DownloadMock is called instead of the download implementation if not nil.
 	DownloadMock func(src, dst string) error
@@ -44,8 +52,26 @@ var (
 
 	releaseHost = "dl.k8s.io"
 	releasePath = ""
+
+	// offline makes the downloads fail rather than reach the network, for air-gapped hosts
+	offline = false
 )
 
+// SetOffline makes minikube use only the cached artifacts, failing instead of downloading the missing ones
+func SetOffline() {
+	offline = true
+}
+
+// Offline returns whether minikube must not reach the network
+func Offline() bool {
+	return offline
+}
+
+// errOffline returns the error of an artifact missing from the cache while offline
+func errOffline(artifact string) error {
+	return errors.Errorf("%s is not in the cache and minikube is offline, import a bundle holding it with 'minikube bundle import'", artifact)
+}
+
 // SetAliyunMirror set the download host for Aliyun mirror
 func SetAliyunMirror() {
 	downloadHost = aliyunMirror
@@ -64,36 +90,87 @@ func CreateDstDownloadMock(_, dst string) error {
 	return err
 }
 
+// progressTracker returns the progress bar of the downloads, nil if it is not displayed
+func progressTracker() getter.ProgressTracker {
+	if !out.IsTerminal(os.Stdout) || detect.GithubActionRunner() {
+		return nil
+	}
+	if out.JSON {
+		return DefaultJSONOutput
+	}
+	return DefaultProgressBar
+}
+
 // download is a well-configured atomic download function
 func download(src, dst string) error {
-	var clientOptions []getter.ClientOption
-	if out.IsTerminal(os.Stdout) && !detect.GithubActionRunner() {
-		progress := getter.WithProgress(DefaultProgressBar)
-		if out.JSON {
-			progress = getter.WithProgress(DefaultJSONOutput)
-		}
-		clientOptions = []getter.ClientOption{progress}
-	} else {
-		clientOptions = []getter.ClientOption{}
+	if err := preflight(src, dst); err != nil || DownloadMock != nil {
+		return err
 	}
-	tmpDst := dst + ".download"
-	client := &getter.Client{
-		Src:     src,
-		Dst:     tmpDst,
-		Dir:     false,
-		Mode:    getter.ClientModeFile,
-		Options: clientOptions,
-		Getters: map[string]getter.Getter{
-			"file":  &getter.FileGetter{Copy: false},
-			"http":  &getter.HttpGetter{Netrc: false},
-			"https": &getter.HttpGetter{Netrc: false},
-		},
+	return getterDownload(src, dst)
+}
+
+// release downloads the release artifact at url into the cache path dst unless it is cached, checking it against the SHA-256
+// published next to it, and calls step before downloading
+func release(url, dst string, step func()) error {
+	releaser, err := lockDownload(dst + ".lock")
+	if releaser != nil {
+		defer releaser.Release()
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := checkCache(dst); err == nil {
+		klog.Infof("Not caching %s, using %s", url, dst)
+		return nil
+	}
+
+	step()
+	if err := download(url+"?checksum=file:"+url+".sha256", dst); err != nil {
+		return errors.Wrapf(err, "download failed: %s", url)
+	}
+	return nil
+}
+
+// downloadInParts downloads src like download, fetching its parts concurrently if it is served over http(s) with ranges,
+// and verifying the checksum parameter of src before the download is renamed to dst
+func downloadInParts(src, dst string) error {
+	if err := preflight(src, dst); err != nil || DownloadMock != nil {
+		return err
+	}
+	u, err := url.Parse(src)
+	if err != nil {
+		return errors.Wrapf(err, "parsing %s", src)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return getterDownload(src, dst)
+	}
+	q := u.Query()
+	checksum := q.Get("checksum")
+	// the checksums of files are fetched by go-getter
+	if typ, _, _ := strings.Cut(checksum, ":"); checksum != "" && checksumHashes[typ] == nil {
+		return getterDownload(src, dst)
+	}
+	q.Del("checksum")
+	u.RawQuery = q.Encode()
+	err = fetchParts(u.String(), dst, checksum)
+	if err == errNoRanges {
+		klog.Infof("%s is not served in parts, downloading it at once", src)
+		return getterDownload(src, dst)
 	}
+	return err
+}
 
+// preflight prepares the download of src to dst, running the mock download if one is set
+func preflight(src, dst string) error {
 	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
 		return errors.Wrap(err, "mkdir")
 	}
 
+	if offline {
+		return errOffline(src)
+	}
+
 	if DownloadMock != nil {
 		klog.Infof("Mock download: %s -> %s", src, dst)
 		return DownloadMock(src, dst)
@@ -103,9 +180,47 @@ func download(src, dst string) error {
 	if withinUnitTest() {
 		return fmt.Errorf("unmocked download under test")
 	}
+	return nil
+}
+
+// getterDownload downloads src with go-getter, retrying the failed attempts, which resume the partial download left by the previous one
+func getterDownload(src, dst string) error {
+	var clientOptions []getter.ClientOption
+	if p := progressTracker(); p != nil {
+		clientOptions = []getter.ClientOption{getter.WithProgress(p)}
+	} else {
+		clientOptions = []getter.ClientOption{}
+	}
+	httpClient := &http.Client{Transport: transfer.Transport(nil)}
+	tmpDst := dst + ".download"
+	client := &getter.Client{
+		Src:     src,
+		Dst:     tmpDst,
+		Dir:     false,
+		Mode:    getter.ClientModeFile,
+		Options: clientOptions,
+		Getters: map[string]getter.Getter{
+			"file":  &getter.FileGetter{Copy: false},
+			"http":  &getter.HttpGetter{Netrc: false, Client: httpClient},
+			"https": &getter.HttpGetter{Netrc: false, Client: httpClient},
+		},
+	}
 
 	klog.Infof("Downloading: %s -> %s", src, dst)
-	if err := client.Get(); err != nil {
+	attempt := 0
+	get := func() error {
+		attempt++
+		err := client.Get()
+		if err != nil {
+			klog.Warningf("attempt %d of the download of %s failed: %v", attempt, src, err)
+			if strings.Contains(err.Error(), "bad response code: 4") {
+				// a new attempt won't find what the server does not have
+				return backoff.Permanent(err)
+			}
+		}
+		return err
+	}
+	if err := retry.Expo(get, 2*time.Second, 2*time.Minute, maxAttempts-1); err != nil {
 		return errors.Wrapf(err, "getter: %+v", client)
 	}
 	return os.Rename(tmpDst, dst)
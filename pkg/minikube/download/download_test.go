@@ -20,11 +20,14 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/localpath"
 )
 
 // Force download tests to run in serial.
@@ -36,6 +39,8 @@ func TestDownload(t *testing.T) {
 	t.Run("PreloadChecksumMismatch", testPreloadChecksumMismatch)
 	t.Run("PreloadExistsCaching", testPreloadExistsCaching)
 	t.Run("PreloadWithCachedSizeZero", testPreloadWithCachedSizeZero)
+	t.Run("PreloadFrom", testPreloadFrom)
+	t.Run("WasmShimChecksum", testWasmShimChecksum)
 }
 
 // Returns a mock function that sleeps before incrementing `downloadsCounter` and creates the requested file.
@@ -242,3 +247,73 @@ func testPreloadWithCachedSizeZero(t *testing.T) {
 		t.Errorf("Expected only 1 download attempt but got %v!", downloadNum)
 	}
 }
+
+func testPreloadFrom(t *testing.T) {
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+	downloadNum := 0
+	DownloadMock = func(src, dst string) error {
+		if err := mockSleepDownload(&downloadNum)(src, dst); err != nil {
+			return err
+		}
+		return os.WriteFile(dst, []byte("preload"), 0644)
+	}
+	checkCache = os.Stat
+
+	url := "https://example.com/ci/preload.tar.lz4?checksum=sha256:abc"
+	p := CustomTarballPath(url)
+	if filepath.Dir(p) != filepath.Join(targetDir(), "custom") || !strings.HasSuffix(p, "-preload.tar.lz4") {
+		t.Errorf("CustomTarballPath(%s) = %s", url, p)
+	}
+	var group sync.WaitGroup
+	group.Add(2)
+	dlCall := func() {
+		if err := PreloadFrom(url); err != nil {
+			t.Errorf("Failed to download preload: %+v", err)
+		}
+		group.Done()
+	}
+	go dlCall()
+	go dlCall()
+	group.Wait()
+	if downloadNum != 1 {
+		t.Errorf("Expected only 1 download attempt but got %v!", downloadNum)
+	}
+
+	file := filepath.Join(t.TempDir(), "preload.tar.lz4")
+	if CustomTarballPath(file) != file {
+		t.Errorf("CustomTarballPath(%s) = %s, want the file itself", file, CustomTarballPath(file))
+	}
+	if err := PreloadFrom(file); err == nil {
+		t.Errorf("PreloadFrom(%s) of a missing file succeeded", file)
+	}
+	if p, ok := PreloadTarball(constants.DefaultKubernetesVersion, constants.Docker, "docker", file); p != file || !ok {
+		t.Errorf("PreloadTarball() = %s, %t, want %s, true", p, ok, file)
+	}
+	if _, ok := PreloadTarball(constants.DefaultKubernetesVersion, constants.Docker, "none", file); ok {
+		t.Errorf("PreloadTarball() of the none driver found a preload")
+	}
+}
+
+func testWasmShimChecksum(t *testing.T) {
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+	var srcs []string
+	DownloadMock = func(src, dst string) error {
+		srcs = append(srcs, src)
+		return CreateDstDownloadMock(src, dst)
+	}
+	checkCache = os.Stat
+
+	p, err := WasmShim("wasmtime", "amd64")
+	if err != nil {
+		t.Fatalf("WasmShim: %v", err)
+	}
+	if _, err := WasmShim("wasmtime", "amd64"); err != nil {
+		t.Fatalf("WasmShim of a cached shim: %v", err)
+	}
+	if len(srcs) != 1 || !strings.HasSuffix(srcs[0], "?checksum=file:"+strings.Split(srcs[0], "?")[0]+".sha256") {
+		t.Errorf("downloaded %v, expected the shim once with its checksum", srcs)
+	}
+	if filepath.Base(p) != filepath.Base(strings.Split(srcs[0], "?")[0]) {
+		t.Errorf("WasmShim() = %s, downloaded %s", p, srcs[0])
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package download
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/sandbox"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// GVisor downloads the gVisor binaries for arch into the cache, returning their paths keyed by name
+func GVisor(arch string) (map[string]string, error) {
+	paths := map[string]string{}
+	for _, b := range sandbox.GVisorBinaries {
+		p, err := gvisorBinary(b, arch)
+		if err != nil {
+			return nil, err
+		}
+		paths[b] = p
+	}
+	return paths, nil
+}
+
+// gvisorBinary downloads the gVisor binary b for arch into the cache, returning its path
+func gvisorBinary(b, arch string) (string, error) {
+	url, err := sandbox.GVisorURL(b, arch)
+	if err != nil {
+		return "", err
+	}
+	targetFilepath := localpath.MakeCachePath("linux", arch, "gvisor", sandbox.GVisorVersion, b)
+
+	releaser, err := lockDownload(targetFilepath + ".lock")
+	if releaser != nil {
+		defer releaser.Release()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := checkCache(targetFilepath); err == nil {
+		klog.Infof("Not caching %s, using %s", b, targetFilepath)
+		return targetFilepath, nil
+	}
+
+	out.Step(style.FileDownload, "Downloading {{.binary}} of gVisor {{.version}} ...", out.V{"binary": b, "version": sandbox.GVisorVersion})
+	if err := download(url, targetFilepath); err != nil {
+		return "", errors.Wrapf(err, "download failed: %s", url)
+	}
+	return targetFilepath, nil
+}
@@ -34,7 +34,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
-	"k8s.io/minikube/pkg/minikube/detect"
+	"k8s.io/minikube/pkg/minikube/cachegc"
 	"k8s.io/minikube/pkg/minikube/image"
 	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/out"
@@ -50,11 +50,16 @@ var (
 
 // imagePathInCache returns path in local cache directory
 func imagePathInCache(img string) string {
-	f := filepath.Join(detect.KICCacheDir(), path.Base(img)+".tar")
+	f := localpath.MakeCachePath("kic", runtime.GOARCH, path.Base(img)+".tar")
 	f = localpath.SanitizeCacheDir(f)
 	return f
 }
 
+// ImagePathInCache returns the path of the tarball of the kic image img in the local cache directory
+func ImagePathInCache(img string) string {
+	return imagePathInCache(img)
+}
+
 // ImageExistsInCache if img exist in local cache directory
 func ImageExistsInCache(img string) bool {
 	f := imagePathInCache(img)
@@ -103,9 +108,14 @@ func ImageToCache(img string) error {
 
 	if checkImageExistsInCache(img) {
 		klog.Infof("%s exists in cache, skipping pull", img)
+		cachegc.Touch(f)
 		return nil
 	}
 
+	if offline {
+		return errOffline(img)
+	}
+
 	if err := os.MkdirAll(filepath.Dir(f), 0777); err != nil {
 		return errors.Wrapf(err, "making cache image directory: %s", f)
 	}
@@ -29,7 +29,8 @@ import (
 	"github.com/juju/mutex/v2"
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
-	"k8s.io/minikube/pkg/minikube/detect"
+	"k8s.io/minikube/pkg/minikube/cachegc"
+	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/style"
 	"k8s.io/minikube/pkg/util/lock"
@@ -77,7 +78,19 @@ func localISOPath(u *url.URL) string {
 		return u.String()
 	}
 
-	return filepath.Join(detect.ISOCacheDir(), path.Base(u.Path))
+	return localpath.MakeCachePath("iso", runtime.GOARCH, path.Base(u.Path))
+}
+
+// LocalISOPath returns the path of the cached ISO of a remote isoURL
+func LocalISOPath(isoURL string) (string, error) {
+	u, err := url.Parse(isoURL)
+	if err != nil {
+		return "", errors.Wrapf(err, "url.parse %q", isoURL)
+	}
+	if u.Scheme == fileScheme {
+		return "", errors.Errorf("%s is not a remote ISO", isoURL)
+	}
+	return localISOPath(u), nil
 }
 
 // ISO downloads and returns the path to the downloaded ISO
@@ -130,6 +143,7 @@ func downloadISO(isoURL string, skipChecksum bool) error {
 	defer releaser.Release()
 
 	if _, err := os.Stat(dst); err == nil {
+		cachegc.Touch(dst)
 		return nil
 	}
 
@@ -0,0 +1,273 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package download
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/transfer"
+	"k8s.io/minikube/pkg/util/retry"
+)
+
+const (
+	// minPartSize is the smallest part fetched by its own request
+	minPartSize = 16 * 1024 * 1024
+	// saveEvery is how many bytes a part fetches between the saves of the progress of the download
+	saveEvery = 4 * 1024 * 1024
+)
+
+// checksumHashes are the hashes of the checksums verified by fetchParts, by their type in go-getter
+var checksumHashes = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// errNoRanges is returned by fetchParts when the server can't serve parts of the file
+var errNoRanges = errors.New("ranges not supported")
+
+// partsState is the progress of a download in parts, saved next to it so an interrupted download resumes
+type partsState struct {
+	// Size and ETag identify the remote file, whose change restarts the download
+	Size int64  `json:"size"`
+	ETag string `json:"etag"`
+	// PartSize is the size of the parts, which are fetched at the same offsets when the download resumes
+	PartSize int64 `json:"partSize"`
+	// Done are the bytes fetched of every part
+	Done []int64 `json:"done"`
+}
+
+// partsLayout returns the number and the size of the parts of a file of size bytes
+func partsLayout(size int64) (int, int64) {
+	n := int64(transfer.Parallelism())
+	if most := (size + minPartSize - 1) / minPartSize; n > most {
+		n = most
+	}
+	if n < 1 {
+		n = 1
+	}
+	return int(n), (size + n - 1) / n
+}
+
+// loadPartsState returns the saved progress of the download of the remote file, or a new one if it changed.
+// The saved progress keeps its part size, so that a download resumed with another parallelism writes its parts at the same offsets.
+func loadPartsState(path string, size int64, etag string) *partsState {
+	st := &partsState{}
+	if b, err := os.ReadFile(path); err == nil && json.Unmarshal(b, st) == nil && st.Size == size && st.ETag == etag &&
+		st.PartSize > 0 && int64(len(st.Done)) == (size+st.PartSize-1)/st.PartSize {
+		klog.Infof("resuming the download of %s", path)
+		return st
+	}
+	n, partSize := partsLayout(size)
+	return &partsState{Size: size, ETag: etag, PartSize: partSize, Done: make([]int64, n)}
+}
+
+// fetchParts downloads the http(s) src to dst in parts fetched concurrently, resuming the parts fetched by an earlier attempt.
+// The download is only renamed to dst if it matches the checksum, in the format of go-getter, if one is given.
+func fetchParts(src, dst, checksum string) error {
+	client := &http.Client{Transport: transfer.Transport(nil)}
+	resp, err := client.Head(src)
+	if err != nil {
+		return errNoRanges
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Accept-Ranges") != "bytes" || resp.ContentLength <= 0 {
+		return errNoRanges
+	}
+	size := resp.ContentLength
+
+	tmpDst := dst + ".download"
+	statePath := tmpDst + ".parts"
+	st := loadPartsState(statePath, size, resp.Header.Get("ETag"))
+	f, err := os.OpenFile(tmpDst, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := f.Truncate(size); err != nil {
+		return errors.Wrap(err, "truncate")
+	}
+
+	var mu sync.Mutex
+	save := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		b, _ := json.Marshal(st)
+		if err := os.WriteFile(statePath, b, 0644); err != nil {
+			klog.Warningf("failed to save the progress of the download of %s: %v", src, err)
+		}
+	}
+
+	// the progress bar reads a copy of the fetched bytes
+	var done int64
+	for _, d := range st.Done {
+		done += d
+	}
+	pw := io.Discard
+	var progress io.ReadCloser
+	if p := progressTracker(); p != nil {
+		pr, w := io.Pipe()
+		pw = w
+		progress = p.TrackProgress(src, done, size, pr)
+		go func() {
+			_, _ = io.Copy(io.Discard, progress)
+		}()
+		defer func() {
+			w.Close()
+			progress.Close()
+		}()
+	}
+
+	klog.Infof("Downloading in %d parts: %s -> %s", len(st.Done), src, dst)
+	partSize := st.PartSize
+	var g errgroup.Group
+	for i := range st.Done {
+		i := i
+		g.Go(func() error {
+			start := int64(i) * partSize
+			end := start + partSize
+			if end > size {
+				end = size
+			}
+			fetch := func() error {
+				mu.Lock()
+				offset := start + st.Done[i]
+				mu.Unlock()
+				if offset >= end {
+					return nil
+				}
+				return fetchPart(client, src, f, offset, end, func(n int, b []byte) {
+					mu.Lock()
+					before := st.Done[i] / saveEvery
+					st.Done[i] += int64(n)
+					_, _ = pw.Write(b)
+					after := st.Done[i] / saveEvery
+					mu.Unlock()
+					if after != before {
+						save()
+					}
+				})
+			}
+			return retry.Expo(fetch, 2*time.Second, 2*time.Minute, maxAttempts-1)
+		})
+	}
+	err = g.Wait()
+	save()
+	if err != nil {
+		return errors.Wrapf(err, "downloading %s", src)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err, "close")
+	}
+	if err := matchChecksum(tmpDst, checksum); err != nil {
+		// the corrupt parts are fetched again by the next attempt
+		for _, p := range []string{tmpDst, statePath} {
+			if rerr := os.Remove(p); rerr != nil {
+				klog.Warningf("removing %s: %v", p, rerr)
+			}
+		}
+		return errors.Wrapf(err, "downloading %s", src)
+	}
+	if err := os.Rename(tmpDst, dst); err != nil {
+		return errors.Wrap(err, "rename")
+	}
+	return os.Remove(statePath)
+}
+
+// fetchPart writes the bytes [offset, end) of src to f, calling fetched with the bytes fetched as they are written
+func fetchPart(client *http.Client, src string, f *os.File, offset, end int64, fetched func(int, []byte)) error {
+	req, err := http.NewRequest("GET", src, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, end-1))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("bad response code to the range %d-%d: %d", offset, end-1, resp.StatusCode)
+	}
+	buf := make([]byte, 32*1024)
+	for offset < end {
+		n, rerr := resp.Body.Read(buf)
+		if int64(n) > end-offset {
+			n = int(end - offset)
+		}
+		if n > 0 {
+			if _, err := f.WriteAt(buf[:n], offset); err != nil {
+				return err
+			}
+			offset += int64(n)
+			fetched(n, buf[:n])
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return rerr
+		}
+	}
+	if offset < end {
+		return fmt.Errorf("the range ended %d bytes early", end-offset)
+	}
+	return nil
+}
+
+// matchChecksum returns an error if the file at path does not match the checksum TYPE:HEX, in the format of go-getter, if one is given
+func matchChecksum(path, checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+	typ, want, _ := strings.Cut(checksum, ":")
+	newHash, ok := checksumHashes[typ]
+	if !ok {
+		return fmt.Errorf("unsupported checksum type %q", typ)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := newHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return errors.Wrap(err, "checksum")
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, want) {
+		return fmt.Errorf("checksums did not match for %s, expected: %s, got: %s", path, want, got)
+	}
+	return nil
+}
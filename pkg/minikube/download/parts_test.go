@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package download
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rangeServer serves content in ranges, recording the ranges requested
+func rangeServer(t *testing.T, content []byte) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var ranges []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			mu.Lock()
+			ranges = append(ranges, r.Header.Get("Range"))
+			mu.Unlock()
+		}
+		http.ServeContent(w, r, "preload.tar.lz4", time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(s.Close)
+	return s, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return ranges
+	}
+}
+
+func TestFetchParts(t *testing.T) {
+	content := make([]byte, 3*minPartSize+5)
+	rand.New(rand.NewSource(0)).Read(content)
+	s, ranges := rangeServer(t, content)
+
+	sum := sha256.Sum256(content)
+	dst := filepath.Join(t.TempDir(), "preload.tar.lz4")
+	if err := fetchParts(s.URL+"/preload.tar.lz4", dst, "sha256:"+hex.EncodeToString(sum[:])); err != nil {
+		t.Fatalf("fetchParts: %v", err)
+	}
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("the downloaded file differs from the served one")
+	}
+	if n := len(ranges()); n != 4 {
+		t.Errorf("fetchParts made %d requests %v, want 4", n, ranges())
+	}
+	if _, err := os.Stat(dst + ".download.parts"); !os.IsNotExist(err) {
+		t.Errorf("the progress of the download was not removed: %v", err)
+	}
+}
+
+func TestFetchPartsResumes(t *testing.T) {
+	content := make([]byte, 2*minPartSize)
+	rand.New(rand.NewSource(0)).Read(content)
+	s, ranges := rangeServer(t, content)
+
+	// an earlier attempt fetched the first part, and half of the second one
+	dst := filepath.Join(t.TempDir(), "preload.tar.lz4")
+	tmp := make([]byte, len(content))
+	copy(tmp, content[:minPartSize+minPartSize/2])
+	if err := os.WriteFile(dst+".download", tmp, 0644); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(partsState{Size: int64(len(content)), PartSize: minPartSize, Done: []int64{minPartSize, minPartSize / 2}})
+	if err := os.WriteFile(dst+".download.parts", b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fetchParts(s.URL+"/preload.tar.lz4", dst, ""); err != nil {
+		t.Fatalf("fetchParts: %v", err)
+	}
+	got, _ := os.ReadFile(dst)
+	if !bytes.Equal(got, content) {
+		t.Errorf("the resumed file differs from the served one")
+	}
+	want := []string{"bytes=25165824-33554431"}
+	if r := ranges(); strings.Join(r, " ") != strings.Join(want, " ") {
+		t.Errorf("fetchParts requested %v, want %v", r, want)
+	}
+}
+
+func TestFetchPartsChecksumMismatch(t *testing.T) {
+	content := make([]byte, minPartSize)
+	rand.New(rand.NewSource(0)).Read(content)
+	s, _ := rangeServer(t, content)
+
+	dst := filepath.Join(t.TempDir(), "preload.tar.lz4")
+	if err := fetchParts(s.URL+"/preload.tar.lz4", dst, "md5:00000000000000000000000000000000"); err == nil {
+		t.Fatalf("fetchParts of a file not matching its checksum succeeded")
+	}
+	for _, p := range []string{dst, dst + ".download", dst + ".download.parts"} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s was left after the checksum mismatch: %v", p, err)
+		}
+	}
+}
+
+func TestLoadPartsStateOtherPartSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "preload.tar.lz4.download.parts")
+	size := int64(4 * minPartSize)
+	// saved by an attempt with a parallelism of 2
+	b, _ := json.Marshal(partsState{Size: size, PartSize: 2 * minPartSize, Done: []int64{minPartSize, 0}})
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	st := loadPartsState(path, size, "")
+	if st.PartSize != 2*minPartSize || len(st.Done) != 2 || st.Done[0] != minPartSize {
+		t.Errorf("loadPartsState() = %+v, want the saved part size and progress", st)
+	}
+
+	// saved without its part size, by an older minikube
+	b, _ = json.Marshal(partsState{Size: size, Done: []int64{minPartSize, 0}})
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	st = loadPartsState(path, size, "")
+	if st.Done[0] != 0 {
+		t.Errorf("loadPartsState() = %+v, want the download started over", st)
+	}
+}
+
+func TestFetchPartsWithoutRanges(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("no ranges"))
+	}))
+	defer s.Close()
+	if err := fetchParts(s.URL, filepath.Join(t.TempDir(), "file"), ""); err != errNoRanges {
+		t.Errorf("fetchParts = %v, want %v", err, errNoRanges)
+	}
+}
+
+func TestPartsLayout(t *testing.T) {
+	tests := []struct {
+		size  int64
+		parts int
+		part  int64
+	}{
+		{1, 1, 1},
+		{minPartSize, 1, minPartSize},
+		{minPartSize + 1, 2, minPartSize/2 + 1},
+		{100 * minPartSize, 4, 25 * minPartSize},
+	}
+	for _, tc := range tests {
+		if n, part := partsLayout(tc.size); n != tc.parts || part != tc.part {
+			t.Errorf("partsLayout(%d) = %d, %d, want %d, %d", tc.size, n, part, tc.parts, tc.part)
+		}
+	}
+}
@@ -19,16 +19,17 @@ package download
 import (
 	"context"
 	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"net/http"
 	"os"
 	"path"
-	"path/filepath"
 	"strings"
 
 	"cloud.google.com/go/storage"
 	"google.golang.org/api/option"
+	"k8s.io/minikube/pkg/minikube/cachegc"
 	"k8s.io/minikube/pkg/minikube/detect"
 
 	"github.com/pkg/errors"
@@ -44,9 +45,12 @@ const (
 	// PreloadVersion is the current version of the preloaded tarball
 	//
 	// NOTE: You may need to bump this version up when upgrading auxiliary docker images
-	PreloadVersion = "v18"
+	PreloadVersion = "v20"
 	// PreloadBucket is the name of the GCS bucket where preloaded volume tarballs exist
 	PreloadBucket = "minikube-preloaded-volume-tarballs"
+	// PreloadCompressor is the program tar compresses the preloaded tarballs with.
+	// pzstd writes independent zstd frames, which it decompresses in parallel.
+	PreloadCompressor = "pzstd -19"
 )
 
 var (
@@ -65,7 +69,12 @@ func TarballName(k8sVersion, containerRuntime string) string {
 		storageDriver = "overlay2"
 	}
 	arch := detect.EffectiveArch()
-	return fmt.Sprintf("preloaded-images-k8s-%s-%s-%s-%s-%s.tar.lz4", PreloadVersion, k8sVersion, containerRuntime, storageDriver, arch)
+	return fmt.Sprintf("preloaded-images-k8s-%s-%s-%s-%s-%s.tar.zst", PreloadVersion, k8sVersion, containerRuntime, storageDriver, arch)
+}
+
+// IsLZ4 returns whether the preload tarball p is compressed with lz4, like the tarballs before PreloadVersion v20, rather than zstd
+func IsLZ4(p string) bool {
+	return strings.HasSuffix(p, ".lz4")
 }
 
 // returns the name of the checksum file
@@ -73,19 +82,19 @@ func checksumName(k8sVersion, containerRuntime string) string {
 	return fmt.Sprintf("%s.checksum", TarballName(k8sVersion, containerRuntime))
 }
 
-// returns target dir for all cached items related to preloading
+// returns target dir for all cached items related to preloading the user downloads
 func targetDir() string {
-	return localpath.MakeMiniPath("cache", "preloaded-tarball")
+	return localpath.MakeWritableCachePath("preloaded-tarball")
 }
 
 // PreloadChecksumPath returns the local path to the cached checksum file
 func PreloadChecksumPath(k8sVersion, containerRuntime string) string {
-	return filepath.Join(targetDir(), checksumName(k8sVersion, containerRuntime))
+	return localpath.MakeCachePath("preloaded-tarball", checksumName(k8sVersion, containerRuntime))
 }
 
 // TarballPath returns the local path to the cached preload tarball
 func TarballPath(k8sVersion, containerRuntime string) string {
-	return filepath.Join(targetDir(), TarballName(k8sVersion, containerRuntime))
+	return localpath.MakeCachePath("preloaded-tarball", TarballName(k8sVersion, containerRuntime))
 }
 
 // remoteTarballURL returns the URL for the remote tarball in GCS
@@ -150,11 +159,76 @@ func PreloadExists(k8sVersion, containerRuntime, driverName string, forcePreload
 		return true
 	}
 
+	if offline {
+		klog.Infof("No local preload for %s and %s, not looking for a remote one while offline", k8sVersion, containerRuntime)
+		setPreloadState(k8sVersion, containerRuntime, false)
+		return false
+	}
+
 	existence := checkRemotePreloadExists(k8sVersion, containerRuntime)
 	setPreloadState(k8sVersion, containerRuntime, existence)
 	return existence
 }
 
+// isURL returns whether the preload tarball of --preload-from is downloaded rather than a file of the host
+func isURL(from string) bool {
+	return strings.HasPrefix(from, "http://") || strings.HasPrefix(from, "https://")
+}
+
+// CustomTarballPath returns the local path to the preload tarball of --preload-from: the file itself, or the cached download of the URL
+func CustomTarballPath(from string) string {
+	if !isURL(from) {
+		return from
+	}
+	sum := sha256.Sum256([]byte(from))
+	name := path.Base(strings.SplitN(from, "?", 2)[0])
+	return localpath.MakeCachePath("preloaded-tarball", "custom", hex.EncodeToString(sum[:6])+"-"+name)
+}
+
+// PreloadFrom caches the preload tarball of --preload-from, downloading it when it is a URL
+func PreloadFrom(from string) error {
+	targetPath := CustomTarballPath(from)
+	if !isURL(from) {
+		if _, err := os.Stat(targetPath); err != nil {
+			return errors.Wrap(err, "preload tarball")
+		}
+		return nil
+	}
+
+	releaser, err := lockDownload(targetPath + ".lock")
+	if releaser != nil {
+		defer releaser.Release()
+	}
+	if err != nil {
+		return err
+	}
+
+	if f, err := checkCache(targetPath); err == nil && f.Size() != 0 {
+		klog.Infof("Found %s in cache, skipping download", targetPath)
+		cachegc.Touch(targetPath)
+		return nil
+	}
+
+	out.Step(style.FileDownload, "Downloading preload {{.url}} ...", out.V{"url": from})
+	// go-getter verifies the checksum parameter of the URL, if any
+	if err := download(from, targetPath); err != nil {
+		return errors.Wrapf(err, "download failed: %s", from)
+	}
+	return nil
+}
+
+// PreloadTarball returns the preload tarball of the nodes of driverName, the one of preloadFrom when it is set,
+// and whether there is one to extract
+func PreloadTarball(k8sVersion, containerRuntime, driverName, preloadFrom string) (string, bool) {
+	if preloadFrom != "" {
+		return CustomTarballPath(preloadFrom), driver.AllowsPreload(driverName)
+	}
+	if !PreloadExists(k8sVersion, containerRuntime, driverName) {
+		return "", false
+	}
+	return TarballPath(k8sVersion, containerRuntime), true
+}
+
 var checkPreloadExists = PreloadExists
 
 // Preload caches the preloaded images tarball on the host machine
@@ -172,6 +246,7 @@ func Preload(k8sVersion, containerRuntime, driverName string) error {
 
 	if f, err := checkCache(targetPath); err == nil && f.Size() != 0 {
 		klog.Infof("Found %s in cache, skipping download", targetPath)
+		cachegc.Touch(targetPath)
 		return nil
 	}
 
@@ -199,11 +274,15 @@ func Preload(k8sVersion, containerRuntime, driverName string) error {
 		url += fmt.Sprintf("?checksum=md5:%s", hex.EncodeToString(checksum))
 	}
 
-	if err := download(url, targetPath); err != nil {
+	if err := downloadInParts(url, targetPath); err != nil {
 		return errors.Wrapf(err, "download failed: %s", url)
 	}
 
 	if err := ensureChecksumValid(k8sVersion, containerRuntime, targetPath, checksum); err != nil {
+		// a corrupt tarball left in the cache would be used by the next start
+		if rerr := os.Remove(targetPath); rerr != nil {
+			klog.Warningf("removing %s: %v", targetPath, rerr)
+		}
 		return err
 	}
 
@@ -0,0 +1,45 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package download
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/blang/semver/v4"
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+// VFKitVersion is the version of vfkit downloaded for the vz driver
+var VFKitVersion = semver.MustParse("0.5.1")
+
+// vfkitWithChecksumURL returns the URL of the vfkit universal binary, signed with the virtualization entitlement
+func vfkitWithChecksumURL(v semver.Version) string {
+	base := fmt.Sprintf("https://github.com/crc-org/vfkit/releases/download/v%s/vfkit", v)
+	return fmt.Sprintf("%s?checksum=file:%s.sha256", base, base)
+}
+
+// VFKit downloads the vfkit binary
+func VFKit(destination string, v semver.Version) error {
+	out.Step(style.FileDownload, "Downloading vfkit {{.version}}:", out.V{"version": v})
+	if err := download(vfkitWithChecksumURL(v), destination); err != nil {
+		return errors.Wrap(err, "download")
+	}
+	return os.Chmod(destination, 0o755)
+}
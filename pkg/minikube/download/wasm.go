@@ -0,0 +1,39 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package download
+
+import (
+	"path"
+
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/wasm"
+)
+
+// WasmShim downloads the release tarball of the containerd wasm shim of rt for arch into the cache, returning its path
+func WasmShim(rt, arch string) (string, error) {
+	url, err := wasm.ShimURL(rt, arch)
+	if err != nil {
+		return "", err
+	}
+	targetFilepath := localpath.MakeCachePath("linux", arch, "wasm", wasm.ShimVersion, path.Base(url))
+	err = release(url, targetFilepath, func() {
+		out.Step(style.FileDownload, "Downloading the {{.runtime}} wasm shim {{.version}} ...", out.V{"runtime": rt, "version": wasm.ShimVersion})
+	})
+	return targetFilepath, err
+}
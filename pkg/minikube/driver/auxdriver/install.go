@@ -40,6 +40,9 @@ import (
 
 // InstallOrUpdate downloads driver if it is not present, or updates it if there's a newer version
 func InstallOrUpdate(name string, directory string, v semver.Version, interactive bool, autoUpdate bool) error {
+	if name == driver.VZ {
+		return installOrUpdateVFKit(directory, autoUpdate)
+	}
 	if name != driver.KVM2 && name != driver.HyperKit {
 		return nil
 	}
@@ -57,7 +60,7 @@ func InstallOrUpdate(name string, directory string, v semver.Version, interactiv
 	defer releaser.Release()
 
 	exists := driverExists(executable)
-	path, err := validateDriver(executable, minAcceptableDriverVersion(name, v))
+	path, err := validateDriver(executable, minAcceptableDriverVersion(name, v), "version")
 	if !exists || (err != nil && autoUpdate) {
 		klog.Warningf("%s: %v", executable, err)
 		path = filepath.Join(directory, executable)
@@ -68,6 +71,26 @@ func InstallOrUpdate(name string, directory string, v semver.Version, interactiv
 	return fixDriverPermissions(name, path, interactive)
 }
 
+// installOrUpdateVFKit downloads the pinned version of vfkit, which the vz driver runs, if it is not present or older
+func installOrUpdateVFKit(directory string, autoUpdate bool) error {
+	spec := lock.PathMutexSpec("vfkit")
+	spec.Timeout = 10 * time.Minute
+	klog.Infof("acquiring lock: %+v", spec)
+	releaser, err := mutex.Acquire(spec)
+	if err != nil {
+		return errors.Wrapf(err, "unable to acquire lock for %+v", spec)
+	}
+	defer releaser.Release()
+
+	exists := driverExists("vfkit")
+	_, err = validateDriver("vfkit", download.VFKitVersion, "--version")
+	if err == nil || (exists && !autoUpdate) {
+		return nil
+	}
+	klog.Warningf("vfkit: %v", err)
+	return download.VFKit(filepath.Join(directory, "vfkit"), download.VFKitVersion)
+}
+
 // fixDriverPermissions fixes the permissions on a driver
 func fixDriverPermissions(name string, path string, interactive bool) error {
 	if name != driver.HyperKit {
@@ -113,14 +136,14 @@ func fixDriverPermissions(name string, path string, interactive bool) error {
 }
 
 // validateDriver validates if a driver appears to be up-to-date and installed properly
-func validateDriver(executable string, v semver.Version) (string, error) {
+func validateDriver(executable string, v semver.Version, versionArg string) (string, error) {
 	klog.Infof("Validating %s, PATH=%s", executable, os.Getenv("PATH"))
 	path, err := exec.LookPath(executable)
 	if err != nil {
 		return path, err
 	}
 
-	output, err := exec.Command(path, "version").Output()
+	output, err := exec.Command(path, versionArg).Output()
 	if err != nil {
 		return path, err
 	}
@@ -143,7 +166,7 @@ func validateDriver(executable string, v semver.Version) (string, error) {
 }
 
 // extractDriverVersion extracts the driver version.
-// KVM and Hyperkit drivers support the 'version' command, that display the information as:
+// KVM and Hyperkit drivers support the 'version' command, and vfkit the '--version' flag, that display the information as:
 // version: vX.X.X
 // commit: XXXX
 // This method returns the version 'vX.X.X' or empty if the version isn't found.
@@ -42,4 +42,9 @@ func TestExtractDriverVersion(t *testing.T) {
 	if expectedVersion != v {
 		t.Errorf("Expected version: %s, got: %s", expectedVersion, v)
 	}
+
+	v = extractDriverVersion("vfkit version: v1.2.3")
+	if expectedVersion != v {
+		t.Errorf("Expected version: %s, got: %s", expectedVersion, v)
+	}
 }
@@ -20,7 +20,6 @@ import (
 	"fmt"
 	"os"
 	"runtime"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -61,6 +60,16 @@ const (
 	HyperV = "hyperv"
 	// Parallels driver
 	Parallels = "parallels"
+	// VZ driver, using Apple's Virtualization.framework
+	VZ = "vz"
+	// Firecracker driver, running the VM as a Firecracker microVM
+	Firecracker = "firecracker"
+	// CloudHypervisor driver, running the VM with cloud-hypervisor and commands over vsock
+	CloudHypervisor = "cloud-hypervisor"
+	// LXD driver, running the kic base image as an LXD system container
+	LXD = "lxd"
+	// WSL driver, running the kic base image as a WSL2 distro
+	WSL = "wsl"
 
 	// AliasKVM is driver name alias for kvm2
 	AliasKVM = "kvm"
@@ -70,6 +79,8 @@ const (
 	AliasNative = "native"
 	// AliasQEMU is the driver name alias for qemu2
 	AliasQEMU = "qemu"
+	// AliasVFKit is the driver name alias for vz, which runs the VM with vfkit
+	AliasVFKit = "vfkit"
 )
 
 var (
@@ -104,6 +115,9 @@ func DisplaySupportedDrivers() string {
 
 // Supported returns if the driver is supported on this host.
 func Supported(name string) bool {
+	if IsPlugin(name) {
+		return true
+	}
 	for _, d := range SupportedDrivers() {
 		if name == d {
 			return true
@@ -114,7 +128,7 @@ func Supported(name string) bool {
 
 // MachineType returns appropriate machine name for the driver
 func MachineType(name string) string {
-	if IsKIC(name) {
+	if IsKIC(name) || IsLXD(name) || IsWSL(name) {
 		return "container"
 	}
 
@@ -135,6 +149,21 @@ func IsKIC(name string) bool {
 	return name == Docker || name == Podman
 }
 
+// IsLXD checks if the driver is lxd, which runs the kic base image without an OCI runtime
+func IsLXD(name string) bool {
+	return name == LXD
+}
+
+// IsWSL checks if the driver is wsl, which runs the kic base image as a WSL2 distro
+func IsWSL(name string) bool {
+	return name == WSL
+}
+
+// IsPlugin checks if the driver is an out-of-tree driver plugin
+func IsPlugin(name string) bool {
+	return strings.HasPrefix(name, registry.PluginPrefix) && len(name) > len(registry.PluginPrefix)
+}
+
 // IsDocker checks if the driver docker
 func IsDocker(name string) bool {
 	return name == Docker
@@ -173,7 +202,7 @@ func IsQEMU(name string) bool {
 
 // IsVM checks if the driver is a VM
 func IsVM(name string) bool {
-	if IsKIC(name) || BareMetal(name) {
+	if IsKIC(name) || IsLXD(name) || IsWSL(name) || BareMetal(name) {
 		return false
 	}
 	return true
@@ -234,7 +263,8 @@ func NeedsPortForward(name string) bool {
 
 // HasResourceLimits returns true if driver can set resource limits such as memory size or CPU count.
 func HasResourceLimits(name string) bool {
-	return name != None
+	// the distros of WSL share the resources set in .wslconfig
+	return name != None && name != WSL
 }
 
 // NeedsShutdown returns true if driver needs manual shutdown command before stopping.
@@ -286,15 +316,9 @@ func FlagDefaults(name string) FlagHints {
 	return fh
 }
 
-// Choices returns a list of drivers which are possible on this system
+// Choices returns a list of drivers which are possible on this system, ranked by the probes of the host
 func Choices(vm bool) []registry.DriverState {
-	options := registry.Available(vm)
-
-	// Descending priority for predictability and appearance
-	sort.Slice(options, func(i, j int) bool {
-		return options[i].Priority > options[j].Priority
-	})
-	return options
+	return Rank(registry.Available(vm), ProbeHost())
 }
 
 // Suggest returns a suggested driver, alternate drivers, and rejected drivers
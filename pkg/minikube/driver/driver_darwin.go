@@ -27,6 +27,7 @@ var supportedDrivers = func() []string {
 	if runtime.GOARCH == "arm64" {
 		// on darwin/arm64 only docker and ssh are supported yet
 		return []string{
+			VZ,
 			QEMU2,
 			Docker,
 			Podman,
@@ -49,6 +50,7 @@ var supportedDrivers = func() []string {
 		Parallels,
 		HyperKit,
 		VMware,
+		VZ,
 		QEMU2,
 		Docker,
 		Podman,
@@ -63,3 +65,12 @@ func VBoxManagePath() string {
 	}
 	return cmd
 }
+
+// hardwareVirtualization returns whether the hypervisors of the host can use hardware virtualization, and whether it is known
+func hardwareVirtualization() (bool, bool) {
+	out, err := exec.Command("sysctl", "-n", "kern.hv_support").Output()
+	if err != nil {
+		return false, false
+	}
+	return strings.TrimSpace(string(out)) == "1", true
+}
@@ -30,3 +30,8 @@ func VBoxManagePath() string {
 	}
 	return cmd
 }
+
+// hardwareVirtualization returns whether the hypervisors of the host can use hardware virtualization, and whether it is known
+func hardwareVirtualization() (bool, bool) {
+	return false, false
+}
@@ -18,6 +18,8 @@ package driver
 
 import (
 	"os/exec"
+
+	"k8s.io/minikube/pkg/minikube/detect"
 )
 
 // supportedDrivers is a list of supported drivers on Linux.
@@ -26,10 +28,13 @@ var supportedDrivers = []string{
 	KVM2,
 	QEMU2,
 	QEMU,
+	Firecracker,
+	CloudHypervisor,
 	VMware,
 	None,
 	Docker,
 	Podman,
+	LXD,
 	SSH,
 }
 
@@ -41,3 +46,8 @@ func VBoxManagePath() string {
 	}
 	return cmd
 }
+
+// hardwareVirtualization returns whether the hypervisors of the host can use hardware virtualization, and whether it is known
+func hardwareVirtualization() (bool, bool) {
+	return detect.Nested().KVMAvailable, true
+}
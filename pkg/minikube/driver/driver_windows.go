@@ -34,6 +34,7 @@ var supportedDrivers = []string{
 	QEMU2,
 	Docker,
 	Podman,
+	WSL,
 	SSH,
 }
 
@@ -88,3 +89,8 @@ func findVBoxInstallDirInRegistry() (string, error) {
 
 	return installDir, nil
 }
+
+// hardwareVirtualization returns whether the hypervisors of the host can use hardware virtualization, and whether it is known
+func hardwareVirtualization() (bool, bool) {
+	return false, false
+}
@@ -20,10 +20,12 @@ import (
 	"fmt"
 	"net"
 
+	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/drivers/kic/oci"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/network"
 )
 
@@ -34,6 +36,15 @@ func ControlPlaneEndpoint(cc *config.ClusterConfig, cp *config.Node, driverName
 		if err != nil {
 			klog.Warningf("failed to get forwarded control plane port %v", err)
 		}
+		if oci.IsSSHDaemonHost(driverName) {
+			// only the ssh port of the remote host is known to be reachable, reach the published port through
+			// the tunnel of StartControlPlaneTunnel
+			lport, err := oci.TunnelPort(localpath.Profile(cc.Name), cp.Port, port)
+			if err != nil {
+				return oci.DefaultBindIPV4, nil, port, errors.Wrap(err, "run 'minikube start' to reconnect")
+			}
+			return oci.DefaultBindIPV4, net.ParseIP(oci.DefaultBindIPV4), lport, nil
+		}
 		hostname := oci.DaemonHost(driverName)
 
 		ips, err := net.LookupIP(hostname)
@@ -48,20 +59,45 @@ func ControlPlaneEndpoint(cc *config.ClusterConfig, cp *config.Node, driverName
 		return hostname, ips[0], port, err
 	} else if IsQEMU(driverName) && network.IsBuiltinQEMU(cc.Network) {
 		return "localhost", net.IPv4(127, 0, 0, 1), cc.APIServerPort, nil
+	} else if IsWSL(driverName) {
+		// the ports of the distro are forwarded to the loopback of the Windows host
+		return "127.0.0.1", net.IPv4(127, 0, 0, 1), cp.Port, nil
+	}
+
+	ip := cp.IP
+	// the virtual IP of a multi-control-plane cluster moves to a healthy control plane
+	if cc.KubernetesConfig.APIServerHAVIP != "" {
+		ip = cc.KubernetesConfig.APIServerHAVIP
 	}
 
 	// https://github.com/kubernetes/minikube/issues/3878
-	hostname := cp.IP
+	hostname := ip
 	if cc.KubernetesConfig.APIServerName != constants.APIServerName {
 		hostname = cc.KubernetesConfig.APIServerName
 	}
-	ips, err := net.LookupIP(cp.IP)
+	ips, err := net.LookupIP(ip)
 	if err != nil || len(ips) == 0 {
-		return hostname, nil, cp.Port, fmt.Errorf("failed to lookup ip for %q", cp.IP)
+		return hostname, nil, cp.Port, fmt.Errorf("failed to lookup ip for %q", ip)
 	}
 	return hostname, ips[0], cp.Port, nil
 }
 
+// StartControlPlaneTunnel starts the ssh tunnel to the API server port of the control plane published on a remote
+// daemon host reached over ssh://, which ControlPlaneEndpoint reads. It does nothing for other daemon hosts.
+func StartControlPlaneTunnel(cc *config.ClusterConfig, cp *config.Node, driverName string) error {
+	if !NeedsPortForward(driverName) || !oci.IsSSHDaemonHost(driverName) {
+		return nil
+	}
+	port, err := oci.ForwardedPort(cc.Driver, cc.Name, cp.Port)
+	if err != nil {
+		return errors.Wrap(err, "forwarded control plane port")
+	}
+	if _, err := oci.ForwardedPortTunnel(driverName, localpath.Profile(cc.Name), cp.Port, port); err != nil {
+		return errors.Wrap(err, "tunnel to the remote daemon host")
+	}
+	return nil
+}
+
 // AutoPauseProxyEndpoint returns the endpoint for the auto-pause (reverse proxy to api-sever)
 func AutoPauseProxyEndpoint(cc *config.ClusterConfig, cp *config.Node, driverName string) (string, net.IP, int, error) {
 	cp.Port = constants.AutoPauseProxyPort
@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+
+	"github.com/shirou/gopsutil/v3/mem"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/detect"
+	"k8s.io/minikube/pkg/minikube/registry"
+)
+
+// lowMemoryMB is the free memory of the host in MiB below which the VM drivers, reserving the memory of the VM, are demoted
+const lowMemoryMB = 3072
+
+// HostProbe is what the probes found of the host, to rank the drivers
+type HostProbe struct {
+	// OS is the operating system of the host
+	OS string
+	// Virtualization is whether the hypervisors can use hardware virtualization, if VirtualizationKnown
+	Virtualization      bool
+	VirtualizationKnown bool
+	// Nested is the environment minikube runs nested in, or empty on bare metal
+	Nested string
+	// CgroupVersion is the cgroup version of the host on Linux: v1, v2 or empty if unknown
+	CgroupVersion string
+	// FreeMemory is the memory available on the host in MiB, or 0 if unknown
+	FreeMemory int
+	// SocketVMnet is whether socket_vmnet is installed, giving the QEMU VMs a routable network on macOS
+	SocketVMnet bool
+}
+
+// ProbeHost probes the host for the virtualization, the cgroup mode, the free resources and the network constraints
+func ProbeHost() HostProbe {
+	h := HostProbe{OS: runtime.GOOS}
+	h.Virtualization, h.VirtualizationKnown = hardwareVirtualization()
+	if h.OS == "linux" {
+		n := detect.Nested()
+		if n.IsNested() {
+			h.Nested = n.String()
+		}
+		h.CgroupVersion = n.CgroupVersion
+	}
+	if v, err := mem.VirtualMemory(); err == nil {
+		h.FreeMemory = int(v.Available / 1024 / 1024)
+	} else {
+		klog.Warningf("unable to probe the free memory: %v", err)
+	}
+	if h.OS == "darwin" {
+		h.SocketVMnet = detect.SocketVMNetInstalled()
+	}
+	klog.Infof("host probe: %+v", h)
+	return h
+}
+
+// needsVirtualization returns whether the driver can only run with the hardware virtualization of the host
+func needsVirtualization(name string, goos string) bool {
+	switch goos {
+	case "linux":
+		return IsKVM(name) || IsQEMU(name) || name == Firecracker || name == CloudHypervisor
+	case "darwin":
+		return name == HyperKit || name == VZ || IsQEMU(name)
+	}
+	return false
+}
+
+// Rank applies the probes of the host to the drivers: the drivers which cannot work are marked unhealthy,
+// the drivers which would work poorly are demoted, and the drivers are sorted by descending priority.
+// QEMU falls back to software emulation without hardware virtualization, so it is only demoted.
+// The reasons are recorded in the Probe field of the drivers.
+func Rank(options []registry.DriverState, h HostProbe) []registry.DriverState {
+	ranked := make([]registry.DriverState, len(options))
+	copy(ranked, options)
+	for i := range ranked {
+		ds := &ranked[i]
+		if h.VirtualizationKnown && !h.Virtualization && IsQEMU(ds.Name) {
+			demote(ds, "hardware virtualization is not available, the VM runs with software emulation")
+			ds.State.NeedsImprovement = true
+			ds.State.Fix = "enable hardware virtualization on the host, the VM is emulated in software and about 10x slower"
+			continue
+		}
+		if h.VirtualizationKnown && !h.Virtualization && needsVirtualization(ds.Name, h.OS) {
+			klog.Infof("disabling %q: hardware virtualization is not available", ds.Name)
+			ds.State.Healthy = false
+			ds.State.Doc = "https://minikube.sigs.k8s.io/docs/drivers/"
+			switch {
+			case h.OS == "linux" && h.Nested != "":
+				ds.State.Error = fmt.Errorf("/dev/kvm is not available inside this %s", h.Nested)
+				ds.State.Fix = "Enable nested virtualization for the outer VM, pass /dev/kvm into the container, or use the docker driver"
+			case h.OS == "linux":
+				ds.State.Error = fmt.Errorf("/dev/kvm is not available")
+				ds.State.Fix = "Enable virtualization in the BIOS and load the kvm module, or use the docker driver"
+			default:
+				ds.State.Error = fmt.Errorf("the Hypervisor framework is not supported by this host")
+				ds.State.Fix = "Enable nested virtualization for the outer VM, or use the docker driver"
+			}
+			ds.Probe = "hardware virtualization is not available"
+			continue
+		}
+		switch {
+		case h.OS == "linux" && h.CgroupVersion == "v1" && (IsKIC(ds.Name) || IsLXD(ds.Name) || ds.Name == None):
+			demote(ds, "the node shares the cgroup v1 hierarchy of the host, which Kubernetes deprecates")
+		case h.FreeMemory > 0 && h.FreeMemory < lowMemoryMB && IsVM(ds.Name) && !IsSSH(ds.Name):
+			demote(ds, fmt.Sprintf("only %dMiB of memory is free for the VM", h.FreeMemory))
+		case h.OS == "darwin" && IsQEMU(ds.Name) && !h.SocketVMnet:
+			demote(ds, "socket_vmnet is not installed, the services are only reachable through port forwarding")
+		case h.OS != "linux" && IsKIC(ds.Name):
+			ds.Probe = "the services are only reachable through port forwarding"
+		}
+	}
+
+	// Descending priority for predictability and appearance
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Priority > ranked[j].Priority
+	})
+	return ranked
+}
+
+// demote lowers the priority of a driver which would work poorly on the host, not below Fallback
+func demote(ds *registry.DriverState, why string) {
+	klog.Infof("demoting %q: %s", ds.Name, why)
+	ds.Probe = why
+	if ds.Priority > registry.Fallback {
+		ds.Priority--
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/minikube/pkg/minikube/registry"
+)
+
+func TestRank(t *testing.T) {
+	healthy := registry.State{Installed: true, Healthy: true}
+	options := func(names ...string) []registry.DriverState {
+		var ds []registry.DriverState
+		for _, n := range names {
+			p := registry.Default
+			if n == Docker || n == KVM2 || n == VZ {
+				p = registry.Preferred
+			}
+			ds = append(ds, registry.DriverState{Name: n, Priority: p, Default: true, State: healthy})
+		}
+		return ds
+	}
+	tests := []struct {
+		name      string
+		options   []registry.DriverState
+		host      HostProbe
+		order     []string
+		unhealthy []string
+		probed    []string
+	}{
+		{
+			name:    "healthy linux host",
+			options: options(Docker, KVM2, QEMU2, Podman),
+			host:    HostProbe{OS: "linux", Virtualization: true, VirtualizationKnown: true, CgroupVersion: "v2", FreeMemory: 16384},
+			order:   []string{Docker, KVM2, QEMU2, Podman},
+		},
+		{
+			name:      "nested linux without kvm",
+			options:   options(KVM2, Docker, QEMU2),
+			host:      HostProbe{OS: "linux", VirtualizationKnown: true, Nested: "container (docker)", CgroupVersion: "v2", FreeMemory: 16384},
+			order:     []string{KVM2, Docker, QEMU2},
+			unhealthy: []string{KVM2},
+			probed:    []string{KVM2, QEMU2},
+		},
+		{
+			name:    "cgroup v1 demotes the drivers sharing the kernel",
+			options: options(Docker, KVM2, None),
+			host:    HostProbe{OS: "linux", Virtualization: true, VirtualizationKnown: true, CgroupVersion: "v1", FreeMemory: 16384},
+			order:   []string{KVM2, Docker, None},
+			probed:  []string{Docker, None},
+		},
+		{
+			name:    "low memory demotes the VM drivers",
+			options: options(KVM2, Docker, SSH),
+			host:    HostProbe{OS: "linux", Virtualization: true, VirtualizationKnown: true, CgroupVersion: "v2", FreeMemory: 2048},
+			order:   []string{Docker, KVM2, SSH},
+			probed:  []string{KVM2},
+		},
+		{
+			name:    "macOS qemu without socket_vmnet",
+			options: options(QEMU2, Docker, VZ),
+			host:    HostProbe{OS: "darwin", Virtualization: true, VirtualizationKnown: true, FreeMemory: 16384},
+			order:   []string{Docker, VZ, QEMU2},
+			probed:  []string{QEMU2, Docker},
+		},
+		{
+			name:    "unknown virtualization is not probed",
+			options: options(Docker, VirtualBox),
+			host:    HostProbe{OS: "windows"},
+			order:   []string{Docker, VirtualBox},
+			probed:  []string{Docker},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := Rank(tc.options, tc.host)
+			var order, unhealthy, probed []string
+			for _, ds := range got {
+				order = append(order, ds.Name)
+				if !ds.State.Healthy {
+					unhealthy = append(unhealthy, ds.Name)
+				}
+			}
+			for _, ds := range tc.options {
+				for _, r := range got {
+					if r.Name == ds.Name && r.Probe != "" {
+						probed = append(probed, r.Name)
+					}
+				}
+			}
+			if diff := cmp.Diff(tc.order, order); diff != "" {
+				t.Errorf("Rank() order mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.unhealthy, unhealthy); diff != "" {
+				t.Errorf("Rank() unhealthy mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.probed, probed); diff != "" {
+				t.Errorf("Rank() probed mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hooks runs the commands of the cluster config before kubeadm starts the cluster, and once it is Ready
+package hooks
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path"
+	"runtime"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+const (
+	// PreStart hooks run before kubeadm initializes or restarts the control plane
+	PreStart = "pre-start"
+	// PostStart hooks run once all the nodes of the cluster are Ready
+	PostStart = "post-start"
+
+	hostPrefix  = "host:"
+	guestPrefix = "guest:"
+)
+
+// Parse parses a hook in the [host:|guest:]COMMAND format, run on the host without prefix
+func Parse(spec string) (config.Hook, error) {
+	h := config.Hook{Command: spec}
+	switch {
+	case strings.HasPrefix(spec, guestPrefix):
+		h = config.Hook{Guest: true, Command: strings.TrimPrefix(spec, guestPrefix)}
+	case strings.HasPrefix(spec, hostPrefix):
+		h.Command = strings.TrimPrefix(spec, hostPrefix)
+	}
+	if strings.TrimSpace(h.Command) == "" {
+		return h, errors.Errorf("%q has no command, expected [host:|guest:]COMMAND", spec)
+	}
+	return h, nil
+}
+
+// String returns h in the format of Parse
+func String(h config.Hook) string {
+	if h.Guest {
+		return guestPrefix + h.Command
+	}
+	return hostPrefix + h.Command
+}
+
+// Run runs the hooks of stage of cc in order, the guest ones in the primary control plane with r,
+// and stops at the first one failing
+func Run(stage string, cc config.ClusterConfig, r command.Runner, hooks []config.Hook) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+	out.Step(style.Provisioning, "Running the {{.count}} {{.stage}} hooks of {{.name}} ...", out.V{"count": len(hooks), "stage": stage, "name": cc.Name})
+	env := []string{"MINIKUBE_PROFILE=" + cc.Name, "MINIKUBE_HOOK=" + stage}
+	if cp, err := config.PrimaryControlPlane(&cc); err == nil {
+		env = append(env, "MINIKUBE_IP="+cp.IP)
+	}
+	for i, h := range hooks {
+		klog.Infof("running %s hook %d of %s: %s", stage, i+1, cc.Name, String(h))
+		var output string
+		var err error
+		if h.Guest {
+			output, err = runGuest(cc, r, env, h.Command)
+		} else {
+			output, err = runHost(env, h.Command)
+		}
+		klog.Infof("%s hook %d output: %s", stage, i+1, output)
+		if err != nil {
+			return errors.Wrapf(err, "%s hook %q: %s", stage, String(h), strings.TrimSpace(output))
+		}
+	}
+	return nil
+}
+
+// runHost runs command through the shell of the host, with the environment of minikube and env
+func runHost(env []string, command string) (string, error) {
+	var c *exec.Cmd
+	if runtime.GOOS == "windows" {
+		c = exec.Command("cmd", "/C", command)
+	} else {
+		c = exec.Command("/bin/sh", "-c", command)
+	}
+	c.Env = append(os.Environ(), env...)
+	var b bytes.Buffer
+	c.Stdout = &b
+	c.Stderr = &b
+	err := c.Run()
+	return b.String(), err
+}
+
+// runGuest runs script as root by bash in the node of r, with env, the kubeconfig of the cluster and its kubectl in the PATH
+func runGuest(cc config.ClusterConfig, r command.Runner, env []string, script string) (string, error) {
+	if r == nil {
+		return "", errors.New("the primary control plane is not running")
+	}
+	env = append(env, "KUBECONFIG=/var/lib/minikube/kubeconfig")
+	script = "export PATH=" + path.Dir(kapi.KubectlBinaryPath(cc.KubernetesConfig.KubernetesVersion)) + ":$PATH\n" + script
+	args := append([]string{"sudo", "env"}, env...)
+	rr, err := r.RunCmd(exec.Command(args[0], append(args[1:], "/bin/bash", "-c", script)...))
+	if rr == nil {
+		return "", err
+	}
+	return rr.Output(), err
+}
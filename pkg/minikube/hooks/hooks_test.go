@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    config.Hook
+		wantErr bool
+	}{
+		{spec: "docker login registry.local", want: config.Hook{Command: "docker login registry.local"}},
+		{spec: "host:./mount-shares.sh", want: config.Hook{Command: "./mount-shares.sh"}},
+		{spec: "guest:kubectl apply -f /data/base", want: config.Hook{Guest: true, Command: "kubectl apply -f /data/base"}},
+		{spec: "guest: ", wantErr: true},
+		{spec: "", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.spec, func(t *testing.T) {
+			got, err := Parse(tc.spec)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tc.spec, err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got != tc.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tc.spec, got, tc.want)
+			}
+			back, err := Parse(String(got))
+			if err != nil || back != got {
+				t.Errorf("Parse(String(%+v)) = %+v, %v", got, back, err)
+			}
+		})
+	}
+}
+
+func TestRunHost(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the hooks are run by /bin/sh")
+	}
+	log := filepath.Join(t.TempDir(), "hooks.log")
+	cc := config.ClusterConfig{Name: "dev", Nodes: []config.Node{{IP: "192.168.49.2", ControlPlane: true}}}
+	hs := []config.Hook{
+		{Command: `echo "$MINIKUBE_HOOK $MINIKUBE_PROFILE $MINIKUBE_IP" >> ` + log},
+		{Command: "exit 3"},
+		{Command: "echo not run >> " + log},
+	}
+	if err := Run(PostStart, cc, nil, hs); err == nil {
+		t.Errorf("Run() did not fail on a failing hook")
+	}
+	b, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), "post-start dev 192.168.49.2\n"; got != want {
+		t.Errorf("hooks wrote %q, want %q", got, want)
+	}
+
+	if err := Run(PreStart, cc, nil, []config.Hook{{Guest: true, Command: "true"}}); err == nil {
+		t.Errorf("Run() did not fail on a guest hook without runner")
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hostdns points the resolver of the host to the ingress-dns addon of a cluster, for the names of the test domain
+package hostdns
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/minikube/pkg/minikube/driver"
+)
+
+// Domain is the domain whose names the host resolves with the ingress-dns addon
+const Domain = "test"
+
+// Reachable returns whether the host reaches the DNS server of the ingress-dns addon at the IP of the cluster,
+// which is not the case of the drivers needing port forwarding, nor of the none driver running it on the host itself
+func Reachable(drv string) bool {
+	return !driver.NeedsPortForward(drv) && !driver.BareMetal(drv)
+}
+
+// nrptComment identifies the NRPT rule of the cluster named profile for zone
+func nrptComment(profile, zone string) string {
+	if zone == Domain {
+		return "minikube " + profile
+	}
+	return "minikube " + profile + " " + zone
+}
+
+// nrptRemoveScript is the PowerShell removing the NRPT rule of the cluster named profile for zone
+func nrptRemoveScript(profile, zone string) string {
+	return fmt.Sprintf("Get-DnsClientNrptRule | Where-Object {$_.Comment -eq '%s'} | Remove-DnsClientNrptRule -Force", nrptComment(profile, zone))
+}
+
+// nrptAddScript is the PowerShell replacing the NRPT rule of the cluster named profile, sending the queries of zone to ip
+func nrptAddScript(profile, zone, ip string) string {
+	return fmt.Sprintf("%s; Add-DnsClientNrptRule -Namespace '.%s' -NameServers '%s' -Comment '%s'", nrptRemoveScript(profile, zone), zone, ip, nrptComment(profile, zone))
+}
+
+// resolverFile is the macOS resolver file of the cluster named profile for zone
+func resolverFile(profile, zone string) string {
+	if zone == Domain {
+		return "/etc/resolver/minikube-" + profile
+	}
+	return "/etc/resolver/minikube-" + profile + "-" + strings.ReplaceAll(zone, ".", "-")
+}
+
+// resolverContent is the macOS resolver configuration sending the queries of zone to port of ip
+func resolverContent(zone, ip string, port int) string {
+	content := fmt.Sprintf("domain %s\nnameserver %s\n", zone, ip)
+	if port != 53 {
+		content += fmt.Sprintf("port %d\n", port)
+	}
+	return content + "search_order 1\ntimeout 5\n"
+}
+
+// routeDevice returns the network interface of the output of "ip route get", empty if there is none:
+//
+//	192.168.49.2 dev br-4a3b2c1d0e9f src 192.168.49.1 uid 1000
+func routeDevice(out string) string {
+	fields := strings.Fields(out)
+	for i := 0; i+1 < len(fields); i++ {
+		if fields[i] == "dev" {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
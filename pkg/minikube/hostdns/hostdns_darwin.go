@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostdns
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// Configure writes a resolver file sending the queries of Domain to the ingress-dns addon at ip
+func Configure(profile, ip string) error {
+	return ConfigureZone(profile, Domain, ip, 53)
+}
+
+// Remove removes the resolver file of the cluster named profile
+func Remove(profile, _ string) error {
+	return RemoveZone(profile, Domain)
+}
+
+// ZoneSupported is whether ConfigureZone can make the resolver of the host send the queries of a zone to a server
+const ZoneSupported = true
+
+// ConfigureZone writes a resolver file sending the queries of zone to port of ip
+func ConfigureZone(profile, zone, ip string, port int) error {
+	tf, err := os.CreateTemp("", "minikube-ingress-dns-resolver-")
+	if err != nil {
+		return errors.Wrap(err, "tempfile")
+	}
+	defer os.Remove(tf.Name())
+	if _, err := tf.WriteString(resolverContent(zone, ip, port)); err != nil {
+		return errors.Wrap(err, "write")
+	}
+	if err := tf.Close(); err != nil {
+		return errors.Wrap(err, "close")
+	}
+	if err := os.Chmod(tf.Name(), 0644); err != nil {
+		return errors.Wrap(err, "chmod")
+	}
+
+	path := resolverFile(profile, zone)
+	if out, err := exec.Command("sudo", "mkdir", "-p", filepath.Dir(path)).CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "mkdir: %s", out)
+	}
+	if out, err := exec.Command("sudo", "cp", "-fp", tf.Name(), path).CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "copy: %s", out)
+	}
+	klog.Infof("queries of %s now sent to %s:%d in %q", zone, ip, port, path)
+	return nil
+}
+
+// RemoveZone removes the resolver file of the cluster named profile for zone
+func RemoveZone(profile, zone string) error {
+	path := resolverFile(profile, zone)
+	if out, err := exec.Command("sudo", "rm", "-f", path).CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "remove %s: %s", path, out)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostdns
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// Configure makes systemd-resolved send the queries of Domain to the ingress-dns addon at ip, on the network interface routing to it
+func Configure(profile, ip string) error {
+	if err := exec.Command("resolvectl", "status").Run(); err != nil {
+		return errors.Wrap(err, "systemd-resolved is not available")
+	}
+	link, err := linkTo(ip)
+	if err != nil {
+		return err
+	}
+	klog.Infof("sending the queries of %s to %s on %s for %s", Domain, ip, link, profile)
+	if err := run("sudo", "resolvectl", "dns", link, ip); err != nil {
+		return err
+	}
+	return run("sudo", "resolvectl", "domain", link, "~"+Domain)
+}
+
+// Remove reverts the DNS settings of the network interface routing to ip
+func Remove(profile, ip string) error {
+	link, err := linkTo(ip)
+	if err != nil {
+		// the network of the cluster is gone, and its settings with it
+		klog.Infof("not reverting the DNS settings of %s: %v", profile, err)
+		return nil
+	}
+	return run("sudo", "resolvectl", "revert", link)
+}
+
+// ZoneSupported is whether ConfigureZone can make the resolver of the host send the queries of a zone to a server
+const ZoneSupported = false
+
+// ConfigureZone is not supported: systemd-resolved only sends the queries of a domain to a server on the link routing to it,
+// and refuses the loopback link the servers of the host listen on
+func ConfigureZone(_, zone, ip string, port int) error {
+	return errors.Errorf("systemd-resolved cannot send the queries of %s to %s:%d on the loopback interface", zone, ip, port)
+}
+
+// RemoveZone does nothing, as ConfigureZone is not supported
+func RemoveZone(_, _ string) error {
+	return nil
+}
+
+// linkTo returns the network interface of the host routing to ip
+func linkTo(ip string) (string, error) {
+	out, err := exec.Command("ip", "route", "get", ip).Output()
+	if err != nil {
+		return "", errors.Wrapf(err, "ip route get %s", ip)
+	}
+	dev := routeDevice(string(out))
+	if dev == "" || dev == "lo" {
+		return "", errors.Errorf("no network interface routes to %s", ip)
+	}
+	return dev, nil
+}
+
+func run(args ...string) error {
+	cmd := exec.Command(args[0], args[1:]...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "%s: %s", strings.Join(cmd.Args, " "), out)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostdns
+
+import (
+	"testing"
+)
+
+func TestRouteDevice(t *testing.T) {
+	tests := []struct {
+		out      string
+		expected string
+	}{
+		{"192.168.49.2 dev br-4a3b2c1d0e9f src 192.168.49.1 uid 1000 \n    cache \n", "br-4a3b2c1d0e9f"},
+		{"local 192.168.1.10 dev lo table local src 192.168.1.10 uid 0 \n", "lo"},
+		{"RTNETLINK answers: Network is unreachable\n", ""},
+	}
+	for _, tc := range tests {
+		if got := routeDevice(tc.out); got != tc.expected {
+			t.Errorf("routeDevice(%q) = %q, expected %q", tc.out, got, tc.expected)
+		}
+	}
+}
+
+func TestNRPTScripts(t *testing.T) {
+	expected := "Get-DnsClientNrptRule | Where-Object {$_.Comment -eq 'minikube p1'} | Remove-DnsClientNrptRule -Force; " +
+		"Add-DnsClientNrptRule -Namespace '.test' -NameServers '172.17.0.2' -Comment 'minikube p1'"
+	if got := nrptAddScript("p1", Domain, "172.17.0.2"); got != expected {
+		t.Errorf("nrptAddScript() = %q, expected %q", got, expected)
+	}
+}
+
+func TestResolverContent(t *testing.T) {
+	expected := "domain test\nnameserver 192.168.64.5\nsearch_order 1\ntimeout 5\n"
+	if got := resolverContent(Domain, "192.168.64.5", 53); got != expected {
+		t.Errorf("resolverContent() = %q, expected %q", got, expected)
+	}
+	expected = "domain svc.p1\nnameserver 127.0.0.1\nport 10053\nsearch_order 1\ntimeout 5\n"
+	if got := resolverContent("svc.p1", "127.0.0.1", 10053); got != expected {
+		t.Errorf("resolverContent() = %q, expected %q", got, expected)
+	}
+	if got := resolverFile("minikube", Domain); got != "/etc/resolver/minikube-minikube" {
+		t.Errorf("resolverFile() = %q", got)
+	}
+	if got := resolverFile("p1", "svc.p1"); got != "/etc/resolver/minikube-p1-svc-p1" {
+		t.Errorf("resolverFile() = %q", got)
+	}
+}
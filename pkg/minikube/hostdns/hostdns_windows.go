@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostdns
+
+import (
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/util"
+)
+
+// Configure adds an NRPT rule sending the queries of Domain to the ingress-dns addon at ip, which needs an Administrator shell
+func Configure(profile, ip string) error {
+	return ConfigureZone(profile, Domain, ip, 53)
+}
+
+// Remove removes the NRPT rule of the cluster named profile
+func Remove(profile, _ string) error {
+	return RemoveZone(profile, Domain)
+}
+
+// ZoneSupported is whether ConfigureZone can make the resolver of the host send the queries of a zone to a server
+const ZoneSupported = true
+
+// ConfigureZone adds an NRPT rule sending the queries of zone to ip, NRPT rules only support port 53
+func ConfigureZone(profile, zone, ip string, port int) error {
+	if port != 53 {
+		return errors.Errorf("NRPT rules only send queries to port 53, not %d", port)
+	}
+	klog.Infof("sending the queries of %s to %s for %s", zone, ip, profile)
+	return nrpt(nrptAddScript(profile, zone, ip))
+}
+
+// RemoveZone removes the NRPT rule of the cluster named profile for zone
+func RemoveZone(profile, zone string) error {
+	return nrpt(nrptRemoveScript(profile, zone))
+}
+
+// nrpt runs the script changing the NRPT rules
+func nrpt(script string) error {
+	return errors.Wrap(util.PowerShell(script), "NRPT rule (are you running as Administrator?)")
+}
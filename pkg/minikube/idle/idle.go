@@ -0,0 +1,221 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package idle pauses or stops a cluster nobody talks to, and resumes it on the next connection to its apiserver
+package idle
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+// What is done to a cluster which stayed idle for --idle-timeout
+const (
+	// ActionPause pauses the kube-system containers, which is quick to resume
+	ActionPause = "pause"
+	// ActionStop stops the machines, which frees their memory but takes a restart to resume
+	ActionStop = "stop"
+)
+
+// Actions are the values of --idle-action
+var Actions = []string{ActionPause, ActionStop}
+
+// checkInterval is how often the proxy checks whether the cluster is idle
+const checkInterval = 10 * time.Second
+
+// Proxy forwards the connections to the apiserver of a cluster, and suspends the cluster once no connection was open for Timeout.
+// The next connection resumes the cluster before being forwarded.
+type Proxy struct {
+	Timeout time.Duration
+	// Upstream returns the host:port of the apiserver, which may change when the cluster restarts
+	Upstream func() (string, error)
+	// Suspend pauses or stops the cluster
+	Suspend func() error
+	// Resume brings the suspended cluster back
+	Resume func() error
+	// Suspended is whether the cluster is suspended, set before Serve when the cluster was paused before the proxy started
+	Suspended bool
+
+	mu   sync.Mutex
+	open int
+	last time.Time
+}
+
+// Serve forwards the connections accepted by l until ctx is done
+func (p *Proxy) Serve(ctx context.Context, l net.Listener) error {
+	p.mu.Lock()
+	p.last = time.Now()
+	p.mu.Unlock()
+
+	go func() {
+		t := time.NewTicker(checkInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				l.Close()
+				return
+			case now := <-t.C:
+				p.checkIdle(now)
+			}
+		}
+	}()
+
+	for {
+		c, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrap(err, "accept")
+		}
+		go p.handle(c)
+	}
+}
+
+// handle forwards the connection c to the apiserver, resuming the cluster first if it is suspended
+func (p *Proxy) handle(c net.Conn) {
+	defer c.Close()
+	if err := p.acquire(); err != nil {
+		klog.Errorf("resuming the cluster: %v", err)
+		return
+	}
+	defer p.release()
+
+	addr, err := p.Upstream()
+	if err != nil {
+		klog.Errorf("apiserver endpoint: %v", err)
+		return
+	}
+	up, err := net.Dial("tcp", addr)
+	if err != nil {
+		klog.Errorf("dial %s: %v", addr, err)
+		return
+	}
+	defer up.Close()
+
+	// the connection is over once either side closes it
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(up, c)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(c, up)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+// acquire counts a new connection, resuming the cluster if it is suspended
+func (p *Proxy) acquire() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Suspended {
+		klog.Infof("resuming the cluster on a new connection")
+		if err := p.Resume(); err != nil {
+			return err
+		}
+		p.Suspended = false
+	}
+	p.open++
+	return nil
+}
+
+// release counts a closed connection, the cluster being idle from then on if it was the last one
+func (p *Proxy) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.open--
+	p.last = time.Now()
+}
+
+// checkIdle suspends the cluster if no connection was open for Timeout at now.
+// Connections opened meanwhile wait for the cluster to be suspended, then resume it.
+func (p *Proxy) checkIdle(now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Suspended || p.open > 0 || now.Sub(p.last) < p.Timeout {
+		return
+	}
+	klog.Infof("no connection for %s, suspending the cluster", now.Sub(p.last))
+	if err := p.Suspend(); err != nil {
+		klog.Errorf("suspending the cluster: %v", err)
+		// tried again once idle for Timeout again
+		p.last = now
+		return
+	}
+	p.Suspended = true
+}
+
+// StartProcess runs the idle-proxy command of the profile in the background, recording its pid in the profile directory
+func StartProcess(profile string) error {
+	cmd := exec.Command(os.Args[0], "idle-proxy", "--profile", profile)
+	cmd.Env = append(os.Environ(), constants.IsMinikubeChildProcess+"=true")
+	if klog.V(8).Enabled() {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "start idle-proxy")
+	}
+	if err := os.WriteFile(filepath.Join(localpath.Profile(profile), constants.IdleProxyProcessFileName), []byte(strconv.Itoa(cmd.Process.Pid)), 0o644); err != nil {
+		return errors.Wrap(err, "write idle-proxy pid")
+	}
+	return nil
+}
+
+// ProcessPid returns the pid of the idle-proxy process of the profile, or 0 if it was not started
+func ProcessPid(profile string) int {
+	data, err := os.ReadFile(filepath.Join(localpath.Profile(profile), constants.IdleProxyProcessFileName))
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(string(data))
+	if err != nil {
+		return 0
+	}
+	return pid
+}
+
+// Enabled returns whether the connections of kubectl to the apiserver of cc go through the idle proxy
+func Enabled(cc config.ClusterConfig) bool {
+	return cc.IdleTimeout > 0 && cc.IdleProxyPort != 0 && cc.KubernetesConfig.KubernetesVersion != constants.NoKubernetesVersion
+}
+
+// FreePort returns a port of 127.0.0.1 nothing listens on
+func FreePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, errors.Wrap(err, "listen")
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package idle
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// echo serves connections writing back what they read
+func echo(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				_, _ = io.Copy(c, c)
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestProxy(t *testing.T) {
+	upstream := echo(t)
+	suspends, resumes := 0, 0
+	p := &Proxy{
+		Timeout:  time.Minute,
+		Upstream: func() (string, error) { return upstream, nil },
+		Suspend:  func() error { suspends++; return nil },
+		Resume:   func() error { resumes++; return nil },
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = p.Serve(ctx, l) }()
+
+	// the state of the proxy, guarded by its lock while connections are handled
+	state := func() (bool, int, int) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return p.Suspended, suspends, resumes
+	}
+	roundTrip := func() net.Conn {
+		c, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Write([]byte("ping\n")); err != nil {
+			t.Fatal(err)
+		}
+		line, err := bufio.NewReader(c).ReadString('\n')
+		if err != nil || line != "ping\n" {
+			t.Fatalf("read %q, %v, expected ping", line, err)
+		}
+		return c
+	}
+
+	// an open connection keeps the cluster running
+	c := roundTrip()
+	p.checkIdle(time.Now().Add(2 * time.Minute))
+	if suspended, n, _ := state(); suspended || n != 0 {
+		t.Errorf("suspended with an open connection")
+	}
+	c.Close()
+
+	// wait for the proxy to see the connection closed
+	for i := 0; ; i++ {
+		p.mu.Lock()
+		open := p.open
+		p.mu.Unlock()
+		if open == 0 {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("%d connections still open", open)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	p.checkIdle(time.Now().Add(30 * time.Second))
+	if suspended, _, _ := state(); suspended {
+		t.Errorf("suspended before the timeout")
+	}
+	p.checkIdle(time.Now().Add(2 * time.Minute))
+	if suspended, n, _ := state(); !suspended || n != 1 {
+		t.Errorf("not suspended after the timeout: suspended=%t, %d suspends", suspended, n)
+	}
+
+	// the next connection resumes the cluster
+	c = roundTrip()
+	c.Close()
+	if suspended, _, n := state(); suspended || n != 1 {
+		t.Errorf("not resumed by a new connection: suspended=%t, %d resumes", suspended, n)
+	}
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/juju/mutex/v2"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/util/lock"
+)
+
+// ArtifactPrefix marks the references of OCI artifacts, like Helm charts, as opposed to container images
+const ArtifactPrefix = "oci://"
+
+// refNameAnnotation is the annotation of the OCI image layout naming its manifests
+const refNameAnnotation = "org.opencontainers.image.ref.name"
+
+// IsArtifact returns whether ref is an OCI artifact reference, like oci://registry-1.docker.io/bitnamicharts/nginx:15.0.0
+func IsArtifact(ref string) bool {
+	return strings.HasPrefix(ref, ArtifactPrefix)
+}
+
+// ArtifactCacheDir returns the OCI image layout caching the artifacts, where blobs are stored by digest
+func ArtifactCacheDir() string {
+	return localpath.MakeCachePath("artifacts")
+}
+
+// parseArtifact parses an artifact reference, with or without its oci:// prefix
+func parseArtifact(ref string) (name.Reference, error) {
+	return name.ParseReference(strings.TrimPrefix(ref, ArtifactPrefix), name.WeakValidation)
+}
+
+// openLayout opens the OCI image layout at dir, creating it if needed.
+// The caller must hold the lock of dir.
+func openLayout(dir string) (layout.Path, error) {
+	if p, err := layout.FromPath(dir); err == nil {
+		return p, nil
+	}
+	return layout.Write(dir, empty.Index)
+}
+
+func lockLayout(dir string) (mutex.Releaser, error) {
+	spec := lock.PathMutexSpec(dir)
+	spec.Timeout = 10 * time.Minute
+	klog.Infof("acquiring lock: %+v", spec)
+	return mutex.Acquire(spec)
+}
+
+// SaveArtifacts pulls the artifacts into the OCI image layout at dir, replacing older versions of the same references
+func SaveArtifacts(refs []string, dir string) error {
+	releaser, err := lockLayout(dir)
+	if err != nil {
+		return errors.Wrapf(err, "unable to acquire lock for %s", dir)
+	}
+	defer releaser.Release()
+
+	p, err := openLayout(dir)
+	if err != nil {
+		return errors.Wrapf(err, "opening artifact cache %s", dir)
+	}
+	for _, r := range refs {
+		ref, err := parseArtifact(r)
+		if err != nil {
+			return errors.Wrapf(err, "parsing artifact reference %s", r)
+		}
+		desc, err := remote.Get(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain))
+		if err != nil {
+			return errors.Wrapf(err, "fetching %s", r)
+		}
+		if err := replaceArtifact(p, desc, ref); err != nil {
+			return errors.Wrapf(err, "caching %s", r)
+		}
+		klog.Infof("cached artifact %s as %s", r, desc.Digest)
+	}
+	return nil
+}
+
+// DeleteArtifacts removes the artifacts from the OCI image layout at dir, with the blobs no other artifact uses
+func DeleteArtifacts(refs []string, dir string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+	releaser, err := lockLayout(dir)
+	if err != nil {
+		return errors.Wrapf(err, "unable to acquire lock for %s", dir)
+	}
+	defer releaser.Release()
+
+	p, err := layout.FromPath(dir)
+	if err != nil {
+		return errors.Wrapf(err, "opening artifact cache %s", dir)
+	}
+	for _, r := range refs {
+		ref, err := parseArtifact(r)
+		if err != nil {
+			return errors.Wrapf(err, "parsing artifact reference %s", r)
+		}
+		if err := p.RemoveDescriptors(match.Annotation(refNameAnnotation, ref.Name())); err != nil {
+			return errors.Wrapf(err, "removing %s", r)
+		}
+	}
+	unused, err := p.GarbageCollect()
+	if err != nil {
+		return errors.Wrap(err, "finding unused blobs")
+	}
+	for _, h := range unused {
+		if err := p.RemoveBlob(h); err != nil {
+			return errors.Wrapf(err, "removing blob %s", h)
+		}
+	}
+	return nil
+}
+
+// PushArtifacts pushes the cached artifacts to the registry at addr, like 127.0.0.1:5000, keeping their repository and tag.
+// The cert of the registry is verified with the CA of the file ca, falling back to plain HTTP for registries without TLS.
+func PushArtifacts(refs []string, dir, addr, ca string) error {
+	tr, err := caTransport(ca)
+	if err != nil {
+		return errors.Wrap(err, "registry transport")
+	}
+	releaser, err := lockLayout(dir)
+	if err != nil {
+		return errors.Wrapf(err, "unable to acquire lock for %s", dir)
+	}
+	defer releaser.Release()
+
+	p, err := layout.FromPath(dir)
+	if err != nil {
+		return errors.Wrapf(err, "opening artifact cache %s", dir)
+	}
+	index, err := p.ImageIndex()
+	if err != nil {
+		return err
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return err
+	}
+	for _, r := range refs {
+		ref, err := parseArtifact(r)
+		if err != nil {
+			return errors.Wrapf(err, "parsing artifact reference %s", r)
+		}
+		desc, err := findArtifact(manifest, ref)
+		if err != nil {
+			return err
+		}
+		dst, err := registryRef(ref, addr)
+		if err != nil {
+			return err
+		}
+		if err := pushArtifact(index, desc, dst, tr); err != nil {
+			return errors.Wrapf(err, "pushing %s to %s", r, dst)
+		}
+		klog.Infof("pushed artifact %s to %s", r, dst)
+	}
+	return nil
+}
+
+// replaceArtifact writes the manifest or index of desc to the layout, in place of the previous version of ref
+func replaceArtifact(p layout.Path, desc *remote.Descriptor, ref name.Reference) error {
+	matcher := match.Annotation(refNameAnnotation, ref.Name())
+	opt := layout.WithAnnotations(map[string]string{refNameAnnotation: ref.Name()})
+	if desc.MediaType.IsIndex() {
+		idx, err := desc.ImageIndex()
+		if err != nil {
+			return err
+		}
+		return p.ReplaceIndex(idx, matcher, opt)
+	}
+	img, err := desc.Image()
+	if err != nil {
+		return err
+	}
+	return p.ReplaceImage(img, matcher, opt)
+}
+
+// pushArtifact pushes the cached manifest or index of desc to dst, through tr
+func pushArtifact(index v1.ImageIndex, desc v1.Descriptor, dst name.Reference, tr http.RoundTripper) error {
+	if desc.MediaType.IsIndex() {
+		idx, err := index.ImageIndex(desc.Digest)
+		if err != nil {
+			return err
+		}
+		return remote.WriteIndex(dst, idx, remote.WithTransport(tr))
+	}
+	img, err := index.Image(desc.Digest)
+	if err != nil {
+		return err
+	}
+	return remote.Write(dst, img, remote.WithTransport(tr))
+}
+
+// findArtifact finds the descriptor of ref in the index of the cache
+func findArtifact(manifest *v1.IndexManifest, ref name.Reference) (v1.Descriptor, error) {
+	for _, d := range manifest.Manifests {
+		if d.Annotations[refNameAnnotation] == ref.Name() {
+			return d, nil
+		}
+	}
+	return v1.Descriptor{}, fmt.Errorf("artifact %s is not cached", ref.Name())
+}
+
+// registryRef returns the reference of ref in the registry at addr
+func registryRef(ref name.Reference, addr string) (name.Reference, error) {
+	sep := ":"
+	if _, ok := ref.(name.Digest); ok {
+		sep = "@"
+	}
+	return name.ParseReference(fmt.Sprintf("%s/%s%s%s", addr, ref.Context().RepositoryStr(), sep, ref.Identifier()), name.Insecure)
+}
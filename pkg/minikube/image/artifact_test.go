@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestArtifactCache(t *testing.T) {
+	upstream := httptest.NewServer(registry.New())
+	defer upstream.Close()
+	inCluster := httptest.NewServer(registry.New())
+	defer inCluster.Close()
+
+	upstreamAddr := strings.TrimPrefix(upstream.URL, "http://")
+	chart := "oci://" + upstreamAddr + "/charts/nginx:15.0.0"
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := parseArtifact(chart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(src, img); err != nil {
+		t.Fatalf("pushing test artifact: %v", err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "artifacts")
+	if err := SaveArtifacts([]string{chart}, dir); err != nil {
+		t.Fatalf("SaveArtifacts: %v", err)
+	}
+	// saving again replaces the cached version instead of adding one
+	if err := SaveArtifacts([]string{chart}, dir); err != nil {
+		t.Fatalf("SaveArtifacts: %v", err)
+	}
+
+	inClusterAddr := strings.TrimPrefix(inCluster.URL, "http://")
+	if err := PushArtifacts([]string{chart}, dir, inClusterAddr, ""); err != nil {
+		t.Fatalf("PushArtifacts: %v", err)
+	}
+	dst, err := name.ParseReference(inClusterAddr + "/charts/nginx:15.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushed, err := remote.Image(dst)
+	if err != nil {
+		t.Fatalf("artifact not in the registry: %v", err)
+	}
+	want, _ := img.Digest()
+	if got, _ := pushed.Digest(); got != want {
+		t.Errorf("pushed digest = %s, want %s", got, want)
+	}
+
+	if err := PushArtifacts([]string{"oci://" + upstreamAddr + "/charts/redis:1.0.0"}, dir, inClusterAddr, ""); err == nil {
+		t.Errorf("PushArtifacts succeeded for an artifact which is not cached")
+	}
+
+	if err := DeleteArtifacts([]string{chart}, dir); err != nil {
+		t.Fatalf("DeleteArtifacts: %v", err)
+	}
+	blobs, err := os.ReadDir(filepath.Join(dir, "blobs", "sha256"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blobs) != 0 {
+		t.Errorf("DeleteArtifacts left %d blobs", len(blobs))
+	}
+}
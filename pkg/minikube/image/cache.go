@@ -28,12 +28,18 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/cachegc"
 	"k8s.io/minikube/pkg/minikube/detect"
 	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/transfer"
 	"k8s.io/minikube/pkg/util/lock"
+	"k8s.io/minikube/pkg/util/retry"
 )
 
+// maxAttempts is the number of attempts of caching an image before it fails
+const maxAttempts = 3
+
 type cacheError struct {
 	Err error
 }
@@ -58,6 +64,11 @@ func DeleteFromCacheDir(images []string) error {
 	return cleanImageCacheDir()
 }
 
+// CachePath returns the path of the tarball of image in cacheDir
+func CachePath(cacheDir, image string) string {
+	return localpath.SanitizeCacheDir(filepath.Join(cacheDir, image))
+}
+
 // SaveToDir will cache images on the host
 //
 // The cache directory currently caches images using the imagename_tag
@@ -65,11 +76,11 @@ func DeleteFromCacheDir(images []string) error {
 // stored at $CACHE_DIR/registry.k8s.io/kube-addon-manager_v6.5
 func SaveToDir(images []string, cacheDir string, overwrite bool) error {
 	var g errgroup.Group
+	g.SetLimit(transfer.Parallelism())
 	for _, image := range images {
 		image := image
 		g.Go(func() error {
-			dst := filepath.Join(cacheDir, image)
-			dst = localpath.SanitizeCacheDir(dst)
+			dst := CachePath(cacheDir, image)
 			if err := saveToTarFile(image, dst, overwrite); err != nil {
 				if err == errCacheImageDoesntExist {
 					out.WarningT("The image '{{.imageName}}' was not found; unable to add it to cache.", out.V{"imageName": image})
@@ -113,6 +124,7 @@ func saveToTarFile(iname, rawDest string, overwrite bool) error {
 
 	if _, err := os.Stat(dst); !overwrite && err == nil {
 		klog.Infof("%s exists", dst)
+		cachegc.Touch(dst)
 		return nil
 	}
 
@@ -149,10 +161,20 @@ func saveToTarFile(iname, rawDest string, overwrite bool) error {
 		}
 	}
 
-	err = writeImage(img, dst, ref)
-	if err != nil {
+	// a failed attempt leaves the layers it completed in the layer cache, the next one resumes from them
+	attempt := 0
+	write := func() error {
+		attempt++
+		err := writeImage(img, dst, ref)
+		if err != nil {
+			klog.Warningf("attempt %d of caching %s failed: %v", attempt, iname, err)
+		}
+		return err
+	}
+	if err := retry.Expo(write, 2*time.Second, 5*time.Minute, maxAttempts-1); err != nil {
 		return err
 	}
+	newLayerCache(layersDir()).forget(img)
 
 	klog.Infof("%s exists", dst)
 	return nil
@@ -29,6 +29,7 @@ import (
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
 	"github.com/google/go-containerregistry/pkg/v1/daemon"
 	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
@@ -38,6 +39,7 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/minikube/detect"
 	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/transfer"
 )
 
 const (
@@ -179,18 +181,20 @@ func retrieveDaemon(ref name.Reference) (v1.Image, error) {
 }
 
 func retrieveRemote(ref name.Reference, p v1.Platform) (v1.Image, error) {
-	img, err := remote.Image(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithPlatform(p))
+	tr := remote.WithTransport(transfer.Transport(remote.DefaultTransport))
+	img, err := remote.Image(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithPlatform(p), tr)
 	if err == nil {
-		return img, nil
+		return cache.Image(img, newLayerCache(layersDir())), nil
 	}
 
 	klog.Warningf("authn lookup for %+v (trying anon): %+v", ref, err)
-	img, err = remote.Image(ref, remote.WithPlatform(p))
+	img, err = remote.Image(ref, remote.WithPlatform(p), tr)
 	// reference does not exist in the remote registry
 	if err != nil {
 		klog.Infof("remote lookup for %+v: %v", ref, err)
+		return img, err
 	}
-	return img, err
+	return cache.Image(img, newLayerCache(layersDir())), nil
 }
 
 // imagePathInCache returns path in local cache directory
@@ -275,7 +279,7 @@ func fixPlatform(ref name.Reference, img v1.Image, p v1.Platform) (v1.Image, err
 }
 
 func cleanImageCacheDir() error {
-	err := filepath.Walk(localpath.MakeMiniPath("cache", "images"), func(path string, info os.FileInfo, err error) error {
+	err := filepath.Walk(localpath.MakeCachePath("images"), func(path string, info os.FileInfo, err error) error {
 		// If error is not nil, it's because the path was already deleted and doesn't exist
 		// Move on to next path
 		if err != nil {
@@ -0,0 +1,103 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+// layersDir keeps the layers of the images pulled from registries until their image is cached,
+// so a pull interrupted by a failure resumes from the layers it completed
+func layersDir() string {
+	return localpath.MakeMiniPath("cache", "layers")
+}
+
+// layerCache is a filesystem cache of layers which drops the layers left incomplete by an interrupted pull
+type layerCache struct {
+	cache.Cache
+	dir string
+}
+
+func newLayerCache(dir string) *layerCache {
+	return &layerCache{Cache: cache.NewFilesystemCache(dir), dir: dir}
+}
+
+// Get returns the layer h if it was completely pulled
+func (c *layerCache) Get(h v1.Hash) (v1.Layer, error) {
+	if !c.complete(h) {
+		return nil, cache.ErrNotFound
+	}
+	return c.Cache.Get(h)
+}
+
+// complete returns whether the file of the layer h has the digest h, removing it otherwise
+func (c *layerCache) complete(h v1.Hash) bool {
+	p := c.path(h)
+	f, err := os.Open(p)
+	if err != nil {
+		return false
+	}
+	sum := sha256.New()
+	_, err = io.Copy(sum, f)
+	f.Close()
+	if err == nil && h.Algorithm == "sha256" && hex.EncodeToString(sum.Sum(nil)) == h.Hex {
+		return true
+	}
+	klog.Infof("dropping the incomplete layer %s", h)
+	if err := os.Remove(p); err != nil {
+		klog.Warningf("failed to remove %s: %v", p, err)
+	}
+	return false
+}
+
+// path returns the file of the layer h, named like the filesystem cache of go-containerregistry
+func (c *layerCache) path(h v1.Hash) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(c.dir, fmt.Sprintf("%s-%s", h.Algorithm, h.Hex))
+	}
+	return filepath.Join(c.dir, h.String())
+}
+
+// forget removes the layers of img, once it is cached
+func (c *layerCache) forget(img v1.Image) {
+	var hashes []v1.Hash
+	if m, err := img.Manifest(); err == nil {
+		for _, l := range m.Layers {
+			hashes = append(hashes, l.Digest)
+		}
+	}
+	if cf, err := img.ConfigFile(); err == nil {
+		hashes = append(hashes, cf.RootFS.DiffIDs...)
+	}
+	for _, h := range hashes {
+		if err := os.Remove(c.path(h)); err != nil && !os.IsNotExist(err) {
+			klog.Warningf("failed to remove the layer %s: %v", h, err)
+		}
+	}
+}
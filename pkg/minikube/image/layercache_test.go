@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+)
+
+func TestLayerCache(t *testing.T) {
+	c := newLayerCache(t.TempDir())
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := cache.Image(img, c).Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range layers {
+		rc, err := l.Compressed()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.Copy(io.Discard, rc); err != nil {
+			t.Fatal(err)
+		}
+		rc.Close()
+	}
+
+	complete, _ := layers[0].Digest()
+	if _, err := c.Get(complete); err != nil {
+		t.Errorf("Get of a pulled layer: %v", err)
+	}
+
+	// an interrupted pull leaves a truncated layer
+	incomplete, _ := layers[1].Digest()
+	if err := os.Truncate(c.path(incomplete), 10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(incomplete); err != cache.ErrNotFound {
+		t.Errorf("Get of an incomplete layer = %v, want %v", err, cache.ErrNotFound)
+	}
+	if _, err := os.Stat(c.path(incomplete)); !os.IsNotExist(err) {
+		t.Errorf("the incomplete layer was not removed: %v", err)
+	}
+
+	c.forget(img)
+	if _, err := os.Stat(c.path(complete)); !os.IsNotExist(err) {
+		t.Errorf("the layer of the cached image was not removed: %v", err)
+	}
+}
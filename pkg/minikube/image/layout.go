@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// imageNameAnnotation is the annotation of the full name of the images in the OCI image layouts written by containerd and buildkit
+const imageNameAnnotation = "io.containerd.image.name"
+
+// IsLayout returns whether dir is an OCI image layout
+func IsLayout(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "oci-layout"))
+	return err == nil
+}
+
+// LayoutToTarball writes the images of the OCI image layout dir, for the platform of the nodes, into the docker tarball dst, which all the container runtimes load.
+// The images are named by their io.containerd.image.name annotation, or else by their org.opencontainers.image.ref.name annotation,
+// a tag of the repository named after dir if it is not a full name. It returns the names of the images.
+func LayoutToTarball(dir, dst string) ([]string, error) {
+	p, err := layout.FromPath(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "opening the OCI image layout %s", dir)
+	}
+	index, err := p.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := index.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	refs := map[name.Reference]v1.Image{}
+	var names []string
+	for _, d := range manifest.Manifests {
+		ref, err := layoutRef(dir, d.Annotations)
+		if err != nil {
+			klog.Warningf("skipping the image %s of %s: %v", d.Digest, dir, err)
+			continue
+		}
+		img, err := layoutImage(index, d)
+		if err != nil {
+			return nil, errors.Wrapf(err, "image %s of %s", ref.Name(), dir)
+		}
+		refs[ref] = img
+		names = append(names, ref.Name())
+	}
+	if len(refs) == 0 {
+		return nil, errors.Errorf("no named image in %s", dir)
+	}
+	if err := tarball.MultiRefWriteToFile(dst, refs); err != nil {
+		return nil, errors.Wrapf(err, "writing %s", dst)
+	}
+	return names, nil
+}
+
+// layoutRef returns the name of the image of the OCI image layout dir with the annotations
+func layoutRef(dir string, annotations map[string]string) (name.Reference, error) {
+	if n := annotations[imageNameAnnotation]; n != "" {
+		return name.ParseReference(n, name.WeakValidation)
+	}
+	n := annotations[refNameAnnotation]
+	if n == "" {
+		return nil, fmt.Errorf("the image has neither an %s nor an %s annotation", imageNameAnnotation, refNameAnnotation)
+	}
+	// the annotation is only a tag for most tools
+	if !strings.ContainsAny(n, "/:@") {
+		n = strings.ToLower(filepath.Base(filepath.Clean(dir))) + ":" + n
+	}
+	return name.ParseReference(n, name.WeakValidation)
+}
+
+// layoutImage returns the image of the descriptor d of index, the one of the platform of the nodes for an image index
+func layoutImage(index v1.ImageIndex, d v1.Descriptor) (v1.Image, error) {
+	if !d.MediaType.IsIndex() {
+		return index.Image(d.Digest)
+	}
+	child, err := index.ImageIndex(d.Digest)
+	if err != nil {
+		return nil, err
+	}
+	m, err := child.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range m.Manifests {
+		if c.Platform != nil && c.Platform.OS == defaultPlatform.OS && c.Platform.Architecture == defaultPlatform.Architecture {
+			return child.Image(c.Digest)
+		}
+	}
+	return nil, errors.Errorf("no image for %s/%s", defaultPlatform.OS, defaultPlatform.Architecture)
+}
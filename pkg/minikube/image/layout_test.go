@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+func TestLayoutToTarball(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "App")
+	p, err := layout.Write(dir, empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if IsLayout(filepath.Dir(dir)) || !IsLayout(dir) {
+		t.Errorf("IsLayout() does not detect the OCI image layout")
+	}
+	tagged, _ := random.Image(1024, 1)
+	named, _ := random.Image(1024, 1)
+	unnamed, _ := random.Image(1024, 1)
+	for img, ann := range map[v1.Image]map[string]string{
+		tagged:  {refNameAnnotation: "dev"},
+		named:   {imageNameAnnotation: "ghcr.io/example/api:v2", refNameAnnotation: "v2"},
+		unnamed: nil,
+	} {
+		if err := p.AppendImage(img, layout.WithAnnotations(ann)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := filepath.Join(t.TempDir(), "images.tar")
+	names, err := LayoutToTarball(dir, dst)
+	if err != nil {
+		t.Fatalf("LayoutToTarball: %v", err)
+	}
+	sort.Strings(names)
+	if got, want := strings.Join(names, " "), "ghcr.io/example/api:v2 index.docker.io/library/app:dev"; got != want {
+		t.Errorf("LayoutToTarball() = %s, want %s", got, want)
+	}
+	for n, img := range map[string]v1.Image{"app:dev": tagged, "ghcr.io/example/api:v2": named} {
+		tag, err := name.NewTag(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := tarball.ImageFromPath(dst, &tag)
+		if err != nil {
+			t.Fatalf("%s is not in the tarball: %v", n, err)
+		}
+		want, _ := img.Digest()
+		if d, _ := got.Digest(); d != want {
+			t.Errorf("%s has digest %s, want %s", n, d, want)
+		}
+	}
+
+	if _, err := LayoutToTarball(filepath.Dir(dir), dst); err == nil {
+		t.Errorf("LayoutToTarball succeeded for a directory which is not an OCI image layout")
+	}
+}
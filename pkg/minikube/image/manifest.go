@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"k8s.io/klog/v2"
+)
+
+// containerKeys are the fields of a pod spec listing containers
+var containerKeys = map[string]bool{
+	"containers":          true,
+	"initContainers":      true,
+	"ephemeralContainers": true,
+}
+
+// FromManifests returns the images referenced by the manifests at paths, sorted and deduplicated.
+// A path is a manifest file, a Helm chart directory, or a directory searched recursively for both.
+func FromManifests(paths []string) ([]string, error) {
+	found := map[string]bool{}
+	for _, p := range paths {
+		if err := walkManifests(p, found); err != nil {
+			return nil, err
+		}
+	}
+	images := make([]string, 0, len(found))
+	for img := range found {
+		images = append(images, img)
+	}
+	sort.Strings(images)
+	return images, nil
+}
+
+func walkManifests(root string, found map[string]bool) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if _, err := os.Stat(filepath.Join(path, "Chart.yaml")); err == nil {
+				// the templates of a chart are not YAML before they are rendered
+				return chartImages(path, found)
+			}
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			if path != root {
+				return nil
+			}
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return errors.Wrapf(err, "opening %s", path)
+		}
+		defer f.Close()
+		return errors.Wrapf(manifestImages(f, found), "parsing %s", path)
+	})
+}
+
+// chartImages renders the Helm chart at dir with its default values, and adds the images it references
+func chartImages(dir string, found map[string]bool) error {
+	helm, err := exec.LookPath("helm")
+	if err != nil {
+		return errors.Errorf("helm is required to render the chart %s", dir)
+	}
+	klog.Infof("rendering chart %s", dir)
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(helm, "template", dir)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return errors.Wrapf(err, "rendering chart %s: %s", dir, stderr.String())
+	}
+	if err := manifestImages(&stdout, found); err != nil {
+		return errors.Wrapf(err, "parsing rendered chart %s", dir)
+	}
+	return filepath.SkipDir
+}
+
+// manifestImages adds the images of the containers in the YAML or JSON documents read from r
+func manifestImages(r io.Reader, found map[string]bool) error {
+	dec := yaml.NewDecoder(r)
+	for {
+		var doc interface{}
+		err := dec.Decode(&doc)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		collectImages(doc, false, found)
+	}
+}
+
+// collectImages walks a document, as pods are nested differently in every workload kind and custom resource
+func collectImages(v interface{}, inContainers bool, found map[string]bool) {
+	switch v := v.(type) {
+	case map[interface{}]interface{}:
+		for k, child := range v {
+			key, _ := k.(string)
+			if inContainers && key == "image" {
+				if img, ok := child.(string); ok && img != "" {
+					found[img] = true
+				}
+				continue
+			}
+			collectImages(child, containerKeys[key], found)
+		}
+	case []interface{}:
+		for _, child := range v {
+			// the containers are the items of the list
+			if inContainers {
+				if m, ok := child.(map[interface{}]interface{}); ok {
+					collectImages(m, true, found)
+					continue
+				}
+			}
+			collectImages(child, false, found)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    image: not-an-image
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox:1.36
+      containers:
+      - name: web
+        image: nginx:1.25
+        env:
+        - name: image
+          value: not-an-image
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: backup
+            image: registry.k8s.io/busybox@sha256:4bdd623e848417d96127e16037743f0cd8b528c026e9175e22a84f639eca58ff
+`
+
+const pod = `{"apiVersion": "v1", "kind": "Pod", "spec": {"containers": [{"name": "web", "image": "nginx:1.25"}]}}`
+
+func TestFromManifests(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"deployment.yaml":  deployment,
+		"nested/pod.json":  pod,
+		"nested/README.md": "image: not-a-manifest",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{
+		"busybox:1.36",
+		"nginx:1.25",
+		"registry.k8s.io/busybox@sha256:4bdd623e848417d96127e16037743f0cd8b528c026e9175e22a84f639eca58ff",
+	}
+	got, err := FromManifests([]string{dir})
+	if err != nil {
+		t.Fatalf("FromManifests: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FromManifests() = %v, want %v", got, want)
+	}
+
+	got, err = FromManifests([]string{filepath.Join(dir, "nested", "pod.json")})
+	if err != nil {
+		t.Fatalf("FromManifests: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"nginx:1.25"}) {
+		t.Errorf("FromManifests() = %v, want [nginx:1.25]", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("a: [b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FromManifests([]string{dir}); err == nil {
+		t.Errorf("FromManifests succeeded with an invalid manifest")
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/transfer"
+)
+
+// caTransport returns a transport trusting the CA cert of the file ca next to the roots of the system, or the default one if ca is empty
+func caTransport(ca string) (http.RoundTripper, error) {
+	if ca == "" {
+		return transfer.Transport(remote.DefaultTransport), nil
+	}
+	b, err := os.ReadFile(ca)
+	if err != nil {
+		return nil, err
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		klog.Warningf("system cert pool: %v", err)
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(b) {
+		return nil, errors.Errorf("no certificate in %s", ca)
+	}
+	tr := remote.DefaultTransport.(*http.Transport).Clone()
+	tr.TLSClientConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	return transfer.Transport(tr), nil
+}
+
+// PushToRegistry pushes the image img of the docker daemon of the host, or else of its registry, to the registry at addr,
+// whose cert is signed by the CA of the file ca. It returns the name of the pushed image, of the same repository and tag in the registry.
+func PushToRegistry(img, addr, ca string) (string, error) {
+	ref, err := name.ParseReference(img, name.WeakValidation)
+	if err != nil {
+		return "", errors.Wrapf(err, "parsing image reference %s", img)
+	}
+	dst, err := registryRef(ref, addr)
+	if err != nil {
+		return "", err
+	}
+	tr, err := caTransport(ca)
+	if err != nil {
+		return "", errors.Wrap(err, "registry transport")
+	}
+
+	var src v1.Image
+	if useDaemon {
+		src, err = retrieveDaemon(ref)
+	}
+	if src == nil {
+		src, err = remote.Image(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithPlatform(defaultPlatform), remote.WithTransport(transfer.Transport(remote.DefaultTransport)))
+		if err != nil {
+			return "", errors.Wrapf(err, "%s is neither in the docker daemon nor in its registry", img)
+		}
+	}
+	klog.Infof("pushing %s to %s", img, dst)
+	if err := remote.Write(dst, src, remote.WithTransport(tr)); err != nil {
+		return "", errors.Wrapf(err, "pushing %s to %s", img, dst)
+	}
+	return dst.Name(), nil
+}
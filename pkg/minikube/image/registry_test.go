@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"encoding/pem"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+func TestPushToRegistry(t *testing.T) {
+	upstream := httptest.NewServer(registry.New())
+	defer upstream.Close()
+	inCluster := httptest.NewTLSServer(registry.New())
+	defer inCluster.Close()
+
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := strings.TrimPrefix(upstream.URL, "http://") + "/team/app:v1"
+	ref, err := name.ParseReference(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatalf("pushing test image: %v", err)
+	}
+
+	ca := filepath.Join(t.TempDir(), "ca.crt")
+	if err := os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: inCluster.Certificate().Raw}), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	UseDaemon(false)
+	defer UseDaemon(true)
+	addr := strings.TrimPrefix(inCluster.URL, "https://")
+	got, err := PushToRegistry(src, addr, ca)
+	if err != nil {
+		t.Fatalf("PushToRegistry: %v", err)
+	}
+	if want := addr + "/team/app:v1"; got != want {
+		t.Errorf("PushToRegistry() = %s, want %s", got, want)
+	}
+	dst, err := name.ParseReference(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pushed, err := remote.Image(dst, remote.WithTransport(inCluster.Client().Transport))
+	if err != nil {
+		t.Fatalf("image not in the registry: %v", err)
+	}
+	want, _ := img.Digest()
+	if d, _ := pushed.Digest(); d != want {
+		t.Errorf("pushed digest = %s, want %s", d, want)
+	}
+
+	// the cert of the registry is verified
+	if _, err := PushToRegistry(src, addr, ""); err == nil {
+		t.Errorf("PushToRegistry succeeded without trusting the CA of the registry")
+	}
+}
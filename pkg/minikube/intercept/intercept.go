@@ -0,0 +1,232 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package intercept routes the traffic of an in-cluster service to a process running on the host.
+//
+// The service is swapped to select a relay pod running in the host network of the control plane node,
+// which forwards connections to a reverse SSH tunnel that ends at the host process.
+package intercept
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// Label is set on the relay pod of an intercepted service, with the service name as value
+	Label = "minikube.sigs.k8s.io/intercept"
+	// originalAnnotation stores the selector and ports of an intercepted service, so that it can be restored
+	originalAnnotation = "minikube.sigs.k8s.io/intercept-original"
+)
+
+// RelayImage is the image of the pod relaying intercepted traffic to the host
+var RelayImage = "docker.io/alpine/socat:1.7.4.4"
+
+// original is the part of a service spec which is modified by an intercept
+type original struct {
+	Selector map[string]string  `json:"selector"`
+	Ports    []core.ServicePort `json:"ports"`
+}
+
+// Options describes an intercept
+type Options struct {
+	Namespace string
+	Service   string
+	// Port is the service port to intercept, 0 means the only port of the service
+	Port int32
+	// NodeName and NodeIP identify the node the reverse tunnel ends on
+	NodeName string
+	NodeIP   string
+	// TunnelPort is the port the reverse tunnel listens on, on the loopback interface of the node
+	TunnelPort int
+}
+
+// PodName returns the name of the relay pod for a service
+func PodName(svc string) string {
+	return "minikube-intercept-" + svc
+}
+
+// Redirect swaps the service to a relay pod forwarding traffic to the reverse tunnel
+func Redirect(ctx context.Context, c kubernetes.Interface, o Options) error {
+	svcs := c.CoreV1().Services(o.Namespace)
+	svc, err := svcs.Get(ctx, o.Service, meta.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "get service %s/%s", o.Namespace, o.Service)
+	}
+	if _, ok := svc.Annotations[originalAnnotation]; ok {
+		return fmt.Errorf("service %s/%s is already intercepted, run with --restore to restore it first", o.Namespace, o.Service)
+	}
+
+	idx, err := portIndex(svc, o.Port)
+	if err != nil {
+		return err
+	}
+
+	orig, err := json.Marshal(original{Selector: svc.Spec.Selector, Ports: svc.Spec.Ports})
+	if err != nil {
+		return errors.Wrap(err, "marshal original service spec")
+	}
+
+	if err := createRelayPod(ctx, c, o); err != nil {
+		return err
+	}
+
+	if svc.Annotations == nil {
+		svc.Annotations = map[string]string{}
+	}
+	svc.Annotations[originalAnnotation] = string(orig)
+	svc.Spec.Selector = map[string]string{Label: o.Service}
+	svc.Spec.Ports[idx].TargetPort = intstr.FromInt(o.TunnelPort)
+	if _, err := svcs.Update(ctx, svc, meta.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "update service %s/%s", o.Namespace, o.Service)
+	}
+	klog.Infof("service %s/%s now targets relay pod port %d", o.Namespace, o.Service, o.TunnelPort)
+	return nil
+}
+
+// Restore restores an intercepted service to its original selector and ports, and deletes the relay pod
+func Restore(ctx context.Context, c kubernetes.Interface, ns, name string) error {
+	svcs := c.CoreV1().Services(ns)
+	svc, err := svcs.Get(ctx, name, meta.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "get service %s/%s", ns, name)
+	}
+
+	if s, ok := svc.Annotations[originalAnnotation]; ok {
+		var orig original
+		if err := json.Unmarshal([]byte(s), &orig); err != nil {
+			return errors.Wrapf(err, "unmarshal original spec of service %s/%s", ns, name)
+		}
+		svc.Spec.Selector = orig.Selector
+		svc.Spec.Ports = orig.Ports
+		delete(svc.Annotations, originalAnnotation)
+		if _, err := svcs.Update(ctx, svc, meta.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "restore service %s/%s", ns, name)
+		}
+		klog.Infof("restored service %s/%s", ns, name)
+	}
+
+	err = c.CoreV1().Pods(ns).Delete(ctx, PodName(name), meta.DeleteOptions{})
+	if err != nil && !apierr.IsNotFound(err) {
+		return errors.Wrapf(err, "delete relay pod %s/%s", ns, PodName(name))
+	}
+	return nil
+}
+
+// portIndex returns the index of the service port to intercept
+func portIndex(svc *core.Service, port int32) (int, error) {
+	if len(svc.Spec.Ports) == 0 {
+		return 0, fmt.Errorf("service %s/%s has no ports", svc.Namespace, svc.Name)
+	}
+	if port == 0 {
+		if len(svc.Spec.Ports) > 1 {
+			return 0, fmt.Errorf("service %s/%s has %d ports, specify which one to intercept with --port", svc.Namespace, svc.Name, len(svc.Spec.Ports))
+		}
+		return 0, nil
+	}
+	for i, p := range svc.Spec.Ports {
+		if p.Port == port {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("service %s/%s has no port %d", svc.Namespace, svc.Name, port)
+}
+
+// createRelayPod creates a pod in the host network of the node, relaying the node IP to the reverse tunnel on the loopback interface
+func createRelayPod(ctx context.Context, c kubernetes.Interface, o Options) error {
+	port := strconv.Itoa(o.TunnelPort)
+	pod := &core.Pod{
+		ObjectMeta: meta.ObjectMeta{
+			Name:      PodName(o.Service),
+			Namespace: o.Namespace,
+			Labels:    map[string]string{Label: o.Service},
+		},
+		Spec: core.PodSpec{
+			HostNetwork:   true,
+			NodeName:      o.NodeName,
+			RestartPolicy: core.RestartPolicyAlways,
+			Containers: []core.Container{{
+				Name:  "relay",
+				Image: RelayImage,
+				Args: []string{
+					fmt.Sprintf("TCP-LISTEN:%s,bind=%s,fork,reuseaddr", port, o.NodeIP),
+					"TCP:127.0.0.1:" + port,
+				},
+				Ports: []core.ContainerPort{{ContainerPort: int32(o.TunnelPort), Protocol: core.ProtocolTCP}},
+			}},
+		},
+	}
+	if _, err := c.CoreV1().Pods(o.Namespace).Create(ctx, pod, meta.CreateOptions{}); err != nil {
+		return errors.Wrapf(err, "create relay pod %s/%s", o.Namespace, pod.Name)
+	}
+	return nil
+}
+
+// Relay accepts connections on the listener (the remote end of the reverse tunnel) and forwards them to target, until ctx is done
+func Relay(ctx context.Context, l net.Listener, target string) error {
+	go func() {
+		<-ctx.Done()
+		l.Close()
+	}()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrap(err, "accept")
+		}
+		go relayConn(conn, target)
+	}
+}
+
+func relayConn(conn net.Conn, target string) {
+	defer conn.Close()
+	dst, err := net.Dial("tcp", target)
+	if err != nil {
+		klog.Warningf("unable to reach %s: %v", target, err)
+		return
+	}
+	defer dst.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	cp := func(w io.Writer, r io.Reader) {
+		defer wg.Done()
+		if _, err := io.Copy(w, r); err != nil {
+			klog.V(2).Infof("relay %s: %v", target, err)
+		}
+		// unblock the other direction
+		conn.Close()
+		dst.Close()
+	}
+	go cp(dst, conn)
+	go cp(conn, dst)
+	wg.Wait()
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package intercept
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	apierr "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRedirectAndRestore(t *testing.T) {
+	ctx := context.Background()
+	svc := &core.Service{
+		ObjectMeta: meta.ObjectMeta{Name: "backend", Namespace: "default"},
+		Spec: core.ServiceSpec{
+			Selector: map[string]string{"app": "backend"},
+			Ports:    []core.ServicePort{{Name: "http", Port: 80, TargetPort: intstr.FromInt(8080)}},
+		},
+	}
+	c := fake.NewSimpleClientset(svc)
+
+	o := Options{Namespace: "default", Service: "backend", NodeName: "minikube", NodeIP: "192.168.49.2", TunnelPort: 40000}
+	if err := Redirect(ctx, c, o); err != nil {
+		t.Fatalf("Redirect() error = %v", err)
+	}
+
+	got, err := c.CoreV1().Services("default").Get(ctx, "backend", meta.GetOptions{})
+	if err != nil {
+		t.Fatalf("get service: %v", err)
+	}
+	if got.Spec.Selector[Label] != "backend" || len(got.Spec.Selector) != 1 {
+		t.Errorf("expected service to select the relay pod, got %v", got.Spec.Selector)
+	}
+	if got.Spec.Ports[0].TargetPort.IntValue() != 40000 {
+		t.Errorf("expected target port 40000, got %v", got.Spec.Ports[0].TargetPort)
+	}
+	pod, err := c.CoreV1().Pods("default").Get(ctx, PodName("backend"), meta.GetOptions{})
+	if err != nil {
+		t.Fatalf("get relay pod: %v", err)
+	}
+	if !pod.Spec.HostNetwork || pod.Spec.NodeName != "minikube" {
+		t.Errorf("expected relay pod in the host network of node minikube, got %+v", pod.Spec)
+	}
+
+	if err := Redirect(ctx, c, o); err == nil {
+		t.Errorf("Redirect() of an intercepted service should have returned error, but didn't")
+	}
+
+	if err := Restore(ctx, c, "default", "backend"); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	got, err = c.CoreV1().Services("default").Get(ctx, "backend", meta.GetOptions{})
+	if err != nil {
+		t.Fatalf("get service: %v", err)
+	}
+	if got.Spec.Selector["app"] != "backend" || got.Spec.Ports[0].TargetPort.IntValue() != 8080 {
+		t.Errorf("service was not restored: %+v", got.Spec)
+	}
+	if _, ok := got.Annotations[originalAnnotation]; ok {
+		t.Errorf("expected the original spec annotation to be removed")
+	}
+	if _, err := c.CoreV1().Pods("default").Get(ctx, PodName("backend"), meta.GetOptions{}); !apierr.IsNotFound(err) {
+		t.Errorf("expected relay pod to be deleted, got %v", err)
+	}
+}
+
+func TestPortIndex(t *testing.T) {
+	svc := &core.Service{Spec: core.ServiceSpec{Ports: []core.ServicePort{{Port: 80}, {Port: 443}}}}
+	if _, err := portIndex(svc, 0); err == nil {
+		t.Errorf("portIndex() with several ports and no --port should have returned error, but didn't")
+	}
+	if i, err := portIndex(svc, 443); err != nil || i != 1 {
+		t.Errorf("portIndex(443) = %d, %v; want 1", i, err)
+	}
+	if _, err := portIndex(svc, 8443); err == nil {
+		t.Errorf("portIndex() with an unknown port should have returned error, but didn't")
+	}
+}
+
+func TestRelay(t *testing.T) {
+	target, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer target.Close()
+	go func() {
+		conn, err := target.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		_, _ = conn.Write([]byte("echo: " + line))
+	}()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- Relay(ctx, l, target.Addr().String()) }()
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatalf("dial relay: %v", err)
+	}
+	if _, err := conn.Write([]byte("hello\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	conn.Close()
+	if reply != "echo: hello\n" {
+		t.Errorf("unexpected reply %q", reply)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Relay() error = %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kata installs Kata Containers into the nodes, and the RuntimeClass running pods in its lightweight VMs
+package kata
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/sandbox"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+)
+
+const (
+	// Name is the value of --runtime-class installing Kata, its runtime handler and the name of its RuntimeClass
+	Name = "kata"
+	// Version is the release of Kata Containers installed into the nodes
+	Version = "3.2.0"
+
+	// shim is the containerd shim of Kata, which cri-o runs as a VM runtime too
+	shim           = "/opt/kata/bin/containerd-shim-kata-v2"
+	shimLink       = "/usr/local/bin/containerd-shim-kata-v2"
+	crioConfigFile = "/etc/crio/crio.conf.d/10-kata.conf"
+)
+
+// RuntimeClasses are the valid values of --runtime-class
+var RuntimeClasses = []string{Name}
+
+// archs are the architectures Kata has static releases for
+var archs = map[string]bool{
+	"amd64": true,
+	"arm64": true,
+}
+
+// containerdHandler is the runtime handler of Kata in the config of containerd
+const containerdHandler = `
+[plugins."io.containerd.grpc.v1.cri".containerd.runtimes.kata]
+  runtime_type = "io.containerd.kata.v2"
+  privileged_without_host_devices = true
+`
+
+// crioHandler is the runtime handler of Kata in the config of cri-o
+const crioHandler = `[crio.runtime.runtimes.kata]
+runtime_path = "` + shimLink + `"
+runtime_type = "vm"
+runtime_root = "/run/vc"
+privileged_without_host_devices = true
+`
+
+// RuntimeClass runs the pods selecting it in the VMs of Kata, accounting for the memory and CPU of the VM of each pod
+const RuntimeClass = `apiVersion: node.k8s.io/v1
+kind: RuntimeClass
+metadata:
+  name: kata
+  labels:
+    app.kubernetes.io/managed-by: minikube
+handler: kata
+overhead:
+  podFixed:
+    memory: 160Mi
+    cpu: 250m
+`
+
+// URL returns the URL of the static release tarball of Kata for the architecture arch
+func URL(arch string) (string, error) {
+	if !archs[arch] {
+		return "", fmt.Errorf("kata containers has no release for the %s architecture", arch)
+	}
+	return fmt.Sprintf("https://github.com/kata-containers/kata-containers/releases/download/%s/kata-static-%s-%s.tar.xz", Version, Version, arch), nil
+}
+
+// SupportsRuntime returns whether Kata can be registered with the container runtime rt
+func SupportsRuntime(rt string) bool {
+	return rt == constants.Containerd || rt == constants.CRIO
+}
+
+// CheckNode returns an error if the node cannot run the VMs of Kata, as it has no /dev/kvm
+func CheckNode(r command.Runner) error {
+	if _, err := r.RunCmd(exec.Command("test", "-c", "/dev/kvm")); err != nil {
+		return errors.New("the node has no /dev/kvm, which Kata needs to run the VMs of the pods")
+	}
+	return nil
+}
+
+// Install extracts Kata from the release tarball into the node, and registers its runtime handler with the container runtime rt.
+// It is done on every start, as the filesystem of the VM is not persistent.
+// The container runtime must be restarted afterwards for the handler to take effect.
+func Install(r command.Runner, rt, tarball string) error {
+	if !SupportsRuntime(rt) {
+		return fmt.Errorf("kata containers is not supported by the %s container runtime", rt)
+	}
+	if _, err := r.RunCmd(exec.Command("test", "-x", shim)); err != nil {
+		// the tarball is kept on the persistent disk, as /opt is not persistent in the VM
+		dst := path.Join(vmpath.GuestPersistentDir, filepath.Base(tarball))
+		if _, err := r.RunCmd(exec.Command("test", "-f", dst)); err != nil {
+			f, err := assets.NewFileAsset(tarball, path.Dir(dst), path.Base(dst), "0644")
+			if err != nil {
+				return errors.Wrap(err, "open kata tarball")
+			}
+			defer func() {
+				if err := f.Close(); err != nil {
+					klog.Warningf("error closing the file %s: %v", f.GetSourcePath(), err)
+				}
+			}()
+			if err := r.Copy(f); err != nil {
+				return errors.Wrap(err, "copy kata tarball")
+			}
+		}
+		if _, err := r.RunCmd(exec.Command("sudo", "tar", "-xJf", dst, "-C", "/")); err != nil {
+			return errors.Wrap(err, "extract kata")
+		}
+	}
+	if _, err := r.RunCmd(exec.Command("sudo", "ln", "-sf", shim, shimLink)); err != nil {
+		return errors.Wrap(err, "link kata shim")
+	}
+
+	if rt == constants.CRIO {
+		if err := r.Copy(assets.NewMemoryAssetTarget([]byte(crioHandler), crioConfigFile, "0644")); err != nil {
+			return errors.Wrap(err, "write cri-o config")
+		}
+		return nil
+	}
+	return sandbox.AddContainerdHandler(r, Name, containerdHandler)
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kata
+
+import (
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+func TestURL(t *testing.T) {
+	got, err := URL("amd64")
+	if err != nil {
+		t.Fatalf("URL: %v", err)
+	}
+	if want := "https://github.com/kata-containers/kata-containers/releases/download/" + Version + "/kata-static-" + Version + "-amd64.tar.xz"; got != want {
+		t.Errorf("URL() = %q, want %q", got, want)
+	}
+	if _, err := URL("ppc64le"); err == nil {
+		t.Errorf("URL() of an architecture without release did not fail")
+	}
+	if !SupportsRuntime(constants.CRIO) || SupportsRuntime(constants.Docker) {
+		t.Errorf("SupportsRuntime() does not match the runtimes with handlers")
+	}
+}
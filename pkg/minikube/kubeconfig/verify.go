@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/tools/clientcmd/api"
+	pkgutil "k8s.io/minikube/pkg/util"
+)
+
+// dialTimeout is how long to wait for the API server when verifying a kubeconfig
+const dialTimeout = 5 * time.Second
+
+// Verify checks the kubeconfig entries of a cluster against the settings minikube would write,
+// and against the running API server. It returns the problems found.
+func Verify(kcs *Settings) ([]string, error) {
+	cfg, err := readOrNew(kcs.filePath())
+	if err != nil {
+		return nil, err
+	}
+	return verifyConfig(cfg, kcs), nil
+}
+
+// Repair rewrites the kubeconfig entries of a cluster from the settings.
+// The current context, the namespace and embedded certs of existing entries are kept.
+func Repair(kcs *Settings) error {
+	cfg, err := readOrNew(kcs.filePath())
+	if err != nil {
+		return err
+	}
+	if c, ok := cfg.Clusters[kcs.ClusterName]; ok && len(c.CertificateAuthorityData) > 0 {
+		kcs.EmbedCerts = true
+	}
+	if c, ok := cfg.Contexts[kcs.ClusterName]; ok && kcs.Namespace == "" {
+		kcs.Namespace = c.Namespace
+	}
+	kcs.KeepContext = cfg.CurrentContext != ""
+	return Update(kcs)
+}
+
+func verifyConfig(cfg *api.Config, kcs *Settings) []string {
+	name := kcs.ClusterName
+	problems := []string{}
+
+	if c, ok := cfg.Contexts[name]; !ok {
+		problems = append(problems, fmt.Sprintf("context %q is missing", name))
+	} else {
+		if c.Cluster != name {
+			problems = append(problems, fmt.Sprintf("context %q refers to cluster %q instead of %q", name, c.Cluster, name))
+		}
+		if c.AuthInfo != name {
+			problems = append(problems, fmt.Sprintf("context %q refers to user %q instead of %q", name, c.AuthInfo, name))
+		}
+	}
+
+	var ca *x509.Certificate
+	if c, ok := cfg.Clusters[name]; !ok {
+		problems = append(problems, fmt.Sprintf("cluster %q is missing", name))
+	} else {
+		if c.Server != kcs.ClusterServerAddress {
+			problems = append(problems, fmt.Sprintf("cluster %q points to %s, but the API server is at %s", name, c.Server, kcs.ClusterServerAddress))
+		}
+		var p string
+		ca, p = verifyCA(c, kcs.CertificateAuthority)
+		if p != "" {
+			problems = append(problems, fmt.Sprintf("cluster %q: %s", name, p))
+		}
+		if ca != nil {
+			if p := verifyServer(kcs.ClusterServerAddress, ca); p != "" {
+				problems = append(problems, p)
+			}
+		}
+	}
+
+	if u, ok := cfg.AuthInfos[name]; !ok {
+		problems = append(problems, fmt.Sprintf("user %q is missing", name))
+	} else if p := verifyClientCert(u, ca, time.Now()); p != "" {
+		problems = append(problems, fmt.Sprintf("user %q: %s", name, p))
+	}
+	return problems
+}
+
+// fileOrData returns the embedded data of a kubeconfig entry, or reads the file it refers to
+func fileOrData(path string, data []byte) ([]byte, error) {
+	if len(data) > 0 {
+		return data, nil
+	}
+	if path == "" {
+		return nil, fmt.Errorf("neither a file nor data is set")
+	}
+	return os.ReadFile(path)
+}
+
+// verifyCA checks that the CA of a cluster entry is the minikube CA
+func verifyCA(c *api.Cluster, caPath string) (*x509.Certificate, string) {
+	data, err := fileOrData(c.CertificateAuthority, c.CertificateAuthorityData)
+	if err != nil {
+		return nil, fmt.Sprintf("unable to read the CA: %v", err)
+	}
+	ca, err := pkgutil.ParseCertificate(data)
+	if err != nil {
+		return nil, fmt.Sprintf("invalid CA: %v", err)
+	}
+	want, err := os.ReadFile(caPath)
+	if err != nil {
+		return ca, fmt.Sprintf("unable to read the minikube CA: %v", err)
+	}
+	wantCA, err := pkgutil.ParseCertificate(want)
+	if err != nil {
+		return ca, fmt.Sprintf("invalid minikube CA %s: %v", caPath, err)
+	}
+	if !bytes.Equal(ca.Raw, wantCA.Raw) {
+		return ca, fmt.Sprintf("the CA %q is not the minikube CA %s", ca.Subject.CommonName, caPath)
+	}
+	return ca, ""
+}
+
+// verifyServer connects to the API server, and checks its certificate against the CA of the cluster entry
+func verifyServer(server string, ca *x509.Certificate) string {
+	u, err := url.Parse(server)
+	if err != nil {
+		return fmt.Sprintf("invalid API server URL %q: %v", server, err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: dialTimeout}, "tcp", u.Host, &tls.Config{RootCAs: pool, ServerName: u.Hostname(), MinVersion: tls.VersionTLS12})
+	if err == nil {
+		conn.Close()
+		return ""
+	}
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	switch {
+	case errors.As(err, &unknownAuthority):
+		return fmt.Sprintf("the API server certificate is not signed by the kubeconfig CA %q (x509: certificate signed by unknown authority)", ca.Subject.CommonName)
+	case errors.As(err, &hostname):
+		return fmt.Sprintf("the API server certificate is not valid for %s", u.Hostname())
+	default:
+		return fmt.Sprintf("unable to connect to the API server at %s: %v", u.Host, err)
+	}
+}
+
+// verifyClientCert checks that the client cert of a user entry is valid, signed by the CA and matches the client key
+func verifyClientCert(u *api.AuthInfo, ca *x509.Certificate, now time.Time) string {
+	certPEM, err := fileOrData(u.ClientCertificate, u.ClientCertificateData)
+	if err != nil {
+		return fmt.Sprintf("unable to read the client certificate: %v", err)
+	}
+	keyPEM, err := fileOrData(u.ClientKey, u.ClientKeyData)
+	if err != nil {
+		return fmt.Sprintf("unable to read the client key: %v", err)
+	}
+	cert, err := pkgutil.ParseCertificate(certPEM)
+	if err != nil {
+		return fmt.Sprintf("invalid client certificate: %v", err)
+	}
+	if now.After(cert.NotAfter) {
+		return fmt.Sprintf("the client certificate expired on %s", cert.NotAfter.Format(time.RFC3339))
+	}
+	if ca != nil {
+		if err := cert.CheckSignatureFrom(ca); err != nil {
+			return fmt.Sprintf("the client certificate is not signed by the CA %q", ca.Subject.CommonName)
+		}
+	}
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return fmt.Sprintf("the client key does not match the client certificate: %v", err)
+	}
+	return ""
+}
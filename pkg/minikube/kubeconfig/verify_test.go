@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/minikube/pkg/util"
+)
+
+// testPKI generates a CA, a client cert and a serving cert for 127.0.0.1 in dir
+func testPKI(t *testing.T, dir string) {
+	ca, caKey := filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key")
+	if err := util.GenerateCACert(ca, caKey, "minikubeCA"); err != nil {
+		t.Fatalf("generate CA: %v", err)
+	}
+	if err := util.GenerateSignedCert(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"), "minikube-user", nil, nil, nil, ca, caKey, time.Hour); err != nil {
+		t.Fatalf("generate client cert: %v", err)
+	}
+	if err := util.GenerateSignedCert(filepath.Join(dir, "apiserver.crt"), filepath.Join(dir, "apiserver.key"), "minikube", []net.IP{net.ParseIP("127.0.0.1")}, nil, nil, ca, caKey, time.Hour); err != nil {
+		t.Fatalf("generate serving cert: %v", err)
+	}
+}
+
+// testAPIServer serves TLS with the serving cert of dir, and returns its URL
+func testAPIServer(t *testing.T, dir string) string {
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, "apiserver.crt"), filepath.Join(dir, "apiserver.key"))
+	if err != nil {
+		t.Fatalf("load serving cert: %v", err)
+	}
+	s := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	s.TLS = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	s.StartTLS()
+	t.Cleanup(s.Close)
+	return s.URL
+}
+
+func TestVerifyConfig(t *testing.T) {
+	dir := t.TempDir()
+	testPKI(t, dir)
+	server := testAPIServer(t, dir)
+	other := t.TempDir()
+	testPKI(t, other)
+
+	kcs := &Settings{
+		ClusterName:          "minikube",
+		ClusterServerAddress: server,
+		ClientCertificate:    filepath.Join(dir, "client.crt"),
+		ClientKey:            filepath.Join(dir, "client.key"),
+		CertificateAuthority: filepath.Join(dir, "ca.crt"),
+	}
+
+	tests := []struct {
+		description string
+		modify      func(cfg *api.Config)
+		want        []string
+	}{
+		{
+			description: "valid",
+			modify:      func(cfg *api.Config) {},
+		},
+		{
+			description: "missing context",
+			modify:      func(cfg *api.Config) { delete(cfg.Contexts, "minikube") },
+			want:        []string{`context "minikube" is missing`},
+		},
+		{
+			description: "stale endpoint",
+			modify:      func(cfg *api.Config) { cfg.Clusters["minikube"].Server = "https://192.168.49.2:8443" },
+			want:        []string{"points to https://192.168.49.2:8443"},
+		},
+		{
+			description: "CA of another cluster",
+			modify: func(cfg *api.Config) {
+				cfg.Clusters["minikube"].CertificateAuthority = filepath.Join(other, "ca.crt")
+			},
+			want: []string{"is not the minikube CA", "unknown authority", "client certificate is not signed"},
+		},
+		{
+			description: "client cert of another cluster",
+			modify: func(cfg *api.Config) {
+				cfg.AuthInfos["minikube"].ClientCertificate = filepath.Join(other, "client.crt")
+				cfg.AuthInfos["minikube"].ClientKey = filepath.Join(other, "client.key")
+			},
+			want: []string{"client certificate is not signed"},
+		},
+		{
+			description: "mismatched client key",
+			modify: func(cfg *api.Config) {
+				cfg.AuthInfos["minikube"].ClientKey = filepath.Join(dir, "apiserver.key")
+			},
+			want: []string{"client key does not match"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			cfg := api.NewConfig()
+			if err := PopulateFromSettings(kcs, cfg); err != nil {
+				t.Fatalf("populate: %v", err)
+			}
+			tc.modify(cfg)
+			got := verifyConfig(cfg, kcs)
+			if len(got) != len(tc.want) {
+				t.Fatalf("verifyConfig() = %q, want %d problems", got, len(tc.want))
+			}
+			for i, w := range tc.want {
+				if !strings.Contains(got[i], w) {
+					t.Errorf("problem %d = %q, want it to contain %q", i, got[i], w)
+				}
+			}
+		})
+	}
+}
+
+func TestRepair(t *testing.T) {
+	dir := t.TempDir()
+	testPKI(t, dir)
+	server := testAPIServer(t, dir)
+
+	kcs := &Settings{
+		ClusterName:          "minikube",
+		ClusterServerAddress: server,
+		ClientCertificate:    filepath.Join(dir, "client.crt"),
+		ClientKey:            filepath.Join(dir, "client.key"),
+		CertificateAuthority: filepath.Join(dir, "ca.crt"),
+	}
+	path := filepath.Join(dir, "config")
+	kcs.SetPath(path)
+
+	cfg := api.NewConfig()
+	if err := PopulateFromSettings(kcs, cfg); err != nil {
+		t.Fatalf("populate: %v", err)
+	}
+	cfg.Clusters["minikube"].Server = "https://192.168.49.2:8443"
+	cfg.Contexts["minikube"].Namespace = "dev"
+	cfg.CurrentContext = "other"
+	if err := writeToFile(cfg, path); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := Repair(kcs); err != nil {
+		t.Fatalf("Repair: %v", err)
+	}
+	problems, err := Verify(kcs)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if len(problems) > 0 {
+		t.Errorf("problems after repair: %q", problems)
+	}
+
+	repaired, err := readOrNew(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if repaired.CurrentContext != "other" {
+		t.Errorf("current context = %q, want it to be kept", repaired.CurrentContext)
+	}
+	if ns := repaired.Contexts["minikube"].Namespace; ns != "dev" {
+		t.Errorf("namespace = %q, want it to be kept", ns)
+	}
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lease records who holds a profile, so that the automation jobs sharing a machine do not change the same cluster at once
+package lease
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/mutex/v2"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/util/lock"
+)
+
+// What the commands changing a cluster do when another holder has its lease
+const (
+	// ModeBlock fails the commands
+	ModeBlock = "block"
+	// ModeWarn warns, and runs the commands
+	ModeWarn = "warn"
+)
+
+// Modes are the values of --mode
+var Modes = []string{ModeBlock, ModeWarn}
+
+// fileName is the name of the lease in the profile directory
+const fileName = "lease.json"
+
+// Lease is the lease of a profile
+type Lease struct {
+	// ID is given to the holder, which passes it to the commands as $MINIKUBE_LEASE
+	ID string
+	// Holder describes the holder, such as the user and host of the job
+	Holder   string
+	Mode     string
+	Acquired time.Time
+	Expires  time.Time
+}
+
+// Active returns whether the lease has not expired at now
+func (l *Lease) Active(now time.Time) bool {
+	return l != nil && now.Before(l.Expires)
+}
+
+// HeldError is returned when another holder has the active lease of the profile
+type HeldError struct {
+	Lease *Lease
+}
+
+func (e *HeldError) Error() string {
+	return fmt.Sprintf("held by %s until %s", e.Lease.Holder, e.Lease.Expires.Format(time.RFC3339))
+}
+
+// Path returns the path of the lease of the profile
+func Path(profile string) string {
+	return filepath.Join(localpath.Profile(profile), fileName)
+}
+
+// Load returns the lease of the profile, nil if it has none. An expired lease is returned too, see Active.
+func Load(profile string) (*Lease, error) {
+	b, err := os.ReadFile(Path(profile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the lease")
+	}
+	var l Lease
+	if err := json.Unmarshal(b, &l); err != nil {
+		return nil, errors.Wrap(err, "parsing the lease")
+	}
+	return &l, nil
+}
+
+// Acquire gives the lease of the profile to holder for ttl, and returns it. An expired lease is taken over,
+// the lease of id is renewed, and an active lease of another holder is a *HeldError.
+func Acquire(profile, id, holder, mode string, ttl time.Duration, now time.Time) (*Lease, error) {
+	release, err := guard(profile)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	cur, err := Load(profile)
+	if err != nil {
+		return nil, err
+	}
+	if cur.Active(now) && (id == "" || cur.ID != id) {
+		return nil, &HeldError{Lease: cur}
+	}
+	l := &Lease{ID: id, Holder: holder, Mode: mode, Acquired: now, Expires: now.Add(ttl)}
+	if l.ID == "" {
+		if l.ID, err = newID(); err != nil {
+			return nil, err
+		}
+	}
+	b, err := json.MarshalIndent(l, "", "    ")
+	if err != nil {
+		return nil, errors.Wrap(err, "encoding the lease")
+	}
+	if err := os.MkdirAll(localpath.Profile(profile), 0o755); err != nil {
+		return nil, errors.Wrap(err, "creating the profile directory")
+	}
+	if err := lock.WriteFile(Path(profile), b, 0o644); err != nil {
+		return nil, errors.Wrap(err, "writing the lease")
+	}
+	return l, nil
+}
+
+// Release removes the lease of the profile. Unless force, an active lease must be the one of id, or it is a *HeldError.
+func Release(profile, id string, force bool, now time.Time) error {
+	release, err := guard(profile)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	cur, err := Load(profile)
+	if err != nil || cur == nil {
+		return err
+	}
+	if !force && cur.Active(now) && cur.ID != id {
+		return &HeldError{Lease: cur}
+	}
+	if err := os.Remove(Path(profile)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "removing the lease")
+	}
+	return nil
+}
+
+// Check returns the active lease of the profile held by another holder than id, nil if there is none
+func Check(profile, id string, now time.Time) (*Lease, error) {
+	cur, err := Load(profile)
+	if err != nil {
+		return nil, err
+	}
+	if !cur.Active(now) || cur.ID == id {
+		return nil, nil
+	}
+	return cur, nil
+}
+
+// guard serializes the changes of the lease of the profile between the minikube processes
+func guard(profile string) (func(), error) {
+	spec := lock.PathMutexSpec(Path(profile) + ".lock")
+	klog.Infof("acquiring the lease lock of %s: %+v", profile, spec)
+	r, err := mutex.Acquire(spec)
+	if err != nil {
+		return nil, errors.Wrapf(err, "acquiring the lease lock of %s", profile)
+	}
+	return r.Release, nil
+}
+
+// newID returns a random lease ID
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "generating the lease ID")
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lease
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+func TestLease(t *testing.T) {
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	a, err := Acquire("p1", "", "ci@runner-1", ModeBlock, 30*time.Minute, now)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if a.ID == "" || !a.Expires.Equal(now.Add(30*time.Minute)) {
+		t.Fatalf("Acquire() = %+v, want an ID expiring in 30m", a)
+	}
+
+	// another holder is refused until the lease expires
+	var held *HeldError
+	if _, err := Acquire("p1", "", "ci@runner-2", ModeBlock, time.Hour, now.Add(time.Minute)); !errors.As(err, &held) || held.Lease.Holder != "ci@runner-1" {
+		t.Errorf("Acquire() by another holder = %v, want a HeldError of ci@runner-1", err)
+	}
+	if l, err := Check("p1", "", now.Add(time.Minute)); err != nil || l == nil || l.ID != a.ID {
+		t.Errorf("Check() by another holder = %+v, %v, want the lease", l, err)
+	}
+	if l, err := Check("p1", a.ID, now.Add(time.Minute)); err != nil || l != nil {
+		t.Errorf("Check() by the holder = %+v, %v, want none", l, err)
+	}
+
+	// the holder renews its lease
+	r, err := Acquire("p1", a.ID, "ci@runner-1", ModeBlock, time.Hour, now.Add(time.Minute))
+	if err != nil || r.ID != a.ID || !r.Expires.Equal(now.Add(61*time.Minute)) {
+		t.Errorf("Acquire() renewal = %+v, %v, want the lease renewed for an hour", r, err)
+	}
+	if err := Release("p1", "", false, now.Add(2*time.Minute)); !errors.As(err, &held) {
+		t.Errorf("Release() by another holder = %v, want a HeldError", err)
+	}
+
+	// an expired lease is taken over
+	later := now.Add(2 * time.Hour)
+	if l, err := Check("p1", "", later); err != nil || l != nil {
+		t.Errorf("Check() of an expired lease = %+v, %v, want none", l, err)
+	}
+	b, err := Acquire("p1", "", "ci@runner-2", ModeWarn, time.Hour, later)
+	if err != nil || b.ID == a.ID {
+		t.Fatalf("Acquire() over an expired lease = %+v, %v, want a new lease", b, err)
+	}
+
+	if err := Release("p1", b.ID, false, later); err != nil {
+		t.Errorf("Release() by the holder: %v", err)
+	}
+	if l, err := Load("p1"); err != nil || l != nil {
+		t.Errorf("Load() after Release = %+v, %v, want none", l, err)
+	}
+
+	if _, err := Acquire("p1", "", "ci@runner-1", ModeBlock, time.Hour, later); err != nil {
+		t.Fatal(err)
+	}
+	if err := Release("p1", "", true, later); err != nil {
+		t.Errorf("Release() forced: %v", err)
+	}
+	if l, err := Load("p1"); err != nil || l != nil {
+		t.Errorf("Load() after a forced Release = %+v, %v, want none", l, err)
+	}
+}
@@ -41,6 +41,9 @@ func ConfigFile() string {
 // MiniPath returns the path to the user's minikube dir
 func MiniPath() string {
 	minikubeHomeEnv := os.Getenv(MinikubeHome)
+	if minikubeHomeEnv == "" && SystemWide() {
+		return filepath.Join(UserDir(), ".minikube")
+	}
 	if minikubeHomeEnv == "" {
 		return filepath.Join(homedir.HomeDir(), ".minikube")
 	}
@@ -68,6 +71,12 @@ func EventLog(name string) string {
 	return filepath.Join(Profile(name), "events.json")
 }
 
+// CertHistoryLog returns the path to the cert history log of a profile.
+// This log contains every cert generation, renewal, deletion and symlink installation, and why it happened.
+func CertHistoryLog(name string) string {
+	return filepath.Join(Profile(name), "cert-history.json")
+}
+
 // AuditLog returns the path to the audit log.
 // This log contains a history of commands run, by who, when, and what arguments.
 func AuditLog() string {
@@ -129,6 +138,16 @@ func CACert() string {
 	return filepath.Join(MiniPath(), "ca.crt")
 }
 
+// RegistryCert returns the path of the serving cert of the registry addon of a profile
+func RegistryCert(name string) string {
+	return filepath.Join(Profile(name), "registry.crt")
+}
+
+// RegistryKey returns the path of the key of the serving cert of the registry addon of a profile
+func RegistryKey(name string) string {
+	return filepath.Join(Profile(name), "registry.key")
+}
+
 // MachinePath returns the minikube machine path of a machine
 func MachinePath(machine string, miniHome ...string) string {
 	miniPath := MiniPath()
@@ -0,0 +1,174 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package localpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// SystemHomeEnv is the name of the environment variable overriding the home of a system-wide install
+const SystemHomeEnv = "MINIKUBE_SYSTEM_HOME"
+
+// SystemConfigFile is the file marking a system-wide install, written by 'minikube system-install'
+const SystemConfigFile = "/etc/minikube/system.json"
+
+// SystemConfig is the content of SystemConfigFile
+type SystemConfig struct {
+	// Home is the root-owned directory holding the shared cache and the per-user directories
+	Home string `json:"home"`
+	// Group is the group allowed to use the system-wide install
+	Group string `json:"group"`
+}
+
+var (
+	systemConfig     SystemConfig
+	systemConfigOnce sync.Once
+)
+
+// SystemHome returns the home of the system-wide install, or an empty string if minikube is installed per user
+func SystemHome() string {
+	if h := os.Getenv(SystemHomeEnv); h != "" {
+		return h
+	}
+	systemConfigOnce.Do(func() {
+		b, err := os.ReadFile(SystemConfigFile)
+		if err != nil {
+			return
+		}
+		if err := json.Unmarshal(b, &systemConfig); err != nil {
+			klog.Warningf("ignoring invalid %s: %v", SystemConfigFile, err)
+		}
+	})
+	return systemConfig.Home
+}
+
+// SystemWide returns whether minikube is installed system-wide, to be shared by the users of the host
+func SystemWide() bool {
+	return SystemHome() != ""
+}
+
+// SystemUser returns the name of the user running minikube
+func SystemUser() string {
+	u, err := user.Current()
+	if err != nil {
+		klog.Warningf("unable to get the current user: %v", err)
+		return fmt.Sprintf("uid-%d", os.Getuid())
+	}
+	return u.Username
+}
+
+// UserDir returns the directory of the current user in a system-wide install
+func UserDir() string {
+	return filepath.Join(SystemHome(), "users", SystemUser())
+}
+
+// EnsureUserDir creates the directory of the current user in a system-wide install,
+// and checks that no other user owns it.
+func EnsureUserDir() error {
+	dir := UserDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return errors.Wrapf(err, "create %s", dir)
+	}
+	// chmod fails with EPERM if the directory is owned by another user
+	if err := os.Chmod(dir, 0700); err != nil {
+		return errors.Wrapf(err, "%s is not owned by %s", dir, SystemUser())
+	}
+	return nil
+}
+
+// geteuid returns the effective user id, root owning the shared cache of a system-wide install
+var geteuid = os.Geteuid
+
+// MakeCachePath returns a path in the artifact cache. In a system-wide install the cache shared by all users is only written by root:
+// the other users read the artifacts it has, and download the others into their own cache.
+func MakeCachePath(fileName ...string) string {
+	if SystemWide() {
+		shared := filepath.Join(append([]string{SystemHome(), "cache"}, fileName...)...)
+		if geteuid() == 0 {
+			return shared
+		}
+		if _, err := os.Stat(shared); err == nil {
+			return shared
+		}
+	}
+	return MakeWritableCachePath(fileName...)
+}
+
+// MakeWritableCachePath returns a path in the artifact cache the current user writes to,
+// which is their own one in a system-wide install, unless they are root
+func MakeWritableCachePath(fileName ...string) string {
+	args := []string{MakeMiniPath("cache")}
+	if SystemWide() && geteuid() == 0 {
+		args = []string{SystemHome(), "cache"}
+	}
+	args = append(args, fileName...)
+	return filepath.Join(args...)
+}
+
+// SetupSystemHome creates the layout of a system-wide install in cfg.Home, owned by root and the group gid:
+// the artifact cache, which only root writes and the group reads, the directory of the users where each user can only create its own,
+// and the directory of the audit logs, where each user can only create and write its own.
+func SetupSystemHome(cfg SystemConfig, gid int) error {
+	dirs := []struct {
+		path string
+		mode os.FileMode
+		gid  int
+	}{
+		{cfg.Home, 0755, os.Getgid()},
+		{filepath.Join(cfg.Home, "cache"), 0750, gid},
+		{filepath.Join(cfg.Home, "users"), 0730 | os.ModeSticky, gid},
+		{filepath.Join(cfg.Home, "logs"), 0770 | os.ModeSticky, gid},
+	}
+	for _, d := range dirs {
+		if err := os.MkdirAll(d.path, 0755); err != nil {
+			return errors.Wrapf(err, "create %s", d.path)
+		}
+		if err := os.Chown(d.path, os.Getuid(), d.gid); err != nil {
+			return errors.Wrapf(err, "chown %s", d.path)
+		}
+		if err := os.Chmod(d.path, d.mode); err != nil {
+			return errors.Wrapf(err, "chmod %s", d.path)
+		}
+	}
+	return nil
+}
+
+// WriteSystemConfig writes the file marking a system-wide install
+func WriteSystemConfig(path string, cfg SystemConfig) error {
+	b, err := json.MarshalIndent(cfg, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrapf(err, "create %s", filepath.Dir(path))
+	}
+	return os.WriteFile(path, b, 0644)
+}
+
+// SystemAuditLog returns the path to the audit log of the current user in a system-wide install,
+// which the other users can read but not write
+func SystemAuditLog() string {
+	return filepath.Join(SystemHome(), "logs", "audit-"+SystemUser()+".json")
+}
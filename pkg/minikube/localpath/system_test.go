@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package localpath
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestSystemWidePaths(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv(SystemHomeEnv, home)
+	t.Setenv(MinikubeHome, "")
+
+	if !SystemWide() {
+		t.Fatalf("SystemWide() = false with %s set", SystemHomeEnv)
+	}
+	want := filepath.Join(home, "users", SystemUser(), ".minikube")
+	if got := MiniPath(); got != want {
+		t.Errorf("MiniPath() = %q, want %q", got, want)
+	}
+	shared := filepath.Join(home, "cache", "iso")
+	own := filepath.Join(want, "cache", "iso")
+	tests := []struct {
+		name   string
+		euid   int
+		exists bool
+		want   string
+	}{
+		{"root writes the shared cache", 0, false, shared},
+		{"user reads a shared artifact", 1000, true, shared},
+		{"user downloads a missing artifact", 1000, false, own},
+	}
+	defer func(f func() int) { geteuid = f }(geteuid)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			geteuid = func() int { return tc.euid }
+			os.RemoveAll(shared)
+			if tc.exists {
+				if err := os.MkdirAll(shared, 0755); err != nil {
+					t.Fatalf("create: %v", err)
+				}
+			}
+			if got := MakeCachePath("iso"); got != tc.want {
+				t.Errorf("MakeCachePath() = %q, want %q", got, tc.want)
+			}
+			wantWritable := own
+			if tc.euid == 0 {
+				wantWritable = shared
+			}
+			if got := MakeWritableCachePath("iso"); got != wantWritable {
+				t.Errorf("MakeWritableCachePath() = %q, want %q", got, wantWritable)
+			}
+		})
+	}
+
+	// an explicit MINIKUBE_HOME still reads the shared cache
+	if err := os.MkdirAll(shared, 0755); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	t.Setenv(MinikubeHome, "/tmp/.minikube")
+	if got := MiniPath(); got != "/tmp/.minikube" {
+		t.Errorf("MiniPath() = %q with %s set", got, MinikubeHome)
+	}
+	if got := MakeCachePath("iso"); got != shared {
+		t.Errorf("MakeCachePath() = %q with %s set", got, MinikubeHome)
+	}
+}
+
+func TestSetupSystemHome(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("system-wide installs are not supported on Windows")
+	}
+	home := filepath.Join(t.TempDir(), "minikube")
+	if err := SetupSystemHome(SystemConfig{Home: home}, os.Getgid()); err != nil {
+		t.Fatalf("SetupSystemHome: %v", err)
+	}
+	modes := map[string]os.FileMode{
+		home:                         os.ModeDir | 0755,
+		filepath.Join(home, "cache"): os.ModeDir | 0750,
+		filepath.Join(home, "users"): os.ModeDir | os.ModeSticky | 0730,
+		filepath.Join(home, "logs"):  os.ModeDir | os.ModeSticky | 0770,
+	}
+	for path, want := range modes {
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatalf("stat: %v", err)
+		}
+		if fi.Mode() != want {
+			t.Errorf("mode of %s = %v, want %v", path, fi.Mode(), want)
+		}
+	}
+
+	t.Setenv(SystemHomeEnv, home)
+	if err := EnsureUserDir(); err != nil {
+		t.Fatalf("EnsureUserDir: %v", err)
+	}
+	fi, err := os.Stat(UserDir())
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if fi.Mode().Perm() != 0700 {
+		t.Errorf("mode of %s = %v, want 0700", UserDir(), fi.Mode())
+	}
+}
+
+func TestWriteSystemConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "etc", "system.json")
+	if err := WriteSystemConfig(path, SystemConfig{Home: "/var/lib/minikube", Group: "minikube"}); err != nil {
+		t.Fatalf("WriteSystemConfig: %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := "{\n    \"home\": \"/var/lib/minikube\",\n    \"group\": \"minikube\"\n}"
+	if string(b) != want {
+		t.Errorf("system config = %q, want %q", b, want)
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package machine
 
 import (
+	"fmt"
 	"net/url"
 	"os"
 	"os/exec"
@@ -29,6 +30,7 @@ import (
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/buildkit"
 	"k8s.io/minikube/pkg/minikube/command"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/cruntime"
@@ -39,8 +41,111 @@ import (
 // buildRoot is where images should be built from within the guest VM
 var buildRoot = path.Join(vmpath.GuestPersistentDir, "build")
 
+// BuildExtras are the secrets, SSH keys and platforms of a build, whose files are copied from the host into the nodes
+type BuildExtras struct {
+	// Secrets are given as id=ID,src=PATH
+	Secrets []string
+	// SSH are the keys given as ID=PATH
+	SSH []string
+	// Platforms are the platforms the image is built for, several of them only with containerd
+	Platforms []string
+}
+
+// buildFile is a file of the host given to a build
+type buildFile struct {
+	id  string
+	src string
+}
+
+// parse returns the files of the secrets and SSH keys
+func (e BuildExtras) parse() ([]buildFile, []buildFile, error) {
+	var secrets, keys []buildFile
+	for _, s := range e.Secrets {
+		f := buildFile{}
+		for _, kv := range strings.Split(s, ",") {
+			k, v, _ := strings.Cut(kv, "=")
+			switch k {
+			case "id":
+				f.id = v
+			case "src", "source":
+				f.src = v
+			case "type":
+				if v != "file" {
+					return nil, nil, fmt.Errorf("secret %q: only the secrets of type file are supported", s)
+				}
+			default:
+				return nil, nil, fmt.Errorf("secret %q: unknown key %q", s, k)
+			}
+		}
+		if f.id == "" || f.src == "" {
+			return nil, nil, fmt.Errorf("secret %q: give it as id=ID,src=PATH", s)
+		}
+		secrets = append(secrets, f)
+	}
+	for _, s := range e.SSH {
+		id, src, ok := strings.Cut(s, "=")
+		if !ok || id == "" || src == "" || strings.Contains(src, ",") {
+			return nil, nil, fmt.Errorf("ssh %q: the SSH agent of the host can't be forwarded into the node, give a single key file as ID=PATH, such as default=$HOME/.ssh/id_ed25519", s)
+		}
+		keys = append(keys, buildFile{id: id, src: src})
+	}
+	return secrets, keys, nil
+}
+
+// Validate checks the extras of a build with the container runtime rt, and that their files exist
+func (e BuildExtras) Validate(rt string) error {
+	secrets, keys, err := e.parse()
+	if err != nil {
+		return err
+	}
+	for _, f := range append(secrets, keys...) {
+		if _, err := os.Stat(f.src); err != nil {
+			return err
+		}
+	}
+	if len(e.Platforms) > 1 && !buildkit.SupportsRuntime(rt) {
+		return fmt.Errorf("building for several platforms needs the containerd container runtime, %s builds for one", rt)
+	}
+	return nil
+}
+
+// stage copies the files of the extras into dir on the node, returning the options of the build using them
+func (e BuildExtras) stage(cr command.Runner, rt string, dir string) ([]string, error) {
+	secrets, keys, err := e.parse()
+	if err != nil {
+		return nil, err
+	}
+	var opts []string
+	for i, f := range append(secrets, keys...) {
+		name := fmt.Sprintf("%d-%s", i, localpath.SanitizeCacheDir(f.id))
+		a, err := assets.NewFileAsset(f.src, dir, name, "0600")
+		if err != nil {
+			return nil, errors.Wrapf(err, "creating copyable file asset: %s", f.src)
+		}
+		err = cr.Copy(a)
+		a.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "transferring %s", f.src)
+		}
+		if i < len(secrets) {
+			opts = append(opts, fmt.Sprintf("secret=id=%s,src=%s", f.id, path.Join(dir, name)))
+		} else {
+			opts = append(opts, fmt.Sprintf("ssh=%s=%s", f.id, path.Join(dir, name)))
+		}
+	}
+	if len(e.Platforms) > 0 {
+		p := strings.Join(e.Platforms, ",")
+		if buildkit.SupportsRuntime(rt) {
+			opts = append(opts, "opt=platform="+p)
+		} else {
+			opts = append(opts, "platform="+p)
+		}
+	}
+	return opts, nil
+}
+
 // BuildImage builds image to all profiles
-func BuildImage(path string, file string, tag string, push bool, env []string, opt []string, profiles []*config.Profile, allNodes bool, nodeName string) error {
+func BuildImage(path string, file string, tag string, push bool, env []string, opt []string, extras BuildExtras, profiles []*config.Profile, allNodes bool, nodeName string) error {
 	api, err := NewAPIClient()
 	if err != nil {
 		return errors.Wrap(err, "api")
@@ -80,7 +185,7 @@ func BuildImage(path string, file string, tag string, push bool, env []string, o
 
 			if !allNodes {
 				// build images on the primary control plane node by default
-				if nodeName == "" && n != cp {
+				if nodeName == "" && n.Name != cp.Name {
 					continue
 				} else if nodeName != n.Name && nodeName != m {
 					continue
@@ -105,11 +210,19 @@ func BuildImage(path string, file string, tag string, push bool, env []string, o
 				if err != nil {
 					return err
 				}
-				if remote {
-					err = buildImage(cr, c.KubernetesConfig, path, file, tag, push, env, opt)
-				} else {
-					err = transferAndBuildImage(cr, c.KubernetesConfig, path, file, tag, push, env, opt)
+				if buildkit.SupportsRuntime(c.KubernetesConfig.ContainerRuntime) {
+					if err := buildkit.Ensure(cr, buildkit.ListensTCP(c.Driver)); err != nil {
+						failed = append(failed, m)
+						klog.Warningf("Failed to start the buildkitd of %s: %v", m, err)
+						continue
+					}
 				}
+				err = buildWithExtras(cr, c.KubernetesConfig, extras, opt, func(opts []string) error {
+					if remote {
+						return buildImage(cr, c.KubernetesConfig, path, file, tag, push, env, opts)
+					}
+					return transferAndBuildImage(cr, c.KubernetesConfig, path, file, tag, push, env, opts)
+				})
 				if err != nil {
 					failed = append(failed, m)
 					klog.Warningf("Failed to build image for profile %s. make sure the profile is running. %v", pName, err)
@@ -125,6 +238,27 @@ func BuildImage(path string, file string, tag string, push bool, env []string, o
 	return nil
 }
 
+// buildWithExtras stages the files of the extras into the node for the duration of build, which is given the options using them after opt
+func buildWithExtras(cr command.Runner, k8s config.KubernetesConfig, extras BuildExtras, opt []string, build func([]string) error) error {
+	if len(extras.Secrets) == 0 && len(extras.SSH) == 0 && len(extras.Platforms) == 0 {
+		return build(opt)
+	}
+	dir := path.Join(buildRoot, "extras")
+	if _, err := cr.RunCmd(exec.Command("sudo", "install", "-d", "-m", "0700", dir)); err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := cr.RunCmd(exec.Command("sudo", "rm", "-rf", dir)); err != nil {
+			klog.Warningf("failed to remove the secrets of the build: %v", err)
+		}
+	}()
+	opts, err := extras.stage(cr, k8s.ContainerRuntime, dir)
+	if err != nil {
+		return errors.Wrap(err, "staging the secrets of the build")
+	}
+	return build(append(append([]string{}, opt...), opts...))
+}
+
 // buildImage builds a single image
 func buildImage(cr command.Runner, k8s config.KubernetesConfig, src string, file string, tag string, push bool, env []string, opt []string) error {
 	r, err := cruntime.New(cruntime.Config{Type: k8s.ContainerRuntime, Runner: cr})
@@ -0,0 +1,55 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+func TestBuildExtrasValidate(t *testing.T) {
+	secret := filepath.Join(t.TempDir(), "npmrc")
+	if err := os.WriteFile(secret, []byte("token"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		extras BuildExtras
+		rt     string
+		valid  bool
+	}{
+		{"secret", BuildExtras{Secrets: []string{"id=npmrc,src=" + secret}}, constants.Docker, true},
+		{"typed secret", BuildExtras{Secrets: []string{"type=file,id=npmrc,source=" + secret}}, constants.CRIO, true},
+		{"ssh key", BuildExtras{SSH: []string{"default=" + secret}}, constants.Containerd, true},
+		{"platforms", BuildExtras{Platforms: []string{"linux/amd64", "linux/arm64"}}, constants.Containerd, true},
+		{"secret without id", BuildExtras{Secrets: []string{"src=" + secret}}, constants.Docker, false},
+		{"env secret", BuildExtras{Secrets: []string{"id=token,env=TOKEN"}}, constants.Docker, false},
+		{"missing secret", BuildExtras{Secrets: []string{"id=npmrc,src=" + secret + ".missing"}}, constants.Docker, false},
+		{"ssh agent", BuildExtras{SSH: []string{"default"}}, constants.Containerd, false},
+		{"platforms of docker", BuildExtras{Platforms: []string{"linux/amd64", "linux/arm64"}}, constants.Docker, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.extras.Validate(tc.rt); (err == nil) != tc.valid {
+				t.Errorf("Validate(%s) = %v, want valid = %t", tc.rt, err, tc.valid)
+			}
+		})
+	}
+}
@@ -531,7 +531,7 @@ func pullImages(cruntime cruntime.Manager, images []string) error {
 	return nil
 }
 
-// PullImages pulls images to all nodes in profile
+// PullImages pulls images to all nodes in profile, in parallel
 func PullImages(images []string, profile *config.Profile) error {
 	api, err := NewAPIClient()
 	if err != nil {
@@ -550,6 +550,10 @@ func PullImages(images []string, profile *config.Profile) error {
 		return errors.Wrapf(err, "error loading config for profile :%v", pName)
 	}
 
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	// the pulls use the api client, do not close it under them when returning early
+	defer wg.Wait()
 	for _, n := range c.Nodes {
 		m := config.MachineName(*c, n)
 
@@ -573,15 +577,22 @@ func PullImages(images []string, profile *config.Profile) error {
 			if err != nil {
 				return errors.Wrap(err, "error creating container runtime")
 			}
-			err = pullImages(cruntime, images)
-			if err != nil {
-				failed = append(failed, m)
-				klog.Warningf("Failed to pull images for profile %s %v", pName, err.Error())
-				continue
-			}
-			succeeded = append(succeeded, m)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := pullImages(cruntime, images)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					failed = append(failed, m)
+					klog.Warningf("Failed to pull images for profile %s %v", pName, err.Error())
+					return
+				}
+				succeeded = append(succeeded, m)
+			}()
 		}
 	}
+	wg.Wait()
 
 	klog.Infof("succeeded pulling to: %s", strings.Join(succeeded, " "))
 	klog.Infof("failed pulling to: %s", strings.Join(failed, " "))
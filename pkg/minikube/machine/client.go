@@ -151,6 +151,11 @@ func (api *LocalClient) Close() error {
 	return nil
 }
 
+// vsockDialer is implemented by the drivers reaching the command agent of the VM over vsock
+type vsockDialer interface {
+	DialVsock() (net.Conn, error)
+}
+
 // CommandRunner returns best available command runner for this host
 func CommandRunner(h *host.Host) (command.Runner, error) {
 	if h.DriverName == driver.Mock {
@@ -159,6 +164,9 @@ func CommandRunner(h *host.Host) (command.Runner, error) {
 	if driver.BareMetal(h.Driver.DriverName()) {
 		return command.NewExecRunner(true), nil
 	}
+	if d, ok := h.Driver.(vsockDialer); ok {
+		return command.NewVsockRunner(d.DialVsock), nil
+	}
 
 	return command.NewSSHRunner(h.Driver), nil
 }
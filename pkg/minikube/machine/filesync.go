@@ -19,14 +19,12 @@ package machine
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
 
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/minikube/assets"
-	"k8s.io/minikube/pkg/minikube/command"
 	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/vmpath"
 )
@@ -40,54 +38,6 @@ var guaranteed = map[string]bool{
 	"/tmp": true,
 }
 
-// syncLocalAssets syncs files from MINIKUBE_HOME into the cluster
-func syncLocalAssets(cr command.Runner) error {
-	fs, err := localAssets()
-	defer func() {
-		for _, f := range fs {
-			if err := f.Close(); err != nil {
-				klog.Warningf("error closing the file %s: %v", f.GetSourcePath(), err)
-			}
-		}
-	}()
-
-	if err != nil {
-		return err
-	}
-
-	if len(fs) == 0 {
-		return nil
-	}
-
-	// Deduplicate the list of directories to create
-	seen := map[string]bool{}
-	create := []string{}
-	for _, f := range fs {
-		dir := f.GetTargetDir()
-		if guaranteed[dir] || seen[dir] {
-			continue
-		}
-		create = append(create, dir)
-	}
-
-	// Create directories that are not guaranteed to exist
-	if len(create) > 0 {
-		args := append([]string{"mkdir", "-p"}, create...)
-		if _, err := cr.RunCmd(exec.Command("sudo", args...)); err != nil {
-			return err
-		}
-	}
-
-	// Copy the files into place
-	for _, f := range fs {
-		err := cr.Copy(f)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
-}
-
 // localAssets returns local files and addons from the minikube home directory
 func localAssets() ([]assets.CopyableFile, error) {
 	fs, err := assetsFromDir(localpath.MakeMiniPath("addons"), vmpath.GuestAddonsDir, true)
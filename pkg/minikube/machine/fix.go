@@ -60,18 +60,26 @@ func fixHost(api libmachine.API, cc *config.ClusterConfig, n *config.Node) (*hos
 	if err != nil {
 		return h, errors.Wrap(err, "error loading existing host. Please try running [minikube delete], then run [minikube start] again")
 	}
-	defer postStartValidations(h, cc.Driver)
+	defer postStartValidations(h, config.NodeDriver(*cc, *n))
 
 	driverName := h.Driver.DriverName()
 
 	// check if need to re-run docker-env
 	maybeWarnAboutEvalEnv(driverName, cc.Name)
 
+	// the state of a host restarted here may have been left inconsistent by an unclean shutdown
+	s, err := h.Driver.GetState()
+	restarted := err != nil || s != state.Running
+
 	h, err = recreateIfNeeded(api, cc, n, h)
 	if err != nil {
 		return h, err
 	}
 
+	if _, err := ensureStaticIP(h, *cc, *n); err != nil {
+		return h, errors.Wrap(err, "static IP")
+	}
+
 	// Avoid reprovisioning "none" driver because provision.Detect requires SSH
 	if !driver.BareMetal(driverName) {
 		e := engineOptions(*cc)
@@ -90,12 +98,19 @@ func fixHost(api libmachine.API, cc *config.ClusterConfig, n *config.Node) (*hos
 		return h, errors.Wrap(err, "post-start")
 	}
 
+	if restarted {
+		if err := recoverState(h, *cc); err != nil {
+			return h, errors.Wrap(err, "recover state")
+		}
+	}
+
 	return h, nil
 }
 
 func recreateIfNeeded(api libmachine.API, cc *config.ClusterConfig, n *config.Node, h *host.Host) (*host.Host, error) {
 	machineName := config.MachineName(*cc, *n)
-	machineType := driver.MachineType(cc.Driver)
+	drv := config.NodeDriver(*cc, *n)
+	machineType := driver.MachineType(drv)
 	recreated := false
 	s, serr := h.Driver.GetState()
 
@@ -108,7 +123,7 @@ func recreateIfNeeded(api libmachine.API, cc *config.ClusterConfig, n *config.No
 		}
 
 		if !me || err == constants.ErrMachineMissing {
-			out.Step(style.Shrug, `{{.driver_name}} "{{.cluster}}" {{.machine_type}} is missing, will recreate.`, out.V{"driver_name": cc.Driver, "cluster": machineName, "machine_type": machineType})
+			out.Step(style.Shrug, `{{.driver_name}} "{{.cluster}}" {{.machine_type}} is missing, will recreate.`, out.V{"driver_name": drv, "cluster": machineName, "machine_type": machineType})
 			demolish(api, *cc, *n, h)
 
 			klog.Infof("Sleeping 1 second for extra luck!")
@@ -131,13 +146,13 @@ func recreateIfNeeded(api libmachine.API, cc *config.ClusterConfig, n *config.No
 	if s == state.Running {
 		if !recreated {
 			register.Reg.SetStep(register.UpdatingDriver)
-			out.Step(style.Running, `Updating the running {{.driver_name}} "{{.cluster}}" {{.machine_type}} ...`, out.V{"driver_name": cc.Driver, "cluster": machineName, "machine_type": machineType})
+			out.Step(style.Running, `Updating the running {{.driver_name}} "{{.cluster}}" {{.machine_type}} ...`, out.V{"driver_name": drv, "cluster": machineName, "machine_type": machineType})
 		}
 		return h, nil
 	}
 
 	if !recreated {
-		out.Step(style.Restarting, `Restarting existing {{.driver_name}} {{.machine_type}} for "{{.cluster}}" ...`, out.V{"driver_name": cc.Driver, "cluster": machineName, "machine_type": machineType})
+		out.Step(style.Restarting, `Restarting existing {{.driver_name}} {{.machine_type}} for "{{.cluster}}" ...`, out.V{"driver_name": drv, "cluster": machineName, "machine_type": machineType})
 	}
 	if err := h.Driver.Start(); err != nil {
 		MaybeDisplayAdvice(err, h.DriverName)
@@ -266,6 +281,16 @@ func machineExists(d string, s state.State, err error) (bool, error) {
 		return machineExistsState(s, err)
 	case driver.KVM2:
 		return machineExistsState(s, err)
+	case driver.VZ:
+		return machineExistsState(s, err)
+	case driver.Firecracker:
+		return machineExistsState(s, err)
+	case driver.CloudHypervisor:
+		return machineExistsState(s, err)
+	case driver.LXD:
+		return machineExistsState(s, err)
+	case driver.WSL:
+		return machineExistsState(s, err)
 	case driver.None:
 		return machineExistsState(s, err)
 	case driver.Parallels:
@@ -0,0 +1,394 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/sysinit"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+)
+
+// provisionedList records the files provisioned from the config of the cluster, with the units they restart,
+// to remove the ones which are no longer declared
+const provisionedList = vmpath.GuestPersistentDir + "/provisioned"
+
+// ProvisionManifest declares the files and systemd drop-ins minikube keeps provisioned in the nodes
+type ProvisionManifest struct {
+	Files          []config.GuestFile
+	SystemdDropIns []config.SystemdDropIn
+}
+
+// guestFile is a file to provision in a node, with its content in memory or in a file of the host
+type guestFile struct {
+	path    string
+	source  string
+	content []byte
+	mode    string
+	owner   string
+	restart []string
+}
+
+// guestState is the state of a provisioned file in a node
+type guestState struct {
+	mode  string
+	owner string
+	hash  string
+}
+
+// LoadProvisionManifest reads the manifest p, with the sources of its files relative to its directory made absolute
+func LoadProvisionManifest(p string) (*ProvisionManifest, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "read the provision manifest")
+	}
+	var m ProvisionManifest
+	if err := yaml.UnmarshalStrict(b, &m); err != nil {
+		return nil, errors.Wrapf(err, "parse %s", p)
+	}
+	seen := map[string]bool{}
+	for i, f := range m.Files {
+		if !path.IsAbs(f.Path) || path.Clean(f.Path) != f.Path {
+			return nil, errors.Errorf("the path %q is not an absolute path", f.Path)
+		}
+		if f.Source != "" && f.Content != "" {
+			return nil, errors.Errorf("%s has both a source and a content", f.Path)
+		}
+		if f.Source != "" {
+			if !filepath.IsAbs(f.Source) {
+				m.Files[i].Source = filepath.Join(filepath.Dir(p), f.Source)
+			}
+			if _, err := os.Stat(m.Files[i].Source); err != nil {
+				return nil, errors.Wrapf(err, "the source of %s", f.Path)
+			}
+		}
+		if _, err := strconv.ParseUint(f.Mode, 8, 32); f.Mode != "" && err != nil {
+			return nil, errors.Errorf("the mode %q of %s is not octal", f.Mode, f.Path)
+		}
+		if seen[f.Path] {
+			return nil, errors.Errorf("%s is declared twice", f.Path)
+		}
+		seen[f.Path] = true
+	}
+	for _, d := range m.SystemdDropIns {
+		if !strings.Contains(d.Unit, ".") || strings.Contains(d.Unit, "/") {
+			return nil, errors.Errorf("the unit %q is not a systemd unit name such as containerd.service", d.Unit)
+		}
+		if !strings.HasSuffix(d.Name, ".conf") || strings.Contains(d.Name, "/") {
+			return nil, errors.Errorf("the drop-in %q of %s is not a file name ending with .conf", d.Name, d.Unit)
+		}
+		p := sysinit.DropInPath(d.Unit, d.Name)
+		if seen[p] {
+			return nil, errors.Errorf("%s is declared twice", p)
+		}
+		seen[p] = true
+	}
+	return &m, nil
+}
+
+// declaredFiles returns the files and drop-ins declared in the config of cc
+func declaredFiles(cc config.ClusterConfig) []guestFile {
+	var fs []guestFile
+	for _, f := range cc.GuestFiles {
+		gf := guestFile{path: f.Path, source: f.Source, mode: f.Mode, owner: f.Owner, restart: f.Restart}
+		if f.Source == "" {
+			gf.content = []byte(f.Content)
+		}
+		fs = append(fs, gf)
+	}
+	for _, d := range cc.SystemdDropIns {
+		fs = append(fs, guestFile{path: sysinit.DropInPath(d.Unit, d.Name), content: []byte(d.Content), restart: []string{d.Unit}})
+	}
+	for i := range fs {
+		if fs[i].mode == "" {
+			fs[i].mode = "0644"
+		}
+		if fs[i].owner == "" {
+			fs[i].owner = "root:root"
+		}
+	}
+	return fs
+}
+
+// provisionGuest syncs the files of the minikube home directory and the ones declared in the config of cc into the node of r,
+// copying only the ones which changed, removes the declared ones which no longer are, and restarts the units of the changed ones
+func provisionGuest(r command.Runner, cc config.ClusterConfig) error {
+	locals, err := localAssets()
+	defer func() {
+		for _, f := range locals {
+			if err := f.Close(); err != nil {
+				klog.Warningf("error closing the file %s: %v", f.GetSourcePath(), err)
+			}
+		}
+	}()
+	if err != nil {
+		return err
+	}
+	var fs []guestFile
+	for _, f := range locals {
+		fs = append(fs, guestFile{path: path.Join(f.GetTargetDir(), f.GetTargetName()), source: f.GetSourcePath(), mode: f.GetPermissions(), owner: "root:root"})
+	}
+	declared := declaredFiles(cc)
+	fs = append(fs, declared...)
+
+	recorded, err := provisionedFiles(r)
+	if err != nil {
+		return err
+	}
+	if len(fs) == 0 && len(recorded) == 0 {
+		return nil
+	}
+
+	var paths []string
+	for _, f := range fs {
+		paths = append(paths, f.path)
+	}
+	state, err := guestStates(r, paths)
+	if err != nil {
+		return err
+	}
+
+	restart := map[string]bool{}
+	var changed []guestFile
+	for _, f := range fs {
+		hash, err := f.hash()
+		if err != nil {
+			return errors.Wrapf(err, "read the content of %s", f.path)
+		}
+		if st, ok := state[f.path]; ok && st.hash == hash && sameMode(st.mode, f.mode) && sameOwner(st.owner, f.owner) {
+			continue
+		}
+		changed = append(changed, f)
+		for _, u := range f.restart {
+			restart[u] = true
+		}
+	}
+	if err := copyGuestFiles(r, changed); err != nil {
+		return err
+	}
+
+	keep := map[string]bool{}
+	record := false
+	for _, f := range declared {
+		keep[f.path] = true
+		if _, ok := recorded[f.path]; !ok {
+			record = true
+		}
+	}
+	var removed []string
+	for p, units := range recorded {
+		if keep[p] {
+			continue
+		}
+		removed = append(removed, p)
+		for _, u := range units {
+			restart[u] = true
+		}
+	}
+	if len(removed) > 0 {
+		sort.Strings(removed)
+		klog.Infof("removing the files no longer declared: %v", removed)
+		if _, err := r.RunCmd(exec.Command("sudo", append([]string{"rm", "-f"}, removed...)...)); err != nil {
+			return errors.Wrap(err, "remove the files no longer declared")
+		}
+	}
+	if record || len(changed) > 0 || len(removed) > 0 {
+		if err := recordProvisioned(r, declared); err != nil {
+			return err
+		}
+	}
+	return restartUnits(r, restart)
+}
+
+// hash returns the sha256 of the content of f
+func (f guestFile) hash() (string, error) {
+	h := sha256.New()
+	if f.source == "" {
+		h.Write(f.content)
+	} else {
+		src, err := os.Open(f.source)
+		if err != nil {
+			return "", err
+		}
+		defer src.Close()
+		if _, err := io.Copy(h, src); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// guestStates returns the mode, owner and sha256 of the files of paths existing in the node of r
+func guestStates(r command.Runner, paths []string) (map[string]guestState, error) {
+	state := map[string]guestState{}
+	if len(paths) == 0 {
+		return state, nil
+	}
+	script := `for f in "$@"; do if [ -f "$f" ]; then echo "$(stat -c '%a %U:%G' "$f") $(sha256sum < "$f" | cut -d' ' -f1) $f"; fi; done`
+	rr, err := r.RunCmd(exec.Command("sudo", append([]string{"sh", "-c", script, "sh"}, paths...)...))
+	if err != nil {
+		return nil, errors.Wrap(err, "check the provisioned files")
+	}
+	for _, line := range strings.Split(rr.Stdout.String(), "\n") {
+		fields := strings.SplitN(line, " ", 4)
+		if len(fields) == 4 {
+			state[fields[3]] = guestState{mode: fields[0], owner: fields[1], hash: fields[2]}
+		}
+	}
+	return state, nil
+}
+
+// copyGuestFiles copies fs into the node of r
+func copyGuestFiles(r command.Runner, fs []guestFile) error {
+	if len(fs) == 0 {
+		return nil
+	}
+	seen := map[string]bool{}
+	create := []string{}
+	for _, f := range fs {
+		dir := path.Dir(f.path)
+		if guaranteed[dir] || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		create = append(create, dir)
+	}
+	if len(create) > 0 {
+		if _, err := r.RunCmd(exec.Command("sudo", append([]string{"mkdir", "-p"}, create...)...)); err != nil {
+			return err
+		}
+	}
+	for _, f := range fs {
+		klog.Infof("provisioning %s (mode %s, owner %s)", f.path, f.mode, f.owner)
+		if err := copyGuestFile(r, f); err != nil {
+			return err
+		}
+		// the copy keeps the mode of an existing file
+		if _, err := r.RunCmd(exec.Command("sudo", "chmod", f.mode, f.path)); err != nil {
+			return err
+		}
+		if _, err := r.RunCmd(exec.Command("sudo", "chown", f.owner, f.path)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyGuestFile(r command.Runner, f guestFile) error {
+	if f.source == "" {
+		return r.Copy(assets.NewMemoryAssetTarget(f.content, f.path, f.mode))
+	}
+	a, err := assets.NewFileAsset(f.source, path.Dir(f.path), path.Base(f.path), f.mode)
+	if err != nil {
+		return errors.Wrapf(err, "creating file asset for %s", f.source)
+	}
+	defer a.Close()
+	return r.Copy(a)
+}
+
+// provisionedFiles returns the files recorded by recordProvisioned in the node of r, with the units they restart
+func provisionedFiles(r command.Runner) (map[string][]string, error) {
+	rr, err := r.RunCmd(exec.Command("sudo", "sh", "-c", fmt.Sprintf("cat %s 2>/dev/null || true", provisionedList)))
+	if err != nil {
+		return nil, errors.Wrap(err, "read the provisioned files")
+	}
+	recorded := map[string][]string{}
+	for _, line := range strings.Split(rr.Stdout.String(), "\n") {
+		fields := strings.Split(line, "\t")
+		if fields[0] == "" {
+			continue
+		}
+		recorded[fields[0]] = nil
+		if len(fields) > 1 && fields[1] != "" {
+			recorded[fields[0]] = strings.Split(fields[1], ",")
+		}
+	}
+	return recorded, nil
+}
+
+// recordProvisioned records the declared files fs in the node of r
+func recordProvisioned(r command.Runner, fs []guestFile) error {
+	var b bytes.Buffer
+	for _, f := range fs {
+		fmt.Fprintf(&b, "%s\t%s\n", f.path, strings.Join(f.restart, ","))
+	}
+	if err := r.Copy(assets.NewMemoryAssetTarget(b.Bytes(), provisionedList, "0644")); err != nil {
+		return errors.Wrap(err, "record the provisioned files")
+	}
+	return nil
+}
+
+// restartUnits restarts the active units of units, once systemd reloaded the changed drop-ins
+func restartUnits(r command.Runner, units map[string]bool) error {
+	var names []string
+	for u := range units {
+		names = append(names, u)
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	// the units which are not active start with their changed drop-ins
+	if _, err := r.RunCmd(exec.Command("sudo", "systemctl", "daemon-reload")); err != nil {
+		klog.Warningf("reloading systemd: %v", err)
+	}
+	s := sysinit.New(r)
+	for _, u := range names {
+		if !s.Active(u) {
+			klog.Infof("not restarting %s, which is not active", u)
+			continue
+		}
+		klog.Infof("restarting %s, as its provisioned files changed", u)
+		if err := s.Restart(u); err != nil {
+			return errors.Wrapf(err, "restart %s", u)
+		}
+	}
+	return nil
+}
+
+// sameMode tells if the octal modes a and b are the same, such as 644 and 0644
+func sameMode(a, b string) bool {
+	ma, erra := strconv.ParseUint(a, 8, 32)
+	mb, errb := strconv.ParseUint(b, 8, 32)
+	return erra == nil && errb == nil && ma == mb
+}
+
+// sameOwner tells if the USER:GROUP owner of a file is the USER[:GROUP] want
+func sameOwner(owner, want string) bool {
+	if strings.Contains(want, ":") {
+		return owner == want
+	}
+	return strings.SplitN(owner, ":", 2)[0] == want
+}
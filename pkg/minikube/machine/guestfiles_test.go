@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestLoadProvisionManifest(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "registries.conf"), []byte("[registries]"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	write := func(manifest string) string {
+		p := filepath.Join(dir, "provision.yaml")
+		if err := os.WriteFile(p, []byte(manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	m, err := LoadProvisionManifest(write(`
+files:
+- path: /etc/containers/registries.conf
+  source: registries.conf
+  restart: [crio.service]
+- path: /etc/motd
+  content: hello
+  mode: "0600"
+  owner: docker
+systemdDropIns:
+- unit: containerd.service
+  name: 10-limits.conf
+  content: |
+    [Service]
+    LimitNOFILE=1048576
+`))
+	if err != nil {
+		t.Fatalf("LoadProvisionManifest: %v", err)
+	}
+	if len(m.Files) != 2 || m.Files[0].Source != filepath.Join(dir, "registries.conf") || len(m.SystemdDropIns) != 1 {
+		t.Fatalf("LoadProvisionManifest() = %+v", m)
+	}
+
+	cc := config.ClusterConfig{GuestFiles: m.Files, SystemdDropIns: m.SystemdDropIns}
+	fs := declaredFiles(cc)
+	if len(fs) != 3 {
+		t.Fatalf("declaredFiles() = %+v", fs)
+	}
+	if fs[0].mode != "0644" || fs[0].owner != "root:root" || fs[1].mode != "0600" || fs[1].owner != "docker" {
+		t.Errorf("declaredFiles() did not default the mode and owner: %+v", fs)
+	}
+	if fs[2].path != "/etc/systemd/system/containerd.service.d/10-limits.conf" || fs[2].restart[0] != "containerd.service" {
+		t.Errorf("declaredFiles() = %+v, expected the drop-in restarting containerd.service", fs[2])
+	}
+
+	for _, invalid := range []string{
+		"files: [{path: etc/motd}]",
+		"files: [{path: /etc/motd, source: missing.conf}]",
+		"files: [{path: /etc/motd, source: registries.conf, content: x}]",
+		"files: [{path: /etc/motd, mode: rw-r--r--}]",
+		"files: [{path: /etc/motd}, {path: /etc/motd}]",
+		"systemdDropIns: [{unit: containerd, name: 10-limits.conf}]",
+		"systemdDropIns: [{unit: containerd.service, name: 10-limits}]",
+		"directories: [/etc/foo]",
+	} {
+		if _, err := LoadProvisionManifest(write(invalid)); err == nil {
+			t.Errorf("LoadProvisionManifest(%q) did not fail", invalid)
+		}
+	}
+}
+
+func TestSameModeOwner(t *testing.T) {
+	if !sameMode("644", "0644") || sameMode("600", "0644") {
+		t.Errorf("sameMode does not compare the octal modes")
+	}
+	if !sameOwner("docker:docker", "docker") || !sameOwner("root:root", "root:root") || sameOwner("root:docker", "root:root") {
+		t.Errorf("sameOwner does not compare the owners")
+	}
+}
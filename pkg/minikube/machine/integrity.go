@@ -0,0 +1,273 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/docker/machine/libmachine/host"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+)
+
+// How the state of a node is recovered on start after an unclean shutdown
+const (
+	// RecoverAutoRepair repairs what can be repaired, and fails on a corrupted etcd database
+	RecoverAutoRepair = "auto-repair"
+	// RecoverRestoreSnapshot restores the etcd data saved by the last clean stop on a corrupted etcd database, and repairs the rest
+	RecoverRestoreSnapshot = "restore-snapshot"
+	// RecoverNone only reports the problems
+	RecoverNone = "none"
+)
+
+// StateRecoveryModes are the values of the state recovery of a cluster
+var StateRecoveryModes = []string{RecoverAutoRepair, RecoverRestoreSnapshot, RecoverNone}
+
+var (
+	// cleanStopMarker is written by a clean stop, and removed by the next start
+	cleanStopMarker = path.Join(vmpath.GuestPersistentDir, "clean-stop")
+	// etcdDataDir is the etcd data directory of kubeadm
+	etcdDataDir = path.Join(vmpath.GuestPersistentDir, "etcd")
+	// etcdSnapshotDir is the copy of the etcd data saved by the last clean stop
+	etcdSnapshotDir = path.Join(vmpath.GuestPersistentDir, "etcd-snapshot")
+	// fsckLog is the output of the e2fsck run on the data disk by the automount of the minikube ISO
+	fsckLog = "/run/minikube-fsck.log"
+)
+
+// stateProblem is a problem with the state of a node, found after an unclean shutdown
+type stateProblem struct {
+	component string
+	problem   string
+	// repair repairs the problem, nil if it can not be repaired
+	repair func(command.Runner) error
+}
+
+// stopEtcd stops the kubelet and the etcd container, so that the etcd data is not written to
+const stopEtcd = `sudo systemctl stop kubelet; ids=$(sudo crictl ps -q --name etcd 2>/dev/null); [ -z "$ids" ] || sudo crictl stop $ids`
+
+// saveCleanStop snapshots the etcd data of a node about to be stopped, and marks the stop as clean
+func saveCleanStop(h *host.Host) {
+	if driver.BareMetal(h.DriverName) || driver.IsMock(h.DriverName) {
+		return
+	}
+	r, err := CommandRunner(h)
+	if err != nil {
+		klog.Warningf("command runner: %v", err)
+		return
+	}
+	script := fmt.Sprintf(`%s; if sudo test -d %[2]s/member; then sudo rm -rf %[3]s.tmp && sudo cp -a %[2]s/member %[3]s.tmp && sudo rm -rf %[3]s && sudo mv %[3]s.tmp %[3]s; fi; sudo touch %[4]s`,
+		stopEtcd, etcdDataDir, etcdSnapshotDir, cleanStopMarker)
+	if _, err := r.RunCmd(exec.Command("/bin/bash", "-c", script)); err != nil {
+		klog.Warningf("saving the clean stop of %s: %v", h.Name, err)
+	}
+}
+
+// uncleanShutdown returns whether the last shutdown of an already provisioned node was not a clean stop
+func uncleanShutdown(r command.Runner) bool {
+	if _, err := r.RunCmd(exec.Command("sudo", "test", "-f", cleanStopMarker)); err == nil {
+		if _, err := r.RunCmd(exec.Command("sudo", "rm", "-f", cleanStopMarker)); err != nil {
+			klog.Warningf("removing the clean stop marker: %v", err)
+		}
+		return false
+	}
+	// a node never provisioned has no state to check
+	_, err := r.RunCmd(exec.Command("sudo", "test", "-e", "/var/lib/kubelet/config.yaml"))
+	return err == nil
+}
+
+// recoverState checks the state of a node restarted after an unclean shutdown, and recovers it with the mode of the cluster
+func recoverState(h *host.Host, cc config.ClusterConfig) error {
+	if driver.BareMetal(h.DriverName) {
+		return nil
+	}
+	r, err := CommandRunner(h)
+	if err != nil {
+		return errors.Wrap(err, "command runner")
+	}
+	if !uncleanShutdown(r) {
+		return nil
+	}
+	mode := cc.StateRecovery
+	if mode == "" {
+		mode = RecoverAutoRepair
+	}
+	out.Step(style.Workaround, "{{.name}} was not stopped cleanly, checking its state ...", out.V{"name": h.Name})
+
+	problems := checkState(r, h.DriverName, cc.KubernetesConfig.ContainerRuntime)
+	etcdCorrupted := false
+	for _, p := range problems {
+		out.WarningT("{{.component}}: {{.problem}}", out.V{"component": p.component, "problem": p.problem})
+		if p.component == "etcd" {
+			etcdCorrupted = true
+		}
+	}
+
+	switch mode {
+	case RecoverNone:
+		if len(problems) > 0 {
+			out.Styled(style.Tip, "To recover the state, run: minikube start --recover-state={{.repair}}, or --recover-state={{.restore}} to restore the etcd data of the last clean stop",
+				out.V{"repair": RecoverAutoRepair, "restore": RecoverRestoreSnapshot})
+		}
+		return nil
+	case RecoverRestoreSnapshot:
+		if !etcdCorrupted {
+			break
+		}
+		if err := restoreEtcdSnapshot(r); err != nil {
+			exit.Message(reason.GuestStateCorrupt, "Unable to restore the etcd data of {{.name}}: {{.error}}", out.V{"name": h.Name, "error": err})
+		}
+		out.Step(style.Check, "Restored the etcd data of the last clean stop of {{.name}}", out.V{"name": h.Name})
+		etcdCorrupted = false
+	}
+
+	for _, p := range problems {
+		if p.repair == nil {
+			continue
+		}
+		out.Step(style.Workaround, "Repairing {{.component}} ...", out.V{"component": p.component})
+		if err := p.repair(r); err != nil {
+			out.WarningT("Failed to repair {{.component}}: {{.error}}", out.V{"component": p.component, "error": err})
+		}
+	}
+	if etcdCorrupted {
+		exit.Message(reason.GuestStateCorrupt, "The etcd database of {{.name}} is corrupted", out.V{"name": h.Name})
+	}
+	if len(problems) == 0 {
+		out.Step(style.Check, "The state of {{.name}} is intact", out.V{"name": h.Name})
+	}
+	return nil
+}
+
+// checkState returns the problems of the filesystem, containerd images and etcd database of a node
+func checkState(r command.Runner, drv string, runtime string) []stateProblem {
+	var problems []stateProblem
+	if driver.IsVM(drv) {
+		if rr, err := r.RunCmd(exec.Command("cat", fsckLog)); err != nil {
+			klog.Infof("no fsck log, skipping the filesystem check: %v", err)
+		} else if p := fsckProblem(rr.Stdout.String()); p != "" {
+			problems = append(problems, stateProblem{component: "filesystem", problem: p})
+		}
+	}
+
+	if runtime == constants.Containerd {
+		if rr, err := r.RunCmd(exec.Command("sudo", "ctr", "-n", "k8s.io", "images", "check")); err != nil {
+			klog.Warningf("checking the containerd images: %v", err)
+		} else if refs := incompleteImages(rr.Stdout.String()); len(refs) > 0 {
+			problems = append(problems, stateProblem{
+				component: "containerd",
+				problem:   fmt.Sprintf("the content of the images %s is incomplete", strings.Join(refs, ", ")),
+				// the removed images are loaded or pulled again by the start
+				repair: func(r command.Runner) error {
+					_, err := r.RunCmd(exec.Command("sudo", append([]string{"ctr", "-n", "k8s.io", "images", "rm"}, refs...)...))
+					return err
+				},
+			})
+		}
+	}
+
+	db := path.Join(etcdDataDir, "member/snap/db")
+	if _, err := r.RunCmd(exec.Command("sudo", "test", "-f", db)); err == nil {
+		// the magic numbers of the two meta pages of the bbolt database
+		script := fmt.Sprintf("sudo od -An -tx4 -j16 -N4 %[1]s; sudo od -An -tx4 -j4112 -N4 %[1]s", db)
+		rr, err := r.RunCmd(exec.Command("/bin/bash", "-c", script))
+		if err != nil {
+			klog.Warningf("reading the etcd database: %v", err)
+		} else if !boltMagicValid(rr.Stdout.String()) {
+			problems = append(problems, stateProblem{component: "etcd", problem: "the meta pages of the etcd database are corrupted"})
+		}
+	}
+	return problems
+}
+
+// restoreEtcdSnapshot replaces the etcd data with the copy saved by the last clean stop, keeping the replaced data aside
+func restoreEtcdSnapshot(r command.Runner) error {
+	if _, err := r.RunCmd(exec.Command("sudo", "test", "-d", etcdSnapshotDir)); err != nil {
+		return fmt.Errorf("no etcd data was saved by a clean stop")
+	}
+	return replaceEtcdData(r, etcdSnapshotDir, "corrupted")
+}
+
+// replaceEtcdData stops etcd and replaces its data with the copy of the member directory in src, keeping the replaced data in member.<aside>
+func replaceEtcdData(r command.Runner, src string, aside string) error {
+	script := fmt.Sprintf(`%s; sudo rm -rf %[2]s/member.%[4]s && (! sudo test -d %[2]s/member || sudo mv %[2]s/member %[2]s/member.%[4]s) && sudo cp -a %[3]s %[2]s/member`,
+		stopEtcd, etcdDataDir, src, aside)
+	_, err := r.RunCmd(exec.Command("/bin/bash", "-c", script))
+	return err
+}
+
+var fsckStatusRe = regexp.MustCompile(`(?m)^exit status: (\d+)$`)
+
+// fsckProblem returns the problem reported by the e2fsck exit status of the log, if any
+func fsckProblem(log string) string {
+	m := fsckStatusRe.FindAllStringSubmatch(log, -1)
+	if len(m) == 0 {
+		return ""
+	}
+	status, err := strconv.Atoi(m[len(m)-1][1])
+	if err != nil {
+		return ""
+	}
+	switch {
+	case status&4 != 0:
+		return "e2fsck left errors uncorrected on the data disk"
+	case status&8 != 0:
+		return "e2fsck failed to check the data disk"
+	case status&3 != 0:
+		klog.Infof("e2fsck repaired the data disk (status %d)", status)
+	}
+	return ""
+}
+
+// incompleteImages returns the references of the images whose content is missing in the output of ctr images check
+func incompleteImages(check string) []string {
+	var refs []string
+	for _, line := range strings.Split(check, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] == "REF" {
+			continue
+		}
+		if strings.Contains(line, "incomplete") || strings.Contains(line, "unavailable") {
+			refs = append(refs, fields[0])
+		}
+	}
+	return refs
+}
+
+// boltMagicValid returns whether one of the meta pages of a bbolt database, dumped by od, has the bbolt magic number
+func boltMagicValid(dump string) bool {
+	for _, f := range strings.Fields(dump) {
+		if f == "ed0cdaed" {
+			return true
+		}
+	}
+	return false
+}
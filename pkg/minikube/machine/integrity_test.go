@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFsckProblem(t *testing.T) {
+	tests := []struct {
+		log  string
+		want bool
+	}{
+		{log: "", want: false},
+		{log: "/dev/vda1: clean, 11/65536 files\nexit status: 0\n", want: false},
+		{log: "/dev/vda1: recovering journal\nexit status: 1\n", want: false},
+		{log: "/dev/vda1: UNEXPECTED INCONSISTENCY; RUN fsck MANUALLY.\nexit status: 4\n", want: true},
+		{log: "exit status: 4\nFIXED\nexit status: 1\n", want: false},
+		{log: "e2fsck: Cannot continue, aborting.\nexit status: 8\n", want: true},
+	}
+	for _, tc := range tests {
+		if got := fsckProblem(tc.log) != ""; got != tc.want {
+			t.Errorf("fsckProblem(%q) reported a problem: %t, want %t", tc.log, got, tc.want)
+		}
+	}
+}
+
+func TestIncompleteImages(t *testing.T) {
+	check := `REF                                      TYPE                                                 DIGEST                                                                  STATUS           SIZE                  UNPACKED
+registry.k8s.io/pause:3.9                application/vnd.docker.distribution.manifest.list.v2+json sha256:7031c1b283388d2c2e09b57badb803c05ebed362dc88d84b480cc47f72a21097 complete (3/3)   262.9 KiB/262.9 KiB   true
+registry.k8s.io/etcd:3.5.10-0            application/vnd.docker.distribution.manifest.list.v2+json sha256:22f892d7672adc0b9c86df67792afdb8b2dc08880f49f669eaaa59c47d7908c2 incomplete (2/3) 30.2 MiB/54.3 MiB     false
+registry.k8s.io/coredns/coredns:v1.11.1  application/vnd.docker.distribution.manifest.list.v2+json sha256:1eeb4c7316bacb1d4c8ead65571cd92dd21e27359f0d4917f1a5822a73b75db1 unavailable      0.0 B/?               false
+`
+	want := []string{"registry.k8s.io/etcd:3.5.10-0", "registry.k8s.io/coredns/coredns:v1.11.1"}
+	if diff := cmp.Diff(want, incompleteImages(check)); diff != "" {
+		t.Errorf("incompleteImages() mismatch (-want +got):\n%s", diff)
+	}
+	if got := incompleteImages(""); len(got) != 0 {
+		t.Errorf("incompleteImages(\"\") = %v, want none", got)
+	}
+}
+
+func TestBoltMagicValid(t *testing.T) {
+	tests := []struct {
+		dump string
+		want bool
+	}{
+		{dump: " ed0cdaed\n ed0cdaed\n", want: true},
+		{dump: " 00000000\n ed0cdaed\n", want: true},
+		{dump: " 00000000\n 00000000\n", want: false},
+		{dump: "", want: false},
+	}
+	for _, tc := range tests {
+		if got := boltMagicValid(tc.dump); got != tc.want {
+			t.Errorf("boltMagicValid(%q) = %t, want %t", tc.dump, got, tc.want)
+		}
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/network"
 	"k8s.io/minikube/pkg/provision"
 )
 
@@ -102,9 +103,11 @@ func provisionDockerMachine(h *host.Host) error {
 func fastDetectProvisioner(h *host.Host) (libprovision.Provisioner, error) {
 	d := h.Driver.DriverName()
 	switch {
-	case driver.IsKIC(d):
+	case driver.IsKIC(d), driver.IsLXD(d), driver.IsWSL(d):
 		return provision.NewUbuntuProvisioner(h.Driver), nil
-	case driver.BareMetal(d), driver.IsSSH(d):
+	case driver.IsSSH(d):
+		return provision.Detect(h.Driver)
+	case driver.BareMetal(d):
 		return libprovision.DetectProvisioner(h.Driver)
 	default:
 		return provision.NewBuildrootProvisioner(h.Driver), nil
@@ -126,5 +129,10 @@ func saveHost(api libmachine.API, h *host.Host, cfg *config.ClusterConfig, n *co
 		ip = "10.0.2.15"
 	}
 	n.IP = ip
+	if config.HasIPv6(*cfg) {
+		if n.IPv6, err = network.IPv6Of(ip); err != nil {
+			return err
+		}
+	}
 	return config.SaveNode(cfg, n)
 }
@@ -0,0 +1,186 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/drivers/kic"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+// ReconstructConfig rebuilds the config of a single node cluster from the state of its machine:
+// the driver config saved by libmachine, and the kubeadm config of the running node.
+func ReconstructConfig(api libmachine.API, name string) (*config.ClusterConfig, error) {
+	h, err := LoadHost(api, name)
+	if err != nil {
+		return nil, err
+	}
+	d, err := json.Marshal(h.Driver)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal driver")
+	}
+	cc, err := configFromDriver(name, h.DriverName, d)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := h.Driver.GetState()
+	if err != nil {
+		return nil, errors.Wrap(err, "state")
+	}
+	if s == state.Running {
+		r, err := CommandRunner(h)
+		if err != nil {
+			return nil, errors.Wrap(err, "command runner")
+		}
+		rr, err := r.RunCmd(exec.Command("sudo", "cat", constants.KubeadmYamlPath))
+		if err != nil {
+			klog.Warningf("unable to read the kubeadm config of %s: %v", name, err)
+		} else if err := applyKubeadmConfig(cc, rr.Stdout.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	if cc.KubernetesConfig.KubernetesVersion == "" {
+		return nil, fmt.Errorf("unable to find the Kubernetes version of %s, start the machine and try again", name)
+	}
+	cc.Nodes[0].KubernetesVersion = cc.KubernetesConfig.KubernetesVersion
+	cc.Nodes[0].ContainerRuntime = cc.KubernetesConfig.ContainerRuntime
+	return cc, nil
+}
+
+// configFromDriver returns the cluster config implied by the driver config saved by libmachine
+func configFromDriver(name, driverName string, driverJSON []byte) (*config.ClusterConfig, error) {
+	var d struct {
+		IPAddress  string
+		CPU        int
+		Memory     int
+		DiskSize   int
+		NodeConfig *kic.Config
+	}
+	if err := json.Unmarshal(driverJSON, &d); err != nil {
+		return nil, errors.Wrap(err, "unmarshal driver")
+	}
+
+	cc := &config.ClusterConfig{
+		Name:     name,
+		Driver:   driverName,
+		CPUs:     d.CPU,
+		Memory:   d.Memory,
+		DiskSize: d.DiskSize,
+		KubernetesConfig: config.KubernetesConfig{
+			ClusterName:      name,
+			ContainerRuntime: constants.DefaultContainerRuntime,
+			ServiceCIDR:      constants.DefaultServiceCIDR,
+			DNSDomain:        constants.ClusterDNSDomain,
+		},
+		Nodes: []config.Node{{
+			IP:           d.IPAddress,
+			Port:         constants.APIServerPort,
+			ControlPlane: true,
+			Worker:       true,
+		}},
+	}
+	if n := d.NodeConfig; n != nil {
+		cc.CPUs = n.CPU
+		cc.Memory = n.Memory
+		cc.KicBaseImage = n.ImageDigest
+		cc.Network = n.Network
+		cc.Subnet = n.Subnet
+		cc.StaticIP = n.StaticIP
+		cc.ListenAddress = n.ListenAddress
+		cc.APIServerPort = n.APIServerPort
+		cc.KubernetesConfig.KubernetesVersion = n.KubernetesVersion
+		if n.ContainerRuntime != "" {
+			cc.KubernetesConfig.ContainerRuntime = n.ContainerRuntime
+		}
+	}
+	return cc, nil
+}
+
+// kubeadmDocument holds the fields of the kubeadm InitConfiguration and ClusterConfiguration used by minikube
+type kubeadmDocument struct {
+	Kind              string `yaml:"kind"`
+	KubernetesVersion string `yaml:"kubernetesVersion"`
+	Networking        struct {
+		DNSDomain     string `yaml:"dnsDomain"`
+		ServiceSubnet string `yaml:"serviceSubnet"`
+	} `yaml:"networking"`
+	LocalAPIEndpoint struct {
+		AdvertiseAddress string `yaml:"advertiseAddress"`
+		BindPort         int    `yaml:"bindPort"`
+	} `yaml:"localAPIEndpoint"`
+	NodeRegistration struct {
+		CRISocket string `yaml:"criSocket"`
+	} `yaml:"nodeRegistration"`
+}
+
+// applyKubeadmConfig updates the cluster config from the kubeadm config of the control plane
+func applyKubeadmConfig(cc *config.ClusterConfig, kubeadmYAML []byte) error {
+	for _, doc := range bytes.Split(kubeadmYAML, []byte("\n---")) {
+		var k kubeadmDocument
+		if err := yaml.Unmarshal(doc, &k); err != nil {
+			return errors.Wrap(err, "unmarshal kubeadm config")
+		}
+		switch k.Kind {
+		case "InitConfiguration":
+			if k.LocalAPIEndpoint.AdvertiseAddress != "" {
+				cc.Nodes[0].IP = k.LocalAPIEndpoint.AdvertiseAddress
+			}
+			if k.LocalAPIEndpoint.BindPort != 0 {
+				cc.Nodes[0].Port = k.LocalAPIEndpoint.BindPort
+			}
+			if rt := runtimeFromSocket(k.NodeRegistration.CRISocket); rt != "" {
+				cc.KubernetesConfig.ContainerRuntime = rt
+			}
+		case "ClusterConfiguration":
+			cc.KubernetesConfig.KubernetesVersion = k.KubernetesVersion
+			if k.Networking.ServiceSubnet != "" {
+				cc.KubernetesConfig.ServiceCIDR = k.Networking.ServiceSubnet
+			}
+			if k.Networking.DNSDomain != "" {
+				cc.KubernetesConfig.DNSDomain = k.Networking.DNSDomain
+			}
+		}
+	}
+	return nil
+}
+
+// runtimeFromSocket returns the container runtime listening on a CRI socket
+func runtimeFromSocket(socket string) string {
+	switch {
+	case strings.Contains(socket, "containerd"):
+		return constants.Containerd
+	case strings.Contains(socket, "crio"):
+		return constants.CRIO
+	case strings.Contains(socket, "dockershim"), strings.Contains(socket, "cri-dockerd"):
+		return constants.Docker
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"os"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+func TestConfigFromDriver(t *testing.T) {
+	vm := `{"IPAddress": "192.168.39.10", "MachineName": "minikube", "CPU": 4, "Memory": 4096, "DiskSize": 20000}`
+	cc, err := configFromDriver("minikube", "kvm2", []byte(vm))
+	if err != nil {
+		t.Fatalf("configFromDriver: %v", err)
+	}
+	if cc.CPUs != 4 || cc.Memory != 4096 || cc.DiskSize != 20000 || cc.Nodes[0].IP != "192.168.39.10" {
+		t.Errorf("configFromDriver() = %+v, want the resources and IP of the VM", cc)
+	}
+
+	kic := `{"IPAddress": "192.168.49.2", "NodeConfig": {"CPU": 2, "Memory": 2200, "ImageDigest": "gcr.io/k8s-minikube/kicbase:v0.0.42", "KubernetesVersion": "v1.28.3", "ContainerRuntime": "containerd", "APIServerPort": 8443}}`
+	cc, err = configFromDriver("minikube", "docker", []byte(kic))
+	if err != nil {
+		t.Fatalf("configFromDriver: %v", err)
+	}
+	if cc.CPUs != 2 || cc.Memory != 2200 || cc.KicBaseImage != "gcr.io/k8s-minikube/kicbase:v0.0.42" {
+		t.Errorf("configFromDriver() = %+v, want the resources and image of the container", cc)
+	}
+	if cc.KubernetesConfig.KubernetesVersion != "v1.28.3" || cc.KubernetesConfig.ContainerRuntime != constants.Containerd {
+		t.Errorf("KubernetesConfig = %+v, want the version and runtime of the container", cc.KubernetesConfig)
+	}
+}
+
+func TestApplyKubeadmConfig(t *testing.T) {
+	b, err := os.ReadFile("../bootstrapper/bsutil/testdata/v1.28/containerd-api-port.yaml")
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	cc, err := configFromDriver("mk", "kvm2", []byte(`{}`))
+	if err != nil {
+		t.Fatalf("configFromDriver: %v", err)
+	}
+	if err := applyKubeadmConfig(cc, b); err != nil {
+		t.Fatalf("applyKubeadmConfig: %v", err)
+	}
+	k := cc.KubernetesConfig
+	if k.KubernetesVersion != "v1.28.0" || k.ContainerRuntime != constants.Containerd || k.ServiceCIDR != "10.96.0.0/12" {
+		t.Errorf("KubernetesConfig = %+v, want the settings of kubeadm", k)
+	}
+	if n := cc.Nodes[0]; n.IP != "1.1.1.1" || n.Port != 12345 {
+		t.Errorf("node = %+v, want the API endpoint of kubeadm", n)
+	}
+}
+
+func TestRuntimeFromSocket(t *testing.T) {
+	tests := map[string]string{
+		"unix:///run/containerd/containerd.sock": constants.Containerd,
+		"unix:///var/run/crio/crio.sock":         constants.CRIO,
+		"unix:///var/run/cri-dockerd.sock":       constants.Docker,
+		"":                                       "",
+	}
+	for socket, want := range tests {
+		if got := runtimeFromSocket(socket); got != want {
+			t.Errorf("runtimeFromSocket(%q) = %q, want %q", socket, got, want)
+		}
+	}
+}
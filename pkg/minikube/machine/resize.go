@@ -0,0 +1,257 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	pkgdrivers "k8s.io/minikube/pkg/drivers"
+	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/sysinit"
+	"k8s.io/minikube/pkg/util"
+)
+
+// SupportsResize returns whether the CPUs and memory of the machines of the driver can be changed after their creation
+func SupportsResize(drv string) bool {
+	return !driver.BareMetal(drv) && !driver.IsSSH(drv)
+}
+
+// LiveResize changes the CPUs and the memory in MB of the running machine of n, and returns false without error
+// if its driver can not change them without restarting it
+func LiveResize(cc config.ClusterConfig, n config.Node, cpus int, memory int) (bool, error) {
+	drv := config.NodeDriver(cc, n)
+	machineName := config.MachineName(cc, n)
+	switch {
+	case driver.IsKIC(drv):
+		if err := oci.UpdateContainerResources(drv, machineName, cpus, memory); err != nil {
+			return false, err
+		}
+	case driver.IsKVM(drv):
+		// only within the maximum vCPUs and memory of the domain, which are the ones it was created with
+		for _, args := range [][]string{
+			{"setvcpus", machineName, strconv.Itoa(cpus), "--live", "--config"},
+			{"setmem", machineName, fmt.Sprintf("%dM", memory), "--live", "--config"},
+		} {
+			if err := virsh(cc, args...); err != nil {
+				klog.Infof("%s can not be resized live: %v", machineName, err)
+				return false, nil
+			}
+		}
+	case driver.IsHyperV(drv):
+		// memory can be changed at runtime on recent Hyper-V hosts, but not the processors
+		if cpus != config.WithNodePool(cc, n).CPUs {
+			return false, nil
+		}
+		if err := util.PowerShell(fmt.Sprintf("Hyper-V\\Set-VMMemory -VMName %s -StartupBytes %dMB", machineName, memory)); err != nil {
+			klog.Infof("%s can not be resized live: %v", machineName, err)
+			return false, nil
+		}
+	default:
+		return false, nil
+	}
+	return true, updateDriverConfig(machineName, resources(cpus, memory))
+}
+
+// ResizeStopped changes the CPUs and the memory in MB of the stopped machine of n, which it gets when started
+func ResizeStopped(cc config.ClusterConfig, n config.Node, cpus int, memory int) error {
+	drv := config.NodeDriver(cc, n)
+	machineName := config.MachineName(cc, n)
+	switch {
+	case driver.IsKIC(drv):
+		if err := oci.UpdateContainerResources(drv, machineName, cpus, memory); err != nil {
+			return err
+		}
+	case driver.IsKVM(drv):
+		// the maximums are raised before the values, and lowered after them
+		cpuArgs := [][]string{{"setvcpus", machineName, strconv.Itoa(cpus), "--config", "--maximum"}, {"setvcpus", machineName, strconv.Itoa(cpus), "--config"}}
+		memArgs := [][]string{{"setmaxmem", machineName, fmt.Sprintf("%dM", memory), "--config"}, {"setmem", machineName, fmt.Sprintf("%dM", memory), "--config"}}
+		for _, pair := range [][][]string{cpuArgs, memArgs} {
+			if err := virsh(cc, pair[0]...); err != nil {
+				klog.Infof("retrying in reverse order: %v", err)
+				if err := virsh(cc, pair[1]...); err != nil {
+					return err
+				}
+				if err := virsh(cc, pair[0]...); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := virsh(cc, pair[1]...); err != nil {
+				return err
+			}
+		}
+	case drv == driver.VirtualBox:
+		if err := runSnapshotCmd(driver.VBoxManagePath(), "modifyvm", machineName, "--cpus", strconv.Itoa(cpus), "--memory", strconv.Itoa(memory)); err != nil {
+			return err
+		}
+	case driver.IsHyperV(drv):
+		if err := util.PowerShell(fmt.Sprintf("Hyper-V\\Set-VMProcessor -VMName %s -Count %d; Hyper-V\\Set-VMMemory -VMName %s -StartupBytes %dMB", machineName, cpus, machineName, memory)); err != nil {
+			return err
+		}
+	}
+	// the other drivers pass the CPUs and memory of their config to the hypervisor on each start
+	return updateDriverConfig(machineName, resources(cpus, memory))
+}
+
+// resources returns the driver config values of cpus and memory, the hyperv driver naming the memory MemSize
+func resources(cpus int, memory int) map[string]int {
+	return map[string]int{"CPU": cpus, "CPUs": cpus, "Memory": memory, "MemSize": memory}
+}
+
+// SupportsDiskResize returns whether the disks of the machines of the driver can be grown after their creation
+func SupportsDiskResize(drv string) bool {
+	switch {
+	case driver.IsKVM(drv), driver.IsQEMU(drv), driver.IsHyperV(drv):
+		return true
+	}
+	switch drv {
+	case driver.HyperKit, driver.VZ, driver.Firecracker, driver.CloudHypervisor:
+		return true
+	}
+	return false
+}
+
+// LiveGrowDisk grows the disk of the running machine of n to size MB, and returns false without error
+// if its driver can not grow it without stopping the machine
+func LiveGrowDisk(cc config.ClusterConfig, n config.Node, size int) (bool, error) {
+	if !driver.IsKVM(config.NodeDriver(cc, n)) {
+		return false, nil
+	}
+	machineName := config.MachineName(cc, n)
+	if err := virsh(cc, "blockresize", machineName, diskImage(config.NodeDriver(cc, n), machineName), fmt.Sprintf("%dM", size)); err != nil {
+		klog.Infof("the disk of %s can not be grown live: %v", machineName, err)
+		return false, nil
+	}
+	return true, updateDriverConfig(machineName, map[string]int{"DiskSize": size})
+}
+
+// GrowDiskStopped grows the disk of the stopped machine of n to size MB, its filesystem being expanded by ExpandFilesystem once started
+func GrowDiskStopped(cc config.ClusterConfig, n config.Node, size int) error {
+	drv := config.NodeDriver(cc, n)
+	machineName := config.MachineName(cc, n)
+	path := diskImage(drv, machineName)
+	switch {
+	case driver.IsQEMU(drv):
+		if err := runSnapshotCmd("qemu-img", "resize", path, fmt.Sprintf("%dM", size)); err != nil {
+			return err
+		}
+	case driver.IsHyperV(drv):
+		if err := util.PowerShell(fmt.Sprintf("Hyper-V\\Resize-VHD -Path '%s' -SizeBytes %dMB", path, size)); err != nil {
+			return err
+		}
+	case SupportsDiskResize(drv):
+		// raw disks are sparse files, growing them only moves their end
+		fi, err := os.Stat(path)
+		if err != nil {
+			return errors.Wrap(err, "stat disk")
+		}
+		if fi.Size() < int64(size)*1024*1024 {
+			if err := os.Truncate(path, int64(size)*1024*1024); err != nil {
+				return errors.Wrap(err, "grow disk")
+			}
+		}
+	default:
+		return fmt.Errorf("the %s driver can not grow disks", drv)
+	}
+	return updateDriverConfig(machineName, map[string]int{"DiskSize": size})
+}
+
+// diskImage returns the path of the image of the main disk of a machine of the driver
+func diskImage(drv string, machineName string) string {
+	switch {
+	case driver.IsQEMU(drv):
+		return filepath.Join(localpath.MachinePath(machineName), "disk.qcow2")
+	case driver.IsHyperV(drv):
+		return filepath.Join(localpath.MachinePath(machineName), "disk.vhd")
+	}
+	return pkgdrivers.GetDiskPath(&drivers.BaseDriver{MachineName: machineName, StorePath: localpath.MiniPath()})
+}
+
+// expandFilesystem grows the data partition of the guest to the end of its disk, moving the backup GPT header there first,
+// and grows its filesystem online
+const expandFilesystem = `set -e
+part=$(sudo blkid -o device -l -t LABEL=boot2docker-data)
+disk=/dev/$(basename $(readlink -f /sys/class/block/$(basename $part)/..))
+sudo sfdisk --relocate gpt-bak-std $disk
+echo ", +" | sudo sfdisk --no-reread --force -N 1 $disk
+sudo partprobe $disk
+sudo resize2fs $part`
+
+// ExpandFilesystem grows the filesystem of the data partition of a node to the size of its grown disk
+func ExpandFilesystem(r command.Runner) error {
+	_, err := r.RunCmd(exec.Command("/bin/bash", "-c", expandFilesystem))
+	return err
+}
+
+// RestartKubelet restarts the kubelet of a node, which only reads the capacity of the machine when it starts
+func RestartKubelet(r command.Runner) error {
+	return sysinit.New(r).Restart("kubelet")
+}
+
+// updateDriverConfig writes values into the driver config of the machine saved by libmachine, only setting the keys
+// it already has, so that it works whatever the driver as long as it names them like the built-in ones
+func updateDriverConfig(machineName string, values map[string]int) error {
+	path := filepath.Join(localpath.MachinePath(machineName), "config.json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "read machine config")
+	}
+	var h map[string]interface{}
+	if err := json.Unmarshal(data, &h); err != nil {
+		return errors.Wrap(err, "parse machine config")
+	}
+	d, ok := h["Driver"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s has no driver config", path)
+	}
+	// the kic driver keeps them in the config of its node
+	if nc, ok := d["NodeConfig"].(map[string]interface{}); ok {
+		d = nc
+	}
+	for key, v := range values {
+		if _, ok := d[key]; ok {
+			d[key] = v
+		}
+	}
+	data, err = json.MarshalIndent(h, "", "    ")
+	if err != nil {
+		return errors.Wrap(err, "marshal machine config")
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// virsh runs virsh on the libvirt of the cluster
+func virsh(cc config.ClusterConfig, args ...string) error {
+	uri := cc.KVMQemuURI
+	if uri == "" {
+		uri = "qemu:///system"
+	}
+	return runSnapshotCmd("virsh", append([]string{"-c", uri}, args...)...)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+func TestUpdateDriverConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		driver   string
+		expected string
+	}{
+		{"kvm2", `{"CPU": 2, "Memory": 2200, "DiskSize": 20000}`, `{"CPU": 4, "Memory": 8192, "DiskSize": 20000}`},
+		{"hyperv", `{"CPU": 2, "MemSize": 2200}`, `{"CPU": 4, "MemSize": 8192}`},
+		{"docker", `{"NodeConfig": {"CPU": 2, "Memory": 2200, "Image": "kicbase"}}`, `{"NodeConfig": {"CPU": 4, "Memory": 8192, "Image": "kicbase"}}`},
+		{"vfkit", `{"CPUs": 2, "Memory": 2200}`, `{"CPUs": 4, "Memory": 8192}`},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("MINIKUBE_HOME", t.TempDir())
+			path := filepath.Join(localpath.MachinePath("minikube"), "config.json")
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(`{"DriverName": "`+tc.name+`", "Driver": `+tc.driver+`}`), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := updateDriverConfig("minikube", resources(4, 8192)); err != nil {
+				t.Fatalf("updateDriverConfig: %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got, expected map[string]interface{}
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tc.expected), &expected); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got["Driver"], expected) {
+				t.Errorf("driver config = %v, expected %v", got["Driver"], expected)
+			}
+		})
+	}
+}
+
+func TestDiskImage(t *testing.T) {
+	t.Setenv("MINIKUBE_HOME", t.TempDir())
+	dir := localpath.MachinePath("minikube")
+	tests := []struct {
+		driver   string
+		expected string
+	}{
+		{"kvm2", "minikube.rawdisk"},
+		{"qemu2", "disk.qcow2"},
+		{"hyperv", "disk.vhd"},
+		{"vz", "minikube.rawdisk"},
+	}
+	for _, tc := range tests {
+		if got := diskImage(tc.driver, "minikube"); got != filepath.Join(dir, tc.expected) {
+			t.Errorf("diskImage(%q) = %q, expected %q", tc.driver, got, filepath.Join(dir, tc.expected))
+		}
+	}
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/images"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+)
+
+var (
+	// etcdArchive is the archive of the etcd data in the guest, on its way to or from a snapshot
+	etcdArchive = path.Join(vmpath.GuestPersistentDir, "etcd-snapshot.tar.gz")
+	// etcdRestoreDir is where the etcd data of a snapshot is extracted to before it replaces the one of the node
+	etcdRestoreDir = path.Join(vmpath.GuestPersistentDir, "etcd-restore")
+)
+
+// stopContainers stops the kubelet and all the containers of the node, so that no component runs on the replaced etcd data
+const stopContainers = `sudo systemctl stop kubelet; ids=$(sudo crictl ps -q 2>/dev/null); [ -z "$ids" ] || sudo crictl stop $ids`
+
+// SupportsDiskSnapshots returns whether the driver snapshots the disks of its machines
+func SupportsDiskSnapshots(drv string) bool {
+	return drv == driver.VirtualBox || driver.IsQEMU(drv)
+}
+
+// DiskSnapshotNeedsStop returns whether the machines of the driver are stopped to snapshot their disks
+func DiskSnapshotNeedsStop(drv string) bool {
+	// qemu-img can not write the snapshot to the disk of a running VM
+	return driver.IsQEMU(drv)
+}
+
+// TakeDiskSnapshot snapshots the disk of the machine with its driver
+func TakeDiskSnapshot(drv string, machineName string, name string) error {
+	switch {
+	case drv == driver.VirtualBox:
+		return runSnapshotCmd(driver.VBoxManagePath(), "snapshot", machineName, "take", name, "--live")
+	case driver.IsQEMU(drv):
+		return runSnapshotCmd("qemu-img", "snapshot", "-c", name, qemuDiskPath(machineName))
+	}
+	return fmt.Errorf("the %s driver does not snapshot disks", drv)
+}
+
+// RestoreDiskSnapshot reverts the disk of the stopped machine to the snapshot
+func RestoreDiskSnapshot(drv string, machineName string, name string) error {
+	switch {
+	case drv == driver.VirtualBox:
+		return runSnapshotCmd(driver.VBoxManagePath(), "snapshot", machineName, "restore", name)
+	case driver.IsQEMU(drv):
+		return runSnapshotCmd("qemu-img", "snapshot", "-a", name, qemuDiskPath(machineName))
+	}
+	return fmt.Errorf("the %s driver does not snapshot disks", drv)
+}
+
+// DeleteDiskSnapshot deletes the snapshot of the disk of the machine
+func DeleteDiskSnapshot(drv string, machineName string, name string) error {
+	switch {
+	case drv == driver.VirtualBox:
+		return runSnapshotCmd(driver.VBoxManagePath(), "snapshot", machineName, "delete", name)
+	case driver.IsQEMU(drv):
+		return runSnapshotCmd("qemu-img", "snapshot", "-d", name, qemuDiskPath(machineName))
+	}
+	return fmt.Errorf("the %s driver does not snapshot disks", drv)
+}
+
+// qemuDiskPath returns the disk of a machine of the qemu2 driver
+func qemuDiskPath(machineName string) string {
+	return filepath.Join(localpath.MachinePath(machineName), "disk.qcow2")
+}
+
+func runSnapshotCmd(name string, args ...string) error {
+	klog.Infof("Run: %s %s", name, strings.Join(args, " "))
+	if output, err := exec.Command(name, args...).CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "%s %s: %s", name, strings.Join(args, " "), output)
+	}
+	return nil
+}
+
+// StopEtcd stops the kubelet and the etcd container of a control plane, so that its data can be copied
+func StopEtcd(r command.Runner) error {
+	_, err := r.RunCmd(exec.Command("/bin/bash", "-c", stopEtcd))
+	return err
+}
+
+// StopContainers stops the kubelet and all the containers of a node, before its etcd data is replaced
+func StopContainers(r command.Runner) error {
+	_, err := r.RunCmd(exec.Command("/bin/bash", "-c", stopContainers))
+	return err
+}
+
+// StartKubelet starts the kubelet of a node again, which restarts its containers
+func StartKubelet(r command.Runner) error {
+	_, err := r.RunCmd(exec.Command("sudo", "systemctl", "start", "kubelet"))
+	return err
+}
+
+// SaveEtcd copies the etcd data of a control plane stopped by StopEtcd to dst on the host
+func SaveEtcd(r command.Runner, dst string) error {
+	script := fmt.Sprintf("sudo tar -C %s -czf %s member", etcdDataDir, etcdArchive)
+	if _, err := r.RunCmd(exec.Command("/bin/bash", "-c", script)); err != nil {
+		return errors.Wrap(err, "archive etcd data")
+	}
+	defer func() {
+		if _, err := r.RunCmd(exec.Command("sudo", "rm", "-f", etcdArchive)); err != nil {
+			klog.Warningf("removing %s: %v", etcdArchive, err)
+		}
+	}()
+
+	if err := os.WriteFile(dst, nil, 0644); err != nil {
+		return errors.Wrap(err, "create archive")
+	}
+	f, err := assets.NewFileAsset(dst, path.Dir(etcdArchive), path.Base(etcdArchive), "0644")
+	if err != nil {
+		return errors.Wrap(err, "file asset")
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			klog.Warningf("error closing the file %s: %v", f.GetSourcePath(), err)
+		}
+	}()
+	return r.CopyFrom(f)
+}
+
+// RestoreEtcd replaces the etcd data of a control plane stopped by StopContainers with the one saved by SaveEtcd in src,
+// keeping the replaced data aside
+func RestoreEtcd(r command.Runner, src string) error {
+	f, err := assets.NewFileAsset(src, path.Dir(etcdArchive), path.Base(etcdArchive), "0644")
+	if err != nil {
+		return errors.Wrap(err, "file asset")
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			klog.Warningf("error closing the file %s: %v", f.GetSourcePath(), err)
+		}
+	}()
+	if err := r.Copy(f); err != nil {
+		return errors.Wrap(err, "copy etcd data")
+	}
+	script := fmt.Sprintf("sudo rm -rf %[1]s && sudo mkdir -p %[1]s && sudo tar -C %[1]s -xzf %[2]s && sudo rm -f %[2]s", etcdRestoreDir, etcdArchive)
+	if _, err := r.RunCmd(exec.Command("/bin/bash", "-c", script)); err != nil {
+		return errors.Wrap(err, "extract etcd data")
+	}
+	defer func() {
+		if _, err := r.RunCmd(exec.Command("sudo", "rm", "-rf", etcdRestoreDir)); err != nil {
+			klog.Warningf("removing %s: %v", etcdRestoreDir, err)
+		}
+	}()
+	return replaceEtcdData(r, path.Join(etcdRestoreDir, "member"), "replaced")
+}
+
+// ExportImages saves the images of a node into dir, leaving out the images of Kubernetes and the ones already in dir,
+// and returns the names of the images of the node
+func ExportImages(cc *config.ClusterConfig, r command.Runner, dir string) ([]string, error) {
+	cr, err := cruntime.New(cruntime.Config{Type: cc.KubernetesConfig.ContainerRuntime, Runner: r})
+	if err != nil {
+		return nil, errors.Wrap(err, "runtime")
+	}
+	list, err := cr.ListImages(cruntime.ListImagesOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "list images")
+	}
+	system, err := images.Kubeadm(cc.KubernetesConfig.ImageRepository, cc.KubernetesConfig.KubernetesVersion)
+	if err != nil {
+		return nil, errors.Wrap(err, "kubeadm images")
+	}
+	skip := map[string]bool{}
+	for _, img := range system {
+		skip[img] = true
+	}
+
+	var names, missing []string
+	for _, img := range list {
+		for _, tag := range img.RepoTags {
+			if skip[tag] || strings.Contains(tag, "<none>") {
+				continue
+			}
+			names = append(names, tag)
+			if _, err := os.Stat(localpath.SanitizeCacheDir(filepath.Join(dir, tag))); err != nil {
+				missing = append(missing, tag)
+			}
+		}
+	}
+	sort.Strings(names)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "mkdir")
+	}
+	if err := SaveCachedImages(cc, r, missing, dir); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// ImportImages loads the images exported by ExportImages from dir into a node, unless the node still has them
+func ImportImages(cc *config.ClusterConfig, r command.Runner, imgs []string, dir string) error {
+	return LoadCachedImages(cc, r, imgs, dir, false)
+}
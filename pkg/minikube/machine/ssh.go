@@ -26,6 +26,7 @@ import (
 	"github.com/docker/machine/libmachine/state"
 	"github.com/pkg/errors"
 	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/sshutil"
 )
 
 // GetHost find node's host information by name in the given cluster.
@@ -55,6 +56,11 @@ func CreateSSHShell(api libmachine.API, cc config.ClusterConfig, n config.Node,
 		return err
 	}
 
+	// the native client of libmachine dials the machine directly, it cannot go through a proxy
+	if native && sshutil.ProxyEnabled() {
+		return sshutil.Shell(host.Driver, args...)
+	}
+
 	if native {
 		ssh.SetDefaultClient(ssh.Native)
 	} else {
@@ -66,6 +72,9 @@ func CreateSSHShell(api libmachine.API, cc config.ClusterConfig, n config.Node,
 	if err != nil {
 		return errors.Wrap(err, "Creating ssh client")
 	}
+	if ec, ok := client.(*ssh.ExternalClient); ok {
+		ec.BaseArgs = append(sshutil.ExternalArgs(), ec.BaseArgs...)
+	}
 	return client.Shell(args...)
 }
 
@@ -72,9 +72,10 @@ var requiredDirectories = []string{
 // StartHost starts a host VM.
 func StartHost(api libmachine.API, cfg *config.ClusterConfig, n *config.Node) (*host.Host, bool, error) {
 	machineName := config.MachineName(*cfg, *n)
+	drv := config.NodeDriver(*cfg, *n)
 
 	// Prevent machine-driver boot races, as well as our own certificate race
-	releaser, err := acquireMachinesLock(machineName, cfg.Driver)
+	releaser, err := acquireMachinesLock(machineName, drv)
 	if err != nil {
 		return nil, false, errors.Wrap(err, "boot lock")
 	}
@@ -99,7 +100,7 @@ func StartHost(api libmachine.API, cfg *config.ClusterConfig, n *config.Node) (*
 	if err != nil {
 		return h, exists, err
 	}
-	return h, exists, ensureSyncedGuestClock(h, cfg.Driver)
+	return h, exists, ensureSyncedGuestClock(h, drv)
 }
 
 // engineOptions returns docker engine options for the dockerd running inside minikube
@@ -122,21 +123,25 @@ func engineOptions(cfg config.ClusterConfig) *engine.Options {
 }
 
 func createHost(api libmachine.API, cfg *config.ClusterConfig, n *config.Node) (*host.Host, error) {
-	klog.Infof("createHost starting for %q (driver=%q)", n.Name, cfg.Driver)
+	drv := config.NodeDriver(*cfg, *n)
+	klog.Infof("createHost starting for %q (driver=%q)", n.Name, drv)
 	start := time.Now()
 	defer func() {
 		klog.Infof("duration metric: createHost completed in %s", time.Since(start))
 	}()
 
-	if cfg.Driver != driver.SSH {
-		showHostInfo(nil, *cfg)
+	// the machines of a node pool have the resources of the pool
+	pcfg := config.WithNodePool(*cfg, *n)
+	pcfg.Driver = drv
+	if drv != driver.SSH {
+		showHostInfo(nil, pcfg)
 	}
 
-	def := registry.Driver(cfg.Driver)
+	def := registry.Driver(drv)
 	if def.Empty() {
-		return nil, fmt.Errorf("unsupported/missing driver: %s", cfg.Driver)
+		return nil, fmt.Errorf("unsupported/missing driver: %s", drv)
 	}
-	dd, err := def.Config(*cfg, *n)
+	dd, err := def.Config(pcfg, *n)
 	if err != nil {
 		return nil, errors.Wrap(err, "config")
 	}
@@ -145,18 +150,18 @@ func createHost(api libmachine.API, cfg *config.ClusterConfig, n *config.Node) (
 		return nil, errors.Wrap(err, "marshal")
 	}
 
-	h, err := api.NewHost(cfg.Driver, data)
+	h, err := api.NewHost(drv, data)
 	if err != nil {
 		return nil, errors.Wrap(err, "new host")
 	}
-	defer postStartValidations(h, cfg.Driver)
+	defer postStartValidations(h, drv)
 
 	h.HostOptions.AuthOptions.CertDir = localpath.MiniPath()
 	h.HostOptions.AuthOptions.StorePath = localpath.MiniPath()
 	h.HostOptions.EngineOptions = engineOptions(*cfg)
 
 	cstart := time.Now()
-	klog.Infof("libmachine.API.Create for %q (driver=%q)", cfg.Name, cfg.Driver)
+	klog.Infof("libmachine.API.Create for %q (driver=%q)", cfg.Name, drv)
 
 	if cfg.StartHostTimeout == 0 {
 		cfg.StartHostTimeout = 6 * time.Minute
@@ -165,10 +170,21 @@ func createHost(api libmachine.API, cfg *config.ClusterConfig, n *config.Node) (
 		return nil, errors.Wrap(err, "creating host")
 	}
 	klog.Infof("duration metric: libmachine.API.Create for %q took %s", cfg.Name, time.Since(cstart))
-	if cfg.Driver == driver.SSH {
+	if drv == driver.SSH {
 		showHostInfo(h, *cfg)
 	}
 
+	moved, err := ensureStaticIP(h, *cfg, *n)
+	if err != nil {
+		return h, errors.Wrap(err, "static IP")
+	}
+	// the certificates of the engine were generated for the previous IP
+	if moved {
+		if err := provisionDockerMachine(h); err != nil {
+			return h, errors.Wrap(err, "provision")
+		}
+	}
+
 	if err := postStartSetup(h, *cfg); err != nil {
 		return h, errors.Wrap(err, "post-start")
 	}
@@ -341,10 +357,19 @@ func postStartSetup(h *host.Host, mc config.ClusterConfig) error {
 	if driver.BareMetal(mc.Driver) {
 		showLocalOsRelease()
 	}
-	if driver.IsVM(mc.Driver) || driver.IsKIC(mc.Driver) || driver.IsSSH(mc.Driver) {
+	if driver.IsVM(mc.Driver) || driver.IsKIC(mc.Driver) || driver.IsLXD(mc.Driver) || driver.IsWSL(mc.Driver) || driver.IsSSH(mc.Driver) {
 		logRemoteOsRelease(r)
 	}
-	return syncLocalAssets(r)
+	if err := provisionGuest(r, mc); err != nil {
+		return err
+	}
+	// as cloud-init would, a failing user-data does not fail the boot
+	if mc.UserData != "" && driver.IsVM(mc.Driver) {
+		if err := applyUserData(r, mc.UserData); err != nil {
+			out.WarningT("Unable to apply the user-data {{.file}} to {{.name}}: {{.error}}", out.V{"file": mc.UserData, "name": h.Name, "error": err})
+		}
+	}
+	return nil
 }
 
 // acquireMachinesLock protects against code that is not parallel-safe (libmachine, cert setup)
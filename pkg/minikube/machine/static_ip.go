@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/docker/machine/libmachine/host"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/network"
+	"k8s.io/minikube/pkg/util/retry"
+)
+
+const (
+	// staticNetworkFile sorts before the networkd configs of the ISO, so it wins for its interface
+	staticNetworkFile = "05-minikube-static.network"
+	// persistentNetworkDir is copied to /etc/systemd/network by minikube-automount on boot
+	persistentNetworkDir = "/var/lib/boot2docker/etc/systemd/network"
+)
+
+// guestInterface is the network interface of the guest holding the IP of the node
+type guestInterface struct {
+	Name    string
+	MAC     string
+	Subnet  *net.IPNet
+	Gateway string
+}
+
+// configuresStaticIPInGuest returns whether the static IP is configured in the guest, as the network of the driver
+// has no DHCP reservations minikube can manage. KVM reserves the IP in its libvirt network instead.
+// The IP of a bridged network belongs to the network of the host interface, and is not managed by minikube.
+func configuresStaticIPInGuest(cc config.ClusterConfig) bool {
+	_, bridged := network.ParseBridged(cc.Network)
+	return cc.Driver == driver.HyperV || (driver.IsQEMU(cc.Driver) && !network.IsBuiltinQEMU(cc.Network) && !bridged)
+}
+
+// ensureStaticIP moves the primary control plane to the static IP of the cluster, if the guest is not on it yet.
+// The config is persisted in the guest, so it comes up with the static IP on the next boots.
+// Returns whether the IP changed, in which case the machine needs provisioning again.
+func ensureStaticIP(h *host.Host, cc config.ClusterConfig, n config.Node) (bool, error) {
+	if cc.StaticIP == "" || !configuresStaticIPInGuest(cc) || !config.IsPrimaryControlPlane(cc, n) {
+		return false, nil
+	}
+	ip, err := h.Driver.GetIP()
+	if err != nil {
+		return false, errors.Wrap(err, "getting IP")
+	}
+	if ip == cc.StaticIP {
+		return false, nil
+	}
+
+	r, err := CommandRunner(h)
+	if err != nil {
+		return false, errors.Wrap(err, "command runner")
+	}
+	iface, err := findGuestInterface(r, ip)
+	if err != nil {
+		return false, err
+	}
+	if !iface.Subnet.Contains(net.ParseIP(cc.StaticIP)) {
+		return false, fmt.Errorf("static IP %s is not in the subnet %s of the VM network", cc.StaticIP, iface.Subnet)
+	}
+
+	out.Step(style.Waiting, "Moving the VM from {{.ip}} to the static IP {{.static_ip}} ...", out.V{"ip": ip, "static_ip": cc.StaticIP})
+	conf := assets.NewMemoryAssetTarget([]byte(staticNetworkConfig(iface, cc.StaticIP)), path.Join(persistentNetworkDir, staticNetworkFile), "0644")
+	if err := r.Copy(conf); err != nil {
+		return false, errors.Wrap(err, "copying static network config")
+	}
+	// the ssh connection drops when the IP changes, so reconfigure the interface once the command returned
+	script := fmt.Sprintf("cp %s /etc/systemd/network/ && networkctl reload && networkctl reconfigure %s", conf.GetTargetPath(), iface.Name)
+	if _, err := r.RunCmd(exec.Command("sudo", "systemd-run", "--on-active=2", "/bin/sh", "-c", script)); err != nil {
+		return false, errors.Wrap(err, "reconfiguring network")
+	}
+
+	moved := func() error {
+		ip, err := h.Driver.GetIP()
+		if err != nil {
+			return err
+		}
+		if ip != cc.StaticIP {
+			return fmt.Errorf("VM is on %s", ip)
+		}
+		return nil
+	}
+	if err := retry.Local(moved, 2*time.Minute); err != nil {
+		return false, errors.Wrapf(err, "waiting for the static IP %s", cc.StaticIP)
+	}
+	if err := drivers.WaitForSSH(h.Driver); err != nil {
+		return false, errors.Wrap(err, "waiting for SSH on the static IP")
+	}
+	klog.Infof("VM moved to static IP %s", cc.StaticIP)
+	return true, nil
+}
+
+// findGuestInterface finds the interface of the guest holding ip, with its subnet and default gateway
+func findGuestInterface(r command.Runner, ip string) (guestInterface, error) {
+	rr, err := r.RunCmd(exec.Command("ip", "-o", "-4", "addr", "show"))
+	if err != nil {
+		return guestInterface{}, errors.Wrap(err, "listing addresses")
+	}
+	iface, err := parseIPAddr(rr.Stdout.String(), ip)
+	if err != nil {
+		return iface, err
+	}
+	rr, err = r.RunCmd(exec.Command("cat", fmt.Sprintf("/sys/class/net/%s/address", iface.Name)))
+	if err != nil {
+		return iface, errors.Wrapf(err, "getting MAC address of %s", iface.Name)
+	}
+	iface.MAC = strings.TrimSpace(rr.Stdout.String())
+	rr, err = r.RunCmd(exec.Command("ip", "-4", "route", "show", "default", "dev", iface.Name))
+	if err != nil {
+		return iface, errors.Wrap(err, "getting default route")
+	}
+	iface.Gateway = parseDefaultGateway(rr.Stdout.String())
+	return iface, nil
+}
+
+// parseIPAddr finds the interface holding ip in the output of 'ip -o -4 addr show':
+//
+//	2: eth0    inet 192.168.105.5/24 brd 192.168.105.255 scope global dynamic eth0\       valid_lft 85829sec preferred_lft 85829sec
+func parseIPAddr(output, ip string) (guestInterface, error) {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[2] != "inet" {
+			continue
+		}
+		addr, subnet, err := net.ParseCIDR(fields[3])
+		if err != nil || addr.String() != ip {
+			continue
+		}
+		return guestInterface{Name: fields[1], Subnet: subnet}, nil
+	}
+	return guestInterface{}, fmt.Errorf("no interface of the VM holds %s", ip)
+}
+
+// parseDefaultGateway parses the output of 'ip -4 route show default': default via 192.168.105.1 proto dhcp metric 1024
+func parseDefaultGateway(output string) string {
+	fields := strings.Fields(output)
+	for i := 0; i < len(fields)-1; i++ {
+		if fields[i] == "via" {
+			return fields[i+1]
+		}
+	}
+	return ""
+}
+
+// staticNetworkConfig is the systemd-networkd config moving the interface to the static IP.
+// The gateway of the network is its DNS server, as with the DHCP servers of Hyper-V and vmnet.
+func staticNetworkConfig(iface guestInterface, staticIP string) string {
+	prefix, _ := iface.Subnet.Mask.Size()
+	conf := fmt.Sprintf("[Match]\nMACAddress=%s\n\n[Network]\nAddress=%s/%d\nLinkLocalAddressing=no\n", iface.MAC, staticIP, prefix)
+	if iface.Gateway != "" {
+		conf += fmt.Sprintf("Gateway=%s\nDNS=%s\n", iface.Gateway, iface.Gateway)
+	}
+	return conf
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+const ipAddr = `1: lo    inet 127.0.0.1/8 scope host lo\       valid_lft forever preferred_lft forever
+2: eth0    inet 192.168.105.5/24 metric 1024 brd 192.168.105.255 scope global dynamic eth0\       valid_lft 85829sec preferred_lft 85829sec
+3: docker0    inet 172.17.0.1/16 brd 172.17.255.255 scope global docker0\       valid_lft forever preferred_lft forever
+`
+
+func TestStaticNetworkConfig(t *testing.T) {
+	iface, err := parseIPAddr(ipAddr, "192.168.105.5")
+	if err != nil {
+		t.Fatalf("parseIPAddr: %v", err)
+	}
+	if iface.Name != "eth0" || iface.Subnet.String() != "192.168.105.0/24" {
+		t.Errorf("parseIPAddr() = %s %s, want eth0 192.168.105.0/24", iface.Name, iface.Subnet)
+	}
+	if _, err := parseIPAddr(ipAddr, "192.168.105.6"); err == nil {
+		t.Errorf("parseIPAddr succeeded for an IP no interface holds")
+	}
+
+	iface.MAC = "52:54:00:12:34:56"
+	iface.Gateway = parseDefaultGateway("default via 192.168.105.1 proto dhcp src 192.168.105.5 metric 1024\n")
+	want := `[Match]
+MACAddress=52:54:00:12:34:56
+
+[Network]
+Address=192.168.105.50/24
+LinkLocalAddressing=no
+Gateway=192.168.105.1
+DNS=192.168.105.1
+`
+	if got := staticNetworkConfig(iface, "192.168.105.50"); got != want {
+		t.Errorf("staticNetworkConfig() = %q, want %q", got, want)
+	}
+}
+
+func TestConfiguresStaticIPInGuest(t *testing.T) {
+	tests := []struct {
+		driver  string
+		network string
+		want    bool
+	}{
+		{"hyperv", "", true},
+		{"qemu2", "socket_vmnet", true},
+		{"qemu2", "builtin", false},
+		{"kvm2", "", false},
+		{"docker", "", false},
+	}
+	for _, tc := range tests {
+		cc := config.ClusterConfig{Driver: tc.driver, Network: tc.network}
+		if got := configuresStaticIPInGuest(cc); got != tc.want {
+			t.Errorf("configuresStaticIPInGuest(%s, %s) = %v, want %v", tc.driver, tc.network, got, tc.want)
+		}
+	}
+}
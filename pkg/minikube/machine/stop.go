@@ -43,6 +43,9 @@ func StopHost(api libmachine.API, machineName string) error {
 	}
 
 	out.Step(style.Stopping, `Stopping node "{{.name}}"  ...`, out.V{"name": machineName})
+	if s, err := h.Driver.GetState(); err == nil && s == state.Running {
+		saveCleanStop(h)
+	}
 	return stop(h)
 }
 
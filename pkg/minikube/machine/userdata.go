@@ -0,0 +1,345 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+)
+
+const (
+	// userDataScript is where the script applying the user-data is copied in the guest
+	userDataScript = "/var/tmp/minikube/user-data.sh"
+	// userDataDone marks the machines which ran the runcmd of their user-data, it is on the persistent disk of the ISO
+	userDataDone = vmpath.GuestPersistentDir + "/user-data.done"
+)
+
+// cloudConfig is the subset of the cloud-config format of cloud-init which minikube applies itself, as the ISO has no cloud-init
+type cloudConfig struct {
+	Bootcmd    []userDataCmd     `json:"bootcmd"`
+	WriteFiles []writeFile       `json:"write_files"`
+	Groups     []json.RawMessage `json:"groups"`
+	Users      []json.RawMessage `json:"users"`
+	Runcmd     []userDataCmd     `json:"runcmd"`
+}
+
+// userDataCmd is a command of bootcmd or runcmd: a string run by sh, or a list of arguments
+type userDataCmd struct {
+	shell string
+	args  []string
+}
+
+func (c *userDataCmd) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &c.shell); err == nil {
+		return nil
+	}
+	return json.Unmarshal(b, &c.args)
+}
+
+func (c userDataCmd) String() string {
+	if c.args != nil {
+		return shellquote.Join(c.args...)
+	}
+	return shellquote.Join("sh", "-c", c.shell)
+}
+
+type writeFile struct {
+	Path        string `json:"path"`
+	Content     string `json:"content"`
+	Encoding    string `json:"encoding"`
+	Permissions string `json:"permissions"`
+	Owner       string `json:"owner"`
+	Append      bool   `json:"append"`
+}
+
+type user struct {
+	Name              string          `json:"name"`
+	Gecos             string          `json:"gecos"`
+	Shell             string          `json:"shell"`
+	Groups            json.RawMessage `json:"groups"`
+	Sudo              json.RawMessage `json:"sudo"`
+	SSHAuthorizedKeys []string        `json:"ssh_authorized_keys"`
+}
+
+// cloudConfigKeys are the keys of cloud-config applied by minikube
+var cloudConfigKeys = map[string]bool{"bootcmd": true, "write_files": true, "groups": true, "users": true, "runcmd": true}
+
+// ValidateUserData checks that the file p is user-data minikube applies: a #cloud-config or a script
+func ValidateUserData(p string) error {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return errors.Wrap(err, "read the user-data")
+	}
+	_, err = cloudConfigScript(b)
+	return err
+}
+
+// cloudConfigScript returns the script applying the #cloud-config b on each boot, as the root filesystem of the ISO is in memory,
+// but its runcmd on the first boot only. It is empty for a script, and fails on the keys of cloud-config minikube does not apply
+func cloudConfigScript(b []byte) (string, error) {
+	if bytes.HasPrefix(b, []byte("#!")) {
+		return "", nil
+	}
+	if !bytes.HasPrefix(b, []byte("#cloud-config")) {
+		return "", errors.New("the user-data is neither a #cloud-config nor a script starting with #!")
+	}
+	var keys map[string]interface{}
+	if err := yaml.Unmarshal(b, &keys); err != nil {
+		return "", errors.Wrap(err, "parse the cloud-config")
+	}
+	if _, ok := keys["packages"]; ok {
+		return "", errors.New("the packages key of cloud-config is not supported, as the minikube ISO has no package manager: install them from a custom ISO, or copy static binaries with write_files")
+	}
+	var unsupported []string
+	for k := range keys {
+		if !cloudConfigKeys[k] {
+			unsupported = append(unsupported, k)
+		}
+	}
+	if len(unsupported) > 0 {
+		sort.Strings(unsupported)
+		return "", errors.Errorf("the keys %s of cloud-config are not supported, minikube only applies bootcmd, write_files, groups, users and runcmd", strings.Join(unsupported, ", "))
+	}
+	var cc cloudConfig
+	if err := yaml.Unmarshal(b, &cc); err != nil {
+		return "", errors.Wrap(err, "parse the cloud-config")
+	}
+
+	var s strings.Builder
+	s.WriteString("set -e\n")
+	for _, c := range cc.Bootcmd {
+		s.WriteString(c.String() + "\n")
+	}
+	sysctl := false
+	for _, f := range cc.WriteFiles {
+		if err := writeFileScript(&s, f); err != nil {
+			return "", err
+		}
+		sysctl = sysctl || f.Path == "/etc/sysctl.conf" || strings.HasPrefix(f.Path, "/etc/sysctl.d/")
+	}
+	if sysctl {
+		s.WriteString("for f in /etc/sysctl.conf /etc/sysctl.d/*.conf; do if [ -e \"$f\" ]; then sysctl -p \"$f\" >/dev/null; fi; done\n")
+	}
+	// the members of the groups are added once the users exist
+	var members strings.Builder
+	for _, g := range cc.Groups {
+		if err := groupScript(&s, &members, g); err != nil {
+			return "", err
+		}
+	}
+	for _, u := range cc.Users {
+		if err := userScript(&s, u); err != nil {
+			return "", err
+		}
+	}
+	s.WriteString(members.String())
+	if len(cc.Runcmd) > 0 {
+		fmt.Fprintf(&s, "if [ ! -e %s ]; then\n", userDataDone)
+		for _, c := range cc.Runcmd {
+			s.WriteString("  " + c.String() + "\n")
+		}
+		fmt.Fprintf(&s, "  touch %s\nfi\n", userDataDone)
+	}
+	return s.String(), nil
+}
+
+func writeFileScript(s *strings.Builder, f writeFile) error {
+	if !path.IsAbs(f.Path) {
+		return errors.Errorf("the path %q of write_files is not absolute", f.Path)
+	}
+	content := []byte(f.Content)
+	switch f.Encoding {
+	case "", "text/plain":
+	case "b64", "base64":
+		b, err := base64.StdEncoding.DecodeString(f.Content)
+		if err != nil {
+			return errors.Wrapf(err, "decode %s", f.Path)
+		}
+		content = b
+	default:
+		return errors.Errorf("the encoding %q of %s is not supported, expected b64 or text/plain", f.Encoding, f.Path)
+	}
+	redirect := ">"
+	if f.Append {
+		redirect = ">>"
+	}
+	perms := f.Permissions
+	if perms == "" {
+		perms = "0644"
+	}
+	owner := f.Owner
+	if owner == "" {
+		owner = "root:root"
+	}
+	p := shellquote.Join(f.Path)
+	fmt.Fprintf(s, "mkdir -p %s\n", shellquote.Join(path.Dir(f.Path)))
+	fmt.Fprintf(s, "echo %s | base64 -d %s %s\n", base64.StdEncoding.EncodeToString(content), redirect, p)
+	fmt.Fprintf(s, "chmod %s %s\nchown %s %s\n", shellquote.Join(perms), p, shellquote.Join(owner), p)
+	return nil
+}
+
+// groupScript adds the group of groups, a name or a map of its name to its members, which are added by members
+func groupScript(s *strings.Builder, members *strings.Builder, raw json.RawMessage) error {
+	var name string
+	if json.Unmarshal(raw, &name) == nil {
+		fmt.Fprintf(s, "grep -q %s /etc/group || addgroup %s\n", shellquote.Join("^"+name+":"), shellquote.Join(name))
+		return nil
+	}
+	var groups map[string][]string
+	if err := json.Unmarshal(raw, &groups); err != nil {
+		return errors.Wrap(err, "groups")
+	}
+	names := []string{}
+	for g := range groups {
+		names = append(names, g)
+	}
+	sort.Strings(names)
+	for _, g := range names {
+		fmt.Fprintf(s, "grep -q %s /etc/group || addgroup %s\n", shellquote.Join("^"+g+":"), shellquote.Join(g))
+		for _, m := range groups[g] {
+			fmt.Fprintf(members, "addgroup %s %s 2>/dev/null || true\n", shellquote.Join(m), shellquote.Join(g))
+		}
+	}
+	return nil
+}
+
+// userScript adds the user of users, with busybox as the ISO has no shadow utilities. The default user is docker
+func userScript(s *strings.Builder, raw json.RawMessage) error {
+	var u user
+	if json.Unmarshal(raw, &u.Name) != nil {
+		if err := json.Unmarshal(raw, &u); err != nil {
+			return errors.Wrap(err, "users")
+		}
+	}
+	if u.Name == "" {
+		return errors.New("a user of users has no name")
+	}
+	if u.Name == "default" {
+		return nil
+	}
+	name := shellquote.Join(u.Name)
+	adduser := []string{"adduser", "-D"}
+	if u.Shell != "" {
+		adduser = append(adduser, "-s", u.Shell)
+	}
+	if u.Gecos != "" {
+		adduser = append(adduser, "-g", u.Gecos)
+	}
+	fmt.Fprintf(s, "id %s >/dev/null 2>&1 || %s %s\n", name, shellquote.Join(adduser...), name)
+
+	groups, err := stringOrList(u.Groups, ",")
+	if err != nil {
+		return errors.Wrapf(err, "groups of %s", u.Name)
+	}
+	for _, g := range groups {
+		fmt.Fprintf(s, "grep -q %s /etc/group || addgroup %s\naddgroup %s %s 2>/dev/null || true\n", shellquote.Join("^"+g+":"), shellquote.Join(g), name, shellquote.Join(g))
+	}
+
+	var noSudo bool
+	rules := []string{}
+	if json.Unmarshal(u.Sudo, &noSudo) != nil {
+		if rules, err = stringOrList(u.Sudo, ""); err != nil {
+			return errors.Wrapf(err, "sudo of %s", u.Name)
+		}
+	}
+	if len(rules) > 0 {
+		var r strings.Builder
+		for _, rule := range rules {
+			fmt.Fprintf(&r, "%s %s\n", u.Name, rule)
+		}
+		sudoers := "/etc/sudoers.d/90-user-data-" + u.Name
+		fmt.Fprintf(s, "mkdir -p /etc/sudoers.d\necho %s | base64 -d > %s\nchmod 0440 %s\n", base64.StdEncoding.EncodeToString([]byte(r.String())), shellquote.Join(sudoers), shellquote.Join(sudoers))
+	}
+
+	if len(u.SSHAuthorizedKeys) > 0 {
+		keys := strings.Join(u.SSHAuthorizedKeys, "\n") + "\n"
+		fmt.Fprintf(s, "home=$(awk -F: '$1==\"%s\" {print $6}' /etc/passwd)\n", u.Name)
+		s.WriteString("mkdir -p \"$home/.ssh\"\n")
+		fmt.Fprintf(s, "echo %s | base64 -d > \"$home/.ssh/authorized_keys\"\n", base64.StdEncoding.EncodeToString([]byte(keys)))
+		fmt.Fprintf(s, "chmod 0700 \"$home/.ssh\"\nchmod 0600 \"$home/.ssh/authorized_keys\"\nchown -R %s \"$home/.ssh\"\n", name)
+	}
+	return nil
+}
+
+// stringOrList returns the values of raw, a list or a string split by sep
+func stringOrList(raw json.RawMessage, sep string) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var v string
+	if json.Unmarshal(raw, &v) == nil {
+		if sep == "" {
+			return []string{v}, nil
+		}
+		var values []string
+		for _, s := range strings.Split(v, sep) {
+			if s = strings.TrimSpace(s); s != "" {
+				values = append(values, s)
+			}
+		}
+		return values, nil
+	}
+	var values []string
+	err := json.Unmarshal(raw, &values)
+	return values, err
+}
+
+// applyUserData applies the user-data file p to the guest of r: a #cloud-config on each boot but its runcmd,
+// which runs on the first boot only as does a script
+func applyUserData(r command.Runner, p string) error {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return errors.Wrap(err, "read the user-data")
+	}
+	script, err := cloudConfigScript(b)
+	if err != nil {
+		return err
+	}
+	if bytes.HasPrefix(b, []byte("#!")) {
+		script = fmt.Sprintf("if [ ! -e %s ]; then\n  %s.user\n  touch %s\nfi\n", userDataDone, userDataScript, userDataDone)
+		f := assets.NewMemoryAssetTarget(b, userDataScript+".user", "0755")
+		if err := r.Copy(f); err != nil {
+			return errors.Wrap(err, "copy the user-data")
+		}
+	}
+	f := assets.NewMemoryAssetTarget([]byte(script), userDataScript, "0755")
+	if err := r.Copy(f); err != nil {
+		return errors.Wrap(err, "copy the user-data")
+	}
+	rr, err := r.RunCmd(exec.Command("sudo", "/bin/bash", userDataScript))
+	if err != nil {
+		return errors.Wrap(err, "apply the user-data")
+	}
+	klog.Infof("applied the user-data %s: %s", p, rr.Output())
+	return nil
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package machine
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestCloudConfigScript(t *testing.T) {
+	userData := `#cloud-config
+bootcmd:
+  - echo boot > /tmp/boot
+write_files:
+  - path: /etc/sysctl.d/90-inotify.conf
+    content: fs.inotify.max_user_watches=524288
+users:
+  - default
+  - name: dev
+    groups: wheel, docker
+    sudo: ALL=(ALL) NOPASSWD:ALL
+    ssh_authorized_keys: [ssh-ed25519 AAAA dev@host]
+runcmd:
+  - [touch, /var/lib/minikube/ran]
+`
+	script, err := cloudConfigScript([]byte(userData))
+	if err != nil {
+		t.Fatalf("cloudConfigScript: %v", err)
+	}
+	for _, want := range []string{
+		"sh -c 'echo boot > /tmp/boot'\n",
+		"echo " + base64.StdEncoding.EncodeToString([]byte("fs.inotify.max_user_watches=524288")) + " | base64 -d > /etc/sysctl.d/90-inotify.conf\n",
+		"sysctl -p \"$f\"",
+		"id dev >/dev/null 2>&1 || adduser -D dev\n",
+		"addgroup dev docker",
+		"/etc/sudoers.d/90-user-data-dev",
+		"authorized_keys",
+		"if [ ! -e " + userDataDone + " ]; then\n  touch /var/lib/minikube/ran\n",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("the script does not contain %q:\n%s", want, script)
+		}
+	}
+	if strings.Contains(script, "adduser -D default") {
+		t.Errorf("the script adds the default user:\n%s", script)
+	}
+
+	for _, invalid := range []string{
+		"users: [dev]",
+		"#cloud-config\nwrite_files: [{path: relative, content: x}]",
+		"#cloud-config\nwrite_files: [{path: /etc/x, content: x, encoding: gzip}]",
+		"#cloud-config\npackages: [htop]",
+		"#cloud-config\nntp: {enabled: true}",
+	} {
+		if _, err := cloudConfigScript([]byte(invalid)); err == nil {
+			t.Errorf("cloudConfigScript(%q) did not fail", invalid)
+		}
+	}
+}
@@ -0,0 +1,490 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mdns advertises the load balancers and ingresses of a cluster on the local network with multicast DNS (RFC 6762)
+package mdns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/out"
+)
+
+const (
+	// Port is the port of multicast DNS
+	Port = 5353
+	// ttl is the TTL of the RFC 6762 for the host names
+	ttl = 120
+	// legacyTTL is the TTL of the answers to the resolvers which are not mDNS aware
+	legacyTTL = 10
+	// cacheFlush is the bit of the class telling the record replaces the cached ones, or asking for a unicast answer in a question
+	cacheFlush = 1 << 15
+)
+
+// group is the IPv4 multicast group of mDNS
+var group = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: Port}
+
+// Responder answers the mDNS queries for the names of the load balancers and ingresses of a cluster
+type Responder struct {
+	address net.IP
+	lan     []*net.IPNet
+
+	mu      sync.RWMutex
+	entries map[string]entry
+	conn    *net.UDPConn
+}
+
+// entry is the names of a service or an ingress, and the IPs they resolve to
+type entry struct {
+	names []string
+	ips   []net.IP
+}
+
+// NewResponder creates a responder. If address is not nil, all the names resolve to it rather than to the IPs of the endpoints.
+// Otherwise only the IPs of the endpoints inside lan, the networks the other devices reach, are advertised.
+func NewResponder(address net.IP, lan []*net.IPNet) *Responder {
+	return &Responder{
+		address: address,
+		lan:     lan,
+		entries: make(map[string]entry),
+	}
+}
+
+// LANNetworks returns the networks of iface, or of the interface of the default route if iface is nil
+func LANNetworks(iface *net.Interface) ([]*net.IPNet, error) {
+	if iface == nil {
+		// no packet is sent, dialing only picks the route
+		c, err := net.DialUDP("udp4", nil, group)
+		if err != nil {
+			return nil, errors.Wrap(err, "finding the default route")
+		}
+		local := c.LocalAddr().(*net.UDPAddr).IP
+		c.Close()
+		if iface, err = interfaceOf(local); err != nil {
+			return nil, err
+		}
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, errors.Wrapf(err, "addresses of %s", iface.Name)
+	}
+	var lan []*net.IPNet
+	for _, a := range addrs {
+		if n, ok := a.(*net.IPNet); ok && !n.IP.IsLinkLocalUnicast() {
+			lan = append(lan, n)
+		}
+	}
+	if len(lan) == 0 {
+		return nil, errors.Errorf("%s has no address", iface.Name)
+	}
+	return lan, nil
+}
+
+// interfaceOf returns the interface having ip
+func interfaceOf(ip net.IP) (*net.Interface, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, errors.Wrap(err, "listing the network interfaces")
+	}
+	for i := range ifaces {
+		addrs, err := ifaces[i].Addrs()
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			if n, ok := a.(*net.IPNet); ok && n.IP.Equal(ip) {
+				return &ifaces[i], nil
+			}
+		}
+	}
+	return nil, errors.Errorf("no interface has %s", ip)
+}
+
+// ServiceName returns the name a load balancer is advertised as: <service>-<namespace>.local
+func ServiceName(svc *v1.Service) string {
+	return strings.ToLower(fmt.Sprintf("%s-%s.local.", svc.Name, svc.Namespace))
+}
+
+// UpdateService advertises a service of type LoadBalancer, as long as it has an IP
+func (r *Responder) UpdateService(svc *v1.Service) {
+	var ips []net.IP
+	if svc.Spec.Type == v1.ServiceTypeLoadBalancer {
+		ips = ingressIPs(svc.Status.LoadBalancer.Ingress)
+	}
+	r.update(key("service", svc.Namespace, svc.Name), entry{names: []string{ServiceName(svc)}, ips: ips})
+}
+
+// DeleteService stops advertising a service
+func (r *Responder) DeleteService(svc *v1.Service) {
+	r.update(key("service", svc.Namespace, svc.Name), entry{})
+}
+
+// UpdateIngress advertises the hosts of an ingress in the .local domain, as long as it has an IP
+func (r *Responder) UpdateIngress(ing *networking.Ingress) {
+	var names []string
+	for _, rule := range ing.Spec.Rules {
+		h := strings.ToLower(rule.Host)
+		if strings.HasSuffix(h, ".local") && !strings.HasPrefix(h, "*") {
+			names = append(names, dns.Fqdn(h))
+		}
+	}
+	var ips []net.IP
+	for _, i := range ing.Status.LoadBalancer.Ingress {
+		if ip := net.ParseIP(i.IP); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	r.update(key("ingress", ing.Namespace, ing.Name), entry{names: names, ips: ips})
+}
+
+// DeleteIngress stops advertising an ingress
+func (r *Responder) DeleteIngress(ing *networking.Ingress) {
+	r.update(key("ingress", ing.Namespace, ing.Name), entry{})
+}
+
+// Names returns the advertised names, sorted
+func (r *Responder) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var names []string
+	for _, e := range r.entries {
+		names = append(names, e.names...)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func key(kind, namespace, name string) string {
+	return kind + "/" + namespace + "/" + name
+}
+
+func ingressIPs(ingress []v1.LoadBalancerIngress) []net.IP {
+	var ips []net.IP
+	for _, i := range ingress {
+		if ip := net.ParseIP(i.IP); ip != nil {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+// update replaces the entry of k, announcing its names on the network, and the end of the names it no longer has
+func (r *Responder) update(k string, e entry) {
+	if r.address != nil && len(e.ips) > 0 {
+		e.ips = []net.IP{r.address}
+	} else if len(e.ips) > 0 {
+		e.ips = r.reachable(e.names, e.ips)
+	}
+	if len(e.ips) == 0 {
+		e.names = nil
+	}
+
+	r.mu.Lock()
+	old := r.entries[k]
+	if len(e.names) == 0 {
+		delete(r.entries, k)
+	} else {
+		r.entries[k] = e
+	}
+	r.mu.Unlock()
+
+	var gone []string
+	for _, n := range old.names {
+		if !contains(e.names, n) {
+			gone = append(gone, n)
+		}
+	}
+	if len(gone) > 0 {
+		klog.Infof("no longer advertising %v", gone)
+		r.send(announcement([]entry{{names: gone, ips: old.ips}}, 0), group)
+	}
+	if len(e.names) > 0 {
+		klog.Infof("advertising %v: %v", e.names, e.ips)
+		r.send(announcement([]entry{e}, ttl), group)
+	}
+}
+
+// reachable returns the IPs of names inside the networks of the LAN, warning about the others, which only the host reaches
+func (r *Responder) reachable(names []string, ips []net.IP) []net.IP {
+	var in, skipped []net.IP
+	for _, ip := range ips {
+		if inside(r.lan, ip) {
+			in = append(in, ip)
+		} else {
+			skipped = append(skipped, ip)
+		}
+	}
+	if len(skipped) > 0 && len(names) > 0 {
+		out.WarningT("Not advertising {{.ips}} for {{.names}}, the local network does not reach it: pass the IP of the host with --address", out.V{"ips": skipped, "names": names})
+	}
+	return in
+}
+
+func inside(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// records returns the records of name of type qtype
+func (r *Responder) records(name string, qtype uint16, ttl uint32, flush bool) []dns.RR {
+	name = strings.ToLower(name)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, e := range r.entries {
+		if contains(e.names, name) {
+			return rrs(name, e.ips, qtype, ttl, flush)
+		}
+	}
+	return nil
+}
+
+// rrs returns the A and AAAA records of ips of type qtype
+func rrs(name string, ips []net.IP, qtype uint16, ttl uint32, flush bool) []dns.RR {
+	class := uint16(dns.ClassINET)
+	if flush {
+		class |= cacheFlush
+	}
+	hdr := func(rrtype uint16) dns.RR_Header {
+		return dns.RR_Header{Name: name, Rrtype: rrtype, Class: class, Ttl: ttl}
+	}
+	var out []dns.RR
+	for _, ip := range ips {
+		if ip4 := ip.To4(); ip4 != nil {
+			if qtype == dns.TypeA || qtype == dns.TypeANY {
+				out = append(out, &dns.A{Hdr: hdr(dns.TypeA), A: ip4})
+			}
+		} else if qtype == dns.TypeAAAA || qtype == dns.TypeANY {
+			out = append(out, &dns.AAAA{Hdr: hdr(dns.TypeAAAA), AAAA: ip})
+		}
+	}
+	return out
+}
+
+// announcement returns the unsolicited response advertising the names of entries, with a TTL of 0 saying goodbye
+func announcement(entries []entry, ttl uint32) *dns.Msg {
+	m := new(dns.Msg)
+	m.Response = true
+	m.Authoritative = true
+	for _, e := range entries {
+		for _, n := range e.names {
+			m.Answer = append(m.Answer, rrs(n, e.ips, dns.TypeANY, ttl, true)...)
+		}
+	}
+	return m
+}
+
+// answer returns the response to the query req, nil if the responder has none of the names.
+// A legacy query, coming from another port than the mDNS one, gets a unicast DNS response.
+func (r *Responder) answer(req *dns.Msg, legacy bool) (m *dns.Msg, unicast bool) {
+	if req.Response || req.Opcode != dns.OpcodeQuery {
+		return nil, false
+	}
+	m = new(dns.Msg)
+	m.Response = true
+	m.Authoritative = true
+	unicast = legacy
+	for _, q := range req.Question {
+		if legacy {
+			m.Answer = append(m.Answer, r.records(q.Name, q.Qtype, legacyTTL, false)...)
+			continue
+		}
+		rrs := r.records(q.Name, q.Qtype, ttl, true)
+		if len(rrs) > 0 && q.Qclass&cacheFlush != 0 {
+			// the QU bit asks for a unicast response
+			unicast = true
+		}
+		m.Answer = append(m.Answer, rrs...)
+	}
+	if len(m.Answer) == 0 {
+		return nil, false
+	}
+	if legacy {
+		m.Id = req.Id
+		m.Question = req.Question
+	}
+	return m, unicast
+}
+
+// Watch keeps the advertised names in sync with the services and ingresses of the cluster until ctx is done, once they are listed
+func (r *Responder) Watch(ctx context.Context, client kubernetes.Interface) error {
+	factory := informers.NewSharedInformerFactory(client, 0)
+	services := factory.Core().V1().Services().Informer()
+	if _, err := services.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { r.onService(obj, false) },
+		UpdateFunc: func(_, obj interface{}) { r.onService(obj, false) },
+		DeleteFunc: func(obj interface{}) { r.onService(obj, true) },
+	}); err != nil {
+		return errors.Wrap(err, "watching services")
+	}
+	ingresses := factory.Networking().V1().Ingresses().Informer()
+	if _, err := ingresses.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { r.onIngress(obj, false) },
+		UpdateFunc: func(_, obj interface{}) { r.onIngress(obj, false) },
+		DeleteFunc: func(obj interface{}) { r.onIngress(obj, true) },
+	}); err != nil {
+		return errors.Wrap(err, "watching ingresses")
+	}
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), services.HasSynced, ingresses.HasSynced) {
+		return errors.New("unable to list the services and ingresses")
+	}
+	return nil
+}
+
+func (r *Responder) onService(obj interface{}, deleted bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	svc, ok := obj.(*v1.Service)
+	if !ok {
+		return
+	}
+	if deleted {
+		r.DeleteService(svc)
+		return
+	}
+	r.UpdateService(svc)
+}
+
+func (r *Responder) onIngress(obj interface{}, deleted bool) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	ing, ok := obj.(*networking.Ingress)
+	if !ok {
+		return
+	}
+	if deleted {
+		r.DeleteIngress(ing)
+		return
+	}
+	r.UpdateIngress(ing)
+}
+
+// Serve answers the queries of the network of iface, or of the default one if iface is nil, until ctx is done.
+// The names are announced when it starts, and withdrawn when it stops.
+func (r *Responder) Serve(ctx context.Context, iface *net.Interface) error {
+	conn, err := net.ListenMulticastUDP("udp4", iface, group)
+	if err != nil {
+		return errors.Wrap(err, "listening on the mDNS group")
+	}
+	r.mu.Lock()
+	r.conn = conn
+	all := r.all()
+	r.mu.Unlock()
+	r.send(announcement(all, ttl), group)
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	buf := make([]byte, 9000)
+	for {
+		var n int
+		var from *net.UDPAddr
+		n, from, err = conn.ReadFromUDP(buf)
+		if err != nil {
+			break
+		}
+		req := new(dns.Msg)
+		if err := req.Unpack(buf[:n]); err != nil {
+			klog.V(3).Infof("ignoring invalid mDNS message from %s: %v", from, err)
+			continue
+		}
+		m, unicast := r.answer(req, from.Port != Port)
+		if m == nil {
+			continue
+		}
+		to := group
+		if unicast {
+			to = from
+		}
+		r.send(m, to)
+	}
+
+	r.mu.Lock()
+	all = r.all()
+	r.mu.Unlock()
+	// the multicast connection is closed, say goodbye with a new one
+	if c, err := net.DialUDP("udp4", nil, group); err == nil {
+		if b, err := announcement(all, 0).Pack(); err == nil {
+			_, _ = c.Write(b)
+		}
+		c.Close()
+	}
+	r.mu.Lock()
+	r.conn = nil
+	r.mu.Unlock()
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// all returns the entries, the caller holds mu
+func (r *Responder) all() []entry {
+	var all []entry
+	for _, e := range r.entries {
+		all = append(all, e)
+	}
+	return all
+}
+
+// send writes m to addr, when the responder is serving
+func (r *Responder) send(m *dns.Msg, addr *net.UDPAddr) {
+	r.mu.RLock()
+	conn := r.conn
+	r.mu.RUnlock()
+	if conn == nil || len(m.Answer) == 0 {
+		return
+	}
+	b, err := m.Pack()
+	if err != nil {
+		klog.Warningf("failed to pack mDNS message: %v", err)
+		return
+	}
+	if _, err := conn.WriteToUDP(b, addr); err != nil {
+		klog.Warningf("failed to send mDNS message to %s: %v", addr, err)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mdns
+
+import (
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/miekg/dns"
+	v1 "k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func loadBalancer(name, ip string) *v1.Service {
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+	}
+	if ip != "" {
+		svc.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: ip}}
+	}
+	return svc
+}
+
+// lan is the local network of the tests, reaching the cluster as with a bridged network
+var lan = []*net.IPNet{{IP: net.IPv4(10, 96, 0, 0), Mask: net.CIDRMask(12, 32)}, {IP: net.IPv4(192, 168, 49, 0), Mask: net.CIDRMask(24, 32)}}
+
+func TestUpdate(t *testing.T) {
+	r := NewResponder(nil, lan)
+	r.UpdateService(loadBalancer("web", "10.96.0.10"))
+	r.UpdateService(loadBalancer("pending", ""))
+	r.UpdateService(&v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "internal", Namespace: "default"}, Spec: v1.ServiceSpec{ClusterIP: "10.96.0.11"}})
+	r.UpdateIngress(&networking.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "default"},
+		Spec: networking.IngressSpec{Rules: []networking.IngressRule{
+			{Host: "Shop.local"}, {Host: "shop.example.com"}, {Host: "*.local"},
+		}},
+		Status: networking.IngressStatus{LoadBalancer: networking.IngressLoadBalancerStatus{
+			Ingress: []networking.IngressLoadBalancerIngress{{IP: "192.168.49.2"}},
+		}},
+	})
+
+	want := []string{"shop.local.", "web-default.local."}
+	if got := r.Names(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Names() = %v, want %v", got, want)
+	}
+
+	r.DeleteService(loadBalancer("web", "10.96.0.10"))
+	want = []string{"shop.local."}
+	if got := r.Names(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() after delete = %v, want %v", got, want)
+	}
+}
+
+func TestAnswer(t *testing.T) {
+	r := NewResponder(nil, lan)
+	r.UpdateService(loadBalancer("web", "10.96.0.10"))
+
+	query := func(name string, qtype uint16, qclass uint16) *dns.Msg {
+		m := new(dns.Msg)
+		m.Id = 42
+		m.Question = []dns.Question{{Name: name, Qtype: qtype, Qclass: qclass}}
+		return m
+	}
+
+	m, unicast := r.answer(query("Web-Default.local.", dns.TypeA, dns.ClassINET), false)
+	if m == nil || unicast {
+		t.Fatalf("answer() = %v, %v, want a multicast answer", m, unicast)
+	}
+	a, ok := m.Answer[0].(*dns.A)
+	if !ok || !a.A.Equal(net.ParseIP("10.96.0.10")) || a.Hdr.Ttl != ttl || a.Hdr.Class != dns.ClassINET|cacheFlush || m.Id != 0 {
+		t.Errorf("answer() = %v", m)
+	}
+
+	if m, _ := r.answer(query("web-default.local.", dns.TypeAAAA, dns.ClassINET), false); m != nil {
+		t.Errorf("answer() of AAAA = %v, want none", m)
+	}
+	if m, _ := r.answer(query("other.local.", dns.TypeA, dns.ClassINET), false); m != nil {
+		t.Errorf("answer() of an unknown name = %v, want none", m)
+	}
+
+	if _, unicast := r.answer(query("web-default.local.", dns.TypeA, dns.ClassINET|cacheFlush), false); !unicast {
+		t.Errorf("answer() of a QU question is not unicast")
+	}
+
+	m, unicast = r.answer(query("web-default.local.", dns.TypeA, dns.ClassINET), true)
+	if m == nil || !unicast || m.Id != 42 || len(m.Question) != 1 || m.Answer[0].Header().Ttl != legacyTTL {
+		t.Errorf("answer() of a legacy query = %v, %v", m, unicast)
+	}
+}
+
+func TestAddress(t *testing.T) {
+	r := NewResponder(net.ParseIP("192.168.1.20"), nil)
+	r.UpdateService(loadBalancer("web", "10.96.0.10"))
+	r.UpdateService(loadBalancer("pending", ""))
+
+	if got, want := r.Names(), []string{"web-default.local."}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Names() = %v, want %v", got, want)
+	}
+	rrs := r.records("web-default.local.", dns.TypeA, ttl, true)
+	if len(rrs) != 1 || !rrs[0].(*dns.A).A.Equal(net.ParseIP("192.168.1.20")) {
+		t.Errorf("records() = %v, want the address", rrs)
+	}
+}
+
+func TestUnreachable(t *testing.T) {
+	r := NewResponder(nil, []*net.IPNet{{IP: net.IPv4(192, 168, 1, 0), Mask: net.CIDRMask(24, 32)}})
+	r.UpdateService(loadBalancer("web", "192.168.49.200"))
+	r.UpdateService(loadBalancer("bridged", "192.168.1.30"))
+
+	if got, want := r.Names(), []string{"bridged-default.local."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+}
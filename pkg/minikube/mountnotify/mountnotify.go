@@ -0,0 +1,235 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mountnotify forwards the file changes of a host directory to the inotify watchers of its 9p mount in the guest,
+// which the guest kernel does not notify of the changes made on the host.
+// The guest kernel only notifies its watchers of the operations made through the mount, so a guest helper makes the ones
+// notified as the changes of the host without changing the files: a truncation of the changed files to go9p.NotifyLength,
+// which the 9p server of minikube mount answers without changing the file, notified as a modification,
+// and the creation and removal of a temporary file in the directories whose entries changed, for the watchers to list them again.
+package mountnotify
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/kballard/go-shellquote"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/third_party/go9p"
+)
+
+const (
+	// batchDelay is how long the changes following a first change are collected, to notify them at once
+	batchDelay = 100 * time.Millisecond
+	// tmpPrefix starts the names of the temporary files of the guest helper, whose changes are not forwarded back
+	tmpPrefix = ".minikube-notify-"
+)
+
+// The operations of the guest helper notifying the watchers of a change
+const (
+	// OpModify notifies the watchers of the file, and of its directory, of a modification of the file
+	OpModify = "modify"
+	// OpList notifies the watchers of the directory of the creation and removal of a file, for them to list it again
+	OpList = "list"
+)
+
+// Change is a change of the host directory to notify the guest watchers of
+type Change struct {
+	// Rel is the slash separated path relative to the directory
+	Rel string
+	// Op is the operation notifying it, OpModify or OpList
+	Op string
+}
+
+// Watcher watches a host directory and its subdirectories for changes
+type Watcher struct {
+	root string
+	w    *fsnotify.Watcher
+}
+
+// NewWatcher returns a Watcher of the host directory root and of its subdirectories
+func NewWatcher(root string) (*Watcher, error) {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.Wrap(err, "creating the watcher")
+	}
+	nw := &Watcher{root: root, w: w}
+	if _, err := nw.watchTree(root); err != nil {
+		w.Close()
+		return nil, err
+	}
+	return nw, nil
+}
+
+// watchTree watches the directory dir and its subdirectories, and returns the files and directories it holds
+func (nw *Watcher) watchTree(dir string) ([]string, error) {
+	var found []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// removed while walking
+			if os.IsNotExist(err) && p != dir {
+				return nil
+			}
+			return err
+		}
+		if p != dir {
+			found = append(found, p)
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if err := nw.w.Add(p); err != nil {
+			return errors.Wrapf(err, "watching %s", p)
+		}
+		return nil
+	})
+	return found, err
+}
+
+// Run calls notify with the changes to notify, sorted by path, until done is closed
+func (nw *Watcher) Run(notify func(changes []Change), done <-chan struct{}) {
+	defer nw.w.Close()
+	pending := map[Change]bool{}
+	var flush <-chan time.Time
+	for {
+		select {
+		case <-done:
+			return
+		case err := <-nw.w.Errors:
+			// such as an overflow of the event queue, or too many directories for the inotify watches of the host
+			klog.Warningf("watching %s: %v", nw.root, err)
+		case ev := <-nw.w.Events:
+			paths := []string{ev.Name}
+			if ev.Has(fsnotify.Create) {
+				if st, err := os.Stat(ev.Name); err == nil && st.IsDir() {
+					// the content of a new directory may be written before it is watched
+					found, err := nw.watchTree(ev.Name)
+					if err != nil {
+						klog.Warningf("%v", err)
+					}
+					paths = append(paths, found...)
+				}
+			}
+			for _, p := range paths {
+				for _, c := range notifyChanges(nw.root, fsnotify.Event{Name: p, Op: ev.Op}) {
+					pending[c] = true
+				}
+			}
+			if len(pending) > 0 && flush == nil {
+				flush = time.After(batchDelay)
+			}
+		case <-flush:
+			var changes []Change
+			for c := range pending {
+				changes = append(changes, c)
+			}
+			sort.Slice(changes, func(i, j int) bool {
+				if changes[i].Rel != changes[j].Rel {
+					return changes[i].Rel < changes[j].Rel
+				}
+				return changes[i].Op < changes[j].Op
+			})
+			notify(changes)
+			pending = map[Change]bool{}
+			flush = nil
+		}
+	}
+}
+
+// notifyChanges returns the changes to notify of an event: the modification of a written or created file or directory,
+// and the listing of the parent directory of a created, removed or renamed one.
+// Attribute changes are not notified, nor the temporary files of the guest helper.
+func notifyChanges(root string, ev fsnotify.Event) []Change {
+	if strings.HasPrefix(filepath.Base(ev.Name), tmpPrefix) {
+		return nil
+	}
+	var changes []Change
+	add := func(p, op string) {
+		rel, err := filepath.Rel(root, p)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return
+		}
+		changes = append(changes, Change{Rel: filepath.ToSlash(rel), Op: op})
+	}
+	switch {
+	case ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename):
+		add(filepath.Dir(ev.Name), OpList)
+	case ev.Has(fsnotify.Create):
+		add(ev.Name, OpModify)
+		add(filepath.Dir(ev.Name), OpList)
+	case ev.Has(fsnotify.Write):
+		add(ev.Name, OpModify)
+	}
+	return changes
+}
+
+// Injector notifies the inotify watchers of a 9p mount in the guest of the changes of the host directory
+type Injector struct {
+	r      command.Runner
+	target string
+	// list is the name of the temporary file listing the paths in the guest
+	list string
+}
+
+// NewInjector returns an Injector of the 9p mount at target in the guest, run with r
+func NewInjector(r command.Runner, target string) *Injector {
+	return &Injector{r: r, target: target, list: fmt.Sprintf("minikube-notify-%d", os.Getpid())}
+}
+
+// Inject notifies the watchers of the changes with the guest helper, the paths which no longer exist being skipped
+func (i *Injector) Inject(changes []Change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	var records []string
+	for _, c := range changes {
+		records = append(records, c.Op, path.Join(i.target, c.Rel))
+	}
+	list := assets.NewMemoryAsset([]byte(strings.Join(records, "\x00")+"\x00"), "/tmp", i.list, "0644")
+	if err := i.r.Copy(list); err != nil {
+		return errors.Wrap(err, "copying the changed paths")
+	}
+	if _, err := i.r.RunCmd(injectCmd(list.GetTargetPath())); err != nil {
+		return errors.Wrap(err, "notifying the changes")
+	}
+	return nil
+}
+
+// helper is the guest helper, reading the NUL separated operations and paths of the changes
+const helper = `while IFS= read -r -d '' op && IFS= read -r -d '' p; do
+  case "$op" in
+  %s) [ -f "$p" ] && truncate --no-create --size=%d -- "$p" 2>/dev/null ;;
+  %s) [ -d "$p" ] && t=$(mktemp --tmpdir="$p" %sXXXXXX 2>/dev/null) && rm -f -- "$t" ;;
+  esac
+done`
+
+// injectCmd runs the guest helper on the changes listed in the file list, and removes it
+func injectCmd(list string) *exec.Cmd {
+	script := fmt.Sprintf(helper, OpModify, go9p.NotifyLength, OpList, tmpPrefix)
+	return exec.Command("/bin/bash", "-c", fmt.Sprintf("sudo bash -c %s < %s; sudo rm -f %s", shellquote.Join(script), list, list))
+}
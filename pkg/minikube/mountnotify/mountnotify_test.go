@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mountnotify
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+func TestNotifyChanges(t *testing.T) {
+	root := filepath.Join(string(filepath.Separator), "src")
+	tests := []struct {
+		name string
+		op   fsnotify.Op
+		want []Change
+	}{
+		{filepath.Join(root, "web", "app.js"), fsnotify.Write, []Change{{"web/app.js", OpModify}}},
+		{filepath.Join(root, "main.go"), fsnotify.Create, []Change{{"main.go", OpModify}, {".", OpList}}},
+		{filepath.Join(root, "web", "old.js"), fsnotify.Remove, []Change{{"web", OpList}}},
+		{filepath.Join(root, "main.go~"), fsnotify.Rename, []Change{{".", OpList}}},
+		{filepath.Join(root, "main.go"), fsnotify.Chmod, nil},
+		{filepath.Join(root, "web", ".minikube-notify-a1B2c3"), fsnotify.Create, nil},
+		{root, fsnotify.Remove, nil},
+	}
+	for _, tc := range tests {
+		if got := notifyChanges(root, fsnotify.Event{Name: tc.name, Op: tc.op}); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("notifyChanges(%s %s) = %v, want %v", tc.op, tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "web"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWatcher(root)
+	if err != nil {
+		t.Fatalf("NewWatcher: %v", err)
+	}
+	batches := make(chan []Change, 10)
+	done := make(chan struct{})
+	defer close(done)
+	go w.Run(func(changes []Change) { batches <- changes }, done)
+
+	write := func(rel string) {
+		if err := os.WriteFile(filepath.Join(root, filepath.FromSlash(rel)), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// waits for the batches holding want, the other changes being notified more than once
+	expect := func(want ...Change) {
+		t.Helper()
+		missing := map[Change]bool{}
+		for _, c := range want {
+			missing[c] = true
+		}
+		timeout := time.After(10 * time.Second)
+		for len(missing) > 0 {
+			select {
+			case changes := <-batches:
+				for _, c := range changes {
+					delete(missing, c)
+				}
+			case <-timeout:
+				t.Fatalf("timed out waiting for %v", missing)
+			}
+		}
+	}
+
+	write("web/app.js")
+	expect(Change{"web/app.js", OpModify}, Change{"web", OpList})
+
+	// the content of new directories is watched
+	if err := os.MkdirAll(filepath.Join(root, "api", "v1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write("api/v1/handler.go")
+	expect(Change{"api", OpModify}, Change{"api/v1/handler.go", OpModify}, Change{"api/v1", OpList})
+	write("api/v1/handler.go")
+	expect(Change{"api/v1/handler.go", OpModify})
+
+	if err := os.Remove(filepath.Join(root, "web", "app.js")); err != nil {
+		t.Fatal(err)
+	}
+	expect(Change{"web", OpList})
+
+	// the temporary files of the guest helper are not forwarded back
+	write("web/.minikube-notify-a1B2c3")
+	write("web/index.html")
+	changes := <-batches
+	for _, c := range changes {
+		if c.Rel == "web/.minikube-notify-a1B2c3" {
+			t.Errorf("expected the changes of the guest helper not to be forwarded, got: %v", changes)
+		}
+	}
+}
+
+func TestInjectCmd(t *testing.T) {
+	args := injectCmd("/tmp/list").Args
+	if len(args) != 3 || args[0] != "/bin/bash" || args[1] != "-c" {
+		t.Fatalf("injectCmd() = %q, want a bash command", args)
+	}
+	for _, want := range []string{"sudo bash -c ", "truncate --no-create --size=9223372036854775807 --", "mktemp --tmpdir=", ".minikube-notify-XXXXXX", "< /tmp/list; sudo rm -f /tmp/list"} {
+		if !strings.Contains(args[2], want) {
+			t.Errorf("expected the inject command to contain %q, got: %s", want, args[2])
+		}
+	}
+	if strings.Contains(args[2], "touch") {
+		t.Errorf("expected the inject command not to touch the files, got: %s", args[2])
+	}
+}
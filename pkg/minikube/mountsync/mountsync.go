@@ -0,0 +1,626 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mountsync syncs a host directory and a node directory both ways, as the sync mount type
+package mountsync
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+// The policies resolving the conflicts, when a path was changed differently on both sides since it was synced
+const (
+	// ConflictHost keeps the version of the host
+	ConflictHost = "host"
+	// ConflictGuest keeps the version of the guest
+	ConflictGuest = "guest"
+	// ConflictNewer keeps the most recently modified version, the one of the host on ties
+	ConflictNewer = "newer"
+)
+
+// ConflictPolicies are the supported conflict policies
+var ConflictPolicies = []string{ConflictHost, ConflictGuest, ConflictNewer}
+
+// Options configures a Syncer
+type Options struct {
+	// Ignore are the patterns of the paths not synced. A pattern without a slash matches the names of the files and directories at any depth,
+	// the other ones match the paths relative to the synced directories. The content of an ignored directory is ignored.
+	Ignore []string
+	// Conflict is the conflict policy
+	Conflict string
+	// UID and GID own the files synced to the guest, as names or numbers
+	UID string
+	GID string
+	// ReadOnly syncs the host directory to the guest only, the changes of the guest being reverted
+	ReadOnly bool
+}
+
+// Validate checks the conflict policy and the ignore patterns
+func (o Options) Validate() error {
+	valid := false
+	for _, c := range ConflictPolicies {
+		valid = valid || o.Conflict == c
+	}
+	if !valid {
+		return errors.Errorf("invalid conflict policy %q, expected one of %s", o.Conflict, strings.Join(ConflictPolicies, ", "))
+	}
+	for _, p := range o.Ignore {
+		if _, err := path.Match(p, ""); err != nil {
+			return errors.Errorf("invalid ignore pattern %q", p)
+		}
+	}
+	return nil
+}
+
+// ignored returns whether the slash separated relative path rel, or one of its parent directories, matches one of the patterns
+func ignored(patterns []string, rel string) bool {
+	parts := strings.Split(rel, "/")
+	for _, p := range patterns {
+		p = strings.TrimSuffix(p, "/")
+		if !strings.Contains(p, "/") {
+			for _, part := range parts {
+				if ok, _ := path.Match(p, part); ok {
+					return true
+				}
+			}
+			continue
+		}
+		p = strings.TrimPrefix(p, "/")
+		for i := range parts {
+			if ok, _ := path.Match(p, strings.Join(parts[:i+1], "/")); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// entry is the state of a file or directory. The modification time is in seconds, which tar preserves.
+// Symbolic links and special files are not synced.
+type entry struct {
+	dir   bool
+	size  int64
+	mtime int64
+}
+
+// tree holds the entries of a synced directory by slash separated relative path
+type tree map[string]entry
+
+// scanHost returns the entries of the host directory root which are not ignored
+func scanHost(root string, ignore []string) (tree, error) {
+	t := tree{}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// removed while walking
+			if os.IsNotExist(err) && p != root {
+				return nil
+			}
+			return err
+		}
+		if p == root {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		// the files being extracted by pull
+		if strings.HasPrefix(d.Name(), ".minikube-sync-") {
+			return nil
+		}
+		if ignored(ignore, rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch {
+		case d.IsDir():
+			t[rel] = entry{dir: true}
+		case d.Type().IsRegular():
+			info, err := d.Info()
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			t[rel] = entry{size: info.Size(), mtime: info.ModTime().Unix()}
+		}
+		return nil
+	})
+	return t, err
+}
+
+// findCmd lists the files and directories of the guest directory dir as "TYPE SIZE MTIME PATH" records ended by NUL bytes
+func findCmd(dir string) *exec.Cmd {
+	return exec.Command("sudo", "find", dir, "-mindepth", "1", "(", "-type", "f", "-o", "-type", "d", ")", "-printf", `%y %s %T@ %P\0`)
+}
+
+// parseFind returns the entries listed by findCmd which are not ignored
+func parseFind(out []byte, ignore []string) tree {
+	t := tree{}
+	for _, rec := range bytes.Split(out, []byte{0}) {
+		fields := strings.SplitN(string(rec), " ", 4)
+		if len(fields) != 4 || fields[3] == "" {
+			continue
+		}
+		rel := fields[3]
+		if ignored(ignore, rel) {
+			continue
+		}
+		if fields[0] == "d" {
+			t[rel] = entry{dir: true}
+			continue
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			klog.Warningf("unexpected size of %s: %q", rel, fields[1])
+			continue
+		}
+		secs, _, _ := strings.Cut(fields[2], ".")
+		mtime, err := strconv.ParseInt(secs, 10, 64)
+		if err != nil {
+			klog.Warningf("unexpected mtime of %s: %q", rel, fields[2])
+			continue
+		}
+		t[rel] = entry{size: size, mtime: mtime}
+	}
+	return t
+}
+
+// plan are the operations syncing the host and the guest
+type plan struct {
+	// push are copied from the host to the guest, and pull from the guest to the host
+	push []string
+	pull []string
+	// removeGuest are removed from the guest, and removeHost from the host, before the copies
+	removeGuest []string
+	removeHost  []string
+	// conflicts were changed differently on both sides
+	conflicts []string
+}
+
+// diff returns the plan syncing the host and the guest from base, the state of the paths when they were last synced, and the state once it is applied.
+// A path changed on one side is copied or removed on the other side, a modification winning over a removal,
+// and a path changed differently on both sides is a conflict resolved by the policy.
+func diff(base, host, guest tree, policy string) (plan, tree) {
+	var p plan
+	synced := tree{}
+	seen := map[string]bool{}
+	var paths []string
+	for _, t := range []tree{base, host, guest} {
+		for rel := range t {
+			if !seen[rel] {
+				seen[rel] = true
+				paths = append(paths, rel)
+			}
+		}
+	}
+	// parents before their content
+	sort.Strings(paths)
+
+	for _, rel := range paths {
+		b, inBase := base[rel]
+		h, onHost := host[rel]
+		g, onGuest := guest[rel]
+		hostChanged := onHost != inBase || (onHost && h != b)
+		guestChanged := onGuest != inBase || (onGuest && g != b)
+		switch {
+		case !onHost && !onGuest:
+			// removed on both sides
+		case onHost && onGuest && h == g:
+			synced[rel] = h
+		case !guestChanged:
+			if onHost {
+				p.push = append(p.push, rel)
+				synced[rel] = h
+			} else {
+				p.removeGuest = append(p.removeGuest, rel)
+			}
+		case !hostChanged:
+			if onGuest {
+				p.pull = append(p.pull, rel)
+				synced[rel] = g
+			} else {
+				p.removeHost = append(p.removeHost, rel)
+			}
+		case !onHost:
+			p.pull = append(p.pull, rel)
+			synced[rel] = g
+		case !onGuest:
+			p.push = append(p.push, rel)
+			synced[rel] = h
+		default:
+			p.conflicts = append(p.conflicts, rel)
+			if policy == ConflictHost || (policy == ConflictNewer && h.mtime >= g.mtime) {
+				// a file can not be extracted over a directory, and the other way around
+				if h.dir != g.dir {
+					p.removeGuest = append(p.removeGuest, rel)
+				}
+				p.push = append(p.push, rel)
+				synced[rel] = h
+				continue
+			}
+			if h.dir != g.dir {
+				p.removeHost = append(p.removeHost, rel)
+			}
+			p.pull = append(p.pull, rel)
+			synced[rel] = g
+		}
+	}
+	return p, synced
+}
+
+// mirror returns the plan making the guest a copy of the host, whatever changed in the guest, and the state once it is applied
+func mirror(host, guest tree) (plan, tree) {
+	var p plan
+	var paths []string
+	for rel := range host {
+		paths = append(paths, rel)
+	}
+	for rel := range guest {
+		if _, ok := host[rel]; !ok {
+			paths = append(paths, rel)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, rel := range paths {
+		h, onHost := host[rel]
+		g, onGuest := guest[rel]
+		switch {
+		case !onHost:
+			p.removeGuest = append(p.removeGuest, rel)
+		case !onGuest:
+			p.push = append(p.push, rel)
+		case h != g:
+			if h.dir != g.dir {
+				p.removeGuest = append(p.removeGuest, rel)
+			}
+			p.push = append(p.push, rel)
+		}
+	}
+	return p, host
+}
+
+// Stats are the operations of a sync cycle
+type Stats struct {
+	// ToGuest and ToHost count the paths copied to the guest and to the host, Removed the ones removed from either side
+	ToGuest int
+	ToHost  int
+	Removed int
+	// Conflicts were changed differently on both sides, and resolved by the conflict policy
+	Conflicts []string
+}
+
+// Syncer syncs a host directory and a guest directory both ways
+type Syncer struct {
+	r     command.Runner
+	host  string
+	guest string
+	opts  Options
+	// base is the state of the paths when they were last synced
+	base    tree
+	started bool
+	// tmp prefixes the temporary files of the syncer in the guest
+	tmp string
+}
+
+// New returns a Syncer of the host directory host and the guest directory guest, run with r
+func New(r command.Runner, host, guest string, opts Options) *Syncer {
+	return &Syncer{r: r, host: host, guest: guest, opts: opts, base: tree{}, tmp: fmt.Sprintf("minikube-sync-%d", os.Getpid())}
+}
+
+// owner returns the owner of the files synced to the guest, quoted for the shell
+func (s *Syncer) owner() string {
+	return shellquote.Join(s.opts.UID + ":" + s.opts.GID)
+}
+
+// Sync runs a sync cycle: it scans both directories, and copies and removes the paths changed since the previous cycle.
+// The first cycle syncs the paths present on one side only, and resolves the ones differing with the conflict policy.
+// With ReadOnly, every cycle copies the host directory to the guest instead, removing the paths of the guest only.
+func (s *Syncer) Sync() (Stats, error) {
+	if !s.started {
+		guest := shellquote.Join(s.guest)
+		c := fmt.Sprintf("sudo mkdir -p %s && sudo chown %s %s", guest, s.owner(), guest)
+		if _, err := s.r.RunCmd(exec.Command("/bin/bash", "-c", c)); err != nil {
+			return Stats{}, errors.Wrapf(err, "creating %s", s.guest)
+		}
+		s.started = true
+	}
+	host, err := scanHost(s.host, s.opts.Ignore)
+	if err != nil {
+		return Stats{}, errors.Wrapf(err, "scanning %s", s.host)
+	}
+	rr, err := s.r.RunCmd(findCmd(s.guest))
+	if err != nil {
+		return Stats{}, errors.Wrapf(err, "scanning %s in the guest", s.guest)
+	}
+	guest := parseFind(rr.Stdout.Bytes(), s.opts.Ignore)
+
+	p, synced := diff(s.base, host, guest, s.opts.Conflict)
+	if s.opts.ReadOnly {
+		p, synced = mirror(host, guest)
+	}
+	if err := s.removeFromGuest(p.removeGuest); err != nil {
+		return Stats{}, err
+	}
+	removeFromHost(s.host, p.removeHost)
+	if err := s.push(p.push); err != nil {
+		return Stats{}, err
+	}
+	if err := s.pull(p.pull); err != nil {
+		return Stats{}, err
+	}
+	// on failures, the next cycle diffs the partially synced directories with the same base again
+	s.base = synced
+	return Stats{ToGuest: len(p.push), ToHost: len(p.pull), Removed: len(p.removeGuest) + len(p.removeHost), Conflicts: p.conflicts}, nil
+}
+
+// copyToGuest copies c to the guest, and returns its path there
+func (s *Syncer) copyToGuest(c assets.CopyableFile) (string, error) {
+	if err := s.r.Copy(c); err != nil {
+		return "", errors.Wrapf(err, "copying %s", c.GetTargetName())
+	}
+	return c.GetTargetPath(), nil
+}
+
+// removeFromGuest removes the paths from the guest, the content of the directories before them.
+// The directories which are not empty are kept, their new content is pulled by the next cycle.
+func (s *Syncer) removeFromGuest(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+	list, err := s.copyToGuest(assets.NewMemoryAsset([]byte(strings.Join(paths, "\x00")), "/tmp", s.tmp+".remove", "0644"))
+	if err != nil {
+		return err
+	}
+	list = shellquote.Join(list)
+	c := fmt.Sprintf("cd %s && xargs -0 -r sudo rm -df -- < %s; sudo rm -f %s", shellquote.Join(s.guest), list, list)
+	if _, err := s.r.RunCmd(exec.Command("/bin/bash", "-c", c)); err != nil {
+		return errors.Wrap(err, "removing from the guest")
+	}
+	return nil
+}
+
+// removeFromHost removes the paths from the host directory root, the content of the directories before them.
+// The directories which are not empty are kept, their new content is pushed by the next cycle.
+func removeFromHost(root string, paths []string) {
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+	for _, rel := range paths {
+		if err := os.Remove(filepath.Join(root, filepath.FromSlash(rel))); err != nil && !os.IsNotExist(err) {
+			klog.Warningf("removing %s: %v", rel, err)
+		}
+	}
+}
+
+// push copies the paths from the host to the guest, in a tarball extracted in the guest
+func (s *Syncer) push(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	f, err := os.CreateTemp("", "minikube-sync-*.tar")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	err = writeTarball(f, s.host, paths)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return errors.Wrap(err, "creating the tarball of the host changes")
+	}
+
+	a, err := assets.NewFileAsset(f.Name(), "/tmp", s.tmp+".push.tar", "0644")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := a.Close(); err != nil {
+			klog.Warningf("closing %s: %v", a.GetSourcePath(), err)
+		}
+	}()
+	tarball, err := s.copyToGuest(a)
+	if err != nil {
+		return err
+	}
+	tarball = shellquote.Join(tarball)
+	c := fmt.Sprintf("cd %s && sudo tar --no-same-owner -xf %s && sudo tar -tf %s | sudo xargs -r -d '\\n' chown -h %s --; sudo rm -f %s", shellquote.Join(s.guest), tarball, tarball, s.owner(), tarball)
+	if _, err := s.r.RunCmd(exec.Command("/bin/bash", "-c", c)); err != nil {
+		return errors.Wrap(err, "extracting the host changes in the guest")
+	}
+	return nil
+}
+
+// writeTarball writes the files and directories paths of the host directory root to w, skipping the ones removed since they were scanned
+func writeTarball(w io.Writer, root string, paths []string) error {
+	tw := tar.NewWriter(w)
+	for _, rel := range paths {
+		src := filepath.Join(root, filepath.FromSlash(rel))
+		info, err := os.Lstat(src)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			continue
+		}
+		hdr := &tar.Header{
+			Name:    rel,
+			Mode:    int64(info.Mode().Perm()),
+			ModTime: info.ModTime().Truncate(time.Second),
+			Format:  tar.FormatGNU,
+		}
+		if info.IsDir() {
+			hdr.Typeflag = tar.TypeDir
+			hdr.Name += "/"
+		} else {
+			hdr.Typeflag = tar.TypeReg
+			hdr.Size = info.Size()
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			continue
+		}
+		if err := copyFile(tw, src, info.Size()); err != nil {
+			return errors.Wrapf(err, "adding %s", rel)
+		}
+	}
+	return tw.Close()
+}
+
+// copyFile writes the size first bytes of the file src to w
+func copyFile(w io.Writer, src string, size int64) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.CopyN(w, f, size)
+	return err
+}
+
+// pull copies the paths from the guest to the host, in a tarball created in the guest
+func (s *Syncer) pull(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	list, err := s.copyToGuest(assets.NewMemoryAsset([]byte(strings.Join(paths, "\x00")), "/tmp", s.tmp+".pull", "0644"))
+	if err != nil {
+		return err
+	}
+	tarball := path.Join("/tmp", s.tmp+".pull.tar")
+	defer func() {
+		if _, err := s.r.RunCmd(exec.Command("sudo", "rm", "-f", list, tarball)); err != nil {
+			klog.Warningf("removing the temporary files of the guest: %v", err)
+		}
+	}()
+	c := fmt.Sprintf("sudo tar -C %s --no-recursion --ignore-failed-read --null -T %s -cf %s", shellquote.Join(s.guest), shellquote.Join(list), shellquote.Join(tarball))
+	if _, err := s.r.RunCmd(exec.Command("/bin/bash", "-c", c)); err != nil {
+		return errors.Wrap(err, "creating the tarball of the guest changes")
+	}
+
+	f, err := os.CreateTemp("", "minikube-sync-*.tar")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := f.Close(); err != nil {
+		return err
+	}
+	a, err := assets.NewFileAsset(f.Name(), path.Dir(tarball), path.Base(tarball), "0644")
+	if err != nil {
+		return err
+	}
+	err = s.r.CopyFrom(a)
+	if cerr := a.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return errors.Wrap(err, "copying the tarball of the guest changes")
+	}
+
+	t, err := os.Open(f.Name())
+	if err != nil {
+		return err
+	}
+	defer t.Close()
+	return extractTarball(t, s.host)
+}
+
+// extractTarball extracts the files and directories of the tarball r into the host directory root, preserving their modes and modification times.
+// The files are replaced atomically, for the watchers of the host not to see them partially written.
+func extractTarball(r io.Reader, root string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		rel := path.Clean(strings.TrimSuffix(hdr.Name, "/"))
+		if path.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, "../") {
+			return errors.Errorf("unexpected path %s in the tarball", hdr.Name)
+		}
+		dst := filepath.Join(root, filepath.FromSlash(rel))
+		mode := fs.FileMode(hdr.Mode).Perm()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(dst, mode|0700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := extractFile(tr, dst, mode, hdr.ModTime); err != nil {
+				return errors.Wrapf(err, "extracting %s", rel)
+			}
+		}
+	}
+}
+
+// extractFile writes the content of r to dst, with the mode and modification time
+func extractFile(r io.Reader, dst string, mode fs.FileMode, mtime time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(dst), ".minikube-sync-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), mode); err != nil {
+		return err
+	}
+	if err := os.Chtimes(f.Name(), mtime, mtime); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), dst)
+}
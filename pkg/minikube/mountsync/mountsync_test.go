@@ -0,0 +1,254 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mountsync
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestIgnored(t *testing.T) {
+	patterns := []string{".git", "*.swp", "build/", "/web/dist"}
+	tests := []struct {
+		rel  string
+		want bool
+	}{
+		{".git", true},
+		{".git/HEAD", true},
+		{"sub/.git/HEAD", true},
+		{"main.go.swp", true},
+		{"cmd/.main.go.swp", true},
+		{"build", true},
+		{"api/build/out.bin", true},
+		{"web/dist/app.js", true},
+		{"api/web/dist/app.js", false},
+		{"web/src/app.js", false},
+		{"main.go", false},
+	}
+	for _, tc := range tests {
+		if got := ignored(patterns, tc.rel); got != tc.want {
+			t.Errorf("ignored(%q) = %t, want %t", tc.rel, got, tc.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := (Options{Conflict: ConflictNewer, Ignore: []string{"*.log"}}).Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	if err := (Options{Conflict: "mine"}).Validate(); err == nil {
+		t.Errorf("Validate() with an unknown conflict policy succeeded")
+	}
+	if err := (Options{Conflict: ConflictHost, Ignore: []string{"[a"}}).Validate(); err == nil {
+		t.Errorf("Validate() with an invalid pattern succeeded")
+	}
+}
+
+func TestParseFind(t *testing.T) {
+	out := []byte("d 4096 1700000000.1234567890 src\x00f 12 1700000001.5000000000 src/main.go\x00f 3 1700000002.0000000000 with space.txt\x00f 1 1700000003.0000000000 .git/HEAD\x00")
+	want := tree{
+		"src":            {dir: true},
+		"src/main.go":    {size: 12, mtime: 1700000001},
+		"with space.txt": {size: 3, mtime: 1700000002},
+	}
+	if got := parseFind(out, []string{".git"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseFind() = %v, want %v", got, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	v1 := entry{size: 1, mtime: 100}
+	v2 := entry{size: 2, mtime: 200}
+	v3 := entry{size: 3, mtime: 300}
+	dir := entry{dir: true}
+	tests := []struct {
+		name              string
+		base, host, guest tree
+		policy            string
+		want              plan
+		synced            tree
+	}{
+		{
+			name:   "first sync",
+			base:   tree{},
+			host:   tree{"src": dir, "src/a": v1, "same": v2},
+			guest:  tree{"b": v1, "same": v2},
+			policy: ConflictHost,
+			want:   plan{push: []string{"src", "src/a"}, pull: []string{"b"}},
+			synced: tree{"src": dir, "src/a": v1, "b": v1, "same": v2},
+		},
+		{
+			name:   "changed on one side",
+			base:   tree{"a": v1, "b": v1},
+			host:   tree{"a": v2, "b": v1},
+			guest:  tree{"a": v1, "b": v3},
+			policy: ConflictHost,
+			want:   plan{push: []string{"a"}, pull: []string{"b"}},
+			synced: tree{"a": v2, "b": v3},
+		},
+		{
+			name:   "removed on one side",
+			base:   tree{"d": dir, "d/a": v1, "b": v1, "c": v1},
+			host:   tree{"b": v1, "c": v1},
+			guest:  tree{"d": dir, "d/a": v1, "c": v1},
+			policy: ConflictHost,
+			want:   plan{removeGuest: []string{"d", "d/a"}, removeHost: []string{"b"}},
+			synced: tree{"c": v1},
+		},
+		{
+			name:   "modification wins over removal",
+			base:   tree{"a": v1, "b": v1},
+			host:   tree{"b": v2},
+			guest:  tree{"a": v3},
+			policy: ConflictHost,
+			want:   plan{push: []string{"b"}, pull: []string{"a"}},
+			synced: tree{"a": v3, "b": v2},
+		},
+		{
+			name:   "removed on both sides",
+			base:   tree{"a": v1},
+			host:   tree{},
+			guest:  tree{},
+			policy: ConflictHost,
+			synced: tree{},
+		},
+		{
+			name:   "conflict kept on the host",
+			base:   tree{"a": v1},
+			host:   tree{"a": v3},
+			guest:  tree{"a": v2},
+			policy: ConflictHost,
+			want:   plan{push: []string{"a"}, conflicts: []string{"a"}},
+			synced: tree{"a": v3},
+		},
+		{
+			name:   "conflict kept on the guest",
+			base:   tree{"a": v1},
+			host:   tree{"a": v3},
+			guest:  tree{"a": v2},
+			policy: ConflictGuest,
+			want:   plan{pull: []string{"a"}, conflicts: []string{"a"}},
+			synced: tree{"a": v2},
+		},
+		{
+			name:   "conflict kept where newer",
+			base:   tree{"a": v1, "b": v1},
+			host:   tree{"a": v3, "b": v2},
+			guest:  tree{"a": v2, "b": v3},
+			policy: ConflictNewer,
+			want:   plan{push: []string{"a"}, pull: []string{"b"}, conflicts: []string{"a", "b"}},
+			synced: tree{"a": v3, "b": v3},
+		},
+		{
+			name:   "file replacing a directory",
+			base:   tree{},
+			host:   tree{"a": v1},
+			guest:  tree{"a": dir},
+			policy: ConflictHost,
+			want:   plan{push: []string{"a"}, removeGuest: []string{"a"}, conflicts: []string{"a"}},
+			synced: tree{"a": v1},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, synced := diff(tc.base, tc.host, tc.guest, tc.policy)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("diff() plan = %+v, want %+v", got, tc.want)
+			}
+			if !reflect.DeepEqual(synced, tc.synced) {
+				t.Errorf("diff() synced = %v, want %v", synced, tc.synced)
+			}
+		})
+	}
+}
+
+func TestMirror(t *testing.T) {
+	v1 := entry{size: 1, mtime: 100}
+	v2 := entry{size: 2, mtime: 200}
+	dir := entry{dir: true}
+	host := tree{"src": dir, "src/a": v1, "b": v1, "c": v1, "d": v1}
+	guest := tree{"src": dir, "src/a": v2, "c": v1, "d": dir, "d/x": v2, "extra": v2}
+	want := plan{push: []string{"b", "d", "src/a"}, removeGuest: []string{"d", "d/x", "extra"}}
+	got, synced := mirror(host, guest)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mirror() plan = %+v, want %+v", got, want)
+	}
+	if !reflect.DeepEqual(synced, host) {
+		t.Errorf("mirror() synced = %v, want %v", synced, host)
+	}
+}
+
+func TestTarballRoundTrip(t *testing.T) {
+	src := t.TempDir()
+	mtime := time.Unix(1700000000, 0)
+	if err := os.MkdirAll(filepath.Join(src, "sub", "node_modules"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{"main.go": "package main", "sub/run.sh": "#!/bin/sh", "sub/node_modules/x.js": "x"} {
+		p := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(p, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Chmod(filepath.Join(src, "sub", "run.sh"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	scan, err := scanHost(src, []string{"node_modules"})
+	if err != nil {
+		t.Fatalf("scanHost: %v", err)
+	}
+	want := tree{
+		"main.go":    {size: 12, mtime: mtime.Unix()},
+		"sub":        {dir: true},
+		"sub/run.sh": {size: 9, mtime: mtime.Unix()},
+	}
+	if !reflect.DeepEqual(scan, want) {
+		t.Fatalf("scanHost() = %v, want %v", scan, want)
+	}
+
+	var b bytes.Buffer
+	// the removed file is skipped
+	if err := writeTarball(&b, src, []string{"main.go", "missing", "sub", "sub/run.sh"}); err != nil {
+		t.Fatalf("writeTarball: %v", err)
+	}
+	dst := t.TempDir()
+	if err := extractTarball(&b, dst); err != nil {
+		t.Fatalf("extractTarball: %v", err)
+	}
+	got, err := scanHost(dst, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extracted %v, want %v", got, want)
+	}
+	st, err := os.Stat(filepath.Join(dst, "sub", "run.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Mode().Perm() != 0755 {
+		t.Errorf("extracted run.sh with mode %v, want 0755", st.Mode().Perm())
+	}
+}
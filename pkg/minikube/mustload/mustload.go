@@ -34,6 +34,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/machine"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/reason"
+	"k8s.io/minikube/pkg/minikube/sshutil"
 	"k8s.io/minikube/pkg/minikube/style"
 )
 
@@ -77,6 +78,10 @@ func Partial(name string, miniHome ...string) (libmachine.API, *config.ClusterCo
 		exit.Error(reason.HostConfigLoad, "Error getting cluster config", err)
 	}
 
+	if err := sshutil.SetProxy(cc.SSHProxy); err != nil {
+		exit.Message(reason.Usage, "The ssh proxy of the cluster is not valid: {{.err}}", out.V{"err": err})
+	}
+
 	return api, cc
 }
 
@@ -0,0 +1,117 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package netem impairs the network of the nodes with the netem queueing discipline of tc, to test workloads against slow and flaky networks
+package netem
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+// Impairment is how the traffic leaving a node is degraded
+type Impairment struct {
+	Latency time.Duration
+	Jitter  time.Duration
+	// Loss is the percent of the packets dropped
+	Loss float64
+}
+
+// ParsePercent parses a percent such as "5%" or "0.5"
+func ParsePercent(s string) (float64, error) {
+	p, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil || p < 0 || p > 100 {
+		return 0, errors.Errorf("%q is not a percent between 0 and 100", s)
+	}
+	return p, nil
+}
+
+// Validate checks that the impairment degrades something
+func (i Impairment) Validate() error {
+	if i.Latency < 0 || i.Jitter < 0 {
+		return errors.New("the latency and the jitter cannot be negative")
+	}
+	if i.Jitter > 0 && i.Latency == 0 {
+		return errors.New("a jitter needs a latency")
+	}
+	if i.Latency == 0 && i.Loss == 0 {
+		return errors.New("at least one of the latency and the loss must be set")
+	}
+	return nil
+}
+
+// String describes the impairment
+func (i Impairment) String() string {
+	return strings.Join(i.args(), " ")
+}
+
+// args returns the netem parameters of the impairment
+func (i Impairment) args() []string {
+	var args []string
+	if i.Latency > 0 {
+		args = append(args, "delay", fmt.Sprintf("%dus", i.Latency.Microseconds()))
+		if i.Jitter > 0 {
+			args = append(args, fmt.Sprintf("%dus", i.Jitter.Microseconds()))
+		}
+	}
+	if i.Loss > 0 {
+		args = append(args, "loss", strconv.FormatFloat(i.Loss, 'f', -1, 64)+"%")
+	}
+	return args
+}
+
+// Device returns the network interface of the node holding ip, which carries the traffic of the cluster
+func Device(r command.Runner, ip string) (string, error) {
+	rr, err := r.RunCmd(exec.Command("ip", "-o", "-4", "addr", "show"))
+	if err != nil {
+		return "", errors.Wrap(err, "ip addr show")
+	}
+	return bsutil.KubeVipInterface(rr.Stdout.String(), ip), nil
+}
+
+// Impair replaces the root queueing discipline of dev with netem, degrading the traffic leaving it
+func Impair(r command.Runner, dev string, i Impairment) error {
+	args := append([]string{"tc", "qdisc", "replace", "dev", dev, "root", "netem"}, i.args()...)
+	if _, err := r.RunCmd(exec.Command("sudo", args...)); err != nil {
+		return errors.Wrapf(err, "impairing %s, which needs tc and the sch_netem kernel module", dev)
+	}
+	return nil
+}
+
+// Restore deletes the root queueing discipline of dev, restoring the default one
+func Restore(r command.Runner, dev string) error {
+	rr, err := r.RunCmd(exec.Command("sudo", "tc", "qdisc", "show", "dev", dev, "root"))
+	if err != nil {
+		return errors.Wrap(err, "tc qdisc show")
+	}
+	if !strings.Contains(rr.Stdout.String(), "netem") {
+		klog.Infof("%s is not impaired: %s", dev, rr.Stdout.String())
+		return nil
+	}
+	if _, err := r.RunCmd(exec.Command("sudo", "tc", "qdisc", "del", "dev", dev, "root")); err != nil {
+		return errors.Wrapf(err, "restoring %s", dev)
+	}
+	return nil
+}
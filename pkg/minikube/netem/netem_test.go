@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package netem
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+func TestParsePercent(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{in: "5%", want: 5},
+		{in: "0.5", want: 0.5},
+		{in: "100%", want: 100},
+		{in: "101%", wantErr: true},
+		{in: "-1%", wantErr: true},
+		{in: "some", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := ParsePercent(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParsePercent(%q) error = %v, wantErr %v", tc.in, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("ParsePercent(%q) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestImpairment(t *testing.T) {
+	tests := []struct {
+		impairment Impairment
+		want       string
+		wantErr    bool
+	}{
+		{impairment: Impairment{Latency: 200 * time.Millisecond, Loss: 5}, want: "delay 200000us loss 5%"},
+		{impairment: Impairment{Latency: 100 * time.Millisecond, Jitter: 10 * time.Millisecond}, want: "delay 100000us 10000us"},
+		{impairment: Impairment{Loss: 0.5}, want: "loss 0.5%"},
+		{impairment: Impairment{}, wantErr: true},
+		{impairment: Impairment{Jitter: time.Millisecond, Loss: 1}, wantErr: true},
+	}
+	for _, tc := range tests {
+		err := tc.impairment.Validate()
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%+v.Validate() = %v, wantErr %v", tc.impairment, err, tc.wantErr)
+			continue
+		}
+		if tc.wantErr {
+			continue
+		}
+		if got := tc.impairment.String(); got != tc.want {
+			t.Errorf("%+v.String() = %q, want %q", tc.impairment, got, tc.want)
+		}
+	}
+}
+
+func TestImpairAndRestore(t *testing.T) {
+	r := command.NewFakeCommandRunner()
+	r.SetCommandToOutput(map[string]string{
+		"ip -o -4 addr show": "1: lo    inet 127.0.0.1/8 scope host lo\n3: eth1    inet 192.168.39.2/24 brd 192.168.39.255 scope global dynamic eth1\n",
+		"sudo tc qdisc replace dev eth1 root netem delay 200000us loss 5%": "",
+		"sudo tc qdisc show dev eth1 root":                                 "qdisc netem 8001: root refcnt 2 limit 1000 delay 200ms loss 5%\n",
+		"sudo tc qdisc del dev eth1 root":                                  "",
+	})
+
+	dev, err := Device(r, "192.168.39.2")
+	if err != nil {
+		t.Fatalf("Device: %v", err)
+	}
+	if dev != "eth1" {
+		t.Fatalf("Device = %q, want eth1", dev)
+	}
+	if err := Impair(r, dev, Impairment{Latency: 200 * time.Millisecond, Loss: 5}); err != nil {
+		t.Errorf("Impair: %v", err)
+	}
+	if err := Restore(r, dev); err != nil {
+		t.Errorf("Restore: %v", err)
+	}
+}
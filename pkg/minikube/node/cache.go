@@ -29,6 +29,7 @@ import (
 	"github.com/spf13/viper"
 	"golang.org/x/sync/errgroup"
 	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/addons"
 	"k8s.io/minikube/pkg/drivers/kic"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
@@ -50,7 +51,19 @@ const (
 )
 
 // BeginCacheKubernetesImages caches images required for Kubernetes version in the background
-func beginCacheKubernetesImages(g *errgroup.Group, imageRepository string, k8sVersion string, cRuntime string, driverName string) {
+func beginCacheKubernetesImages(g *errgroup.Group, imageRepository string, k8sVersion string, cRuntime string, driverName string, preloadFrom string) {
+	// the preload of --preload-from replaces the one of minikube, and is required
+	if preloadFrom != "" {
+		if !driver.AllowsPreload(driverName) {
+			out.WarningT("The {{.driver}} driver does not use preloads, ignoring --preload-from", out.V{"driver": driverName})
+			return
+		}
+		if err := download.PreloadFrom(preloadFrom); err != nil {
+			exit.Error(reason.InetCachePreload, "Failed to cache the preload of --preload-from", err)
+		}
+		return
+	}
+
 	// TODO: remove imageRepository check once #7695 is fixed
 	if imageRepository == "" && download.PreloadExists(k8sVersion, cRuntime, driverName) {
 		klog.Info("Caching tarball of preloaded images")
@@ -72,14 +85,14 @@ func beginCacheKubernetesImages(g *errgroup.Group, imageRepository string, k8sVe
 }
 
 // handleDownloadOnly caches appropariate binaries and images
-func handleDownloadOnly(cacheGroup, kicGroup *errgroup.Group, k8sVersion, containerRuntime, driverName string) {
+func handleDownloadOnly(cacheGroup, kicGroup *errgroup.Group, k8sVersion, containerRuntime, driverName, preloadFrom string) {
 	// If --download-only, complete the remaining downloads and exit.
 	if !viper.GetBool("download-only") {
 		return
 	}
 
 	binariesURL := viper.GetString("binary-mirror")
-	if err := doCacheBinaries(k8sVersion, containerRuntime, driverName, binariesURL); err != nil {
+	if err := doCacheBinaries(k8sVersion, containerRuntime, driverName, preloadFrom, binariesURL); err != nil {
 		exit.Error(reason.InetCacheBinaries, "Failed to cache binaries", err)
 	}
 	if _, err := CacheKubectlBinary(k8sVersion, binariesURL); err != nil {
@@ -107,18 +120,18 @@ func CacheKubectlBinary(k8sVersion, binaryURL string) (string, error) {
 }
 
 // doCacheBinaries caches Kubernetes binaries in the foreground
-func doCacheBinaries(k8sVersion, containerRuntime, driverName, binariesURL string) error {
+func doCacheBinaries(k8sVersion, containerRuntime, driverName, preloadFrom, binariesURL string) error {
 	existingBinaries := constants.KubernetesReleaseBinaries
-	if !download.PreloadExists(k8sVersion, containerRuntime, driverName) {
+	if _, ok := download.PreloadTarball(k8sVersion, containerRuntime, driverName, preloadFrom); !ok {
 		existingBinaries = nil
 	}
 	return machine.CacheBinariesForBootstrapper(k8sVersion, existingBinaries, binariesURL)
 }
 
-// beginDownloadKicBaseImage downloads the kic image
-func beginDownloadKicBaseImage(g *errgroup.Group, cc *config.ClusterConfig, downloadOnly bool) {
+// beginDownloadKicBaseImage downloads the kic image for the kic driver drv
+func beginDownloadKicBaseImage(g *errgroup.Group, cc *config.ClusterConfig, drv string, downloadOnly bool) {
 
-	klog.Infof("Beginning downloading kic base image for %s with %s", cc.Driver, cc.KubernetesConfig.ContainerRuntime)
+	klog.Infof("Beginning downloading kic base image for %s with %s", drv, cc.KubernetesConfig.ContainerRuntime)
 	register.Reg.SetStep(register.PullingBaseImage)
 	out.Step(style.Pulling, "Pulling base image {{.kicVersion}} ...", out.V{"kicVersion": kic.Version})
 	g.Go(func() error {
@@ -140,7 +153,7 @@ func beginDownloadKicBaseImage(g *errgroup.Group, cc *config.ClusterConfig, down
 		for _, img := range append([]string{baseImg}, kic.FallbackImages...) {
 			var err error
 
-			if driver.IsDocker(cc.Driver) && download.ImageExistsInDaemon(img) && !downloadOnly {
+			if driver.IsDocker(drv) && download.ImageExistsInDaemon(img) && !downloadOnly {
 				klog.Infof("%s exists in daemon, skipping load", img)
 				finalImg = img
 				return nil
@@ -155,10 +168,10 @@ func beginDownloadKicBaseImage(g *errgroup.Group, cc *config.ClusterConfig, down
 				return nil
 			}
 
-			if cc.Driver == driver.Podman {
+			if drv == driver.Podman {
 				return fmt.Errorf("not yet implemented, see issue #8426")
 			}
-			if driver.IsDocker(cc.Driver) && err == nil {
+			if driver.IsDocker(drv) && err == nil {
 				klog.Infof("Loading %s from local cache", img)
 				if finalImg, err = download.CacheToDaemon(img); err == nil {
 					klog.Infof("successfully loaded and using %s from cached tarball", img)
@@ -231,20 +244,72 @@ func CacheAndLoadImagesInConfig(profiles []*config.Profile) error {
 }
 
 func imagesInConfigFile() ([]string, error) {
+	entries, err := cacheEntriesInConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	images := []string{}
+	for _, e := range entries {
+		if !image.IsArtifact(e) {
+			images = append(images, e)
+		}
+	}
+	return images, nil
+}
+
+// ArtifactsInConfigFile returns the OCI artifacts added with 'cache add'
+func ArtifactsInConfigFile() ([]string, error) {
+	entries, err := cacheEntriesInConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	artifacts := []string{}
+	for _, e := range entries {
+		if image.IsArtifact(e) {
+			artifacts = append(artifacts, e)
+		}
+	}
+	return artifacts, nil
+}
+
+func cacheEntriesInConfigFile() ([]string, error) {
 	configFile, err := config.ReadConfig(localpath.ConfigFile())
 	if err != nil {
 		return nil, errors.Wrap(err, "read")
 	}
 	if values, ok := configFile[cacheImageConfigKey]; ok {
-		var images []string
+		var entries []string
 		for key := range values.(map[string]interface{}) {
-			images = append(images, key)
+			entries = append(entries, key)
 		}
-		return images, nil
+		return entries, nil
 	}
 	return []string{}, nil
 }
 
+// PushArtifactsToRegistry pushes the cached artifacts to the registry addon of the profiles, so charts can be installed offline.
+// Profiles without the registry addon are skipped.
+func PushArtifactsToRegistry(artifacts []string, profiles []*config.Profile) error {
+	if len(artifacts) == 0 {
+		return nil
+	}
+	for _, p := range profiles {
+		if p.Config == nil || !p.Config.Addons["registry"] {
+			out.Styled(style.Tip, `Enable the registry addon of "{{.profile}}" to serve the cached artifacts in the cluster: minikube addons enable registry -p {{.profile}}`, out.V{"profile": p.Name})
+			continue
+		}
+		addr, err := addons.RegistryAddr(p.Config)
+		if err != nil {
+			return errors.Wrapf(err, "registry addon of %s", p.Name)
+		}
+		if err := image.PushArtifacts(artifacts, image.ArtifactCacheDir(), addr, localpath.CACert()); err != nil {
+			return err
+		}
+		out.Step(style.Copying, `Pushed {{.count}} artifacts to the registry addon of "{{.profile}}" at {{.addr}}`, out.V{"count": len(artifacts), "profile": p.Name, "addr": addr})
+	}
+	return nil
+}
+
 func updateKicImageRepo(imgName string, repo string) string {
 	image := strings.TrimPrefix(imgName, "gcr.io/")
 	if repo == constants.AliyunMirror {
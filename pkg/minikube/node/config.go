@@ -18,27 +18,24 @@ package node
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"regexp"
-	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/spf13/viper"
 	"k8s.io/klog/v2"
+	pkgdrivers "k8s.io/minikube/pkg/drivers"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/command"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/cruntime"
 	"k8s.io/minikube/pkg/minikube/driver"
 	"k8s.io/minikube/pkg/minikube/exit"
-	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/out/register"
 	"k8s.io/minikube/pkg/minikube/reason"
 	"k8s.io/minikube/pkg/minikube/style"
-	"k8s.io/minikube/pkg/util/lock"
 )
 
 func maskProxyPassword(v string) string {
@@ -101,59 +98,26 @@ func showNoK8sVersionInfo(cr cruntime.Manager) {
 }
 
 // configureMounts configures any requested filesystem mounts
-func configureMounts(wg *sync.WaitGroup, cc config.ClusterConfig) {
+func configureMounts(wg *sync.WaitGroup, cc config.ClusterConfig, runner command.Runner) {
 	wg.Add(1)
 	defer wg.Done()
 
-	if !cc.Mount || driver.IsKIC(cc.Driver) {
+	// the KIC drivers mount the directory in the container on its creation, unless it is synced
+	if !cc.Mount || (driver.IsKIC(cc.Driver) && cc.MountType != constants.SyncMountType) {
 		return
 	}
 
-	out.Step(style.Mounting, "Creating mount {{.name}} ...", out.V{"name": cc.MountString})
-	path := os.Args[0]
-	profile := viper.GetString("profile")
-
-	args := generateMountArgs(profile, cc)
-	mountCmd := exec.Command(path, args...)
-	mountCmd.Env = append(os.Environ(), constants.IsMinikubeChildProcess+"=true")
-	if klog.V(8).Enabled() {
-		mountCmd.Stdout = os.Stdout
-		mountCmd.Stderr = os.Stderr
-	}
-	if err := mountCmd.Start(); err != nil {
-		exit.Error(reason.GuestMount, "Error starting mount", err)
-	}
-	if err := lock.AppendToFile(filepath.Join(localpath.Profile(profile), constants.MountProcessFileName), []byte(fmt.Sprintf(" %s", strconv.Itoa(mountCmd.Process.Pid))), 0o644); err != nil {
-		exit.Error(reason.HostMountPid, "Error writing mount pid", err)
-	}
-}
-
-func generateMountArgs(profile string, cc config.ClusterConfig) []string {
-	mountDebugVal := 0
-	if klog.V(8).Enabled() {
-		mountDebugVal = 1
+	// the virtiofs share was attached to the VM on creation, it only has to be mounted in the guest
+	if host, guest := config.VirtiofsMount(cc); host != "" {
+		out.Step(style.Mounting, "Mounting {{.name}} with virtiofs ...", out.V{"name": cc.MountString})
+		if err := cluster.MountVirtiofs(runner, pkgdrivers.VirtiofsTag, guest); err != nil {
+			exit.Error(reason.GuestMount, "Error mounting with virtiofs", err)
+		}
+		return
 	}
 
-	args := []string{"mount", cc.MountString}
-	flags := []struct {
-		name  string
-		value string
-	}{
-		{"profile", profile},
-		{"v", fmt.Sprintf("%d", mountDebugVal)},
-		{constants.Mount9PVersionFlag, cc.Mount9PVersion},
-		{constants.MountGIDFlag, cc.MountGID},
-		{constants.MountIPFlag, cc.MountIP},
-		{constants.MountMSizeFlag, fmt.Sprintf("%d", cc.MountMSize)},
-		{constants.MountPortFlag, fmt.Sprintf("%d", cc.MountPort)},
-		{constants.MountTypeFlag, cc.MountType},
-		{constants.MountUIDFlag, cc.MountUID},
-	}
-	for _, flag := range flags {
-		args = append(args, fmt.Sprintf("--%s", flag.name), flag.value)
-	}
-	for _, option := range cc.MountOptions {
-		args = append(args, fmt.Sprintf("--%s", constants.MountOptionsFlag), option)
+	out.Step(style.Mounting, "Creating mount {{.name}} ...", out.V{"name": cc.MountString})
+	if err := cluster.StartMountProcess(viper.GetString("profile"), cc, cc.MountString); err != nil {
+		exit.Error(reason.GuestMount, "Error starting mount", err)
 	}
-	return args
 }
@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/out"
+)
+
+// drainRetryInterval is how often a blocked eviction is retried, and the eviction of the pods checked
+var drainRetryInterval = 5 * time.Second
+
+// DrainOptions are the options of draining a node
+type DrainOptions struct {
+	// GracePeriod is how long the evicted pods have to terminate, negative to use their own termination grace period
+	GracePeriod int
+	// Force also evicts the pods not managed by a controller, which are not recreated on another node
+	Force bool
+	// Timeout is how long to wait for the pods to be evicted
+	Timeout time.Duration
+}
+
+// Drain cordons a node, and evicts its pods through the eviction API, so that their disruption budgets are honored
+func Drain(cc config.ClusterConfig, name string, opts DrainOptions) error {
+	n, _, err := Retrieve(cc, name)
+	if err != nil {
+		return errors.Wrap(err, "retrieve")
+	}
+	client, err := kapi.Client(cc.Name)
+	if err != nil {
+		return errors.Wrap(err, "client")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	return drain(ctx, client, config.MachineName(cc, *n), opts)
+}
+
+func drain(ctx context.Context, client kubernetes.Interface, nodeName string, opts DrainOptions) error {
+	node, err := client.CoreV1().Nodes().Get(ctx, nodeName, meta.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "get node %s", nodeName)
+	}
+	if !node.Spec.Unschedulable {
+		node.Spec.Unschedulable = true
+		if _, err := client.CoreV1().Nodes().Update(ctx, node, meta.UpdateOptions{}); err != nil {
+			return errors.Wrapf(err, "cordon node %s", nodeName)
+		}
+	}
+	klog.Infof("cordoned node %q", nodeName)
+
+	pods, err := client.CoreV1().Pods("").List(ctx, meta.ListOptions{FieldSelector: "spec.nodeName=" + nodeName})
+	if err != nil {
+		return errors.Wrapf(err, "list pods of %s", nodeName)
+	}
+	evict, unmanaged := drainablePods(pods.Items)
+	if len(unmanaged) > 0 {
+		if !opts.Force {
+			return fmt.Errorf("the pods %s are not managed by a controller and would not be recreated, use --force to evict them anyway", strings.Join(unmanaged, ", "))
+		}
+		out.WarningT("Evicting the pods {{.pods}}, which are not managed by a controller", out.V{"pods": strings.Join(unmanaged, ", ")})
+	}
+
+	var grace *int64
+	if opts.GracePeriod >= 0 {
+		g := int64(opts.GracePeriod)
+		grace = &g
+	}
+	for _, p := range evict {
+		if err := evictPod(ctx, client, p, grace); err != nil {
+			return err
+		}
+		out.Infof("Evicted pod {{.namespace}}/{{.pod}}", out.V{"namespace": p.Namespace, "pod": p.Name})
+	}
+	return waitForEviction(ctx, client, evict)
+}
+
+// drainablePods returns the pods to evict, and the names of those without a controller among them.
+// The pods of DaemonSets, the static pods and the terminated pods stay on the node.
+func drainablePods(pods []core.Pod) (evict []core.Pod, unmanaged []string) {
+	for _, p := range pods {
+		if _, ok := p.Annotations[core.MirrorPodAnnotationKey]; ok {
+			continue
+		}
+		if p.Status.Phase == core.PodSucceeded || p.Status.Phase == core.PodFailed {
+			continue
+		}
+		c := meta.GetControllerOf(&p)
+		if c != nil && c.Kind == "DaemonSet" {
+			continue
+		}
+		if c == nil {
+			unmanaged = append(unmanaged, p.Namespace+"/"+p.Name)
+		}
+		evict = append(evict, p)
+	}
+	return evict, unmanaged
+}
+
+// evictPod evicts a pod, retrying while a disruption budget blocks its eviction
+func evictPod(ctx context.Context, client kubernetes.Interface, p core.Pod, grace *int64) error {
+	eviction := &policy.Eviction{
+		ObjectMeta:    meta.ObjectMeta{Name: p.Name, Namespace: p.Namespace},
+		DeleteOptions: &meta.DeleteOptions{GracePeriodSeconds: grace},
+	}
+	for {
+		err := client.PolicyV1().Evictions(p.Namespace).Evict(ctx, eviction)
+		switch {
+		case err == nil, apierrors.IsNotFound(err):
+			return nil
+		case !apierrors.IsTooManyRequests(err):
+			return errors.Wrapf(err, "evict pod %s/%s", p.Namespace, p.Name)
+		}
+		klog.Infof("eviction of %s/%s blocked by a disruption budget, retrying: %v", p.Namespace, p.Name, err)
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "evict pod %s/%s blocked by a disruption budget", p.Namespace, p.Name)
+		case <-time.After(drainRetryInterval):
+		}
+	}
+}
+
+// waitForEviction waits for the evicted pods to be gone
+func waitForEviction(ctx context.Context, client kubernetes.Interface, pods []core.Pod) error {
+	for _, p := range pods {
+		for {
+			cur, err := client.CoreV1().Pods(p.Namespace).Get(ctx, p.Name, meta.GetOptions{})
+			if apierrors.IsNotFound(err) || (err == nil && cur.UID != p.UID) {
+				break
+			}
+			if err != nil && ctx.Err() == nil {
+				klog.Warningf("get pod %s/%s: %v", p.Namespace, p.Name, err)
+			}
+			select {
+			case <-ctx.Done():
+				return errors.Wrapf(ctx.Err(), "wait for pod %s/%s to terminate", p.Namespace, p.Name)
+			case <-time.After(drainRetryInterval):
+			}
+		}
+	}
+	return nil
+}
+
+// Uncordon makes a node drained by Drain schedulable again
+func Uncordon(cc config.ClusterConfig, name string) error {
+	n, _, err := Retrieve(cc, name)
+	if err != nil {
+		return errors.Wrap(err, "retrieve")
+	}
+	client, err := kapi.Client(cc.Name)
+	if err != nil {
+		return errors.Wrap(err, "client")
+	}
+	return uncordon(context.Background(), client, config.MachineName(cc, *n))
+}
+
+func uncordon(ctx context.Context, client kubernetes.Interface, nodeName string) error {
+	node, err := client.CoreV1().Nodes().Get(ctx, nodeName, meta.GetOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "get node %s", nodeName)
+	}
+	if !node.Spec.Unschedulable {
+		return nil
+	}
+	node.Spec.Unschedulable = false
+	if _, err := client.CoreV1().Nodes().Update(ctx, node, meta.UpdateOptions{}); err != nil {
+		return errors.Wrapf(err, "uncordon node %s", nodeName)
+	}
+	klog.Infof("uncordoned node %q", nodeName)
+	return nil
+}
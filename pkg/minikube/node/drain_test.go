@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	policy "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func drainTestPod(name string, controller string, mods ...func(*core.Pod)) *core.Pod {
+	p := &core.Pod{
+		ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "default", UID: types.UID("uid-" + name)},
+		Spec:       core.PodSpec{NodeName: "minikube-m02"},
+		Status:     core.PodStatus{Phase: core.PodRunning},
+	}
+	if controller != "" {
+		yes := true
+		p.OwnerReferences = []meta.OwnerReference{{Kind: controller, Name: name + "-owner", Controller: &yes}}
+	}
+	for _, m := range mods {
+		m(p)
+	}
+	return p
+}
+
+func TestDrainablePods(t *testing.T) {
+	pods := []core.Pod{
+		*drainTestPod("web", "ReplicaSet"),
+		*drainTestPod("agent", "DaemonSet"),
+		*drainTestPod("static", "Node", func(p *core.Pod) { p.Annotations = map[string]string{core.MirrorPodAnnotationKey: "x"} }),
+		*drainTestPod("done", "Job", func(p *core.Pod) { p.Status.Phase = core.PodSucceeded }),
+		*drainTestPod("bare", ""),
+	}
+	evict, unmanaged := drainablePods(pods)
+	var names []string
+	for _, p := range evict {
+		names = append(names, p.Name)
+	}
+	if len(names) != 2 || names[0] != "web" || names[1] != "bare" {
+		t.Errorf("drainablePods() evicts %v, want [web bare]", names)
+	}
+	if len(unmanaged) != 1 || unmanaged[0] != "default/bare" {
+		t.Errorf("drainablePods() unmanaged = %v, want [default/bare]", unmanaged)
+	}
+}
+
+// evictionClient returns a fake client evicting the pods, after blocking the first blocked evictions with a disruption budget
+func evictionClient(blocked int, objs ...runtime.Object) *fake.Clientset {
+	client := fake.NewSimpleClientset(objs...)
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		if blocked > 0 {
+			blocked--
+			return true, nil, apierrors.NewTooManyRequests("disruption budget", 0)
+		}
+		name := action.(k8stesting.CreateAction).GetObject().(*policy.Eviction).Name
+		return true, nil, client.Tracker().Delete(schema.GroupVersionResource{Version: "v1", Resource: "pods"}, action.GetNamespace(), name)
+	})
+	return client
+}
+
+func TestDrain(t *testing.T) {
+	defer func(d time.Duration) { drainRetryInterval = d }(drainRetryInterval)
+	drainRetryInterval = time.Millisecond
+
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: "minikube-m02"}}
+	tests := []struct {
+		description string
+		force       bool
+		blocked     int
+		pods        []runtime.Object
+		wantErr     bool
+		wantLeft    int
+	}{
+		{description: "managed pods", pods: []runtime.Object{drainTestPod("web", "ReplicaSet"), drainTestPod("agent", "DaemonSet")}, wantLeft: 1},
+		{description: "disruption budget", blocked: 3, pods: []runtime.Object{drainTestPod("web", "ReplicaSet")}},
+		{description: "unmanaged pod", pods: []runtime.Object{drainTestPod("web", "ReplicaSet"), drainTestPod("bare", "")}, wantErr: true, wantLeft: 2},
+		{description: "forced unmanaged pod", force: true, pods: []runtime.Object{drainTestPod("web", "ReplicaSet"), drainTestPod("bare", "")}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			client := evictionClient(tc.blocked, append(tc.pods, node.DeepCopy())...)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			err := drain(ctx, client, "minikube-m02", DrainOptions{GracePeriod: -1, Force: tc.force})
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("drain() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			n, err := client.CoreV1().Nodes().Get(ctx, "minikube-m02", meta.GetOptions{})
+			if err != nil {
+				t.Fatalf("get node: %v", err)
+			}
+			if !n.Spec.Unschedulable {
+				t.Errorf("drain() did not cordon the node")
+			}
+			pods, err := client.CoreV1().Pods("").List(ctx, meta.ListOptions{})
+			if err != nil {
+				t.Fatalf("list pods: %v", err)
+			}
+			if len(pods.Items) != tc.wantLeft {
+				t.Errorf("drain() left %d pods, want %d", len(pods.Items), tc.wantLeft)
+			}
+		})
+	}
+}
+
+func TestUncordon(t *testing.T) {
+	node := &core.Node{ObjectMeta: meta.ObjectMeta{Name: "minikube-m02"}, Spec: core.NodeSpec{Unschedulable: true}}
+	client := fake.NewSimpleClientset(node)
+	ctx := context.Background()
+	if err := uncordon(ctx, client, "minikube-m02"); err != nil {
+		t.Fatalf("uncordon: %v", err)
+	}
+	n, err := client.CoreV1().Nodes().Get(ctx, "minikube-m02", meta.GetOptions{})
+	if err != nil {
+		t.Fatalf("get node: %v", err)
+	}
+	if n.Spec.Unschedulable {
+		t.Errorf("uncordon() left the node unschedulable")
+	}
+	if err := uncordon(ctx, client, "minikube-m03"); err == nil {
+		t.Errorf("uncordon() of a missing node succeeded, expected an error")
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/network"
+	"k8s.io/minikube/pkg/util/retry"
+)
+
+// saveExtraNetworkIPs records the IPs of the node on the extra networks in the node config.
+// The drivers attach the extra networks after the network of the cluster, so they are the last NICs of the node.
+func saveExtraNetworkIPs(cc *config.ClusterConfig, n *config.Node, r command.Runner) {
+	nets, err := network.ParseExtraNetworks(cc.ExtraNetworks)
+	if err != nil || len(nets) == 0 {
+		return
+	}
+	var ips map[string]string
+	lookup := func() error {
+		links, err := r.RunCmd(exec.Command("ip", "-o", "link", "show"))
+		if err != nil {
+			return errors.Wrap(err, "listing links")
+		}
+		addrs, err := r.RunCmd(exec.Command("ip", "-o", "-4", "addr", "show"))
+		if err != nil {
+			return errors.Wrap(err, "listing addresses")
+		}
+		ips, err = extraNetworkIPs(nets, links.Stdout.String(), addrs.Stdout.String())
+		return err
+	}
+	// the NICs on networks with DHCP may take a while to get their address
+	if err := retry.Expo(lookup, time.Second, 30*time.Second); err != nil {
+		out.WarningT("Unable to find the IPs of node {{.name}} on the extra networks: {{.error}}", out.V{"name": n.Name, "error": err})
+	}
+	klog.Infof("IPs of node %q on the extra networks: %v", n.Name, ips)
+	n.ExtraIPs = ips
+	if err := config.SaveNode(cc, n); err != nil {
+		klog.Warningf("failed to save the IPs of node %q on the extra networks: %v", n.Name, err)
+	}
+}
+
+// extraNetworkIPs maps the extra networks to the IPv4 addresses of the last ethernet interfaces,
+// from the output of `ip -o link show` and `ip -o -4 addr show`
+func extraNetworkIPs(nets []network.ExtraNetwork, links, addrs string) (map[string]string, error) {
+	var ifaces []string
+	for _, line := range strings.Split(links, "\n") {
+		// 3: eth1@if12: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 ...
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		name, _, _ := strings.Cut(strings.TrimSuffix(fields[1], ":"), "@")
+		if strings.HasPrefix(name, "eth") {
+			ifaces = append(ifaces, name)
+		}
+	}
+	if len(ifaces) < len(nets) {
+		return nil, fmt.Errorf("found %d ethernet interfaces, expected at least %d", len(ifaces), len(nets))
+	}
+	ifaces = ifaces[len(ifaces)-len(nets):]
+
+	ifaceIPs := map[string]string{}
+	for _, line := range strings.Split(addrs, "\n") {
+		// 3: eth1    inet 192.168.58.2/24 brd 192.168.58.255 scope global eth1\       valid_lft forever ...
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[2] != "inet" {
+			continue
+		}
+		name, _, _ := strings.Cut(fields[1], "@")
+		if _, ok := ifaceIPs[name]; !ok {
+			ip, _, _ := strings.Cut(fields[3], "/")
+			ifaceIPs[name] = ip
+		}
+	}
+
+	ips := map[string]string{}
+	var missing []string
+	for i, n := range nets {
+		ip, ok := ifaceIPs[ifaces[i]]
+		if !ok {
+			missing = append(missing, fmt.Sprintf("%s (%s)", n.Name, ifaces[i]))
+			continue
+		}
+		ips[n.Name] = ip
+	}
+	if len(missing) > 0 {
+		return ips, fmt.Errorf("no IPv4 address on %s", strings.Join(missing, ", "))
+	}
+	return ips, nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/minikube/pkg/network"
+)
+
+func TestExtraNetworkIPs(t *testing.T) {
+	nets := []network.ExtraNetwork{{Name: "mgmt", Network: "mgmt"}, {Name: "storage", Network: "virbr2"}}
+	links := `1: lo: <LOOPBACK,UP,LOWER_UP> mtu 65536 qdisc noqueue state UNKNOWN mode DEFAULT group default qlen 1000\    link/loopback 00:00:00:00:00:00 brd 00:00:00:00:00:00
+2: eth0: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc pfifo_fast state UP mode DEFAULT group default qlen 1000\    link/ether 52:54:00:6c:1b:0a brd ff:ff:ff:ff:ff:ff
+3: eth1: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc pfifo_fast state UP mode DEFAULT group default qlen 1000\    link/ether 52:54:00:2f:4d:11 brd ff:ff:ff:ff:ff:ff
+4: docker0: <NO-CARRIER,BROADCAST,MULTICAST,UP> mtu 1500 qdisc noqueue state DOWN mode DEFAULT group default\    link/ether 02:42:3a:7c:9f:01 brd ff:ff:ff:ff:ff:ff
+5: eth2@if14: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc noqueue state UP mode DEFAULT group default\    link/ether 02:42:c0:a8:3a:02 brd ff:ff:ff:ff:ff:ff link-netnsid 0
+6: eth3@if16: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc noqueue state UP mode DEFAULT group default\    link/ether 02:42:c0:a8:43:02 brd ff:ff:ff:ff:ff:ff link-netnsid 0
+`
+	addrs := `1: lo    inet 127.0.0.1/8 scope host lo\       valid_lft forever preferred_lft forever
+2: eth0    inet 192.168.39.10/24 brd 192.168.39.255 scope global dynamic eth0\       valid_lft 3590sec preferred_lft 3590sec
+3: eth1    inet 192.168.122.15/24 brd 192.168.122.255 scope global dynamic eth1\       valid_lft 3590sec preferred_lft 3590sec
+4: docker0    inet 172.17.0.1/16 brd 172.17.255.255 scope global docker0\       valid_lft forever preferred_lft forever
+5: eth2    inet 192.168.58.2/24 brd 192.168.58.255 scope global eth2\       valid_lft forever preferred_lft forever
+6: eth3    inet 192.168.67.2/24 brd 192.168.67.255 scope global eth3\       valid_lft forever preferred_lft forever
+`
+	got, err := extraNetworkIPs(nets, links, addrs)
+	if err != nil {
+		t.Fatalf("extraNetworkIPs: %v", err)
+	}
+	want := map[string]string{"mgmt": "192.168.58.2", "storage": "192.168.67.2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extraNetworkIPs = %v, want %v", got, want)
+	}
+
+	// eth3 has not got an address from DHCP yet
+	addrs = `2: eth0    inet 192.168.39.10/24 brd 192.168.39.255 scope global dynamic eth0
+5: eth2    inet 192.168.58.2/24 brd 192.168.58.255 scope global eth2
+`
+	got, err = extraNetworkIPs(nets, links, addrs)
+	if err == nil {
+		t.Errorf("extraNetworkIPs: expected an error for the interface without address")
+	}
+	want = map[string]string{"mgmt": "192.168.58.2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extraNetworkIPs = %v, want %v", got, want)
+	}
+
+	if _, err := extraNetworkIPs(nets, "2: eth0: <BROADCAST> mtu 1500\n", addrs); err == nil {
+		t.Errorf("extraNetworkIPs: expected an error for the missing interfaces")
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/docker/machine/libmachine"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/network"
+)
+
+// hybridSubnet returns the subnet of the node and the address of the host on it, as seen from the host
+func hybridSubnet(n config.Node) (string, string, error) {
+	p, err := network.Inspect(n.IP)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "inspecting the network of node %q", n.Name)
+	}
+	return p.CIDR, p.Gateway, nil
+}
+
+// routeHybridNetworks routes the traffic between the node and the nodes running on the other driver of a hybrid cluster:
+// the host forwards it between the networks of the drivers, and the nodes send it to the host
+func routeHybridNetworks(api libmachine.API, cc config.ClusterConfig, n config.Node, runner command.Runner) error {
+	if !config.IsHybrid(cc) {
+		return nil
+	}
+	subnet, gateway, err := hybridSubnet(n)
+	if err != nil {
+		return err
+	}
+	for _, other := range cc.Nodes {
+		if other.IP == "" || config.NodeDriver(cc, other) == config.NodeDriver(cc, n) {
+			continue
+		}
+		otherSubnet, otherGateway, err := hybridSubnet(other)
+		if err != nil {
+			return err
+		}
+		if otherSubnet == subnet {
+			continue
+		}
+		if err := network.AllowForwarding(subnet, otherSubnet); err != nil {
+			return errors.Wrapf(err, "forwarding between %s and %s", subnet, otherSubnet)
+		}
+		if err := addRoute(runner, otherSubnet, gateway); err != nil {
+			return errors.Wrapf(err, "routing node %q to %s", n.Name, otherSubnet)
+		}
+		// the routes of the other node are gone when it restarted since
+		h, err := machine.LoadHost(api, config.MachineName(cc, other))
+		if err != nil {
+			klog.Warningf("unable to route node %q to %s: %v", other.Name, subnet, err)
+			continue
+		}
+		r, err := machine.CommandRunner(h)
+		if err != nil {
+			klog.Warningf("unable to route node %q to %s: %v", other.Name, subnet, err)
+			continue
+		}
+		if err := addRoute(r, subnet, otherGateway); err != nil {
+			klog.Warningf("unable to route node %q to %s: %v", other.Name, subnet, err)
+		}
+	}
+	return nil
+}
+
+// addRoute routes the traffic of the node to subnet through the gateway
+func addRoute(runner command.Runner, subnet, gateway string) error {
+	_, err := runner.RunCmd(exec.Command("sudo", "ip", "route", "replace", subnet, "via", gateway))
+	return err
+}
+
+// UnrouteHybridNetworks removes the forwarding of the host between the networks of a hybrid cluster
+func UnrouteHybridNetworks(cc config.ClusterConfig) {
+	subnets := map[string]string{}
+	for _, n := range cc.Nodes {
+		if n.IP == "" {
+			continue
+		}
+		if subnet, _, err := hybridSubnet(n); err == nil {
+			subnets[subnet] = config.NodeDriver(cc, n)
+		}
+	}
+	for a, drvA := range subnets {
+		for b, drvB := range subnets {
+			if a < b && drvA != drvB {
+				klog.Infof("removing forwarding between %s and %s", a, b)
+				network.RemoveForwarding(a, b)
+			}
+		}
+	}
+}
+
+// ValidateHybrid checks that a node of the cluster can run on drv, making a hybrid cluster of a VM driver and a container driver
+func ValidateHybrid(cc config.ClusterConfig, drv string, goos string) error {
+	if drv == cc.Driver {
+		return nil
+	}
+	if goos != "linux" {
+		return fmt.Errorf("the nodes of a cluster can only run on different drivers on Linux")
+	}
+	vm, kic := cc.Driver, drv
+	if driver.IsKIC(cc.Driver) {
+		vm, kic = drv, cc.Driver
+	}
+	if !driver.IsKVM(vm) || !driver.IsKIC(kic) {
+		return fmt.Errorf("the nodes of a cluster can only run on the kvm2 driver and the docker or podman driver, not %s and %s", cc.Driver, drv)
+	}
+	// the host forwards the traffic between the networks of the drivers
+	if oci.IsExternalDaemonHost(kic) {
+		return fmt.Errorf("the nodes of a cluster can not run on a remote %s daemon and on the %s driver", kic, vm)
+	}
+	for _, n := range cc.Nodes {
+		if d := config.NodeDriver(cc, n); d != cc.Driver && d != drv {
+			return fmt.Errorf("the nodes of the cluster already run on %s and %s", cc.Driver, d)
+		}
+	}
+	// the pod networks of the nodes are not on the same link, the CNI needs an overlay
+	switch cc.KubernetesConfig.CNI {
+	case "flannel", "calico", "cilium":
+	default:
+		return fmt.Errorf("the nodes of a cluster on different drivers need an overlay network, start the cluster with --cni=flannel, calico or cilium")
+	}
+	return nil
+}
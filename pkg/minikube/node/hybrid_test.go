@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestValidateHybrid(t *testing.T) {
+	kvm := config.ClusterConfig{Driver: "kvm2", KubernetesConfig: config.KubernetesConfig{CNI: "flannel"}, Nodes: []config.Node{{Name: ""}}}
+	docker := config.ClusterConfig{Driver: "docker", KubernetesConfig: config.KubernetesConfig{CNI: "calico"}, Nodes: []config.Node{{Name: ""}}}
+	kindnet := config.ClusterConfig{Driver: "kvm2", KubernetesConfig: config.KubernetesConfig{CNI: ""}, Nodes: []config.Node{{Name: ""}}}
+	mixed := config.ClusterConfig{Driver: "kvm2", KubernetesConfig: config.KubernetesConfig{CNI: "flannel"}, Nodes: []config.Node{{Name: ""}, {Name: "m02", Driver: "docker"}}}
+
+	tests := []struct {
+		description string
+		cc          config.ClusterConfig
+		drv         string
+		goos        string
+		valid       bool
+	}{
+		{"same driver", kindnet, "kvm2", "darwin", true},
+		{"docker nodes in a kvm2 cluster", kvm, "docker", "linux", true},
+		{"podman nodes in a kvm2 cluster", kvm, "podman", "linux", true},
+		{"kvm2 nodes in a docker cluster", docker, "kvm2", "linux", true},
+		{"not on linux", kvm, "docker", "darwin", false},
+		{"two VM drivers", kvm, "qemu2", "linux", false},
+		{"two kic drivers", docker, "podman", "linux", false},
+		{"no overlay", kindnet, "docker", "linux", false},
+		{"third driver", mixed, "podman", "linux", false},
+		{"same second driver", mixed, "docker", "linux", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			err := ValidateHybrid(tc.cc, tc.drv, tc.goos)
+			if (err == nil) != tc.valid {
+				t.Errorf("ValidateHybrid(%s, %s) = %v, want valid = %t", tc.cc.Driver, tc.drv, err, tc.valid)
+			}
+		})
+	}
+}
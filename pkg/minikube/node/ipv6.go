@@ -0,0 +1,48 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/network"
+)
+
+// configureIPv6 prepares the node of an ipv6 or dual cluster: it enables IPv6 forwarding for the pods, and on a VM
+// adds the IPv6 address of the node to the interface of its IPv4 one, as the container drivers assign it themselves
+func configureIPv6(runner command.Runner, cc config.ClusterConfig, n config.Node) error {
+	if !config.HasIPv6(cc) {
+		return nil
+	}
+	if _, err := runner.RunCmd(exec.Command("sudo", "sysctl", "-w", "net.ipv6.conf.all.disable_ipv6=0", "net.ipv6.conf.all.forwarding=1")); err != nil {
+		return errors.Wrap(err, "enabling IPv6 forwarding")
+	}
+	if driver.IsKIC(config.NodeDriver(cc, n)) {
+		return nil
+	}
+	addr := fmt.Sprintf("%s/%d", n.IPv6, network.IPv6Prefix)
+	cmd := fmt.Sprintf("sudo ip -6 addr replace %s dev $(ip -o -4 addr show to %s | awk '{print $2}')", addr, n.IP)
+	if _, err := runner.RunCmd(exec.Command("/bin/bash", "-c", cmd)); err != nil {
+		return errors.Wrapf(err, "adding the IPv6 address %s", addr)
+	}
+	return nil
+}
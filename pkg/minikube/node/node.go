@@ -21,12 +21,14 @@ import (
 	"fmt"
 	"os/exec"
 
+	"github.com/docker/machine/libmachine"
 	"github.com/pkg/errors"
 	"github.com/spf13/viper"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
 	"k8s.io/minikube/pkg/minikube/config"
 	"k8s.io/minikube/pkg/minikube/machine"
 )
@@ -133,24 +135,58 @@ func Delete(cc config.ClusterConfig, name string) (*config.Node, error) {
 		return n, err
 	}
 
+	if config.IsWindows(*n) {
+		if err := DeleteWindows(cc, *n); err != nil {
+			return n, err
+		}
+		return n, removeNode(cc, name)
+	}
+
 	m := config.MachineName(cc, *n)
 	api, err := machine.NewAPIClient()
 	if err != nil {
 		return n, err
 	}
 
+	// a control plane has to leave etcd before its machine goes away, or the remaining members may lose their quorum
+	if n.ControlPlane {
+		resetControlPlane(api, cc, m)
+	}
+
 	err = machine.DeleteHost(api, m)
 	if err != nil {
 		return n, err
 	}
 
+	return n, removeNode(cc, name)
+}
+
+// removeNode removes the node from the cluster config
+func removeNode(cc config.ClusterConfig, name string) error {
 	_, index, err := Retrieve(cc, name)
 	if err != nil {
-		return n, errors.Wrap(err, "retrieve")
+		return errors.Wrap(err, "retrieve")
 	}
 
 	cc.Nodes = append(cc.Nodes[:index], cc.Nodes[index+1:]...)
-	return n, config.SaveProfile(viper.GetString(config.ProfileName), &cc)
+	return config.SaveProfile(viper.GetString(config.ProfileName), &cc)
+}
+
+// resetControlPlane resets the control plane on the machine, which removes its etcd member (intentionally non-fatal)
+func resetControlPlane(api libmachine.API, cc config.ClusterConfig, machineName string) {
+	host, err := machine.LoadHost(api, machineName)
+	if err != nil {
+		klog.Warningf("unable to load control plane %q to reset it: %v", machineName, err)
+		return
+	}
+	runner, err := machine.CommandRunner(host)
+	if err != nil {
+		klog.Warningf("unable to reach control plane %q to reset it: %v", machineName, err)
+		return
+	}
+	if _, err := runner.RunCmd(exec.Command("/bin/bash", "-c", fmt.Sprintf("%s reset --force", bsutil.InvokeKubeadm(cc.KubernetesConfig.KubernetesVersion)))); err != nil {
+		klog.Warningf("unable to reset control plane %q: %v", machineName, err)
+	}
 }
 
 // Retrieve finds the node by name in the given cluster
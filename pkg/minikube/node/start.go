@@ -17,6 +17,8 @@ limitations under the License.
 package node
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"net"
 	"os"
@@ -40,6 +42,7 @@ import (
 	"k8s.io/minikube/pkg/addons"
 	"k8s.io/minikube/pkg/drivers/kic/oci"
 	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/assets"
 	"k8s.io/minikube/pkg/minikube/bootstrapper"
 	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
 	"k8s.io/minikube/pkg/minikube/bootstrapper/images"
@@ -51,8 +54,11 @@ import (
 	"k8s.io/minikube/pkg/minikube/constants"
 	"k8s.io/minikube/pkg/minikube/cruntime"
 	"k8s.io/minikube/pkg/minikube/detect"
+	"k8s.io/minikube/pkg/minikube/download"
 	"k8s.io/minikube/pkg/minikube/driver"
 	"k8s.io/minikube/pkg/minikube/exit"
+	"k8s.io/minikube/pkg/minikube/hooks"
+	"k8s.io/minikube/pkg/minikube/kata"
 	"k8s.io/minikube/pkg/minikube/kubeconfig"
 	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/logs"
@@ -63,8 +69,15 @@ import (
 	"k8s.io/minikube/pkg/minikube/proxy"
 	"k8s.io/minikube/pkg/minikube/reason"
 	"k8s.io/minikube/pkg/minikube/registry"
+	"k8s.io/minikube/pkg/minikube/registryauth"
+	"k8s.io/minikube/pkg/minikube/registrymirror"
+	"k8s.io/minikube/pkg/minikube/sandbox"
+	"k8s.io/minikube/pkg/minikube/sshutil"
 	"k8s.io/minikube/pkg/minikube/style"
+	"k8s.io/minikube/pkg/minikube/sysinit"
+	"k8s.io/minikube/pkg/minikube/tuning"
 	"k8s.io/minikube/pkg/minikube/vmpath"
+	"k8s.io/minikube/pkg/minikube/wasm"
 	"k8s.io/minikube/pkg/network"
 	"k8s.io/minikube/pkg/util"
 	"k8s.io/minikube/pkg/util/retry"
@@ -103,7 +116,7 @@ func Start(starter Starter, apiServer bool) (*kubeconfig.Settings, error) {
 
 		showNoK8sVersionInfo(cr)
 
-		configureMounts(&wg, *starter.Cfg)
+		configureMounts(&wg, *starter.Cfg, starter.Runner)
 		return nil, config.Write(viper.GetString(config.ProfileName), starter.Cfg)
 	}
 
@@ -125,6 +138,10 @@ func Start(starter Starter, apiServer bool) (*kubeconfig.Settings, error) {
 
 	showVersionInfo(starter.Node.KubernetesVersion, cr)
 
+	if err := configureIPv6(starter.Runner, *starter.Cfg, *starter.Node); err != nil {
+		return nil, err
+	}
+
 	// Add "host.minikube.internal" DNS alias (intentionally non-fatal)
 	hostIP, err := cluster.HostIP(starter.Host, starter.Cfg.Name)
 	if err != nil {
@@ -155,7 +172,7 @@ func Start(starter Starter, apiServer bool) (*kubeconfig.Settings, error) {
 		}
 	}
 
-	go configureMounts(&wg, *starter.Cfg)
+	go configureMounts(&wg, *starter.Cfg, starter.Runner)
 
 	wg.Add(1)
 	go func() {
@@ -192,6 +209,12 @@ func Start(starter Starter, apiServer bool) (*kubeconfig.Settings, error) {
 		if starter.Cfg.Driver == driver.None && len(starter.Cfg.Nodes) == 1 {
 			prepareNone()
 		}
+		if err := applyRuntimeClasses(starter.Runner, *starter.Cfg); err != nil {
+			out.WarningT("Unable to create the RuntimeClasses: {{.error}}", out.V{"error": err})
+		}
+		if err := syncRegistrySecrets(*starter.Cfg); err != nil {
+			out.WarningT("Unable to create the imagePullSecrets of the registries: {{.error}}", out.V{"error": err})
+		}
 	} else {
 		// Make sure to use the command runner for the control plane to generate the join token
 		cpBs, cpr, err := cluster.ControlPlaneBootstrapper(starter.MachineAPI, starter.Cfg, viper.GetString(cmdcfg.Bootstrapper))
@@ -199,6 +222,10 @@ func Start(starter Starter, apiServer bool) (*kubeconfig.Settings, error) {
 			return nil, errors.Wrap(err, "getting control plane bootstrapper")
 		}
 
+		if err := routeHybridNetworks(starter.MachineAPI, *starter.Cfg, *starter.Node, starter.Runner); err != nil {
+			return nil, errors.Wrap(err, "routing the networks of the hybrid cluster")
+		}
+
 		if err := joinCluster(starter, cpBs, bs); err != nil {
 			return nil, errors.Wrap(err, "joining cp")
 		}
@@ -275,6 +302,10 @@ func handleAPIServer(starter Starter, cr cruntime.Manager, hostIP net.IP) (*kube
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "Failed to setup kubeadm")
 	}
+	// a failing hook is not fixed by deleting the cluster and trying again
+	if err := hooks.Run(hooks.PreStart, *starter.Cfg, starter.Runner, starter.Cfg.PreStartHooks); err != nil {
+		exit.Error(reason.HostHook, "Failed to run the pre-start hooks", err)
+	}
 	err = bs.StartCluster(*starter.Cfg)
 	if err != nil {
 		ExitIfFatal(err, false)
@@ -306,7 +337,16 @@ func joinCluster(starter Starter, cpBs bootstrapper.Bootstrapper, bs bootstrappe
 		klog.Infof("JoinCluster complete in %s", time.Since(start))
 	}()
 
-	joinCmd, err := cpBs.GenerateToken(*starter.Cfg)
+	// rejoining would leave the etcd member of an existing control plane behind, its kubelet brings its static pods back instead
+	if starter.PreExists && starter.Node.ControlPlane {
+		klog.Infof("restarting kubelet of existing control plane node %q", starter.Node.Name)
+		if err := sysinit.New(starter.Runner).Restart("kubelet"); err != nil {
+			return fmt.Errorf("error restarting kubelet of control plane node: %w", err)
+		}
+		return nil
+	}
+
+	joinCmd, err := cpBs.GenerateToken(*starter.Cfg, *starter.Node)
 	if err != nil {
 		return fmt.Errorf("error generating join token: %w", err)
 	}
@@ -359,18 +399,27 @@ func Provision(cc *config.ClusterConfig, n *config.Node, apiServer bool, delOnFa
 	} else {
 		if apiServer {
 			out.Step(style.ThumbsUp, "Starting control plane node {{.name}} in cluster {{.cluster}}", out.V{"name": name, "cluster": cc.Name})
+		} else if n.ControlPlane {
+			out.Step(style.ThumbsUp, "Starting secondary control plane node {{.name}} in cluster {{.cluster}}", out.V{"name": name, "cluster": cc.Name})
 		} else {
 			out.Step(style.ThumbsUp, "Starting worker node {{.name}} in cluster {{.cluster}}", out.V{"name": name, "cluster": cc.Name})
 		}
 
 	}
 
-	if driver.IsKIC(cc.Driver) {
-		beginDownloadKicBaseImage(&kicGroup, cc, viper.GetBool("download-only"))
+	if err := sshutil.SetProxy(cc.SSHProxy); err != nil {
+		return nil, false, nil, nil, errors.Wrap(err, "ssh proxy")
 	}
 
-	if !driver.BareMetal(cc.Driver) {
-		beginCacheKubernetesImages(&cacheGroup, cc.KubernetesConfig.ImageRepository, n.KubernetesVersion, cc.KubernetesConfig.ContainerRuntime, cc.Driver)
+	// the node of a hybrid cluster may run on another driver than the cluster
+	drv := config.NodeDriver(*cc, *n)
+	if driver.IsKIC(drv) {
+		beginDownloadKicBaseImage(&kicGroup, cc, drv, viper.GetBool("download-only"))
+	}
+
+	// the images of a local build of Kubernetes are loaded from its directory, they are in no registry
+	if !driver.BareMetal(drv) && cc.KubernetesConfig.KubernetesImagesDir == "" {
+		beginCacheKubernetesImages(&cacheGroup, cc.KubernetesConfig.ImageRepository, n.KubernetesVersion, cc.KubernetesConfig.ContainerRuntime, drv, cc.PreloadFrom)
 	}
 
 	// Abstraction leakage alert: startHost requires the config to be saved, to satistfy pkg/provision/buildroot.
@@ -379,14 +428,31 @@ func Provision(cc *config.ClusterConfig, n *config.Node, apiServer bool, delOnFa
 		return nil, false, nil, nil, errors.Wrap(err, "Failed to save config")
 	}
 
-	handleDownloadOnly(&cacheGroup, &kicGroup, n.KubernetesVersion, cc.KubernetesConfig.ContainerRuntime, cc.Driver)
-	if driver.IsKIC(cc.Driver) {
+	handleDownloadOnly(&cacheGroup, &kicGroup, n.KubernetesVersion, cc.KubernetesConfig.ContainerRuntime, drv, cc.PreloadFrom)
+	if driver.IsKIC(drv) {
 		waitDownloadKicBaseImage(&kicGroup)
 	}
 
 	return startMachine(cc, n, delOnFail)
 }
 
+// configureProxy passes the proxy settings of the host to the container runtime and the kubelet of the node
+func configureProxy(runner cruntime.CommandRunner, cc config.ClusterConfig) {
+	podCIDR, err := bsutil.PodCIDR(cc)
+	if err != nil {
+		klog.Warningf("unable to pass the proxy settings to the node: %v", err)
+		return
+	}
+	s := proxy.Detect()
+	env := proxy.Env(s, cc, podCIDR)
+	if len(env) > 0 && s.Source != proxy.SourceEnv {
+		out.Infof("Using the proxy settings of the {{.source}}: HTTP_PROXY={{.http}} HTTPS_PROXY={{.https}}", out.V{"source": s.Source, "http": proxy.Mask(s.HTTP), "https": proxy.Mask(s.HTTPS)})
+	}
+	if err := proxy.ConfigureNode(runner, cc.KubernetesConfig.ContainerRuntime, env); err != nil {
+		klog.Warningf("unable to pass the proxy settings to the node: %v", err)
+	}
+}
+
 // ConfigureRuntimes does what needs to happen to get a runtime going.
 func configureRuntimes(runner cruntime.CommandRunner, cc config.ClusterConfig, kv semver.Version) cruntime.Manager {
 	co := cruntime.Config{
@@ -397,15 +463,33 @@ func configureRuntimes(runner cruntime.CommandRunner, cc config.ClusterConfig, k
 		ImageRepository:   cc.KubernetesConfig.ImageRepository,
 		KubernetesVersion: kv,
 		InsecureRegistry:  cc.InsecureRegistry,
+		Sandbox:           cc.ContainerRuntimeSandbox,
+		OCIRuntime:        cc.OCIRuntime,
 	}
 	if cc.GPUs != "" {
-		co.GPUs = true
+		if driver.IsKVM(cc.Driver) {
+			// the GPUs are passed through to the VM, which has no NVIDIA container runtime:
+			// install the NVIDIA driver in the VM and the device plugin using it instead
+			assets.Addons["nvidia-driver-installer"].EnableByDefault()
+			assets.Addons["nvidia-gpu-device-plugin"].EnableByDefault()
+		} else {
+			// the container runtime runs the containers with the nvidia runtime of the NVIDIA Container Toolkit,
+			// exposing the GPUs of the host to the device plugin and to the DCGM exporter of their metrics
+			assets.Addons["nvidia-device-plugin"].EnableByDefault()
+			assets.Addons["nvidia-dcgm-exporter"].EnableByDefault()
+			co.GPUs = true
+		}
 	}
 	cr, err := cruntime.New(co)
 	if err != nil {
 		exit.Error(reason.InternalRuntime, "Failed runtime", err)
 	}
 
+	// the drop-ins are picked up by the restarts of the runtime below, and of the kubelet by the bootstrapper
+	if !cc.DisableProxyPropagation && !driver.BareMetal(cc.Driver) {
+		configureProxy(runner, cc)
+	}
+
 	// 87-podman.conflist cni conf potentially conflicts with others and is created by podman on its first invocation,
 	// so we "provoke" it here to ensure it's generated and that we can disable it
 	// note: using 'help' or '--help' would be cheaper, but does not trigger that; 'version' seems to be next best option
@@ -443,10 +527,39 @@ func configureRuntimes(runner cruntime.CommandRunner, cc config.ClusterConfig, k
 		}
 	}
 
+	// apply the tuning profile on each and every node (re)start, as the filesystem of VMs is not persistent
+	// make sure container runtime is restarted afterwards for the limits to take effect
+	applyTuning(runner, cc)
+
+	// the handler of the wasm shim is picked up by the restart of containerd by Enable below
+	if cc.WasmRuntime != "" {
+		installWasm(runner, cc)
+	}
+	if cc.RuntimeClass == kata.Name {
+		installKata(runner, cc)
+	}
+	// the handler of the sandbox is registered by Enable below
+	if cc.ContainerRuntimeSandbox != "" {
+		installSandbox(runner, cc)
+	}
+	// the drop-ins of cri-o are read by the restart of Enable below
+	if cc.KubernetesConfig.ContainerRuntime == constants.CRIO {
+		applyRegistryMirrors(runner, cc)
+	}
+	if len(cc.RegistryCredentials) > 0 {
+		if err := registryauth.ApplyNode(runner, cc.RegistryCredentials); err != nil {
+			out.WarningT("Unable to give the credentials of the registries to the node: {{.error}}", out.V{"error": err})
+		}
+	}
+
 	disableOthers := !driver.BareMetal(cc.Driver)
 	if err = cr.Enable(disableOthers, cgroupDriver(cc), inUserNamespace); err != nil {
 		exit.Error(reason.RuntimeEnable, "Failed to enable container runtime", err)
 	}
+	// the hosts.toml of containerd replace those Enable writes for --insecure-registry, and are read on every pull
+	if cc.KubernetesConfig.ContainerRuntime == constants.Containerd {
+		applyRegistryMirrors(runner, cc)
+	}
 
 	// Wait for the CRI to be "live", before returning it
 	if err = waitForCRISocket(runner, cr.SocketPath(), 60, 1); err != nil {
@@ -461,6 +574,179 @@ func configureRuntimes(runner cruntime.CommandRunner, cc config.ClusterConfig, k
 	return cr
 }
 
+// applyRegistryMirrors generates the mirrors of the registries of the cluster into the node
+func applyRegistryMirrors(runner cruntime.CommandRunner, cc config.ClusterConfig) {
+	if len(cc.RegistryMirrors) == 0 {
+		return
+	}
+	if err := registrymirror.Apply(runner, cc.KubernetesConfig.ContainerRuntime, cc.RegistryMirrors, cc.InsecureRegistry, nil); err != nil {
+		out.WarningT("Unable to configure the mirrors of the registries: {{.error}}", out.V{"error": err})
+	}
+}
+
+// applyTuning applies the tuning profile, sysctls and kernel modules of the cluster to the node, or removes the ones of a previous start
+func applyTuning(runner cruntime.CommandRunner, cc config.ClusterConfig) {
+	s, err := tuning.Resolve(cc.Tuning, cc.TuningOptions)
+	if err == nil {
+		err = s.AddSysctls(cc.Sysctls)
+	}
+	if err == nil {
+		err = s.AddModules(cc.KernelModules)
+	}
+	if err != nil {
+		out.WarningT("Ignoring the tuning profile: {{.error}}", out.V{"error": err})
+		return
+	}
+	if s.Empty() {
+		tuning.Clear(runner)
+		return
+	}
+
+	out.Step(style.Option, "Tuning the node: {{.settings}}", out.V{"settings": s.String()})
+	// the sysctls of the host apply to the nodes sharing its kernel
+	shareKernel := driver.IsKIC(cc.Driver)
+	if shareKernel && len(s.Sysctls) > 0 {
+		out.WarningT("The {{.driver}} driver shares the kernel of the host, set the sysctls of the tuning profile on the host: sudo sysctl {{.sysctls}}", out.V{"driver": cc.Driver, "sysctls": s.SysctlArgs()})
+	}
+	err = tuning.Apply(runner, s, shareKernel)
+	var unsupported *tuning.UnsupportedError
+	if errors.As(err, &unsupported) {
+		if shareKernel && len(unsupported.Modules) > 0 {
+			exit.Message(reason.GuestKernelUnsupported, "The {{.driver}} driver shares the kernel of the host, load the kernel modules on the host: sudo modprobe -a {{.modules}}", out.V{"driver": cc.Driver, "modules": strings.Join(unsupported.Modules, " ")})
+		}
+		exit.Message(reason.GuestKernelUnsupported, "Unable to tune the node: {{.error}}", out.V{"error": err})
+	}
+	if err != nil {
+		out.WarningT("Unable to apply the tuning profile: {{.error}}", out.V{"error": err})
+	}
+}
+
+// installWasm installs the wasm shim of the cluster into the node, and registers its runtime handler with containerd
+func installWasm(runner cruntime.CommandRunner, cc config.ClusterConfig) {
+	if cc.KubernetesConfig.ContainerRuntime != constants.Containerd {
+		out.WarningT("Ignoring the {{.wasm}} wasm runtime, only supported by the containerd container runtime", out.V{"wasm": cc.WasmRuntime})
+		return
+	}
+	tarball, err := download.WasmShim(cc.WasmRuntime, detect.EffectiveArch())
+	if err == nil {
+		err = wasm.Install(runner, cc.WasmRuntime, tarball)
+	}
+	if err != nil {
+		out.WarningT("Unable to install the {{.wasm}} wasm runtime: {{.error}}", out.V{"wasm": cc.WasmRuntime, "error": err})
+	}
+}
+
+// installKata installs Kata Containers into the node, and registers its runtime handler with the container runtime
+func installKata(runner cruntime.CommandRunner, cc config.ClusterConfig) {
+	if err := kata.CheckNode(runner); err != nil {
+		exit.Message(reason.GuestRuntimeClassUnsupported, "Unable to run Kata Containers on the {{.driver}} driver: {{.error}}", out.V{"driver": cc.Driver, "error": err})
+	}
+	tarball, err := download.Kata(detect.EffectiveArch())
+	if err == nil {
+		out.Step(style.Option, "Installing Kata Containers {{.version}} ...", out.V{"version": kata.Version})
+		err = kata.Install(runner, cc.KubernetesConfig.ContainerRuntime, tarball)
+	}
+	if err != nil {
+		exit.Error(reason.RuntimeEnable, "Failed to install Kata Containers", err)
+	}
+}
+
+// installSandbox installs the binaries of the sandbox of the cluster into the node, unless its preload carried them
+func installSandbox(runner cruntime.CommandRunner, cc config.ClusterConfig) {
+	if !sandbox.SupportsRuntime(cc.KubernetesConfig.ContainerRuntime) {
+		out.WarningT("Ignoring the {{.sandbox}} sandbox, only supported by the containerd and cri-o container runtimes", out.V{"sandbox": cc.ContainerRuntimeSandbox})
+		return
+	}
+	var err error
+	if sandbox.Installed(runner) {
+		err = sandbox.Link(runner)
+	} else {
+		var binaries map[string]string
+		binaries, err = download.GVisor(detect.EffectiveArch())
+		if err == nil {
+			err = sandbox.Install(runner, binaries)
+		}
+	}
+	if err != nil {
+		exit.Error(reason.RuntimeEnable, "Failed to install the gVisor sandbox", err)
+	}
+}
+
+// syncRegistrySecrets creates the imagePullSecrets of the credentials of minikube registry login in their namespaces,
+// which may have been created since the login
+func syncRegistrySecrets(cc config.ClusterConfig) error {
+	if len(registryauth.Namespaces(cc.RegistryCredentials)) == 0 {
+		return nil
+	}
+	c, err := kapi.Client(cc.Name)
+	if err != nil {
+		return errors.Wrap(err, "client")
+	}
+	missing, err := registryauth.SyncSecrets(context.Background(), c, cc.RegistryCredentials, nil)
+	if len(missing) > 0 {
+		klog.Infof("skipped the imagePullSecrets of the missing namespaces %v", missing)
+	}
+	return err
+}
+
+// applyRuntimeClasses applies the RuntimeClasses running the pods selecting them with the wasm shim and the sandboxed runtimes of the cluster,
+// and the admission policy defaulting the pods of the sandbox namespaces to the sandbox
+func applyRuntimeClasses(cpr command.Runner, cc config.ClusterConfig) error {
+	var manifests []string
+	if cc.WasmRuntime != "" && cc.KubernetesConfig.ContainerRuntime == constants.Containerd {
+		manifests = append(manifests, wasm.RuntimeClass(cc.WasmRuntime))
+	}
+	if cc.RuntimeClass == kata.Name {
+		manifests = append(manifests, kata.RuntimeClass)
+	}
+	kubectl := kapi.KubectlBinaryPath(cc.KubernetesConfig.KubernetesVersion)
+	if cc.ContainerRuntimeSandbox != "" && sandbox.SupportsRuntime(cc.KubernetesConfig.ContainerRuntime) {
+		manifests = append(manifests, sandbox.RuntimeClass(cc.ContainerRuntimeSandbox))
+		if err := applySandboxPolicy(cpr, cc, kubectl); err != nil {
+			return err
+		}
+	}
+	if len(manifests) == 0 {
+		return nil
+	}
+	apply := exec.Command("sudo", "KUBECONFIG=/var/lib/minikube/kubeconfig", kubectl, "apply", "-f", "-")
+	apply.Stdin = bytes.NewBufferString(strings.Join(manifests, "---\n"))
+	if _, err := cpr.RunCmd(apply); err != nil {
+		return errors.Wrap(err, "applying the RuntimeClasses")
+	}
+	return nil
+}
+
+// applySandboxPolicy applies the admission policy defaulting the pods of the sandbox namespaces to the sandbox, or deletes it if there are none
+func applySandboxPolicy(cpr command.Runner, cc config.ClusterConfig, kubectl string) error {
+	v, err := util.ParseKubernetesVersion(cc.KubernetesConfig.KubernetesVersion)
+	if err != nil {
+		return err
+	}
+	apiVersion, err := sandbox.AdmissionAPIVersion(v)
+	if err != nil {
+		if len(cc.SandboxNamespaces) > 0 {
+			return err
+		}
+		return nil
+	}
+	if len(cc.SandboxNamespaces) == 0 {
+		del := exec.Command("sudo", "KUBECONFIG=/var/lib/minikube/kubeconfig", kubectl, "delete", "--ignore-not-found",
+			"mutatingadmissionpolicybinding/"+sandbox.PolicyName, "mutatingadmissionpolicy/"+sandbox.PolicyName)
+		// the API is not served unless a previous start enabled it for the policy
+		if _, err := cpr.RunCmd(del); err != nil {
+			klog.Infof("unable to delete the sandbox admission policy: %v", err)
+		}
+		return nil
+	}
+	apply := exec.Command("sudo", "KUBECONFIG=/var/lib/minikube/kubeconfig", kubectl, "apply", "-f", "-")
+	apply.Stdin = bytes.NewBufferString(sandbox.NamespacePolicy(cc.ContainerRuntimeSandbox, cc.SandboxNamespaces, apiVersion))
+	if _, err := cpr.RunCmd(apply); err != nil {
+		return errors.Wrap(err, "applying the sandbox admission policy")
+	}
+	return nil
+}
+
 // cgroupDriver returns cgroup driver that should be used to further configure container runtime, node(s) and cluster.
 // It is based on:
 // - (forced) user preference (set via flags or env), if present, or
@@ -492,6 +778,11 @@ func cgroupDriver(cc config.ClusterConfig) string {
 		return constants.CgroupfsCgroupDriver
 	}
 
+	// the wsl driver boots the distro with systemd, on the cgroup v2 hierarchy of WSL 2
+	if driver.IsWSL(cc.Driver) {
+		return constants.SystemdCgroupDriver
+	}
+
 	// for "remote baremetal", we assume cgroupfs and user can "force-systemd" with flag to override
 	// potential improvement: use systemd as default (in line with k8s) and allow user to override it with new flag (eg, "cgroup-driver", that would replace "force-systemd")
 	if driver.IsSSH(cc.Driver) {
@@ -652,6 +943,13 @@ func startMachine(cfg *config.ClusterConfig, node *config.Node, delOnFail bool)
 	if err != nil {
 		return runner, preExists, m, host, errors.Wrap(err, "Failed to validate network")
 	}
+	saveExtraNetworkIPs(cfg, node, runner)
+
+	if node.ControlPlane {
+		if err := driver.StartControlPlaneTunnel(cfg, node, host.DriverName); err != nil {
+			return runner, preExists, m, host, err
+		}
+	}
 
 	if driver.IsQEMU(host.Driver.DriverName()) && network.IsBuiltinQEMU(cfg.Network) {
 		apiServerPort, err := getPort()
@@ -769,7 +1067,9 @@ func validateNetwork(h *host.Host, r command.Runner, imageRepository string) (st
 	}
 
 	// Non-blocking
-	go tryRegistry(r, h.Driver.DriverName(), imageRepository, ip)
+	if !download.Offline() {
+		go tryRegistry(r, h.Driver.DriverName(), imageRepository, ip)
+	}
 	return ip, nil
 }
 
@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package node
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/cluster"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/machine"
+	"k8s.io/minikube/pkg/minikube/windows"
+	"k8s.io/minikube/pkg/util/retry"
+
+	cmdcfg "k8s.io/minikube/cmd/minikube/cmd/config"
+)
+
+// WindowsVM returns the manager of the Windows VMs of the cluster, attached to the network of its primary control plane
+func WindowsVM(cc config.ClusterConfig) (windows.VM, error) {
+	cp, err := config.PrimaryControlPlane(&cc)
+	if err != nil {
+		return nil, err
+	}
+	return windows.NewVM(cc.Driver, config.MachineName(cc, cp))
+}
+
+// AddWindows adds an experimental Windows Server worker to the cluster: it creates its VM from the image,
+// joins it with kubeadm through the guest channel of the hypervisor, and taints it for the pods of the windows RuntimeClass
+func AddWindows(cc *config.ClusterConfig, n config.Node, image, user, password string) error {
+	vm, err := WindowsVM(*cc)
+	if err != nil {
+		return err
+	}
+	name := config.MachineName(*cc, n)
+	dir := localpath.MachinePath(name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrap(err, "machine dir")
+	}
+
+	// saved first, so that deleting the cluster cleans up after a failed creation
+	n.OS = config.WindowsOS
+	n.Worker = true
+	if err := config.SaveNode(cc, &n); err != nil {
+		return errors.Wrap(err, "save node")
+	}
+	if err := vm.Create(name, image, dir, cc.CPUs, cc.Memory); err != nil {
+		return errors.Wrap(err, "creating the Windows VM")
+	}
+	if err := vm.Start(name); err != nil {
+		return errors.Wrap(err, "starting the Windows VM")
+	}
+	// the first boot of a sysprepped image takes a few minutes before the guest services report the address
+	getIP := func() error {
+		n.IP, err = vm.IP(name)
+		return err
+	}
+	if err := retry.Expo(getIP, 10*time.Second, 15*time.Minute); err != nil {
+		return errors.Wrap(err, "waiting for the IP of the Windows VM")
+	}
+	if err := config.SaveNode(cc, &n); err != nil {
+		return errors.Wrap(err, "save node")
+	}
+
+	api, err := machine.NewAPIClient()
+	if err != nil {
+		return err
+	}
+	defer api.Close()
+	cpBs, cpr, err := cluster.ControlPlaneBootstrapper(api, cc, viper.GetString(cmdcfg.Bootstrapper))
+	if err != nil {
+		return errors.Wrap(err, "control plane bootstrapper")
+	}
+	cp, err := config.PrimaryControlPlane(cc)
+	if err != nil {
+		return err
+	}
+	linuxJoin, err := cpBs.GenerateToken(*cc, n)
+	if err != nil {
+		return errors.Wrap(err, "generating join token")
+	}
+	joinCmd, err := windows.JoinCommand(linuxJoin, name)
+	if err != nil {
+		return err
+	}
+	klog.Infof("joining Windows node %q to the cluster", name)
+	if out, err := vm.Run(name, user, password, windows.JoinScript(cc.KubernetesConfig.KubernetesVersion, cp.IP, joinCmd)); err != nil {
+		return errors.Wrapf(err, "joining the Windows node: %s", out)
+	}
+	return configureWindowsScheduling(cpr, *cc, name)
+}
+
+// configureWindowsScheduling taints the Windows node and applies the windows RuntimeClass, which lets the pods selecting it tolerate the taint
+func configureWindowsScheduling(cpr command.Runner, cc config.ClusterConfig, name string) error {
+	kubectl := kapi.KubectlBinaryPath(cc.KubernetesConfig.KubernetesVersion)
+	taint := exec.Command("sudo", "KUBECONFIG=/var/lib/minikube/kubeconfig", kubectl, "taint", "nodes", name, windows.Taint, "--overwrite")
+	// the node registers a little after kubeadm join returns
+	if err := retry.Expo(func() error {
+		_, err := cpr.RunCmd(taint)
+		return err
+	}, 5*time.Second, 2*time.Minute); err != nil {
+		return errors.Wrap(err, "tainting the Windows node")
+	}
+	apply := exec.Command("sudo", "KUBECONFIG=/var/lib/minikube/kubeconfig", kubectl, "apply", "-f", "-")
+	apply.Stdin = bytes.NewBufferString(windows.RuntimeClass)
+	if _, err := cpr.RunCmd(apply); err != nil {
+		return errors.Wrap(err, "applying the windows RuntimeClass")
+	}
+	return nil
+}
+
+// StartWindows starts the VM of a Windows node, whose kubelet service rejoins the cluster on boot
+func StartWindows(cc config.ClusterConfig, n config.Node) error {
+	vm, err := WindowsVM(cc)
+	if err != nil {
+		return err
+	}
+	name := config.MachineName(cc, n)
+	st, err := vm.State(name)
+	if err != nil {
+		return err
+	}
+	if st == state.Running {
+		return nil
+	}
+	return vm.Start(name)
+}
+
+// StopWindows stops the VM of a Windows node, returning false when it does not exist
+func StopWindows(cc config.ClusterConfig, n config.Node) (bool, error) {
+	vm, err := WindowsVM(cc)
+	if err != nil {
+		return false, err
+	}
+	name := config.MachineName(cc, n)
+	st, err := vm.State(name)
+	if err != nil {
+		return false, err
+	}
+	switch st {
+	case state.None:
+		return false, nil
+	case state.Stopped:
+		return true, nil
+	}
+	return true, vm.Stop(name)
+}
+
+// DeleteWindows deletes the VM of a Windows node, along with its disk
+func DeleteWindows(cc config.ClusterConfig, n config.Node) error {
+	vm, err := WindowsVM(cc)
+	if err != nil {
+		return err
+	}
+	name := config.MachineName(cc, n)
+	if err := vm.Delete(name); err != nil {
+		return fmt.Errorf("deleting the Windows VM %s: %w", name, err)
+	}
+	return os.RemoveAll(localpath.MachinePath(name))
+}
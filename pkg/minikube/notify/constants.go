@@ -26,4 +26,7 @@ const (
 	GithubMinikubeReleasesAliyunURL = "https://kubernetes.oss-cn-hangzhou.aliyuncs.com/minikube/releases.json"
 	// GithubMinikubeBetaReleasesAliyunURL is the URL of the minikube GitHub beta releases JSON file
 	GithubMinikubeBetaReleasesAliyunURL = "https://kubernetes.oss-cn-hangzhou.aliyuncs.com/minikube/releases-beta.json"
+
+	// ReleaseFeedURL is the URL of the release feed JSON file, listing the minikube and Kubernetes releases and the security advisories
+	ReleaseFeedURL = "https://storage.googleapis.com/minikube/release-feed.json"
 )
@@ -0,0 +1,206 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/util"
+	"k8s.io/minikube/pkg/version"
+)
+
+const (
+	// FeedMinikube is the kind of the entries announcing a minikube release
+	FeedMinikube = "minikube"
+	// FeedKubernetes is the kind of the entries announcing a Kubernetes version supported by minikube
+	FeedKubernetes = "kubernetes"
+	// FeedSecurity is the kind of the entries advising of a vulnerability of the base image, fixed in a minikube release
+	FeedSecurity = "security"
+)
+
+// FeedEntry is an entry of the release feed
+type FeedEntry struct {
+	Kind    string `json:"kind"`
+	Version string `json:"version"`
+	Date    string `json:"date,omitempty"`
+	Title   string `json:"title"`
+	Summary string `json:"summary,omitempty"`
+	URL     string `json:"url,omitempty"`
+	// Severity is the severity of a security advisory
+	Severity string `json:"severity,omitempty"`
+	// Drivers, ContainerRuntimes and Addons restrict the entry to the clusters using one of them
+	Drivers           []string `json:"drivers,omitempty"`
+	ContainerRuntimes []string `json:"containerRuntimes,omitempty"`
+	Addons            []string `json:"addons,omitempty"`
+}
+
+// FeedSource is a source of release feed entries
+type FeedSource interface {
+	Entries() ([]FeedEntry, error)
+}
+
+// NewFeedSource returns the source of the release feed at location, a http(s) URL or a local file
+func NewFeedSource(location string) FeedSource {
+	if u, err := url.Parse(location); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return urlFeed(location)
+	}
+	return fileFeed(strings.TrimPrefix(location, "file://"))
+}
+
+type urlFeed string
+
+// Entries returns the entries of the feed served at the URL
+func (f urlFeed) Entries() ([]FeedEntry, error) {
+	var entries []FeedEntry
+	if err := getJSON(string(f), &entries); err != nil {
+		return nil, errors.Wrap(err, "getting the release feed")
+	}
+	return entries, nil
+}
+
+type fileFeed string
+
+// Entries returns the entries of the feed stored in the file
+func (f fileFeed) Entries() ([]FeedEntry, error) {
+	b, err := os.ReadFile(string(f))
+	if err != nil {
+		return nil, errors.Wrap(err, "reading the release feed")
+	}
+	var entries []FeedEntry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, errors.Wrapf(err, "parsing the release feed %s", f)
+	}
+	return entries, nil
+}
+
+// Interest is what the release feed entries are relevant to
+type Interest struct {
+	MinikubeVersion   semver.Version
+	KubernetesVersion semver.Version
+	// Driver, ContainerRuntime and Addons are empty when there is no cluster, all entries are relevant then
+	Driver           string
+	ContainerRuntime string
+	Addons           []string
+}
+
+// InterestOf returns the interest of the running minikube, and of the cluster cc if not nil
+func InterestOf(cc *config.ClusterConfig) Interest {
+	in := Interest{}
+	if v, err := version.GetSemverVersion(); err == nil {
+		in.MinikubeVersion = v
+	}
+	kv := constants.DefaultKubernetesVersion
+	if cc != nil {
+		kv = cc.KubernetesConfig.KubernetesVersion
+		in.Driver = cc.Driver
+		in.ContainerRuntime = cc.KubernetesConfig.ContainerRuntime
+		for name, enabled := range cc.Addons {
+			if enabled {
+				in.Addons = append(in.Addons, name)
+			}
+		}
+		sort.Strings(in.Addons)
+	}
+	if v, err := util.ParseKubernetesVersion(kv); err == nil {
+		in.KubernetesVersion = v
+	}
+	return in
+}
+
+// Relevant returns the entries newer than the versions of in and matching its configuration, the security advisories first
+func Relevant(entries []FeedEntry, in Interest) []FeedEntry {
+	var relevant []FeedEntry
+	for _, e := range entries {
+		if e.newer(in) && e.matches(in) {
+			relevant = append(relevant, e)
+		}
+	}
+	sort.SliceStable(relevant, func(i, j int) bool {
+		return relevant[i].Kind == FeedSecurity && relevant[j].Kind != FeedSecurity
+	})
+	return relevant
+}
+
+// newer returns whether the entry is about a version newer than the one of in
+func (e FeedEntry) newer(in Interest) bool {
+	v, err := semver.ParseTolerant(e.Version)
+	if err != nil {
+		klog.Warningf("release feed entry %q has an invalid version %q: %v", e.Title, e.Version, err)
+		return false
+	}
+	switch e.Kind {
+	case FeedMinikube, FeedSecurity:
+		return v.GT(in.MinikubeVersion)
+	case FeedKubernetes:
+		return v.GT(in.KubernetesVersion)
+	}
+	klog.Warningf("release feed entry %q has an unknown kind %q", e.Title, e.Kind)
+	return false
+}
+
+// matches returns whether the entry applies to the configuration of in
+func (e FeedEntry) matches(in Interest) bool {
+	if in.Driver == "" {
+		return true
+	}
+	return matchesAnyOf(e.Drivers, []string{in.Driver}) && matchesAnyOf(e.ContainerRuntimes, []string{in.ContainerRuntime}) && matchesAnyOf(e.Addons, in.Addons)
+}
+
+func matchesAnyOf(values []string, enabled []string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		for _, e := range enabled {
+			if v == e {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// String returns a one-line description of the entry
+func (e FeedEntry) String() string {
+	s := e.Version
+	if e.Kind == FeedSecurity && e.Severity != "" {
+		s = fmt.Sprintf("[%s] fixed in %s", e.Severity, e.Version)
+	}
+	if e.Date != "" {
+		s += " (" + e.Date + ")"
+	}
+	return s + ": " + e.Title
+}
+
+// FeedURL returns the location of the release feed, set with the ReleaseFeedURL setting
+func FeedURL() string {
+	if u := viper.GetString(config.ReleaseFeedURL); u != "" {
+		return u
+	}
+	return ReleaseFeedURL
+}
@@ -0,0 +1,169 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/spf13/viper"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/tests"
+)
+
+var testFeed = []FeedEntry{
+	{Kind: FeedMinikube, Version: "v1.40.0", Title: "minikube v1.40.0"},
+	{Kind: FeedMinikube, Version: "v1.39.0", Title: "podman improvements", Drivers: []string{"podman"}},
+	{Kind: FeedKubernetes, Version: "v1.31.0", Title: "Kubernetes v1.31.0"},
+	{Kind: FeedKubernetes, Version: "v1.29.0", Title: "Kubernetes v1.29.0"},
+	{Kind: FeedSecurity, Version: "v1.38.1", Severity: "high", Title: "CVE in the base image runc"},
+	{Kind: FeedSecurity, Version: "v1.37.0", Title: "fixed before the running version"},
+	{Kind: FeedMinikube, Version: "v1.39.1", Title: "ingress fix", Addons: []string{"ingress"}},
+	{Kind: FeedMinikube, Version: "cat", Title: "invalid version"},
+}
+
+func titles(entries []FeedEntry) string {
+	var t []string
+	for _, e := range entries {
+		t = append(t, e.Title)
+	}
+	return strings.Join(t, ",")
+}
+
+func TestRelevant(t *testing.T) {
+	base := Interest{MinikubeVersion: semver.MustParse("1.38.0"), KubernetesVersion: semver.MustParse("1.30.0")}
+
+	tests := []struct {
+		description string
+		in          Interest
+		want        string
+	}{
+		{
+			description: "no cluster",
+			in:          base,
+			want:        "CVE in the base image runc,minikube v1.40.0,podman improvements,Kubernetes v1.31.0,ingress fix",
+		},
+		{
+			description: "docker cluster",
+			in:          Interest{MinikubeVersion: base.MinikubeVersion, KubernetesVersion: base.KubernetesVersion, Driver: "docker", ContainerRuntime: "containerd"},
+			want:        "CVE in the base image runc,minikube v1.40.0,Kubernetes v1.31.0",
+		},
+		{
+			description: "podman cluster with ingress",
+			in:          Interest{MinikubeVersion: base.MinikubeVersion, KubernetesVersion: base.KubernetesVersion, Driver: "podman", ContainerRuntime: "cri-o", Addons: []string{"dashboard", "ingress"}},
+			want:        "CVE in the base image runc,minikube v1.40.0,podman improvements,Kubernetes v1.31.0,ingress fix",
+		},
+		{
+			description: "up to date",
+			in:          Interest{MinikubeVersion: semver.MustParse("1.40.0"), KubernetesVersion: semver.MustParse("1.31.0")},
+			want:        "",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := titles(Relevant(testFeed, tc.in)); got != tc.want {
+				t.Errorf("Relevant() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestInterestOf(t *testing.T) {
+	cc := &config.ClusterConfig{
+		Driver:           "kvm2",
+		Addons:           map[string]bool{"ingress": true, "dashboard": false, "metrics-server": true},
+		KubernetesConfig: config.KubernetesConfig{KubernetesVersion: "v1.28.3", ContainerRuntime: "containerd"},
+	}
+	in := InterestOf(cc)
+	if in.Driver != "kvm2" || in.ContainerRuntime != "containerd" || strings.Join(in.Addons, ",") != "ingress,metrics-server" || in.KubernetesVersion.String() != "1.28.3" {
+		t.Errorf("InterestOf() = %+v", in)
+	}
+	if in := InterestOf(nil); in.Driver != "" || in.KubernetesVersion.Major != 1 {
+		t.Errorf("InterestOf(nil) = %+v, want the default Kubernetes version and no driver", in)
+	}
+}
+
+func TestFeedSource(t *testing.T) {
+	b, err := json.Marshal(testFeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(b)
+	}))
+	defer server.Close()
+	file := filepath.Join(t.TempDir(), "feed.json")
+	if err := os.WriteFile(file, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, location := range []string{server.URL, file, "file://" + file} {
+		entries, err := NewFeedSource(location).Entries()
+		if err != nil {
+			t.Fatalf("Entries(%s) failed: %v", location, err)
+		}
+		if titles(entries) != titles(testFeed) {
+			t.Errorf("Entries(%s) = %q, want %q", location, titles(entries), titles(testFeed))
+		}
+	}
+
+	if _, err := NewFeedSource(filepath.Join(t.TempDir(), "missing.json")).Entries(); err == nil {
+		t.Errorf("Entries() of a missing file succeeded")
+	}
+}
+
+func TestMaybePrintReleaseNotesText(t *testing.T) {
+	b, err := json.Marshal([]FeedEntry{
+		{Kind: FeedMinikube, Version: "v99.0.0", Title: "minikube v99.0.0"},
+		{Kind: FeedSecurity, Version: "v99.0.0", Severity: "high", Title: "CVE in the base image"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed := filepath.Join(t.TempDir(), "feed.json")
+	if err := os.WriteFile(feed, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests.MakeTempDir(t)
+	viper.Set("interactive", true)
+	viper.Set(config.WantUpdateNotification, true)
+	viper.Set(config.WantReleaseNotes, true)
+	viper.Set(config.ReleaseFeedURL, feed)
+	viper.Set(config.ReminderWaitPeriodInHours, 24)
+	defer viper.Set(config.WantReleaseNotes, false)
+
+	outputBuffer := tests.NewFakeFile()
+	out.SetOutFile(outputBuffer)
+	lastUpdatePath := filepath.Join(t.TempDir(), "last_update_check")
+	maybePrintUpdateText("", "", lastUpdatePath)
+	got := outputBuffer.String()
+	for _, want := range []string{"1 security advisories", "the latest is v99.0.0: minikube v99.0.0", "minikube release-notes"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("maybePrintUpdateText() output %q does not contain %q", got, want)
+		}
+	}
+	if timeFromFileIfExists(lastUpdatePath).IsZero() {
+		t.Errorf("maybePrintUpdateText() did not record the time of the check")
+	}
+}
@@ -54,6 +54,10 @@ func MaybePrintUpdateTextFromAliyunMirror() {
 }
 
 func maybePrintUpdateText(latestReleasesURL string, betaReleasesURL string, lastUpdatePath string) {
+	if viper.GetBool(config.WantReleaseNotes) {
+		maybePrintReleaseNotesText(lastUpdatePath)
+		return
+	}
 	latestVersion, err := latestVersionFromURL(latestReleasesURL)
 	if err != nil {
 		klog.Warning(err)
@@ -96,6 +100,44 @@ func maybePrintBetaUpdateText(betaReleasesURL string, localVersion semver.Versio
 	return true
 }
 
+// maybePrintReleaseNotesText prints a summary of the release feed entries relevant to the current profile, instead of the update text
+func maybePrintReleaseNotesText(lastUpdatePath string) {
+	if !shouldCheckURLVersion(lastUpdatePath) {
+		return
+	}
+	entries, err := NewFeedSource(FeedURL()).Entries()
+	if err != nil {
+		klog.Warning(err)
+		return
+	}
+	var cc *config.ClusterConfig
+	if existing, err := config.Load(viper.GetString(config.ProfileName)); err == nil {
+		cc = existing
+	}
+	relevant := Relevant(entries, InterestOf(cc))
+	if len(relevant) == 0 {
+		return
+	}
+	if err := writeTimeToFile(lastUpdatePath, time.Now().UTC()); err != nil {
+		klog.Errorf("write time failed: %v", err)
+	}
+	var advisories, releases []FeedEntry
+	for _, e := range relevant {
+		if e.Kind == FeedSecurity {
+			advisories = append(advisories, e)
+		} else {
+			releases = append(releases, e)
+		}
+	}
+	if len(advisories) > 0 {
+		out.Styled(style.Warning, "{{.count}} security advisories apply to minikube {{.version}}", out.V{"count": len(advisories), "version": version.GetVersion()})
+	}
+	if len(releases) > 0 {
+		out.Styled(style.Celebrate, "{{.count}} new releases are relevant to your configuration, the latest is {{.release}}", out.V{"count": len(releases), "release": releases[0]})
+	}
+	out.Styled(style.Tip, "To read the release notes, run: 'minikube release-notes'\n")
+}
+
 func printUpdateTextCommon(version semver.Version) {
 	if err := writeTimeToFile(lastUpdateCheckFilePath, time.Now().UTC()); err != nil {
 		klog.Errorf("write time failed: %v", err)
@@ -165,7 +207,7 @@ func (r *Releases) UnmarshalJSON(p []byte) error {
 	return json.Unmarshal(p, &r.Releases)
 }
 
-func getJSON(url string, target *Releases) error {
+func getJSON(url string, target interface{}) error {
 	client := &http.Client{}
 
 	req, err := http.NewRequest("GET", url, nil)
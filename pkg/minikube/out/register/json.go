@@ -69,3 +69,9 @@ func PrintWarning(warning string) {
 	w := NewWarning(warning)
 	printAndRecordCloudEvent(w, w.data)
 }
+
+// RecordMigration records a Migration type in JSON format
+func RecordMigration(profile, migration string) {
+	m := NewMigration(profile, migration)
+	recordCloudEvent(m, m.data)
+}
@@ -86,6 +86,26 @@ func NewDownloadProgress(artifact, progress string) *DownloadProgress {
 	}}
 }
 
+// Migration records the translation of a persisted config written by an older minikube version
+type Migration struct {
+	data map[string]string
+}
+
+// NewMigration returns a new migration type
+func NewMigration(profile, migration string) *Migration {
+	return &Migration{
+		map[string]string{
+			"profile":   profile,
+			"migration": migration,
+		},
+	}
+}
+
+// Type returns the cloud events compatible type of this struct
+func (s *Migration) Type() string {
+	return "io.k8s.sigs.minikube.migration"
+}
+
 // Warning will be used to notify the user of warnings
 type Warning struct {
 	data map[string]string
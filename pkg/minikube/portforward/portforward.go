@@ -0,0 +1,301 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package portforward keeps the ports of services and pods declared in the cluster config forwarded to the host
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/out"
+	"k8s.io/minikube/pkg/minikube/style"
+)
+
+const (
+	// maxBackoff is the longest wait before re-establishing a forward
+	maxBackoff = 30 * time.Second
+	// podCheckInterval is how often the pod of a forward is checked, to move to another pod when it goes away
+	podCheckInterval = 2 * time.Second
+)
+
+// Parse parses a forward in the [NAMESPACE/]svc/NAME:HOSTPORT:PORT or [NAMESPACE/]pod/SELECTOR:HOSTPORT:PORT format
+func Parse(spec string) (config.PortForward, error) {
+	var f config.PortForward
+	fields := strings.Split(spec, ":")
+	if len(fields) < 3 {
+		return f, errors.Errorf("%q is not in the [NAMESPACE/]svc/NAME:HOSTPORT:PORT or [NAMESPACE/]pod/SELECTOR:HOSTPORT:PORT format", spec)
+	}
+	var err error
+	if f.HostPort, err = parsePort(fields[len(fields)-2]); err != nil {
+		return f, errors.Wrapf(err, "host port of %q", spec)
+	}
+	if f.Port, err = parsePort(fields[len(fields)-1]); err != nil {
+		return f, errors.Wrapf(err, "port of %q", spec)
+	}
+
+	// the keys of selectors may have a prefix with a slash, so the namespace is only split when followed by a kind
+	target := strings.Join(fields[:len(fields)-2], ":")
+	f.Namespace = "default"
+	if !hasKind(target) {
+		ns, rest, ok := strings.Cut(target, "/")
+		if !ok || !hasKind(rest) {
+			return f, errors.Errorf("%q is neither svc/NAME nor pod/SELECTOR", target)
+		}
+		f.Namespace, target = ns, rest
+	}
+	kind, name, _ := strings.Cut(target, "/")
+	if name == "" {
+		return f, errors.Errorf("%q is missing the name of the service or the selector of the pods", spec)
+	}
+	if kind == "pod" || kind == "pods" {
+		if _, err := labels.Parse(name); err != nil {
+			return f, errors.Wrapf(err, "selector of %q", spec)
+		}
+		f.Selector = name
+	} else {
+		f.Service = name
+	}
+	return f, nil
+}
+
+func hasKind(target string) bool {
+	for _, kind := range []string{"svc/", "service/", "services/", "pod/", "pods/"} {
+		if strings.HasPrefix(target, kind) {
+			return true
+		}
+	}
+	return false
+}
+
+func parsePort(s string) (int, error) {
+	p, err := strconv.Atoi(s)
+	if err != nil || p < 1 || p > 65535 {
+		return 0, errors.Errorf("%q is not a port", s)
+	}
+	return p, nil
+}
+
+// String formats a forward the way Parse reads it
+func String(f config.PortForward) string {
+	target := "svc/" + f.Service
+	if f.Service == "" {
+		target = "pod/" + f.Selector
+	}
+	return fmt.Sprintf("%s/%s:%d:%d", f.Namespace, target, f.HostPort, f.Port)
+}
+
+// Forward forwards f to 127.0.0.1 of the host until ctx is done, re-establishing it when it drops
+func Forward(ctx context.Context, restConfig *rest.Config, client kubernetes.Interface, f config.PortForward) {
+	backoff := time.Second
+	for {
+		established, err := forwardOnce(ctx, restConfig, client, f)
+		if ctx.Err() != nil {
+			return
+		}
+		if established {
+			backoff = time.Second
+		}
+		klog.Warningf("port forward %s dropped, re-establishing it in %s: %v", String(f), backoff, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// forwardOnce forwards f to a pod until the connection or the pod goes away, and returns whether the forward was established
+func forwardOnce(ctx context.Context, restConfig *rest.Config, client kubernetes.Interface, f config.PortForward) (bool, error) {
+	pod, port, err := Target(ctx, client, f)
+	if err != nil {
+		return false, err
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(restConfig)
+	if err != nil {
+		return false, errors.Wrap(err, "spdy round tripper")
+	}
+	req := client.CoreV1().RESTClient().Post().Resource("pods").Namespace(pod.Namespace).Name(pod.Name).SubResource("portforward")
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stop := make(chan struct{})
+	ready := make(chan struct{})
+	podCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		waitPodGone(podCtx, client, pod)
+		close(stop)
+	}()
+
+	pf, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"}, []string{fmt.Sprintf("%d:%d", f.HostPort, port)}, stop, ready, io.Discard, io.Discard)
+	if err != nil {
+		return false, errors.Wrap(err, "port forward")
+	}
+	go func() {
+		select {
+		case <-ready:
+			out.Step(style.Connectivity, "Forwarding 127.0.0.1:{{.host_port}} to {{.pod}}:{{.port}} for {{.forward}}", out.V{"host_port": f.HostPort, "pod": pod.Namespace + "/" + pod.Name, "port": port, "forward": String(f)})
+		case <-podCtx.Done():
+		}
+	}()
+	err = pf.ForwardPorts()
+	select {
+	case <-ready:
+		return true, err
+	default:
+		return false, err
+	}
+}
+
+// Target returns the pod the forward goes to, and its port
+func Target(ctx context.Context, client kubernetes.Interface, f config.PortForward) (*v1.Pod, int, error) {
+	selector := f.Selector
+	var svcPort *v1.ServicePort
+	if f.Service != "" {
+		svc, err := client.CoreV1().Services(f.Namespace).Get(ctx, f.Service, meta.GetOptions{})
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "service %s/%s", f.Namespace, f.Service)
+		}
+		if len(svc.Spec.Selector) == 0 {
+			return nil, 0, errors.Errorf("service %s/%s has no selector", f.Namespace, f.Service)
+		}
+		for i := range svc.Spec.Ports {
+			if int(svc.Spec.Ports[i].Port) == f.Port {
+				svcPort = &svc.Spec.Ports[i]
+			}
+		}
+		if svcPort == nil {
+			return nil, 0, errors.Errorf("service %s/%s has no port %d", f.Namespace, f.Service, f.Port)
+		}
+		selector = labels.SelectorFromSet(svc.Spec.Selector).String()
+	}
+
+	pods, err := client.CoreV1().Pods(f.Namespace).List(ctx, meta.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, 0, errors.Wrapf(err, "listing pods %s", selector)
+	}
+	pod := readyPod(pods.Items)
+	if pod == nil {
+		return nil, 0, errors.Errorf("no ready pod matches %s in %s", selector, f.Namespace)
+	}
+	if svcPort == nil {
+		return pod, f.Port, nil
+	}
+	port, err := containerPort(pod, svcPort.TargetPort, f.Port)
+	return pod, port, err
+}
+
+// readyPod returns the first ready pod by name, nil if none is ready
+func readyPod(pods []v1.Pod) *v1.Pod {
+	sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+	for i := range pods {
+		if isReady(&pods[i]) {
+			return &pods[i]
+		}
+	}
+	return nil
+}
+
+func isReady(pod *v1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != v1.PodRunning {
+		return false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == v1.PodReady {
+			return c.Status == v1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// containerPort resolves the target port of a service port in pod, which is the service port if unset
+func containerPort(pod *v1.Pod, target intstr.IntOrString, port int) (int, error) {
+	if target.Type == intstr.Int {
+		if target.IntVal == 0 {
+			return port, nil
+		}
+		return int(target.IntVal), nil
+	}
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			if p.Name == target.StrVal {
+				return int(p.ContainerPort), nil
+			}
+		}
+	}
+	return 0, errors.Errorf("pod %s/%s has no port named %s", pod.Namespace, pod.Name, target.StrVal)
+}
+
+// waitPodGone returns once pod is not ready anymore, or ctx is done
+func waitPodGone(ctx context.Context, client kubernetes.Interface, pod *v1.Pod) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(podCheckInterval):
+		}
+		p, err := client.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, meta.GetOptions{})
+		if apierrors.IsNotFound(err) || (err == nil && (p.UID != pod.UID || !isReady(p))) {
+			klog.Infof("pod %s/%s went away", pod.Namespace, pod.Name)
+			return
+		}
+	}
+}
+
+// StartProcess runs the port-forward command of the profile in the background, recording its pid in the profile directory
+func StartProcess(profile string) error {
+	cmd := exec.Command(os.Args[0], "port-forward", "--profile", profile)
+	cmd.Env = append(os.Environ(), constants.IsMinikubeChildProcess+"=true")
+	if klog.V(8).Enabled() {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	}
+	if err := cmd.Start(); err != nil {
+		return errors.Wrap(err, "start port-forward")
+	}
+	if err := os.WriteFile(filepath.Join(localpath.Profile(profile), constants.PortForwardProcessFileName), []byte(strconv.Itoa(cmd.Process.Pid)), 0o644); err != nil {
+		return errors.Wrap(err, "write port-forward pid")
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"context"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    config.PortForward
+		wantErr bool
+	}{
+		{spec: "svc/web:8080:80", want: config.PortForward{Namespace: "default", Service: "web", HostPort: 8080, Port: 80}},
+		{spec: "monitoring/service/grafana:3000:3000", want: config.PortForward{Namespace: "monitoring", Service: "grafana", HostPort: 3000, Port: 3000}},
+		{spec: "pod/app=web,tier=front:8080:8080", want: config.PortForward{Namespace: "default", Selector: "app=web,tier=front", HostPort: 8080, Port: 8080}},
+		{spec: "kube-system/pod/app.kubernetes.io/name=dns:5353:53", want: config.PortForward{Namespace: "kube-system", Selector: "app.kubernetes.io/name=dns", HostPort: 5353, Port: 53}},
+		{spec: "svc/web:80", wantErr: true},
+		{spec: "deploy/web:8080:80", wantErr: true},
+		{spec: "svc/:8080:80", wantErr: true},
+		{spec: "svc/web:0:80", wantErr: true},
+		{spec: "svc/web:8080:http", wantErr: true},
+		{spec: "pod/app in (web:8080:80", wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.spec, func(t *testing.T) {
+			got, err := Parse(tc.spec)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tc.spec, err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got != tc.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tc.spec, got, tc.want)
+			}
+			back, err := Parse(String(got))
+			if err != nil || back != got {
+				t.Errorf("Parse(String(%+v)) = %+v, %v", got, back, err)
+			}
+		})
+	}
+}
+
+func pod(name string, ready bool) *v1.Pod {
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	return &v1.Pod{
+		ObjectMeta: meta.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "web"}},
+		Spec: v1.PodSpec{Containers: []v1.Container{{
+			Name:  "web",
+			Ports: []v1.ContainerPort{{Name: "http", ContainerPort: 8000}},
+		}}},
+		Status: v1.PodStatus{
+			Phase:      v1.PodRunning,
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestTarget(t *testing.T) {
+	svc := &v1.Service{
+		ObjectMeta: meta.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: v1.ServiceSpec{
+			Selector: map[string]string{"app": "web"},
+			Ports: []v1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromString("http")},
+				{Name: "admin", Port: 9090, TargetPort: intstr.FromInt32(9091)},
+				{Name: "metrics", Port: 9100},
+			},
+		},
+	}
+	client := fake.NewSimpleClientset(svc, pod("web-a", false), pod("web-b", true), pod("web-c", true))
+
+	tests := []struct {
+		name    string
+		forward config.PortForward
+		pod     string
+		port    int
+		wantErr bool
+	}{
+		{name: "named target port", forward: config.PortForward{Namespace: "default", Service: "web", Port: 80}, pod: "web-b", port: 8000},
+		{name: "numbered target port", forward: config.PortForward{Namespace: "default", Service: "web", Port: 9090}, pod: "web-b", port: 9091},
+		{name: "unset target port", forward: config.PortForward{Namespace: "default", Service: "web", Port: 9100}, pod: "web-b", port: 9100},
+		{name: "pod selector", forward: config.PortForward{Namespace: "default", Selector: "app=web", Port: 8000}, pod: "web-b", port: 8000},
+		{name: "missing service port", forward: config.PortForward{Namespace: "default", Service: "web", Port: 443}, wantErr: true},
+		{name: "missing service", forward: config.PortForward{Namespace: "default", Service: "api", Port: 80}, wantErr: true},
+		{name: "no ready pod", forward: config.PortForward{Namespace: "default", Selector: "app=api", Port: 80}, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, port, err := Target(context.Background(), client, tc.forward)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Target() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if p.Name != tc.pod || port != tc.port {
+				t.Errorf("Target() = %s:%d, want %s:%d", p.Name, port, tc.pod, tc.port)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package preloadbuild builds the preload tarballs carrying images of the user next to the ones of Kubernetes,
+// which minikube start --preload-from extracts into the nodes instead of the preload of minikube
+package preloadbuild
+
+import (
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/drivers/kic"
+	"k8s.io/minikube/pkg/drivers/kic/oci"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/bsutil"
+	"k8s.io/minikube/pkg/minikube/bootstrapper/images"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+	"k8s.io/minikube/pkg/minikube/detect"
+	"k8s.io/minikube/pkg/minikube/download"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/sysinit"
+	"k8s.io/minikube/pkg/util"
+	"k8s.io/minikube/pkg/util/retry"
+)
+
+const (
+	// nodeName is the name of the temporary node the tarballs are built in
+	nodeName = "minikube-preload-build"
+
+	// tarball is the path of the tarball in the node
+	tarball = "/preloaded.tar.zst"
+)
+
+// Options selects the content of a preload tarball
+type Options struct {
+	// KubernetesVersion is the version of the Kubernetes images and binaries
+	KubernetesVersion string
+	// ContainerRuntime is the container runtime whose image store is preloaded
+	ContainerRuntime string
+	// Images are pulled into the tarball next to the images of Kubernetes
+	Images []string
+	// OCIBinary runs the temporary node, docker or podman
+	OCIBinary string
+}
+
+// Dirs returns the dirs of /var carried by the preload tarballs of the container runtime
+func Dirs(containerRuntime string) []string {
+	dirs := []string{"./lib/minikube/binaries"}
+	switch containerRuntime {
+	case constants.Docker:
+		dirs = append(dirs, "./lib/docker/overlay2", "./lib/docker/image")
+	case constants.Containerd:
+		dirs = append(dirs, "./lib/containerd")
+	case constants.CRIO, "cri-o":
+		dirs = append(dirs, "./lib/containers")
+	}
+	return dirs
+}
+
+// Images returns the images of the preload tarball of o: the ones of Kubernetes, of the CNI of the container runtime, and of the user
+func Images(o Options) ([]string, error) {
+	imgs, err := images.Kubeadm("", o.KubernetesVersion)
+	if err != nil {
+		return nil, errors.Wrap(err, "kubeadm images")
+	}
+	// kindnet is only needed by containerd and cri-o https://github.com/kubernetes/minikube/issues/7428
+	if o.ContainerRuntime != constants.Docker {
+		imgs = append(imgs, images.KindNet(""))
+	}
+	seen := map[string]bool{}
+	for _, img := range imgs {
+		seen[img] = true
+	}
+	for _, img := range o.Images {
+		if !seen[img] {
+			seen[img] = true
+			imgs = append(imgs, img)
+		}
+	}
+	return imgs, nil
+}
+
+// Build builds the preload tarball of o into dst, in a temporary node removed afterwards
+func Build(o Options, dst string) error {
+	imgs, err := Images(o)
+	if err != nil {
+		return err
+	}
+	sv, err := util.ParseKubernetesVersion(o.KubernetesVersion)
+	if err != nil {
+		return errors.Wrap(err, "parsing Kubernetes version")
+	}
+
+	d := kic.NewDriver(kic.Config{
+		ClusterName:       nodeName,
+		MachineName:       nodeName,
+		KubernetesVersion: o.KubernetesVersion,
+		ContainerRuntime:  o.ContainerRuntime,
+		OCIBinary:         o.OCIBinary,
+		ImageDigest:       kic.BaseImage,
+		StorePath:         localpath.MiniPath(),
+		CPU:               2,
+		Memory:            4000,
+		APIServerPort:     8080,
+	})
+	baseDir := filepath.Dir(d.GetSSHKeyPath())
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		return errors.Wrap(err, "mkdir")
+	}
+	defer func() {
+		if err := d.Remove(); err != nil {
+			klog.Warningf("failed to remove the node %s: %v", nodeName, err)
+		}
+		if err := oci.RemoveVolume(o.OCIBinary, nodeName); err != nil {
+			klog.Warningf("failed to remove the volume of %s: %v", nodeName, err)
+		}
+		os.RemoveAll(baseDir)
+	}()
+	if err := d.Create(); err != nil {
+		return errors.Wrap(err, "creating the node")
+	}
+
+	runner := command.NewKICRunner(nodeName, o.OCIBinary)
+	cr, err := cruntime.New(cruntime.Config{Type: o.ContainerRuntime, Runner: runner, KubernetesVersion: sv})
+	if err != nil {
+		return errors.Wrap(err, "container runtime")
+	}
+	if err := cr.Enable(true, detect.CgroupDriver(), false); err != nil {
+		return errors.Wrap(err, "enabling the container runtime")
+	}
+
+	for _, img := range imgs {
+		klog.Infof("pulling %s", img)
+		pull := func() error { return cr.PullImage(img) }
+		if err := retry.Expo(pull, time.Second, time.Minute, 5); err != nil {
+			return errors.Wrapf(err, "pulling %s", img)
+		}
+	}
+
+	sm := sysinit.New(runner)
+	if err := bsutil.TransferBinaries(config.KubernetesConfig{KubernetesVersion: o.KubernetesVersion}, runner, sm, ""); err != nil {
+		return errors.Wrap(err, "transferring the Kubernetes binaries")
+	}
+
+	args := append([]string{"tar", "-I", download.PreloadCompressor, "-C", "/var", "-cf", tarball}, Dirs(o.ContainerRuntime)...)
+	if rr, err := runner.RunCmd(exec.Command("sudo", args...)); err != nil {
+		return errors.Wrapf(err, "creating the tarball: %s", rr.Output())
+	}
+	return copyTarball(runner, dst)
+}
+
+// copyTarball copies the tarball of the node of r to dst
+func copyTarball(r command.Runner, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return errors.Wrap(err, "mkdir")
+	}
+	if err := os.WriteFile(dst, nil, 0644); err != nil {
+		return errors.Wrap(err, "create tarball")
+	}
+	f, err := assets.NewFileAsset(dst, path.Dir(tarball), path.Base(tarball), "0644")
+	if err != nil {
+		return errors.Wrap(err, "file asset")
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			klog.Warningf("error closing the file %s: %v", f.GetSourcePath(), err)
+		}
+	}()
+	return r.CopyFrom(f)
+}
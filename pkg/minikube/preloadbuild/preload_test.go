@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preloadbuild
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/bootstrapper/images"
+	"k8s.io/minikube/pkg/minikube/constants"
+)
+
+func TestDirs(t *testing.T) {
+	tests := []struct {
+		runtime string
+		want    []string
+	}{
+		{constants.Docker, []string{"./lib/minikube/binaries", "./lib/docker/overlay2", "./lib/docker/image"}},
+		{constants.Containerd, []string{"./lib/minikube/binaries", "./lib/containerd"}},
+		{constants.CRIO, []string{"./lib/minikube/binaries", "./lib/containers"}},
+	}
+	for _, tc := range tests {
+		if got := Dirs(tc.runtime); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Dirs(%s) = %v, want %v", tc.runtime, got, tc.want)
+		}
+	}
+}
+
+func TestImages(t *testing.T) {
+	kubeadm, err := images.Kubeadm("", constants.DefaultKubernetesVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	extra := []string{"ghcr.io/example/app:v1", kubeadm[0], "ghcr.io/example/app:v1"}
+
+	got, err := Images(Options{KubernetesVersion: constants.DefaultKubernetesVersion, ContainerRuntime: constants.Docker, Images: extra})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append(append([]string{}, kubeadm...), "ghcr.io/example/app:v1")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Images(docker) = %v, want %v", got, want)
+	}
+
+	got, err = Images(Options{KubernetesVersion: constants.DefaultKubernetesVersion, ContainerRuntime: constants.Containerd, Images: extra})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = append(append(append([]string{}, kubeadm...), images.KindNet("")), "ghcr.io/example/app:v1")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Images(containerd) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package pressure watches the memory and CPU usage of the host, to throttle the cluster while the host is under pressure
+package pressure
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/mem"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/kapi"
+)
+
+// ThrottledAnnotation records the replicas of a workload scaled down by ScaleDown
+const ThrottledAnnotation = "minikube.k8s.io/throttled-replicas"
+
+// Sample is the usage of the host, in percents
+type Sample struct {
+	Memory float64
+	CPU    float64
+}
+
+// Read samples the memory and CPU usage of the host, measuring the CPU usage for a second
+func Read() (Sample, error) {
+	v, err := mem.VirtualMemory()
+	if err != nil {
+		return Sample{}, errors.Wrap(err, "memory usage")
+	}
+	c, err := cpu.Percent(time.Second, false)
+	if err != nil {
+		return Sample{}, errors.Wrap(err, "cpu usage")
+	}
+	if len(c) == 0 {
+		return Sample{}, errors.New("cpu usage: no sample")
+	}
+	return Sample{Memory: v.UsedPercent, CPU: c[0]}, nil
+}
+
+// Action is what to do with the cluster after a sample
+type Action int
+
+const (
+	// None keeps the cluster as it is
+	None Action = iota
+	// Throttle throttles the cluster, the host is under pressure
+	Throttle
+	// Resume resumes the throttled cluster, the pressure subsided
+	Resume
+)
+
+// Thresholds are the usages above which the host is under pressure, a zero threshold is ignored
+type Thresholds struct {
+	Memory float64
+	CPU    float64
+	// Margin is how far below the thresholds the usage must get to resume, so that the cluster does not flap
+	Margin float64
+	// For is how long the pressure must last, or have subsided, to throttle or resume
+	For time.Duration
+}
+
+// Monitor decides when to throttle and resume the cluster from the samples of the host
+type Monitor struct {
+	Thresholds
+	throttled bool
+	// since is when the samples started calling for a change, zero if the last one did not
+	since time.Time
+}
+
+// NewMonitor returns a monitor of the thresholds, for a cluster which is not throttled
+func NewMonitor(t Thresholds) *Monitor {
+	return &Monitor{Thresholds: t}
+}
+
+// Throttled returns whether the monitor throttled the cluster
+func (m *Monitor) Throttled() bool {
+	return m.throttled
+}
+
+// Observe records a sample taken at now, and returns the action to take
+func (m *Monitor) Observe(s Sample, now time.Time) Action {
+	if !m.calledFor(s) {
+		m.since = time.Time{}
+		return None
+	}
+	if m.since.IsZero() {
+		m.since = now
+	}
+	if now.Sub(m.since) < m.For {
+		return None
+	}
+	m.since = time.Time{}
+	m.throttled = !m.throttled
+	if m.throttled {
+		return Throttle
+	}
+	return Resume
+}
+
+// calledFor returns whether the sample calls for throttling the cluster, or resuming it if throttled
+func (m *Monitor) calledFor(s Sample) bool {
+	if m.throttled {
+		return below(s.Memory, m.Memory-m.Margin, m.Memory) && below(s.CPU, m.CPU-m.Margin, m.CPU)
+	}
+	return above(s.Memory, m.Memory) || above(s.CPU, m.CPU)
+}
+
+func above(usage, threshold float64) bool {
+	return threshold > 0 && usage >= threshold
+}
+
+func below(usage, limit, threshold float64) bool {
+	return threshold <= 0 || usage < limit
+}
+
+// ScaleDown scales the Deployments and StatefulSets of the namespaces down to zero, recording their replicas for ScaleUp.
+// Returns the scaled down workloads.
+func ScaleDown(ctx context.Context, c kubernetes.Interface, namespaces []string) ([]string, error) {
+	var scaled []string
+	for _, ns := range namespaces {
+		ws, err := list(ctx, c, ns)
+		if err != nil {
+			return scaled, err
+		}
+		for _, w := range ws {
+			if !scaleDownNeeded(w.ObjectMeta, w.replicas) {
+				continue
+			}
+			if err := w.scale(ctx, c, 0, strconv.Itoa(int(kapi.Replicas(w.replicas)))); err != nil {
+				return scaled, err
+			}
+			scaled = append(scaled, w.String())
+		}
+	}
+	return scaled, nil
+}
+
+// ScaleUp scales the workloads of the namespaces scaled down by ScaleDown back to their replicas. Returns the scaled up workloads.
+func ScaleUp(ctx context.Context, c kubernetes.Interface, namespaces []string) ([]string, error) {
+	var scaled []string
+	for _, ns := range namespaces {
+		ws, err := list(ctx, c, ns)
+		if err != nil {
+			return scaled, err
+		}
+		for _, w := range ws {
+			r, ok := throttledReplicas(w.ObjectMeta)
+			if !ok {
+				continue
+			}
+			if err := w.scale(ctx, c, r, ""); err != nil {
+				return scaled, err
+			}
+			scaled = append(scaled, w.String())
+		}
+	}
+	return scaled, nil
+}
+
+// workload is a Deployment or StatefulSet
+type workload struct {
+	meta.ObjectMeta
+	kind     string
+	replicas *int32
+}
+
+func (w workload) String() string {
+	return w.kind + " " + w.Namespace + "/" + w.Name
+}
+
+// list returns the Deployments and StatefulSets of the namespace
+func list(ctx context.Context, c kubernetes.Interface, ns string) ([]workload, error) {
+	var ws []workload
+	deps, err := c.AppsV1().Deployments(ns).List(ctx, meta.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "list deployments")
+	}
+	for _, d := range deps.Items {
+		ws = append(ws, workload{ObjectMeta: d.ObjectMeta, kind: "deployment", replicas: d.Spec.Replicas})
+	}
+	sets, err := c.AppsV1().StatefulSets(ns).List(ctx, meta.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "list statefulsets")
+	}
+	for _, s := range sets.Items {
+		ws = append(ws, workload{ObjectMeta: s.ObjectMeta, kind: "statefulset", replicas: s.Spec.Replicas})
+	}
+	return ws, nil
+}
+
+// scale sets the replicas of the workload and its throttled annotation, which is removed if empty
+func (w workload) scale(ctx context.Context, c kubernetes.Interface, replicas int32, throttled string) error {
+	var annotation interface{}
+	if throttled != "" {
+		annotation = throttled
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]interface{}{ThrottledAnnotation: annotation}},
+		"spec":     map[string]interface{}{"replicas": replicas},
+	})
+	if err != nil {
+		return err
+	}
+	if w.kind == "deployment" {
+		_, err = c.AppsV1().Deployments(w.Namespace).Patch(ctx, w.Name, types.MergePatchType, patch, meta.PatchOptions{})
+	} else {
+		_, err = c.AppsV1().StatefulSets(w.Namespace).Patch(ctx, w.Name, types.MergePatchType, patch, meta.PatchOptions{})
+	}
+	return errors.Wrapf(err, "scale %s to %d", w, replicas)
+}
+
+// scaleDownNeeded returns whether the workload runs and was not scaled down already
+func scaleDownNeeded(m meta.ObjectMeta, r *int32) bool {
+	_, throttled := m.Annotations[ThrottledAnnotation]
+	return !throttled && kapi.Replicas(r) > 0
+}
+
+func throttledReplicas(m meta.ObjectMeta) (int32, bool) {
+	a, ok := m.Annotations[ThrottledAnnotation]
+	if !ok {
+		return 0, false
+	}
+	r, err := strconv.Atoi(a)
+	if err != nil {
+		klog.Warningf("%s/%s: invalid %s %q, restoring 1 replica", m.Namespace, m.Name, ThrottledAnnotation, a)
+		r = 1
+	}
+	return int32(r), true
+}
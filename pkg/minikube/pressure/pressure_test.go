@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pressure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMonitor(t *testing.T) {
+	m := NewMonitor(Thresholds{Memory: 90, CPU: 80, Margin: 10, For: 30 * time.Second})
+	start := time.Now()
+	steps := []struct {
+		at     time.Duration
+		sample Sample
+		want   Action
+	}{
+		{0, Sample{Memory: 50, CPU: 20}, None},
+		// the pressure must last
+		{5 * time.Second, Sample{Memory: 95, CPU: 20}, None},
+		{20 * time.Second, Sample{Memory: 60, CPU: 20}, None},
+		{25 * time.Second, Sample{Memory: 60, CPU: 85}, None},
+		{55 * time.Second, Sample{Memory: 92, CPU: 50}, Throttle},
+		{60 * time.Second, Sample{Memory: 95, CPU: 90}, None},
+		// below the thresholds, but not below the margin
+		{65 * time.Second, Sample{Memory: 85, CPU: 20}, None},
+		{100 * time.Second, Sample{Memory: 85, CPU: 20}, None},
+		{105 * time.Second, Sample{Memory: 70, CPU: 20}, None},
+		{135 * time.Second, Sample{Memory: 70, CPU: 60}, Resume},
+		{140 * time.Second, Sample{Memory: 70, CPU: 60}, None},
+	}
+	for _, s := range steps {
+		if got := m.Observe(s.sample, start.Add(s.at)); got != s.want {
+			t.Errorf("at %s, Observe(%+v) = %v, want %v", s.at, s.sample, got, s.want)
+		}
+	}
+	if m.Throttled() {
+		t.Errorf("Throttled() = true after resuming")
+	}
+
+	// a zero threshold is ignored
+	m = NewMonitor(Thresholds{CPU: 80, For: 0})
+	if got := m.Observe(Sample{Memory: 100, CPU: 10}, start); got != None {
+		t.Errorf("Observe with the memory threshold disabled = %v, want None", got)
+	}
+	if got := m.Observe(Sample{Memory: 100, CPU: 80}, start); got != Throttle {
+		t.Errorf("Observe above the CPU threshold = %v, want Throttle", got)
+	}
+	if got := m.Observe(Sample{Memory: 100, CPU: 10}, start); got != Resume {
+		t.Errorf("Observe below the CPU threshold = %v, want Resume", got)
+	}
+}
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func TestScaleDownUp(t *testing.T) {
+	c := fake.NewSimpleClientset(
+		&apps.Deployment{ObjectMeta: meta.ObjectMeta{Namespace: "dev", Name: "web"}, Spec: apps.DeploymentSpec{Replicas: int32Ptr(3)}},
+		&apps.Deployment{ObjectMeta: meta.ObjectMeta{Namespace: "dev", Name: "idle"}, Spec: apps.DeploymentSpec{Replicas: int32Ptr(0)}},
+		&apps.StatefulSet{ObjectMeta: meta.ObjectMeta{Namespace: "dev", Name: "db"}, Spec: apps.StatefulSetSpec{Replicas: int32Ptr(1)}},
+		&apps.Deployment{ObjectMeta: meta.ObjectMeta{Namespace: "prod", Name: "api"}, Spec: apps.DeploymentSpec{Replicas: int32Ptr(2)}},
+	)
+	ctx := context.Background()
+
+	scaled, err := ScaleDown(ctx, c, []string{"dev"})
+	if err != nil {
+		t.Fatalf("ScaleDown: %v", err)
+	}
+	if len(scaled) != 2 {
+		t.Errorf("ScaleDown scaled %v, want web and db", scaled)
+	}
+	web, err := c.AppsV1().Deployments("dev").Get(ctx, "web", meta.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *web.Spec.Replicas != 0 || web.Annotations[ThrottledAnnotation] != "3" {
+		t.Errorf("web has %d replicas and %s=%q, want 0 and \"3\"", *web.Spec.Replicas, ThrottledAnnotation, web.Annotations[ThrottledAnnotation])
+	}
+	// scaling down again keeps the recorded replicas
+	if scaled, err := ScaleDown(ctx, c, []string{"dev"}); err != nil || len(scaled) != 0 {
+		t.Errorf("ScaleDown again = %v, %v, want nothing", scaled, err)
+	}
+
+	scaled, err = ScaleUp(ctx, c, []string{"dev"})
+	if err != nil {
+		t.Fatalf("ScaleUp: %v", err)
+	}
+	if len(scaled) != 2 {
+		t.Errorf("ScaleUp scaled %v, want web and db", scaled)
+	}
+	web, err = c.AppsV1().Deployments("dev").Get(ctx, "web", meta.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := web.Annotations[ThrottledAnnotation]; *web.Spec.Replicas != 3 || ok {
+		t.Errorf("web has %d replicas and annotations %v, want 3 and no %s", *web.Spec.Replicas, web.Annotations, ThrottledAnnotation)
+	}
+	idle, err := c.AppsV1().Deployments("dev").Get(ctx, "idle", meta.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *idle.Spec.Replicas != 0 {
+		t.Errorf("idle has %d replicas, want 0", *idle.Spec.Replicas)
+	}
+	api, err := c.AppsV1().Deployments("prod").Get(ctx, "api", meta.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *api.Spec.Replicas != 2 {
+		t.Errorf("a workload of another namespace was scaled")
+	}
+}
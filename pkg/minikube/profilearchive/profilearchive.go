@@ -0,0 +1,276 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package profilearchive packs the config, images and applied objects of a cluster into a single archive,
+// which recreates the cluster on another host
+package profilearchive
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/bundle"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+const (
+	// manifestName is the name of the manifest in the archive, before the files
+	manifestName = "profile.json"
+	// ImagesDir is the directory of the archive holding the images exported from the nodes
+	ImagesDir = "images"
+	// ObjectsFile is the file of the archive holding the objects applied to the cluster, as a List
+	ObjectsFile = "objects.json"
+)
+
+// lastApplied is the annotation of kubectl apply holding the applied manifest of an object
+const lastApplied = "kubectl.kubernetes.io/last-applied-configuration"
+
+// systemNamespaces hold the objects of Kubernetes and minikube, which the new cluster creates itself
+var systemNamespaces = map[string]bool{"kube-system": true, "kube-public": true, "kube-node-lease": true}
+
+// Manifest describes the cluster an archive was exported from, and what it holds
+type Manifest struct {
+	MinikubeVersion string
+	Config          config.ClusterConfig
+	// Images are the images exported from each node, by name of node
+	Images map[string][]string
+	// Files are the entries of the files, relative to the directory they were exported from
+	Files []string
+}
+
+// Write writes the archive of m to dst, a tarball of the manifest and the files of dir, compressed with gzip
+// if dst ends with .gz or .tgz and with zstd otherwise
+func Write(dst string, m Manifest, dir string) (err error) {
+	var files []string
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "listing files")
+	}
+	sort.Strings(files)
+	m.Files = files
+
+	tmp := dst + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(tmp)
+			return
+		}
+		err = os.Rename(tmp, dst)
+	}()
+
+	var zw io.WriteCloser
+	if strings.HasSuffix(dst, ".gz") || strings.HasSuffix(dst, ".tgz") {
+		zw = gzip.NewWriter(f)
+	} else if zw, err = zstd.NewWriter(f); err != nil {
+		return err
+	}
+	tw := tar.NewWriter(zw)
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "manifest")
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: manifestName, Mode: 0644, Size: int64(len(b))}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(b); err != nil {
+		return err
+	}
+	for _, entry := range files {
+		if err := writeFile(tw, entry, filepath.Join(dir, filepath.FromSlash(entry))); err != nil {
+			return errors.Wrapf(err, "adding %s", entry)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeFile(tw *tar.Writer, entry string, p string) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if !st.Mode().IsRegular() {
+		return errors.Errorf("%s is not a regular file", p)
+	}
+	klog.Infof("adding %s (%d bytes)", p, st.Size())
+	if err := tw.WriteHeader(&tar.Header{Name: entry, Mode: int64(st.Mode().Perm()), Size: st.Size(), ModTime: st.ModTime()}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
+
+// Extract writes the files of the archive src into dir, and returns its manifest
+func Extract(src string, dir string) (*Manifest, error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// the compression is told by the magic number rather than by the name, which may have been changed
+	br := bufio.NewReader(f)
+	magic, err := br.Peek(4)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s is not a profile archive", src)
+	}
+	var r io.Reader
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s is not a profile archive", src)
+		}
+		r = gz
+	case bytes.Equal(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s is not a profile archive", src)
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return nil, errors.Errorf("%s is not a profile archive: neither gzip nor zstd compressed", src)
+	}
+	tr := tar.NewReader(r)
+
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != manifestName {
+		return nil, errors.Errorf("%s is not a profile archive: no %s", src, manifestName)
+	}
+	var m Manifest
+	if err := json.NewDecoder(tr).Decode(&m); err != nil {
+		return nil, errors.Wrap(err, "manifest")
+	}
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return &m, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return nil, errors.Errorf("%s is not a regular file", hdr.Name)
+		}
+		if path.IsAbs(hdr.Name) || path.Clean(hdr.Name) != hdr.Name || hdr.Name == ".." || strings.HasPrefix(hdr.Name, "../") {
+			return nil, errors.Errorf("invalid entry %q", hdr.Name)
+		}
+		if err := bundle.ExtractFile(tr, filepath.Join(dir, filepath.FromSlash(hdr.Name)), os.FileMode(hdr.Mode).Perm()); err != nil {
+			return nil, errors.Wrapf(err, "extracting %s", hdr.Name)
+		}
+	}
+}
+
+// AppliedObjects returns the manifests applied with kubectl apply to the objects of list, the output of kubectl get -o json,
+// as a List of the ones the new cluster does not create itself, namespaces and custom resource definitions first
+func AppliedObjects(list []byte) ([]byte, error) {
+	var in struct {
+		Items []struct {
+			Metadata struct {
+				Namespace   string
+				Labels      map[string]string
+				Annotations map[string]string
+			}
+		}
+	}
+	if err := json.Unmarshal(list, &in); err != nil {
+		return nil, errors.Wrap(err, "parse objects")
+	}
+
+	var items []json.RawMessage
+	var kinds []string
+	for _, item := range in.Items {
+		applied, ok := item.Metadata.Annotations[lastApplied]
+		if !ok || systemNamespaces[item.Metadata.Namespace] {
+			continue
+		}
+		// the objects of the addons, which the new cluster enables again
+		if _, ok := item.Metadata.Labels["addonmanager.kubernetes.io/mode"]; ok {
+			continue
+		}
+		var obj struct{ Kind string }
+		if err := json.Unmarshal([]byte(applied), &obj); err != nil {
+			klog.Warningf("skipping an invalid %s annotation: %v", lastApplied, err)
+			continue
+		}
+		items = append(items, json.RawMessage(applied))
+		kinds = append(kinds, obj.Kind)
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return kindRank(kinds[order[i]]) < kindRank(kinds[order[j]]) })
+	sorted := []json.RawMessage{}
+	for _, i := range order {
+		sorted = append(sorted, items[i])
+	}
+	return json.MarshalIndent(map[string]interface{}{"apiVersion": "v1", "kind": "List", "items": sorted}, "", "  ")
+}
+
+// kindRank orders the kinds which other objects depend on first
+func kindRank(kind string) int {
+	switch kind {
+	case "CustomResourceDefinition":
+		return 0
+	case "Namespace":
+		return 1
+	}
+	return 2
+}
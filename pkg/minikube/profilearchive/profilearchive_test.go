@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package profilearchive
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestWriteExtract(t *testing.T) {
+	for _, name := range []string{"dev.tar.zst", "dev.tar.gz"} {
+		t.Run(name, func(t *testing.T) {
+			src := t.TempDir()
+			contents := map[string]string{
+				"images/docker.io/library/nginx_1.25": "image",
+				ObjectsFile:                           `{"kind": "List"}`,
+			}
+			for entry, c := range contents {
+				p := filepath.Join(src, filepath.FromSlash(entry))
+				if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(p, []byte(c), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			archive := filepath.Join(t.TempDir(), name)
+			m := Manifest{
+				Config: config.ClusterConfig{Name: "dev", Driver: "docker"},
+				Images: map[string][]string{"dev": {"docker.io/library/nginx:1.25"}},
+			}
+			if err := Write(archive, m, src); err != nil {
+				t.Fatalf("Write: %v", err)
+			}
+
+			dst := t.TempDir()
+			got, err := Extract(archive, dst)
+			if err != nil {
+				t.Fatalf("Extract: %v", err)
+			}
+			if got.Config.Name != "dev" || !reflect.DeepEqual(got.Images, m.Images) || len(got.Files) != len(contents) {
+				t.Errorf("Extract() = %+v", got)
+			}
+			for entry, c := range contents {
+				b, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(entry)))
+				if err != nil || string(b) != c {
+					t.Errorf("%s = %q, %v, expected %q", entry, b, err, c)
+				}
+			}
+		})
+	}
+}
+
+func TestAppliedObjects(t *testing.T) {
+	list := `{"items": [
+		{"metadata": {"name": "web", "namespace": "default", "annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{\"kind\":\"Deployment\",\"metadata\":{\"name\":\"web\"}}"}}},
+		{"metadata": {"name": "created", "namespace": "default"}},
+		{"metadata": {"name": "coredns", "namespace": "kube-system", "annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{\"kind\":\"Deployment\"}"}}},
+		{"metadata": {"name": "ingress-nginx", "labels": {"addonmanager.kubernetes.io/mode": "Reconcile"}, "annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{\"kind\":\"Namespace\"}"}}},
+		{"metadata": {"name": "team", "annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{\"kind\":\"Namespace\",\"metadata\":{\"name\":\"team\"}}"}}}
+	]}`
+	b, err := AppliedObjects([]byte(list))
+	if err != nil {
+		t.Fatalf("AppliedObjects: %v", err)
+	}
+	var got struct {
+		Kind  string
+		Items []struct {
+			Kind     string
+			Metadata struct{ Name string }
+		}
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("invalid List %s: %v", b, err)
+	}
+	if got.Kind != "List" || len(got.Items) != 2 || got.Items[0].Metadata.Name != "team" || got.Items[1].Metadata.Name != "web" {
+		t.Errorf("AppliedObjects() = %s, expected the team namespace and the web deployment", b)
+	}
+}
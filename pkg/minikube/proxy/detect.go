@@ -0,0 +1,351 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/network"
+)
+
+// Sources of the proxy settings
+const (
+	SourceEnv      = "environment"
+	SourceRegistry = "Windows registry"
+	SourceScutil   = "scutil"
+	SourcePAC      = "PAC file"
+)
+
+// Settings are the proxy settings of the host
+type Settings struct {
+	HTTP    string
+	HTTPS   string
+	NoProxy []string
+	// Source is where the settings come from
+	Source string
+	// PACURL is the URL of the proxy auto-config file of the system, if any
+	PACURL string
+}
+
+// Empty returns whether the host uses no proxy
+func (s Settings) Empty() bool {
+	return s.HTTP == "" && s.HTTPS == ""
+}
+
+// Detect returns the proxy settings of the host: the environment variables, or else the system settings on Windows and macOS,
+// of which a PAC file is only read for the first proxy it returns
+func Detect() Settings {
+	if s := fromEnv(); !s.Empty() {
+		return s
+	}
+	s, err := systemSettings()
+	if err != nil {
+		klog.Infof("no system proxy settings: %v", err)
+		return Settings{}
+	}
+	if s.Empty() && s.PACURL != "" {
+		p, err := pacProxy(s.PACURL)
+		if err != nil {
+			klog.Warningf("unable to read the proxy of the PAC file %s: %v", s.PACURL, err)
+			return s
+		}
+		s.HTTP, s.HTTPS, s.Source = p, p, SourcePAC
+	}
+	return s
+}
+
+// fromEnv returns the proxy settings of the environment variables
+func fromEnv() Settings {
+	s := Settings{Source: SourceEnv}
+	s.HTTP = firstEnv("HTTP_PROXY", "http_proxy")
+	s.HTTPS = firstEnv("HTTPS_PROXY", "https_proxy")
+	s.NoProxy = splitList(firstEnv("NO_PROXY", "no_proxy"), ",")
+	return s
+}
+
+func firstEnv(keys ...string) string {
+	for _, k := range keys {
+		if v := os.Getenv(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// splitList splits a list of entries, dropping the empty ones
+func splitList(s, sep string) []string {
+	var l []string
+	for _, e := range strings.Split(s, sep) {
+		if e = strings.TrimSpace(e); e != "" {
+			l = append(l, e)
+		}
+	}
+	return l
+}
+
+// pacDirective matches the first proxy a PAC file returns
+var pacDirective = regexp.MustCompile(`PROXY\s+([^\s;"']+)`)
+
+// pacProxy returns the first proxy of the PAC file at u, as PAC files are scripts minikube does not evaluate
+func pacProxy(u string) (string, error) {
+	var b []byte
+	var err error
+	if path, ok := strings.CutPrefix(u, "file://"); ok {
+		b, err = os.ReadFile(path)
+	} else {
+		c := http.Client{Timeout: 5 * time.Second}
+		var resp *http.Response
+		resp, err = c.Get(u)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("%s: %s", u, resp.Status)
+		}
+		b, err = io.ReadAll(resp.Body)
+	}
+	if err != nil {
+		return "", err
+	}
+	m := pacDirective.FindSubmatch(b)
+	if m == nil {
+		return "", errors.New("the PAC file returns no proxy")
+	}
+	return "http://" + string(m[1]), nil
+}
+
+// parseScutil parses the output of 'scutil --proxy' on macOS:
+//
+//	<dictionary> {
+//	  ExceptionsList : <array> {
+//	    0 : *.local
+//	  }
+//	  HTTPEnable : 1
+//	  HTTPPort : 3128
+//	  HTTPProxy : proxy.example.com
+//	  ProxyAutoConfigURLString : http://wpad/wpad.dat
+//	}
+func parseScutil(output string) Settings {
+	s := Settings{Source: SourceScutil}
+	values := map[string]string{}
+	inExceptions := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if inExceptions {
+			if line == "}" {
+				inExceptions = false
+			} else if _, v, ok := strings.Cut(line, " : "); ok {
+				s.NoProxy = append(s.NoProxy, v)
+			}
+			continue
+		}
+		k, v, ok := strings.Cut(line, " : ")
+		if !ok {
+			continue
+		}
+		if k == "ExceptionsList" {
+			inExceptions = true
+			continue
+		}
+		values[k] = v
+	}
+	server := func(prefix string) string {
+		if values[prefix+"Enable"] != "1" || values[prefix+"Proxy"] == "" {
+			return ""
+		}
+		host := values[prefix+"Proxy"]
+		if p := values[prefix+"Port"]; p != "" {
+			host = net.JoinHostPort(host, p)
+		}
+		return "http://" + host
+	}
+	s.HTTP = server("HTTP")
+	s.HTTPS = server("HTTPS")
+	if values["ProxyAutoConfigEnable"] == "1" {
+		s.PACURL = values["ProxyAutoConfigURLString"]
+	}
+	return s
+}
+
+// parseProxyServer parses the ProxyServer value of the Internet Settings of the Windows registry, which is either
+// HOST:PORT for all the protocols, or a list of PROTOCOL=HOST:PORT separated by semicolons
+func parseProxyServer(server string) (string, string) {
+	if !strings.Contains(server, "=") {
+		p := withScheme(server)
+		return p, p
+	}
+	var httpProxy, httpsProxy string
+	for _, e := range splitList(server, ";") {
+		proto, host, _ := strings.Cut(e, "=")
+		switch strings.ToLower(proto) {
+		case "http":
+			httpProxy = withScheme(host)
+		case "https":
+			httpsProxy = withScheme(host)
+		}
+	}
+	return httpProxy, httpsProxy
+}
+
+// parseProxyOverride parses the ProxyOverride value of the Internet Settings of the Windows registry, dropping
+// <local>, which stands for the names without a dot
+func parseProxyOverride(override string) []string {
+	var l []string
+	for _, e := range splitList(override, ";") {
+		if e != "<local>" {
+			l = append(l, e)
+		}
+	}
+	return l
+}
+
+func withScheme(host string) string {
+	if host == "" || strings.Contains(host, "://") {
+		return host
+	}
+	return "http://" + host
+}
+
+// isLocal returns whether the proxy listens on the loopback of the host, which the cluster can not reach
+func isLocal(proxy string) bool {
+	u, err := url.Parse(withScheme(proxy))
+	if err != nil {
+		return false
+	}
+	h := u.Hostname()
+	return h == "localhost" || strings.HasPrefix(h, "127.") || h == "::1"
+}
+
+// NoProxy returns the NO_PROXY entries of the cluster: the ones of the host, and the addresses of the cluster which must
+// not go through the proxy, the nodes and their network, the services, the pods of podCIDR (comma separated for dual stack)
+// and the names of minikube
+func NoProxy(s Settings, cc config.ClusterConfig, podCIDR string) []string {
+	entries := append([]string{}, s.NoProxy...)
+	entries = append(entries, "localhost", "127.0.0.1", constants.ControlPlaneAlias, constants.HostAlias, ".svc")
+	domain := cc.KubernetesConfig.DNSDomain
+	if domain == "" {
+		domain = constants.ClusterDNSDomain
+	}
+	entries = append(entries, "."+domain)
+
+	serviceCIDR := cc.KubernetesConfig.ServiceCIDR
+	if serviceCIDR == "" {
+		serviceCIDR = constants.DefaultServiceCIDR
+	}
+	switch cc.KubernetesConfig.IPFamily {
+	case config.IPv6Family:
+		entries = append(entries, constants.DefaultServiceCIDRv6)
+	case config.DualStack:
+		entries = append(entries, serviceCIDR, constants.DefaultServiceCIDRv6)
+	default:
+		entries = append(entries, serviceCIDR)
+	}
+	entries = append(entries, splitList(podCIDR, ",")...)
+
+	if vip := cc.KubernetesConfig.APIServerHAVIP; vip != "" {
+		entries = append(entries, vip)
+	}
+	if cc.Subnet != "" {
+		entries = append(entries, cc.Subnet)
+	}
+	for _, n := range cc.Nodes {
+		if n.IP == "" {
+			continue
+		}
+		entries = append(entries, n.IP)
+		// the nodes added later are on the same network
+		if cc.Subnet == "" {
+			if p, err := network.Inspect(n.IP); err == nil {
+				entries = append(entries, p.CIDR)
+			} else {
+				klog.Infof("unable to inspect the network of %s: %v", n.IP, err)
+			}
+		}
+		if n.IPv6 != "" {
+			entries = append(entries, n.IPv6)
+		}
+	}
+
+	var dedup []string
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if !seen[e] {
+			seen[e] = true
+			dedup = append(dedup, e)
+		}
+	}
+	return dedup
+}
+
+// Env returns the proxy environment variables of the cluster as KEY=VALUE, in upper and lower case as tools read either,
+// nil when the host has no proxy the cluster can reach. The proxies of the environment are the ones SetDockerEnv passes
+// to the container runtime.
+func Env(s Settings, cc config.ClusterConfig, podCIDR string) []string {
+	proxies := map[string]string{"HTTP_PROXY": s.HTTP, "HTTPS_PROXY": s.HTTPS}
+	if s.Source == SourceEnv {
+		proxies = map[string]string{}
+		for _, e := range SetDockerEnv() {
+			if k, v, ok := strings.Cut(e, "="); ok && (k == "HTTP_PROXY" || k == "HTTPS_PROXY") {
+				proxies[k] = v
+			}
+		}
+	}
+	var env []string
+	for _, k := range []string{"HTTP_PROXY", "HTTPS_PROXY"} {
+		v := proxies[k]
+		if v == "" {
+			continue
+		}
+		if isLocal(v) {
+			klog.Warningf("not passing %s=%s to the cluster, which can not reach the loopback of the host", k, Mask(v))
+			continue
+		}
+		env = append(env, k+"="+v, strings.ToLower(k)+"="+v)
+	}
+	if len(env) == 0 {
+		return nil
+	}
+	noProxy := strings.Join(NoProxy(s, cc, podCIDR), ",")
+	return append(env, "NO_PROXY="+noProxy, "no_proxy="+noProxy)
+}
+
+// Mask hides the password of a proxy URL
+func Mask(proxy string) string {
+	u, err := url.Parse(proxy)
+	if err != nil || u.User == nil {
+		return proxy
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "*****")
+		return strings.Replace(u.String(), url.QueryEscape("*****"), "*****", 1)
+	}
+	return proxy
+}
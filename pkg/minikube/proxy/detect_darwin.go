@@ -0,0 +1,34 @@
+//go:build darwin
+
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"os/exec"
+
+	"github.com/pkg/errors"
+)
+
+// systemSettings returns the proxy settings of the network services of macOS
+func systemSettings() (Settings, error) {
+	out, err := exec.Command("scutil", "--proxy").Output()
+	if err != nil {
+		return Settings{}, errors.Wrap(err, "scutil --proxy")
+	}
+	return parseScutil(string(out)), nil
+}
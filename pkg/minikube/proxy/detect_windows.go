@@ -0,0 +1,47 @@
+//go:build windows
+
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"github.com/pkg/errors"
+	"golang.org/x/sys/windows/registry"
+)
+
+// systemSettings returns the proxy settings of the Internet Settings of the user in the registry
+func systemSettings() (Settings, error) {
+	k, err := registry.OpenKey(registry.CURRENT_USER, `Software\Microsoft\Windows\CurrentVersion\Internet Settings`, registry.QUERY_VALUE)
+	if err != nil {
+		return Settings{}, errors.Wrap(err, "opening the Internet Settings")
+	}
+	defer k.Close()
+
+	s := Settings{Source: SourceRegistry}
+	if enabled, _, err := k.GetIntegerValue("ProxyEnable"); err == nil && enabled == 1 {
+		if server, _, err := k.GetStringValue("ProxyServer"); err == nil {
+			s.HTTP, s.HTTPS = parseProxyServer(server)
+		}
+		if override, _, err := k.GetStringValue("ProxyOverride"); err == nil {
+			s.NoProxy = parseProxyOverride(override)
+		}
+	}
+	if pac, _, err := k.GetStringValue("AutoConfigURL"); err == nil {
+		s.PACURL = pac
+	}
+	return s, nil
+}
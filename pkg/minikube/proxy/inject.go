@@ -0,0 +1,204 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/sysinit"
+)
+
+// Runner is the subset of command.Runner this package consumes
+type Runner interface {
+	RunCmd(cmd *exec.Cmd) (*command.RunResult, error)
+	Copy(assets.CopyableFile) error
+}
+
+// dropInName is the name of the systemd drop-ins passing the proxy environment to the services of the nodes
+const dropInName = "20-proxy.conf"
+
+// Services returns the systemd services of a node which get the proxy environment: the container runtime and the kubelet
+func Services(runtime string) []string {
+	svc := runtime
+	if runtime == "cri-o" {
+		svc = "crio"
+	}
+	return []string{svc, "kubelet"}
+}
+
+// dropIn returns a systemd drop-in setting the environment variables env
+func dropIn(env []string) string {
+	var b strings.Builder
+	b.WriteString("[Service]\n")
+	for _, e := range env {
+		fmt.Fprintf(&b, "Environment=%q\n", e)
+	}
+	return b.String()
+}
+
+// ConfigureNode writes the systemd drop-ins passing the proxy environment env to the container runtime and the kubelet of
+// a node, which their next restart picks up, or removes them when env is empty
+func ConfigureNode(r Runner, runtime string, env []string) error {
+	for _, svc := range Services(runtime) {
+		p := sysinit.DropInPath(svc+".service", dropInName)
+		if len(env) == 0 {
+			if _, err := r.RunCmd(exec.Command("sudo", "rm", "-f", p)); err != nil {
+				return errors.Wrapf(err, "removing %s", p)
+			}
+			continue
+		}
+		if _, err := r.RunCmd(exec.Command("sudo", "mkdir", "-p", path.Dir(p))); err != nil {
+			return errors.Wrapf(err, "creating %s", path.Dir(p))
+		}
+		if err := r.Copy(assets.NewMemoryAssetTarget([]byte(dropIn(env)), p, "0644")); err != nil {
+			return errors.Wrapf(err, "writing %s", p)
+		}
+	}
+	return nil
+}
+
+// NodeEnv returns the proxy environment the drop-in of a service of a node passes, nil if it has none
+func NodeEnv(r Runner, svc string) ([]string, error) {
+	p := sysinit.DropInPath(svc+".service", dropInName)
+	rr, err := r.RunCmd(exec.Command("sudo", "sh", "-c", fmt.Sprintf("cat %s 2>/dev/null || true", p)))
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading %s", p)
+	}
+	var env []string
+	for _, line := range strings.Split(rr.Stdout.String(), "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), "Environment="); ok {
+			env = append(env, strings.Trim(v, `"`))
+		}
+	}
+	return env, nil
+}
+
+// podSpecPaths are the paths of the pod specs in the workloads, by kind
+var podSpecPaths = map[string][]string{
+	"Pod":         {"spec"},
+	"Deployment":  {"spec", "template", "spec"},
+	"DaemonSet":   {"spec", "template", "spec"},
+	"StatefulSet": {"spec", "template", "spec"},
+	"ReplicaSet":  {"spec", "template", "spec"},
+	"Job":         {"spec", "template", "spec"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// InjectPodEnv adds the proxy environment env to the containers of the workloads of a manifest, keeping the variables they set,
+// and returns the manifest unchanged when it has no workload
+func InjectPodEnv(manifest []byte, env []string) ([]byte, error) {
+	if len(env) == 0 {
+		return manifest, nil
+	}
+	var docs [][]byte
+	injected := false
+	r := utilyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(manifest)))
+	for {
+		doc, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		patched, ok, err := injectDoc(doc, env)
+		if err != nil {
+			return nil, err
+		}
+		injected = injected || ok
+		docs = append(docs, patched)
+	}
+	if !injected {
+		return manifest, nil
+	}
+	var b bytes.Buffer
+	for _, d := range docs {
+		b.WriteString("---\n")
+		b.Write(bytes.TrimPrefix(d, []byte("---\n")))
+	}
+	return b.Bytes(), nil
+}
+
+// injectDoc adds env to the containers of a document if it is a workload, and returns whether it did
+func injectDoc(doc []byte, env []string) ([]byte, bool, error) {
+	var u unstructured.Unstructured
+	if err := yaml.Unmarshal(doc, &u.Object); err != nil || u.Object == nil {
+		// not an object, such as a document of comments
+		return doc, false, nil
+	}
+	specPath, ok := podSpecPaths[u.GetKind()]
+	if !ok {
+		return doc, false, nil
+	}
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, found, err := unstructured.NestedSlice(u.Object, append(specPath, field)...)
+		if err != nil || !found {
+			continue
+		}
+		for i, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			container["env"] = withEnv(container["env"], env)
+			containers[i] = container
+		}
+		if err := unstructured.SetNestedSlice(u.Object, containers, append(specPath, field)...); err != nil {
+			return nil, false, errors.Wrapf(err, "setting the env of %s %s", u.GetKind(), u.GetName())
+		}
+	}
+	b, err := yaml.Marshal(u.Object)
+	if err != nil {
+		return nil, false, err
+	}
+	klog.Infof("passing the proxy environment to %s %s", u.GetKind(), u.GetName())
+	return b, true, nil
+}
+
+// withEnv appends the variables of env missing from the env of a container
+func withEnv(current interface{}, env []string) []interface{} {
+	vars, _ := current.([]interface{})
+	set := map[string]bool{}
+	for _, v := range vars {
+		if m, ok := v.(map[string]interface{}); ok {
+			if name, ok := m["name"].(string); ok {
+				set[name] = true
+			}
+		}
+	}
+	for _, e := range env {
+		k, v, _ := strings.Cut(e, "=")
+		if !set[k] {
+			vars = append(vars, map[string]interface{}{"name": k, "value": v})
+		}
+	}
+	return vars
+}
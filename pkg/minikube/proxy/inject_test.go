@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+func TestInjectPodEnv(t *testing.T) {
+	manifest := `# comments
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  key: value
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: init
+        image: busybox
+      containers:
+      - name: web
+        image: nginx
+        env:
+        - name: NO_PROXY
+          value: custom
+`
+	env := []string{"HTTP_PROXY=http://proxy:3128", "NO_PROXY=localhost"}
+	b, err := InjectPodEnv([]byte(manifest), env)
+	if err != nil {
+		t.Fatalf("InjectPodEnv: %v", err)
+	}
+	got := string(b)
+	if !strings.Contains(got, "key: value") {
+		t.Errorf("the ConfigMap was changed:\n%s", got)
+	}
+	if strings.Count(got, "value: http://proxy:3128") != 2 {
+		t.Errorf("HTTP_PROXY was not added to both containers:\n%s", got)
+	}
+	if !strings.Contains(got, "value: localhost") || !strings.Contains(got, "value: custom") {
+		t.Errorf("NO_PROXY of the container was overridden, or not added to the init container:\n%s", got)
+	}
+
+	unchanged := "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n"
+	if b, err := InjectPodEnv([]byte(unchanged), env); err != nil || string(b) != unchanged {
+		t.Errorf("InjectPodEnv(Service) = %q, %v, want it unchanged", b, err)
+	}
+}
+
+func TestConfigureNode(t *testing.T) {
+	r := command.NewFakeCommandRunner()
+	r.SetCommandToOutput(map[string]string{
+		"sudo mkdir -p /etc/systemd/system/crio.service.d":    "",
+		"sudo mkdir -p /etc/systemd/system/kubelet.service.d": "",
+	})
+	env := []string{"HTTP_PROXY=http://proxy:3128", "NO_PROXY=localhost,10.96.0.0/12"}
+	if err := ConfigureNode(r, "cri-o", env); err != nil {
+		t.Fatalf("ConfigureNode: %v", err)
+	}
+	// the fake runner keeps the copied files by source, the last drop-in
+	got, err := r.GetFileToContents(assets.MemorySource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[Service]\nEnvironment=\"HTTP_PROXY=http://proxy:3128\"\nEnvironment=\"NO_PROXY=localhost,10.96.0.0/12\"\n"
+	if got != want {
+		t.Errorf("drop-in = %q, want %q", got, want)
+	}
+
+	r.SetCommandToOutput(map[string]string{`sudo sh -c "cat /etc/systemd/system/kubelet.service.d/20-proxy.conf 2>/dev/null || true"`: want})
+	nodeEnv, err := NodeEnv(r, "kubelet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(nodeEnv, env) {
+		t.Errorf("NodeEnv() = %q, want %q", nodeEnv, env)
+	}
+}
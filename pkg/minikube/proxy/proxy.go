@@ -162,21 +162,12 @@ func SetDockerEnv() []string {
 			// TODO (@medyagh): if user has both http_proxy & HTTPS_PROXY set merge them.
 			k = strings.ToUpper(k)
 			if k == "HTTP_PROXY" || k == "HTTPS_PROXY" {
-				isLocalProxy := func(url string) bool {
-					return strings.HasPrefix(url, "localhost") || strings.HasPrefix(url, "127.0")
-				}
-
-				normalizedURL := v
-				if !strings.Contains(v, "://") {
-					normalizedURL = "http://" + v // by default, assumes the url is HTTP scheme
-				}
-				u, err := url.Parse(normalizedURL)
-				if err != nil {
+				if _, err := url.Parse(withScheme(v)); err != nil {
 					out.WarningT("Error parsing {{.name}}={{.value}}, {{.err}}", out.V{"name": k, "value": v, "err": err})
 					continue
 				}
 
-				if isLocalProxy(u.Host) {
+				if isLocal(v) {
 					out.WarningT("Local proxy ignored: not passing {{.name}}={{.value}} to docker env.", out.V{"name": k, "value": v})
 					continue
 				}
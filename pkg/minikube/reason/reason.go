@@ -72,6 +72,10 @@ var (
 	}
 	// minikube was interrupted by an OS signal
 	Interrupted = Kind{ID: "MK_INTERRUPTED", ExitCode: ExProgramConflict}
+	// another holder has the lease of the profile, see 'minikube lock'
+	ProfileLeased = Kind{ID: "MK_PROFILE_LEASED", ExitCode: ExProgramConflict,
+		Advice: translate.T("Wait for the holder to release the lease with 'minikube lock release', or run the command with the ID of the lease in $MINIKUBE_LEASE"),
+	}
 
 	// user attempted to run a Windows executable (.exe) inside of WSL rather than using the Linux binary
 	WrongBinaryWSL = Kind{ID: "MK_WRONG_BINARY_WSL", ExitCode: ExProgramUnsupported}
@@ -108,6 +112,8 @@ var (
 	InternalConfigSet = Kind{ID: "MK_CONFIG_SET", ExitCode: ExProgramError}
 	// minikube failed to unset an internal config value
 	InternalConfigUnset = Kind{ID: "MK_CONFIG_UNSET", ExitCode: ExProgramError}
+	// minikube failed to migrate the persisted configs
+	InternalConfigMigrate = Kind{ID: "MK_CONFIG_MIGRATE", ExitCode: ExProgramError}
 	// minikube failed to view current config values
 	InternalConfigView = Kind{ID: "MK_CONFIG_VIEW", ExitCode: ExProgramError}
 	// minikube failed to delete an internal configuration, such as a cached image
@@ -254,6 +260,10 @@ var (
 	HostHomeMkdir = Kind{ID: "HOST_HOME_MKDIR", ExitCode: ExHostPermission}
 	// minikube could not change permissions for the minikube directory
 	HostHomeChown = Kind{ID: "HOST_HOME_CHOWN", ExitCode: ExHostPermission}
+	// minikube could not set up a system-wide install
+	HostSystemInstall = Kind{ID: "HOST_SYSTEM_INSTALL", ExitCode: ExHostPermission}
+	// the profiles, certificates or kubeconfig of minikube are broken
+	HostProfileCheck = Kind{ID: "HOST_PROFILE_CHECK", ExitCode: ExHostConfig}
 	// minikube failed to open the host browser, such as when running minikube dashboard
 	HostBrowser = Kind{ID: "HOST_BROWSER", ExitCode: ExHostError}
 	// minikube failed to load cluster config from the host for the profile in use
@@ -266,6 +276,8 @@ var (
 		Issues:   []int{9165},
 	}
 
+	// minikube failed to read the cert history of a profile
+	HostCertHistory = Kind{ID: "HOST_CERT_HISTORY", ExitCode: ExHostError}
 	// minikube failed to determine current user
 	HostCurrentUser = Kind{ID: "HOST_CURRENT_USER", ExitCode: ExHostConfig}
 	// minikube failed to delete cached images from host
@@ -276,6 +288,8 @@ var (
 	HostKubeconfigUpdate = Kind{ID: "HOST_KUBECONFIG_UPDATE", ExitCode: ExHostConfig}
 	// minikube failed to delete Kubernetes config from context for a given profile
 	HostKubeconfigDeleteCtx = Kind{ID: "HOST_KUBECONFIG_DELETE_CTX", ExitCode: ExHostConfig}
+	// the kubeconfig entries of a cluster are broken or could not be verified
+	HostKubeconfigVerify = Kind{ID: "HOST_KUBECONFIG_VERIFY", ExitCode: ExHostConfig}
 	// minikube failed to launch a kubectl proxy
 	HostKubectlProxy = Kind{ID: "HOST_KUBECTL_PROXY", ExitCode: ExHostError}
 	// minikube failed to write mount pid
@@ -288,6 +302,29 @@ var (
 	HostPurge = Kind{ID: "HOST_PURGE", ExitCode: ExHostError}
 	// minikube failed to persist profile config
 	HostSaveProfile = Kind{ID: "HOST_SAVE_PROFILE", ExitCode: ExHostConfig}
+	// minikube failed to write or read an offline bundle
+	HostBundle = Kind{ID: "HOST_BUNDLE", ExitCode: ExHostError}
+	// minikube failed to take, restore or delete a snapshot of a cluster
+	HostSnapshot = Kind{ID: "HOST_SNAPSHOT", ExitCode: ExHostError}
+	// minikube failed to listen for the connections of kubectl to the idle proxy
+	HostIdleProxy = Kind{ID: "HOST_IDLE_PROXY", ExitCode: ExHostError}
+	// minikube failed to install or remove the timer of the host starting a cluster on schedule
+	HostScheduledStart = Kind{ID: "HOST_SCHEDULED_START", ExitCode: ExHostError}
+	// minikube failed to change the CPUs or memory of the machines of a cluster
+	HostResize = Kind{ID: "HOST_RESIZE", ExitCode: ExHostError}
+	// minikube failed to write or read the archive of an exported profile
+	HostProfileArchive = Kind{ID: "HOST_PROFILE_ARCHIVE", ExitCode: ExHostError}
+	// a pre-start or post-start hook of the cluster failed
+	HostHook = Kind{ID: "HOST_HOOK", ExitCode: ExHostError}
+	// minikube failed to layer an overlay onto the ISO or the kicbase image
+	HostBaseOverlay = Kind{ID: "HOST_BASE_OVERLAY", ExitCode: ExHostError}
+	// the host has no NVIDIA driver to run the GPU containers of the nodes
+	HostNvidia = Kind{
+		ID:       "HOST_NVIDIA",
+		ExitCode: ExHostUnsupported,
+		Advice:   translate.T("Run 'minikube doctor --gpu' to check the NVIDIA setup of the host"),
+		URL:      "https://minikube.sigs.k8s.io/docs/tutorials/nvidia/",
+	}
 
 	// minikube could not find a provider for the selected driver
 	ProviderNotFound = Kind{ID: "PROVIDER_NOT_FOUND", ExitCode: ExProviderNotFound}
@@ -324,6 +361,8 @@ var (
 	DrvAsRoot = Kind{ID: "DRV_AS_ROOT", ExitCode: ExDriverPermission}
 	// the specified driver needs to be run as root
 	DrvNeedsRoot = Kind{ID: "DRV_NEEDS_ROOT", ExitCode: ExDriverPermission}
+	// the start would run a privileged helper on the host, which --rootless=strict forbids
+	DrvRootlessStrict = Kind{ID: "DRV_ROOTLESS_STRICT", ExitCode: ExDriverPermission}
 
 	// minikube failed to load cached images
 	GuestCacheLoad = Kind{ID: "GUEST_CACHE_LOAD", ExitCode: ExGuestError}
@@ -366,6 +405,8 @@ var (
 	GuestMountConflict = Kind{ID: "GUEST_MOUNT_CONFLICT", ExitCode: ExGuestConflict}
 	// minikube failed to add a node to the cluster
 	GuestNodeAdd = Kind{ID: "GUEST_NODE_ADD", ExitCode: ExGuestError}
+	// minikube failed to drain a node of the cluster
+	GuestNodeDrain = Kind{ID: "GUEST_NODE_DRAIN", ExitCode: ExGuestError}
 	// minikube failed to remove a node from the cluster
 	GuestNodeDelete = Kind{ID: "GUEST_NODE_DELETE", ExitCode: ExGuestError}
 	// minikube failed to provision a node
@@ -386,14 +427,34 @@ var (
 	GuestStart = Kind{ID: "GUEST_START", ExitCode: ExGuestError}
 	// minikube failed to get docker machine status
 	GuestStatus = Kind{ID: "GUEST_STATUS", ExitCode: ExGuestError}
+	// minikube failed to reset the Kubernetes state of a cluster
+	GuestReset = Kind{ID: "GUEST_RESET", ExitCode: ExGuestError}
+	// the state of a node restarted after an unclean shutdown is corrupted
+	GuestStateCorrupt = Kind{ID: "GUEST_STATE_CORRUPT", ExitCode: ExGuestError, Advice: translate.T("Restore the etcd data saved by the last clean stop with 'minikube start --recover-state=restore-snapshot', or recreate the cluster with 'minikube delete'")}
 	// stopping the cluster process timed out
 	GuestStopTimeout = Kind{ID: "GUEST_STOP_TIMEOUT", ExitCode: ExGuestTimeout}
+	// minikube failed to replace the kubelet binary of a node
+	GuestKubeletReplace = Kind{ID: "GUEST_KUBELET_REPLACE", ExitCode: ExGuestError}
+	// minikube failed to configure a node for the node e2e tests
+	GuestNodeE2E = Kind{ID: "GUEST_NODE_E2E", ExitCode: ExGuestError}
+	// minikube failed to impair or restore the network of a node
+	GuestNetworkImpair = Kind{ID: "GUEST_NETWORK_IMPAIR", ExitCode: ExGuestError}
+	// the kernel of a node does not support the sysctls or kernel modules of the cluster
+	GuestKernelUnsupported = Kind{ID: "GUEST_KERNEL_UNSUPPORTED", ExitCode: ExGuestUnsupported}
+	// a node can not run the sandboxed runtime of the cluster
+	GuestRuntimeClassUnsupported = Kind{ID: "GUEST_RUNTIME_CLASS_UNSUPPORTED", ExitCode: ExGuestUnsupported, Advice: translate.T("Enable nested virtualization on the host, or start the cluster without --runtime-class")}
+	// minikube failed to configure the mirrors of the registries in the nodes
+	GuestRegistryMirror = Kind{ID: "GUEST_REGISTRY_MIRROR", ExitCode: ExGuestError}
+	// minikube failed to give the credentials of the registries to the nodes or namespaces
+	GuestRegistryAuth = Kind{ID: "GUEST_REGISTRY_AUTH", ExitCode: ExGuestError}
 	// minikube failed to unpause the cluster process
 	GuestUnpause = Kind{ID: "GUEST_UNPAUSE", ExitCode: ExGuestError}
 	// minikube failed to check if Kubernetes containers are paused
 	GuestCheckPaused = Kind{ID: "GUEST_CHECK_PAUSED", ExitCode: ExGuestError}
 	// minikube cluster was created used a driver that is incompatible with the driver being requested
 	GuestDrvMismatch = Kind{ID: "GUEST_DRIVER_MISMATCH", ExitCode: ExGuestConflict, Style: style.Conflict}
+	// minikube cluster can not be changed to match the spec applied with 'minikube apply' without being deleted
+	GuestApplyConflict = Kind{ID: "GUEST_APPLY_CONFLICT", ExitCode: ExGuestConflict, Style: style.Conflict}
 	// minikube could not find conntrack on the host, which is required from Kubernetes 1.18 onwards
 	GuestMissingConntrack = Kind{ID: "GUEST_MISSING_CONNTRACK", ExitCode: ExGuestUnsupported}
 	// minikube could not find crictl on the host, which is required from Kubernetes 1.24 onwards
@@ -424,6 +485,10 @@ var (
 	InetCacheKubectl = Kind{ID: "INET_CACHE_KUBECTL", ExitCode: ExInternetError}
 	// minikube failed to cache required images to tar files
 	InetCacheTar = Kind{ID: "INET_CACHE_TAR", ExitCode: ExInternetError}
+	// minikube failed to cache the preload tarball of --preload-from
+	InetCachePreload = Kind{ID: "INET_CACHE_PRELOAD", ExitCode: ExInternetError}
+	// minikube failed to cache the ISO booted by the VM drivers
+	InetCacheISO = Kind{ID: "INET_CACHE_ISO", ExitCode: ExInternetError}
 	// minikube failed to download licenses
 	InetLicenses = Kind{ID: "INET_LICENSES", ExitCode: ExInternetError}
 	// minikube was unable to access main repository and mirrors for images
@@ -460,6 +525,14 @@ var (
 	SvcURLTimeout = Kind{ID: "SVC_URL_TIMEOUT", ExitCode: ExSvcTimeout}
 	// minikube couldn't find the specified service in the specified namespace
 	SvcNotFound = Kind{ID: "SVC_NOT_FOUND", ExitCode: ExSvcNotFound}
+	// minikube failed to intercept a service or to restore an intercepted service
+	SvcIntercept = Kind{ID: "SVC_INTERCEPT", ExitCode: ExSvcError}
+	// minikube failed to add or delete host routes to the cluster networks
+	SvcRoute = Kind{ID: "SVC_ROUTE", ExitCode: ExSvcError}
+	// minikube failed to answer the DNS queries of the host for the services
+	SvcDNS = Kind{ID: "SVC_DNS", ExitCode: ExSvcError}
+	// minikube failed to advertise the services on the local network with mDNS
+	SvcMDNS = Kind{ID: "SVC_MDNS", ExitCode: ExSvcError}
 
 	// user attempted to use a command that is not supported by the driver currently in use
 	EnvDriverConflict = Kind{ID: "ENV_DRIVER_CONFLICT", ExitCode: ExDriverConflict}
@@ -481,6 +554,10 @@ var (
 	KubernetesTooOld = Kind{ID: "K8S_OLD_UNSUPPORTED", ExitCode: ExControlPlaneUnsupported}
 	// a too new Kubernetes version was specified for minikube to use
 	KubernetesTooNew = Kind{ID: "K8S_NEW_UNSUPPORTED", ExitCode: ExControlPlaneUnsupported}
+	// resources of the cluster use APIs removed by the target Kubernetes version
+	KubernetesRemovedAPIs = Kind{ID: "K8S_REMOVED_APIS", ExitCode: ExControlPlaneConflict}
+	// minikube failed to move the workloads of a namespace to another cluster
+	KubernetesWorkloadsMove = Kind{ID: "K8S_WORKLOADS_MOVE", ExitCode: ExControlPlaneError}
 	// error fetching GitHub Kubernetes version list
 	KubernetesNotConnect = Kind{ID: "K8S_FAIL_CONNECT", ExitCode: ExInternetError}
 	// minikube was unable to safely downgrade installed Kubernetes version
@@ -0,0 +1,90 @@
+//go:build linux
+
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudhypervisor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/docker/machine/libmachine/drivers"
+
+	pkgdrivers "k8s.io/minikube/pkg/drivers"
+	"k8s.io/minikube/pkg/drivers/cloudhypervisor"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/download"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/registry"
+)
+
+const docURL = "https://minikube.sigs.k8s.io/docs/reference/drivers/cloud-hypervisor/"
+
+func init() {
+	if err := registry.Register(registry.DriverDef{
+		Name:     driver.CloudHypervisor,
+		Init:     func() drivers.Driver { return cloudhypervisor.NewDriver("", "") },
+		Config:   configure,
+		Status:   status,
+		Default:  false,
+		Priority: registry.Experimental,
+	}); err != nil {
+		panic(fmt.Sprintf("register failed: %v", err))
+	}
+}
+
+func configure(cc config.ClusterConfig, n config.Node) (interface{}, error) {
+	mac, err := pkgdrivers.GenerateMACAddress()
+	if err != nil {
+		return nil, fmt.Errorf("generating MAC address: %v", err)
+	}
+	name := config.MachineName(cc, n)
+
+	return &cloudhypervisor.Driver{
+		BaseDriver: &drivers.BaseDriver{
+			MachineName: name,
+			StorePath:   localpath.MiniPath(),
+			SSHUser:     "docker",
+		},
+		Boot2DockerURL: download.LocalISOResource(cc.MinikubeISO),
+		DiskSize:       cc.DiskSize,
+		Memory:         cc.Memory,
+		CPU:            cc.CPUs,
+		MACAddress:     mac,
+		ExtraDisks:     cc.ExtraDisks,
+		Cmdline:        "loglevel=3 console=ttyS0 panic=1 noembed nomodeset norestore systemd.legacy_systemd_cgroup_controller=yes random.trust_cpu=on base host=" + name,
+		Kernel:         cc.CloudHypervisorKernel,
+		Program:        "cloud-hypervisor",
+	}, nil
+}
+
+func status() registry.State {
+	if _, err := exec.LookPath("cloud-hypervisor"); err != nil {
+		return registry.State{Error: err, Fix: "Install cloud-hypervisor from https://github.com/cloud-hypervisor/cloud-hypervisor/releases", Doc: docURL}
+	}
+	if _, err := exec.LookPath("dnsmasq"); err != nil {
+		return registry.State{Installed: true, Error: err, Fix: "Install dnsmasq with your package manager", Doc: docURL}
+	}
+	f, err := os.OpenFile("/dev/kvm", os.O_RDWR, 0)
+	if err != nil {
+		return registry.State{Installed: true, Error: err, Fix: "Add your user to the kvm group, and log in again", Doc: docURL}
+	}
+	f.Close()
+	return registry.State{Installed: true, Healthy: true, Running: true}
+}
@@ -36,6 +36,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/driver"
 	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/registry"
+	"k8s.io/minikube/pkg/network"
 )
 
 const (
@@ -73,6 +74,11 @@ func configure(cc config.ClusterConfig, n config.Node) (interface{}, error) {
 		extraArgs = append(extraArgs, "-p", port)
 	}
 
+	extraNetworks, err := network.ExtraDriverNetworks(cc.ExtraNetworks)
+	if err != nil {
+		return nil, err
+	}
+
 	return kic.NewDriver(kic.Config{
 		ClusterName:       cc.Name,
 		MachineName:       config.MachineName(cc, n),
@@ -85,11 +91,15 @@ func configure(cc config.ClusterConfig, n config.Node) (interface{}, error) {
 		APIServerPort:     cc.Nodes[0].Port,
 		KubernetesVersion: cc.KubernetesConfig.KubernetesVersion,
 		ContainerRuntime:  cc.KubernetesConfig.ContainerRuntime,
+		PreloadFrom:       cc.PreloadFrom,
 		ExtraArgs:         extraArgs,
 		Network:           cc.Network,
 		Subnet:            cc.Subnet,
 		StaticIP:          cc.StaticIP,
+		IPv6:              config.HasIPv6(cc),
+		ExtraNetworks:     extraNetworks,
 		ListenAddress:     cc.ListenAddress,
+		RegistryPort:      cc.RegistryPort,
 		GPUs:              cc.GPUs,
 	}), nil
 }
@@ -141,7 +151,11 @@ func status() (retState registry.State) {
 		return suggestFix("info", -1, serr, fmt.Errorf("docker info error: %s", serr))
 	}
 
-	// TODO: validate cgroup v2 delegation when si.Rootless is true
+	if si.Rootless {
+		if err := oci.CheckRootlessCgroups(oci.Docker, si); err != nil {
+			return registry.State{Reason: "PROVIDER_DOCKER_ROOTLESS_CGROUP", Error: err, Installed: true, Running: true, Healthy: false, Fix: "Enable cgroup v2 and delegate the cpu, cpuset, io, memory and pids controllers to your user", Doc: oci.RootlessCgroupDocURL}
+		}
+	}
 
 	return checkNeedsImprovement()
 }
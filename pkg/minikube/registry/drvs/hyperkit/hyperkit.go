@@ -53,7 +53,7 @@ func init() {
 		Config:   configure,
 		Status:   status,
 		Default:  true,
-		Priority: registry.Preferred,
+		Priority: registry.Default, // deprecated, vz is preferred on macOS 13 and later
 	}); err != nil {
 		panic(fmt.Sprintf("register: %v", err))
 	}
@@ -0,0 +1,88 @@
+//go:build windows
+
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hyperv
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/drivers/hyperv"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// hypervDriver adds the dynamic memory and nested virtualization settings to the libmachine Hyper-V driver
+type hypervDriver struct {
+	*hyperv.Driver
+	MinMemSize           int // in MB, only used with dynamic memory
+	MaxMemSize           int // in MB, only used with dynamic memory
+	MemoryBuffer         int // percentage, only used with dynamic memory
+	NestedVirtualization bool
+}
+
+func newDriver(hostName, storePath string) *hypervDriver {
+	return &hypervDriver{Driver: hyperv.NewDriver(hostName, storePath)}
+}
+
+// Create creates the VM, then applies the settings which the libmachine driver does not support.
+// The libmachine driver starts the VM it creates, and these settings can only be changed while it is off.
+func (d *hypervDriver) Create() error {
+	if err := d.Driver.Create(); err != nil {
+		return err
+	}
+	commands := d.settingsCommands()
+	if len(commands) == 0 {
+		return nil
+	}
+	if err := d.Driver.Stop(); err != nil {
+		return errors.Wrap(err, "stopping VM to change its settings")
+	}
+	for _, c := range commands {
+		if err := cmd(c...); err != nil {
+			return errors.Wrapf(err, "%s", c[0])
+		}
+	}
+	klog.Infof("applied Hyper-V settings to %s, starting it again", d.MachineName)
+	return d.Driver.Start()
+}
+
+// settingsCommands returns the PowerShell commands applying the dynamic memory and nested virtualization settings
+func (d *hypervDriver) settingsCommands() [][]string {
+	var commands [][]string
+	if !d.DisableDynamicMemory {
+		c := []string{"Hyper-V\\Set-VMMemory", "-VMName", d.MachineName, "-DynamicMemoryEnabled", "$true"}
+		if d.MinMemSize != 0 {
+			c = append(c, "-MinimumBytes", fmt.Sprintf("%dMB", d.MinMemSize))
+		}
+		if d.MaxMemSize != 0 {
+			c = append(c, "-MaximumBytes", fmt.Sprintf("%dMB", d.MaxMemSize))
+		}
+		if d.MemoryBuffer != 0 {
+			c = append(c, "-Buffer", fmt.Sprintf("%d", d.MemoryBuffer))
+		}
+		commands = append(commands, c)
+	}
+	if d.NestedVirtualization {
+		commands = append(commands,
+			[]string{"Hyper-V\\Set-VMProcessor", d.MachineName, "-ExposeVirtualizationExtensions", "$true"},
+			// the nested VMs send frames with their own MAC address
+			[]string{"Hyper-V\\Get-VMNetworkAdapter", "-VMName", d.MachineName, "|", "Hyper-V\\Set-VMNetworkAdapter", "-MacAddressSpoofing", "On"},
+		)
+	}
+	return commands
+}
@@ -25,7 +25,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/docker/machine/drivers/hyperv"
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/pkg/errors"
 
@@ -44,7 +43,7 @@ const (
 func init() {
 	if err := registry.Register(registry.DriverDef{
 		Name:     driver.HyperV,
-		Init:     func() drivers.Driver { return hyperv.NewDriver("", "") },
+		Init:     func() drivers.Driver { return newDriver("", "") },
 		Config:   configure,
 		Status:   status,
 		Default:  true,
@@ -55,7 +54,7 @@ func init() {
 }
 
 func configure(cfg config.ClusterConfig, n config.Node) (interface{}, error) {
-	d := hyperv.NewDriver(config.MachineName(cfg, n), localpath.MiniPath())
+	d := newDriver(config.MachineName(cfg, n), localpath.MiniPath())
 	d.Boot2DockerURL = download.LocalISOResource(cfg.MinikubeISO)
 	d.VSwitch = cfg.HypervVirtualSwitch
 	if d.VSwitch == "" && cfg.HypervUseExternalSwitch {
@@ -77,7 +76,12 @@ func configure(cfg config.ClusterConfig, n config.Node) (interface{}, error) {
 	d.CPU = cfg.CPUs
 	d.DiskSize = cfg.DiskSize
 	d.SSHUser = "docker"
-	d.DisableDynamicMemory = true // default to disable dynamic memory as minikube is unlikely to work properly with dynamic memory
+	// dynamic memory is disabled by default as minikube is unlikely to work properly with it
+	d.DisableDynamicMemory = !cfg.HypervDynamicMemory
+	d.MinMemSize = cfg.HypervMinMemory
+	d.MaxMemSize = cfg.HypervMaxMemory
+	d.MemoryBuffer = cfg.HypervMemoryBuffer
+	d.NestedVirtualization = cfg.HypervNestedVirt
 	return d, nil
 }
 
@@ -18,15 +18,21 @@ package drvs
 
 import (
 	// Register all of the drvs we know of
+	_ "k8s.io/minikube/pkg/minikube/registry/drvs/cloudhypervisor"
 	_ "k8s.io/minikube/pkg/minikube/registry/drvs/docker"
+	_ "k8s.io/minikube/pkg/minikube/registry/drvs/firecracker"
 	_ "k8s.io/minikube/pkg/minikube/registry/drvs/hyperkit"
 	_ "k8s.io/minikube/pkg/minikube/registry/drvs/hyperv"
 	_ "k8s.io/minikube/pkg/minikube/registry/drvs/kvm2"
+	_ "k8s.io/minikube/pkg/minikube/registry/drvs/lxd"
 	_ "k8s.io/minikube/pkg/minikube/registry/drvs/none"
 	_ "k8s.io/minikube/pkg/minikube/registry/drvs/parallels"
+	_ "k8s.io/minikube/pkg/minikube/registry/drvs/plugin"
 	_ "k8s.io/minikube/pkg/minikube/registry/drvs/podman"
 	_ "k8s.io/minikube/pkg/minikube/registry/drvs/qemu2"
 	_ "k8s.io/minikube/pkg/minikube/registry/drvs/ssh"
 	_ "k8s.io/minikube/pkg/minikube/registry/drvs/virtualbox"
 	_ "k8s.io/minikube/pkg/minikube/registry/drvs/vmware"
+	_ "k8s.io/minikube/pkg/minikube/registry/drvs/vz"
+	_ "k8s.io/minikube/pkg/minikube/registry/drvs/wsl"
 )
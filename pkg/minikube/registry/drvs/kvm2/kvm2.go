@@ -35,6 +35,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/driver"
 	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/registry"
+	"k8s.io/minikube/pkg/network"
 )
 
 const (
@@ -67,14 +68,28 @@ type kvmDriver struct {
 	Boot2DockerURL string
 	DiskPath       string
 	GPU            bool
+	GPUDevices     []string
 	Hidden         bool
 	ConnectionURI  string
 	NUMANodeCount  int
 	ExtraDisks     int
+	StaticIP       string
+	IPv6           bool
+	ExtraNetworks  []string
+	VirtiofsDir    string
 }
 
 func configure(cc config.ClusterConfig, n config.Node) (interface{}, error) {
 	name := config.MachineName(cc, n)
+	var staticIP string
+	if config.IsPrimaryControlPlane(cc, n) {
+		staticIP = cc.StaticIP
+	}
+	extraNetworks, err := network.ExtraDriverNetworks(cc.ExtraNetworks)
+	if err != nil {
+		return nil, err
+	}
+	virtiofsDir, _ := config.VirtiofsMount(cc)
 	return kvmDriver{
 		BaseDriver: &drivers.BaseDriver{
 			MachineName: name,
@@ -89,14 +104,27 @@ func configure(cc config.ClusterConfig, n config.Node) (interface{}, error) {
 		DiskSize:       cc.DiskSize,
 		DiskPath:       filepath.Join(localpath.MiniPath(), "machines", name, fmt.Sprintf("%s.rawdisk", name)),
 		ISO:            filepath.Join(localpath.MiniPath(), "machines", name, "boot2docker.iso"),
-		GPU:            cc.KVMGPU,
+		GPU:            cc.KVMGPU || cc.GPUs != "",
+		GPUDevices:     gpuDevices(cc.GPUs),
 		Hidden:         cc.KVMHidden,
 		ConnectionURI:  cc.KVMQemuURI,
 		NUMANodeCount:  cc.KVMNUMACount,
 		ExtraDisks:     cc.ExtraDisks,
+		StaticIP:       staticIP,
+		IPv6:           config.HasIPv6(cc),
+		ExtraNetworks:  extraNetworks,
+		VirtiofsDir:    virtiofsDir,
 	}, nil
 }
 
+// gpuDevices returns the PCI addresses of the devices selected by --gpus, nil to passthrough all the NVIDIA GPUs
+func gpuDevices(gpus string) []string {
+	if gpus == "" || gpus == "all" || gpus == "nvidia" {
+		return nil
+	}
+	return strings.Split(gpus, ",")
+}
+
 // if network is not user-defined it defaults to "mk-<cluster_name>"
 func privateNetwork(cc config.ClusterConfig) string {
 	if cc.Network == "" {
@@ -0,0 +1,72 @@
+//go:build linux
+
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lxd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/docker/machine/libmachine/drivers"
+
+	"k8s.io/minikube/pkg/drivers/lxd"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/registry"
+)
+
+const docURL = "https://minikube.sigs.k8s.io/docs/reference/drivers/lxd/"
+
+func init() {
+	if err := registry.Register(registry.DriverDef{
+		Name:     driver.LXD,
+		Init:     func() drivers.Driver { return lxd.NewDriver("", "") },
+		Config:   configure,
+		Status:   status,
+		Default:  false,
+		Priority: registry.Experimental,
+	}); err != nil {
+		panic(fmt.Sprintf("register failed: %v", err))
+	}
+}
+
+func configure(cc config.ClusterConfig, n config.Node) (interface{}, error) {
+	return &lxd.Driver{
+		BaseDriver: &drivers.BaseDriver{
+			MachineName: config.MachineName(cc, n),
+			StorePath:   localpath.MiniPath(),
+			SSHUser:     "docker",
+		},
+		Image:  cc.KicBaseImage,
+		Memory: cc.Memory,
+		CPU:    cc.CPUs,
+		Socket: lxd.SocketPath(),
+	}, nil
+}
+
+func status() registry.State {
+	socket := lxd.SocketPath()
+	if _, err := os.Stat(socket); err != nil {
+		return registry.State{Error: err, Fix: "Install LXD with 'sudo snap install lxd', and initialize it with 'sudo lxd init --auto'", Doc: docURL}
+	}
+	if err := lxd.Ping(socket); err != nil {
+		return registry.State{Installed: true, Error: err, Fix: "Add your user to the lxd group, and log in again", Doc: docURL}
+	}
+	return registry.State{Installed: true, Healthy: true, Running: true}
+}
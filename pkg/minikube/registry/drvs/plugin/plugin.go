@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine/drivers"
+
+	"k8s.io/minikube/pkg/drivers/plugin"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/download"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/registry"
+)
+
+const docURL = "https://minikube.sigs.k8s.io/docs/drivers/plugin/"
+
+func init() {
+	registry.RegisterPluginLoader(load)
+}
+
+// load defines the driver of a plugin, when it is selected
+func load(name string) registry.DriverDef {
+	return registry.DriverDef{
+		Name:     registry.PluginPrefix + name,
+		Init:     func() drivers.Driver { return plugin.NewDriver(name, "", "") },
+		Config:   func(cc config.ClusterConfig, n config.Node) (interface{}, error) { return configure(name, cc, n) },
+		Status:   func() registry.State { return status(name) },
+		Default:  false, // out-of-tree drivers are never selected automatically
+		Priority: registry.Experimental,
+	}
+}
+
+func configure(name string, cc config.ClusterConfig, n config.Node) (interface{}, error) {
+	opts, err := plugin.ParseOptions(cc.PluginOptions)
+	if err != nil {
+		return nil, err
+	}
+	d := plugin.NewDriver(name, config.MachineName(cc, n), localpath.MiniPath())
+	d.Boot2DockerURL = download.LocalISOResource(cc.MinikubeISO)
+	d.CPU = cc.CPUs
+	d.Memory = cc.Memory
+	d.DiskSize = cc.DiskSize
+	d.ExtraDisks = cc.ExtraDisks
+	d.Network = cc.Network
+	d.Options = opts
+	return d, nil
+}
+
+func status(name string) registry.State {
+	fix := fmt.Sprintf("Install the %s driver plugin in your PATH", plugin.BinaryName(name))
+	if _, err := plugin.Lookup(name); err != nil {
+		return registry.State{Error: err, Fix: fix, Doc: docURL}
+	}
+	info, err := plugin.Info(name)
+	if err != nil {
+		return registry.State{Installed: true, Error: err, Fix: "Install a version of the driver plugin compatible with this minikube", Doc: docURL}
+	}
+	return registry.State{Installed: true, Healthy: true, Running: true, Version: info.Version}
+}
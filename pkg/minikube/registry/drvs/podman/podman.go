@@ -28,6 +28,7 @@ import (
 
 	"github.com/blang/semver/v4"
 	"github.com/docker/machine/libmachine/drivers"
+	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 	"k8s.io/minikube/pkg/drivers/kic"
 	"k8s.io/minikube/pkg/drivers/kic/oci"
@@ -36,6 +37,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/out"
 	"k8s.io/minikube/pkg/minikube/registry"
+	"k8s.io/minikube/pkg/network"
 )
 
 var docURL = "https://minikube.sigs.k8s.io/docs/drivers/podman/"
@@ -82,6 +84,11 @@ func configure(cc config.ClusterConfig, n config.Node) (interface{}, error) {
 		extraArgs = append(extraArgs, "-p", port)
 	}
 
+	extraNetworks, err := network.ExtraDriverNetworks(cc.ExtraNetworks)
+	if err != nil {
+		return nil, err
+	}
+
 	return kic.NewDriver(kic.Config{
 		ClusterName:       cc.Name,
 		MachineName:       config.MachineName(cc, n),
@@ -94,9 +101,13 @@ func configure(cc config.ClusterConfig, n config.Node) (interface{}, error) {
 		APIServerPort:     cc.Nodes[0].Port,
 		KubernetesVersion: cc.KubernetesConfig.KubernetesVersion,
 		ContainerRuntime:  cc.KubernetesConfig.ContainerRuntime,
+		PreloadFrom:       cc.PreloadFrom,
 		ExtraArgs:         extraArgs,
 		ListenAddress:     cc.ListenAddress,
+		RegistryPort:      cc.RegistryPort,
 		Subnet:            cc.Subnet,
+		IPv6:              config.HasIPv6(cc),
+		ExtraNetworks:     extraNetworks,
 	}), nil
 }
 
@@ -132,6 +143,9 @@ func status() registry.State {
 				out.V{"minVersion": minReqPodmanVer.String(), "currentVersion": v.String()})
 		}
 
+		if runtime.GOOS == "linux" && oci.IsRootlessForced() {
+			return rootlessStatus()
+		}
 		return registry.State{Installed: true, Healthy: true}
 	}
 
@@ -169,3 +183,23 @@ func status() registry.State {
 
 	return registry.State{Error: err, Installed: true, Healthy: false, Doc: docURL}
 }
+
+// rootlessStatus checks that the host is configured to run Kubernetes in rootless podman
+func rootlessStatus() registry.State {
+	si, err := oci.CachedDaemonInfo(oci.Podman)
+	if err != nil {
+		return registry.State{Reason: "PROVIDER_PODMAN_INFO_FAILED", Error: errors.Wrap(err, "podman info"), Installed: true, Running: true, Healthy: false, Doc: docURL}
+	}
+	if !si.Rootless {
+		// reported by start, as for the docker driver
+		return registry.State{Installed: true, Healthy: true}
+	}
+	if err := oci.CheckRootlessCgroups(oci.Podman, si); err != nil {
+		return registry.State{Reason: "PROVIDER_PODMAN_ROOTLESS_CGROUP", Error: err, Installed: true, Running: true, Healthy: false, Fix: "Enable cgroup v2 and delegate the cpu, cpuset, io, memory and pids controllers to your user", Doc: oci.RootlessCgroupDocURL}
+	}
+	if err := oci.CheckRootlessPortForwarder(oci.Podman, si); err != nil {
+		return registry.State{Reason: "PROVIDER_PODMAN_ROOTLESS_NETWORK", Error: err, Installed: true, Running: true, Healthy: false, Fix: "Install the 'passt' (pasta) or 'slirp4netns' package", Doc: docURL}
+	}
+	klog.Infof("rootless podman publishes ports with %s", si.PortForwarder)
+	return registry.State{Installed: true, Healthy: true}
+}
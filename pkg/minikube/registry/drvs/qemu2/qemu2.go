@@ -17,7 +17,6 @@ limitations under the License.
 package qemu2
 
 import (
-	"crypto/rand"
 	"fmt"
 	"os"
 	"os/exec"
@@ -28,6 +27,7 @@ import (
 	"github.com/blang/semver"
 	"github.com/docker/machine/libmachine/drivers"
 	"github.com/spf13/viper"
+	pkgdrivers "k8s.io/minikube/pkg/drivers"
 	"k8s.io/minikube/pkg/drivers/qemu"
 
 	"k8s.io/minikube/pkg/minikube/config"
@@ -35,6 +35,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/driver"
 	"k8s.io/minikube/pkg/minikube/localpath"
 	"k8s.io/minikube/pkg/minikube/registry"
+	"k8s.io/minikube/pkg/network"
 )
 
 const docURL = "https://minikube.sigs.k8s.io/docs/reference/drivers/qemu/"
@@ -154,10 +155,19 @@ func configure(cc config.ClusterConfig, n config.Node) (interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
-	mac, err := generateMACAddress()
+	var staticIP string
+	netName := cc.Network
+	bridge, bridged := network.ParseBridged(cc.Network)
+	if bridged {
+		netName = network.Bridged
+	} else if config.IsPrimaryControlPlane(cc, n) && !network.IsBuiltinQEMU(cc.Network) {
+		staticIP = cc.StaticIP
+	}
+	mac, err := pkgdrivers.GenerateMACAddress()
 	if err != nil {
 		return nil, fmt.Errorf("generating MAC address: %v", err)
 	}
+	virtiofsDir, _ := config.VirtiofsMount(cc)
 
 	return qemu.Driver{
 		BaseDriver: &drivers.BaseDriver{
@@ -178,13 +188,16 @@ func configure(cc config.ClusterConfig, n config.Node) (interface{}, error) {
 		CPUType:               qemuCPU,
 		Firmware:              qemuFirmware,
 		VirtioDrives:          false,
-		Network:               cc.Network,
+		Network:               netName,
+		BridgeInterface:       bridge,
 		CacheMode:             "default",
 		IOMode:                "threads",
 		MACAddress:            mac,
 		SocketVMNetPath:       cc.SocketVMnetPath,
 		SocketVMNetClientPath: cc.SocketVMnetClientPath,
 		ExtraDisks:            cc.ExtraDisks,
+		StaticIP:              staticIP,
+		VirtiofsDir:           virtiofsDir,
 	}, nil
 }
 
@@ -209,14 +222,3 @@ func status() registry.State {
 
 	return registry.State{Installed: true, Healthy: true, Running: true}
 }
-
-func generateMACAddress() (string, error) {
-	buf := make([]byte, 6)
-	if _, err := rand.Read(buf); err != nil {
-		return "", err
-	}
-	// Set local bit, ensure unicast address, socket_vmnet doesn't support multicast
-	buf[0] = (buf[0] | 2) & 0xfe
-	mac := fmt.Sprintf("%02x:%02x:%02x:%02x:%02x:%02x", buf[0], buf[1], buf[2], buf[3], buf[4], buf[5])
-	return mac, nil
-}
@@ -0,0 +1,97 @@
+//go:build darwin
+
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vz
+
+import (
+	"fmt"
+
+	"github.com/docker/machine/libmachine/drivers"
+
+	pkgdrivers "k8s.io/minikube/pkg/drivers"
+	"k8s.io/minikube/pkg/drivers/vz"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/detect"
+	"k8s.io/minikube/pkg/minikube/download"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/registry"
+	"k8s.io/minikube/pkg/network"
+)
+
+const docURL = "https://minikube.sigs.k8s.io/docs/reference/drivers/vz/"
+
+func init() {
+	if err := registry.Register(registry.DriverDef{
+		Name:     driver.VZ,
+		Init:     func() drivers.Driver { return vz.NewDriver("", "") },
+		Config:   configure,
+		Status:   status,
+		Alias:    []string{driver.AliasVFKit},
+		Default:  true,
+		Priority: registry.Preferred,
+	}); err != nil {
+		panic(fmt.Sprintf("register failed: %v", err))
+	}
+}
+
+func configure(cc config.ClusterConfig, n config.Node) (interface{}, error) {
+	mac, err := pkgdrivers.GenerateMACAddress()
+	if err != nil {
+		return nil, fmt.Errorf("generating MAC address: %v", err)
+	}
+	virtiofsDir, _ := config.VirtiofsMount(cc)
+	netName, bridge := cc.Network, cc.VZBridgeInterface
+	if iface, bridged := network.ParseBridged(cc.Network); bridged {
+		// --network=bridged:<ifname> names the interface, --vz-bridge-interface is the one of --network=bridged
+		netName = vz.NetworkBridged
+		if iface != "" {
+			bridge = iface
+		}
+	}
+
+	return &vz.Driver{
+		BaseDriver: &drivers.BaseDriver{
+			MachineName: config.MachineName(cc, n),
+			StorePath:   localpath.MiniPath(),
+			SSHUser:     "docker",
+		},
+		Boot2DockerURL:        download.LocalISOResource(cc.MinikubeISO),
+		DiskSize:              cc.DiskSize,
+		Memory:                cc.Memory,
+		CPU:                   cc.CPUs,
+		MACAddress:            mac,
+		ExtraDisks:            cc.ExtraDisks,
+		Program:               "vfkit",
+		Rosetta:               cc.VZRosetta,
+		SharedFolders:         cc.VZSharedFolders,
+		VirtiofsDir:           virtiofsDir,
+		Network:               netName,
+		BridgeInterface:       bridge,
+		SocketVMNetPath:       cc.SocketVMnetPath,
+		SocketVMNetClientPath: cc.SocketVMnetClientPath,
+	}, nil
+}
+
+func status() registry.State {
+	if !detect.MacOS13Plus() {
+		return registry.State{Error: fmt.Errorf("the vz driver requires macOS 13 or later"), Fix: "Upgrade macOS, or use the qemu2 driver", Doc: docURL}
+	}
+	// vfkit is downloaded by minikube if it is missing
+	return registry.State{Installed: true, Healthy: true, Running: true}
+}
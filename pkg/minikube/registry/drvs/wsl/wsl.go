@@ -0,0 +1,70 @@
+//go:build windows
+
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wsl
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/docker/machine/libmachine/drivers"
+
+	"k8s.io/minikube/pkg/drivers/wsl"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/localpath"
+	"k8s.io/minikube/pkg/minikube/registry"
+)
+
+const docURL = "https://minikube.sigs.k8s.io/docs/reference/drivers/wsl/"
+
+func init() {
+	if err := registry.Register(registry.DriverDef{
+		Name:     driver.WSL,
+		Init:     func() drivers.Driver { return wsl.NewDriver("", "") },
+		Config:   configure,
+		Status:   status,
+		Default:  false,
+		Priority: registry.Experimental,
+	}); err != nil {
+		panic(fmt.Sprintf("register failed: %v", err))
+	}
+}
+
+func configure(cc config.ClusterConfig, n config.Node) (interface{}, error) {
+	return &wsl.Driver{
+		BaseDriver: &drivers.BaseDriver{
+			MachineName: config.MachineName(cc, n),
+			StorePath:   localpath.MiniPath(),
+			SSHUser:     "docker",
+		},
+		Image:         cc.KicBaseImage,
+		APIServerPort: n.Port,
+		ListenAddress: cc.ListenAddress,
+	}, nil
+}
+
+func status() registry.State {
+	if _, err := exec.LookPath("wsl.exe"); err != nil {
+		return registry.State{Error: err, Fix: "Install WSL with 'wsl --install --no-distribution'", Doc: docURL}
+	}
+	if err := wsl.Status(); err != nil {
+		return registry.State{Installed: true, Error: err, Fix: "Update WSL with 'wsl --update'", Doc: docURL}
+	}
+	return registry.State{Installed: true, Healthy: true, Running: true}
+}
@@ -35,6 +35,10 @@ const (
 	Mock = "mock"
 	// None driver
 	None = "none"
+	// LXD driver
+	LXD = "lxd"
+	// WSL driver
+	WSL = "wsl"
 )
 
 // IsKIC checks if the driver is a Kubernetes in container
@@ -49,7 +53,7 @@ func IsMock(name string) bool {
 
 // IsVM checks if the driver is a VM
 func IsVM(name string) bool {
-	if IsKIC(name) || IsMock(name) || BareMetal(name) {
+	if IsKIC(name) || name == LXD || name == WSL || IsMock(name) || BareMetal(name) {
 		return false
 	}
 	return true
@@ -81,6 +85,8 @@ type DriverState struct {
 	Rejection string
 	// Suggestion is how the user could improve health
 	Suggestion string
+	// Probe is how the probes of the host changed the health or the priority of the driver
+	Probe string
 }
 
 func (d DriverState) String() string {
@@ -101,6 +107,11 @@ func Register(driver DriverDef) error {
 	return globalRegistry.Register(driver)
 }
 
+// RegisterPluginLoader registers how to define out-of-tree drivers with the global registry
+func RegisterPluginLoader(l PluginLoader) {
+	globalRegistry.RegisterPluginLoader(l)
+}
+
 // Driver gets a named driver from the global registry
 func Driver(name string) DriverDef {
 	return globalRegistry.Driver(name)
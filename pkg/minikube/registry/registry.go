@@ -18,6 +18,7 @@ package registry
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/docker/machine/libmachine/drivers"
@@ -69,6 +70,12 @@ type Configurator func(config.ClusterConfig, config.Node) (interface{}, error)
 // Loader is a function that loads a byte stream and creates a driver.
 type Loader func() drivers.Driver
 
+// PluginPrefix is the prefix of the names of out-of-tree drivers, as in plugin:<name>
+const PluginPrefix = "plugin:"
+
+// PluginLoader returns the definition of an out-of-tree driver from its name, without PluginPrefix
+type PluginLoader func(name string) DriverDef
+
 // StatusChecker checks if a driver is available, offering a
 type StatusChecker func() State
 
@@ -122,6 +129,7 @@ func (d DriverDef) String() string {
 type driverRegistry struct {
 	drivers        map[string]DriverDef
 	driversByAlias map[string]DriverDef
+	plugins        PluginLoader
 	lock           sync.RWMutex
 }
 
@@ -152,6 +160,13 @@ func (r *driverRegistry) Register(def DriverDef) error {
 	return nil
 }
 
+// RegisterPluginLoader registers how to define the out-of-tree drivers, which are not known in advance
+func (r *driverRegistry) RegisterPluginLoader(l PluginLoader) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.plugins = l
+}
+
 // List returns a list of registered drivers
 func (r *driverRegistry) List() []DriverDef {
 	r.lock.RLock()
@@ -176,6 +191,10 @@ func (r *driverRegistry) Driver(name string) DriverDef {
 		return def
 	}
 
+	if strings.HasPrefix(name, PluginPrefix) && r.plugins != nil {
+		return r.plugins(strings.TrimPrefix(name, PluginPrefix))
+	}
+
 	// Check if we have driver def with name as alias
 	return r.driversByAlias[name]
 }
@@ -91,3 +91,24 @@ func TestDriverAlias(t *testing.T) {
 		t.Errorf("driver.Empty = false, expected true")
 	}
 }
+
+func TestDriverPlugin(t *testing.T) {
+	r := newRegistry()
+
+	if d := r.Driver("plugin:foo"); !d.Empty() {
+		t.Errorf("driver.Empty = false without a plugin loader, expected true")
+	}
+
+	r.RegisterPluginLoader(func(name string) DriverDef {
+		return DriverDef{Name: PluginPrefix + name}
+	})
+	if d := r.Driver("plugin:foo"); d.Name != "plugin:foo" {
+		t.Errorf("driver.Name = %q, expected plugin:foo", d.Name)
+	}
+	if d := r.Driver("foo"); !d.Empty() {
+		t.Errorf("driver.Empty = false, expected true")
+	}
+	if len(r.List()) != 0 {
+		t.Errorf("List() = %v, expected plugins not to be listed", r.List())
+	}
+}
@@ -0,0 +1,335 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registryauth materializes the credentials of the private registries of minikube registry login:
+// into the docker config of the kubelet of the nodes, and into an imagePullSecret of the default service account of namespaces
+package registryauth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+const (
+	// SecretName is the imagePullSecret holding the credentials of the registries selected for a namespace
+	SecretName = "minikube-registry-credentials"
+
+	// GCloud gets a token of Artifact Registry and Container Registry with the gcloud CLI
+	GCloud = "gcloud"
+	// AWS gets a token of Elastic Container Registry with the aws CLI
+	AWS = "aws"
+	// Azure gets a token of Azure Container Registry with the az CLI
+	Azure = "az"
+
+	// kubeletConfig is the docker config which the kubelet reads the credentials of the pulls of all the pods from
+	kubeletConfig = "/var/lib/kubelet/config.json"
+	// dockerHub is the key of Docker Hub in the docker configs
+	dockerHub = "https://index.docker.io/v1/"
+)
+
+// Helpers are the cloud CLIs which credentials can be got from
+var Helpers = []string{GCloud, AWS, Azure}
+
+// execCommand runs the CLIs of the host, replaced by the tests
+var execCommand = exec.Command
+
+// dockerConfig is the part of the docker config holding credentials
+type dockerConfig struct {
+	Auths       map[string]dockerAuth `json:"auths"`
+	CredsStore  string                `json:"credsStore,omitempty"`
+	CredHelpers map[string]string     `json:"credHelpers,omitempty"`
+}
+
+type dockerAuth struct {
+	Auth string `json:"auth,omitempty"`
+}
+
+// configKey returns the key of the registry in the docker configs
+func configKey(registry string) string {
+	if registry == "docker.io" {
+		return dockerHub
+	}
+	return registry
+}
+
+// matchesKey returns whether the key of a docker config is the one of the registry, with or without a scheme
+func matchesKey(key, registry string) bool {
+	if key == configKey(registry) {
+		return true
+	}
+	host := strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+	host, _, _ = strings.Cut(host, "/")
+	return host == registry || (registry == "docker.io" && host == "index.docker.io")
+}
+
+// FromDockerConfig returns the credentials of the registry stored by docker login in the docker config at path,
+// asking the credential helper of docker for those it does not store itself
+func FromDockerConfig(path, registry string) (string, string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	var c dockerConfig
+	if err := json.Unmarshal(b, &c); err != nil {
+		return "", "", errors.Wrapf(err, "parsing %s", path)
+	}
+	for key, h := range c.CredHelpers {
+		if matchesKey(key, registry) {
+			return fromCredentialHelper(h, key)
+		}
+	}
+	for key, a := range c.Auths {
+		if !matchesKey(key, registry) {
+			continue
+		}
+		if a.Auth == "" && c.CredsStore != "" {
+			return fromCredentialHelper(c.CredsStore, key)
+		}
+		dec, err := base64.StdEncoding.DecodeString(a.Auth)
+		if err != nil {
+			return "", "", errors.Wrapf(err, "decoding the credentials of %s", key)
+		}
+		user, pass, ok := strings.Cut(string(dec), ":")
+		if !ok {
+			return "", "", fmt.Errorf("the credentials of %s in %s are malformed", key, path)
+		}
+		return user, pass, nil
+	}
+	return "", "", fmt.Errorf("%s has no credentials of %s, run 'docker login %s' first", path, registry, registry)
+}
+
+// fromCredentialHelper returns the credentials of the server key from the docker credential helper h
+func fromCredentialHelper(h, key string) (string, string, error) {
+	cmd := execCommand("docker-credential-"+h, "get")
+	cmd.Stdin = strings.NewReader(key)
+	o, err := cmd.Output()
+	if err != nil {
+		return "", "", errors.Wrapf(err, "docker-credential-%s get", h)
+	}
+	var c struct {
+		Username string
+		Secret   string
+	}
+	if err := json.Unmarshal(o, &c); err != nil {
+		return "", "", errors.Wrapf(err, "parsing the output of docker-credential-%s", h)
+	}
+	return c.Username, c.Secret, nil
+}
+
+// FromHelper returns a token of the registry got from the cloud CLI helper, which expires: within an hour for gcloud, 12 hours for aws and 3 hours for az
+func FromHelper(helper, registry string) (string, string, error) {
+	host, _, _ := strings.Cut(registry, ":")
+	var user string
+	var cmd *exec.Cmd
+	switch helper {
+	case GCloud:
+		user, cmd = "oauth2accesstoken", execCommand("gcloud", "auth", "print-access-token")
+	case AWS:
+		// ACCOUNT.dkr.ecr.REGION.amazonaws.com
+		parts := strings.Split(host, ".")
+		if len(parts) < 6 || parts[1] != "dkr" || parts[2] != "ecr" {
+			return "", "", fmt.Errorf("%s is not an Elastic Container Registry, such as 123456789012.dkr.ecr.us-east-1.amazonaws.com", registry)
+		}
+		user, cmd = "AWS", execCommand("aws", "ecr", "get-login-password", "--region", parts[3])
+	case Azure:
+		name, _, _ := strings.Cut(host, ".")
+		user, cmd = "00000000-0000-0000-0000-000000000000", execCommand("az", "acr", "login", "--name", name, "--expose-token", "--output", "tsv", "--query", "accessToken")
+	default:
+		return "", "", fmt.Errorf("invalid helper %q, valid helpers are: %s", helper, strings.Join(Helpers, ", "))
+	}
+	o, err := cmd.Output()
+	if err != nil {
+		return "", "", errors.Wrapf(err, "%s", strings.Join(cmd.Args, " "))
+	}
+	token := strings.TrimSpace(string(o))
+	if token == "" {
+		return "", "", fmt.Errorf("%s returned no token", strings.Join(cmd.Args, " "))
+	}
+	return user, token, nil
+}
+
+// Add returns the credentials with c, replacing those of the same registry
+func Add(creds []config.RegistryCredential, c config.RegistryCredential) []config.RegistryCredential {
+	for i, o := range creds {
+		if o.Registry == c.Registry {
+			creds[i] = c
+			return creds
+		}
+	}
+	return append(creds, c)
+}
+
+// Remove returns the credentials without those of the registry, and whether there were some
+func Remove(creds []config.RegistryCredential, registry string) ([]config.RegistryCredential, bool) {
+	var kept []config.RegistryCredential
+	for _, c := range creds {
+		if c.Registry != registry {
+			kept = append(kept, c)
+		}
+	}
+	return kept, len(kept) != len(creds)
+}
+
+// DockerConfig returns the docker config holding the credentials
+func DockerConfig(creds []config.RegistryCredential) ([]byte, error) {
+	c := dockerConfig{Auths: map[string]dockerAuth{}}
+	for _, cred := range creds {
+		c.Auths[configKey(cred.Registry)] = dockerAuth{Auth: base64.StdEncoding.EncodeToString([]byte(cred.Username + ":" + cred.Password))}
+	}
+	return json.MarshalIndent(c, "", "  ")
+}
+
+// ApplyNode writes the credentials into the docker config of the kubelet of the node, which passes them to the container runtime
+// for the pulls of all the pods. The kubelet reads it again within minutes, without a restart.
+func ApplyNode(r command.Runner, creds []config.RegistryCredential) error {
+	if len(creds) == 0 {
+		_, err := r.RunCmd(exec.Command("sudo", "rm", "-f", kubeletConfig))
+		return err
+	}
+	b, err := DockerConfig(creds)
+	if err != nil {
+		return err
+	}
+	return r.Copy(assets.NewMemoryAssetTarget(b, kubeletConfig, "0600"))
+}
+
+// Namespaces returns the namespaces whose pods are given the credentials as an imagePullSecret
+func Namespaces(creds []config.RegistryCredential) []string {
+	seen := map[string]bool{}
+	var ns []string
+	for _, c := range creds {
+		for _, n := range c.Namespaces {
+			if !seen[n] {
+				seen[n] = true
+				ns = append(ns, n)
+			}
+		}
+	}
+	sort.Strings(ns)
+	return ns
+}
+
+// SyncSecrets creates the imagePullSecret of the credentials selected for each namespace, and adds it to its default service account.
+// It is removed from the namespaces of stale, the namespaces selected before, which no credentials select anymore.
+// The namespaces which do not exist yet are skipped and returned.
+func SyncSecrets(ctx context.Context, c kubernetes.Interface, creds []config.RegistryCredential, stale []string) ([]string, error) {
+	selected := map[string][]config.RegistryCredential{}
+	for _, cred := range creds {
+		for _, n := range cred.Namespaces {
+			selected[n] = append(selected[n], cred)
+		}
+	}
+	var missing []string
+	for _, n := range Namespaces(creds) {
+		if _, err := c.CoreV1().Namespaces().Get(ctx, n, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+			missing = append(missing, n)
+			continue
+		}
+		if err := applySecret(ctx, c, n, selected[n]); err != nil {
+			return missing, errors.Wrapf(err, "namespace %s", n)
+		}
+	}
+	for _, n := range stale {
+		if _, ok := selected[n]; ok {
+			continue
+		}
+		if err := deleteSecret(ctx, c, n); err != nil {
+			return missing, errors.Wrapf(err, "namespace %s", n)
+		}
+	}
+	return missing, nil
+}
+
+func applySecret(ctx context.Context, c kubernetes.Interface, ns string, creds []config.RegistryCredential) error {
+	b, err := DockerConfig(creds)
+	if err != nil {
+		return err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: SecretName, Namespace: ns, Labels: map[string]string{"app.kubernetes.io/managed-by": "minikube"}},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: b},
+	}
+	secrets := c.CoreV1().Secrets(ns)
+	if _, err := secrets.Get(ctx, SecretName, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+		_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+		if err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else if _, err := secrets.Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return err
+	}
+
+	sa, err := c.CoreV1().ServiceAccounts(ns).Get(ctx, "default", metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// the service account of a namespace just created is not there yet, its pods pull with the credentials of the nodes meanwhile
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, s := range sa.ImagePullSecrets {
+		if s.Name == SecretName {
+			return nil
+		}
+	}
+	sa.ImagePullSecrets = append(sa.ImagePullSecrets, corev1.LocalObjectReference{Name: SecretName})
+	_, err = c.CoreV1().ServiceAccounts(ns).Update(ctx, sa, metav1.UpdateOptions{})
+	return err
+}
+
+func deleteSecret(ctx context.Context, c kubernetes.Interface, ns string) error {
+	if err := c.CoreV1().Secrets(ns).Delete(ctx, SecretName, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	sa, err := c.CoreV1().ServiceAccounts(ns).Get(ctx, "default", metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var kept []corev1.LocalObjectReference
+	for _, s := range sa.ImagePullSecrets {
+		if s.Name != SecretName {
+			kept = append(kept, s)
+		}
+	}
+	if len(kept) == len(sa.ImagePullSecrets) {
+		return nil
+	}
+	sa.ImagePullSecrets = kept
+	_, err = c.CoreV1().ServiceAccounts(ns).Update(ctx, sa, metav1.UpdateOptions{})
+	return err
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registryauth
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+func TestFromDockerConfig(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "config.json")
+	// bWU6c2VjcmV0 is me:secret
+	cfg := `{"auths": {"https://index.docker.io/v1/": {"auth": "bWU6c2VjcmV0"}, "ghcr.io": {"auth": "bWU6c2VjcmV0"}, "quay.io": {}}}`
+	if err := os.WriteFile(p, []byte(cfg), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, registry := range []string{"docker.io", "ghcr.io"} {
+		user, pass, err := FromDockerConfig(p, registry)
+		if err != nil || user != "me" || pass != "secret" {
+			t.Errorf("FromDockerConfig(%s) = %q, %q, %v, want me, secret", registry, user, pass, err)
+		}
+	}
+	for _, registry := range []string{"quay.io", "gcr.io"} {
+		if _, _, err := FromDockerConfig(p, registry); err == nil {
+			t.Errorf("FromDockerConfig(%s) succeeded, want an error", registry)
+		}
+	}
+}
+
+func TestFromHelper(t *testing.T) {
+	defer func() { execCommand = exec.Command }()
+	execCommand = func(name string, args ...string) *exec.Cmd {
+		return exec.Command("echo", "token")
+	}
+	tests := []struct {
+		helper   string
+		registry string
+		user     string
+		valid    bool
+	}{
+		{GCloud, "us-docker.pkg.dev", "oauth2accesstoken", true},
+		{AWS, "123456789012.dkr.ecr.us-east-1.amazonaws.com", "AWS", true},
+		{AWS, "registry.example.com", "", false},
+		{Azure, "example.azurecr.io", "00000000-0000-0000-0000-000000000000", true},
+		{"vault", "registry.example.com", "", false},
+	}
+	for _, tc := range tests {
+		user, token, err := FromHelper(tc.helper, tc.registry)
+		if (err == nil) != tc.valid {
+			t.Errorf("FromHelper(%s, %s) error = %v, want valid = %t", tc.helper, tc.registry, err, tc.valid)
+			continue
+		}
+		if tc.valid && (user != tc.user || token != "token") {
+			t.Errorf("FromHelper(%s, %s) = %q, %q, want %q, token", tc.helper, tc.registry, user, token, tc.user)
+		}
+	}
+}
+
+func TestDockerConfig(t *testing.T) {
+	b, err := DockerConfig([]config.RegistryCredential{
+		{Registry: "docker.io", Username: "me", Password: "secret"},
+		{Registry: "ghcr.io", Username: "me", Password: "secret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "auths": {
+    "ghcr.io": {
+      "auth": "bWU6c2VjcmV0"
+    },
+    "https://index.docker.io/v1/": {
+      "auth": "bWU6c2VjcmV0"
+    }
+  }
+}`
+	if string(b) != want {
+		t.Errorf("DockerConfig() = %s, want %s", b, want)
+	}
+}
+
+func TestSyncSecrets(t *testing.T) {
+	ctx := context.Background()
+	var objs []runtime.Object
+	for _, ns := range []string{"default", "dev"} {
+		objs = append(objs,
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}},
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: ns}})
+	}
+	c := fake.NewSimpleClientset(objs...)
+
+	creds := []config.RegistryCredential{
+		{Registry: "ghcr.io", Username: "me", Password: "secret", Namespaces: []string{"default", "dev", "missing"}},
+	}
+	missing, err := SyncSecrets(ctx, c, creds, nil)
+	if err != nil {
+		t.Fatalf("SyncSecrets: %v", err)
+	}
+	if !reflect.DeepEqual(missing, []string{"missing"}) {
+		t.Errorf("SyncSecrets skipped %v, want [missing]", missing)
+	}
+	for _, ns := range []string{"default", "dev"} {
+		s, err := c.CoreV1().Secrets(ns).Get(ctx, SecretName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("secret of %s: %v", ns, err)
+		}
+		if s.Type != corev1.SecretTypeDockerConfigJson {
+			t.Errorf("secret of %s has type %s", ns, s.Type)
+		}
+		sa, _ := c.CoreV1().ServiceAccounts(ns).Get(ctx, "default", metav1.GetOptions{})
+		if len(sa.ImagePullSecrets) != 1 || sa.ImagePullSecrets[0].Name != SecretName {
+			t.Errorf("service account of %s has imagePullSecrets %v", ns, sa.ImagePullSecrets)
+		}
+	}
+
+	// a second sync does not add the secret twice, and removes it from the namespaces not selected anymore
+	creds[0].Namespaces = []string{"default"}
+	if _, err := SyncSecrets(ctx, c, creds, []string{"default", "dev"}); err != nil {
+		t.Fatalf("SyncSecrets: %v", err)
+	}
+	if sa, _ := c.CoreV1().ServiceAccounts("default").Get(ctx, "default", metav1.GetOptions{}); len(sa.ImagePullSecrets) != 1 {
+		t.Errorf("service account of default has imagePullSecrets %v", sa.ImagePullSecrets)
+	}
+	if _, err := c.CoreV1().Secrets("dev").Get(ctx, SecretName, metav1.GetOptions{}); err == nil {
+		t.Errorf("the secret of dev was not removed")
+	}
+	if sa, _ := c.CoreV1().ServiceAccounts("dev").Get(ctx, "default", metav1.GetOptions{}); len(sa.ImagePullSecrets) != 0 {
+		t.Errorf("service account of dev has imagePullSecrets %v", sa.ImagePullSecrets)
+	}
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registrymirror generates the mirrors of registries managed by minikube registry-mirror into the nodes:
+// the hosts.toml of containerd, and the registries.conf drop-ins of cri-o
+package registrymirror
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/cruntime"
+)
+
+const (
+	// marker marks the configs generated by minikube registry-mirror, which are the only ones it removes
+	marker = "# managed by minikube registry-mirror"
+
+	crioRegistriesDir = "/etc/containers/registries.conf.d"
+	crioCertsDir      = "/etc/containers/certs.d"
+	crioAuthFile      = "/etc/crio/registry-mirrors-auth.json"
+	crioAuthConfig    = "/etc/crio/crio.conf.d/06-registry-mirrors.conf"
+)
+
+// registryName is a registry host, with an optional port
+var registryName = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9.-]*[a-zA-Z0-9])?(:[0-9]+)?$`)
+
+// SupportsRuntime returns whether the mirrors can be generated into the config of the container runtime rt
+func SupportsRuntime(rt string) bool {
+	return rt == constants.Containerd || rt == constants.CRIO
+}
+
+// Validate returns an error if the mirror m cannot be generated
+func Validate(m config.RegistryMirror) error {
+	if !registryName.MatchString(m.Registry) {
+		return fmt.Errorf("invalid registry %q, expected a host with an optional port, such as docker.io or localhost:5000", m.Registry)
+	}
+	u, err := url.Parse(m.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid mirror URL %q, expected http://HOST[:PORT] or https://HOST[:PORT]", m.URL)
+	}
+	if (m.Username == "") != (m.Password == "") {
+		return fmt.Errorf("the credentials of the mirror need both a username and a password")
+	}
+	if m.CACert != "" {
+		if b, _ := pem.Decode([]byte(m.CACert)); b == nil || b.Type != "CERTIFICATE" {
+			return fmt.Errorf("the CA of the mirror is not a PEM certificate")
+		}
+	}
+	return nil
+}
+
+// Add returns the mirrors with m, replacing the mirror of the same registry and URL
+func Add(mirrors []config.RegistryMirror, m config.RegistryMirror) []config.RegistryMirror {
+	for i, o := range mirrors {
+		if o.Registry == m.Registry && o.URL == m.URL {
+			mirrors[i] = m
+			return mirrors
+		}
+	}
+	return append(mirrors, m)
+}
+
+// Remove returns the mirrors without those of the registry, or only without the one of mirrorURL if it is set, and whether one was removed
+func Remove(mirrors []config.RegistryMirror, registry, mirrorURL string) ([]config.RegistryMirror, bool) {
+	var kept []config.RegistryMirror
+	for _, m := range mirrors {
+		if m.Registry == registry && (mirrorURL == "" || m.URL == mirrorURL) {
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept, len(kept) != len(mirrors)
+}
+
+// byRegistry groups the mirrors by registry, in the order of their first mirror
+func byRegistry(mirrors []config.RegistryMirror) ([]string, map[string][]config.RegistryMirror) {
+	var registries []string
+	grouped := map[string][]config.RegistryMirror{}
+	for _, m := range mirrors {
+		if _, ok := grouped[m.Registry]; !ok {
+			registries = append(registries, m.Registry)
+		}
+		grouped[m.Registry] = append(grouped[m.Registry], m)
+	}
+	return registries, grouped
+}
+
+// upstream returns the URL of the registry itself, which containerd falls back to
+func upstream(registry string) string {
+	if registry == "docker.io" {
+		return "https://registry-1.docker.io"
+	}
+	return "https://" + registry
+}
+
+// insecureURL returns the URL of the registry in the insecure registries of --insecure-registry, empty if it is not one of them
+func insecureURL(registry string, insecure []string) string {
+	for _, i := range insecure {
+		u := i
+		if !strings.HasPrefix(strings.ToLower(u), "http://") && !strings.HasPrefix(strings.ToLower(u), "https://") {
+			u = "http://" + u
+		}
+		if u[strings.Index(u, "//")+2:] == registry {
+			return u
+		}
+	}
+	return ""
+}
+
+// mirrorHost returns the host and port of the URL of the mirror m, and its path
+func mirrorHost(m config.RegistryMirror) (string, string) {
+	u, err := url.Parse(m.URL)
+	if err != nil {
+		return m.URL, ""
+	}
+	return u.Host, strings.TrimSuffix(u.Path, "/")
+}
+
+// basicAuth returns the credentials of the mirror m, encoded for basic authentication
+func basicAuth(m config.RegistryMirror) string {
+	return base64.StdEncoding.EncodeToString([]byte(m.Username + ":" + m.Password))
+}
+
+// fileName returns the registry as a file name, without the colon of its port
+func fileName(registry string) string {
+	return strings.ReplaceAll(registry, ":", "_")
+}
+
+// containerdCAPath returns the path of the CA of the mirror m of the registry in the node
+func containerdCAPath(registry string, m config.RegistryMirror) string {
+	host, _ := mirrorHost(m)
+	return path.Join(cruntime.ContainerdMirrorsRoot, registry, fileName(host)+".crt")
+}
+
+// HostsToml returns the hosts.toml of containerd sending the pulls of the registry to its mirrors, in order, before the registry itself.
+// If the registry is one of the insecure registries, it is reached at the URL of --insecure-registry without verifying its certificate,
+// as in the hosts.toml generated for them by the container runtime, which this one replaces.
+func HostsToml(registry string, mirrors []config.RegistryMirror, insecure []string) string {
+	server := insecureURL(registry, insecure)
+	var b strings.Builder
+	if server != "" {
+		fmt.Fprintf(&b, "%s\nserver = %q\n", marker, server)
+	} else {
+		fmt.Fprintf(&b, "%s\nserver = %q\n", marker, upstream(registry))
+	}
+	for _, m := range mirrors {
+		u := strings.TrimSuffix(m.URL, "/")
+		fmt.Fprintf(&b, "\n[host.%q]\n  capabilities = [\"pull\", \"resolve\"]\n", u)
+		if m.Insecure {
+			b.WriteString("  skip_verify = true\n")
+		}
+		if m.CACert != "" {
+			fmt.Fprintf(&b, "  ca = %q\n", containerdCAPath(registry, m))
+		}
+		if m.Username != "" {
+			fmt.Fprintf(&b, "  [host.%q.header]\n    authorization = \"Basic %s\"\n", u, basicAuth(m))
+		}
+	}
+	if server != "" {
+		fmt.Fprintf(&b, "\n[host.%q]\n  skip_verify = true\n", server)
+	}
+	return b.String()
+}
+
+// RegistriesConf returns the registries.conf drop-in of cri-o sending the pulls of the registry to its mirrors, in order, before the registry itself
+func RegistriesConf(registry string, mirrors []config.RegistryMirror) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n[[registry]]\nprefix = %q\nlocation = %q\n", marker, registry, registry)
+	for _, m := range mirrors {
+		host, p := mirrorHost(m)
+		// cri-o has no scheme in the locations, the mirrors without TLS are insecure
+		insecure := m.Insecure || strings.HasPrefix(m.URL, "http://")
+		fmt.Fprintf(&b, "\n[[registry.mirror]]\nlocation = %q\ninsecure = %t\n", host+p, insecure)
+	}
+	return b.String()
+}
+
+// crioAuth returns the auth file of cri-o with the credentials of the mirrors, empty if none has any
+func crioAuth(mirrors []config.RegistryMirror) (string, error) {
+	auths := map[string]map[string]string{}
+	for _, m := range mirrors {
+		if m.Username == "" {
+			continue
+		}
+		host, _ := mirrorHost(m)
+		auths[host] = map[string]string{"auth": basicAuth(m)}
+	}
+	if len(auths) == 0 {
+		return "", nil
+	}
+	b, err := json.MarshalIndent(map[string]interface{}{"auths": auths}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b) + "\n", nil
+}
+
+// Apply generates the mirrors into the config of the container runtime rt of the node, and removes those of the removed registries.
+// The hosts.toml of containerd include the insecure registries, and replace those generated for them when the runtime is enabled,
+// so they are applied after it. containerd reads its hosts.toml on every pull, cri-o must be restarted afterwards.
+func Apply(r command.Runner, rt string, mirrors []config.RegistryMirror, insecure []string, removed []string) error {
+	if !SupportsRuntime(rt) {
+		return fmt.Errorf("the mirrors of registries are not supported by the %s container runtime, use --registry-mirror", rt)
+	}
+	registries, grouped := byRegistry(mirrors)
+	for _, reg := range removed {
+		if _, ok := grouped[reg]; ok {
+			continue
+		}
+		// the insecure registry keeps its hosts.toml, without the mirrors
+		if rt == constants.Containerd && insecureURL(reg, insecure) != "" {
+			if err := writeContainerd(r, reg, nil, insecure); err != nil {
+				return errors.Wrapf(err, "removing the mirrors of %s", reg)
+			}
+			continue
+		}
+		if err := removeRegistry(r, rt, reg); err != nil {
+			return errors.Wrapf(err, "removing the mirrors of %s", reg)
+		}
+	}
+	for _, reg := range registries {
+		var err error
+		if rt == constants.CRIO {
+			err = writeCRIO(r, reg, grouped[reg])
+		} else {
+			err = writeContainerd(r, reg, grouped[reg], insecure)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "generating the mirrors of %s", reg)
+		}
+	}
+	if rt == constants.CRIO {
+		return writeCRIOAuth(r, mirrors)
+	}
+	return nil
+}
+
+func writeContainerd(r command.Runner, registry string, mirrors []config.RegistryMirror, insecure []string) error {
+	for _, m := range mirrors {
+		if m.CACert == "" {
+			continue
+		}
+		if err := r.Copy(assets.NewMemoryAssetTarget([]byte(m.CACert), containerdCAPath(registry, m), "0644")); err != nil {
+			return errors.Wrap(err, "copy CA")
+		}
+	}
+	// the credentials are in the headers of the hosts
+	return r.Copy(assets.NewMemoryAssetTarget([]byte(HostsToml(registry, mirrors, insecure)), path.Join(cruntime.ContainerdMirrorsRoot, registry, "hosts.toml"), "0600"))
+}
+
+func writeCRIO(r command.Runner, registry string, mirrors []config.RegistryMirror) error {
+	for _, m := range mirrors {
+		if m.CACert == "" {
+			continue
+		}
+		host, _ := mirrorHost(m)
+		if err := r.Copy(assets.NewMemoryAssetTarget([]byte(m.CACert), path.Join(crioCertsDir, host, "ca.crt"), "0644")); err != nil {
+			return errors.Wrap(err, "copy CA")
+		}
+	}
+	return r.Copy(assets.NewMemoryAssetTarget([]byte(RegistriesConf(registry, mirrors)), crioRegistriesPath(registry), "0644"))
+}
+
+// writeCRIOAuth points cri-o to the credentials of the mirrors, or removes them if no mirror has any
+func writeCRIOAuth(r command.Runner, mirrors []config.RegistryMirror) error {
+	auth, err := crioAuth(mirrors)
+	if err != nil {
+		return errors.Wrap(err, "encoding the credentials")
+	}
+	if auth == "" {
+		_, err := r.RunCmd(exec.Command("sudo", "rm", "-f", crioAuthFile, crioAuthConfig))
+		return err
+	}
+	if err := r.Copy(assets.NewMemoryAssetTarget([]byte(auth), crioAuthFile, "0600")); err != nil {
+		return errors.Wrap(err, "copy credentials")
+	}
+	conf := fmt.Sprintf("%s\n[crio.image]\nglobal_auth_file = %q\n", marker, crioAuthFile)
+	return r.Copy(assets.NewMemoryAssetTarget([]byte(conf), crioAuthConfig, "0644"))
+}
+
+func crioRegistriesPath(registry string) string {
+	return path.Join(crioRegistriesDir, "50-minikube-"+fileName(registry)+".conf")
+}
+
+// removeRegistry removes the generated config of the mirrors of the registry
+func removeRegistry(r command.Runner, rt, registry string) error {
+	conf := crioRegistriesPath(registry)
+	rm := conf
+	if rt == constants.Containerd {
+		conf = path.Join(cruntime.ContainerdMirrorsRoot, registry, "hosts.toml")
+		rm = path.Join(cruntime.ContainerdMirrorsRoot, registry)
+	}
+	_, err := r.RunCmd(exec.Command("sudo", "sh", "-c", fmt.Sprintf("if grep -qsF %q %s; then rm -rf %s; fi", marker, conf, rm)))
+	return err
+}
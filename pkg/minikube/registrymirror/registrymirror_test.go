@@ -0,0 +1,154 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registrymirror
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/config"
+)
+
+const testCA = `-----BEGIN CERTIFICATE-----
+MIIBhTCCASugAwIBAgIQIRi6zePL6mKjOipn+dNuaTAKBggqhkjOPQQDAjASMRAw
+-----END CERTIFICATE-----
+`
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		m     config.RegistryMirror
+		valid bool
+	}{
+		{"https", config.RegistryMirror{Registry: "docker.io", URL: "https://mirror.gcr.io"}, true},
+		{"port", config.RegistryMirror{Registry: "localhost:5000", URL: "http://192.168.49.1:5000"}, true},
+		{"credentials", config.RegistryMirror{Registry: "quay.io", URL: "https://r.example.com", Username: "me", Password: "secret"}, true},
+		{"ca", config.RegistryMirror{Registry: "quay.io", URL: "https://r.example.com", CACert: testCA}, true},
+		{"registry with path", config.RegistryMirror{Registry: "docker.io/library", URL: "https://mirror.gcr.io"}, false},
+		{"no scheme", config.RegistryMirror{Registry: "docker.io", URL: "mirror.gcr.io"}, false},
+		{"no password", config.RegistryMirror{Registry: "docker.io", URL: "https://mirror.gcr.io", Username: "me"}, false},
+		{"bad ca", config.RegistryMirror{Registry: "docker.io", URL: "https://mirror.gcr.io", CACert: "not a certificate"}, false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := Validate(tc.m); (err == nil) != tc.valid {
+				t.Errorf("Validate(%+v) = %v, want valid = %t", tc.m, err, tc.valid)
+			}
+		})
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	var ms []config.RegistryMirror
+	ms = Add(ms, config.RegistryMirror{Registry: "docker.io", URL: "https://a"})
+	ms = Add(ms, config.RegistryMirror{Registry: "docker.io", URL: "https://b"})
+	ms = Add(ms, config.RegistryMirror{Registry: "quay.io", URL: "https://a"})
+	ms = Add(ms, config.RegistryMirror{Registry: "docker.io", URL: "https://a", Insecure: true})
+	if len(ms) != 3 || !ms[0].Insecure {
+		t.Fatalf("Add did not replace the mirror of the same registry and URL: %+v", ms)
+	}
+
+	ms, removed := Remove(ms, "docker.io", "https://b")
+	if !removed || len(ms) != 2 {
+		t.Errorf("Remove(docker.io, https://b) = %+v, %t", ms, removed)
+	}
+	ms, removed = Remove(ms, "docker.io", "")
+	if !removed || len(ms) != 1 || ms[0].Registry != "quay.io" {
+		t.Errorf("Remove(docker.io) = %+v, %t", ms, removed)
+	}
+	if _, removed = Remove(ms, "gcr.io", ""); removed {
+		t.Errorf("Remove(gcr.io) removed a mirror")
+	}
+}
+
+func TestHostsToml(t *testing.T) {
+	got := HostsToml("docker.io", []config.RegistryMirror{
+		{Registry: "docker.io", URL: "https://mirror.gcr.io/"},
+		{Registry: "docker.io", URL: "https://r.example.com:5000", Username: "me", Password: "secret", CACert: testCA, Insecure: true},
+	}, []string{"localhost:5000"})
+	want := marker + `
+server = "https://registry-1.docker.io"
+
+[host."https://mirror.gcr.io"]
+  capabilities = ["pull", "resolve"]
+
+[host."https://r.example.com:5000"]
+  capabilities = ["pull", "resolve"]
+  skip_verify = true
+  ca = "/etc/containerd/certs.d/docker.io/r.example.com_5000.crt"
+  [host."https://r.example.com:5000".header]
+    authorization = "Basic bWU6c2VjcmV0"
+`
+	if got != want {
+		t.Errorf("HostsToml() = %s\nwant %s", got, want)
+	}
+}
+
+func TestHostsTomlInsecure(t *testing.T) {
+	got := HostsToml("localhost:5000", []config.RegistryMirror{
+		{Registry: "localhost:5000", URL: "http://192.168.49.1:5000"},
+	}, []string{"docker.io", "localhost:5000"})
+	want := marker + `
+server = "http://localhost:5000"
+
+[host."http://192.168.49.1:5000"]
+  capabilities = ["pull", "resolve"]
+
+[host."http://localhost:5000"]
+  skip_verify = true
+`
+	if got != want {
+		t.Errorf("HostsToml() = %s\nwant %s", got, want)
+	}
+}
+
+func TestRegistriesConf(t *testing.T) {
+	got := RegistriesConf("localhost:5000", []config.RegistryMirror{
+		{Registry: "localhost:5000", URL: "http://192.168.49.1:5000"},
+		{Registry: "localhost:5000", URL: "https://r.example.com/cache"},
+	})
+	want := marker + `
+[[registry]]
+prefix = "localhost:5000"
+location = "localhost:5000"
+
+[[registry.mirror]]
+location = "192.168.49.1:5000"
+insecure = true
+
+[[registry.mirror]]
+location = "r.example.com/cache"
+insecure = false
+`
+	if got != want {
+		t.Errorf("RegistriesConf() = %s\nwant %s", got, want)
+	}
+}
+
+func TestCRIOAuth(t *testing.T) {
+	got, err := crioAuth([]config.RegistryMirror{{Registry: "docker.io", URL: "https://mirror.gcr.io"}})
+	if err != nil || got != "" {
+		t.Errorf("crioAuth() without credentials = %q, %v, want none", got, err)
+	}
+	got, err = crioAuth([]config.RegistryMirror{{Registry: "docker.io", URL: "https://r.example.com:5000", Username: "me", Password: "secret"}})
+	if err != nil {
+		t.Fatalf("crioAuth(): %v", err)
+	}
+	if !strings.Contains(got, `"r.example.com:5000": {`) || !strings.Contains(got, `"auth": "bWU6c2VjcmV0"`) {
+		t.Errorf("crioAuth() = %s, want the credentials of r.example.com:5000", got)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package rootless audits the start of a cluster for the privileged helpers it would run on the host,
+// such as sudo and setuid binaries, which --rootless=strict forbids on the hosts where root is unattainable
+package rootless
+
+import (
+	"sort"
+	"strconv"
+
+	"k8s.io/minikube/pkg/minikube/driver"
+	"k8s.io/minikube/pkg/minikube/hostdns"
+)
+
+// Strict is the mode of --rootless forcing a rootless driver, and failing the start which would run a privileged helper on the host
+const Strict = "strict"
+
+// Valid returns whether mode is a value of --rootless: a boolean or strict
+func Valid(mode string) bool {
+	if mode == Strict {
+		return true
+	}
+	_, err := strconv.ParseBool(mode)
+	return err == nil
+}
+
+// Enabled returns whether mode forces a rootless driver
+func Enabled(mode string) bool {
+	if mode == Strict {
+		return true
+	}
+	v, _ := strconv.ParseBool(mode)
+	return v
+}
+
+// Start is what the audit needs to know about the start of a cluster
+type Start struct {
+	Root           bool            // whether minikube runs as root
+	Drivers        []string        // drivers of the nodes
+	RootlessDaemon bool            // whether the docker or podman driver runs rootless
+	Addons         map[string]bool // addons of the cluster
+}
+
+// Violation is a privileged helper which the start would run on the host
+type Violation struct {
+	Helper string // the privileged helper, such as sudo
+	Cause  string // what runs it
+	Fix    string // how to start without it
+}
+
+// driverViolations are the privileged helpers of the drivers which are not rootless, other drivers manage their VMs with the hypervisor of the host
+var driverViolations = map[string]Violation{
+	driver.None:        {Helper: "sudo", Cause: "the none driver runs Kubernetes on the host with sudo"},
+	driver.SSH:         {Helper: "sudo", Cause: "the ssh driver provisions the remote host with sudo"},
+	driver.HyperKit:    {Helper: "setuid", Cause: "the hyperkit driver runs its driver binary setuid root"},
+	driver.KVM2:        {Helper: "libvirtd", Cause: "the kvm2 driver runs the VMs with the libvirt daemon of the system, running as root"},
+	driver.QEMU2:       {Helper: "sudo", Cause: "the qemu2 driver runs socket_vmnet with sudo for the network of the VMs"},
+	driver.Firecracker: {Helper: "sudo", Cause: "the firecracker driver creates the tap device of the VMs with sudo"},
+}
+
+// Audit returns the privileged helpers which the start s would run on the host
+func Audit(s Start) []Violation {
+	var vs []Violation
+	if s.Root {
+		vs = append(vs, Violation{Helper: "root", Cause: "minikube runs as root", Fix: "Run minikube as an unprivileged user"})
+	}
+
+	drivers := map[string]bool{}
+	for _, d := range s.Drivers {
+		drivers[d] = true
+	}
+	names := make([]string, 0, len(drivers))
+	for d := range drivers {
+		names = append(names, d)
+	}
+	sort.Strings(names)
+	for _, d := range names {
+		if driver.IsKIC(d) {
+			continue
+		}
+		v, ok := driverViolations[d]
+		if !ok {
+			v = Violation{Helper: "hypervisor", Cause: "the " + d + " driver runs the VMs with the privileges of the hypervisor"}
+		}
+		v.Fix = "Use the docker or podman driver with a rootless daemon: --driver=docker or --driver=podman"
+		vs = append(vs, v)
+	}
+
+	if drivers[driver.Podman] && !s.RootlessDaemon {
+		vs = append(vs, Violation{Helper: "sudo", Cause: "the podman driver runs podman with sudo, as it is not rootless", Fix: "Run podman as your user, see https://rootlesscontaine.rs/getting-started/podman/"})
+	}
+	if drivers[driver.Docker] && !s.RootlessDaemon {
+		vs = append(vs, Violation{Helper: "dockerd", Cause: "the docker daemon runs as root", Fix: "Use rootless docker: 'dockerd-rootless-setuptool.sh install' and 'docker context use rootless'"})
+	}
+
+	if s.Addons["ingress-dns"] {
+		for _, d := range names {
+			if hostdns.Reachable(d) {
+				vs = append(vs, Violation{Helper: "sudo", Cause: "the ingress-dns addon configures the DNS of the host with sudo", Fix: "Disable the ingress-dns addon, and resolve the names of the ingresses with 'minikube ip'"})
+				break
+			}
+		}
+	}
+	return vs
+}
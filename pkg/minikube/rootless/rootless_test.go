@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rootless
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		valid   bool
+		enabled bool
+	}{
+		{"", false, false},
+		{"false", true, false},
+		{"true", true, true},
+		{"strict", true, true},
+		{"yes", false, false},
+	}
+	for _, tc := range tests {
+		if got := Valid(tc.mode); got != tc.valid {
+			t.Errorf("Valid(%q) = %t, want %t", tc.mode, got, tc.valid)
+		}
+		if got := Enabled(tc.mode); got != tc.enabled {
+			t.Errorf("Enabled(%q) = %t, want %t", tc.mode, got, tc.enabled)
+		}
+	}
+}
+
+func TestAudit(t *testing.T) {
+	tests := []struct {
+		name  string
+		start Start
+		want  []string
+	}{
+		{
+			name:  "rootless docker",
+			start: Start{Drivers: []string{"docker"}, RootlessDaemon: true},
+		},
+		{
+			name:  "rootful podman as root",
+			start: Start{Root: true, Drivers: []string{"podman"}},
+			want:  []string{"root", "sudo"},
+		},
+		{
+			name:  "rootful docker",
+			start: Start{Drivers: []string{"docker", "docker"}},
+			want:  []string{"dockerd"},
+		},
+		{
+			name:  "hybrid cluster",
+			start: Start{Drivers: []string{"docker", "kvm2", "none"}, RootlessDaemon: true},
+			want:  []string{"libvirtd", "sudo"},
+		},
+		{
+			name:  "ingress-dns",
+			start: Start{Drivers: []string{"kvm2"}, Addons: map[string]bool{"ingress-dns": true}},
+			want:  []string{"libvirtd", "sudo"},
+		},
+		{
+			name:  "unknown VM driver",
+			start: Start{Drivers: []string{"vfkit"}},
+			want:  []string{"hypervisor"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, v := range Audit(tc.start) {
+				if v.Cause == "" || v.Fix == "" {
+					t.Errorf("violation %+v has no cause or fix", v)
+				}
+				got = append(got, v.Helper)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Audit(%+v) = %v, want %v", tc.start, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sandbox integrates the sandboxed runtimes of the container runtimes with the nodes: their binaries, their runtime handlers,
+// their RuntimeClass, and the admission policy making it the default of namespaces
+package sandbox
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/vmpath"
+)
+
+const (
+	// GVisor runs the containers on the user-space kernel of gVisor
+	GVisor = "gvisor"
+	// GVisorHandler is the runtime handler of gVisor in the container runtimes
+	GVisorHandler = "runsc"
+	// GVisorVersion is the release of gVisor installed into the nodes, and into the preloads
+	GVisorVersion = "20240212"
+
+	// PolicyName is the name of the MutatingAdmissionPolicy and of its binding, setting the RuntimeClass of the pods of namespaces
+	PolicyName = "minikube-sandbox-runtime-class"
+	// FeatureGate is the feature gate of the apiserver enabling MutatingAdmissionPolicy
+	FeatureGate = "MutatingAdmissionPolicy"
+
+	binDir               = "/usr/local/bin"
+	containerdConfigFile = "/etc/containerd/config.toml"
+	crioConfigFile       = "/etc/crio/crio.conf.d/10-gvisor.conf"
+)
+
+// Sandboxes are the valid sandboxed runtimes
+var Sandboxes = []string{GVisor}
+
+// GVisorBinaries are the binaries of gVisor: runsc, run by cri-o, and the shim of containerd running it
+var GVisorBinaries = []string{"runsc", "containerd-shim-runsc-v1"}
+
+// gvisorArchs maps the architectures of the nodes to the ones of the releases of gVisor
+var gvisorArchs = map[string]string{
+	"amd64": "x86_64",
+	"arm64": "aarch64",
+}
+
+// containerdHandler is the runtime handler of gVisor in the config of containerd
+const containerdHandler = `
+[plugins."io.containerd.grpc.v1.cri".containerd.runtimes.runsc]
+  runtime_type = "io.containerd.runsc.v1"
+  pod_annotations = [ "dev.gvisor.*" ]
+`
+
+// crioHandler is the runtime handler of gVisor in the config of cri-o
+const crioHandler = `[crio.runtime.runtimes.runsc]
+runtime_path = "/usr/local/bin/runsc"
+runtime_root = "/run/runsc"
+runtime_type = "oci"
+`
+
+// Runner runs the commands configuring the sandbox on a node
+type Runner interface {
+	RunCmd(cmd *exec.Cmd) (*command.RunResult, error)
+	Copy(assets.CopyableFile) error
+}
+
+// Valid returns whether s is a sandboxed runtime
+func Valid(s string) bool {
+	for _, v := range Sandboxes {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportsRuntime returns whether the sandboxes can be registered with the container runtime rt
+func SupportsRuntime(rt string) bool {
+	return rt == constants.Containerd || rt == constants.CRIO
+}
+
+// GVisorURL returns the URL of the gVisor binary for the architecture arch, with the checksum verifying it
+func GVisorURL(binary, arch string) (string, error) {
+	a, ok := gvisorArchs[arch]
+	if !ok {
+		return "", fmt.Errorf("gVisor has no release for the %s architecture", arch)
+	}
+	base := fmt.Sprintf("https://storage.googleapis.com/gvisor/releases/release/%s/%s/%s", GVisorVersion, a, binary)
+	return fmt.Sprintf("%s?checksum=file:%s.sha512", base, base), nil
+}
+
+// BinariesDir is the directory of the gVisor binaries in the nodes, on the persistent disk next to the Kubernetes binaries,
+// so that the preloads extracted into /var carry them
+func BinariesDir() string {
+	return path.Join(vmpath.GuestPersistentDir, "binaries", "gvisor", GVisorVersion)
+}
+
+// Installed returns whether the gVisor binaries are in the node, from a preload or a previous start
+func Installed(r Runner) bool {
+	for _, b := range GVisorBinaries {
+		if _, err := r.RunCmd(exec.Command("test", "-x", path.Join(BinariesDir(), b))); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Install copies the gVisor binaries, keyed by name, into the node unless it has them, and links them into the PATH of the container runtime.
+// Linking is done on every start, as the filesystem of the VM is not persistent.
+func Install(r Runner, binaries map[string]string) error {
+	if !Installed(r) {
+		for _, b := range GVisorBinaries {
+			src, ok := binaries[b]
+			if !ok {
+				return fmt.Errorf("missing gVisor binary %s", b)
+			}
+			f, err := assets.NewFileAsset(src, BinariesDir(), b, "0755")
+			if err != nil {
+				return errors.Wrapf(err, "open %s", b)
+			}
+			err = r.Copy(f)
+			if cerr := f.Close(); cerr != nil {
+				klog.Warningf("error closing the file %s: %v", src, cerr)
+			}
+			if err != nil {
+				return errors.Wrapf(err, "copy %s", b)
+			}
+		}
+	}
+	return Link(r)
+}
+
+// Link links the gVisor binaries of the node into the PATH of the container runtime
+func Link(r Runner) error {
+	for _, b := range GVisorBinaries {
+		if _, err := r.RunCmd(exec.Command("sudo", "ln", "-sf", path.Join(BinariesDir(), b), path.Join(binDir, b))); err != nil {
+			return errors.Wrapf(err, "link %s", b)
+		}
+	}
+	return nil
+}
+
+// withContainerdHandler returns the containerd config with the table of the runtime handler, and whether it was added
+func withContainerdHandler(cfg, handler, table string) (string, bool) {
+	if strings.Contains(cfg, "runtimes."+handler+"]") {
+		return cfg, false
+	}
+	return strings.TrimRight(cfg, "\n") + "\n" + table, true
+}
+
+// AddContainerdHandler registers the runtime handler, configured by its table, with containerd unless its config has it already.
+// containerd must be restarted afterwards.
+func AddContainerdHandler(r Runner, handler, table string) error {
+	rr, err := r.RunCmd(exec.Command("sudo", "cat", containerdConfigFile))
+	if err != nil {
+		return errors.Wrap(err, "read containerd config")
+	}
+	cfg, changed := withContainerdHandler(rr.Stdout.String(), handler, table)
+	if !changed {
+		return nil
+	}
+	return errors.Wrap(r.Copy(assets.NewMemoryAssetTarget([]byte(cfg), containerdConfigFile, "0644")), "write containerd config")
+}
+
+// ConfigureContainerd registers the runtime handler of the sandbox s with containerd, which must be restarted afterwards.
+// The handler is left in place when s is empty, as the gvisor addon registers the same one.
+func ConfigureContainerd(r Runner, s string) error {
+	if s != GVisor {
+		return nil
+	}
+	return AddContainerdHandler(r, GVisorHandler, containerdHandler)
+}
+
+// ConfigureCRIO registers the runtime handler of the sandbox s with cri-o, or removes it if s is empty.
+// cri-o must be restarted afterwards.
+func ConfigureCRIO(r Runner, s string) error {
+	if s != GVisor {
+		_, err := r.RunCmd(exec.Command("sudo", "rm", "-f", crioConfigFile))
+		return errors.Wrap(err, "remove cri-o config")
+	}
+	return errors.Wrap(r.Copy(assets.NewMemoryAssetTarget([]byte(crioHandler), crioConfigFile, "0644")), "write cri-o config")
+}
+
+// RuntimeClass returns the RuntimeClass running the pods selecting it in the sandbox s
+func RuntimeClass(s string) string {
+	return fmt.Sprintf(`apiVersion: node.k8s.io/v1
+kind: RuntimeClass
+metadata:
+  name: %s
+  labels:
+    app.kubernetes.io/managed-by: minikube
+handler: %s
+`, s, GVisorHandler)
+}
+
+// AdmissionAPIVersion returns the version of the admissionregistration.k8s.io API serving MutatingAdmissionPolicy in Kubernetes v
+func AdmissionAPIVersion(v semver.Version) (string, error) {
+	switch {
+	case v.GTE(semver.MustParse("1.34.0-0")):
+		return "v1beta1", nil
+	case v.GTE(semver.MustParse("1.32.0-0")):
+		return "v1alpha1", nil
+	}
+	return "", fmt.Errorf("the default RuntimeClass of namespaces needs MutatingAdmissionPolicy, added in Kubernetes v1.32, not v%s", v)
+}
+
+// NamespacePolicy returns the MutatingAdmissionPolicy and its binding, setting the RuntimeClass of the sandbox s on the pods
+// of the namespaces which select none, served by the apiVersion of admissionregistration.k8s.io
+func NamespacePolicy(s string, namespaces []string, apiVersion string) string {
+	ns := append([]string{}, namespaces...)
+	sort.Strings(ns)
+	return fmt.Sprintf(`apiVersion: admissionregistration.k8s.io/%[1]s
+kind: MutatingAdmissionPolicy
+metadata:
+  name: %[2]s
+  labels:
+    app.kubernetes.io/managed-by: minikube
+spec:
+  matchConstraints:
+    namespaceSelector:
+      matchExpressions:
+      - key: kubernetes.io/metadata.name
+        operator: In
+        values: ["%[3]s"]
+    resourceRules:
+    - apiGroups: [""]
+      apiVersions: ["v1"]
+      operations: ["CREATE"]
+      resources: ["pods"]
+  matchConditions:
+  - name: no-runtime-class
+    expression: "!has(object.spec.runtimeClassName)"
+  failurePolicy: Fail
+  reinvocationPolicy: Never
+  mutations:
+  - patchType: ApplyConfiguration
+    applyConfiguration:
+      expression: 'Object{spec: Object.spec{runtimeClassName: "%[4]s"}}'
+---
+apiVersion: admissionregistration.k8s.io/%[1]s
+kind: MutatingAdmissionPolicyBinding
+metadata:
+  name: %[2]s
+  labels:
+    app.kubernetes.io/managed-by: minikube
+spec:
+  policyName: %[2]s
+`, apiVersion, PolicyName, strings.Join(ns, `", "`), s)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sandbox
+
+import (
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/blang/semver/v4"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+// recordingRunner records the commands and the files copied to a node, answering cat with the containerd config
+type recordingRunner struct {
+	cmds   []string
+	files  map[string]string
+	config string
+}
+
+func (r *recordingRunner) RunCmd(cmd *exec.Cmd) (*command.RunResult, error) {
+	r.cmds = append(r.cmds, strings.Join(cmd.Args, " "))
+	rr := &command.RunResult{Args: cmd.Args}
+	if cmd.Args[len(cmd.Args)-1] == containerdConfigFile {
+		rr.Stdout.WriteString(r.config)
+	}
+	return rr, nil
+}
+
+func (r *recordingRunner) Copy(f assets.CopyableFile) error {
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	r.files[f.GetTargetPath()] = string(b)
+	return nil
+}
+
+func TestWithContainerdHandler(t *testing.T) {
+	table := "\n[plugins.\"io.containerd.grpc.v1.cri\".containerd.runtimes.kata]\n  runtime_type = \"io.containerd.kata.v2\"\n"
+	got, changed := withContainerdHandler("version = 2\n", "kata", table)
+	if !changed || got != "version = 2\n"+table {
+		t.Fatalf("withContainerdHandler() = %q, %v", got, changed)
+	}
+	if _, changed := withContainerdHandler(got, "kata", table); changed {
+		t.Errorf("withContainerdHandler() added the handler twice")
+	}
+	if _, changed := withContainerdHandler(got, GVisorHandler, containerdHandler); !changed {
+		t.Errorf("withContainerdHandler() took the kata handler for the runsc one")
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	r := &recordingRunner{files: map[string]string{}, config: "version = 2\n"}
+	if err := ConfigureContainerd(r, GVisor); err != nil {
+		t.Fatalf("ConfigureContainerd: %v", err)
+	}
+	cfg := r.files[containerdConfigFile]
+	if !strings.HasPrefix(cfg, "version = 2\n") || !strings.Contains(cfg, "runtimes.runsc]\n  runtime_type = \"io.containerd.runsc.v1\"") {
+		t.Errorf("containerd config = %q", cfg)
+	}
+
+	r = &recordingRunner{files: map[string]string{}, config: cfg}
+	if err := ConfigureContainerd(r, GVisor); err != nil || len(r.files) != 0 {
+		t.Errorf("ConfigureContainerd() rewrote a config with the handler: %v", err)
+	}
+	if err := ConfigureContainerd(r, ""); err != nil || len(r.cmds) != 1 {
+		t.Errorf("ConfigureContainerd() without sandbox ran %q: %v", r.cmds, err)
+	}
+
+	if err := ConfigureCRIO(r, GVisor); err != nil || !strings.Contains(r.files[crioConfigFile], `runtime_path = "/usr/local/bin/runsc"`) {
+		t.Errorf("ConfigureCRIO() = %v, wrote %q", err, r.files[crioConfigFile])
+	}
+	if err := ConfigureCRIO(r, ""); err != nil || r.cmds[len(r.cmds)-1] != "sudo rm -f "+crioConfigFile {
+		t.Errorf("ConfigureCRIO() without sandbox ran %q: %v", r.cmds, err)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	r := &recordingRunner{files: map[string]string{}}
+	if err := Install(r, nil); err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if len(r.files) != 0 {
+		t.Errorf("Install() copied the binaries the node has: %v", r.files)
+	}
+	if want := "sudo ln -sf " + BinariesDir() + "/runsc /usr/local/bin/runsc"; !strings.Contains(strings.Join(r.cmds, "\n"), want) {
+		t.Errorf("commands = %q, want %q", r.cmds, want)
+	}
+}
+
+func TestNamespacePolicy(t *testing.T) {
+	for _, tc := range []struct {
+		version, want string
+	}{
+		{"1.31.4", ""},
+		{"1.32.0", "v1alpha1"},
+		{"1.34.0-rc.1", "v1beta1"},
+	} {
+		got, err := AdmissionAPIVersion(semver.MustParse(tc.version))
+		if got != tc.want || (err == nil) != (tc.want != "") {
+			t.Errorf("AdmissionAPIVersion(%s) = %q, %v, want %q", tc.version, got, err, tc.want)
+		}
+	}
+
+	p := NamespacePolicy(GVisor, []string{"untrusted", "ci"}, "v1beta1")
+	for _, want := range []string{
+		"apiVersion: admissionregistration.k8s.io/v1beta1\nkind: MutatingAdmissionPolicy\n",
+		`values: ["ci", "untrusted"]`,
+		`runtimeClassName: "gvisor"`,
+		"kind: MutatingAdmissionPolicyBinding",
+		"policyName: " + PolicyName,
+	} {
+		if !strings.Contains(p, want) {
+			t.Errorf("NamespacePolicy() has no %q:\n%s", want, p)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Cron is a recurring schedule, a nil field matching every value
+type Cron struct {
+	Minutes  []int
+	Hours    []int
+	Days     []int // of the month, from 1
+	Months   []int // from 1
+	Weekdays []int // from 0 for Sunday
+}
+
+// cronField is the range and value names of a field of a cron expression
+type cronField struct {
+	name     string
+	min, max int
+	names    []string // names of the values from min
+}
+
+var (
+	minuteField  = cronField{name: "minute", min: 0, max: 59}
+	hourField    = cronField{name: "hour", min: 0, max: 23}
+	dayField     = cronField{name: "day of month", min: 1, max: 31}
+	monthField   = cronField{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	weekdayField = cronField{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat", "sun"}}
+)
+
+// weekdayNames are the names of the days of the week, from Sunday, as used by systemd
+var weekdayNames = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// ParseCron parses a cron expression in the MINUTE HOUR DAY MONTH WEEKDAY format, such as "0 9 * * 1-5",
+// or a shorthand in the [WEEKDAYS] HH:MM format, such as "Mon-Fri 09:00"
+func ParseCron(expr string) (Cron, error) {
+	fields := strings.Fields(expr)
+	if (len(fields) == 1 || len(fields) == 2) && strings.Contains(fields[len(fields)-1], ":") {
+		hour, minute, ok := strings.Cut(fields[len(fields)-1], ":")
+		if !ok {
+			return Cron{}, errors.Errorf("%q is not in the HH:MM format", fields[len(fields)-1])
+		}
+		weekdays := "*"
+		if len(fields) == 2 {
+			weekdays = fields[0]
+		}
+		fields = []string{minute, hour, "*", "*", weekdays}
+	}
+	if len(fields) != 5 {
+		return Cron{}, errors.Errorf("%q is neither a cron expression (MINUTE HOUR DAY MONTH WEEKDAY) nor in the [WEEKDAYS] HH:MM format", expr)
+	}
+
+	var c Cron
+	var err error
+	for i, f := range []struct {
+		field cronField
+		dest  *[]int
+	}{
+		{minuteField, &c.Minutes},
+		{hourField, &c.Hours},
+		{dayField, &c.Days},
+		{monthField, &c.Months},
+		{weekdayField, &c.Weekdays},
+	} {
+		if *f.dest, err = f.field.parse(fields[i]); err != nil {
+			return Cron{}, err
+		}
+	}
+	// 7 is Sunday too
+	if len(c.Weekdays) > 0 && c.Weekdays[len(c.Weekdays)-1] == 7 {
+		c.Weekdays = unique(append([]int{0}, c.Weekdays[:len(c.Weekdays)-1]...))
+	}
+	if c.Days != nil && c.Weekdays != nil {
+		return Cron{}, errors.New("restricting both the day of the month and the day of the week is not supported")
+	}
+	return c, nil
+}
+
+// parse returns the values of the field matched by spec, or nil for all of them
+func (f cronField) parse(spec string) ([]int, error) {
+	if spec == "*" {
+		return nil, nil
+	}
+	var values []int
+	for _, item := range strings.Split(spec, ",") {
+		rng, step := item, 1
+		if r, s, ok := strings.Cut(item, "/"); ok {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 {
+				return nil, errors.Errorf("invalid step %q in the %s field %q", s, f.name, spec)
+			}
+			rng, step = r, n
+		}
+		first, last := f.min, f.max
+		if rng != "*" {
+			from, to, isRange := strings.Cut(rng, "-")
+			var err error
+			if first, err = f.value(from, false); err != nil {
+				return nil, err
+			}
+			last = first
+			if isRange {
+				if last, err = f.value(to, true); err != nil {
+					return nil, err
+				}
+			} else if step > 1 {
+				// a/n is from a to the end
+				last = f.max
+			}
+			if last < first {
+				return nil, errors.Errorf("invalid range %q in the %s field", rng, f.name)
+			}
+		}
+		for v := first; v <= last; v += step {
+			values = append(values, v)
+		}
+	}
+	return unique(values), nil
+}
+
+// value parses a number or a name of the field, taking the last value of a name given twice when it ends a range, such as Sunday in Fri-Sun
+func (f cronField) value(s string, end bool) (int, error) {
+	v := -1
+	for i, n := range f.names {
+		if strings.EqualFold(s, n) && (v < 0 || end) {
+			v = f.min + i
+		}
+	}
+	if v >= 0 {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, errors.Errorf("invalid %s %q, expected %d to %d", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// unique sorts values and removes their duplicates
+func unique(values []int) []int {
+	sort.Ints(values)
+	var u []int
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			u = append(u, v)
+		}
+	}
+	return u
+}
+
+// join formats values with format, separated by commas, or returns all when values is nil
+func join(values []int, format string, all string) string {
+	if values == nil {
+		return all
+	}
+	var s []string
+	for _, v := range values {
+		s = append(s, fmt.Sprintf(format, v))
+	}
+	return strings.Join(s, ",")
+}
+
+// String returns the cron expression of c
+func (c Cron) String() string {
+	return strings.Join([]string{join(c.Minutes, "%d", "*"), join(c.Hours, "%d", "*"), join(c.Days, "%d", "*"), join(c.Months, "%d", "*"), join(c.Weekdays, "%d", "*")}, " ")
+}
+
+// OnCalendar returns c as a calendar event of systemd timers, such as "Mon,Tue *-*-* 09:00:00"
+func (c Cron) OnCalendar() string {
+	event := fmt.Sprintf("*-%s-%s %s:%s:00", join(c.Months, "%02d", "*"), join(c.Days, "%02d", "*"), join(c.Hours, "%02d", "*"), join(c.Minutes, "%02d", "*"))
+	if c.Weekdays == nil {
+		return event
+	}
+	var days []string
+	for _, d := range c.Weekdays {
+		days = append(days, weekdayNames[d])
+	}
+	return strings.Join(days, ",") + " " + event
+}
+
+// CalendarIntervals returns c as the StartCalendarInterval dictionaries of a launchd job, one for each combination of values
+func (c Cron) CalendarIntervals() []map[string]int {
+	intervals := []map[string]int{{}}
+	for _, f := range []struct {
+		key    string
+		values []int
+	}{
+		{"Minute", c.Minutes},
+		{"Hour", c.Hours},
+		{"Day", c.Days},
+		{"Month", c.Months},
+		{"Weekday", c.Weekdays},
+	} {
+		if f.values == nil {
+			continue
+		}
+		var next []map[string]int
+		for _, i := range intervals {
+			for _, v := range f.values {
+				n := map[string]int{f.key: v}
+				for k, v := range i {
+					n[k] = v
+				}
+				next = append(next, n)
+			}
+		}
+		intervals = next
+	}
+	return intervals
+}
+
+// SchtasksArgs returns the arguments of 'schtasks /create' scheduling c, which only supports daily and weekly schedules at a single time
+func (c Cron) SchtasksArgs() ([]string, error) {
+	if len(c.Minutes) != 1 || len(c.Hours) != 1 || c.Days != nil || c.Months != nil {
+		return nil, errors.New("only schedules at a single time of some days of the week are supported on Windows")
+	}
+	at := fmt.Sprintf("%02d:%02d", c.Hours[0], c.Minutes[0])
+	if c.Weekdays == nil {
+		return []string{"/sc", "DAILY", "/st", at}, nil
+	}
+	var days []string
+	for _, d := range c.Weekdays {
+		days = append(days, strings.ToUpper(weekdayNames[d]))
+	}
+	return []string{"/sc", "WEEKLY", "/d", strings.Join(days, ","), "/st", at}, nil
+}
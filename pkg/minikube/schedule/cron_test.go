@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		expr       string
+		cron       string
+		onCalendar string
+	}{
+		{"Mon-Fri 09:00", "0 9 * * 1,2,3,4,5", "Mon,Tue,Wed,Thu,Fri *-*-* 09:00:00"},
+		{"8:30", "30 8 * * *", "*-*-* 08:30:00"},
+		{"0 9 * * 1-5", "0 9 * * 1,2,3,4,5", "Mon,Tue,Wed,Thu,Fri *-*-* 09:00:00"},
+		{"0 */6 1,15 * *", "0 0,6,12,18 1,15 * *", "*-*-01,15 00,06,12,18:00:00"},
+		{"15 7 * jan-mar sat,7", "15 7 * 1,2,3 0,6", "Sun,Sat *-01,02,03-* 07:15:00"},
+		{"Fri-Sun 10:00", "0 10 * * 0,5,6", "Sun,Fri,Sat *-*-* 10:00:00"},
+		{"0 10 * * 5-7", "0 10 * * 0,5,6", "Sun,Fri,Sat *-*-* 10:00:00"},
+		{"0 10 * * sun-tue", "0 10 * * 0,1,2", "Sun,Mon,Tue *-*-* 10:00:00"},
+	}
+	for _, tc := range tests {
+		c, err := ParseCron(tc.expr)
+		if err != nil {
+			t.Errorf("ParseCron(%q) = %v", tc.expr, err)
+			continue
+		}
+		if c.String() != tc.cron {
+			t.Errorf("ParseCron(%q) = %q, expected %q", tc.expr, c, tc.cron)
+		}
+		if got := c.OnCalendar(); got != tc.onCalendar {
+			t.Errorf("ParseCron(%q).OnCalendar() = %q, expected %q", tc.expr, got, tc.onCalendar)
+		}
+	}
+
+	for _, expr := range []string{"", "9", "Mon-Fri 9h", "60 9 * * *", "0 9 * * 1-9", "0 9 1 * mon", "5-1 * * * *", "*/0 * * * *", "0 9 * *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, expected an error", expr)
+		}
+	}
+}
+
+func TestCalendarIntervals(t *testing.T) {
+	c, err := ParseCron("0 9,17 * * 1,5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []map[string]int{
+		{"Minute": 0, "Hour": 9, "Weekday": 1},
+		{"Minute": 0, "Hour": 9, "Weekday": 5},
+		{"Minute": 0, "Hour": 17, "Weekday": 1},
+		{"Minute": 0, "Hour": 17, "Weekday": 5},
+	}
+	if got := c.CalendarIntervals(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("CalendarIntervals() = %v, expected %v", got, expected)
+	}
+}
+
+func TestSchtasksArgs(t *testing.T) {
+	c, _ := ParseCron("Mon-Fri 09:00")
+	args, err := c.SchtasksArgs()
+	if err != nil || strings.Join(args, " ") != "/sc WEEKLY /d MON,TUE,WED,THU,FRI /st 09:00" {
+		t.Errorf("SchtasksArgs() = %v, %v", args, err)
+	}
+	c, _ = ParseCron("07:30")
+	if args, err := c.SchtasksArgs(); err != nil || strings.Join(args, " ") != "/sc DAILY /st 07:30" {
+		t.Errorf("SchtasksArgs() = %v, %v", args, err)
+	}
+	c, _ = ParseCron("0 9,17 * * *")
+	if _, err := c.SchtasksArgs(); err == nil {
+		t.Errorf("SchtasksArgs() of two times a day succeeded, expected an error")
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/minikube/pkg/minikube/constants"
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+// startJob is the command run by a timer of the host to start a cluster
+type startJob struct {
+	// Name identifies the timer of the profile
+	Name string
+	// Args is the minikube binary and its arguments
+	Args []string
+	// Env is the environment of the command, which the timers of the host do not inherit from the shell
+	Env map[string]string
+}
+
+// newStartJob returns the job starting the cluster of the profile
+func newStartJob(profile string) (startJob, error) {
+	binary, err := os.Executable()
+	if err != nil {
+		return startJob{}, errors.Wrap(err, "minikube binary")
+	}
+	job := startJob{
+		Name: "minikube-start-" + profile,
+		Args: []string{binary, "start", "--profile", profile},
+		Env: map[string]string{
+			localpath.MinikubeHome: localpath.MiniPath(),
+			// for the docker, podman and VM driver binaries
+			"PATH": os.Getenv("PATH"),
+		},
+	}
+	if kc := os.Getenv(constants.KubeconfigEnvVar); kc != "" {
+		job.Env[constants.KubeconfigEnvVar] = kc
+	}
+	return job, nil
+}
+
+// InstallStart installs a timer of the host starting the cluster of the profile on the schedule c, replacing the existing one
+func InstallStart(profile string, c Cron) error {
+	job, err := newStartJob(profile)
+	if err != nil {
+		return err
+	}
+	return installTimer(job, c)
+}
+
+// RemoveStart removes the timer of the host starting the cluster of the profile, if any
+func RemoveStart(profile string) error {
+	job, err := newStartJob(profile)
+	if err != nil {
+		return err
+	}
+	return removeTimer(job)
+}
+
+// command returns the arguments of job quoted for a systemd unit or a scheduled task of Windows
+func (job startJob) command() string {
+	var args []string
+	for _, a := range job.Args {
+		if strings.ContainsAny(a, " \t\"") {
+			a = strconv.Quote(a)
+		}
+		args = append(args, a)
+	}
+	return strings.Join(args, " ")
+}
+
+// systemdUnits returns the service and timer units of systemd running job on the schedule c
+func systemdUnits(job startJob, c Cron) (service string, timer string) {
+	var env []string
+	for _, k := range sortedKeys(job.Env) {
+		env = append(env, fmt.Sprintf("Environment=%s", strconv.Quote(k+"="+job.Env[k])))
+	}
+	service = fmt.Sprintf(`[Unit]
+Description=%s
+
+[Service]
+Type=oneshot
+%s
+ExecStart=%s
+`, job.Name, strings.Join(env, "\n"), job.command())
+	// Persistent starts the cluster when the host wakes up if it was asleep at the scheduled time
+	timer = fmt.Sprintf(`[Unit]
+Description=%s on schedule %s
+
+[Timer]
+OnCalendar=%s
+Persistent=true
+
+[Install]
+WantedBy=timers.target
+`, job.Name, c, c.OnCalendar())
+	return service, timer
+}
+
+// launchdLabel returns the label of the launchd job running job
+func launchdLabel(job startJob) string {
+	return "io.k8s." + job.Name
+}
+
+// launchdPlist returns the property list of the launchd agent running job on the schedule c
+func launchdPlist(job startJob, c Cron) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+`)
+	fmt.Fprintf(&b, "  <key>Label</key>\n  <string>%s</string>\n", xmlEscape(launchdLabel(job)))
+	b.WriteString("  <key>ProgramArguments</key>\n  <array>\n")
+	for _, a := range job.Args {
+		fmt.Fprintf(&b, "    <string>%s</string>\n", xmlEscape(a))
+	}
+	b.WriteString("  </array>\n  <key>EnvironmentVariables</key>\n  <dict>\n")
+	for _, k := range sortedKeys(job.Env) {
+		fmt.Fprintf(&b, "    <key>%s</key>\n    <string>%s</string>\n", xmlEscape(k), xmlEscape(job.Env[k]))
+	}
+	b.WriteString("  </dict>\n  <key>StartCalendarInterval</key>\n  <array>\n")
+	for _, i := range c.CalendarIntervals() {
+		b.WriteString("    <dict>\n")
+		for _, k := range sortedKeys(i) {
+			fmt.Fprintf(&b, "      <key>%s</key>\n      <integer>%d</integer>\n", k, i[k])
+		}
+		b.WriteString("    </dict>\n")
+	}
+	b.WriteString("  </array>\n</dict>\n</plist>\n")
+	return b.String()
+}
+
+// xmlEscape escapes s for the text of an XML element
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStartJobUnits(t *testing.T) {
+	job := startJob{
+		Name: "minikube-start-dev",
+		Args: []string{"/opt/my tools/minikube", "start", "--profile", "dev"},
+		Env:  map[string]string{"MINIKUBE_HOME": "/home/u/.minikube", "PATH": "/usr/bin"},
+	}
+	c, err := ParseCron("Mon-Fri 09:00")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, timer := systemdUnits(job, c)
+	for _, want := range []string{`ExecStart="/opt/my tools/minikube" start --profile dev`, `Environment="MINIKUBE_HOME=/home/u/.minikube"`, `Environment="PATH=/usr/bin"`} {
+		if !strings.Contains(service, want) {
+			t.Errorf("service unit has no %q:\n%s", want, service)
+		}
+	}
+	if !strings.Contains(timer, "OnCalendar=Mon,Tue,Wed,Thu,Fri *-*-* 09:00:00\n") {
+		t.Errorf("timer unit has no OnCalendar:\n%s", timer)
+	}
+
+	plist := launchdPlist(job, c)
+	for _, want := range []string{"<string>io.k8s.minikube-start-dev</string>", "<string>/opt/my tools/minikube</string>", "<key>Weekday</key>\n      <integer>5</integer>"} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("launchd plist has no %q:\n%s", want, plist)
+		}
+	}
+	if n := strings.Count(plist, "<key>Hour</key>"); n != 5 {
+		t.Errorf("launchd plist has %d calendar intervals, expected 5:\n%s", n, plist)
+	}
+}
@@ -0,0 +1,70 @@
+//go:build darwin
+
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/klog/v2"
+)
+
+// launchAgentPath returns the path of the property list of the launchd agent running job
+func launchAgentPath(job startJob) string {
+	return filepath.Join(homedir.HomeDir(), "Library", "LaunchAgents", launchdLabel(job)+".plist")
+}
+
+// installTimer installs a launchd agent of the user running job on the schedule c
+func installTimer(job startJob, c Cron) error {
+	path := launchAgentPath(job)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrap(err, "mkdir")
+	}
+	// an existing agent has to be unloaded for its new schedule to be taken into account
+	if _, err := os.Stat(path); err == nil {
+		if output, err := exec.Command("launchctl", "unload", path).CombinedOutput(); err != nil {
+			klog.Warningf("launchctl unload %s: %v: %s", path, err, output)
+		}
+	}
+	if err := os.WriteFile(path, []byte(launchdPlist(job, c)), 0644); err != nil {
+		return errors.Wrap(err, "write launch agent")
+	}
+	if output, err := exec.Command("launchctl", "load", "-w", path).CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "launchctl load: %s", output)
+	}
+	return nil
+}
+
+// removeTimer unloads and removes the launchd agent of the user running job
+func removeTimer(job startJob) error {
+	path := launchAgentPath(job)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	if output, err := exec.Command("launchctl", "unload", "-w", path).CombinedOutput(); err != nil {
+		klog.Warningf("launchctl unload %s: %v: %s", path, err, output)
+	}
+	if err := os.Remove(path); err != nil {
+		return errors.Wrap(err, "remove launch agent")
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+//go:build linux
+
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/klog/v2"
+)
+
+// systemdUserDir returns the directory of the systemd units of the user
+func systemdUserDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "systemd", "user")
+	}
+	return filepath.Join(homedir.HomeDir(), ".config", "systemd", "user")
+}
+
+// systemctl runs systemctl on the units of the user
+func systemctl(args ...string) error {
+	c := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+	if output, err := c.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "systemctl %v: %s", args, output)
+	}
+	return nil
+}
+
+// installTimer installs a systemd timer of the user running job on the schedule c
+func installTimer(job startJob, c Cron) error {
+	dir := systemdUserDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrap(err, "mkdir")
+	}
+	service, timer := systemdUnits(job, c)
+	if err := os.WriteFile(filepath.Join(dir, job.Name+".service"), []byte(service), 0644); err != nil {
+		return errors.Wrap(err, "write service")
+	}
+	if err := os.WriteFile(filepath.Join(dir, job.Name+".timer"), []byte(timer), 0644); err != nil {
+		return errors.Wrap(err, "write timer")
+	}
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	return systemctl("enable", "--now", job.Name+".timer")
+}
+
+// removeTimer disables and removes the systemd timer of the user running job
+func removeTimer(job startJob) error {
+	dir := systemdUserDir()
+	timer := filepath.Join(dir, job.Name+".timer")
+	if _, err := os.Stat(timer); os.IsNotExist(err) {
+		return nil
+	}
+	if err := systemctl("disable", "--now", job.Name+".timer"); err != nil {
+		klog.Warningf("disabling %s: %v", job.Name, err)
+	}
+	for _, f := range []string{timer, filepath.Join(dir, job.Name+".service")} {
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "remove unit")
+		}
+	}
+	return systemctl("daemon-reload")
+}
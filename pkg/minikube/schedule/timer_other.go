@@ -0,0 +1,35 @@
+//go:build !linux && !darwin && !windows
+
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"runtime"
+
+	"github.com/pkg/errors"
+)
+
+// installTimer is not implemented on this OS
+func installTimer(_ startJob, _ Cron) error {
+	return errors.Errorf("scheduled starts are not supported on %s", runtime.GOOS)
+}
+
+// removeTimer has nothing to remove on this OS
+func removeTimer(_ startJob) error {
+	return nil
+}
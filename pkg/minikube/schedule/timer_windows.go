@@ -0,0 +1,55 @@
+//go:build windows
+
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// taskName returns the name of the scheduled task running job
+func taskName(job startJob) string {
+	return `minikube\` + strings.TrimPrefix(job.Name, "minikube-")
+}
+
+// installTimer creates a scheduled task of the user running job on the schedule c, which runs with the environment of the user
+func installTimer(job startJob, c Cron) error {
+	sc, err := c.SchtasksArgs()
+	if err != nil {
+		return err
+	}
+	args := append([]string{"/create", "/f", "/tn", taskName(job), "/tr", job.command()}, sc...)
+	if output, err := exec.Command("schtasks", args...).CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "schtasks /create: %s", output)
+	}
+	return nil
+}
+
+// removeTimer deletes the scheduled task running job, if any
+func removeTimer(job startJob) error {
+	if err := exec.Command("schtasks", "/query", "/tn", taskName(job)).Run(); err != nil {
+		return nil
+	}
+	if output, err := exec.Command("schtasks", "/delete", "/f", "/tn", taskName(job)).CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "schtasks /delete: %s", output)
+	}
+	return nil
+}
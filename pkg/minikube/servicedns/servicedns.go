@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package servicedns answers the DNS queries of the host for the services of a cluster, named <svc>.<ns>.svc.<domain>
+package servicedns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// ttl is short, as the IPs change with the services
+const ttl = 5
+
+// Server answers the A, AAAA and CNAME queries of the services of a cluster
+type Server struct {
+	zone string
+
+	mu      sync.RWMutex
+	records map[string]record
+}
+
+// record is what the name of a service resolves to: the IPs of the service, or the name of an ExternalName service
+type record struct {
+	ips   []net.IP
+	cname string
+}
+
+// NewServer creates a server answering for the services under svc.<domain>
+func NewServer(domain string) *Server {
+	return &Server{
+		zone:    dns.Fqdn("svc." + strings.ToLower(domain)),
+		records: make(map[string]record),
+	}
+}
+
+// Zone returns the domain the server answers for, as a fully qualified name
+func (s *Server) Zone() string {
+	return s.zone
+}
+
+// Name returns the fully qualified name of the service name in namespace
+func (s *Server) Name(namespace, name string) string {
+	return strings.ToLower(fmt.Sprintf("%s.%s.%s", name, namespace, s.zone))
+}
+
+// Update sets the record of a service, which resolves to the IPs of its load balancer if it has some, to its cluster IPs otherwise
+func (s *Server) Update(svc *v1.Service) {
+	r := recordOf(svc)
+	name := s.Name(svc.Namespace, svc.Name)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(r.ips) == 0 && r.cname == "" {
+		delete(s.records, name)
+		return
+	}
+	klog.Infof("%s: %v %s", name, r.ips, r.cname)
+	s.records[name] = r
+}
+
+// Delete removes the record of a service
+func (s *Server) Delete(svc *v1.Service) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, s.Name(svc.Namespace, svc.Name))
+}
+
+func recordOf(svc *v1.Service) record {
+	if svc.Spec.Type == v1.ServiceTypeExternalName {
+		return record{cname: dns.Fqdn(svc.Spec.ExternalName)}
+	}
+	var r record
+	for _, ing := range svc.Status.LoadBalancer.Ingress {
+		if ip := net.ParseIP(ing.IP); ip != nil {
+			r.ips = append(r.ips, ip)
+		}
+	}
+	if len(r.ips) > 0 {
+		return r
+	}
+	clusterIPs := svc.Spec.ClusterIPs
+	if len(clusterIPs) == 0 {
+		clusterIPs = []string{svc.Spec.ClusterIP}
+	}
+	for _, c := range clusterIPs {
+		// headless services have "None"
+		if ip := net.ParseIP(c); ip != nil {
+			r.ips = append(r.ips, ip)
+		}
+	}
+	return r
+}
+
+// ServeDNS answers the queries of the names of the services, and refuses the ones outside of the zone
+func (s *Server) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	m := new(dns.Msg)
+	m.SetReply(req)
+	m.Authoritative = true
+	if len(req.Question) != 1 {
+		m.SetRcode(req, dns.RcodeFormatError)
+		s.write(w, m)
+		return
+	}
+
+	q := req.Question[0]
+	name := strings.ToLower(q.Name)
+	if !dns.IsSubDomain(s.zone, name) {
+		m.SetRcode(req, dns.RcodeRefused)
+		s.write(w, m)
+		return
+	}
+	s.mu.RLock()
+	r, ok := s.records[name]
+	s.mu.RUnlock()
+	if !ok {
+		m.SetRcode(req, dns.RcodeNameError)
+		s.write(w, m)
+		return
+	}
+	m.Answer = r.answers(q.Name, q.Qtype)
+	s.write(w, m)
+}
+
+func (s *Server) write(w dns.ResponseWriter, m *dns.Msg) {
+	if err := w.WriteMsg(m); err != nil {
+		klog.Warningf("failed to answer DNS query: %v", err)
+	}
+}
+
+// answers returns the records of r of type qtype
+func (r record) answers(name string, qtype uint16) []dns.RR {
+	hdr := func(rrtype uint16) dns.RR_Header {
+		return dns.RR_Header{Name: name, Rrtype: rrtype, Class: dns.ClassINET, Ttl: ttl}
+	}
+	if r.cname != "" {
+		return []dns.RR{&dns.CNAME{Hdr: hdr(dns.TypeCNAME), Target: r.cname}}
+	}
+	var rrs []dns.RR
+	for _, ip := range r.ips {
+		if ip4 := ip.To4(); ip4 != nil {
+			if qtype == dns.TypeA || qtype == dns.TypeANY {
+				rrs = append(rrs, &dns.A{Hdr: hdr(dns.TypeA), A: ip4})
+			}
+		} else if qtype == dns.TypeAAAA || qtype == dns.TypeANY {
+			rrs = append(rrs, &dns.AAAA{Hdr: hdr(dns.TypeAAAA), AAAA: ip})
+		}
+	}
+	return rrs
+}
+
+// Watch keeps the records in sync with the services of the cluster until ctx is done, once the services are listed
+func (s *Server) Watch(ctx context.Context, client kubernetes.Interface) error {
+	factory := informers.NewSharedInformerFactory(client, 0)
+	informer := factory.Core().V1().Services().Informer()
+	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if svc, ok := obj.(*v1.Service); ok {
+				s.Update(svc)
+			}
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			if svc, ok := obj.(*v1.Service); ok {
+				s.Update(svc)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if svc, ok := obj.(*v1.Service); ok {
+				s.Delete(svc)
+			}
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "watching services")
+	}
+	factory.Start(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return errors.New("unable to list the services")
+	}
+	return nil
+}
+
+// ListenAndServe answers the queries on UDP and TCP of addr until ctx is done
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	udp := &dns.Server{Addr: addr, Net: "udp", Handler: s}
+	tcp := &dns.Server{Addr: addr, Net: "tcp", Handler: s}
+	errc := make(chan error, 2)
+	go func() { errc <- udp.ListenAndServe() }()
+	go func() { errc <- tcp.ListenAndServe() }()
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case err = <-errc:
+	}
+	// shutting down a server which failed to listen returns an error too
+	_ = udp.Shutdown()
+	_ = tcp.Shutdown()
+	return err
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package servicedns
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	v1 "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// recorder keeps the message written to it
+type recorder struct {
+	dns.ResponseWriter
+	msg *dns.Msg
+}
+
+func (r *recorder) WriteMsg(m *dns.Msg) error {
+	r.msg = m
+	return nil
+}
+
+func query(s *Server, name string, qtype uint16) *dns.Msg {
+	req := new(dns.Msg)
+	req.SetQuestion(name, qtype)
+	r := &recorder{}
+	s.ServeDNS(r, req)
+	return r.msg
+}
+
+func TestServeDNS(t *testing.T) {
+	s := NewServer("minikube")
+	s.Update(&v1.Service{
+		ObjectMeta: meta.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeClusterIP, ClusterIP: "10.96.10.1", ClusterIPs: []string{"10.96.10.1", "fd00:10:96::a"}},
+	})
+	s.Update(&v1.Service{
+		ObjectMeta: meta.ObjectMeta{Name: "lb", Namespace: "apps"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer, ClusterIP: "10.96.10.2"},
+		Status:     v1.ServiceStatus{LoadBalancer: v1.LoadBalancerStatus{Ingress: []v1.LoadBalancerIngress{{IP: "192.168.49.200"}}}},
+	})
+	s.Update(&v1.Service{
+		ObjectMeta: meta.ObjectMeta{Name: "ext", Namespace: "default"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeExternalName, ExternalName: "example.com"},
+	})
+	s.Update(&v1.Service{
+		ObjectMeta: meta.ObjectMeta{Name: "headless", Namespace: "default"},
+		Spec:       v1.ServiceSpec{ClusterIP: "None"},
+	})
+
+	tests := []struct {
+		name   string
+		qtype  uint16
+		rcode  int
+		answer string
+	}{
+		{"web.default.svc.minikube.", dns.TypeA, dns.RcodeSuccess, "10.96.10.1"},
+		{"WEB.Default.svc.minikube.", dns.TypeA, dns.RcodeSuccess, "10.96.10.1"},
+		{"web.default.svc.minikube.", dns.TypeAAAA, dns.RcodeSuccess, "fd00:10:96::a"},
+		{"lb.apps.svc.minikube.", dns.TypeA, dns.RcodeSuccess, "192.168.49.200"},
+		{"ext.default.svc.minikube.", dns.TypeA, dns.RcodeSuccess, "example.com."},
+		{"headless.default.svc.minikube.", dns.TypeA, dns.RcodeNameError, ""},
+		{"missing.default.svc.minikube.", dns.TypeA, dns.RcodeNameError, ""},
+		{"example.com.", dns.TypeA, dns.RcodeRefused, ""},
+	}
+	for _, tc := range tests {
+		m := query(s, tc.name, tc.qtype)
+		if m.Rcode != tc.rcode {
+			t.Errorf("%s: rcode = %s, want %s", tc.name, dns.RcodeToString[m.Rcode], dns.RcodeToString[tc.rcode])
+			continue
+		}
+		if tc.answer == "" {
+			continue
+		}
+		if len(m.Answer) != 1 {
+			t.Errorf("%s: answer = %v, want %s", tc.name, m.Answer, tc.answer)
+			continue
+		}
+		var got string
+		switch rr := m.Answer[0].(type) {
+		case *dns.A:
+			got = rr.A.String()
+		case *dns.AAAA:
+			got = rr.AAAA.String()
+		case *dns.CNAME:
+			got = rr.Target
+		}
+		if got != tc.answer {
+			t.Errorf("%s: answer = %s, want %s", tc.name, got, tc.answer)
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := fake.NewSimpleClientset(&v1.Service{
+		ObjectMeta: meta.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.ServiceSpec{ClusterIP: "10.96.10.1"},
+	})
+
+	s := NewServer("p1")
+	if err := s.Watch(ctx, client); err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+	if m := query(s, "web.default.svc.p1.", dns.TypeA); len(m.Answer) != 1 || !m.Answer[0].(*dns.A).A.Equal(net.ParseIP("10.96.10.1")) {
+		t.Errorf("answer of a listed service = %v", m.Answer)
+	}
+
+	if err := client.CoreV1().Services("default").Delete(ctx, "web", meta.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for query(s, "web.default.svc.p1.", dns.TypeA).Rcode != dns.RcodeNameError {
+		if time.Now().After(deadline) {
+			t.Fatalf("the record of a deleted service remains")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
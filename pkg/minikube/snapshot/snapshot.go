@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package snapshot stores the snapshots of the state of the clusters taken by 'minikube snapshot create',
+// which 'minikube restore' rolls the clusters back to.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+// How the state of a cluster is captured
+const (
+	// MethodDisk snapshots the disks of the machines with the driver
+	MethodDisk = "disk"
+	// MethodEtcd copies the etcd data of the control planes and exports the images of the workloads
+	MethodEtcd = "etcd"
+)
+
+// manifestFile is the file of the manifest in the directory of a snapshot
+const manifestFile = "snapshot.json"
+
+var nameRe = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// Manifest describes a snapshot of a cluster
+type Manifest struct {
+	Name              string    `json:"name"`
+	Profile           string    `json:"profile"`
+	Created           time.Time `json:"created"`
+	Method            string    `json:"method"`
+	Driver            string    `json:"driver"`
+	KubernetesVersion string    `json:"kubernetesVersion"`
+	// Machines are the machines of the cluster when the snapshot was taken
+	Machines []string `json:"machines"`
+	// Images are the images exported from each machine by the etcd method
+	Images map[string][]string `json:"images,omitempty"`
+}
+
+// ValidateName returns an error if name can not name a snapshot
+func ValidateName(name string) error {
+	if !nameRe.MatchString(name) {
+		return fmt.Errorf("invalid snapshot name %q, it must start with a letter or a digit followed by letters, digits, '_', '.' or '-'", name)
+	}
+	return nil
+}
+
+// Dir returns the directory of the snapshot name of profile
+func Dir(profile string, name string) string {
+	return filepath.Join(localpath.Profile(profile), "snapshots", name)
+}
+
+// EtcdPath returns the path of the etcd data of the control plane machine in the snapshot
+func EtcdPath(profile string, name string, machine string) string {
+	return filepath.Join(Dir(profile, name), "etcd-"+machine+".tar.gz")
+}
+
+// ImagesDir returns the directory of the images exported into the snapshot
+func ImagesDir(profile string, name string) string {
+	return filepath.Join(Dir(profile, name), "images")
+}
+
+// Exists returns whether profile has the snapshot name
+func Exists(profile string, name string) bool {
+	_, err := os.Stat(filepath.Join(Dir(profile, name), manifestFile))
+	return err == nil
+}
+
+// Save writes the manifest of a snapshot, which completes it
+func Save(m Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshal manifest")
+	}
+	dir := Dir(m.Profile, m.Name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return errors.Wrap(err, "mkdir")
+	}
+	return os.WriteFile(filepath.Join(dir, manifestFile), data, 0644)
+}
+
+// Load returns the manifest of the snapshot name of profile
+func Load(profile string, name string) (*Manifest, error) {
+	data, err := os.ReadFile(filepath.Join(Dir(profile, name), manifestFile))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("the cluster %s has no snapshot %s", profile, name)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "read manifest")
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, errors.Wrapf(err, "parse manifest of %s", name)
+	}
+	return m, nil
+}
+
+// List returns the manifests of the snapshots of profile, the oldest first, skipping the incomplete ones
+func List(profile string) ([]Manifest, error) {
+	entries, err := os.ReadDir(filepath.Join(localpath.Profile(profile), "snapshots"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "read snapshots")
+	}
+	var ms []Manifest
+	for _, e := range entries {
+		if !e.IsDir() || !Exists(profile, e.Name()) {
+			continue
+		}
+		m, err := Load(profile, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, *m)
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Created.Before(ms[j].Created) })
+	return ms, nil
+}
+
+// Remove removes the files of the snapshot name of profile
+func Remove(profile string, name string) error {
+	return os.RemoveAll(Dir(profile, name))
+}
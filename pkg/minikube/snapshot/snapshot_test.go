@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"before-upgrade", "v1.28.4", "a_b"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("ValidateName(%q) = %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "-x", "../x", "a/b", "a b"} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("ValidateName(%q) succeeded, expected an error", name)
+		}
+	}
+}
+
+func TestSaveListRemove(t *testing.T) {
+	t.Setenv(localpath.MinikubeHome, t.TempDir())
+
+	now := time.Now().Round(time.Second)
+	for i, name := range []string{"second", "first"} {
+		m := Manifest{Name: name, Profile: "p", Created: now.Add(-time.Duration(i) * time.Hour), Method: MethodEtcd, Machines: []string{"p", "p-m02"}}
+		if err := Save(m); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+	// an incomplete snapshot has no manifest
+	if err := os.MkdirAll(Dir("p", "incomplete"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ms, err := List("p")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(ms) != 2 || ms[0].Name != "first" || ms[1].Name != "second" {
+		t.Errorf("List() = %+v, expected first and second", ms)
+	}
+	m, err := Load("p", "second")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !m.Created.Equal(now) || len(m.Machines) != 2 {
+		t.Errorf("Load() = %+v", m)
+	}
+
+	if err := Remove("p", "second"); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if Exists("p", "second") {
+		t.Errorf("second exists after Remove")
+	}
+	if _, err := Load("p", "second"); err == nil {
+		t.Errorf("Load of a removed snapshot succeeded")
+	}
+	if ms, err := List("q"); err != nil || len(ms) != 0 {
+		t.Errorf("List() of a profile without snapshots = %v, %v", ms, err)
+	}
+}
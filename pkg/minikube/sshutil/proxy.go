@@ -0,0 +1,300 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sshutil
+
+import (
+	"crypto/ed25519"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/net/proxy"
+	"k8s.io/client-go/util/homedir"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// SchemeJump is the scheme of a proxy which is an SSH jump host (bastion)
+	SchemeJump = "ssh"
+	// SchemeSOCKS5 is the scheme of a proxy which is a SOCKS5 server
+	SchemeSOCKS5 = "socks5"
+)
+
+var (
+	// proxyURL is the proxy the machines are reached through, nil to connect directly
+	proxyURL *url.URL
+
+	// jump is the connection to the jump host, shared by all the clients of the process
+	jump   *ssh.Client
+	jumpMu sync.Mutex
+)
+
+// ParseProxy parses an ssh://[user@]host[:port] jump host or a socks5://[user[:password]@]host[:port] proxy
+func ParseProxy(spec string) (*url.URL, error) {
+	u, err := url.Parse(spec)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != SchemeJump && u.Scheme != SchemeSOCKS5 {
+		return nil, errors.Errorf("%q has no %s:// or %s:// scheme", spec, SchemeJump, SchemeSOCKS5)
+	}
+	if u.Hostname() == "" {
+		return nil, errors.Errorf("%q has no host", spec)
+	}
+	if u.Path != "" && u.Path != "/" {
+		return nil, errors.Errorf("%q has a path", spec)
+	}
+	if p := u.Port(); p != "" {
+		if n, err := strconv.Atoi(p); err != nil || n < 1 || n > 65535 {
+			return nil, errors.Errorf("%q has an invalid port", spec)
+		}
+	}
+	return u, nil
+}
+
+// SetProxy routes the SSH connections of the process through the proxy spec, see ParseProxy. An empty spec connects directly.
+func SetProxy(spec string) error {
+	jumpMu.Lock()
+	defer jumpMu.Unlock()
+
+	if spec == "" {
+		proxyURL = nil
+		resetJump()
+		return nil
+	}
+	u, err := ParseProxy(spec)
+	if err != nil {
+		return err
+	}
+	if proxyURL == nil || proxyURL.String() != u.String() {
+		resetJump()
+	}
+	proxyURL = u
+	klog.Infof("ssh connections go through %s", u.Redacted())
+	return nil
+}
+
+// ProxyEnabled returns whether the SSH connections go through a proxy
+func ProxyEnabled() bool {
+	jumpMu.Lock()
+	defer jumpMu.Unlock()
+	return proxyURL != nil
+}
+
+// ExternalArgs returns the arguments making the ssh binary go through the proxy
+func ExternalArgs() []string {
+	jumpMu.Lock()
+	defer jumpMu.Unlock()
+
+	switch {
+	case proxyURL == nil:
+		return nil
+	case proxyURL.Scheme == SchemeJump:
+		if name := proxyURL.User.Username(); name != "" {
+			return []string{"-J", name + "@" + proxyURL.Host}
+		}
+		return []string{"-J", proxyURL.Host}
+	default:
+		if proxyURL.User != nil {
+			klog.Warningf("the ssh binary cannot authenticate to the SOCKS5 proxy, use the native client")
+		}
+		return []string{"-o", "ProxyCommand=nc -X 5 -x " + hostPort(proxyURL, "1080") + " %h %p"}
+	}
+}
+
+// dial opens an SSH connection to addr, through the proxy if there is one
+func dial(addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	jumpMu.Lock()
+	u := proxyURL
+	jumpMu.Unlock()
+
+	if u == nil {
+		return ssh.Dial("tcp", addr, config)
+	}
+
+	var conn net.Conn
+	var err error
+	if u.Scheme == SchemeJump {
+		conn, err = dialJump(u, addr)
+	} else {
+		conn, err = dialSOCKS5(u, addr)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "dial %s through %s", addr, u.Redacted())
+	}
+
+	c, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return ssh.NewClient(c, chans, reqs), nil
+}
+
+// dialJump opens a connection to addr from the jump host, connecting to it first if needed
+func dialJump(u *url.URL, addr string) (net.Conn, error) {
+	jumpMu.Lock()
+	defer jumpMu.Unlock()
+
+	if jump == nil {
+		config, err := jumpConfig(u)
+		if err != nil {
+			return nil, err
+		}
+		c, err := ssh.Dial("tcp", hostPort(u, "22"), config)
+		if err != nil {
+			return nil, errors.Wrap(err, "jump host")
+		}
+		jump = c
+	}
+
+	conn, err := jump.Dial("tcp", addr)
+	if err != nil {
+		// the connection to the jump host may be broken, reconnect the next time
+		resetJump()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// jumpConfig authenticates to the jump host with the ssh agent and the default keys of the user
+func jumpConfig(u *url.URL) (*ssh.ClientConfig, error) {
+	name := u.User.Username()
+	if name == "" {
+		cur, err := user.Current()
+		if err != nil {
+			return nil, errors.Wrap(err, "current user")
+		}
+		name = cur.Username
+	}
+
+	var signers []ssh.Signer
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err != nil {
+			klog.Warningf("ssh agent: %v", err)
+		} else if s, err := agent.NewClient(conn).Signers(); err == nil {
+			signers = append(signers, s...)
+		}
+	}
+	for _, k := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+		b, err := os.ReadFile(filepath.Join(homedir.HomeDir(), ".ssh", k))
+		if err != nil {
+			continue
+		}
+		s, err := ssh.ParsePrivateKey(b)
+		if err != nil {
+			klog.Warningf("skipping key %s: %v", k, err)
+			continue
+		}
+		signers = append(signers, s)
+	}
+	if len(signers) == 0 {
+		return nil, errors.New("no ssh agent nor key in ~/.ssh to authenticate to the jump host")
+	}
+
+	hostKeys, err := knownHosts()
+	if err != nil {
+		return nil, err
+	}
+	return &ssh.ClientConfig{
+		User:              name,
+		Auth:              []ssh.AuthMethod{ssh.PublicKeys(signers...)},
+		HostKeyCallback:   hostKeys,
+		HostKeyAlgorithms: knownHostKeyAlgorithms(hostKeys, hostPort(u, "22")),
+	}, nil
+}
+
+// knownHostsFiles are the known_hosts files of the user and of the system, which the key of the jump host is checked against
+var knownHostsFiles = func() []string {
+	return []string{filepath.Join(homedir.HomeDir(), ".ssh", "known_hosts"), "/etc/ssh/ssh_known_hosts"}
+}
+
+// knownHosts returns the callback checking the key of the jump host against the known_hosts files
+func knownHosts() (ssh.HostKeyCallback, error) {
+	var files []string
+	for _, f := range knownHostsFiles() {
+		if _, err := os.Stat(f); err == nil {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no known_hosts file to check the key of the jump host, connect to it once with ssh to add it")
+	}
+	return knownhosts.New(files...)
+}
+
+// knownHostKeyAlgorithms returns the algorithms of the keys known for addr, so that the jump host presents one of them
+// rather than another of its keys, which would not match. It returns nil, the default algorithms, if no key is known.
+func knownHostKeyAlgorithms(hostKeys ssh.HostKeyCallback, addr string) []string {
+	// a key matching no entry makes the callback return the known keys of the host
+	probe, err := ssh.NewPublicKey(ed25519.PublicKey(make([]byte, ed25519.PublicKeySize)))
+	if err != nil {
+		return nil
+	}
+	var keyErr *knownhosts.KeyError
+	if err := hostKeys(addr, &net.TCPAddr{}, probe); !errors.As(err, &keyErr) {
+		return nil
+	}
+	var algos []string
+	for _, k := range keyErr.Want {
+		switch t := k.Key.Type(); t {
+		case ssh.KeyAlgoRSA:
+			algos = append(algos, ssh.KeyAlgoRSASHA512, ssh.KeyAlgoRSASHA256, ssh.KeyAlgoRSA)
+		default:
+			algos = append(algos, t)
+		}
+	}
+	return algos
+}
+
+// dialSOCKS5 opens a connection to addr through the SOCKS5 proxy
+func dialSOCKS5(u *url.URL, addr string) (net.Conn, error) {
+	var auth *proxy.Auth
+	if u.User != nil {
+		password, _ := u.User.Password()
+		auth = &proxy.Auth{User: u.User.Username(), Password: password}
+	}
+	d, err := proxy.SOCKS5("tcp", hostPort(u, "1080"), auth, proxy.Direct)
+	if err != nil {
+		return nil, err
+	}
+	return d.Dial("tcp", addr)
+}
+
+// resetJump closes the connection to the jump host, the caller holds jumpMu
+func resetJump() {
+	if jump != nil {
+		jump.Close()
+		jump = nil
+	}
+}
+
+// hostPort returns the address of the proxy, with its default port if it has none
+func hostPort(u *url.URL, port string) string {
+	if p := u.Port(); p != "" {
+		port = p
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sshutil
+
+import (
+	"os"
+	"strings"
+
+	"github.com/docker/machine/libmachine/drivers"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+	"k8s.io/klog/v2"
+)
+
+// Shell runs an interactive shell, or the command args, on the machine of the driver.
+// Unlike the libmachine client, it goes through the proxy set with SetProxy.
+func Shell(d drivers.Driver, args ...string) error {
+	client, err := NewSSHClient(d)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	sess, err := client.NewSession()
+	if err != nil {
+		return errors.Wrap(err, "new session")
+	}
+	defer sess.Close()
+
+	sess.Stdout = os.Stdout
+	sess.Stderr = os.Stderr
+	sess.Stdin = os.Stdin
+
+	width, height := 80, 24
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		old, err := term.MakeRaw(fd)
+		if err != nil {
+			return errors.Wrap(err, "raw terminal")
+		}
+		defer func() {
+			if err := term.Restore(fd, old); err != nil {
+				klog.Warningf("failed to restore the terminal: %v", err)
+			}
+		}()
+
+		if w, h, err := term.GetSize(fd); err == nil {
+			width, height = w, h
+		}
+	}
+
+	if err := sess.RequestPty("xterm", height, width, ssh.TerminalModes{ssh.ECHO: 1}); err != nil {
+		return errors.Wrap(err, "request pty")
+	}
+
+	if len(args) == 0 {
+		if err := sess.Shell(); err != nil {
+			return err
+		}
+		return sess.Wait()
+	}
+	return sess.Run(strings.Join(args, " "))
+}
@@ -59,7 +59,7 @@ func NewSSHClient(d drivers.Driver) (*ssh.Client, error) {
 
 	var client *ssh.Client
 	getSSH := func() (err error) {
-		client, err = ssh.Dial("tcp", net.JoinHostPort(h.IP, strconv.Itoa(h.Port)), &config)
+		client, err = dial(net.JoinHostPort(h.IP, strconv.Itoa(h.Port)), &config)
 		if err != nil {
 			klog.Warningf("dial failure (will retry): %v", err)
 		}
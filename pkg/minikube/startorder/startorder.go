@@ -0,0 +1,426 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package startorder brings up the workloads annotated with a start priority tier by tier, so that
+// for example databases are ready before the apps depending on them. The workloads are held when the
+// cluster stops or pauses, and released in priority order when it starts or unpauses. Holding a workload
+// does not change its spec: its pods are deleted, and an admission policy denies their creation until
+// the workload is released.
+package startorder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admissionregistration/v1"
+	admission "k8s.io/api/admissionregistration/v1beta1"
+	apps "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/kapi"
+)
+
+const (
+	// PriorityAnnotation sets the start priority of a Deployment or StatefulSet, higher priorities start first
+	PriorityAnnotation = "minikube.k8s.io/start-priority"
+	// HeldAnnotation marks a workload held by Hold, recording its replicas
+	HeldAnnotation = "minikube.k8s.io/held-replicas"
+	// GateName is the name of the admission policy, and of its binding, denying the pods of the held workloads
+	GateName = "minikube-start-order"
+	// DefaultTierTimeout bounds the wait for the workloads of a tier to be ready
+	DefaultTierTimeout = 5 * time.Minute
+)
+
+const (
+	deployment  = "deployment"
+	statefulSet = "statefulset"
+)
+
+// Workload is a Deployment or StatefulSet with a start priority
+type Workload struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Priority  int
+	// Replicas is the current replicas of the workload
+	Replicas int32
+	// Held is the replicas recorded when the workload was held, or -1 if the workload is not held
+	Held int32
+	// Selector selects the pods of the workload
+	Selector string
+}
+
+// ErrNoGate is returned by Hold when the cluster does not serve the admission policies holding the workloads
+var ErrNoGate = errors.New("the cluster does not serve ValidatingAdmissionPolicy (admissionregistration.k8s.io/v1beta1)")
+
+func (w Workload) String() string {
+	return fmt.Sprintf("%s %s/%s", w.Kind, w.Namespace, w.Name)
+}
+
+// List returns the workloads with a start priority in namespaces, or in all namespaces if empty
+func List(ctx context.Context, c kubernetes.Interface, namespaces []string) ([]Workload, error) {
+	if len(namespaces) == 0 {
+		namespaces = []string{meta.NamespaceAll}
+	}
+	var ws []Workload
+	for _, ns := range namespaces {
+		deps, err := c.AppsV1().Deployments(ns).List(ctx, meta.ListOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "list deployments")
+		}
+		for _, d := range deps.Items {
+			if w, ok := workload(deployment, d.ObjectMeta, d.Spec.Replicas, d.Spec.Selector); ok {
+				ws = append(ws, w)
+			}
+		}
+		sets, err := c.AppsV1().StatefulSets(ns).List(ctx, meta.ListOptions{})
+		if err != nil {
+			return nil, errors.Wrap(err, "list statefulsets")
+		}
+		for _, s := range sets.Items {
+			if w, ok := workload(statefulSet, s.ObjectMeta, s.Spec.Replicas, s.Spec.Selector); ok {
+				ws = append(ws, w)
+			}
+		}
+	}
+	return ws, nil
+}
+
+func workload(kind string, m meta.ObjectMeta, replicas *int32, selector *meta.LabelSelector) (Workload, bool) {
+	p, ok := m.Annotations[PriorityAnnotation]
+	if !ok {
+		return Workload{}, false
+	}
+	priority, err := strconv.Atoi(p)
+	if err != nil {
+		klog.Warningf("ignoring %s %s/%s: invalid %s %q", kind, m.Namespace, m.Name, PriorityAnnotation, p)
+		return Workload{}, false
+	}
+	w := Workload{Kind: kind, Namespace: m.Namespace, Name: m.Name, Priority: priority, Replicas: kapi.Replicas(replicas), Held: -1}
+	if sel, err := meta.LabelSelectorAsSelector(selector); err == nil && !sel.Empty() {
+		w.Selector = sel.String()
+	}
+	if h, ok := m.Annotations[HeldAnnotation]; ok {
+		held, err := strconv.Atoi(h)
+		if err != nil {
+			klog.Warningf("%s: invalid %s %q", w, HeldAnnotation, h)
+		}
+		w.Held = int32(held)
+	}
+	return w, true
+}
+
+// Tiers groups the workloads by priority, highest first
+func Tiers(ws []Workload) [][]Workload {
+	byPriority := map[int][]Workload{}
+	for _, w := range ws {
+		byPriority[w.Priority] = append(byPriority[w.Priority], w)
+	}
+	var priorities []int
+	for p := range byPriority {
+		priorities = append(priorities, p)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(priorities)))
+	var tiers [][]Workload
+	for _, p := range priorities {
+		tier := byPriority[p]
+		sort.Slice(tier, func(i, j int) bool { return tier[i].String() < tier[j].String() })
+		tiers = append(tiers, tier)
+	}
+	return tiers
+}
+
+// Hold holds the workloads with a start priority in namespaces, or in all namespaces if empty, until Release:
+// it records their replicas in HeldAnnotation, denies the creation of their pods and deletes the running ones.
+// The spec of the workloads is not changed.
+func Hold(ctx context.Context, c kubernetes.Interface, namespaces []string) ([]Workload, error) {
+	ws, err := List(ctx, c, namespaces)
+	if err != nil {
+		return nil, err
+	}
+	var hold []Workload
+	for _, w := range ws {
+		// skip the workloads already held, or scaled down by the user
+		if w.Held < 0 && w.Replicas > 0 {
+			hold = append(hold, w)
+		}
+	}
+	if len(hold) == 0 {
+		return nil, nil
+	}
+	if !gateServed(c) {
+		return nil, ErrNoGate
+	}
+	var held []Workload
+	for _, w := range hold {
+		klog.Infof("holding %s with %d replicas", w, w.Replicas)
+		if err := annotate(ctx, c, w, strconv.Itoa(int(w.Replicas))); err != nil {
+			return held, err
+		}
+		held = append(held, w)
+	}
+	if err := updateGate(ctx, c); err != nil {
+		return held, err
+	}
+	for _, w := range held {
+		if err := deletePods(ctx, c, w); err != nil {
+			return held, errors.Wrapf(err, "delete the pods of %s", w)
+		}
+	}
+	return held, nil
+}
+
+// deletePods deletes the pods of the workload, which the admission policy then keeps from being created again
+func deletePods(ctx context.Context, c kubernetes.Interface, w Workload) error {
+	// an empty selector would select all the pods of the namespace
+	if w.Selector == "" {
+		return nil
+	}
+	pods, err := c.CoreV1().Pods(w.Namespace).List(ctx, meta.ListOptions{LabelSelector: w.Selector})
+	if err != nil {
+		return err
+	}
+	for _, p := range pods.Items {
+		if err := c.CoreV1().Pods(w.Namespace).Delete(ctx, p.Name, meta.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Release releases the held workloads tier by tier, waiting up to timeout for each tier to be ready.
+// Every tier is released even if a previous one did not get ready, and the first error is returned.
+func Release(ctx context.Context, c kubernetes.Interface, timeout time.Duration, started func(tier []Workload)) error {
+	ws, err := List(ctx, c, nil)
+	if err != nil {
+		return err
+	}
+	var held []Workload
+	for _, w := range ws {
+		if w.Held >= 0 {
+			held = append(held, w)
+		}
+	}
+
+	var firstErr error
+	for _, tier := range Tiers(held) {
+		if started != nil {
+			started(tier)
+		}
+		for _, w := range tier {
+			klog.Infof("releasing %s", w)
+			if err := annotate(ctx, c, w, ""); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		if err := updateGate(ctx, c); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		for _, w := range tier {
+			// the controllers back off after their pods were denied, updating them syncs them again
+			if err := resync(ctx, c, w); err != nil {
+				klog.Warningf("unable to resync %s: %v", w, err)
+			}
+		}
+		for _, w := range tier {
+			if err := waitReady(ctx, c, w, timeout); err != nil {
+				klog.Warningf("%s not ready: %v", w, err)
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+	}
+	return firstErr
+}
+
+// gateServed returns whether the cluster serves the admission policies holding the workloads
+func gateServed(c kubernetes.Interface) bool {
+	rs, err := c.Discovery().ServerResourcesForGroupVersion(admission.SchemeGroupVersion.String())
+	if err != nil {
+		klog.Infof("unable to discover %s: %v", admission.SchemeGroupVersion, err)
+		return false
+	}
+	for _, r := range rs.APIResources {
+		if r.Name == "validatingadmissionpolicies" {
+			return true
+		}
+	}
+	return false
+}
+
+// annotate sets the held annotation of the workload, which is removed if empty
+func annotate(ctx context.Context, c kubernetes.Interface, w Workload, held string) error {
+	var annotation interface{}
+	if held != "" {
+		annotation = held
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": map[string]interface{}{HeldAnnotation: annotation}},
+	})
+	if err != nil {
+		return err
+	}
+	switch w.Kind {
+	case deployment:
+		_, err = c.AppsV1().Deployments(w.Namespace).Patch(ctx, w.Name, types.MergePatchType, patch, meta.PatchOptions{})
+	case statefulSet:
+		_, err = c.AppsV1().StatefulSets(w.Namespace).Patch(ctx, w.Name, types.MergePatchType, patch, meta.PatchOptions{})
+	}
+	return errors.Wrapf(err, "annotate %s", w)
+}
+
+// resync updates the replica sets of a deployment, which the deployment controller copies the annotations of the
+// deployment to, so that they create their pods again. Removing the annotation of a stateful set already syncs it.
+func resync(ctx context.Context, c kubernetes.Interface, w Workload) error {
+	if w.Kind != deployment || w.Selector == "" {
+		return nil
+	}
+	rss, err := c.AppsV1().ReplicaSets(w.Namespace).List(ctx, meta.ListOptions{LabelSelector: w.Selector})
+	if err != nil {
+		return err
+	}
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:null}}}`, HeldAnnotation))
+	for _, rs := range rss.Items {
+		if _, err := c.AppsV1().ReplicaSets(w.Namespace).Patch(ctx, rs.Name, types.MergePatchType, patch, meta.PatchOptions{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gateExpression returns the CEL expression admitting a pod unless it is owned by one of the held workloads
+func gateExpression(held []Workload) string {
+	var owners []string
+	for _, w := range held {
+		switch w.Kind {
+		case deployment:
+			// the replica sets of a deployment are named after it and the hash of its pod template
+			owners = append(owners, fmt.Sprintf("(request.namespace == '%s' && r.kind == 'ReplicaSet' && r.name.matches('^%s-[a-z0-9]+$'))", w.Namespace, w.Name))
+		case statefulSet:
+			owners = append(owners, fmt.Sprintf("(request.namespace == '%s' && r.kind == 'StatefulSet' && r.name == '%s')", w.Namespace, w.Name))
+		}
+	}
+	return fmt.Sprintf("!has(object.metadata.ownerReferences) || !object.metadata.ownerReferences.exists(r, %s)", strings.Join(owners, " || "))
+}
+
+// updateGate denies the creation of the pods of the held workloads, and removes the admission policy once none is held
+func updateGate(ctx context.Context, c kubernetes.Interface) error {
+	ws, err := List(ctx, c, nil)
+	if err != nil {
+		return err
+	}
+	var held []Workload
+	for _, w := range ws {
+		if w.Held >= 0 {
+			held = append(held, w)
+		}
+	}
+	policies := c.AdmissionregistrationV1beta1().ValidatingAdmissionPolicies()
+	bindings := c.AdmissionregistrationV1beta1().ValidatingAdmissionPolicyBindings()
+	if len(held) == 0 {
+		if err := bindings.Delete(ctx, GateName, meta.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "delete admission policy binding")
+		}
+		if err := policies.Delete(ctx, GateName, meta.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrap(err, "delete admission policy")
+		}
+		return nil
+	}
+
+	fail := admission.Fail
+	policy := &admission.ValidatingAdmissionPolicy{
+		ObjectMeta: meta.ObjectMeta{Name: GateName},
+		Spec: admission.ValidatingAdmissionPolicySpec{
+			FailurePolicy: &fail,
+			MatchConstraints: &admission.MatchResources{
+				ResourceRules: []admission.NamedRuleWithOperations{{
+					RuleWithOperations: admissionv1.RuleWithOperations{
+						Operations: []admissionv1.OperationType{admissionv1.Create},
+						Rule:       admissionv1.Rule{APIGroups: []string{""}, APIVersions: []string{"v1"}, Resources: []string{"pods"}},
+					},
+				}},
+			},
+			Validations: []admission.Validation{{
+				Expression: gateExpression(held),
+				Message:    "the workload is held by minikube until the cluster starts, see the " + PriorityAnnotation + " annotation",
+			}},
+		},
+	}
+	current, err := policies.Get(ctx, GateName, meta.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = policies.Create(ctx, policy, meta.CreateOptions{})
+	case err == nil:
+		policy.ResourceVersion = current.ResourceVersion
+		_, err = policies.Update(ctx, policy, meta.UpdateOptions{})
+	}
+	if err != nil {
+		return errors.Wrap(err, "write admission policy")
+	}
+
+	binding := &admission.ValidatingAdmissionPolicyBinding{
+		ObjectMeta: meta.ObjectMeta{Name: GateName},
+		Spec: admission.ValidatingAdmissionPolicyBindingSpec{
+			PolicyName:        GateName,
+			ValidationActions: []admission.ValidationAction{admission.Deny},
+		},
+	}
+	if _, err := bindings.Create(ctx, binding, meta.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrap(err, "write admission policy binding")
+	}
+	return nil
+}
+
+// waitReady waits for all the replicas of the workload to be ready
+func waitReady(ctx context.Context, c kubernetes.Interface, w Workload, timeout time.Duration) error {
+	return wait.PollUntilContextTimeout(ctx, time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		switch w.Kind {
+		case deployment:
+			d, err := c.AppsV1().Deployments(w.Namespace).Get(ctx, w.Name, meta.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			return deploymentReady(d), nil
+		case statefulSet:
+			s, err := c.AppsV1().StatefulSets(w.Namespace).Get(ctx, w.Name, meta.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			return statefulSetReady(s), nil
+		}
+		return true, nil
+	})
+}
+
+func deploymentReady(d *apps.Deployment) bool {
+	return d.Status.ObservedGeneration >= d.Generation && d.Status.ReadyReplicas >= kapi.Replicas(d.Spec.Replicas)
+}
+
+func statefulSetReady(s *apps.StatefulSet) bool {
+	return s.Status.ObservedGeneration >= s.Generation && s.Status.ReadyReplicas >= kapi.Replicas(s.Spec.Replicas)
+}
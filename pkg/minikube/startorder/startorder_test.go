@@ -0,0 +1,182 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package startorder
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	apps "k8s.io/api/apps/v1"
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func int32Ptr(i int32) *int32 { return &i }
+
+func objectMeta(ns, name, priority string) meta.ObjectMeta {
+	m := meta.ObjectMeta{Namespace: ns, Name: name}
+	if priority != "" {
+		m.Annotations = map[string]string{PriorityAnnotation: priority}
+	}
+	return m
+}
+
+// newDeployment is reported ready with its replicas, as the fake clientset runs no controller
+func newDeployment(ns, name, priority string, replicas int32) *apps.Deployment {
+	return &apps.Deployment{
+		ObjectMeta: objectMeta(ns, name, priority),
+		Spec:       apps.DeploymentSpec{Replicas: int32Ptr(replicas), Selector: &meta.LabelSelector{MatchLabels: map[string]string{"app": name}}},
+		Status:     apps.DeploymentStatus{ReadyReplicas: replicas},
+	}
+}
+
+func newPod(ns, app string) *core.Pod {
+	return &core.Pod{ObjectMeta: meta.ObjectMeta{Namespace: ns, Name: app + "-abc12-xyz34", Labels: map[string]string{"app": app}}}
+}
+
+// newClientset serves the admission policies holding the workloads
+func newClientset(objects ...runtime.Object) *fake.Clientset {
+	c := fake.NewSimpleClientset(objects...)
+	c.Fake.Resources = []*meta.APIResourceList{{
+		GroupVersion: "admissionregistration.k8s.io/v1beta1",
+		APIResources: []meta.APIResource{{Name: "validatingadmissionpolicies"}, {Name: "validatingadmissionpolicybindings"}},
+	}}
+	return c
+}
+
+func TestHoldRelease(t *testing.T) {
+	c := newClientset(
+		newPod("default", "web"),
+		newPod("other", "worker"),
+		newDeployment("default", "web", "0", 2),
+		newDeployment("default", "api", "10", 1),
+		newDeployment("default", "plain", "", 1),
+		newDeployment("default", "invalid", "high", 1),
+		newDeployment("other", "worker", "0", 1),
+		&apps.StatefulSet{
+			ObjectMeta: objectMeta("default", "db", "100"),
+			Spec:       apps.StatefulSetSpec{Replicas: int32Ptr(1)},
+			Status:     apps.StatefulSetStatus{ReadyReplicas: 1},
+		},
+	)
+	ctx := context.Background()
+
+	held, err := Hold(ctx, c, []string{"default"})
+	if err != nil {
+		t.Fatalf("Hold: %v", err)
+	}
+	if len(held) != 3 {
+		t.Errorf("Hold held %v, want web, api and db", held)
+	}
+	web, err := c.AppsV1().Deployments("default").Get(ctx, "web", meta.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *web.Spec.Replicas != 2 || web.Annotations[HeldAnnotation] != "2" {
+		t.Errorf("held web has %d replicas and %s=%q, want its spec unchanged and \"2\"", *web.Spec.Replicas, HeldAnnotation, web.Annotations[HeldAnnotation])
+	}
+	if pods, _ := c.CoreV1().Pods("default").List(ctx, meta.ListOptions{}); len(pods.Items) != 0 {
+		t.Errorf("Hold kept the pods %v of web", pods.Items)
+	}
+	if pods, _ := c.CoreV1().Pods("other").List(ctx, meta.ListOptions{}); len(pods.Items) != 1 {
+		t.Errorf("Hold deleted the pod of a workload of another namespace")
+	}
+	policy, err := c.AdmissionregistrationV1beta1().ValidatingAdmissionPolicies().Get(ctx, GateName, meta.GetOptions{})
+	if err != nil {
+		t.Fatalf("Hold did not write the admission policy: %v", err)
+	}
+	if expr := policy.Spec.Validations[0].Expression; !strings.Contains(expr, "'^web-[a-z0-9]+$'") || strings.Contains(expr, "worker") {
+		t.Errorf("admission policy denies %q, want web and not worker", expr)
+	}
+
+	// holding again keeps the recorded replicas
+	if held, err := Hold(ctx, c, nil); err != nil || len(held) != 1 {
+		t.Errorf("Hold again = %v, %v, want only worker", held, err)
+	}
+
+	var order [][]string
+	err = Release(ctx, c, time.Second, func(tier []Workload) {
+		var names []string
+		for _, w := range tier {
+			names = append(names, w.Name)
+		}
+		order = append(order, names)
+	})
+	if err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	want := [][]string{{"db"}, {"api"}, {"web", "worker"}}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("Release order = %v, want %v", order, want)
+	}
+	web, err = c.AppsV1().Deployments("default").Get(ctx, "web", meta.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := web.Annotations[HeldAnnotation]; ok {
+		t.Errorf("released web still has %s", HeldAnnotation)
+	}
+	if _, err := c.AdmissionregistrationV1beta1().ValidatingAdmissionPolicies().Get(ctx, GateName, meta.GetOptions{}); err == nil {
+		t.Errorf("Release kept the admission policy once all workloads were released")
+	}
+}
+
+func TestHoldWithoutGate(t *testing.T) {
+	c := fake.NewSimpleClientset(newDeployment("default", "web", "0", 2), newPod("default", "web"))
+	if _, err := Hold(context.Background(), c, nil); err != ErrNoGate {
+		t.Fatalf("Hold = %v, want ErrNoGate", err)
+	}
+	if pods, _ := c.CoreV1().Pods("default").List(context.Background(), meta.ListOptions{}); len(pods.Items) != 1 {
+		t.Errorf("Hold deleted pods although it can not hold them")
+	}
+}
+
+func TestGateExpression(t *testing.T) {
+	expr := gateExpression([]Workload{
+		{Kind: deployment, Namespace: "default", Name: "web"},
+		{Kind: statefulSet, Namespace: "data", Name: "db"},
+	})
+	want := "!has(object.metadata.ownerReferences) || !object.metadata.ownerReferences.exists(r, " +
+		"(request.namespace == 'default' && r.kind == 'ReplicaSet' && r.name.matches('^web-[a-z0-9]+$')) || " +
+		"(request.namespace == 'data' && r.kind == 'StatefulSet' && r.name == 'db'))"
+	if expr != want {
+		t.Errorf("gateExpression() = %q, want %q", expr, want)
+	}
+}
+
+func TestReleaseTimeout(t *testing.T) {
+	d := newDeployment("default", "slow", "1", 3)
+	d.Status.ReadyReplicas = 0
+	d.Annotations[HeldAnnotation] = "3"
+	c := newClientset(d)
+
+	if err := Release(context.Background(), c, time.Second, nil); err == nil {
+		t.Errorf("Release succeeded for a workload never ready")
+	}
+	slow, err := c.AppsV1().Deployments("default").Get(context.Background(), "slow", meta.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := slow.Annotations[HeldAnnotation]; ok {
+		t.Errorf("slow is still held after Release")
+	}
+}
@@ -21,11 +21,17 @@ import (
 	"errors"
 	"fmt"
 	"os/exec"
+	"path"
 	"strings"
 
 	"k8s.io/minikube/pkg/minikube/assets"
 )
 
+// DropInPath returns the path of the drop-in name overriding the systemd unit, such as kubelet.service
+func DropInPath(unit, name string) string {
+	return path.Join("/etc/systemd/system", unit+".d", name)
+}
+
 // Systemd is a service manager for systemd distributions
 type Systemd struct {
 	r Runner
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package transfer holds the settings shared by the downloads of minikube: how many of them run concurrently,
+// and the bandwidth they may use together
+package transfer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/docker/go-units"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultParallelism is the default number of concurrent downloads
+	DefaultParallelism = 4
+
+	// maxBurst is the most bytes read at once by a download limited in bandwidth
+	maxBurst = 256 * 1024
+)
+
+var (
+	parallelism = DefaultParallelism
+
+	// limiter is shared by all the downloads, nil when their bandwidth is not limited
+	limiter *rate.Limiter
+)
+
+// SetParallelism sets the number of concurrent downloads, of images and of the parts of preloads
+func SetParallelism(n int) {
+	if n > 0 {
+		parallelism = n
+	}
+}
+
+// Parallelism returns the number of concurrent downloads
+func Parallelism() int {
+	return parallelism
+}
+
+// ParseRate parses a bandwidth in bytes per second, such as 10MB or 512KB
+func ParseRate(s string) (int64, error) {
+	b, err := units.FromHumanSize(s)
+	if err != nil {
+		return 0, err
+	}
+	if b <= 0 {
+		return 0, fmt.Errorf("the bandwidth %q must be positive", s)
+	}
+	return b, nil
+}
+
+// SetRateLimit limits the bandwidth of all the downloads together to bytesPerSecond, 0 removing the limit
+func SetRateLimit(bytesPerSecond int64) {
+	if bytesPerSecond <= 0 {
+		limiter = nil
+		return
+	}
+	burst := bytesPerSecond
+	if burst > maxBurst {
+		burst = maxBurst
+	}
+	limiter = rate.NewLimiter(rate.Limit(bytesPerSecond), int(burst))
+}
+
+// Transport returns base limited to the bandwidth of SetRateLimit, base defaulting to http.DefaultTransport
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &limitedTransport{base: base}
+}
+
+type limitedTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip limits the bandwidth of the body of the response, checking the limit at every request so it applies to the clients created before SetRateLimit
+func (t *limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || limiter == nil {
+		return resp, err
+	}
+	resp.Body = &limitedReader{ReadCloser: resp.Body, ctx: req.Context(), l: limiter}
+	return resp, nil
+}
+
+type limitedReader struct {
+	io.ReadCloser
+	ctx context.Context
+	l   *rate.Limiter
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if len(p) > r.l.Burst() {
+		p = p[:r.l.Burst()]
+	}
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		if werr := r.l.WaitN(r.ctx, n); werr != nil && err == nil {
+			err = werr
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package transfer
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		rate  string
+		want  int64
+		valid bool
+	}{
+		{"10MB", 10000000, true},
+		{"512KB", 512000, true},
+		{"1024", 1024, true},
+		{"0", 0, false},
+		{"fast", 0, false},
+	}
+	for _, tc := range tests {
+		got, err := ParseRate(tc.rate)
+		if (err == nil) != tc.valid || got != tc.want {
+			t.Errorf("ParseRate(%q) = %d, %v, want %d, valid = %t", tc.rate, got, err, tc.want, tc.valid)
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 64*1024)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(content)
+	}))
+	defer s.Close()
+	defer SetRateLimit(0)
+
+	get := func() time.Duration {
+		start := time.Now()
+		resp, err := (&http.Client{Transport: Transport(nil)}).Get(s.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		if err != nil || !bytes.Equal(b, content) {
+			t.Fatalf("read %d bytes: %v", len(b), err)
+		}
+		return time.Since(start)
+	}
+
+	if d := get(); d > time.Second {
+		t.Errorf("the unlimited download took %s", d)
+	}
+	// the burst is spent at once, the rest of the body takes about a second
+	SetRateLimit(32 * 1024)
+	if d := get(); d < 500*time.Millisecond {
+		t.Errorf("the download limited to 32KB/s took %s, want about a second", d)
+	}
+}
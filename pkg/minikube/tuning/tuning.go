@@ -0,0 +1,338 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tuning applies the kernel and ulimit settings of a tuning profile to the nodes of a cluster
+package tuning
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+	"k8s.io/minikube/pkg/minikube/sysinit"
+)
+
+const (
+	// None applies no tuning, leaving the defaults of the node
+	None = "none"
+	// Dev raises the inotify, open files and pid limits, which file watchers of dev servers and controllers with many watches exhaust
+	Dev = "dev"
+
+	// NoFile is the option setting the open files limit of the container runtime and of the containers it runs
+	NoFile = "nofile"
+
+	sysctlPath  = "/etc/sysctl.d/99-minikube-tuning.conf"
+	modulesPath = "/etc/modules-load.d/99-minikube-tuning.conf"
+	dropInName  = "99-minikube-tuning.conf"
+)
+
+var (
+	sysctlName = regexp.MustCompile(`^[a-zA-Z0-9_-]+([./][a-zA-Z0-9_-]+)+$`)
+	moduleName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+// Profiles are the valid tuning profiles
+var Profiles = []string{None, Dev}
+
+// runtimeServices are the services of the container runtimes, which the containers inherit the limits of
+var runtimeServices = []string{"docker", "containerd", "crio"}
+
+// profiles maps a tuning profile to its options
+var profiles = map[string]map[string]string{
+	None: {},
+	Dev: {
+		"fs.inotify.max_user_watches":   "1048576",
+		"fs.inotify.max_user_instances": "8192",
+		"fs.inotify.max_queued_events":  "65536",
+		"kernel.pid_max":                "4194304",
+		NoFile:                          "1048576",
+	},
+}
+
+// Settings are the options of a tuning profile: sysctls, kernel modules, and the open files limit
+type Settings struct {
+	Sysctls map[string]string
+	Modules []string
+	NoFile  int
+}
+
+// Empty returns whether the settings change nothing
+func (s Settings) Empty() bool {
+	return len(s.Sysctls) == 0 && len(s.Modules) == 0 && s.NoFile == 0
+}
+
+// AddSysctls sets the sysctls in the key=value format, overriding the values of the profile
+func (s *Settings) AddSysctls(sysctls []string) error {
+	for _, o := range sysctls {
+		k, v, ok := strings.Cut(o, "=")
+		if !ok || v == "" || !sysctlName.MatchString(k) {
+			return fmt.Errorf("invalid sysctl %q, expected key=value such as net.netfilter.nf_conntrack_max=262144", o)
+		}
+		if s.Sysctls == nil {
+			s.Sysctls = map[string]string{}
+		}
+		s.Sysctls[k] = v
+	}
+	return nil
+}
+
+// AddModules adds the kernel modules loaded on the nodes
+func (s *Settings) AddModules(modules []string) error {
+	for _, m := range modules {
+		if !moduleName.MatchString(m) {
+			return fmt.Errorf("invalid kernel module name %q", m)
+		}
+		if !slices.Contains(s.Modules, m) {
+			s.Modules = append(s.Modules, m)
+		}
+	}
+	return nil
+}
+
+// Resolve returns the settings of a profile, with options in the key=value format overriding its values.
+// Keys are sysctl names, or "nofile".
+func Resolve(profile string, opts []string) (Settings, error) {
+	if profile == "" {
+		profile = None
+	}
+	defaults, ok := profiles[profile]
+	if !ok {
+		return Settings{}, fmt.Errorf("unknown tuning profile %q, valid profiles are: %s", profile, strings.Join(Profiles, ", "))
+	}
+	merged := map[string]string{}
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for _, o := range opts {
+		k, v, ok := strings.Cut(o, "=")
+		if !ok || k == "" || v == "" {
+			return Settings{}, fmt.Errorf("invalid tuning option %q, expected key=value", o)
+		}
+		if k != NoFile && !strings.Contains(k, ".") {
+			return Settings{}, fmt.Errorf("invalid tuning option %q, the key must be a sysctl such as fs.inotify.max_user_watches, or %s", o, NoFile)
+		}
+		merged[k] = v
+	}
+
+	s := Settings{Sysctls: map[string]string{}}
+	for k, v := range merged {
+		if k != NoFile {
+			s.Sysctls[k] = v
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return Settings{}, fmt.Errorf("invalid %s value %q, expected a positive number", NoFile, v)
+		}
+		s.NoFile = n
+	}
+	return s, nil
+}
+
+// sysctlKeys returns the sorted names of the sysctls
+func (s Settings) sysctlKeys() []string {
+	keys := []string{}
+	for k := range s.Sysctls {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SysctlArgs returns the sysctls as arguments of the sysctl command
+func (s Settings) SysctlArgs() string {
+	args := []string{}
+	for _, k := range s.sysctlKeys() {
+		args = append(args, k+"="+s.Sysctls[k])
+	}
+	return strings.Join(args, " ")
+}
+
+// String returns the settings in the key=value format of the options, followed by the kernel modules
+func (s Settings) String() string {
+	args := s.SysctlArgs()
+	if s.NoFile > 0 {
+		args = strings.TrimSpace(fmt.Sprintf("%s %s=%d", args, NoFile, s.NoFile))
+	}
+	if len(s.Modules) > 0 {
+		args = strings.TrimSpace(fmt.Sprintf("%s modules=%s", args, strings.Join(s.Modules, ",")))
+	}
+	return args
+}
+
+// sysctlConf returns the content of the sysctl.d file of the settings
+func (s Settings) sysctlConf() string {
+	var b strings.Builder
+	b.WriteString("# written by minikube, from the tuning profile of the cluster\n")
+	for _, k := range s.sysctlKeys() {
+		fmt.Fprintf(&b, "%s = %s\n", k, s.Sysctls[k])
+	}
+	return b.String()
+}
+
+// modulesConf returns the content of the modules-load.d file loading the kernel modules at boot
+func (s Settings) modulesConf() string {
+	return "# written by minikube, from the kernel modules of the cluster\n" + strings.Join(s.Modules, "\n") + "\n"
+}
+
+// dropIn returns the content of the systemd drop-in setting the limits of a service
+func (s Settings) dropIn() string {
+	return fmt.Sprintf("[Service]\nLimitNOFILE=%d\n", s.NoFile)
+}
+
+// Runner runs the commands applying the settings on a node
+type Runner interface {
+	RunCmd(cmd *exec.Cmd) (*command.RunResult, error)
+	Copy(assets.CopyableFile) error
+}
+
+// UnsupportedError is returned by Apply when the kernel of the node has no such sysctls or kernel modules
+type UnsupportedError struct {
+	Sysctls []string
+	Modules []string
+}
+
+func (e *UnsupportedError) Error() string {
+	var missing []string
+	if len(e.Modules) > 0 {
+		missing = append(missing, "kernel modules: "+strings.Join(e.Modules, ", "))
+	}
+	if len(e.Sysctls) > 0 {
+		missing = append(missing, "sysctls: "+strings.Join(e.Sysctls, ", "))
+	}
+	return "the kernel of the node does not support the " + strings.Join(missing, "; the ")
+}
+
+// unsupported returns the kernel modules and sysctls the running kernel of the node does not have.
+// A module is available if it is loaded or built in, or unless loaded is set, if modprobe can find it.
+func unsupported(r Runner, modules, sysctls []string, loaded bool) (*UnsupportedError, error) {
+	var script strings.Builder
+	for _, m := range modules {
+		// modprobe resolves the names with - and _ alike, /sys/module has the _ form
+		fmt.Fprintf(&script, "[ -d /sys/module/%s ]", strings.ReplaceAll(m, "-", "_"))
+		if !loaded {
+			fmt.Fprintf(&script, " || modprobe -n %s >/dev/null 2>&1", m)
+		}
+		fmt.Fprintf(&script, " || echo module %s\n", m)
+	}
+	for _, k := range sysctls {
+		fmt.Fprintf(&script, "[ -e /proc/sys/%s ] || echo sysctl %s\n", strings.ReplaceAll(k, ".", "/"), k)
+	}
+	if script.Len() == 0 {
+		return nil, nil
+	}
+	rr, err := r.RunCmd(exec.Command("/bin/bash", "-c", script.String()))
+	if err != nil {
+		return nil, errors.Wrap(err, "check the kernel of the node")
+	}
+	e := &UnsupportedError{}
+	for _, line := range strings.Split(strings.TrimSpace(rr.Stdout.String()), "\n") {
+		if kind, name, ok := strings.Cut(line, " "); ok && kind == "module" {
+			e.Modules = append(e.Modules, name)
+		} else if ok && kind == "sysctl" {
+			e.Sysctls = append(e.Sysctls, name)
+		}
+	}
+	if len(e.Modules) == 0 && len(e.Sysctls) == 0 {
+		return nil, nil
+	}
+	return e, nil
+}
+
+// Apply applies the settings to a node, which is done on every start as the filesystem of the VM is not persistent.
+// The kernel modules are loaded before the sysctls are checked against the running kernel, as modules such as
+// nf_conntrack add sysctls of their own. If shareKernel is set, the node shares the kernel of the host: the modules
+// and sysctls are only checked, as they must be loaded and set on the host.
+// The container runtime must be restarted afterwards for the open files limit to take effect.
+func Apply(r Runner, s Settings, shareKernel bool) error {
+	if len(s.Modules) > 0 {
+		missing, err := unsupported(r, s.Modules, nil, shareKernel)
+		if err != nil {
+			return err
+		}
+		if missing != nil {
+			return missing
+		}
+		if !shareKernel {
+			if err := r.Copy(assets.NewMemoryAssetTarget([]byte(s.modulesConf()), modulesPath, "0644")); err != nil {
+				return errors.Wrap(err, "copy modules config")
+			}
+			if _, err := r.RunCmd(exec.Command("sudo", append([]string{"modprobe", "-a"}, s.Modules...)...)); err != nil {
+				return errors.Wrap(err, "load kernel modules")
+			}
+		}
+	}
+
+	if len(s.Sysctls) > 0 {
+		missing, err := unsupported(r, nil, s.sysctlKeys(), shareKernel)
+		if err != nil {
+			return err
+		}
+		if missing != nil {
+			return missing
+		}
+	}
+	if len(s.Sysctls) > 0 && !shareKernel {
+		if err := r.Copy(assets.NewMemoryAssetTarget([]byte(s.sysctlConf()), sysctlPath, "0644")); err != nil {
+			return errors.Wrap(err, "copy sysctl config")
+		}
+		if _, err := r.RunCmd(exec.Command("sudo", "sysctl", "-p", sysctlPath)); err != nil {
+			return errors.Wrap(err, "apply sysctls")
+		}
+	}
+
+	if s.NoFile > 0 {
+		for _, svc := range runtimeServices {
+			f := assets.NewMemoryAssetTarget([]byte(s.dropIn()), sysinit.DropInPath(svc+".service", dropInName), "0644")
+			if err := r.Copy(f); err != nil {
+				return errors.Wrapf(err, "copy %s drop-in", svc)
+			}
+		}
+		if _, err := r.RunCmd(exec.Command("sudo", "systemctl", "daemon-reload")); err != nil {
+			return errors.Wrap(err, "daemon-reload")
+		}
+	}
+	return nil
+}
+
+// Clear removes the files of a previous tuning profile from a node with a persistent filesystem.
+// Its sysctls and kernel modules stay in effect until the next reboot of the node.
+func Clear(r Runner) {
+	files := []string{sysctlPath, modulesPath}
+	for _, svc := range runtimeServices {
+		files = append(files, sysinit.DropInPath(svc+".service", dropInName))
+	}
+	rr, err := r.RunCmd(exec.Command("sudo", append([]string{"rm", "-fv"}, files...)...))
+	if err != nil {
+		klog.Warningf("unable to remove the tuning files: %v", err)
+		return
+	}
+	if rr.Stdout.Len() == 0 {
+		return
+	}
+	if _, err := r.RunCmd(exec.Command("sudo", "systemctl", "daemon-reload")); err != nil {
+		klog.Warningf("daemon-reload: %v", err)
+	}
+}
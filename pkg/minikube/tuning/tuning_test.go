@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tuning
+
+import (
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+func TestResolve(t *testing.T) {
+	s, err := Resolve("", nil)
+	if err != nil || !s.Empty() {
+		t.Errorf("Resolve(\"\") = %+v, %v, want empty settings", s, err)
+	}
+
+	s, err = Resolve(Dev, []string{"fs.inotify.max_user_watches=2097152", "nofile=65536", "vm.max_map_count=262144"})
+	if err != nil {
+		t.Fatalf("Resolve(dev): %v", err)
+	}
+	if s.NoFile != 65536 {
+		t.Errorf("NoFile = %d, want the override 65536", s.NoFile)
+	}
+	want := "fs.inotify.max_queued_events=65536 fs.inotify.max_user_instances=8192 fs.inotify.max_user_watches=2097152 kernel.pid_max=4194304 vm.max_map_count=262144 nofile=65536"
+	if got := s.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	for _, tc := range []struct {
+		profile string
+		opts    []string
+	}{
+		{"prod", nil},
+		{Dev, []string{"max_user_watches"}},
+		{Dev, []string{"watches=1"}},
+		{Dev, []string{"nofile=many"}},
+		{None, []string{"nofile=0"}},
+	} {
+		if _, err := Resolve(tc.profile, tc.opts); err == nil {
+			t.Errorf("Resolve(%q, %v) succeeded, want an error", tc.profile, tc.opts)
+		}
+	}
+}
+
+// recordingRunner records the commands and the files copied to a node, answering the checks of the kernel with missing
+type recordingRunner struct {
+	cmds    []string
+	files   map[string]string
+	missing string
+}
+
+func (r *recordingRunner) RunCmd(cmd *exec.Cmd) (*command.RunResult, error) {
+	r.cmds = append(r.cmds, strings.Join(cmd.Args, " "))
+	rr := &command.RunResult{Args: cmd.Args}
+	if cmd.Args[0] == "/bin/bash" {
+		rr.Stdout.WriteString(r.missing)
+	}
+	return rr, nil
+}
+
+func (r *recordingRunner) Copy(f assets.CopyableFile) error {
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	r.files[f.GetTargetPath()] = string(b)
+	return nil
+}
+
+func TestApply(t *testing.T) {
+	s, err := Resolve(Dev, nil)
+	if err != nil {
+		t.Fatalf("Resolve(dev): %v", err)
+	}
+
+	r := &recordingRunner{files: map[string]string{}}
+	if err := Apply(r, s, false); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if conf := r.files[sysctlPath]; !strings.Contains(conf, "fs.inotify.max_user_watches = 1048576\n") {
+		t.Errorf("sysctl config = %q, want the inotify limits", conf)
+	}
+	if d := r.files["/etc/systemd/system/containerd.service.d/99-minikube-tuning.conf"]; d != "[Service]\nLimitNOFILE=1048576\n" {
+		t.Errorf("containerd drop-in = %q", d)
+	}
+	if len(r.cmds) != 3 || !strings.Contains(r.cmds[0], "[ -e /proc/sys/fs/inotify/max_user_watches ] || echo sysctl fs.inotify.max_user_watches") {
+		t.Fatalf("commands = %q, want the sysctls checked first", r.cmds)
+	}
+	if got := strings.Join(r.cmds[1:], "; "); got != "sudo sysctl -p "+sysctlPath+"; sudo systemctl daemon-reload" {
+		t.Errorf("commands = %q", got)
+	}
+
+	r = &recordingRunner{files: map[string]string{}}
+	if err := Apply(r, s, true); err != nil {
+		t.Fatalf("Apply sharing the kernel: %v", err)
+	}
+	if _, ok := r.files[sysctlPath]; ok {
+		t.Errorf("Apply set sysctls on a node sharing the kernel of the host")
+	}
+}
+
+func TestKernelSettings(t *testing.T) {
+	s, err := Resolve(None, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddSysctls([]string{"net.netfilter.nf_conntrack_max=262144", "net.ipv4.ip_forward=1"}); err != nil {
+		t.Fatalf("AddSysctls: %v", err)
+	}
+	if err := s.AddModules([]string{"nf_conntrack", "sctp", "sctp"}); err != nil {
+		t.Fatalf("AddModules: %v", err)
+	}
+	if want := "net.ipv4.ip_forward=1 net.netfilter.nf_conntrack_max=262144 modules=nf_conntrack,sctp"; s.String() != want {
+		t.Errorf("String() = %q, want %q", s.String(), want)
+	}
+	for _, invalid := range []string{"ip_forward=1", "net.ipv4.ip_forward", "net.ipv4.ip_forward=", "nofile=1024", "net.ipv4.ip_forward;reboot=1"} {
+		if err := s.AddSysctls([]string{invalid}); err == nil {
+			t.Errorf("AddSysctls(%q) succeeded, want an error", invalid)
+		}
+	}
+	if err := s.AddModules([]string{"sctp; reboot"}); err == nil {
+		t.Errorf("AddModules() of an invalid name succeeded")
+	}
+
+	r := &recordingRunner{files: map[string]string{}}
+	if err := Apply(r, s, false); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if len(r.cmds) != 4 || r.cmds[1] != "sudo modprobe -a nf_conntrack sctp" || !strings.Contains(r.cmds[2], "/proc/sys/net/netfilter/nf_conntrack_max") {
+		t.Errorf("commands = %q, want the modules loaded before the sysctls are checked", r.cmds)
+	}
+	if !strings.Contains(r.cmds[0], "modprobe -n sctp") {
+		t.Errorf("check = %q, want the modules looked up with modprobe", r.cmds[0])
+	}
+	if conf := r.files[modulesPath]; !strings.HasSuffix(conf, "\nnf_conntrack\nsctp\n") {
+		t.Errorf("modules config = %q", conf)
+	}
+
+	r = &recordingRunner{files: map[string]string{}, missing: "module sctp\n"}
+	err = Apply(r, s, true)
+	if e, ok := err.(*UnsupportedError); !ok || len(e.Modules) != 1 || e.Modules[0] != "sctp" {
+		t.Fatalf("Apply() = %v, want sctp unsupported", err)
+	}
+	if strings.Contains(r.cmds[0], "modprobe") || len(r.files) != 0 {
+		t.Errorf("Apply sharing the kernel looked up or loaded modules: %q", r.cmds)
+	}
+
+	r = &recordingRunner{files: map[string]string{}, missing: "sysctl net.ipv4.ip_forward\n"}
+	if err := Apply(r, Settings{Sysctls: map[string]string{"net.ipv4.ip_forward": "1"}}, false); err == nil || !strings.Contains(err.Error(), "sysctls: net.ipv4.ip_forward") {
+		t.Errorf("Apply() = %v, want the sysctl unsupported", err)
+	}
+	if _, ok := r.files[sysctlPath]; ok {
+		t.Errorf("Apply wrote an unsupported sysctl")
+	}
+}
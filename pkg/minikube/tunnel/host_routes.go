@@ -0,0 +1,220 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/localpath"
+)
+
+// HostRoute is a route to a cluster CIDR installed on the host by `minikube route add`, which stays until it is deleted
+type HostRoute struct {
+	CIDR    string `json:"cidr"`
+	Gateway string `json:"gateway"`
+}
+
+func (r HostRoute) String() string {
+	return fmt.Sprintf("%s -> %s", r.CIDR, r.Gateway)
+}
+
+func (r HostRoute) route() (*Route, error) {
+	_, cidr, err := net.ParseCIDR(r.CIDR)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse CIDR %s", r.CIDR)
+	}
+	gw := net.ParseIP(r.Gateway)
+	if gw == nil {
+		return nil, fmt.Errorf("invalid gateway IP %q", r.Gateway)
+	}
+	return &Route{DestCIDR: cidr, Gateway: gw}, nil
+}
+
+// PodRoutes returns the routes to the pod CIDR of each node, via the internal IP of the node
+func PodRoutes(nodes []core.Node) []HostRoute {
+	routes := []HostRoute{}
+	for _, n := range nodes {
+		ip := ""
+		for _, a := range n.Status.Addresses {
+			if a.Type == core.NodeInternalIP {
+				ip = a.Address
+				break
+			}
+		}
+		if ip == "" {
+			klog.Warningf("node %s has no internal IP, skipping its pod CIDR", n.Name)
+			continue
+		}
+		cidrs := n.Spec.PodCIDRs
+		if len(cidrs) == 0 && n.Spec.PodCIDR != "" {
+			cidrs = []string{n.Spec.PodCIDR}
+		}
+		for _, cidr := range cidrs {
+			routes = append(routes, HostRoute{CIDR: cidr, Gateway: ip})
+		}
+	}
+	return routes
+}
+
+// hostRoutesFile returns the path of the file recording the routes installed for a profile
+func hostRoutesFile(profile string) string {
+	return filepath.Join(localpath.Profile(profile), "routes.json")
+}
+
+// LoadHostRoutes returns the routes installed on the host for a profile
+func LoadHostRoutes(profile string) ([]HostRoute, error) {
+	return loadHostRoutes(hostRoutesFile(profile))
+}
+
+// AddHostRoutes installs the routes on the host and records them for the profile. It fails on conflicting routes.
+func AddHostRoutes(profile string, routes []HostRoute) error {
+	return addHostRoutes(&osRouter{}, hostRoutesFile(profile), routes)
+}
+
+// DeleteHostRoutes removes the routes installed on the host for a profile, and returns them
+func DeleteHostRoutes(profile string) ([]HostRoute, error) {
+	return deleteHostRoutes(&osRouter{}, hostRoutesFile(profile))
+}
+
+func loadHostRoutes(path string) ([]HostRoute, error) {
+	routes := []HostRoute{}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return routes, nil
+		}
+		return nil, errors.Wrapf(err, "read %s", path)
+	}
+	if err := json.Unmarshal(b, &routes); err != nil {
+		return nil, errors.Wrapf(err, "unmarshal %s", path)
+	}
+	return routes, nil
+}
+
+func saveHostRoutes(path string, routes []HostRoute) error {
+	if len(routes) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	b, err := json.MarshalIndent(routes, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0644)
+}
+
+func addHostRoutes(r router, path string, routes []HostRoute) error {
+	installed, err := loadHostRoutes(path)
+	if err != nil {
+		return err
+	}
+	for _, hr := range routes {
+		rt, err := hr.route()
+		if err != nil {
+			return err
+		}
+		if err := r.EnsureRouteIsAdded(rt); err != nil {
+			return errors.Wrapf(err, "add route %s", hr)
+		}
+		if !containsHostRoute(installed, hr) {
+			installed = append(installed, hr)
+		}
+		// record each route as soon as it is added, so that a later failure does not leak it
+		if err := saveHostRoutes(path, installed); err != nil {
+			return errors.Wrapf(err, "record route %s", hr)
+		}
+	}
+	return nil
+}
+
+func deleteHostRoutes(r router, path string) ([]HostRoute, error) {
+	installed, err := loadHostRoutes(path)
+	if err != nil {
+		return nil, err
+	}
+	deleted := []HostRoute{}
+	remaining := []HostRoute{}
+	errs := []string{}
+	for _, hr := range installed {
+		rt, err := hr.route()
+		if err == nil {
+			err = r.Cleanup(rt)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("delete route %s: %v", hr, err))
+			remaining = append(remaining, hr)
+			continue
+		}
+		deleted = append(deleted, hr)
+	}
+	if err := saveHostRoutes(path, remaining); err != nil {
+		return deleted, err
+	}
+	if len(errs) > 0 {
+		return deleted, errors.New(strings.Join(errs, "; "))
+	}
+	return deleted, nil
+}
+
+func containsHostRoute(routes []HostRoute, r HostRoute) bool {
+	for _, hr := range routes {
+		if hr == r {
+			return true
+		}
+	}
+	return false
+}
+
+// LocalConflicts returns the local addresses of the host which are inside the destination CIDR of a route,
+// as routing that CIDR to the cluster would make them unreachable
+func LocalConflicts(routes []HostRoute) ([]string, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil, errors.Wrap(err, "list interface addresses")
+	}
+	return localConflicts(routes, addrs), nil
+}
+
+func localConflicts(routes []HostRoute, addrs []net.Addr) []string {
+	conflicts := []string{}
+	for _, hr := range routes {
+		_, cidr, err := net.ParseCIDR(hr.CIDR)
+		if err != nil {
+			continue
+		}
+		for _, a := range addrs {
+			ipNet, ok := a.(*net.IPNet)
+			if !ok || ipNet.IP.IsLoopback() {
+				continue
+			}
+			if cidr.Contains(ipNet.IP) || ipNet.Contains(cidr.IP) {
+				conflicts = append(conflicts, fmt.Sprintf("%s overlaps the local network %s", hr.CIDR, ipNet))
+			}
+		}
+	}
+	return conflicts
+}
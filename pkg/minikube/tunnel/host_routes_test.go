@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tunnel
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	core "k8s.io/api/core/v1"
+	meta "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAddAndDeleteHostRoutes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routes.json")
+	r := &fakeRouter{}
+	routes := []HostRoute{
+		{CIDR: "10.96.0.0/12", Gateway: "192.168.49.2"},
+		{CIDR: "10.244.0.0/24", Gateway: "192.168.49.2"},
+	}
+
+	if err := addHostRoutes(r, path, routes); err != nil {
+		t.Fatalf("addHostRoutes() error = %v", err)
+	}
+	// adding the same routes again is a no-op
+	if err := addHostRoutes(r, path, routes); err != nil {
+		t.Fatalf("addHostRoutes() error = %v", err)
+	}
+	if len(r.rt) != 2 {
+		t.Errorf("expected 2 routes in the routing table, got %s", r.rt.String())
+	}
+	installed, err := loadHostRoutes(path)
+	if err != nil {
+		t.Fatalf("loadHostRoutes() error = %v", err)
+	}
+	if len(installed) != 2 {
+		t.Errorf("expected 2 recorded routes, got %v", installed)
+	}
+
+	// the same CIDR via another gateway conflicts
+	if err := addHostRoutes(r, path, []HostRoute{{CIDR: "10.96.0.0/12", Gateway: "192.168.49.3"}}); err == nil {
+		t.Errorf("addHostRoutes() with a conflicting route should have returned error, but didn't")
+	}
+
+	deleted, err := deleteHostRoutes(r, path)
+	if err != nil {
+		t.Fatalf("deleteHostRoutes() error = %v", err)
+	}
+	if len(deleted) != 2 || len(r.rt) != 0 {
+		t.Errorf("expected all routes to be deleted, deleted %v, left %s", deleted, r.rt.String())
+	}
+	if installed, _ := loadHostRoutes(path); len(installed) != 0 {
+		t.Errorf("expected no recorded routes after delete, got %v", installed)
+	}
+}
+
+func TestPodRoutes(t *testing.T) {
+	nodes := []core.Node{
+		{
+			ObjectMeta: meta.ObjectMeta{Name: "minikube"},
+			Spec:       core.NodeSpec{PodCIDR: "10.244.0.0/24", PodCIDRs: []string{"10.244.0.0/24"}},
+			Status:     core.NodeStatus{Addresses: []core.NodeAddress{{Type: core.NodeHostName, Address: "minikube"}, {Type: core.NodeInternalIP, Address: "192.168.49.2"}}},
+		},
+		{
+			ObjectMeta: meta.ObjectMeta{Name: "minikube-m02"},
+			Spec:       core.NodeSpec{PodCIDR: "10.244.1.0/24"},
+			Status:     core.NodeStatus{Addresses: []core.NodeAddress{{Type: core.NodeInternalIP, Address: "192.168.49.3"}}},
+		},
+		{
+			ObjectMeta: meta.ObjectMeta{Name: "no-ip"},
+			Spec:       core.NodeSpec{PodCIDR: "10.244.2.0/24"},
+		},
+	}
+	want := []HostRoute{{CIDR: "10.244.0.0/24", Gateway: "192.168.49.2"}, {CIDR: "10.244.1.0/24", Gateway: "192.168.49.3"}}
+	got := PodRoutes(nodes)
+	if len(got) != len(want) {
+		t.Fatalf("PodRoutes() = %v; want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("PodRoutes()[%d] = %v; want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLocalConflicts(t *testing.T) {
+	addrs := []net.Addr{
+		&net.IPNet{IP: net.ParseIP("127.0.0.1"), Mask: net.CIDRMask(8, 32)},
+		&net.IPNet{IP: net.ParseIP("10.100.0.5"), Mask: net.CIDRMask(16, 32)},
+	}
+	routes := []HostRoute{{CIDR: "10.96.0.0/12", Gateway: "192.168.49.2"}, {CIDR: "10.244.0.0/24", Gateway: "192.168.49.2"}}
+	got := localConflicts(routes, addrs)
+	if len(got) != 1 {
+		t.Errorf("expected only the service CIDR to conflict, got %v", got)
+	}
+}
@@ -71,6 +71,9 @@ func (t *ServiceTunnel) Start(svcName, namespace string) ([]string, error) {
 	for _, port := range t.sshConn.ports {
 		urls = append(urls, fmt.Sprintf("http://127.0.0.1:%d", port))
 	}
+	for _, relay := range t.sshConn.relays {
+		urls = append(urls, fmt.Sprintf("udp://127.0.0.1:%d", relay.port()))
+	}
 
 	return urls, nil
 }
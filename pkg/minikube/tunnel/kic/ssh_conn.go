@@ -37,11 +37,12 @@ type sshConn struct {
 	service        string
 	cmd            *exec.Cmd
 	ports          []int
+	relays         []*udpRelay
 	activeConn     bool
 	suppressStdOut bool
 }
 
-func createSSHConn(name, sshPort, sshKey, bindAddress string, resourcePorts []int32, resourceIP string, resourceName string) *sshConn {
+func createSSHConn(name, sshPort, sshKey, bindAddress string, resourcePorts []v1.ServicePort, resourceIP string, resourceName string) *sshConn {
 	// extract sshArgs
 	sshArgs := []string{
 		// TODO: document the options here
@@ -56,7 +57,23 @@ func createSSHConn(name, sshPort, sshKey, bindAddress string, resourcePorts []in
 
 	askForSudo := false
 	var privilegedPorts []int32
-	for _, port := range resourcePorts {
+	var relays []*udpRelay
+	for _, p := range resourcePorts {
+		port := p.Port
+		switch p.Protocol {
+		case v1.ProtocolUDP:
+			relay, err := newUDPRelay(sshPort, sshKey, bindAddress, int(port), resourceIP, port)
+			if err != nil {
+				out.WarningT("Unable to forward UDP port {{.port}} of {{.resource}}: {{.error}}", out.V{"port": port, "resource": resourceName, "error": err})
+				continue
+			}
+			relays = append(relays, relay)
+			continue
+		case v1.ProtocolSCTP:
+			out.WarningT("SCTP port {{.port}} of {{.resource}} cannot be forwarded over ssh", out.V{"port": port, "resource": resourceName})
+			continue
+		}
+
 		var arg string
 		if bindAddress == "" || bindAddress == "*" {
 			// bind on all interfaces
@@ -110,6 +127,7 @@ func createSSHConn(name, sshPort, sshKey, bindAddress string, resourcePorts []in
 		name:       name,
 		service:    resourceName,
 		cmd:        cmd,
+		relays:     relays,
 		activeConn: false,
 	}
 }
@@ -128,8 +146,25 @@ func createSSHConnWithRandomPorts(name, sshPort, sshKey string, svc *v1.Service)
 	}
 
 	usedPorts := make([]int, 0, len(svc.Spec.Ports))
+	var relays []*udpRelay
 
 	for _, port := range svc.Spec.Ports {
+		switch port.Protocol {
+		case v1.ProtocolUDP:
+			relay, err := newUDPRelay(sshPort, sshKey, "127.0.0.1", 0, svc.Spec.ClusterIP, port.Port)
+			if err != nil {
+				for _, r := range relays {
+					_ = r.stop()
+				}
+				return nil, err
+			}
+			relays = append(relays, relay)
+			continue
+		case v1.ProtocolSCTP:
+			out.WarningT("SCTP port {{.port}} of {{.resource}} cannot be forwarded over ssh", out.V{"port": port.Port, "resource": svc.Name})
+			continue
+		}
+
 		freeport, err := freeport.GetFreePort()
 		if err != nil {
 			return nil, err
@@ -153,6 +188,7 @@ func createSSHConnWithRandomPorts(name, sshPort, sshKey string, svc *v1.Service)
 		service:    svc.Name,
 		cmd:        cmd,
 		ports:      usedPorts,
+		relays:     relays,
 		activeConn: false,
 	}, nil
 }
@@ -170,6 +206,9 @@ func (c *sshConn) startAndWait() error {
 		return err
 	}
 	go logOutput(r, c.service)
+	for _, relay := range c.relays {
+		go relay.serve()
+	}
 
 	c.activeConn = true
 	// we ignore wait error because the process will be killed
@@ -192,6 +231,11 @@ func logOutput(r io.Reader, service string) {
 }
 
 func (c *sshConn) stop() error {
+	for _, relay := range c.relays {
+		if err := relay.stop(); err != nil {
+			klog.Warningf("failed to stop UDP relay of %s: %v", c.service, err)
+		}
+	}
 	if c.activeConn {
 		c.activeConn = false
 		if !c.suppressStdOut {
@@ -119,14 +119,8 @@ func (t *SSHTunnel) startConnection(svc v1.Service) {
 		return
 	}
 
-	resourcePorts := []int32{}
-
-	for _, port := range svc.Spec.Ports {
-		resourcePorts = append(resourcePorts, port.Port)
-	}
-
 	// create new ssh conn
-	newSSHConn := createSSHConn(uniqName, t.sshPort, t.sshKey, t.bindAddress, resourcePorts, svc.Spec.ClusterIP, svc.Name)
+	newSSHConn := createSSHConn(uniqName, t.sshPort, t.sshKey, t.bindAddress, svc.Spec.Ports, svc.Spec.ClusterIP, svc.Name)
 	t.conns[newSSHConn.name] = newSSHConn
 
 	go func() {
@@ -152,7 +146,7 @@ func (t *SSHTunnel) startConnectionIngress(ingress v1_networking.Ingress) {
 		return
 	}
 
-	resourcePorts := []int32{80, 443}
+	resourcePorts := []v1.ServicePort{{Port: 80}, {Port: 443}}
 	resourceIP := "127.0.0.1"
 
 	// create new ssh conn
@@ -198,7 +192,7 @@ func sshConnUniqName(service v1.Service) string {
 	}
 
 	for _, port := range service.Spec.Ports {
-		n = append(n, fmt.Sprintf("-%d", port.Port))
+		n = append(n, fmt.Sprintf("-%d%s", port.Port, port.Protocol))
 	}
 
 	return strings.Join(n, "")
@@ -0,0 +1,204 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kic
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"os/exec"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// udpIdleTimeout is how long, in seconds, the relay keeps the session of a client sending no datagram
+const udpIdleTimeout = 60
+
+// udpFramer relays the datagrams between the UDP socket of the target and stdio, each prefixed by its length
+// on 2 bytes so that they keep their boundaries over the ssh stream. It runs with perl, which is part of the node image,
+// and exits once no datagram went through for the timeout.
+const udpFramer = `use IO::Socket::INET; use IO::Select;
+my ($target, $timeout) = @ARGV;
+binmode STDIN; binmode STDOUT;
+my $s = IO::Socket::INET->new(Proto => "udp", PeerAddr => $target) or die "$target: $!";
+my $sel = IO::Select->new(\*STDIN, $s);
+sub readn { my $n = shift; my $d = ""; while (length $d < $n) { sysread(STDIN, $d, $n - length $d, length $d) > 0 or exit } $d }
+while (my @ready = $sel->can_read($timeout)) {
+  for my $h (@ready) {
+    if ($h == $s) { defined $s->recv(my $d, 65535) or next; syswrite STDOUT, pack("n", length $d) . $d }
+    else { $s->send(readn(unpack "n", readn(2))) }
+  }
+}`
+
+// udpRelay forwards the datagrams of a UDP port of the host to a UDP port in the cluster.
+// ssh only forwards TCP, so each client of the port gets an ssh session running udpFramer in the node,
+// which sends the datagrams of the client to the target and writes back the replies.
+type udpRelay struct {
+	conn    *net.UDPConn
+	sshArgs []string
+	target  string
+
+	mu    sync.Mutex
+	peers map[string]*udpPeer
+}
+
+// udpPeer is the session of a client of the relay
+type udpPeer struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+}
+
+// newUDPRelay listens on port of bindAddress, 0 for a random port, to relay its datagrams to the port of targetIP
+func newUDPRelay(sshPort, sshKey, bindAddress string, port int, targetIP string, targetPort int32) (*udpRelay, error) {
+	addr := &net.UDPAddr{Port: port}
+	if bindAddress != "" && bindAddress != "*" {
+		addr.IP = net.ParseIP(bindAddress)
+		if addr.IP == nil {
+			return nil, errors.Errorf("invalid bind address %q", bindAddress)
+		}
+	}
+	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "listen on UDP port %d", port)
+	}
+	return &udpRelay{
+		conn: conn,
+		sshArgs: []string{
+			"-o", "UserKnownHostsFile=/dev/null",
+			"-o", "StrictHostKeyChecking=no",
+			"-o", "IdentitiesOnly=yes",
+			"-p", sshPort,
+			"-i", sshKey,
+			"docker@127.0.0.1",
+		},
+		target: net.JoinHostPort(targetIP, strconv.Itoa(int(targetPort))),
+		peers:  make(map[string]*udpPeer),
+	}, nil
+}
+
+// port returns the UDP port of the host the relay listens on
+func (r *udpRelay) port() int {
+	return r.conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// serve relays the datagrams until the relay is stopped
+func (r *udpRelay) serve() {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := r.conn.ReadFromUDP(buf)
+		if err != nil {
+			// the relay was stopped
+			return
+		}
+		stdin, err := r.peer(addr)
+		if err != nil {
+			klog.Warningf("unable to relay UDP datagrams of %s to %s: %v", addr, r.target, err)
+			continue
+		}
+		if err := writeDatagram(stdin, buf[:n]); err != nil {
+			klog.Warningf("failed to relay UDP datagram of %s to %s: %v", addr, r.target, err)
+		}
+	}
+}
+
+// peer returns the stdin of the session of the client at addr, starting it if needed
+func (r *udpRelay) peer(addr *net.UDPAddr) (io.Writer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := addr.String()
+	if p, ok := r.peers[key]; ok {
+		return p.stdin, nil
+	}
+
+	// the session ends once nothing went through for udpIdleTimeout, and the next datagram starts a new one
+	args := append(append([]string{}, r.sshArgs...), "perl", "-e", "'"+udpFramer+"'", r.target, strconv.Itoa(udpIdleTimeout))
+	cmd := exec.Command("ssh", args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	p := &udpPeer{cmd: cmd, stdin: stdin}
+	r.peers[key] = p
+
+	go func() {
+		replies := bufio.NewReader(stdout)
+		for {
+			d, err := readDatagram(replies)
+			if err != nil {
+				break
+			}
+			if _, err := r.conn.WriteToUDP(d, addr); err != nil {
+				klog.Warningf("failed to relay UDP reply of %s to %s: %v", r.target, addr, err)
+			}
+		}
+		_ = cmd.Wait()
+		r.mu.Lock()
+		if r.peers[key] == p {
+			delete(r.peers, key)
+		}
+		r.mu.Unlock()
+	}()
+	return stdin, nil
+}
+
+// writeDatagram writes the datagram prefixed by its length
+func writeDatagram(w io.Writer, d []byte) error {
+	b := make([]byte, 2+len(d))
+	binary.BigEndian.PutUint16(b, uint16(len(d)))
+	copy(b[2:], d)
+	_, err := w.Write(b)
+	return err
+}
+
+// readDatagram reads a datagram written by writeDatagram
+func readDatagram(r io.Reader) ([]byte, error) {
+	var l [2]byte
+	if _, err := io.ReadFull(r, l[:]); err != nil {
+		return nil, err
+	}
+	d := make([]byte, binary.BigEndian.Uint16(l[:]))
+	if _, err := io.ReadFull(r, d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// stop closes the port and ends the sessions of the clients
+func (r *udpRelay) stop() error {
+	err := r.conn.Close()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, p := range r.peers {
+		if p.cmd.Process != nil {
+			_ = p.cmd.Process.Kill()
+		}
+		delete(r.peers, key)
+	}
+	return err
+}
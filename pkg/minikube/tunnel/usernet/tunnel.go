@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usernet
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	v1_networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typed_core "k8s.io/client-go/kubernetes/typed/core/v1"
+	typed_networking "k8s.io/client-go/kubernetes/typed/networking/v1"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/drivers/qemu"
+	"k8s.io/minikube/pkg/minikube/tunnel"
+)
+
+// Tunnel forwards the ports of LoadBalancer services and ingresses to the host, until its context is done
+type Tunnel struct {
+	ctx                  context.Context
+	fwd                  Forwarder
+	bindAddress          string
+	v1Core               typed_core.CoreV1Interface
+	v1Networking         typed_networking.NetworkingV1Interface
+	LoadBalancerEmulator tunnel.LoadBalancerEmulator
+	// forwards are the forwards added for each service and ingress, by unique name
+	forwards map[string][]qemu.PortForward
+}
+
+// NewTunnel creates a tunnel forwarding ports through fwd, on bindAddress or 127.0.0.1
+func NewTunnel(ctx context.Context, fwd Forwarder, bindAddress string, v1Core typed_core.CoreV1Interface, v1Networking typed_networking.NetworkingV1Interface) *Tunnel {
+	if bindAddress == "" {
+		bindAddress = "127.0.0.1"
+	}
+	return &Tunnel{
+		ctx:                  ctx,
+		fwd:                  fwd,
+		bindAddress:          bindAddress,
+		v1Core:               v1Core,
+		v1Networking:         v1Networking,
+		LoadBalancerEmulator: tunnel.NewLoadBalancerEmulator(v1Core),
+		forwards:             make(map[string][]qemu.PortForward),
+	}
+}
+
+// Start forwards the ports until the context is done, then removes the forwards
+func (t *Tunnel) Start() error {
+	for {
+		select {
+		case <-t.ctx.Done():
+			_, err := t.LoadBalancerEmulator.Cleanup()
+			if err != nil {
+				klog.Errorf("error cleaning up: %v", err)
+			}
+			for name, forwards := range t.forwards {
+				Remove(t.fwd, forwards)
+				delete(t.forwards, name)
+			}
+			return err
+		default:
+		}
+
+		services, err := t.v1Core.Services("").List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			klog.Errorf("error listing services: %v", err)
+			services = &v1.ServiceList{}
+		}
+		ingresses, err := t.v1Networking.Ingresses("").List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			klog.Errorf("error listing ingresses: %v", err)
+			ingresses = &v1_networking.IngressList{}
+		}
+
+		want := make(map[string][]qemu.PortForward)
+		for _, svc := range services.Items {
+			if svc.Spec.Type == v1.ServiceTypeLoadBalancer {
+				want[serviceName(svc)] = LoadBalancerForwards(svc, t.bindAddress)
+			}
+		}
+		for _, ingress := range ingresses.Items {
+			want["ingress/"+ingress.Namespace+"/"+ingress.Name] = []qemu.PortForward{
+				{HostAddr: t.bindAddress, HostPort: 80, GuestPort: 80},
+				{HostAddr: t.bindAddress, HostPort: 443, GuestPort: 443},
+			}
+		}
+		t.update(want, services.Items)
+
+		time.Sleep(1 * time.Second)
+	}
+}
+
+// update removes the forwards of the services gone, and adds the missing ones.
+// Forwards are re-added when missing as a restart of the VM drops them.
+func (t *Tunnel) update(want map[string][]qemu.PortForward, services []v1.Service) {
+	for name, forwards := range t.forwards {
+		if _, ok := want[name]; !ok {
+			Remove(t.fwd, forwards)
+			delete(t.forwards, name)
+		}
+	}
+
+	var all []qemu.PortForward
+	for _, forwards := range want {
+		all = append(all, forwards...)
+	}
+	if err := Ensure(t.fwd, all); err != nil {
+		klog.Errorf("error forwarding ports: %v", err)
+	}
+
+	for _, svc := range services {
+		name := serviceName(svc)
+		if _, ok := want[name]; !ok {
+			continue
+		}
+		if _, ok := t.forwards[name]; ok {
+			continue
+		}
+		if err := t.LoadBalancerEmulator.PatchServiceIP(t.v1Core.RESTClient(), svc, "127.0.0.1"); err != nil {
+			klog.Errorf("error patching service: %v", err)
+		}
+	}
+	for name, forwards := range want {
+		t.forwards[name] = forwards
+	}
+}
+
+// serviceName is unique for the ports of the service, so that changing them replaces its forwards
+func serviceName(svc v1.Service) string {
+	n := fmt.Sprintf("service/%s/%s", svc.Namespace, svc.Name)
+	for _, p := range svc.Spec.Ports {
+		n += fmt.Sprintf("-%d:%d/%s", p.Port, p.NodePort, p.Protocol)
+	}
+	return n
+}
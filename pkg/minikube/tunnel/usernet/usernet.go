@@ -0,0 +1,119 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package usernet forwards the ports of services to the host, for VMs on a user mode network
+// (the builtin network of QEMU) the host cannot route to.
+package usernet
+
+import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/drivers/qemu"
+)
+
+// Forwarder forwards ports of the host to the VM
+type Forwarder interface {
+	AddPortForward(f qemu.PortForward) error
+	RemovePortForward(f qemu.PortForward) error
+	PortForwards() ([]qemu.PortForward, error)
+}
+
+// NodePortForwards returns the forwards of the TCP and UDP NodePorts of a service, on the same ports of the host
+func NodePortForwards(svc v1.Service, bindAddress string) []qemu.PortForward {
+	var forwards []qemu.PortForward
+	for _, p := range svc.Spec.Ports {
+		protocol, ok := forwardProtocol(p)
+		if p.NodePort == 0 || !ok {
+			continue
+		}
+		forwards = append(forwards, qemu.PortForward{Protocol: protocol, HostAddr: bindAddress, HostPort: int(p.NodePort), GuestPort: int(p.NodePort)})
+	}
+	return forwards
+}
+
+// LoadBalancerForwards returns the forwards of the TCP and UDP ports of a LoadBalancer service to its NodePorts
+func LoadBalancerForwards(svc v1.Service, bindAddress string) []qemu.PortForward {
+	var forwards []qemu.PortForward
+	for _, p := range svc.Spec.Ports {
+		protocol, ok := forwardProtocol(p)
+		if p.NodePort == 0 || !ok {
+			continue
+		}
+		forwards = append(forwards, qemu.PortForward{Protocol: protocol, HostAddr: bindAddress, HostPort: int(p.Port), GuestPort: int(p.NodePort)})
+	}
+	return forwards
+}
+
+// forwardProtocol returns the protocol of the forward of a port, false for SCTP which the builtin network of QEMU does not carry
+func forwardProtocol(p v1.ServicePort) (string, bool) {
+	switch p.Protocol {
+	case v1.ProtocolUDP:
+		return "udp", true
+	case v1.ProtocolSCTP:
+		klog.Warningf("not forwarding SCTP port %d: the user mode network only forwards TCP and UDP", p.Port)
+		return "", false
+	default:
+		return "", true
+	}
+}
+
+// Ensure adds the forwards missing from the VM, replacing the ones of the same host port to another guest port.
+// It tries every forward, and returns the first error.
+func Ensure(fwd Forwarder, want []qemu.PortForward) error {
+	if len(want) == 0 {
+		return nil
+	}
+	have, err := fwd.PortForwards()
+	if err != nil {
+		return err
+	}
+	var firstErr error
+	for _, f := range want {
+		exists := false
+		for _, h := range have {
+			if h.Proto() != f.Proto() || h.HostAddr != f.HostAddr || h.HostPort != f.HostPort {
+				continue
+			}
+			if h.GuestPort == f.GuestPort {
+				exists = true
+				break
+			}
+			klog.Infof("replacing port forward %s with %s", h, f)
+			if err := fwd.RemovePortForward(h); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		if exists {
+			continue
+		}
+		klog.Infof("adding port forward %s", f)
+		if err := fwd.AddPortForward(f); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Remove removes forwards from the VM, logging the failures
+func Remove(fwd Forwarder, forwards []qemu.PortForward) {
+	for _, f := range forwards {
+		klog.Infof("removing port forward %s", f)
+		if err := fwd.RemovePortForward(f); err != nil {
+			klog.Warningf("failed to remove port forward %s: %v", f, err)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usernet
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+
+	"k8s.io/minikube/pkg/drivers/qemu"
+)
+
+// fakeForwarder keeps the forwards of the VM in memory, like QEMU refusing busy host ports
+type fakeForwarder struct {
+	forwards []qemu.PortForward
+	busy     map[int]bool
+}
+
+func (f *fakeForwarder) AddPortForward(fwd qemu.PortForward) error {
+	if f.busy[fwd.HostPort] {
+		return fmt.Errorf("could not set up host forwarding rule %s", fwd)
+	}
+	f.forwards = append(f.forwards, fwd)
+	return nil
+}
+
+func (f *fakeForwarder) RemovePortForward(fwd qemu.PortForward) error {
+	for i, h := range f.forwards {
+		if h.Proto() == fwd.Proto() && h.HostAddr == fwd.HostAddr && h.HostPort == fwd.HostPort {
+			f.forwards = append(f.forwards[:i], f.forwards[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%s not found", fwd)
+}
+
+func (f *fakeForwarder) PortForwards() ([]qemu.PortForward, error) {
+	return append([]qemu.PortForward{}, f.forwards...), nil
+}
+
+func (f *fakeForwarder) sorted() []qemu.PortForward {
+	sort.Slice(f.forwards, func(i, j int) bool { return f.forwards[i].HostPort < f.forwards[j].HostPort })
+	return f.forwards
+}
+
+func TestForwards(t *testing.T) {
+	svc := v1.Service{Spec: v1.ServiceSpec{Ports: []v1.ServicePort{
+		{Port: 80, NodePort: 30080},
+		{Port: 53, NodePort: 30053, Protocol: v1.ProtocolUDP},
+		{Port: 9999, NodePort: 30099, Protocol: v1.ProtocolSCTP},
+		{Port: 8080},
+	}}}
+
+	want := []qemu.PortForward{
+		{HostAddr: "127.0.0.1", HostPort: 30080, GuestPort: 30080},
+		{Protocol: "udp", HostAddr: "127.0.0.1", HostPort: 30053, GuestPort: 30053},
+	}
+	if got := NodePortForwards(svc, "127.0.0.1"); !reflect.DeepEqual(got, want) {
+		t.Errorf("NodePortForwards() = %v, want %v", got, want)
+	}
+
+	want = []qemu.PortForward{
+		{HostAddr: "", HostPort: 80, GuestPort: 30080},
+		{Protocol: "udp", HostAddr: "", HostPort: 53, GuestPort: 30053},
+	}
+	if got := LoadBalancerForwards(svc, ""); !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadBalancerForwards() = %v, want %v", got, want)
+	}
+}
+
+func TestEnsure(t *testing.T) {
+	ssh := qemu.PortForward{HostPort: 50022, GuestPort: 22}
+	fwd := &fakeForwarder{
+		forwards: []qemu.PortForward{ssh, {HostAddr: "127.0.0.1", HostPort: 8080, GuestPort: 30001}},
+		busy:     map[int]bool{80: true},
+	}
+
+	err := Ensure(fwd, []qemu.PortForward{
+		{HostAddr: "127.0.0.1", HostPort: 80, GuestPort: 30000},
+		{HostAddr: "127.0.0.1", HostPort: 8080, GuestPort: 30002},
+		{HostAddr: "127.0.0.1", HostPort: 30003, GuestPort: 30003},
+	})
+	if err == nil {
+		t.Errorf("Ensure succeeded with a busy host port")
+	}
+	want := []qemu.PortForward{
+		{HostAddr: "127.0.0.1", HostPort: 8080, GuestPort: 30002},
+		{HostAddr: "127.0.0.1", HostPort: 30003, GuestPort: 30003},
+		ssh,
+	}
+	if got := fwd.sorted(); !reflect.DeepEqual(got, want) {
+		t.Errorf("forwards after Ensure = %v, want %v", got, want)
+	}
+
+	// already there
+	if err := Ensure(fwd, want[:2]); err != nil {
+		t.Errorf("Ensure: %v", err)
+	}
+	if len(fwd.forwards) != 3 {
+		t.Errorf("Ensure added existing forwards: %v", fwd.forwards)
+	}
+
+	Remove(fwd, want[:2])
+	if got := fwd.sorted(); !reflect.DeepEqual(got, []qemu.PortForward{ssh}) {
+		t.Errorf("forwards after Remove = %v, want %v", got, []qemu.PortForward{ssh})
+	}
+}
+
+func TestTunnelUpdate(t *testing.T) {
+	fwd := &fakeForwarder{}
+	tun := NewTunnel(context.Background(), fwd, "", nil, nil)
+
+	ingress := []qemu.PortForward{
+		{HostAddr: "127.0.0.1", HostPort: 80, GuestPort: 80},
+		{HostAddr: "127.0.0.1", HostPort: 443, GuestPort: 443},
+	}
+	tun.update(map[string][]qemu.PortForward{"ingress/default/web": ingress}, nil)
+	if got := fwd.sorted(); !reflect.DeepEqual(got, ingress) {
+		t.Errorf("forwards = %v, want %v", got, ingress)
+	}
+
+	// the VM restarted
+	fwd.forwards = nil
+	tun.update(map[string][]qemu.PortForward{"ingress/default/web": ingress}, nil)
+	if got := fwd.sorted(); !reflect.DeepEqual(got, ingress) {
+		t.Errorf("forwards after a restart = %v, want %v", got, ingress)
+	}
+
+	tun.update(map[string][]qemu.PortForward{}, nil)
+	if len(fwd.forwards) != 0 || len(tun.forwards) != 0 {
+		t.Errorf("forwards of a deleted ingress remain: %v, %v", fwd.forwards, tun.forwards)
+	}
+}
@@ -25,6 +25,8 @@ const (
 	GuestEphemeralDir = "/var/tmp/minikube"
 	// GuestPersistentDir is the path where persistent data should be stored within the VM (not tmpfs)
 	GuestPersistentDir = "/var/lib/minikube"
+	// GuestGatekeeperPolicyDir is where the policy directory of the gatekeeper addon is mounted
+	GuestGatekeeperPolicyDir = GuestPersistentDir + "/gatekeeper-policies"
 	// GuestKubernetesCertsDir are where Kubernetes certificates are stored
 	GuestKubernetesCertsDir = GuestPersistentDir + "/certs"
 	// GuestCertAuthDir is where system CA certificates are installed to
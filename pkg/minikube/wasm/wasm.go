@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package wasm installs the WebAssembly shims of containerd into the nodes, and the RuntimeClass running pods with them
+package wasm
+
+import (
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+const (
+	// WasmEdge is the runwasi shim running the modules with WasmEdge
+	WasmEdge = "wasmedge"
+	// Wasmtime is the runwasi shim running the modules with wasmtime
+	Wasmtime = "wasmtime"
+
+	// RuntimeClassName is the RuntimeClass of the pods running WebAssembly modules
+	RuntimeClassName = "wasm"
+	// ShimVersion is the release of the runwasi shims installed into the nodes
+	ShimVersion = "v0.5.0"
+
+	containerdConfigFile = "/etc/containerd/config.toml"
+	shimDir              = "/usr/local/bin"
+)
+
+// Runtimes are the valid wasm runtimes
+var Runtimes = []string{WasmEdge, Wasmtime}
+
+// shimArchs maps the architectures of the nodes to the ones of the release tarballs
+var shimArchs = map[string]string{
+	"amd64": "x86_64",
+	"arm64": "aarch64",
+}
+
+// Valid returns whether rt is a wasm runtime
+func Valid(rt string) bool {
+	for _, r := range Runtimes {
+		if r == rt {
+			return true
+		}
+	}
+	return false
+}
+
+// ShimURL returns the URL of the release tarball of the shim of rt for the architecture arch
+func ShimURL(rt, arch string) (string, error) {
+	a, ok := shimArchs[arch]
+	if !ok {
+		return "", fmt.Errorf("the %s shim has no release for the %s architecture", rt, arch)
+	}
+	return fmt.Sprintf("https://github.com/containerd/runwasi/releases/download/containerd-shim-%s/%s/containerd-shim-%s-%s-linux-musl.tar.gz", rt, ShimVersion, rt, a), nil
+}
+
+// ShimBinary returns the name of the shim binary of rt, which containerd finds from the runtime type of the handler
+func ShimBinary(rt string) string {
+	return "containerd-shim-" + rt + "-v1"
+}
+
+// withContainerdRuntime returns the containerd config with the runtime handler rt, and whether it was added
+func withContainerdRuntime(cfg, rt string) (string, bool) {
+	if strings.Contains(cfg, "runtimes."+rt+"]") {
+		return cfg, false
+	}
+	handler := fmt.Sprintf("\n[plugins.\"io.containerd.grpc.v1.cri\".containerd.runtimes.%s]\n  runtime_type = \"io.containerd.%s.v1\"\n", rt, rt)
+	return strings.TrimRight(cfg, "\n") + "\n" + handler, true
+}
+
+// RuntimeClass returns the RuntimeClass running the pods selecting it with the handler of rt
+func RuntimeClass(rt string) string {
+	return fmt.Sprintf(`apiVersion: node.k8s.io/v1
+kind: RuntimeClass
+metadata:
+  name: %s
+  labels:
+    app.kubernetes.io/managed-by: minikube
+handler: %s
+`, RuntimeClassName, rt)
+}
+
+// Install extracts the shim of rt from the release tarball into the node, and registers its runtime handler with containerd.
+// It is done on every start, as the filesystem of the VM is not persistent.
+// containerd must be restarted afterwards for the handler to take effect.
+func Install(r command.Runner, rt, tarball string) error {
+	name := filepath.Base(tarball)
+	f, err := assets.NewFileAsset(tarball, "/tmp", name, "0644")
+	if err != nil {
+		return errors.Wrap(err, "open shim tarball")
+	}
+	defer func() {
+		if err := f.Close(); err != nil {
+			klog.Warningf("error closing the file %s: %v", f.GetSourcePath(), err)
+		}
+	}()
+	if err := r.Copy(f); err != nil {
+		return errors.Wrap(err, "copy shim tarball")
+	}
+	dst := path.Join("/tmp", name)
+	if _, err := r.RunCmd(exec.Command("sudo", "tar", "-xzf", dst, "-C", shimDir, ShimBinary(rt))); err != nil {
+		return errors.Wrapf(err, "extract %s", ShimBinary(rt))
+	}
+	if _, err := r.RunCmd(exec.Command("sudo", "rm", "-f", dst)); err != nil {
+		return errors.Wrap(err, "remove shim tarball")
+	}
+
+	rr, err := r.RunCmd(exec.Command("sudo", "cat", containerdConfigFile))
+	if err != nil {
+		return errors.Wrap(err, "read containerd config")
+	}
+	cfg, changed := withContainerdRuntime(rr.Stdout.String(), rt)
+	if !changed {
+		return nil
+	}
+	if err := r.Copy(assets.NewMemoryAssetTarget([]byte(cfg), containerdConfigFile, "0644")); err != nil {
+		return errors.Wrap(err, "write containerd config")
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wasm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestShimURL(t *testing.T) {
+	got, err := ShimURL(Wasmtime, "arm64")
+	if err != nil {
+		t.Fatalf("ShimURL: %v", err)
+	}
+	want := "https://github.com/containerd/runwasi/releases/download/containerd-shim-wasmtime/" + ShimVersion + "/containerd-shim-wasmtime-aarch64-linux-musl.tar.gz"
+	if got != want {
+		t.Errorf("ShimURL() = %q, want %q", got, want)
+	}
+	if _, err := ShimURL(WasmEdge, "s390x"); err == nil {
+		t.Errorf("ShimURL() of an architecture without release did not fail")
+	}
+	if !Valid(WasmEdge) || Valid("wasmer") {
+		t.Errorf("Valid() does not match the runtimes")
+	}
+}
+
+func TestWithContainerdRuntime(t *testing.T) {
+	cfg := "version = 2\n\n[plugins.\"io.containerd.grpc.v1.cri\".containerd.runtimes.runc]\n  runtime_type = \"io.containerd.runc.v2\"\n"
+	got, changed := withContainerdRuntime(cfg, WasmEdge)
+	if !changed {
+		t.Fatalf("withContainerdRuntime() did not add the handler")
+	}
+	if !strings.HasPrefix(got, cfg) || !strings.HasSuffix(got, "[plugins.\"io.containerd.grpc.v1.cri\".containerd.runtimes.wasmedge]\n  runtime_type = \"io.containerd.wasmedge.v1\"\n") {
+		t.Errorf("withContainerdRuntime() = %q", got)
+	}
+	if again, changed := withContainerdRuntime(got, WasmEdge); changed || again != got {
+		t.Errorf("withContainerdRuntime() added the handler twice")
+	}
+	if !strings.Contains(RuntimeClass(WasmEdge), "name: wasm\n") || !strings.HasSuffix(RuntimeClass(WasmEdge), "handler: wasmedge\n") {
+		t.Errorf("RuntimeClass() = %q", RuntimeClass(WasmEdge))
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windows
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// Taint keeps the Linux workloads off the Windows nodes, the pods of the windows RuntimeClass tolerate it
+	Taint = "os=windows:NoSchedule"
+
+	// criSocket is the named pipe of containerd on Windows
+	criSocket = "npipe:////./pipe/containerd-containerd"
+	// kubeadm is where PrepareNode.ps1 installs the Kubernetes binaries
+	kubeadm = `C:\k\kubeadm.exe`
+	// toolsRef is the release of sig-windows-tools whose node preparation scripts are run
+	toolsRef = "v0.1.6"
+	// toolsURL hosts the node preparation scripts of sig-windows-tools
+	toolsURL = "https://raw.githubusercontent.com/kubernetes-sigs/sig-windows-tools/" + toolsRef + "/hostprocess"
+)
+
+// RuntimeClass schedules the pods selecting it on the Windows nodes, with the process isolated runtime of containerd
+const RuntimeClass = `apiVersion: node.k8s.io/v1
+kind: RuntimeClass
+metadata:
+  name: windows
+handler: runhcs-wcow-process
+scheduling:
+  nodeSelector:
+    kubernetes.io/os: windows
+  tolerations:
+  - key: os
+    operator: Equal
+    value: windows
+    effect: NoSchedule
+`
+
+// JoinCommand turns the kubeadm join command generated for the Linux nodes into the one of the Windows node
+func JoinCommand(linuxJoin, nodeName string) (string, error) {
+	fields := strings.Fields(linuxJoin)
+	start := -1
+	for i, f := range fields {
+		if f == "join" {
+			start = i + 1
+			break
+		}
+	}
+	if start < 0 {
+		return "", fmt.Errorf("not a kubeadm join command: %q", linuxJoin)
+	}
+	args := []string{kubeadm, "join"}
+	for i := start; i < len(fields); i++ {
+		if fields[i] == "--cri-socket" {
+			i++
+			continue
+		}
+		args = append(args, fields[i])
+	}
+	args = append(args, "--cri-socket", criSocket, "--node-name", nodeName)
+	return strings.Join(args, " "), nil
+}
+
+// JoinScript returns the PowerShell script joining the VM to the cluster: it resolves the control plane endpoint,
+// installs containerd and the Kubernetes binaries with the scripts of sig-windows-tools when they are missing, then runs kubeadm join
+func JoinScript(k8sVersion, controlPlaneIP, joinCmd string) string {
+	return fmt.Sprintf(`$ErrorActionPreference = 'Stop'
+$ProgressPreference = 'SilentlyContinue'
+$hosts = 'C:\Windows\System32\drivers\etc\hosts'
+if (-not (Select-String -Quiet -Path $hosts -Pattern 'control-plane.minikube.internal')) {
+  Add-Content -Path $hosts -Value "%s control-plane.minikube.internal"
+}
+if (-not (Get-Service containerd -ErrorAction SilentlyContinue)) {
+  Invoke-WebRequest -UseBasicParsing -Uri %s/Install-Containerd.ps1 -OutFile C:\Install-Containerd.ps1
+  C:\Install-Containerd.ps1
+}
+if (-not (Test-Path %s)) {
+  Invoke-WebRequest -UseBasicParsing -Uri %s/PrepareNode.ps1 -OutFile C:\PrepareNode.ps1
+  C:\PrepareNode.ps1 -KubernetesVersion %s
+}
+& %s
+if ($LASTEXITCODE) { exit $LASTEXITCODE }
+`, controlPlaneIP, toolsURL, kubeadm, toolsURL, k8sVersion, joinCmd)
+}
@@ -0,0 +1,302 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package windows manages the VMs of the experimental Windows Server worker nodes, which run on the hyperv or virtualbox driver next to the Linux machines of the cluster
+package windows
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/docker/machine/libmachine/state"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+	"k8s.io/minikube/pkg/minikube/driver"
+)
+
+// VM manages the Windows VMs of a driver
+type VM interface {
+	// Create creates the VM from a copy of the image in dir, attached to the network of the cluster
+	Create(name, image, dir string, cpus, memory int) error
+	Start(name string) error
+	Stop(name string) error
+	Delete(name string) error
+	State(name string) (state.State, error)
+	// IP returns the IPv4 address of the VM on the network of the cluster, reported by the guest services
+	IP(name string) (string, error)
+	// Run runs the PowerShell script in the VM through the guest channel of the hypervisor, with the credentials of a local administrator
+	Run(name, user, password, script string) (string, error)
+}
+
+// Supported returns whether the driver can run Windows nodes
+func Supported(drv string) bool {
+	return drv == driver.HyperV || drv == driver.VirtualBox
+}
+
+// NewVM returns the VM manager of the driver, which attaches the VMs to the network of the cluster machine
+func NewVM(drv, clusterMachine string) (VM, error) {
+	switch drv {
+	case driver.HyperV:
+		return &hypervVM{cluster: clusterMachine}, nil
+	case driver.VirtualBox:
+		return &virtualboxVM{cluster: clusterMachine}, nil
+	default:
+		return nil, fmt.Errorf("the %s driver does not support Windows nodes, use hyperv or virtualbox", drv)
+	}
+}
+
+// ipv4Regexp matches the IPv4 addresses reported by the guest services
+var ipv4Regexp = regexp.MustCompile(`\b\d{1,3}(\.\d{1,3}){3}\b`)
+
+// psQuote quotes the string for PowerShell
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// encodeCommand encodes the script for powershell -EncodedCommand, which takes base64 of UTF-16LE
+func encodeCommand(script string) string {
+	var b []byte
+	for _, r := range utf16.Encode([]rune(script)) {
+		b = append(b, byte(r), byte(r>>8))
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// run runs the command on the host, logging only its subcommand
+func run(name string, args ...string) (string, error) {
+	return runStdin("", name, args...)
+}
+
+// runStdin runs the command on the host with stdin, which keeps secrets such as the password of the VM off the command line
+func runStdin(stdin, name string, args ...string) (string, error) {
+	klog.Infof("Run: %s %s ...", name, args[0])
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return string(out), errors.Wrapf(err, "%s %s: %s", filepath.Base(name), args[0], strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
+
+// hypervVM manages the VMs with the Hyper-V cmdlets, and runs the scripts over PowerShell Direct
+type hypervVM struct {
+	cluster string
+}
+
+func (h *hypervVM) ps(cmd string) (string, error) {
+	return run("powershell.exe", "-NoProfile", "-NonInteractive", "-Command", cmd)
+}
+
+func (h *hypervVM) Create(name, image, dir string, cpus, memory int) error {
+	disk := filepath.Join(dir, name+".vhdx")
+	_, err := h.ps(fmt.Sprintf(`Copy-Item -Path %s -Destination %s; `+
+		`$switch = (Get-VMNetworkAdapter -VMName %s | Select-Object -First 1).SwitchName; `+
+		`New-VM -Name %s -Generation 2 -MemoryStartupBytes %dMB -VHDPath %s -SwitchName $switch | Out-Null; `+
+		`Set-VMProcessor -VMName %s -Count %d`,
+		psQuote(image), psQuote(disk), psQuote(h.cluster), psQuote(name), memory, psQuote(disk), psQuote(name), cpus))
+	return err
+}
+
+func (h *hypervVM) Start(name string) error {
+	_, err := h.ps(fmt.Sprintf("Start-VM -Name %s", psQuote(name)))
+	return err
+}
+
+func (h *hypervVM) Stop(name string) error {
+	_, err := h.ps(fmt.Sprintf("Stop-VM -Name %s -Force", psQuote(name)))
+	return err
+}
+
+func (h *hypervVM) Delete(name string) error {
+	_, err := h.ps(fmt.Sprintf("if (Get-VM -Name %[1]s -ErrorAction SilentlyContinue) { Stop-VM -Name %[1]s -TurnOff -Force; Remove-VM -Name %[1]s -Force }", psQuote(name)))
+	return err
+}
+
+func (h *hypervVM) State(name string) (state.State, error) {
+	out, err := h.ps(fmt.Sprintf("(Get-VM -Name %s -ErrorAction SilentlyContinue).State", psQuote(name)))
+	if err != nil {
+		return state.Error, err
+	}
+	switch strings.TrimSpace(out) {
+	case "":
+		return state.None, nil
+	case "Running":
+		return state.Running, nil
+	case "Off":
+		return state.Stopped, nil
+	case "Paused":
+		return state.Paused, nil
+	case "Saved":
+		return state.Saved, nil
+	case "Starting":
+		return state.Starting, nil
+	case "Stopping":
+		return state.Stopping, nil
+	default:
+		return state.Error, nil
+	}
+}
+
+func (h *hypervVM) IP(name string) (string, error) {
+	out, err := h.ps(fmt.Sprintf("(Get-VMNetworkAdapter -VMName %s).IPAddresses", psQuote(name)))
+	if err != nil {
+		return "", err
+	}
+	ip := ipv4Regexp.FindString(out)
+	if ip == "" {
+		return "", fmt.Errorf("the VM %s has no IPv4 address yet", name)
+	}
+	return ip, nil
+}
+
+func (h *hypervVM) Run(name, user, password, script string) (string, error) {
+	// the password is read from stdin
+	cmd := fmt.Sprintf(`$cred = New-Object System.Management.Automation.PSCredential(%s, (ConvertTo-SecureString ([Console]::In.ReadLine()) -AsPlainText -Force)); `+
+		`$encoded = %s; `+
+		`Invoke-Command -VMName %s -Credential $cred -ScriptBlock { powershell.exe -NoProfile -NonInteractive -EncodedCommand $using:encoded; if ($LASTEXITCODE) { throw "exit code $LASTEXITCODE" } }`,
+		psQuote(user), psQuote(encodeCommand(script)), psQuote(name))
+	return runStdin(password+"\n", "powershell.exe", "-NoProfile", "-NonInteractive", "-Command", cmd)
+}
+
+// virtualboxVM manages the VMs with VBoxManage, and runs the scripts with its guest control, which needs the guest additions in the image
+type virtualboxVM struct {
+	cluster string
+}
+
+func (v *virtualboxVM) vbm(args ...string) (string, error) {
+	return run(driver.VBoxManagePath(), args...)
+}
+
+// machineReadable returns the value of the key in the machine readable VM info
+func (v *virtualboxVM) machineReadable(name, key string) (string, error) {
+	out, err := v.vbm("showvminfo", name, "--machinereadable")
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if k, val, ok := strings.Cut(strings.TrimSpace(line), "="); ok && k == key {
+			return strings.Trim(val, `"`), nil
+		}
+	}
+	return "", fmt.Errorf("no %s in the info of the VM %s", key, name)
+}
+
+func (v *virtualboxVM) Create(name, image, dir string, cpus, memory int) error {
+	adapter, err := v.machineReadable(v.cluster, "hostonlyadapter2")
+	if err != nil {
+		return errors.Wrap(err, "host-only adapter of the cluster")
+	}
+	disk := filepath.Join(dir, name+".vdi")
+	steps := [][]string{
+		{"createvm", "--name", name, "--ostype", "Windows2019_64", "--basefolder", dir, "--register"},
+		{"modifyvm", name, "--cpus", fmt.Sprint(cpus), "--memory", fmt.Sprint(memory), "--nic1", "nat", "--nic2", "hostonly", "--hostonlyadapter2", adapter},
+		{"storagectl", name, "--name", "SATA", "--add", "sata", "--controller", "IntelAhci"},
+		{"clonemedium", "disk", image, disk},
+		{"storageattach", name, "--storagectl", "SATA", "--port", "0", "--device", "0", "--type", "hdd", "--medium", disk},
+	}
+	for _, args := range steps {
+		if _, err := v.vbm(args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *virtualboxVM) Start(name string) error {
+	_, err := v.vbm("startvm", name, "--type", "headless")
+	return err
+}
+
+func (v *virtualboxVM) Stop(name string) error {
+	_, err := v.vbm("controlvm", name, "acpipowerbutton")
+	return err
+}
+
+func (v *virtualboxVM) Delete(name string) error {
+	if st, err := v.State(name); err != nil || st == state.None {
+		return err
+	}
+	if _, err := v.vbm("controlvm", name, "poweroff"); err != nil {
+		klog.Infof("power off of %s: %v", name, err)
+	}
+	_, err := v.vbm("unregistervm", name, "--delete")
+	return err
+}
+
+func (v *virtualboxVM) State(name string) (state.State, error) {
+	st, err := v.machineReadable(name, "VMState")
+	if err != nil {
+		if strings.Contains(err.Error(), "Could not find a registered machine") {
+			return state.None, nil
+		}
+		return state.Error, err
+	}
+	switch st {
+	case "running":
+		return state.Running, nil
+	case "poweroff", "aborted":
+		return state.Stopped, nil
+	case "paused":
+		return state.Paused, nil
+	case "saved":
+		return state.Saved, nil
+	case "starting":
+		return state.Starting, nil
+	case "stopping":
+		return state.Stopping, nil
+	default:
+		return state.Error, nil
+	}
+}
+
+func (v *virtualboxVM) IP(name string) (string, error) {
+	// the second network adapter is the host-only one shared with the cluster
+	out, err := v.vbm("guestproperty", "get", name, "/VirtualBox/GuestInfo/Net/1/V4/IP")
+	if err != nil {
+		return "", err
+	}
+	ip := ipv4Regexp.FindString(out)
+	if ip == "" {
+		return "", fmt.Errorf("the VM %s has no IPv4 address yet", name)
+	}
+	return ip, nil
+}
+
+func (v *virtualboxVM) Run(name, user, password, script string) (string, error) {
+	// VBoxManage reads the password from a file, readable only by the user
+	f, err := os.CreateTemp("", "minikube-windows-password")
+	if err != nil {
+		return "", errors.Wrap(err, "password file")
+	}
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(password)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "writing password file")
+	}
+	powershell := `C:\Windows\System32\WindowsPowerShell\v1.0\powershell.exe`
+	return v.vbm("guestcontrol", name, "run", "--exe", powershell, "--username", user, "--passwordfile", f.Name(), "--wait-stdout", "--wait-stderr",
+		"--", "powershell.exe", "-NoProfile", "-NonInteractive", "-EncodedCommand", encodeCommand(script))
+}
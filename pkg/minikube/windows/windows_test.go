@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package windows
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestJoinCommand(t *testing.T) {
+	linux := "sudo env PATH=\"/var/lib/minikube/binaries/v1.30.0:$PATH\" kubeadm join control-plane.minikube.internal:8443 --token abc.def --discovery-token-ca-cert-hash sha256:123 --ignore-preflight-errors=all --cri-socket unix:///run/containerd/containerd.sock"
+	got, err := JoinCommand(linux, "minikube-m02")
+	if err != nil {
+		t.Fatalf("JoinCommand: %v", err)
+	}
+	want := `C:\k\kubeadm.exe join control-plane.minikube.internal:8443 --token abc.def --discovery-token-ca-cert-hash sha256:123 --ignore-preflight-errors=all --cri-socket npipe:////./pipe/containerd-containerd --node-name minikube-m02`
+	if got != want {
+		t.Errorf("JoinCommand() = %q, want %q", got, want)
+	}
+
+	if _, err := JoinCommand("kubeadm token create", "minikube-m02"); err == nil {
+		t.Errorf("JoinCommand() of a command without join succeeded")
+	}
+}
+
+func TestJoinScript(t *testing.T) {
+	script := JoinScript("v1.30.0", "192.168.59.100", `C:\k\kubeadm.exe join control-plane.minikube.internal:8443`)
+	for _, want := range []string{
+		"192.168.59.100 control-plane.minikube.internal",
+		"C:\\PrepareNode.ps1 -KubernetesVersion v1.30.0",
+		"& C:\\k\\kubeadm.exe join control-plane.minikube.internal:8443",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("JoinScript() does not contain %q:\n%s", want, script)
+		}
+	}
+}
+
+func TestEncodeCommand(t *testing.T) {
+	b, err := base64.StdEncoding.DecodeString(encodeCommand("dir"))
+	if err != nil {
+		t.Fatalf("decoding: %v", err)
+	}
+	if want := []byte{'d', 0, 'i', 0, 'r', 0}; string(b) != string(want) {
+		t.Errorf("encodeCommand() decodes to %v, want the UTF-16LE %v", b, want)
+	}
+}
+
+func TestPSQuote(t *testing.T) {
+	if got, want := psQuote("it's"), "'it''s'"; got != want {
+		t.Errorf("psQuote() = %s, want %s", got, want)
+	}
+}
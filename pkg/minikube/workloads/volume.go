@@ -0,0 +1,157 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/kapi"
+	"k8s.io/minikube/pkg/minikube/assets"
+	"k8s.io/minikube/pkg/minikube/command"
+)
+
+// volumeArchive is the archive of a volume on the source and target nodes, while it is copied
+const volumeArchive = "/tmp/minikube-volume.tar.gz"
+
+// Quiesced is a Deployment or StatefulSet scaled down by Quiesce, with the replicas Resume restores
+type Quiesced struct {
+	Resource string
+	Name     string
+	Replicas int32
+}
+
+// Quiesce scales the Deployments and StatefulSets of the namespace down to zero, and waits for the pods mounting
+// the claims to be gone, so that the data of their volumes does not change while it is copied.
+// The workloads scaled down are returned for Resume, even on error.
+func Quiesce(ctx context.Context, cs kubernetes.Interface, namespace string, claims []string, timeout time.Duration) ([]Quiesced, error) {
+	var scaled []Quiesced
+	deps, err := cs.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing deployments")
+	}
+	for _, d := range deps.Items {
+		if r := kapi.Replicas(d.Spec.Replicas); r > 0 {
+			scaled = append(scaled, Quiesced{Resource: "deployments", Name: d.Name, Replicas: r})
+		}
+	}
+	sets, err := cs.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "listing statefulsets")
+	}
+	for _, st := range sets.Items {
+		if r := kapi.Replicas(st.Spec.Replicas); r > 0 {
+			scaled = append(scaled, Quiesced{Resource: "statefulsets", Name: st.Name, Replicas: r})
+		}
+	}
+	for i, q := range scaled {
+		if err := scale(ctx, cs, namespace, q.Resource, q.Name, 0); err != nil {
+			return scaled[:i], err
+		}
+	}
+
+	mounted := map[string]bool{}
+	for _, c := range claims {
+		mounted[c] = true
+	}
+	err = wait.PollUntilContextTimeout(ctx, time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		pods, err := cs.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return false, err
+		}
+		for _, p := range pods.Items {
+			for _, v := range p.Spec.Volumes {
+				if v.PersistentVolumeClaim != nil && mounted[v.PersistentVolumeClaim.ClaimName] {
+					klog.Infof("pod %s still mounts claim %s", p.Name, v.PersistentVolumeClaim.ClaimName)
+					return false, nil
+				}
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return scaled, errors.Wrap(err, "waiting for the pods mounting the claims to stop")
+	}
+	return scaled, nil
+}
+
+// Resume scales the workloads scaled down by Quiesce back to their replicas
+func Resume(ctx context.Context, cs kubernetes.Interface, namespace string, scaled []Quiesced) error {
+	var firstErr error
+	for _, q := range scaled {
+		if err := scale(ctx, cs, namespace, q.Resource, q.Name, q.Replicas); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func scale(ctx context.Context, cs kubernetes.Interface, namespace, resource, name string, replicas int32) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas))
+	var err error
+	switch resource {
+	case "deployments":
+		_, err = cs.AppsV1().Deployments(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	case "statefulsets":
+		_, err = cs.AppsV1().StatefulSets(namespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	}
+	return errors.Wrapf(err, "scaling %s/%s to %d", resource, name, replicas)
+}
+
+// CopyVolume copies the content of the host path volume at srcPath on the src node to dstPath on the dst node,
+// through an archive copied the way 'minikube cp' copies files between nodes
+func CopyVolume(src, dst command.Runner, srcPath, dstPath string) error {
+	if rr, err := src.RunCmd(exec.Command("sudo", "tar", "-C", srcPath, "-czf", volumeArchive, ".")); err != nil {
+		return errors.Wrapf(err, "archiving %s: %s", srcPath, rr.Output())
+	}
+	defer func() {
+		_, _ = src.RunCmd(exec.Command("sudo", "rm", "-f", volumeArchive))
+	}()
+	if rr, err := src.RunCmd(exec.Command("sudo", "chmod", "0644", volumeArchive)); err != nil {
+		return errors.Wrapf(err, "chmod: %s", rr.Output())
+	}
+
+	f, err := src.ReadableFile(volumeArchive)
+	if err != nil {
+		return errors.Wrap(err, "reading archive")
+	}
+	noWriter := func(_ []byte) (int, error) { return 0, nil }
+	if err := dst.Copy(assets.NewBaseCopyableFile(f, noWriter, path.Dir(volumeArchive), path.Base(volumeArchive))); err != nil {
+		return errors.Wrap(err, "copying archive")
+	}
+	defer func() {
+		_, _ = dst.RunCmd(exec.Command("sudo", "rm", "-f", volumeArchive))
+	}()
+
+	if rr, err := dst.RunCmd(exec.Command("sudo", "mkdir", "-p", dstPath)); err != nil {
+		return errors.Wrapf(err, "mkdir: %s", rr.Output())
+	}
+	if rr, err := dst.RunCmd(exec.Command("sudo", "tar", "-C", dstPath, "-xzpf", volumeArchive)); err != nil {
+		return errors.Wrapf(err, "extracting to %s: %s", dstPath, rr.Output())
+	}
+	return nil
+}
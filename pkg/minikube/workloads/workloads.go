@@ -0,0 +1,238 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package workloads moves the workloads of a namespace from a cluster to another
+package workloads
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	"k8s.io/minikube/pkg/kapi"
+)
+
+// fieldManager is the field manager of the resources applied to the target cluster
+const fieldManager = "minikube-workloads-move"
+
+// kinds are the resources moved, in the order they are applied: the workloads come last,
+// so that their config and volumes exist when their pods start
+var kinds = []schema.GroupVersionResource{
+	{Version: "v1", Resource: "serviceaccounts"},
+	{Version: "v1", Resource: "configmaps"},
+	{Version: "v1", Resource: "secrets"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "roles"},
+	{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "rolebindings"},
+	{Version: "v1", Resource: "persistentvolumeclaims"},
+	{Version: "v1", Resource: "services"},
+	{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+	{Group: "apps", Version: "v1", Resource: "deployments"},
+	{Group: "apps", Version: "v1", Resource: "statefulsets"},
+	{Group: "apps", Version: "v1", Resource: "daemonsets"},
+	{Group: "batch", Version: "v1", Resource: "jobs"},
+	{Group: "batch", Version: "v1", Resource: "cronjobs"},
+}
+
+// Object is a resource of the snapshot of a namespace
+type Object struct {
+	Resource schema.GroupVersionResource
+	*unstructured.Unstructured
+}
+
+// IsWorkload returns whether the object runs pods
+func (o Object) IsWorkload() bool {
+	return o.Resource.Group == "apps" || o.Resource.Group == "batch"
+}
+
+// Snapshot returns the resources of the namespace, without the fields set by the source cluster.
+// Objects owned by another object, like the ReplicaSets of a Deployment, are recreated by their owner and left out.
+func Snapshot(ctx context.Context, client dynamic.Interface, namespace string) ([]Object, error) {
+	var objs []Object
+	for _, gvr := range kinds {
+		list, err := client.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "listing %s", gvr.Resource)
+		}
+		for i := range list.Items {
+			u := &list.Items[i]
+			if len(u.GetOwnerReferences()) > 0 || generated(gvr, u) {
+				continue
+			}
+			sanitize(gvr, u)
+			objs = append(objs, Object{Resource: gvr, Unstructured: u})
+		}
+	}
+	return objs, nil
+}
+
+// generated returns whether the object is created by Kubernetes in every namespace
+func generated(gvr schema.GroupVersionResource, u *unstructured.Unstructured) bool {
+	switch gvr.Resource {
+	case "serviceaccounts":
+		return u.GetName() == "default"
+	case "configmaps":
+		return u.GetName() == "kube-root-ca.crt"
+	case "secrets":
+		t, _, _ := unstructured.NestedString(u.Object, "type")
+		return t == string(corev1.SecretTypeServiceAccountToken)
+	}
+	return false
+}
+
+// sanitize removes the fields of u set by the source cluster, which would conflict with the target cluster
+func sanitize(gvr schema.GroupVersionResource, u *unstructured.Unstructured) {
+	for _, f := range []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink"} {
+		unstructured.RemoveNestedField(u.Object, "metadata", f)
+	}
+	unstructured.RemoveNestedField(u.Object, "status")
+	annotations := u.GetAnnotations()
+	for k := range annotations {
+		if k == corev1.LastAppliedConfigAnnotation || strings.HasPrefix(k, "pv.kubernetes.io/") ||
+			strings.HasPrefix(k, "volume.beta.kubernetes.io/") || strings.HasPrefix(k, "volume.kubernetes.io/") ||
+			k == "deployment.kubernetes.io/revision" {
+			delete(annotations, k)
+		}
+	}
+	u.SetAnnotations(annotations)
+
+	switch gvr.Resource {
+	case "services":
+		// the IPs are allocated from the service CIDR of the target cluster
+		for _, f := range []string{"clusterIP", "clusterIPs", "healthCheckNodePort"} {
+			unstructured.RemoveNestedField(u.Object, "spec", f)
+		}
+	case "persistentvolumeclaims":
+		// a new volume is provisioned in the target cluster
+		unstructured.RemoveNestedField(u.Object, "spec", "volumeName")
+	case "jobs":
+		// the selector and its labels are generated from the uid of the job
+		unstructured.RemoveNestedField(u.Object, "spec", "selector")
+		for _, l := range []string{"controller-uid", "batch.kubernetes.io/controller-uid"} {
+			unstructured.RemoveNestedField(u.Object, "spec", "template", "metadata", "labels", l)
+		}
+	}
+}
+
+// Apply creates the namespace in the target cluster if needed, and applies the objects to it
+func Apply(ctx context.Context, cs kubernetes.Interface, client dynamic.Interface, namespace string, objs []Object) error {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+	if _, err := cs.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "creating namespace %s", namespace)
+	}
+	force := true
+	for _, o := range objs {
+		b, err := o.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		if _, err := client.Resource(o.Resource).Namespace(namespace).Patch(ctx, o.GetName(), types.ApplyPatchType, b,
+			metav1.PatchOptions{FieldManager: fieldManager, Force: &force}); err != nil {
+			return errors.Wrapf(err, "applying %s/%s", o.Resource.Resource, o.GetName())
+		}
+		klog.Infof("applied %s/%s", o.Resource.Resource, o.GetName())
+	}
+	return nil
+}
+
+// Volume is a persistent volume claim, with the host path of its volume
+type Volume struct {
+	Claim string
+	Path  string
+}
+
+// BoundVolumes waits for the claims to be bound, and returns the host paths of their volumes.
+// Claims of volumes which are not host paths, like CSI volumes, are returned with an empty path.
+func BoundVolumes(ctx context.Context, cs kubernetes.Interface, namespace string, claims []string, timeout time.Duration) ([]Volume, error) {
+	var vols []Volume
+	for _, c := range claims {
+		var pvc *corev1.PersistentVolumeClaim
+		err := wait.PollUntilContextTimeout(ctx, time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+			var err error
+			pvc, err = cs.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, c, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			return pvc.Status.Phase == corev1.ClaimBound, nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "waiting for claim %s to be bound", c)
+		}
+		pv, err := cs.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "getting volume of claim %s", c)
+		}
+		vols = append(vols, Volume{Claim: c, Path: hostPath(pv)})
+	}
+	return vols, nil
+}
+
+// hostPath returns the path of the volume on the node, empty if it is not a host path volume
+func hostPath(pv *corev1.PersistentVolume) string {
+	switch {
+	case pv.Spec.HostPath != nil:
+		return pv.Spec.HostPath.Path
+	case pv.Spec.Local != nil:
+		return pv.Spec.Local.Path
+	}
+	return ""
+}
+
+// WaitReady waits for the deployments, statefulsets and daemonsets among the objects to have all their replicas ready
+func WaitReady(ctx context.Context, cs kubernetes.Interface, namespace string, objs []Object, timeout time.Duration) error {
+	for _, o := range objs {
+		name := o.GetName()
+		ready := func(ctx context.Context) (bool, error) {
+			switch o.Resource.Resource {
+			case "deployments":
+				d, err := cs.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+				if err != nil {
+					return false, err
+				}
+				return d.Status.ObservedGeneration >= d.Generation && d.Status.ReadyReplicas == kapi.Replicas(d.Spec.Replicas), nil
+			case "statefulsets":
+				s, err := cs.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+				if err != nil {
+					return false, err
+				}
+				return s.Status.ObservedGeneration >= s.Generation && s.Status.ReadyReplicas == kapi.Replicas(s.Spec.Replicas), nil
+			case "daemonsets":
+				d, err := cs.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+				if err != nil {
+					return false, err
+				}
+				return d.Status.ObservedGeneration >= d.Generation && d.Status.NumberReady == d.Status.DesiredNumberScheduled, nil
+			}
+			return true, nil
+		}
+		if err := wait.PollUntilContextTimeout(ctx, 2*time.Second, timeout, true, ready); err != nil {
+			return fmt.Errorf("%s/%s is not ready: %v", o.Resource.Resource, name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSnapshot(t *testing.T) {
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "app", UID: "1234", ResourceVersion: "42",
+			Annotations: map[string]string{corev1.LastAppliedConfigAnnotation: "{}", "team": "web"}}
+	}
+	owned := meta("web-5d4f")
+	owned.OwnerReferences = []metav1.OwnerReference{{Kind: "Deployment", Name: "web"}}
+	v1 := corev1.SchemeGroupVersion
+	apps := appsv1.SchemeGroupVersion
+	objs := []runtime.Object{
+		unstructuredObject(t, v1.WithKind("Service"), &corev1.Service{ObjectMeta: meta("web"), Spec: corev1.ServiceSpec{ClusterIP: "10.96.0.12", ClusterIPs: []string{"10.96.0.12"}}}),
+		unstructuredObject(t, v1.WithKind("PersistentVolumeClaim"), &corev1.PersistentVolumeClaim{ObjectMeta: meta("data"), Spec: corev1.PersistentVolumeClaimSpec{VolumeName: "pvc-1234"}}),
+		unstructuredObject(t, v1.WithKind("ConfigMap"), &corev1.ConfigMap{ObjectMeta: meta("kube-root-ca.crt")}),
+		unstructuredObject(t, v1.WithKind("ServiceAccount"), &corev1.ServiceAccount{ObjectMeta: meta("default")}),
+		unstructuredObject(t, v1.WithKind("ConfigMap"), &corev1.ConfigMap{ObjectMeta: meta("settings")}),
+		unstructuredObject(t, apps.WithKind("Deployment"), &appsv1.Deployment{ObjectMeta: meta("web"), Status: appsv1.DeploymentStatus{ReadyReplicas: 1}}),
+		unstructuredObject(t, apps.WithKind("Deployment"), &appsv1.Deployment{ObjectMeta: owned}),
+		unstructuredObject(t, v1.WithKind("ConfigMap"), &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "kube-system"}}),
+	}
+	lists := map[schema.GroupVersionResource]string{}
+	for _, gvr := range kinds {
+		lists[gvr] = "List"
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), lists, objs...)
+
+	got, err := Snapshot(context.Background(), client, "app")
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	var names []string
+	for _, o := range got {
+		names = append(names, o.Resource.Resource+"/"+o.GetName())
+		if o.GetUID() != "" || o.GetResourceVersion() != "" {
+			t.Errorf("%s/%s kept its uid and resourceVersion", o.Resource.Resource, o.GetName())
+		}
+		if _, ok := o.GetAnnotations()[corev1.LastAppliedConfigAnnotation]; ok {
+			t.Errorf("%s/%s kept the last applied config", o.Resource.Resource, o.GetName())
+		}
+		if o.GetAnnotations()["team"] != "web" {
+			t.Errorf("%s/%s lost its annotations", o.Resource.Resource, o.GetName())
+		}
+		if _, found, _ := unstructured.NestedFieldNoCopy(o.Object, "status"); found {
+			t.Errorf("%s/%s kept its status", o.Resource.Resource, o.GetName())
+		}
+	}
+	want := []string{"configmaps/settings", "persistentvolumeclaims/data", "services/web", "deployments/web"}
+	if !equalUnordered(names, want) {
+		t.Errorf("Snapshot() = %v, want %v", names, want)
+	}
+	for _, o := range got {
+		switch o.Resource.Resource {
+		case "services":
+			if _, found, _ := unstructured.NestedString(o.Object, "spec", "clusterIP"); found {
+				t.Errorf("the service kept its cluster IP")
+			}
+		case "persistentvolumeclaims":
+			if _, found, _ := unstructured.NestedString(o.Object, "spec", "volumeName"); found {
+				t.Errorf("the claim kept its volume")
+			}
+		case "deployments":
+			if !o.IsWorkload() {
+				t.Errorf("the deployment is not a workload")
+			}
+		}
+	}
+}
+
+func TestBoundVolumesAndWaitReady(t *testing.T) {
+	replicas := int32(2)
+	cs := fake.NewSimpleClientset(
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "app"},
+			Spec: corev1.PersistentVolumeClaimSpec{VolumeName: "pvc-1234"}, Status: corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound}},
+		&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1234"},
+			Spec: corev1.PersistentVolumeSpec{PersistentVolumeSource: corev1.PersistentVolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/tmp/hostpath-provisioner/app/data"}}}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"}, Spec: appsv1.DeploymentSpec{Replicas: &replicas},
+			Status: appsv1.DeploymentStatus{ReadyReplicas: 1}},
+	)
+	ctx := context.Background()
+	vols, err := BoundVolumes(ctx, cs, "app", []string{"data"}, time.Second)
+	if err != nil {
+		t.Fatalf("BoundVolumes: %v", err)
+	}
+	if len(vols) != 1 || vols[0].Path != "/tmp/hostpath-provisioner/app/data" {
+		t.Errorf("BoundVolumes() = %v, want the host path of the volume", vols)
+	}
+
+	u := &unstructured.Unstructured{}
+	u.SetName("web")
+	wl := []Object{{Resource: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, Unstructured: u}}
+	if err := WaitReady(ctx, cs, "app", wl, 3*time.Second); err == nil {
+		t.Errorf("WaitReady succeeded with 1 of 2 replicas ready")
+	}
+	d, _ := cs.AppsV1().Deployments("app").Get(ctx, "web", metav1.GetOptions{})
+	d.Status.ReadyReplicas = 2
+	if _, err := cs.AppsV1().Deployments("app").UpdateStatus(ctx, d, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := WaitReady(ctx, cs, "app", wl, 3*time.Second); err != nil {
+		t.Errorf("WaitReady: %v", err)
+	}
+}
+
+func unstructuredObject(t *testing.T, gvk schema.GroupVersionKind, obj runtime.Object) *unstructured.Unstructured {
+	m, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &unstructured.Unstructured{Object: m}
+	u.SetGroupVersionKind(gvk)
+	return u
+}
+
+func equalUnordered(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestQuiesceResume(t *testing.T) {
+	two := int32(2)
+	cs := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"}, Spec: appsv1.DeploymentSpec{Replicas: &two}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "app"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}},
+		// the fake clientset runs no controller: a pod mounting the claim is never deleted
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "app"}, Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+			Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-db-0"}},
+		}}}},
+	)
+	ctx := context.Background()
+
+	scaled, err := Quiesce(ctx, cs, "app", []string{"data-db-0"}, time.Second)
+	if err == nil {
+		t.Errorf("Quiesce succeeded while a pod mounts the claim")
+	}
+	if len(scaled) != 2 {
+		t.Fatalf("Quiesce scaled %v, want web and db", scaled)
+	}
+	web, _ := cs.AppsV1().Deployments("app").Get(ctx, "web", metav1.GetOptions{})
+	if *web.Spec.Replicas != 0 {
+		t.Errorf("quiesced web has %d replicas, want 0", *web.Spec.Replicas)
+	}
+	if err := cs.CoreV1().Pods("app").Delete(ctx, "db-0", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Quiesce(ctx, cs, "app", []string{"data-db-0"}, time.Second); err != nil {
+		t.Errorf("Quiesce: %v", err)
+	}
+
+	if err := Resume(ctx, cs, "app", scaled); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	web, _ = cs.AppsV1().Deployments("app").Get(ctx, "web", metav1.GetOptions{})
+	db, _ := cs.AppsV1().StatefulSets("app").Get(ctx, "db", metav1.GetOptions{})
+	if *web.Spec.Replicas != 2 || *db.Spec.Replicas != 1 {
+		t.Errorf("resumed web and db have %d and %d replicas, want 2 and 1", *web.Spec.Replicas, *db.Spec.Replicas)
+	}
+	other, _ := cs.AppsV1().Deployments("default").Get(ctx, "other", metav1.GetOptions{})
+	if other.Spec.Replicas != nil {
+		t.Errorf("Quiesce scaled a deployment of another namespace")
+	}
+}
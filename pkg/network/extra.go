@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// extraNetworkNameRe matches the names of the extra networks, which end up in the node config and pod annotations of CNI plugins like Multus
+var extraNetworkNameRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// ExtraNetwork is an additional network the nodes are attached to, on top of the network of the cluster
+type ExtraNetwork struct {
+	// Name identifies the network in the node config
+	Name string
+	// Network is the network of the driver the additional NIC is attached to:
+	// a libvirt network or host bridge with kvm2, a docker or podman network with the kic drivers
+	Network string
+}
+
+// ParseExtraNetwork parses a NAME=NETWORK value of --extra-network, a NAME alone attaches to the network of that name
+func ParseExtraNetwork(spec string) (ExtraNetwork, error) {
+	name, netName, found := strings.Cut(spec, "=")
+	if !found {
+		netName = name
+	}
+	if !extraNetworkNameRe.MatchString(name) {
+		return ExtraNetwork{}, fmt.Errorf("invalid extra network name %q in %q: must consist of lower case alphanumeric characters or '-'", name, spec)
+	}
+	if netName == "" {
+		return ExtraNetwork{}, fmt.Errorf("missing network in %q, expected NAME=NETWORK", spec)
+	}
+	return ExtraNetwork{Name: name, Network: netName}, nil
+}
+
+// ParseExtraNetworks parses the values of --extra-network, each name must be unique
+func ParseExtraNetworks(specs []string) ([]ExtraNetwork, error) {
+	var nets []ExtraNetwork
+	seen := map[string]bool{}
+	for _, spec := range specs {
+		n, err := ParseExtraNetwork(spec)
+		if err != nil {
+			return nil, err
+		}
+		if seen[n.Name] {
+			return nil, fmt.Errorf("extra network %q is defined more than once", n.Name)
+		}
+		seen[n.Name] = true
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// ExtraDriverNetworks returns the networks of the driver to attach the nodes to, in the order of the values of --extra-network
+func ExtraDriverNetworks(specs []string) ([]string, error) {
+	nets, err := ParseExtraNetworks(specs)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, n := range nets {
+		names = append(names, n.Network)
+	}
+	return names, nil
+}
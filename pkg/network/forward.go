@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"os/exec"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// forwardingRule is an iptables rule of the host
+type forwardingRule struct {
+	table string
+	chain string
+	spec  []string
+}
+
+// args returns the arguments of iptables running op on the rule, at the optional position pos of the chain
+func (r forwardingRule) args(op string, pos ...string) []string {
+	args := append([]string{"iptables", "-t", r.table, op, r.chain}, pos...)
+	return append(args, r.spec...)
+}
+
+// forwardingRules returns the rules of the host forwarding the traffic between the subnets a and b ahead of
+// the rules of docker and libvirt, without masquerading it, so the nodes see the addresses of each other
+func forwardingRules(a, b string) []forwardingRule {
+	var rules []forwardingRule
+	for _, p := range [][2]string{{a, b}, {b, a}} {
+		rules = append(rules,
+			forwardingRule{table: "filter", chain: "FORWARD", spec: []string{"-s", p[0], "-d", p[1], "-j", "ACCEPT"}},
+			forwardingRule{table: "nat", chain: "POSTROUTING", spec: []string{"-s", p[0], "-d", p[1], "-j", "RETURN"}})
+	}
+	return rules
+}
+
+// AllowForwarding makes the host forward the traffic between the subnets a and b, with sudo iptables
+func AllowForwarding(a, b string) error {
+	for _, r := range forwardingRules(a, b) {
+		if err := exec.Command("sudo", r.args("-C")...).Run(); err == nil {
+			continue
+		}
+		klog.Infof("allowing forwarding: sudo %v", r.args("-I", "1"))
+		if out, err := exec.Command("sudo", r.args("-I", "1")...).CombinedOutput(); err != nil {
+			return errors.Wrapf(err, "iptables: %s", out)
+		}
+	}
+	return nil
+}
+
+// RemoveForwarding removes the rules of AllowForwarding for the subnets a and b
+func RemoveForwarding(a, b string) {
+	for _, r := range forwardingRules(a, b) {
+		if out, err := exec.Command("sudo", r.args("-D")...).CombinedOutput(); err != nil {
+			klog.Infof("removing forwarding rule %v: %v: %s", r.spec, err, out)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2024 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"fmt"
+	"net"
+)
+
+// IPv6Prefix is the length of the IPv6 subnets paired with the IPv4 subnets of the minikube networks
+const IPv6Prefix = 64
+
+// IPv6Of returns the unique local IPv6 address paired with the IPv4 address on a minikube network,
+// keeping its digits readable: 192.168.49.2 maps to fd00:192:168:49::2
+func IPv6Of(ipv4 string) (string, error) {
+	ip := net.ParseIP(ipv4).To4()
+	if ip == nil {
+		return "", fmt.Errorf("%q is not an IPv4 address", ipv4)
+	}
+	return fmt.Sprintf("fd00:%d:%d:%d::%d", ip[0], ip[1], ip[2], ip[3]), nil
+}
+
+// IPv6Subnet returns the IPv6 subnet paired with the IPv4 subnet of the address: 192.168.49.0/24 maps to fd00:192:168:49::/64
+func IPv6Subnet(ipv4 string) (string, error) {
+	ip := net.ParseIP(ipv4).To4()
+	if ip == nil {
+		return "", fmt.Errorf("%q is not an IPv4 address", ipv4)
+	}
+	return fmt.Sprintf("fd00:%d:%d:%d::/%d", ip[0], ip[1], ip[2], IPv6Prefix), nil
+}
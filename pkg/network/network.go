@@ -20,6 +20,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/juju/mutex/v2"
@@ -29,6 +30,10 @@ import (
 
 const defaultReservationPeriod = 1 * time.Minute
 
+// ReservedFrom is the last octet of the first address of a /24 network kept out of the DHCP ranges minikube configures,
+// so that the addresses up to the broadcast one can be given to the virtual IP and the load balancer pool of the cluster
+const ReservedFrom = 200
+
 // Parameters contains main network parameters.
 type Parameters struct {
 	IP        string // IP address of network
@@ -162,6 +167,21 @@ var inspect = func(addr string) (*Parameters, error) {
 	return n, nil
 }
 
+// DHCPMax returns the last address of the network given to clients by DHCP: the one before ReservedFrom for a /24 network,
+// ClientMax otherwise
+func (p Parameters) DHCPMax() string {
+	ip := net.ParseIP(p.ClientMax).To4()
+	if p.Prefix != 24 || ip == nil {
+		return p.ClientMax
+	}
+	return net.IPv4(ip[0], ip[1], ip[2], ReservedFrom-1).String()
+}
+
+// Inspect returns the parameters of the network of addr.
+func Inspect(addr string) (*Parameters, error) {
+	return inspect(addr)
+}
+
 // isSubnetTaken returns if local network subnet exists and any error occurred.
 // If will return false in case of an error.
 var isSubnetTaken = func(subnet string) (bool, error) {
@@ -189,6 +209,22 @@ func IsBuiltinQEMU(network string) bool {
 	return network == "builtin"
 }
 
+// Bridged is the network of the VM drivers attaching the VM to the network of a host interface, written bridged:<ifname>
+const Bridged = "bridged"
+
+// ParseBridged returns the host interface of a bridged network, empty if the network does not name one,
+// and whether the network is bridged
+func ParseBridged(network string) (string, bool) {
+	if network == Bridged {
+		return "", true
+	}
+	iface, ok := strings.CutPrefix(network, Bridged+":")
+	if !ok || iface == "" {
+		return "", false
+	}
+	return iface, true
+}
+
 // FreeSubnet will try to find free private network beginning with startSubnet, incrementing it in steps up to number of tries.
 func FreeSubnet(startSubnet string, step, tries int) (*Parameters, error) {
 	currSubnet := startSubnet
@@ -17,6 +17,8 @@ limitations under the License.
 package network
 
 import (
+	"net"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -102,6 +104,25 @@ func TestFreeSubnet(t *testing.T) {
 	})
 }
 
+func TestDHCPMax(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"192.168.39.0/24", "192.168.39.199"},
+		{"10.96.0.0/16", "10.96.255.254"},
+	}
+	for _, tc := range tests {
+		n, err := Inspect(tc.addr)
+		if err != nil {
+			t.Fatalf("Inspect(%s): %v", tc.addr, err)
+		}
+		if got := n.DHCPMax(); got != tc.want {
+			t.Errorf("DHCPMax() of %s = %s, want %s", tc.addr, got, tc.want)
+		}
+	}
+}
+
 func TestParseAddr(t *testing.T) {
 	t.Run("ValidIP", func(t *testing.T) {
 		addr := "192.168.9.0"
@@ -146,3 +167,88 @@ func TestParseAddr(t *testing.T) {
 		}
 	})
 }
+
+func TestParseExtraNetworks(t *testing.T) {
+	tests := []struct {
+		specs   []string
+		want    []ExtraNetwork
+		wantErr bool
+	}{
+		{specs: []string{"storage=virbr2"}, want: []ExtraNetwork{{Name: "storage", Network: "virbr2"}}},
+		{specs: []string{"mgmt", "storage=minikube-storage"}, want: []ExtraNetwork{{Name: "mgmt", Network: "mgmt"}, {Name: "storage", Network: "minikube-storage"}}},
+		{specs: []string{"storage="}, wantErr: true},
+		{specs: []string{"=virbr2"}, wantErr: true},
+		{specs: []string{"Storage=virbr2"}, wantErr: true},
+		{specs: []string{"storage=virbr2", "storage=virbr3"}, wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := ParseExtraNetworks(tc.specs)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("ParseExtraNetworks(%v) error = %v, wantErr %v", tc.specs, err, tc.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("ParseExtraNetworks(%v) = %+v, want %+v", tc.specs, got, tc.want)
+		}
+	}
+}
+
+func TestParseBridged(t *testing.T) {
+	tests := []struct {
+		network string
+		iface   string
+		bridged bool
+	}{
+		{network: "bridged", bridged: true},
+		{network: "bridged:en1", iface: "en1", bridged: true},
+		{network: "bridged:"},
+		{network: "socket_vmnet"},
+		{network: "builtin"},
+	}
+	for _, tc := range tests {
+		iface, bridged := ParseBridged(tc.network)
+		if iface != tc.iface || bridged != tc.bridged {
+			t.Errorf("ParseBridged(%q) = %q, %t, want %q, %t", tc.network, iface, bridged, tc.iface, tc.bridged)
+		}
+	}
+}
+
+func TestForwardingRules(t *testing.T) {
+	rules := forwardingRules("192.168.39.0/24", "192.168.49.0/24")
+	var got []string
+	for _, r := range rules {
+		got = append(got, strings.Join(r.args("-I", "1"), " "))
+	}
+	want := []string{
+		"iptables -t filter -I FORWARD 1 -s 192.168.39.0/24 -d 192.168.49.0/24 -j ACCEPT",
+		"iptables -t nat -I POSTROUTING 1 -s 192.168.39.0/24 -d 192.168.49.0/24 -j RETURN",
+		"iptables -t filter -I FORWARD 1 -s 192.168.49.0/24 -d 192.168.39.0/24 -j ACCEPT",
+		"iptables -t nat -I POSTROUTING 1 -s 192.168.49.0/24 -d 192.168.39.0/24 -j RETURN",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("forwardingRules() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestIPv6Of(t *testing.T) {
+	got, err := IPv6Of("192.168.49.2")
+	if err != nil {
+		t.Fatalf("IPv6Of: %v", err)
+	}
+	if want := "fd00:192:168:49::2"; got != want {
+		t.Errorf("IPv6Of() = %s, want %s", got, want)
+	}
+	if net.ParseIP(got) == nil {
+		t.Errorf("IPv6Of() = %s is not a valid IP", got)
+	}
+	subnet, err := IPv6Subnet("192.168.49.0")
+	if err != nil {
+		t.Fatalf("IPv6Subnet: %v", err)
+	}
+	if want := "fd00:192:168:49::/64"; subnet != want {
+		t.Errorf("IPv6Subnet() = %s, want %s", subnet, want)
+	}
+	if _, err := IPv6Of("fd00::2"); err == nil {
+		t.Errorf("IPv6Of() of an IPv6 address succeeded")
+	}
+}
@@ -40,6 +40,7 @@ import (
 	"k8s.io/minikube/pkg/minikube/assets"
 	"k8s.io/minikube/pkg/minikube/command"
 	"k8s.io/minikube/pkg/minikube/config"
+	"k8s.io/minikube/pkg/minikube/sshutil"
 )
 
 // generic interface for minikube provisioner
@@ -70,7 +71,7 @@ func init() {
 func NewSystemdProvisioner(osReleaseID string, d drivers.Driver) provision.SystemdProvisioner {
 	return provision.SystemdProvisioner{
 		GenericProvisioner: provision.GenericProvisioner{
-			SSHCommander:      provision.GenericSSHCommander{Driver: d},
+			SSHCommander:      newSSHCommander(d),
 			DockerOptionsDir:  "/etc/docker",
 			DaemonOptionsFile: "/etc/systemd/system/docker.service.d/10-machine.conf",
 			OsReleaseID:       osReleaseID,
@@ -79,6 +80,59 @@ func NewSystemdProvisioner(osReleaseID string, d drivers.Driver) provision.Syste
 	}
 }
 
+// newSSHCommander returns the commander of the provisioning commands: the one of libmachine, unless the machine is
+// reached through the ssh proxy of the cluster, which only the ssh runner of minikube goes through
+func newSSHCommander(d drivers.Driver) provision.SSHCommander {
+	if sshutil.ProxyEnabled() {
+		return sshCommander{runner: command.NewSSHRunner(d)}
+	}
+	return provision.GenericSSHCommander{Driver: d}
+}
+
+// sshCommander runs the provisioning commands with the ssh runner of minikube, which goes through the ssh proxy of the cluster
+type sshCommander struct {
+	runner *command.SSHRunner
+}
+
+// SSHCommand runs the shell command args, returning its combined output like the libmachine commander
+func (c sshCommander) SSHCommand(args string) (string, error) {
+	var out bytes.Buffer
+	cmd := exec.Command("/bin/bash", "-c", args)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if _, err := c.runner.RunCmd(cmd); err != nil {
+		return "", fmt.Errorf(`ssh command error:
+command : %s
+err     : %v
+output  : %s`, args, err, out.String())
+	}
+	return out.String(), nil
+}
+
+// Detect returns the provisioner of the OS of the machine with provision.DetectProvisioner, or through the ssh runner
+// when the machine is reached through the ssh proxy of the cluster
+func Detect(d drivers.Driver) (provision.Provisioner, error) {
+	if !sshutil.ProxyEnabled() {
+		return provision.DetectProvisioner(d)
+	}
+	klog.Infof("detecting the provisioner of %s", d.GetMachineName())
+	out, err := newSSHCommander(d).SSHCommand("cat /etc/os-release")
+	if err != nil {
+		return nil, err
+	}
+	info, err := provision.NewOsRelease([]byte(out))
+	if err != nil {
+		return nil, errors.Wrap(err, "parsing /etc/os-release")
+	}
+	for _, p := range []provision.Provisioner{NewBuildrootProvisioner(d), NewUbuntuProvisioner(d)} {
+		p.SetOsReleaseInfo(info)
+		if p.CompatibleWithHost() {
+			return p, nil
+		}
+	}
+	return nil, provision.ErrDetectionFailed
+}
+
 func configureAuth(p miniProvisioner) error {
 	klog.Infof("configureAuth start")
 	start := time.Now()
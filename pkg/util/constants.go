@@ -17,7 +17,10 @@ limitations under the License.
 package util
 
 import (
+	"fmt"
 	"net"
+	"net/url"
+	"strings"
 
 	"github.com/pkg/errors"
 )
@@ -50,19 +53,23 @@ func GetServiceClusterIP(serviceCIDR string) (net.IP, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "parsing default service cidr")
 	}
-	ip = ip.To4()
-	ip[3]++
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	ip[len(ip)-1]++
 	return ip, nil
 }
 
-// GetDNSIP returns x.x.x.10 of the service CIDR
+// GetDNSIP returns the address ending in 10 of the service CIDR
 func GetDNSIP(serviceCIDR string) (net.IP, error) {
 	ip, _, err := net.ParseCIDR(serviceCIDR)
 	if err != nil {
 		return nil, errors.Wrap(err, "parsing default service cidr")
 	}
-	ip = ip.To4()
-	ip[3] = 10
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	ip[len(ip)-1] = 10
 	return ip, nil
 }
 
@@ -70,3 +77,16 @@ func GetDNSIP(serviceCIDR string) (net.IP, error) {
 func GetAlternateDNS(domain string) []string {
 	return []string{"kubernetes.default.svc." + domain, "kubernetes.default.svc", "kubernetes.default", "kubernetes", "localhost"}
 }
+
+// GetSPIFFEID returns a SPIFFE ID (spiffe://<trust domain>/<path>) suitable for use as a URI SAN
+func GetSPIFFEID(trustDomain, path string) (*url.URL, error) {
+	td := strings.ToLower(strings.TrimPrefix(trustDomain, "spiffe://"))
+	if td == "" || strings.ContainsAny(td, "/:@?#") {
+		return nil, fmt.Errorf("invalid SPIFFE trust domain %q", trustDomain)
+	}
+	u := &url.URL{Scheme: "spiffe", Host: td, Path: "/" + strings.TrimPrefix(path, "/")}
+	if _, err := url.Parse(u.String()); err != nil {
+		return nil, errors.Wrapf(err, "parsing SPIFFE ID %q", u.String())
+	}
+	return u, nil
+}
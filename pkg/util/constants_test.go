@@ -28,6 +28,7 @@ func TestGetServiceClusterIP(t *testing.T) {
 	}{
 		{"1111.0.0.1/12", "", true},
 		{"10.96.0.0/24", "10.96.0.1", false},
+		{"fd00:10:96::/112", "fd00:10:96::1", false},
 	}
 
 	for _, tt := range testData {
@@ -54,6 +55,7 @@ func TestGetDNSIP(t *testing.T) {
 	}{
 		{"1111.0.0.1/12", "", true},
 		{"10.96.0.0/24", "10.96.0.10", false},
+		{"fd00:10:96::/112", "fd00:10:96::a", false},
 	}
 
 	for _, tt := range testData {
@@ -71,3 +73,33 @@ func TestGetDNSIP(t *testing.T) {
 		}
 	}
 }
+
+func TestGetSPIFFEID(t *testing.T) {
+	testData := []struct {
+		trustDomain string
+		path        string
+		expectedID  string
+		err         bool
+	}{
+		{"cluster.local", "ns/kube-system/sa/kube-apiserver", "spiffe://cluster.local/ns/kube-system/sa/kube-apiserver", false},
+		{"spiffe://Example.Org", "/user/minikube-user", "spiffe://example.org/user/minikube-user", false},
+		{"", "user/minikube-user", "", true},
+		{"example.org:8443", "user/minikube-user", "", true},
+		{"example.org/extra", "user/minikube-user", "", true},
+	}
+
+	for _, tt := range testData {
+		id, err := GetSPIFFEID(tt.trustDomain, tt.path)
+		if err != nil && !tt.err {
+			t.Fatalf("GetSPIFFEID() err = %v", err)
+		}
+		if err == nil && tt.err {
+			t.Fatalf("GetSPIFFEID() should have returned error, but didn't")
+		}
+		if err == nil {
+			if id.String() != tt.expectedID {
+				t.Fatalf("Expected '%s' but got '%s'", tt.expectedID, id.String())
+			}
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"testing"
@@ -67,6 +68,11 @@ func TestGenerateSignedCert(t *testing.T) {
 
 	ips := []net.IP{net.ParseIP("192.168.59.100"), net.ParseIP("10.0.0.10")}
 	alternateDNS := []string{"kubernetes.default.svc.cluster.local", "kubernetes.default"}
+	spiffeID, err := GetSPIFFEID(DefaultDNSDomain, "ns/kube-system/sa/kube-apiserver")
+	if err != nil {
+		t.Fatalf("Error generating SPIFFE ID: %v", err)
+	}
+	uris := []*url.URL{spiffeID}
 
 	var tests = []struct {
 		description    string
@@ -109,7 +115,7 @@ func TestGenerateSignedCert(t *testing.T) {
 		test := test
 		t.Run(test.description, func(t *testing.T) {
 			err := GenerateSignedCert(
-				certPath, keyPath, "minikube", ips, alternateDNS, test.signerCertPath,
+				certPath, keyPath, "minikube", ips, alternateDNS, uris, test.signerCertPath,
 				test.signerKeyPath, constants.DefaultCertExpiration,
 			)
 			if err != nil && !test.err {
@@ -124,9 +130,12 @@ func TestGenerateSignedCert(t *testing.T) {
 					t.Errorf("Error reading cert data: %v", err)
 				}
 				data, _ := pem.Decode(certBytes)
-				_, err = x509.ParseCertificate(data.Bytes)
+				cert, err := x509.ParseCertificate(data.Bytes)
 				if err != nil {
-					t.Errorf("Error parsing certificate: %v", err)
+					t.Fatalf("Error parsing certificate: %v", err)
+				}
+				if len(cert.URIs) != 1 || cert.URIs[0].String() != spiffeID.String() {
+					t.Errorf("Expected URI SANs [%s] but got %v", spiffeID, cert.URIs)
 				}
 			}
 
@@ -20,6 +20,8 @@ import (
 	"crypto/sha1"
 	"fmt"
 	"os"
+	"path/filepath"
+	"runtime"
 	"time"
 
 	"github.com/juju/clock"
@@ -29,7 +31,8 @@ import (
 	"k8s.io/klog/v2"
 )
 
-// WriteFile decorates os.WriteFile with a file lock and retry
+// WriteFile decorates os.WriteFile with a file lock and retry.
+// The file is replaced atomically, so that it is never left truncated or half written by a crash or a power loss.
 func WriteFile(filename string, data []byte, perm os.FileMode) error {
 	spec := PathMutexSpec(filename)
 	klog.Infof("WriteFile acquiring %s: %+v", filename, spec)
@@ -40,7 +43,62 @@ func WriteFile(filename string, data []byte, perm os.FileMode) error {
 
 	defer releaser.Release()
 
-	return os.WriteFile(filename, data, perm)
+	return writeFileAtomic(filename, data, perm)
+}
+
+// writeFileAtomic writes data to a temp file next to filename, syncs it to disk and renames it to filename
+func writeFileAtomic(filename string, data []byte, perm os.FileMode) error {
+	// replace the target of a symlink, not the symlink
+	if target, err := filepath.EvalSymlinks(filename); err == nil {
+		filename = target
+	}
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp")
+	if err != nil {
+		return errors.Wrapf(err, "create temp file for %s", filename)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "write %s", tmp.Name())
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "chmod %s", tmp.Name())
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "sync %s", tmp.Name())
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "close %s", tmp.Name())
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return errors.Wrapf(err, "rename %s", tmp.Name())
+	}
+	syncDir(dir)
+	return nil
+}
+
+// syncDir syncs a directory to disk, so that a rename in it survives a power loss
+func syncDir(dir string) {
+	// directories can not be synced on Windows
+	if runtime.GOOS == "windows" {
+		return
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		klog.Warningf("unable to open %s: %v", dir, err)
+		return
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		klog.Warningf("unable to sync %s: %v", dir, err)
+	}
 }
 
 // AppendToFile appends DATA bytes to the specified FILENAME in a mutually exclusive way.
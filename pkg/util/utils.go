@@ -19,6 +19,7 @@ package util
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"os/user"
 	"path/filepath"
 	"strconv"
@@ -26,6 +27,7 @@ import (
 	"github.com/blang/semver/v4"
 	units "github.com/docker/go-units"
 	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
 )
 
 const (
@@ -123,3 +125,13 @@ func RemoveDuplicateStrings(initial []string) []string {
 	}
 	return result
 }
+
+// PowerShell runs script in a non-interactive PowerShell without profile, the error holding its output
+func PowerShell(script string) error {
+	klog.Infof("Run: powershell %s", script)
+	out, err := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-Command", script).CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "powershell: %s", out)
+	}
+	return nil
+}
@@ -130,6 +130,10 @@ func dir2Npmode(d os.FileInfo, dotu bool) uint32 {
 	return ret
 }
 
+// NotifyLength is the length of the truncations answered without changing the file nor its times: the client
+// notifies its inotify watchers of a modification of the file, which minikube mount uses to forward the changes of the host.
+const NotifyLength = 1<<63 - 1
+
 // Dir is an instantiation of the p.Dir structure
 // that can act as a receiver for local methods.
 type ufsDir struct {
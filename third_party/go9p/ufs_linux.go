@@ -126,6 +126,10 @@ func (u *Ufs) Wstat(req *SrvReq) {
 	}
 
 	dir := &req.Tc.Dir
+	if dir.Length == NotifyLength {
+		req.RespondRwstat()
+		return
+	}
 	if dir.Mode != 0xFFFFFFFF {
 		mode := dir.Mode & 0777
 		if req.Conn.Dotu {